@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -71,6 +72,16 @@ func main() {
 	roleRepo := postgres.NewRoleRepository(db)
 	branchRepo := postgres.NewBranchRepository(db)
 	categoryRepo := postgres.NewCategoryRepository(db)
+	loanProductRepo := postgres.NewLoanProductRepository(db)
+	campaignRepo := postgres.NewCampaignRepository(db)
+	noteRepo := postgres.NewNoteRepository(db)
+	fxRateRepo := postgres.NewFXRateRepository(db)
+	eventRepo := postgres.NewEventRepository(db)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	stockTakeRepo := postgres.NewStockTakeRepository(db)
+	collectionRepo := postgres.NewCollectionRepository(db)
+	exportJobRepo := postgres.NewExportJobRepository(db)
+	storedFileRepo := postgres.NewStoredFileRepository(db)
 	customerRepo := postgres.NewCustomerRepository(db)
 	itemRepo := postgres.NewItemRepository(db)
 	loanRepo := postgres.NewLoanRepository(db)
@@ -91,8 +102,16 @@ func main() {
 	notificationTemplateRepo := postgres.NewNotificationTemplateRepository(db)
 	notificationPreferenceRepo := postgres.NewCustomerNotificationPreferenceRepository(db)
 	internalNotificationRepo := postgres.NewInternalNotificationRepository(db)
+	userNotificationPreferenceRepo := postgres.NewUserNotificationPreferenceRepository(db)
+	notificationChannelStatusRepo := postgres.NewNotificationChannelStatusRepository(db)
+	contactVerificationRepo := postgres.NewContactVerificationRepository(db)
+	jobRunRepo := postgres.NewJobRunRepository(db)
 	twoFactorRepo := postgres.NewTwoFactorRepository(db)
 	loyaltyRepo := postgres.NewLoyaltyRepository(db)
+	accountRepo := postgres.NewAccountRepository(db)
+	accountingEntryRepo := postgres.NewAccountingEntryRepository(db)
+	accountingPeriodRepo := postgres.NewAccountingPeriodRepository(db)
+	documentRepo := postgres.NewDocumentRepository(db)
 
 	// Initialize auth components
 	jwtManager := auth.NewJWTManager(auth.JWTConfig{
@@ -103,26 +122,60 @@ func main() {
 	})
 	passwordManager := auth.NewPasswordManager()
 
+	// Initialize storage service
+	storagePath := filepath.Join(".", "storage")
+	storageBaseURL := "/storage" // URL path for serving images
+	storageService := service.NewStorageService(&cfg.Storage, storagePath, storageBaseURL)
+
+	// Initialize PDF generator
+	pdfGenerator := pdf.NewGenerator(cfg.App.Name, "", "", cfg.App.VerifyURL).WithVerificationKey(cfg.App.VerifyKey)
+	reportService := service.NewReportService(loanRepo, paymentRepo, saleRepo, customerRepo, itemRepo, documentRepo, pdfGenerator, storageService)
+	reportService.SetDailyBalances(postgres.NewDailyBalanceRepository(db), branchRepo)
+	reportService.SetLoanSnapshots(postgres.NewLoanBalanceSnapshotRepository(db), settingRepo)
+	reportService.SetUserPerformance(postgres.NewUserActivityRepository(db), userRepo, settingRepo)
+	loanWriteOffRepo := postgres.NewLoanWriteOffRepository(db)
+	reportService.SetWriteOffs(loanWriteOffRepo)
+	moneyFormatService := service.NewMoneyFormatService(branchRepo, settingRepo)
+	reportService.SetMoneyFormat(moneyFormatService)
+
 	// Initialize services
 	authService := service.NewAuthService(userRepo, roleRepo, refreshTokenRepo, jwtManager, passwordManager, log.Logger)
 	userService := service.NewUserService(userRepo, roleRepo, branchRepo, passwordManager)
-	customerService := service.NewCustomerService(customerRepo, branchRepo)
-	itemService := service.NewItemService(itemRepo, branchRepo, categoryRepo, customerRepo)
-	loanService := service.NewLoanService(loanRepo, itemRepo, customerRepo, paymentRepo, settingRepo, log.Logger)
-	paymentService := service.NewPaymentService(paymentRepo, loanRepo, customerRepo, itemRepo, log.Logger)
-	saleService := service.NewSaleService(saleRepo, itemRepo, customerRepo, branchRepo)
-	cashService := service.NewCashService(cashRegisterRepo, cashSessionRepo, cashMovementRepo, branchRepo)
+	customerService := service.NewCustomerService(customerRepo, branchRepo, loanRepo, paymentRepo, saleRepo, notificationRepo, notificationChannelStatusRepo)
+	contactVerificationService := service.NewContactVerificationService(contactVerificationRepo, customerRepo, notificationRepo, settingRepo)
+	customerService.SetContactVerification(contactVerificationService)
+	itemService := service.NewItemService(itemRepo, branchRepo, categoryRepo, customerRepo, settingRepo)
+	itemService.SetStockTakes(stockTakeRepo)
+	itemService.SetAttentionQueue(postgres.NewItemAttentionRepository(db))
+	eventService := service.NewEventService(eventRepo, webhookRepo, service.NewHTTPWebhookSender(), log.Logger)
+	cashService := service.NewCashService(cashRegisterRepo, cashSessionRepo, cashMovementRepo, branchRepo, paymentRepo, saleRepo)
+	cashService.SetEvents(eventService, settingRepo)
+	loanService := service.NewLoanService(loanRepo, itemRepo, customerRepo, paymentRepo, categoryRepo, loanProductRepo, campaignRepo, settingRepo, cashService, reportService, log.Logger)
+	loanService.SetBranches(branchRepo)
+	loanService.SetMoneyFormat(moneyFormatService)
+	loanService.SetVerificationKey(cfg.App.VerifyKey)
+	loanService.SetWriteOffs(loanWriteOffRepo, accountRepo, accountingEntryRepo)
+	for _, warning := range loanService.CheckDefaultRates(context.Background()) {
+		log.Warn().Str("check", "rate_bounds").Msg(warning)
+	}
+	paymentService := service.NewPaymentService(paymentRepo, loanRepo, customerRepo, itemRepo, settingRepo, cashService, log.Logger)
+	paymentService.SetAccounting(accountRepo, accountingEntryRepo)
+	saleService := service.NewSaleService(saleRepo, itemRepo, customerRepo, branchRepo, cashService)
 	branchService := service.NewBranchService(branchRepo)
 	categoryService := service.NewCategoryService(categoryRepo)
+	loanProductService := service.NewLoanProductService(loanProductRepo)
+	campaignService := service.NewCampaignService(campaignRepo, customerRepo)
+	dashboardAlertService := service.NewDashboardAlertService(reportService, itemRepo, settingRepo, cashService)
 
 	// Use cached services when Redis is available
 	roleService := service.NewCachedRoleService(roleRepo, redisCache)
 	settingService := service.NewCachedSettingService(settingRepo, redisCache)
+	settingService.SetHistory(postgres.NewSettingHistoryRepository(db))
 	auditService := service.NewAuditService(auditRepo)
 
 	// New services for transfers, expenses, and notifications
-	transferService := service.NewTransferService(transferRepo, itemRepo, branchRepo)
-	expenseService := service.NewExpenseService(expenseRepo, expenseCategoryRepo, branchRepo)
+	transferService := service.NewTransferService(transferRepo, itemRepo, branchRepo, itemService, settingRepo)
+	expenseService := service.NewExpenseService(expenseRepo, expenseCategoryRepo, branchRepo, settingRepo, cashService)
 	notificationService := service.NewNotificationService(
 		notificationRepo,
 		notificationTemplateRepo,
@@ -131,54 +184,103 @@ func main() {
 		customerRepo,
 		userRepo,
 	)
+	notificationService.SetQuietHours(settingRepo, branchRepo)
+	notificationEscalationService := service.NewNotificationEscalationService(notificationChannelStatusRepo, settingRepo, notificationService, log.Logger)
+	notificationRouter := service.NewNotificationRouter(settingRepo, roleRepo, userRepo, internalNotificationRepo, log.Logger)
+	notificationRouter.SetDigests(userNotificationPreferenceRepo)
+	notificationService.SetDigests(userNotificationPreferenceRepo, log.Logger)
+	loanService.SetNotificationRouter(notificationRouter)
+	cashService.SetNotificationRouter(notificationRouter)
+	saleService.SetRoundTripDetection(service.NewRoundTripDetector(loanRepo, settingRepo), notificationRouter)
+	saleService.SetWarrantyPolicy(categoryRepo, settingRepo)
+	saleService.SetAccounting(accountRepo, accountingEntryRepo, log.Logger)
 	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, passwordManager, cfg.App.Name)
-	loyaltyService := service.NewLoyaltyService(customerRepo, loyaltyRepo)
-
-	// Initialize storage service
-	storagePath := filepath.Join(".", "storage")
-	storageBaseURL := "/storage" // URL path for serving images
-	storageService := service.NewStorageService(storagePath, storageBaseURL)
+	loyaltyService := service.NewLoyaltyService(customerRepo, loyaltyRepo, settingRepo)
+	loyaltyService.SetMoneyFormat(moneyFormatService)
+	accountingService := service.NewAccountingService(accountRepo, accountingEntryRepo)
+	accountingService.SetInventoryReconciliation(postgres.NewInventoryValuationRepository(db), settingRepo)
+	accountingService.SetTrialBalancePDF(pdfGenerator)
+	accountingPeriodService := service.NewAccountingPeriodService(accountingPeriodRepo, accountRepo, accountingEntryRepo, loanRepo, settingRepo)
+	accountingPeriodService.SetInventoryCheck(accountingService)
+	jobMonitorService := service.NewJobMonitorService(jobRunRepo, roleRepo, userRepo, notificationService, log.Logger)
+	noteService := service.NewNoteService(noteRepo, loanRepo, itemRepo, customerRepo, userRepo, notificationService)
+	fxService := service.NewFXService(fxRateRepo, settingRepo)
+	branchComparisonService := service.NewBranchComparisonService(branchRepo, loanRepo, paymentRepo, saleRepo, fxService)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, itemRepo, branchRepo)
+	collectionService := service.NewCollectionService(collectionRepo, loanRepo, userRepo, settingRepo, log.Logger)
+	exportService := service.NewExportService(exportJobRepo, itemRepo, paymentRepo, cfg.Storage.ExportDir, log.Logger)
+	storageQuotaService := service.NewStorageQuotaService(storedFileRepo, branchRepo, settingRepo)
+	reportService.SetStorageQuota(storageQuotaService)
 
 	// Initialize backup service
 	backupPath := filepath.Join(".", "backups")
 	backupService := service.NewBackupService(&cfg.Database, backupPath, log.Logger)
 
-	// Initialize PDF generator
-	pdfGenerator := pdf.NewGenerator(cfg.App.Name, "", "")
-	reportService := service.NewReportService(loanRepo, paymentRepo, saleRepo, customerRepo, itemRepo, pdfGenerator)
-
 	// Initialize audit logger
 	auditLogger := middleware.NewAuditLogger(auditService)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, auditLogger, log.Logger)
 	userHandler := handler.NewUserHandler(userService, auditLogger)
-	customerHandler := handler.NewCustomerHandler(customerService, auditLogger)
+	customerHandler := handler.NewCustomerHandler(customerService, contactVerificationService, auditLogger)
 	itemHandler := handler.NewItemHandler(itemService, auditLogger)
-	loanHandler := handler.NewLoanHandler(loanService, auditLogger, log.Logger)
-	paymentHandler := handler.NewPaymentHandler(paymentService, auditLogger, log.Logger)
+	loanHandler := handler.NewLoanHandler(loanService, reportService, moneyFormatService, auditLogger, log.Logger)
+	paymentHandler := handler.NewPaymentHandler(paymentService, moneyFormatService, auditLogger, log.Logger)
 	saleHandler := handler.NewSaleHandler(saleService, auditLogger)
 	cashHandler := handler.NewCashHandler(cashService, auditLogger)
 	branchHandler := handler.NewBranchHandler(branchService, auditLogger)
 	categoryHandler := handler.NewCategoryHandler(categoryService, auditLogger)
+	loanProductHandler := handler.NewLoanProductHandler(loanProductService, auditLogger)
+	campaignHandler := handler.NewCampaignHandler(campaignService, auditLogger)
 	roleHandler := handler.NewRoleHandler(roleService, auditLogger)
 	reportHandler := handler.NewReportHandler(reportService)
+	dashboardHandler := handler.NewDashboardHandler(dashboardAlertService)
 	settingHandler := handler.NewSettingHandler(settingService, auditLogger)
 	auditHandler := handler.NewAuditHandler(auditService)
 
 	// New handlers for transfers, expenses, and notifications
 	transferHandler := handler.NewTransferHandler(transferService)
 	expenseHandler := handler.NewExpenseHandler(expenseService, auditLogger)
-	notificationHandler := handler.NewNotificationHandler(notificationService)
+	notificationHandler := handler.NewNotificationHandler(notificationService, notificationEscalationService, notificationRouter)
+	notificationHandler.SetDigests(service.NewNotificationDigestService(internalNotificationRepo, userNotificationPreferenceRepo, log.Logger))
+	webhookSecrets := cfg.Notifications.WebhookSecrets
+	if _, ok := webhookSecrets["twilio"]; !ok && cfg.Notifications.Twilio.AuthToken != "" {
+		// Twilio signs its status callbacks with the account's auth token
+		webhookSecrets = map[string]string{"twilio": cfg.Notifications.Twilio.AuthToken}
+		for provider, secret := range cfg.Notifications.WebhookSecrets {
+			webhookSecrets[provider] = secret
+		}
+	}
+	if len(webhookSecrets) > 0 {
+		deliveryService := service.NewNotificationDeliveryService(notificationRepo, log.Logger)
+		for provider, secret := range webhookSecrets {
+			deliveryService.RegisterProvider(provider, secret)
+		}
+		notificationHandler.SetDeliveryReceipts(deliveryService)
+	}
 	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, userService)
 	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService)
-	storageHandler := handler.NewStorageHandler(storageService, itemService)
+	storageHandler := handler.NewStorageHandler(storageService, itemService, storageQuotaService)
 	backupHandler := handler.NewBackupHandler(backupService)
+	accountingHandler := handler.NewAccountingHandler(accountingService, accountingPeriodService)
+	schedulerHandler := handler.NewSchedulerHandler(jobMonitorService)
+	noteHandler := handler.NewNoteHandler(noteService, auditLogger)
+	fxHandler := handler.NewFXHandler(fxService, branchComparisonService, auditLogger)
+	eventHandler := handler.NewEventHandler(eventService, auditLogger)
+	stockTakeHandler := handler.NewStockTakeHandler(stockTakeService, auditLogger)
+	collectionHandler := handler.NewCollectionHandler(collectionService, auditLogger)
+	exportHandler := handler.NewExportHandler(exportService, auditLogger)
 
 	// Initialize middleware
 	loggingMiddleware := middleware.NewLoggingMiddleware(log.Logger)
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo, roleRepo, log.Logger)
-	rateLimiter := middleware.NewRateLimiter(middleware.DefaultRateLimitConfig())
+	rateLimitConfig := middleware.DefaultRateLimitConfig()
+	rateLimitConfig.TierFunc = middleware.NewRateLimitTierResolver(middleware.RateLimitTiers{
+		Tiers:   cfg.RateLimit.Tiers,
+		Roles:   cfg.RateLimit.Roles,
+		APIKeys: cfg.RateLimit.APIKeys,
+	}, jwtManager, roleRepo).Resolve
+	rateLimiter := middleware.NewRateLimiter(rateLimitConfig)
 	loginRateLimiter := middleware.NewRateLimiter(middleware.LoginRateLimitConfig())
 
 	// Create Fiber app
@@ -193,6 +295,7 @@ func main() {
 
 	// Global middleware
 	app.Use(recover.New())
+	app.Use(middleware.RequestID())
 	app.Use(loggingMiddleware.Logger())
 	app.Use(loggingMiddleware.Recovery())
 
@@ -273,8 +376,11 @@ func main() {
 	cashHandler.RegisterRoutes(api, authMiddleware)
 	branchHandler.RegisterRoutes(api, authMiddleware)
 	categoryHandler.RegisterRoutes(api, authMiddleware)
+	loanProductHandler.RegisterRoutes(api, authMiddleware)
+	campaignHandler.RegisterRoutes(api, authMiddleware)
 	roleHandler.RegisterRoutes(api, authMiddleware)
 	reportHandler.RegisterRoutes(api, authMiddleware)
+	dashboardHandler.RegisterRoutes(api, authMiddleware)
 	settingHandler.RegisterRoutes(api, authMiddleware)
 	auditHandler.RegisterRoutes(api, authMiddleware)
 
@@ -286,6 +392,14 @@ func main() {
 	loyaltyHandler.RegisterRoutes(api, authMiddleware)
 	storageHandler.RegisterRoutes(app, api, authMiddleware)
 	backupHandler.RegisterRoutes(api, authMiddleware)
+	accountingHandler.RegisterRoutes(api, authMiddleware)
+	schedulerHandler.RegisterRoutes(api, authMiddleware)
+	noteHandler.RegisterRoutes(api, authMiddleware)
+	fxHandler.RegisterRoutes(api, authMiddleware)
+	eventHandler.RegisterRoutes(api, authMiddleware)
+	stockTakeHandler.RegisterRoutes(api, authMiddleware)
+	collectionHandler.RegisterRoutes(api, authMiddleware)
+	exportHandler.RegisterRoutes(api, authMiddleware)
 
 	// 404 handler
 	app.Use(func(c *fiber.Ctx) error {
@@ -362,7 +476,7 @@ func errorHandler(c *fiber.Ctx, err error) error {
 	}
 
 	// Log the error with details
-	requestID := c.Get("X-Request-ID")
+	requestID := middleware.GetRequestID(c)
 	logEvent := log.Error().
 		Err(err).
 		Str("request_id", requestID).
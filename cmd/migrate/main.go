@@ -1,20 +1,45 @@
 package main
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"pawnshop/internal/config"
 )
 
 func main() {
+	// Parse command
+	flag.Parse()
+	command := flag.Arg(0)
+
+	// Scaffolding needs no database
+	if command == "create" {
+		if flag.Arg(1) == "" {
+			fmt.Println("Usage: migrate create <name>")
+			os.Exit(1)
+		}
+		files, err := createMigration("migrations", flag.Arg(1), time.Now())
+		if err != nil {
+			log.Fatal("Failed to create migration:", err)
+		}
+		for _, file := range files {
+			fmt.Printf("Created %s\n", file)
+		}
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal("Failed to load config:", err)
@@ -30,18 +55,26 @@ func main() {
 		log.Fatal("Failed to ping database:", err)
 	}
 
-	// Parse command
-	flag.Parse()
-	command := flag.Arg(0)
+	store, err := newSQLStore(db)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	switch command {
 	case "up":
-		if err := migrateUp(db); err != nil {
+		upFlags := flag.NewFlagSet("up", flag.ExitOnError)
+		force := upFlags.Bool("force", false, "re-record the checksums of modified applied migrations")
+		upFlags.Parse(flag.Args()[1:])
+		if err := migrateUp(store, "migrations", *force); err != nil {
 			log.Fatal("Migration failed:", err)
 		}
 		fmt.Println("Migrations completed successfully")
 	case "down":
-		if err := migrateDown(db); err != nil {
+		steps, err := parseSteps(flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := migrateDown(store, "migrations", steps); err != nil {
 			log.Fatal("Rollback failed:", err)
 		}
 		fmt.Println("Rollback completed successfully")
@@ -50,50 +83,40 @@ func main() {
 			log.Fatal("Failed to show status:", err)
 		}
 	default:
-		fmt.Println("Usage: migrate [up|down|status]")
+		fmt.Println("Usage: migrate [up [--force]|down [N]|status|create <name>]")
 		os.Exit(1)
 	}
 }
 
-func migrateUp(db *sql.DB) error {
-	// Create migrations table if not exists
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMPTZ DEFAULT NOW()
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
+// migrateUp applies the pending migrations after checking the applied ones
+// still match the checksum recorded when they ran. With force, mismatching
+// checksums are re-recorded instead of failing.
+func migrateUp(store migrationStore, dir string, force bool) error {
 	// Get applied migrations
-	applied := make(map[string]bool)
-	rows, err := db.Query("SELECT version FROM schema_migrations")
+	migrations, err := store.Applied()
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return err
-		}
-		applied[version] = true
+	applied := make(map[string]string, len(migrations))
+	for _, migration := range migrations {
+		applied[migration.Version] = migration.Checksum
 	}
 
 	// Get migration files
-	files, err := filepath.Glob("migrations/*.up.sql")
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
 	if err != nil {
 		return fmt.Errorf("failed to read migrations: %w", err)
 	}
 	sort.Strings(files)
 
+	if err := verifyChecksums(store, files, applied, force); err != nil {
+		return err
+	}
+
 	// Apply pending migrations
 	for _, file := range files {
 		version := extractVersion(file)
-		if applied[version] {
+		if _, ok := applied[version]; ok {
 			continue
 		}
 
@@ -104,75 +127,111 @@ func migrateUp(db *sql.DB) error {
 
 		fmt.Printf("Applying %s...\n", version)
 
-		tx, err := db.Begin()
-		if err != nil {
+		if err := store.Apply(version, string(content), checksum(content)); err != nil {
 			return err
 		}
 
-		if _, err := tx.Exec(string(content)); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to apply %s: %w", file, err)
+		fmt.Printf("Applied %s\n", version)
+	}
+
+	return nil
+}
+
+// verifyChecksums fails when an applied migration file was edited since it
+// ran, listing every edited file. Migrations applied before checksums were
+// recorded get their current checksum.
+func verifyChecksums(store migrationStore, files []string, applied map[string]string, force bool) error {
+	var mismatched []string
+	for _, file := range files {
+		version := extractVersion(file)
+		recorded, ok := applied[version]
+		if !ok {
+			continue
 		}
 
-		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		current := checksum(content)
+		if current == recorded {
+			continue
 		}
 
-		if err := tx.Commit(); err != nil {
+		if recorded != "" && !force {
+			mismatched = append(mismatched, version)
+			continue
+		}
+		if recorded != "" {
+			fmt.Printf("Re-recording checksum of %s\n", version)
+		}
+		if err := store.RecordChecksum(version, current); err != nil {
 			return err
 		}
-
-		fmt.Printf("Applied %s\n", version)
 	}
 
+	if len(mismatched) > 0 {
+		return fmt.Errorf("applied migrations were modified since they ran: %s (restore the files, or rerun with --force to accept them)",
+			strings.Join(mismatched, ", "))
+	}
 	return nil
 }
 
-func migrateDown(db *sql.DB) error {
-	// Get last applied migration
-	var version string
-	err := db.QueryRow("SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1").Scan(&version)
+// checksum returns the hex SHA-256 of a migration's content
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// migrateDown rolls back the last steps applied migrations, newest first,
+// stopping at the first one that fails
+func migrateDown(store migrationStore, dir string, steps int) error {
+	migrations, err := store.Applied()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			fmt.Println("No migrations to rollback")
-			return nil
-		}
 		return err
 	}
-
-	// Find corresponding down file
-	downFile := fmt.Sprintf("migrations/%s.down.sql", version)
-	content, err := os.ReadFile(downFile)
-	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", downFile, err)
+	if len(migrations) == 0 {
+		fmt.Println("No migrations to rollback")
+		return nil
+	}
+	if steps > len(migrations) {
+		steps = len(migrations)
 	}
 
-	fmt.Printf("Rolling back %s...\n", version)
+	for i := len(migrations) - 1; i >= len(migrations)-steps; i-- {
+		version := migrations[i].Version
 
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
+		// Find corresponding down file
+		downFile := filepath.Join(dir, version+".down.sql")
+		content, err := os.ReadFile(downFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", downFile, err)
+		}
 
-	if _, err := tx.Exec(string(content)); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to rollback %s: %w", version, err)
-	}
+		fmt.Printf("Rolling back %s...\n", version)
 
-	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", version); err != nil {
-		tx.Rollback()
-		return err
-	}
+		if err := store.Rollback(version, string(content)); err != nil {
+			return err
+		}
 
-	if err := tx.Commit(); err != nil {
-		return err
+		fmt.Printf("Rolled back %s\n", version)
 	}
 
-	fmt.Printf("Rolled back %s\n", version)
 	return nil
 }
 
+// parseSteps reads the optional count of migrations to roll back, 1 by default
+func parseSteps(arg string) (int, error) {
+	if arg == "" {
+		return 1, nil
+	}
+	steps, err := strconv.Atoi(arg)
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of steps %q", arg)
+	}
+	return steps, nil
+}
+
 func showStatus(db *sql.DB) error {
 	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations ORDER BY version")
 	if err != nil {
@@ -200,3 +259,53 @@ func extractVersion(filename string) string {
 	version = strings.TrimSuffix(version, ".down.sql")
 	return version
 }
+
+// migrationNameChars matches the runs of characters not allowed in a migration name
+var migrationNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationFilenames returns the up and down file names of a new migration,
+// versioned by the creation time so both share the same prefix
+func migrationFilenames(name string, now time.Time) (string, string, error) {
+	name = strings.Trim(migrationNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	version := fmt.Sprintf("%s_%s", now.UTC().Format("20060102150405"), name)
+	return version + ".up.sql", version + ".down.sql", nil
+}
+
+// createMigration writes an empty up/down migration pair to dir. It refuses to
+// overwrite existing files, leaving both untouched when either exists.
+func createMigration(dir, name string, now time.Time) ([]string, error) {
+	up, down, err := migrationFilenames(name, now)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{filepath.Join(dir, up), filepath.Join(dir, down)}
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			return nil, fmt.Errorf("%s already exists", file)
+		}
+	}
+
+	version := extractVersion(up)
+	templates := []string{
+		fmt.Sprintf("-- Migration: %s\n\n", version),
+		fmt.Sprintf("-- Rollback: %s\n\n", version),
+	}
+	for i, file := range files {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", file, err)
+		}
+		_, err = f.WriteString(templates[i])
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+
+	return files, nil
+}
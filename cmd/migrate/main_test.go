@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore records migrations in memory and fails the versions listed in failOn
+type fakeStore struct {
+	applied []appliedMigration
+	failOn  map[string]bool
+}
+
+func (f *fakeStore) Applied() ([]appliedMigration, error) {
+	return append([]appliedMigration(nil), f.applied...), nil
+}
+
+func (f *fakeStore) Apply(version, content, checksum string) error {
+	f.applied = append(f.applied, appliedMigration{Version: version, Checksum: checksum})
+	return nil
+}
+
+func (f *fakeStore) Rollback(version, content string) error {
+	if f.failOn[version] {
+		return errors.New("rollback failed")
+	}
+	for i, applied := range f.applied {
+		if applied.Version == version {
+			f.applied = append(f.applied[:i], f.applied[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) RecordChecksum(version, checksum string) error {
+	for i := range f.applied {
+		if f.applied[i].Version == version {
+			f.applied[i].Checksum = checksum
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) versions() []string {
+	versions := make([]string, len(f.applied))
+	for i, applied := range f.applied {
+		versions[i] = applied.Version
+	}
+	return versions
+}
+
+// writeMigrations writes up/down pairs for the given versions to a new directory
+func writeMigrations(t *testing.T, versions ...string) string {
+	dir := t.TempDir()
+	for _, version := range versions {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version+".up.sql"), []byte("SELECT 1;"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version+".down.sql"), []byte("SELECT 1;"), 0644))
+	}
+	return dir
+}
+
+func TestMigrateDown_RollsBackSteps(t *testing.T) {
+	dir := writeMigrations(t, "000001_a", "000002_b", "000003_c")
+	store := &fakeStore{}
+	require.NoError(t, migrateUp(store, dir, false))
+	require.Equal(t, []string{"000001_a", "000002_b", "000003_c"}, store.versions())
+
+	require.NoError(t, migrateDown(store, dir, 2))
+
+	assert.Equal(t, []string{"000001_a"}, store.versions())
+}
+
+func TestMigrateDown_StopsOnFirstError(t *testing.T) {
+	dir := writeMigrations(t, "000001_a", "000002_b", "000003_c")
+	store := &fakeStore{failOn: map[string]bool{"000002_b": true}}
+	require.NoError(t, migrateUp(store, dir, false))
+
+	err := migrateDown(store, dir, 3)
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"000001_a", "000002_b"}, store.versions())
+}
+
+func TestMigrateUp_DetectsModifiedMigration(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "000001_a.up.sql")
+	require.NoError(t, os.WriteFile(file, []byte("CREATE TABLE a (id INT);"), 0644))
+	store := &fakeStore{}
+	require.NoError(t, migrateUp(store, dir, false))
+	original := store.applied[0].Checksum
+
+	// The same version now has different content
+	require.NoError(t, os.WriteFile(file, []byte("CREATE TABLE a (id BIGINT);"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000002_b.up.sql"), []byte("SELECT 1;"), 0644))
+
+	err := migrateUp(store, dir, false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "000001_a")
+	assert.Equal(t, []string{"000001_a"}, store.versions(), "nothing is applied while a migration is modified")
+	assert.Equal(t, original, store.applied[0].Checksum)
+
+	// Forcing re-records the checksum and carries on
+	require.NoError(t, migrateUp(store, dir, true))
+	assert.Equal(t, []string{"000001_a", "000002_b"}, store.versions())
+	assert.Equal(t, checksum([]byte("CREATE TABLE a (id BIGINT);")), store.applied[0].Checksum)
+	require.NoError(t, migrateUp(store, dir, false))
+}
+
+func TestMigrateUp_BackfillsMissingChecksums(t *testing.T) {
+	dir := writeMigrations(t, "000001_a")
+	store := &fakeStore{applied: []appliedMigration{{Version: "000001_a"}}}
+
+	require.NoError(t, migrateUp(store, dir, false))
+
+	assert.Equal(t, checksum([]byte("SELECT 1;")), store.applied[0].Checksum)
+}
+
+func TestParseSteps(t *testing.T) {
+	steps, err := parseSteps("")
+	require.NoError(t, err)
+	assert.Equal(t, 1, steps)
+
+	steps, err = parseSteps("3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, steps)
+
+	for _, arg := range []string{"0", "-1", "two"} {
+		_, err := parseSteps(arg)
+		assert.Error(t, err, arg)
+	}
+}
+
+func TestMigrationFilenames_ShareVersion(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	up, down, err := migrationFilenames("Add foo", now)
+
+	require.NoError(t, err)
+	assert.Equal(t, "20240101120000_add_foo.up.sql", up)
+	assert.Equal(t, "20240101120000_add_foo.down.sql", down)
+	assert.Equal(t, extractVersion(up), extractVersion(down))
+	assert.True(t, strings.HasPrefix(extractVersion(up), "20240101120000_"))
+
+	_, _, err = migrationFilenames(" -- ", now)
+	assert.Error(t, err)
+}
+
+func TestCreateMigration_RefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	files, err := createMigration(dir, "add_foo", now)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, file := range files {
+		assert.FileExists(t, file)
+	}
+
+	// An edited migration is never clobbered by a second create
+	require.NoError(t, os.WriteFile(files[0], []byte("ALTER TABLE foo ADD COLUMN bar INT;"), 0644))
+	_, err = createMigration(dir, "add_foo", now)
+	assert.Error(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "20240101120000_add_foo.up.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE foo ADD COLUMN bar INT;", string(content))
+}
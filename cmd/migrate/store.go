@@ -0,0 +1,117 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// appliedMigration is the record of an applied migration. Checksum is the
+// SHA-256 of its up file, empty for migrations applied before checksums were
+// recorded.
+type appliedMigration struct {
+	Version  string
+	Checksum string
+}
+
+// migrationStore records which migrations are applied and runs each one in
+// its own transaction together with its record
+type migrationStore interface {
+	// Applied returns the applied migrations, oldest first
+	Applied() ([]appliedMigration, error)
+
+	// Apply runs an up migration and records it with its checksum
+	Apply(version, content, checksum string) error
+
+	// Rollback runs a down migration and removes its record
+	Rollback(version, content string) error
+
+	// RecordChecksum replaces the checksum recorded for an applied migration
+	RecordChecksum(version, checksum string) error
+}
+
+// sqlStore keeps the migration records in the schema_migrations table
+type sqlStore struct {
+	db *sql.DB
+}
+
+// newSQLStore creates the schema_migrations table if needed
+func newSQLStore(db *sql.DB) (*sqlStore, error) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMPTZ DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	// Tables created before checksums were recorded lack the column
+	if _, err := db.Exec("ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64)"); err != nil {
+		return nil, fmt.Errorf("failed to add migration checksum column: %w", err)
+	}
+	return &sqlStore{db: db}, nil
+}
+
+func (s *sqlStore) Applied() ([]appliedMigration, error) {
+	rows, err := s.db.Query("SELECT version, COALESCE(checksum, '') FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var migrations []appliedMigration
+	for rows.Next() {
+		var migration appliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Checksum); err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, rows.Err()
+}
+
+func (s *sqlStore) Apply(version, content, checksum string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(content); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to apply %s: %w", version, err)
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)", version, checksum); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqlStore) Rollback(version, content string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(content); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to rollback %s: %w", version, err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqlStore) RecordChecksum(version, checksum string) error {
+	if _, err := s.db.Exec("UPDATE schema_migrations SET checksum = $2 WHERE version = $1", version, checksum); err != nil {
+		return fmt.Errorf("failed to record checksum of %s: %w", version, err)
+	}
+	return nil
+}
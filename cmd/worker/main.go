@@ -1,14 +1,19 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"pawnshop/internal/config"
+	"pawnshop/internal/pdf"
 	"pawnshop/internal/repository/postgres"
 	"pawnshop/internal/scheduler"
 	"pawnshop/internal/service"
@@ -51,6 +56,15 @@ func main() {
 	notificationTemplateRepo := postgres.NewNotificationTemplateRepository(db)
 	notificationPreferenceRepo := postgres.NewCustomerNotificationPreferenceRepository(db)
 	internalNotificationRepo := postgres.NewInternalNotificationRepository(db)
+	userNotificationPreferenceRepo := postgres.NewUserNotificationPreferenceRepository(db)
+	notificationChannelStatusRepo := postgres.NewNotificationChannelStatusRepository(db)
+	settingRepo := postgres.NewSettingRepository(db)
+	roleRepo := postgres.NewRoleRepository(db)
+	jobRunRepo := postgres.NewJobRunRepository(db)
+	markdownRepo := postgres.NewMarkdownRepository(db)
+	branchRepo := postgres.NewBranchRepository(db)
+	dailyBalanceRepo := postgres.NewDailyBalanceRepository(db)
+	saleRepo := postgres.NewSaleRepository(db)
 
 	// Initialize services
 	notificationService := service.NewNotificationService(
@@ -61,10 +75,38 @@ func main() {
 		customerRepo,
 		userRepo,
 	)
-	loyaltyService := service.NewLoyaltyService(customerRepo, loyaltyRepo)
+	notificationService.SetRetryBackoffBase(cfg.Worker.RetryBackoffBase)
+	notificationService.SetQuietHours(settingRepo, branchRepo)
+	notificationDispatcher := service.NewNotificationDispatcher(notificationRepo, loanRepo, log.Logger)
+	notificationDispatcher.SetEscalation(service.NewNotificationEscalationService(notificationChannelStatusRepo, settingRepo, notificationService, log.Logger))
+	notificationDispatcher.SetDrainSettings(settingRepo)
+	if smtpCfg := cfg.Notifications.SMTP; smtpCfg.Host != "" {
+		notificationDispatcher.SetEmailSender(service.NewEmailNotificationSender(
+			smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password, smtpCfg.From, customerRepo,
+		))
+	}
+	if twilioCfg := cfg.Notifications.Twilio; twilioCfg.AccountSID != "" {
+		twilio := service.NewTwilioClient(twilioCfg.AccountSID, twilioCfg.AuthToken, twilioCfg.SMSFrom).WithWhatsAppFrom(twilioCfg.WhatsAppFrom)
+		if twilioCfg.SMSFrom != "" {
+			notificationDispatcher.SetSMSSender(twilio, customerRepo)
+		}
+		if twilioCfg.WhatsAppFrom != "" {
+			notificationDispatcher.SetWhatsAppSender(twilio, customerRepo)
+		}
+	}
+	// Without a sender the queue would fill up without anything ever being sent
+	if len(notificationDispatcher.Channels()) == 0 {
+		log.Fatal().Msg("No notification sender configured, set SMTP_HOST or TWILIO_ACCOUNT_SID with TWILIO_SMS_FROM or TWILIO_WHATSAPP_FROM")
+	}
+	log.Info().Strs("channels", notificationDispatcher.Channels()).Msg("Notification senders registered")
+	moneyFormatService := service.NewMoneyFormatService(branchRepo, settingRepo)
+	loyaltyService := service.NewLoyaltyService(customerRepo, loyaltyRepo, settingRepo)
+	loyaltyService.SetMoneyFormat(moneyFormatService)
+	jobMonitor := service.NewJobMonitorService(jobRunRepo, roleRepo, userRepo, notificationService, log.Logger)
 
 	// Initialize scheduler
 	sched := scheduler.New(log.Logger)
+	sched.SetRecorder(jobMonitor)
 
 	// Initialize job service
 	jobService := scheduler.NewJobService(
@@ -73,9 +115,113 @@ func main() {
 		paymentRepo,
 		customerRepo,
 		notificationService,
+		notificationDispatcher,
 		loyaltyService,
 		log.Logger,
 	)
+	jobService.SetJobMonitor(jobMonitor)
+	jobService.SetSettings(settingRepo)
+	confiscationReminderService := service.NewConfiscationReminderService(
+		loanRepo,
+		itemRepo,
+		customerRepo,
+		notificationRepo,
+		settingRepo,
+		notificationService,
+		log.Logger,
+	)
+	confiscationReminderService.SetMoneyFormat(moneyFormatService)
+	jobService.SetConfiscationReminders(confiscationReminderService)
+	markdownService := service.NewMarkdownService(
+		markdownRepo,
+		itemRepo,
+		settingRepo,
+		roleRepo,
+		userRepo,
+		notificationService,
+		log.Logger,
+	)
+	markdownService.SetMoneyFormat(moneyFormatService)
+	jobService.SetMarkdowns(markdownService)
+	// Statements are stored where the API serves them from
+	storageService := service.NewStorageService(&cfg.Storage, filepath.Join(".", "storage"), "/storage")
+	reportService := service.NewReportService(loanRepo, paymentRepo, nil, customerRepo, itemRepo,
+		postgres.NewDocumentRepository(db), pdf.NewGenerator(cfg.App.Name, "", "", cfg.App.VerifyURL).WithVerificationKey(cfg.App.VerifyKey), storageService)
+	reportService.SetDailyBalances(dailyBalanceRepo, branchRepo)
+	reportService.SetLoanSnapshots(postgres.NewLoanBalanceSnapshotRepository(db), settingRepo)
+	reportService.SetMoneyFormat(moneyFormatService)
+	reportService.SetStorageQuota(service.NewStorageQuotaService(postgres.NewStoredFileRepository(db), branchRepo, settingRepo))
+	jobService.SetDailyBalances(reportService)
+	statementService := service.NewCustomerStatementService(
+		reportService,
+		notificationPreferenceRepo,
+		notificationRepo,
+		settingRepo,
+		notificationService,
+		log.Logger,
+	)
+	statementService.SetMoneyFormat(moneyFormatService)
+	jobService.SetStatements(statementService)
+	jobService.SetCollections(service.NewCollectionService(
+		postgres.NewCollectionRepository(db),
+		loanRepo,
+		userRepo,
+		settingRepo,
+		log.Logger,
+	))
+	jobService.SetExports(service.NewExportService(
+		postgres.NewExportJobRepository(db),
+		itemRepo,
+		paymentRepo,
+		cfg.Storage.ExportDir,
+		log.Logger,
+	))
+	notificationRouter := service.NewNotificationRouter(settingRepo, roleRepo, userRepo, internalNotificationRepo, log.Logger)
+	notificationRouter.SetDigests(userNotificationPreferenceRepo)
+	notificationService.SetDigests(userNotificationPreferenceRepo, log.Logger)
+	jobService.SetNotificationRouter(notificationRouter)
+	jobService.SetNotificationDigests(service.NewNotificationDigestService(internalNotificationRepo, userNotificationPreferenceRepo, log.Logger))
+	cashService := service.NewCashService(
+		postgres.NewCashRegisterRepository(db),
+		postgres.NewCashSessionRepository(db),
+		postgres.NewCashMovementRepository(db),
+		branchRepo,
+		paymentRepo,
+		saleRepo,
+	)
+	cashService.SetEvents(service.NewEventService(
+		postgres.NewEventRepository(db),
+		postgres.NewWebhookRepository(db),
+		service.NewHTTPWebhookSender(),
+		log.Logger,
+	), settingRepo)
+	cashService.SetNotificationRouter(notificationRouter)
+	cashService.SetLogger(log.Logger)
+	jobService.SetCash(cashService)
+
+	backupService := service.NewBackupService(&cfg.Database, filepath.Join(".", "backups"), log.Logger)
+	if cfg.Worker.BackupSchedule != "" {
+		scheduledBackups := service.NewScheduledBackupService(backupService, cfg.Worker.BackupKeep, log.Logger)
+		scheduledBackups.SetNotificationRouter(notificationRouter)
+		jobService.SetScheduledBackups(scheduledBackups, cfg.Worker.BackupSchedule)
+	} else {
+		log.Info().Msg("BACKUP_SCHEDULE not set, automatic backups disabled")
+	}
+
+	// Backups are only test-restored when a scratch database is configured
+	if cfg.Worker.BackupVerifyDSN != "" {
+		backupVerification := service.NewBackupVerificationService(
+			backupService,
+			service.NewPsqlBackupRestorer(cfg.Worker.BackupVerifyDSN),
+			postgres.NewBackupVerificationRepository(db),
+			settingRepo,
+			log.Logger,
+		)
+		backupVerification.SetNotificationRouter(notificationRouter)
+		jobService.SetBackupVerification(backupVerification)
+	} else {
+		log.Info().Msg("BACKUP_VERIFY_DSN not set, backup verification disabled")
+	}
 
 	// Register default jobs
 	scheduler.RegisterDefaultJobs(sched, jobService)
@@ -84,6 +230,19 @@ func main() {
 	sched.Start()
 	log.Info().Msg("Worker started")
 
+	// Expose job metrics such as the age of each job's last success
+	if cfg.Worker.MetricsPort > 0 {
+		go func() {
+			addr := fmt.Sprintf(":%d", cfg.Worker.MetricsPort)
+			log.Info().Str("addr", addr).Msg("Serving worker metrics")
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				log.Error().Err(err).Msg("Worker metrics server stopped")
+			}
+		}()
+	}
+
 	// Wait for shutdown signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -10,13 +10,16 @@ import (
 )
 
 type Config struct {
-	App      AppConfig
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Storage  StorageConfig
-	Logging  LoggingConfig
+	App           AppConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Redis         RedisConfig
+	JWT           JWTConfig
+	Storage       StorageConfig
+	Logging       LoggingConfig
+	Worker        WorkerConfig
+	RateLimit     RateLimitConfig
+	Notifications NotificationsConfig
 }
 
 type AppConfig struct {
@@ -24,6 +27,8 @@ type AppConfig struct {
 	Environment string
 	Debug       bool
 	Version     string
+	VerifyURL   string // base URL of the public loan verification page; empty leaves the QR code off contracts
+	VerifyKey   string // key signing contract QR codes; changing it invalidates the QR codes already printed
 }
 
 type ServerConfig struct {
@@ -67,6 +72,51 @@ type StorageConfig struct {
 	SecretKey string
 	Bucket    string
 	Region    string
+	PublicURL string // base URL objects are linked from; defaults to the endpoint
+	ExportDir string // local directory background exports are written to
+}
+
+type WorkerConfig struct {
+	MetricsPort      int           // port serving worker metrics; 0 disables it
+	BackupVerifyDSN  string        // scratch database backups are test-restored into; empty skips the verification
+	RetryBackoffBase time.Duration // wait before the first retry of a failed notification, doubled on every retry
+	BackupSchedule   string        // when the automatic backup runs, e.g. "daily@02:00"; empty disables it
+	BackupKeep       int           // number of most recent automatic backups kept
+}
+
+// RateLimitConfig assigns request budgets to API keys and roles; callers
+// matching neither get the default budget
+type RateLimitConfig struct {
+	Tiers   map[string]int    // tier name -> requests per minute
+	Roles   map[string]string // role name -> tier name
+	APIKeys map[string]string // API key -> tier name
+}
+
+// NotificationsConfig holds the providers customer notifications are sent
+// through and the secrets they sign their delivery receipts with, by provider name
+type NotificationsConfig struct {
+	WebhookSecrets map[string]string
+	SMTP           SMTPConfig
+	Twilio         TwilioConfig
+}
+
+// SMTPConfig is the server email notifications are sent through; an empty
+// host leaves email notifications unsent
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// TwilioConfig is the Twilio account SMS and WhatsApp notifications are sent
+// from; a channel without a sender number stays unsent
+type TwilioConfig struct {
+	AccountSID   string
+	AuthToken    string
+	SMSFrom      string // phone number SMS are sent from
+	WhatsAppFrom string // WhatsApp sender number, without the whatsapp: prefix
 }
 
 type LoggingConfig struct {
@@ -124,6 +174,8 @@ func Load() (*Config, error) {
 		Environment: viper.GetString("app.environment"),
 		Debug:       viper.GetBool("app.debug"),
 		Version:     viper.GetString("app.version"),
+		VerifyURL:   viper.GetString("app.verify_url"),
+		VerifyKey:   viper.GetString("app.verify_key"),
 	}
 
 	// Server
@@ -172,6 +224,8 @@ func Load() (*Config, error) {
 		SecretKey: viper.GetString("storage.secret_key"),
 		Bucket:    viper.GetString("storage.bucket"),
 		Region:    viper.GetString("storage.region"),
+		PublicURL: viper.GetString("storage.public_url"),
+		ExportDir: viper.GetString("storage.export_dir"),
 	}
 
 	// Logging
@@ -182,6 +236,54 @@ func Load() (*Config, error) {
 		LogAllQueries:      viper.GetBool("logging.log_all_queries"),
 	}
 
+	// Worker
+	config.Worker = WorkerConfig{
+		MetricsPort:      viper.GetInt("worker.metrics_port"),
+		BackupVerifyDSN:  viper.GetString("worker.backup_verify_dsn"),
+		RetryBackoffBase: viper.GetDuration("worker.retry_backoff_base"),
+		BackupSchedule:   viper.GetString("worker.backup_schedule"),
+		BackupKeep:       viper.GetInt("worker.backup_keep"),
+	}
+
+	// Rate limit
+	config.RateLimit = RateLimitConfig{
+		Tiers:   make(map[string]int),
+		Roles:   viper.GetStringMapString("rate_limit.roles"),
+		APIKeys: make(map[string]string),
+	}
+	for name := range viper.GetStringMap("rate_limit.tiers") {
+		config.RateLimit.Tiers[name] = viper.GetInt("rate_limit.tiers." + name)
+	}
+	// API keys are a list rather than a map since viper lowercases map keys
+	var apiKeys []struct {
+		Key  string `mapstructure:"key"`
+		Tier string `mapstructure:"tier"`
+	}
+	if err := viper.UnmarshalKey("rate_limit.api_keys", &apiKeys); err != nil {
+		return nil, fmt.Errorf("error reading rate limit API keys: %w", err)
+	}
+	for _, apiKey := range apiKeys {
+		config.RateLimit.APIKeys[apiKey.Key] = apiKey.Tier
+	}
+
+	// Notifications
+	config.Notifications = NotificationsConfig{
+		WebhookSecrets: viper.GetStringMapString("notifications.webhook_secrets"),
+		SMTP: SMTPConfig{
+			Host:     viper.GetString("notifications.smtp.host"),
+			Port:     viper.GetInt("notifications.smtp.port"),
+			Username: viper.GetString("notifications.smtp.username"),
+			Password: viper.GetString("notifications.smtp.password"),
+			From:     viper.GetString("notifications.smtp.from"),
+		},
+		Twilio: TwilioConfig{
+			AccountSID:   viper.GetString("notifications.twilio.account_sid"),
+			AuthToken:    viper.GetString("notifications.twilio.auth_token"),
+			SMSFrom:      viper.GetString("notifications.twilio.sms_from"),
+			WhatsAppFrom: viper.GetString("notifications.twilio.whatsapp_from"),
+		},
+	}
+
 	return &config, nil
 }
 
@@ -225,12 +327,22 @@ func setDefaults() {
 	// Storage defaults
 	viper.SetDefault("storage.type", "local")
 	viper.SetDefault("storage.bucket", "pawnshop")
+	viper.SetDefault("storage.export_dir", "./exports")
 
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "console")
 	viper.SetDefault("logging.slow_query_threshold", "1s")
 	viper.SetDefault("logging.log_all_queries", false)
+
+	// Worker defaults
+	viper.SetDefault("worker.metrics_port", 9091)
+	viper.SetDefault("worker.retry_backoff_base", "1m")
+	viper.SetDefault("worker.backup_schedule", "daily@02:00")
+	viper.SetDefault("worker.backup_keep", 7)
+
+	// Notification defaults
+	viper.SetDefault("notifications.smtp.port", 587)
 }
 
 // DSN returns the PostgreSQL connection string
@@ -278,6 +390,8 @@ func bindEnvVariables() {
 	// App
 	viper.BindEnv("app.environment", "APP_ENV")
 	viper.BindEnv("app.debug", "APP_DEBUG")
+	viper.BindEnv("app.verify_url", "APP_VERIFY_URL")
+	viper.BindEnv("app.verify_key", "APP_VERIFY_KEY")
 
 	// Storage
 	viper.BindEnv("storage.endpoint", "S3_ENDPOINT")
@@ -285,4 +399,23 @@ func bindEnvVariables() {
 	viper.BindEnv("storage.secret_key", "S3_SECRET_KEY")
 	viper.BindEnv("storage.bucket", "S3_BUCKET")
 	viper.BindEnv("storage.region", "S3_REGION")
+	viper.BindEnv("storage.public_url", "S3_PUBLIC_URL")
+
+	// Worker
+	viper.BindEnv("worker.metrics_port", "WORKER_METRICS_PORT")
+	viper.BindEnv("worker.backup_verify_dsn", "BACKUP_VERIFY_DSN")
+	viper.BindEnv("worker.retry_backoff_base", "NOTIFICATION_RETRY_BACKOFF_BASE")
+	viper.BindEnv("worker.backup_schedule", "BACKUP_SCHEDULE")
+	viper.BindEnv("worker.backup_keep", "BACKUP_KEEP")
+
+	// Notifications
+	viper.BindEnv("notifications.smtp.host", "SMTP_HOST")
+	viper.BindEnv("notifications.smtp.port", "SMTP_PORT")
+	viper.BindEnv("notifications.smtp.username", "SMTP_USERNAME")
+	viper.BindEnv("notifications.smtp.password", "SMTP_PASSWORD")
+	viper.BindEnv("notifications.smtp.from", "SMTP_FROM")
+	viper.BindEnv("notifications.twilio.account_sid", "TWILIO_ACCOUNT_SID")
+	viper.BindEnv("notifications.twilio.auth_token", "TWILIO_AUTH_TOKEN")
+	viper.BindEnv("notifications.twilio.sms_from", "TWILIO_SMS_FROM")
+	viper.BindEnv("notifications.twilio.whatsapp_from", "TWILIO_WHATSAPP_FROM")
 }
@@ -1,6 +1,11 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
 
 // Account types
 const (
@@ -69,6 +74,54 @@ type AccountingEntry struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// EntryBalanceTolerance is the rounding difference allowed between the debit
+// and credit sides of an entry
+const EntryBalanceTolerance = 0.005
+
+// ErrUnbalancedEntry is returned for entries whose debits and credits differ
+var ErrUnbalancedEntry = errors.New("accounting entry is not balanced")
+
+// LineTotals sums the debit and credit lines of the entry
+func (e *AccountingEntry) LineTotals() (debit, credit float64) {
+	for _, line := range e.Lines {
+		switch line.EntryType {
+		case EntryTypeDebit:
+			debit += line.Amount
+		case EntryTypeCredit:
+			credit += line.Amount
+		}
+	}
+	return debit, credit
+}
+
+// Validate checks the entry is a balanced double entry: its debit lines add up
+// to its credit lines, and both to the header totals
+func (e *AccountingEntry) Validate() error {
+	if len(e.Lines) == 0 {
+		return fmt.Errorf("%w: entry has no lines", ErrUnbalancedEntry)
+	}
+	for i, line := range e.Lines {
+		if line.EntryType != EntryTypeDebit && line.EntryType != EntryTypeCredit {
+			return fmt.Errorf("line %d has invalid entry type %q", i+1, line.EntryType)
+		}
+		if line.Amount <= 0 {
+			return fmt.Errorf("line %d must have a positive amount", i+1)
+		}
+	}
+
+	debit, credit := e.LineTotals()
+	if math.Abs(debit-credit) > EntryBalanceTolerance {
+		return fmt.Errorf("%w: debits %.2f, credits %.2f", ErrUnbalancedEntry, debit, credit)
+	}
+	if math.Abs(debit-e.TotalDebit) > EntryBalanceTolerance {
+		return fmt.Errorf("%w: debit lines add up to %.2f, total debit is %.2f", ErrUnbalancedEntry, debit, e.TotalDebit)
+	}
+	if math.Abs(credit-e.TotalCredit) > EntryBalanceTolerance {
+		return fmt.Errorf("%w: credit lines add up to %.2f, total credit is %.2f", ErrUnbalancedEntry, credit, e.TotalCredit)
+	}
+	return nil
+}
+
 // AccountingEntryLine represents a single debit or credit line
 type AccountingEntryLine struct {
 	ID          int64    `json:"id"`
@@ -81,6 +134,43 @@ type AccountingEntryLine struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// AccountTypes lists the account types in the order financial statements present them
+var AccountTypes = []string{
+	AccountTypeAsset,
+	AccountTypeLiability,
+	AccountTypeEquity,
+	AccountTypeIncome,
+	AccountTypeExpense,
+}
+
+// IsDebitNormal reports whether accounts of the type grow with debits
+func IsDebitNormal(accountType string) bool {
+	return accountType == AccountTypeAsset || accountType == AccountTypeExpense
+}
+
+// TrialBalanceLine is an account's posted debits and credits up to a date.
+// Balance is the net of both on the account's normal side, so it is negative
+// when the account is overdrawn.
+type TrialBalanceLine struct {
+	AccountID   int64   `json:"account_id"`
+	AccountCode string  `json:"account_code"`
+	AccountName string  `json:"account_name"`
+	AccountType string  `json:"account_type"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Balance     float64 `json:"balance"`
+}
+
+// TrialBalance is the trial balance of a branch, or of every branch, as of a date
+type TrialBalance struct {
+	BranchID    *int64              `json:"branch_id,omitempty"`
+	AsOf        time.Time           `json:"as_of"`
+	Lines       []*TrialBalanceLine `json:"lines"` // Grouped by account type, then by code
+	TotalDebit  float64             `json:"total_debit"`
+	TotalCredit float64             `json:"total_credit"`
+	Balanced    bool                `json:"balanced"`
+}
+
 // DailyBalance represents daily financial summary for a branch
 type DailyBalance struct {
 	ID          int64     `json:"id"`
@@ -164,9 +254,146 @@ type Expense struct {
 	CreatedBy *int64    `json:"created_by,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// CategorySuggestion is the category suggested for an expense created
+	// without one; it is not stored until the user confirms it
+	CategorySuggestion *ExpenseCategorySuggestion `json:"category_suggestion,omitempty"`
+}
+
+// ExpenseCategorySuggestion is an advisory category for an expense
+type ExpenseCategorySuggestion struct {
+	CategoryID   int64  `json:"category_id"`
+	CategoryCode string `json:"category_code"`
+	CategoryName string `json:"category_name"`
+	Keyword      string `json:"keyword"`
 }
 
 // IsApproved checks if expense is approved
 func (e *Expense) IsApproved() bool {
 	return e.ApprovedBy != nil
 }
+
+// Accounting period statuses
+const (
+	AccountingPeriodOpen   = "open"
+	AccountingPeriodClosed = "closed"
+)
+
+// AccountingReferencePeriod is the reference type of entries generated when closing a period
+const AccountingReferencePeriod = "accounting_period"
+
+// AccountingPeriod is a calendar month of a branch's books. Closing it books the
+// period-end accruals, if requested, and marks it as closed.
+type AccountingPeriod struct {
+	ID       int64  `json:"id"`
+	BranchID int64  `json:"branch_id"`
+	Year     int    `json:"year"`
+	Month    int    `json:"month"`
+	Status   string `json:"status"`
+
+	// Accrued-but-unpaid balances booked at period end
+	AccruedInterest  float64    `json:"accrued_interest"`
+	AccruedLateFees  float64    `json:"accrued_late_fees"`
+	AccrualEntryID   *int64     `json:"accrual_entry_id,omitempty"`
+	AccrualsPostedAt *time.Time `json:"accruals_posted_at,omitempty"`
+
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+	ClosedBy *int64     `json:"closed_by,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// InventoryCheck is the inventory reconciliation run when closing the period
+	InventoryCheck *InventoryReconciliation `json:"inventory_check,omitempty"`
+}
+
+// TableName returns the database table name
+func (AccountingPeriod) TableName() string {
+	return "accounting_periods"
+}
+
+// ParseAccountingPeriod parses a period written as YYYY-MM
+func ParseAccountingPeriod(period string) (year, month int, err error) {
+	t, err := time.Parse("2006-01", period)
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.Year(), int(t.Month()), nil
+}
+
+// Label returns the period written as YYYY-MM
+func (p *AccountingPeriod) Label() string {
+	return p.StartDate().Format("2006-01")
+}
+
+// StartDate returns the first day of the period
+func (p *AccountingPeriod) StartDate() Date {
+	return NewDate(p.Year, time.Month(p.Month), 1)
+}
+
+// EndDate returns the last day of the period
+func (p *AccountingPeriod) EndDate() Date {
+	return Date{p.StartDate().AddDate(0, 1, -1)}
+}
+
+// Previous returns the year and month of the period before this one
+func (p *AccountingPeriod) Previous() (year, month int) {
+	prev := p.StartDate().AddDate(0, -1, 0)
+	return prev.Year(), int(prev.Month())
+}
+
+// IsClosed checks if the period has been closed
+func (p *AccountingPeriod) IsClosed() bool {
+	return p.Status == AccountingPeriodClosed
+}
+
+// HasAccruals checks if the period-end accruals were already booked
+func (p *AccountingPeriod) HasAccruals() bool {
+	return p.AccrualsPostedAt != nil
+}
+
+// Inventory valuation bases
+const (
+	// InventoryValuationCost values items at their acquisition price, or their appraisal when it is unknown
+	InventoryValuationCost = "cost"
+	// InventoryValuationAppraised values items at their appraisal
+	InventoryValuationAppraised = "appraised"
+)
+
+// InventoryItemValue is an item a branch held on a date, with the figures it can be valued at
+type InventoryItemValue struct {
+	ItemID           int64      `json:"item_id"`
+	SKU              string     `json:"sku"`
+	Name             string     `json:"name"`
+	Status           ItemStatus `json:"status"`
+	AppraisedValue   float64    `json:"appraised_value"`
+	AcquisitionPrice *float64   `json:"acquisition_price,omitempty"`
+}
+
+// InventoryReconciliationItem is an on-hand item counted in a reconciliation
+type InventoryReconciliationItem struct {
+	ItemID int64      `json:"item_id"`
+	SKU    string     `json:"sku"`
+	Name   string     `json:"name"`
+	Status ItemStatus `json:"status"`
+	Value  float64    `json:"value"`
+	// ValuedAtAppraisal marks cost-basis items without an acquisition price,
+	// the usual source of drift
+	ValuedAtAppraisal bool `json:"valued_at_appraisal,omitempty"`
+}
+
+// InventoryReconciliation compares a branch's inventory account balance to the
+// value of the items it held on a date
+type InventoryReconciliation struct {
+	BranchID       int64                          `json:"branch_id"`
+	AsOf           time.Time                      `json:"as_of"`
+	AccountCode    string                         `json:"account_code"`
+	ValuationBasis string                         `json:"valuation_basis"`
+	AccountBalance float64                        `json:"account_balance"`
+	ItemsValue     float64                        `json:"items_value"`
+	Variance       float64                        `json:"variance"` // Account balance minus items value
+	Tolerance      float64                        `json:"tolerance"`
+	Reconciled     bool                           `json:"reconciled"`
+	ItemCount      int                            `json:"item_count"`
+	Items          []*InventoryReconciliationItem `json:"items"` // Largest value first
+}
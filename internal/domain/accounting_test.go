@@ -46,3 +46,62 @@ func TestExpense_IsApproved_False(t *testing.T) {
 	e := &Expense{ApprovedBy: nil}
 	assert.False(t, e.IsApproved())
 }
+
+func TestAccountingPeriod_Dates(t *testing.T) {
+	p := &AccountingPeriod{Year: 2024, Month: 2}
+	assert.Equal(t, "2024-02", p.Label())
+	assert.Equal(t, "2024-02-01", p.StartDate().String())
+	assert.Equal(t, "2024-02-29", p.EndDate().String())
+
+	year, month := (&AccountingPeriod{Year: 2024, Month: 1}).Previous()
+	assert.Equal(t, 2023, year)
+	assert.Equal(t, 12, month)
+}
+
+func TestParseAccountingPeriod(t *testing.T) {
+	year, month, err := ParseAccountingPeriod("2026-03")
+	assert.NoError(t, err)
+	assert.Equal(t, 2026, year)
+	assert.Equal(t, 3, month)
+
+	_, _, err = ParseAccountingPeriod("2026-13")
+	assert.Error(t, err)
+}
+
+func TestAccountingEntry_Validate_Balanced(t *testing.T) {
+	entry := &AccountingEntry{
+		TotalDebit:  150.10,
+		TotalCredit: 150.10,
+		Lines: []*AccountingEntryLine{
+			{AccountID: 1, EntryType: EntryTypeDebit, Amount: 150.10},
+			{AccountID: 2, EntryType: EntryTypeCredit, Amount: 100.05},
+			{AccountID: 3, EntryType: EntryTypeCredit, Amount: 50.05},
+		},
+	}
+	assert.NoError(t, entry.Validate())
+}
+
+func TestAccountingEntry_Validate_Unbalanced(t *testing.T) {
+	lines := func() []*AccountingEntryLine {
+		return []*AccountingEntryLine{
+			{AccountID: 1, EntryType: EntryTypeDebit, Amount: 100},
+			{AccountID: 2, EntryType: EntryTypeCredit, Amount: 90},
+		}
+	}
+
+	entry := &AccountingEntry{TotalDebit: 100, TotalCredit: 90, Lines: lines()}
+	assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+
+	// Balanced lines that disagree with the header totals
+	entry = &AccountingEntry{TotalDebit: 100, TotalCredit: 100, Lines: lines()}
+	entry.Lines[1].Amount = 100
+	entry.TotalCredit = 120
+	assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+
+	entry = &AccountingEntry{}
+	assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+
+	entry = &AccountingEntry{TotalDebit: 100, TotalCredit: 100, Lines: lines()}
+	entry.Lines[1].EntryType = "transfer"
+	assert.Error(t, entry.Validate())
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Backup verification status
+const (
+	BackupVerificationPassed = "passed"
+	BackupVerificationFailed = "failed"
+)
+
+// BackupVerification records a test restore of a backup into the scratch
+// database and the row counts found there
+type BackupVerification struct {
+	ID             int64            `json:"id"`
+	BackupFilename string           `json:"backup_filename"`
+	Status         string           `json:"status"` // passed, failed
+	RowCounts      map[string]int64 `json:"row_counts"`
+	ErrorMessage   *string          `json:"error_message,omitempty"`
+	StartedAt      time.Time        `json:"started_at"`
+	FinishedAt     time.Time        `json:"finished_at"`
+	DurationMs     int64            `json:"duration_ms"`
+}
+
+// TableName returns the database table name
+func (BackupVerification) TableName() string {
+	return "backup_verifications"
+}
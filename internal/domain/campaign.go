@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+)
+
+// Campaign is a date-bounded promotion that waives or discounts the interest
+// and late fees of loans created while it runs, such as "no interest first week"
+type Campaign struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Code        string  `json:"code"`
+	Description *string `json:"description,omitempty"`
+
+	// Window, both days inclusive
+	StartDate Date `json:"start_date"`
+	EndDate   Date `json:"end_date"`
+
+	// Scope: nil branch applies to every branch; flagged campaigns only apply
+	// to customers explicitly added to them
+	BranchID             *int64 `json:"branch_id,omitempty"`
+	FlaggedCustomersOnly bool   `json:"flagged_customers_only"`
+
+	// Discounts in percent, 100 waives the charge entirely
+	InterestDiscountPercent float64 `json:"interest_discount_percent"`
+	LateFeeDiscountPercent  float64 `json:"late_fee_discount_percent"`
+
+	IsActive bool `json:"is_active"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+// IsRunningOn checks if the campaign is active on the given day
+func (c *Campaign) IsRunningOn(day Date) bool {
+	return c.IsActive && !day.Before(c.StartDate.Time) && !day.After(c.EndDate.Time)
+}
+
+// AppliesToBranch checks if the campaign covers loans of the given branch
+func (c *Campaign) AppliesToBranch(branchID int64) bool {
+	return c.BranchID == nil || *c.BranchID == branchID
+}
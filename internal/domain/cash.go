@@ -20,6 +20,10 @@ const (
 	CashMovementTypeExpense CashMovementType = "expense"
 )
 
+// DefaultCashCurrency is the currency of sessions and movements recorded
+// before cash sessions tracked currencies
+const DefaultCashCurrency = "GTQ"
+
 // CashRegister represents a physical cash register
 type CashRegister struct {
 	ID          int64   `json:"id"`
@@ -49,12 +53,20 @@ type CashSession struct {
 	CashRegisterID   int64 `json:"cash_register_id"`
 	UserID           int64 `json:"user_id"`
 
-	// Amounts
+	// Amounts, in the session's main currency
+	Currency       string   `json:"currency"`
 	OpeningAmount  float64  `json:"opening_amount"`
 	ClosingAmount  *float64 `json:"closing_amount,omitempty"`
 	ExpectedAmount *float64 `json:"expected_amount,omitempty"`
 	Difference     *float64 `json:"difference,omitempty"`
 
+	// Currencies are the other currencies held in the drawer, each counted
+	// and reconciled on its own
+	Currencies []*CashSessionCurrency `json:"currencies,omitempty"`
+
+	// Denominations are the bills and coins counted when the session closed
+	Denominations []*CashDenominationCount `json:"denominations,omitempty"`
+
 	// Status
 	Status CashSessionStatus `json:"status"`
 
@@ -68,6 +80,10 @@ type CashSession struct {
 
 	// Audit
 	ClosedBy *int64 `json:"closed_by,omitempty"`
+	// AutoClosed marks a session closed by the system after staying open too
+	// long; its closing amount is the expected one, not a counted one
+	AutoClosed        bool       `json:"auto_closed"`
+	OverdueNotifiedAt *time.Time `json:"overdue_notified_at,omitempty"`
 
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -90,6 +106,45 @@ func (cs *CashSession) IsOpen() bool {
 	return cs.Status == CashSessionStatusOpen
 }
 
+// HoldsCurrency reports whether the session's drawer holds a currency
+func (cs *CashSession) HoldsCurrency(currency string) bool {
+	return currency == cs.Currency || cs.OtherCurrency(currency) != nil
+}
+
+// OtherCurrency returns the amounts of one of the session's other currencies,
+// nil when the drawer does not hold it
+func (cs *CashSession) OtherCurrency(currency string) *CashSessionCurrency {
+	for _, amounts := range cs.Currencies {
+		if amounts.Currency == currency {
+			return amounts
+		}
+	}
+	return nil
+}
+
+// CashSessionCurrency holds the amounts of a currency other than the main one
+// in a cash session
+type CashSessionCurrency struct {
+	Currency       string   `json:"currency"`
+	OpeningAmount  float64  `json:"opening_amount"`
+	ClosingAmount  *float64 `json:"closing_amount,omitempty"`
+	ExpectedAmount *float64 `json:"expected_amount,omitempty"`
+	Difference     *float64 `json:"difference,omitempty"`
+}
+
+// CashDenominationCount is how many bills or coins of one denomination were
+// counted in a currency when closing a session
+type CashDenominationCount struct {
+	Currency     string  `json:"currency"`
+	Denomination float64 `json:"denomination"`
+	Count        int     `json:"count"`
+}
+
+// Total returns the value of the counted bills or coins
+func (d *CashDenominationCount) Total() float64 {
+	return d.Denomination * float64(d.Count)
+}
+
 // CashMovement represents a cash movement in a session
 type CashMovement struct {
 	ID        int64 `json:"id"`
@@ -99,6 +154,7 @@ type CashMovement struct {
 	// Movement details
 	MovementType  CashMovementType `json:"movement_type"`
 	Amount        float64          `json:"amount"`
+	Currency      string           `json:"currency"`
 	PaymentMethod PaymentMethod    `json:"payment_method"`
 
 	// Reference to related entity
@@ -13,12 +13,19 @@ type Category struct {
 	ParentID    *int64  `json:"parent_id,omitempty"`
 	Icon        *string `json:"icon,omitempty"`
 
-	// Loan settings
+	// Loan settings; a zero interest rate or nil late fee rate uses the global
+	// default for loans that don't set their own rates
 	DefaultInterestRate float64  `json:"default_interest_rate"`
+	DefaultLateFeeRate  *float64 `json:"default_late_fee_rate,omitempty"`
 	MinLoanAmount       *float64 `json:"min_loan_amount,omitempty"`
 	MaxLoanAmount       *float64 `json:"max_loan_amount,omitempty"`
+	MinLoanTermDays     *int     `json:"min_loan_term_days,omitempty"`
+	MaxLoanTermDays     *int     `json:"max_loan_term_days,omitempty"`
 	LoanToValueRatio    float64  `json:"loan_to_value_ratio"`
 
+	// Sale settings; nil uses the branch default warranty period
+	WarrantyDays *int `json:"warranty_days,omitempty"`
+
 	// Display
 	SortOrder int  `json:"sort_order"`
 	IsActive  bool `json:"is_active"`
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"time"
+)
+
+// CollectionStatus represents where a collection case stands in the follow-up
+type CollectionStatus string
+
+const (
+	CollectionStatusInProgress    CollectionStatus = "in_progress"
+	CollectionStatusPromiseToPay  CollectionStatus = "promise_to_pay"
+	CollectionStatusUncollectible CollectionStatus = "uncollectible"
+	// CollectionStatusResolved is set by the queue job once the loan is no
+	// longer overdue, taking the case out of the queue
+	CollectionStatusResolved CollectionStatus = "resolved"
+)
+
+// IsValid checks if the status is one staff can set on a case
+func (s CollectionStatus) IsValid() bool {
+	switch s {
+	case CollectionStatusInProgress, CollectionStatusPromiseToPay, CollectionStatusUncollectible:
+		return true
+	}
+	return false
+}
+
+// CollectionCase is an overdue loan placed in the collections queue. A loan has
+// at most one open case; a case stays in the queue until it is resolved.
+type CollectionCase struct {
+	ID         int64            `json:"id"`
+	LoanID     int64            `json:"loan_id"`
+	LoanNumber string           `json:"loan_number"`
+	CustomerID int64            `json:"customer_id"`
+	BranchID   int64            `json:"branch_id"`
+	Status     CollectionStatus `json:"status"`
+	AssignedTo *int64           `json:"assigned_to,omitempty"`
+
+	// Refreshed by the queue job every run
+	DaysOverdue int     `json:"days_overdue"`
+	AmountDue   float64 `json:"amount_due"`
+
+	// Set while the case is a promise to pay
+	PromiseDate   *Date    `json:"promise_date,omitempty"`
+	PromiseAmount *float64 `json:"promise_amount,omitempty"`
+
+	Notes         *string    `json:"notes,omitempty"`
+	LastContactAt *time.Time `json:"last_contact_at,omitempty"`
+	EnteredAt     time.Time  `json:"entered_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relations
+	Contacts []*CollectionContact `json:"contacts,omitempty"`
+}
+
+// TableName returns the database table name
+func (CollectionCase) TableName() string {
+	return "collection_cases"
+}
+
+// IsOpen checks if the case is still in the collections queue
+func (c *CollectionCase) IsOpen() bool {
+	return c.ResolvedAt == nil
+}
+
+// CollectionAgeBucket returns the overdue age range a number of days falls in
+func CollectionAgeBucket(daysOverdue int) string {
+	switch {
+	case daysOverdue <= 30:
+		return "1-30"
+	case daysOverdue <= 60:
+		return "31-60"
+	case daysOverdue <= 90:
+		return "61-90"
+	default:
+		return "90+"
+	}
+}
+
+// CollectionContactMethod is how the customer was contacted
+type CollectionContactMethod string
+
+const (
+	CollectionContactPhone    CollectionContactMethod = "phone"
+	CollectionContactSMS      CollectionContactMethod = "sms"
+	CollectionContactWhatsApp CollectionContactMethod = "whatsapp"
+	CollectionContactEmail    CollectionContactMethod = "email"
+	CollectionContactVisit    CollectionContactMethod = "visit"
+)
+
+// IsValid checks if the contact method is supported
+func (m CollectionContactMethod) IsValid() bool {
+	switch m {
+	case CollectionContactPhone, CollectionContactSMS, CollectionContactWhatsApp, CollectionContactEmail, CollectionContactVisit:
+		return true
+	}
+	return false
+}
+
+// CollectionContact is a contact attempt logged on a collection case
+type CollectionContact struct {
+	ID          int64                   `json:"id"`
+	CaseID      int64                   `json:"case_id"`
+	Method      CollectionContactMethod `json:"method"`
+	Reached     bool                    `json:"reached"`
+	Notes       *string                 `json:"notes,omitempty"`
+	ContactedBy int64                   `json:"contacted_by"`
+	ContactedAt time.Time               `json:"contacted_at"`
+}
+
+// TableName returns the database table name
+func (CollectionContact) TableName() string {
+	return "collection_contacts"
+}
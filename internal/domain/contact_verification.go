@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// ContactVerification is a one-time code sent to a customer's phone to confirm
+// the number can be reached
+type ContactVerification struct {
+	ID         int64      `json:"id"`
+	CustomerID int64      `json:"customer_id"`
+	Phone      string     `json:"phone"`
+	Channel    string     `json:"channel"` // sms, whatsapp
+	CodeHash   string     `json:"-"`       // Never expose hash
+	Attempts   int        `json:"attempts"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsExpired checks if the code can no longer be confirmed
+func (v *ContactVerification) IsExpired(now time.Time) bool {
+	return !now.Before(v.ExpiresAt)
+}
+
+// IsVerified checks if the code has been confirmed
+func (v *ContactVerification) IsVerified() bool {
+	return v.VerifiedAt != nil
+}
+
+// CanVerify checks if the code is still open for confirmation
+func (v *ContactVerification) CanVerify(now time.Time, maxAttempts int) bool {
+	return !v.IsVerified() && !v.IsExpired(now) && v.Attempts < maxAttempts
+}
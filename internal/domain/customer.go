@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
@@ -26,6 +27,10 @@ type Customer struct {
 	State          string `json:"state,omitempty"`
 	PostalCode     string `json:"postal_code,omitempty"`
 
+	// Contact verification
+	PhoneVerifiedAt      *time.Time `json:"phone_verified_at,omitempty"`
+	PhoneVerifiedChannel string     `json:"phone_verified_channel,omitempty"` // sms, whatsapp
+
 	// Emergency contact
 	EmergencyContactName     string `json:"emergency_contact_name,omitempty"`
 	EmergencyContactPhone    string `json:"emergency_contact_phone,omitempty"`
@@ -104,6 +109,32 @@ func (c *Customer) CanTakeLoan() bool {
 	return c.IsActive && !c.IsBlocked && c.IsAdult()
 }
 
+// IsPhoneVerified checks if the customer confirmed a code sent to their phone
+func (c *Customer) IsPhoneVerified() bool {
+	return c.PhoneVerifiedAt != nil
+}
+
+// ReminderChannel returns the channel reminders should be sent on: the channel
+// the phone was verified through, or SMS when it has not been verified
+func (c *Customer) ReminderChannel() string {
+	if c.IsPhoneVerified() && c.PhoneVerifiedChannel != "" {
+		return c.PhoneVerifiedChannel
+	}
+	return NotificationChannelSMS
+}
+
+// NormalizePhone strips everything but digits from a phone number so numbers
+// typed with spaces, dashes or a country prefix can be compared
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 // Identity type constants
 const (
 	IdentityTypeDPI      = "dpi"
@@ -150,3 +150,20 @@ func TestGetLoyaltyDiscount_Platinum(t *testing.T) {
 func TestGetLoyaltyDiscount_Unknown(t *testing.T) {
 	assert.Equal(t, 0.0, GetLoyaltyDiscount("unknown"))
 }
+
+func TestNormalizePhone(t *testing.T) {
+	assert.Equal(t, "50255551234", NormalizePhone("+502 5555-1234"))
+	assert.Equal(t, "55551234", NormalizePhone("(5555) 12.34"))
+	assert.Equal(t, "", NormalizePhone("n/a"))
+}
+
+func TestCustomer_ReminderChannel(t *testing.T) {
+	c := &Customer{Phone: "5555-1234"}
+	assert.Equal(t, NotificationChannelSMS, c.ReminderChannel())
+
+	verifiedAt := time.Now()
+	c.PhoneVerifiedAt = &verifiedAt
+	c.PhoneVerifiedChannel = NotificationChannelWhatsApp
+	assert.True(t, c.IsPhoneVerified())
+	assert.Equal(t, NotificationChannelWhatsApp, c.ReminderChannel())
+}
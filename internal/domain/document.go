@@ -13,6 +13,7 @@ const (
 	DocumentTypePaymentReceipt     DocumentType = "payment_receipt"
 	DocumentTypeSaleReceipt        DocumentType = "sale_receipt"
 	DocumentTypeConfiscationNotice DocumentType = "confiscation_notice"
+	DocumentTypeCustomerStatement  DocumentType = "customer_statement"
 	DocumentTypeOther              DocumentType = "other"
 )
 
@@ -66,6 +67,7 @@ type AuditLog struct {
 	NewValues interface{} `json:"new_values,omitempty"`
 	IPAddress string      `json:"ip_address,omitempty"`
 	UserAgent string      `json:"user_agent,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -99,6 +101,25 @@ func (Setting) TableName() string {
 	return "settings"
 }
 
+// Setting history actions
+const (
+	SettingChangeSet    = "set"
+	SettingChangeDelete = "delete"
+)
+
+// SettingHistory records a change to a setting: its value before and after,
+// who made it and when. A nil value means the setting did not exist.
+type SettingHistory struct {
+	ID         int64       `json:"id"`
+	SettingKey string      `json:"setting_key"`
+	BranchID   *int64      `json:"branch_id,omitempty"`
+	Action     string      `json:"action"`
+	OldValue   interface{} `json:"old_value"`
+	NewValue   interface{} `json:"new_value"`
+	ChangedBy  *int64      `json:"changed_by,omitempty"`
+	ChangedAt  time.Time   `json:"changed_at"`
+}
+
 // RefreshToken represents a refresh token for auth
 type RefreshToken struct {
 	ID         int64      `json:"id"`
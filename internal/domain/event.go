@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+)
+
+// Event types emitted to the activity feed and to subscribed webhooks
+const (
+	EventCashSessionOpened = "cash_session_opened"
+	EventCashSessionClosed = "cash_session_closed"
+	// EventCashSessionAutoClosed is a session closed by the worker after it
+	// stayed open past the branch's auto-close threshold
+	EventCashSessionAutoClosed = "cash_session_auto_closed"
+)
+
+// Event is a business event recorded in the activity feed and delivered to
+// the webhooks subscribed to its type
+type Event struct {
+	ID         int64                  `json:"id"`
+	EventType  string                 `json:"event_type"`
+	BranchID   *int64                 `json:"branch_id,omitempty"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   int64                  `json:"entity_id"`
+	UserID     *int64                 `json:"user_id,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
+	CreatedAt  time.Time              `json:"created_at"`
+
+	// Relations
+	UserName   string `json:"user_name,omitempty"`
+	BranchName string `json:"branch_name,omitempty"`
+}
+
+// TableName returns the database table name
+func (Event) TableName() string {
+	return "events"
+}
+
+// Webhook is an external endpoint that receives events by HTTP POST. An empty
+// EventTypes list subscribes the webhook to every event.
+type Webhook struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"-"`
+	EventTypes []string `json:"event_types"`
+	IsActive   bool     `json:"is_active"`
+
+	// Audit
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes checks if the webhook receives events of the given type
+func (w *Webhook) Subscribes(eventType string) bool {
+	if !w.IsActive {
+		return false
+	}
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
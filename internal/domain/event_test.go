@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent_TableName(t *testing.T) {
+	assert.Equal(t, "events", Event{}.TableName())
+	assert.Equal(t, "webhooks", Webhook{}.TableName())
+}
+
+func TestWebhook_Subscribes(t *testing.T) {
+	all := &Webhook{IsActive: true}
+	assert.True(t, all.Subscribes(EventCashSessionOpened))
+
+	closes := &Webhook{IsActive: true, EventTypes: []string{EventCashSessionClosed}}
+	assert.True(t, closes.Subscribes(EventCashSessionClosed))
+	assert.False(t, closes.Subscribes(EventCashSessionOpened))
+
+	inactive := &Webhook{IsActive: false}
+	assert.False(t, inactive.Subscribes(EventCashSessionClosed))
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+)
+
+// ExportDataset is the data an export job writes
+type ExportDataset string
+
+const (
+	ExportDatasetItems    ExportDataset = "items"
+	ExportDatasetPayments ExportDataset = "payments"
+)
+
+// IsValid checks if the dataset can be exported
+func (d ExportDataset) IsValid() bool {
+	return d == ExportDatasetItems || d == ExportDatasetPayments
+}
+
+// ExportJobStatus represents the status of an export job
+type ExportJobStatus string
+
+const (
+	ExportJobPending   ExportJobStatus = "pending"
+	ExportJobRunning   ExportJobStatus = "running"
+	ExportJobCompleted ExportJobStatus = "completed"
+	ExportJobFailed    ExportJobStatus = "failed"
+)
+
+// ExportJob is a CSV export written in the background batch by batch. Cursor is
+// the ID of the last row committed to the file and BytesWritten its size at
+// that point, so an interrupted export resumes right after the last batch.
+type ExportJob struct {
+	ID       int64           `json:"id"`
+	Dataset  ExportDataset   `json:"dataset"`
+	BranchID int64           `json:"branch_id"` // 0 exports every branch
+	Status   ExportJobStatus `json:"status"`
+
+	// Progress
+	Cursor       int64 `json:"-"`
+	RowsExported int   `json:"rows_exported"`
+	BytesWritten int64 `json:"bytes_written"`
+	Attempts     int   `json:"attempts"`
+
+	FileName string  `json:"file_name"`
+	Error    *string `json:"error,omitempty"`
+
+	// Audit
+	CreatedBy   int64      `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName returns the database table name
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// IsFinished checks if the job will not write any more rows
+func (j *ExportJob) IsFinished() bool {
+	return j.Status == ExportJobCompleted || j.Status == ExportJobFailed
+}
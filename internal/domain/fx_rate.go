@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+)
+
+// FXRate is the exchange rate of a currency pair on a day:
+// one unit of FromCurrency is worth Rate units of ToCurrency
+type FXRate struct {
+	ID           int64     `json:"id"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	RateDate     Date      `json:"rate_date"`
+	Rate         float64   `json:"rate"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name
+func (FXRate) TableName() string {
+	return "fx_rates"
+}
+
+// FXRevaluationMode selects which day's rate converts a figure to the base currency
+type FXRevaluationMode string
+
+const (
+	// FXRevaluationTransactionDate converts each transaction at its own day's rate
+	FXRevaluationTransactionDate FXRevaluationMode = "transaction_date"
+	// FXRevaluationPeriodEnd converts the period totals at the last day's rate
+	FXRevaluationPeriodEnd FXRevaluationMode = "period_end"
+)
+
+// IsValid checks if the revaluation mode is supported
+func (m FXRevaluationMode) IsValid() bool {
+	return m == FXRevaluationTransactionDate || m == FXRevaluationPeriodEnd
+}
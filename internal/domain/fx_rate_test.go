@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFXRate_TableName(t *testing.T) {
+	assert.Equal(t, "fx_rates", FXRate{}.TableName())
+}
+
+func TestFXRevaluationMode_IsValid(t *testing.T) {
+	assert.True(t, FXRevaluationTransactionDate.IsValid())
+	assert.True(t, FXRevaluationPeriodEnd.IsValid())
+	assert.False(t, FXRevaluationMode("average").IsValid())
+}
@@ -31,6 +31,24 @@ const (
 	ItemConditionPoor      ItemCondition = "poor"
 )
 
+// ConditionGrade is a step of the item condition scale and its effect on value
+type ConditionGrade struct {
+	Code   string  `json:"code"`
+	Label  string  `json:"label"`
+	Factor float64 `json:"factor"` // Multiplier applied to the market value
+}
+
+// DefaultConditionScale returns the condition scale used when none is configured
+func DefaultConditionScale() []ConditionGrade {
+	return []ConditionGrade{
+		{Code: string(ItemConditionNew), Label: "Nuevo", Factor: 1.0},
+		{Code: string(ItemConditionExcellent), Label: "Excelente", Factor: 0.9},
+		{Code: string(ItemConditionGood), Label: "Bueno", Factor: 0.75},
+		{Code: string(ItemConditionFair), Label: "Regular", Factor: 0.6},
+		{Code: string(ItemConditionPoor), Label: "Malo", Factor: 0.4},
+	}
+}
+
 // Item represents a pawn item
 type Item struct {
 	ID         int64  `json:"id"`
@@ -115,6 +133,16 @@ func (i *Item) IsPendingDelivery() bool {
 	return i.Status == ItemStatusAvailable && i.AcquisitionType == AcquisitionTypePawn && i.DeliveredAt == nil
 }
 
+// IsOnPremises checks if the item should physically be in its branch, i.e. it
+// was not sold, lost, sent to another branch or returned to its owner
+func (i *Item) IsOnPremises() bool {
+	switch i.Status {
+	case ItemStatusSold, ItemStatusTransferred, ItemStatusInTransfer, ItemStatusLost:
+		return false
+	}
+	return !i.IsDelivered()
+}
+
 // Acquisition type constants
 const (
 	AcquisitionTypePawn         = "pawn"
@@ -142,3 +170,9 @@ type ItemHistory struct {
 func (ItemHistory) TableName() string {
 	return "item_history"
 }
+
+// ItemHistoryActionRepriced records a sale price recomputed for the item's new branch
+const ItemHistoryActionRepriced = "repriced"
+
+// ItemHistoryActionLoanRenewed records the item's loan renewed into a new loan
+const ItemHistoryActionLoanRenewed = "loan_renewed"
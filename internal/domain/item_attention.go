@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// Reasons an item needs staff attention, in order of priority
+const (
+	ItemAttentionTransferUnreceived  = "transfer_unreceived"
+	ItemAttentionConfiscatedUnlisted = "confiscated_unlisted"
+	ItemAttentionMissingPhotos       = "missing_photos"
+	ItemAttentionAgedForSale         = "aged_for_sale"
+)
+
+// ItemAttentionPriority ranks the attention reasons, lowest first
+var ItemAttentionPriority = map[string]int{
+	ItemAttentionTransferUnreceived:  1,
+	ItemAttentionConfiscatedUnlisted: 2,
+	ItemAttentionMissingPhotos:       3,
+	ItemAttentionAgedForSale:         4,
+}
+
+// ItemAttentionCandidate is an item that may need attention along with the
+// facts the attention rules look at
+type ItemAttentionCandidate struct {
+	ItemID         int64      `json:"item_id"`
+	BranchID       int64      `json:"branch_id"`
+	SKU            string     `json:"sku"`
+	Name           string     `json:"name"`
+	Status         ItemStatus `json:"status"`
+	AppraisedValue float64    `json:"appraised_value"`
+	PhotoCount     int        `json:"photo_count"`
+	StatusSince    time.Time  `json:"status_since"` // When the item last moved to its current status
+	TransferNumber *string    `json:"transfer_number,omitempty"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"` // When the in-transit transfer of the item left its branch
+}
+
+// ItemAttention is an entry of the queue of items needing attention
+type ItemAttention struct {
+	ItemID         int64      `json:"item_id"`
+	BranchID       int64      `json:"branch_id"`
+	SKU            string     `json:"sku"`
+	Name           string     `json:"name"`
+	Status         ItemStatus `json:"status"`
+	AppraisedValue float64    `json:"appraised_value"`
+	Reason         string     `json:"reason"`
+	Priority       int        `json:"priority"`
+	Detail         string     `json:"detail"`
+	Since          time.Time  `json:"since"`
+	DaysWaiting    int        `json:"days_waiting"`
+	// OtherReasons lists the lower priority reasons the item also matches
+	OtherReasons []string `json:"other_reasons,omitempty"`
+}
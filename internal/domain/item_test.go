@@ -2,6 +2,7 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,3 +53,16 @@ func TestItem_CanBeSold_OtherStatuses(t *testing.T) {
 func TestItemHistory_TableName(t *testing.T) {
 	assert.Equal(t, "item_history", ItemHistory{}.TableName())
 }
+
+func TestItem_IsOnPremises(t *testing.T) {
+	for _, status := range []ItemStatus{ItemStatusAvailable, ItemStatusPawned, ItemStatusCollateral, ItemStatusForSale, ItemStatusConfiscated, ItemStatusDamaged} {
+		assert.True(t, (&Item{Status: status}).IsOnPremises(), "expected true for status %s", status)
+	}
+	for _, status := range []ItemStatus{ItemStatusSold, ItemStatusTransferred, ItemStatusInTransfer, ItemStatusLost} {
+		assert.False(t, (&Item{Status: status}).IsOnPremises(), "expected false for status %s", status)
+	}
+
+	now := time.Now()
+	delivered := &Item{Status: ItemStatusAvailable, AcquisitionType: AcquisitionTypePawn, DeliveredAt: &now}
+	assert.False(t, delivered.IsOnPremises())
+}
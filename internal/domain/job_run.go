@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Job run status
+const (
+	JobRunStatusSuccess = "success"
+	JobRunStatusFailed  = "failed"
+)
+
+// JobStaleFactor is how many expected intervals may pass without a successful
+// run before a critical job is considered stale; one interval of slack absorbs
+// jitter and slow runs
+const JobStaleFactor = 2
+
+// JobRun records one execution of a scheduled worker job
+type JobRun struct {
+	ID             int64     `json:"id"`
+	JobName        string    `json:"job_name"`
+	Status         string    `json:"status"` // success, failed
+	ErrorMessage   *string   `json:"error_message,omitempty"`
+	ItemsProcessed int       `json:"items_processed"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	DurationMs     int64     `json:"duration_ms"`
+
+	// Schedule the job ran under, so readers outside the worker can judge staleness
+	IntervalSeconds int64 `json:"interval_seconds"`
+	Critical        bool  `json:"critical"`
+}
+
+// TableName returns the database table name
+func (JobRun) TableName() string {
+	return "job_runs"
+}
+
+// JobStatus summarizes the latest runs of a scheduled job
+type JobStatus struct {
+	JobName         string     `json:"job_name"`
+	Critical        bool       `json:"critical"`
+	IntervalSeconds int64      `json:"interval_seconds"`
+	FirstRunAt      time.Time  `json:"first_run_at"`
+	LastRun         *JobRun    `json:"last_run"`
+	LastSuccessAt   *time.Time `json:"last_success_at,omitempty"`
+	Stale           bool       `json:"stale"`
+}
+
+// LastSuccessAge returns how long ago the job last succeeded, counting from its
+// first run when it never succeeded
+func (s *JobStatus) LastSuccessAge(now time.Time) time.Duration {
+	if s.LastSuccessAt != nil {
+		return now.Sub(*s.LastSuccessAt)
+	}
+	return now.Sub(s.FirstRunAt)
+}
+
+// IsStaleAt checks if a critical job has gone too long without succeeding
+func (s *JobStatus) IsStaleAt(now time.Time) bool {
+	if !s.Critical || s.IntervalSeconds <= 0 {
+		return false
+	}
+	limit := time.Duration(s.IntervalSeconds*JobStaleFactor) * time.Second
+	return s.LastSuccessAge(now) > limit
+}
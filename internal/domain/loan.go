@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/json"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,11 @@ const (
 	LoanStatusDefaulted   LoanStatus = "defaulted"
 	LoanStatusRenewed     LoanStatus = "renewed"
 	LoanStatusConfiscated LoanStatus = "confiscated"
+	LoanStatusWrittenOff  LoanStatus = "written_off"
+
+	// LoanStatusPendingAuthorization holds a high-value loan, and its item, until
+	// a second user co-signs the disbursement; the cash has not been paid out yet
+	LoanStatusPendingAuthorization LoanStatus = "pending_authorization"
 )
 
 // PaymentPlanType represents the type of payment plan
@@ -42,6 +50,17 @@ type Loan struct {
 	TotalAmount        float64 `json:"total_amount"`
 	AmountPaid         float64 `json:"amount_paid"`
 
+	// InterestMethod is the convention the interest was computed with
+	InterestMethod InterestMethod `json:"interest_method"`
+
+	// DisbursementRounding is the adjustment applied when a cash disbursement is
+	// rounded to the configured denomination (rounded amount minus requested amount)
+	DisbursementRounding float64 `json:"disbursement_rounding"`
+
+	// Promotional campaign applied when the loan was created and the interest it waived
+	CampaignID               *int64  `json:"campaign_id,omitempty"`
+	CampaignInterestDiscount float64 `json:"campaign_interest_discount"`
+
 	// Late fees
 	LateFeeRate      float64 `json:"late_fee_rate"`
 	LateFeeAmount    float64 `json:"late_fee_amount"`    // Total late fees accrued (historical)
@@ -76,6 +95,20 @@ type Loan struct {
 	// Notes
 	Notes string `json:"notes,omitempty"`
 
+	// Free-form labels such as "vip" or "legal_hold"
+	Tags []string `json:"tags"`
+
+	// Stored contract generated at creation, kept as a record of the original terms
+	ContractDocumentID *int64 `json:"contract_document_id,omitempty"`
+	ContractURL        string `json:"contract_url,omitempty"`
+	ContractHash       string `json:"contract_hash,omitempty"`
+
+	// Two-person rule: the cash of a high-value loan is only disbursed once a
+	// second user co-signs it
+	AuthorizationRequired bool       `json:"authorization_required"`
+	AuthorizedBy          *int64     `json:"authorized_by,omitempty"`
+	AuthorizedAt          *time.Time `json:"authorized_at,omitempty"`
+
 	// Audit
 	CreatedBy int64  `json:"created_by,omitempty"`
 	UpdatedBy *int64 `json:"updated_by,omitempty"`
@@ -85,12 +118,35 @@ type Loan struct {
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 
+	// Amounts written in the branch currency and locale, only filled in when
+	// money formatting is enabled for API responses
+	Formatted map[string]string `json:"formatted,omitempty"`
+
 	// Relations
 	Branch   *Branch   `json:"branch,omitempty"`
 	Customer *Customer `json:"customer,omitempty"`
 	Item     *Item     `json:"item,omitempty"`
 }
 
+// AwaitingAuthorization checks if the loan's disbursement still needs its co-signer
+func (l *Loan) AwaitingAuthorization() bool {
+	return l.Status == LoanStatusPendingAuthorization
+}
+
+// LoanTagLegalHold is the default tag that keeps a loan's item from being
+// confiscated automatically while a legal dispute is open
+const LoanTagLegalHold = "legal_hold"
+
+// HasTag checks if the loan carries a tag, ignoring case
+func (l *Loan) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // TableName returns the database table name
 func (Loan) TableName() string {
 	return "loans"
@@ -135,6 +191,117 @@ func (l *Loan) CalculateDaysOverdue() int {
 	return int(time.Since(l.DueDate.Time).Hours() / 24)
 }
 
+// IsOpen checks if the loan still has an outstanding obligation that accrues late fees
+func (l *Loan) IsOpen() bool {
+	return l.Status == LoanStatusActive || l.Status == LoanStatusOverdue
+}
+
+// GracePeriodEnd returns the last day of the grace period after the due date
+func (l *Loan) GracePeriodEnd() Date {
+	return DateFromTime(l.DueDate.AddDate(0, 0, l.GracePeriodDays))
+}
+
+// DaysUntilGracePeriodEnd returns the days left from day until the grace period
+// ends and the item can be confiscated; it is negative once the grace period is over
+func (l *Loan) DaysUntilGracePeriodEnd(day Date) int {
+	return int(l.GracePeriodEnd().Sub(day.Time).Hours() / 24)
+}
+
+// DaysUntilDueAt returns the number of whole days from the given time until the due date
+func (l *Loan) DaysUntilDueAt(now time.Time) int {
+	days := int(l.DueDate.Sub(DateFromTime(now).Time).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// DaysPastDueAt returns the number of whole days an open loan is past its due date at the given time
+func (l *Loan) DaysPastDueAt(now time.Time) int {
+	if !l.IsOpen() {
+		return 0
+	}
+	days := int(DateFromTime(now).Sub(l.DueDate.Time).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// IsPastDueAt checks if an open loan is past its due date at the given time
+func (l *Loan) IsPastDueAt(now time.Time) bool {
+	return l.DaysPastDueAt(now) > 0
+}
+
+// IsInGracePeriodAt checks if an open loan is past due but still within its grace period at the given time
+func (l *Loan) IsInGracePeriodAt(now time.Time) bool {
+	if !l.IsPastDueAt(now) {
+		return false
+	}
+	return !DateFromTime(now).After(l.GracePeriodEnd().Time)
+}
+
+// AccruedInterestAt returns the interest earned on the loan by the given day but
+// not yet paid, as its interest method earns it over the loan term
+func (l *Loan) AccruedInterestAt(day Date) float64 {
+	termDays := l.LoanTermDays
+	if termDays <= 0 {
+		termDays = int(l.DueDate.Sub(l.StartDate.Time).Hours() / 24)
+	}
+	elapsed := int(day.Sub(l.StartDate.Time).Hours() / 24)
+	if termDays <= 0 || elapsed >= termDays {
+		elapsed = termDays
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+
+	earned := l.InterestAmount * InterestStrategyFor(l.InterestMethod).EarnedFraction(l.InterestRate, elapsed, termDays)
+	paid := l.InterestAmount - l.InterestRemaining
+	if earned <= paid {
+		return 0
+	}
+	return math.Round((earned-paid)*100) / 100
+}
+
+// ProjectedLateFeeAt returns the late fee owed at the given time, including
+// accrual not yet applied by the late fee job (daily rate * principal * days past due)
+func (l *Loan) ProjectedLateFeeAt(now time.Time) float64 {
+	days := l.DaysPastDueAt(now)
+	if days == 0 {
+		return l.LateFeeRemaining
+	}
+	accrued := l.LateFeeRate / 100 * l.LoanAmount * float64(days)
+	if accrued <= l.LateFeeAmount {
+		return l.LateFeeRemaining
+	}
+	return math.Round((l.LateFeeRemaining+accrued-l.LateFeeAmount)*100) / 100
+}
+
+// MarshalJSON adds derived, read-only fields computed as of now to the loan
+// so every client sees the same state. They are never persisted.
+func (l Loan) MarshalJSON() ([]byte, error) {
+	type loanFields Loan
+	now := time.Now()
+	return json.Marshal(struct {
+		loanFields
+		DaysUntilDue     int     `json:"days_until_due"`
+		DaysPastDue      int     `json:"days_past_due"`
+		IsPastDue        bool    `json:"is_past_due"`
+		GracePeriodEnd   Date    `json:"grace_period_end"`
+		IsInGracePeriod  bool    `json:"is_in_grace_period"`
+		ProjectedLateFee float64 `json:"projected_late_fee"`
+	}{
+		loanFields:       loanFields(l),
+		DaysUntilDue:     l.DaysUntilDueAt(now),
+		DaysPastDue:      l.DaysPastDueAt(now),
+		IsPastDue:        l.IsPastDueAt(now),
+		GracePeriodEnd:   l.GracePeriodEnd(),
+		IsInGracePeriod:  l.IsInGracePeriodAt(now),
+		ProjectedLateFee: l.ProjectedLateFeeAt(now),
+	})
+}
+
 // LoanInstallment represents an installment for a loan
 type LoanInstallment struct {
 	ID                int64     `json:"id"`
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// LoanBalanceSnapshot records what an open loan owed at the end of a day, so
+// reports for past dates read the figures as they were instead of recomputing
+// them from the loan as it is today
+type LoanBalanceSnapshot struct {
+	ID                 int64      `json:"id"`
+	LoanID             int64      `json:"loan_id"`
+	LoanNumber         string     `json:"loan_number"`
+	BranchID           int64      `json:"branch_id"`
+	SnapshotDate       Date       `json:"snapshot_date"`
+	Status             LoanStatus `json:"status"`
+	PrincipalRemaining float64    `json:"principal_remaining"`
+	InterestRemaining  float64    `json:"interest_remaining"`
+	AccruedInterest    float64    `json:"accrued_interest"`
+	LateFeeRemaining   float64    `json:"late_fee_remaining"`
+	Balance            float64    `json:"balance"`
+	DaysPastDue        int        `json:"days_past_due"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// NewLoanBalanceSnapshot takes the snapshot of a loan at the end of day. Late
+// fees include the accrual the late fee job has not applied yet.
+func NewLoanBalanceSnapshot(loan *Loan, day Date) *LoanBalanceSnapshot {
+	lateFees := loan.ProjectedLateFeeAt(day.Time)
+	return &LoanBalanceSnapshot{
+		LoanID:             loan.ID,
+		LoanNumber:         loan.LoanNumber,
+		BranchID:           loan.BranchID,
+		SnapshotDate:       day,
+		Status:             loan.Status,
+		PrincipalRemaining: loan.PrincipalRemaining,
+		InterestRemaining:  loan.InterestRemaining,
+		AccruedInterest:    loan.AccruedInterestAt(day),
+		LateFeeRemaining:   lateFees,
+		Balance:            math.Round((loan.PrincipalRemaining+loan.InterestRemaining+lateFees)*100) / 100,
+		DaysPastDue:        loan.DaysPastDueAt(day.Time),
+	}
+}
@@ -0,0 +1,137 @@
+package domain
+
+import "math"
+
+// InterestMethod names the convention a loan's interest is computed with
+type InterestMethod string
+
+const (
+	// InterestMethodFlat charges the rate once on the principal, whatever the
+	// length of the term. It is how interest has always been charged.
+	InterestMethodFlat InterestMethod = "flat"
+	// InterestMethodSimple charges the monthly rate on the principal for every
+	// day of the term, a month counting as 30 days
+	InterestMethodSimple InterestMethod = "simple"
+	// InterestMethodCompound charges the monthly rate spread over 30 days and
+	// compounded daily over the term
+	InterestMethodCompound InterestMethod = "compound"
+)
+
+// DefaultInterestMethod is used for loans and branches without a method
+const DefaultInterestMethod = InterestMethodFlat
+
+// DaysPerInterestMonth is the length of the month a monthly rate covers
+const DaysPerInterestMonth = 30
+
+// InterestMethods lists the supported interest methods
+var InterestMethods = []InterestMethod{InterestMethodFlat, InterestMethodSimple, InterestMethodCompound}
+
+// IsValid reports whether the method is a supported interest method
+func (m InterestMethod) IsValid() bool {
+	for _, method := range InterestMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// InterestStrategy works out the interest of a loan under one convention.
+// Rates are monthly percentages.
+type InterestStrategy interface {
+	// Interest is the interest on principal over a term of days
+	Interest(principal, monthlyRate float64, days int) float64
+	// EarnedFraction is the share of a term's interest earned after elapsed days
+	EarnedFraction(monthlyRate float64, elapsed, termDays int) float64
+}
+
+// InterestStrategyFor returns the strategy of an interest method. Loans saved
+// before methods existed have none and keep the flat method.
+func InterestStrategyFor(method InterestMethod) InterestStrategy {
+	switch method {
+	case InterestMethodSimple:
+		return SimpleInterest{}
+	case InterestMethodCompound:
+		return CompoundInterest{}
+	default:
+		return FlatInterest{}
+	}
+}
+
+// FlatInterest charges the rate once, earned evenly over the term
+type FlatInterest struct{}
+
+// Interest implements InterestStrategy
+func (FlatInterest) Interest(principal, monthlyRate float64, days int) float64 {
+	return principal * (monthlyRate / 100)
+}
+
+// EarnedFraction implements InterestStrategy
+func (FlatInterest) EarnedFraction(monthlyRate float64, elapsed, termDays int) float64 {
+	return straightLineFraction(elapsed, termDays)
+}
+
+// SimpleInterest charges the monthly rate pro rata for each day of the term
+type SimpleInterest struct{}
+
+// Interest implements InterestStrategy
+func (SimpleInterest) Interest(principal, monthlyRate float64, days int) float64 {
+	if days <= 0 {
+		return 0
+	}
+	return roundInterest(principal * monthlyRate / 100 * float64(days) / DaysPerInterestMonth)
+}
+
+// EarnedFraction implements InterestStrategy
+func (SimpleInterest) EarnedFraction(monthlyRate float64, elapsed, termDays int) float64 {
+	return straightLineFraction(elapsed, termDays)
+}
+
+// CompoundInterest compounds the daily share of the monthly rate
+type CompoundInterest struct{}
+
+// Interest implements InterestStrategy
+func (CompoundInterest) Interest(principal, monthlyRate float64, days int) float64 {
+	if days <= 0 {
+		return 0
+	}
+	return roundInterest(principal * compoundGrowth(monthlyRate, days))
+}
+
+// EarnedFraction implements InterestStrategy. Compounding earns less interest
+// early in the term than late, so the fraction trails the elapsed time.
+func (CompoundInterest) EarnedFraction(monthlyRate float64, elapsed, termDays int) float64 {
+	if elapsed >= termDays {
+		return 1
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	total := compoundGrowth(monthlyRate, termDays)
+	if total <= 0 {
+		return straightLineFraction(elapsed, termDays)
+	}
+	return compoundGrowth(monthlyRate, elapsed) / total
+}
+
+// compoundGrowth is the growth of one unit compounded daily over days
+func compoundGrowth(monthlyRate float64, days int) float64 {
+	dailyRate := monthlyRate / 100 / DaysPerInterestMonth
+	return math.Pow(1+dailyRate, float64(days)) - 1
+}
+
+// straightLineFraction is the share of a term that has elapsed
+func straightLineFraction(elapsed, termDays int) float64 {
+	if termDays <= 0 || elapsed >= termDays {
+		return 1
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(elapsed) / float64(termDays)
+}
+
+// roundInterest rounds an interest amount to cents
+func roundInterest(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
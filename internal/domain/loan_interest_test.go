@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterestStrategies_SamePrincipalAndTerm(t *testing.T) {
+	// Q1,000 at 10% a month
+	tests := []struct {
+		method InterestMethod
+		days   int
+		want   float64
+	}{
+		{InterestMethodFlat, 15, 100},
+		{InterestMethodFlat, 30, 100},
+		{InterestMethodFlat, 60, 100},
+		{InterestMethodSimple, 15, 50},
+		{InterestMethodSimple, 30, 100},
+		{InterestMethodSimple, 60, 200},
+		{InterestMethodCompound, 15, 51.18},
+		{InterestMethodCompound, 30, 104.99},
+		{InterestMethodCompound, 60, 221},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			got := InterestStrategyFor(tt.method).Interest(1000, 10, tt.days)
+			assert.InDelta(t, tt.want, got, 0.001, "%d days", tt.days)
+		})
+	}
+}
+
+func TestInterestStrategyFor_DefaultsToFlat(t *testing.T) {
+	assert.Equal(t, FlatInterest{}, InterestStrategyFor(""))
+	assert.Equal(t, FlatInterest{}, InterestStrategyFor("weekly"))
+	assert.False(t, InterestMethod("weekly").IsValid())
+	assert.True(t, InterestMethodCompound.IsValid())
+}
+
+func TestInterestStrategies_EarnedFraction(t *testing.T) {
+	for _, method := range InterestMethods {
+		strategy := InterestStrategyFor(method)
+		assert.Equal(t, 0.0, strategy.EarnedFraction(10, 0, 30), method)
+		assert.Equal(t, 1.0, strategy.EarnedFraction(10, 30, 30), method)
+		assert.Equal(t, 1.0, strategy.EarnedFraction(10, 45, 30), method)
+	}
+
+	// Compounding earns less of the term's interest early on
+	assert.InDelta(t, 1.0/3, SimpleInterest{}.EarnedFraction(10, 10, 30), 0.0001)
+	assert.Less(t, CompoundInterest{}.EarnedFraction(10, 10, 30), 1.0/3)
+}
+
+func TestLoan_AccruedInterestAt_Compound(t *testing.T) {
+	loan := &Loan{
+		InterestRate: 10, InterestMethod: InterestMethodCompound,
+		InterestAmount: 300, InterestRemaining: 300, LoanTermDays: 30,
+		StartDate: NewDate(2024, 3, 1), DueDate: NewDate(2024, 3, 31),
+	}
+
+	assert.Equal(t, 96.69, loan.AccruedInterestAt(NewDate(2024, 3, 11)))
+	assert.Equal(t, 300.0, loan.AccruedInterestAt(NewDate(2024, 3, 31)))
+}
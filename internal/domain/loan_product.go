@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// LoanProduct is a named set of preset loan terms, such as a 30-day gold loan
+type LoanProduct struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Code        string  `json:"code"`
+	Description *string `json:"description,omitempty"`
+
+	// Preset terms
+	InterestRate           float64         `json:"interest_rate"`
+	LoanTermDays           int             `json:"loan_term_days"`
+	GracePeriodDays        int             `json:"grace_period_days"`
+	LateFeeRate            float64         `json:"late_fee_rate"`
+	PaymentPlanType        PaymentPlanType `json:"payment_plan_type"`
+	NumberOfInstallments   int             `json:"number_of_installments"`
+	RequiresMinimumPayment bool            `json:"requires_minimum_payment"`
+
+	IsActive bool `json:"is_active"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name
+func (LoanProduct) TableName() string {
+	return "loan_products"
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -15,7 +16,7 @@ func TestLoan_RemainingBalance(t *testing.T) {
 	loan := &Loan{
 		PrincipalRemaining: 500.0,
 		InterestRemaining:  50.0,
-		LateFeeAmount:      10.0,
+		LateFeeRemaining:   10.0,
 	}
 	assert.Equal(t, 560.0, loan.RemainingBalance())
 }
@@ -28,7 +29,7 @@ func TestLoan_RemainingBalance_Zero(t *testing.T) {
 func TestLoan_IsOverdue_Active_PastDue(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusActive,
-		DueDate: time.Now().AddDate(0, 0, -5),
+		DueDate: Date{time.Now().AddDate(0, 0, -5)},
 	}
 	assert.True(t, loan.IsOverdue())
 }
@@ -36,7 +37,7 @@ func TestLoan_IsOverdue_Active_PastDue(t *testing.T) {
 func TestLoan_IsOverdue_Active_NotPastDue(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusActive,
-		DueDate: time.Now().AddDate(0, 0, 5),
+		DueDate: Date{time.Now().AddDate(0, 0, 5)},
 	}
 	assert.False(t, loan.IsOverdue())
 }
@@ -44,7 +45,7 @@ func TestLoan_IsOverdue_Active_NotPastDue(t *testing.T) {
 func TestLoan_IsOverdue_NotActive(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusPaid,
-		DueDate: time.Now().AddDate(0, 0, -5),
+		DueDate: Date{time.Now().AddDate(0, 0, -5)},
 	}
 	assert.False(t, loan.IsOverdue())
 }
@@ -52,7 +53,7 @@ func TestLoan_IsOverdue_NotActive(t *testing.T) {
 func TestLoan_IsOverdue_OverdueStatus(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusOverdue,
-		DueDate: time.Now().AddDate(0, 0, -5),
+		DueDate: Date{time.Now().AddDate(0, 0, -5)},
 	}
 	assert.False(t, loan.IsOverdue())
 }
@@ -60,7 +61,7 @@ func TestLoan_IsOverdue_OverdueStatus(t *testing.T) {
 func TestLoan_IsInGracePeriod_WithinGrace(t *testing.T) {
 	loan := &Loan{
 		Status:          LoanStatusActive,
-		DueDate:         time.Now().AddDate(0, 0, -5),
+		DueDate:         Date{time.Now().AddDate(0, 0, -5)},
 		GracePeriodDays: 15,
 	}
 	assert.True(t, loan.IsInGracePeriod())
@@ -69,7 +70,7 @@ func TestLoan_IsInGracePeriod_WithinGrace(t *testing.T) {
 func TestLoan_IsInGracePeriod_PastGrace(t *testing.T) {
 	loan := &Loan{
 		Status:          LoanStatusActive,
-		DueDate:         time.Now().AddDate(0, 0, -20),
+		DueDate:         Date{time.Now().AddDate(0, 0, -20)},
 		GracePeriodDays: 15,
 	}
 	assert.False(t, loan.IsInGracePeriod())
@@ -78,7 +79,7 @@ func TestLoan_IsInGracePeriod_PastGrace(t *testing.T) {
 func TestLoan_IsInGracePeriod_NotOverdue(t *testing.T) {
 	loan := &Loan{
 		Status:          LoanStatusActive,
-		DueDate:         time.Now().AddDate(0, 0, 5),
+		DueDate:         Date{time.Now().AddDate(0, 0, 5)},
 		GracePeriodDays: 15,
 	}
 	assert.False(t, loan.IsInGracePeriod())
@@ -86,7 +87,7 @@ func TestLoan_IsInGracePeriod_NotOverdue(t *testing.T) {
 
 func TestLoan_DaysUntilDue_Future(t *testing.T) {
 	loan := &Loan{
-		DueDate: time.Now().Add(72 * time.Hour),
+		DueDate: Date{time.Now().Add(72 * time.Hour)},
 	}
 	days := loan.DaysUntilDue()
 	assert.True(t, days >= 2 && days <= 3)
@@ -94,14 +95,14 @@ func TestLoan_DaysUntilDue_Future(t *testing.T) {
 
 func TestLoan_DaysUntilDue_Past(t *testing.T) {
 	loan := &Loan{
-		DueDate: time.Now().AddDate(0, 0, -5),
+		DueDate: Date{time.Now().AddDate(0, 0, -5)},
 	}
 	assert.Equal(t, 0, loan.DaysUntilDue())
 }
 
 func TestLoan_DaysUntilDue_Today(t *testing.T) {
 	loan := &Loan{
-		DueDate: time.Now().Add(1 * time.Hour),
+		DueDate: Date{time.Now().Add(1 * time.Hour)},
 	}
 	assert.Equal(t, 0, loan.DaysUntilDue())
 }
@@ -109,7 +110,7 @@ func TestLoan_DaysUntilDue_Today(t *testing.T) {
 func TestLoan_CalculateDaysOverdue_Overdue(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusActive,
-		DueDate: time.Now().Add(-72 * time.Hour),
+		DueDate: Date{time.Now().Add(-72 * time.Hour)},
 	}
 	days := loan.CalculateDaysOverdue()
 	assert.True(t, days >= 2 && days <= 3)
@@ -118,7 +119,7 @@ func TestLoan_CalculateDaysOverdue_Overdue(t *testing.T) {
 func TestLoan_CalculateDaysOverdue_NotOverdue(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusActive,
-		DueDate: time.Now().AddDate(0, 0, 5),
+		DueDate: Date{time.Now().AddDate(0, 0, 5)},
 	}
 	assert.Equal(t, 0, loan.CalculateDaysOverdue())
 }
@@ -126,11 +127,113 @@ func TestLoan_CalculateDaysOverdue_NotOverdue(t *testing.T) {
 func TestLoan_CalculateDaysOverdue_PaidLoan(t *testing.T) {
 	loan := &Loan{
 		Status:  LoanStatusPaid,
-		DueDate: time.Now().AddDate(0, 0, -5),
+		DueDate: Date{time.Now().AddDate(0, 0, -5)},
 	}
 	assert.Equal(t, 0, loan.CalculateDaysOverdue())
 }
 
+func TestLoan_DerivedFields_Active(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	loan := &Loan{
+		Status:          LoanStatusActive,
+		DueDate:         NewDate(2024, 3, 20),
+		GracePeriodDays: 5,
+		LoanAmount:      1000,
+		LateFeeRate:     1,
+	}
+
+	assert.Equal(t, 10, loan.DaysUntilDueAt(now))
+	assert.Equal(t, 0, loan.DaysPastDueAt(now))
+	assert.False(t, loan.IsPastDueAt(now))
+	assert.False(t, loan.IsInGracePeriodAt(now))
+	assert.Equal(t, NewDate(2024, 3, 25), loan.GracePeriodEnd())
+	assert.Equal(t, 0.0, loan.ProjectedLateFeeAt(now))
+}
+
+func TestLoan_DerivedFields_InGrace(t *testing.T) {
+	now := time.Date(2024, 3, 23, 9, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		Status:          LoanStatusOverdue,
+		DueDate:         NewDate(2024, 3, 20),
+		GracePeriodDays: 5,
+		LoanAmount:      1000,
+		LateFeeRate:     1,
+	}
+
+	assert.Equal(t, 0, loan.DaysUntilDueAt(now))
+	assert.Equal(t, 3, loan.DaysPastDueAt(now))
+	assert.True(t, loan.IsPastDueAt(now))
+	assert.True(t, loan.IsInGracePeriodAt(now))
+	assert.Equal(t, 2, loan.DaysUntilGracePeriodEnd(DateFromTime(now)))
+	assert.Equal(t, 30.0, loan.ProjectedLateFeeAt(now)) // 1% * 1000 * 3 days
+}
+
+func TestLoan_DerivedFields_GraceLastDay(t *testing.T) {
+	now := time.Date(2024, 3, 25, 23, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		Status:          LoanStatusOverdue,
+		DueDate:         NewDate(2024, 3, 20),
+		GracePeriodDays: 5,
+	}
+
+	assert.True(t, loan.IsInGracePeriodAt(now))
+	assert.False(t, loan.IsInGracePeriodAt(now.AddDate(0, 0, 1)))
+}
+
+func TestLoan_DerivedFields_OverduePastGrace(t *testing.T) {
+	now := time.Date(2024, 4, 4, 12, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		Status:           LoanStatusOverdue,
+		DueDate:          NewDate(2024, 3, 20),
+		GracePeriodDays:  5,
+		LoanAmount:       1000,
+		LateFeeRate:      1,
+		LateFeeAmount:    100, // Accrued by the job through day 10
+		LateFeeRemaining: 60,  // 40 already paid
+	}
+
+	assert.Equal(t, 15, loan.DaysPastDueAt(now))
+	assert.True(t, loan.IsPastDueAt(now))
+	assert.False(t, loan.IsInGracePeriodAt(now))
+	assert.Equal(t, 110.0, loan.ProjectedLateFeeAt(now)) // 60 remaining + 50 not yet accrued
+}
+
+func TestLoan_DerivedFields_PaidLoan(t *testing.T) {
+	now := time.Date(2024, 4, 4, 12, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		Status:      LoanStatusPaid,
+		DueDate:     NewDate(2024, 3, 20),
+		LoanAmount:  1000,
+		LateFeeRate: 1,
+	}
+
+	assert.Equal(t, 0, loan.DaysPastDueAt(now))
+	assert.False(t, loan.IsPastDueAt(now))
+	assert.False(t, loan.IsInGracePeriodAt(now))
+	assert.Equal(t, 0.0, loan.ProjectedLateFeeAt(now))
+}
+
+func TestLoan_MarshalJSON_IncludesDerivedFields(t *testing.T) {
+	loan := Loan{
+		ID:              1,
+		LoanNumber:      "LN-000001",
+		Status:          LoanStatusActive,
+		DueDate:         DateFromTime(time.Now().AddDate(0, 0, 10)),
+		GracePeriodDays: 5,
+	}
+
+	data, err := json.Marshal(loan)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "LN-000001", decoded["loan_number"])
+	assert.Equal(t, 10.0, decoded["days_until_due"])
+	assert.Equal(t, false, decoded["is_past_due"])
+	assert.Equal(t, loan.GracePeriodEnd().String(), decoded["grace_period_end"])
+	assert.Contains(t, decoded, "projected_late_fee")
+}
+
 func TestLoanInstallment_TableName(t *testing.T) {
 	assert.Equal(t, "loan_installments", LoanInstallment{}.TableName())
 }
@@ -158,3 +261,20 @@ func TestLoanInstallment_RemainingAmount_NoPaid(t *testing.T) {
 	}
 	assert.Equal(t, 500.0, li.RemainingAmount())
 }
+
+func TestLoan_AccruedInterestAt(t *testing.T) {
+	loan := &Loan{
+		InterestAmount: 300, InterestRemaining: 300, LoanTermDays: 30,
+		StartDate: NewDate(2024, 3, 1), DueDate: NewDate(2024, 3, 31),
+	}
+
+	assert.Equal(t, 0.0, loan.AccruedInterestAt(NewDate(2024, 3, 1)))
+	assert.Equal(t, 100.0, loan.AccruedInterestAt(NewDate(2024, 3, 11)))
+	assert.Equal(t, 300.0, loan.AccruedInterestAt(NewDate(2024, 5, 1))) // capped at the term
+
+	// Interest already paid is not accrued again
+	loan.InterestRemaining = 250
+	assert.Equal(t, 50.0, loan.AccruedInterestAt(NewDate(2024, 3, 11)))
+	loan.InterestRemaining = 0
+	assert.Equal(t, 0.0, loan.AccruedInterestAt(NewDate(2024, 3, 11)))
+}
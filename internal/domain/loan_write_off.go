@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// AccountingReferenceLoanWriteOff is the reference type of the loss entries
+// posted when a loan is written off
+const AccountingReferenceLoanWriteOff = "loan_write_off"
+
+// LoanWriteOff records an uncollectible loan taken off the books: the balance
+// that was lost, why, and who decided it
+type LoanWriteOff struct {
+	ID       int64 `json:"id"`
+	LoanID   int64 `json:"loan_id"`
+	BranchID int64 `json:"branch_id"`
+
+	// Balance written off, split as it was owed
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	LateFees  float64 `json:"late_fees"`
+	Amount    float64 `json:"amount"`
+
+	Reason       string    `json:"reason"`
+	EntryID      *int64    `json:"entry_id,omitempty"`
+	WrittenOffBy int64     `json:"written_off_by"`
+	WrittenOffAt time.Time `json:"written_off_at"`
+
+	// Relations
+	Loan *Loan `json:"loan,omitempty"`
+}
+
+// TableName returns the database table name
+func (LoanWriteOff) TableName() string {
+	return "loan_write_offs"
+}
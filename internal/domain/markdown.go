@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ItemHistoryActionMarkedDown is the item history action recorded for each automatic markdown step
+const ItemHistoryActionMarkedDown = "marked_down"
+
+// MarkdownRule takes a percentage off the sale price of an item once it has
+// been listed for sale for a number of days. Rules stack: each one applies to
+// the price left by the previous one.
+type MarkdownRule struct {
+	AfterDays int     `json:"after_days"`
+	Percent   float64 `json:"percent"`
+}
+
+// DefaultMarkdownRules returns the markdown schedule used when none is configured
+func DefaultMarkdownRules() []MarkdownRule {
+	return []MarkdownRule{
+		{AfterDays: 30, Percent: 10},
+		{AfterDays: 60, Percent: 10},
+	}
+}
+
+// DueMarkdownSteps returns the rules an item listed for daysListed days is due
+// for, skipping the first applied ones which were already taken off its price
+func DueMarkdownSteps(rules []MarkdownRule, daysListed, applied int) []MarkdownRule {
+	sorted := make([]MarkdownRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.AfterDays > 0 && rule.Percent > 0 && rule.Percent < 100 {
+			sorted = append(sorted, rule)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].AfterDays < sorted[j].AfterDays })
+
+	due := 0
+	for due < len(sorted) && sorted[due].AfterDays <= daysListed {
+		due++
+	}
+	if applied >= due {
+		return nil
+	}
+	return sorted[applied:due]
+}
+
+// MarkdownCandidate is an item listed for sale that the markdown job looks at
+type MarkdownCandidate struct {
+	ItemID         int64     `json:"item_id"`
+	BranchID       int64     `json:"branch_id"`
+	SKU            string    `json:"sku"`
+	Name           string    `json:"name"`
+	AppraisedValue float64   `json:"appraised_value"`
+	SalePrice      float64   `json:"sale_price"`
+	ListedAt       time.Time `json:"listed_at"`     // When the item was last put up for sale
+	StepsApplied   int       `json:"steps_applied"` // Markdown steps taken since ListedAt
+}
+
+// DaysListed returns the number of whole days the item has been for sale
+func (c *MarkdownCandidate) DaysListed(now time.Time) int {
+	if now.Before(c.ListedAt) {
+		return 0
+	}
+	return int(now.Sub(c.ListedAt).Hours() / 24)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDueMarkdownSteps(t *testing.T) {
+	rules := []MarkdownRule{
+		{AfterDays: 60, Percent: 10},
+		{AfterDays: 30, Percent: 10},
+		{AfterDays: 90, Percent: 0}, // ignored
+	}
+
+	assert.Empty(t, DueMarkdownSteps(rules, 29, 0))
+	assert.Equal(t, []MarkdownRule{{AfterDays: 30, Percent: 10}}, DueMarkdownSteps(rules, 30, 0))
+	assert.Len(t, DueMarkdownSteps(rules, 75, 0), 2)
+	assert.Equal(t, []MarkdownRule{{AfterDays: 60, Percent: 10}}, DueMarkdownSteps(rules, 75, 1))
+	assert.Empty(t, DueMarkdownSteps(rules, 75, 2))
+	assert.Empty(t, DueMarkdownSteps(rules, 200, 2))
+}
+
+func TestMarkdownCandidate_DaysListed(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	candidate := &MarkdownCandidate{ListedAt: now.AddDate(0, 0, -30).Add(time.Hour)}
+
+	assert.Equal(t, 29, candidate.DaysListed(now))
+	assert.Equal(t, 30, candidate.DaysListed(now.Add(time.Hour)))
+	assert.Equal(t, 0, candidate.DaysListed(now.AddDate(0, 0, -31)))
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency assumed when a branch has none configured
+const DefaultCurrency = "GTQ"
+
+// DefaultMoneyLocale is the locale assumed when none is configured
+const DefaultMoneyLocale = "es-GT"
+
+// currencySymbols maps ISO currency codes to the symbol printed before amounts
+var currencySymbols = map[string]string{
+	"GTQ": "Q",
+	"USD": "$",
+	"MXN": "$",
+	"EUR": "€",
+	"HNL": "L",
+	"NIO": "C$",
+	"CRC": "₡",
+	"SVC": "₡",
+	"PAB": "B/.",
+	"DOP": "RD$",
+	"COP": "$",
+}
+
+// commaDecimalLocales write amounts as 1.234,56 instead of 1,234.56
+var commaDecimalLocales = map[string]bool{
+	"es": true, "es-es": true, "es-ar": true, "es-cl": true, "es-co": true, "es-cr": true,
+	"es-uy": true, "es-ve": true, "pt": true, "pt-br": true, "de": true, "fr": true, "it": true,
+}
+
+// Where the currency symbol is written relative to the amount
+const (
+	MoneySymbolPrefix = "prefix" // Q1,234.50
+	MoneySymbolSuffix = "suffix" // 1.234,50 €
+)
+
+// MoneyFormat describes how amounts of a currency are written for a locale
+type MoneyFormat struct {
+	Currency       string `json:"currency"`
+	Locale         string `json:"locale"`
+	CustomSymbol   string `json:"symbol,omitempty"`          // Replaces the currency's own symbol
+	SymbolPosition string `json:"symbol_position,omitempty"` // prefix (default) or suffix
+}
+
+// NewMoneyFormat creates a MoneyFormat, falling back to the defaults for empty values
+func NewMoneyFormat(currency, locale string) MoneyFormat {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		locale = DefaultMoneyLocale
+	}
+	return MoneyFormat{Currency: currency, Locale: locale}
+}
+
+// WithSymbol returns a copy of the format that writes the given symbol at the
+// given position; an empty symbol keeps the currency's own
+func (f MoneyFormat) WithSymbol(symbol, position string) MoneyFormat {
+	f.CustomSymbol = strings.TrimSpace(symbol)
+	f.SymbolPosition = ""
+	if strings.EqualFold(strings.TrimSpace(position), MoneySymbolSuffix) {
+		f.SymbolPosition = MoneySymbolSuffix
+	}
+	return f
+}
+
+// Symbol returns the currency symbol, or the currency code followed by a space
+// for currencies without a known symbol
+func (f MoneyFormat) Symbol() string {
+	if f.CustomSymbol != "" {
+		return f.CustomSymbol
+	}
+	if symbol, ok := currencySymbols[f.Currency]; ok {
+		return symbol
+	}
+	if f.Currency == "" {
+		return currencySymbols[DefaultCurrency]
+	}
+	return f.Currency + " "
+}
+
+// Format writes an amount with the currency symbol, two decimals and the
+// locale's separators, e.g. Q1,234.50, €1.234,50 or 1.234,50 € with the
+// symbol as a suffix
+func (f MoneyFormat) Format(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	number := f.Number(amount)
+	if f.SymbolPosition == MoneySymbolSuffix {
+		return sign + number + " " + strings.TrimSpace(f.Symbol())
+	}
+	return sign + f.Symbol() + number
+}
+
+// Number writes an amount with two decimals and the locale's separators but
+// no symbol, for texts that place the symbol themselves
+func (f MoneyFormat) Number(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	cents := int64(math.Round(amount * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+	decimals := strconv.FormatInt(cents%100+100, 10)[1:]
+
+	thousands, decimal := ",", "."
+	locale := strings.ToLower(strings.ReplaceAll(f.Locale, "_", "-"))
+	if commaDecimalLocales[locale] {
+		thousands, decimal = ".", ","
+	}
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return sign + grouped.String() + decimal + decimals
+}
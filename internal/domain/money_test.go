@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMoneyFormat_Defaults(t *testing.T) {
+	assert.Equal(t, MoneyFormat{Currency: "GTQ", Locale: "es-GT"}, NewMoneyFormat("", ""))
+	assert.Equal(t, MoneyFormat{Currency: "USD", Locale: "en-US"}, NewMoneyFormat(" usd ", "en-US"))
+}
+
+func TestMoneyFormat_Symbol(t *testing.T) {
+	assert.Equal(t, "Q", NewMoneyFormat("GTQ", "").Symbol())
+	assert.Equal(t, "$", NewMoneyFormat("MXN", "").Symbol())
+	assert.Equal(t, "€", NewMoneyFormat("EUR", "").Symbol())
+	assert.Equal(t, "BZD ", NewMoneyFormat("BZD", "").Symbol())
+	assert.Equal(t, "US$", NewMoneyFormat("USD", "").WithSymbol(" US$ ", "").Symbol())
+}
+
+func TestMoneyFormat_Format(t *testing.T) {
+	tests := []struct {
+		format MoneyFormat
+		amount float64
+		want   string
+	}{
+		{NewMoneyFormat("GTQ", "es-GT"), 1234.5, "Q1,234.50"},
+		{NewMoneyFormat("GTQ", "es-GT"), 0, "Q0.00"},
+		{NewMoneyFormat("USD", "en-US"), 1234567.891, "$1,234,567.89"},
+		{NewMoneyFormat("EUR", "es_ES"), 1234.5, "€1.234,50"},
+		{NewMoneyFormat("GTQ", "es-GT"), -99.999, "-Q100.00"},
+		{NewMoneyFormat("BZD", "en"), 12, "BZD 12.00"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.format.Format(tt.amount))
+	}
+}
+
+func TestMoneyFormat_Format_SymbolPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		format MoneyFormat
+		amount float64
+		want   string
+	}{
+		{"prefix", NewMoneyFormat("GTQ", "es-GT").WithSymbol("", MoneySymbolPrefix), 1234.5, "Q1,234.50"},
+		{"unknown position is a prefix", NewMoneyFormat("GTQ", "es-GT").WithSymbol("", "middle"), 10, "Q10.00"},
+		{"custom symbol prefix", NewMoneyFormat("USD", "en-US").WithSymbol("US$", MoneySymbolPrefix), 99.5, "US$99.50"},
+		{"suffix", NewMoneyFormat("EUR", "es-ES").WithSymbol("", MoneySymbolSuffix), 1234.5, "1.234,50 €"},
+		{"suffix negative", NewMoneyFormat("EUR", "es-ES").WithSymbol("", "SUFFIX"), -3, "-3,00 €"},
+		{"suffix code without symbol", NewMoneyFormat("BZD", "en").WithSymbol("", MoneySymbolSuffix), 12, "12.00 BZD"},
+		{"custom symbol suffix", NewMoneyFormat("GTQ", "es-GT").WithSymbol("GTQ", MoneySymbolSuffix), 5, "5.00 GTQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.format.Format(tt.amount))
+		})
+	}
+}
+
+func TestMoneyFormat_Number(t *testing.T) {
+	assert.Equal(t, "1,234.50", NewMoneyFormat("GTQ", "es-GT").Number(1234.5))
+	assert.Equal(t, "1.234,50", NewMoneyFormat("EUR", "es-ES").WithSymbol("", MoneySymbolSuffix).Number(1234.5))
+	assert.Equal(t, "-3.00", NewMoneyFormat("USD", "en-US").Number(-3))
+}
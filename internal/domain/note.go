@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+)
+
+// NoteEntityType is the kind of record an internal note is attached to
+type NoteEntityType string
+
+const (
+	NoteEntityLoan     NoteEntityType = "loan"
+	NoteEntityItem     NoteEntityType = "item"
+	NoteEntityCustomer NoteEntityType = "customer"
+)
+
+// IsValid checks if notes can be attached to the entity type
+func (t NoteEntityType) IsValid() bool {
+	switch t {
+	case NoteEntityLoan, NoteEntityItem, NoteEntityCustomer:
+		return true
+	}
+	return false
+}
+
+// Note is an internal staff comment on a loan, item or customer. Notes are
+// never shown to customers nor printed on their documents. A note with a
+// ParentID is a reply in the parent's thread.
+type Note struct {
+	ID         int64          `json:"id"`
+	EntityType NoteEntityType `json:"entity_type"`
+	EntityID   int64          `json:"entity_id"`
+	ParentID   *int64         `json:"parent_id,omitempty"`
+	Body       string         `json:"body"`
+
+	// Users mentioned in the note, who get an internal notification
+	MentionedUserIDs []int64 `json:"mentioned_user_ids"`
+
+	// Audit
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	AuthorName string  `json:"author_name,omitempty"`
+	Replies    []*Note `json:"replies,omitempty"`
+}
+
+// TableName returns the database table name
+func (Note) TableName() string {
+	return "notes"
+}
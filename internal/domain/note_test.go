@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNote_TableName(t *testing.T) {
+	assert.Equal(t, "notes", Note{}.TableName())
+}
+
+func TestNoteEntityType_IsValid(t *testing.T) {
+	assert.True(t, NoteEntityLoan.IsValid())
+	assert.True(t, NoteEntityItem.IsValid())
+	assert.True(t, NoteEntityCustomer.IsValid())
+	assert.False(t, NoteEntityType("payment").IsValid())
+}
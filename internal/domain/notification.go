@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
 
 // Notification types
 const (
@@ -9,6 +12,7 @@ const (
 	NotificationTypeMinimumPaymentDue = "minimum_payment_due"
 	NotificationTypePaymentReceived   = "payment_received"
 	NotificationTypeLoanConfiscated   = "loan_confiscated"
+	NotificationTypeConfiscationRisk  = "confiscation_risk_reminder"
 	NotificationTypeItemForSale       = "item_for_sale"
 	NotificationTypeItemSold          = "item_sold"
 	NotificationTypePromotion         = "promotion"
@@ -16,6 +20,13 @@ const (
 	NotificationTypeGeneral           = "general"
 )
 
+// NotificationTypeContactVerification carries a one-time code confirming a customer's phone
+const NotificationTypeContactVerification = "contact_verification"
+
+// NotificationTypeCustomerStatement delivers the monthly account statement. Unlike
+// other types customers only get it after explicitly enabling it on a channel.
+const NotificationTypeCustomerStatement = "customer_statement"
+
 // Notification channels
 const (
 	NotificationChannelEmail    = "email"
@@ -34,6 +45,9 @@ const (
 	NotificationStatusCancelled = "cancelled"
 )
 
+// NotificationMaxRetries is how many times a failed notification is retried
+const NotificationMaxRetries = 3
+
 // NotificationTemplate represents a template for notifications
 type NotificationTemplate struct {
 	ID              int64  `json:"id"`
@@ -48,6 +62,81 @@ type NotificationTemplate struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// templatePlaceholder matches a {{name}} placeholder of a notification template
+var templatePlaceholder = regexp.MustCompile(`{{\s*([^{}]*?)\s*}}`)
+
+// commonTemplateVariables are provided to the templates of every notification type
+var commonTemplateVariables = []string{"customer_name", "currency"}
+
+// notificationTemplateVariables are the variables each notification type
+// provides to its templates besides the common ones
+var notificationTemplateVariables = map[string][]string{
+	NotificationTypeLoanDueReminder:     {"loan_number", "due_date", "amount", "amount_due"},
+	NotificationTypeLoanOverdue:         {"loan_number", "due_date", "amount", "amount_due", "days_overdue", "late_fee"},
+	NotificationTypeMinimumPaymentDue:   {"loan_number", "due_date", "amount", "amount_due", "minimum_payment"},
+	NotificationTypePaymentReceived:     {"loan_number", "amount", "payment_number", "payment_date", "remaining_balance"},
+	NotificationTypeLoanConfiscated:     {"loan_number", "days_overdue", "confiscation_date", "item_name", "total_due"},
+	"loan_confiscation":                 {"loan_number", "days_overdue", "confiscation_date", "item_name", "total_due"}, // Seeded confiscation warning
+	NotificationTypeConfiscationRisk:    {"loan_number", "item_description", "payoff_amount", "confiscation_date", "days_left"},
+	NotificationTypeItemForSale:         {"item_name", "price"},
+	NotificationTypeItemSold:            {"item_name", "price", "sale_number"},
+	NotificationTypePromotion:           {"promotion_name", "discount", "valid_until"},
+	NotificationTypeLoyaltyPoints:       {"points", "points_balance"},
+	NotificationTypeGeneral:             {"message"},
+	NotificationTypeContactVerification: {"code"},
+	NotificationTypeCustomerStatement:   {"period", "total_paid", "balance", "statement_url"},
+}
+
+// TemplateVariables returns the variables the templates of a notification type may use
+func TemplateVariables(notificationType string) []string {
+	vars := append([]string{}, commonTemplateVariables...)
+	return append(vars, notificationTemplateVariables[notificationType]...)
+}
+
+// TemplatePlaceholders returns the distinct {{...}} placeholders of a template text, in order
+func TemplatePlaceholders(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RenderTemplate replaces the placeholders of a template text with their
+// values. Spaces inside the braces are allowed, as in validation, and
+// placeholders without a value are left as they are.
+func RenderTemplate(text string, data map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if value, ok := data[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// UnknownPlaceholders returns the placeholders of the template's subject and
+// body that its notification type does not provide
+func (t *NotificationTemplate) UnknownPlaceholders() []string {
+	allowed := make(map[string]bool)
+	for _, name := range TemplateVariables(t.NotificationType) {
+		allowed[name] = true
+	}
+
+	var unknown []string
+	for _, name := range append(TemplatePlaceholders(t.Subject), TemplatePlaceholders(t.BodyTemplate)...) {
+		if !allowed[name] {
+			allowed[name] = true // Report each placeholder once
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
 // Notification represents a notification to be sent to a customer
 type Notification struct {
 	ID               int64     `json:"id"`
@@ -74,6 +163,8 @@ type Notification struct {
 	FailedAt     *time.Time `json:"failed_at,omitempty"`
 	FailureReason string    `json:"failure_reason,omitempty"`
 	RetryCount   int        `json:"retry_count"`
+	// ExternalMessageID is the ID the provider gave the message, matching its delivery receipts
+	ExternalMessageID string `json:"external_message_id,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -101,7 +192,127 @@ func (n *Notification) IsFailed() bool {
 
 // CanRetry checks if notification can be retried
 func (n *Notification) CanRetry() bool {
-	return n.Status == NotificationStatusFailed && n.RetryCount < 3
+	return n.Status == NotificationStatusFailed && n.RetryCount < NotificationMaxRetries
+}
+
+// NotificationRetryDelay is how long a failed notification waits before it is
+// retried: the base delay, doubled for every retry already made
+func NotificationRetryDelay(base time.Duration, retryCount int) time.Duration {
+	if retryCount < 0 {
+		retryCount = 0
+	}
+	return base << uint(retryCount)
+}
+
+// NextRetryAt returns when a failed notification may be retried, counting the
+// backoff from when it failed
+func (n *Notification) NextRetryAt(base time.Duration, now time.Time) time.Time {
+	failedAt := now
+	if n.FailedAt != nil {
+		failedAt = *n.FailedAt
+	}
+	return failedAt.Add(NotificationRetryDelay(base, n.RetryCount))
+}
+
+// RetriesExhausted checks if the notification has used up all of its retries
+func (n *Notification) RetriesExhausted() bool {
+	return n.RetryCount >= NotificationMaxRetries
+}
+
+// RedactedNotificationBody replaces the body of a sent notification that
+// carried a secret
+const RedactedNotificationBody = "[contenido oculto tras el envío]"
+
+// ContainsSecret checks if the notification body carries a secret, such as a
+// one-time code, that must not be kept once it is sent
+func (n *Notification) ContainsSecret() bool {
+	return n.NotificationType == NotificationTypeContactVerification
+}
+
+// RequiresOpenLoan checks if the notification asks the customer to pay a loan balance,
+// so it only makes sense while the referenced loan is still open
+func (n *Notification) RequiresOpenLoan() bool {
+	if n.ReferenceType != "loan" || n.ReferenceID == nil {
+		return false
+	}
+	switch n.NotificationType {
+	case NotificationTypeLoanDueReminder, NotificationTypeLoanOverdue, NotificationTypeMinimumPaymentDue,
+		NotificationTypeConfiscationRisk:
+		return true
+	}
+	return false
+}
+
+// NotificationQuietHours is a daily window, in "HH:MM" local times, during
+// which customers must not be contacted. A window whose end comes before its
+// start wraps past midnight, e.g. 21:00 to 08:00.
+type NotificationQuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// parseClock reads an "HH:MM" time as minutes since midnight
+func parseClock(clock string) (int, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// IsValid checks that both ends of the window are valid times and differ
+func (q NotificationQuietHours) IsValid() bool {
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	return okStart && okEnd && start != end
+}
+
+// NextAllowed returns the first time at or after t, in t's location, outside
+// the quiet hours: t itself when it is outside the window, otherwise the end
+// of the window
+func (q NotificationQuietHours) NextAllowed(t time.Time) time.Time {
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	if !okStart || !okEnd || start == end {
+		return t
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	endToday := time.Date(t.Year(), t.Month(), t.Day(), 0, end, 0, 0, t.Location())
+
+	if start < end {
+		if minute >= start && minute < end {
+			return endToday
+		}
+		return t
+	}
+
+	// The window wraps past midnight
+	switch {
+	case minute >= start:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, end, 0, 0, t.Location())
+	case minute < end:
+		return endToday
+	}
+	return t
+}
+
+// NotificationChannelStatus tracks delivery health of a customer's channel.
+// FailureStreak counts notifications that exhausted their retries since
+// StreakStartedAt; the channel is paused once the streak gets too long.
+type NotificationChannelStatus struct {
+	CustomerID      int64      `json:"customer_id"`
+	Channel         string     `json:"channel"`
+	FailureStreak   int        `json:"failure_streak"`
+	StreakStartedAt *time.Time `json:"streak_started_at,omitempty"`
+	PausedAt        *time.Time `json:"paused_at,omitempty"`
+	PauseReason     string     `json:"pause_reason,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// IsPaused checks if automatic sends to the channel are paused
+func (s *NotificationChannelStatus) IsPaused() bool {
+	return s != nil && s.PausedAt != nil
 }
 
 // CustomerNotificationPreference represents customer preferences for notifications
@@ -137,10 +348,34 @@ type InternalNotification struct {
 	// Status
 	IsRead   bool       `json:"is_read"`
 	ReadAt   *time.Time `json:"read_at,omitempty"`
+	// DigestPending holds the notification back for the user's next digest
+	DigestPending bool `json:"-"`
 
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// UserNotificationPreference is how a user wants to receive internal
+// notifications: as they happen or bundled in a periodic digest
+type UserNotificationPreference struct {
+	UserID              int64      `json:"user_id"`
+	DigestMode          bool       `json:"digest_mode"`
+	DigestIntervalHours int        `json:"digest_interval_hours"`
+	LastDigestAt        *time.Time `json:"last_digest_at,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DigestDue checks if the user's next digest is due
+func (p *UserNotificationPreference) DigestDue(now time.Time) bool {
+	if !p.DigestMode {
+		return false
+	}
+	if p.LastDigestAt == nil {
+		return true
+	}
+	return !now.Before(p.LastDigestAt.Add(time.Duration(p.DigestIntervalHours) * time.Hour))
+}
+
 // MarkAsRead marks the notification as read
 func (n *InternalNotification) MarkAsRead() {
 	n.IsRead = true
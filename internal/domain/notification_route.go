@@ -0,0 +1,77 @@
+package domain
+
+import "fmt"
+
+// Internal events routed to staff as internal notifications
+const (
+	InternalEventHighValueLoan      = "high_value_loan"
+	InternalEventCashDifference     = "cash_difference"
+	InternalEventConfiscation       = "confiscation"
+	InternalEventCashSessionOpen    = "cash_session_open"
+	InternalEventBackupVerifyFailed = "backup_verification_failed"
+	InternalEventRoundTrip          = "round_trip_detected"
+	InternalEventBackupCompleted    = "backup_completed"
+	InternalEventBackupFailed       = "backup_failed"
+)
+
+// InternalEvents lists the events that can be routed
+var InternalEvents = []string{
+	InternalEventHighValueLoan,
+	InternalEventCashDifference,
+	InternalEventConfiscation,
+	InternalEventCashSessionOpen,
+	InternalEventBackupVerifyFailed,
+	InternalEventRoundTrip,
+	InternalEventBackupCompleted,
+	InternalEventBackupFailed,
+}
+
+// NotificationRoute decides which staff hear about an internal event: the
+// active users holding any of Roles, limited to the event's branch when
+// BranchOnly is set. Events for amounts under MinAmount are not routed.
+type NotificationRoute struct {
+	Event      string   `json:"event"`
+	Roles      []string `json:"roles"`
+	BranchOnly bool     `json:"branch_only"`
+	MinAmount  float64  `json:"min_amount,omitempty"`
+}
+
+// Validate checks the route names a known event and at least one role
+func (r NotificationRoute) Validate() error {
+	known := false
+	for _, event := range InternalEvents {
+		if r.Event == event {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown event %q", r.Event)
+	}
+	if len(r.Roles) == 0 {
+		return fmt.Errorf("route for %s needs at least one role", r.Event)
+	}
+	if r.MinAmount < 0 {
+		return fmt.Errorf("route for %s cannot have a negative minimum amount", r.Event)
+	}
+	return nil
+}
+
+// Matches reports whether an event of the given amount is routed
+func (r NotificationRoute) Matches(event string, amount float64) bool {
+	return r.Event == event && amount >= r.MinAmount
+}
+
+// DefaultNotificationRoutes returns the routing used when none is configured
+func DefaultNotificationRoutes() []NotificationRoute {
+	return []NotificationRoute{
+		{Event: InternalEventHighValueLoan, Roles: []string{RoleManager}, BranchOnly: true, MinAmount: 10000},
+		{Event: InternalEventCashDifference, Roles: []string{RoleManager}, BranchOnly: true},
+		{Event: InternalEventConfiscation, Roles: []string{RoleManager}, BranchOnly: true},
+		{Event: InternalEventCashSessionOpen, Roles: []string{RoleManager}, BranchOnly: true},
+		{Event: InternalEventBackupVerifyFailed, Roles: []string{RoleAdmin}},
+		{Event: InternalEventRoundTrip, Roles: []string{RoleManager}, BranchOnly: true},
+		{Event: InternalEventBackupCompleted, Roles: []string{RoleAdmin}},
+		{Event: InternalEventBackupFailed, Roles: []string{RoleAdmin}},
+	}
+}
@@ -86,3 +86,36 @@ func TestInternalNotification_MarkAsRead_AlreadyRead(t *testing.T) {
 	// ReadAt should be updated to now
 	assert.WithinDuration(t, time.Now(), *n.ReadAt, 2*time.Second)
 }
+
+func TestNotificationRetryDelay(t *testing.T) {
+	base := 2 * time.Minute
+	assert.Equal(t, 2*time.Minute, NotificationRetryDelay(base, 0))
+	assert.Equal(t, 4*time.Minute, NotificationRetryDelay(base, 1))
+	assert.Equal(t, 8*time.Minute, NotificationRetryDelay(base, 2))
+	assert.Equal(t, 16*time.Minute, NotificationRetryDelay(base, 3))
+	assert.Equal(t, 2*time.Minute, NotificationRetryDelay(base, -1))
+}
+
+func TestNotification_NextRetryAt(t *testing.T) {
+	now := time.Date(2026, 9, 10, 12, 0, 0, 0, time.UTC)
+	failedAt := now.Add(-time.Hour)
+
+	n := &Notification{RetryCount: 2, FailedAt: &failedAt}
+	assert.Equal(t, failedAt.Add(4*time.Minute), n.NextRetryAt(time.Minute, now))
+
+	n.FailedAt = nil
+	assert.Equal(t, now.Add(4*time.Minute), n.NextRetryAt(time.Minute, now))
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	assert.Equal(t, []string{"customer_name", "loan_number", "currency"},
+		TemplatePlaceholders("Hola {{customer_name}}, préstamo {{ loan_number }}: {{currency}}{{customer_name}}"))
+	assert.Empty(t, TemplatePlaceholders("Sin variables { ni llaves sueltas }"))
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := map[string]string{"customer_name": "Ana", "loan_number": "LN-000001"}
+
+	assert.Equal(t, "Hola Ana, préstamo LN-000001 {{currency}}",
+		RenderTemplate("Hola {{customer_name}}, préstamo {{ loan_number }} {{currency}}", data))
+}
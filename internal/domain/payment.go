@@ -25,6 +25,12 @@ const (
 	PaymentStatusFailed    PaymentStatus = "failed"
 )
 
+// Reference types of the entries posted for a payment and for its reversal
+const (
+	AccountingReferencePayment         = "payment"
+	AccountingReferencePaymentReversal = "payment_reversal"
+)
+
 // Payment represents a loan payment
 type Payment struct {
 	ID            int64  `json:"id"`
@@ -62,6 +68,10 @@ type Payment struct {
 	// Cash session reference
 	CashSessionID *int64 `json:"cash_session_id,omitempty"`
 
+	// IdempotencyKey is the client's key for the request that created the
+	// payment; it is only written when the payment is created
+	IdempotencyKey string `json:"-"`
+
 	// Audit
 	CreatedBy int64 `json:"created_by,omitempty"`
 
@@ -69,6 +79,10 @@ type Payment struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Amounts written in the branch currency and locale, only filled in when
+	// money formatting is enabled for API responses
+	Formatted map[string]string `json:"formatted,omitempty"`
+
 	// Relations
 	Branch   *Branch   `json:"branch,omitempty"`
 	Loan     *Loan     `json:"loan,omitempty"`
@@ -15,6 +15,10 @@ const (
 	SaleStatusPartialRefund SaleStatus = "partial_refund"
 )
 
+// AccountingReferenceSaleRefund is the reference type of the entries posted
+// for a sale refund
+const AccountingReferenceSaleRefund = "sale_refund"
+
 // Sale represents a sale of an item
 type Sale struct {
 	ID         int64  `json:"id"`
@@ -43,6 +47,13 @@ type Sale struct {
 	RefundReason  *string    `json:"refund_reason,omitempty"`
 	RefundedAt    *time.Time `json:"refunded_at,omitempty"`
 	RefundedBy    *int64     `json:"refunded_by,omitempty"`
+	// RefundOutsideWarranty marks a refund accepted after the return period ended
+	RefundOutsideWarranty bool `json:"refund_outside_warranty,omitempty"`
+
+	// Warranty / return period, fixed when the sale is completed
+	WarrantyDays          int        `json:"warranty_days"`
+	WarrantyExpiry        *time.Time `json:"warranty_expiry,omitempty"`
+	WarrantyDaysRemaining *int       `json:"warranty_days_remaining,omitempty"`
 
 	// Notes
 	Notes *string `json:"notes,omitempty"`
@@ -79,3 +90,14 @@ func (s *Sale) IsRefunded() bool {
 func (s *Sale) CanBeRefunded() bool {
 	return s.Status == SaleStatusCompleted
 }
+
+// WarrantyDaysLeft returns the calendar days left to return the item, negative
+// once the period is over. ok is false when the sale has no return period.
+func (s *Sale) WarrantyDaysLeft(now time.Time) (days int, ok bool) {
+	if s.WarrantyExpiry == nil {
+		return 0, false
+	}
+	expiry := time.Date(s.WarrantyExpiry.Year(), s.WarrantyExpiry.Month(), s.WarrantyExpiry.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(expiry.Sub(today).Hours() / 24), true
+}
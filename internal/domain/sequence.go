@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// SequenceResetCadence controls when a document number sequence restarts at 1
+type SequenceResetCadence string
+
+const (
+	SequenceResetNever   SequenceResetCadence = "never"
+	SequenceResetYearly  SequenceResetCadence = "yearly"
+	SequenceResetMonthly SequenceResetCadence = "monthly"
+)
+
+// IsValid checks if the cadence is a known value
+func (c SequenceResetCadence) IsValid() bool {
+	switch c {
+	case SequenceResetNever, SequenceResetYearly, SequenceResetMonthly:
+		return true
+	}
+	return false
+}
+
+// Period returns the period a sequence counts within at the given time. Numbers
+// embed the period, so they stay unique after the sequence restarts.
+func (c SequenceResetCadence) Period(t time.Time) string {
+	switch c {
+	case SequenceResetYearly:
+		return t.Format("2006")
+	case SequenceResetMonthly:
+		return t.Format("2006-01")
+	}
+	return ""
+}
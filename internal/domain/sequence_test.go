@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceResetCadence_Period(t *testing.T) {
+	date := time.Date(2026, 3, 5, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "", SequenceResetNever.Period(date))
+	assert.Equal(t, "2026", SequenceResetYearly.Period(date))
+	assert.Equal(t, "2026-03", SequenceResetMonthly.Period(date))
+}
+
+func TestSequenceResetCadence_IsValid(t *testing.T) {
+	assert.True(t, SequenceResetNever.IsValid())
+	assert.True(t, SequenceResetYearly.IsValid())
+	assert.True(t, SequenceResetMonthly.IsValid())
+	assert.False(t, SequenceResetCadence("weekly").IsValid())
+}
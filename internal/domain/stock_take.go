@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"time"
+)
+
+// StockTakeStatus represents the status of a physical inventory count
+type StockTakeStatus string
+
+const (
+	StockTakeStatusInProgress StockTakeStatus = "in_progress"
+	StockTakeStatusFinalized  StockTakeStatus = "finalized"
+	StockTakeStatusCancelled  StockTakeStatus = "cancelled"
+)
+
+// StockTake is a physical inventory count of a branch. Only one count per
+// branch can be in progress at a time.
+type StockTake struct {
+	ID       int64           `json:"id"`
+	BranchID int64           `json:"branch_id"`
+	Status   StockTakeStatus `json:"status"`
+	Notes    *string         `json:"notes,omitempty"`
+
+	// Discrepancy report, set when the count is finalized
+	Report *StockTakeReport `json:"report,omitempty"`
+
+	// Audit
+	StartedBy   int64      `json:"started_by"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinalizedBy *int64     `json:"finalized_by,omitempty"`
+	FinalizedAt *time.Time `json:"finalized_at,omitempty"`
+}
+
+// TableName returns the database table name
+func (StockTake) TableName() string {
+	return "stock_takes"
+}
+
+// IsInProgress checks if items can still be scanned into the count
+func (s *StockTake) IsInProgress() bool {
+	return s.Status == StockTakeStatusInProgress
+}
+
+// StockTakeScan is an item SKU found on the shelves during a count. ItemID is
+// nil when the SKU does not match any item in the system.
+type StockTakeScan struct {
+	ID          int64     `json:"id"`
+	StockTakeID int64     `json:"stock_take_id"`
+	SKU         string    `json:"sku"`
+	ItemID      *int64    `json:"item_id,omitempty"`
+	ScannedBy   int64     `json:"scanned_by"`
+	ScannedAt   time.Time `json:"scanned_at"`
+}
+
+// TableName returns the database table name
+func (StockTakeScan) TableName() string {
+	return "stock_take_scans"
+}
+
+// StockTakeDiscrepancyType classifies a difference between the system and the count
+type StockTakeDiscrepancyType string
+
+const (
+	// StockTakeMissing is an item the system expects in the branch that was not scanned
+	StockTakeMissing StockTakeDiscrepancyType = "missing"
+	// StockTakeExtra is a scanned SKU that is unknown or belongs to another branch
+	StockTakeExtra StockTakeDiscrepancyType = "extra"
+	// StockTakeStatusMismatch is a scanned item whose status says it left the branch
+	StockTakeStatusMismatch StockTakeDiscrepancyType = "status_mismatch"
+)
+
+// StockTakeDiscrepancy is a single difference found by a count
+type StockTakeDiscrepancy struct {
+	Type     StockTakeDiscrepancyType `json:"type"`
+	SKU      string                   `json:"sku"`
+	ItemID   *int64                   `json:"item_id,omitempty"`
+	ItemName string                   `json:"item_name,omitempty"`
+	Status   ItemStatus               `json:"status,omitempty"`
+	BranchID *int64                   `json:"branch_id,omitempty"`
+
+	// Set when the item was edited after the count started, so the difference
+	// may come from the edit rather than from the shelves
+	ChangedDuringCount bool `json:"changed_during_count,omitempty"`
+}
+
+// StockTakeReport compares the items the system expects in a branch with the items counted
+type StockTakeReport struct {
+	ExpectedCount  int                    `json:"expected_count"`
+	ScannedCount   int                    `json:"scanned_count"`
+	MatchedCount   int                    `json:"matched_count"`
+	Missing        []StockTakeDiscrepancy `json:"missing"`
+	Extra          []StockTakeDiscrepancy `json:"extra"`
+	StatusMismatch []StockTakeDiscrepancy `json:"status_mismatch"`
+}
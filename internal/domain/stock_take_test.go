@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockTake_TableName(t *testing.T) {
+	assert.Equal(t, "stock_takes", StockTake{}.TableName())
+	assert.Equal(t, "stock_take_scans", StockTakeScan{}.TableName())
+}
+
+func TestStockTake_IsInProgress(t *testing.T) {
+	assert.True(t, (&StockTake{Status: StockTakeStatusInProgress}).IsInProgress())
+	assert.False(t, (&StockTake{Status: StockTakeStatusFinalized}).IsInProgress())
+	assert.False(t, (&StockTake{Status: StockTakeStatusCancelled}).IsInProgress())
+}
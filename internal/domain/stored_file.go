@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+)
+
+// StoredFile is the metadata of an uploaded file, kept to enforce each
+// branch's storage quota and report its usage
+type StoredFile struct {
+	ID           int64  `json:"id"`
+	BranchID     int64  `json:"branch_id"`
+	EntityType   string `json:"entity_type"` // item, ...
+	EntityID     int64  `json:"entity_id"`
+	FileID       string `json:"file_id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"` // Empty for files that are not images
+	MimeType     string `json:"mime_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+
+	CreatedBy int64     `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName returns the database table name
+func (StoredFile) TableName() string {
+	return "stored_files"
+}
+
+// StorageUsage is the storage used by a branch for one entity type
+type StorageUsage struct {
+	BranchID   int64  `json:"branch_id"`
+	EntityType string `json:"entity_type"`
+	Objects    int    `json:"objects"`
+	Bytes      int64  `json:"bytes"`
+}
@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoredFile_TableName(t *testing.T) {
+	assert.Equal(t, "stored_files", StoredFile{}.TableName())
+}
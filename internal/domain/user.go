@@ -77,6 +77,22 @@ func (u *User) HasPermission(permission string) bool {
 	return u.Role.HasPermission(permission)
 }
 
+// PermissionBranchesAll lets a user query the data of every branch, not only their own
+const PermissionBranchesAll = "branches.all"
+
+// CanAccessAllBranches checks if the user may query any branch's data
+func (u *User) CanAccessAllBranches() bool {
+	return u.HasPermission(PermissionBranchesAll)
+}
+
+// CanAccessBranch checks if the user may query the data of a branch
+func (u *User) CanAccessBranch(branchID int64) bool {
+	if u.CanAccessAllBranches() {
+		return true
+	}
+	return u.BranchID != nil && *u.BranchID == branchID
+}
+
 // UserPublic is a safe version of User for API responses
 type UserPublic struct {
 	ID        int64  `json:"id"`
@@ -0,0 +1,23 @@
+package domain
+
+// UserActivity totals what a staff member did over a period: the loans they
+// created, the payments and sales they processed and the cash sessions they
+// closed. Auto-closed sessions are left out of the cash difference since no
+// cash was counted.
+type UserActivity struct {
+	UserID                int64   `json:"user_id"`
+	LoansCreated          int     `json:"loans_created"`
+	LoansAmount           float64 `json:"loans_amount"`
+	PaymentsProcessed     int     `json:"payments_processed"`
+	PaymentsAmount        float64 `json:"payments_amount"`
+	SalesCount            int     `json:"sales_count"`
+	SalesAmount           float64 `json:"sales_amount"`
+	CashSessionsClosed    int     `json:"cash_sessions_closed"`
+	AverageCashDifference float64 `json:"average_cash_difference"`
+}
+
+// UserBranchActivity is the part of a user's activity done in one branch
+type UserBranchActivity struct {
+	BranchID int64 `json:"branch_id"`
+	UserActivity
+}
@@ -80,6 +80,21 @@ func TestUser_HasPermission_Wildcard(t *testing.T) {
 	assert.True(t, u.HasPermission("anything"))
 }
 
+func TestUser_CanAccessBranch(t *testing.T) {
+	branchID := int64(2)
+	single := &User{BranchID: &branchID, Role: &Role{Permissions: json.RawMessage(`["loans.read"]`)}}
+	assert.False(t, single.CanAccessAllBranches())
+	assert.True(t, single.CanAccessBranch(2))
+	assert.False(t, single.CanAccessBranch(3))
+
+	cross := &User{BranchID: &branchID, Role: &Role{Permissions: json.RawMessage(`["loans.read", "branches.all"]`)}}
+	assert.True(t, cross.CanAccessAllBranches())
+	assert.True(t, cross.CanAccessBranch(3))
+
+	unassigned := &User{Role: &Role{Permissions: json.RawMessage(`["loans.read"]`)}}
+	assert.False(t, unassigned.CanAccessBranch(2))
+}
+
 func TestUser_ToPublic(t *testing.T) {
 	branchID := int64(5)
 	u := &User{
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// AccountingHandler handles accounting endpoints
+type AccountingHandler struct {
+	accountingService *service.AccountingService
+	periodService     *service.AccountingPeriodService
+}
+
+// NewAccountingHandler creates a new AccountingHandler
+func NewAccountingHandler(accountingService *service.AccountingService, periodService *service.AccountingPeriodService) *AccountingHandler {
+	return &AccountingHandler{accountingService: accountingService, periodService: periodService}
+}
+
+// Export exports posted accounting entries for a date range
+func (h *AccountingHandler) Export(c *fiber.Ctx) error {
+	input := service.AccountingExportInput{
+		Format:   c.Query("format", service.AccountingExportFormatCSV),
+		DateFrom: c.Query("from", time.Now().AddDate(0, -1, 0).Format("2006-01-02")),
+		DateTo:   c.Query("to", time.Now().Format("2006-01-02")),
+	}
+	if branchID := c.QueryInt("branch_id", 0); branchID > 0 {
+		id := int64(branchID)
+		input.BranchID = &id
+	}
+
+	export, err := h.accountingService.Export(c.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExportFormat) || errors.Is(err, service.ErrInvalidDateRange) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	c.Set("Content-Type", export.ContentType)
+	c.Set("Content-Disposition", "attachment; filename="+export.Filename)
+	return c.Send(export.Data)
+}
+
+// ReconcileInventory compares a branch's inventory account to the value of
+// its stock on hand at the end of a day
+func (h *AccountingHandler) ReconcileInventory(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	branchID := int64(c.QueryInt("branch_id", 0))
+	if branchID == 0 && user.BranchID != nil {
+		branchID = *user.BranchID
+	}
+	if branchID == 0 {
+		return response.BadRequest(c, "branch_id is required")
+	}
+	if !user.CanAccessBranch(branchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+
+	day, err := time.Parse("2006-01-02", c.Query("as_of", time.Now().Format("2006-01-02")))
+	if err != nil {
+		return response.BadRequest(c, "Invalid as_of date, expected YYYY-MM-DD")
+	}
+	asOf := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	reconciliation, err := h.accountingService.ReconcileInventory(c.Context(), branchID, asOf)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, reconciliation)
+}
+
+// GetTrialBalance returns the posted debits and credits of every account as
+// of the end of a day, as JSON or as a PDF with format=pdf
+func (h *AccountingHandler) GetTrialBalance(c *fiber.Ctx) error {
+	// Users limited to their branch only see its books
+	user := middleware.GetUser(c)
+	var branchID *int64
+	if id := c.QueryInt("branch_id", 0); id > 0 {
+		value := int64(id)
+		branchID = &value
+	} else if !user.CanAccessAllBranches() {
+		branchID = user.BranchID
+	}
+
+	day, err := time.Parse("2006-01-02", c.Query("as_of", time.Now().Format("2006-01-02")))
+	if err != nil {
+		return response.BadRequest(c, "Invalid as_of date, expected YYYY-MM-DD")
+	}
+	asOf := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	if c.Query("format") == "pdf" {
+		data, err := h.accountingService.GetTrialBalancePDF(c.Context(), branchID, asOf)
+		if err != nil {
+			return response.InternalErrorWithErr(c, err)
+		}
+		c.Set("Content-Type", "application/pdf")
+		c.Set("Content-Disposition", "attachment; filename=trial_balance_"+day.Format("2006-01-02")+".pdf")
+		return c.Send(data)
+	}
+
+	trialBalance, err := h.accountingService.GetTrialBalance(c.Context(), branchID, asOf)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, trialBalance)
+}
+
+// ListPeriods lists accounting periods
+func (h *AccountingHandler) ListPeriods(c *fiber.Ctx) error {
+	var branchID *int64
+	if id := c.QueryInt("branch_id", 0); id > 0 {
+		value := int64(id)
+		branchID = &value
+	}
+
+	periods, err := h.periodService.List(c.Context(), branchID)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, periods)
+}
+
+// GenerateAccruals books the period-end accruals of a branch's period
+func (h *AccountingHandler) GenerateAccruals(c *fiber.Ctx) error {
+	var input service.AccrualInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+	user := middleware.GetUser(c)
+	if !user.CanAccessBranch(input.BranchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+	input.UserID = user.ID
+
+	period, err := h.periodService.GenerateAccruals(c.Context(), input)
+	if err != nil {
+		return periodError(c, err)
+	}
+
+	return response.OK(c, period)
+}
+
+// ClosePeriod closes a branch's accounting period
+func (h *AccountingHandler) ClosePeriod(c *fiber.Ctx) error {
+	var input service.ClosePeriodInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+	user := middleware.GetUser(c)
+	if !user.CanAccessBranch(input.BranchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+	input.UserID = user.ID
+
+	period, err := h.periodService.ClosePeriod(c.Context(), input)
+	if err != nil {
+		return periodError(c, err)
+	}
+
+	return response.OK(c, period)
+}
+
+// periodError maps accounting period errors to responses
+func periodError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAccountingPeriod):
+		return response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrAccountingPeriodClosed):
+		return response.Conflict(c, err.Error())
+	case errors.Is(err, service.ErrInventoryNotReconciled):
+		return response.Error(c, fiber.StatusUnprocessableEntity, "INVENTORY_VARIANCE", err.Error())
+	}
+	return response.InternalErrorWithErr(c, err)
+}
+
+// RegisterRoutes registers accounting routes
+func (h *AccountingHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	accounting := app.Group("/accounting")
+	accounting.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
+
+	accounting.Get("/export", authMiddleware.RequirePermission("reports.export"), h.Export)
+	accounting.Get("/inventory/reconciliation", authMiddleware.RequirePermission("reports.read"), h.ReconcileInventory)
+	accounting.Get("/trial-balance", authMiddleware.RequirePermission("reports.read"), h.GetTrialBalance)
+	accounting.Get("/periods", authMiddleware.RequirePermission("reports.read"), h.ListPeriods)
+	accounting.Post("/periods/accruals", authMiddleware.RequirePermission("accounting.close"), h.GenerateAccruals)
+	accounting.Post("/periods/close", authMiddleware.RequirePermission("accounting.close"), h.ClosePeriod)
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"pawnshop/internal/middleware"
 	"pawnshop/internal/repository"
@@ -43,15 +45,19 @@ func (h *AuditHandler) List(c *fiber.Ctx) error {
 		params.EntityID = &id
 	}
 	params.Action = c.Query("action")
-	params.EntityType = c.Query("entity_type")
+	params.EntityType = c.Query("entity_type", c.Query("entity"))
 
-	if dateFrom := c.Query("date_from"); dateFrom != "" {
+	if dateFrom := c.Query("date_from", c.Query("from")); dateFrom != "" {
 		params.DateFrom = &dateFrom
 	}
-	if dateTo := c.Query("date_to"); dateTo != "" {
+	if dateTo := c.Query("date_to", c.Query("to")); dateTo != "" {
 		params.DateTo = &dateTo
 	}
 
+	if format := c.Query("format"); format != "" {
+		return h.export(c, params, format)
+	}
+
 	result, err := h.auditService.List(c.Context(), params)
 	if err != nil {
 		return response.InternalErrorWithErr(c, err)
@@ -60,6 +66,21 @@ func (h *AuditHandler) List(c *fiber.Ctx) error {
 	return response.Paginated(c, result.Data, result.Page, result.PerPage, result.Total)
 }
 
+// export sends every audit log matching the filters as a CSV or JSON file
+func (h *AuditHandler) export(c *fiber.Ctx, params repository.AuditLogListParams, format string) error {
+	export, err := h.auditService.Export(c.Context(), params, format)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAuditExportFormat) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	c.Set("Content-Type", export.ContentType)
+	c.Set("Content-Disposition", "attachment; filename="+export.Filename)
+	return c.Send(export.Data)
+}
+
 // GetStats retrieves audit statistics
 func (h *AuditHandler) GetStats(c *fiber.Ctx) error {
 	params := repository.AuditLogListParams{}
@@ -88,7 +109,7 @@ func (h *AuditHandler) GetStats(c *fiber.Ctx) error {
 // RegisterRoutes registers audit log routes
 func (h *AuditHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	audit := app.Group("/audit")
-	audit.Use(authMiddleware.Authenticate())
+	audit.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	audit.Get("/", authMiddleware.RequirePermission("audit.read"), h.List)
 	audit.Get("/stats", authMiddleware.RequirePermission("audit.read"), h.GetStats)
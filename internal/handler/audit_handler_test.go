@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+	"pawnshop/internal/service"
+)
+
+func TestAuditHandler_List_FiltersByEntity(t *testing.T) {
+	app := fiber.New()
+	auditRepo := new(mocks.MockAuditLogRepository)
+	h := NewAuditHandler(service.NewAuditService(auditRepo))
+
+	auditRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.AuditLogListParams) bool {
+		return p.EntityType == "loan" && p.DateFrom != nil && *p.DateFrom == "2026-01-01"
+	})).Return(&repository.PaginatedResult[domain.AuditLog]{
+		Data: []domain.AuditLog{
+			{ID: 1, Action: "create", EntityType: "loan"},
+			{ID: 2, Action: "update", EntityType: "loan"},
+		},
+		Total:      2,
+		Page:       1,
+		PerPage:    50,
+		TotalPages: 1,
+	}, nil)
+
+	app.Get("/api/v1/audit", h.List)
+
+	req := httptest.NewRequest("GET", "/api/v1/audit?entity=loan&from=2026-01-01", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data []domain.AuditLog `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result.Data, 2)
+	for _, log := range result.Data {
+		assert.Equal(t, "loan", log.EntityType)
+	}
+
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAuditHandler_List_InvalidExportFormat(t *testing.T) {
+	app := fiber.New()
+	h := NewAuditHandler(service.NewAuditService(new(mocks.MockAuditLogRepository)))
+
+	app.Get("/api/v1/audit", h.List)
+
+	req := httptest.NewRequest("GET", "/api/v1/audit?format=xml", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 
 	"pawnshop/internal/middleware"
@@ -85,17 +86,40 @@ func (h *BackupHandler) Download(c *fiber.Ctx) error {
 	return c.SendStream(reader)
 }
 
-// Restore restores a database from a backup
+// Restore restores a database from a backup. The body must confirm the
+// restore, since it overwrites the current data. Backups taken before
+// checksums were stored are restored only with allow_unverified.
 // @Summary Restore database from backup
 // @Tags Backup
+// @Accept json
 // @Param filename path string true "Backup filename"
+// @Param body body object{confirm bool,allow_unverified bool} true "Confirmation"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/admin/backups/{filename}/restore [post]
 func (h *BackupHandler) Restore(c *fiber.Ctx) error {
 	filename := c.Params("filename")
 
-	if err := h.backupService.RestoreBackup(c.Context(), filename); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	var body struct {
+		Confirm         bool `json:"confirm"`
+		AllowUnverified bool `json:"allow_unverified"`
+	}
+	if err := c.BodyParser(&body); err != nil || !body.Confirm {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Restoring a backup overwrites the current data; send {\"confirm\": true} to proceed",
+		})
+	}
+
+	if err := h.backupService.RestoreBackup(c.Context(), filename, body.AllowUnverified); err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidBackupFilename):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, service.ErrBackupNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, service.ErrBackupChecksumMissing), errors.Is(err, service.ErrBackupChecksumMismatch):
+			status = fiber.StatusUnprocessableEntity
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -153,6 +154,9 @@ func (h *BranchHandler) Delete(c *fiber.Ctx) error {
 	originalBranch, _ := h.branchService.GetByID(c.Context(), id)
 
 	if err := h.branchService.Delete(c.Context(), id); err != nil {
+		if errors.Is(err, service.ErrInUse) {
+			return handleServiceError(c, err)
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
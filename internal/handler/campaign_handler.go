@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// CampaignHandler handles campaign endpoints
+type CampaignHandler struct {
+	campaignService *service.CampaignService
+	auditLogger     *middleware.AuditLogger
+}
+
+// NewCampaignHandler creates a new CampaignHandler
+func NewCampaignHandler(campaignService *service.CampaignService, auditLogger *middleware.AuditLogger) *CampaignHandler {
+	return &CampaignHandler{campaignService: campaignService, auditLogger: auditLogger}
+}
+
+// Create handles campaign creation
+func (h *CampaignHandler) Create(c *fiber.Ctx) error {
+	var input service.CreateCampaignInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	campaign, err := h.campaignService.Create(c.Context(), input)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Campaña '%s' creada", campaign.Name)
+		h.auditLogger.LogCreateWithDescription(c, "campaign", campaign.ID, description, campaign)
+	}
+
+	return response.Created(c, campaign)
+}
+
+// GetByID handles getting a campaign by ID
+func (h *CampaignHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid campaign ID format")
+	}
+
+	campaign, err := h.campaignService.GetByID(c.Context(), id)
+	if err != nil {
+		return response.NotFound(c, "Campaign not found")
+	}
+
+	return response.OK(c, campaign)
+}
+
+// List handles listing campaigns, active ones by default
+func (h *CampaignHandler) List(c *fiber.Ctx) error {
+	campaigns, err := h.campaignService.List(c.Context(), c.QueryBool("include_inactive", false))
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, campaigns)
+}
+
+// Update handles campaign update
+func (h *CampaignHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid campaign ID")
+	}
+
+	var input service.UpdateCampaignInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	// Get original campaign for audit
+	originalCampaign, _ := h.campaignService.GetByID(c.Context(), id)
+
+	campaign, err := h.campaignService.Update(c.Context(), id, input)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil && originalCampaign != nil {
+		description := fmt.Sprintf("Campaña '%s' actualizada", campaign.Name)
+		h.auditLogger.LogUpdateWithDescription(c, "campaign", id, description, originalCampaign, campaign)
+	}
+
+	return response.OK(c, campaign)
+}
+
+// Delete handles campaign deletion
+func (h *CampaignHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid campaign ID")
+	}
+
+	// Get original campaign for audit
+	originalCampaign, _ := h.campaignService.GetByID(c.Context(), id)
+
+	if err := h.campaignService.Delete(c.Context(), id); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil && originalCampaign != nil {
+		description := fmt.Sprintf("Campaña '%s' eliminada", originalCampaign.Name)
+		h.auditLogger.LogDeleteWithDescription(c, "campaign", id, description, originalCampaign)
+	}
+
+	return response.NoContent(c)
+}
+
+// FlagCustomer handles adding a customer to a campaign restricted to flagged customers
+func (h *CampaignHandler) FlagCustomer(c *fiber.Ctx) error {
+	id, customerID, err := parseCampaignCustomerParams(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	if err := h.campaignService.FlagCustomer(c.Context(), id, customerID); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Cliente #%d agregado a la campaña", customerID)
+		h.auditLogger.LogCreateWithDescription(c, "campaign", id, description, fiber.Map{"customer_id": customerID})
+	}
+
+	return response.NoContent(c)
+}
+
+// UnflagCustomer handles removing a customer from a campaign
+func (h *CampaignHandler) UnflagCustomer(c *fiber.Ctx) error {
+	id, customerID, err := parseCampaignCustomerParams(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	if err := h.campaignService.UnflagCustomer(c.Context(), id, customerID); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Cliente #%d quitado de la campaña", customerID)
+		h.auditLogger.LogDeleteWithDescription(c, "campaign", id, description, fiber.Map{"customer_id": customerID})
+	}
+
+	return response.NoContent(c)
+}
+
+func parseCampaignCustomerParams(c *fiber.Ctx) (int64, int64, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid campaign ID")
+	}
+	customerID, err := strconv.ParseInt(c.Params("customer_id"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid customer ID")
+	}
+	return id, customerID, nil
+}
+
+// RegisterRoutes registers campaign routes
+func (h *CampaignHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	campaigns := app.Group("/campaigns")
+	campaigns.Use(authMiddleware.Authenticate())
+
+	campaigns.Get("/", authMiddleware.RequirePermission("loans.read"), h.List)
+	campaigns.Post("/", authMiddleware.RequirePermission("settings.update"), h.Create)
+	campaigns.Get("/:id", authMiddleware.RequirePermission("loans.read"), h.GetByID)
+	campaigns.Put("/:id", authMiddleware.RequirePermission("settings.update"), h.Update)
+	campaigns.Delete("/:id", authMiddleware.RequirePermission("settings.update"), h.Delete)
+	campaigns.Post("/:id/customers/:customer_id", authMiddleware.RequirePermission("settings.update"), h.FlagCustomer)
+	campaigns.Delete("/:id/customers/:customer_id", authMiddleware.RequirePermission("settings.update"), h.UnflagCustomer)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -70,12 +71,7 @@ func (h *CashHandler) GetRegister(c *fiber.Ctx) error {
 func (h *CashHandler) ListRegisters(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 
-	var branchID int64
-	if user.BranchID != nil {
-		branchID = *user.BranchID
-	} else if bid := c.Query("branch_id"); bid != "" {
-		branchID, _ = strconv.ParseInt(bid, 10, 64)
-	}
+	branchID := requestBranch(c, user)
 
 	if branchID == 0 {
 		return response.BadRequest(c, "Branch ID is required")
@@ -134,6 +130,9 @@ func (h *CashHandler) OpenSession(c *fiber.Ctx) error {
 
 	session, err := h.cashService.OpenSession(c.Context(), input)
 	if err != nil {
+		if errors.Is(err, service.ErrRegisterHasOpenSession) {
+			return response.Conflict(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -190,13 +189,8 @@ func (h *CashHandler) ListSessions(c *fiber.Ctx) error {
 		},
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if userID := c.Query("user_id"); userID != "" {
@@ -234,8 +228,10 @@ func (h *CashHandler) CloseSession(c *fiber.Ctx) error {
 	}
 
 	var input struct {
-		ClosingAmount float64 `json:"closing_amount" validate:"gte=0"`
-		ClosingNotes  *string `json:"closing_notes"`
+		ClosingAmount float64                      `json:"closing_amount" validate:"gte=0"`
+		ClosingNotes  *string                      `json:"closing_notes"`
+		Currencies    []service.CashCurrencyAmount `json:"currencies" validate:"dive"`
+		Denominations map[string]int               `json:"denominations"`
 	}
 	if err := c.BodyParser(&input); err != nil {
 		return response.BadRequest(c, "Error parsing request body: "+err.Error())
@@ -250,6 +246,8 @@ func (h *CashHandler) CloseSession(c *fiber.Ctx) error {
 		ClosingAmount: input.ClosingAmount,
 		ClosingNotes:  input.ClosingNotes,
 		ClosedBy:      user.ID,
+		Currencies:    input.Currencies,
+		Denominations: input.Denominations,
 	})
 	if err != nil {
 		return response.BadRequest(c, err.Error())
@@ -269,6 +267,7 @@ func (h *CashHandler) CloseSession(c *fiber.Ctx) error {
 				"closing_amount": session.ClosingAmount,
 				"difference":     session.Difference,
 				"closing_notes":  input.ClosingNotes,
+				"currencies":     session.Currencies,
 			})
 	}
 
@@ -290,6 +289,21 @@ func (h *CashHandler) GetSessionSummary(c *fiber.Ctx) error {
 	return response.OK(c, summary)
 }
 
+// Reconcile handles reconciling a session's cash movements against its payments and sales
+func (h *CashHandler) Reconcile(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid session ID")
+	}
+
+	result, err := h.cashService.Reconcile(c.Context(), id)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, result)
+}
+
 // === Cash Movement Endpoints ===
 
 // CreateMovement handles cash movement creation
@@ -357,13 +371,8 @@ func (h *CashHandler) ListMovements(c *fiber.Ctx) error {
 		},
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if sessionID := c.Query("session_id"); sessionID != "" {
@@ -411,7 +420,7 @@ func (h *CashHandler) ListSessionMovements(c *fiber.Ctx) error {
 // RegisterRoutes registers cash/POS routes
 func (h *CashHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	cash := app.Group("/cash")
-	cash.Use(authMiddleware.Authenticate())
+	cash.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	// Cash registers
 	registers := cash.Group("/registers")
@@ -428,6 +437,7 @@ func (h *CashHandler) RegisterRoutes(app fiber.Router, authMiddleware *middlewar
 	sessions.Get("/:id", authMiddleware.RequirePermission("cash.read"), h.GetSession)
 	sessions.Post("/:id/close", authMiddleware.RequirePermission("cash.update"), h.CloseSession)
 	sessions.Get("/:id/summary", authMiddleware.RequirePermission("cash.read"), h.GetSessionSummary)
+	sessions.Get("/:id/reconcile", authMiddleware.RequirePermission("cash.read"), h.Reconcile)
 	sessions.Get("/:id/movements", authMiddleware.RequirePermission("cash.read"), h.ListSessionMovements)
 
 	// Cash movements
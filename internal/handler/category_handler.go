@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -168,6 +169,9 @@ func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
 	originalCategory, _ := h.categoryService.GetByID(c.Context(), id)
 
 	if err := h.categoryService.Delete(c.Context(), id); err != nil {
+		if errors.Is(err, service.ErrInUse) {
+			return handleServiceError(c, err)
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+	"pawnshop/internal/service"
+)
+
+func TestCategoryHandler_Delete_InUseReturnsConflict(t *testing.T) {
+	app := fiber.New()
+	categoryRepo := new(mocks.MockCategoryRepository)
+	h := NewCategoryHandler(service.NewCategoryService(categoryRepo), nil)
+
+	categoryRepo.On("GetByID", mock.Anything, int64(1)).Return(&domain.Category{ID: 1, Name: "Joyería"}, nil)
+	categoryRepo.On("Delete", mock.Anything, int64(1)).Return(&repository.InUseError{Entity: "category", ReferencedBy: "items"})
+
+	app.Delete("/api/v1/categories/:id", h.Delete)
+
+	req := httptest.NewRequest("DELETE", "/api/v1/categories/1", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, "category is still referenced by items", result["error"])
+
+	categoryRepo.AssertExpectations(t)
+}
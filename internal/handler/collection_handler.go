@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// CollectionHandler handles the overdue loan collections queue endpoints
+type CollectionHandler struct {
+	collectionService *service.CollectionService
+	auditLogger       *middleware.AuditLogger
+}
+
+// NewCollectionHandler creates a new CollectionHandler
+func NewCollectionHandler(collectionService *service.CollectionService, auditLogger *middleware.AuditLogger) *CollectionHandler {
+	return &CollectionHandler{collectionService: collectionService, auditLogger: auditLogger}
+}
+
+// List handles listing the collections queue
+func (h *CollectionHandler) List(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	var params repository.CollectionListParams
+	if err := c.QueryParser(&params); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
+
+	cases, err := h.collectionService.List(c.Context(), params)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, cases)
+}
+
+// GetByID handles getting a collection case with its contact attempts
+func (h *CollectionHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid collection case ID")
+	}
+
+	collectionCase, err := h.collectionService.GetByID(c.Context(), id)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, collectionCase)
+}
+
+// Assign handles assigning a collection case to a staff member
+func (h *CollectionHandler) Assign(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid collection case ID")
+	}
+
+	var input service.AssignCollectionInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	collectionCase, err := h.collectionService.Assign(c.Context(), id, input)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Cobro del préstamo %s asignado al usuario #%d", collectionCase.LoanNumber, input.UserID)
+		h.auditLogger.LogUpdateWithDescription(c, "collection_case", id, description, nil, collectionCase)
+	}
+
+	return response.OK(c, collectionCase)
+}
+
+// UpdateStatus handles moving a collection case along the follow-up
+func (h *CollectionHandler) UpdateStatus(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid collection case ID")
+	}
+
+	var input service.UpdateCollectionStatusInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	collectionCase, err := h.collectionService.UpdateStatus(c.Context(), id, input)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Cobro del préstamo %s cambiado a %s", collectionCase.LoanNumber, collectionCase.Status)
+		h.auditLogger.LogUpdateWithDescription(c, "collection_case", id, description, nil, collectionCase)
+	}
+
+	return response.OK(c, collectionCase)
+}
+
+// LogContact handles recording an attempt to contact the customer
+func (h *CollectionHandler) LogContact(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid collection case ID")
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	var input service.LogCollectionContactInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+	input.ContactedBy = user.ID
+
+	contact, err := h.collectionService.LogContact(c.Context(), id, input)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Created(c, contact)
+}
+
+// GetReport handles the outstanding balance in the queue by age and assignee
+func (h *CollectionHandler) GetReport(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	report, err := h.collectionService.Report(c.Context(), branchScope(c, user))
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, report)
+}
+
+func (h *CollectionHandler) handleError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrCollectionCaseResolved) {
+		return response.Conflict(c, err.Error())
+	}
+	return handleServiceError(c, err)
+}
+
+// RegisterRoutes registers collections queue routes
+func (h *CollectionHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	collections := app.Group("/collections")
+	collections.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
+
+	collections.Get("/", authMiddleware.RequirePermission("loans.read"), h.List)
+	collections.Get("/report", authMiddleware.RequirePermission("reports.read"), h.GetReport)
+	collections.Get("/:id", authMiddleware.RequirePermission("loans.read"), h.GetByID)
+	collections.Put("/:id/assign", authMiddleware.RequirePermission("loans.update"), h.Assign)
+	collections.Put("/:id/status", authMiddleware.RequirePermission("loans.update"), h.UpdateStatus)
+	collections.Post("/:id/contacts", authMiddleware.RequirePermission("loans.update"), h.LogContact)
+}
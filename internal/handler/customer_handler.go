@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -14,13 +15,14 @@ import (
 
 // CustomerHandler handles customer endpoints
 type CustomerHandler struct {
-	customerService *service.CustomerService
-	auditLogger     *middleware.AuditLogger
+	customerService     *service.CustomerService
+	verificationService *service.ContactVerificationService
+	auditLogger         *middleware.AuditLogger
 }
 
 // NewCustomerHandler creates a new CustomerHandler
-func NewCustomerHandler(customerService *service.CustomerService, auditLogger *middleware.AuditLogger) *CustomerHandler {
-	return &CustomerHandler{customerService: customerService, auditLogger: auditLogger}
+func NewCustomerHandler(customerService *service.CustomerService, verificationService *service.ContactVerificationService, auditLogger *middleware.AuditLogger) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService, verificationService: verificationService, auditLogger: auditLogger}
 }
 
 // Create handles customer creation
@@ -77,6 +79,22 @@ func (h *CustomerHandler) GetByID(c *fiber.Ctx) error {
 	return response.OK(c, customer)
 }
 
+// Get360 handles getting the consolidated view of a customer: loans, payments,
+// sales, loyalty, risk and blocks in one response
+func (h *CustomerHandler) Get360(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer ID format")
+	}
+
+	view, err := h.customerService.Get360(c.Context(), id)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, view)
+}
+
 // List handles listing customers
 func (h *CustomerHandler) List(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
@@ -89,15 +107,12 @@ func (h *CustomerHandler) List(c *fiber.Ctx) error {
 			Order:   c.Query("order", "desc"),
 		},
 		Search: c.Query("search"),
+		Phone:  c.Query("phone"),
+		Email:  c.Query("email"),
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if isActive := c.Query("is_active"); isActive != "" {
@@ -176,6 +191,9 @@ func (h *CustomerHandler) Delete(c *fiber.Ctx) error {
 	customer, _ := h.customerService.GetByID(c.Context(), id)
 
 	if err := h.customerService.Delete(c.Context(), id); err != nil {
+		if errors.Is(err, service.ErrInUse) {
+			return handleServiceError(c, err)
+		}
 		return response.NotFound(c, "Customer not found")
 	}
 
@@ -270,16 +288,89 @@ func (h *CustomerHandler) Unblock(c *fiber.Ctx) error {
 	return response.OK(c, fiber.Map{"message": "Customer unblocked successfully"})
 }
 
+// RequestVerification sends a new verification code to the customer's phone
+func (h *CustomerHandler) RequestVerification(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer ID format")
+	}
+
+	var input struct {
+		Channel string `json:"channel" validate:"omitempty,oneof=sms whatsapp"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return response.BadRequest(c, "Error parsing request body: "+err.Error())
+		}
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	verification, err := h.verificationService.RequestCode(c.Context(), id, input.Channel)
+	if err != nil {
+		if errors.Is(err, service.ErrCustomerNotFound) {
+			return response.NotFound(c, "Customer not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, verification)
+}
+
+// ConfirmVerification confirms the code sent to the customer's phone
+func (h *CustomerHandler) ConfirmVerification(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer ID format")
+	}
+
+	var input struct {
+		Code string `json:"code" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	customer, err := h.verificationService.ConfirmCode(c.Context(), id, input.Code)
+	if err != nil {
+		if errors.Is(err, service.ErrCustomerNotFound) {
+			return response.NotFound(c, "Customer not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Teléfono del cliente '%s %s' verificado por %s", customer.FirstName, customer.LastName, customer.PhoneVerifiedChannel)
+		h.auditLogger.LogCustomAction(c, "verify_phone", "customer", id, description, nil,
+			fiber.Map{
+				"phone":                  customer.Phone,
+				"phone_verified_channel": customer.PhoneVerifiedChannel,
+			})
+	}
+
+	return response.OK(c, customer)
+}
+
 // RegisterRoutes registers customer routes
 func (h *CustomerHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	customers := app.Group("/customers")
-	customers.Use(authMiddleware.Authenticate())
+	customers.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	customers.Get("/", authMiddleware.RequirePermission("customers.read"), h.List)
 	customers.Post("/", authMiddleware.RequirePermission("customers.create"), h.Create)
-	customers.Get("/:id", authMiddleware.RequirePermission("customers.read"), h.GetByID)
+	customers.Get("/:id", authMiddleware.RequirePermission("customers.read"), middleware.ETag(), h.GetByID)
+	customers.Get("/:id/360", authMiddleware.RequirePermission("customers.read"), middleware.ETag(), h.Get360)
 	customers.Put("/:id", authMiddleware.RequirePermission("customers.update"), h.Update)
 	customers.Delete("/:id", authMiddleware.RequirePermission("customers.delete"), h.Delete)
 	customers.Post("/:id/block", authMiddleware.RequirePermission("customers.update"), h.Block)
 	customers.Post("/:id/unblock", authMiddleware.RequirePermission("customers.update"), h.Unblock)
+	customers.Post("/:id/verification", authMiddleware.RequirePermission("customers.update"), h.RequestVerification)
+	customers.Post("/:id/verification/confirm", authMiddleware.RequirePermission("customers.update"), h.ConfirmVerification)
 }
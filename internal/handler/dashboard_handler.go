@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+)
+
+// DashboardHandler handles dashboard endpoints
+type DashboardHandler struct {
+	alertService *service.DashboardAlertService
+}
+
+// NewDashboardHandler creates a new DashboardHandler
+func NewDashboardHandler(alertService *service.DashboardAlertService) *DashboardHandler {
+	return &DashboardHandler{alertService: alertService}
+}
+
+// GetAlerts retrieves the dashboard alert flags
+func (h *DashboardHandler) GetAlerts(c *fiber.Ctx) error {
+	branchID := c.QueryInt("branch_id", 0)
+
+	alerts, err := h.alertService.GetAlerts(c.Context(), int64(branchID))
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, alerts)
+}
+
+// RegisterRoutes registers dashboard routes
+func (h *DashboardHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	dashboard := app.Group("/dashboard")
+	dashboard.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
+
+	dashboard.Get("/alerts", authMiddleware.RequirePermission("reports.read"), h.GetAlerts)
+}
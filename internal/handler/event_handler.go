@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// EventHandler handles activity feed and webhook endpoints
+type EventHandler struct {
+	eventService *service.EventService
+	auditLogger  *middleware.AuditLogger
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(eventService *service.EventService, auditLogger *middleware.AuditLogger) *EventHandler {
+	return &EventHandler{eventService: eventService, auditLogger: auditLogger}
+}
+
+// ListEvents handles listing the activity feed
+func (h *EventHandler) ListEvents(c *fiber.Ctx) error {
+	var params repository.EventListParams
+	if err := c.QueryParser(&params); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	events, err := h.eventService.ListEvents(c.Context(), params)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, events)
+}
+
+// ListWebhooks handles listing webhooks
+func (h *EventHandler) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.eventService.ListWebhooks(c.Context())
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, webhooks)
+}
+
+// CreateWebhook handles subscribing an endpoint to events
+func (h *EventHandler) CreateWebhook(c *fiber.Ctx) error {
+	var input service.CreateWebhookInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	webhook, err := h.eventService.CreateWebhook(c.Context(), input, user.ID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Webhook %s creado: %s", webhook.Name, webhook.URL)
+		h.auditLogger.LogCreateWithDescription(c, "webhook", webhook.ID, description, webhook)
+	}
+
+	return response.Created(c, webhook)
+}
+
+// DeleteWebhook handles webhook deletion
+func (h *EventHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid webhook ID")
+	}
+
+	if err := h.eventService.DeleteWebhook(c.Context(), id); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Webhook #%d eliminado", id)
+		h.auditLogger.LogDeleteWithDescription(c, "webhook", id, description, nil)
+	}
+
+	return response.NoContent(c)
+}
+
+// RegisterRoutes registers activity feed and webhook routes
+func (h *EventHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	events := app.Group("/events")
+	events.Use(authMiddleware.Authenticate())
+
+	events.Get("/", authMiddleware.RequirePermission("audit.read"), h.ListEvents)
+
+	webhooks := app.Group("/webhooks")
+	webhooks.Use(authMiddleware.Authenticate())
+
+	webhooks.Get("/", authMiddleware.RequirePermission("settings.read"), h.ListWebhooks)
+	webhooks.Post("/", authMiddleware.RequirePermission("settings.update"), h.CreateWebhook)
+	webhooks.Delete("/:id", authMiddleware.RequirePermission("settings.update"), h.DeleteWebhook)
+}
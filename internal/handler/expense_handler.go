@@ -461,6 +461,46 @@ func (h *ExpenseHandler) GetTotalByBranchAndDate(c *fiber.Ctx) error {
 	})
 }
 
+// SuggestCategory suggests an expense category from its description
+// @Summary Suggest an expense category
+// @Tags Expenses
+// @Produce json
+// @Param description query string true "Expense description"
+// @Param amount query number false "Expense amount"
+// @Param branch_id query int false "Branch ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/expenses/category-suggestion [get]
+func (h *ExpenseHandler) SuggestCategory(c *fiber.Ctx) error {
+	description := c.Query("description")
+	if description == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "description is required",
+		})
+	}
+
+	amount, err := strconv.ParseFloat(c.Query("amount", "0"), 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid amount format",
+		})
+	}
+
+	var branchID *int64
+	if id := c.QueryInt("branch_id", 0); id > 0 {
+		bid := int64(id)
+		branchID = &bid
+	}
+
+	suggestion, err := h.expenseService.SuggestCategory(c.Context(), description, amount, branchID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"suggestion": suggestion,
+	})
+}
+
 // RegisterRoutes registers expense routes
 func (h *ExpenseHandler) RegisterRoutes(router fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	// Expense categories
@@ -473,9 +513,10 @@ func (h *ExpenseHandler) RegisterRoutes(router fiber.Router, authMiddleware *mid
 
 	// Expenses
 	expenses := router.Group("/expenses")
-	expenses.Use(authMiddleware.Authenticate())
+	expenses.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 	expenses.Post("/", authMiddleware.RequirePermission("expenses:create"), h.Create)
 	expenses.Get("/", authMiddleware.RequirePermission("expenses:read"), h.List)
+	expenses.Get("/category-suggestion", authMiddleware.RequirePermission("expenses:create"), h.SuggestCategory)
 	expenses.Get("/:id", authMiddleware.RequirePermission("expenses:read"), h.GetByID)
 	expenses.Put("/:id", authMiddleware.RequirePermission("expenses:update"), h.Update)
 	expenses.Delete("/:id", authMiddleware.RequirePermission("expenses:delete"), h.Delete)
@@ -483,7 +524,7 @@ func (h *ExpenseHandler) RegisterRoutes(router fiber.Router, authMiddleware *mid
 
 	// Branch-specific expense routes
 	branchExpenses := router.Group("/branches/:branch_id/expenses")
-	branchExpenses.Use(authMiddleware.Authenticate())
+	branchExpenses.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 	branchExpenses.Get("/", authMiddleware.RequirePermission("expenses:read"), h.ListByBranch)
 	branchExpenses.Get("/total", authMiddleware.RequirePermission("expenses:read"), h.GetTotalByBranchAndDate)
 }
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// ExportHandler handles background CSV export endpoints
+type ExportHandler struct {
+	exportService *service.ExportService
+	auditLogger   *middleware.AuditLogger
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(exportService *service.ExportService, auditLogger *middleware.AuditLogger) *ExportHandler {
+	return &ExportHandler{exportService: exportService, auditLogger: auditLogger}
+}
+
+// Create handles requesting an export. The worker writes the file; the job's
+// progress is polled until it is completed.
+func (h *ExportHandler) Create(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	var input service.CreateExportInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	// Users without branches.all only export their own branch
+	input.BranchID = branchScope(c, user)
+	input.CreatedBy = user.ID
+
+	job, err := h.exportService.Create(c.Context(), input)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Exportación de %s solicitada", job.Dataset)
+		h.auditLogger.LogCreateWithDescription(c, "export_job", job.ID, description, job)
+	}
+
+	return response.Accepted(c, job)
+}
+
+// GetByID handles getting an export's progress
+func (h *ExportHandler) GetByID(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid export job ID")
+	}
+
+	job, err := h.exportService.GetByID(c.Context(), id, user.ID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, job)
+}
+
+// Download handles downloading a completed export
+func (h *ExportHandler) Download(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid export job ID")
+	}
+
+	job, err := h.exportService.GetByID(c.Context(), id, user.ID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	path, err := h.exportService.FilePath(job)
+	if err != nil {
+		if errors.Is(err, service.ErrExportNotReady) {
+			return response.Conflict(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return c.Download(path, job.FileName)
+}
+
+// RegisterRoutes registers export routes
+func (h *ExportHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	exports := app.Group("/exports")
+	exports.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
+
+	exports.Post("/", authMiddleware.RequirePermission("reports.export"), h.Create)
+	exports.Get("/:id", authMiddleware.RequirePermission("reports.export"), h.GetByID)
+	exports.Get("/:id/download", authMiddleware.RequirePermission("reports.export"), h.Download)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// FXHandler handles exchange rate and base-currency report endpoints
+type FXHandler struct {
+	fxService         *service.FXService
+	comparisonService *service.BranchComparisonService
+	auditLogger       *middleware.AuditLogger
+}
+
+// NewFXHandler creates a new FXHandler
+func NewFXHandler(fxService *service.FXService, comparisonService *service.BranchComparisonService, auditLogger *middleware.AuditLogger) *FXHandler {
+	return &FXHandler{fxService: fxService, comparisonService: comparisonService, auditLogger: auditLogger}
+}
+
+// ListRates handles listing exchange rates
+func (h *FXHandler) ListRates(c *fiber.Ctx) error {
+	var params repository.FXRateListParams
+	if err := c.QueryParser(&params); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	rates, err := h.fxService.ListRates(c.Context(), params)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, rates)
+}
+
+// SetRate handles recording the exchange rate of a currency pair on a day
+func (h *FXHandler) SetRate(c *fiber.Ctx) error {
+	var input service.SetFXRateInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	rate, err := h.fxService.SetRate(c.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFXRate) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Tipo de cambio %s/%s del %s: %.6f", rate.FromCurrency, rate.ToCurrency, rate.RateDate, rate.Rate)
+		h.auditLogger.LogCreateWithDescription(c, "fx_rate", rate.ID, description, rate)
+	}
+
+	return response.OK(c, rate)
+}
+
+// DeleteRate handles exchange rate deletion
+func (h *FXHandler) DeleteRate(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid exchange rate ID")
+	}
+
+	if err := h.fxService.DeleteRate(c.Context(), id); err != nil {
+		return response.NotFound(c, "Exchange rate not found")
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Tipo de cambio #%d eliminado", id)
+		h.auditLogger.LogDeleteWithDescription(c, "fx_rate", id, description, nil)
+	}
+
+	return response.NoContent(c)
+}
+
+// GetBranchComparison compares branches with native and base-currency totals
+func (h *FXHandler) GetBranchComparison(c *fiber.Ctx) error {
+	var params service.BranchComparisonParams
+	if err := c.QueryParser(&params); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+	if params.DateFrom == "" {
+		params.DateFrom = time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+	}
+	if params.DateTo == "" {
+		params.DateTo = time.Now().Format("2006-01-02")
+	}
+
+	report, err := h.comparisonService.Compare(c.Context(), params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) ||
+			errors.Is(err, service.ErrInvalidInput) ||
+			errors.Is(err, service.ErrFXRateNotFound) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, report)
+}
+
+// RegisterRoutes registers exchange rate routes
+func (h *FXHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	rates := app.Group("/fx-rates")
+	rates.Use(authMiddleware.Authenticate())
+
+	rates.Get("/", authMiddleware.RequirePermission("settings.read"), h.ListRates)
+	rates.Post("/", authMiddleware.RequirePermission("settings.update"), h.SetRate)
+	rates.Delete("/:id", authMiddleware.RequirePermission("settings.update"), h.DeleteRate)
+
+	reports := app.Group("/reports")
+	reports.Use(authMiddleware.Authenticate())
+
+	reports.Get("/branch-comparison", authMiddleware.RequirePermission("reports.read"), h.GetBranchComparison)
+}
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/middleware"
 	"pawnshop/internal/service"
 )
 
@@ -27,7 +30,10 @@ func handleServiceError(c *fiber.Ctx, err error) error {
 		errors.Is(err, service.ErrRoleNotFound),
 		errors.Is(err, service.ErrSettingNotFound),
 		errors.Is(err, service.ErrTransferNotFound),
-		errors.Is(err, service.ErrExpenseNotFound):
+		errors.Is(err, service.ErrExpenseNotFound),
+		errors.Is(err, service.ErrNoteNotFound),
+		errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, service.ErrStockTakeNotFound):
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -45,17 +51,28 @@ func handleServiceError(c *fiber.Ctx, err error) error {
 	case errors.Is(err, service.ErrInvalidInput),
 		errors.Is(err, service.ErrInvalidStatus),
 		errors.Is(err, service.ErrInvalidAmount),
-		errors.Is(err, service.ErrInsufficientFunds):
+		errors.Is(err, service.ErrInsufficientFunds),
+		errors.Is(err, service.ErrInvalidNoteEntity),
+		errors.Is(err, service.ErrNoteParentMismatch),
+		errors.Is(err, service.ErrInvalidWebhook),
+		errors.Is(err, service.ErrStockTakeClosed):
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 
 	case errors.Is(err, service.ErrDuplicateEntry),
-		errors.Is(err, service.ErrConflict):
+		errors.Is(err, service.ErrConflict),
+		errors.Is(err, service.ErrStockTakeInProgress),
+		errors.Is(err, service.ErrInUse):
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 
+	case errors.Is(err, service.ErrStorageQuotaExceeded):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+
 	default:
 		// Check for common error message patterns
 		errMsg := err.Error()
@@ -83,3 +100,26 @@ func handleServiceError(c *fiber.Ctx, err error) error {
 		})
 	}
 }
+
+// branchScope returns the branch a list request is limited to, 0 meaning every
+// branch. Users without the branches.all permission always get their own branch,
+// whatever branch_id they asked for.
+func branchScope(c *fiber.Ctx, user *domain.User) int64 {
+	if user.BranchID != nil && !user.CanAccessAllBranches() {
+		return *user.BranchID
+	}
+	id, _ := strconv.ParseInt(c.Query(middleware.BranchQueryParam), 10, 64)
+	return id
+}
+
+// requestBranch is branchScope for endpoints working on a single branch: when no
+// branch is requested it falls back to the user's own
+func requestBranch(c *fiber.Ctx, user *domain.User) int64 {
+	if branchID := branchScope(c, user); branchID != 0 {
+		return branchID
+	}
+	if user.BranchID != nil {
+		return *user.BranchID
+	}
+	return 0
+}
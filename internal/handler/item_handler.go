@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"pawnshop/internal/domain"
@@ -104,13 +105,8 @@ func (h *ItemHandler) List(c *fiber.Ctx) error {
 		Search: c.Query("search"),
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if categoryID := c.Query("category_id"); categoryID != "" {
@@ -125,6 +121,12 @@ func (h *ItemHandler) List(c *fiber.Ctx) error {
 		s := domain.ItemStatus(status)
 		params.Status = &s
 	}
+	if c.QueryBool("include_deleted") {
+		if !user.HasPermission(service.PermissionRestoreItems) {
+			return response.Forbidden(c, "Not allowed to list deleted items")
+		}
+		params.IncludeDeleted = true
+	}
 
 	result, err := h.itemService.List(c.Context(), params)
 	if err != nil {
@@ -212,6 +214,32 @@ func (h *ItemHandler) Delete(c *fiber.Ctx) error {
 	return response.NoContent(c)
 }
 
+// Restore handles bringing back a deleted item
+func (h *ItemHandler) Restore(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid item ID")
+	}
+
+	user := middleware.GetUser(c)
+	item, err := h.itemService.Restore(c.Context(), id, user.ID)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Artículo '%s' (SKU: %s) restaurado", item.Name, item.SKU)
+		h.auditLogger.LogCustomAction(c, "restore", "item", id, description, nil, fiber.Map{
+			"sku":    item.SKU,
+			"name":   item.Name,
+			"status": item.Status,
+		})
+	}
+
+	return response.OK(c, item)
+}
+
 // UpdateStatus handles item status update
 func (h *ItemHandler) UpdateStatus(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -237,6 +265,28 @@ func (h *ItemHandler) UpdateStatus(c *fiber.Ctx) error {
 	return response.OK(c, fiber.Map{"message": "Item status updated successfully"})
 }
 
+// BulkUpdateStatus handles moving several items to a new status at once
+func (h *ItemHandler) BulkUpdateStatus(c *fiber.Ctx) error {
+	var input struct {
+		ItemIDs []int64           `json:"item_ids" validate:"required,min=1"`
+		Status  domain.ItemStatus `json:"status" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	result, err := h.itemService.BulkUpdateStatus(c.Context(), input.ItemIDs, input.Status, middleware.GetUser(c).ID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, result)
+}
+
 // MarkForSale handles marking an item for sale
 func (h *ItemHandler) MarkForSale(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -284,12 +334,7 @@ func (h *ItemHandler) MarkForSale(c *fiber.Ctx) error {
 func (h *ItemHandler) GetForSale(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 
-	var branchID int64
-	if user.BranchID != nil {
-		branchID = *user.BranchID
-	} else if bid := c.Query("branch_id"); bid != "" {
-		branchID, _ = strconv.ParseInt(bid, 10, 64)
-	}
+	branchID := requestBranch(c, user)
 
 	if branchID == 0 {
 		return response.BadRequest(c, "Branch ID is required")
@@ -350,12 +395,7 @@ func (h *ItemHandler) MarkAsDelivered(c *fiber.Ctx) error {
 func (h *ItemHandler) GetPendingDeliveries(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 
-	var branchID int64
-	if user.BranchID != nil {
-		branchID = *user.BranchID
-	} else if bid := c.Query("branch_id"); bid != "" {
-		branchID, _ = strconv.ParseInt(bid, 10, 64)
-	}
+	branchID := requestBranch(c, user)
 
 	if branchID == 0 {
 		return response.BadRequest(c, "Branch ID is required")
@@ -369,20 +409,87 @@ func (h *ItemHandler) GetPendingDeliveries(c *fiber.Ctx) error {
 	return response.OK(c, items)
 }
 
+// GetAttentionQueue handles getting the items needing staff action
+func (h *ItemHandler) GetAttentionQueue(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+
+	branchID := requestBranch(c, user)
+
+	if branchID == 0 {
+		return response.BadRequest(c, "Branch ID is required")
+	}
+
+	queue, err := h.itemService.GetAttentionQueue(c.Context(), branchID, time.Now())
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, queue)
+}
+
+// GetConditionScale handles getting the configured item condition scale
+func (h *ItemHandler) GetConditionScale(c *fiber.Ctx) error {
+	return response.OK(c, h.itemService.GetConditionScale(c.Context()))
+}
+
+// SuggestAppraisal handles suggesting appraised and loan values for an item
+func (h *ItemHandler) SuggestAppraisal(c *fiber.Ctx) error {
+	var input service.ValuationInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	suggestion, err := h.itemService.SuggestAppraisal(c.Context(), input)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.OK(c, suggestion)
+}
+
+// SuggestSalePrice handles suggesting a sale price for an item
+func (h *ItemHandler) SuggestSalePrice(c *fiber.Ctx) error {
+	var input service.ValuationInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	suggestion, err := h.itemService.SuggestSalePrice(c.Context(), input)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.OK(c, suggestion)
+}
+
 // RegisterRoutes registers item routes
 func (h *ItemHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	items := app.Group("/items")
-	items.Use(authMiddleware.Authenticate())
+	items.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	items.Get("/", authMiddleware.RequirePermission("items.read"), h.List)
 	items.Post("/", authMiddleware.RequirePermission("items.create"), h.Create)
 	items.Get("/for-sale", authMiddleware.RequirePermission("items.read"), h.GetForSale)
 	items.Get("/pending-deliveries", authMiddleware.RequirePermission("items.read"), h.GetPendingDeliveries)
+	items.Get("/attention", authMiddleware.RequirePermission("items.read"), h.GetAttentionQueue)
+	items.Get("/conditions", authMiddleware.RequirePermission("items.read"), h.GetConditionScale)
+	items.Post("/suggest-appraisal", authMiddleware.RequirePermission("items.read"), h.SuggestAppraisal)
+	items.Post("/suggest-sale-price", authMiddleware.RequirePermission("items.read"), h.SuggestSalePrice)
+	items.Post("/bulk-status", authMiddleware.RequirePermission("items.update"), h.BulkUpdateStatus)
 	items.Get("/sku/:sku", authMiddleware.RequirePermission("items.read"), h.GetBySKU)
-	items.Get("/:id", authMiddleware.RequirePermission("items.read"), h.GetByID)
+	items.Get("/:id", authMiddleware.RequirePermission("items.read"), middleware.ETag(), h.GetByID)
 	items.Put("/:id", authMiddleware.RequirePermission("items.update"), h.Update)
 	items.Delete("/:id", authMiddleware.RequirePermission("items.delete"), h.Delete)
 	items.Post("/:id/status", authMiddleware.RequirePermission("items.update"), h.UpdateStatus)
+	items.Post("/:id/restore", authMiddleware.RequirePermission(service.PermissionRestoreItems), h.Restore)
 	items.Post("/:id/mark-for-sale", authMiddleware.RequirePermission("items.update"), h.MarkForSale)
 	items.Post("/:id/mark-as-delivered", authMiddleware.RequirePermission("items.update"), h.MarkAsDelivered)
 }
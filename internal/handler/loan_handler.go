@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -17,17 +22,21 @@ import (
 
 // LoanHandler handles loan endpoints
 type LoanHandler struct {
-	loanService *service.LoanService
-	auditLogger *middleware.AuditLogger
-	logger      zerolog.Logger
+	loanService   *service.LoanService
+	reportService *service.ReportService
+	moneyFormat   *service.MoneyFormatService
+	auditLogger   *middleware.AuditLogger
+	logger        zerolog.Logger
 }
 
 // NewLoanHandler creates a new LoanHandler
-func NewLoanHandler(loanService *service.LoanService, auditLogger *middleware.AuditLogger, logger zerolog.Logger) *LoanHandler {
+func NewLoanHandler(loanService *service.LoanService, reportService *service.ReportService, moneyFormat *service.MoneyFormatService, auditLogger *middleware.AuditLogger, logger zerolog.Logger) *LoanHandler {
 	return &LoanHandler{
-		loanService: loanService,
-		auditLogger: auditLogger,
-		logger:      logger.With().Str("handler", "loan").Logger(),
+		loanService:   loanService,
+		reportService: reportService,
+		moneyFormat:   moneyFormat,
+		auditLogger:   auditLogger,
+		logger:        logger.With().Str("handler", "loan").Logger(),
 	}
 }
 
@@ -54,10 +63,31 @@ func (h *LoanHandler) Create(c *fiber.Ctx) error {
 		return response.ValidationError(c, errors)
 	}
 
+	if input.OverrideDailyLimit && !user.HasPermission(service.PermissionOverrideDisbursementLimit) {
+		return response.Forbidden(c, "Not allowed to override the daily cash disbursement limit")
+	}
+	if input.OverrideItemLimit && !user.HasPermission(service.PermissionOverridePawnedItemLimit) {
+		return response.Forbidden(c, "Not allowed to override the customer's pawned item limit")
+	}
+
 	// Service layer handles detailed logging
 	loan, err := h.loanService.Create(c.Context(), input)
 	if err != nil {
 		// Service already logged the error
+		var unmet *service.LoanRequirementsError
+		if errors.As(err, &unmet) {
+			details := make([]response.FieldError, len(unmet.Unmet))
+			for i, requirement := range unmet.Unmet {
+				details[i] = response.FieldError{Field: "requirements", Message: requirement}
+			}
+			return response.ErrorWithDetails(c, fiber.StatusUnprocessableEntity, "REQUIREMENTS_NOT_MET", "Loan requirements not met", details)
+		}
+		if errors.Is(err, service.ErrItemHasActiveLoan) {
+			return response.Conflict(c, err.Error())
+		}
+		if errors.Is(err, service.ErrPawnedItemLimitExceeded) {
+			return response.Error(c, fiber.StatusUnprocessableEntity, "PAWNED_ITEM_LIMIT_REACHED", err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -72,6 +102,9 @@ func (h *LoanHandler) Create(c *fiber.Ctx) error {
 	// Audit log
 	if h.auditLogger != nil {
 		description := fmt.Sprintf("Préstamo #%s creado por Q%.2f a %d días", loan.LoanNumber, input.LoanAmount, input.LoanTermDays)
+		if loan.AuthorizationRequired {
+			description += ". Desembolso pendiente de autorización de un segundo usuario"
+		}
 		h.auditLogger.LogCreateWithDescription(c, "loan", loan.ID, description, fiber.Map{
 			"loan_number":   loan.LoanNumber,
 			"customer_id":   input.CustomerID,
@@ -107,6 +140,67 @@ func (h *LoanHandler) Calculate(c *fiber.Ctx) error {
 	return response.OK(c, result)
 }
 
+// Quote handles quoting a loan's cost without creating it
+func (h *LoanHandler) Quote(c *fiber.Ctx) error {
+	var input service.LoanQuoteInput
+	if err := c.QueryParser(&input); err != nil {
+		return response.BadRequest(c, "Invalid query parameters: "+err.Error())
+	}
+
+	user := middleware.GetUser(c)
+	if input.BranchID == 0 {
+		input.BranchID = requestBranch(c, user)
+	} else if !user.CanAccessBranch(input.BranchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+
+	quote, err := h.loanService.Quote(c.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrBranchNotFound) {
+			return response.NotFound(c, "Branch not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.OK(c, quote)
+}
+
+// GetLimits handles getting the configured loan amount and term bounds
+func (h *LoanHandler) GetLimits(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+
+	branchID := requestBranch(c, user)
+
+	var categoryID *int64
+	if cid := c.Query("category_id"); cid != "" {
+		id, err := strconv.ParseInt(cid, 10, 64)
+		if err != nil {
+			return response.BadRequest(c, "Invalid category ID")
+		}
+		categoryID = &id
+	}
+
+	return response.OK(c, h.loanService.GetLimits(c.Context(), branchID, categoryID))
+}
+
+// GetPawnedItemCapacity handles getting how many items a customer has pawned
+// against the branch's cap
+func (h *LoanHandler) GetPawnedItemCapacity(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+
+	customerID, err := strconv.ParseInt(c.Query("customer_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer ID")
+	}
+
+	capacity, err := h.loanService.GetPawnedItemCapacity(c.Context(), customerID, requestBranch(c, user))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, capacity)
+}
+
 // GetByID handles getting a loan by ID
 func (h *LoanHandler) GetByID(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -119,6 +213,10 @@ func (h *LoanHandler) GetByID(c *fiber.Ctx) error {
 		return response.NotFound(c, "Loan not found")
 	}
 
+	if h.moneyFormat != nil {
+		h.moneyFormat.FormatLoan(c.Context(), loan)
+	}
+
 	return response.OK(c, loan)
 }
 
@@ -131,9 +229,29 @@ func (h *LoanHandler) GetByNumber(c *fiber.Ctx) error {
 		return response.NotFound(c, "Loan not found")
 	}
 
+	if h.moneyFormat != nil {
+		h.moneyFormat.FormatLoan(c.Context(), loan)
+	}
+
 	return response.OK(c, loan)
 }
 
+// Verify handles the public check of a contract QR code. It needs no login:
+// the signed token in the link is what proves the contract is genuine.
+func (h *LoanHandler) Verify(c *fiber.Ctx) error {
+	loanNumber, err := url.PathUnescape(c.Params("number"))
+	if err != nil {
+		return response.NotFound(c, "Loan not found")
+	}
+
+	verification, err := h.loanService.Verify(c.Context(), loanNumber, c.Query("token"))
+	if err != nil {
+		return response.NotFound(c, "Loan not found")
+	}
+
+	return response.OK(c, verification)
+}
+
 // List handles listing loans
 func (h *LoanHandler) List(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
@@ -148,13 +266,8 @@ func (h *LoanHandler) List(c *fiber.Ctx) error {
 		Search: c.Query("search"),
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if customerID := c.Query("customer_id"); customerID != "" {
@@ -175,6 +288,13 @@ func (h *LoanHandler) List(c *fiber.Ctx) error {
 	if dueAfter := c.Query("due_after"); dueAfter != "" {
 		params.DueAfter = &dueAfter
 	}
+	if tags := c.Query("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				params.Tags = append(params.Tags, tag)
+			}
+		}
+	}
 
 	result, err := h.loanService.List(c.Context(), params)
 	if err != nil {
@@ -184,6 +304,29 @@ func (h *LoanHandler) List(c *fiber.Ctx) error {
 	return response.Paginated(c, result.Data, result.Page, result.PerPage, result.Total)
 }
 
+// GetPayoffQuote handles quoting how much pays a loan off on a date, today by default
+func (h *LoanHandler) GetPayoffQuote(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID format")
+	}
+
+	asOf := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		asOf, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return response.BadRequest(c, "Invalid date format, expected YYYY-MM-DD")
+		}
+	}
+
+	quote, err := h.loanService.GetPayoffQuote(c.Context(), id, asOf)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, quote)
+}
+
 // GetPayments handles getting payments for a loan
 func (h *LoanHandler) GetPayments(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -214,6 +357,42 @@ func (h *LoanHandler) GetInstallments(c *fiber.Ctx) error {
 	return response.OK(c, installments)
 }
 
+// GetSchedule handles getting a loan's stored installment schedule
+func (h *LoanHandler) GetSchedule(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID format")
+	}
+
+	schedule, err := h.loanService.GetInstallmentSchedule(c.Context(), id)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, schedule)
+}
+
+// GenerateSchedule handles generating a loan's installment schedule from the
+// number of installments in the query string, replacing the stored one
+func (h *LoanHandler) GenerateSchedule(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID format")
+	}
+
+	numInstallments, err := strconv.Atoi(c.Query("installments"))
+	if err != nil || numInstallments <= 0 {
+		return response.BadRequest(c, "installments must be a positive number")
+	}
+
+	schedule, err := h.loanService.GenerateInstallmentSchedule(c.Context(), id, numInstallments)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, schedule)
+}
+
 // Renew handles loan renewal
 func (h *LoanHandler) Renew(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -238,14 +417,17 @@ func (h *LoanHandler) Renew(c *fiber.Ctx) error {
 
 	loan, err := h.loanService.Renew(c.Context(), input)
 	if err != nil {
+		if errors.Is(err, service.ErrRenewalInterestUnpaid) {
+			return response.Error(c, fiber.StatusUnprocessableEntity, "INTEREST_UNPAID", err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
 	// Audit log
 	if h.auditLogger != nil && originalLoan != nil {
 		description := fmt.Sprintf("Préstamo #%s renovado por %d días", originalLoan.LoanNumber, input.NewTermDays)
-		if input.PayInterest {
-			description += " con pago de interés"
+		if input.InterestPaid > 0 {
+			description += fmt.Sprintf(" con pago de interés de %.2f", input.InterestPaid)
 		}
 		h.auditLogger.LogCustomAction(c, "renew", "loan", id, description,
 			fiber.Map{
@@ -255,7 +437,8 @@ func (h *LoanHandler) Renew(c *fiber.Ctx) error {
 			fiber.Map{
 				"new_due_date":      loan.DueDate,
 				"new_term_days":     input.NewTermDays,
-				"pay_interest":      input.PayInterest,
+				"interest_paid":     input.InterestPaid,
+				"new_loan_number":   loan.LoanNumber,
 				"new_interest_rate": input.NewInterestRate,
 			})
 	}
@@ -306,16 +489,177 @@ func (h *LoanHandler) Confiscate(c *fiber.Ctx) error {
 	return response.OK(c, fiber.Map{"message": "Loan confiscated successfully"})
 }
 
+// WriteOff handles writing off an uncollectible loan
+func (h *LoanHandler) WriteOff(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID")
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	// Get loan before writing it off for audit
+	originalLoan, _ := h.loanService.GetByID(c.Context(), id)
+
+	user := middleware.GetUser(c)
+	writeOff, err := h.loanService.WriteOff(c.Context(), id, input.Reason, user.ID)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	if h.auditLogger != nil && originalLoan != nil {
+		description := fmt.Sprintf("Préstamo #%s castigado por Q%.2f. Motivo: %s", originalLoan.LoanNumber, writeOff.Amount, writeOff.Reason)
+		h.auditLogger.LogCustomAction(c, "write_off", "loan", id, description,
+			fiber.Map{
+				"status":      originalLoan.Status,
+				"loan_number": originalLoan.LoanNumber,
+				"balance":     originalLoan.RemainingBalance(),
+			},
+			fiber.Map{
+				"status":   domain.LoanStatusWrittenOff,
+				"amount":   writeOff.Amount,
+				"reason":   writeOff.Reason,
+				"entry_id": writeOff.EntryID,
+			})
+	}
+
+	return response.OK(c, writeOff)
+}
+
+// AddTag handles tagging a loan
+func (h *LoanHandler) AddTag(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID")
+	}
+
+	var input struct {
+		Tag string `json:"tag"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	loan, err := h.loanService.AddTag(c.Context(), id, input.Tag)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Etiqueta '%s' agregada al préstamo #%s", input.Tag, loan.LoanNumber)
+		h.auditLogger.LogCustomAction(c, "tag", "loan", id, description, nil, fiber.Map{"tags": loan.Tags})
+	}
+
+	return response.OK(c, loan)
+}
+
+// RemoveTag handles removing a tag from a loan
+func (h *LoanHandler) RemoveTag(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID")
+	}
+
+	tag := c.Params("tag")
+	loan, err := h.loanService.RemoveTag(c.Context(), id, tag)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Etiqueta '%s' quitada del préstamo #%s", tag, loan.LoanNumber)
+		h.auditLogger.LogCustomAction(c, "untag", "loan", id, description, nil, fiber.Map{"tags": loan.Tags})
+	}
+
+	return response.OK(c, loan)
+}
+
+// Reinstate handles reversing a confiscation once the customer pays the loan off
+func (h *LoanHandler) Reinstate(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID")
+	}
+
+	user := middleware.GetUser(c)
+	result, err := h.loanService.ReinstateConfiscated(c.Context(), id, user.ID)
+	if err != nil {
+		if errors.Is(err, service.ErrItemAlreadySold) {
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Confiscación del préstamo #%s revertida con pago total de Q%.2f. Artículo devuelto al cliente",
+			result.Loan.LoanNumber, result.Payment.Amount)
+		h.auditLogger.LogCustomAction(c, "reinstate", "loan", id, description,
+			fiber.Map{
+				"status":  domain.LoanStatusConfiscated,
+				"item_id": result.Loan.ItemID,
+			},
+			fiber.Map{
+				"status":         domain.LoanStatusPaid,
+				"payment_id":     result.Payment.ID,
+				"payment_number": result.Payment.PaymentNumber,
+				"amount":         result.Payment.Amount,
+			})
+	}
+
+	return response.OK(c, result)
+}
+
+// Authorize handles a second user co-signing the cash disbursement of a high-value loan
+func (h *LoanHandler) Authorize(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID")
+	}
+
+	user := middleware.GetUser(c)
+	loan, err := h.loanService.AuthorizeDisbursement(c.Context(), id, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLoanNotFound):
+			return response.NotFound(c, "Loan not found")
+		case errors.Is(err, service.ErrSelfAuthorization):
+			return response.Forbidden(c, err.Error())
+		case errors.Is(err, service.ErrLoanNotAwaitingAuthorization):
+			return response.Conflict(c, err.Error())
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Desembolso del préstamo #%s por Q%.2f autorizado por un segundo usuario",
+			loan.LoanNumber, loan.LoanAmount)
+		h.auditLogger.LogCustomAction(c, "authorize", "loan", id, description,
+			fiber.Map{
+				"created_by":    loan.CreatedBy,
+				"authorized_by": nil,
+			},
+			fiber.Map{
+				"created_by":    loan.CreatedBy,
+				"authorized_by": user.ID,
+				"loan_amount":   loan.LoanAmount,
+			})
+	}
+
+	return response.OK(c, loan)
+}
+
 // GetOverdue handles getting overdue loans
 func (h *LoanHandler) GetOverdue(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 
-	var branchID int64
-	if user.BranchID != nil {
-		branchID = *user.BranchID
-	} else if bid := c.Query("branch_id"); bid != "" {
-		branchID, _ = strconv.ParseInt(bid, 10, 64)
-	}
+	branchID := requestBranch(c, user)
 
 	if branchID == 0 {
 		return response.BadRequest(c, "Branch ID is required")
@@ -329,19 +673,70 @@ func (h *LoanHandler) GetOverdue(c *fiber.Ctx) error {
 	return response.OK(c, loans)
 }
 
+// ExportDocuments streams a ZIP archive with the contract, payment receipts and
+// stored documents of a loan
+func (h *LoanHandler) ExportDocuments(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan ID format")
+	}
+
+	loan, err := h.loanService.GetByID(c.Context(), id)
+	if err != nil {
+		return response.NotFound(c, "Loan not found")
+	}
+	if !middleware.GetUser(c).CanAccessBranch(loan.BranchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+
+	bundle, err := h.reportService.GenerateLoanDocumentBundle(c.Context(), id)
+	if err != nil {
+		log := logger.FromContext(c.UserContext(), h.logger)
+		log.Error().Err(err).Int64("loan_id", id).Msg("Failed to generate loan document bundle")
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	// The archive is generated while it is streamed, after the handler has
+	// returned, so the fiber context must not be used from here on
+	ctx := c.UserContext()
+	log := logger.FromContext(ctx, h.logger)
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", "attachment; filename="+bundle.Filename)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := bundle.WriteZip(ctx, w); err != nil {
+			log.Error().Err(err).Int64("loan_id", id).Msg("Failed to write loan document bundle")
+		}
+	})
+	return nil
+}
+
 // RegisterRoutes registers loan routes
 func (h *LoanHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	app.Get("/verify/loans/:number", h.Verify)
+
 	loans := app.Group("/loans")
-	loans.Use(authMiddleware.Authenticate())
+	loans.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	loans.Get("/", authMiddleware.RequirePermission("loans.read"), h.List)
 	loans.Post("/", authMiddleware.RequirePermission("loans.create"), h.Create)
 	loans.Post("/calculate", authMiddleware.RequirePermission("loans.read"), h.Calculate)
 	loans.Get("/overdue", authMiddleware.RequirePermission("loans.read"), h.GetOverdue)
+	loans.Get("/limits", authMiddleware.RequirePermission("loans.read"), h.GetLimits)
+	loans.Get("/pawned-items", authMiddleware.RequirePermission("loans.read"), h.GetPawnedItemCapacity)
+	loans.Get("/quote", authMiddleware.RequirePermission("loans.read"), h.Quote)
 	loans.Get("/number/:number", authMiddleware.RequirePermission("loans.read"), h.GetByNumber)
 	loans.Get("/:id", authMiddleware.RequirePermission("loans.read"), h.GetByID)
 	loans.Get("/:id/payments", authMiddleware.RequirePermission("loans.read"), h.GetPayments)
+	loans.Get("/:id/payoff-quote", authMiddleware.RequirePermission("loans.read"), h.GetPayoffQuote)
 	loans.Get("/:id/installments", authMiddleware.RequirePermission("loans.read"), h.GetInstallments)
+	loans.Get("/:id/schedule", authMiddleware.RequirePermission("loans.read"), h.GetSchedule)
+	loans.Post("/:id/schedule", authMiddleware.RequirePermission("loans.update"), h.GenerateSchedule)
+	loans.Get("/:id/documents.zip", authMiddleware.RequirePermission("reports.export"), h.ExportDocuments)
 	loans.Post("/:id/renew", authMiddleware.RequirePermission("loans.update"), h.Renew)
 	loans.Post("/:id/confiscate", authMiddleware.RequirePermission("loans.update"), h.Confiscate)
+	loans.Post("/:id/reinstate", authMiddleware.RequirePermission("loans.update"), h.Reinstate)
+	loans.Post("/:id/authorize", authMiddleware.RequirePermission("loans.authorize"), h.Authorize)
+	loans.Post("/:id/write-off", authMiddleware.RequirePermission("accounting.write_off"), h.WriteOff)
+	loans.Post("/:id/tags", authMiddleware.RequirePermission("loans.update"), h.AddTag)
+	loans.Delete("/:id/tags/:tag", authMiddleware.RequirePermission("loans.update"), h.RemoveTag)
 }
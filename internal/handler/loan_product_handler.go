@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// LoanProductHandler handles loan product endpoints
+type LoanProductHandler struct {
+	productService *service.LoanProductService
+	auditLogger    *middleware.AuditLogger
+}
+
+// NewLoanProductHandler creates a new LoanProductHandler
+func NewLoanProductHandler(productService *service.LoanProductService, auditLogger *middleware.AuditLogger) *LoanProductHandler {
+	return &LoanProductHandler{productService: productService, auditLogger: auditLogger}
+}
+
+// Create handles loan product creation
+func (h *LoanProductHandler) Create(c *fiber.Ctx) error {
+	var input service.CreateLoanProductInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	product, err := h.productService.Create(c.Context(), input)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Producto de préstamo '%s' creado", product.Name)
+		h.auditLogger.LogCreateWithDescription(c, "loan_product", product.ID, description, product)
+	}
+
+	return response.Created(c, product)
+}
+
+// GetByID handles getting a loan product by ID
+func (h *LoanProductHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan product ID format")
+	}
+
+	product, err := h.productService.GetByID(c.Context(), id)
+	if err != nil {
+		return response.NotFound(c, "Loan product not found")
+	}
+
+	return response.OK(c, product)
+}
+
+// List handles listing loan products, active ones by default for the loan create form
+func (h *LoanProductHandler) List(c *fiber.Ctx) error {
+	products, err := h.productService.List(c.Context(), c.QueryBool("include_inactive", false))
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, products)
+}
+
+// Update handles loan product update
+func (h *LoanProductHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan product ID")
+	}
+
+	var input service.UpdateLoanProductInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	// Get original product for audit
+	originalProduct, _ := h.productService.GetByID(c.Context(), id)
+
+	product, err := h.productService.Update(c.Context(), id, input)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil && originalProduct != nil {
+		description := fmt.Sprintf("Producto de préstamo '%s' actualizado", product.Name)
+		h.auditLogger.LogUpdateWithDescription(c, "loan_product", id, description, originalProduct, product)
+	}
+
+	return response.OK(c, product)
+}
+
+// Delete handles loan product deletion
+func (h *LoanProductHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid loan product ID")
+	}
+
+	// Get original product for audit
+	originalProduct, _ := h.productService.GetByID(c.Context(), id)
+
+	if err := h.productService.Delete(c.Context(), id); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Audit log
+	if h.auditLogger != nil && originalProduct != nil {
+		description := fmt.Sprintf("Producto de préstamo '%s' eliminado", originalProduct.Name)
+		h.auditLogger.LogDeleteWithDescription(c, "loan_product", id, description, originalProduct)
+	}
+
+	return response.NoContent(c)
+}
+
+// RegisterRoutes registers loan product routes
+func (h *LoanProductHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	products := app.Group("/loan-products")
+	products.Use(authMiddleware.Authenticate())
+
+	products.Get("/", authMiddleware.RequirePermission("loans.read"), h.List)
+	products.Post("/", authMiddleware.RequirePermission("settings.update"), h.Create)
+	products.Get("/:id", authMiddleware.RequirePermission("loans.read"), h.GetByID)
+	products.Put("/:id", authMiddleware.RequirePermission("settings.update"), h.Update)
+	products.Delete("/:id", authMiddleware.RequirePermission("settings.update"), h.Delete)
+}
@@ -243,6 +243,41 @@ func (h *LoyaltyHandler) CalculateDiscount(c *fiber.Ctx) error {
 	})
 }
 
+// GetRedeemOptions lists the point increments a customer can redeem
+// @Summary Get loyalty redemption options
+// @Tags Loyalty
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Param branch_id query int false "Branch ID"
+// @Success 200 {object} service.RedeemOptions
+// @Router /api/v1/customers/{customer_id}/loyalty/redeem-options [get]
+func (h *LoyaltyHandler) GetRedeemOptions(c *fiber.Ctx) error {
+	customerID, err := strconv.ParseInt(c.Params("customer_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid customer ID format",
+		})
+	}
+
+	var branchID *int64
+	if raw := c.Query("branch_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid branch ID format",
+			})
+		}
+		branchID = &id
+	}
+
+	options, err := h.loyaltyService.GetRedeemOptions(c.Context(), customerID, branchID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(options)
+}
+
 // RegisterRoutes registers loyalty routes
 func (h *LoyaltyHandler) RegisterRoutes(apiRouter fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	loyalty := apiRouter.Group("/customers/:customer_id/loyalty")
@@ -252,6 +287,7 @@ func (h *LoyaltyHandler) RegisterRoutes(apiRouter fiber.Router, authMiddleware *
 	loyalty.Post("/enroll", authMiddleware.RequirePermission("customers:update"), h.EnrollCustomer)
 	loyalty.Post("/points", authMiddleware.RequirePermission("loyalty:manage"), h.AddPoints)
 	loyalty.Post("/redeem", authMiddleware.RequirePermission("loyalty:manage"), h.RedeemPoints)
+	loyalty.Get("/redeem-options", authMiddleware.RequirePermission("customers:read"), h.GetRedeemOptions)
 	loyalty.Get("/history", authMiddleware.RequirePermission("customers:read"), h.GetPointsHistory)
 	loyalty.Get("/discount", authMiddleware.RequirePermission("customers:read"), h.CalculateDiscount)
 }
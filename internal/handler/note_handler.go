@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// NoteHandler handles internal note endpoints
+type NoteHandler struct {
+	noteService *service.NoteService
+	auditLogger *middleware.AuditLogger
+}
+
+// NewNoteHandler creates a new NoteHandler
+func NewNoteHandler(noteService *service.NoteService, auditLogger *middleware.AuditLogger) *NoteHandler {
+	return &NoteHandler{noteService: noteService, auditLogger: auditLogger}
+}
+
+// List returns a handler listing the notes of an entity type's record
+func (h *NoteHandler) List(entityType domain.NoteEntityType) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		entityID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			return response.BadRequest(c, "Invalid ID")
+		}
+
+		notes, err := h.noteService.List(c.Context(), entityType, entityID)
+		if err != nil {
+			return handleServiceError(c, err)
+		}
+
+		return response.OK(c, notes)
+	}
+}
+
+// Add returns a handler adding a note to an entity type's record
+func (h *NoteHandler) Add(entityType domain.NoteEntityType) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		entityID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			return response.BadRequest(c, "Invalid ID")
+		}
+
+		var input service.AddNoteInput
+		if err := c.BodyParser(&input); err != nil {
+			return response.BadRequest(c, "Error parsing request body: "+err.Error())
+		}
+
+		if errors := validator.Validate(&input); errors != nil {
+			return response.ValidationError(c, errors)
+		}
+
+		user := middleware.GetUser(c)
+		if user == nil {
+			return response.Unauthorized(c, "")
+		}
+
+		note, err := h.noteService.Add(c.Context(), entityType, entityID, input, user)
+		if err != nil {
+			return handleServiceError(c, err)
+		}
+
+		// Audit log
+		if h.auditLogger != nil {
+			description := fmt.Sprintf("Nota agregada a %s #%d", entityType, entityID)
+			h.auditLogger.LogCreateWithDescription(c, "note", note.ID, description, note)
+		}
+
+		return response.Created(c, note)
+	}
+}
+
+// Delete handles note deletion
+func (h *NoteHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid note ID")
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	if err := h.noteService.Delete(c.Context(), id, user); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Nota #%d eliminada", id)
+		h.auditLogger.LogDeleteWithDescription(c, "note", id, description, nil)
+	}
+
+	return response.NoContent(c)
+}
+
+// RegisterRoutes registers note routes. Notes inherit the permissions of the
+// record they are attached to: reading it to list them, updating it to add one.
+func (h *NoteHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	for _, entity := range []struct {
+		entityType domain.NoteEntityType
+		resource   string
+	}{
+		{domain.NoteEntityLoan, "loans"},
+		{domain.NoteEntityItem, "items"},
+		{domain.NoteEntityCustomer, "customers"},
+	} {
+		notes := app.Group("/" + entity.resource + "/:id/notes")
+		notes.Use(authMiddleware.Authenticate())
+
+		notes.Get("/", authMiddleware.RequirePermission(entity.resource+".read"), h.List(entity.entityType))
+		notes.Post("/", authMiddleware.RequirePermission(entity.resource+".update"), h.Add(entity.entityType))
+	}
+
+	notes := app.Group("/notes")
+	notes.Use(authMiddleware.Authenticate())
+
+	notes.Delete("/:id", h.Delete)
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
+	"pawnshop/internal/domain"
 	"pawnshop/internal/middleware"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/service"
@@ -12,14 +14,30 @@ import (
 
 type NotificationHandler struct {
 	notificationService service.NotificationService
+	escalationService   *service.NotificationEscalationService
+	router              *service.NotificationRouter
+	digests             *service.NotificationDigestService
+	delivery            *service.NotificationDeliveryService
 }
 
-func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+func NewNotificationHandler(notificationService service.NotificationService, escalationService *service.NotificationEscalationService, router *service.NotificationRouter) *NotificationHandler {
 	return &NotificationHandler{
 		notificationService: notificationService,
+		escalationService:   escalationService,
+		router:              router,
 	}
 }
 
+// SetDigests enables choosing between real time and digest delivery of internal notifications
+func (h *NotificationHandler) SetDigests(digests *service.NotificationDigestService) {
+	h.digests = digests
+}
+
+// SetDeliveryReceipts enables the webhook providers post delivery receipts to
+func (h *NotificationHandler) SetDeliveryReceipts(delivery *service.NotificationDeliveryService) {
+	h.delivery = delivery
+}
+
 // Template Handlers
 
 // CreateTemplate creates a new notification template
@@ -40,12 +58,26 @@ func (h *NotificationHandler) CreateTemplate(c *fiber.Ctx) error {
 
 	template, err := h.notificationService.CreateTemplate(c.Context(), req)
 	if err != nil {
-		return handleServiceError(c, err)
+		return templateError(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(template)
 }
 
+// templateError responds to a failed template change, listing the offending
+// placeholders when the template uses variables its type does not provide
+func templateError(c *fiber.Ctx, err error) error {
+	var varErr *service.TemplateVariableError
+	if errors.As(err, &varErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":                varErr.Error(),
+			"unknown_placeholders": varErr.Unknown,
+			"allowed_placeholders": varErr.Allowed,
+		})
+	}
+	return handleServiceError(c, err)
+}
+
 // GetTemplateByID retrieves a notification template by ID
 // @Summary Get a notification template by ID
 // @Tags Notifications
@@ -95,7 +127,7 @@ func (h *NotificationHandler) UpdateTemplate(c *fiber.Ctx) error {
 
 	template, err := h.notificationService.UpdateTemplate(c.Context(), id, req)
 	if err != nil {
-		return handleServiceError(c, err)
+		return templateError(c, err)
 	}
 
 	return c.JSON(template)
@@ -298,6 +330,46 @@ func (h *NotificationHandler) ListByCustomer(c *fiber.Ctx) error {
 	})
 }
 
+// ExportCustomerHistory exports every notification sent to a customer in a period
+// @Summary Export a customer's notification history
+// @Tags Notifications
+// @Produce text/csv
+// @Param customer_id path int true "Customer ID"
+// @Param date_from query string false "Start date (YYYY-MM-DD), defaults to a year ago"
+// @Param date_to query string false "End date (YYYY-MM-DD), defaults to today"
+// @Success 200 {file} file
+// @Router /api/v1/customers/{customer_id}/notification-history/export [get]
+func (h *NotificationHandler) ExportCustomerHistory(c *fiber.Ctx) error {
+	customerID, err := strconv.ParseInt(c.Params("customer_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid customer ID format",
+		})
+	}
+
+	from, err := domain.ParseDate(c.Query("date_from", time.Now().AddDate(-1, 0, 0).Format(domain.DateFormat)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_from, expected YYYY-MM-DD",
+		})
+	}
+	to, err := domain.ParseDate(c.Query("date_to", time.Now().Format(domain.DateFormat)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date_to, expected YYYY-MM-DD",
+		})
+	}
+
+	export, err := h.notificationService.ExportCustomerHistory(c.Context(), customerID, from, to)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	c.Set("Content-Type", export.ContentType)
+	c.Set("Content-Disposition", "attachment; filename="+export.Filename)
+	return c.Send(export.Data)
+}
+
 // Cancel cancels a pending notification
 // @Summary Cancel a notification
 // @Tags Notifications
@@ -319,6 +391,39 @@ func (h *NotificationHandler) Cancel(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// DeliveryWebhook receives the delivery receipts a provider posts for the
+// messages it was given, signed in the X-Signature header or the way the
+// provider signs its callbacks
+// @Summary Receive a notification delivery receipt
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/notifications/webhook/{provider} [post]
+func (h *NotificationHandler) DeliveryWebhook(c *fiber.Ctx) error {
+	_, err := h.delivery.HandleReceipt(c.Context(), c.Params("provider"), service.DeliveryWebhookRequest{
+		URL:    c.BaseURL() + c.OriginalURL(),
+		Body:   c.Body(),
+		Header: func(key string) string { return c.Get(key) },
+	})
+	switch {
+	case errors.Is(err, service.ErrUnknownDeliveryProvider):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown provider"})
+	case errors.Is(err, service.ErrInvalidWebhookSignature):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid signature"})
+	case errors.Is(err, service.ErrInvalidDeliveryReceipt):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrNotificationNotFound):
+		// Acknowledge receipts of messages we do not know so the provider stops retrying
+		return c.JSON(fiber.Map{"status": "ignored"})
+	case err != nil:
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
 // Customer Preference Handlers
 
 // GetCustomerPreferences retrieves notification preferences for a customer
@@ -407,6 +512,52 @@ func (h *NotificationHandler) UpdateCustomerPreferences(c *fiber.Ctx) error {
 	return c.JSON(updatedPrefs)
 }
 
+// GetCustomerChannelStatuses retrieves delivery status of a customer's notification channels
+// @Summary Get customer notification channel statuses
+// @Tags Notifications
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Success 200 {array} domain.NotificationChannelStatus
+// @Router /api/v1/customers/{customer_id}/notification-channels [get]
+func (h *NotificationHandler) GetCustomerChannelStatuses(c *fiber.Ctx) error {
+	customerID, err := strconv.ParseInt(c.Params("customer_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid customer ID format",
+		})
+	}
+
+	statuses, err := h.escalationService.ListChannelStatuses(c.Context(), customerID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(statuses)
+}
+
+// VerifyCustomerChannel marks a customer's contact details as verified for a channel,
+// resuming automatic notifications paused after repeated failures
+// @Summary Verify customer notification channel
+// @Tags Notifications
+// @Param customer_id path int true "Customer ID"
+// @Param channel path string true "Channel"
+// @Success 204
+// @Router /api/v1/customers/{customer_id}/notification-channels/{channel}/verify [post]
+func (h *NotificationHandler) VerifyCustomerChannel(c *fiber.Ctx) error {
+	customerID, err := strconv.ParseInt(c.Params("customer_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid customer ID format",
+		})
+	}
+
+	if err := h.escalationService.VerifyChannel(c.Context(), customerID, c.Params("channel")); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 // Internal Notification Handlers
 
 // CreateInternalNotification creates a new internal notification
@@ -542,6 +693,49 @@ func (h *NotificationHandler) GetUnreadCount(c *fiber.Ctx) error {
 	})
 }
 
+// GetMyNotificationPreference retrieves how the current user receives internal notifications
+// @Summary Get my internal notification preference
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} domain.UserNotificationPreference
+// @Router /api/v1/internal-notifications/me/preferences [get]
+func (h *NotificationHandler) GetMyNotificationPreference(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int64)
+
+	pref, err := h.digests.GetPreference(c.Context(), userID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(pref)
+}
+
+// UpdateMyNotificationPreference switches the current user between real time and digest delivery
+// @Summary Update my internal notification preference
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param preference body service.UpdateNotificationPreferenceInput true "Preference"
+// @Success 200 {object} domain.UserNotificationPreference
+// @Router /api/v1/internal-notifications/me/preferences [put]
+func (h *NotificationHandler) UpdateMyNotificationPreference(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int64)
+
+	var input service.UpdateNotificationPreferenceInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	pref, err := h.digests.UpdatePreference(c.Context(), userID, input)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(pref)
+}
+
 // Stats Handlers
 
 // GetStatsByCustomer retrieves notification stats for a customer
@@ -607,8 +801,50 @@ func (h *NotificationHandler) GetStatsByBranch(c *fiber.Ctx) error {
 	return c.JSON(stats)
 }
 
+// Routing Handlers
+
+// GetRoutes retrieves the routing rules of internal events
+// @Summary Get internal notification routing rules
+// @Tags Notifications
+// @Produce json
+// @Success 200 {array} domain.NotificationRoute
+// @Router /api/v1/notifications/routes [get]
+func (h *NotificationHandler) GetRoutes(c *fiber.Ctx) error {
+	return c.JSON(h.router.Routes(c.Context()))
+}
+
+// UpdateRoutes replaces the routing rules of internal events
+// @Summary Update internal notification routing rules
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param routes body []domain.NotificationRoute true "Routing rules"
+// @Success 200 {array} domain.NotificationRoute
+// @Router /api/v1/notifications/routes [put]
+func (h *NotificationHandler) UpdateRoutes(c *fiber.Ctx) error {
+	var routes []domain.NotificationRoute
+	if err := c.BodyParser(&routes); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	updated, err := h.router.SetRoutes(c.Context(), routes)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(updated)
+}
+
 // RegisterRoutes registers notification routes
 func (h *NotificationHandler) RegisterRoutes(router fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	// Provider delivery receipts, authenticated by their signature. Registered
+	// first so the authenticated notification routes do not catch them.
+	if h.delivery != nil {
+		router.Post("/notifications/webhook/:provider", h.DeliveryWebhook)
+	}
+
 	// Notification templates
 	templates := router.Group("/notifications/templates")
 	templates.Use(authMiddleware.Authenticate())
@@ -618,6 +854,12 @@ func (h *NotificationHandler) RegisterRoutes(router fiber.Router, authMiddleware
 	templates.Put("/:id", authMiddleware.RequirePermission("notifications:manage"), h.UpdateTemplate)
 	templates.Delete("/:id", authMiddleware.RequirePermission("notifications:manage"), h.DeleteTemplate)
 
+	// Internal event routing
+	routes := router.Group("/notifications/routes")
+	routes.Use(authMiddleware.Authenticate())
+	routes.Get("/", authMiddleware.RequirePermission("notifications:manage"), h.GetRoutes)
+	routes.Put("/", authMiddleware.RequirePermission("notifications:manage"), h.UpdateRoutes)
+
 	// Notifications
 	notifications := router.Group("/notifications")
 	notifications.Use(authMiddleware.Authenticate())
@@ -635,15 +877,22 @@ func (h *NotificationHandler) RegisterRoutes(router fiber.Router, authMiddleware
 	internalNotifications.Get("/me/unread", h.GetUnreadInternalNotifications)
 	internalNotifications.Get("/me/unread-count", h.GetUnreadCount)
 	internalNotifications.Post("/me/read-all", h.MarkAllInternalNotificationsAsRead)
+	if h.digests != nil {
+		internalNotifications.Get("/me/preferences", h.GetMyNotificationPreference)
+		internalNotifications.Put("/me/preferences", h.UpdateMyNotificationPreference)
+	}
 	internalNotifications.Post("/:id/read", h.MarkInternalNotificationAsRead)
 
 	// Customer notification preferences (nested under customers)
 	customerNotifications := router.Group("/customers/:customer_id")
 	customerNotifications.Use(authMiddleware.Authenticate())
 	customerNotifications.Get("/notifications", authMiddleware.RequirePermission("notifications:read"), h.ListByCustomer)
+	customerNotifications.Get("/notification-history/export", authMiddleware.RequirePermission("notifications:read"), h.ExportCustomerHistory)
 	customerNotifications.Get("/notification-preferences", authMiddleware.RequirePermission("customers:read"), h.GetCustomerPreferences)
 	customerNotifications.Put("/notification-preferences", authMiddleware.RequirePermission("customers:update"), h.UpdateCustomerPreferences)
 	customerNotifications.Get("/notification-stats", authMiddleware.RequirePermission("notifications:read"), h.GetStatsByCustomer)
+	customerNotifications.Get("/notification-channels", authMiddleware.RequirePermission("customers:read"), h.GetCustomerChannelStatuses)
+	customerNotifications.Post("/notification-channels/:channel/verify", authMiddleware.RequirePermission("customers:update"), h.VerifyCustomerChannel)
 
 	// Branch notification stats (nested under branches)
 	branchNotifications := router.Group("/branches/:branch_id")
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -18,19 +21,28 @@ import (
 // PaymentHandler handles payment endpoints
 type PaymentHandler struct {
 	paymentService *service.PaymentService
+	moneyFormat    *service.MoneyFormatService
 	auditLogger    *middleware.AuditLogger
 	logger         zerolog.Logger
 }
 
 // NewPaymentHandler creates a new PaymentHandler
-func NewPaymentHandler(paymentService *service.PaymentService, auditLogger *middleware.AuditLogger, logger zerolog.Logger) *PaymentHandler {
+func NewPaymentHandler(paymentService *service.PaymentService, moneyFormat *service.MoneyFormatService, auditLogger *middleware.AuditLogger, logger zerolog.Logger) *PaymentHandler {
 	return &PaymentHandler{
 		paymentService: paymentService,
+		moneyFormat:    moneyFormat,
 		auditLogger:    auditLogger,
 		logger:         logger.With().Str("handler", "payment").Logger(),
 	}
 }
 
+// IdempotencyKeyHeader carries the client's key for a payment request, so a
+// retried request does not record the payment twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength is the longest idempotency key stored with a payment
+const maxIdempotencyKeyLength = 255
+
 // Create handles payment creation
 func (h *PaymentHandler) Create(c *fiber.Ctx) error {
 	log := logger.FromContext(c.UserContext(), h.logger)
@@ -47,6 +59,10 @@ func (h *PaymentHandler) Create(c *fiber.Ctx) error {
 		input.BranchID = *user.BranchID
 	}
 	input.CreatedBy = user.ID
+	input.IdempotencyKey = strings.TrimSpace(c.Get(IdempotencyKeyHeader))
+	if len(input.IdempotencyKey) > maxIdempotencyKeyLength {
+		return response.BadRequest(c, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+	}
 
 	// Validate
 	if errors := validator.Validate(&input); errors != nil {
@@ -54,13 +70,26 @@ func (h *PaymentHandler) Create(c *fiber.Ctx) error {
 		return response.ValidationError(c, errors)
 	}
 
+	backdated := input.IsBackdated(time.Now())
+	if backdated && !user.HasPermission(service.PermissionBackdatePayment) {
+		return response.Forbidden(c, "Not allowed to record a payment with an earlier date")
+	}
+
 	// Service layer handles detailed logging
 	result, err := h.paymentService.Create(c.Context(), input)
 	if err != nil {
 		// Service already logged the error
+		if errors.Is(err, service.ErrIdempotencyKeyReused) || errors.Is(err, service.ErrIdempotentPaymentNotCompleted) {
+			return response.Conflict(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
+	// A repeated request was already logged and audited when it first ran
+	if result.Replayed {
+		return response.OK(c, result)
+	}
+
 	log.Info().
 		Int64("payment_id", result.Payment.ID).
 		Str("payment_number", result.Payment.PaymentNumber).
@@ -75,13 +104,21 @@ func (h *PaymentHandler) Create(c *fiber.Ctx) error {
 		if result.IsFullyPaid {
 			description += " (préstamo totalmente pagado)"
 		}
-		h.auditLogger.LogCreateWithDescription(c, "payment", result.Payment.ID, description, fiber.Map{
+		details := fiber.Map{
 			"payment_number": result.Payment.PaymentNumber,
 			"loan_id":        input.LoanID,
 			"amount":         input.Amount,
 			"payment_method": input.PaymentMethod,
 			"fully_paid":     result.IsFullyPaid,
-		})
+		}
+		if backdated {
+			description += fmt.Sprintf(" con fecha retroactiva %s (mora revertida Q%.2f)",
+				input.PaymentDate.Format(domain.DateFormat), result.LateFeesRolledBack)
+			details["backdated"] = true
+			details["payment_date"] = input.PaymentDate.Format(domain.DateFormat)
+			details["late_fees_rolled_back"] = result.LateFeesRolledBack
+		}
+		h.auditLogger.LogCreateWithDescription(c, "payment", result.Payment.ID, description, details)
 	}
 
 	return response.Created(c, result)
@@ -99,6 +136,10 @@ func (h *PaymentHandler) GetByID(c *fiber.Ctx) error {
 		return response.NotFound(c, "Payment not found")
 	}
 
+	if h.moneyFormat != nil {
+		h.moneyFormat.FormatPayment(c.Context(), payment)
+	}
+
 	return response.OK(c, payment)
 }
 
@@ -115,13 +156,8 @@ func (h *PaymentHandler) List(c *fiber.Ctx) error {
 		},
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if customerID := c.Query("customer_id"); customerID != "" {
@@ -249,7 +285,7 @@ func (h *PaymentHandler) CalculateMinimum(c *fiber.Ctx) error {
 // RegisterRoutes registers payment routes
 func (h *PaymentHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	payments := app.Group("/payments")
-	payments.Use(authMiddleware.Authenticate())
+	payments.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	payments.Get("/", authMiddleware.RequirePermission("payments.read"), h.List)
 	payments.Post("/", authMiddleware.RequirePermission("payments.create"), h.Create)
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/domain"
 	"pawnshop/internal/middleware"
 	"pawnshop/internal/service"
 	"pawnshop/pkg/response"
@@ -37,10 +39,28 @@ func (h *ReportHandler) GetLoanReport(c *fiber.Ctx) error {
 	branchID := c.QueryInt("branch_id", 0)
 	dateFrom := c.Query("date_from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
 	dateTo := c.Query("date_to", time.Now().Format("2006-01-02"))
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		return response.BadRequest(c, "Invalid as_of date, expected YYYY-MM-DD")
+	}
 
-	report, err := h.reportService.GetLoanReport(c.Context(), int64(branchID), dateFrom, dateTo)
+	report, err := h.reportService.GetLoanReport(c.Context(), int64(branchID), dateFrom, dateTo, asOf)
 	if err != nil {
-		return response.InternalErrorWithErr(c, err)
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, report)
+}
+
+// GetWriteOffReport retrieves the loans written off in a period
+func (h *ReportHandler) GetWriteOffReport(c *fiber.Ctx) error {
+	branchID := c.QueryInt("branch_id", 0)
+	dateFrom := c.Query("date_from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
+	dateTo := c.Query("date_to", time.Now().Format("2006-01-02"))
+
+	report, err := h.reportService.GetWriteOffReport(c.Context(), int64(branchID), dateFrom, dateTo)
+	if err != nil {
+		return handleServiceError(c, err)
 	}
 
 	return response.OK(c, report)
@@ -77,15 +97,90 @@ func (h *ReportHandler) GetSalesReport(c *fiber.Ctx) error {
 // GetOverdueReport retrieves overdue loans report
 func (h *ReportHandler) GetOverdueReport(c *fiber.Ctx) error {
 	branchID := c.QueryInt("branch_id", 0)
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		return response.BadRequest(c, "Invalid as_of date, expected YYYY-MM-DD")
+	}
 
-	report, err := h.reportService.GetOverdueReport(c.Context(), int64(branchID))
+	report, err := h.reportService.GetOverdueReport(c.Context(), int64(branchID), asOf)
 	if err != nil {
-		return response.InternalErrorWithErr(c, err)
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, report)
+}
+
+// GetLoanBalanceReport retrieves the open loan balances, as of a past day when as_of is given
+func (h *ReportHandler) GetLoanBalanceReport(c *fiber.Ctx) error {
+	branchID := c.QueryInt("branch_id", 0)
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		return response.BadRequest(c, "Invalid as_of date, expected YYYY-MM-DD")
+	}
+
+	report, err := h.reportService.GetLoanBalanceReport(c.Context(), int64(branchID), asOf)
+	if err != nil {
+		return handleServiceError(c, err)
 	}
 
 	return response.OK(c, report)
 }
 
+// parseAsOf reads the optional as_of day a report is read from snapshots for
+func parseAsOf(c *fiber.Ctx) (*domain.Date, error) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		return nil, nil
+	}
+	date, err := domain.ParseDate(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// GetUserPerformance retrieves a staff member's activity and commission over a period
+func (h *ReportHandler) GetUserPerformance(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	from, err := domain.ParseDate(c.Query("date_from", time.Now().AddDate(0, -1, 0).Format(domain.DateFormat)))
+	if err != nil {
+		return response.BadRequest(c, "Invalid date_from, expected YYYY-MM-DD")
+	}
+	to, err := domain.ParseDate(c.Query("date_to", time.Now().Format(domain.DateFormat)))
+	if err != nil {
+		return response.BadRequest(c, "Invalid date_to, expected YYYY-MM-DD")
+	}
+
+	performance, err := h.reportService.GetUserPerformance(c.Context(), userID, from, to)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Users without branches.all only see the staff of their own branch, and
+	// only when the figures hold no activity from a branch they cannot access
+	if !user.CanAccessAllBranches() {
+		if performance.BranchID == nil || !user.CanAccessBranch(*performance.BranchID) {
+			return response.Forbidden(c, "Cannot view the performance of users from another branch")
+		}
+		for _, branch := range performance.Branches {
+			if !user.CanAccessBranch(branch.BranchID) {
+				return response.Forbidden(c, "Cannot view activity from another branch")
+			}
+		}
+	}
+
+	return response.OK(c, performance)
+}
+
 // ExportDailyReport exports daily report as PDF
 func (h *ReportHandler) ExportDailyReport(c *fiber.Ctx) error {
 	branchID := c.QueryInt("branch_id", 0)
@@ -106,6 +201,52 @@ func (h *ReportHandler) ExportDailyReport(c *fiber.Ctx) error {
 	return c.Send(pdfData)
 }
 
+// ExportLoanReportCSV exports the loans of the loan report as CSV
+func (h *ReportHandler) ExportLoanReportCSV(c *fiber.Ctx) error {
+	branchID := c.QueryInt("branch_id", 0)
+	dateFrom := c.Query("date_from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
+	dateTo := c.Query("date_to", time.Now().Format("2006-01-02"))
+
+	if _, err := time.Parse("2006-01-02", dateFrom); err != nil {
+		return response.BadRequest(c, "Invalid date_from format")
+	}
+	if _, err := time.Parse("2006-01-02", dateTo); err != nil {
+		return response.BadRequest(c, "Invalid date_to format")
+	}
+
+	csvData, err := h.reportService.ExportLoanReportCSV(c.Context(), int64(branchID), dateFrom, dateTo)
+	if err != nil {
+		return response.InternalError(c, "Failed to generate report")
+	}
+
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", "attachment; filename=loan_report_"+dateFrom+"_"+dateTo+".csv")
+	return c.Send(csvData)
+}
+
+// ExportPaymentReportXLSX exports the payment report as an Excel workbook
+func (h *ReportHandler) ExportPaymentReportXLSX(c *fiber.Ctx) error {
+	branchID := c.QueryInt("branch_id", 0)
+	dateFrom := c.Query("date_from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
+	dateTo := c.Query("date_to", time.Now().Format("2006-01-02"))
+
+	if _, err := time.Parse("2006-01-02", dateFrom); err != nil {
+		return response.BadRequest(c, "Invalid date_from format")
+	}
+	if _, err := time.Parse("2006-01-02", dateTo); err != nil {
+		return response.BadRequest(c, "Invalid date_to format")
+	}
+
+	xlsxData, err := h.reportService.ExportPaymentReportXLSX(c.Context(), int64(branchID), dateFrom, dateTo)
+	if err != nil {
+		return response.InternalError(c, "Failed to generate report")
+	}
+
+	c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Set("Content-Disposition", "attachment; filename=payment_report_"+dateFrom+"_"+dateTo+".xlsx")
+	return c.Send(xlsxData)
+}
+
 // ExportLoanContract exports loan contract as PDF
 func (h *ReportHandler) ExportLoanContract(c *fiber.Ctx) error {
 	loanID, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -157,23 +298,98 @@ func (h *ReportHandler) ExportSaleReceipt(c *fiber.Ctx) error {
 	return c.Send(pdfData)
 }
 
+// RegenerateDocument regenerates a document from the current data and returns the PDF.
+// With ?overwrite=true the stored copy is replaced as well.
+func (h *ReportHandler) RegenerateDocument(c *fiber.Ctx) error {
+	referenceID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid reference ID format")
+	}
+
+	result, err := h.reportService.RegenerateDocument(c.Context(), domain.DocumentType(c.Params("type")), referenceID, service.RegenerateDocumentOptions{
+		Overwrite: c.QueryBool("overwrite", false),
+		CreatedBy: middleware.GetUserID(c),
+	})
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	if result.Document != nil {
+		c.Set("X-Document-ID", strconv.FormatInt(result.Document.ID, 10))
+	}
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", "attachment; filename="+result.Filename)
+	return c.Send(result.Data)
+}
+
+// BackfillDailyBalancesRequest is the body of a daily balance backfill
+type BackfillDailyBalancesRequest struct {
+	BranchID int64       `json:"branch_id"`
+	DateFrom domain.Date `json:"date_from"`
+	DateTo   domain.Date `json:"date_to"`
+}
+
+// BackfillDailyBalances recomputes and stores the daily balances of a branch for a date range
+func (h *ReportHandler) BackfillDailyBalances(c *fiber.Ctx) error {
+	var req BackfillDailyBalancesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if req.DateFrom.IsZero() || req.DateTo.IsZero() {
+		return response.BadRequest(c, "date_from and date_to are required")
+	}
+
+	user := middleware.GetUser(c)
+	if req.BranchID == 0 {
+		req.BranchID = requestBranch(c, user)
+	} else if !user.CanAccessBranch(req.BranchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+	if req.BranchID == 0 {
+		return response.BadRequest(c, "branch_id is required")
+	}
+
+	result, err := h.reportService.BackfillDailyBalances(c.Context(), req.BranchID, req.DateFrom, req.DateTo)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) || errors.Is(err, service.ErrBackfillRangeTooLong) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, result)
+}
+
 // RegisterRoutes registers report routes
 func (h *ReportHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	reports := app.Group("/reports")
-	reports.Use(authMiddleware.Authenticate())
+	reports.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	// Dashboard
 	reports.Get("/dashboard", authMiddleware.RequirePermission("reports.read"), h.GetDashboard)
 
 	// Reports
 	reports.Get("/loans", authMiddleware.RequirePermission("reports.read"), h.GetLoanReport)
+	reports.Get("/loans/csv", authMiddleware.RequirePermission("reports.export"), h.ExportLoanReportCSV)
+	reports.Get("/write-offs", authMiddleware.RequirePermission("reports.read"), h.GetWriteOffReport)
 	reports.Get("/payments", authMiddleware.RequirePermission("reports.read"), h.GetPaymentReport)
+	reports.Get("/payments/xlsx", authMiddleware.RequirePermission("reports.export"), h.ExportPaymentReportXLSX)
 	reports.Get("/sales", authMiddleware.RequirePermission("reports.read"), h.GetSalesReport)
 	reports.Get("/overdue", authMiddleware.RequirePermission("reports.read"), h.GetOverdueReport)
+	reports.Get("/loan-balances", authMiddleware.RequirePermission("reports.read"), h.GetLoanBalanceReport)
+	reports.Get("/users/:id/performance", authMiddleware.RequirePermission("reports.read"), h.GetUserPerformance)
+
+	// Daily balances
+	reports.Post("/daily-balances/backfill", authMiddleware.RequirePermission("reports.backfill"), h.BackfillDailyBalances)
 
 	// PDF exports
 	reports.Get("/export/daily", authMiddleware.RequirePermission("reports.export"), h.ExportDailyReport)
 	reports.Get("/export/loan/:id/contract", authMiddleware.RequirePermission("reports.export"), h.ExportLoanContract)
 	reports.Get("/export/payment/:id/receipt", authMiddleware.RequirePermission("reports.export"), h.ExportPaymentReceipt)
 	reports.Get("/export/sale/:id/receipt", authMiddleware.RequirePermission("reports.export"), h.ExportSaleReceipt)
+
+	// Document regeneration
+	documents := app.Group("/documents")
+	documents.Use(authMiddleware.Authenticate())
+	documents.Post("/:type/:id/regenerate", authMiddleware.RequirePermission("reports.export"), h.RegenerateDocument)
 }
@@ -169,11 +169,11 @@ func (h *RoleHandler) RegisterRoutes(app fiber.Router, authMiddleware *middlewar
 	roles := app.Group("/roles")
 	roles.Use(authMiddleware.Authenticate())
 
-	roles.Get("/", authMiddleware.RequirePermission("roles.read"), h.List)
+	roles.Get("/", authMiddleware.RequirePermission("roles.read"), middleware.ETag(), h.List)
 	roles.Get("/permissions", authMiddleware.RequirePermission("roles.read"), h.GetPermissions)
 	roles.Post("/", authMiddleware.RequirePermission("roles.create"), h.Create)
 	roles.Get("/name/:name", authMiddleware.RequirePermission("roles.read"), h.GetByName)
-	roles.Get("/:id", authMiddleware.RequirePermission("roles.read"), h.GetByID)
+	roles.Get("/:id", authMiddleware.RequirePermission("roles.read"), middleware.ETag(), h.GetByID)
 	roles.Put("/:id", authMiddleware.RequirePermission("roles.update"), h.Update)
 	roles.Delete("/:id", authMiddleware.RequirePermission("roles.delete"), h.Delete)
 }
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -45,6 +46,9 @@ func (h *SaleHandler) Create(c *fiber.Ctx) error {
 
 	result, err := h.saleService.Create(c.Context(), input)
 	if err != nil {
+		if errors.Is(err, service.ErrRoundTripNoteRequired) {
+			return response.Error(c, fiber.StatusUnprocessableEntity, "NOTE_REQUIRED", err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -105,13 +109,8 @@ func (h *SaleHandler) List(c *fiber.Ctx) error {
 		},
 	}
 
-	// Filter by user's branch if not admin
-	if user.BranchID != nil {
-		params.BranchID = *user.BranchID
-	} else if branchID := c.Query("branch_id"); branchID != "" {
-		id, _ := strconv.ParseInt(branchID, 10, 64)
-		params.BranchID = id
-	}
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
 
 	// Parse optional filters
 	if customerID := c.Query("customer_id"); customerID != "" {
@@ -149,8 +148,10 @@ func (h *SaleHandler) Refund(c *fiber.Ctx) error {
 	}
 
 	var input struct {
-		RefundAmount float64 `json:"refund_amount" validate:"required,gt=0"`
-		Reason       string  `json:"reason" validate:"required"`
+		RefundAmount  float64           `json:"refund_amount" validate:"required,gt=0"`
+		Reason        string            `json:"reason" validate:"required"`
+		ItemStatus    domain.ItemStatus `json:"item_status" validate:"omitempty,oneof=available for_sale"`
+		CashSessionID *int64            `json:"cash_session_id"`
 	}
 	if err := c.BodyParser(&input); err != nil {
 		return response.BadRequest(c, "Error parsing request body: "+err.Error())
@@ -165,27 +166,39 @@ func (h *SaleHandler) Refund(c *fiber.Ctx) error {
 
 	user := middleware.GetUser(c)
 	sale, err := h.saleService.Refund(c.Context(), service.RefundSaleInput{
-		SaleID:       id,
-		RefundAmount: input.RefundAmount,
-		Reason:       input.Reason,
-		RefundedBy:   user.ID,
+		SaleID:        id,
+		RefundAmount:  input.RefundAmount,
+		Reason:        input.Reason,
+		ItemStatus:    input.ItemStatus,
+		CashSessionID: input.CashSessionID,
+		RefundedBy:    user.ID,
 	})
 	if err != nil {
+		if errors.Is(err, service.ErrRefundOutsideWarranty) {
+			return response.Error(c, fiber.StatusUnprocessableEntity, "OUTSIDE_WARRANTY", err.Error())
+		}
+		if errors.Is(err, service.ErrSaleAlreadyRefunded) {
+			return response.Conflict(c, err.Error())
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
 	// Audit log
 	if h.auditLogger != nil && originalSale != nil {
 		description := fmt.Sprintf("Venta #%s reembolsada por Q%.2f. Razón: %s", originalSale.SaleNumber, input.RefundAmount, input.Reason)
+		if sale.RefundOutsideWarranty {
+			description += " (fuera del período de garantía)"
+		}
 		h.auditLogger.LogCustomAction(c, "refund", "sale", id, description,
 			fiber.Map{
 				"final_price": originalSale.FinalPrice,
 				"status":      originalSale.Status,
 			},
 			fiber.Map{
-				"refund_amount": input.RefundAmount,
-				"refund_reason": input.Reason,
-				"refunded_at":   "now",
+				"refund_amount":           input.RefundAmount,
+				"refund_reason":           input.Reason,
+				"refunded_at":             "now",
+				"refund_outside_warranty": sale.RefundOutsideWarranty,
 			})
 	}
 
@@ -196,12 +209,7 @@ func (h *SaleHandler) Refund(c *fiber.Ctx) error {
 func (h *SaleHandler) GetSummary(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 
-	var branchID int64
-	if user.BranchID != nil {
-		branchID = *user.BranchID
-	} else if bid := c.Query("branch_id"); bid != "" {
-		branchID, _ = strconv.ParseInt(bid, 10, 64)
-	}
+	branchID := requestBranch(c, user)
 
 	if branchID == 0 {
 		return response.BadRequest(c, "Branch ID is required")
@@ -225,7 +233,7 @@ func (h *SaleHandler) GetSummary(c *fiber.Ctx) error {
 // RegisterRoutes registers sale routes
 func (h *SaleHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	sales := app.Group("/sales")
-	sales.Use(authMiddleware.Authenticate())
+	sales.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
 
 	sales.Get("/", authMiddleware.RequirePermission("sales.read"), h.List)
 	sales.Post("/", authMiddleware.RequirePermission("sales.create"), h.Create)
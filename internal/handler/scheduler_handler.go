@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+)
+
+// SchedulerHandler handles worker scheduler endpoints
+type SchedulerHandler struct {
+	jobMonitor *service.JobMonitorService
+}
+
+// NewSchedulerHandler creates a new SchedulerHandler
+func NewSchedulerHandler(jobMonitor *service.JobMonitorService) *SchedulerHandler {
+	return &SchedulerHandler{jobMonitor: jobMonitor}
+}
+
+// Status handles listing the last run of every scheduler job, flagging stale critical jobs
+func (h *SchedulerHandler) Status(c *fiber.Ctx) error {
+	statuses, err := h.jobMonitor.Status(c.Context())
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, statuses)
+}
+
+// RegisterRoutes registers scheduler routes
+func (h *SchedulerHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	scheduler := app.Group("/scheduler")
+	scheduler.Use(authMiddleware.Authenticate())
+
+	scheduler.Get("/status", authMiddleware.RequirePermission("settings.read"), h.Status)
+}
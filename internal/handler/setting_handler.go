@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"pawnshop/internal/middleware"
 	"pawnshop/internal/service"
@@ -67,7 +71,7 @@ func (h *SettingHandler) Set(c *fiber.Ctx) error {
 		return response.ValidationError(c, errors)
 	}
 
-	setting, err := h.settingService.Set(c.Context(), input)
+	setting, err := h.settingService.Set(c.UserContext(), input)
 	if err != nil {
 		return response.BadRequest(c, err.Error())
 	}
@@ -92,7 +96,7 @@ func (h *SettingHandler) SetMultiple(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.settingService.SetMultiple(c.Context(), inputs); err != nil {
+	if err := h.settingService.SetMultiple(c.UserContext(), inputs); err != nil {
 		return response.BadRequest(c, err.Error())
 	}
 
@@ -104,23 +108,78 @@ func (h *SettingHandler) Delete(c *fiber.Ctx) error {
 	key := c.Params("key")
 	branchID := getBranchIDFromQuery(c)
 
-	if err := h.settingService.Delete(c.Context(), key, branchID); err != nil {
+	if err := h.settingService.Delete(c.UserContext(), key, branchID); err != nil {
 		return response.BadRequest(c, err.Error())
 	}
 
 	return response.NoContent(c)
 }
 
+// History lists the latest changes to a setting, newest first
+func (h *SettingHandler) History(c *fiber.Ctx) error {
+	key := c.Params("key")
+	branchID := getBranchIDFromQuery(c)
+
+	history, err := h.settingService.History(c.Context(), key, branchID, c.QueryInt("limit", service.DefaultSettingHistoryLimit))
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, history)
+}
+
+// Export downloads the global settings as a versioned JSON file
+func (h *SettingHandler) Export(c *fiber.Ctx) error {
+	export, err := h.settingService.Export(c.Context())
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	filename := fmt.Sprintf("settings_%s.json", export.ExportedAt.Format("20060102_150405"))
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+	return c.JSON(export)
+}
+
+// Import applies a settings file exported from another environment
+func (h *SettingHandler) Import(c *fiber.Ctx) error {
+	if len(c.Body()) == 0 {
+		return response.BadRequest(c, "No settings file provided")
+	}
+
+	result, err := h.settingService.Import(c.UserContext(), c.Body(), c.Query("mode", service.SettingsImportModeMerge))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSettingsFile) || errors.Is(err, service.ErrInvalidImportMode) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Configuración importada (%s): %d actualizadas, %d eliminadas", result.Mode, result.Updated, len(result.Deleted))
+		h.auditLogger.LogCustomAction(c, "import", "setting", 0, description, nil, fiber.Map{
+			"mode":        result.Mode,
+			"updated":     result.Updated,
+			"deleted":     result.Deleted,
+			"imported_at": time.Now(),
+		})
+	}
+
+	return response.OK(c, result)
+}
+
 // RegisterRoutes registers setting routes
 func (h *SettingHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	settings := app.Group("/settings")
 	settings.Use(authMiddleware.Authenticate())
 
-	settings.Get("/", authMiddleware.RequirePermission("settings.read"), h.List)
-	settings.Get("/merged", authMiddleware.RequirePermission("settings.read"), h.GetMerged)
+	settings.Get("/", authMiddleware.RequirePermission("settings.read"), middleware.ETag(), h.List)
+	settings.Get("/merged", authMiddleware.RequirePermission("settings.read"), middleware.ETag(), h.GetMerged)
 	settings.Post("/", authMiddleware.RequirePermission("settings.update"), h.Set)
 	settings.Post("/bulk", authMiddleware.RequirePermission("settings.update"), h.SetMultiple)
-	settings.Get("/:key", authMiddleware.RequirePermission("settings.read"), h.Get)
+	settings.Get("/export", authMiddleware.RequireAnyRole("super_admin", "admin"), authMiddleware.RequirePermission("settings.read"), h.Export)
+	settings.Post("/import", authMiddleware.RequireAnyRole("super_admin", "admin"), authMiddleware.RequirePermission("settings.update"), h.Import)
+	settings.Get("/:key", authMiddleware.RequirePermission("settings.read"), middleware.ETag(), h.Get)
+	settings.Get("/:key/history", authMiddleware.RequirePermission("settings.read"), h.History)
 	settings.Delete("/:key", authMiddleware.RequirePermission("settings.update"), h.Delete)
 }
 
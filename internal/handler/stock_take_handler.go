@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/middleware"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/service"
+	"pawnshop/pkg/response"
+	"pawnshop/pkg/validator"
+)
+
+// StockTakeHandler handles physical inventory count endpoints
+type StockTakeHandler struct {
+	stockTakeService *service.StockTakeService
+	auditLogger      *middleware.AuditLogger
+}
+
+// NewStockTakeHandler creates a new StockTakeHandler
+func NewStockTakeHandler(stockTakeService *service.StockTakeService, auditLogger *middleware.AuditLogger) *StockTakeHandler {
+	return &StockTakeHandler{stockTakeService: stockTakeService, auditLogger: auditLogger}
+}
+
+// List handles listing stock takes
+func (h *StockTakeHandler) List(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	var params repository.StockTakeListParams
+	if err := c.QueryParser(&params); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	// Users without branches.all only see their own branch
+	params.BranchID = branchScope(c, user)
+
+	stockTakes, err := h.stockTakeService.List(c.Context(), params)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, stockTakes)
+}
+
+// GetByID handles getting a stock take
+func (h *StockTakeHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stock take ID")
+	}
+
+	stockTake, err := h.stockTakeService.GetByID(c.Context(), id)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, stockTake)
+}
+
+// Start handles starting a count of a branch
+func (h *StockTakeHandler) Start(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	var input service.StartStockTakeInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+	if input.BranchID == 0 && user.BranchID != nil {
+		input.BranchID = *user.BranchID
+	}
+	input.StartedBy = user.ID
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+
+	stockTake, err := h.stockTakeService.Start(c.Context(), input)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil {
+		description := fmt.Sprintf("Inventario físico iniciado en sucursal #%d", stockTake.BranchID)
+		h.auditLogger.LogCreateWithDescription(c, "stock_take", stockTake.ID, description, stockTake)
+	}
+
+	return response.Created(c, stockTake)
+}
+
+// RecordScan handles recording a scanned item SKU
+func (h *StockTakeHandler) RecordScan(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stock take ID")
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	var input service.RecordScanInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Error parsing request body: "+err.Error())
+	}
+
+	if errors := validator.Validate(&input); errors != nil {
+		return response.ValidationError(c, errors)
+	}
+	input.ScannedBy = user.ID
+
+	scan, err := h.stockTakeService.RecordScan(c.Context(), id, input)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, scan)
+}
+
+// GetReport handles getting the discrepancy report of a count
+func (h *StockTakeHandler) GetReport(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stock take ID")
+	}
+
+	report, err := h.stockTakeService.Report(c.Context(), id)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, report)
+}
+
+// Finalize handles closing a count and storing its discrepancy report
+func (h *StockTakeHandler) Finalize(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stock take ID")
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	stockTake, err := h.stockTakeService.Finalize(c.Context(), id, user.ID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	// Audit log
+	if h.auditLogger != nil && stockTake.Report != nil {
+		description := fmt.Sprintf("Inventario físico #%d finalizado: %d faltantes, %d sobrantes, %d con estado inconsistente",
+			id, len(stockTake.Report.Missing), len(stockTake.Report.Extra), len(stockTake.Report.StatusMismatch))
+		h.auditLogger.LogUpdateWithDescription(c, "stock_take", id, description, nil, stockTake)
+	}
+
+	return response.OK(c, stockTake)
+}
+
+// Cancel handles abandoning a count
+func (h *StockTakeHandler) Cancel(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stock take ID")
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	if err := h.stockTakeService.Cancel(c.Context(), id, user.ID); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return response.OK(c, fiber.Map{"message": "Stock take cancelled successfully"})
+}
+
+// RegisterRoutes registers stock take routes
+func (h *StockTakeHandler) RegisterRoutes(app fiber.Router, authMiddleware *middleware.AuthMiddleware) {
+	stockTakes := app.Group("/stock-takes")
+	stockTakes.Use(authMiddleware.Authenticate(), authMiddleware.ScopeBranch())
+
+	stockTakes.Get("/", authMiddleware.RequirePermission("items.read"), h.List)
+	stockTakes.Post("/", authMiddleware.RequirePermission("items.update"), h.Start)
+	stockTakes.Get("/:id", authMiddleware.RequirePermission("items.read"), h.GetByID)
+	stockTakes.Post("/:id/scans", authMiddleware.RequirePermission("items.update"), h.RecordScan)
+	stockTakes.Get("/:id/report", authMiddleware.RequirePermission("items.read"), h.GetReport)
+	stockTakes.Post("/:id/finalize", authMiddleware.RequirePermission("items.update"), h.Finalize)
+	stockTakes.Post("/:id/cancel", authMiddleware.RequirePermission("items.update"), h.Cancel)
+}
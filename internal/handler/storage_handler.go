@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"io"
 	"strconv"
 
@@ -13,12 +14,14 @@ import (
 type StorageHandler struct {
 	storageService service.StorageService
 	itemService    *service.ItemService
+	quotaService   *service.StorageQuotaService
 }
 
-func NewStorageHandler(storageService service.StorageService, itemService *service.ItemService) *StorageHandler {
+func NewStorageHandler(storageService service.StorageService, itemService *service.ItemService, quotaService *service.StorageQuotaService) *StorageHandler {
 	return &StorageHandler{
 		storageService: storageService,
 		itemService:    itemService,
+		quotaService:   quotaService,
 	}
 }
 
@@ -38,7 +41,7 @@ func (h *StorageHandler) UploadItemImage(c *fiber.Ctx) error {
 	}
 
 	// Verify item exists
-	_, err = h.itemService.GetByID(c.Context(), itemID)
+	item, err := h.itemService.GetByID(c.Context(), itemID)
 	if err != nil {
 		return response.NotFound(c, "Item not found")
 	}
@@ -49,6 +52,16 @@ func (h *StorageHandler) UploadItemImage(c *fiber.Ctx) error {
 		return response.BadRequest(c, "No image file provided")
 	}
 
+	// Reject uploads that do not fit in the branch's storage quota
+	if err := h.quotaService.CheckUpload(c.Context(), item.BranchID, file.Size); err != nil {
+		if errors.Is(err, service.ErrStorageQuotaExceeded) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return response.InternalErrorWithErr(c, err)
+	}
+
 	// Upload image
 	category := "items"
 	imageInfo, err := h.storageService.UploadImage(c.Context(), file, category)
@@ -64,6 +77,10 @@ func (h *StorageHandler) UploadItemImage(c *fiber.Ctx) error {
 		return response.InternalError(c, "Failed to save photo to item")
 	}
 
+	if err := h.quotaService.RecordUpload(c.Context(), item.BranchID, "item", itemID, imageInfo, userID); err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
 	return response.Created(c, imageInfo)
 }
 
@@ -132,6 +149,7 @@ func (h *StorageHandler) DeleteItemImage(c *fiber.Ctx) error {
 
 	// Try to delete the actual file (ignore errors as the URL removal is the important part)
 	_ = h.storageService.DeleteImage(c.Context(), photoURL)
+	_ = h.quotaService.RecordDeletion(c.Context(), photoURL)
 
 	return response.NoContent(c)
 }
@@ -208,6 +226,91 @@ func (h *StorageHandler) ServeThumbnail(c *fiber.Ctx) error {
 	return c.Send(content)
 }
 
+// ServeDocument serves a stored document such as a loan contract to users
+// with access to the branch that owns it
+// @Summary Serve document
+// @Tags Storage
+// @Produce application/pdf
+// @Param path path string true "Document path"
+// @Success 200 {file} file
+// @Router /storage/documents/{path} [get]
+func (h *StorageHandler) ServeDocument(c *fiber.Ctx) error {
+	path := c.Params("*")
+	if path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid path",
+		})
+	}
+
+	// Documents are only served when their owning branch is known
+	file, err := h.quotaService.GetFile(c.Context(), path)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Document not found",
+		})
+	}
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+	if !user.CanAccessBranch(file.BranchID) {
+		return response.Forbidden(c, "Access to this branch is not allowed")
+	}
+
+	reader, info, err := h.storageService.GetDocument(c.Context(), path)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Document not found",
+		})
+	}
+
+	// Read all content before closing the reader
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read document",
+		})
+	}
+
+	c.Set("Content-Type", info.MimeType)
+	c.Set("Cache-Control", "private, no-store")
+	return c.Send(content)
+}
+
+// GetUsage reports storage used per branch and entity type
+// @Summary Storage usage
+// @Tags Storage
+// @Produce json
+// @Param branch_id query int false "Branch ID"
+// @Success 200 {object} service.StorageUsageReport
+// @Router /api/v1/storage/usage [get]
+func (h *StorageHandler) GetUsage(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return response.Unauthorized(c, "")
+	}
+
+	// Users without branches.all only see their own branch
+	var branchID *int64
+	if user.BranchID != nil && !user.CanAccessAllBranches() {
+		branchID = user.BranchID
+	} else if value := c.Query("branch_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return response.BadRequest(c, "Invalid branch ID")
+		}
+		branchID = &id
+	}
+
+	report, err := h.quotaService.Usage(c.Context(), branchID)
+	if err != nil {
+		return response.InternalErrorWithErr(c, err)
+	}
+
+	return response.OK(c, report)
+}
+
 // RegisterRoutes registers storage routes
 func (h *StorageHandler) RegisterRoutes(app *fiber.App, apiRouter fiber.Router, authMiddleware *middleware.AuthMiddleware) {
 	// Public routes for serving images (no auth required)
@@ -215,10 +318,17 @@ func (h *StorageHandler) RegisterRoutes(app *fiber.App, apiRouter fiber.Router,
 	storage.Get("/images/*", h.ServeImage)
 	storage.Get("/thumbnails/*", h.ServeThumbnail)
 
+	// Stored documents contain customer data and require authentication
+	storage.Get("/documents/*", authMiddleware.Authenticate(), authMiddleware.RequirePermission("loans.read"), h.ServeDocument)
+
 	// Protected routes for managing images
 	items := apiRouter.Group("/items/:item_id/images")
 	items.Use(authMiddleware.Authenticate())
 	items.Post("/", authMiddleware.RequirePermission("items.update"), h.UploadItemImage)
 	items.Get("/", authMiddleware.RequirePermission("items.read"), h.GetItemImages)
 	items.Delete("/", authMiddleware.RequirePermission("items.update"), h.DeleteItemImage)
+
+	usage := apiRouter.Group("/storage")
+	usage.Use(authMiddleware.Authenticate())
+	usage.Get("/usage", authMiddleware.RequirePermission("reports.read"), h.GetUsage)
 }
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+	"pawnshop/internal/service"
+)
+
+func TestStorageHandler_ServeDocument_OtherBranchForbidden(t *testing.T) {
+	app := fiber.New()
+	fileRepo := new(mocks.MockStoredFileRepository)
+	h := NewStorageHandler(nil, nil, service.NewStorageQuotaService(fileRepo, nil, nil))
+
+	fileRepo.On("GetByFileID", mock.Anything, "contracts/contract.pdf").
+		Return(&domain.StoredFile{BranchID: 2, EntityType: "loan", EntityID: 7}, nil)
+
+	branchID := int64(1)
+	app.Get("/storage/documents/*", func(c *fiber.Ctx) error {
+		c.Locals("user", &domain.User{ID: 1, BranchID: &branchID})
+		return c.Next()
+	}, h.ServeDocument)
+
+	req := httptest.NewRequest("GET", "/storage/documents/contracts/contract.pdf", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	fileRepo.AssertExpectations(t)
+}
+
+func TestStorageHandler_ServeDocument_UnknownFileNotFound(t *testing.T) {
+	app := fiber.New()
+	fileRepo := new(mocks.MockStoredFileRepository)
+	h := NewStorageHandler(nil, nil, service.NewStorageQuotaService(fileRepo, nil, nil))
+
+	fileRepo.On("GetByFileID", mock.Anything, "contracts/missing.pdf").
+		Return(nil, assert.AnError)
+
+	app.Get("/storage/documents/*", h.ServeDocument)
+
+	req := httptest.NewRequest("GET", "/storage/documents/contracts/missing.pdf", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
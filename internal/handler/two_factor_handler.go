@@ -76,6 +76,43 @@ func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
 	})
 }
 
+// VerifySetup confirms the authenticator app was set up correctly and enables 2FA
+// @Summary Verify 2FA setup
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Param body body object{code string} true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/verify-setup [post]
+func (h *TwoFactorHandler) VerifySetup(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int64)
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Error parsing request body: " + err.Error(),
+		})
+	}
+
+	if body.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	codes, err := h.twoFactorService.VerifySetup(c.Context(), userID, body.Code)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"backup_codes": codes,
+		"message":      "2FA has been enabled. Please save the backup codes in a secure location.",
+	})
+}
+
 // Disable disables 2FA for the current user
 // @Summary Disable 2FA
 // @Tags Two-Factor Authentication
@@ -273,6 +310,7 @@ func (h *TwoFactorHandler) RegisterRoutes(router fiber.Router, authMiddleware *m
 	twoFactor.Use(authMiddleware.Authenticate())
 	twoFactor.Post("/setup", h.Setup)
 	twoFactor.Post("/enable", h.Enable)
+	twoFactor.Post("/verify-setup", h.VerifySetup)
 	twoFactor.Post("/disable", h.Disable)
 	twoFactor.Get("/status", h.GetStatus)
 	twoFactor.Post("/backup-codes/regenerate", h.RegenerateBackupCodes)
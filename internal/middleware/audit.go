@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"context"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
 	"pawnshop/internal/service"
+	"pawnshop/pkg/logger"
 )
 
 // AuditMiddleware handles audit logging
@@ -31,6 +34,12 @@ func safeGo(fn func(), operation string) {
 	}()
 }
 
+// auditContext detaches audit writes from the request context, which fasthttp
+// recycles once the handler returns, while keeping the request ID
+func auditContext(c *fiber.Ctx) context.Context {
+	return logger.WithRequestID(context.Background(), GetRequestID(c))
+}
+
 // LogAction logs an action to the audit log
 func (m *AuditMiddleware) LogAction(action, entityType string, entityID *int64, oldValues, newValues interface{}) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -48,9 +57,10 @@ func (m *AuditMiddleware) LogAction(action, entityType string, entityID *int64,
 			}
 
 			// Log the action asynchronously to not block the response
+			ctx := auditContext(c)
 			safeGo(func() {
 				m.auditService.LogAction(
-					c.Context(),
+					ctx,
 					branchID,
 					userID,
 					action,
@@ -87,9 +97,10 @@ func (l *AuditLogger) LogCreate(c *fiber.Ctx, entityType string, entityID int64,
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogCreate(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			entityType,
@@ -110,9 +121,10 @@ func (l *AuditLogger) LogUpdate(c *fiber.Ctx, entityType string, entityID int64,
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogUpdate(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			entityType,
@@ -134,9 +146,10 @@ func (l *AuditLogger) LogDelete(c *fiber.Ctx, entityType string, entityID int64,
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogDelete(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			entityType,
@@ -150,9 +163,10 @@ func (l *AuditLogger) LogDelete(c *fiber.Ctx, entityType string, entityID int64,
 
 // LogLogin logs a login action
 func (l *AuditLogger) LogLogin(c *fiber.Ctx, userID int64, branchID *int64) {
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogLogin(
-			c.Context(),
+			ctx,
 			branchID,
 			&userID,
 			c.IP(),
@@ -163,9 +177,10 @@ func (l *AuditLogger) LogLogin(c *fiber.Ctx, userID int64, branchID *int64) {
 
 // LogLogout logs a logout action
 func (l *AuditLogger) LogLogout(c *fiber.Ctx, userID int64, branchID *int64) {
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogLogout(
-			c.Context(),
+			ctx,
 			branchID,
 			&userID,
 			c.IP(),
@@ -183,9 +198,10 @@ func (l *AuditLogger) LogCreateWithDescription(c *fiber.Ctx, entityType string,
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogActionWithDescription(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			"create",
@@ -209,9 +225,10 @@ func (l *AuditLogger) LogUpdateWithDescription(c *fiber.Ctx, entityType string,
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogActionWithDescription(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			"update",
@@ -235,9 +252,10 @@ func (l *AuditLogger) LogDeleteWithDescription(c *fiber.Ctx, entityType string,
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogActionWithDescription(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			"delete",
@@ -261,9 +279,10 @@ func (l *AuditLogger) LogCustomAction(c *fiber.Ctx, action, entityType string, e
 		branchID = user.BranchID
 	}
 
+	ctx := auditContext(c)
 	safeGo(func() {
 		l.auditService.LogActionWithDescription(
-			c.Context(),
+			ctx,
 			branchID,
 			userID,
 			action,
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"pawnshop/internal/domain"
+	"pawnshop/pkg/logger"
+	"pawnshop/pkg/response"
+)
+
+// BranchQueryParam is the query parameter list endpoints filter by branch with
+const BranchQueryParam = "branch_id"
+
+// ScopeBranch keeps users without the branches.all permission inside their own
+// branch. A branch_id query or path parameter naming another branch is rejected,
+// and a missing branch_id query parameter is set to the user's branch, so list
+// endpoints only return that branch's data. It must run after Authenticate.
+func (m *AuthMiddleware) ScopeBranch() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		log := logger.FromContext(c.UserContext(), m.logger)
+
+		user, ok := c.Locals("user").(*domain.User)
+		if !ok || user == nil {
+			return response.Unauthorized(c, "")
+		}
+
+		if user.CanAccessAllBranches() {
+			return c.Next()
+		}
+
+		if user.BranchID == nil {
+			log.Warn().
+				Int64("user_id", user.ID).
+				Str("path", c.Path()).
+				Msg("Branch scope denied: user is not assigned to a branch")
+			return response.Forbidden(c, "User is not assigned to a branch")
+		}
+
+		for _, requested := range []string{c.Query(BranchQueryParam), c.Params(BranchQueryParam)} {
+			if requested == "" {
+				continue
+			}
+			branchID, err := strconv.ParseInt(requested, 10, 64)
+			if err != nil || !user.CanAccessBranch(branchID) {
+				log.Warn().
+					Int64("user_id", user.ID).
+					Int64("user_branch_id", *user.BranchID).
+					Str("requested_branch_id", requested).
+					Str("path", c.Path()).
+					Msg("Branch scope denied: user requested another branch")
+				return response.Forbidden(c, "Access to this branch is not allowed")
+			}
+		}
+
+		c.Request().URI().QueryArgs().Set(BranchQueryParam, strconv.FormatInt(*user.BranchID, 10))
+		return c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+)
+
+func setupBranchScopeApp(user *domain.User) *fiber.App {
+	m := NewAuthMiddleware(nil, nil, nil, zerolog.Nop())
+	setUser := func(c *fiber.Ctx) error {
+		c.Locals("user", user)
+		return c.Next()
+	}
+
+	app := fiber.New()
+	loans := app.Group("/loans", setUser, m.ScopeBranch())
+	loans.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.Query("branch_id"))
+	})
+	branchExpenses := app.Group("/branches/:branch_id/expenses", setUser, m.ScopeBranch())
+	branchExpenses.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.Params("branch_id"))
+	})
+	return app
+}
+
+func branchUser(branchID int64, permissions string) *domain.User {
+	return &domain.User{ID: 1, BranchID: &branchID, Role: &domain.Role{Permissions: json.RawMessage(permissions)}}
+}
+
+func TestScopeBranch_SingleBranchUserScopedToOwnBranch(t *testing.T) {
+	app := setupBranchScopeApp(branchUser(2, `["loans.read"]`))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "2", string(body))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/loans?branch_id=2", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestScopeBranch_SingleBranchUserRejectedForOtherBranch(t *testing.T) {
+	app := setupBranchScopeApp(branchUser(2, `["loans.read"]`))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans?branch_id=3", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/branches/3/expenses", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestScopeBranch_CrossBranchUserNotScoped(t *testing.T) {
+	app := setupBranchScopeApp(branchUser(2, `["loans.read", "branches.all"]`))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "", string(body))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/loans?branch_id=3", nil))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "3", string(body))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/branches/3/expenses", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestScopeBranch_UnassignedUserRejected(t *testing.T) {
+	app := setupBranchScopeApp(&domain.User{ID: 1, Role: &domain.Role{Permissions: json.RawMessage(`["loans.read"]`)}})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
@@ -20,9 +20,9 @@ func DefaultCORSConfig() CORSConfig {
 	return CORSConfig{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID,If-None-Match,Idempotency-Key",
 		AllowCredentials: false,
-		ExposeHeaders:    "Content-Length,Content-Type,X-Request-ID",
+		ExposeHeaders:    "Content-Length,Content-Type,X-Request-ID,ETag",
 		MaxAge:           86400, // 24 hours
 	}
 }
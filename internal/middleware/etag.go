@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag returns a middleware that tags successful GET responses with a hash of
+// their body and answers 304 Not Modified when the client's If-None-Match
+// already carries that tag, so polling clients skip unchanged payloads
+func ETag() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+		body := c.Response().Body()
+		if len(body) == 0 {
+			return nil
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		c.Set(fiber.HeaderETag, etag)
+		// Responses are per user; clients must revalidate before reusing them
+		c.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Context().ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+
+		return nil
+	}
+}
+
+// etagMatches checks an If-None-Match header against an entity tag using the
+// weak comparison required for GET requests
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupETagApp(resource *string) *fiber.App {
+	app := fiber.New()
+	app.Get("/resource", ETag(), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"name": *resource})
+	})
+	app.Put("/resource", ETag(), func(c *fiber.Ctx) error {
+		*resource = string(c.Body())
+		return c.JSON(fiber.Map{"name": *resource})
+	})
+	app.Get("/missing", ETag(), func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not found"})
+	})
+	return app
+}
+
+func getResource(t *testing.T, app *fiber.App, ifNoneMatch string) (int, string, string) {
+	req := httptest.NewRequest("GET", "/resource", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag), string(body)
+}
+
+func TestETag_UnchangedResourceReturnsNotModified(t *testing.T) {
+	resource := "Anillo"
+	app := setupETagApp(&resource)
+
+	status, etag, body := getResource(t, app, "")
+	require.Equal(t, fiber.StatusOK, status)
+	require.NotEmpty(t, etag)
+	assert.Contains(t, body, "Anillo")
+
+	status, revalidated, body := getResource(t, app, etag)
+	assert.Equal(t, fiber.StatusNotModified, status)
+	assert.Equal(t, etag, revalidated)
+	assert.Empty(t, body)
+
+	// Weak validators and lists of tags match as well
+	status, _, _ = getResource(t, app, `"other", W/`+etag)
+	assert.Equal(t, fiber.StatusNotModified, status)
+}
+
+func TestETag_UpdatedResourceReturnsNewETag(t *testing.T) {
+	resource := "Anillo"
+	app := setupETagApp(&resource)
+
+	_, etag, _ := getResource(t, app, "")
+
+	resp, err := app.Test(httptest.NewRequest("PUT", "/resource", strings.NewReader("Collar")))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Header.Get(fiber.HeaderETag))
+
+	status, updated, body := getResource(t, app, etag)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.NotEmpty(t, updated)
+	assert.NotEqual(t, etag, updated)
+	assert.Contains(t, body, "Collar")
+}
+
+func TestETag_SkipsErrorResponses(t *testing.T) {
+	resource := ""
+	app := setupETagApp(&resource)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/missing", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(fiber.HeaderETag))
+}
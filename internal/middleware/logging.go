@@ -4,9 +4,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
-	"pawnshop/pkg/logger"
 )
 
 // LoggingMiddleware handles request logging
@@ -25,15 +23,7 @@ func (m *LoggingMiddleware) Logger() fiber.Handler {
 		start := time.Now()
 
 		// Generate request ID if not present
-		requestID := c.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.New().String()
-		}
-		c.Set("X-Request-ID", requestID)
-
-		// Inject request ID into context for services
-		ctx := logger.WithRequestID(c.Context(), requestID)
-		c.SetUserContext(ctx)
+		requestID := ensureRequestID(c)
 
 		// Process request
 		err := c.Next()
@@ -86,7 +76,7 @@ func (m *LoggingMiddleware) Recovery() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		defer func() {
 			if r := recover(); r != nil {
-				requestID := c.Get("X-Request-ID")
+				requestID := GetRequestID(c)
 
 				m.logger.Error().
 					Str("request_id", requestID).
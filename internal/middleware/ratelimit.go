@@ -14,6 +14,9 @@ type RateLimitConfig struct {
 	Max        int           // Maximum number of requests
 	Window     time.Duration // Time window for rate limiting
 	KeyFunc    func(*fiber.Ctx) string // Function to get the key for rate limiting
+	// TierFunc, when set, picks the key and budget of a request from its
+	// caller. Requests it returns no tier for use KeyFunc and Max.
+	TierFunc func(*fiber.Ctx) (string, *RateLimitTier)
 }
 
 // DefaultRateLimitConfig returns the default rate limit configuration
@@ -62,7 +65,12 @@ func NewRateLimiter(config RateLimitConfig) *RateLimiter {
 // Middleware returns the rate limiting middleware
 func (rl *RateLimiter) Middleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		key := rl.config.KeyFunc(c)
+		key, max := rl.config.KeyFunc(c), rl.config.Max
+		if rl.config.TierFunc != nil {
+			if tierKey, tier := rl.config.TierFunc(c); tier != nil {
+				key, max = tierKey, tier.Max
+			}
+		}
 		now := time.Now()
 
 		// Get or create entry
@@ -82,16 +90,16 @@ func (rl *RateLimiter) Middleware() fiber.Handler {
 		entry.count++
 
 		// Set rate limit headers
-		remaining := rl.config.Max - entry.count
+		remaining := max - entry.count
 		if remaining < 0 {
 			remaining = 0
 		}
-		c.Set("X-RateLimit-Limit", strconv.Itoa(rl.config.Max))
+		c.Set("X-RateLimit-Limit", strconv.Itoa(max))
 		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
 		c.Set("X-RateLimit-Reset", entry.expiresAt.Format(time.RFC3339))
 
 		// Check if limit exceeded
-		if entry.count > rl.config.Max {
+		if entry.count > max {
 			retryAfter := int(entry.expiresAt.Sub(now).Seconds())
 			c.Set("Retry-After", strconv.Itoa(retryAfter))
 			return response.TooManyRequests(c, "")
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+	"pawnshop/pkg/auth"
+)
+
+func setupTieredRateLimitApp(roleRepo *mocks.MockRoleRepository, jwtManager *auth.JWTManager) *fiber.App {
+	config := RateLimitConfig{
+		Max:     2,
+		Window:  time.Minute,
+		KeyFunc: func(c *fiber.Ctx) string { return c.IP() },
+	}
+	config.TierFunc = NewRateLimitTierResolver(RateLimitTiers{
+		Tiers:   map[string]int{"trusted": 5, "staff": 3},
+		Roles:   map[string]string{domain.RoleManager: "staff"},
+		APIKeys: map[string]string{"integration-key": "trusted"},
+	}, jwtManager, roleRepo).Resolve
+
+	app := fiber.New()
+	app.Use(NewRateLimiter(config).Middleware())
+	app.Get("/resource", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+// allowedRequests sends requests until the limiter refuses one and returns how
+// many got through along with the limit advertised on the first one
+func allowedRequests(t *testing.T, app *fiber.App, headers map[string]string) (int, string) {
+	limit := ""
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest("GET", "/resource", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		if i == 0 {
+			limit = resp.Header.Get("X-RateLimit-Limit")
+		}
+		if resp.StatusCode == fiber.StatusTooManyRequests {
+			return i, limit
+		}
+	}
+	return 10, limit
+}
+
+func TestRateLimiter_HighTierAPIKeyGetsLargerBudget(t *testing.T) {
+	app := setupTieredRateLimitApp(new(mocks.MockRoleRepository), auth.NewJWTManager(auth.JWTConfig{Secret: "test", AccessTokenTTL: time.Minute}))
+
+	allowed, limit := allowedRequests(t, app, map[string]string{APIKeyHeader: "integration-key"})
+
+	assert.Equal(t, 5, allowed)
+	assert.Equal(t, "5", limit)
+}
+
+func TestRateLimiter_AnonymousCallerGetsDefaultBudget(t *testing.T) {
+	app := setupTieredRateLimitApp(new(mocks.MockRoleRepository), auth.NewJWTManager(auth.JWTConfig{Secret: "test", AccessTokenTTL: time.Minute}))
+
+	allowed, limit := allowedRequests(t, app, nil)
+	assert.Equal(t, 2, allowed)
+	assert.Equal(t, "2", limit)
+
+	// Unknown keys are anonymous as well and share the caller's budget
+	allowed, _ = allowedRequests(t, app, map[string]string{APIKeyHeader: "made-up-key"})
+	assert.Equal(t, 0, allowed)
+}
+
+func TestRateLimiter_RoleTier(t *testing.T) {
+	roleRepo := new(mocks.MockRoleRepository)
+	roleRepo.On("GetByID", mock.Anything, int64(2)).Return(&domain.Role{ID: 2, Name: domain.RoleManager}, nil).Once()
+	jwtManager := auth.NewJWTManager(auth.JWTConfig{Secret: "test", AccessTokenTTL: time.Minute})
+	token, err := jwtManager.GenerateAccessToken(auth.JWTClaims{UserID: 9, RoleID: 2})
+	require.NoError(t, err)
+	app := setupTieredRateLimitApp(roleRepo, jwtManager)
+
+	allowed, limit := allowedRequests(t, app, map[string]string{"Authorization": "Bearer " + token})
+
+	assert.Equal(t, 3, allowed)
+	assert.Equal(t, "3", limit)
+	// The role name is looked up once
+	roleRepo.AssertExpectations(t)
+}
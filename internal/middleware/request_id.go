@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"pawnshop/pkg/logger"
+)
+
+// RequestIDHeader is the header carrying the request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// MaxRequestIDLength is the longest request ID accepted from a client, the
+// size of the audit_logs.request_id column
+const MaxRequestIDLength = 64
+
+// RequestID returns a middleware that ensures a request ID is present.
+// A missing ID is generated and written back onto the request header so that
+// every later c.Get(RequestIDHeader) sees it, echoed in the response header and
+// stored in the request context for services and repositories.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ensureRequestID(c)
+		return c.Next()
+	}
+}
+
+// GetRequestID returns the request ID for the current request
+func GetRequestID(c *fiber.Ctx) string {
+	if requestID, ok := c.Locals(logger.RequestIDKey).(string); ok {
+		return requestID
+	}
+	return c.Get(RequestIDHeader)
+}
+
+// ensureRequestID assigns a request ID to the request if it has none yet. An
+// incoming ID too long to store is replaced by a generated one.
+func ensureRequestID(c *fiber.Ctx) string {
+	if requestID, ok := c.Locals(logger.RequestIDKey).(string); ok && requestID != "" {
+		return requestID
+	}
+
+	requestID := c.Get(RequestIDHeader)
+	if requestID == "" || len(requestID) > MaxRequestIDLength {
+		requestID = uuid.New().String()
+		c.Request().Header.Set(RequestIDHeader, requestID)
+	}
+	c.Set(RequestIDHeader, requestID)
+
+	// Locals are user values on the fasthttp context, so c.Context() carries the ID too
+	c.Locals(logger.RequestIDKey, requestID)
+	c.SetUserContext(logger.WithRequestID(c.UserContext(), requestID))
+
+	return requestID
+}
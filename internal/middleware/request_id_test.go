@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pawnshop/pkg/logger"
+)
+
+func setupRequestIDApp(logOutput io.Writer) *fiber.App {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Use(NewLoggingMiddleware(zerolog.New(logOutput)).Logger())
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		// Echo what downstream code sees: the request header and the service context
+		return c.SendString(c.Get(RequestIDHeader) + "|" + logger.GetRequestID(c.Context()))
+	})
+	return app
+}
+
+func TestRequestID_GeneratedWhenMissing(t *testing.T) {
+	var logs bytes.Buffer
+	app := setupRequestIDApp(&logs)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+	require.NoError(t, err)
+
+	requestID := resp.Header.Get(RequestIDHeader)
+	require.NotEmpty(t, requestID)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, requestID+"|"+requestID, string(body))
+	assert.Contains(t, logs.String(), `"request_id":"`+requestID+`"`)
+}
+
+func TestRequestID_PreservesIncomingHeader(t *testing.T) {
+	var logs bytes.Buffer
+	app := setupRequestIDApp(&logs)
+
+	req := httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc-123", resp.Header.Get(RequestIDHeader))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "abc-123|abc-123", string(body))
+	assert.Contains(t, logs.String(), `"request_id":"abc-123"`)
+}
+
+func TestRequestID_RegeneratesOverlongHeader(t *testing.T) {
+	var logs bytes.Buffer
+	app := setupRequestIDApp(&logs)
+
+	incoming := strings.Repeat("a", MaxRequestIDLength+1)
+	req := httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set(RequestIDHeader, incoming)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	requestID := resp.Header.Get(RequestIDHeader)
+	assert.NotEqual(t, incoming, requestID)
+	assert.LessOrEqual(t, len(requestID), MaxRequestIDLength)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, requestID+"|"+requestID, string(body))
+}
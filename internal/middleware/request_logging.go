@@ -70,7 +70,7 @@ func RequestLoggingMiddleware(config RequestLoggingConfig) fiber.Handler {
 		}
 
 		start := time.Now()
-		requestID := c.Get("X-Request-ID")
+		requestID := GetRequestID(c)
 
 		// Get user info
 		var userID int64
@@ -31,17 +31,3 @@ func SecurityHeaders() fiber.Handler {
 		return c.Next()
 	}
 }
-
-// RequestID returns a middleware that ensures a request ID is present
-func RequestID() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		requestID := c.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = c.GetRespHeader("X-Request-ID")
-		}
-		if requestID != "" {
-			c.Set("X-Request-ID", requestID)
-		}
-		return c.Next()
-	}
-}
@@ -7,10 +7,12 @@ import (
 
 	"github.com/johnfercher/maroto/v2"
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/config"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	"github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
@@ -23,17 +25,40 @@ type Generator struct {
 	companyName    string
 	companyAddress string
 	companyPhone   string
+	moneyFormat    domain.MoneyFormat
+	verifyURL      string // base URL of the loan verification page
+	verifyKey      []byte // signs the loan verification links
 }
 
-// NewGenerator creates a new PDF generator
-func NewGenerator(companyName, companyAddress, companyPhone string) *Generator {
+// NewGenerator creates a new PDF generator. Loan contracts carry a QR code
+// linking to verifyURL when it is set along with a verification key.
+func NewGenerator(companyName, companyAddress, companyPhone, verifyURL string) *Generator {
 	return &Generator{
 		companyName:    companyName,
 		companyAddress: companyAddress,
 		companyPhone:   companyPhone,
+		moneyFormat:    domain.NewMoneyFormat("", ""),
+		verifyURL:      verifyURL,
 	}
 }
 
+// WithMoneyFormat returns a copy of the generator that writes amounts in the
+// given format, e.g. the currency of the branch a document belongs to
+func (g *Generator) WithMoneyFormat(format domain.MoneyFormat) *Generator {
+	clone := *g
+	clone.moneyFormat = format
+	return &clone
+}
+
+// MoneyFormat returns the format the generator writes amounts in
+func (g *Generator) MoneyFormat() domain.MoneyFormat {
+	return g.moneyFormat
+}
+
+func (g *Generator) money(amount float64) string {
+	return g.moneyFormat.Format(amount)
+}
+
 // GenerateLoanContract generates a loan contract PDF
 func (g *Generator) GenerateLoanContract(loan *domain.Loan, customer *domain.Customer, item *domain.Item) ([]byte, error) {
 	cfg := config.NewBuilder().
@@ -46,8 +71,12 @@ func (g *Generator) GenerateLoanContract(loan *domain.Loan, customer *domain.Cus
 	m := maroto.New(cfg)
 	doc := m.GetStructure()
 
-	// Header
-	g.addHeader(m, "CONTRATO DE EMPEÑO")
+	// Header, with the verification QR code in the top-right corner
+	qrCode, err := g.LoanVerificationQR(loan.LoanNumber)
+	if err != nil {
+		return nil, err
+	}
+	g.addHeaderWithQR(m, "CONTRATO DE EMPEÑO", qrCode)
 
 	// Contract Info
 	m.AddRow(8, text.NewCol(12, fmt.Sprintf("Contrato No: %s", loan.LoanNumber), props.Text{
@@ -92,7 +121,7 @@ func (g *Generator) GenerateLoanContract(loan *domain.Loan, customer *domain.Cus
 		m.AddRow(6, text.NewCol(6, fmt.Sprintf("No. Serie: %s", *item.SerialNumber), props.Text{Size: 10}))
 	}
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Condición: %s", item.Condition), props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Valor Avalúo: $%.2f", item.AppraisedValue), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, "Valor Avalúo: "+g.money(item.AppraisedValue), props.Text{Size: 10}))
 
 	// Loan Details
 	m.AddRow(10)
@@ -102,10 +131,10 @@ func (g *Generator) GenerateLoanContract(loan *domain.Loan, customer *domain.Cus
 		Top:   2,
 	}))
 
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Monto del Préstamo: $%.2f", loan.LoanAmount), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, "Monto del Préstamo: "+g.money(loan.LoanAmount), props.Text{Size: 10, Style: fontstyle.Bold}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Tasa de Interés: %.2f%% mensual", loan.InterestRate), props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Interés: $%.2f", loan.InterestAmount), props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Total a Pagar: $%.2f", loan.TotalAmount), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, "Interés: "+g.money(loan.InterestAmount), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, "Total a Pagar: "+g.money(loan.TotalAmount), props.Text{Size: 10, Style: fontstyle.Bold}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Plazo: %d días", loan.LoanTermDays), props.Text{Size: 10}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Período de Gracia: %d días", loan.GracePeriodDays), props.Text{Size: 10}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Mora por día vencido: %.2f%%", loan.LateFeeRate), props.Text{Size: 10}))
@@ -195,17 +224,17 @@ func (g *Generator) GeneratePaymentReceipt(payment *domain.Payment, loan *domain
 		Top:   2,
 	}))
 
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Monto del Pago: $%.2f", payment.Amount), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, "Monto del Pago: "+g.money(payment.Amount), props.Text{Size: 10, Style: fontstyle.Bold}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Método de Pago: %s", payment.PaymentMethod), props.Text{Size: 10}))
 
 	if payment.PrincipalAmount > 0 {
-		m.AddRow(6, text.NewCol(6, fmt.Sprintf("Aplicado a Capital: $%.2f", payment.PrincipalAmount), props.Text{Size: 10}))
+		m.AddRow(6, text.NewCol(6, "Aplicado a Capital: "+g.money(payment.PrincipalAmount), props.Text{Size: 10}))
 	}
 	if payment.InterestAmount > 0 {
-		m.AddRow(6, text.NewCol(6, fmt.Sprintf("Aplicado a Intereses: $%.2f", payment.InterestAmount), props.Text{Size: 10}))
+		m.AddRow(6, text.NewCol(6, "Aplicado a Intereses: "+g.money(payment.InterestAmount), props.Text{Size: 10}))
 	}
 	if payment.LateFeeAmount > 0 {
-		m.AddRow(6, text.NewCol(6, fmt.Sprintf("Aplicado a Mora: $%.2f", payment.LateFeeAmount), props.Text{Size: 10}))
+		m.AddRow(6, text.NewCol(6, "Aplicado a Mora: "+g.money(payment.LateFeeAmount), props.Text{Size: 10}))
 	}
 
 	// Balance After Payment
@@ -217,7 +246,7 @@ func (g *Generator) GeneratePaymentReceipt(payment *domain.Payment, loan *domain
 	}))
 
 	balanceRemaining := loan.RemainingBalance()
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Saldo Pendiente: $%.2f", balanceRemaining), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, "Saldo Pendiente: "+g.money(balanceRemaining), props.Text{Size: 10, Style: fontstyle.Bold}))
 
 	if loan.Status == domain.LoanStatusPaid {
 		m.AddRow(10)
@@ -292,13 +321,22 @@ func (g *Generator) GenerateSaleReceipt(sale *domain.Sale, item *domain.Item, cu
 		Top:   2,
 	}))
 
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Precio: $%.2f", sale.SalePrice), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, "Precio: "+g.money(sale.SalePrice), props.Text{Size: 10}))
 	if sale.DiscountAmount > 0 {
-		m.AddRow(6, text.NewCol(6, fmt.Sprintf("Descuento: $%.2f", sale.DiscountAmount), props.Text{Size: 10}))
+		m.AddRow(6, text.NewCol(6, "Descuento: "+g.money(sale.DiscountAmount), props.Text{Size: 10}))
 	}
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Total: $%.2f", sale.FinalPrice), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, "Total: "+g.money(sale.FinalPrice), props.Text{Size: 10, Style: fontstyle.Bold}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Método de Pago: %s", sale.PaymentMethod), props.Text{Size: 10}))
 
+	// Warranty / return period
+	m.AddRow(4)
+	if sale.WarrantyExpiry != nil {
+		m.AddRow(6, text.NewCol(12, fmt.Sprintf("Garantía y devoluciones: %d día(s), hasta el %s",
+			sale.WarrantyDays, sale.WarrantyExpiry.Format("02/01/2006")), props.Text{Size: 10, Style: fontstyle.Bold}))
+	} else {
+		m.AddRow(6, text.NewCol(12, "Artículo vendido sin garantía ni devolución.", props.Text{Size: 10}))
+	}
+
 	// Footer
 	m.AddRow(20)
 	m.AddRow(6, text.NewCol(12, "Gracias por su compra.", props.Text{Size: 10, Align: align.Center}))
@@ -313,6 +351,31 @@ func (g *Generator) GenerateSaleReceipt(sale *domain.Sale, item *domain.Item, cu
 
 // addHeader adds a common header to the document
 func (g *Generator) addHeader(m core.Maroto, title string) {
+	g.addHeaderWithQR(m, title, nil)
+}
+
+// addHeaderWithQR adds the common header with a QR code image in its top-right
+// corner, or the plain header when there is no QR code
+func (g *Generator) addHeaderWithQR(m core.Maroto, title string, qrCode []byte) {
+	if qrCode != nil {
+		m.AddRows(row.New(28).Add(
+			col.New(3),
+			col.New(6).Add(
+				text.New(g.companyName, props.Text{Size: 16, Style: fontstyle.Bold, Align: align.Center}),
+				text.New(g.companyAddress, props.Text{Size: 9, Align: align.Center, Top: 10}),
+				text.New(fmt.Sprintf("Tel: %s", g.companyPhone), props.Text{Size: 9, Align: align.Center, Top: 15}),
+			),
+			image.NewFromBytesCol(3, qrCode, extension.Png, props.Rect{Center: true, Percent: 95}),
+		))
+		m.AddRow(8, text.NewCol(12, title, props.Text{
+			Size:  14,
+			Style: fontstyle.Bold,
+			Align: align.Center,
+		}))
+		m.AddRow(5)
+		return
+	}
+
 	m.AddRow(10, text.NewCol(12, g.companyName, props.Text{
 		Size:  16,
 		Style: fontstyle.Bold,
@@ -370,19 +433,19 @@ func (g *Generator) GenerateDailyReport(report *DailyReport) ([]byte, error) {
 	}))
 
 	m.AddRow(6, text.NewCol(6, "Préstamos nuevos:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d ($%.2f)", report.NewLoansCount, report.NewLoansAmount), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d (%s)", report.NewLoansCount, g.money(report.NewLoansAmount)), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Pagos recibidos:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d ($%.2f)", report.PaymentsCount, report.PaymentsAmount), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d (%s)", report.PaymentsCount, g.money(report.PaymentsAmount)), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Ventas realizadas:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d ($%.2f)", report.SalesCount, report.SalesAmount), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d (%s)", report.SalesCount, g.money(report.SalesAmount)), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Renovaciones:", props.Text{Size: 10}))
 	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d", report.RenewalsCount), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Préstamos vencidos:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d ($%.2f)", report.OverdueCount, report.OverdueAmount), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, fmt.Sprintf("%d (%s)", report.OverdueCount, g.money(report.OverdueAmount)), props.Text{Size: 10}))
 
 	// Cash Summary
 	m.AddRow(10)
@@ -392,16 +455,16 @@ func (g *Generator) GenerateDailyReport(report *DailyReport) ([]byte, error) {
 	}))
 
 	m.AddRow(6, text.NewCol(6, "Efectivo inicial:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("$%.2f", report.OpeningCash), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, g.money(report.OpeningCash), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Ingresos:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("$%.2f", report.TotalIncome), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, g.money(report.TotalIncome), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Egresos:", props.Text{Size: 10}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("$%.2f", report.TotalExpenses), props.Text{Size: 10}))
+	m.AddRow(6, text.NewCol(6, g.money(report.TotalExpenses), props.Text{Size: 10}))
 
 	m.AddRow(6, text.NewCol(6, "Efectivo final:", props.Text{Size: 10, Style: fontstyle.Bold}))
-	m.AddRow(6, text.NewCol(6, fmt.Sprintf("$%.2f", report.ClosingCash), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, g.money(report.ClosingCash), props.Text{Size: 10, Style: fontstyle.Bold}))
 
 	// Generated timestamp
 	m.AddRow(20)
@@ -437,6 +500,194 @@ type DailyReport struct {
 	TotalExpenses  float64
 }
 
+// GenerateCustomerStatement generates a customer account statement PDF
+func (g *Generator) GenerateCustomerStatement(statement *CustomerStatement) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithPageNumber().
+		WithLeftMargin(10).
+		WithTopMargin(15).
+		WithRightMargin(10).
+		Build()
+
+	m := maroto.New(cfg)
+	customer := statement.Customer
+
+	// Header
+	g.addHeader(m, "ESTADO DE CUENTA")
+
+	m.AddRow(8, text.NewCol(12, fmt.Sprintf("Período: %s al %s",
+		statement.From.Format("02/01/2006"), statement.To.Format("02/01/2006")), props.Text{
+		Size:  12,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+	}))
+
+	// Customer Section
+	m.AddRow(10)
+	m.AddRow(8, text.NewCol(12, "DATOS DEL CLIENTE", props.Text{
+		Size:  11,
+		Style: fontstyle.Bold,
+		Top:   2,
+	}))
+
+	m.AddRow(6, text.NewCol(6, fmt.Sprintf("Nombre: %s %s", customer.FirstName, customer.LastName), props.Text{Size: 10}))
+	if customer.IdentityNumber != "" {
+		m.AddRow(6, text.NewCol(6, fmt.Sprintf("Identificación: %s - %s", customer.IdentityType, customer.IdentityNumber), props.Text{Size: 10}))
+	}
+
+	// Loans Section
+	m.AddRow(10)
+	m.AddRow(8, text.NewCol(12, "PRÉSTAMOS", props.Text{
+		Size:  11,
+		Style: fontstyle.Bold,
+		Top:   2,
+	}))
+
+	if len(statement.Loans) == 0 {
+		m.AddRow(6, text.NewCol(12, "Sin préstamos en el período", props.Text{Size: 10}))
+	}
+	for _, loan := range statement.Loans {
+		m.AddRow(6,
+			text.NewCol(3, loan.LoanNumber, props.Text{Size: 9}),
+			text.NewCol(3, fmt.Sprintf("Inicio: %s", loan.StartDate.Format("02/01/2006")), props.Text{Size: 9}),
+			text.NewCol(3, fmt.Sprintf("Vence: %s", loan.DueDate.Format("02/01/2006")), props.Text{Size: 9}),
+			text.NewCol(3, "Saldo: "+g.money(loan.RemainingBalance()), props.Text{Size: 9, Align: align.Right}),
+		)
+	}
+
+	// Payments Section
+	m.AddRow(10)
+	m.AddRow(8, text.NewCol(12, "PAGOS DEL PERÍODO", props.Text{
+		Size:  11,
+		Style: fontstyle.Bold,
+		Top:   2,
+	}))
+
+	if len(statement.Payments) == 0 {
+		m.AddRow(6, text.NewCol(12, "Sin pagos en el período", props.Text{Size: 10}))
+	}
+	for _, payment := range statement.Payments {
+		m.AddRow(6,
+			text.NewCol(4, payment.PaymentNumber, props.Text{Size: 9}),
+			text.NewCol(4, payment.PaymentDate.Format("02/01/2006"), props.Text{Size: 9}),
+			text.NewCol(4, g.money(payment.Amount), props.Text{Size: 9, Align: align.Right}),
+		)
+	}
+
+	// Totals
+	m.AddRow(10)
+	m.AddRow(6, text.NewCol(6, "Total pagado: "+g.money(statement.TotalPaid), props.Text{Size: 10, Style: fontstyle.Bold}))
+	m.AddRow(6, text.NewCol(6, "Saldo pendiente: "+g.money(statement.TotalBalance), props.Text{Size: 10, Style: fontstyle.Bold}))
+
+	// Generated timestamp
+	m.AddRow(20)
+	m.AddRow(5, text.NewCol(12, fmt.Sprintf("Generado: %s", time.Now().Format("02/01/2006 15:04:05")), props.Text{
+		Size:  8,
+		Align: align.Right,
+	}))
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return document.GetBytes(), nil
+}
+
+// CustomerStatement contains a customer's account activity for a period
+type CustomerStatement struct {
+	Customer     *domain.Customer
+	From         time.Time
+	To           time.Time
+	Loans        []*domain.Loan    // loans opened in the period or still open
+	Payments     []*domain.Payment // completed payments made in the period
+	TotalPaid    float64
+	TotalBalance float64
+}
+
+// accountTypeLabels names the account types in trial balance headings
+var accountTypeLabels = map[string]string{
+	domain.AccountTypeAsset:     "ACTIVOS",
+	domain.AccountTypeLiability: "PASIVOS",
+	domain.AccountTypeEquity:    "PATRIMONIO",
+	domain.AccountTypeIncome:    "INGRESOS",
+	domain.AccountTypeExpense:   "GASTOS",
+}
+
+// GenerateTrialBalancePDF generates a trial balance PDF, one section per account type
+func (g *Generator) GenerateTrialBalancePDF(trialBalance *domain.TrialBalance) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithPageNumber().
+		WithLeftMargin(10).
+		WithTopMargin(15).
+		WithRightMargin(10).
+		Build()
+
+	m := maroto.New(cfg)
+
+	// Header
+	g.addHeader(m, "BALANCE DE COMPROBACIÓN")
+
+	m.AddRow(8, text.NewCol(12, fmt.Sprintf("Al %s", trialBalance.AsOf.Format("02/01/2006")), props.Text{
+		Size:  12,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+	}))
+
+	m.AddRow(10)
+	m.AddRow(6,
+		text.NewCol(2, "Código", props.Text{Size: 9, Style: fontstyle.Bold}),
+		text.NewCol(4, "Cuenta", props.Text{Size: 9, Style: fontstyle.Bold}),
+		text.NewCol(2, "Debe", props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(2, "Haber", props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(2, "Saldo", props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right}),
+	)
+
+	accountType := ""
+	for _, line := range trialBalance.Lines {
+		if line.AccountType != accountType {
+			accountType = line.AccountType
+			m.AddRow(8, text.NewCol(12, accountTypeLabels[accountType], props.Text{
+				Size:  10,
+				Style: fontstyle.Bold,
+				Top:   2,
+			}))
+		}
+		m.AddRow(6,
+			text.NewCol(2, line.AccountCode, props.Text{Size: 9}),
+			text.NewCol(4, line.AccountName, props.Text{Size: 9}),
+			text.NewCol(2, g.money(line.Debit), props.Text{Size: 9, Align: align.Right}),
+			text.NewCol(2, g.money(line.Credit), props.Text{Size: 9, Align: align.Right}),
+			text.NewCol(2, g.money(line.Balance), props.Text{Size: 9, Align: align.Right}),
+		)
+	}
+
+	// Totals
+	m.AddRow(10)
+	m.AddRow(6,
+		text.NewCol(6, "TOTALES", props.Text{Size: 10, Style: fontstyle.Bold}),
+		text.NewCol(2, g.money(trialBalance.TotalDebit), props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(2, g.money(trialBalance.TotalCredit), props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right}),
+	)
+	if !trialBalance.Balanced {
+		m.AddRow(6, text.NewCol(12, "El debe y el haber no cuadran", props.Text{Size: 10, Style: fontstyle.Bold}))
+	}
+
+	// Generated timestamp
+	m.AddRow(20)
+	m.AddRow(5, text.NewCol(12, fmt.Sprintf("Generado: %s", time.Now().Format("02/01/2006 15:04:05")), props.Text{
+		Size:  8,
+		Align: align.Right,
+	}))
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return document.GetBytes(), nil
+}
+
 // SaveToBuffer saves the PDF to a buffer
 func SaveToBuffer(data []byte) (*bytes.Buffer, error) {
 	buf := bytes.NewBuffer(data)
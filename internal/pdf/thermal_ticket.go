@@ -22,6 +22,7 @@ type ThermalTicketGenerator struct {
 	companyAddress string
 	companyPhone   string
 	companyRFC     string // Tax ID for Mexico/Latin America
+	moneyFormat    domain.MoneyFormat
 }
 
 // NewThermalTicketGenerator creates a new thermal ticket generator
@@ -31,9 +32,22 @@ func NewThermalTicketGenerator(companyName, companyAddress, companyPhone, compan
 		companyAddress: companyAddress,
 		companyPhone:   companyPhone,
 		companyRFC:     companyRFC,
+		moneyFormat:    domain.NewMoneyFormat("", ""),
 	}
 }
 
+// WithMoneyFormat returns a copy of the generator that writes amounts in the
+// given format
+func (g *ThermalTicketGenerator) WithMoneyFormat(format domain.MoneyFormat) *ThermalTicketGenerator {
+	clone := *g
+	clone.moneyFormat = format
+	return &clone
+}
+
+func (g *ThermalTicketGenerator) money(amount float64) string {
+	return g.moneyFormat.Format(amount)
+}
+
 // createThermalConfig creates the configuration for 80mm thermal paper
 func createThermalConfig(height float64) core.Maroto {
 	cfg := config.NewBuilder().
@@ -95,11 +109,11 @@ func (g *ThermalTicketGenerator) GenerateLoanTicket(loan *domain.Loan, customer
 	m.AddRow(3, text.NewCol(12, "DETALLE DEL PRESTAMO:", props.Text{Size: 7, Style: fontstyle.Bold}))
 	m.AddRow(3,
 		text.NewCol(6, "Prestamo:", props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", loan.LoanAmount), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(loan.LoanAmount), props.Text{Size: 7, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, "Interes:", props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", loan.InterestAmount), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(loan.InterestAmount), props.Text{Size: 7, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, "Tasa:", props.Text{Size: 6}),
@@ -110,7 +124,7 @@ func (g *ThermalTicketGenerator) GenerateLoanTicket(loan *domain.Loan, customer
 
 	m.AddRow(4,
 		text.NewCol(6, "TOTAL:", props.Text{Size: 8, Style: fontstyle.Bold}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", loan.TotalAmount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(6, g.money(loan.TotalAmount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 	)
 
 	g.addSeparator(m)
@@ -175,19 +189,19 @@ func (g *ThermalTicketGenerator) GeneratePaymentTicket(payment *domain.Payment,
 	if payment.PrincipalAmount > 0 {
 		m.AddRow(3,
 			text.NewCol(6, "Capital:", props.Text{Size: 7}),
-			text.NewCol(6, fmt.Sprintf("$%.2f", payment.PrincipalAmount), props.Text{Size: 7, Align: align.Right}),
+			text.NewCol(6, g.money(payment.PrincipalAmount), props.Text{Size: 7, Align: align.Right}),
 		)
 	}
 	if payment.InterestAmount > 0 {
 		m.AddRow(3,
 			text.NewCol(6, "Interes:", props.Text{Size: 7}),
-			text.NewCol(6, fmt.Sprintf("$%.2f", payment.InterestAmount), props.Text{Size: 7, Align: align.Right}),
+			text.NewCol(6, g.money(payment.InterestAmount), props.Text{Size: 7, Align: align.Right}),
 		)
 	}
 	if payment.LateFeeAmount > 0 {
 		m.AddRow(3,
 			text.NewCol(6, "Mora:", props.Text{Size: 7}),
-			text.NewCol(6, fmt.Sprintf("$%.2f", payment.LateFeeAmount), props.Text{Size: 7, Align: align.Right}),
+			text.NewCol(6, g.money(payment.LateFeeAmount), props.Text{Size: 7, Align: align.Right}),
 		)
 	}
 
@@ -196,7 +210,7 @@ func (g *ThermalTicketGenerator) GeneratePaymentTicket(payment *domain.Payment,
 	// Total paid
 	m.AddRow(4,
 		text.NewCol(6, "PAGADO:", props.Text{Size: 8, Style: fontstyle.Bold}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", payment.Amount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(6, g.money(payment.Amount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, "Metodo:", props.Text{Size: 6}),
@@ -209,7 +223,7 @@ func (g *ThermalTicketGenerator) GeneratePaymentTicket(payment *domain.Payment,
 	balance := loan.RemainingBalance()
 	m.AddRow(4,
 		text.NewCol(6, "SALDO:", props.Text{Size: 8, Style: fontstyle.Bold}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", balance), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(6, g.money(balance), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 	)
 
 	if loan.Status == domain.LoanStatusPaid {
@@ -270,13 +284,13 @@ func (g *ThermalTicketGenerator) GenerateSaleTicket(sale *domain.Sale, item *dom
 	// Price breakdown
 	m.AddRow(3,
 		text.NewCol(6, "Precio:", props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", sale.SalePrice), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(sale.SalePrice), props.Text{Size: 7, Align: align.Right}),
 	)
 
 	if sale.DiscountAmount > 0 {
 		m.AddRow(3,
 			text.NewCol(6, "Descuento:", props.Text{Size: 7}),
-			text.NewCol(6, fmt.Sprintf("-$%.2f", sale.DiscountAmount), props.Text{Size: 7, Align: align.Right}),
+			text.NewCol(6, "-"+g.money(sale.DiscountAmount), props.Text{Size: 7, Align: align.Right}),
 		)
 	}
 
@@ -285,12 +299,17 @@ func (g *ThermalTicketGenerator) GenerateSaleTicket(sale *domain.Sale, item *dom
 	// Total
 	m.AddRow(4,
 		text.NewCol(6, "TOTAL:", props.Text{Size: 9, Style: fontstyle.Bold}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", sale.FinalPrice), props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(6, g.money(sale.FinalPrice), props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, "Pago:", props.Text{Size: 6}),
 		text.NewCol(6, string(sale.PaymentMethod), props.Text{Size: 6, Align: align.Right}),
 	)
+	if sale.WarrantyExpiry != nil {
+		m.AddRow(3, text.NewCol(12, fmt.Sprintf("Garantía: %d día(s), hasta %s", sale.WarrantyDays, sale.WarrantyExpiry.Format("02/01/2006")), props.Text{
+			Size: 6,
+		}))
+	}
 
 	g.addTicketFooter(m)
 
@@ -346,15 +365,15 @@ func (g *ThermalTicketGenerator) GenerateCashCutTicket(cutType string, session *
 
 	m.AddRow(3,
 		text.NewCol(6, "Saldo Inicial:", props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", session.OpeningAmount), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(session.OpeningAmount), props.Text{Size: 7, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, fmt.Sprintf("Ingresos (%d):", incomeCount), props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", totalIncome), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(totalIncome), props.Text{Size: 7, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, fmt.Sprintf("Egresos (%d):", expenseCount), props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", totalExpense), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(totalExpense), props.Text{Size: 7, Align: align.Right}),
 	)
 
 	g.addSeparator(m)
@@ -363,13 +382,13 @@ func (g *ThermalTicketGenerator) GenerateCashCutTicket(cutType string, session *
 	calculatedBalance := session.OpeningAmount + totalIncome - totalExpense
 	m.AddRow(4,
 		text.NewCol(6, "ESPERADO:", props.Text{Size: 8, Style: fontstyle.Bold}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", calculatedBalance), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(6, g.money(calculatedBalance), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 	)
 
 	if session.ClosingAmount != nil {
 		m.AddRow(4,
 			text.NewCol(6, "CONTADO:", props.Text{Size: 8, Style: fontstyle.Bold}),
-			text.NewCol(6, fmt.Sprintf("$%.2f", *session.ClosingAmount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+			text.NewCol(6, g.money(*session.ClosingAmount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 		)
 
 		diff := *session.ClosingAmount - calculatedBalance
@@ -379,7 +398,7 @@ func (g *ThermalTicketGenerator) GenerateCashCutTicket(cutType string, session *
 		}
 		m.AddRow(4,
 			text.NewCol(6, diffLabel, props.Text{Size: 8, Style: fontstyle.Bold}),
-			text.NewCol(6, fmt.Sprintf("$%.2f", diff), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+			text.NewCol(6, g.money(diff), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 		)
 	}
 
@@ -402,7 +421,7 @@ func (g *ThermalTicketGenerator) GenerateCashCutTicket(cutType string, session *
 		desc := truncateString(mov.Description, 20)
 		m.AddRow(3,
 			text.NewCol(8, desc, props.Text{Size: 6}),
-			text.NewCol(4, fmt.Sprintf("%s$%.2f", sign, mov.Amount), props.Text{Size: 6, Align: align.Right}),
+			text.NewCol(4, sign+g.money(mov.Amount), props.Text{Size: 6, Align: align.Right}),
 		)
 	}
 
@@ -547,18 +566,18 @@ func (g *ThermalTicketGenerator) GenerateLoanTicketWithSize(loan *domain.Loan, c
 
 	m.AddRow(3,
 		text.NewCol(6, "Prestamo:", props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", loan.LoanAmount), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(loan.LoanAmount), props.Text{Size: 7, Align: align.Right}),
 	)
 	m.AddRow(3,
 		text.NewCol(6, "Interes:", props.Text{Size: 7}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", loan.InterestAmount), props.Text{Size: 7, Align: align.Right}),
+		text.NewCol(6, g.money(loan.InterestAmount), props.Text{Size: 7, Align: align.Right}),
 	)
 
 	g.addSeparator(m)
 
 	m.AddRow(4,
 		text.NewCol(6, "TOTAL:", props.Text{Size: 8, Style: fontstyle.Bold}),
-		text.NewCol(6, fmt.Sprintf("$%.2f", loan.TotalAmount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
+		text.NewCol(6, g.money(loan.TotalAmount), props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}),
 	)
 
 	m.AddRow(4, text.NewCol(12, fmt.Sprintf("VENCE: %s", loan.DueDate.Format("02/01/2006")), props.Text{
@@ -0,0 +1,249 @@
+package pdf
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pawnshop/internal/domain"
+)
+
+// qrModules reads the dark / light modules of a rendered QR code
+func qrModules(t *testing.T, data []byte) [][]bool {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	bounds := img.Bounds()
+	require.Zero(t, bounds.Dx()%qrModulePixels)
+	size := bounds.Dx() / qrModulePixels
+	modules := make([][]bool, size)
+	for y := 0; y < size; y++ {
+		modules[y] = make([]bool, size)
+		for x := 0; x < size; x++ {
+			r, _, _, _ := img.At(x*qrModulePixels+qrModulePixels/2, y*qrModulePixels+qrModulePixels/2).RGBA()
+			modules[y][x] = r < 0x8000
+		}
+	}
+	return modules
+}
+
+// qrBlocksM is the data codeword blocks of each QR version at error
+// correction level M: {blocks, data codewords per block} per group
+var qrBlocksM = map[int][][2]int{
+	1: {{1, 16}},
+	2: {{1, 28}},
+	3: {{1, 44}},
+	4: {{2, 32}},
+	5: {{2, 43}},
+	6: {{4, 27}},
+	7: {{4, 31}},
+	8: {{2, 38}, {2, 39}},
+	9: {{3, 36}, {2, 37}},
+}
+
+// qrAlignmentCenters is the row and column of the alignment pattern centers
+// of each QR version
+var qrAlignmentCenters = map[int][]int{
+	2: {6, 18},
+	3: {6, 22},
+	4: {6, 26},
+	5: {6, 30},
+	6: {6, 34},
+	7: {6, 22, 38},
+	8: {6, 24, 42},
+	9: {6, 26, 46},
+}
+
+// qrMasked reports whether the data mask flips the module at a row and column
+func qrMasked(mask, row, col int) bool {
+	switch mask {
+	case 0:
+		return (row+col)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return col%3 == 0
+	case 3:
+		return (row+col)%3 == 0
+	case 4:
+		return (row/2+col/3)%2 == 0
+	case 5:
+		return (row*col)%2+(row*col)%3 == 0
+	case 6:
+		return ((row*col)%2+(row*col)%3)%2 == 0
+	default:
+		return ((row+col)%2+(row*col)%3)%2 == 0
+	}
+}
+
+// decodeQRPayload reads the byte-mode payload of an undamaged level M QR code
+// of version 1 to 9, the codes the contracts carry
+func decodeQRPayload(t *testing.T, data []byte) string {
+	t.Helper()
+	modules := qrModules(t, data)
+	size := len(modules)
+	version := (size - 17) / 4
+	blocks, ok := qrBlocksM[version]
+	require.True(t, ok, "unsupported QR version %d", version)
+
+	// Format information, next to the top-left finder pattern
+	formatCells := [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	format := 0
+	for _, cell := range formatCells {
+		format <<= 1
+		if modules[cell[0]][cell[1]] {
+			format |= 1
+		}
+	}
+	format ^= 0x5412
+	require.Equal(t, 0, format>>13, "QR code is not error correction level M")
+	mask := (format >> 10) & 7
+
+	function := func(row, col int) bool {
+		switch {
+		case row <= 8 && (col <= 8 || col >= size-8), row >= size-8 && col <= 8:
+			return true // finder patterns, separators and format information
+		case row == 6 || col == 6:
+			return true // timing patterns
+		case version >= 7 && ((row <= 5 && col >= size-11) || (col <= 5 && row >= size-11)):
+			return true // version information
+		}
+		centers := qrAlignmentCenters[version]
+		for _, r := range centers {
+			for _, c := range centers {
+				if (r <= 8 && (c <= 8 || c >= size-8)) || (r >= size-8 && c <= 8) {
+					continue
+				}
+				if row >= r-2 && row <= r+2 && col >= c-2 && col <= c+2 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// Codewords run in two-column strips from the bottom right, alternating up and down
+	var codewords []byte
+	var current byte
+	bits := 0
+	upward := true
+	for right := size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < size; i++ {
+			row := i
+			if upward {
+				row = size - 1 - i
+			}
+			for col := right; col >= right-1; col-- {
+				if function(row, col) {
+					continue
+				}
+				current <<= 1
+				if modules[row][col] != qrMasked(mask, row, col) {
+					current |= 1
+				}
+				if bits++; bits%8 == 0 {
+					codewords = append(codewords, current)
+				}
+			}
+		}
+		upward = !upward
+	}
+
+	// Data codewords are interleaved across the blocks
+	var blockData [][]byte
+	var sizes []int
+	for _, group := range blocks {
+		for i := 0; i < group[0]; i++ {
+			sizes = append(sizes, group[1])
+			blockData = append(blockData, nil)
+		}
+	}
+	next := 0
+	for i := 0; i < sizes[len(sizes)-1]; i++ {
+		for b := range blockData {
+			if i < sizes[b] {
+				blockData[b] = append(blockData[b], codewords[next])
+				next++
+			}
+		}
+	}
+	var stream []byte
+	for _, block := range blockData {
+		stream = append(stream, block...)
+	}
+
+	require.Equal(t, byte(0x4), stream[0]>>4, "QR code is not in byte mode")
+	length := int(stream[0]&0x0f)<<4 | int(stream[1]>>4)
+	payload := make([]byte, length)
+	for i := range payload {
+		payload[i] = stream[1+i]<<4 | stream[2+i]>>4
+	}
+	return string(payload)
+}
+
+func TestLoanVerificationQR_RoundTripsLoanNumber(t *testing.T) {
+	key := "contract-secret"
+	generator := NewGenerator("Casa de Empeño", "", "", "https://pawnshop.example.com/verify/loans/").WithVerificationKey(key)
+	loanNumber := "PR-2026/000042"
+
+	data, err := generator.LoanVerificationQR(loanNumber)
+	require.NoError(t, err)
+	require.NotNil(t, data)
+
+	payload := decodeQRPayload(t, data)
+
+	link, err := url.Parse(payload)
+	require.NoError(t, err)
+	assert.Equal(t, "pawnshop.example.com", link.Host)
+	assert.Equal(t, "/verify/loans", path.Dir(link.EscapedPath()))
+
+	decodedNumber, err := url.PathUnescape(path.Base(link.EscapedPath()))
+	require.NoError(t, err)
+	assert.Equal(t, loanNumber, decodedNumber)
+	assert.True(t, VerifyLoanToken([]byte(key), decodedNumber, link.Query().Get("token")))
+	assert.False(t, VerifyLoanToken([]byte("other-secret"), decodedNumber, link.Query().Get("token")))
+	assert.False(t, VerifyLoanToken([]byte(key), "PR-2026/000041", link.Query().Get("token")))
+}
+
+func TestLoanVerificationQR_NoURLSkipsQR(t *testing.T) {
+	generator := NewGenerator("Casa de Empeño", "", "", "").WithVerificationKey("contract-secret")
+
+	data, err := generator.LoanVerificationQR("PR-2026/000042")
+
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	assert.Empty(t, generator.LoanVerificationURL("PR-2026/000042"))
+}
+
+func TestGenerateLoanContract_WithAndWithoutQR(t *testing.T) {
+	loan := &domain.Loan{
+		LoanNumber: "PR-2026/000042",
+		StartDate:  domain.NewDate(2026, time.March, 1),
+		DueDate:    domain.NewDate(2026, time.April, 1),
+		LoanAmount: 1500,
+	}
+	customer := &domain.Customer{FirstName: "Ana", LastName: "López"}
+	item := &domain.Item{Name: "Anillo de oro", AppraisedValue: 2500}
+
+	plain, err := NewGenerator("Casa de Empeño", "", "", "").GenerateLoanContract(loan, customer, item)
+	require.NoError(t, err)
+
+	withQR, err := NewGenerator("Casa de Empeño", "", "", "https://pawnshop.example.com/verify/loans").
+		WithVerificationKey("contract-secret").
+		GenerateLoanContract(loan, customer, item)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(withQR, []byte("%PDF")))
+	assert.NotContains(t, string(plain), "/Subtype /Image")
+	assert.Contains(t, string(withQR), "/Subtype /Image")
+}
@@ -36,7 +36,8 @@ type AccountRepository interface {
 
 // AccountingEntryRepository defines the interface for journal entry operations
 type AccountingEntryRepository interface {
-	// Create creates a new accounting entry with its lines
+	// Create creates a new accounting entry with its lines, rejecting entries
+	// that are not balanced
 	Create(ctx context.Context, entry *domain.AccountingEntry) error
 
 	// GetByID retrieves an entry by ID with its lines
@@ -68,6 +69,17 @@ type AccountingEntryRepository interface {
 
 	// GetAccountBalanceByBranch retrieves the balance for an account in a specific branch
 	GetAccountBalanceByBranch(ctx context.Context, accountID int64, branchID int64, asOfDate time.Time) (float64, error)
+
+	// GetAccountTotals sums the posted debit and credit lines of every account
+	// with postings up to a date, for one branch or all of them
+	GetAccountTotals(ctx context.Context, branchID *int64, asOfDate time.Time) ([]AccountTotals, error)
+}
+
+// AccountTotals is the sum of the posted debit and credit lines of an account
+type AccountTotals struct {
+	AccountID int64   `json:"account_id"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
 }
 
 // AccountingEntryFilter contains filters for listing accounting entries
@@ -105,6 +117,35 @@ type DailyBalanceRepository interface {
 
 	// GetSummary retrieves aggregated balances for a period
 	GetSummary(ctx context.Context, branchID *int64, dateFrom, dateTo time.Time) (*DailyBalanceSummary, error)
+
+	// UpsertBatch creates or updates several daily balances in one transaction
+	UpsertBatch(ctx context.Context, balances []*domain.DailyBalance) error
+
+	// GetDailyActivity aggregates the loans, payments, sales and expenses of a branch on a day
+	GetDailyActivity(ctx context.Context, branchID int64, date time.Time) (*DailyActivity, error)
+}
+
+// DailyActivity is the activity of a branch on one day, read from the transactional tables
+type DailyActivity struct {
+	LoanDisbursements float64 `json:"loan_disbursements"`
+	PaymentsReceived  float64 `json:"payments_received"`
+	InterestCollected float64 `json:"interest_collected"`
+	LateFeesCollected float64 `json:"late_fees_collected"`
+	SalesIncome       float64 `json:"sales_income"`
+	Refunds           float64 `json:"refunds"`
+	Expenses          float64 `json:"expenses"`
+	ActiveLoansAmount float64 `json:"active_loans_amount"`
+	ActiveLoansCount  int     `json:"active_loans_count"`
+}
+
+// LoanBalanceSnapshotRepository stores the end-of-day balances of open loans
+type LoanBalanceSnapshotRepository interface {
+	// UpsertBatch stores several snapshots in one transaction, replacing any
+	// taken earlier for the same loan and day
+	UpsertBatch(ctx context.Context, snapshots []*domain.LoanBalanceSnapshot) error
+
+	// ListByDate retrieves the snapshots taken on a day; branchID 0 lists every branch
+	ListByDate(ctx context.Context, branchID int64, date time.Time) ([]*domain.LoanBalanceSnapshot, error)
 }
 
 // DailyBalanceSummary represents aggregated daily balance data
@@ -183,3 +224,23 @@ type ExpenseFilter struct {
 	Page        int
 	PageSize    int
 }
+
+// AccountingPeriodRepository defines the interface for accounting period operations
+type AccountingPeriodRepository interface {
+	// GetOrCreate retrieves a branch's period, opening it if it does not exist yet
+	GetOrCreate(ctx context.Context, branchID int64, year, month int) (*domain.AccountingPeriod, error)
+
+	// Get retrieves a branch's period, or nil if it was never opened
+	Get(ctx context.Context, branchID int64, year, month int) (*domain.AccountingPeriod, error)
+
+	// List retrieves periods, newest first, optionally for a single branch
+	List(ctx context.Context, branchID *int64) ([]*domain.AccountingPeriod, error)
+
+	// SaveAccruals records the accruals booked for a period together with their
+	// entry, if any, in one transaction. It reports false, creating nothing, if
+	// accruals were already recorded for the period.
+	SaveAccruals(ctx context.Context, period *domain.AccountingPeriod, entry *domain.AccountingEntry) (bool, error)
+
+	// Close marks a period as closed
+	Close(ctx context.Context, id int64, closedBy int64) error
+}
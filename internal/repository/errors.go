@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInUse is matched by errors.Is for any InUseError
+var ErrInUse = errors.New("record is in use")
+
+// ErrItemHasActiveLoan is returned when creating a loan on an item that already
+// backs an active or overdue loan
+var ErrItemHasActiveLoan = errors.New("item already backs an active loan")
+
+// ErrRegisterHasOpenSession is returned when opening a cash session on a
+// register that already has an open one
+var ErrRegisterHasOpenSession = errors.New("register already has an open session")
+
+// ErrDuplicateIdempotencyKey is returned when creating a payment with an
+// idempotency key the same user already created a payment with
+var ErrDuplicateIdempotencyKey = errors.New("idempotency key already used")
+
+// ErrLoanAlreadyWrittenOff is returned when writing off a loan that was written
+// off, or left the defaulted and overdue statuses, in the meantime
+var ErrLoanAlreadyWrittenOff = errors.New("loan has already been written off")
+
+// InUseError is returned when a record cannot be deleted because other
+// records still reference it
+type InUseError struct {
+	// Entity is the kind of record being deleted, e.g. "category"
+	Entity string
+	// ReferencedBy describes what still references it, e.g. "items"
+	ReferencedBy string
+}
+
+func (e *InUseError) Error() string {
+	return fmt.Sprintf("%s is still referenced by %s", e.Entity, e.ReferencedBy)
+}
+
+// Is reports whether target is ErrInUse
+func (e *InUseError) Is(target error) bool {
+	return target == ErrInUse
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"pawnshop/internal/domain"
 )
 
@@ -90,6 +92,8 @@ type CustomerListParams struct {
 	IsActive  *bool  `query:"is_active"`
 	IsBlocked *bool  `query:"is_blocked"`
 	Search    string `query:"search"`
+	Phone     string `query:"phone"` // Partial match on the digits of the phone number
+	Email     string `query:"email"` // Partial, case-insensitive match
 }
 
 // CustomerCreditUpdate for updating credit info
@@ -118,6 +122,30 @@ type CategoryListParams struct {
 	IsActive *bool  `query:"is_active"`
 }
 
+// LoanProductRepository defines methods for loan product operations
+type LoanProductRepository interface {
+	GetByID(ctx context.Context, id int64) (*domain.LoanProduct, error)
+	GetByCode(ctx context.Context, code string) (*domain.LoanProduct, error)
+	List(ctx context.Context, activeOnly bool) ([]*domain.LoanProduct, error)
+	Create(ctx context.Context, product *domain.LoanProduct) error
+	Update(ctx context.Context, product *domain.LoanProduct) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// CampaignRepository defines methods for promotional campaign operations
+type CampaignRepository interface {
+	GetByID(ctx context.Context, id int64) (*domain.Campaign, error)
+	GetByCode(ctx context.Context, code string) (*domain.Campaign, error)
+	List(ctx context.Context, activeOnly bool) ([]*domain.Campaign, error)
+	ListRunning(ctx context.Context, day domain.Date) ([]*domain.Campaign, error)
+	Create(ctx context.Context, campaign *domain.Campaign) error
+	Update(ctx context.Context, campaign *domain.Campaign) error
+	Delete(ctx context.Context, id int64) error
+	FlagCustomer(ctx context.Context, campaignID, customerID int64) error
+	UnflagCustomer(ctx context.Context, campaignID, customerID int64) error
+	IsCustomerFlagged(ctx context.Context, campaignID, customerID int64) (bool, error)
+}
+
 // ItemRepository defines methods for item operations
 type ItemRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Item, error)
@@ -125,10 +153,18 @@ type ItemRepository interface {
 	List(ctx context.Context, params ItemListParams) (*PaginatedResult[domain.Item], error)
 	Create(ctx context.Context, item *domain.Item) error
 	Update(ctx context.Context, item *domain.Item) error
+	// Delete soft deletes an item; it stays in the database for its loans and sales
 	Delete(ctx context.Context, id int64) error
+	// GetDeletedByID retrieves a soft-deleted item by ID
+	GetDeletedByID(ctx context.Context, id int64) (*domain.Item, error)
+	// Restore undoes the soft deletion of an item
+	Restore(ctx context.Context, id int64) error
 	UpdateStatus(ctx context.Context, id int64, status domain.ItemStatus) error
 	GenerateSKU(ctx context.Context, branchID int64) (string, error)
 	CreateHistory(ctx context.Context, history *domain.ItemHistory) error
+	// ListAfter lists up to limit items with an ID above afterID in ID order,
+	// for exports that page by cursor; branchID 0 lists every branch
+	ListAfter(ctx context.Context, branchID, afterID int64, limit int) ([]*domain.Item, error)
 }
 
 // ItemListParams for filtering item list
@@ -139,18 +175,35 @@ type ItemListParams struct {
 	CustomerID *int64              `query:"customer_id"`
 	Status     *domain.ItemStatus  `query:"status"`
 	Search     string              `query:"search"`
+	// IncludeDeleted lists soft-deleted items along with the rest
+	IncludeDeleted bool `query:"include_deleted"`
 }
 
 // LoanRepository defines methods for loan operations
 type LoanRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Loan, error)
 	GetByNumber(ctx context.Context, loanNumber string) (*domain.Loan, error)
+	// GetActiveByItemID returns the active or overdue loan backed by an item,
+	// or nil if there is none
+	GetActiveByItemID(ctx context.Context, itemID int64) (*domain.Loan, error)
 	List(ctx context.Context, params LoanListParams) (*PaginatedResult[domain.Loan], error)
 	Create(ctx context.Context, loan *domain.Loan) error
 	Update(ctx context.Context, loan *domain.Loan) error
-	GenerateNumber(ctx context.Context) (string, error)
+	GenerateNumber(ctx context.Context, cadence domain.SequenceResetCadence) (string, error)
 	GetOverdueLoans(ctx context.Context, branchID int64) ([]*domain.Loan, error)
 	UpdateStatus(ctx context.Context, id int64, status domain.LoanStatus) error
+	SetContract(ctx context.Context, id int64, documentID int64, url, hash string) error
+	SetTags(ctx context.Context, id int64, tags []string) error
+	// AuthorizeDisbursement records the second user co-signing a loan's cash
+	// disbursement; it fails when the loan needs no authorization or already has it
+	AuthorizeDisbursement(ctx context.Context, id int64, authorizedBy int64) error
+	// RevokeAuthorization undoes AuthorizeDisbursement when the cash could not be paid out
+	RevokeAuthorization(ctx context.Context, id int64) error
+	// Renew closes a loan as renewed, records the interest paid with the
+	// renewal and its cash movement, and creates the successor loan in one
+	// transaction; payment is nil when no interest was owed and movement is
+	// nil when the interest was not received in a cash session
+	Renew(ctx context.Context, loan *domain.Loan, renewed *domain.Loan, payment *domain.Payment, movement *domain.CashMovement) error
 	BeginTx(ctx context.Context) (Transaction, error)
 	CreateTx(ctx context.Context, tx Transaction, loan *domain.Loan) error
 
@@ -159,6 +212,9 @@ type LoanRepository interface {
 	CreateInstallmentsTx(ctx context.Context, tx Transaction, installments []*domain.LoanInstallment) error
 	GetInstallments(ctx context.Context, loanID int64) ([]*domain.LoanInstallment, error)
 	UpdateInstallment(ctx context.Context, installment *domain.LoanInstallment) error
+	// ReplaceInstallments replaces a loan's installment schedule and puts the
+	// loan on an installment payment plan
+	ReplaceInstallments(ctx context.Context, loanID int64, installments []*domain.LoanInstallment) error
 }
 
 // LoanListParams for filtering loan list
@@ -171,6 +227,28 @@ type LoanListParams struct {
 	DueBefore  *string            `query:"due_before"`
 	DueAfter   *string            `query:"due_after"`
 	Search     string             `query:"search"`
+	// OpenOn keeps loans that were open at the end of the given day: started
+	// by then and not yet paid, renewed, confiscated or written off
+	OpenOn *string `query:"open_on"`
+	// Tags keeps loans carrying all of the given tags
+	Tags []string `query:"tags"`
+}
+
+// LoanWriteOffRepository defines methods for loan write-off operations
+type LoanWriteOffRepository interface {
+	// Create records a write-off in one transaction: the loan is written off,
+	// its pawned item is confiscated and the loss entry, if any, is posted. It
+	// returns ErrLoanAlreadyWrittenOff when the loan can no longer be written off.
+	Create(ctx context.Context, writeOff *domain.LoanWriteOff, entry *domain.AccountingEntry) error
+	// List retrieves write-offs, newest first, with their loan and customer
+	List(ctx context.Context, params LoanWriteOffListParams) ([]*domain.LoanWriteOff, error)
+}
+
+// LoanWriteOffListParams for filtering loan write-offs
+type LoanWriteOffListParams struct {
+	BranchID int64
+	DateFrom *string
+	DateTo   *string
 }
 
 // PaymentRepository defines methods for payment operations
@@ -179,21 +257,28 @@ type PaymentRepository interface {
 	GetByNumber(ctx context.Context, paymentNumber string) (*domain.Payment, error)
 	List(ctx context.Context, params PaymentListParams) (*PaginatedResult[domain.Payment], error)
 	ListByLoan(ctx context.Context, loanID int64) ([]*domain.Payment, error)
+	// ListAfter lists up to limit payments with an ID above afterID in ID order,
+	// for exports that page by cursor; branchID 0 lists every branch
+	ListAfter(ctx context.Context, branchID, afterID int64, limit int) ([]*domain.Payment, error)
+	// GetByIdempotencyKey returns the payment a user created with an
+	// idempotency key, or nil when there is none
+	GetByIdempotencyKey(ctx context.Context, createdBy int64, key string) (*domain.Payment, error)
 	Create(ctx context.Context, payment *domain.Payment) error
 	Update(ctx context.Context, payment *domain.Payment) error
-	GenerateNumber(ctx context.Context) (string, error)
+	GenerateNumber(ctx context.Context, cadence domain.SequenceResetCadence) (string, error)
 }
 
 // PaymentListParams for filtering payment list
 type PaymentListParams struct {
 	PaginationParams
-	BranchID   int64                  `query:"branch_id"`
-	CustomerID *int64                 `query:"customer_id"`
-	LoanID     *int64                 `query:"loan_id"`
-	Status     *domain.PaymentStatus  `query:"status"`
-	Method     *domain.PaymentMethod  `query:"method"`
-	DateFrom   *string                `query:"date_from"`
-	DateTo     *string                `query:"date_to"`
+	BranchID      int64                 `query:"branch_id"`
+	CustomerID    *int64                `query:"customer_id"`
+	LoanID        *int64                `query:"loan_id"`
+	Status        *domain.PaymentStatus `query:"status"`
+	Method        *domain.PaymentMethod `query:"method"`
+	CashSessionID *int64                `query:"cash_session_id"`
+	DateFrom      *string               `query:"date_from"`
+	DateTo        *string               `query:"date_to"`
 }
 
 // SaleRepository defines methods for sale operations
@@ -209,12 +294,13 @@ type SaleRepository interface {
 // SaleListParams for filtering sale list
 type SaleListParams struct {
 	PaginationParams
-	BranchID   int64              `query:"branch_id"`
-	CustomerID *int64             `query:"customer_id"`
-	ItemID     *int64             `query:"item_id"`
-	Status     *domain.SaleStatus `query:"status"`
-	DateFrom   *string            `query:"date_from"`
-	DateTo     *string            `query:"date_to"`
+	BranchID      int64              `query:"branch_id"`
+	CustomerID    *int64             `query:"customer_id"`
+	ItemID        *int64             `query:"item_id"`
+	Status        *domain.SaleStatus `query:"status"`
+	CashSessionID *int64             `query:"cash_session_id"`
+	DateFrom      *string            `query:"date_from"`
+	DateTo        *string            `query:"date_to"`
 }
 
 // CashRegisterRepository defines methods for cash register operations
@@ -234,6 +320,12 @@ type CashSessionRepository interface {
 	Create(ctx context.Context, session *domain.CashSession) error
 	Update(ctx context.Context, session *domain.CashSession) error
 	Close(ctx context.Context, id int64, closingData CashSessionCloseData) error
+	// ListOpen lists every open session, oldest first
+	ListOpen(ctx context.Context) ([]*domain.CashSession, error)
+	// MarkOverdueNotified records that staff were told the session is still open
+	MarkOverdueNotified(ctx context.Context, id int64, notifiedAt time.Time) error
+	// ListDenominations lists the bills and coins counted when a session closed
+	ListDenominations(ctx context.Context, sessionID int64) ([]*domain.CashDenominationCount, error)
 }
 
 // CashSessionListParams for filtering cash session list
@@ -252,8 +344,15 @@ type CashSessionCloseData struct {
 	ClosingAmount  float64
 	ExpectedAmount float64
 	Difference     float64
-	ClosedBy       int64
+	ClosedBy       int64 // 0 when the system closes the session
 	ClosingNotes   string
+	AutoClosed     bool
+	// Currencies are the counted, expected and difference amounts of the
+	// session's other currencies
+	Currencies []domain.CashSessionCurrency
+	// Denominations are the bills and coins counted, when the cashier broke
+	// the count down
+	Denominations []domain.CashDenominationCount
 }
 
 // CashMovementRepository defines methods for cash movement operations
@@ -262,6 +361,13 @@ type CashMovementRepository interface {
 	List(ctx context.Context, params CashMovementListParams) (*PaginatedResult[domain.CashMovement], error)
 	ListBySession(ctx context.Context, sessionID int64) ([]*domain.CashMovement, error)
 	Create(ctx context.Context, movement *domain.CashMovement) error
+	// CreateTx creates a movement within a transaction, so it is saved or
+	// discarded together with the operation that moved the cash
+	CreateTx(ctx context.Context, tx Transaction, movement *domain.CashMovement) error
+	GetSessionBalance(ctx context.Context, sessionID int64) (float64, error)
+	// GetSessionCurrencyBalance retrieves the balance of one of the currencies a session holds
+	GetSessionCurrencyBalance(ctx context.Context, sessionID int64, currency string) (float64, error)
+	GetDailyLoanDisbursements(ctx context.Context, branchID int64, date time.Time) (float64, error)
 }
 
 // CashMovementListParams for filtering cash movement list
@@ -290,6 +396,17 @@ type SettingRepository interface {
 	GetAll(ctx context.Context, branchID *int64) ([]*domain.Setting, error)
 	Set(ctx context.Context, setting *domain.Setting) error
 	Delete(ctx context.Context, key string, branchID *int64) error
+	// Import saves and deletes global settings and records their history in
+	// a single transaction
+	Import(ctx context.Context, settings []*domain.Setting, deleteKeys []string, history []*domain.SettingHistory) error
+}
+
+// SettingHistoryRepository defines methods for the change history of settings
+type SettingHistoryRepository interface {
+	Create(ctx context.Context, entry *domain.SettingHistory) error
+	// ListByKey returns the latest changes to a setting, newest first. A nil
+	// branch lists the changes to the global setting.
+	ListByKey(ctx context.Context, key string, branchID *int64, limit int) ([]*domain.SettingHistory, error)
 }
 
 // AuditLogRepository defines methods for audit log operations
@@ -315,4 +432,176 @@ type DocumentRepository interface {
 	Create(ctx context.Context, doc *domain.Document) error
 	GetByID(ctx context.Context, id int64) (*domain.Document, error)
 	ListByReference(ctx context.Context, refType string, refID int64) ([]*domain.Document, error)
+	UpdateFile(ctx context.Context, doc *domain.Document) error
+}
+
+// JobRunRepository defines methods for scheduler job run tracking
+type JobRunRepository interface {
+	Create(ctx context.Context, run *domain.JobRun) error
+	ListStatuses(ctx context.Context) ([]*domain.JobStatus, error)
+}
+
+// BackupVerificationRepository defines methods for backup verification results
+type BackupVerificationRepository interface {
+	Create(ctx context.Context, verification *domain.BackupVerification) error
+}
+
+// NoteRepository defines methods for internal note operations
+type NoteRepository interface {
+	Create(ctx context.Context, note *domain.Note) error
+	GetByID(ctx context.Context, id int64) (*domain.Note, error)
+	ListByEntity(ctx context.Context, entityType domain.NoteEntityType, entityID int64) ([]*domain.Note, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// FXRateRepository defines methods for exchange rate operations
+type FXRateRepository interface {
+	Upsert(ctx context.Context, rate *domain.FXRate) error
+	// GetLatest retrieves the most recent rate of the pair on or before the given day
+	GetLatest(ctx context.Context, fromCurrency, toCurrency string, day domain.Date) (*domain.FXRate, error)
+	List(ctx context.Context, params FXRateListParams) ([]*domain.FXRate, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// FXRateListParams for filtering exchange rates
+type FXRateListParams struct {
+	FromCurrency *string `query:"from_currency"`
+	ToCurrency   *string `query:"to_currency"`
+	DateFrom     *string `query:"date_from"`
+	DateTo       *string `query:"date_to"`
+}
+
+// EventRepository defines methods for activity feed event operations
+type EventRepository interface {
+	Create(ctx context.Context, event *domain.Event) error
+	List(ctx context.Context, params EventListParams) (*PaginatedResult[domain.Event], error)
+}
+
+// EventListParams for filtering the activity feed
+type EventListParams struct {
+	PaginationParams
+	BranchID   *int64  `query:"branch_id"`
+	EventType  string  `query:"event_type"`
+	EntityType string  `query:"entity_type"`
+	EntityID   *int64  `query:"entity_id"`
+	DateFrom   *string `query:"date_from"`
+	DateTo     *string `query:"date_to"`
+}
+
+// WebhookRepository defines methods for webhook subscription operations
+type WebhookRepository interface {
+	GetByID(ctx context.Context, id int64) (*domain.Webhook, error)
+	List(ctx context.Context) ([]*domain.Webhook, error)
+	ListActive(ctx context.Context) ([]*domain.Webhook, error)
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// StockTakeRepository defines methods for physical inventory count operations
+type StockTakeRepository interface {
+	// Create starts a count, returning false when the branch already has one in progress
+	Create(ctx context.Context, stockTake *domain.StockTake) (bool, error)
+	GetByID(ctx context.Context, id int64) (*domain.StockTake, error)
+	// GetActiveByBranch retrieves the branch's count in progress, or nil if there is none
+	GetActiveByBranch(ctx context.Context, branchID int64) (*domain.StockTake, error)
+	List(ctx context.Context, params StockTakeListParams) (*PaginatedResult[domain.StockTake], error)
+	// AddScan records a scanned SKU, returning false when it was already scanned in the count
+	AddScan(ctx context.Context, scan *domain.StockTakeScan) (bool, error)
+	ListScans(ctx context.Context, stockTakeID int64) ([]*domain.StockTakeScan, error)
+	Finalize(ctx context.Context, id int64, finalizedBy int64, report *domain.StockTakeReport) error
+	Cancel(ctx context.Context, id int64, cancelledBy int64) error
+}
+
+// StockTakeListParams for filtering stock takes
+type StockTakeListParams struct {
+	PaginationParams
+	BranchID int64                   `query:"branch_id"`
+	Status   *domain.StockTakeStatus `query:"status"`
+}
+
+// StoredFileRepository defines methods for uploaded file metadata operations
+type StoredFileRepository interface {
+	Create(ctx context.Context, file *domain.StoredFile) error
+	DeleteByURL(ctx context.Context, url string) error
+	// GetByFileID returns the metadata of a stored file that has not been deleted
+	GetByFileID(ctx context.Context, fileID string) (*domain.StoredFile, error)
+	// GetBranchBytes returns the bytes used by a branch's uploads and generated documents
+	GetBranchBytes(ctx context.Context, branchID int64) (int64, error)
+	// ListUsage groups the storage used per branch and entity type, optionally for a single branch
+	ListUsage(ctx context.Context, branchID *int64) ([]*domain.StorageUsage, error)
+}
+
+// MarkdownRepository defines methods for automatic markdown of aging inventory
+type MarkdownRepository interface {
+	// ListCandidates lists the items currently for sale with when they were listed
+	// and how many markdown steps they received since
+	ListCandidates(ctx context.Context) ([]*domain.MarkdownCandidate, error)
+}
+
+// ItemAttentionRepository defines methods for the queue of items needing attention
+type ItemAttentionRepository interface {
+	// ListCandidates lists a branch's items that are confiscated, for sale or
+	// in transfer, along with those in custody without photos appraised at
+	// minPhotoValue or more
+	ListCandidates(ctx context.Context, branchID int64, minPhotoValue float64) ([]*domain.ItemAttentionCandidate, error)
+}
+
+// InventoryValuationRepository defines methods for valuing a branch's stock
+type InventoryValuationRepository interface {
+	// ListOnHand lists the items a branch owned and held at asOf: available,
+	// for sale, confiscated or in transfer at that time
+	ListOnHand(ctx context.Context, branchID int64, asOf time.Time) ([]*domain.InventoryItemValue, error)
+}
+
+// ContactVerificationRepository defines methods for customer phone verification codes
+type ContactVerificationRepository interface {
+	Create(ctx context.Context, verification *domain.ContactVerification) error
+	// GetLatest retrieves the customer's most recent code, or nil if none was sent
+	GetLatest(ctx context.Context, customerID int64) (*domain.ContactVerification, error)
+	IncrementAttempts(ctx context.Context, id int64) error
+	MarkVerified(ctx context.Context, id int64, verifiedAt time.Time) error
+}
+
+// CollectionRepository defines methods for the overdue loan collections queue
+type CollectionRepository interface {
+	// Create places a loan in the queue, returning false when it already has an open case
+	Create(ctx context.Context, collectionCase *domain.CollectionCase) (bool, error)
+	GetByID(ctx context.Context, id int64) (*domain.CollectionCase, error)
+	List(ctx context.Context, params CollectionListParams) (*PaginatedResult[domain.CollectionCase], error)
+	// ListOpen lists every case still in the queue; branchID 0 lists every branch
+	ListOpen(ctx context.Context, branchID int64) ([]*domain.CollectionCase, error)
+	Update(ctx context.Context, collectionCase *domain.CollectionCase) error
+	// Resolve takes an open case out of the queue
+	Resolve(ctx context.Context, id int64, resolvedAt time.Time) error
+	// AddContact logs a contact attempt and stamps the case's last contact
+	AddContact(ctx context.Context, contact *domain.CollectionContact) error
+	ListContacts(ctx context.Context, caseID int64) ([]*domain.CollectionContact, error)
+}
+
+// CollectionListParams for filtering collection cases. Resolved cases are only
+// listed when asked for by status.
+type CollectionListParams struct {
+	PaginationParams
+	BranchID   int64                    `query:"branch_id"`
+	Status     *domain.CollectionStatus `query:"status"`
+	AssignedTo *int64                   `query:"assigned_to"`
+}
+
+// ExportJobRepository defines methods for background CSV export jobs
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *domain.ExportJob) error
+	GetByID(ctx context.Context, id int64) (*domain.ExportJob, error)
+	// FindActive retrieves the user's unfinished export of a dataset and branch, or nil if there is none
+	FindActive(ctx context.Context, dataset domain.ExportDataset, branchID, createdBy int64) (*domain.ExportJob, error)
+	// ListUnfinished lists the pending and interrupted jobs, oldest first
+	ListUnfinished(ctx context.Context) ([]*domain.ExportJob, error)
+	// SaveProgress stores the job's status, cursor and counters
+	SaveProgress(ctx context.Context, job *domain.ExportJob) error
+}
+
+// UserActivityRepository defines methods for per-user activity reports
+type UserActivityRepository interface {
+	// ListUserActivity totals a user's activity in each branch they worked in
+	// from from up to, but not including, to
+	ListUserActivity(ctx context.Context, userID int64, from, to time.Time) ([]*domain.UserBranchActivity, error)
 }
@@ -0,0 +1,278 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// MockAccountRepository is a mock implementation of AccountRepository
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetByID(ctx context.Context, id int64) (*domain.Account, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetByCode(ctx context.Context, code string) (*domain.Account, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) Update(ctx context.Context, account *domain.Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) ListByType(ctx context.Context, accountType string) ([]*domain.Account, error) {
+	args := m.Called(ctx, accountType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) ListChildren(ctx context.Context, parentID int64) ([]*domain.Account, error) {
+	args := m.Called(ctx, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetTree(ctx context.Context) ([]*domain.Account, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Account), args.Error(1)
+}
+
+// MockAccountingEntryRepository is a mock implementation of AccountingEntryRepository
+type MockAccountingEntryRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountingEntryRepository) Create(ctx context.Context, entry *domain.AccountingEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAccountingEntryRepository) GetByID(ctx context.Context, id int64) (*domain.AccountingEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountingEntry), args.Error(1)
+}
+
+func (m *MockAccountingEntryRepository) GetByNumber(ctx context.Context, number string) (*domain.AccountingEntry, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountingEntry), args.Error(1)
+}
+
+func (m *MockAccountingEntryRepository) Update(ctx context.Context, entry *domain.AccountingEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAccountingEntryRepository) List(ctx context.Context, filter repository.AccountingEntryFilter) ([]*domain.AccountingEntry, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.AccountingEntry), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAccountingEntryRepository) ListByBranch(ctx context.Context, branchID int64, filter repository.AccountingEntryFilter) ([]*domain.AccountingEntry, int64, error) {
+	args := m.Called(ctx, branchID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.AccountingEntry), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAccountingEntryRepository) ListByReference(ctx context.Context, refType string, refID int64) ([]*domain.AccountingEntry, error) {
+	args := m.Called(ctx, refType, refID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AccountingEntry), args.Error(1)
+}
+
+func (m *MockAccountingEntryRepository) Post(ctx context.Context, id int64, postedBy int64) error {
+	args := m.Called(ctx, id, postedBy)
+	return args.Error(0)
+}
+
+func (m *MockAccountingEntryRepository) GenerateEntryNumber(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAccountingEntryRepository) GetAccountBalance(ctx context.Context, accountID int64, asOfDate time.Time) (float64, error) {
+	args := m.Called(ctx, accountID, asOfDate)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockAccountingEntryRepository) GetAccountBalanceByBranch(ctx context.Context, accountID int64, branchID int64, asOfDate time.Time) (float64, error) {
+	args := m.Called(ctx, accountID, branchID, asOfDate)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockAccountingEntryRepository) GetAccountTotals(ctx context.Context, branchID *int64, asOfDate time.Time) ([]repository.AccountTotals, error) {
+	args := m.Called(ctx, branchID, asOfDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.AccountTotals), args.Error(1)
+}
+
+// MockAccountingPeriodRepository is a mock implementation of AccountingPeriodRepository
+type MockAccountingPeriodRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountingPeriodRepository) GetOrCreate(ctx context.Context, branchID int64, year, month int) (*domain.AccountingPeriod, error) {
+	args := m.Called(ctx, branchID, year, month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountingPeriod), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepository) Get(ctx context.Context, branchID int64, year, month int) (*domain.AccountingPeriod, error) {
+	args := m.Called(ctx, branchID, year, month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountingPeriod), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepository) List(ctx context.Context, branchID *int64) ([]*domain.AccountingPeriod, error) {
+	args := m.Called(ctx, branchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AccountingPeriod), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepository) SaveAccruals(ctx context.Context, period *domain.AccountingPeriod, entry *domain.AccountingEntry) (bool, error) {
+	args := m.Called(ctx, period, entry)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepository) Close(ctx context.Context, id int64, closedBy int64) error {
+	args := m.Called(ctx, id, closedBy)
+	return args.Error(0)
+}
+
+// MockDailyBalanceRepository is a mock implementation of DailyBalanceRepository
+type MockDailyBalanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockDailyBalanceRepository) Create(ctx context.Context, balance *domain.DailyBalance) error {
+	args := m.Called(ctx, balance)
+	return args.Error(0)
+}
+
+func (m *MockDailyBalanceRepository) GetByID(ctx context.Context, id int64) (*domain.DailyBalance, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DailyBalance), args.Error(1)
+}
+
+func (m *MockDailyBalanceRepository) GetByBranchAndDate(ctx context.Context, branchID int64, date time.Time) (*domain.DailyBalance, error) {
+	args := m.Called(ctx, branchID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DailyBalance), args.Error(1)
+}
+
+func (m *MockDailyBalanceRepository) Update(ctx context.Context, balance *domain.DailyBalance) error {
+	args := m.Called(ctx, balance)
+	return args.Error(0)
+}
+
+func (m *MockDailyBalanceRepository) Upsert(ctx context.Context, balance *domain.DailyBalance) error {
+	args := m.Called(ctx, balance)
+	return args.Error(0)
+}
+
+func (m *MockDailyBalanceRepository) ListByBranch(ctx context.Context, branchID int64, dateFrom, dateTo time.Time) ([]*domain.DailyBalance, error) {
+	args := m.Called(ctx, branchID, dateFrom, dateTo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DailyBalance), args.Error(1)
+}
+
+func (m *MockDailyBalanceRepository) GetSummary(ctx context.Context, branchID *int64, dateFrom, dateTo time.Time) (*repository.DailyBalanceSummary, error) {
+	args := m.Called(ctx, branchID, dateFrom, dateTo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.DailyBalanceSummary), args.Error(1)
+}
+
+func (m *MockDailyBalanceRepository) UpsertBatch(ctx context.Context, balances []*domain.DailyBalance) error {
+	args := m.Called(ctx, balances)
+	return args.Error(0)
+}
+
+func (m *MockDailyBalanceRepository) GetDailyActivity(ctx context.Context, branchID int64, date time.Time) (*repository.DailyActivity, error) {
+	args := m.Called(ctx, branchID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.DailyActivity), args.Error(1)
+}
+
+// MockLoanBalanceSnapshotRepository is a mock implementation of LoanBalanceSnapshotRepository
+type MockLoanBalanceSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanBalanceSnapshotRepository) UpsertBatch(ctx context.Context, snapshots []*domain.LoanBalanceSnapshot) error {
+	args := m.Called(ctx, snapshots)
+	return args.Error(0)
+}
+
+func (m *MockLoanBalanceSnapshotRepository) ListByDate(ctx context.Context, branchID int64, date time.Time) ([]*domain.LoanBalanceSnapshot, error) {
+	args := m.Called(ctx, branchID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LoanBalanceSnapshot), args.Error(1)
+}
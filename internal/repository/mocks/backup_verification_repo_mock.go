@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockBackupVerificationRepository is a mock implementation of BackupVerificationRepository
+type MockBackupVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockBackupVerificationRepository) Create(ctx context.Context, verification *domain.BackupVerification) error {
+	args := m.Called(ctx, verification)
+	return args.Error(0)
+}
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockCampaignRepository is a mock implementation of CampaignRepository
+type MockCampaignRepository struct {
+	mock.Mock
+}
+
+func (m *MockCampaignRepository) GetByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) GetByCode(ctx context.Context, code string) (*domain.Campaign, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) List(ctx context.Context, activeOnly bool) ([]*domain.Campaign, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) ListRunning(ctx context.Context, day domain.Date) ([]*domain.Campaign, error) {
+	args := m.Called(ctx, day)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) Create(ctx context.Context, campaign *domain.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) Update(ctx context.Context, campaign *domain.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) FlagCustomer(ctx context.Context, campaignID, customerID int64) error {
+	args := m.Called(ctx, campaignID, customerID)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) UnflagCustomer(ctx context.Context, campaignID, customerID int64) error {
+	args := m.Called(ctx, campaignID, customerID)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) IsCustomerFlagged(ctx context.Context, campaignID, customerID int64) (bool, error) {
+	args := m.Called(ctx, campaignID, customerID)
+	return args.Bool(0), args.Error(1)
+}
@@ -2,10 +2,12 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/postgres"
 )
 
 // MockCashMovementRepository is a mock implementation of CashMovementRepository
@@ -41,3 +43,39 @@ func (m *MockCashMovementRepository) Create(ctx context.Context, movement *domai
 	args := m.Called(ctx, movement)
 	return args.Error(0)
 }
+
+func (m *MockCashMovementRepository) CreateTx(ctx context.Context, tx repository.Transaction, movement *domain.CashMovement) error {
+	args := m.Called(ctx, tx, movement)
+	return args.Error(0)
+}
+
+func (m *MockCashMovementRepository) GetSessionBalance(ctx context.Context, sessionID int64) (float64, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockCashMovementRepository) GetSessionCurrencyBalance(ctx context.Context, sessionID int64, currency string) (float64, error) {
+	args := m.Called(ctx, sessionID, currency)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockCashMovementRepository) GetDailyLoanDisbursements(ctx context.Context, branchID int64, date time.Time) (float64, error) {
+	args := m.Called(ctx, branchID, date)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockCashMovementRepository) GetSessionSummary(ctx context.Context, sessionID int64) (*postgres.CashSessionSummary, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.CashSessionSummary), args.Error(1)
+}
+
+func (m *MockCashMovementRepository) GetSessionCurrencySummary(ctx context.Context, sessionID int64, currency string) (*postgres.CashSessionSummary, error) {
+	args := m.Called(ctx, sessionID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.CashSessionSummary), args.Error(1)
+}
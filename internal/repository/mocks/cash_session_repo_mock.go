@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"pawnshop/internal/domain"
@@ -59,3 +60,24 @@ func (m *MockCashSessionRepository) Close(ctx context.Context, id int64, closing
 	args := m.Called(ctx, id, closingData)
 	return args.Error(0)
 }
+
+func (m *MockCashSessionRepository) ListOpen(ctx context.Context) ([]*domain.CashSession, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CashSession), args.Error(1)
+}
+
+func (m *MockCashSessionRepository) MarkOverdueNotified(ctx context.Context, id int64, notifiedAt time.Time) error {
+	args := m.Called(ctx, id, notifiedAt)
+	return args.Error(0)
+}
+
+func (m *MockCashSessionRepository) ListDenominations(ctx context.Context, sessionID int64) ([]*domain.CashDenominationCount, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CashDenominationCount), args.Error(1)
+}
@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// MockCollectionRepository is a mock implementation of CollectionRepository
+type MockCollectionRepository struct {
+	mock.Mock
+}
+
+func (m *MockCollectionRepository) Create(ctx context.Context, collectionCase *domain.CollectionCase) (bool, error) {
+	args := m.Called(ctx, collectionCase)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCollectionRepository) GetByID(ctx context.Context, id int64) (*domain.CollectionCase, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CollectionCase), args.Error(1)
+}
+
+func (m *MockCollectionRepository) List(ctx context.Context, params repository.CollectionListParams) (*repository.PaginatedResult[domain.CollectionCase], error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PaginatedResult[domain.CollectionCase]), args.Error(1)
+}
+
+func (m *MockCollectionRepository) ListOpen(ctx context.Context, branchID int64) ([]*domain.CollectionCase, error) {
+	args := m.Called(ctx, branchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CollectionCase), args.Error(1)
+}
+
+func (m *MockCollectionRepository) Update(ctx context.Context, collectionCase *domain.CollectionCase) error {
+	args := m.Called(ctx, collectionCase)
+	return args.Error(0)
+}
+
+func (m *MockCollectionRepository) Resolve(ctx context.Context, id int64, resolvedAt time.Time) error {
+	args := m.Called(ctx, id, resolvedAt)
+	return args.Error(0)
+}
+
+func (m *MockCollectionRepository) AddContact(ctx context.Context, contact *domain.CollectionContact) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *MockCollectionRepository) ListContacts(ctx context.Context, caseID int64) ([]*domain.CollectionContact, error) {
+	args := m.Called(ctx, caseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CollectionContact), args.Error(1)
+}
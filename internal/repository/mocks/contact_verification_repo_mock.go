@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockContactVerificationRepository is a mock implementation of ContactVerificationRepository
+type MockContactVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockContactVerificationRepository) Create(ctx context.Context, verification *domain.ContactVerification) error {
+	args := m.Called(ctx, verification)
+	return args.Error(0)
+}
+
+func (m *MockContactVerificationRepository) GetLatest(ctx context.Context, customerID int64) (*domain.ContactVerification, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContactVerification), args.Error(1)
+}
+
+func (m *MockContactVerificationRepository) IncrementAttempts(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockContactVerificationRepository) MarkVerified(ctx context.Context, id int64, verifiedAt time.Time) error {
+	args := m.Called(ctx, id, verifiedAt)
+	return args.Error(0)
+}
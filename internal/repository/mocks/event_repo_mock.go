@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// MockEventRepository is a mock implementation of EventRepository
+type MockEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventRepository) Create(ctx context.Context, event *domain.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventRepository) List(ctx context.Context, params repository.EventListParams) (*repository.PaginatedResult[domain.Event], error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PaginatedResult[domain.Event]), args.Error(1)
+}
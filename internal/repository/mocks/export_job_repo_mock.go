@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockExportJobRepository is a mock implementation of ExportJobRepository
+type MockExportJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockExportJobRepository) GetByID(ctx context.Context, id int64) (*domain.ExportJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobRepository) FindActive(ctx context.Context, dataset domain.ExportDataset, branchID, createdBy int64) (*domain.ExportJob, error) {
+	args := m.Called(ctx, dataset, branchID, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobRepository) ListUnfinished(ctx context.Context) ([]*domain.ExportJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobRepository) SaveProgress(ctx context.Context, job *domain.ExportJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
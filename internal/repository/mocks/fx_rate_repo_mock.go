@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// MockFXRateRepository is a mock implementation of FXRateRepository
+type MockFXRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockFXRateRepository) Upsert(ctx context.Context, rate *domain.FXRate) error {
+	args := m.Called(ctx, rate)
+	return args.Error(0)
+}
+
+func (m *MockFXRateRepository) GetLatest(ctx context.Context, fromCurrency, toCurrency string, day domain.Date) (*domain.FXRate, error) {
+	args := m.Called(ctx, fromCurrency, toCurrency, day)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FXRate), args.Error(1)
+}
+
+func (m *MockFXRateRepository) List(ctx context.Context, params repository.FXRateListParams) ([]*domain.FXRate, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.FXRate), args.Error(1)
+}
+
+func (m *MockFXRateRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockInventoryValuationRepository is a mock implementation of InventoryValuationRepository
+type MockInventoryValuationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryValuationRepository) ListOnHand(ctx context.Context, branchID int64, asOf time.Time) ([]*domain.InventoryItemValue, error) {
+	args := m.Called(ctx, branchID, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.InventoryItemValue), args.Error(1)
+}
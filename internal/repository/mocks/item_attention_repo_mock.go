@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockItemAttentionRepository is a mock implementation of ItemAttentionRepository
+type MockItemAttentionRepository struct {
+	mock.Mock
+}
+
+func (m *MockItemAttentionRepository) ListCandidates(ctx context.Context, branchID int64, minPhotoValue float64) ([]*domain.ItemAttentionCandidate, error) {
+	args := m.Called(ctx, branchID, minPhotoValue)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ItemAttentionCandidate), args.Error(1)
+}
@@ -52,6 +52,19 @@ func (m *MockItemRepository) Delete(ctx context.Context, id int64) error {
 	return args.Error(0)
 }
 
+func (m *MockItemRepository) GetDeletedByID(ctx context.Context, id int64) (*domain.Item, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Item), args.Error(1)
+}
+
+func (m *MockItemRepository) Restore(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockItemRepository) UpdateStatus(ctx context.Context, id int64, status domain.ItemStatus) error {
 	args := m.Called(ctx, id, status)
 	return args.Error(0)
@@ -66,3 +79,11 @@ func (m *MockItemRepository) CreateHistory(ctx context.Context, history *domain.
 	args := m.Called(ctx, history)
 	return args.Error(0)
 }
+
+func (m *MockItemRepository) ListAfter(ctx context.Context, branchID, afterID int64, limit int) ([]*domain.Item, error) {
+	args := m.Called(ctx, branchID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Item), args.Error(1)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockLoanProductRepository is a mock implementation of LoanProductRepository
+type MockLoanProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanProductRepository) GetByID(ctx context.Context, id int64) (*domain.LoanProduct, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanProduct), args.Error(1)
+}
+
+func (m *MockLoanProductRepository) GetByCode(ctx context.Context, code string) (*domain.LoanProduct, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanProduct), args.Error(1)
+}
+
+func (m *MockLoanProductRepository) List(ctx context.Context, activeOnly bool) ([]*domain.LoanProduct, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LoanProduct), args.Error(1)
+}
+
+func (m *MockLoanProductRepository) Create(ctx context.Context, product *domain.LoanProduct) error {
+	args := m.Called(ctx, product)
+	return args.Error(0)
+}
+
+func (m *MockLoanProductRepository) Update(ctx context.Context, product *domain.LoanProduct) error {
+	args := m.Called(ctx, product)
+	return args.Error(0)
+}
+
+func (m *MockLoanProductRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
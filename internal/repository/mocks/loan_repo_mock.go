@@ -29,6 +29,14 @@ func (m *MockLoanRepository) GetByNumber(ctx context.Context, loanNumber string)
 	return args.Get(0).(*domain.Loan), args.Error(1)
 }
 
+func (m *MockLoanRepository) GetActiveByItemID(ctx context.Context, itemID int64) (*domain.Loan, error) {
+	args := m.Called(ctx, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Loan), args.Error(1)
+}
+
 func (m *MockLoanRepository) List(ctx context.Context, params repository.LoanListParams) (*repository.PaginatedResult[domain.Loan], error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
@@ -47,8 +55,8 @@ func (m *MockLoanRepository) Update(ctx context.Context, loan *domain.Loan) erro
 	return args.Error(0)
 }
 
-func (m *MockLoanRepository) GenerateNumber(ctx context.Context) (string, error) {
-	args := m.Called(ctx)
+func (m *MockLoanRepository) GenerateNumber(ctx context.Context, cadence domain.SequenceResetCadence) (string, error) {
+	args := m.Called(ctx, cadence)
 	return args.String(0), args.Error(1)
 }
 
@@ -65,6 +73,26 @@ func (m *MockLoanRepository) UpdateStatus(ctx context.Context, id int64, status
 	return args.Error(0)
 }
 
+func (m *MockLoanRepository) AuthorizeDisbursement(ctx context.Context, id int64, authorizedBy int64) error {
+	args := m.Called(ctx, id, authorizedBy)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) RevokeAuthorization(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) SetContract(ctx context.Context, id int64, documentID int64, url, hash string) error {
+	args := m.Called(ctx, id, documentID, url, hash)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) SetTags(ctx context.Context, id int64, tags []string) error {
+	args := m.Called(ctx, id, tags)
+	return args.Error(0)
+}
+
 func (m *MockLoanRepository) BeginTx(ctx context.Context) (repository.Transaction, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -73,6 +101,11 @@ func (m *MockLoanRepository) BeginTx(ctx context.Context) (repository.Transactio
 	return args.Get(0).(repository.Transaction), args.Error(1)
 }
 
+func (m *MockLoanRepository) Renew(ctx context.Context, loan *domain.Loan, renewed *domain.Loan, payment *domain.Payment, movement *domain.CashMovement) error {
+	args := m.Called(ctx, loan, renewed, payment, movement)
+	return args.Error(0)
+}
+
 func (m *MockLoanRepository) CreateTx(ctx context.Context, tx repository.Transaction, loan *domain.Loan) error {
 	args := m.Called(ctx, tx, loan)
 	return args.Error(0)
@@ -100,3 +133,8 @@ func (m *MockLoanRepository) UpdateInstallment(ctx context.Context, installment
 	args := m.Called(ctx, installment)
 	return args.Error(0)
 }
+
+func (m *MockLoanRepository) ReplaceInstallments(ctx context.Context, loanID int64, installments []*domain.LoanInstallment) error {
+	args := m.Called(ctx, loanID, installments)
+	return args.Error(0)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// MockLoanWriteOffRepository is a mock implementation of LoanWriteOffRepository
+type MockLoanWriteOffRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanWriteOffRepository) Create(ctx context.Context, writeOff *domain.LoanWriteOff, entry *domain.AccountingEntry) error {
+	args := m.Called(ctx, writeOff, entry)
+	return args.Error(0)
+}
+
+func (m *MockLoanWriteOffRepository) List(ctx context.Context, params repository.LoanWriteOffListParams) ([]*domain.LoanWriteOff, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LoanWriteOff), args.Error(1)
+}
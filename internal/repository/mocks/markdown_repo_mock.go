@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockMarkdownRepository is a mock implementation of MarkdownRepository
+type MockMarkdownRepository struct {
+	mock.Mock
+}
+
+func (m *MockMarkdownRepository) ListCandidates(ctx context.Context) ([]*domain.MarkdownCandidate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MarkdownCandidate), args.Error(1)
+}
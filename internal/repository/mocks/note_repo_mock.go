@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockNoteRepository is a mock implementation of NoteRepository
+type MockNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockNoteRepository) Create(ctx context.Context, note *domain.Note) error {
+	args := m.Called(ctx, note)
+	return args.Error(0)
+}
+
+func (m *MockNoteRepository) GetByID(ctx context.Context, id int64) (*domain.Note, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Note), args.Error(1)
+}
+
+func (m *MockNoteRepository) ListByEntity(ctx context.Context, entityType domain.NoteEntityType, entityID int64) ([]*domain.Note, error) {
+	args := m.Called(ctx, entityType, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Note), args.Error(1)
+}
+
+func (m *MockNoteRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
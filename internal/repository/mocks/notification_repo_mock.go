@@ -113,6 +113,14 @@ func (m *MockNotificationRepository) ListPending(ctx context.Context, limit int)
 	return args.Get(0).([]*domain.Notification), args.Error(1)
 }
 
+func (m *MockNotificationRepository) ListPendingExcept(ctx context.Context, limit int, channels []string) ([]*domain.Notification, error) {
+	args := m.Called(ctx, limit, channels)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Notification), args.Error(1)
+}
+
 func (m *MockNotificationRepository) ListScheduled(ctx context.Context, before time.Time, limit int) ([]*domain.Notification, error) {
 	args := m.Called(ctx, before, limit)
 	if args.Get(0) == nil {
@@ -121,8 +129,8 @@ func (m *MockNotificationRepository) ListScheduled(ctx context.Context, before t
 	return args.Get(0).([]*domain.Notification), args.Error(1)
 }
 
-func (m *MockNotificationRepository) ListFailed(ctx context.Context, maxRetries int, limit int) ([]*domain.Notification, error) {
-	args := m.Called(ctx, maxRetries, limit)
+func (m *MockNotificationRepository) ListFailed(ctx context.Context, maxRetries int, backoffBase time.Duration, limit int) ([]*domain.Notification, error) {
+	args := m.Called(ctx, maxRetries, backoffBase, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -134,6 +142,11 @@ func (m *MockNotificationRepository) MarkAsSent(ctx context.Context, id int64) e
 	return args.Error(0)
 }
 
+func (m *MockNotificationRepository) RedactBody(ctx context.Context, id int64, body string) error {
+	args := m.Called(ctx, id, body)
+	return args.Error(0)
+}
+
 func (m *MockNotificationRepository) MarkAsDelivered(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -144,11 +157,29 @@ func (m *MockNotificationRepository) MarkAsFailed(ctx context.Context, id int64,
 	return args.Error(0)
 }
 
-func (m *MockNotificationRepository) IncrementRetry(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *MockNotificationRepository) ApplyDeliveryReceipt(ctx context.Context, id int64, status, reason string) (bool, error) {
+	args := m.Called(ctx, id, status, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) IncrementRetry(ctx context.Context, id int64, scheduledFor time.Time) error {
+	args := m.Called(ctx, id, scheduledFor)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) SetExternalMessageID(ctx context.Context, id int64, externalMessageID string) error {
+	args := m.Called(ctx, id, externalMessageID)
 	return args.Error(0)
 }
 
+func (m *MockNotificationRepository) GetByExternalMessageID(ctx context.Context, externalMessageID string) (*domain.Notification, error) {
+	args := m.Called(ctx, externalMessageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Notification), args.Error(1)
+}
+
 func (m *MockNotificationRepository) Cancel(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -224,11 +255,63 @@ func (m *MockCustomerNotificationPreferenceRepository) IsEnabled(ctx context.Con
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockCustomerNotificationPreferenceRepository) ListEnabledByType(ctx context.Context, notificationType string) ([]*domain.CustomerNotificationPreference, error) {
+	args := m.Called(ctx, notificationType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CustomerNotificationPreference), args.Error(1)
+}
+
 func (m *MockCustomerNotificationPreferenceRepository) BulkUpsert(ctx context.Context, customerID int64, prefs []*domain.CustomerNotificationPreference) error {
 	args := m.Called(ctx, customerID, prefs)
 	return args.Error(0)
 }
 
+// MockNotificationChannelStatusRepository is a mock implementation
+type MockNotificationChannelStatusRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationChannelStatusRepository) Get(ctx context.Context, customerID int64, channel string) (*domain.NotificationChannelStatus, error) {
+	args := m.Called(ctx, customerID, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationChannelStatus), args.Error(1)
+}
+
+func (m *MockNotificationChannelStatusRepository) ListByCustomer(ctx context.Context, customerID int64) ([]*domain.NotificationChannelStatus, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.NotificationChannelStatus), args.Error(1)
+}
+
+func (m *MockNotificationChannelStatusRepository) RecordFailure(ctx context.Context, customerID int64, channel string, windowStart time.Time) (*domain.NotificationChannelStatus, error) {
+	args := m.Called(ctx, customerID, channel, windowStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationChannelStatus), args.Error(1)
+}
+
+func (m *MockNotificationChannelStatusRepository) ResetStreak(ctx context.Context, customerID int64, channel string) error {
+	args := m.Called(ctx, customerID, channel)
+	return args.Error(0)
+}
+
+func (m *MockNotificationChannelStatusRepository) Pause(ctx context.Context, customerID int64, channel, reason string) error {
+	args := m.Called(ctx, customerID, channel, reason)
+	return args.Error(0)
+}
+
+func (m *MockNotificationChannelStatusRepository) Resume(ctx context.Context, customerID int64, channel string) error {
+	args := m.Called(ctx, customerID, channel)
+	return args.Error(0)
+}
+
 // MockInternalNotificationRepository is a mock implementation
 type MockInternalNotificationRepository struct {
 	mock.Mock
@@ -305,3 +388,55 @@ func (m *MockInternalNotificationRepository) DeleteOlderThan(ctx context.Context
 	args := m.Called(ctx, olderThan)
 	return args.Get(0).(int64), args.Error(1)
 }
+
+func (m *MockInternalNotificationRepository) ListDigestPending(ctx context.Context, userID int64) ([]*domain.InternalNotification, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.InternalNotification), args.Error(1)
+}
+
+func (m *MockInternalNotificationRepository) CreateDigest(ctx context.Context, digest *domain.InternalNotification, ids []int64) error {
+	args := m.Called(ctx, digest, ids)
+	return args.Error(0)
+}
+
+// MockUserNotificationPreferenceRepository is a mock implementation
+type MockUserNotificationPreferenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserNotificationPreferenceRepository) Get(ctx context.Context, userID int64) (*domain.UserNotificationPreference, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserNotificationPreference), args.Error(1)
+}
+
+func (m *MockUserNotificationPreferenceRepository) Upsert(ctx context.Context, pref *domain.UserNotificationPreference) error {
+	args := m.Called(ctx, pref)
+	return args.Error(0)
+}
+
+func (m *MockUserNotificationPreferenceRepository) ListDigestUsers(ctx context.Context) ([]*domain.UserNotificationPreference, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UserNotificationPreference), args.Error(1)
+}
+
+func (m *MockUserNotificationPreferenceRepository) ListDigestUserIDs(ctx context.Context, userIDs []int64) ([]int64, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockUserNotificationPreferenceRepository) MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	args := m.Called(ctx, userID, sentAt)
+	return args.Error(0)
+}
@@ -45,6 +45,22 @@ func (m *MockPaymentRepository) ListByLoan(ctx context.Context, loanID int64) ([
 	return args.Get(0).([]*domain.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) ListAfter(ctx context.Context, branchID, afterID int64, limit int) ([]*domain.Payment, error) {
+	args := m.Called(ctx, branchID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetByIdempotencyKey(ctx context.Context, createdBy int64, key string) (*domain.Payment, error) {
+	args := m.Called(ctx, createdBy, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
 func (m *MockPaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
 	args := m.Called(ctx, payment)
 	return args.Error(0)
@@ -55,7 +71,7 @@ func (m *MockPaymentRepository) Update(ctx context.Context, payment *domain.Paym
 	return args.Error(0)
 }
 
-func (m *MockPaymentRepository) GenerateNumber(ctx context.Context) (string, error) {
-	args := m.Called(ctx)
+func (m *MockPaymentRepository) GenerateNumber(ctx context.Context, cadence domain.SequenceResetCadence) (string, error) {
+	args := m.Called(ctx, cadence)
 	return args.String(0), args.Error(1)
 }
@@ -37,3 +37,26 @@ func (m *MockSettingRepository) Delete(ctx context.Context, key string, branchID
 	args := m.Called(ctx, key, branchID)
 	return args.Error(0)
 }
+
+func (m *MockSettingRepository) Import(ctx context.Context, settings []*domain.Setting, deleteKeys []string, history []*domain.SettingHistory) error {
+	args := m.Called(ctx, settings, deleteKeys, history)
+	return args.Error(0)
+}
+
+// MockSettingHistoryRepository is a mock implementation of SettingHistoryRepository
+type MockSettingHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingHistoryRepository) Create(ctx context.Context, entry *domain.SettingHistory) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockSettingHistoryRepository) ListByKey(ctx context.Context, key string, branchID *int64, limit int) ([]*domain.SettingHistory, error) {
+	args := m.Called(ctx, key, branchID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SettingHistory), args.Error(1)
+}
@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// MockStockTakeRepository is a mock implementation of StockTakeRepository
+type MockStockTakeRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockTakeRepository) Create(ctx context.Context, stockTake *domain.StockTake) (bool, error) {
+	args := m.Called(ctx, stockTake)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStockTakeRepository) GetByID(ctx context.Context, id int64) (*domain.StockTake, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockTake), args.Error(1)
+}
+
+func (m *MockStockTakeRepository) GetActiveByBranch(ctx context.Context, branchID int64) (*domain.StockTake, error) {
+	args := m.Called(ctx, branchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockTake), args.Error(1)
+}
+
+func (m *MockStockTakeRepository) List(ctx context.Context, params repository.StockTakeListParams) (*repository.PaginatedResult[domain.StockTake], error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PaginatedResult[domain.StockTake]), args.Error(1)
+}
+
+func (m *MockStockTakeRepository) AddScan(ctx context.Context, scan *domain.StockTakeScan) (bool, error) {
+	args := m.Called(ctx, scan)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStockTakeRepository) ListScans(ctx context.Context, stockTakeID int64) ([]*domain.StockTakeScan, error) {
+	args := m.Called(ctx, stockTakeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockTakeScan), args.Error(1)
+}
+
+func (m *MockStockTakeRepository) Finalize(ctx context.Context, id int64, finalizedBy int64, report *domain.StockTakeReport) error {
+	args := m.Called(ctx, id, finalizedBy, report)
+	return args.Error(0)
+}
+
+func (m *MockStockTakeRepository) Cancel(ctx context.Context, id int64, cancelledBy int64) error {
+	args := m.Called(ctx, id, cancelledBy)
+	return args.Error(0)
+}
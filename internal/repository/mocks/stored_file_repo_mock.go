@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockStoredFileRepository is a mock implementation of StoredFileRepository
+type MockStoredFileRepository struct {
+	mock.Mock
+}
+
+func (m *MockStoredFileRepository) Create(ctx context.Context, file *domain.StoredFile) error {
+	args := m.Called(ctx, file)
+	return args.Error(0)
+}
+
+func (m *MockStoredFileRepository) DeleteByURL(ctx context.Context, url string) error {
+	args := m.Called(ctx, url)
+	return args.Error(0)
+}
+
+func (m *MockStoredFileRepository) GetByFileID(ctx context.Context, fileID string) (*domain.StoredFile, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StoredFile), args.Error(1)
+}
+
+func (m *MockStoredFileRepository) GetBranchBytes(ctx context.Context, branchID int64) (int64, error) {
+	args := m.Called(ctx, branchID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStoredFileRepository) ListUsage(ctx context.Context, branchID *int64) ([]*domain.StorageUsage, error) {
+	args := m.Called(ctx, branchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StorageUsage), args.Error(1)
+}
@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+)
+
+// MockUserActivityRepository is a mock implementation of UserActivityRepository
+type MockUserActivityRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserActivityRepository) ListUserActivity(ctx context.Context, userID int64, from, to time.Time) ([]*domain.UserBranchActivity, error) {
+	args := m.Called(ctx, userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UserBranchActivity), args.Error(1)
+}
@@ -54,23 +54,44 @@ type NotificationRepository interface {
 	// ListPending retrieves pending notifications ready to send
 	ListPending(ctx context.Context, limit int) ([]*domain.Notification, error)
 
+	// ListPendingExcept retrieves pending notifications ready to send on any
+	// channel but the given ones
+	ListPendingExcept(ctx context.Context, limit int, channels []string) ([]*domain.Notification, error)
+
 	// ListScheduled retrieves scheduled notifications ready to send
 	ListScheduled(ctx context.Context, before time.Time, limit int) ([]*domain.Notification, error)
 
-	// ListFailed retrieves failed notifications that can be retried
-	ListFailed(ctx context.Context, maxRetries int, limit int) ([]*domain.Notification, error)
+	// ListFailed retrieves failed notifications that can be retried and whose
+	// backoff, backoffBase doubled for every retry made, has elapsed
+	ListFailed(ctx context.Context, maxRetries int, backoffBase time.Duration, limit int) ([]*domain.Notification, error)
 
 	// MarkAsSent marks a notification as sent
 	MarkAsSent(ctx context.Context, id int64) error
 
+	// RedactBody replaces the body of a notification
+	RedactBody(ctx context.Context, id int64, body string) error
+
 	// MarkAsDelivered marks a notification as delivered
 	MarkAsDelivered(ctx context.Context, id int64) error
 
 	// MarkAsFailed marks a notification as failed
 	MarkAsFailed(ctx context.Context, id int64, reason string) error
 
-	// IncrementRetry increments the retry count
-	IncrementRetry(ctx context.Context, id int64) error
+	// ApplyDeliveryReceipt marks a sent notification as delivered or failed, as
+	// its provider reported. It reports false, changing nothing, when the
+	// notification is no longer sent.
+	ApplyDeliveryReceipt(ctx context.Context, id int64, status, reason string) (bool, error)
+
+	// IncrementRetry increments the retry count and queues the notification
+	// again to be sent at scheduledFor
+	IncrementRetry(ctx context.Context, id int64, scheduledFor time.Time) error
+
+	// SetExternalMessageID stores the ID the provider gave a sent notification
+	SetExternalMessageID(ctx context.Context, id int64, externalMessageID string) error
+
+	// GetByExternalMessageID retrieves the notification a provider message ID
+	// belongs to, nil if there is none
+	GetByExternalMessageID(ctx context.Context, externalMessageID string) (*domain.Notification, error)
 
 	// Cancel cancels a pending notification
 	Cancel(ctx context.Context, id int64) error
@@ -131,10 +152,36 @@ type CustomerNotificationPreferenceRepository interface {
 	// IsEnabled checks if a notification type/channel is enabled for a customer
 	IsEnabled(ctx context.Context, customerID int64, notificationType, channel string) (bool, error)
 
+	// ListEnabledByType retrieves the explicitly enabled preferences for a
+	// notification type, for notifications customers must opt in to
+	ListEnabledByType(ctx context.Context, notificationType string) ([]*domain.CustomerNotificationPreference, error)
+
 	// BulkUpsert creates or updates multiple preferences
 	BulkUpsert(ctx context.Context, customerID int64, prefs []*domain.CustomerNotificationPreference) error
 }
 
+// NotificationChannelStatusRepository defines the interface for customer channel delivery health
+type NotificationChannelStatusRepository interface {
+	// Get retrieves the status of a customer's channel, nil when nothing was recorded
+	Get(ctx context.Context, customerID int64, channel string) (*domain.NotificationChannelStatus, error)
+
+	// ListByCustomer retrieves the statuses of all channels of a customer
+	ListByCustomer(ctx context.Context, customerID int64) ([]*domain.NotificationChannelStatus, error)
+
+	// RecordFailure adds an exhausted notification to the failure streak, restarting
+	// the streak when it began before windowStart, and returns the updated status
+	RecordFailure(ctx context.Context, customerID int64, channel string, windowStart time.Time) (*domain.NotificationChannelStatus, error)
+
+	// ResetStreak clears the failure streak after a successful send
+	ResetStreak(ctx context.Context, customerID int64, channel string) error
+
+	// Pause pauses automatic sends to the channel
+	Pause(ctx context.Context, customerID int64, channel, reason string) error
+
+	// Resume lifts a pause and clears the failure streak
+	Resume(ctx context.Context, customerID int64, channel string) error
+}
+
 // InternalNotificationRepository defines the interface for internal notification operations
 type InternalNotificationRepository interface {
 	// Create creates a new internal notification
@@ -172,6 +219,31 @@ type InternalNotificationRepository interface {
 
 	// DeleteOlderThan deletes notifications older than a date
 	DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+
+	// ListDigestPending retrieves the notifications held back for a user's digest, oldest first
+	ListDigestPending(ctx context.Context, userID int64) ([]*domain.InternalNotification, error)
+
+	// CreateDigest creates a digest notification and, in the same transaction,
+	// marks the notifications it covers as delivered and read
+	CreateDigest(ctx context.Context, digest *domain.InternalNotification, ids []int64) error
+}
+
+// UserNotificationPreferenceRepository defines the interface for how users receive internal notifications
+type UserNotificationPreferenceRepository interface {
+	// Get retrieves a user's preference, nil when the user never set one
+	Get(ctx context.Context, userID int64) (*domain.UserNotificationPreference, error)
+
+	// Upsert creates or replaces a user's preference
+	Upsert(ctx context.Context, pref *domain.UserNotificationPreference) error
+
+	// ListDigestUsers retrieves the preferences of users in digest mode
+	ListDigestUsers(ctx context.Context) ([]*domain.UserNotificationPreference, error)
+
+	// ListDigestUserIDs returns which of the given users are in digest mode
+	ListDigestUserIDs(ctx context.Context, userIDs []int64) ([]int64, error)
+
+	// MarkDigestSent records when a user's last digest was sent
+	MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error
 }
 
 // InternalNotificationFilter contains filters for listing internal notifications
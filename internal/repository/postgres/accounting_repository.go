@@ -259,22 +259,36 @@ func NewAccountingEntryRepository(db *DB) repository.AccountingEntryRepository {
 }
 
 func (r *accountingEntryRepository) Create(ctx context.Context, entry *domain.AccountingEntry) error {
+	// Unbalanced entries never reach the books
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
 	tx, err := r.db.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Insert entry
+	if err := insertAccountingEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertAccountingEntry inserts an entry and its lines. An entry marked as
+// posted is stored posted by its PostedBy user, so it never sits unposted.
+func insertAccountingEntry(ctx context.Context, q Querier, entry *domain.AccountingEntry) error {
 	query := `
 		INSERT INTO accounting_entries (
 			entry_number, branch_id, entry_date, description,
 			reference_type, reference_id, total_debit, total_credit,
-			is_posted, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, created_at, updated_at`
+			is_posted, posted_by, posted_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CASE WHEN $9::boolean THEN NOW() END, $11)
+		RETURNING id, posted_at, created_at, updated_at`
 
-	err = tx.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		entry.EntryNumber,
 		entry.BranchID,
 		entry.EntryDate,
@@ -284,13 +298,13 @@ func (r *accountingEntryRepository) Create(ctx context.Context, entry *domain.Ac
 		entry.TotalDebit,
 		entry.TotalCredit,
 		entry.IsPosted,
+		entry.PostedBy,
 		entry.CreatedBy,
-	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	).Scan(&entry.ID, &entry.PostedAt, &entry.CreatedAt, &entry.UpdatedAt)
 	if err != nil {
 		return err
 	}
 
-	// Insert lines
 	for _, line := range entry.Lines {
 		line.EntryID = entry.ID
 		lineQuery := `
@@ -298,7 +312,7 @@ func (r *accountingEntryRepository) Create(ctx context.Context, entry *domain.Ac
 			VALUES ($1, $2, $3, $4, $5)
 			RETURNING id, created_at`
 
-		err = tx.QueryRowContext(ctx, lineQuery,
+		err = q.QueryRowContext(ctx, lineQuery,
 			line.EntryID,
 			line.AccountID,
 			line.EntryType,
@@ -310,7 +324,7 @@ func (r *accountingEntryRepository) Create(ctx context.Context, entry *domain.Ac
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (r *accountingEntryRepository) GetByID(ctx context.Context, id int64) (*domain.AccountingEntry, error) {
@@ -597,20 +611,30 @@ func (r *accountingEntryRepository) Post(ctx context.Context, id int64, postedBy
 	return nil
 }
 
+// GenerateEntryNumber reserves the next daily entry number. The per-day counter
+// is incremented atomically in accounting_entry_sequences, so concurrent callers
+// never receive the same number; numbers reserved by failed inserts are skipped.
 func (r *accountingEntryRepository) GenerateEntryNumber(ctx context.Context) (string, error) {
 	now := time.Now()
-	prefix := fmt.Sprintf("JE-%s-", now.Format("20060102"))
 
 	query := `
-		SELECT COUNT(*) + 1 FROM accounting_entries
-		WHERE entry_number LIKE $1`
+		INSERT INTO accounting_entry_sequences (sequence_date, last_value)
+		VALUES ($1, 1)
+		ON CONFLICT (sequence_date)
+		DO UPDATE SET last_value = accounting_entry_sequences.last_value + 1
+		RETURNING last_value`
 
 	var seq int
-	if err := r.db.QueryRowContext(ctx, query, prefix+"%").Scan(&seq); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, now.Format("2006-01-02")).Scan(&seq); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s%04d", prefix, seq), nil
+	return formatEntryNumber(now, seq), nil
+}
+
+// formatEntryNumber formats a daily entry number as JE-YYYYMMDD-NNNN
+func formatEntryNumber(date time.Time, seq int) string {
+	return fmt.Sprintf("JE-%s-%04d", date.Format("20060102"), seq)
 }
 
 func (r *accountingEntryRepository) GetAccountBalance(ctx context.Context, accountID int64, asOfDate time.Time) (float64, error) {
@@ -647,6 +671,38 @@ func (r *accountingEntryRepository) GetAccountBalanceByBranch(ctx context.Contex
 	return balance, nil
 }
 
+func (r *accountingEntryRepository) GetAccountTotals(ctx context.Context, branchID *int64, asOfDate time.Time) ([]repository.AccountTotals, error) {
+	query := `
+		SELECT l.account_id,
+			   COALESCE(SUM(CASE WHEN l.entry_type = 'debit' THEN l.amount ELSE 0 END), 0),
+			   COALESCE(SUM(CASE WHEN l.entry_type = 'credit' THEN l.amount ELSE 0 END), 0)
+		FROM accounting_entry_lines l
+		JOIN accounting_entries e ON l.entry_id = e.id
+		WHERE e.entry_date <= $1 AND e.is_posted = true`
+	args := []interface{}{asOfDate}
+	if branchID != nil {
+		query += " AND e.branch_id = $2"
+		args = append(args, *branchID)
+	}
+	query += " GROUP BY l.account_id ORDER BY l.account_id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []repository.AccountTotals
+	for rows.Next() {
+		var t repository.AccountTotals
+		if err := rows.Scan(&t.AccountID, &t.Debit, &t.Credit); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
 // Daily Balance Repository
 type dailyBalanceRepository struct {
 	db *DB
@@ -802,32 +858,32 @@ func (r *dailyBalanceRepository) Update(ctx context.Context, balance *domain.Dai
 	).Scan(&balance.UpdatedAt)
 }
 
-func (r *dailyBalanceRepository) Upsert(ctx context.Context, balance *domain.DailyBalance) error {
-	query := `
-		INSERT INTO daily_balances (
-			branch_id, balance_date, loan_disbursements, interest_income,
-			late_fee_income, sales_income, other_income, operational_expenses,
-			refunds, other_expenses, cash_opening, cash_closing,
-			total_loans_active, total_loans_count, net_income
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
-		ON CONFLICT (branch_id, balance_date) DO UPDATE SET
-			loan_disbursements = EXCLUDED.loan_disbursements,
-			interest_income = EXCLUDED.interest_income,
-			late_fee_income = EXCLUDED.late_fee_income,
-			sales_income = EXCLUDED.sales_income,
-			other_income = EXCLUDED.other_income,
-			operational_expenses = EXCLUDED.operational_expenses,
-			refunds = EXCLUDED.refunds,
-			other_expenses = EXCLUDED.other_expenses,
-			cash_opening = EXCLUDED.cash_opening,
-			cash_closing = EXCLUDED.cash_closing,
-			total_loans_active = EXCLUDED.total_loans_active,
-			total_loans_count = EXCLUDED.total_loans_count,
-			net_income = EXCLUDED.net_income,
-			updated_at = NOW()
-		RETURNING id, created_at, updated_at`
-
-	return r.db.QueryRowContext(ctx, query,
+const upsertDailyBalanceQuery = `
+	INSERT INTO daily_balances (
+		branch_id, balance_date, loan_disbursements, interest_income,
+		late_fee_income, sales_income, other_income, operational_expenses,
+		refunds, other_expenses, cash_opening, cash_closing,
+		total_loans_active, total_loans_count, net_income
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	ON CONFLICT (branch_id, balance_date) DO UPDATE SET
+		loan_disbursements = EXCLUDED.loan_disbursements,
+		interest_income = EXCLUDED.interest_income,
+		late_fee_income = EXCLUDED.late_fee_income,
+		sales_income = EXCLUDED.sales_income,
+		other_income = EXCLUDED.other_income,
+		operational_expenses = EXCLUDED.operational_expenses,
+		refunds = EXCLUDED.refunds,
+		other_expenses = EXCLUDED.other_expenses,
+		cash_opening = EXCLUDED.cash_opening,
+		cash_closing = EXCLUDED.cash_closing,
+		total_loans_active = EXCLUDED.total_loans_active,
+		total_loans_count = EXCLUDED.total_loans_count,
+		net_income = EXCLUDED.net_income,
+		updated_at = NOW()
+	RETURNING id, created_at, updated_at`
+
+func upsertDailyBalance(ctx context.Context, q Querier, balance *domain.DailyBalance) error {
+	return q.QueryRowContext(ctx, upsertDailyBalanceQuery,
 		balance.BranchID,
 		balance.BalanceDate,
 		balance.LoanDisbursements,
@@ -846,6 +902,85 @@ func (r *dailyBalanceRepository) Upsert(ctx context.Context, balance *domain.Dai
 	).Scan(&balance.ID, &balance.CreatedAt, &balance.UpdatedAt)
 }
 
+func (r *dailyBalanceRepository) Upsert(ctx context.Context, balance *domain.DailyBalance) error {
+	return upsertDailyBalance(ctx, r.db, balance)
+}
+
+func (r *dailyBalanceRepository) UpsertBatch(ctx context.Context, balances []*domain.DailyBalance) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, balance := range balances {
+		if err := upsertDailyBalance(ctx, tx, balance); err != nil {
+			return fmt.Errorf("failed to upsert daily balance for %s: %w", balance.BalanceDate.Format("2006-01-02"), err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *dailyBalanceRepository) GetDailyActivity(ctx context.Context, branchID int64, date time.Time) (*repository.DailyActivity, error) {
+	// A loan counts as active at the end of the day when it had started and was
+	// not yet paid, confiscated or replaced by a renewal. Only the cash paid out
+	// of a drawer for a new loan counts as disbursed; renewals move no cash.
+	query := `
+		WITH pay AS (
+			SELECT COALESCE(SUM(amount), 0) AS received,
+				   COALESCE(SUM(interest_amount), 0) AS interest,
+				   COALESCE(SUM(late_fee_amount), 0) AS late_fees
+			FROM payments
+			WHERE branch_id = $1 AND status = 'completed' AND payment_date::date = $2::date
+		), sal AS (
+			SELECT COALESCE(SUM(final_price) FILTER (WHERE sale_date::date = $2::date AND status IN ('completed', 'refunded')), 0) AS income,
+				   COALESCE(SUM(refund_amount) FILTER (WHERE refunded_at::date = $2::date), 0) AS refunds
+			FROM sales
+			WHERE branch_id = $1
+		), exp AS (
+			SELECT COALESCE(SUM(amount), 0) AS total
+			FROM expenses
+			WHERE branch_id = $1 AND expense_date = $2::date
+		), active AS (
+			SELECT loan_amount, start_date
+			FROM loans
+			WHERE branch_id = $1 AND deleted_at IS NULL AND start_date <= $2::date
+			  AND (paid_date IS NULL OR paid_date > $2::date)
+			  AND (confiscated_date IS NULL OR confiscated_date > $2::date)
+			  AND (status <> 'renewed' OR updated_at::date > $2::date)
+		), disbursed AS (
+			SELECT COALESCE(SUM(m.amount), 0) AS total
+			FROM cash_movements m
+			JOIN loans l ON l.id = m.reference_id
+			WHERE m.branch_id = $1 AND m.reference_type = 'loan' AND m.movement_type = 'expense'
+			  AND m.payment_method = 'cash' AND m.created_at::date = $2::date
+			  AND l.renewed_from_id IS NULL
+		)
+		SELECT disbursed.total, pay.received, pay.interest, pay.late_fees,
+			   sal.income, sal.refunds, exp.total,
+			   (SELECT COALESCE(SUM(loan_amount), 0) FROM active),
+			   (SELECT COUNT(*) FROM active)
+		FROM pay, sal, exp, disbursed`
+
+	activity := &repository.DailyActivity{}
+	err := r.db.QueryRowContext(ctx, query, branchID, date).Scan(
+		&activity.LoanDisbursements,
+		&activity.PaymentsReceived,
+		&activity.InterestCollected,
+		&activity.LateFeesCollected,
+		&activity.SalesIncome,
+		&activity.Refunds,
+		&activity.Expenses,
+		&activity.ActiveLoansAmount,
+		&activity.ActiveLoansCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
 func (r *dailyBalanceRepository) ListByBranch(ctx context.Context, branchID int64, dateFrom, dateTo time.Time) ([]*domain.DailyBalance, error) {
 	query := `
 		SELECT id, branch_id, balance_date, loan_disbursements, interest_income,
@@ -946,3 +1081,153 @@ func (r *dailyBalanceRepository) GetSummary(ctx context.Context, branchID *int64
 	}
 	return summary, nil
 }
+
+// Accounting Period Repository
+type accountingPeriodRepository struct {
+	db *DB
+}
+
+// NewAccountingPeriodRepository creates a new accounting period repository
+func NewAccountingPeriodRepository(db *DB) repository.AccountingPeriodRepository {
+	return &accountingPeriodRepository{db: db}
+}
+
+const accountingPeriodColumns = `
+	id, branch_id, year, month, status, accrued_interest, accrued_late_fees,
+	accrual_entry_id, accruals_posted_at, closed_at, closed_by, created_at, updated_at`
+
+func scanAccountingPeriod(row rowScanner) (*domain.AccountingPeriod, error) {
+	period := &domain.AccountingPeriod{}
+	err := row.Scan(
+		&period.ID,
+		&period.BranchID,
+		&period.Year,
+		&period.Month,
+		&period.Status,
+		&period.AccruedInterest,
+		&period.AccruedLateFees,
+		&period.AccrualEntryID,
+		&period.AccrualsPostedAt,
+		&period.ClosedAt,
+		&period.ClosedBy,
+		&period.CreatedAt,
+		&period.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+func (r *accountingPeriodRepository) GetOrCreate(ctx context.Context, branchID int64, year, month int) (*domain.AccountingPeriod, error) {
+	query := `
+		INSERT INTO accounting_periods (branch_id, year, month)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (branch_id, year, month) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, branchID, year, month); err != nil {
+		return nil, fmt.Errorf("failed to open accounting period: %w", err)
+	}
+
+	return r.Get(ctx, branchID, year, month)
+}
+
+func (r *accountingPeriodRepository) Get(ctx context.Context, branchID int64, year, month int) (*domain.AccountingPeriod, error) {
+	query := `SELECT ` + accountingPeriodColumns + `
+		FROM accounting_periods
+		WHERE branch_id = $1 AND year = $2 AND month = $3`
+
+	period, err := scanAccountingPeriod(r.db.QueryRowContext(ctx, query, branchID, year, month))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+func (r *accountingPeriodRepository) List(ctx context.Context, branchID *int64) ([]*domain.AccountingPeriod, error) {
+	query := `SELECT ` + accountingPeriodColumns + ` FROM accounting_periods`
+	args := []interface{}{}
+	if branchID != nil {
+		query += ` WHERE branch_id = $1`
+		args = append(args, *branchID)
+	}
+	query += ` ORDER BY year DESC, month DESC, branch_id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []*domain.AccountingPeriod
+	for rows.Next() {
+		period, err := scanAccountingPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+	return periods, rows.Err()
+}
+
+func (r *accountingPeriodRepository) SaveAccruals(ctx context.Context, period *domain.AccountingPeriod, entry *domain.AccountingEntry) (bool, error) {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if entry != nil {
+		if err := entry.Validate(); err != nil {
+			return false, err
+		}
+		if err := insertAccountingEntry(ctx, tx, entry); err != nil {
+			return false, fmt.Errorf("failed to create accrual entry: %w", err)
+		}
+		period.AccrualEntryID = &entry.ID
+	}
+
+	query := `
+		UPDATE accounting_periods SET
+			accrued_interest = $2,
+			accrued_late_fees = $3,
+			accrual_entry_id = $4,
+			accruals_posted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND accruals_posted_at IS NULL
+		RETURNING accruals_posted_at`
+
+	err = tx.QueryRowContext(ctx, query,
+		period.ID,
+		period.AccruedInterest,
+		period.AccruedLateFees,
+		NullInt64(period.AccrualEntryID),
+	).Scan(&period.AccrualsPostedAt)
+	if err == sql.ErrNoRows {
+		period.AccrualEntryID = nil
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *accountingPeriodRepository) Close(ctx context.Context, id int64, closedBy int64) error {
+	query := `
+		UPDATE accounting_periods SET
+			status = 'closed',
+			closed_at = NOW(),
+			closed_by = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'open'`
+
+	_, err := r.db.ExecContext(ctx, query, id, closedBy)
+	return err
+}
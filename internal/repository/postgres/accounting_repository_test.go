@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+)
+
+func TestFormatEntryNumber(t *testing.T) {
+	date := time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "JE-20240305-0001", formatEntryNumber(date, 1))
+	assert.Equal(t, "JE-20240305-12345", formatEntryNumber(date, 12345))
+}
+
+func TestAccountingEntryRepository_Create_RejectsUnbalanced(t *testing.T) {
+	// No database: an unbalanced entry must be rejected before any write
+	repo := NewAccountingEntryRepository(nil)
+	entry := &domain.AccountingEntry{
+		TotalDebit:  100,
+		TotalCredit: 100,
+		Lines: []*domain.AccountingEntryLine{
+			{AccountID: 1, EntryType: domain.EntryTypeDebit, Amount: 100},
+			{AccountID: 2, EntryType: domain.EntryTypeCredit, Amount: 99.5},
+		},
+	}
+
+	err := repo.Create(context.Background(), entry)
+
+	assert.ErrorIs(t, err, domain.ErrUnbalancedEntry)
+	assert.Zero(t, entry.ID)
+}
+
+// TestGenerateEntryNumber_Concurrent runs against a migrated database set in
+// TEST_DATABASE_URL and is skipped otherwise.
+func TestGenerateEntryNumber_Concurrent(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	sqlDB, err := sql.Open("pgx", url)
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(20)
+
+	repo := NewAccountingEntryRepository(&DB{sqlDB})
+	ctx := context.Background()
+
+	const workers = 50
+	numbers := make([]string, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			numbers[i], errs[i] = repo.GenerateEntryNumber(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	format := regexp.MustCompile(`^JE-\d{8}-\d{4,}$`)
+	seen := make(map[string]bool, workers)
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+		assert.Regexp(t, format, numbers[i])
+		assert.False(t, seen[numbers[i]], "duplicate entry number %s", numbers[i])
+		seen[numbers[i]] = true
+	}
+}
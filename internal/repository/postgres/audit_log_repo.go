@@ -23,8 +23,8 @@ func NewAuditLogRepository(db *DB) *AuditLogRepository {
 // Create creates a new audit log entry
 func (r *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
 	query := `
-		INSERT INTO audit_logs (branch_id, user_id, action, entity_type, entity_id, description, old_values, new_values, ip_address, user_agent)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO audit_logs (branch_id, user_id, action, entity_type, entity_id, description, old_values, new_values, ip_address, user_agent, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 
@@ -55,6 +55,7 @@ func (r *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) e
 		newValuesJSON,
 		NullString(log.IPAddress),
 		NullString(log.UserAgent),
+		NullString(log.RequestID),
 	).Scan(&log.ID, &log.CreatedAt)
 
 	if err != nil {
@@ -115,7 +116,7 @@ func (r *AuditLogRepository) List(ctx context.Context, params repository.AuditLo
 	}
 
 	if params.DateTo != nil {
-		where += fmt.Sprintf(" AND al.created_at <= $%d", argNum)
+		where += fmt.Sprintf(" AND al.created_at %s", auditDateToCondition(*params.DateTo, argNum))
 		args = append(args, *params.DateTo)
 		argNum++
 	}
@@ -132,7 +133,7 @@ func (r *AuditLogRepository) List(ctx context.Context, params repository.AuditLo
 	query := fmt.Sprintf(`
 		SELECT
 			al.id, al.branch_id, al.user_id, al.action, al.entity_type, al.entity_id, al.description,
-			al.old_values, al.new_values, al.ip_address, al.user_agent, al.created_at,
+			al.old_values, al.new_values, al.ip_address, al.user_agent, al.request_id, al.created_at,
 			COALESCE(u.first_name || ' ' || u.last_name, '') as user_name,
 			COALESCE(b.name, '') as branch_name
 		FROM audit_logs al
@@ -156,7 +157,7 @@ func (r *AuditLogRepository) List(ctx context.Context, params repository.AuditLo
 		var log domain.AuditLog
 		var branchID, userID, entityID sql.NullInt64
 		var oldValues, newValues []byte
-		var ipAddress, userAgent, userName, branchName, description sql.NullString
+		var ipAddress, userAgent, requestID, userName, branchName, description sql.NullString
 
 		err := rows.Scan(
 			&log.ID,
@@ -170,6 +171,7 @@ func (r *AuditLogRepository) List(ctx context.Context, params repository.AuditLo
 			&newValues,
 			&ipAddress,
 			&userAgent,
+			&requestID,
 			&log.CreatedAt,
 			&userName,
 			&branchName,
@@ -184,6 +186,7 @@ func (r *AuditLogRepository) List(ctx context.Context, params repository.AuditLo
 		log.Description = StringPtrVal(description)
 		log.IPAddress = StringPtr(ipAddress)
 		log.UserAgent = StringPtr(userAgent)
+		log.RequestID = StringPtr(requestID)
 		log.UserName = StringPtrVal(userName)
 		log.BranchName = StringPtrVal(branchName)
 
@@ -408,3 +411,11 @@ func (r *AuditLogRepository) GetStats(ctx context.Context, params repository.Aud
 
 	return stats, nil
 }
+
+// auditDateToCondition makes a date-only upper bound include the whole day
+func auditDateToCondition(dateTo string, argNum int) string {
+	if len(dateTo) == len("2006-01-02") {
+		return fmt.Sprintf("< $%d::date + 1", argNum)
+	}
+	return fmt.Sprintf("<= $%d", argNum)
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// BackupVerificationRepository implements repository.BackupVerificationRepository
+type BackupVerificationRepository struct {
+	db *DB
+}
+
+// NewBackupVerificationRepository creates a new BackupVerificationRepository
+func NewBackupVerificationRepository(db *DB) *BackupVerificationRepository {
+	return &BackupVerificationRepository{db: db}
+}
+
+// Create records a backup verification result
+func (r *BackupVerificationRepository) Create(ctx context.Context, verification *domain.BackupVerification) error {
+	if verification.RowCounts == nil {
+		verification.RowCounts = map[string]int64{}
+	}
+	rowCounts, err := json.Marshal(verification.RowCounts)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup row counts: %w", err)
+	}
+
+	query := `
+		INSERT INTO backup_verifications (
+			backup_filename, status, row_counts, error_message,
+			started_at, finished_at, duration_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		verification.BackupFilename, verification.Status, string(rowCounts), NullStringPtr(verification.ErrorMessage),
+		verification.StartedAt, verification.FinishedAt, verification.DurationMs,
+	).Scan(&verification.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create backup verification: %w", err)
+	}
+
+	return nil
+}
@@ -226,10 +226,30 @@ func (r *BranchRepository) Update(ctx context.Context, branch *domain.Branch) er
 
 // Delete soft deletes a branch
 func (r *BranchRepository) Delete(ctx context.Context, id int64) error {
+	// Branches are soft deleted, so foreign keys do not protect the records
+	// still working out of the branch
+	var referencedBy string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT CASE
+			WHEN EXISTS (SELECT 1 FROM users WHERE branch_id = $1 AND deleted_at IS NULL) THEN 'users'
+			WHEN EXISTS (SELECT 1 FROM loans WHERE branch_id = $1 AND status IN ('active', 'overdue', 'pending_authorization') AND deleted_at IS NULL) THEN 'open loans'
+			WHEN EXISTS (SELECT 1 FROM cash_sessions WHERE branch_id = $1 AND status = 'open') THEN 'open cash sessions'
+			ELSE ''
+		END`, id).Scan(&referencedBy)
+	if err != nil {
+		return fmt.Errorf("failed to check branch references: %w", err)
+	}
+	if referencedBy != "" {
+		return &repository.InUseError{Entity: "branch", ReferencedBy: referencedBy}
+	}
+
 	query := `UPDATE branches SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
+		if inUse := inUseError(err, "branch"); inUse != nil {
+			return inUse
+		}
 		return fmt.Errorf("failed to delete branch: %w", err)
 	}
 
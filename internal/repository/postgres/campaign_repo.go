@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// CampaignRepository implements repository.CampaignRepository
+type CampaignRepository struct {
+	db *DB
+}
+
+// NewCampaignRepository creates a new CampaignRepository
+func NewCampaignRepository(db *DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+const campaignColumns = `
+	id, name, code, description, start_date, end_date, branch_id,
+	flagged_customers_only, interest_discount_percent, late_fee_discount_percent,
+	is_active, created_at, updated_at`
+
+// GetByID retrieves a campaign by ID
+func (r *CampaignRepository) GetByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE id = $1`
+	return r.scanCampaign(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByCode retrieves a campaign by code
+func (r *CampaignRepository) GetByCode(ctx context.Context, code string) (*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE code = $1`
+	return r.scanCampaign(r.db.QueryRowContext(ctx, query, code))
+}
+
+// List retrieves campaigns, most recent first
+func (r *CampaignRepository) List(ctx context.Context, activeOnly bool) ([]*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY start_date DESC, name ASC`
+
+	return r.queryCampaigns(ctx, query)
+}
+
+// ListRunning retrieves the active campaigns whose window includes the given day
+func (r *CampaignRepository) ListRunning(ctx context.Context, day domain.Date) ([]*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns
+		WHERE is_active = true AND start_date <= $1 AND end_date >= $1
+		ORDER BY id ASC`
+
+	return r.queryCampaigns(ctx, query, day)
+}
+
+// Create creates a new campaign
+func (r *CampaignRepository) Create(ctx context.Context, campaign *domain.Campaign) error {
+	query := `
+		INSERT INTO campaigns (
+			name, code, description, start_date, end_date, branch_id,
+			flagged_customers_only, interest_discount_percent, late_fee_discount_percent,
+			is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		campaign.Name, campaign.Code, NullStringPtr(campaign.Description),
+		campaign.StartDate, campaign.EndDate, NullInt64(campaign.BranchID),
+		campaign.FlaggedCustomersOnly, campaign.InterestDiscountPercent, campaign.LateFeeDiscountPercent,
+		campaign.IsActive,
+	).Scan(&campaign.ID, &campaign.CreatedAt, &campaign.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates an existing campaign
+func (r *CampaignRepository) Update(ctx context.Context, campaign *domain.Campaign) error {
+	query := `
+		UPDATE campaigns SET
+			name = $2, description = $3, start_date = $4, end_date = $5, branch_id = $6,
+			flagged_customers_only = $7, interest_discount_percent = $8,
+			late_fee_discount_percent = $9, is_active = $10, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		campaign.ID, campaign.Name, NullStringPtr(campaign.Description),
+		campaign.StartDate, campaign.EndDate, NullInt64(campaign.BranchID),
+		campaign.FlaggedCustomersOnly, campaign.InterestDiscountPercent,
+		campaign.LateFeeDiscountPercent, campaign.IsActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("campaign not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a campaign
+func (r *CampaignRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM campaigns WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("campaign not found")
+	}
+
+	return nil
+}
+
+// FlagCustomer makes a customer eligible for a flagged-customers campaign
+func (r *CampaignRepository) FlagCustomer(ctx context.Context, campaignID, customerID int64) error {
+	query := `
+		INSERT INTO campaign_customers (campaign_id, customer_id)
+		VALUES ($1, $2)
+		ON CONFLICT (campaign_id, customer_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, campaignID, customerID); err != nil {
+		return fmt.Errorf("failed to flag customer for campaign: %w", err)
+	}
+
+	return nil
+}
+
+// UnflagCustomer removes a customer from a flagged-customers campaign
+func (r *CampaignRepository) UnflagCustomer(ctx context.Context, campaignID, customerID int64) error {
+	query := `DELETE FROM campaign_customers WHERE campaign_id = $1 AND customer_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, campaignID, customerID); err != nil {
+		return fmt.Errorf("failed to unflag customer for campaign: %w", err)
+	}
+
+	return nil
+}
+
+// IsCustomerFlagged checks if a customer was added to a campaign
+func (r *CampaignRepository) IsCustomerFlagged(ctx context.Context, campaignID, customerID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM campaign_customers WHERE campaign_id = $1 AND customer_id = $2)`
+
+	var flagged bool
+	if err := r.db.QueryRowContext(ctx, query, campaignID, customerID).Scan(&flagged); err != nil {
+		return false, fmt.Errorf("failed to check campaign customer: %w", err)
+	}
+
+	return flagged, nil
+}
+
+// Helper functions
+func (r *CampaignRepository) queryCampaigns(ctx context.Context, query string, args ...interface{}) ([]*domain.Campaign, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := []*domain.Campaign{}
+	for rows.Next() {
+		campaign, err := r.scanCampaignRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+func (r *CampaignRepository) scanCampaign(row *sql.Row) (*domain.Campaign, error) {
+	campaign, err := r.scanCampaignRow(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("campaign not found")
+		}
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+func (r *CampaignRepository) scanCampaignRow(row rowScanner) (*domain.Campaign, error) {
+	campaign := &domain.Campaign{}
+	var description sql.NullString
+	var branchID sql.NullInt64
+
+	err := row.Scan(
+		&campaign.ID, &campaign.Name, &campaign.Code, &description,
+		&campaign.StartDate, &campaign.EndDate, &branchID,
+		&campaign.FlaggedCustomersOnly, &campaign.InterestDiscountPercent, &campaign.LateFeeDiscountPercent,
+		&campaign.IsActive, &campaign.CreatedAt, &campaign.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign.Description = StringPtrVal(description)
+	campaign.BranchID = Int64Ptr(branchID)
+	return campaign, nil
+}
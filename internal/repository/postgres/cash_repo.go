@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
@@ -138,46 +139,58 @@ func NewCashSessionRepository(db *DB) *CashSessionRepository {
 func (r *CashSessionRepository) GetByID(ctx context.Context, id int64) (*domain.CashSession, error) {
 	query := `
 		SELECT id, branch_id, cash_register_id, user_id, status,
-			   opening_amount, closing_amount, expected_amount, difference,
+			   currency, opening_amount, closing_amount, expected_amount, difference,
 			   opened_at, closed_at, closed_by, opening_notes, closing_notes,
-			   created_at, updated_at
+			   auto_closed, overdue_notified_at, created_at, updated_at
 		FROM cash_sessions
 		WHERE id = $1
 	`
 
-	return r.scanSession(r.db.QueryRowContext(ctx, query, id))
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+	return session, r.loadCurrencies(ctx, session)
 }
 
 // GetOpenSession retrieves an open session for a user
 func (r *CashSessionRepository) GetOpenSession(ctx context.Context, userID int64) (*domain.CashSession, error) {
 	query := `
 		SELECT id, branch_id, cash_register_id, user_id, status,
-			   opening_amount, closing_amount, expected_amount, difference,
+			   currency, opening_amount, closing_amount, expected_amount, difference,
 			   opened_at, closed_at, closed_by, opening_notes, closing_notes,
-			   created_at, updated_at
+			   auto_closed, overdue_notified_at, created_at, updated_at
 		FROM cash_sessions
 		WHERE user_id = $1 AND status = 'open'
 		ORDER BY opened_at DESC
 		LIMIT 1
 	`
 
-	return r.scanSession(r.db.QueryRowContext(ctx, query, userID))
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		return nil, err
+	}
+	return session, r.loadCurrencies(ctx, session)
 }
 
 // GetOpenSessionByRegister retrieves an open session for a register
 func (r *CashSessionRepository) GetOpenSessionByRegister(ctx context.Context, registerID int64) (*domain.CashSession, error) {
 	query := `
 		SELECT id, branch_id, cash_register_id, user_id, status,
-			   opening_amount, closing_amount, expected_amount, difference,
+			   currency, opening_amount, closing_amount, expected_amount, difference,
 			   opened_at, closed_at, closed_by, opening_notes, closing_notes,
-			   created_at, updated_at
+			   auto_closed, overdue_notified_at, created_at, updated_at
 		FROM cash_sessions
 		WHERE cash_register_id = $1 AND status = 'open'
 		ORDER BY opened_at DESC
 		LIMIT 1
 	`
 
-	return r.scanSession(r.db.QueryRowContext(ctx, query, registerID))
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, registerID))
+	if err != nil {
+		return nil, err
+	}
+	return session, r.loadCurrencies(ctx, session)
 }
 
 // List retrieves cash sessions with pagination and filters
@@ -250,9 +263,9 @@ func (r *CashSessionRepository) List(ctx context.Context, params repository.Cash
 
 	dataQuery := fmt.Sprintf(`
 		SELECT cs.id, cs.branch_id, cs.cash_register_id, cs.user_id, cs.status,
-			   cs.opening_amount, cs.closing_amount, cs.expected_amount, cs.difference,
+			   cs.currency, cs.opening_amount, cs.closing_amount, cs.expected_amount, cs.difference,
 			   cs.opened_at, cs.closed_at, cs.closed_by, cs.opening_notes, cs.closing_notes,
-			   cs.created_at, cs.updated_at,
+			   cs.auto_closed, cs.overdue_notified_at, cs.created_at, cs.updated_at,
 			   cr.id, cr.branch_id, cr.name, cr.code, cr.description, cr.is_active, cr.created_at, cr.updated_at,
 			   u.id, u.branch_id, u.first_name, u.last_name, u.email, u.phone
 		FROM cash_sessions cs
@@ -293,24 +306,40 @@ func (r *CashSessionRepository) List(ctx context.Context, params repository.Cash
 	}, nil
 }
 
-// Create creates a new cash session
+// Create creates a new cash session with the opening amounts of its other currencies
 func (r *CashSessionRepository) Create(ctx context.Context, session *domain.CashSession) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO cash_sessions (branch_id, cash_register_id, user_id, status, opening_amount, opened_at, opening_notes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO cash_sessions (branch_id, cash_register_id, user_id, status, currency, opening_amount, opened_at, opening_notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
-		session.BranchID, session.CashRegisterID, session.UserID, session.Status,
+	err = tx.QueryRowContext(ctx, query,
+		session.BranchID, session.CashRegisterID, session.UserID, session.Status, session.Currency,
 		session.OpeningAmount, session.OpenedAt, NullStringPtr(session.OpeningNotes),
 	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
 
 	if err != nil {
-		return fmt.Errorf("failed to create cash session: %w", err)
+		return cashSessionCreateError(err)
 	}
 
-	return nil
+	for _, amounts := range session.Currencies {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO cash_session_currencies (session_id, currency, opening_amount) VALUES ($1, $2, $3)`,
+			session.ID, amounts.Currency, amounts.OpeningAmount,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create cash session currency %s: %w", amounts.Currency, err)
+		}
+	}
+
+	return tx.Commit()
 }
 
 // Update updates an existing cash session
@@ -339,17 +368,25 @@ func (r *CashSessionRepository) Update(ctx context.Context, session *domain.Cash
 	return nil
 }
 
-// Close closes a cash session
+// Close closes a cash session, recording the count of each of its currencies
+// and the bills and coins counted
 func (r *CashSessionRepository) Close(ctx context.Context, id int64, data repository.CashSessionCloseData) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE cash_sessions SET
 			status = 'closed', closing_amount = $2, expected_amount = $3, difference = $4,
-			closed_at = NOW(), closed_by = $5, closing_notes = $6, updated_at = NOW()
+			closed_at = NOW(), closed_by = $5, closing_notes = $6, auto_closed = $7, updated_at = NOW()
 		WHERE id = $1 AND status = 'open'
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
-		id, data.ClosingAmount, data.ExpectedAmount, data.Difference, data.ClosedBy, data.ClosingNotes,
+	closedBy := sql.NullInt64{Int64: data.ClosedBy, Valid: data.ClosedBy > 0}
+	result, err := tx.ExecContext(ctx, query,
+		id, data.ClosingAmount, data.ExpectedAmount, data.Difference, closedBy, data.ClosingNotes, data.AutoClosed,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to close cash session: %w", err)
@@ -360,6 +397,145 @@ func (r *CashSessionRepository) Close(ctx context.Context, id int64, data reposi
 		return fmt.Errorf("cash session not found or already closed")
 	}
 
+	for _, amounts := range data.Currencies {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE cash_session_currencies SET closing_amount = $3, expected_amount = $4, difference = $5
+			WHERE session_id = $1 AND currency = $2`,
+			id, amounts.Currency, NullFloat64(amounts.ClosingAmount), NullFloat64(amounts.ExpectedAmount), NullFloat64(amounts.Difference),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to close cash session currency %s: %w", amounts.Currency, err)
+		}
+	}
+
+	for _, count := range data.Denominations {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO cash_session_denominations (session_id, currency, denomination, count)
+			VALUES ($1, $2, $3, $4)`,
+			id, count.Currency, count.Denomination, count.Count,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record %s %.2f denomination count: %w", count.Currency, count.Denomination, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListDenominations lists the bills and coins counted when a session closed,
+// by currency and largest denomination first
+func (r *CashSessionRepository) ListDenominations(ctx context.Context, sessionID int64) ([]*domain.CashDenominationCount, error) {
+	query := `
+		SELECT currency, denomination, count
+		FROM cash_session_denominations
+		WHERE session_id = $1
+		ORDER BY currency, denomination DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cash session denominations: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []*domain.CashDenominationCount{}
+	for rows.Next() {
+		count := &domain.CashDenominationCount{}
+		if err := rows.Scan(&count.Currency, &count.Denomination, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan cash session denomination: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// ListOpen lists every open session, oldest first
+func (r *CashSessionRepository) ListOpen(ctx context.Context) ([]*domain.CashSession, error) {
+	query := `
+		SELECT id, branch_id, cash_register_id, user_id, status,
+			   currency, opening_amount, closing_amount, expected_amount, difference,
+			   opened_at, closed_at, closed_by, opening_notes, closing_notes,
+			   auto_closed, overdue_notified_at, created_at, updated_at
+		FROM cash_sessions
+		WHERE status = 'open'
+		ORDER BY opened_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open cash sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*domain.CashSession{}
+	for rows.Next() {
+		session, err := r.scanSessionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if err := r.loadCurrencies(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// MarkOverdueNotified records that staff were told the session is still open
+func (r *CashSessionRepository) MarkOverdueNotified(ctx context.Context, id int64, notifiedAt time.Time) error {
+	query := `UPDATE cash_sessions SET overdue_notified_at = $2, updated_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, notifiedAt); err != nil {
+		return fmt.Errorf("failed to mark cash session notified: %w", err)
+	}
+	return nil
+}
+
+// listCurrencies lists the amounts of a session's other currencies
+func (r *CashSessionRepository) listCurrencies(ctx context.Context, sessionID int64) ([]*domain.CashSessionCurrency, error) {
+	query := `
+		SELECT currency, opening_amount, closing_amount, expected_amount, difference
+		FROM cash_session_currencies
+		WHERE session_id = $1
+		ORDER BY currency
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cash session currencies: %w", err)
+	}
+	defer rows.Close()
+
+	currencies := []*domain.CashSessionCurrency{}
+	for rows.Next() {
+		amounts := &domain.CashSessionCurrency{}
+		var closingAmount, expectedAmount, difference sql.NullFloat64
+		if err := rows.Scan(&amounts.Currency, &amounts.OpeningAmount, &closingAmount, &expectedAmount, &difference); err != nil {
+			return nil, fmt.Errorf("failed to scan cash session currency: %w", err)
+		}
+		amounts.ClosingAmount = Float64Ptr(closingAmount)
+		amounts.ExpectedAmount = Float64Ptr(expectedAmount)
+		amounts.Difference = Float64Ptr(difference)
+		currencies = append(currencies, amounts)
+	}
+
+	return currencies, rows.Err()
+}
+
+// loadCurrencies loads the other currencies of a session
+func (r *CashSessionRepository) loadCurrencies(ctx context.Context, session *domain.CashSession) error {
+	currencies, err := r.listCurrencies(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	session.Currencies = currencies
 	return nil
 }
 
@@ -367,15 +543,15 @@ func (r *CashSessionRepository) Close(ctx context.Context, id int64, data reposi
 func (r *CashSessionRepository) scanSession(row *sql.Row) (*domain.CashSession, error) {
 	session := &domain.CashSession{}
 	var closingAmount, expectedAmount, difference sql.NullFloat64
-	var closedAt sql.NullTime
+	var closedAt, overdueNotifiedAt sql.NullTime
 	var closedBy sql.NullInt64
 	var openingNotes, closingNotes sql.NullString
 
 	err := row.Scan(
 		&session.ID, &session.BranchID, &session.CashRegisterID, &session.UserID, &session.Status,
-		&session.OpeningAmount, &closingAmount, &expectedAmount, &difference,
+		&session.Currency, &session.OpeningAmount, &closingAmount, &expectedAmount, &difference,
 		&session.OpenedAt, &closedAt, &closedBy, &openingNotes, &closingNotes,
-		&session.CreatedAt, &session.UpdatedAt,
+		&session.AutoClosed, &overdueNotifiedAt, &session.CreatedAt, &session.UpdatedAt,
 	)
 
 	if err != nil {
@@ -394,6 +570,7 @@ func (r *CashSessionRepository) scanSession(row *sql.Row) (*domain.CashSession,
 	session.ClosedBy = Int64Ptr(closedBy)
 	session.OpeningNotes = StringPtrVal(openingNotes)
 	session.ClosingNotes = StringPtrVal(closingNotes)
+	session.OverdueNotifiedAt = TimePtr(overdueNotifiedAt)
 
 	return session, nil
 }
@@ -401,15 +578,15 @@ func (r *CashSessionRepository) scanSession(row *sql.Row) (*domain.CashSession,
 func (r *CashSessionRepository) scanSessionRow(rows *sql.Rows) (*domain.CashSession, error) {
 	session := &domain.CashSession{}
 	var closingAmount, expectedAmount, difference sql.NullFloat64
-	var closedAt sql.NullTime
+	var closedAt, overdueNotifiedAt sql.NullTime
 	var closedBy sql.NullInt64
 	var openingNotes, closingNotes sql.NullString
 
 	err := rows.Scan(
 		&session.ID, &session.BranchID, &session.CashRegisterID, &session.UserID, &session.Status,
-		&session.OpeningAmount, &closingAmount, &expectedAmount, &difference,
+		&session.Currency, &session.OpeningAmount, &closingAmount, &expectedAmount, &difference,
 		&session.OpenedAt, &closedAt, &closedBy, &openingNotes, &closingNotes,
-		&session.CreatedAt, &session.UpdatedAt,
+		&session.AutoClosed, &overdueNotifiedAt, &session.CreatedAt, &session.UpdatedAt,
 	)
 
 	if err != nil {
@@ -425,6 +602,7 @@ func (r *CashSessionRepository) scanSessionRow(rows *sql.Rows) (*domain.CashSess
 	session.ClosedBy = Int64Ptr(closedBy)
 	session.OpeningNotes = StringPtrVal(openingNotes)
 	session.ClosingNotes = StringPtrVal(closingNotes)
+	session.OverdueNotifiedAt = TimePtr(overdueNotifiedAt)
 
 	return session, nil
 }
@@ -432,7 +610,7 @@ func (r *CashSessionRepository) scanSessionRow(rows *sql.Rows) (*domain.CashSess
 func (r *CashSessionRepository) scanSessionRowWithRelations(rows *sql.Rows) (*domain.CashSession, error) {
 	session := &domain.CashSession{}
 	var closingAmount, expectedAmount, difference sql.NullFloat64
-	var closedAt sql.NullTime
+	var closedAt, overdueNotifiedAt sql.NullTime
 	var closedBy sql.NullInt64
 	var openingNotes, closingNotes sql.NullString
 
@@ -449,9 +627,9 @@ func (r *CashSessionRepository) scanSessionRowWithRelations(rows *sql.Rows) (*do
 
 	err := rows.Scan(
 		&session.ID, &session.BranchID, &session.CashRegisterID, &session.UserID, &session.Status,
-		&session.OpeningAmount, &closingAmount, &expectedAmount, &difference,
+		&session.Currency, &session.OpeningAmount, &closingAmount, &expectedAmount, &difference,
 		&session.OpenedAt, &closedAt, &closedBy, &openingNotes, &closingNotes,
-		&session.CreatedAt, &session.UpdatedAt,
+		&session.AutoClosed, &overdueNotifiedAt, &session.CreatedAt, &session.UpdatedAt,
 		// Register
 		&registerID, &registerBranchID, &registerName, &registerCode, &registerDescription, &registerIsActive, &registerCreatedAt, &registerUpdatedAt,
 		// User
@@ -471,6 +649,7 @@ func (r *CashSessionRepository) scanSessionRowWithRelations(rows *sql.Rows) (*do
 	session.ClosedBy = Int64Ptr(closedBy)
 	session.OpeningNotes = StringPtrVal(openingNotes)
 	session.ClosingNotes = StringPtrVal(closingNotes)
+	session.OverdueNotifiedAt = TimePtr(overdueNotifiedAt)
 
 	// Populate register
 	if registerID.Valid {
@@ -514,7 +693,7 @@ func NewCashMovementRepository(db *DB) *CashMovementRepository {
 // GetByID retrieves a cash movement by ID
 func (r *CashMovementRepository) GetByID(ctx context.Context, id int64) (*domain.CashMovement, error) {
 	query := `
-		SELECT id, branch_id, session_id, movement_type, amount,
+		SELECT id, branch_id, session_id, movement_type, amount, currency,
 			   payment_method, reference_type, reference_id, description,
 			   balance_after, created_by, created_at
 		FROM cash_movements
@@ -526,7 +705,7 @@ func (r *CashMovementRepository) GetByID(ctx context.Context, id int64) (*domain
 	var referenceID sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&movement.ID, &movement.BranchID, &movement.SessionID, &movement.MovementType, &movement.Amount,
+		&movement.ID, &movement.BranchID, &movement.SessionID, &movement.MovementType, &movement.Amount, &movement.Currency,
 		&movement.PaymentMethod, &referenceType, &referenceID, &movement.Description,
 		&movement.BalanceAfter, &movement.CreatedBy, &movement.CreatedAt,
 	)
@@ -612,7 +791,7 @@ func (r *CashMovementRepository) List(ctx context.Context, params repository.Cas
 
 	offset := (params.Page - 1) * params.PerPage
 	dataQuery := fmt.Sprintf(`
-		SELECT id, branch_id, session_id, movement_type, amount,
+		SELECT id, branch_id, session_id, movement_type, amount, currency,
 			   payment_method, reference_type, reference_id, description,
 			   balance_after, created_by, created_at
 		%s ORDER BY %s %s LIMIT $%d OFFSET $%d`,
@@ -633,7 +812,7 @@ func (r *CashMovementRepository) List(ctx context.Context, params repository.Cas
 		var referenceID sql.NullInt64
 
 		err := rows.Scan(
-			&movement.ID, &movement.BranchID, &movement.SessionID, &movement.MovementType, &movement.Amount,
+			&movement.ID, &movement.BranchID, &movement.SessionID, &movement.MovementType, &movement.Amount, &movement.Currency,
 			&movement.PaymentMethod, &referenceType, &referenceID, &movement.Description,
 			&movement.BalanceAfter, &movement.CreatedBy, &movement.CreatedAt,
 		)
@@ -663,7 +842,7 @@ func (r *CashMovementRepository) List(ctx context.Context, params repository.Cas
 // ListBySession retrieves all cash movements for a session
 func (r *CashMovementRepository) ListBySession(ctx context.Context, sessionID int64) ([]*domain.CashMovement, error) {
 	query := `
-		SELECT id, branch_id, session_id, movement_type, amount,
+		SELECT id, branch_id, session_id, movement_type, amount, currency,
 			   payment_method, reference_type, reference_id, description,
 			   balance_after, created_by, created_at
 		FROM cash_movements
@@ -684,7 +863,7 @@ func (r *CashMovementRepository) ListBySession(ctx context.Context, sessionID in
 		var referenceID sql.NullInt64
 
 		err := rows.Scan(
-			&movement.ID, &movement.BranchID, &movement.SessionID, &movement.MovementType, &movement.Amount,
+			&movement.ID, &movement.BranchID, &movement.SessionID, &movement.MovementType, &movement.Amount, &movement.Currency,
 			&movement.PaymentMethod, &referenceType, &referenceID, &movement.Description,
 			&movement.BalanceAfter, &movement.CreatedBy, &movement.CreatedAt,
 		)
@@ -702,17 +881,27 @@ func (r *CashMovementRepository) ListBySession(ctx context.Context, sessionID in
 
 // Create creates a new cash movement
 func (r *CashMovementRepository) Create(ctx context.Context, movement *domain.CashMovement) error {
+	return insertCashMovement(ctx, r.db, movement)
+}
+
+// CreateTx creates a new cash movement within a transaction
+func (r *CashMovementRepository) CreateTx(ctx context.Context, tx repository.Transaction, movement *domain.CashMovement) error {
+	return insertCashMovement(ctx, tx.(*Tx), movement)
+}
+
+// insertCashMovement inserts a cash movement through q, which may be a transaction
+func insertCashMovement(ctx context.Context, q Querier, movement *domain.CashMovement) error {
 	query := `
 		INSERT INTO cash_movements (
-			branch_id, session_id, movement_type, amount,
+			branch_id, session_id, movement_type, amount, currency,
 			payment_method, reference_type, reference_id, description,
 			balance_after, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
-		movement.BranchID, movement.SessionID, movement.MovementType, movement.Amount,
+	err := q.QueryRowContext(ctx, query,
+		movement.BranchID, movement.SessionID, movement.MovementType, movement.Amount, movement.Currency,
 		movement.PaymentMethod, NullStringPtr(movement.ReferenceType), NullInt64(movement.ReferenceID),
 		movement.Description, movement.BalanceAfter, movement.CreatedBy,
 	).Scan(&movement.ID, &movement.CreatedAt)
@@ -724,11 +913,13 @@ func (r *CashMovementRepository) Create(ctx context.Context, movement *domain.Ca
 	return nil
 }
 
-// GetSessionBalance retrieves the current balance for a session
+// GetSessionBalance retrieves the current balance for a session in its main currency
 func (r *CashMovementRepository) GetSessionBalance(ctx context.Context, sessionID int64) (float64, error) {
 	query := `
 		SELECT COALESCE(
-			(SELECT balance_after FROM cash_movements WHERE session_id = $1 ORDER BY created_at DESC LIMIT 1),
+			(SELECT cm.balance_after FROM cash_movements cm
+			 JOIN cash_sessions cs ON cs.id = cm.session_id AND cs.currency = cm.currency
+			 WHERE cm.session_id = $1 ORDER BY cm.created_at DESC LIMIT 1),
 			(SELECT opening_amount FROM cash_sessions WHERE id = $1)
 		)
 	`
@@ -742,8 +933,55 @@ func (r *CashMovementRepository) GetSessionBalance(ctx context.Context, sessionI
 	return balance, nil
 }
 
-// GetSessionSummary retrieves a summary of movements for a session
+// GetSessionCurrencyBalance retrieves the current balance of one of the
+// currencies a session holds
+func (r *CashMovementRepository) GetSessionCurrencyBalance(ctx context.Context, sessionID int64, currency string) (float64, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT balance_after FROM cash_movements WHERE session_id = $1 AND currency = $2 ORDER BY created_at DESC LIMIT 1),
+			(SELECT opening_amount FROM cash_session_currencies WHERE session_id = $1 AND currency = $2),
+			(SELECT opening_amount FROM cash_sessions WHERE id = $1 AND currency = $2)
+		)
+	`
+
+	var balance sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, query, sessionID, currency).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get session balance: %w", err)
+	}
+	if !balance.Valid {
+		return 0, fmt.Errorf("cash session does not hold %s", currency)
+	}
+
+	return balance.Float64, nil
+}
+
+// GetDailyLoanDisbursements sums the cash a branch paid out for loans on a date
+func (r *CashMovementRepository) GetDailyLoanDisbursements(ctx context.Context, branchID int64, date time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM cash_movements
+		WHERE branch_id = $1 AND movement_type = 'expense' AND payment_method = 'cash'
+		  AND reference_type = 'loan' AND created_at::date = $2::date
+	`
+
+	var total float64
+	err := r.db.QueryRowContext(ctx, query, branchID, date).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get daily loan disbursements: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetSessionSummary retrieves a summary of movements for a session in its main currency
 func (r *CashMovementRepository) GetSessionSummary(ctx context.Context, sessionID int64) (*CashSessionSummary, error) {
+	return r.GetSessionCurrencySummary(ctx, sessionID, "")
+}
+
+// GetSessionCurrencySummary retrieves a summary of the movements of a session
+// in one currency; an empty currency is the session's main currency
+func (r *CashMovementRepository) GetSessionCurrencySummary(ctx context.Context, sessionID int64, currency string) (*CashSessionSummary, error) {
 	query := `
 		SELECT
 			COALESCE(SUM(CASE WHEN movement_type = 'income' THEN amount ELSE 0 END), 0) as total_income,
@@ -753,10 +991,11 @@ func (r *CashMovementRepository) GetSessionSummary(ctx context.Context, sessionI
 			COUNT(*) as total_movements
 		FROM cash_movements
 		WHERE session_id = $1
+		  AND currency = COALESCE(NULLIF($2, ''), (SELECT currency FROM cash_sessions WHERE id = $1))
 	`
 
 	summary := &CashSessionSummary{}
-	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, sessionID, currency).Scan(
 		&summary.TotalIncome, &summary.TotalExpense,
 		&summary.CashIncome, &summary.CashExpense, &summary.TotalMovements,
 	)
@@ -24,8 +24,9 @@ func NewCategoryRepository(db *DB) *CategoryRepository {
 func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*domain.Category, error) {
 	query := `
 		SELECT id, parent_id, name, slug, description, icon,
-			   default_interest_rate, min_loan_amount, max_loan_amount,
-			   loan_to_value_ratio, sort_order, is_active,
+			   default_interest_rate, default_late_fee_rate, min_loan_amount, max_loan_amount,
+			   min_loan_term_days, max_loan_term_days,
+			   loan_to_value_ratio, warranty_days, sort_order, is_active,
 			   created_at, updated_at
 		FROM categories
 		WHERE id = $1
@@ -38,8 +39,9 @@ func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*domain.Cat
 func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
 	query := `
 		SELECT id, parent_id, name, slug, description, icon,
-			   default_interest_rate, min_loan_amount, max_loan_amount,
-			   loan_to_value_ratio, sort_order, is_active,
+			   default_interest_rate, default_late_fee_rate, min_loan_amount, max_loan_amount,
+			   min_loan_term_days, max_loan_term_days,
+			   loan_to_value_ratio, warranty_days, sort_order, is_active,
 			   created_at, updated_at
 		FROM categories
 		WHERE slug = $1
@@ -52,8 +54,9 @@ func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*domai
 func (r *CategoryRepository) List(ctx context.Context, params repository.CategoryListParams) ([]*domain.Category, error) {
 	query := `
 		SELECT id, parent_id, name, slug, description, icon,
-			   default_interest_rate, min_loan_amount, max_loan_amount,
-			   loan_to_value_ratio, sort_order, is_active,
+			   default_interest_rate, default_late_fee_rate, min_loan_amount, max_loan_amount,
+			   min_loan_term_days, max_loan_term_days,
+			   loan_to_value_ratio, warranty_days, sort_order, is_active,
 			   created_at, updated_at
 		FROM categories
 		WHERE 1=1
@@ -97,8 +100,9 @@ func (r *CategoryRepository) List(ctx context.Context, params repository.Categor
 func (r *CategoryRepository) ListWithChildren(ctx context.Context) ([]*domain.Category, error) {
 	query := `
 		SELECT id, parent_id, name, slug, description, icon,
-			   default_interest_rate, min_loan_amount, max_loan_amount,
-			   loan_to_value_ratio, sort_order, is_active,
+			   default_interest_rate, default_late_fee_rate, min_loan_amount, max_loan_amount,
+			   min_loan_term_days, max_loan_term_days,
+			   loan_to_value_ratio, warranty_days, sort_order, is_active,
 			   created_at, updated_at
 		FROM categories
 		WHERE is_active = true
@@ -144,8 +148,9 @@ func (r *CategoryRepository) Create(ctx context.Context, category *domain.Catego
 		INSERT INTO categories (
 			parent_id, name, slug, description, icon,
 			default_interest_rate, min_loan_amount, max_loan_amount,
-			loan_to_value_ratio, sort_order, is_active
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			loan_to_value_ratio, warranty_days, sort_order, is_active,
+			default_late_fee_rate, min_loan_term_days, max_loan_term_days
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -153,7 +158,9 @@ func (r *CategoryRepository) Create(ctx context.Context, category *domain.Catego
 		NullInt64(category.ParentID), category.Name, category.Slug, NullStringPtr(category.Description),
 		NullStringPtr(category.Icon), category.DefaultInterestRate,
 		NullFloat64(category.MinLoanAmount), NullFloat64(category.MaxLoanAmount),
-		category.LoanToValueRatio, category.SortOrder, category.IsActive,
+		category.LoanToValueRatio, NullIntPtr(category.WarrantyDays), category.SortOrder, category.IsActive,
+		NullFloat64(category.DefaultLateFeeRate),
+		NullIntPtr(category.MinLoanTermDays), NullIntPtr(category.MaxLoanTermDays),
 	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
 
 	if err != nil {
@@ -169,7 +176,8 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 		UPDATE categories SET
 			parent_id = $2, name = $3, slug = $4, description = $5, icon = $6,
 			default_interest_rate = $7, min_loan_amount = $8, max_loan_amount = $9,
-			loan_to_value_ratio = $10, sort_order = $11, is_active = $12,
+			loan_to_value_ratio = $10, sort_order = $11, is_active = $12, warranty_days = $13,
+			default_late_fee_rate = $14, min_loan_term_days = $15, max_loan_term_days = $16,
 			updated_at = NOW()
 		WHERE id = $1
 	`
@@ -179,7 +187,9 @@ func (r *CategoryRepository) Update(ctx context.Context, category *domain.Catego
 		NullStringPtr(category.Description), NullStringPtr(category.Icon),
 		category.DefaultInterestRate, NullFloat64(category.MinLoanAmount),
 		NullFloat64(category.MaxLoanAmount), category.LoanToValueRatio,
-		category.SortOrder, category.IsActive,
+		category.SortOrder, category.IsActive, NullIntPtr(category.WarrantyDays),
+		NullFloat64(category.DefaultLateFeeRate),
+		NullIntPtr(category.MinLoanTermDays), NullIntPtr(category.MaxLoanTermDays),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update category: %w", err)
@@ -202,7 +212,7 @@ func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to check for children: %w", err)
 	}
 	if childCount > 0 {
-		return fmt.Errorf("cannot delete category with children")
+		return &repository.InUseError{Entity: "category", ReferencedBy: "subcategories"}
 	}
 
 	// Check if category has items
@@ -212,11 +222,15 @@ func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to check for items: %w", err)
 	}
 	if itemCount > 0 {
-		return fmt.Errorf("cannot delete category with items")
+		return &repository.InUseError{Entity: "category", ReferencedBy: "items"}
 	}
 
+	// Deleted items and other records may still reference the category
 	result, err := r.db.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
 	if err != nil {
+		if inUse := inUseError(err, "category"); inUse != nil {
+			return inUse
+		}
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
 
@@ -233,13 +247,14 @@ func (r *CategoryRepository) scanCategory(row *sql.Row) (*domain.Category, error
 	category := &domain.Category{}
 	var parentID sql.NullInt64
 	var description, icon sql.NullString
-	var minLoanAmount, maxLoanAmount sql.NullFloat64
+	var defaultLateFeeRate, minLoanAmount, maxLoanAmount sql.NullFloat64
+	var minLoanTermDays, maxLoanTermDays, warrantyDays sql.NullInt64
 
 	err := row.Scan(
 		&category.ID, &parentID, &category.Name, &category.Slug,
-		&description, &icon, &category.DefaultInterestRate,
-		&minLoanAmount, &maxLoanAmount, &category.LoanToValueRatio,
-		&category.SortOrder, &category.IsActive,
+		&description, &icon, &category.DefaultInterestRate, &defaultLateFeeRate,
+		&minLoanAmount, &maxLoanAmount, &minLoanTermDays, &maxLoanTermDays, &category.LoanToValueRatio,
+		&warrantyDays, &category.SortOrder, &category.IsActive,
 		&category.CreatedAt, &category.UpdatedAt,
 	)
 
@@ -253,8 +268,12 @@ func (r *CategoryRepository) scanCategory(row *sql.Row) (*domain.Category, error
 	category.ParentID = Int64Ptr(parentID)
 	category.Description = StringPtrVal(description)
 	category.Icon = StringPtrVal(icon)
+	category.DefaultLateFeeRate = Float64Ptr(defaultLateFeeRate)
 	category.MinLoanAmount = Float64Ptr(minLoanAmount)
 	category.MaxLoanAmount = Float64Ptr(maxLoanAmount)
+	category.MinLoanTermDays = IntPtr(minLoanTermDays)
+	category.MaxLoanTermDays = IntPtr(maxLoanTermDays)
+	category.WarrantyDays = IntPtr(warrantyDays)
 
 	return category, nil
 }
@@ -263,13 +282,14 @@ func (r *CategoryRepository) scanCategoryRow(rows *sql.Rows) (*domain.Category,
 	category := &domain.Category{}
 	var parentID sql.NullInt64
 	var description, icon sql.NullString
-	var minLoanAmount, maxLoanAmount sql.NullFloat64
+	var defaultLateFeeRate, minLoanAmount, maxLoanAmount sql.NullFloat64
+	var minLoanTermDays, maxLoanTermDays, warrantyDays sql.NullInt64
 
 	err := rows.Scan(
 		&category.ID, &parentID, &category.Name, &category.Slug,
-		&description, &icon, &category.DefaultInterestRate,
-		&minLoanAmount, &maxLoanAmount, &category.LoanToValueRatio,
-		&category.SortOrder, &category.IsActive,
+		&description, &icon, &category.DefaultInterestRate, &defaultLateFeeRate,
+		&minLoanAmount, &maxLoanAmount, &minLoanTermDays, &maxLoanTermDays, &category.LoanToValueRatio,
+		&warrantyDays, &category.SortOrder, &category.IsActive,
 		&category.CreatedAt, &category.UpdatedAt,
 	)
 
@@ -280,8 +300,12 @@ func (r *CategoryRepository) scanCategoryRow(rows *sql.Rows) (*domain.Category,
 	category.ParentID = Int64Ptr(parentID)
 	category.Description = StringPtrVal(description)
 	category.Icon = StringPtrVal(icon)
+	category.DefaultLateFeeRate = Float64Ptr(defaultLateFeeRate)
 	category.MinLoanAmount = Float64Ptr(minLoanAmount)
 	category.MaxLoanAmount = Float64Ptr(maxLoanAmount)
+	category.MinLoanTermDays = IntPtr(minLoanTermDays)
+	category.MaxLoanTermDays = IntPtr(maxLoanTermDays)
+	category.WarrantyDays = IntPtr(warrantyDays)
 
 	return category, nil
 }
@@ -0,0 +1,302 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// CollectionRepository implements repository.CollectionRepository
+type CollectionRepository struct {
+	db *DB
+}
+
+// NewCollectionRepository creates a new CollectionRepository
+func NewCollectionRepository(db *DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+const collectionCaseColumns = `c.id, c.loan_id, l.loan_number, c.customer_id, c.branch_id, c.status, c.assigned_to,
+	c.days_overdue, c.amount_due, c.promise_date, c.promise_amount, c.notes, c.last_contact_at,
+	c.entered_at, c.resolved_at, c.updated_at`
+
+// Create places a loan in the queue. The partial unique index on open cases
+// makes a loan enter the queue once however often the job runs.
+func (r *CollectionRepository) Create(ctx context.Context, collectionCase *domain.CollectionCase) (bool, error) {
+	query := `
+		INSERT INTO collection_cases (loan_id, customer_id, branch_id, status, days_overdue, amount_due)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (loan_id) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING id, entered_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		collectionCase.LoanID, collectionCase.CustomerID, collectionCase.BranchID, collectionCase.Status,
+		collectionCase.DaysOverdue, collectionCase.AmountDue,
+	).Scan(&collectionCase.ID, &collectionCase.EnteredAt, &collectionCase.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create collection case: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetByID retrieves a collection case by ID
+func (r *CollectionRepository) GetByID(ctx context.Context, id int64) (*domain.CollectionCase, error) {
+	query := `SELECT ` + collectionCaseColumns + `
+		FROM collection_cases c JOIN loans l ON l.id = c.loan_id
+		WHERE c.id = $1`
+
+	collectionCase, err := r.scanCaseRow(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("collection case not found")
+		}
+		return nil, fmt.Errorf("failed to get collection case: %w", err)
+	}
+	return collectionCase, nil
+}
+
+// List retrieves collection cases, most overdue first
+func (r *CollectionRepository) List(ctx context.Context, params repository.CollectionListParams) (*repository.PaginatedResult[domain.CollectionCase], error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PerPage <= 0 {
+		params.PerPage = 20
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argNum := 1
+
+	if params.BranchID > 0 {
+		where += fmt.Sprintf(" AND c.branch_id = $%d", argNum)
+		args = append(args, params.BranchID)
+		argNum++
+	}
+
+	if params.Status != nil {
+		where += fmt.Sprintf(" AND c.status = $%d", argNum)
+		args = append(args, *params.Status)
+		argNum++
+	} else {
+		where += " AND c.resolved_at IS NULL"
+	}
+
+	if params.AssignedTo != nil {
+		where += fmt.Sprintf(" AND c.assigned_to = $%d", argNum)
+		args = append(args, *params.AssignedTo)
+		argNum++
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM collection_cases c "+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count collection cases: %w", err)
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	query := fmt.Sprintf(`SELECT %s FROM collection_cases c JOIN loans l ON l.id = c.loan_id %s
+		ORDER BY c.days_overdue DESC, c.id ASC LIMIT $%d OFFSET $%d`,
+		collectionCaseColumns, where, argNum, argNum+1)
+	args = append(args, params.PerPage, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := []domain.CollectionCase{}
+	for rows.Next() {
+		collectionCase, err := r.scanCaseRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, *collectionCase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	totalPages := total / params.PerPage
+	if total%params.PerPage > 0 {
+		totalPages++
+	}
+
+	return &repository.PaginatedResult[domain.CollectionCase]{
+		Data:       cases,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ListOpen lists every case still in the queue
+func (r *CollectionRepository) ListOpen(ctx context.Context, branchID int64) ([]*domain.CollectionCase, error) {
+	query := `SELECT ` + collectionCaseColumns + `
+		FROM collection_cases c JOIN loans l ON l.id = c.loan_id
+		WHERE c.resolved_at IS NULL AND ($1 = 0 OR c.branch_id = $1)
+		ORDER BY c.id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, branchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open collection cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := []*domain.CollectionCase{}
+	for rows.Next() {
+		collectionCase, err := r.scanCaseRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, collectionCase)
+	}
+
+	return cases, rows.Err()
+}
+
+// Update saves a case's follow-up and refreshed balance
+func (r *CollectionRepository) Update(ctx context.Context, collectionCase *domain.CollectionCase) error {
+	query := `
+		UPDATE collection_cases
+		SET status = $2, assigned_to = $3, days_overdue = $4, amount_due = $5,
+			promise_date = $6, promise_amount = $7, notes = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	var promiseDate interface{}
+	if collectionCase.PromiseDate != nil {
+		promiseDate = *collectionCase.PromiseDate
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		collectionCase.ID, collectionCase.Status, NullInt64(collectionCase.AssignedTo),
+		collectionCase.DaysOverdue, collectionCase.AmountDue, promiseDate,
+		NullFloat64(collectionCase.PromiseAmount), NullStringPtr(collectionCase.Notes),
+	).Scan(&collectionCase.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("collection case not found")
+		}
+		return fmt.Errorf("failed to update collection case: %w", err)
+	}
+
+	return nil
+}
+
+// Resolve takes an open case out of the queue
+func (r *CollectionRepository) Resolve(ctx context.Context, id int64, resolvedAt time.Time) error {
+	query := `
+		UPDATE collection_cases
+		SET status = $2, resolved_at = $3, updated_at = NOW()
+		WHERE id = $1 AND resolved_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, domain.CollectionStatusResolved, resolvedAt); err != nil {
+		return fmt.Errorf("failed to resolve collection case: %w", err)
+	}
+	return nil
+}
+
+// AddContact logs a contact attempt and stamps the case's last contact
+func (r *CollectionRepository) AddContact(ctx context.Context, contact *domain.CollectionContact) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO collection_contacts (case_id, method, reached, notes, contacted_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, contacted_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		contact.CaseID, contact.Method, contact.Reached, NullStringPtr(contact.Notes), contact.ContactedBy,
+	).Scan(&contact.ID, &contact.ContactedAt)
+	if err != nil {
+		return fmt.Errorf("failed to log collection contact: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE collection_cases SET last_contact_at = $2, updated_at = NOW() WHERE id = $1`,
+		contact.CaseID, contact.ContactedAt,
+	); err != nil {
+		return fmt.Errorf("failed to update collection case: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListContacts retrieves the contact attempts of a case, most recent first
+func (r *CollectionRepository) ListContacts(ctx context.Context, caseID int64) ([]*domain.CollectionContact, error) {
+	query := `
+		SELECT id, case_id, method, reached, notes, contacted_by, contacted_at
+		FROM collection_contacts
+		WHERE case_id = $1
+		ORDER BY contacted_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection contacts: %w", err)
+	}
+	defer rows.Close()
+
+	contacts := []*domain.CollectionContact{}
+	for rows.Next() {
+		contact := &domain.CollectionContact{}
+		var notes sql.NullString
+		if err := rows.Scan(&contact.ID, &contact.CaseID, &contact.Method, &contact.Reached, &notes,
+			&contact.ContactedBy, &contact.ContactedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan collection contact: %w", err)
+		}
+		contact.Notes = StringPtrVal(notes)
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+func (r *CollectionRepository) scanCaseRow(row rowScanner) (*domain.CollectionCase, error) {
+	collectionCase := &domain.CollectionCase{}
+	var assignedTo sql.NullInt64
+	var promiseDate, lastContactAt, resolvedAt sql.NullTime
+	var promiseAmount sql.NullFloat64
+	var notes sql.NullString
+
+	err := row.Scan(
+		&collectionCase.ID, &collectionCase.LoanID, &collectionCase.LoanNumber, &collectionCase.CustomerID,
+		&collectionCase.BranchID, &collectionCase.Status, &assignedTo,
+		&collectionCase.DaysOverdue, &collectionCase.AmountDue, &promiseDate, &promiseAmount, &notes, &lastContactAt,
+		&collectionCase.EnteredAt, &resolvedAt, &collectionCase.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collectionCase.AssignedTo = Int64Ptr(assignedTo)
+	if promiseDate.Valid {
+		date := domain.DateFromTime(promiseDate.Time)
+		collectionCase.PromiseDate = &date
+	}
+	collectionCase.PromiseAmount = Float64Ptr(promiseAmount)
+	collectionCase.Notes = StringPtrVal(notes)
+	collectionCase.LastContactAt = TimePtr(lastContactAt)
+	collectionCase.ResolvedAt = TimePtr(resolvedAt)
+	return collectionCase, nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// ContactVerificationRepository implements repository.ContactVerificationRepository
+type ContactVerificationRepository struct {
+	db *DB
+}
+
+// NewContactVerificationRepository creates a new ContactVerificationRepository
+func NewContactVerificationRepository(db *DB) *ContactVerificationRepository {
+	return &ContactVerificationRepository{db: db}
+}
+
+// Create stores a new verification code
+func (r *ContactVerificationRepository) Create(ctx context.Context, v *domain.ContactVerification) error {
+	query := `
+		INSERT INTO contact_verifications (customer_id, phone, channel, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, attempts, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, v.CustomerID, v.Phone, v.Channel, v.CodeHash, v.ExpiresAt).
+		Scan(&v.ID, &v.Attempts, &v.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create contact verification: %w", err)
+	}
+	return nil
+}
+
+// GetLatest retrieves the customer's most recent verification code
+func (r *ContactVerificationRepository) GetLatest(ctx context.Context, customerID int64) (*domain.ContactVerification, error) {
+	query := `
+		SELECT id, customer_id, phone, channel, code_hash, attempts, expires_at, verified_at, created_at
+		FROM contact_verifications
+		WHERE customer_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	v := &domain.ContactVerification{}
+	var verifiedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, customerID).Scan(
+		&v.ID, &v.CustomerID, &v.Phone, &v.Channel, &v.CodeHash, &v.Attempts, &v.ExpiresAt, &verifiedAt, &v.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get contact verification: %w", err)
+	}
+	v.VerifiedAt = TimePtr(verifiedAt)
+
+	return v, nil
+}
+
+// IncrementAttempts records a failed confirmation attempt
+func (r *ContactVerificationRepository) IncrementAttempts(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE contact_verifications SET attempts = attempts + 1 WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to update contact verification: %w", err)
+	}
+	return nil
+}
+
+// MarkVerified records when the code was confirmed
+func (r *ContactVerificationRepository) MarkVerified(ctx context.Context, id int64, verifiedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE contact_verifications SET verified_at = $2 WHERE id = $1`, id, verifiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update contact verification: %w", err)
+	}
+	return nil
+}
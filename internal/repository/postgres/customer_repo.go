@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
@@ -29,6 +30,7 @@ func (r *CustomerRepository) GetByID(ctx context.Context, id int64) (*domain.Cus
 			   occupation, workplace, monthly_income,
 			   credit_limit, credit_score, total_loans, total_paid, total_defaulted,
 			   is_active, is_blocked, blocked_reason, notes, photo_url,
+			   phone_verified_at, phone_verified_channel,
 			   created_by, created_at, updated_at, deleted_at
 		FROM customers
 		WHERE id = $1 AND deleted_at IS NULL
@@ -46,6 +48,7 @@ func (r *CustomerRepository) GetByIdentity(ctx context.Context, branchID int64,
 			   occupation, workplace, monthly_income,
 			   credit_limit, credit_score, total_loans, total_paid, total_defaulted,
 			   is_active, is_blocked, blocked_reason, notes, photo_url,
+			   phone_verified_at, phone_verified_channel,
 			   created_by, created_at, updated_at, deleted_at
 		FROM customers
 		WHERE branch_id = $1 AND identity_type = $2 AND identity_number = $3 AND deleted_at IS NULL
@@ -54,15 +57,11 @@ func (r *CustomerRepository) GetByIdentity(ctx context.Context, branchID int64,
 	return r.scanCustomer(r.db.QueryRowContext(ctx, query, branchID, identityType, identityNumber))
 }
 
-// List retrieves customers with pagination and filters
-func (r *CustomerRepository) List(ctx context.Context, params repository.CustomerListParams) (*repository.PaginatedResult[domain.Customer], error) {
-	if params.Page <= 0 {
-		params.Page = 1
-	}
-	if params.PerPage <= 0 {
-		params.PerPage = 20
-	}
+// minPhoneSearchDigits is the fewest digits in a free-text search that are also matched against phones
+const minPhoneSearchDigits = 3
 
+// customerListFilter builds the FROM/WHERE clause and arguments for a customer list
+func customerListFilter(params repository.CustomerListParams) (string, []interface{}) {
 	baseQuery := `FROM customers WHERE deleted_at IS NULL`
 	args := []interface{}{}
 	argCount := 0
@@ -87,10 +86,44 @@ func (r *CustomerRepository) List(ctx context.Context, params repository.Custome
 
 	if params.Search != "" {
 		argCount++
-		baseQuery += fmt.Sprintf(" AND (first_name ILIKE $%d OR last_name ILIKE $%d OR identity_number ILIKE $%d OR phone ILIKE $%d)", argCount, argCount, argCount, argCount)
+		search := fmt.Sprintf("first_name ILIKE $%d OR last_name ILIKE $%d OR identity_number ILIKE $%d OR phone ILIKE $%d", argCount, argCount, argCount, argCount)
 		args = append(args, "%"+params.Search+"%")
+		// Numeric searches also match phones typed with other formatting
+		if digits := domain.NormalizePhone(params.Search); len(digits) >= minPhoneSearchDigits {
+			argCount++
+			search += fmt.Sprintf(" OR phone_normalized LIKE $%d", argCount)
+			args = append(args, "%"+digits+"%")
+		}
+		baseQuery += " AND (" + search + ")"
+	}
+
+	if digits := domain.NormalizePhone(params.Phone); digits != "" {
+		argCount++
+		baseQuery += fmt.Sprintf(" AND phone_normalized LIKE $%d", argCount)
+		args = append(args, "%"+digits+"%")
+	}
+
+	if email := strings.TrimSpace(params.Email); email != "" {
+		argCount++
+		baseQuery += fmt.Sprintf(" AND email ILIKE $%d", argCount)
+		args = append(args, "%"+email+"%")
+	}
+
+	return baseQuery, args
+}
+
+// List retrieves customers with pagination and filters
+func (r *CustomerRepository) List(ctx context.Context, params repository.CustomerListParams) (*repository.PaginatedResult[domain.Customer], error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PerPage <= 0 {
+		params.PerPage = 20
 	}
 
+	baseQuery, args := customerListFilter(params)
+	argCount := len(args)
+
 	// Count total
 	var total int
 	countQuery := "SELECT COUNT(*) " + baseQuery
@@ -116,6 +149,7 @@ func (r *CustomerRepository) List(ctx context.Context, params repository.Custome
 			   occupation, workplace, monthly_income,
 			   credit_limit, credit_score, total_loans, total_paid, total_defaulted,
 			   is_active, is_blocked, blocked_reason, notes, photo_url,
+			   phone_verified_at, phone_verified_channel,
 			   created_by, created_at, updated_at, deleted_at
 		%s ORDER BY %s %s LIMIT $%d OFFSET $%d`,
 		baseQuery, orderBy, order, argCount+1, argCount+2,
@@ -193,7 +227,8 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *domain.Custom
 			emergency_contact_name = $15, emergency_contact_phone = $16, emergency_contact_relation = $17,
 			occupation = $18, workplace = $19, monthly_income = $20,
 			credit_limit = $21, is_active = $22, is_blocked = $23, blocked_reason = $24,
-			notes = $25, photo_url = $26, updated_at = NOW()
+			notes = $25, photo_url = $26, phone_verified_at = $27, phone_verified_channel = $28,
+			updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -207,6 +242,7 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *domain.Custom
 		NullString(customer.Occupation), NullString(customer.Workplace), NullFloat64(&customer.MonthlyIncome),
 		customer.CreditLimit, customer.IsActive, customer.IsBlocked, NullString(customer.BlockedReason),
 		NullString(customer.Notes), NullString(customer.PhotoURL),
+		NullTime(customer.PhoneVerifiedAt), NullString(customer.PhoneVerifiedChannel),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update customer: %w", err)
@@ -222,10 +258,25 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *domain.Custom
 
 // Delete soft deletes a customer
 func (r *CustomerRepository) Delete(ctx context.Context, id int64) error {
+	// Customers are soft deleted, so foreign keys do not protect their open loans
+	var openLoans bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM loans WHERE customer_id = $1 AND status IN ('active', 'overdue', 'pending_authorization') AND deleted_at IS NULL)`,
+		id).Scan(&openLoans)
+	if err != nil {
+		return fmt.Errorf("failed to check customer references: %w", err)
+	}
+	if openLoans {
+		return &repository.InUseError{Entity: "customer", ReferencedBy: "open loans"}
+	}
+
 	query := `UPDATE customers SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
+		if inUse := inUseError(err, "customer"); inUse != nil {
+			return inUse
+		}
 		return fmt.Errorf("failed to delete customer: %w", err)
 	}
 
@@ -287,10 +338,10 @@ func (r *CustomerRepository) UpdateCreditInfo(ctx context.Context, id int64, inf
 // Helper functions
 func (r *CustomerRepository) scanCustomer(row *sql.Row) (*domain.Customer, error) {
 	c := &domain.Customer{}
-	var birthDate, deletedAt sql.NullTime
+	var birthDate, phoneVerifiedAt, deletedAt sql.NullTime
 	var gender, phoneSecondary, email, address, city, state, postalCode sql.NullString
 	var emergencyName, emergencyPhone, emergencyRelation sql.NullString
-	var occupation, workplace, blockedReason, notes, photoURL sql.NullString
+	var occupation, workplace, blockedReason, notes, photoURL, phoneVerifiedChannel sql.NullString
 	var monthlyIncome sql.NullFloat64
 	var createdBy sql.NullInt64
 
@@ -301,6 +352,7 @@ func (r *CustomerRepository) scanCustomer(row *sql.Row) (*domain.Customer, error
 		&occupation, &workplace, &monthlyIncome,
 		&c.CreditLimit, &c.CreditScore, &c.TotalLoans, &c.TotalPaid, &c.TotalDefaulted,
 		&c.IsActive, &c.IsBlocked, &blockedReason, &notes, &photoURL,
+		&phoneVerifiedAt, &phoneVerifiedChannel,
 		&createdBy, &c.CreatedAt, &c.UpdatedAt, &deletedAt,
 	)
 
@@ -330,6 +382,8 @@ func (r *CustomerRepository) scanCustomer(row *sql.Row) (*domain.Customer, error
 	c.BlockedReason = StringPtr(blockedReason)
 	c.Notes = StringPtr(notes)
 	c.PhotoURL = StringPtr(photoURL)
+	c.PhoneVerifiedAt = TimePtr(phoneVerifiedAt)
+	c.PhoneVerifiedChannel = StringPtr(phoneVerifiedChannel)
 	if createdBy.Valid {
 		c.CreatedBy = createdBy.Int64
 	}
@@ -340,10 +394,10 @@ func (r *CustomerRepository) scanCustomer(row *sql.Row) (*domain.Customer, error
 
 func (r *CustomerRepository) scanCustomerRow(rows *sql.Rows) (*domain.Customer, error) {
 	c := &domain.Customer{}
-	var birthDate, deletedAt sql.NullTime
+	var birthDate, phoneVerifiedAt, deletedAt sql.NullTime
 	var gender, phoneSecondary, email, address, city, state, postalCode sql.NullString
 	var emergencyName, emergencyPhone, emergencyRelation sql.NullString
-	var occupation, workplace, blockedReason, notes, photoURL sql.NullString
+	var occupation, workplace, blockedReason, notes, photoURL, phoneVerifiedChannel sql.NullString
 	var monthlyIncome sql.NullFloat64
 	var createdBy sql.NullInt64
 
@@ -354,6 +408,7 @@ func (r *CustomerRepository) scanCustomerRow(rows *sql.Rows) (*domain.Customer,
 		&occupation, &workplace, &monthlyIncome,
 		&c.CreditLimit, &c.CreditScore, &c.TotalLoans, &c.TotalPaid, &c.TotalDefaulted,
 		&c.IsActive, &c.IsBlocked, &blockedReason, &notes, &photoURL,
+		&phoneVerifiedAt, &phoneVerifiedChannel,
 		&createdBy, &c.CreatedAt, &c.UpdatedAt, &deletedAt,
 	)
 
@@ -380,6 +435,8 @@ func (r *CustomerRepository) scanCustomerRow(rows *sql.Rows) (*domain.Customer,
 	c.BlockedReason = StringPtr(blockedReason)
 	c.Notes = StringPtr(notes)
 	c.PhotoURL = StringPtr(photoURL)
+	c.PhoneVerifiedAt = TimePtr(phoneVerifiedAt)
+	c.PhoneVerifiedChannel = StringPtr(phoneVerifiedChannel)
 	if createdBy.Valid {
 		c.CreatedBy = createdBy.Int64
 	}
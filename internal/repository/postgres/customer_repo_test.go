@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+func TestCustomerListFilter_PhoneLastDigits(t *testing.T) {
+	query, args := customerListFilter(repository.CustomerListParams{Phone: "12-34"})
+
+	assert.Equal(t, "FROM customers WHERE deleted_at IS NULL AND phone_normalized LIKE $1", query)
+	assert.Equal(t, []interface{}{"%1234%"}, args)
+
+	// The pattern matches the stored, normalized phone of the customer
+	stored := domain.NormalizePhone("+502 5555-1234")
+	assert.True(t, strings.Contains(stored, strings.Trim(args[0].(string), "%")))
+}
+
+func TestCustomerListFilter_CombinedWithNameSearch(t *testing.T) {
+	query, args := customerListFilter(repository.CustomerListParams{
+		BranchID: 2,
+		Search:   "López",
+		Phone:    "1234",
+		Email:    " ana@",
+	})
+
+	assert.Equal(t, "FROM customers WHERE deleted_at IS NULL AND branch_id = $1"+
+		" AND (first_name ILIKE $2 OR last_name ILIKE $2 OR identity_number ILIKE $2 OR phone ILIKE $2)"+
+		" AND phone_normalized LIKE $3 AND email ILIKE $4", query)
+	assert.Equal(t, []interface{}{int64(2), "%López%", "%1234%", "%ana@%"}, args)
+}
+
+func TestCustomerListFilter_NumericSearchMatchesNormalizedPhone(t *testing.T) {
+	query, args := customerListFilter(repository.CustomerListParams{Search: "5555-1234"})
+
+	assert.Contains(t, query, "OR phone_normalized LIKE $2)")
+	assert.Equal(t, []interface{}{"%5555-1234%", "%55551234%"}, args)
+}
+
+func TestCustomerListFilter_IgnoresPhoneWithoutDigits(t *testing.T) {
+	query, args := customerListFilter(repository.CustomerListParams{Phone: "abc"})
+
+	assert.Equal(t, "FROM customers WHERE deleted_at IS NULL", query)
+	assert.Empty(t, args)
+}
@@ -38,7 +38,7 @@ func (r *DocumentRepository) Create(ctx context.Context, doc *domain.Document) e
 		doc.FileSize,
 		doc.MimeType,
 		NullString(doc.ContentHash),
-		doc.CreatedBy,
+		sql.NullInt64{Int64: doc.CreatedBy, Valid: doc.CreatedBy > 0}, // 0 for documents generated by jobs
 	).Scan(&doc.ID, &doc.CreatedAt)
 
 	if err != nil {
@@ -58,7 +58,7 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id int64) (*domain.Doc
 
 	doc := &domain.Document{}
 	var filePath, fileURL, contentHash sql.NullString
-	var fileSize sql.NullInt64
+	var fileSize, createdBy sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&doc.ID,
@@ -72,7 +72,7 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id int64) (*domain.Doc
 		&fileSize,
 		&doc.MimeType,
 		&contentHash,
-		&doc.CreatedBy,
+		&createdBy,
 		&doc.CreatedAt,
 	)
 
@@ -86,6 +86,7 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id int64) (*domain.Doc
 	doc.FilePath = StringPtr(filePath)
 	doc.FileURL = StringPtr(fileURL)
 	doc.ContentHash = StringPtr(contentHash)
+	doc.CreatedBy = createdBy.Int64
 	if fileSize.Valid {
 		doc.FileSize = int(fileSize.Int64)
 	}
@@ -112,7 +113,7 @@ func (r *DocumentRepository) ListByReference(ctx context.Context, refType string
 	for rows.Next() {
 		doc := &domain.Document{}
 		var filePath, fileURL, contentHash sql.NullString
-		var fileSize sql.NullInt64
+		var fileSize, createdBy sql.NullInt64
 
 		err := rows.Scan(
 			&doc.ID,
@@ -126,7 +127,7 @@ func (r *DocumentRepository) ListByReference(ctx context.Context, refType string
 			&fileSize,
 			&doc.MimeType,
 			&contentHash,
-			&doc.CreatedBy,
+			&createdBy,
 			&doc.CreatedAt,
 		)
 		if err != nil {
@@ -136,6 +137,7 @@ func (r *DocumentRepository) ListByReference(ctx context.Context, refType string
 		doc.FilePath = StringPtr(filePath)
 		doc.FileURL = StringPtr(fileURL)
 		doc.ContentHash = StringPtr(contentHash)
+		doc.CreatedBy = createdBy.Int64
 		if fileSize.Valid {
 			doc.FileSize = int(fileSize.Int64)
 		}
@@ -145,3 +147,34 @@ func (r *DocumentRepository) ListByReference(ctx context.Context, refType string
 
 	return docs, nil
 }
+
+// UpdateFile points a document at a newly stored file
+func (r *DocumentRepository) UpdateFile(ctx context.Context, doc *domain.Document) error {
+	query := `
+		UPDATE documents
+		SET file_path = $2, file_url = $3, file_size = $4, mime_type = $5, content_hash = $6
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		doc.ID,
+		NullString(doc.FilePath),
+		NullString(doc.FileURL),
+		doc.FileSize,
+		doc.MimeType,
+		NullString(doc.ContentHash),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update document file: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("document not found")
+	}
+
+	return nil
+}
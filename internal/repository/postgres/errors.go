@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"pawnshop/internal/repository"
+)
+
+// pgForeignKeyViolation is the Postgres error code raised when a delete or
+// update would leave rows referencing a missing record
+const pgForeignKeyViolation = "23503"
+
+// pgUniqueViolation is the Postgres error code raised when an insert or update
+// would duplicate a unique key
+const pgUniqueViolation = "23505"
+
+// activeLoanPerItemIndex is the partial unique index allowing a single active
+// or overdue loan per item
+const activeLoanPerItemIndex = "idx_loans_active_item"
+
+// openSessionPerRegisterIndex is the partial unique index allowing a single
+// open cash session per register
+const openSessionPerRegisterIndex = "idx_cash_sessions_open_register"
+
+// paymentIdempotencyKeyIndex is the unique index on the idempotency key each
+// user creates payments with
+const paymentIdempotencyKeyIndex = "idx_payments_idempotency_key"
+
+// inUseError translates a foreign-key violation raised while deleting an entity
+// into a repository.InUseError naming the referencing table. It returns nil for
+// any other error.
+func inUseError(err error, entity string) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgForeignKeyViolation {
+		return nil
+	}
+
+	referencedBy := "other records"
+	if pgErr.TableName != "" {
+		referencedBy = strings.ReplaceAll(pgErr.TableName, "_", " ")
+	}
+	return &repository.InUseError{Entity: entity, ReferencedBy: referencedBy}
+}
+
+// loanCreateError translates a violation of the one-active-loan-per-item index,
+// raised when two loans on the same item are created concurrently, into
+// repository.ErrItemHasActiveLoan
+func loanCreateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == activeLoanPerItemIndex {
+		return repository.ErrItemHasActiveLoan
+	}
+	return fmt.Errorf("failed to create loan: %w", err)
+}
+
+// cashSessionCreateError translates a violation of the one-open-session-per-
+// register index, raised when two sessions are opened on the same register
+// concurrently, into repository.ErrRegisterHasOpenSession
+func cashSessionCreateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == openSessionPerRegisterIndex {
+		return repository.ErrRegisterHasOpenSession
+	}
+	return fmt.Errorf("failed to create cash session: %w", err)
+}
+
+// paymentCreateError translates a violation of the idempotency key index,
+// raised when the same request creates a payment twice concurrently, into
+// repository.ErrDuplicateIdempotencyKey
+func paymentCreateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == paymentIdempotencyKeyIndex {
+		return repository.ErrDuplicateIdempotencyKey
+	}
+	return fmt.Errorf("failed to create payment: %w", err)
+}
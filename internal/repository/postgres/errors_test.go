@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"pawnshop/internal/repository"
+)
+
+func TestInUseError_ForeignKeyViolation(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: pgForeignKeyViolation, TableName: "loan_items"}
+
+	err := inUseError(fmt.Errorf("failed to delete category: %w", pgErr), "category")
+
+	assert.True(t, errors.Is(err, repository.ErrInUse))
+	assert.Equal(t, "category is still referenced by loan items", err.Error())
+}
+
+func TestInUseError_UnknownTable(t *testing.T) {
+	err := inUseError(&pgconn.PgError{Code: pgForeignKeyViolation}, "branch")
+
+	assert.Equal(t, "branch is still referenced by other records", err.Error())
+}
+
+func TestInUseError_OtherErrors(t *testing.T) {
+	assert.Nil(t, inUseError(&pgconn.PgError{Code: "23505"}, "category"))
+	assert.Nil(t, inUseError(errors.New("connection refused"), "category"))
+}
+
+func TestLoanCreateError_ActiveLoanPerItem(t *testing.T) {
+	err := loanCreateError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: activeLoanPerItemIndex})
+
+	assert.True(t, errors.Is(err, repository.ErrItemHasActiveLoan))
+}
+
+func TestLoanCreateError_OtherErrors(t *testing.T) {
+	err := loanCreateError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "loans_loan_number_key"})
+
+	assert.False(t, errors.Is(err, repository.ErrItemHasActiveLoan))
+	assert.Contains(t, err.Error(), "failed to create loan")
+}
+
+func TestPaymentCreateError_IdempotencyKey(t *testing.T) {
+	err := paymentCreateError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: paymentIdempotencyKeyIndex})
+
+	assert.True(t, errors.Is(err, repository.ErrDuplicateIdempotencyKey))
+
+	err = paymentCreateError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "payments_payment_number_key"})
+
+	assert.False(t, errors.Is(err, repository.ErrDuplicateIdempotencyKey))
+	assert.Contains(t, err.Error(), "failed to create payment")
+}
+
+func TestCashSessionCreateError_OpenSessionPerRegister(t *testing.T) {
+	err := cashSessionCreateError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: openSessionPerRegisterIndex})
+
+	assert.True(t, errors.Is(err, repository.ErrRegisterHasOpenSession))
+
+	err = cashSessionCreateError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "idx_cash_sessions_open_user"})
+
+	assert.False(t, errors.Is(err, repository.ErrRegisterHasOpenSession))
+	assert.Contains(t, err.Error(), "failed to create cash session")
+}
@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// EventRepository implements repository.EventRepository
+type EventRepository struct {
+	db *DB
+}
+
+// NewEventRepository creates a new EventRepository
+func NewEventRepository(db *DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// Create records a new event
+func (r *EventRepository) Create(ctx context.Context, event *domain.Event) error {
+	if event.Payload == nil {
+		event.Payload = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode event payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO events (event_type, branch_id, entity_type, entity_id, user_id, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		event.EventType, NullInt64(event.BranchID), event.EntityType, event.EntityID,
+		NullInt64(event.UserID), string(payload),
+	).Scan(&event.ID, &event.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves events with filters, most recent first
+func (r *EventRepository) List(ctx context.Context, params repository.EventListParams) (*repository.PaginatedResult[domain.Event], error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PerPage <= 0 {
+		params.PerPage = 50
+	}
+
+	// Build WHERE clause
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argNum := 1
+
+	if params.BranchID != nil {
+		where += fmt.Sprintf(" AND e.branch_id = $%d", argNum)
+		args = append(args, *params.BranchID)
+		argNum++
+	}
+
+	if params.EventType != "" {
+		where += fmt.Sprintf(" AND e.event_type = $%d", argNum)
+		args = append(args, params.EventType)
+		argNum++
+	}
+
+	if params.EntityType != "" {
+		where += fmt.Sprintf(" AND e.entity_type = $%d", argNum)
+		args = append(args, params.EntityType)
+		argNum++
+	}
+
+	if params.EntityID != nil {
+		where += fmt.Sprintf(" AND e.entity_id = $%d", argNum)
+		args = append(args, *params.EntityID)
+		argNum++
+	}
+
+	if params.DateFrom != nil {
+		where += fmt.Sprintf(" AND e.created_at >= $%d", argNum)
+		args = append(args, *params.DateFrom)
+		argNum++
+	}
+
+	if params.DateTo != nil {
+		where += fmt.Sprintf(" AND e.created_at <= $%d", argNum)
+		args = append(args, *params.DateTo)
+		argNum++
+	}
+
+	// Count total
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM events e %s", where)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count events: %w", err)
+	}
+
+	// Get data
+	offset := (params.Page - 1) * params.PerPage
+	query := fmt.Sprintf(`
+		SELECT
+			e.id, e.event_type, e.branch_id, e.entity_type, e.entity_id, e.user_id, e.payload, e.created_at,
+			COALESCE(u.first_name || ' ' || u.last_name, '') as user_name,
+			COALESCE(b.name, '') as branch_name
+		FROM events e
+		LEFT JOIN users u ON u.id = e.user_id
+		LEFT JOIN branches b ON b.id = e.branch_id
+		%s
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, argNum, argNum+1)
+
+	args = append(args, params.PerPage, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		var event domain.Event
+		var branchID, userID sql.NullInt64
+		var payload []byte
+
+		err := rows.Scan(
+			&event.ID, &event.EventType, &branchID, &event.EntityType, &event.EntityID,
+			&userID, &payload, &event.CreatedAt, &event.UserName, &event.BranchName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		event.BranchID = Int64Ptr(branchID)
+		event.UserID = Int64Ptr(userID)
+		if err := json.Unmarshal(payload, &event.Payload); err != nil {
+			return nil, fmt.Errorf("failed to decode event payload: %w", err)
+		}
+
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	totalPages := total / params.PerPage
+	if total%params.PerPage > 0 {
+		totalPages++
+	}
+
+	return &repository.PaginatedResult[domain.Event]{
+		Data:       events,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// WebhookRepository implements repository.WebhookRepository
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookColumns = `id, name, url, secret, event_types, is_active, COALESCE(created_by, 0), created_at, updated_at`
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND deleted_at IS NULL`
+
+	webhook, err := r.scanWebhookRow(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// List retrieves all webhooks
+func (r *WebhookRepository) List(ctx context.Context) ([]*domain.Webhook, error) {
+	return r.list(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE deleted_at IS NULL ORDER BY name ASC`)
+}
+
+// ListActive retrieves the webhooks that currently receive events
+func (r *WebhookRepository) ListActive(ctx context.Context) ([]*domain.Webhook, error) {
+	return r.list(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE is_active = TRUE AND deleted_at IS NULL ORDER BY id ASC`)
+}
+
+func (r *WebhookRepository) list(ctx context.Context, query string) ([]*domain.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*domain.Webhook{}
+	for rows.Next() {
+		webhook, err := r.scanWebhookRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Create creates a new webhook
+func (r *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []string{}
+	}
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event types: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhooks (name, url, secret, event_types, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	var createdBy *int64
+	if webhook.CreatedBy != 0 {
+		createdBy = &webhook.CreatedBy
+	}
+
+	err = r.db.QueryRowContext(ctx, query,
+		webhook.Name, webhook.URL, webhook.Secret, string(eventTypes), webhook.IsActive, NullInt64(createdBy),
+	).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a webhook
+func (r *WebhookRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE webhooks SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) scanWebhookRow(row rowScanner) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{}
+	var eventTypes []byte
+
+	err := row.Scan(
+		&webhook.ID, &webhook.Name, &webhook.URL, &webhook.Secret, &eventTypes, &webhook.IsActive,
+		&webhook.CreatedBy, &webhook.CreatedAt, &webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eventTypes, &webhook.EventTypes); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook event types: %w", err)
+	}
+	return webhook, nil
+}
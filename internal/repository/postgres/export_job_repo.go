@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// ExportJobRepository implements repository.ExportJobRepository
+type ExportJobRepository struct {
+	db *DB
+}
+
+// NewExportJobRepository creates a new ExportJobRepository
+func NewExportJobRepository(db *DB) *ExportJobRepository {
+	return &ExportJobRepository{db: db}
+}
+
+const exportJobColumns = `id, dataset, branch_id, status, cursor, rows_exported, bytes_written, attempts,
+	file_name, error, created_by, created_at, updated_at, completed_at`
+
+// Create queues an export job
+func (r *ExportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	query := `
+		INSERT INTO export_jobs (dataset, branch_id, status, file_name, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.Dataset, job.BranchID, job.Status, job.FileName, job.CreatedBy,
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an export job by ID
+func (r *ExportJobRepository) GetByID(ctx context.Context, id int64) (*domain.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE id = $1`
+
+	job, err := r.scanJobRow(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("export job not found")
+		}
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	return job, nil
+}
+
+// FindActive retrieves the user's unfinished export of a dataset and branch
+func (r *ExportJobRepository) FindActive(ctx context.Context, dataset domain.ExportDataset, branchID, createdBy int64) (*domain.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs
+		WHERE dataset = $1 AND branch_id = $2 AND created_by = $3 AND status IN ('pending', 'running')
+		ORDER BY id DESC
+		LIMIT 1`
+
+	job, err := r.scanJobRow(r.db.QueryRowContext(ctx, query, dataset, branchID, createdBy))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find active export job: %w", err)
+	}
+	return job, nil
+}
+
+// ListUnfinished lists the pending and interrupted jobs, oldest first
+func (r *ExportJobRepository) ListUnfinished(ctx context.Context) ([]*domain.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs
+		WHERE status IN ('pending', 'running')
+		ORDER BY id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*domain.ExportJob{}
+	for rows.Next() {
+		job, err := r.scanJobRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// SaveProgress stores the job's status, cursor and counters
+func (r *ExportJobRepository) SaveProgress(ctx context.Context, job *domain.ExportJob) error {
+	query := `
+		UPDATE export_jobs
+		SET status = $2, cursor = $3, rows_exported = $4, bytes_written = $5, attempts = $6,
+			error = $7, completed_at = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.ID, job.Status, job.Cursor, job.RowsExported, job.BytesWritten, job.Attempts,
+		NullStringPtr(job.Error), NullTime(job.CompletedAt),
+	).Scan(&job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save export job progress: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ExportJobRepository) scanJobRow(row rowScanner) (*domain.ExportJob, error) {
+	job := &domain.ExportJob{}
+	var jobError sql.NullString
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID, &job.Dataset, &job.BranchID, &job.Status, &job.Cursor, &job.RowsExported, &job.BytesWritten,
+		&job.Attempts, &job.FileName, &jobError, &job.CreatedBy, &job.CreatedAt, &job.UpdatedAt, &completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Error = StringPtrVal(jobError)
+	job.CompletedAt = TimePtr(completedAt)
+	return job, nil
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// FXRateRepository implements repository.FXRateRepository
+type FXRateRepository struct {
+	db *DB
+}
+
+// NewFXRateRepository creates a new FXRateRepository
+func NewFXRateRepository(db *DB) *FXRateRepository {
+	return &FXRateRepository{db: db}
+}
+
+const fxRateColumns = `id, from_currency, to_currency, rate_date, rate, created_at, updated_at`
+
+// Upsert creates the rate of a pair on a day, or replaces it if it was already set
+func (r *FXRateRepository) Upsert(ctx context.Context, rate *domain.FXRate) error {
+	query := `
+		INSERT INTO fx_rates (from_currency, to_currency, rate_date, rate)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (from_currency, to_currency, rate_date)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rate.FromCurrency, rate.ToCurrency, rate.RateDate, rate.Rate,
+	).Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatest retrieves the most recent rate of the pair on or before the given day,
+// or nil if the pair has no rate yet
+func (r *FXRateRepository) GetLatest(ctx context.Context, fromCurrency, toCurrency string, day domain.Date) (*domain.FXRate, error) {
+	query := `SELECT ` + fxRateColumns + ` FROM fx_rates
+		WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+		ORDER BY rate_date DESC
+		LIMIT 1`
+
+	rate, err := r.scanFXRateRow(r.db.QueryRowContext(ctx, query, fromCurrency, toCurrency, day))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// List retrieves exchange rates, most recent first
+func (r *FXRateRepository) List(ctx context.Context, params repository.FXRateListParams) ([]*domain.FXRate, error) {
+	var conditions []string
+	var args []interface{}
+
+	if params.FromCurrency != nil {
+		args = append(args, *params.FromCurrency)
+		conditions = append(conditions, fmt.Sprintf("from_currency = $%d", len(args)))
+	}
+	if params.ToCurrency != nil {
+		args = append(args, *params.ToCurrency)
+		conditions = append(conditions, fmt.Sprintf("to_currency = $%d", len(args)))
+	}
+	if params.DateFrom != nil {
+		args = append(args, *params.DateFrom)
+		conditions = append(conditions, fmt.Sprintf("rate_date >= $%d", len(args)))
+	}
+	if params.DateTo != nil {
+		args = append(args, *params.DateTo)
+		conditions = append(conditions, fmt.Sprintf("rate_date <= $%d", len(args)))
+	}
+
+	query := `SELECT ` + fxRateColumns + ` FROM fx_rates`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY rate_date DESC, from_currency ASC, to_currency ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []*domain.FXRate{}
+	for rows.Next() {
+		rate, err := r.scanFXRateRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// Delete deletes an exchange rate
+func (r *FXRateRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM fx_rates WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("exchange rate not found")
+	}
+
+	return nil
+}
+
+func (r *FXRateRepository) scanFXRateRow(row rowScanner) (*domain.FXRate, error) {
+	rate := &domain.FXRate{}
+	err := row.Scan(
+		&rate.ID, &rate.FromCurrency, &rate.ToCurrency, &rate.RateDate, &rate.Rate,
+		&rate.CreatedAt, &rate.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// InventoryValuationRepository implements repository.InventoryValuationRepository
+type InventoryValuationRepository struct {
+	db *DB
+}
+
+// NewInventoryValuationRepository creates a new InventoryValuationRepository
+func NewInventoryValuationRepository(db *DB) *InventoryValuationRepository {
+	return &InventoryValuationRepository{db: db}
+}
+
+// ListOnHand lists the items a branch owned and held at asOf. An item's status
+// at that time is the last status its history moved it to by then; items with
+// no earlier history were in the status their first later change moved them
+// out of, or are still in their current one.
+func (r *InventoryValuationRepository) ListOnHand(ctx context.Context, branchID int64, asOf time.Time) ([]*domain.InventoryItemValue, error) {
+	query := `
+		SELECT id, sku, name, status, appraised_value, acquisition_price
+		FROM (
+			SELECT i.id, i.sku, i.name, i.appraised_value, i.acquisition_price,
+				   COALESCE(before.new_status, after.old_status, i.status) AS status
+			FROM items i
+			LEFT JOIN LATERAL (
+				SELECT h.new_status FROM item_history h
+				WHERE h.item_id = i.id AND h.created_at <= $2 AND h.new_status IS NOT NULL
+				ORDER BY h.created_at DESC, h.id DESC
+				LIMIT 1
+			) before ON true
+			LEFT JOIN LATERAL (
+				SELECT h.old_status FROM item_history h
+				WHERE h.item_id = i.id AND h.created_at > $2 AND h.old_status IS NOT NULL
+				ORDER BY h.created_at ASC, h.id ASC
+				LIMIT 1
+			) after ON true
+			WHERE i.branch_id = $1 AND i.created_at <= $2
+			  AND (i.deleted_at IS NULL OR i.deleted_at > $2)
+		) on_hand
+		WHERE status IN ('available', 'for_sale', 'confiscated', 'in_transfer')
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, branchID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items on hand: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*domain.InventoryItemValue{}
+	for rows.Next() {
+		item := &domain.InventoryItemValue{}
+		var acquisitionPrice sql.NullFloat64
+		if err := rows.Scan(&item.ItemID, &item.SKU, &item.Name, &item.Status, &item.AppraisedValue, &acquisitionPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan item on hand: %w", err)
+		}
+		item.AcquisitionPrice = Float64Ptr(acquisitionPrice)
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// ItemAttentionRepository implements repository.ItemAttentionRepository
+type ItemAttentionRepository struct {
+	db *DB
+}
+
+// NewItemAttentionRepository creates a new ItemAttentionRepository
+func NewItemAttentionRepository(db *DB) *ItemAttentionRepository {
+	return &ItemAttentionRepository{db: db}
+}
+
+// ListCandidates lists a branch's items that may need attention. Each branch of
+// the filter matches a partial index on items, so only the few items in those
+// states are read. An item's status date is the last time its history shows it
+// moving to its current status, falling back to when it was registered.
+func (r *ItemAttentionRepository) ListCandidates(ctx context.Context, branchID int64, minPhotoValue float64) ([]*domain.ItemAttentionCandidate, error) {
+	query := `
+		SELECT i.id, i.branch_id, i.sku, i.name, i.status, i.appraised_value,
+			   COALESCE(cardinality(i.photos), 0), since.at, t.transfer_number, t.shipped_at
+		FROM items i
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(MAX(h.created_at), i.created_at) AS at
+			FROM item_history h
+			WHERE h.item_id = i.id AND h.new_status = i.status
+		) since
+		LEFT JOIN item_transfers t ON t.item_id = i.id AND t.status = 'in_transit'
+		WHERE i.branch_id = $1 AND i.deleted_at IS NULL
+		  AND (i.status IN ('confiscated', 'for_sale', 'in_transfer')
+			   OR (COALESCE(cardinality(i.photos), 0) = 0 AND i.appraised_value >= $2
+				   AND i.status IN ('available', 'pawned', 'collateral')))
+		ORDER BY i.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, branchID, minPhotoValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items needing attention: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []*domain.ItemAttentionCandidate{}
+	for rows.Next() {
+		c := &domain.ItemAttentionCandidate{}
+		var transferNumber sql.NullString
+		var shippedAt sql.NullTime
+		if err := rows.Scan(&c.ItemID, &c.BranchID, &c.SKU, &c.Name, &c.Status, &c.AppraisedValue,
+			&c.PhotoCount, &c.StatusSince, &transferNumber, &shippedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan item needing attention: %w", err)
+		}
+		if transferNumber.Valid {
+			c.TransferNumber = &transferNumber.String
+		}
+		if shippedAt.Valid {
+			c.ShippedAt = &shippedAt.Time
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
@@ -67,9 +67,9 @@ func (r *ItemRepository) List(ctx context.Context, params repository.ItemListPar
 		LEFT JOIN categories c ON i.category_id = c.id
 		LEFT JOIN customers cu ON i.customer_id = cu.id
 		LEFT JOIN branches b ON i.branch_id = b.id
-		WHERE i.deleted_at IS NULL`
-	args := []interface{}{}
-	argCount := 0
+		WHERE (i.deleted_at IS NULL OR $1)`
+	args := []interface{}{params.IncludeDeleted}
+	argCount := 1
 
 	if params.BranchID > 0 {
 		argCount++
@@ -162,6 +162,38 @@ func (r *ItemRepository) List(ctx context.Context, params repository.ItemListPar
 	}, nil
 }
 
+// ListAfter lists up to limit items with an ID above afterID in ID order
+func (r *ItemRepository) ListAfter(ctx context.Context, branchID, afterID int64, limit int) ([]*domain.Item, error) {
+	query := `
+		SELECT id, branch_id, category_id, customer_id, sku, name, description,
+			   brand, model, serial_number, color, condition,
+			   appraised_value, loan_value, sale_price, status,
+			   weight, purity, notes, tags, acquisition_type, acquisition_date, acquisition_price,
+			   photos, delivered_at, created_by, updated_by, created_at, updated_at, deleted_at
+		FROM items
+		WHERE id > $1 AND deleted_at IS NULL AND ($2 = 0 OR branch_id = $2)
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, branchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*domain.Item{}
+	for rows.Next() {
+		item, err := r.scanItemRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
 // Create creates a new item
 func (r *ItemRepository) Create(ctx context.Context, item *domain.Item) error {
 	query := `
@@ -242,6 +274,38 @@ func (r *ItemRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// GetDeletedByID retrieves a soft-deleted item by ID
+func (r *ItemRepository) GetDeletedByID(ctx context.Context, id int64) (*domain.Item, error) {
+	query := `
+		SELECT id, branch_id, category_id, customer_id, sku, name, description,
+			   brand, model, serial_number, color, condition,
+			   appraised_value, loan_value, sale_price, status,
+			   weight, purity, notes, tags, acquisition_type, acquisition_date, acquisition_price,
+			   photos, delivered_at, created_by, updated_by, created_at, updated_at, deleted_at
+		FROM items
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	return r.scanItem(r.db.QueryRowContext(ctx, query, id))
+}
+
+// Restore undoes the soft deletion of an item
+func (r *ItemRepository) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE items SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore item: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("item not found")
+	}
+
+	return nil
+}
+
 // UpdateStatus updates item status
 func (r *ItemRepository) UpdateStatus(ctx context.Context, id int64, status domain.ItemStatus) error {
 	query := `UPDATE items SET status = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
@@ -284,11 +348,17 @@ func (r *ItemRepository) CreateHistory(ctx context.Context, history *domain.Item
 		RETURNING id, created_at
 	`
 
+	// Entries written by scheduled jobs have no user
+	var createdBy *int64
+	if history.CreatedBy != 0 {
+		createdBy = &history.CreatedBy
+	}
+
 	err := r.db.QueryRowContext(ctx, query,
 		history.ItemID, history.Action, history.OldStatus, history.NewStatus,
 		NullInt64(history.OldBranchID), NullInt64(history.NewBranchID),
 		NullStringPtr(history.ReferenceType), NullInt64(history.ReferenceID),
-		NullString(history.Notes), history.CreatedBy,
+		NullString(history.Notes), NullInt64(createdBy),
 	).Scan(&history.ID, &history.CreatedAt)
 
 	return err
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// JobRunRepository implements repository.JobRunRepository
+type JobRunRepository struct {
+	db *DB
+}
+
+// NewJobRunRepository creates a new JobRunRepository
+func NewJobRunRepository(db *DB) *JobRunRepository {
+	return &JobRunRepository{db: db}
+}
+
+// Create records a job run
+func (r *JobRunRepository) Create(ctx context.Context, run *domain.JobRun) error {
+	query := `
+		INSERT INTO job_runs (
+			job_name, status, error_message, items_processed,
+			started_at, finished_at, duration_ms, interval_seconds, critical
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		run.JobName, run.Status, NullStringPtr(run.ErrorMessage), run.ItemsProcessed,
+		run.StartedAt, run.FinishedAt, run.DurationMs, run.IntervalSeconds, run.Critical,
+	).Scan(&run.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create job run: %w", err)
+	}
+
+	return nil
+}
+
+// ListStatuses retrieves the latest run, last success and first run of every job
+func (r *JobRunRepository) ListStatuses(ctx context.Context) ([]*domain.JobStatus, error) {
+	query := `
+		SELECT DISTINCT ON (jr.job_name)
+			jr.id, jr.job_name, jr.status, jr.error_message, jr.items_processed,
+			jr.started_at, jr.finished_at, jr.duration_ms, jr.interval_seconds, jr.critical,
+			(SELECT MAX(s.started_at) FROM job_runs s WHERE s.job_name = jr.job_name AND s.status = 'success'),
+			(SELECT MIN(f.started_at) FROM job_runs f WHERE f.job_name = jr.job_name)
+		FROM job_runs jr
+		ORDER BY jr.job_name, jr.started_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []*domain.JobStatus{}
+	for rows.Next() {
+		run := &domain.JobRun{}
+		status := &domain.JobStatus{LastRun: run}
+		var errorMessage sql.NullString
+		var lastSuccessAt sql.NullTime
+
+		err := rows.Scan(
+			&run.ID, &run.JobName, &run.Status, &errorMessage, &run.ItemsProcessed,
+			&run.StartedAt, &run.FinishedAt, &run.DurationMs, &run.IntervalSeconds, &run.Critical,
+			&lastSuccessAt, &status.FirstRunAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job status: %w", err)
+		}
+
+		run.ErrorMessage = StringPtrVal(errorMessage)
+		status.JobName = run.JobName
+		status.Critical = run.Critical
+		status.IntervalSeconds = run.IntervalSeconds
+		if lastSuccessAt.Valid {
+			status.LastSuccessAt = &lastSuccessAt.Time
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
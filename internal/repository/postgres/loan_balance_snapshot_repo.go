@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// LoanBalanceSnapshotRepository implements repository.LoanBalanceSnapshotRepository
+type LoanBalanceSnapshotRepository struct {
+	db *DB
+}
+
+// NewLoanBalanceSnapshotRepository creates a new LoanBalanceSnapshotRepository
+func NewLoanBalanceSnapshotRepository(db *DB) *LoanBalanceSnapshotRepository {
+	return &LoanBalanceSnapshotRepository{db: db}
+}
+
+// UpsertBatch stores the snapshots in one transaction, replacing those already
+// taken for the same loan and day
+func (r *LoanBalanceSnapshotRepository) UpsertBatch(ctx context.Context, snapshots []*domain.LoanBalanceSnapshot) error {
+	query := `
+		INSERT INTO loan_balance_snapshots (
+			loan_id, branch_id, snapshot_date, status, principal_remaining,
+			interest_remaining, accrued_interest, late_fee_remaining, balance, days_past_due
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (loan_id, snapshot_date) DO UPDATE SET
+			status = EXCLUDED.status,
+			principal_remaining = EXCLUDED.principal_remaining,
+			interest_remaining = EXCLUDED.interest_remaining,
+			accrued_interest = EXCLUDED.accrued_interest,
+			late_fee_remaining = EXCLUDED.late_fee_remaining,
+			balance = EXCLUDED.balance,
+			days_past_due = EXCLUDED.days_past_due,
+			created_at = NOW()
+		RETURNING id, created_at
+	`
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, s := range snapshots {
+		err := tx.QueryRowContext(ctx, query,
+			s.LoanID, s.BranchID, s.SnapshotDate, s.Status, s.PrincipalRemaining,
+			s.InterestRemaining, s.AccruedInterest, s.LateFeeRemaining, s.Balance, s.DaysPastDue,
+		).Scan(&s.ID, &s.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store snapshot of loan %d: %w", s.LoanID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListByDate lists the snapshots taken on a day, for one branch or every branch when branchID is 0
+func (r *LoanBalanceSnapshotRepository) ListByDate(ctx context.Context, branchID int64, date time.Time) ([]*domain.LoanBalanceSnapshot, error) {
+	query := `
+		SELECT s.id, s.loan_id, l.loan_number, s.branch_id, s.snapshot_date, s.status,
+			   s.principal_remaining, s.interest_remaining, s.accrued_interest,
+			   s.late_fee_remaining, s.balance, s.days_past_due, s.created_at
+		FROM loan_balance_snapshots s
+		JOIN loans l ON l.id = s.loan_id
+		WHERE s.snapshot_date = $1::date AND ($2 = 0 OR s.branch_id = $2)
+		ORDER BY s.loan_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, date, branchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan balance snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*domain.LoanBalanceSnapshot{}
+	for rows.Next() {
+		s := &domain.LoanBalanceSnapshot{}
+		if err := rows.Scan(
+			&s.ID, &s.LoanID, &s.LoanNumber, &s.BranchID, &s.SnapshotDate, &s.Status,
+			&s.PrincipalRemaining, &s.InterestRemaining, &s.AccruedInterest,
+			&s.LateFeeRemaining, &s.Balance, &s.DaysPastDue, &s.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan loan balance snapshot: %w", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, rows.Err()
+}
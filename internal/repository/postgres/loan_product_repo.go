@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// LoanProductRepository implements repository.LoanProductRepository
+type LoanProductRepository struct {
+	db *DB
+}
+
+// NewLoanProductRepository creates a new LoanProductRepository
+func NewLoanProductRepository(db *DB) *LoanProductRepository {
+	return &LoanProductRepository{db: db}
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const loanProductColumns = `
+	id, name, code, description, interest_rate, loan_term_days,
+	grace_period_days, late_fee_rate, payment_plan_type, number_of_installments,
+	requires_minimum_payment, is_active, created_at, updated_at`
+
+// GetByID retrieves a loan product by ID
+func (r *LoanProductRepository) GetByID(ctx context.Context, id int64) (*domain.LoanProduct, error) {
+	query := `SELECT ` + loanProductColumns + ` FROM loan_products WHERE id = $1`
+	return r.scanLoanProduct(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByCode retrieves a loan product by code
+func (r *LoanProductRepository) GetByCode(ctx context.Context, code string) (*domain.LoanProduct, error) {
+	query := `SELECT ` + loanProductColumns + ` FROM loan_products WHERE code = $1`
+	return r.scanLoanProduct(r.db.QueryRowContext(ctx, query, code))
+}
+
+// List retrieves loan products ordered by name
+func (r *LoanProductRepository) List(ctx context.Context, activeOnly bool) ([]*domain.LoanProduct, error) {
+	query := `SELECT ` + loanProductColumns + ` FROM loan_products`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY name ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*domain.LoanProduct{}
+	for rows.Next() {
+		product, err := r.scanLoanProductRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+// Create creates a new loan product
+func (r *LoanProductRepository) Create(ctx context.Context, product *domain.LoanProduct) error {
+	query := `
+		INSERT INTO loan_products (
+			name, code, description, interest_rate, loan_term_days,
+			grace_period_days, late_fee_rate, payment_plan_type, number_of_installments,
+			requires_minimum_payment, is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		product.Name, product.Code, NullStringPtr(product.Description),
+		product.InterestRate, product.LoanTermDays, product.GracePeriodDays,
+		product.LateFeeRate, product.PaymentPlanType, product.NumberOfInstallments,
+		product.RequiresMinimumPayment, product.IsActive,
+	).Scan(&product.ID, &product.CreatedAt, &product.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create loan product: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates an existing loan product
+func (r *LoanProductRepository) Update(ctx context.Context, product *domain.LoanProduct) error {
+	query := `
+		UPDATE loan_products SET
+			name = $2, code = $3, description = $4, interest_rate = $5,
+			loan_term_days = $6, grace_period_days = $7, late_fee_rate = $8,
+			payment_plan_type = $9, number_of_installments = $10,
+			requires_minimum_payment = $11, is_active = $12, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		product.ID, product.Name, product.Code, NullStringPtr(product.Description),
+		product.InterestRate, product.LoanTermDays, product.GracePeriodDays,
+		product.LateFeeRate, product.PaymentPlanType, product.NumberOfInstallments,
+		product.RequiresMinimumPayment, product.IsActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update loan product: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("loan product not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a loan product
+func (r *LoanProductRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM loan_products WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete loan product: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("loan product not found")
+	}
+
+	return nil
+}
+
+// Helper functions
+func (r *LoanProductRepository) scanLoanProduct(row *sql.Row) (*domain.LoanProduct, error) {
+	product, err := r.scanLoanProductRow(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("loan product not found")
+		}
+		return nil, fmt.Errorf("failed to get loan product: %w", err)
+	}
+	return product, nil
+}
+
+func (r *LoanProductRepository) scanLoanProductRow(row rowScanner) (*domain.LoanProduct, error) {
+	product := &domain.LoanProduct{}
+	var description sql.NullString
+
+	err := row.Scan(
+		&product.ID, &product.Name, &product.Code, &description,
+		&product.InterestRate, &product.LoanTermDays, &product.GracePeriodDays,
+		&product.LateFeeRate, &product.PaymentPlanType, &product.NumberOfInstallments,
+		&product.RequiresMinimumPayment, &product.IsActive,
+		&product.CreatedAt, &product.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Description = StringPtrVal(description)
+	return product, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 )
@@ -31,7 +32,10 @@ func (r *LoanRepository) GetByID(ctx context.Context, id int64) (*domain.Loan, e
 			   payment_plan_type, loan_term_days, requires_minimum_payment,
 			   minimum_payment_amount, next_payment_due_date, grace_period_days,
 			   number_of_installments, installment_amount,
-			   status, days_overdue, renewed_from_id, renewal_count, notes,
+			   status, days_overdue, renewed_from_id, renewal_count, notes, tags,
+			   contract_document_id, contract_url, contract_hash, disbursement_rounding,
+			   campaign_id, campaign_interest_discount, interest_method,
+			   authorization_required, authorized_by, authorized_at,
 			   created_by, updated_by, created_at, updated_at, deleted_at
 		FROM loans
 		WHERE id = $1 AND deleted_at IS NULL
@@ -50,7 +54,10 @@ func (r *LoanRepository) GetByNumber(ctx context.Context, loanNumber string) (*d
 			   payment_plan_type, loan_term_days, requires_minimum_payment,
 			   minimum_payment_amount, next_payment_due_date, grace_period_days,
 			   number_of_installments, installment_amount,
-			   status, days_overdue, renewed_from_id, renewal_count, notes,
+			   status, days_overdue, renewed_from_id, renewal_count, notes, tags,
+			   contract_document_id, contract_url, contract_hash, disbursement_rounding,
+			   campaign_id, campaign_interest_discount, interest_method,
+			   authorization_required, authorized_by, authorized_at,
 			   created_by, updated_by, created_at, updated_at, deleted_at
 		FROM loans
 		WHERE loan_number = $1 AND deleted_at IS NULL
@@ -59,6 +66,27 @@ func (r *LoanRepository) GetByNumber(ctx context.Context, loanNumber string) (*d
 	return r.scanLoan(r.db.QueryRowContext(ctx, query, loanNumber))
 }
 
+// GetActiveByItemID retrieves the active, overdue or pending loan backed by an item
+func (r *LoanRepository) GetActiveByItemID(ctx context.Context, itemID int64) (*domain.Loan, error) {
+	query := `
+		SELECT id FROM loans
+		WHERE item_id = $1
+		  AND status IN ('active', 'overdue', 'pending_authorization')
+		  AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	var id int64
+	if err := r.db.QueryRowContext(ctx, query, itemID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active loan: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
 // List retrieves loans with pagination and filters
 func (r *LoanRepository) List(ctx context.Context, params repository.LoanListParams) (*repository.PaginatedResult[domain.Loan], error) {
 	if params.Page <= 0 {
@@ -113,6 +141,23 @@ func (r *LoanRepository) List(ctx context.Context, params repository.LoanListPar
 		args = append(args, *params.DueAfter)
 	}
 
+	if params.OpenOn != nil {
+		argCount++
+		baseQuery += fmt.Sprintf(` AND l.start_date <= $%[1]d::date
+			AND l.status <> 'pending_authorization'
+			AND (l.paid_date IS NULL OR l.paid_date > $%[1]d::date)
+			AND (l.confiscated_date IS NULL OR l.confiscated_date > $%[1]d::date)
+			AND NOT EXISTS (SELECT 1 FROM loans rl WHERE rl.renewed_from_id = l.id AND rl.deleted_at IS NULL AND rl.start_date <= $%[1]d::date)
+			AND NOT EXISTS (SELECT 1 FROM loan_write_offs w WHERE w.loan_id = l.id AND w.written_off_at::date <= $%[1]d::date)`, argCount)
+		args = append(args, *params.OpenOn)
+	}
+
+	if len(params.Tags) > 0 {
+		argCount++
+		baseQuery += fmt.Sprintf(" AND l.tags @> $%d", argCount)
+		args = append(args, pq.Array(params.Tags))
+	}
+
 	if params.Search != "" {
 		argCount++
 		baseQuery += fmt.Sprintf(" AND (l.loan_number ILIKE $%d OR c.first_name ILIKE $%d OR c.last_name ILIKE $%d OR c.identity_number ILIKE $%d OR i.name ILIKE $%d)", argCount, argCount, argCount, argCount, argCount)
@@ -145,7 +190,7 @@ func (r *LoanRepository) List(ctx context.Context, params repository.LoanListPar
 			   l.payment_plan_type, l.loan_term_days, l.requires_minimum_payment,
 			   l.minimum_payment_amount, l.next_payment_due_date, l.grace_period_days,
 			   l.number_of_installments, l.installment_amount,
-			   l.status, l.days_overdue, l.renewed_from_id, l.renewal_count, l.notes,
+			   l.status, l.days_overdue, l.renewed_from_id, l.renewal_count, l.notes, l.tags,
 			   l.created_by, l.updated_by, l.created_at, l.updated_at, l.deleted_at,
 			   c.id, c.first_name, c.last_name, c.identity_number,
 			   i.id, i.name, i.sku
@@ -185,6 +230,11 @@ func (r *LoanRepository) List(ctx context.Context, params repository.LoanListPar
 
 // Create creates a new loan
 func (r *LoanRepository) Create(ctx context.Context, loan *domain.Loan) error {
+	return insertLoan(ctx, r.db, loan)
+}
+
+// insertLoan inserts a loan, including the renewal it comes from
+func insertLoan(ctx context.Context, q Querier, loan *domain.Loan) error {
 	query := `
 		INSERT INTO loans (
 			loan_number, branch_id, customer_id, item_id,
@@ -194,12 +244,13 @@ func (r *LoanRepository) Create(ctx context.Context, loan *domain.Loan) error {
 			payment_plan_type, loan_term_days, requires_minimum_payment,
 			minimum_payment_amount, next_payment_due_date, grace_period_days,
 			number_of_installments, installment_amount,
-			status, notes, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+			status, notes, created_by,
+			late_fee_amount, late_fee_remaining, renewed_from_id, renewal_count, interest_method
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		loan.LoanNumber, loan.BranchID, loan.CustomerID, loan.ItemID,
 		loan.LoanAmount, loan.InterestRate, loan.InterestAmount,
 		loan.PrincipalRemaining, loan.InterestRemaining, loan.TotalAmount, loan.LateFeeRate,
@@ -208,10 +259,12 @@ func (r *LoanRepository) Create(ctx context.Context, loan *domain.Loan) error {
 		NullFloat64(loan.MinimumPaymentAmount), NullTime(loan.NextPaymentDueDate), loan.GracePeriodDays,
 		loan.NumberOfInstallments, NullFloat64(loan.InstallmentAmount),
 		loan.Status, NullString(loan.Notes), loan.CreatedBy,
+		loan.LateFeeAmount, loan.LateFeeRemaining, NullInt64(loan.RenewedFromID), loan.RenewalCount,
+		interestMethodOrDefault(loan.InterestMethod),
 	).Scan(&loan.ID, &loan.CreatedAt, &loan.UpdatedAt)
 
 	if err != nil {
-		return fmt.Errorf("failed to create loan: %w", err)
+		return loanCreateError(err)
 	}
 
 	return nil
@@ -219,6 +272,11 @@ func (r *LoanRepository) Create(ctx context.Context, loan *domain.Loan) error {
 
 // Update updates an existing loan
 func (r *LoanRepository) Update(ctx context.Context, loan *domain.Loan) error {
+	return updateLoan(ctx, r.db, loan)
+}
+
+// updateLoan updates the balances, dates and status of a loan
+func updateLoan(ctx context.Context, q Querier, loan *domain.Loan) error {
 	query := `
 		UPDATE loans SET
 			interest_amount = $2, principal_remaining = $3, interest_remaining = $4,
@@ -230,7 +288,7 @@ func (r *LoanRepository) Update(ctx context.Context, loan *domain.Loan) error {
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := q.ExecContext(ctx, query,
 		loan.ID, loan.InterestAmount, loan.PrincipalRemaining, loan.InterestRemaining,
 		loan.TotalAmount, loan.AmountPaid, loan.LateFeeAmount, loan.LateFeeRemaining,
 		loan.DueDate, NullTime(loan.PaidDate), NullTime(loan.ConfiscatedDate),
@@ -250,26 +308,113 @@ func (r *LoanRepository) Update(ctx context.Context, loan *domain.Loan) error {
 	return nil
 }
 
-// GenerateNumber generates a unique loan number
-func (r *LoanRepository) GenerateNumber(ctx context.Context) (string, error) {
-	query := `SELECT generate_loan_number()`
-	var loanNumber string
-	err := r.db.QueryRowContext(ctx, query).Scan(&loanNumber)
+// Renew closes a loan as renewed, records the interest paid with the renewal
+// and creates the loan that succeeds it in one transaction, so a failure never
+// leaves a payment without its renewal or a renewed loan without a successor.
+// The payment's cash movement, when given, is saved referencing the payment.
+func (r *LoanRepository) Renew(ctx context.Context, loan *domain.Loan, renewed *domain.Loan, payment *domain.Payment, movement *domain.CashMovement) error {
+	tx, err := r.db.BeginTx(ctx)
 	if err != nil {
-		// Fallback if function doesn't exist
-		yearStr := time.Now().Format("2006")
-		var seqNum int
-		fallbackQuery := `
-			SELECT COALESCE(MAX(CAST(SUBSTRING(loan_number FROM 'LN-\d{4}-(\d+)') AS INTEGER)), 0) + 1
-			FROM loans WHERE loan_number LIKE 'LN-' || $1 || '-%'
-		`
-		r.db.QueryRowContext(ctx, fallbackQuery, yearStr).Scan(&seqNum)
-		if seqNum == 0 {
-			seqNum = 1
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if payment != nil {
+		if err := insertPayment(ctx, tx, payment); err != nil {
+			return err
+		}
+		if movement != nil {
+			movement.ReferenceID = &payment.ID
+			if err := insertCashMovement(ctx, tx, movement); err != nil {
+				return err
+			}
 		}
-		loanNumber = fmt.Sprintf("LN-%s-%06d", yearStr, seqNum)
 	}
-	return loanNumber, nil
+	if err := updateLoan(ctx, tx, loan); err != nil {
+		return err
+	}
+	if err := insertLoan(ctx, tx, renewed); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetContract references the stored contract document on a loan
+func (r *LoanRepository) SetContract(ctx context.Context, id int64, documentID int64, url, hash string) error {
+	query := `
+		UPDATE loans SET contract_document_id = $2, contract_url = $3, contract_hash = $4, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, documentID, url, hash)
+	if err != nil {
+		return fmt.Errorf("failed to set loan contract: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("loan not found")
+	}
+
+	return nil
+}
+
+// SetTags replaces the tags of a loan
+func (r *LoanRepository) SetTags(ctx context.Context, id int64, tags []string) error {
+	query := `UPDATE loans SET tags = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, pq.Array(tags))
+	if err != nil {
+		return fmt.Errorf("failed to set loan tags: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("loan not found")
+	}
+
+	return nil
+}
+
+// AuthorizeDisbursement records the co-signer of a loan's cash disbursement
+// and activates the loan
+func (r *LoanRepository) AuthorizeDisbursement(ctx context.Context, id int64, authorizedBy int64) error {
+	query := `
+		UPDATE loans SET status = 'active', authorized_by = $2, authorized_at = NOW(), updated_by = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending_authorization' AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, authorizedBy)
+	if err != nil {
+		return fmt.Errorf("failed to authorize loan disbursement: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("loan not found or not awaiting authorization")
+	}
+
+	return nil
+}
+
+// RevokeAuthorization puts an authorized loan back to waiting for its co-signer
+func (r *LoanRepository) RevokeAuthorization(ctx context.Context, id int64) error {
+	query := `
+		UPDATE loans SET status = 'pending_authorization', authorized_by = NULL, authorized_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'active' AND authorization_required AND deleted_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to revoke loan authorization: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateNumber reserves the next loan number for the given reset cadence
+func (r *LoanRepository) GenerateNumber(ctx context.Context, cadence domain.SequenceResetCadence) (string, error) {
+	return nextSequenceNumber(ctx, r.db, "loan", "LN", cadence, time.Now())
 }
 
 // GetOverdueLoans retrieves overdue loans for a branch
@@ -282,7 +427,7 @@ func (r *LoanRepository) GetOverdueLoans(ctx context.Context, branchID int64) ([
 			   payment_plan_type, loan_term_days, requires_minimum_payment,
 			   minimum_payment_amount, next_payment_due_date, grace_period_days,
 			   number_of_installments, installment_amount,
-			   status, days_overdue, renewed_from_id, renewal_count, notes,
+			   status, days_overdue, renewed_from_id, renewal_count, notes, tags,
 			   created_by, updated_by, created_at, updated_at, deleted_at
 		FROM loans
 		WHERE (branch_id = $1 OR $1 = 0)
@@ -332,6 +477,15 @@ func (r *LoanRepository) BeginTx(ctx context.Context) (repository.Transaction, e
 	return r.db.BeginTx(ctx)
 }
 
+// interestMethodOrDefault is the interest method saved for a loan, the default
+// one for loans built without a method
+func interestMethodOrDefault(method domain.InterestMethod) domain.InterestMethod {
+	if method == "" {
+		return domain.DefaultInterestMethod
+	}
+	return method
+}
+
 // CreateTx creates a loan within a transaction
 func (r *LoanRepository) CreateTx(ctx context.Context, tx repository.Transaction, loan *domain.Loan) error {
 	pgTx := tx.(*Tx)
@@ -345,8 +499,9 @@ func (r *LoanRepository) CreateTx(ctx context.Context, tx repository.Transaction
 			payment_plan_type, loan_term_days, requires_minimum_payment,
 			minimum_payment_amount, next_payment_due_date, grace_period_days,
 			number_of_installments, installment_amount,
-			status, notes, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+			status, notes, created_by, disbursement_rounding,
+			campaign_id, campaign_interest_discount, authorization_required, interest_method
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -358,10 +513,16 @@ func (r *LoanRepository) CreateTx(ctx context.Context, tx repository.Transaction
 		loan.PaymentPlanType, loan.LoanTermDays, loan.RequiresMinimumPayment,
 		NullFloat64(loan.MinimumPaymentAmount), NullTime(loan.NextPaymentDueDate), loan.GracePeriodDays,
 		loan.NumberOfInstallments, NullFloat64(loan.InstallmentAmount),
-		loan.Status, NullString(loan.Notes), loan.CreatedBy,
+		loan.Status, NullString(loan.Notes), loan.CreatedBy, loan.DisbursementRounding,
+		NullInt64(loan.CampaignID), loan.CampaignInterestDiscount, loan.AuthorizationRequired,
+		interestMethodOrDefault(loan.InterestMethod),
 	).Scan(&loan.ID, &loan.CreatedAt, &loan.UpdatedAt)
 
-	return err
+	if err != nil {
+		return loanCreateError(err)
+	}
+
+	return nil
 }
 
 // CreateInstallments creates installments for a loan
@@ -459,13 +620,46 @@ func (r *LoanRepository) UpdateInstallment(ctx context.Context, installment *dom
 	return err
 }
 
+// ReplaceInstallments replaces a loan's installment schedule and puts the loan
+// on an installment payment plan
+func (r *LoanRepository) ReplaceInstallments(ctx context.Context, loanID int64, installments []*domain.LoanInstallment) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM loan_installments WHERE loan_id = $1`, loanID); err != nil {
+		return fmt.Errorf("failed to delete installments: %w", err)
+	}
+	if err := r.CreateInstallmentsTx(ctx, tx, installments); err != nil {
+		return err
+	}
+
+	var installmentAmount *float64
+	if len(installments) > 0 {
+		installmentAmount = &installments[0].TotalAmount
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE loans SET
+			payment_plan_type = $2, number_of_installments = $3, installment_amount = $4, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, loanID, domain.PaymentPlanInstallments, len(installments), NullFloat64(installmentAmount))
+	if err != nil {
+		return fmt.Errorf("failed to update loan payment plan: %w", err)
+	}
+
+	return tx.Commit()
+}
+
 // Helper functions
 func (r *LoanRepository) scanLoan(row *sql.Row) (*domain.Loan, error) {
 	loan := &domain.Loan{}
-	var paidDate, confiscatedDate, nextPaymentDueDate, deletedAt sql.NullTime
+	var paidDate, confiscatedDate, nextPaymentDueDate, authorizedAt, deletedAt sql.NullTime
 	var minimumPaymentAmount, installmentAmount sql.NullFloat64
-	var numberOfInstallments, renewedFromID sql.NullInt64
-	var notes sql.NullString
+	var numberOfInstallments, renewedFromID, contractDocumentID, campaignID, authorizedBy sql.NullInt64
+	var notes, contractURL, contractHash sql.NullString
+	var tags pq.StringArray
 	var createdBy, updatedBy sql.NullInt64
 
 	err := row.Scan(
@@ -477,7 +671,10 @@ func (r *LoanRepository) scanLoan(row *sql.Row) (*domain.Loan, error) {
 		&loan.PaymentPlanType, &loan.LoanTermDays, &loan.RequiresMinimumPayment,
 		&minimumPaymentAmount, &nextPaymentDueDate, &loan.GracePeriodDays,
 		&numberOfInstallments, &installmentAmount,
-		&loan.Status, &loan.DaysOverdue, &renewedFromID, &loan.RenewalCount, &notes,
+		&loan.Status, &loan.DaysOverdue, &renewedFromID, &loan.RenewalCount, &notes, &tags,
+		&contractDocumentID, &contractURL, &contractHash, &loan.DisbursementRounding,
+		&campaignID, &loan.CampaignInterestDiscount, &loan.InterestMethod,
+		&loan.AuthorizationRequired, &authorizedBy, &authorizedAt,
 		&createdBy, &updatedBy, &loan.CreatedAt, &loan.UpdatedAt, &deletedAt,
 	)
 
@@ -496,6 +693,13 @@ func (r *LoanRepository) scanLoan(row *sql.Row) (*domain.Loan, error) {
 	loan.NumberOfInstallments = IntPtr(numberOfInstallments)
 	loan.RenewedFromID = Int64Ptr(renewedFromID)
 	loan.Notes = StringPtr(notes)
+	loan.Tags = []string(tags)
+	loan.ContractDocumentID = Int64Ptr(contractDocumentID)
+	loan.ContractURL = StringPtr(contractURL)
+	loan.ContractHash = StringPtr(contractHash)
+	loan.CampaignID = Int64Ptr(campaignID)
+	loan.AuthorizedBy = Int64Ptr(authorizedBy)
+	loan.AuthorizedAt = TimePtr(authorizedAt)
 	if createdBy.Valid {
 		loan.CreatedBy = createdBy.Int64
 	}
@@ -511,6 +715,7 @@ func (r *LoanRepository) scanLoanRow(rows *sql.Rows) (*domain.Loan, error) {
 	var minimumPaymentAmount, installmentAmount sql.NullFloat64
 	var numberOfInstallments, renewedFromID sql.NullInt64
 	var notes sql.NullString
+	var tags pq.StringArray
 	var createdBy, updatedBy sql.NullInt64
 
 	err := rows.Scan(
@@ -522,7 +727,7 @@ func (r *LoanRepository) scanLoanRow(rows *sql.Rows) (*domain.Loan, error) {
 		&loan.PaymentPlanType, &loan.LoanTermDays, &loan.RequiresMinimumPayment,
 		&minimumPaymentAmount, &nextPaymentDueDate, &loan.GracePeriodDays,
 		&numberOfInstallments, &installmentAmount,
-		&loan.Status, &loan.DaysOverdue, &renewedFromID, &loan.RenewalCount, &notes,
+		&loan.Status, &loan.DaysOverdue, &renewedFromID, &loan.RenewalCount, &notes, &tags,
 		&createdBy, &updatedBy, &loan.CreatedAt, &loan.UpdatedAt, &deletedAt,
 	)
 
@@ -538,6 +743,7 @@ func (r *LoanRepository) scanLoanRow(rows *sql.Rows) (*domain.Loan, error) {
 	loan.NumberOfInstallments = IntPtr(numberOfInstallments)
 	loan.RenewedFromID = Int64Ptr(renewedFromID)
 	loan.Notes = StringPtr(notes)
+	loan.Tags = []string(tags)
 	if createdBy.Valid {
 		loan.CreatedBy = createdBy.Int64
 	}
@@ -553,6 +759,7 @@ var paidDate, confiscatedDate, nextPaymentDueDate, deletedAt sql.NullTime
 var minimumPaymentAmount, installmentAmount sql.NullFloat64
 var numberOfInstallments, renewedFromID sql.NullInt64
 var notes sql.NullString
+var tags pq.StringArray
 var createdBy, updatedBy sql.NullInt64
 
 // Customer fields
@@ -572,7 +779,7 @@ err := rows.Scan(
 &loan.PaymentPlanType, &loan.LoanTermDays, &loan.RequiresMinimumPayment,
 &minimumPaymentAmount, &nextPaymentDueDate, &loan.GracePeriodDays,
 &numberOfInstallments, &installmentAmount,
-&loan.Status, &loan.DaysOverdue, &renewedFromID, &loan.RenewalCount, &notes,
+&loan.Status, &loan.DaysOverdue, &renewedFromID, &loan.RenewalCount, &notes, &tags,
 &createdBy, &updatedBy, &loan.CreatedAt, &loan.UpdatedAt, &deletedAt,
 // Customer
 &custID, &custFirstName, &custLastName, &custIdentityNumber,
@@ -592,6 +799,7 @@ loan.InstallmentAmount = Float64Ptr(installmentAmount)
 loan.NumberOfInstallments = IntPtr(numberOfInstallments)
 loan.RenewedFromID = Int64Ptr(renewedFromID)
 loan.Notes = StringPtr(notes)
+loan.Tags = []string(tags)
 if createdBy.Valid {
 loan.CreatedBy = createdBy.Int64
 }
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// LoanWriteOffRepository implements repository.LoanWriteOffRepository
+type LoanWriteOffRepository struct {
+	db *DB
+}
+
+// NewLoanWriteOffRepository creates a new LoanWriteOffRepository
+func NewLoanWriteOffRepository(db *DB) *LoanWriteOffRepository {
+	return &LoanWriteOffRepository{db: db}
+}
+
+// Create records a loan write-off, closing the loan, confiscating its item and
+// posting the loss entry in the same transaction. The loan only moves out of
+// the defaulted or overdue status once, so a retry or a concurrent write-off
+// gets ErrLoanAlreadyWrittenOff instead of posting the loss twice.
+func (r *LoanWriteOffRepository) Create(ctx context.Context, writeOff *domain.LoanWriteOff, entry *domain.AccountingEntry) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var itemID int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE loans SET status = 'written_off', updated_by = $2, updated_at = NOW()
+		WHERE id = $1 AND status IN ('defaulted', 'overdue') AND deleted_at IS NULL
+		RETURNING item_id
+	`, writeOff.LoanID, writeOff.WrittenOffBy).Scan(&itemID)
+	if err == sql.ErrNoRows {
+		return repository.ErrLoanAlreadyWrittenOff
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write off loan: %w", err)
+	}
+
+	// The shop keeps the collateral of a loan it will not collect
+	_, err = tx.ExecContext(ctx, `
+		UPDATE items SET status = 'confiscated', updated_at = NOW()
+		WHERE id = $1 AND status IN ('pawned', 'collateral')
+	`, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to confiscate written-off item: %w", err)
+	}
+
+	if entry != nil {
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+		if err := insertAccountingEntry(ctx, tx, entry); err != nil {
+			return fmt.Errorf("failed to create write-off entry: %w", err)
+		}
+		writeOff.EntryID = &entry.ID
+	}
+
+	query := `
+		INSERT INTO loan_write_offs (
+			loan_id, branch_id, principal, interest, late_fees, amount,
+			reason, entry_id, written_off_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, written_off_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		writeOff.LoanID, writeOff.BranchID, writeOff.Principal, writeOff.Interest, writeOff.LateFees, writeOff.Amount,
+		writeOff.Reason, NullInt64(writeOff.EntryID), writeOff.WrittenOffBy,
+	).Scan(&writeOff.ID, &writeOff.WrittenOffAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create loan write-off: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// List retrieves write-offs, newest first, with their loan and customer
+func (r *LoanWriteOffRepository) List(ctx context.Context, params repository.LoanWriteOffListParams) ([]*domain.LoanWriteOff, error) {
+	query := `
+		SELECT w.id, w.loan_id, w.branch_id, w.principal, w.interest, w.late_fees, w.amount,
+			   w.reason, w.entry_id, w.written_off_by, w.written_off_at,
+			   l.loan_number, l.customer_id, l.loan_amount,
+			   c.first_name, c.last_name, c.identity_number
+		FROM loan_write_offs w
+		JOIN loans l ON l.id = w.loan_id
+		LEFT JOIN customers c ON c.id = l.customer_id
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 0
+
+	if params.BranchID > 0 {
+		argCount++
+		query += fmt.Sprintf(" AND w.branch_id = $%d", argCount)
+		args = append(args, params.BranchID)
+	}
+
+	if params.DateFrom != nil {
+		argCount++
+		query += fmt.Sprintf(" AND w.written_off_at::date >= $%d", argCount)
+		args = append(args, *params.DateFrom)
+	}
+
+	if params.DateTo != nil {
+		argCount++
+		query += fmt.Sprintf(" AND w.written_off_at::date <= $%d", argCount)
+		args = append(args, *params.DateTo)
+	}
+
+	query += " ORDER BY w.written_off_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan write-offs: %w", err)
+	}
+	defer rows.Close()
+
+	writeOffs := []*domain.LoanWriteOff{}
+	for rows.Next() {
+		writeOff := &domain.LoanWriteOff{}
+		loan := &domain.Loan{}
+		var entryID sql.NullInt64
+		var firstName, lastName, identityNumber sql.NullString
+
+		err := rows.Scan(
+			&writeOff.ID, &writeOff.LoanID, &writeOff.BranchID, &writeOff.Principal, &writeOff.Interest,
+			&writeOff.LateFees, &writeOff.Amount, &writeOff.Reason, &entryID, &writeOff.WrittenOffBy, &writeOff.WrittenOffAt,
+			&loan.LoanNumber, &loan.CustomerID, &loan.LoanAmount,
+			&firstName, &lastName, &identityNumber,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan loan write-off: %w", err)
+		}
+
+		writeOff.EntryID = Int64Ptr(entryID)
+		loan.ID = writeOff.LoanID
+		loan.BranchID = writeOff.BranchID
+		loan.Status = domain.LoanStatusWrittenOff
+		if firstName.Valid {
+			loan.Customer = &domain.Customer{
+				ID:             loan.CustomerID,
+				FirstName:      firstName.String,
+				LastName:       lastName.String,
+				IdentityNumber: identityNumber.String,
+			}
+		}
+		writeOff.Loan = loan
+		writeOffs = append(writeOffs, writeOff)
+	}
+
+	return writeOffs, rows.Err()
+}
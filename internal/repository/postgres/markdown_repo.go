@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// MarkdownRepository implements repository.MarkdownRepository
+type MarkdownRepository struct {
+	db *DB
+}
+
+// NewMarkdownRepository creates a new MarkdownRepository
+func NewMarkdownRepository(db *DB) *MarkdownRepository {
+	return &MarkdownRepository{db: db}
+}
+
+// ListCandidates lists the items currently for sale. An item's listing date is
+// the last time its history shows it moving to for_sale, other than through a
+// markdown, falling back to when it was registered.
+func (r *MarkdownRepository) ListCandidates(ctx context.Context) ([]*domain.MarkdownCandidate, error) {
+	query := `
+		SELECT i.id, i.branch_id, i.sku, i.name, i.appraised_value, i.sale_price, listed.listed_at,
+			   (SELECT COUNT(*) FROM item_history h
+				 WHERE h.item_id = i.id AND h.action = $1 AND h.created_at >= listed.listed_at)
+		FROM items i
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(MAX(h.created_at), i.created_at) AS listed_at
+			FROM item_history h
+			WHERE h.item_id = i.id AND h.new_status = 'for_sale' AND h.action <> $1
+		) listed
+		WHERE i.status = 'for_sale' AND i.sale_price IS NOT NULL AND i.deleted_at IS NULL
+		ORDER BY i.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.ItemHistoryActionMarkedDown)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list markdown candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []*domain.MarkdownCandidate{}
+	for rows.Next() {
+		c := &domain.MarkdownCandidate{}
+		if err := rows.Scan(&c.ItemID, &c.BranchID, &c.SKU, &c.Name, &c.AppraisedValue, &c.SalePrice,
+			&c.ListedAt, &c.StepsApplied); err != nil {
+			return nil, fmt.Errorf("failed to scan markdown candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
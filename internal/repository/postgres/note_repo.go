@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// NoteRepository implements repository.NoteRepository
+type NoteRepository struct {
+	db *DB
+}
+
+// NewNoteRepository creates a new NoteRepository
+func NewNoteRepository(db *DB) *NoteRepository {
+	return &NoteRepository{db: db}
+}
+
+const noteColumns = `
+	n.id, n.entity_type, n.entity_id, n.parent_id, n.body, n.mentioned_user_ids,
+	n.created_by, n.created_at, COALESCE(u.first_name || ' ' || u.last_name, '')`
+
+// Create creates a new note
+func (r *NoteRepository) Create(ctx context.Context, note *domain.Note) error {
+	if note.MentionedUserIDs == nil {
+		note.MentionedUserIDs = []int64{}
+	}
+	mentions, err := json.Marshal(note.MentionedUserIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode note mentions: %w", err)
+	}
+
+	query := `
+		INSERT INTO notes (entity_type, entity_id, parent_id, body, mentioned_user_ids, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		note.EntityType, note.EntityID, NullInt64(note.ParentID),
+		note.Body, string(mentions), note.CreatedBy,
+	).Scan(&note.ID, &note.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create note: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a note by ID
+func (r *NoteRepository) GetByID(ctx context.Context, id int64) (*domain.Note, error) {
+	query := `SELECT ` + noteColumns + `
+		FROM notes n
+		LEFT JOIN users u ON u.id = n.created_by
+		WHERE n.id = $1 AND n.deleted_at IS NULL`
+
+	note, err := r.scanNoteRow(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("note not found")
+		}
+		return nil, fmt.Errorf("failed to get note: %w", err)
+	}
+	return note, nil
+}
+
+// ListByEntity retrieves the notes of an entity, oldest first
+func (r *NoteRepository) ListByEntity(ctx context.Context, entityType domain.NoteEntityType, entityID int64) ([]*domain.Note, error) {
+	query := `SELECT ` + noteColumns + `
+		FROM notes n
+		LEFT JOIN users u ON u.id = n.created_by
+		WHERE n.entity_type = $1 AND n.entity_id = $2 AND n.deleted_at IS NULL
+		ORDER BY n.created_at ASC, n.id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []*domain.Note{}
+	for rows.Next() {
+		note, err := r.scanNoteRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+// Delete soft deletes a note along with its replies
+func (r *NoteRepository) Delete(ctx context.Context, id int64) error {
+	query := `UPDATE notes SET deleted_at = NOW() WHERE (id = $1 OR parent_id = $1) AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("note not found")
+	}
+
+	return nil
+}
+
+func (r *NoteRepository) scanNoteRow(row rowScanner) (*domain.Note, error) {
+	note := &domain.Note{}
+	var parentID sql.NullInt64
+	var mentions []byte
+
+	err := row.Scan(
+		&note.ID, &note.EntityType, &note.EntityID, &parentID, &note.Body, &mentions,
+		&note.CreatedBy, &note.CreatedAt, &note.AuthorName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	note.ParentID = Int64Ptr(parentID)
+	if err := json.Unmarshal(mentions, &note.MentionedUserIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode note mentions: %w", err)
+	}
+	return note, nil
+}
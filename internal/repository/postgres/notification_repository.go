@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 )
@@ -238,7 +239,7 @@ func (r *notificationRepository) GetByID(ctx context.Context, id int64) (*domain
 		SELECT id, customer_id, branch_id, notification_type, channel,
 			   subject, body, reference_type, reference_id,
 			   status, scheduled_for, sent_at, delivered_at, failed_at,
-			   failure_reason, retry_count, created_at, updated_at
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
 		FROM notifications
 		WHERE id = $1`
 
@@ -260,6 +261,7 @@ func (r *notificationRepository) GetByID(ctx context.Context, id int64) (*domain
 		&notification.FailedAt,
 		&notification.FailureReason,
 		&notification.RetryCount,
+		&notification.ExternalMessageID,
 		&notification.CreatedAt,
 		&notification.UpdatedAt,
 	)
@@ -344,6 +346,16 @@ func (r *notificationRepository) List(ctx context.Context, filter repository.Not
 		args = append(args, *filter.Status)
 		argPos++
 	}
+	if filter.ReferenceType != nil {
+		conditions = append(conditions, fmt.Sprintf("reference_type = $%d", argPos))
+		args = append(args, *filter.ReferenceType)
+		argPos++
+	}
+	if filter.ReferenceID != nil {
+		conditions = append(conditions, fmt.Sprintf("reference_id = $%d", argPos))
+		args = append(args, *filter.ReferenceID)
+		argPos++
+	}
 	if filter.DateFrom != nil {
 		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argPos))
 		args = append(args, *filter.DateFrom)
@@ -372,7 +384,7 @@ func (r *notificationRepository) List(ctx context.Context, filter repository.Not
 		SELECT id, customer_id, branch_id, notification_type, channel,
 			   subject, body, reference_type, reference_id,
 			   status, scheduled_for, sent_at, delivered_at, failed_at,
-			   failure_reason, retry_count, created_at, updated_at
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
 		FROM notifications
 		%s
 		ORDER BY created_at DESC
@@ -413,6 +425,7 @@ func (r *notificationRepository) List(ctx context.Context, filter repository.Not
 			&notification.FailedAt,
 			&notification.FailureReason,
 			&notification.RetryCount,
+			&notification.ExternalMessageID,
 			&notification.CreatedAt,
 			&notification.UpdatedAt,
 		); err != nil {
@@ -434,7 +447,7 @@ func (r *notificationRepository) ListPending(ctx context.Context, limit int) ([]
 		SELECT id, customer_id, branch_id, notification_type, channel,
 			   subject, body, reference_type, reference_id,
 			   status, scheduled_for, sent_at, delivered_at, failed_at,
-			   failure_reason, retry_count, created_at, updated_at
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
 		FROM notifications
 		WHERE status = 'pending' AND (scheduled_for IS NULL OR scheduled_for <= NOW())
 		ORDER BY created_at ASC
@@ -449,12 +462,33 @@ func (r *notificationRepository) ListPending(ctx context.Context, limit int) ([]
 	return r.scanNotifications(rows)
 }
 
+func (r *notificationRepository) ListPendingExcept(ctx context.Context, limit int, channels []string) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, customer_id, branch_id, notification_type, channel,
+			   subject, body, reference_type, reference_id,
+			   status, scheduled_for, sent_at, delivered_at, failed_at,
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
+		FROM notifications
+		WHERE status = 'pending' AND (scheduled_for IS NULL OR scheduled_for <= NOW())
+		  AND NOT (channel = ANY($2))
+		ORDER BY created_at ASC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, pq.Array(channels))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanNotifications(rows)
+}
+
 func (r *notificationRepository) ListScheduled(ctx context.Context, before time.Time, limit int) ([]*domain.Notification, error) {
 	query := `
 		SELECT id, customer_id, branch_id, notification_type, channel,
 			   subject, body, reference_type, reference_id,
 			   status, scheduled_for, sent_at, delivered_at, failed_at,
-			   failure_reason, retry_count, created_at, updated_at
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
 		FROM notifications
 		WHERE status = 'pending' AND scheduled_for IS NOT NULL AND scheduled_for <= $1
 		ORDER BY scheduled_for ASC
@@ -469,18 +503,19 @@ func (r *notificationRepository) ListScheduled(ctx context.Context, before time.
 	return r.scanNotifications(rows)
 }
 
-func (r *notificationRepository) ListFailed(ctx context.Context, maxRetries int, limit int) ([]*domain.Notification, error) {
+func (r *notificationRepository) ListFailed(ctx context.Context, maxRetries int, backoffBase time.Duration, limit int) ([]*domain.Notification, error) {
 	query := `
 		SELECT id, customer_id, branch_id, notification_type, channel,
 			   subject, body, reference_type, reference_id,
 			   status, scheduled_for, sent_at, delivered_at, failed_at,
-			   failure_reason, retry_count, created_at, updated_at
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
 		FROM notifications
 		WHERE status = 'failed' AND retry_count < $1
+		  AND COALESCE(failed_at, updated_at) + make_interval(secs => $2 * power(2, retry_count)) <= NOW()
 		ORDER BY failed_at ASC
-		LIMIT $2`
+		LIMIT $3`
 
-	rows, err := r.db.QueryContext(ctx, query, maxRetries, limit)
+	rows, err := r.db.QueryContext(ctx, query, maxRetries, backoffBase.Seconds(), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -510,6 +545,7 @@ func (r *notificationRepository) scanNotifications(rows *sql.Rows) ([]*domain.No
 			&notification.FailedAt,
 			&notification.FailureReason,
 			&notification.RetryCount,
+			&notification.ExternalMessageID,
 			&notification.CreatedAt,
 			&notification.UpdatedAt,
 		); err != nil {
@@ -532,6 +568,13 @@ func (r *notificationRepository) MarkAsSent(ctx context.Context, id int64) error
 	return err
 }
 
+func (r *notificationRepository) RedactBody(ctx context.Context, id int64, body string) error {
+	query := `UPDATE notifications SET body = $2, updated_at = NOW() WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, body)
+	return err
+}
+
 func (r *notificationRepository) MarkAsDelivered(ctx context.Context, id int64) error {
 	query := `
 		UPDATE notifications SET
@@ -557,18 +600,75 @@ func (r *notificationRepository) MarkAsFailed(ctx context.Context, id int64, rea
 	return err
 }
 
-func (r *notificationRepository) IncrementRetry(ctx context.Context, id int64) error {
+func (r *notificationRepository) ApplyDeliveryReceipt(ctx context.Context, id int64, status, reason string) (bool, error) {
+	query := `
+		UPDATE notifications SET
+			status = $2,
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END,
+			failed_at = CASE WHEN $2 = 'failed' THEN NOW() ELSE failed_at END,
+			failure_reason = CASE WHEN $2 = 'failed' THEN $3 ELSE failure_reason END,
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'sent'`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, reason)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *notificationRepository) IncrementRetry(ctx context.Context, id int64, scheduledFor time.Time) error {
 	query := `
 		UPDATE notifications SET
 			retry_count = retry_count + 1,
 			status = 'pending',
+			scheduled_for = $2,
 			updated_at = NOW()
 		WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.db.ExecContext(ctx, query, id, scheduledFor)
+	return err
+}
+
+func (r *notificationRepository) SetExternalMessageID(ctx context.Context, id int64, externalMessageID string) error {
+	query := `
+		UPDATE notifications SET
+			external_message_id = $2,
+			updated_at = NOW()
+		WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, externalMessageID)
 	return err
 }
 
+func (r *notificationRepository) GetByExternalMessageID(ctx context.Context, externalMessageID string) (*domain.Notification, error) {
+	query := `
+		SELECT id, customer_id, branch_id, notification_type, channel,
+			   subject, body, reference_type, reference_id,
+			   status, scheduled_for, sent_at, delivered_at, failed_at,
+			   failure_reason, retry_count, COALESCE(external_message_id, ''), created_at, updated_at
+		FROM notifications
+		WHERE external_message_id = $1
+		ORDER BY id DESC
+		LIMIT 1`
+
+	rows, err := r.db.QueryContext(ctx, query, externalMessageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications, err := r.scanNotifications(rows)
+	if err != nil || len(notifications) == 0 {
+		return nil, err
+	}
+	return notifications[0], nil
+}
+
 func (r *notificationRepository) Cancel(ctx context.Context, id int64) error {
 	query := `
 		UPDATE notifications SET
@@ -777,6 +877,39 @@ func (r *customerNotificationPreferenceRepository) ListByCustomer(ctx context.Co
 	return prefs, rows.Err()
 }
 
+func (r *customerNotificationPreferenceRepository) ListEnabledByType(ctx context.Context, notificationType string) ([]*domain.CustomerNotificationPreference, error) {
+	query := `
+		SELECT id, customer_id, notification_type, channel, is_enabled, created_at, updated_at
+		FROM customer_notification_preferences
+		WHERE notification_type = $1 AND is_enabled = true
+		ORDER BY customer_id, channel`
+
+	rows, err := r.db.QueryContext(ctx, query, notificationType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []*domain.CustomerNotificationPreference
+	for rows.Next() {
+		pref := &domain.CustomerNotificationPreference{}
+		if err := rows.Scan(
+			&pref.ID,
+			&pref.CustomerID,
+			&pref.NotificationType,
+			&pref.Channel,
+			&pref.IsEnabled,
+			&pref.CreatedAt,
+			&pref.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, pref)
+	}
+
+	return prefs, rows.Err()
+}
+
 func (r *customerNotificationPreferenceRepository) IsEnabled(ctx context.Context, customerID int64, notificationType, channel string) (bool, error) {
 	query := `
 		SELECT is_enabled
@@ -826,6 +959,133 @@ func (r *customerNotificationPreferenceRepository) BulkUpsert(ctx context.Contex
 	return tx.Commit()
 }
 
+// Notification Channel Status Repository
+type notificationChannelStatusRepository struct {
+	db *DB
+}
+
+// NewNotificationChannelStatusRepository creates a new notification channel status repository
+func NewNotificationChannelStatusRepository(db *DB) repository.NotificationChannelStatusRepository {
+	return &notificationChannelStatusRepository{db: db}
+}
+
+const notificationChannelStatusColumns = `customer_id, channel, failure_streak, streak_started_at,
+			   paused_at, COALESCE(pause_reason, ''), updated_at`
+
+func scanNotificationChannelStatus(row interface{ Scan(...interface{}) error }) (*domain.NotificationChannelStatus, error) {
+	status := &domain.NotificationChannelStatus{}
+	err := row.Scan(
+		&status.CustomerID,
+		&status.Channel,
+		&status.FailureStreak,
+		&status.StreakStartedAt,
+		&status.PausedAt,
+		&status.PauseReason,
+		&status.UpdatedAt,
+	)
+	return status, err
+}
+
+func (r *notificationChannelStatusRepository) Get(ctx context.Context, customerID int64, channel string) (*domain.NotificationChannelStatus, error) {
+	query := `
+		SELECT ` + notificationChannelStatusColumns + `
+		FROM notification_channel_statuses
+		WHERE customer_id = $1 AND channel = $2`
+
+	status, err := scanNotificationChannelStatus(r.db.QueryRowContext(ctx, query, customerID, channel))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (r *notificationChannelStatusRepository) ListByCustomer(ctx context.Context, customerID int64) ([]*domain.NotificationChannelStatus, error) {
+	query := `
+		SELECT ` + notificationChannelStatusColumns + `
+		FROM notification_channel_statuses
+		WHERE customer_id = $1
+		ORDER BY channel`
+
+	rows, err := r.db.QueryContext(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []*domain.NotificationChannelStatus
+	for rows.Next() {
+		status, err := scanNotificationChannelStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+func (r *notificationChannelStatusRepository) RecordFailure(ctx context.Context, customerID int64, channel string, windowStart time.Time) (*domain.NotificationChannelStatus, error) {
+	query := `
+		INSERT INTO notification_channel_statuses (customer_id, channel, failure_streak, streak_started_at, updated_at)
+		VALUES ($1, $2, 1, NOW(), NOW())
+		ON CONFLICT (customer_id, channel) DO UPDATE SET
+			failure_streak = CASE
+				WHEN notification_channel_statuses.streak_started_at IS NULL
+					OR notification_channel_statuses.streak_started_at < $3 THEN 1
+				ELSE notification_channel_statuses.failure_streak + 1
+			END,
+			streak_started_at = CASE
+				WHEN notification_channel_statuses.streak_started_at IS NULL
+					OR notification_channel_statuses.streak_started_at < $3 THEN NOW()
+				ELSE notification_channel_statuses.streak_started_at
+			END,
+			updated_at = NOW()
+		RETURNING ` + notificationChannelStatusColumns
+
+	return scanNotificationChannelStatus(r.db.QueryRowContext(ctx, query, customerID, channel, windowStart))
+}
+
+func (r *notificationChannelStatusRepository) ResetStreak(ctx context.Context, customerID int64, channel string) error {
+	query := `
+		UPDATE notification_channel_statuses SET
+			failure_streak = 0,
+			streak_started_at = NULL,
+			updated_at = NOW()
+		WHERE customer_id = $1 AND channel = $2 AND failure_streak > 0`
+
+	_, err := r.db.ExecContext(ctx, query, customerID, channel)
+	return err
+}
+
+func (r *notificationChannelStatusRepository) Pause(ctx context.Context, customerID int64, channel, reason string) error {
+	query := `
+		INSERT INTO notification_channel_statuses (customer_id, channel, paused_at, pause_reason, updated_at)
+		VALUES ($1, $2, NOW(), $3, NOW())
+		ON CONFLICT (customer_id, channel) DO UPDATE SET
+			paused_at = COALESCE(notification_channel_statuses.paused_at, NOW()),
+			pause_reason = EXCLUDED.pause_reason,
+			updated_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, query, customerID, channel, reason)
+	return err
+}
+
+func (r *notificationChannelStatusRepository) Resume(ctx context.Context, customerID int64, channel string) error {
+	query := `
+		UPDATE notification_channel_statuses SET
+			failure_streak = 0,
+			streak_started_at = NULL,
+			paused_at = NULL,
+			pause_reason = NULL,
+			updated_at = NOW()
+		WHERE customer_id = $1 AND channel = $2`
+
+	_, err := r.db.ExecContext(ctx, query, customerID, channel)
+	return err
+}
+
 // Internal Notification Repository
 type internalNotificationRepository struct {
 	db *DB
@@ -840,8 +1100,8 @@ func (r *internalNotificationRepository) Create(ctx context.Context, notificatio
 	query := `
 		INSERT INTO internal_notifications (
 			user_id, branch_id, title, message, type,
-			reference_type, reference_id, action_url
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			reference_type, reference_id, action_url, digest_pending
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at`
 
 	return r.db.QueryRowContext(ctx, query,
@@ -853,6 +1113,7 @@ func (r *internalNotificationRepository) Create(ctx context.Context, notificatio
 		notification.ReferenceType,
 		notification.ReferenceID,
 		notification.ActionURL,
+		notification.DigestPending,
 	).Scan(&notification.ID, &notification.CreatedAt)
 }
 
@@ -915,7 +1176,8 @@ func (r *internalNotificationRepository) Delete(ctx context.Context, id int64) e
 }
 
 func (r *internalNotificationRepository) List(ctx context.Context, filter repository.InternalNotificationFilter) ([]*domain.InternalNotification, int64, error) {
-	var conditions []string
+	// Notifications held for a digest are not delivered yet
+	conditions := []string{"digest_pending = false"}
 	var args []interface{}
 	argPos := 1
 
@@ -950,10 +1212,7 @@ func (r *internalNotificationRepository) List(ctx context.Context, filter reposi
 		argPos++
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
-	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
 
 	// Count query
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM internal_notifications %s", whereClause)
@@ -1004,7 +1263,7 @@ func (r *internalNotificationRepository) ListUnreadByUser(ctx context.Context, u
 		SELECT id, user_id, branch_id, title, message, type,
 			   reference_type, reference_id, action_url, is_read, read_at, created_at
 		FROM internal_notifications
-		WHERE user_id = $1 AND is_read = false
+		WHERE user_id = $1 AND is_read = false AND digest_pending = false
 		ORDER BY created_at DESC
 		LIMIT $2`
 
@@ -1058,14 +1317,14 @@ func (r *internalNotificationRepository) MarkAllAsRead(ctx context.Context, user
 		UPDATE internal_notifications SET
 			is_read = true,
 			read_at = NOW()
-		WHERE user_id = $1 AND is_read = false`
+		WHERE user_id = $1 AND is_read = false AND digest_pending = false`
 
 	_, err := r.db.ExecContext(ctx, query, userID)
 	return err
 }
 
 func (r *internalNotificationRepository) GetUnreadCount(ctx context.Context, userID int64) (int64, error) {
-	query := `SELECT COUNT(*) FROM internal_notifications WHERE user_id = $1 AND is_read = false`
+	query := `SELECT COUNT(*) FROM internal_notifications WHERE user_id = $1 AND is_read = false AND digest_pending = false`
 
 	var count int64
 	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
@@ -1084,8 +1343,8 @@ func (r *internalNotificationRepository) CreateBulk(ctx context.Context, notific
 	query := `
 		INSERT INTO internal_notifications (
 			user_id, branch_id, title, message, type,
-			reference_type, reference_id, action_url
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			reference_type, reference_id, action_url, digest_pending
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at`
 
 	for _, notification := range notifications {
@@ -1098,6 +1357,7 @@ func (r *internalNotificationRepository) CreateBulk(ctx context.Context, notific
 			notification.ReferenceType,
 			notification.ReferenceID,
 			notification.ActionURL,
+			notification.DigestPending,
 		).Scan(&notification.ID, &notification.CreatedAt)
 		if err != nil {
 			return err
@@ -1116,3 +1376,173 @@ func (r *internalNotificationRepository) DeleteOlderThan(ctx context.Context, ol
 	}
 	return result.RowsAffected()
 }
+
+func (r *internalNotificationRepository) ListDigestPending(ctx context.Context, userID int64) ([]*domain.InternalNotification, error) {
+	query := `
+		SELECT id, user_id, branch_id, title, message, type,
+			   reference_type, reference_id, action_url, is_read, read_at, created_at
+		FROM internal_notifications
+		WHERE user_id = $1 AND digest_pending = true
+		ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanInternalNotifications(rows)
+}
+
+func (r *internalNotificationRepository) CreateDigest(ctx context.Context, digest *domain.InternalNotification, ids []int64) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO internal_notifications (
+			user_id, branch_id, title, message, type,
+			reference_type, reference_id, action_url
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, insert,
+		digest.UserID,
+		digest.BranchID,
+		digest.Title,
+		digest.Message,
+		digest.Type,
+		digest.ReferenceType,
+		digest.ReferenceID,
+		digest.ActionURL,
+	).Scan(&digest.ID, &digest.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	release := `
+		UPDATE internal_notifications SET
+			digest_pending = false,
+			is_read = true,
+			read_at = NOW()
+		WHERE id = ANY($1)`
+
+	if _, err := tx.ExecContext(ctx, release, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// User Notification Preference Repository
+type userNotificationPreferenceRepository struct {
+	db *DB
+}
+
+// NewUserNotificationPreferenceRepository creates a new user notification preference repository
+func NewUserNotificationPreferenceRepository(db *DB) repository.UserNotificationPreferenceRepository {
+	return &userNotificationPreferenceRepository{db: db}
+}
+
+func (r *userNotificationPreferenceRepository) Get(ctx context.Context, userID int64) (*domain.UserNotificationPreference, error) {
+	query := `
+		SELECT user_id, digest_mode, digest_interval_hours, last_digest_at, updated_at
+		FROM user_notification_preferences
+		WHERE user_id = $1`
+
+	pref := &domain.UserNotificationPreference{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&pref.UserID,
+		&pref.DigestMode,
+		&pref.DigestIntervalHours,
+		&pref.LastDigestAt,
+		&pref.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+func (r *userNotificationPreferenceRepository) Upsert(ctx context.Context, pref *domain.UserNotificationPreference) error {
+	query := `
+		INSERT INTO user_notification_preferences (user_id, digest_mode, digest_interval_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			digest_mode = EXCLUDED.digest_mode,
+			digest_interval_hours = EXCLUDED.digest_interval_hours,
+			updated_at = NOW()
+		RETURNING last_digest_at, updated_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		pref.UserID,
+		pref.DigestMode,
+		pref.DigestIntervalHours,
+	).Scan(&pref.LastDigestAt, &pref.UpdatedAt)
+}
+
+func (r *userNotificationPreferenceRepository) ListDigestUsers(ctx context.Context) ([]*domain.UserNotificationPreference, error) {
+	query := `
+		SELECT p.user_id, p.digest_mode, p.digest_interval_hours, p.last_digest_at, p.updated_at
+		FROM user_notification_preferences p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.digest_mode = true AND u.is_active = true
+		ORDER BY p.user_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []*domain.UserNotificationPreference
+	for rows.Next() {
+		pref := &domain.UserNotificationPreference{}
+		if err := rows.Scan(
+			&pref.UserID,
+			&pref.DigestMode,
+			&pref.DigestIntervalHours,
+			&pref.LastDigestAt,
+			&pref.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, rows.Err()
+}
+
+func (r *userNotificationPreferenceRepository) ListDigestUserIDs(ctx context.Context, userIDs []int64) ([]int64, error) {
+	query := `
+		SELECT user_id
+		FROM user_notification_preferences
+		WHERE digest_mode = true AND user_id = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *userNotificationPreferenceRepository) MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	query := `UPDATE user_notification_preferences SET last_digest_at = $2 WHERE user_id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, userID, sentAt)
+	return err
+}
@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// nextSequenceNumber reserves the next number of a document sequence. The counter
+// for the sequence and the current period is incremented atomically in
+// number_sequences, so concurrent callers never receive the same number. Each
+// period keeps its own counter and the period is part of the number, so numbers
+// restart at 1 on a new period without colliding with earlier ones.
+func nextSequenceNumber(ctx context.Context, db *DB, name, prefix string, cadence domain.SequenceResetCadence, now time.Time) (string, error) {
+	period := cadence.Period(now)
+
+	query := `
+		INSERT INTO number_sequences (name, period, last_value)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (name, period)
+		DO UPDATE SET last_value = number_sequences.last_value + 1
+		RETURNING last_value`
+
+	var seq int
+	if err := db.QueryRowContext(ctx, query, name, period).Scan(&seq); err != nil {
+		return "", fmt.Errorf("failed to reserve %s number: %w", name, err)
+	}
+
+	return formatSequenceNumber(prefix, period, seq), nil
+}
+
+// formatSequenceNumber formats a document number as PREFIX-PERIOD-NNNNNN, or
+// PREFIX-NNNNNN for sequences that never reset
+func formatSequenceNumber(prefix, period string, seq int) string {
+	if period == "" {
+		return fmt.Sprintf("%s-%06d", prefix, seq)
+	}
+	return fmt.Sprintf("%s-%s-%06d", prefix, period, seq)
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+)
+
+func TestFormatSequenceNumber(t *testing.T) {
+	assert.Equal(t, "LN-000001", formatSequenceNumber("LN", "", 1))
+	assert.Equal(t, "LN-2026-000042", formatSequenceNumber("LN", "2026", 42))
+	assert.Equal(t, "PY-2026-03-000007", formatSequenceNumber("PY", "2026-03", 7))
+}
+
+// TestNextSequenceNumber_YearlyReset runs against a migrated database set in
+// TEST_DATABASE_URL and is skipped otherwise.
+func TestNextSequenceNumber_YearlyReset(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	sqlDB, err := sql.Open("pgx", url)
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db := &DB{sqlDB}
+	ctx := context.Background()
+	name := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	defer sqlDB.Exec("DELETE FROM number_sequences WHERE name = $1", name)
+
+	lastOf2030 := time.Date(2030, 12, 31, 23, 59, 59, 0, time.Local)
+	firstOf2031 := time.Date(2031, 1, 1, 0, 0, 0, 0, time.Local)
+
+	seen := make(map[string]bool)
+	for i := 1; i <= 3; i++ {
+		number, err := nextSequenceNumber(ctx, db, name, "LN", domain.SequenceResetYearly, lastOf2030)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("LN-2030-%06d", i), number)
+		assert.False(t, seen[number], "duplicate number %s", number)
+		seen[number] = true
+	}
+
+	number, err := nextSequenceNumber(ctx, db, name, "LN", domain.SequenceResetYearly, firstOf2031)
+	require.NoError(t, err)
+	assert.Equal(t, "LN-2031-000001", number)
+	assert.False(t, seen[number], "number reused across the year boundary")
+
+	// Switching cadence starts a separate sequence that cannot collide either
+	number, err = nextSequenceNumber(ctx, db, name, "LN", domain.SequenceResetMonthly, firstOf2031)
+	require.NoError(t, err)
+	assert.Equal(t, "LN-2031-01-000001", number)
+}
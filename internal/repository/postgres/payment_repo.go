@@ -101,6 +101,12 @@ func (r *PaymentRepository) List(ctx context.Context, params repository.PaymentL
 		args = append(args, *params.Method)
 	}
 
+	if params.CashSessionID != nil {
+		argCount++
+		baseQuery += fmt.Sprintf(" AND p.cash_session_id = $%d", argCount)
+		args = append(args, *params.CashSessionID)
+	}
+
 	if params.DateFrom != nil {
 		argCount++
 		baseQuery += fmt.Sprintf(" AND p.payment_date >= $%d", argCount)
@@ -206,38 +212,98 @@ func (r *PaymentRepository) ListByLoan(ctx context.Context, loanID int64) ([]*do
 	return payments, nil
 }
 
+// ListAfter lists up to limit payments with an ID above afterID in ID order
+func (r *PaymentRepository) ListAfter(ctx context.Context, branchID, afterID int64, limit int) ([]*domain.Payment, error) {
+	query := `
+		SELECT id, payment_number, branch_id, loan_id, customer_id,
+			   amount, principal_amount, interest_amount, late_fee_amount,
+			   payment_method, reference_number, status, payment_date,
+			   loan_balance_after, interest_balance_after,
+			   reversed_at, reversed_by, reversal_reason, notes, cash_session_id,
+			   created_by, created_at, updated_at
+		FROM payments
+		WHERE id > $1 AND ($2 = 0 OR branch_id = $2)
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, branchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*domain.Payment{}
+	for rows.Next() {
+		payment, err := r.scanPaymentRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+// GetByIdempotencyKey retrieves the payment a user created with an
+// idempotency key, or nil when there is none
+func (r *PaymentRepository) GetByIdempotencyKey(ctx context.Context, createdBy int64, key string) (*domain.Payment, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id FROM payments WHERE created_by = $1 AND idempotency_key = $2`,
+		createdBy, key,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment by idempotency key: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
 // Create creates a new payment
 func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	return insertPayment(ctx, r.db, payment)
+}
+
+// insertPayment inserts a payment
+func insertPayment(ctx context.Context, q Querier, payment *domain.Payment) error {
 	query := `
 		INSERT INTO payments (
 			payment_number, branch_id, loan_id, customer_id,
 			amount, principal_amount, interest_amount, late_fee_amount,
 			payment_method, reference_number, status, payment_date,
-			loan_balance_after, interest_balance_after, notes, cash_session_id, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			loan_balance_after, interest_balance_after, notes, cash_session_id, created_by,
+			idempotency_key
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		payment.PaymentNumber, payment.BranchID, payment.LoanID, payment.CustomerID,
 		payment.Amount, payment.PrincipalAmount, payment.InterestAmount, payment.LateFeeAmount,
 		payment.PaymentMethod, NullString(payment.ReferenceNumber), payment.Status, payment.PaymentDate,
 		payment.LoanBalanceAfter, payment.InterestBalanceAfter,
 		NullString(payment.Notes), NullInt64(payment.CashSessionID), payment.CreatedBy,
+		NullString(payment.IdempotencyKey),
 	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
 
 	if err != nil {
-		return fmt.Errorf("failed to create payment: %w", err)
+		return paymentCreateError(err)
 	}
 
 	return nil
 }
 
-// Update updates an existing payment
+// Update updates an existing payment. A payment that failed gives up its
+// idempotency key, so the client can retry the request with it.
 func (r *PaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
 	query := `
 		UPDATE payments SET
-			status = $2, reversed_at = $3, reversed_by = $4, reversal_reason = $5, updated_at = NOW()
+			status = $2, reversed_at = $3, reversed_by = $4, reversal_reason = $5, updated_at = NOW(),
+			idempotency_key = CASE WHEN $2 = 'failed' THEN NULL ELSE idempotency_key END
 		WHERE id = $1
 	`
 
@@ -257,21 +323,9 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *domain.Payment)
 	return nil
 }
 
-// GenerateNumber generates a unique payment number
-func (r *PaymentRepository) GenerateNumber(ctx context.Context) (string, error) {
-	yearStr := time.Now().Format("2006")
-	var seqNum int
-
-	query := `
-		SELECT COALESCE(MAX(CAST(SUBSTRING(payment_number FROM 'PY-\d{4}-(\d+)') AS INTEGER)), 0) + 1
-		FROM payments WHERE payment_number LIKE 'PY-' || $1 || '-%'
-	`
-	r.db.QueryRowContext(ctx, query, yearStr).Scan(&seqNum)
-	if seqNum == 0 {
-		seqNum = 1
-	}
-
-	return fmt.Sprintf("PY-%s-%06d", yearStr, seqNum), nil
+// GenerateNumber reserves the next payment number for the given reset cadence
+func (r *PaymentRepository) GenerateNumber(ctx context.Context, cadence domain.SequenceResetCadence) (string, error) {
+	return nextSequenceNumber(ctx, r.db, "payment", "PY", cadence, time.Now())
 }
 
 // Helper functions
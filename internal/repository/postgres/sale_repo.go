@@ -28,7 +28,8 @@ func (r *SaleRepository) GetByID(ctx context.Context, id int64) (*domain.Sale, e
 			   payment_method, reference_number, status, sale_date,
 			   refund_amount, refund_reason, refunded_at, refunded_by,
 			   notes, cash_session_id, created_by, updated_by,
-			   created_at, updated_at, deleted_at
+			   created_at, updated_at, deleted_at,
+			   COALESCE(warranty_days, 0), warranty_expiry, refund_outside_warranty
 		FROM sales
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -44,7 +45,8 @@ func (r *SaleRepository) GetByNumber(ctx context.Context, saleNumber string) (*d
 			   payment_method, reference_number, status, sale_date,
 			   refund_amount, refund_reason, refunded_at, refunded_by,
 			   notes, cash_session_id, created_by, updated_by,
-			   created_at, updated_at, deleted_at
+			   created_at, updated_at, deleted_at,
+			   COALESCE(warranty_days, 0), warranty_expiry, refund_outside_warranty
 		FROM sales
 		WHERE sale_number = $1 AND deleted_at IS NULL
 	`
@@ -94,6 +96,12 @@ func (r *SaleRepository) List(ctx context.Context, params repository.SaleListPar
 		args = append(args, *params.Status)
 	}
 
+	if params.CashSessionID != nil {
+		argCount++
+		baseQuery += fmt.Sprintf(" AND s.cash_session_id = $%d", argCount)
+		args = append(args, *params.CashSessionID)
+	}
+
 	if params.DateFrom != nil {
 		argCount++
 		baseQuery += fmt.Sprintf(" AND s.sale_date >= $%d", argCount)
@@ -131,6 +139,7 @@ func (r *SaleRepository) List(ctx context.Context, params repository.SaleListPar
 			   s.refund_amount, s.refund_reason, s.refunded_at, s.refunded_by,
 			   s.notes, s.cash_session_id, s.created_by, s.updated_by,
 			   s.created_at, s.updated_at, s.deleted_at,
+			   COALESCE(s.warranty_days, 0), s.warranty_expiry, s.refund_outside_warranty,
 			   i.id, i.name, i.sku,
 			   c.id, c.first_name, c.last_name, c.identity_number
 		%s ORDER BY %s %s LIMIT $%d OFFSET $%d`,
@@ -174,8 +183,8 @@ func (r *SaleRepository) Create(ctx context.Context, sale *domain.Sale) error {
 			branch_id, item_id, customer_id, sale_number, sale_type,
 			sale_price, discount_amount, discount_reason, final_price,
 			payment_method, reference_number, status, sale_date,
-			notes, cash_session_id, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			notes, cash_session_id, created_by, warranty_days, warranty_expiry
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -184,6 +193,7 @@ func (r *SaleRepository) Create(ctx context.Context, sale *domain.Sale) error {
 		sale.SalePrice, sale.DiscountAmount, NullStringPtr(sale.DiscountReason), sale.FinalPrice,
 		sale.PaymentMethod, NullStringPtr(sale.ReferenceNumber), sale.Status, sale.SaleDate,
 		NullStringPtr(sale.Notes), NullInt64(sale.CashSessionID), sale.CreatedBy,
+		sale.WarrantyDays, NullTime(sale.WarrantyExpiry),
 	).Scan(&sale.ID, &sale.CreatedAt, &sale.UpdatedAt)
 
 	if err != nil {
@@ -199,13 +209,14 @@ func (r *SaleRepository) Update(ctx context.Context, sale *domain.Sale) error {
 		UPDATE sales SET
 			status = $2, refund_amount = $3, refund_reason = $4,
 			refunded_at = $5, refunded_by = $6, notes = $7,
-			updated_by = $8, updated_at = NOW()
+			updated_by = $8, refund_outside_warranty = $9, updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
 		sale.ID, sale.Status, NullFloat64(sale.RefundAmount), NullStringPtr(sale.RefundReason),
 		sale.RefundedAt, NullInt64(sale.RefundedBy), NullStringPtr(sale.Notes), sale.UpdatedBy,
+		sale.RefundOutsideWarranty,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update sale: %w", err)
@@ -240,7 +251,7 @@ func (r *SaleRepository) scanSale(row *sql.Row) (*domain.Sale, error) {
 	var customerID, cashSessionID, refundedBy, createdBy, updatedBy sql.NullInt64
 	var discountReason, referenceNumber, refundReason, notes sql.NullString
 	var refundAmount sql.NullFloat64
-	var refundedAt, deletedAt sql.NullTime
+	var refundedAt, deletedAt, warrantyExpiry sql.NullTime
 
 	err := row.Scan(
 		&sale.ID, &sale.BranchID, &sale.ItemID, &customerID, &sale.SaleNumber, &sale.SaleType,
@@ -249,6 +260,7 @@ func (r *SaleRepository) scanSale(row *sql.Row) (*domain.Sale, error) {
 		&refundAmount, &refundReason, &refundedAt, &refundedBy,
 		&notes, &cashSessionID, &createdBy, &updatedBy,
 		&sale.CreatedAt, &sale.UpdatedAt, &deletedAt,
+		&sale.WarrantyDays, &warrantyExpiry, &sale.RefundOutsideWarranty,
 	)
 
 	if err != nil {
@@ -276,6 +288,7 @@ func (r *SaleRepository) scanSale(row *sql.Row) (*domain.Sale, error) {
 		sale.UpdatedBy = updatedBy.Int64
 	}
 	sale.DeletedAt = TimePtr(deletedAt)
+	sale.WarrantyExpiry = TimePtr(warrantyExpiry)
 
 	return sale, nil
 }
@@ -285,7 +298,7 @@ func (r *SaleRepository) scanSaleRow(rows *sql.Rows) (*domain.Sale, error) {
 	var customerID, cashSessionID, refundedBy, createdBy, updatedBy sql.NullInt64
 	var discountReason, referenceNumber, refundReason, notes sql.NullString
 	var refundAmount sql.NullFloat64
-	var refundedAt, deletedAt sql.NullTime
+	var refundedAt, deletedAt, warrantyExpiry sql.NullTime
 
 	err := rows.Scan(
 		&sale.ID, &sale.BranchID, &sale.ItemID, &customerID, &sale.SaleNumber, &sale.SaleType,
@@ -294,6 +307,7 @@ func (r *SaleRepository) scanSaleRow(rows *sql.Rows) (*domain.Sale, error) {
 		&refundAmount, &refundReason, &refundedAt, &refundedBy,
 		&notes, &cashSessionID, &createdBy, &updatedBy,
 		&sale.CreatedAt, &sale.UpdatedAt, &deletedAt,
+		&sale.WarrantyDays, &warrantyExpiry, &sale.RefundOutsideWarranty,
 	)
 
 	if err != nil {
@@ -318,6 +332,7 @@ func (r *SaleRepository) scanSaleRow(rows *sql.Rows) (*domain.Sale, error) {
 		sale.UpdatedBy = updatedBy.Int64
 	}
 	sale.DeletedAt = TimePtr(deletedAt)
+	sale.WarrantyExpiry = TimePtr(warrantyExpiry)
 
 	return sale, nil
 }
@@ -329,7 +344,7 @@ func (r *SaleRepository) scanSaleRowWithRelations(rows *sql.Rows) (*domain.Sale,
 	var discountReason, referenceNumber, refundReason, notes sql.NullString
 	var refundedAt sql.NullTime
 	var refundedBy, cashSessionID, createdBy, updatedBy sql.NullInt64
-	var deletedAt sql.NullTime
+	var deletedAt, warrantyExpiry sql.NullTime
 
 	// Item fields
 	var itemID sql.NullInt64
@@ -347,6 +362,7 @@ func (r *SaleRepository) scanSaleRowWithRelations(rows *sql.Rows) (*domain.Sale,
 		&refundAmount, &refundReason, &refundedAt, &refundedBy,
 		&notes, &cashSessionID, &createdBy, &updatedBy,
 		&sale.CreatedAt, &sale.UpdatedAt, &deletedAt,
+		&sale.WarrantyDays, &warrantyExpiry, &sale.RefundOutsideWarranty,
 		// Item
 		&itemID, &itemName, &itemSKU,
 		// Customer
@@ -375,6 +391,7 @@ func (r *SaleRepository) scanSaleRowWithRelations(rows *sql.Rows) (*domain.Sale,
 		sale.UpdatedBy = updatedBy.Int64
 	}
 	sale.DeletedAt = TimePtr(deletedAt)
+	sale.WarrantyExpiry = TimePtr(warrantyExpiry)
 
 	// Populate Item relation
 	if itemID.Valid {
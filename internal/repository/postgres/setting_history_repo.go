@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// SettingHistoryRepository implements repository.SettingHistoryRepository
+type SettingHistoryRepository struct {
+	db *DB
+}
+
+// NewSettingHistoryRepository creates a new SettingHistoryRepository
+func NewSettingHistoryRepository(db *DB) *SettingHistoryRepository {
+	return &SettingHistoryRepository{db: db}
+}
+
+// Create records a change to a setting
+func (r *SettingHistoryRepository) Create(ctx context.Context, entry *domain.SettingHistory) error {
+	return insertSettingHistory(ctx, r.db, entry)
+}
+
+// insertSettingHistory records a change to a setting through q
+func insertSettingHistory(ctx context.Context, q Querier, entry *domain.SettingHistory) error {
+	oldValue, err := settingHistoryValue(entry.OldValue)
+	if err != nil {
+		return err
+	}
+	newValue, err := settingHistoryValue(entry.NewValue)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO setting_history (setting_key, branch_id, action, old_value, new_value, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, changed_at
+	`
+
+	err = q.QueryRowContext(ctx, query,
+		entry.SettingKey,
+		NullInt64(entry.BranchID),
+		entry.Action,
+		oldValue,
+		newValue,
+		NullInt64(entry.ChangedBy),
+	).Scan(&entry.ID, &entry.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create setting history: %w", err)
+	}
+
+	return nil
+}
+
+// ListByKey returns the latest changes to a setting, newest first
+func (r *SettingHistoryRepository) ListByKey(ctx context.Context, key string, branchID *int64, limit int) ([]*domain.SettingHistory, error) {
+	query := `
+		SELECT id, setting_key, branch_id, action, old_value, new_value, changed_by, changed_at
+		FROM setting_history
+		WHERE setting_key = $1 AND COALESCE(branch_id, 0) = COALESCE($2::bigint, 0)
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, key, NullInt64(branchID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list setting history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.SettingHistory{}
+	for rows.Next() {
+		entry := &domain.SettingHistory{}
+		var oldValue, newValue []byte
+		var entryBranchID, changedBy sql.NullInt64
+		if err := rows.Scan(&entry.ID, &entry.SettingKey, &entryBranchID, &entry.Action,
+			&oldValue, &newValue, &changedBy, &entry.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan setting history: %w", err)
+		}
+		if oldValue != nil {
+			_ = json.Unmarshal(oldValue, &entry.OldValue)
+		}
+		if newValue != nil {
+			_ = json.Unmarshal(newValue, &entry.NewValue)
+		}
+		entry.BranchID = Int64Ptr(entryBranchID)
+		entry.ChangedBy = Int64Ptr(changedBy)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// settingHistoryValue encodes a setting value for a JSONB column, NULL when
+// the setting did not exist
+func settingHistoryValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal setting history value: %w", err)
+	}
+	return raw, nil
+}
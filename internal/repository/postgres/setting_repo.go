@@ -143,6 +143,11 @@ func (r *SettingRepository) GetAll(ctx context.Context, branchID *int64) ([]*dom
 
 // Set creates or updates a setting
 func (r *SettingRepository) Set(ctx context.Context, setting *domain.Setting) error {
+	return upsertSetting(ctx, r.db, setting)
+}
+
+// upsertSetting creates or updates a setting through q
+func upsertSetting(ctx context.Context, q Querier, setting *domain.Setting) error {
 	// Convert value to JSON
 	valueJSON, err := json.Marshal(setting.Value)
 	if err != nil {
@@ -157,7 +162,7 @@ func (r *SettingRepository) Set(ctx context.Context, setting *domain.Setting) er
 		RETURNING id, created_at, updated_at
 	`
 
-	err = r.db.QueryRowContext(ctx, query,
+	err = q.QueryRowContext(ctx, query,
 		setting.Key,
 		valueJSON,
 		NullString(setting.Description),
@@ -173,6 +178,11 @@ func (r *SettingRepository) Set(ctx context.Context, setting *domain.Setting) er
 
 // Delete deletes a setting
 func (r *SettingRepository) Delete(ctx context.Context, key string, branchID *int64) error {
+	return deleteSetting(ctx, r.db, key, branchID)
+}
+
+// deleteSetting deletes a setting through q
+func deleteSetting(ctx context.Context, q Querier, key string, branchID *int64) error {
 	var query string
 	var args []interface{}
 
@@ -184,7 +194,7 @@ func (r *SettingRepository) Delete(ctx context.Context, key string, branchID *in
 		args = []interface{}{key}
 	}
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := q.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete setting: %w", err)
 	}
@@ -196,3 +206,31 @@ func (r *SettingRepository) Delete(ctx context.Context, key string, branchID *in
 
 	return nil
 }
+
+// Import saves and deletes global settings and records their history in a
+// single transaction, so a failure leaves every setting as it was
+func (r *SettingRepository) Import(ctx context.Context, settings []*domain.Setting, deleteKeys []string, history []*domain.SettingHistory) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, setting := range settings {
+		if err := upsertSetting(ctx, tx, setting); err != nil {
+			return fmt.Errorf("failed to save setting %s: %w", setting.Key, err)
+		}
+	}
+	for _, key := range deleteKeys {
+		if err := deleteSetting(ctx, tx, key, nil); err != nil {
+			return fmt.Errorf("failed to delete setting %s: %w", key, err)
+		}
+	}
+	for _, entry := range history {
+		if err := insertSettingHistory(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// StockTakeRepository implements repository.StockTakeRepository
+type StockTakeRepository struct {
+	db *DB
+}
+
+// NewStockTakeRepository creates a new StockTakeRepository
+func NewStockTakeRepository(db *DB) *StockTakeRepository {
+	return &StockTakeRepository{db: db}
+}
+
+const stockTakeColumns = `id, branch_id, status, notes, report, started_by, started_at, finalized_by, finalized_at`
+
+// Create starts a count. The partial unique index on in-progress counts makes
+// concurrent starts on the same branch create a single count.
+func (r *StockTakeRepository) Create(ctx context.Context, stockTake *domain.StockTake) (bool, error) {
+	query := `
+		INSERT INTO stock_takes (branch_id, status, notes, started_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (branch_id) WHERE status = 'in_progress' DO NOTHING
+		RETURNING id, started_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		stockTake.BranchID, stockTake.Status, NullStringPtr(stockTake.Notes), stockTake.StartedBy,
+	).Scan(&stockTake.ID, &stockTake.StartedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create stock take: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetByID retrieves a stock take by ID
+func (r *StockTakeRepository) GetByID(ctx context.Context, id int64) (*domain.StockTake, error) {
+	query := `SELECT ` + stockTakeColumns + ` FROM stock_takes WHERE id = $1`
+
+	stockTake, err := r.scanStockTakeRow(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("stock take not found")
+		}
+		return nil, fmt.Errorf("failed to get stock take: %w", err)
+	}
+	return stockTake, nil
+}
+
+// GetActiveByBranch retrieves the branch's count in progress, or nil if there is none
+func (r *StockTakeRepository) GetActiveByBranch(ctx context.Context, branchID int64) (*domain.StockTake, error) {
+	query := `SELECT ` + stockTakeColumns + ` FROM stock_takes WHERE branch_id = $1 AND status = 'in_progress'`
+
+	stockTake, err := r.scanStockTakeRow(r.db.QueryRowContext(ctx, query, branchID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active stock take: %w", err)
+	}
+	return stockTake, nil
+}
+
+// List retrieves stock takes, most recent first
+func (r *StockTakeRepository) List(ctx context.Context, params repository.StockTakeListParams) (*repository.PaginatedResult[domain.StockTake], error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PerPage <= 0 {
+		params.PerPage = 20
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argNum := 1
+
+	if params.BranchID > 0 {
+		where += fmt.Sprintf(" AND branch_id = $%d", argNum)
+		args = append(args, params.BranchID)
+		argNum++
+	}
+
+	if params.Status != nil {
+		where += fmt.Sprintf(" AND status = $%d", argNum)
+		args = append(args, *params.Status)
+		argNum++
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_takes "+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count stock takes: %w", err)
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	query := fmt.Sprintf(`SELECT %s FROM stock_takes %s ORDER BY started_at DESC LIMIT $%d OFFSET $%d`,
+		stockTakeColumns, where, argNum, argNum+1)
+	args = append(args, params.PerPage, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock takes: %w", err)
+	}
+	defer rows.Close()
+
+	stockTakes := []domain.StockTake{}
+	for rows.Next() {
+		stockTake, err := r.scanStockTakeRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		// The full report is only returned by GetByID
+		stockTake.Report = nil
+		stockTakes = append(stockTakes, *stockTake)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	totalPages := total / params.PerPage
+	if total%params.PerPage > 0 {
+		totalPages++
+	}
+
+	return &repository.PaginatedResult[domain.StockTake]{
+		Data:       stockTakes,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// AddScan records a scanned SKU. Several devices can scan the same count at
+// once; a SKU scanned twice keeps its first scan.
+func (r *StockTakeRepository) AddScan(ctx context.Context, scan *domain.StockTakeScan) (bool, error) {
+	query := `
+		INSERT INTO stock_take_scans (stock_take_id, sku, item_id, scanned_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (stock_take_id, sku) DO NOTHING
+		RETURNING id, scanned_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		scan.StockTakeID, scan.SKU, NullInt64(scan.ItemID), scan.ScannedBy,
+	).Scan(&scan.ID, &scan.ScannedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record stock take scan: %w", err)
+	}
+
+	return true, nil
+}
+
+// ListScans retrieves the SKUs scanned in a count, in scan order
+func (r *StockTakeRepository) ListScans(ctx context.Context, stockTakeID int64) ([]*domain.StockTakeScan, error) {
+	query := `
+		SELECT id, stock_take_id, sku, item_id, scanned_by, scanned_at
+		FROM stock_take_scans
+		WHERE stock_take_id = $1
+		ORDER BY scanned_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, stockTakeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock take scans: %w", err)
+	}
+	defer rows.Close()
+
+	scans := []*domain.StockTakeScan{}
+	for rows.Next() {
+		scan := &domain.StockTakeScan{}
+		var itemID sql.NullInt64
+		if err := rows.Scan(&scan.ID, &scan.StockTakeID, &scan.SKU, &itemID, &scan.ScannedBy, &scan.ScannedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stock take scan: %w", err)
+		}
+		scan.ItemID = Int64Ptr(itemID)
+		scans = append(scans, scan)
+	}
+
+	return scans, rows.Err()
+}
+
+// Finalize closes a count in progress and stores its discrepancy report
+func (r *StockTakeRepository) Finalize(ctx context.Context, id int64, finalizedBy int64, report *domain.StockTakeReport) error {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode stock take report: %w", err)
+	}
+
+	return r.close(ctx, id, domain.StockTakeStatusFinalized, finalizedBy, encoded)
+}
+
+// Cancel closes a count in progress without a report
+func (r *StockTakeRepository) Cancel(ctx context.Context, id int64, cancelledBy int64) error {
+	return r.close(ctx, id, domain.StockTakeStatusCancelled, cancelledBy, nil)
+}
+
+func (r *StockTakeRepository) close(ctx context.Context, id int64, status domain.StockTakeStatus, userID int64, report []byte) error {
+	query := `
+		UPDATE stock_takes
+		SET status = $2, report = $3, finalized_by = $4, finalized_at = NOW()
+		WHERE id = $1 AND status = 'in_progress'
+	`
+
+	var encoded interface{}
+	if report != nil {
+		encoded = string(report)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, id, status, encoded, userID)
+	if err != nil {
+		return fmt.Errorf("failed to close stock take: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("stock take is not in progress")
+	}
+
+	return nil
+}
+
+func (r *StockTakeRepository) scanStockTakeRow(row rowScanner) (*domain.StockTake, error) {
+	stockTake := &domain.StockTake{}
+	var notes sql.NullString
+	var report []byte
+	var finalizedBy sql.NullInt64
+	var finalizedAt sql.NullTime
+
+	err := row.Scan(
+		&stockTake.ID, &stockTake.BranchID, &stockTake.Status, &notes, &report,
+		&stockTake.StartedBy, &stockTake.StartedAt, &finalizedBy, &finalizedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stockTake.Notes = StringPtrVal(notes)
+	stockTake.FinalizedBy = Int64Ptr(finalizedBy)
+	stockTake.FinalizedAt = TimePtr(finalizedAt)
+	if report != nil {
+		stockTake.Report = &domain.StockTakeReport{}
+		if err := json.Unmarshal(report, stockTake.Report); err != nil {
+			return nil, fmt.Errorf("failed to decode stock take report: %w", err)
+		}
+	}
+	return stockTake, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+)
+
+// StoredFileRepository implements repository.StoredFileRepository
+type StoredFileRepository struct {
+	db *DB
+}
+
+// NewStoredFileRepository creates a new StoredFileRepository
+func NewStoredFileRepository(db *DB) *StoredFileRepository {
+	return &StoredFileRepository{db: db}
+}
+
+// storageUsageSource lists every stored object with its branch, entity type and
+// size: uploaded files plus generated documents, which track their own size
+const storageUsageSource = `
+	SELECT branch_id, entity_type, size_bytes FROM stored_files WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT branch_id, reference_type, COALESCE(file_size, 0) FROM documents WHERE file_path IS NOT NULL`
+
+// Create records an uploaded file
+func (r *StoredFileRepository) Create(ctx context.Context, file *domain.StoredFile) error {
+	query := `
+		INSERT INTO stored_files (branch_id, entity_type, entity_id, file_id, url, thumbnail_url, mime_type, size_bytes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	var createdBy *int64
+	if file.CreatedBy != 0 {
+		createdBy = &file.CreatedBy
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		file.BranchID, file.EntityType, file.EntityID, file.FileID, file.URL, NullString(file.ThumbnailURL), file.MimeType,
+		file.SizeBytes, NullInt64(createdBy),
+	).Scan(&file.ID, &file.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record stored file: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByURL marks the file at a URL as deleted
+func (r *StoredFileRepository) DeleteByURL(ctx context.Context, url string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE stored_files SET deleted_at = NOW() WHERE url = $1 AND deleted_at IS NULL`, url)
+	if err != nil {
+		return fmt.Errorf("failed to delete stored file: %w", err)
+	}
+	return nil
+}
+
+// GetByFileID returns the metadata of a stored file that has not been deleted
+func (r *StoredFileRepository) GetByFileID(ctx context.Context, fileID string) (*domain.StoredFile, error) {
+	query := `
+		SELECT id, branch_id, entity_type, entity_id, file_id, url, thumbnail_url, mime_type, size_bytes, created_by, created_at
+		FROM stored_files
+		WHERE file_id = $1 AND deleted_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	file := &domain.StoredFile{}
+	var thumbnailURL sql.NullString
+	var createdBy sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, fileID).Scan(
+		&file.ID, &file.BranchID, &file.EntityType, &file.EntityID, &file.FileID, &file.URL, &thumbnailURL,
+		&file.MimeType, &file.SizeBytes, &createdBy, &file.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("stored file not found")
+		}
+		return nil, fmt.Errorf("failed to get stored file: %w", err)
+	}
+
+	file.ThumbnailURL = thumbnailURL.String
+	file.CreatedBy = createdBy.Int64
+	return file, nil
+}
+
+// GetBranchBytes returns the bytes used by a branch's uploads and generated documents
+func (r *StoredFileRepository) GetBranchBytes(ctx context.Context, branchID int64) (int64, error) {
+	query := `SELECT COALESCE(SUM(size_bytes), 0) FROM (` + storageUsageSource + `) usage WHERE branch_id = $1`
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, query, branchID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get branch storage usage: %w", err)
+	}
+	return total, nil
+}
+
+// ListUsage groups the storage used per branch and entity type
+func (r *StoredFileRepository) ListUsage(ctx context.Context, branchID *int64) ([]*domain.StorageUsage, error) {
+	query := `SELECT branch_id, entity_type, COUNT(*), COALESCE(SUM(size_bytes), 0) FROM (` + storageUsageSource + `) usage`
+	args := []interface{}{}
+	if branchID != nil {
+		query += ` WHERE branch_id = $1`
+		args = append(args, *branchID)
+	}
+	query += ` GROUP BY branch_id, entity_type ORDER BY branch_id, entity_type`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []*domain.StorageUsage{}
+	for rows.Next() {
+		u := &domain.StorageUsage{}
+		if err := rows.Scan(&u.BranchID, &u.EntityType, &u.Objects, &u.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan storage usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// UserActivityRepository implements repository.UserActivityRepository
+type UserActivityRepository struct {
+	db *DB
+}
+
+// NewUserActivityRepository creates a new UserActivityRepository
+func NewUserActivityRepository(db *DB) *UserActivityRepository {
+	return &UserActivityRepository{db: db}
+}
+
+// ListUserActivity totals a user's activity in each branch. Each source
+// contributes one row per record the user created to the union, and the rows
+// are grouped per branch.
+func (r *UserActivityRepository) ListUserActivity(ctx context.Context, userID int64, from, to time.Time) ([]*domain.UserBranchActivity, error) {
+	query := `
+		WITH activity AS (
+			SELECT branch_id, created_by AS user_id, 1 AS loans, loan_amount AS loans_amount, 0 AS payments, 0 AS payments_amount,
+				0 AS sales, 0 AS sales_amount, 0 AS sessions, 0 AS difference
+			FROM loans
+			WHERE created_by = $1 AND deleted_at IS NULL AND created_at >= $2 AND created_at < $3
+			UNION ALL
+			SELECT branch_id, created_by, 0, 0, 1, amount, 0, 0, 0, 0
+			FROM payments
+			WHERE created_by = $1 AND status = 'completed' AND payment_date >= $2 AND payment_date < $3
+			UNION ALL
+			SELECT branch_id, created_by, 0, 0, 0, 0, 1, total_amount, 0, 0
+			FROM sales
+			WHERE created_by = $1 AND status = 'completed' AND sale_date >= $2 AND sale_date < $3
+			UNION ALL
+			SELECT branch_id, user_id, 0, 0, 0, 0, 0, 0, 1, COALESCE(difference, 0)
+			FROM cash_sessions
+			WHERE user_id = $1 AND status = 'closed' AND NOT auto_closed AND closed_at >= $2 AND closed_at < $3
+		)
+		SELECT branch_id, user_id, SUM(loans), SUM(loans_amount), SUM(payments), SUM(payments_amount),
+			SUM(sales), SUM(sales_amount), SUM(sessions),
+			COALESCE(SUM(difference) / NULLIF(SUM(sessions), 0), 0)
+		FROM activity
+		GROUP BY branch_id, user_id
+		ORDER BY branch_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := []*domain.UserBranchActivity{}
+	for rows.Next() {
+		a := &domain.UserBranchActivity{}
+		if err := rows.Scan(
+			&a.BranchID, &a.UserID, &a.LoansCreated, &a.LoansAmount, &a.PaymentsProcessed, &a.PaymentsAmount,
+			&a.SalesCount, &a.SalesAmount, &a.CashSessionsClosed, &a.AverageCashDifference,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user activity: %w", err)
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, rows.Err()
+}
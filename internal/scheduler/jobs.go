@@ -14,13 +14,28 @@ import (
 
 // JobService contains dependencies for scheduled jobs
 type JobService struct {
-	loanRepo            repository.LoanRepository
-	itemRepo            repository.ItemRepository
-	paymentRepo         repository.PaymentRepository
-	customerRepo        repository.CustomerRepository
-	notificationService service.NotificationService
-	loyaltyService      service.LoyaltyService
-	logger              zerolog.Logger
+	loanRepo              repository.LoanRepository
+	itemRepo              repository.ItemRepository
+	paymentRepo           repository.PaymentRepository
+	customerRepo          repository.CustomerRepository
+	notificationService   service.NotificationService
+	dispatcher            *service.NotificationDispatcher
+	loyaltyService        service.LoyaltyService
+	jobMonitor            *service.JobMonitorService
+	confiscationReminders *service.ConfiscationReminderService
+	markdowns             *service.MarkdownService
+	reports               *service.ReportService
+	router                *service.NotificationRouter
+	collections           *service.CollectionService
+	exports               *service.ExportService
+	cash                  *service.CashService
+	statements            *service.CustomerStatementService
+	backupVerification    *service.BackupVerificationService
+	backups               *service.ScheduledBackupService
+	backupSchedule        string
+	digests               *service.NotificationDigestService
+	settingRepo           repository.SettingRepository
+	logger                zerolog.Logger
 }
 
 // NewJobService creates a new JobService
@@ -30,6 +45,7 @@ func NewJobService(
 	paymentRepo repository.PaymentRepository,
 	customerRepo repository.CustomerRepository,
 	notificationService service.NotificationService,
+	dispatcher *service.NotificationDispatcher,
 	loyaltyService service.LoyaltyService,
 	logger zerolog.Logger,
 ) *JobService {
@@ -39,11 +55,78 @@ func NewJobService(
 		paymentRepo:         paymentRepo,
 		customerRepo:        customerRepo,
 		notificationService: notificationService,
+		dispatcher:          dispatcher,
 		loyaltyService:      loyaltyService,
 		logger:              logger,
 	}
 }
 
+// SetJobMonitor enables the job health check
+func (s *JobService) SetJobMonitor(jobMonitor *service.JobMonitorService) {
+	s.jobMonitor = jobMonitor
+}
+
+// SetConfiscationReminders enables the final warning before a loan's grace period ends
+func (s *JobService) SetConfiscationReminders(confiscationReminders *service.ConfiscationReminderService) {
+	s.confiscationReminders = confiscationReminders
+}
+
+// SetMarkdowns enables the automatic markdown of aging for-sale inventory
+func (s *JobService) SetMarkdowns(markdowns *service.MarkdownService) {
+	s.markdowns = markdowns
+}
+
+// SetDailyBalances enables recording the daily balance of every branch
+func (s *JobService) SetDailyBalances(reports *service.ReportService) {
+	s.reports = reports
+}
+
+// SetNotificationRouter enables notifying staff of automatic confiscations
+func (s *JobService) SetNotificationRouter(router *service.NotificationRouter) {
+	s.router = router
+}
+
+// SetCollections enables placing aging overdue loans in the collections queue
+func (s *JobService) SetCollections(collections *service.CollectionService) {
+	s.collections = collections
+}
+
+// SetExports enables writing queued CSV exports in the background
+func (s *JobService) SetExports(exports *service.ExportService) {
+	s.exports = exports
+}
+
+// SetCash enables checking for cash sessions left open too long
+func (s *JobService) SetCash(cash *service.CashService) {
+	s.cash = cash
+}
+
+// SetStatements enables the monthly customer statements
+func (s *JobService) SetStatements(statements *service.CustomerStatementService) {
+	s.statements = statements
+}
+
+// SetBackupVerification enables test restores of the latest backup
+func (s *JobService) SetBackupVerification(backupVerification *service.BackupVerificationService) {
+	s.backupVerification = backupVerification
+}
+
+// SetScheduledBackups enables the automatic backup, run on the given schedule
+func (s *JobService) SetScheduledBackups(backups *service.ScheduledBackupService, schedule string) {
+	s.backups = backups
+	s.backupSchedule = schedule
+}
+
+// SetNotificationDigests enables the periodic digest of internal notifications
+func (s *JobService) SetNotificationDigests(digests *service.NotificationDigestService) {
+	s.digests = digests
+}
+
+// SetSettings gives jobs access to branch settings such as the legal hold tag
+func (s *JobService) SetSettings(settingRepo repository.SettingRepository) {
+	s.settingRepo = settingRepo
+}
+
 // ProcessOverdueLoans checks for overdue loans and updates their status
 func (s *JobService) ProcessOverdueLoans(ctx context.Context) error {
 	s.logger.Info().Msg("Processing overdue loans...")
@@ -116,7 +199,14 @@ func (s *JobService) ProcessOverdueLoans(ctx context.Context) error {
 				Str("current_status", string(loan.Status)).
 				Msg("Checking confiscation eligibility")
 
-			if gracePeriodEndOfDay.Before(now) && loan.Status == domain.LoanStatusOverdue {
+			if gracePeriodEndOfDay.Before(now) && loan.Status == domain.LoanStatusOverdue &&
+				loan.HasTag(service.LegalHoldTag(ctx, s.settingRepo, loan.BranchID)) {
+				s.logger.Info().
+					Int64("loan_id", loan.ID).
+					Str("loan_number", loan.LoanNumber).
+					Msg("Skipping confiscation - loan is on legal hold")
+				skipped++
+			} else if gracePeriodEndOfDay.Before(now) && loan.Status == domain.LoanStatusOverdue {
 				// Update loan status to confiscated
 				if err := s.loanRepo.UpdateStatus(ctx, loan.ID, domain.LoanStatusConfiscated); err != nil {
 					s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to confiscate loan")
@@ -135,6 +225,22 @@ func (s *JobService) ProcessOverdueLoans(ctx context.Context) error {
 					Int64("item_id", loan.ItemID).
 					Msg("Loan automatically confiscated after grace period")
 
+				if s.router != nil {
+					_, err := s.router.Emit(ctx, service.InternalEvent{
+						Event:         domain.InternalEventConfiscation,
+						BranchID:      loan.BranchID,
+						Amount:        loan.RemainingBalance(),
+						Title:         "Préstamo Confiscado",
+						Message:       fmt.Sprintf("Se confiscó automáticamente la prenda del préstamo %s al vencer el período de gracia", loan.LoanNumber),
+						Type:          "warning",
+						ReferenceType: "loan",
+						ReferenceID:   &loan.ID,
+					})
+					if err != nil {
+						s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to notify staff about confiscation")
+					}
+				}
+
 				confiscated++
 			} else {
 				s.logger.Debug().
@@ -156,6 +262,7 @@ func (s *JobService) ProcessOverdueLoans(ctx context.Context) error {
 		Int("total_processed", len(loans)).
 		Msg("Overdue loan processing completed")
 
+	SetItemsProcessed(ctx, processed+confiscated)
 	return nil
 }
 
@@ -249,6 +356,7 @@ func (s *JobService) CalculateLateFeesJob(ctx context.Context) error {
 		Int("skipped", skipped).
 		Int("total_processed", len(loans)).
 		Msg("Late fee calculation completed")
+	SetItemsProcessed(ctx, updated)
 	return nil
 }
 
@@ -274,6 +382,11 @@ func (s *JobService) SendDueDateReminders(ctx context.Context) error {
 
 		// Send reminder if due in 1, 3, or 7 days
 		if daysUntilDue == 1 || daysUntilDue == 3 || daysUntilDue == 7 {
+			// Loans close to confiscation get the final warning instead
+			if s.confiscationReminders != nil && s.confiscationReminders.Covers(ctx, &loan, now) {
+				continue
+			}
+
 			// Get customer info
 			customer, err := s.customerRepo.GetByID(ctx, loan.CustomerID)
 			if err != nil || customer == nil {
@@ -291,7 +404,7 @@ func (s *JobService) SendDueDateReminders(ctx context.Context) error {
 					Type:          "loan_due_reminder",
 					Title:         "Recordatorio de Vencimiento de Préstamo",
 					Message:       message,
-					Channel:       "sms", // Default to SMS
+					Channel:       customer.ReminderChannel(), // SMS unless the phone was verified on another channel
 					ReferenceType: func() *string { t := "loan"; return &t }(),
 					ReferenceID:   &loan.ID,
 				})
@@ -311,47 +424,206 @@ func (s *JobService) SendDueDateReminders(ctx context.Context) error {
 	}
 
 	s.logger.Info().Int("reminders_sent", remindersSent).Msg("Due date reminder processing completed")
+	SetItemsProcessed(ctx, remindersSent)
 	return nil
 }
 
-// CalculateDailyInterest calculates daily interest for active loans
-func (s *JobService) CalculateDailyInterest(ctx context.Context) error {
-	s.logger.Info().Msg("Calculating daily interest...")
+// SendConfiscationReminders warns customers shortly before their loan's grace period ends
+func (s *JobService) SendConfiscationReminders(ctx context.Context) error {
+	if s.confiscationReminders == nil {
+		return nil
+	}
+
+	s.logger.Info().Msg("Sending confiscation reminders...")
 
-	params := repository.LoanListParams{
-		Status:           func() *domain.LoanStatus { status := domain.LoanStatusActive; return &status }(),
-		PaginationParams: repository.PaginationParams{PerPage: 1000},
+	sent, err := s.confiscationReminders.SendReminders(ctx, time.Now())
+	if err != nil {
+		return err
 	}
 
-	result, err := s.loanRepo.List(ctx, params)
+	s.logger.Info().Int("reminders_sent", sent).Msg("Confiscation reminder processing completed")
+	SetItemsProcessed(ctx, sent)
+	return nil
+}
+
+// SendCustomerStatements sends opted-in customers their statement for the previous month
+func (s *JobService) SendCustomerStatements(ctx context.Context) error {
+	if s.statements == nil {
+		return nil
+	}
+
+	s.logger.Info().Msg("Sending customer statements...")
+
+	queued, err := s.statements.SendMonthlyStatements(ctx, time.Now())
 	if err != nil {
 		return err
 	}
 
-	updated := 0
-	for i := range result.Data {
-		loan := &result.Data[i]
-		// Calculate daily interest rate
-		dailyRate := loan.InterestRate / 100 / 365 // Annual rate to daily
+	s.logger.Info().Int("statements_queued", queued).Msg("Customer statement processing completed")
+	SetItemsProcessed(ctx, queued)
+	return nil
+}
 
-		// Calculate interest for today
-		dailyInterest := loan.LoanAmount * dailyRate
+// VerifyLatestBackup restores the latest backup into the scratch database to
+// confirm it is usable
+func (s *JobService) VerifyLatestBackup(ctx context.Context) error {
+	if s.backupVerification == nil {
+		return nil
+	}
 
-		// Add to accrued interest
-		loan.InterestAmount += dailyInterest
+	s.logger.Info().Msg("Verifying latest backup...")
 
-		// Update the loan
-		if err := s.loanRepo.Update(ctx, loan); err != nil {
-			s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to update loan interest")
-			continue
-		}
-		updated++
+	verification, err := s.backupVerification.VerifyLatest(ctx)
+	if err != nil {
+		return err
+	}
+	if verification == nil {
+		return nil
 	}
 
 	s.logger.Info().
-		Int("loans_processed", updated).
-		Msg("Daily interest calculation completed")
+		Str("filename", verification.BackupFilename).
+		Str("status", verification.Status).
+		Msg("Backup verification completed")
+	SetItemsProcessed(ctx, 1)
+	return nil
+}
+
+// CreateScheduledBackup backs up the database and prunes the oldest backups
+func (s *JobService) CreateScheduledBackup(ctx context.Context) error {
+	if s.backups == nil {
+		return nil
+	}
+
+	s.logger.Info().Msg("Creating scheduled backup...")
+
+	result, err := s.backups.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("filename", result.Backup.Filename).
+		Int("pruned", result.Pruned).
+		Msg("Scheduled backup processing completed")
+	SetItemsProcessed(ctx, 1)
+	return nil
+}
+
+// MarkDownAgingInventory lowers the sale price of items that stay for sale too long
+func (s *JobService) MarkDownAgingInventory(ctx context.Context) error {
+	if s.markdowns == nil {
+		return nil
+	}
+
+	s.logger.Info().Msg("Marking down aging inventory...")
+
+	markedDown, err := s.markdowns.Run(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("items_marked_down", markedDown).Msg("Inventory markdown completed")
+	SetItemsProcessed(ctx, markedDown)
+	return nil
+}
+
+// RecordDailyBalances records yesterday's balance of every branch. Balances are
+// upserted, so running it more than once a day only refreshes them.
+func (s *JobService) RecordDailyBalances(ctx context.Context) error {
+	if s.reports == nil {
+		return nil
+	}
+
+	yesterday := domain.DateFromTime(time.Now().AddDate(0, 0, -1))
+	recorded, err := s.reports.RecordDailyBalances(ctx, yesterday)
+	SetItemsProcessed(ctx, recorded)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("branches", recorded).Str("date", yesterday.String()).Msg("Daily balances recorded")
+	return nil
+}
+
+// RecordLoanBalanceSnapshots snapshots today's open loan balances. Every run
+// replaces the day's snapshots, so the last one before midnight holds the
+// figures reports read for that day.
+func (s *JobService) RecordLoanBalanceSnapshots(ctx context.Context) error {
+	if s.reports == nil {
+		return nil
+	}
+
+	today := domain.Today()
+	recorded, err := s.reports.RecordLoanBalanceSnapshots(ctx, today)
+	SetItemsProcessed(ctx, recorded)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("loans", recorded).Str("date", today.String()).Msg("Loan balance snapshots recorded")
+	return nil
+}
+
+// RefreshCollectionsQueue places aging overdue loans in the collections queue
+// and resolves the cases whose loan is no longer overdue
+func (s *JobService) RefreshCollectionsQueue(ctx context.Context) error {
+	if s.collections == nil {
+		return nil
+	}
+
+	s.logger.Info().Msg("Refreshing collections queue...")
+
+	result, err := s.collections.RefreshQueue(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Int("entered", result.Entered).
+		Int("updated", result.Updated).
+		Int("resolved", result.Resolved).
+		Msg("Collections queue refreshed")
+	SetItemsProcessed(ctx, result.Entered+result.Resolved)
+	return nil
+}
+
+// ProcessExportJobs writes the queued exports and resumes the interrupted ones
+func (s *JobService) ProcessExportJobs(ctx context.Context) error {
+	if s.exports == nil {
+		return nil
+	}
+
+	completed, err := s.exports.ProcessPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if completed > 0 {
+		s.logger.Info().Int("completed", completed).Msg("Export jobs processed")
+	}
+	SetItemsProcessed(ctx, completed)
+	return nil
+}
+
+// CheckOpenCashSessions warns about cash sessions left open too long and
+// auto-closes the ones past their branch's auto-close threshold
+func (s *JobService) CheckOpenCashSessions(ctx context.Context) error {
+	if s.cash == nil {
+		return nil
+	}
 
+	result, err := s.cash.CheckOpenSessions(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Int("notified", result.Notified).
+		Int("auto_closed", result.AutoClosed).
+		Int("failed", result.Failed).
+		Msg("Open cash sessions checked")
+	SetItemsProcessed(ctx, result.Notified+result.AutoClosed)
 	return nil
 }
 
@@ -399,7 +671,7 @@ func (s *JobService) SendOverdueNotifications(ctx context.Context) error {
 				Type:          "loan_overdue",
 				Title:         "Préstamo Vencido - Riesgo de Confiscación",
 				Message:       message,
-				Channel:       "sms",
+				Channel:       customer.ReminderChannel(),
 				ReferenceType: func() *string { t := "loan"; return &t }(),
 				ReferenceID:   &loan.ID,
 			})
@@ -413,6 +685,87 @@ func (s *JobService) SendOverdueNotifications(ctx context.Context) error {
 	}
 
 	s.logger.Info().Int("notifications_sent", notificationsSent).Msg("Overdue notification processing completed")
+	SetItemsProcessed(ctx, notificationsSent)
+	return nil
+}
+
+// DispatchNotifications drains the queue of customer notifications in
+// batches, slowing down channels whose provider is throttling
+func (s *JobService) DispatchNotifications(ctx context.Context) error {
+	if s.dispatcher == nil {
+		return nil
+	}
+
+	result, err := s.dispatcher.Drain(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Int("sent", result.Sent).
+		Int("failed", result.Failed).
+		Int("cancelled", result.Cancelled).
+		Int("skipped", result.Skipped).
+		Int("throttled", result.Throttled).
+		Int("deferred", result.Deferred).
+		Int("batches", result.Batches).
+		Float64("per_second", result.PerSecond).
+		Msg("Notification dispatch completed")
+	SetItemsProcessed(ctx, result.Sent+result.Failed+result.Cancelled)
+	return nil
+}
+
+// RetryFailedNotifications queues failed notifications again once their
+// exponential backoff has elapsed
+func (s *JobService) RetryFailedNotifications(ctx context.Context) error {
+	notifications, err := s.notificationService.GetFailedNotifications(ctx, 100)
+	if err != nil {
+		return err
+	}
+
+	retried := 0
+	for _, notification := range notifications {
+		if err := s.notificationService.RetryNotification(ctx, notification.ID); err != nil {
+			s.logger.Error().Err(err).Int64("notification_id", notification.ID).Msg("Failed to retry notification")
+			continue
+		}
+		retried++
+	}
+
+	s.logger.Info().Int("retried", retried).Msg("Failed notification retry completed")
+	SetItemsProcessed(ctx, retried)
+	return nil
+}
+
+// SendNotificationDigests bundles the internal notifications held for users
+// in digest mode into their summary once their interval is up
+func (s *JobService) SendNotificationDigests(ctx context.Context) error {
+	if s.digests == nil {
+		return nil
+	}
+
+	sent, err := s.digests.SendDue(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("sent", sent).Msg("Notification digests sent")
+	SetItemsProcessed(ctx, sent)
+	return nil
+}
+
+// CheckJobHealth flags critical jobs that stopped succeeding and alerts administrators
+func (s *JobService) CheckJobHealth(ctx context.Context) error {
+	if s.jobMonitor == nil {
+		return nil
+	}
+
+	stale, err := s.jobMonitor.CheckStaleJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	SetItemsProcessed(ctx, len(stale))
 	return nil
 }
 
@@ -477,6 +830,7 @@ func RegisterDefaultJobs(scheduler *Scheduler, jobService *JobService) {
 		Schedule: "every:1m",
 		Handler:  jobService.ProcessOverdueLoans,
 		Enabled:  true,
+		Critical: true,
 	})
 
 	// Calculate late fees - run every minute (dev: every:1m, prod: every:6h)
@@ -485,17 +839,12 @@ func RegisterDefaultJobs(scheduler *Scheduler, jobService *JobService) {
 		Schedule: "every:1m",
 		Handler:  jobService.CalculateLateFeesJob,
 		Enabled:  true,
+		Critical: true,
 	})
 
-	// Calculate daily interest - DISABLED for pawnshop model (simple interest)
-	// In pawnshops, interest is calculated once at loan creation, not daily
-	// Only late fees (mora) accumulate when overdue
-	scheduler.AddJob(&Job{
-		Name:     "calculate_daily_interest",
-		Schedule: "every:1m",
-		Handler:  jobService.CalculateDailyInterest,
-		Enabled:  false, // Disabled: pawnshop uses simple interest model
-	})
+	// Interest is not accrued by a job: it is set for the term when the loan is
+	// made, and Loan.AccruedInterestAt reads what its interest method earned
+	// by a given day. Only late fees (mora) accumulate when overdue.
 
 	// Send due date reminders - run every day
 	scheduler.AddJob(&Job{
@@ -505,6 +854,30 @@ func RegisterDefaultJobs(scheduler *Scheduler, jobService *JobService) {
 		Enabled:  true,
 	})
 
+	// Send final warning before confiscation - run every day
+	scheduler.AddJob(&Job{
+		Name:     "send_confiscation_reminders",
+		Schedule: "every:1m",
+		Handler:  jobService.SendConfiscationReminders,
+		Enabled:  true,
+	})
+
+	// Send last month's statements to opted-in customers - run every 6 hours
+	scheduler.AddJob(&Job{
+		Name:     "send_customer_statements",
+		Schedule: "every:6h",
+		Handler:  jobService.SendCustomerStatements,
+		Enabled:  true,
+	})
+
+	// Mark down items that stay for sale too long - run every hour
+	scheduler.AddJob(&Job{
+		Name:     "mark_down_aging_inventory",
+		Schedule: "every:1h",
+		Handler:  jobService.MarkDownAgingInventory,
+		Enabled:  true,
+	})
+
 	// Send overdue notifications - run every day
 	scheduler.AddJob(&Job{
 		Name:     "send_overdue_notifications",
@@ -513,6 +886,31 @@ func RegisterDefaultJobs(scheduler *Scheduler, jobService *JobService) {
 		Enabled:  true,
 	})
 
+	// Dispatch queued notifications - run every minute
+	scheduler.AddJob(&Job{
+		Name:     "dispatch_notifications",
+		Schedule: "every:1m",
+		Handler:  jobService.DispatchNotifications,
+		Enabled:  true,
+		Critical: true,
+	})
+
+	// Retry failed notifications whose backoff has elapsed - run every 5 minutes
+	scheduler.AddJob(&Job{
+		Name:     "retry_failed_notifications",
+		Schedule: "every:5m",
+		Handler:  jobService.RetryFailedNotifications,
+		Enabled:  true,
+	})
+
+	// Check critical jobs keep succeeding - run every 5 minutes
+	scheduler.AddJob(&Job{
+		Name:     "check_job_health",
+		Schedule: "every:5m",
+		Handler:  jobService.CheckJobHealth,
+		Enabled:  true,
+	})
+
 	// Cleanup expired sessions - run every day
 	scheduler.AddJob(&Job{
 		Name:     "cleanup_expired_sessions",
@@ -521,6 +919,73 @@ func RegisterDefaultJobs(scheduler *Scheduler, jobService *JobService) {
 		Enabled:  true,
 	})
 
+	// Record yesterday's daily balances - run every hour
+	scheduler.AddJob(&Job{
+		Name:     "record_daily_balances",
+		Schedule: "every:1h",
+		Handler:  jobService.RecordDailyBalances,
+		Enabled:  true,
+	})
+
+	// Snapshot open loan balances for historical reports - run every hour
+	scheduler.AddJob(&Job{
+		Name:     "record_loan_balance_snapshots",
+		Schedule: "every:1h",
+		Handler:  jobService.RecordLoanBalanceSnapshots,
+		Enabled:  true,
+	})
+
+	// Refresh the collections queue - run every hour
+	scheduler.AddJob(&Job{
+		Name:     "refresh_collections_queue",
+		Schedule: "every:1h",
+		Handler:  jobService.RefreshCollectionsQueue,
+		Enabled:  true,
+	})
+
+	// Write queued exports - run every minute
+	scheduler.AddJob(&Job{
+		Name:     "process_export_jobs",
+		Schedule: "every:1m",
+		Handler:  jobService.ProcessExportJobs,
+		Enabled:  true,
+	})
+
+	// Check for cash sessions left open too long - run every 15 minutes
+	scheduler.AddJob(&Job{
+		Name:     "check_open_cash_sessions",
+		Schedule: "every:15m",
+		Handler:  jobService.CheckOpenCashSessions,
+		Enabled:  true,
+	})
+
+	// Send due internal notification digests - run every 15 minutes
+	scheduler.AddJob(&Job{
+		Name:     "send_notification_digests",
+		Schedule: "every:15m",
+		Handler:  jobService.SendNotificationDigests,
+		Enabled:  true,
+	})
+
+	// Back up the database and prune old backups - run at the configured time
+	if jobService.backups != nil {
+		scheduler.AddJob(&Job{
+			Name:     "create_scheduled_backup",
+			Schedule: jobService.backupSchedule,
+			Handler:  jobService.CreateScheduledBackup,
+			Enabled:  true,
+			Critical: true,
+		})
+	}
+
+	// Test-restore the latest backup into the scratch database - run every day
+	scheduler.AddJob(&Job{
+		Name:     "verify_latest_backup",
+		Schedule: "every:24h",
+		Handler:  jobService.VerifyLatestBackup,
+		Enabled:  true,
+	})
+
 	// Generate daily report - run every day
 	scheduler.AddJob(&Job{
 		Name:     "generate_daily_report",
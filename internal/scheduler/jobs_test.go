@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+	"pawnshop/internal/service"
+)
+
+func TestJobService_ProcessOverdueLoans_LegalHoldBlocksConfiscation(t *testing.T) {
+	ctx := context.Background()
+	loanRepo := new(mocks.MockLoanRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, service.SettingLoanLegalHoldTag, mock.Anything).
+		Return(nil, errors.New("setting not found"))
+
+	dueDate := domain.Date{Time: time.Now().AddDate(0, 0, -30)}
+	held := &domain.Loan{ID: 1, LoanNumber: "LN-000001", BranchID: 1, ItemID: 10,
+		Status: domain.LoanStatusOverdue, DueDate: dueDate, GracePeriodDays: 5, Tags: []string{"Legal_Hold"}}
+	free := &domain.Loan{ID: 2, LoanNumber: "LN-000002", BranchID: 1, ItemID: 20,
+		Status: domain.LoanStatusOverdue, DueDate: dueDate, GracePeriodDays: 5, Tags: []string{"vip"}}
+	loanRepo.On("GetOverdueLoans", ctx, int64(0)).Return([]*domain.Loan{held, free}, nil)
+	loanRepo.On("UpdateStatus", ctx, int64(2), domain.LoanStatusConfiscated).Return(nil).Once()
+	itemRepo.On("UpdateStatus", ctx, int64(20), domain.ItemStatusForSale).Return(nil).Once()
+
+	jobs := NewJobService(loanRepo, itemRepo, nil, nil, nil, nil, nil, zerolog.Nop())
+	jobs.SetSettings(settingRepo)
+
+	require.NoError(t, jobs.ProcessOverdueLoans(ctx))
+	loanRepo.AssertNotCalled(t, "UpdateStatus", ctx, int64(1), mock.Anything)
+	itemRepo.AssertNotCalled(t, "UpdateStatus", ctx, int64(10), mock.Anything)
+	loanRepo.AssertExpectations(t)
+	itemRepo.AssertExpectations(t)
+}
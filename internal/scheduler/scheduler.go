@@ -2,10 +2,15 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/pkg/logger"
 )
 
 // Job represents a scheduled job
@@ -14,6 +19,12 @@ type Job struct {
 	Schedule string // cron-like: "daily@02:00", "hourly", "every:5m"
 	Handler  func(ctx context.Context) error
 	Enabled  bool
+	Critical bool // alert when it stops succeeding within its interval
+}
+
+// RunRecorder persists the outcome of each job run
+type RunRecorder interface {
+	RecordRun(ctx context.Context, run *domain.JobRun) error
 }
 
 // Scheduler manages scheduled jobs
@@ -25,14 +36,30 @@ type Scheduler struct {
 	cancel  context.CancelFunc
 	running bool
 	mu      sync.Mutex
+
+	recorder RunRecorder
 }
 
 type jobRunner struct {
 	job      *Job
+	interval time.Duration
 	ticker   *time.Ticker
 	stopChan chan struct{}
 }
 
+type runStatsKey struct{}
+
+type runStats struct {
+	itemsProcessed int
+}
+
+// SetItemsProcessed reports how many items the running job handled, recorded with its run
+func SetItemsProcessed(ctx context.Context, count int) {
+	if stats, ok := ctx.Value(runStatsKey{}).(*runStats); ok {
+		stats.itemsProcessed = count
+	}
+}
+
 // New creates a new Scheduler
 func New(logger zerolog.Logger) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -44,6 +71,11 @@ func New(logger zerolog.Logger) *Scheduler {
 	}
 }
 
+// SetRecorder enables persisting the outcome of every job run
+func (s *Scheduler) SetRecorder(recorder RunRecorder) {
+	s.recorder = recorder
+}
+
 // AddJob adds a job to the scheduler
 func (s *Scheduler) AddJob(job *Job) {
 	s.mu.Lock()
@@ -62,6 +94,7 @@ func (s *Scheduler) AddJob(job *Job) {
 
 	runner := &jobRunner{
 		job:      job,
+		interval: interval,
 		ticker:   time.NewTicker(interval),
 		stopChan: make(chan struct{}),
 	}
@@ -117,13 +150,26 @@ func (s *Scheduler) Stop() {
 func (s *Scheduler) runJob(runner *jobRunner) {
 	defer s.wg.Done()
 
-	// Run immediately on start
-	s.executeJob(runner.job)
+	// Jobs pinned to a time of day wait for it; the rest run immediately on start
+	if delay, ok := untilTimeOfDay(runner.job.Schedule, time.Now()); ok {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-runner.stopChan:
+			timer.Stop()
+			return
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+		runner.ticker.Reset(runner.interval)
+	}
+	s.executeJob(runner)
 
 	for {
 		select {
 		case <-runner.ticker.C:
-			s.executeJob(runner.job)
+			s.executeJob(runner)
 		case <-runner.stopChan:
 			return
 		case <-s.ctx.Done():
@@ -132,30 +178,78 @@ func (s *Scheduler) runJob(runner *jobRunner) {
 	}
 }
 
-func (s *Scheduler) executeJob(job *Job) {
+func (s *Scheduler) executeJob(runner *jobRunner) {
+	job := runner.job
 	start := time.Now()
-	s.logger.Info().Str("job", job.Name).Msg("Starting job execution")
+
+	// Each run gets its own correlation ID, carried in the context like an HTTP request ID
+	requestID := "job-" + uuid.New().String()
+	log := s.logger.With().Str("job", job.Name).Str("request_id", requestID).Logger()
+	log.Info().Msg("Starting job execution")
 
 	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Minute)
 	defer cancel()
+	ctx = logger.WithRequestID(ctx, requestID)
+	stats := &runStats{}
+	ctx = context.WithValue(ctx, runStatsKey{}, stats)
+
+	err := job.Handler(ctx)
+	s.recordRun(runner, start, stats, err)
 
-	if err := job.Handler(ctx); err != nil {
-		s.logger.Error().
+	if err != nil {
+		log.Error().
 			Err(err).
-			Str("job", job.Name).
 			Dur("duration", time.Since(start)).
 			Msg("Job execution failed")
 		return
 	}
 
-	s.logger.Info().
-		Str("job", job.Name).
+	log.Info().
 		Dur("duration", time.Since(start)).
+		Int("items_processed", stats.itemsProcessed).
 		Msg("Job execution completed")
 }
 
+// recordRun persists the run outcome; recording failures never fail the job
+func (s *Scheduler) recordRun(runner *jobRunner, start time.Time, stats *runStats, jobErr error) {
+	if s.recorder == nil {
+		return
+	}
+
+	finished := time.Now()
+	run := &domain.JobRun{
+		JobName:         runner.job.Name,
+		Status:          domain.JobRunStatusSuccess,
+		ItemsProcessed:  stats.itemsProcessed,
+		StartedAt:       start,
+		FinishedAt:      finished,
+		DurationMs:      finished.Sub(start).Milliseconds(),
+		IntervalSeconds: int64(runner.interval.Seconds()),
+		Critical:        runner.job.Critical,
+	}
+	if jobErr != nil {
+		message := jobErr.Error()
+		run.Status = domain.JobRunStatusFailed
+		run.ErrorMessage = &message
+	}
+
+	// Use a fresh context so runs that hit their timeout are still recorded
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.recorder.RecordRun(ctx, run); err != nil {
+		s.logger.Error().Err(err).Str("job", runner.job.Name).Msg("Failed to record job run")
+	}
+}
+
 // parseSchedule parses schedule strings like "daily@02:00", "hourly", "every:5m"
 func parseSchedule(schedule string) (time.Duration, error) {
+	if strings.HasPrefix(schedule, "daily@") {
+		if _, _, err := parseTimeOfDay(schedule[6:]); err != nil {
+			return 0, err
+		}
+		return 24 * time.Hour, nil
+	}
+
 	switch schedule {
 	case "hourly":
 		return time.Hour, nil
@@ -170,3 +264,30 @@ func parseSchedule(schedule string) (time.Duration, error) {
 		return 24 * time.Hour, nil
 	}
 }
+
+// untilTimeOfDay returns how long to wait from now for the next run of a
+// "daily@HH:MM" schedule; other schedules are not pinned to a time of day
+func untilTimeOfDay(schedule string, now time.Time) (time.Duration, bool) {
+	if !strings.HasPrefix(schedule, "daily@") {
+		return 0, false
+	}
+	hour, minute, err := parseTimeOfDay(schedule[6:])
+	if err != nil {
+		return 0, false
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next.Sub(now), true
+}
+
+// parseTimeOfDay parses a 24-hour "HH:MM" time
+func parseTimeOfDay(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+	return t.Hour(), t.Minute(), nil
+}
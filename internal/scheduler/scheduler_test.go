@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+)
+
+type recordedRuns struct {
+	runs []*domain.JobRun
+}
+
+func (r *recordedRuns) RecordRun(ctx context.Context, run *domain.JobRun) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func newTestRunner(job *Job) *jobRunner {
+	return &jobRunner{job: job, interval: time.Minute}
+}
+
+func TestScheduler_ExecuteJob_RecordsSuccess(t *testing.T) {
+	sched := New(zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	recorder := &recordedRuns{}
+	sched.SetRecorder(recorder)
+
+	sched.executeJob(newTestRunner(&Job{
+		Name:     "process_overdue_loans",
+		Critical: true,
+		Handler: func(ctx context.Context) error {
+			SetItemsProcessed(ctx, 4)
+			return nil
+		},
+	}))
+
+	require.Len(t, recorder.runs, 1)
+	run := recorder.runs[0]
+	assert.Equal(t, "process_overdue_loans", run.JobName)
+	assert.Equal(t, domain.JobRunStatusSuccess, run.Status)
+	assert.Nil(t, run.ErrorMessage)
+	assert.Equal(t, 4, run.ItemsProcessed)
+	assert.Equal(t, int64(60), run.IntervalSeconds)
+	assert.True(t, run.Critical)
+	assert.False(t, run.FinishedAt.Before(run.StartedAt))
+}
+
+func TestScheduler_ExecuteJob_RecordsFailure(t *testing.T) {
+	sched := New(zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	recorder := &recordedRuns{}
+	sched.SetRecorder(recorder)
+
+	sched.executeJob(newTestRunner(&Job{
+		Name: "dispatch_notifications",
+		Handler: func(ctx context.Context) error {
+			return errors.New("database unavailable")
+		},
+	}))
+
+	require.Len(t, recorder.runs, 1)
+	run := recorder.runs[0]
+	assert.Equal(t, domain.JobRunStatusFailed, run.Status)
+	require.NotNil(t, run.ErrorMessage)
+	assert.Equal(t, "database unavailable", *run.ErrorMessage)
+}
+
+func TestUntilTimeOfDay(t *testing.T) {
+	now := time.Date(2026, 10, 16, 1, 30, 0, 0, time.UTC)
+
+	delay, ok := untilTimeOfDay("daily@02:00", now)
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Minute, delay)
+
+	// Past today's time, the next run is tomorrow
+	delay, ok = untilTimeOfDay("daily@01:00", now)
+	require.True(t, ok)
+	assert.Equal(t, 23*time.Hour+30*time.Minute, delay)
+
+	_, ok = untilTimeOfDay("every:24h", now)
+	assert.False(t, ok)
+
+	_, err := parseSchedule("daily@25:00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+var (
+	ErrInvalidAccountingPeriod = errors.New("invalid accounting period, expected YYYY-MM")
+	ErrAccountingPeriodClosed  = errors.New("accounting period is already closed")
+)
+
+// SettingPeriodClosePostAccruals controls whether closing a period books its
+// accruals when the request does not say
+const SettingPeriodClosePostAccruals = "period_close_post_accruals"
+
+// Accounts the period-end accruals are booked to
+const (
+	accrualInterestReceivableCode = "1220" // Intereses por Cobrar
+	accrualInterestIncomeCode     = "4100" // Ingresos por Intereses
+	accrualLateFeeReceivableCode  = "1200" // Cuentas por Cobrar
+	accrualLateFeeIncomeCode      = "4200" // Ingresos por Mora
+)
+
+// accrualLoanPageSize is how many open loans are read per query when computing accruals
+const accrualLoanPageSize = 100
+
+// AccountingPeriodService closes monthly accounting periods and books their
+// period-end accruals of interest and late fees earned but not yet collected
+type AccountingPeriodService struct {
+	periodRepo  repository.AccountingPeriodRepository
+	accountRepo repository.AccountRepository
+	entryRepo   repository.AccountingEntryRepository
+	loanRepo    repository.LoanRepository
+	settingRepo repository.SettingRepository
+
+	accountingService *AccountingService
+}
+
+// NewAccountingPeriodService creates a new AccountingPeriodService
+func NewAccountingPeriodService(
+	periodRepo repository.AccountingPeriodRepository,
+	accountRepo repository.AccountRepository,
+	entryRepo repository.AccountingEntryRepository,
+	loanRepo repository.LoanRepository,
+	settingRepo repository.SettingRepository,
+) *AccountingPeriodService {
+	return &AccountingPeriodService{
+		periodRepo:  periodRepo,
+		accountRepo: accountRepo,
+		entryRepo:   entryRepo,
+		loanRepo:    loanRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// AccrualInput represents a request to book a period's accruals
+type AccrualInput struct {
+	BranchID int64  `json:"branch_id" validate:"required"`
+	Period   string `json:"period" validate:"required"` // YYYY-MM
+	UserID   int64  `json:"-"`
+}
+
+// ClosePeriodInput represents a request to close an accounting period
+type ClosePeriodInput struct {
+	BranchID     int64  `json:"branch_id" validate:"required"`
+	Period       string `json:"period" validate:"required"` // YYYY-MM
+	PostAccruals *bool  `json:"post_accruals"`              // Defaults to the branch setting
+	UserID       int64  `json:"-"`
+}
+
+// List retrieves accounting periods, optionally for a single branch
+func (s *AccountingPeriodService) List(ctx context.Context, branchID *int64) ([]*domain.AccountingPeriod, error) {
+	return s.periodRepo.List(ctx, branchID)
+}
+
+// GenerateAccruals books the period-end accruals of a branch. It is idempotent:
+// a period whose accruals were already booked is returned unchanged.
+func (s *AccountingPeriodService) GenerateAccruals(ctx context.Context, input AccrualInput) (*domain.AccountingPeriod, error) {
+	period, err := s.openPeriod(ctx, input.BranchID, input.Period)
+	if err != nil {
+		return nil, err
+	}
+	if period.HasAccruals() {
+		return period, nil
+	}
+	if period.IsClosed() {
+		return nil, ErrAccountingPeriodClosed
+	}
+
+	if err := s.postAccruals(ctx, period, input.UserID); err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// ClosePeriod closes a branch's accounting period, booking its accruals first
+// when requested or, if the request does not say, when the branch setting asks for it.
+// The branch inventory is reconciled to accounting beforehand.
+func (s *AccountingPeriodService) ClosePeriod(ctx context.Context, input ClosePeriodInput) (*domain.AccountingPeriod, error) {
+	period, err := s.openPeriod(ctx, input.BranchID, input.Period)
+	if err != nil {
+		return nil, err
+	}
+	if period.IsClosed() {
+		return nil, ErrAccountingPeriodClosed
+	}
+	if err := s.checkInventory(ctx, period); err != nil {
+		return nil, err
+	}
+
+	postAccruals := getSettingBool(ctx, s.settingRepo, SettingPeriodClosePostAccruals, &input.BranchID, true)
+	if input.PostAccruals != nil {
+		postAccruals = *input.PostAccruals
+	}
+	if postAccruals && !period.HasAccruals() {
+		if err := s.postAccruals(ctx, period, input.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.periodRepo.Close(ctx, period.ID, input.UserID); err != nil {
+		return nil, fmt.Errorf("failed to close accounting period: %w", err)
+	}
+	now := time.Now()
+	period.Status = domain.AccountingPeriodClosed
+	period.ClosedAt = &now
+	period.ClosedBy = &input.UserID
+
+	return period, nil
+}
+
+// openPeriod parses the period and loads it, opening it if needed
+func (s *AccountingPeriodService) openPeriod(ctx context.Context, branchID int64, label string) (*domain.AccountingPeriod, error) {
+	year, month, err := domain.ParseAccountingPeriod(label)
+	if err != nil {
+		return nil, ErrInvalidAccountingPeriod
+	}
+	period, err := s.periodRepo.GetOrCreate(ctx, branchID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// postAccruals books the change in accrued-but-unpaid interest and late fees
+// since the previous period as a single balanced, posted journal entry dated on
+// the last day of the period. Booking only the change keeps the receivable
+// accounts equal to the amounts accrued at period end.
+func (s *AccountingPeriodService) postAccruals(ctx context.Context, period *domain.AccountingPeriod, userID int64) error {
+	interest, lateFees, err := s.computeAccruals(ctx, period)
+	if err != nil {
+		return err
+	}
+
+	var prevInterest, prevLateFees float64
+	prevYear, prevMonth := period.Previous()
+	prev, err := s.periodRepo.Get(ctx, period.BranchID, prevYear, prevMonth)
+	if err != nil {
+		return fmt.Errorf("failed to load previous accounting period: %w", err)
+	}
+	if prev != nil && prev.HasAccruals() {
+		prevInterest, prevLateFees = prev.AccruedInterest, prev.AccruedLateFees
+	}
+
+	var lines []*domain.AccountingEntryLine
+	for _, accrual := range []struct {
+		change                     float64
+		receivableCode, incomeCode string
+		description                string
+	}{
+		{roundCents(interest - prevInterest), accrualInterestReceivableCode, accrualInterestIncomeCode, "Intereses devengados no cobrados"},
+		{roundCents(lateFees - prevLateFees), accrualLateFeeReceivableCode, accrualLateFeeIncomeCode, "Mora devengada no cobrada"},
+	} {
+		accrualLines, err := s.accrualLines(ctx, accrual.change, accrual.receivableCode, accrual.incomeCode, accrual.description)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, accrualLines...)
+	}
+
+	period.AccruedInterest = interest
+	period.AccruedLateFees = lateFees
+	var entry *domain.AccountingEntry
+	if len(lines) > 0 {
+		entry, err = s.buildEntry(ctx, period, lines, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The entry is booked posted together with the period, so a failure leaves
+	// neither behind
+	saved, err := s.periodRepo.SaveAccruals(ctx, period, entry)
+	if err != nil {
+		return fmt.Errorf("failed to save accruals: %w", err)
+	}
+	if !saved {
+		return fmt.Errorf("accruals for period %s were booked concurrently", period.Label())
+	}
+	return nil
+}
+
+// computeAccruals sums the interest and late fees earned by the loans the
+// branch had open at the end of the period but not yet paid
+func (s *AccountingPeriodService) computeAccruals(ctx context.Context, period *domain.AccountingPeriod) (float64, float64, error) {
+	end := period.EndDate()
+	openOn := end.Format(domain.DateFormat)
+	var interest, lateFees float64
+	for page := 1; ; page++ {
+		result, err := s.loanRepo.List(ctx, repository.LoanListParams{
+			PaginationParams: repository.PaginationParams{Page: page, PerPage: accrualLoanPageSize},
+			BranchID:         period.BranchID,
+			OpenOn:           &openOn,
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list open loans: %w", err)
+		}
+		for i := range result.Data {
+			loan := &result.Data[i]
+			interest += loan.AccruedInterestAt(end)
+			lateFees += loan.ProjectedLateFeeAt(end.Time)
+		}
+		if page >= result.TotalPages || len(result.Data) == 0 {
+			break
+		}
+	}
+	return roundCents(interest), roundCents(lateFees), nil
+}
+
+// accrualLines returns the debit and credit lines booking a change in an accrued
+// amount: receivable against income, or the reverse when the accrual shrank
+func (s *AccountingPeriodService) accrualLines(ctx context.Context, change float64, receivableCode, incomeCode, description string) ([]*domain.AccountingEntryLine, error) {
+	if change == 0 {
+		return nil, nil
+	}
+
+	receivable, err := s.accountRepo.GetByCode(ctx, receivableCode)
+	if err != nil || receivable == nil {
+		return nil, fmt.Errorf("account %s not found", receivableCode)
+	}
+	income, err := s.accountRepo.GetByCode(ctx, incomeCode)
+	if err != nil || income == nil {
+		return nil, fmt.Errorf("account %s not found", incomeCode)
+	}
+
+	debit, credit := receivable, income
+	if change < 0 {
+		debit, credit = income, receivable
+	}
+	amount := math.Abs(change)
+	return []*domain.AccountingEntryLine{
+		{AccountID: debit.ID, EntryType: domain.EntryTypeDebit, Amount: amount, Description: description},
+		{AccountID: credit.ID, EntryType: domain.EntryTypeCredit, Amount: amount, Description: description},
+	}, nil
+}
+
+// buildEntry builds the period's accrual entry, ready to be stored posted
+func (s *AccountingPeriodService) buildEntry(ctx context.Context, period *domain.AccountingPeriod, lines []*domain.AccountingEntryLine, userID int64) (*domain.AccountingEntry, error) {
+	number, err := s.entryRepo.GenerateEntryNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate entry number: %w", err)
+	}
+
+	entry := &domain.AccountingEntry{
+		EntryNumber:   number,
+		BranchID:      period.BranchID,
+		EntryDate:     period.EndDate().Time,
+		Description:   "Devengo de cierre del período " + period.Label(),
+		ReferenceType: domain.AccountingReferencePeriod,
+		ReferenceID:   &period.ID,
+		Lines:         lines,
+		IsPosted:      true,
+	}
+	if userID != 0 {
+		entry.CreatedBy = &userID
+		entry.PostedBy = &userID
+	}
+	for _, line := range lines {
+		if line.EntryType == domain.EntryTypeDebit {
+			entry.TotalDebit += line.Amount
+		} else {
+			entry.TotalCredit += line.Amount
+		}
+	}
+	entry.TotalDebit = roundCents(entry.TotalDebit)
+	entry.TotalCredit = roundCents(entry.TotalCredit)
+
+	return entry, nil
+}
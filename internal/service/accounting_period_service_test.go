@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+type accountingPeriodMocks struct {
+	periodRepo  *mocks.MockAccountingPeriodRepository
+	accountRepo *mocks.MockAccountRepository
+	entryRepo   *mocks.MockAccountingEntryRepository
+	loanRepo    *mocks.MockLoanRepository
+	settingRepo *mocks.MockSettingRepository
+}
+
+func setupAccountingPeriodService() (*AccountingPeriodService, accountingPeriodMocks) {
+	m := accountingPeriodMocks{
+		periodRepo:  new(mocks.MockAccountingPeriodRepository),
+		accountRepo: new(mocks.MockAccountRepository),
+		entryRepo:   new(mocks.MockAccountingEntryRepository),
+		loanRepo:    new(mocks.MockLoanRepository),
+		settingRepo: new(mocks.MockSettingRepository),
+	}
+	service := NewAccountingPeriodService(m.periodRepo, m.accountRepo, m.entryRepo, m.loanRepo, m.settingRepo)
+	return service, m
+}
+
+func accrualFixtures(m accountingPeriodMocks) *domain.AccountingPeriod {
+	ctx := context.Background()
+
+	// Half-way through its term at the end of March: 150 of 300 interest accrued
+	current := domain.Loan{
+		ID: 1, BranchID: 1, Status: domain.LoanStatusActive, LoanAmount: 1000,
+		InterestAmount: 300, InterestRemaining: 300, LoanTermDays: 30,
+		StartDate: domain.NewDate(2024, 3, 16), DueDate: domain.NewDate(2024, 4, 15),
+	}
+	// Term over: all 200 interest accrued, plus 29 days of 1% late fees on 1000
+	overdue := domain.Loan{
+		ID: 2, BranchID: 1, Status: domain.LoanStatusOverdue, LoanAmount: 1000,
+		InterestAmount: 200, InterestRemaining: 200, LoanTermDays: 30, LateFeeRate: 1,
+		StartDate: domain.NewDate(2024, 2, 1), DueDate: domain.NewDate(2024, 3, 2),
+	}
+	m.loanRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.LoanListParams) bool {
+		return p.OpenOn != nil && *p.OpenOn == "2024-03-31" && p.Status == nil
+	})).Return(&repository.PaginatedResult[domain.Loan]{Data: []domain.Loan{current, overdue}, Total: 2, Page: 1, TotalPages: 1}, nil)
+
+	m.accountRepo.On("GetByCode", ctx, "1220").Return(&domain.Account{ID: 3, Code: "1220"}, nil)
+	m.accountRepo.On("GetByCode", ctx, "4100").Return(&domain.Account{ID: 5, Code: "4100"}, nil)
+	m.accountRepo.On("GetByCode", ctx, "1200").Return(&domain.Account{ID: 2, Code: "1200"}, nil)
+	m.accountRepo.On("GetByCode", ctx, "4200").Return(&domain.Account{ID: 6, Code: "4200"}, nil)
+
+	period := &domain.AccountingPeriod{ID: 9, BranchID: 1, Year: 2024, Month: 3, Status: domain.AccountingPeriodOpen}
+	m.periodRepo.On("GetOrCreate", ctx, int64(1), 2024, 3).Return(period, nil)
+	m.periodRepo.On("Get", ctx, int64(1), 2024, 2).Return(nil, nil)
+	m.entryRepo.On("GenerateEntryNumber", ctx).Return("JE-20240331-0001", nil)
+	return period
+}
+
+func TestAccountingPeriodService_GenerateAccruals_BalancedAndIdempotent(t *testing.T) {
+	service, m := setupAccountingPeriodService()
+	ctx := context.Background()
+	period := accrualFixtures(m)
+
+	var entry *domain.AccountingEntry
+	m.periodRepo.On("SaveAccruals", ctx, period, mock.AnythingOfType("*domain.AccountingEntry")).Run(func(args mock.Arguments) {
+		entry = args.Get(2).(*domain.AccountingEntry)
+		entry.ID = 50
+		now := time.Now()
+		saved := args.Get(1).(*domain.AccountingPeriod)
+		saved.AccrualEntryID = &entry.ID
+		saved.AccrualsPostedAt = &now
+	}).Return(true, nil).Once()
+
+	input := AccrualInput{BranchID: 1, Period: "2024-03", UserID: 7}
+	result, err := service.GenerateAccruals(ctx, input)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+
+	var debits, credits float64
+	for _, line := range entry.Lines {
+		if line.EntryType == domain.EntryTypeDebit {
+			debits += line.Amount
+		} else {
+			credits += line.Amount
+		}
+	}
+	assert.Equal(t, debits, credits)
+	assert.Equal(t, 640.0, entry.TotalDebit) // 350 interest + 290 late fees
+	assert.Equal(t, entry.TotalDebit, entry.TotalCredit)
+	assert.Equal(t, domain.AccountingReferencePeriod, entry.ReferenceType)
+	assert.Equal(t, "2024-03-31", entry.EntryDate.Format("2006-01-02"))
+	assert.True(t, entry.IsPosted)
+	assert.Equal(t, int64(7), *entry.PostedBy)
+	assert.Equal(t, 350.0, result.AccruedInterest)
+	assert.Equal(t, 290.0, result.AccruedLateFees)
+	assert.Equal(t, int64(50), *period.AccrualEntryID)
+
+	// Running it again for the same period books nothing new
+	again, err := service.GenerateAccruals(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, period, again)
+	m.periodRepo.AssertNumberOfCalls(t, "SaveAccruals", 1)
+	m.entryRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	m.entryRepo.AssertNotCalled(t, "Post", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountingPeriodService_ClosePeriod_PostsAccruals(t *testing.T) {
+	service, m := setupAccountingPeriodService()
+	ctx := context.Background()
+	period := accrualFixtures(m)
+
+	m.settingRepo.On("Get", ctx, SettingPeriodClosePostAccruals, mock.Anything).Return(nil, errors.New("setting not found"))
+	m.periodRepo.On("SaveAccruals", ctx, period, mock.AnythingOfType("*domain.AccountingEntry")).Run(func(args mock.Arguments) {
+		now := time.Now()
+		args.Get(1).(*domain.AccountingPeriod).AccrualsPostedAt = &now
+	}).Return(true, nil).Once()
+	m.periodRepo.On("Close", ctx, int64(9), int64(7)).Return(nil)
+
+	closed, err := service.ClosePeriod(ctx, ClosePeriodInput{BranchID: 1, Period: "2024-03", UserID: 7})
+
+	require.NoError(t, err)
+	assert.True(t, closed.IsClosed())
+	assert.True(t, closed.HasAccruals())
+	m.periodRepo.AssertExpectations(t)
+
+	_, err = service.ClosePeriod(ctx, ClosePeriodInput{BranchID: 1, Period: "2024-03", UserID: 7})
+	assert.ErrorIs(t, err, ErrAccountingPeriodClosed)
+}
+
+func TestAccountingPeriodService_ClosePeriod_InvalidPeriod(t *testing.T) {
+	service, _ := setupAccountingPeriodService()
+
+	_, err := service.ClosePeriod(context.Background(), ClosePeriodInput{BranchID: 1, Period: "March"})
+
+	assert.ErrorIs(t, err, ErrInvalidAccountingPeriod)
+}
+
+func TestAccountingPeriodService_ClosePeriod_BlockedByInventoryVariance(t *testing.T) {
+	service, m := setupAccountingPeriodService()
+	ctx := context.Background()
+	period := &domain.AccountingPeriod{ID: 9, BranchID: 1, Year: 2024, Month: 3, Status: domain.AccountingPeriodOpen}
+	m.periodRepo.On("GetOrCreate", ctx, int64(1), 2024, 3).Return(period, nil)
+	m.settingRepo.On("Get", ctx, SettingPeriodCloseInventoryCheck, mock.Anything).
+		Return(&domain.Setting{Key: SettingPeriodCloseInventoryCheck, Value: InventoryCheckBlock}, nil)
+	m.settingRepo.On("Get", ctx, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	accountingService := NewAccountingService(m.accountRepo, m.entryRepo)
+	valuationRepo := new(mocks.MockInventoryValuationRepository)
+	accountingService.SetInventoryReconciliation(valuationRepo, m.settingRepo)
+	service.SetInventoryCheck(accountingService)
+	inventoryFixtures(m.accountRepo, m.entryRepo, valuationRepo, period.EndDate().AddDate(0, 0, 1).Add(-time.Nanosecond))
+
+	_, err := service.ClosePeriod(ctx, ClosePeriodInput{BranchID: 1, Period: "2024-03", UserID: 7})
+
+	assert.ErrorIs(t, err, ErrInventoryNotReconciled)
+	assert.Contains(t, err.Error(), "200.00")
+	assert.False(t, period.IsClosed())
+	m.periodRepo.AssertNotCalled(t, "Close", mock.Anything, mock.Anything, mock.Anything)
+	m.entryRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAccountingPeriodService_GenerateAccruals_SaveFailureBooksNothing(t *testing.T) {
+	service, m := setupAccountingPeriodService()
+	ctx := context.Background()
+	period := accrualFixtures(m)
+	m.periodRepo.On("SaveAccruals", ctx, period, mock.AnythingOfType("*domain.AccountingEntry")).Return(false, errors.New("connection reset"))
+
+	_, err := service.GenerateAccruals(ctx, AccrualInput{BranchID: 1, Period: "2024-03", UserID: 7})
+
+	assert.Error(t, err)
+	assert.False(t, period.HasAccruals())
+	m.entryRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
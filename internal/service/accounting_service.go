@@ -0,0 +1,264 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
+	"pawnshop/internal/repository"
+)
+
+// Accounting export formats
+const (
+	// AccountingExportFormatCSV is a generic journal CSV, one row per entry line
+	AccountingExportFormatCSV = "csv"
+	// AccountingExportFormatIIF is a tab-delimited IIF file importable by QuickBooks
+	AccountingExportFormatIIF = "iif"
+)
+
+var (
+	ErrInvalidExportFormat = errors.New("invalid export format, expected csv or iif")
+	ErrInvalidDateRange    = errors.New("invalid date range")
+)
+
+// accountingExportPageSize is the page size used to read entries for an export
+const accountingExportPageSize = 100
+
+// AccountingService handles accounting business logic
+type AccountingService struct {
+	accountRepo   repository.AccountRepository
+	entryRepo     repository.AccountingEntryRepository
+	valuationRepo repository.InventoryValuationRepository
+	settingRepo   repository.SettingRepository
+	pdfGenerator  *pdf.Generator
+}
+
+// NewAccountingService creates a new AccountingService
+func NewAccountingService(
+	accountRepo repository.AccountRepository,
+	entryRepo repository.AccountingEntryRepository,
+) *AccountingService {
+	return &AccountingService{
+		accountRepo: accountRepo,
+		entryRepo:   entryRepo,
+	}
+}
+
+// AccountingExportInput represents accounting export request data
+type AccountingExportInput struct {
+	Format   string
+	DateFrom string
+	DateTo   string
+	BranchID *int64
+}
+
+// AccountingExport is a rendered accounting export file
+type AccountingExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// accountingExportLine is a single entry line resolved to its account
+type accountingExportLine struct {
+	entry   *domain.AccountingEntry
+	account *domain.Account
+	debit   float64
+	credit  float64
+	memo    string
+}
+
+// Export renders posted accounting entries in the date range in the requested format
+func (s *AccountingService) Export(ctx context.Context, input AccountingExportInput) (*AccountingExport, error) {
+	if input.Format == "" {
+		input.Format = AccountingExportFormatCSV
+	}
+	if input.Format != AccountingExportFormatCSV && input.Format != AccountingExportFormatIIF {
+		return nil, ErrInvalidExportFormat
+	}
+
+	from, err := time.Parse("2006-01-02", input.DateFrom)
+	if err != nil {
+		return nil, ErrInvalidDateRange
+	}
+	to, err := time.Parse("2006-01-02", input.DateTo)
+	if err != nil || to.Before(from) {
+		return nil, ErrInvalidDateRange
+	}
+
+	entries, err := s.listPostedEntries(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	accountsByID := make(map[int64]*domain.Account, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	var lines []accountingExportLine
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			account, ok := accountsByID[line.AccountID]
+			if !ok {
+				return nil, fmt.Errorf("account %d not found for entry %s", line.AccountID, entry.EntryNumber)
+			}
+			exportLine := accountingExportLine{entry: entry, account: account, memo: line.Description}
+			if exportLine.memo == "" {
+				exportLine.memo = entry.Description
+			}
+			if line.EntryType == domain.EntryTypeDebit {
+				exportLine.debit = line.Amount
+			} else {
+				exportLine.credit = line.Amount
+			}
+			lines = append(lines, exportLine)
+		}
+	}
+
+	var data []byte
+	contentType := "text/csv"
+	if input.Format == AccountingExportFormatIIF {
+		data, err = writeAccountingIIF(lines)
+		contentType = "text/plain"
+	} else {
+		data, err = writeAccountingCSV(lines)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return &AccountingExport{
+		Filename:    fmt.Sprintf("accounting_%s_%s.%s", input.DateFrom, input.DateTo, input.Format),
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+// listPostedEntries loads every posted entry in the range with its lines, oldest first
+func (s *AccountingService) listPostedEntries(ctx context.Context, input AccountingExportInput) ([]*domain.AccountingEntry, error) {
+	posted := true
+	filter := repository.AccountingEntryFilter{
+		BranchID: input.BranchID,
+		IsPosted: &posted,
+		DateFrom: &input.DateFrom,
+		DateTo:   &input.DateTo,
+		Page:     1,
+		PageSize: accountingExportPageSize,
+	}
+
+	var entries []*domain.AccountingEntry
+	for {
+		page, total, err := s.entryRepo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounting entries: %w", err)
+		}
+		for _, summary := range page {
+			// List does not load lines
+			entry, err := s.entryRepo.GetByID(ctx, summary.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load accounting entry %s: %w", summary.EntryNumber, err)
+			}
+			if entry != nil && entry.IsPosted {
+				entries = append(entries, entry)
+			}
+		}
+		if len(page) == 0 || int64(filter.Page*filter.PageSize) >= total {
+			break
+		}
+		filter.Page++
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].EntryDate.Equal(entries[j].EntryDate) {
+			return entries[i].EntryDate.Before(entries[j].EntryDate)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries, nil
+}
+
+// writeAccountingCSV writes a generic journal CSV, one row per entry line
+func writeAccountingCSV(lines []accountingExportLine) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{
+		"date", "entry_number", "account_code", "account_name",
+		"debit", "credit", "reference_type", "reference_id", "description",
+	})
+	for _, line := range lines {
+		referenceID := ""
+		if line.entry.ReferenceID != nil {
+			referenceID = strconv.FormatInt(*line.entry.ReferenceID, 10)
+		}
+		w.Write([]string{
+			line.entry.EntryDate.Format("2006-01-02"),
+			line.entry.EntryNumber,
+			line.account.Code,
+			line.account.Name,
+			formatExportAmount(line.debit),
+			formatExportAmount(line.credit),
+			line.entry.ReferenceType,
+			referenceID,
+			line.memo,
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// writeAccountingIIF writes a QuickBooks IIF general journal file.
+// Each entry becomes a TRNS row for its first line, SPL rows for the rest and
+// an ENDTRNS marker; debits are positive amounts and credits negative.
+func writeAccountingIIF(lines []accountingExportLine) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = '\t'
+
+	w.Write([]string{"!TRNS", "TRNSTYPE", "DATE", "ACCNT", "AMOUNT", "DOCNUM", "MEMO"})
+	w.Write([]string{"!SPL", "TRNSTYPE", "DATE", "ACCNT", "AMOUNT", "DOCNUM", "MEMO"})
+	w.Write([]string{"!ENDTRNS"})
+
+	for i, line := range lines {
+		first := i == 0 || lines[i-1].entry.ID != line.entry.ID
+		last := i == len(lines)-1 || lines[i+1].entry.ID != line.entry.ID
+
+		rowType := "SPL"
+		if first {
+			rowType = "TRNS"
+		}
+		w.Write([]string{
+			rowType,
+			"GENERAL JOURNAL",
+			line.entry.EntryDate.Format("01/02/2006"),
+			line.account.Code,
+			formatExportAmount(line.debit - line.credit),
+			line.entry.EntryNumber,
+			line.memo,
+		})
+		if last {
+			w.Write([]string{"ENDTRNS"})
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func formatExportAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
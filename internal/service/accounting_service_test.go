@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupAccountingService() (*AccountingService, *mocks.MockAccountRepository, *mocks.MockAccountingEntryRepository) {
+	accountRepo := new(mocks.MockAccountRepository)
+	entryRepo := new(mocks.MockAccountingEntryRepository)
+	service := NewAccountingService(accountRepo, entryRepo)
+	return service, accountRepo, entryRepo
+}
+
+func accountingFixtures(accountRepo *mocks.MockAccountRepository, entryRepo *mocks.MockAccountingEntryRepository) {
+	ctx := context.Background()
+	loanID := int64(42)
+
+	accountRepo.On("List", ctx).Return([]*domain.Account{
+		{ID: 1, Code: "1100", Name: "Caja", AccountType: domain.AccountTypeAsset},
+		{ID: 2, Code: "1200", Name: "Préstamos por cobrar", AccountType: domain.AccountTypeAsset},
+		{ID: 3, Code: "4100", Name: "Ingresos por intereses", AccountType: domain.AccountTypeIncome},
+	}, nil)
+
+	disbursement := &domain.AccountingEntry{
+		ID: 10, EntryNumber: "AE-000010", EntryDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Description: "Desembolso de préstamo", ReferenceType: "loan", ReferenceID: &loanID, IsPosted: true,
+		Lines: []*domain.AccountingEntryLine{
+			{AccountID: 2, EntryType: domain.EntryTypeDebit, Amount: 500},
+			{AccountID: 1, EntryType: domain.EntryTypeCredit, Amount: 500},
+		},
+	}
+	payment := &domain.AccountingEntry{
+		ID: 11, EntryNumber: "AE-000011", EntryDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		Description: "Pago de préstamo", ReferenceType: "payment", IsPosted: true,
+		Lines: []*domain.AccountingEntryLine{
+			{AccountID: 1, EntryType: domain.EntryTypeDebit, Amount: 150},
+			{AccountID: 2, EntryType: domain.EntryTypeCredit, Amount: 100},
+			{AccountID: 3, EntryType: domain.EntryTypeCredit, Amount: 50},
+		},
+	}
+
+	// List returns newest first and without lines
+	entryRepo.On("List", ctx, mock.AnythingOfType("repository.AccountingEntryFilter")).Return([]*domain.AccountingEntry{
+		{ID: 11, EntryNumber: "AE-000011", IsPosted: true},
+		{ID: 10, EntryNumber: "AE-000010", IsPosted: true},
+	}, int64(2), nil)
+	entryRepo.On("GetByID", ctx, int64(10)).Return(disbursement, nil)
+	entryRepo.On("GetByID", ctx, int64(11)).Return(payment, nil)
+}
+
+func TestAccountingService_Export_CSVBalancesPerEntry(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	ctx := context.Background()
+	accountingFixtures(accountRepo, entryRepo)
+
+	export, err := service.Export(ctx, AccountingExportInput{Format: "csv", DateFrom: "2024-03-01", DateTo: "2024-03-31"})
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", export.ContentType)
+	assert.Equal(t, "accounting_2024-03-01_2024-03-31.csv", export.Filename)
+
+	rows, err := csv.NewReader(strings.NewReader(string(export.Data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+	assert.Equal(t, []string{"date", "entry_number", "account_code", "account_name", "debit", "credit", "reference_type", "reference_id", "description"}, rows[0])
+
+	balance := map[string]float64{}
+	var codes []string
+	for _, row := range rows[1:] {
+		debit, err := strconv.ParseFloat(row[4], 64)
+		require.NoError(t, err)
+		credit, err := strconv.ParseFloat(row[5], 64)
+		require.NoError(t, err)
+		balance[row[1]] += debit - credit
+		codes = append(codes, row[2])
+	}
+	assert.InDelta(t, 0, balance["AE-000010"], 0.001)
+	assert.InDelta(t, 0, balance["AE-000011"], 0.001)
+	assert.Equal(t, []string{"1200", "1100", "1100", "1200", "4100"}, codes)
+
+	// Oldest entry first, references carried through
+	assert.Equal(t, []string{"2024-03-01", "AE-000010", "1200", "Préstamos por cobrar", "500.00", "0.00", "loan", "42", "Desembolso de préstamo"}, rows[1])
+}
+
+func TestAccountingService_Export_OnlyPostedEntries(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	ctx := context.Background()
+	accountingFixtures(accountRepo, entryRepo)
+
+	_, err := service.Export(ctx, AccountingExportInput{DateFrom: "2024-03-01", DateTo: "2024-03-31"})
+	require.NoError(t, err)
+
+	filter := entryRepo.Calls[0].Arguments.Get(1).(repository.AccountingEntryFilter)
+	require.NotNil(t, filter.IsPosted)
+	assert.True(t, *filter.IsPosted)
+	assert.Equal(t, "2024-03-01", *filter.DateFrom)
+	assert.Equal(t, "2024-03-31", *filter.DateTo)
+}
+
+func TestAccountingService_Export_IIF(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	ctx := context.Background()
+	accountingFixtures(accountRepo, entryRepo)
+
+	export, err := service.Export(ctx, AccountingExportInput{Format: "iif", DateFrom: "2024-03-01", DateTo: "2024-03-31"})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(export.Data)), "\n")
+	require.Len(t, lines, 3+5+2)
+	assert.True(t, strings.HasPrefix(lines[0], "!TRNS\t"))
+	assert.Equal(t, "TRNS\tGENERAL JOURNAL\t03/01/2024\t1200\t500.00\tAE-000010\tDesembolso de préstamo", lines[3])
+	assert.Equal(t, "SPL\tGENERAL JOURNAL\t03/01/2024\t1100\t-500.00\tAE-000010\tDesembolso de préstamo", lines[4])
+	assert.Equal(t, "ENDTRNS", lines[5])
+	assert.Equal(t, "ENDTRNS", lines[9])
+}
+
+func TestAccountingService_Export_InvalidInput(t *testing.T) {
+	service, _, _ := setupAccountingService()
+	ctx := context.Background()
+
+	_, err := service.Export(ctx, AccountingExportInput{Format: "xml", DateFrom: "2024-03-01", DateTo: "2024-03-31"})
+	assert.ErrorIs(t, err, ErrInvalidExportFormat)
+
+	_, err = service.Export(ctx, AccountingExportInput{Format: "csv", DateFrom: "2024-03-31", DateTo: "2024-03-01"})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
+
+// inventoryFixtures sets up a branch whose inventory accounts hold 2,500 and
+// whose stock on hand is worth 2,300 at cost
+func inventoryFixtures(accountRepo *mocks.MockAccountRepository, entryRepo *mocks.MockAccountingEntryRepository, valuationRepo *mocks.MockInventoryValuationRepository, asOf time.Time) {
+	accountRepo.On("GetByCode", mock.Anything, "1320").Return(&domain.Account{ID: 12, Code: "1320"}, nil)
+	accountRepo.On("ListChildren", mock.Anything, int64(12)).Return([]*domain.Account{{ID: 13, Code: "1321"}}, nil)
+	accountRepo.On("ListChildren", mock.Anything, int64(13)).Return([]*domain.Account{}, nil)
+	entryRepo.On("GetAccountBalanceByBranch", mock.Anything, int64(12), int64(1), asOf).Return(2000.0, nil)
+	entryRepo.On("GetAccountBalanceByBranch", mock.Anything, int64(13), int64(1), asOf).Return(500.0, nil)
+
+	cost := func(v float64) *float64 { return &v }
+	valuationRepo.On("ListOnHand", mock.Anything, int64(1), asOf).Return([]*domain.InventoryItemValue{
+		{ItemID: 1, SKU: "JOY-0001", Status: domain.ItemStatusForSale, AppraisedValue: 1500, AcquisitionPrice: cost(1200)},
+		{ItemID: 2, SKU: "ELE-0002", Status: domain.ItemStatusConfiscated, AppraisedValue: 800},
+		{ItemID: 3, SKU: "HER-0003", Status: domain.ItemStatusAvailable, AppraisedValue: 450, AcquisitionPrice: cost(300)},
+	}, nil)
+}
+
+func TestAccountingService_ReconcileInventory_ReportsVariance(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	valuationRepo := new(mocks.MockInventoryValuationRepository)
+	service.SetInventoryReconciliation(valuationRepo, nil)
+	asOf := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	inventoryFixtures(accountRepo, entryRepo, valuationRepo, asOf)
+
+	result, err := service.ReconcileInventory(context.Background(), 1, asOf)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2500.0, result.AccountBalance)
+	assert.Equal(t, 2300.0, result.ItemsValue)
+	assert.Equal(t, 200.0, result.Variance)
+	assert.False(t, result.Reconciled)
+	require.Len(t, result.Items, 3)
+	assert.Equal(t, int64(1), result.Items[0].ItemID)
+	// The item without an acquisition price is counted at its appraisal
+	assert.Equal(t, 800.0, result.Items[1].Value)
+	assert.True(t, result.Items[1].ValuedAtAppraisal)
+}
+
+func TestAccountingService_ReconcileInventory_AppraisedBasis(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	valuationRepo := new(mocks.MockInventoryValuationRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingInventoryValuationBasis, mock.Anything).
+		Return(&domain.Setting{Key: SettingInventoryValuationBasis, Value: domain.InventoryValuationAppraised}, nil)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+	service.SetInventoryReconciliation(valuationRepo, settingRepo)
+	asOf := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	inventoryFixtures(accountRepo, entryRepo, valuationRepo, asOf)
+
+	result, err := service.ReconcileInventory(context.Background(), 1, asOf)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2750.0, result.ItemsValue)
+	assert.Equal(t, -250.0, result.Variance)
+}
+
+// postedTotals sums entry lines per account the way GetAccountTotals does
+func postedTotals(entries ...*domain.AccountingEntry) []repository.AccountTotals {
+	byAccount := map[int64]*repository.AccountTotals{}
+	var order []int64
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			totals, ok := byAccount[line.AccountID]
+			if !ok {
+				totals = &repository.AccountTotals{AccountID: line.AccountID}
+				byAccount[line.AccountID] = totals
+				order = append(order, line.AccountID)
+			}
+			if line.EntryType == domain.EntryTypeDebit {
+				totals.Debit += line.Amount
+			} else {
+				totals.Credit += line.Amount
+			}
+		}
+	}
+
+	result := make([]repository.AccountTotals, 0, len(order))
+	for _, id := range order {
+		result = append(result, *byAccount[id])
+	}
+	return result
+}
+
+func TestAccountingService_GetTrialBalance(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	ctx := context.Background()
+	asOf := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	branchID := int64(1)
+
+	accountRepo.On("List", ctx).Return([]*domain.Account{
+		{ID: 1, Code: "1100", Name: "Caja", AccountType: domain.AccountTypeAsset},
+		{ID: 2, Code: "1200", Name: "Préstamos por cobrar", AccountType: domain.AccountTypeAsset},
+		{ID: 3, Code: "3100", Name: "Capital", AccountType: domain.AccountTypeEquity},
+		{ID: 4, Code: "4100", Name: "Ingresos por intereses", AccountType: domain.AccountTypeIncome},
+		{ID: 5, Code: "5100", Name: "Gastos de operación", AccountType: domain.AccountTypeExpense},
+		{ID: 6, Code: "1300", Name: "Sin movimientos", AccountType: domain.AccountTypeAsset},
+	}, nil)
+
+	entries := []*domain.AccountingEntry{
+		{Lines: []*domain.AccountingEntryLine{ // Owner's contribution
+			{AccountID: 1, EntryType: domain.EntryTypeDebit, Amount: 1000},
+			{AccountID: 3, EntryType: domain.EntryTypeCredit, Amount: 1000},
+		}},
+		{Lines: []*domain.AccountingEntryLine{ // Loan disbursement
+			{AccountID: 2, EntryType: domain.EntryTypeDebit, Amount: 500},
+			{AccountID: 1, EntryType: domain.EntryTypeCredit, Amount: 500},
+		}},
+		{Lines: []*domain.AccountingEntryLine{ // Payment with interest
+			{AccountID: 1, EntryType: domain.EntryTypeDebit, Amount: 150.10},
+			{AccountID: 2, EntryType: domain.EntryTypeCredit, Amount: 100},
+			{AccountID: 4, EntryType: domain.EntryTypeCredit, Amount: 50.10},
+		}},
+		{Lines: []*domain.AccountingEntryLine{ // Rent
+			{AccountID: 5, EntryType: domain.EntryTypeDebit, Amount: 80},
+			{AccountID: 1, EntryType: domain.EntryTypeCredit, Amount: 80},
+		}},
+	}
+	entryRepo.On("GetAccountTotals", ctx, &branchID, asOf).Return(postedTotals(entries...), nil)
+
+	trialBalance, err := service.GetTrialBalance(ctx, &branchID, asOf)
+	require.NoError(t, err)
+
+	var codes []string
+	balances := map[string]float64{}
+	for _, line := range trialBalance.Lines {
+		codes = append(codes, line.AccountCode)
+		balances[line.AccountCode] = line.Balance
+	}
+	// Grouped by account type, accounts without postings left out
+	assert.Equal(t, []string{"1100", "1200", "3100", "4100", "5100"}, codes)
+	assert.Equal(t, map[string]float64{
+		"1100": 570.10, // 1000 - 500 + 150.10 - 80
+		"1200": 400,
+		"3100": 1000,
+		"4100": 50.10,
+		"5100": 80,
+	}, balances)
+
+	assert.Equal(t, 1730.10, trialBalance.TotalDebit)
+	assert.Equal(t, trialBalance.TotalDebit, trialBalance.TotalCredit)
+	assert.True(t, trialBalance.Balanced)
+}
+
+func TestAccountingService_GetTrialBalance_UnknownAccount(t *testing.T) {
+	service, accountRepo, entryRepo := setupAccountingService()
+	ctx := context.Background()
+	asOf := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	accountRepo.On("List", ctx).Return([]*domain.Account{}, nil)
+	entryRepo.On("GetAccountTotals", ctx, (*int64)(nil), asOf).
+		Return([]repository.AccountTotals{{AccountID: 9, Debit: 10}}, nil)
+
+	_, err := service.GetTrialBalance(ctx, nil, asOf)
+	assert.Error(t, err)
+}
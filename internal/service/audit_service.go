@@ -1,11 +1,19 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
+	"pawnshop/pkg/logger"
 )
 
 // AuditService handles audit log business logic
@@ -30,6 +38,7 @@ func (s *AuditService) LogAction(ctx context.Context, branchID, userID *int64, a
 		NewValues:  newValues,
 		IPAddress:  ipAddress,
 		UserAgent:  userAgent,
+		RequestID:  logger.GetRequestID(ctx),
 	}
 
 	return s.auditRepo.Create(ctx, log)
@@ -48,6 +57,7 @@ func (s *AuditService) LogActionWithDescription(ctx context.Context, branchID, u
 		NewValues:   newValues,
 		IPAddress:   ipAddress,
 		UserAgent:   userAgent,
+		RequestID:   logger.GetRequestID(ctx),
 	}
 
 	return s.auditRepo.Create(ctx, log)
@@ -55,7 +65,196 @@ func (s *AuditService) LogActionWithDescription(ctx context.Context, branchID, u
 
 // List retrieves audit logs with filters
 func (s *AuditService) List(ctx context.Context, params repository.AuditLogListParams) (*repository.PaginatedResult[domain.AuditLog], error) {
-	return s.auditRepo.List(ctx, params)
+	result, err := s.auditRepo.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Data {
+		redactAuditLog(&result.Data[i])
+	}
+	return result, nil
+}
+
+// Audit export formats
+const (
+	AuditExportFormatCSV  = "csv"
+	AuditExportFormatJSON = "json"
+)
+
+var ErrInvalidAuditExportFormat = errors.New("invalid export format, expected csv or json")
+
+// auditExportPageSize is the page size used to read audit logs for an export
+const auditExportPageSize = 500
+
+// auditExportMaxRows caps the rows in a single export; narrower filters are needed beyond it
+const auditExportMaxRows = 50000
+
+// AuditExport is a rendered audit log export file
+type AuditExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Export renders every audit log matching the filters, newest first, as CSV or
+// JSON. Sensitive fields are redacted from the recorded values as in List.
+func (s *AuditService) Export(ctx context.Context, params repository.AuditLogListParams, format string) (*AuditExport, error) {
+	if format != AuditExportFormatCSV && format != AuditExportFormatJSON {
+		return nil, ErrInvalidAuditExportFormat
+	}
+
+	params.Page = 1
+	params.PerPage = auditExportPageSize
+	logs := []domain.AuditLog{}
+	for len(logs) < auditExportMaxRows {
+		result, err := s.List(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audit logs: %w", err)
+		}
+		logs = append(logs, result.Data...)
+		if len(result.Data) == 0 || params.Page >= result.TotalPages {
+			break
+		}
+		params.Page++
+	}
+	if len(logs) > auditExportMaxRows {
+		logs = logs[:auditExportMaxRows]
+	}
+
+	var data []byte
+	var err error
+	contentType := "text/csv"
+	if format == AuditExportFormatJSON {
+		data, err = json.Marshal(logs)
+		contentType = "application/json"
+	} else {
+		data, err = writeAuditCSV(logs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return &AuditExport{
+		Filename:    fmt.Sprintf("audit_%s.%s", time.Now().Format("20060102_150405"), format),
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+// writeAuditCSV writes one row per audit log, with the recorded values as JSON
+func writeAuditCSV(logs []domain.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{
+		"id", "created_at", "user_id", "user_name", "branch_id", "branch_name", "action",
+		"entity_type", "entity_id", "description", "old_values", "new_values", "ip_address", "request_id",
+	})
+	for _, log := range logs {
+		oldValues, err := auditValuesJSON(log.OldValues)
+		if err != nil {
+			return nil, err
+		}
+		newValues, err := auditValuesJSON(log.NewValues)
+		if err != nil {
+			return nil, err
+		}
+		w.Write([]string{
+			strconv.FormatInt(log.ID, 10),
+			log.CreatedAt.Format(time.RFC3339),
+			formatOptionalID(log.UserID),
+			stringValue(log.UserName),
+			formatOptionalID(log.BranchID),
+			stringValue(log.BranchName),
+			log.Action,
+			log.EntityType,
+			formatOptionalID(log.EntityID),
+			stringValue(log.Description),
+			oldValues,
+			newValues,
+			log.IPAddress,
+			log.RequestID,
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func auditValuesJSON(values interface{}) (string, error) {
+	if values == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
+
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// auditRedactedValue replaces sensitive values in audit logs returned to clients
+const auditRedactedValue = "***REDACTED***"
+
+// auditSensitiveKeys are recorded fields never returned as they were written;
+// keys containing password, secret or token are redacted too
+var auditSensitiveKeys = map[string]bool{
+	"pin":          true,
+	"api_key":      true,
+	"backup_codes": true,
+	"otp":          true,
+	"cvv":          true,
+}
+
+// isSensitiveAuditKey checks if a recorded field must be redacted
+func isSensitiveAuditKey(key string) bool {
+	key = strings.ToLower(key)
+	if auditSensitiveKeys[key] {
+		return true
+	}
+	for _, fragment := range []string{"password", "secret", "token"} {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAuditLog redacts sensitive fields from the values recorded in an audit log
+func redactAuditLog(log *domain.AuditLog) {
+	log.OldValues = redactAuditValues(log.OldValues)
+	log.NewValues = redactAuditValues(log.NewValues)
+}
+
+// redactAuditValues replaces sensitive fields at any depth of decoded JSON values
+func redactAuditValues(values interface{}) interface{} {
+	switch v := values.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSensitiveAuditKey(key) {
+				v[key] = auditRedactedValue
+			} else {
+				v[key] = redactAuditValues(value)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactAuditValues(value)
+		}
+		return v
+	}
+	return values
 }
 
 // LogCreate logs a create action
@@ -73,6 +73,80 @@ func TestAuditService_List_Success(t *testing.T) {
 	auditRepo.AssertExpectations(t)
 }
 
+func TestAuditService_List_RedactsSensitiveFields(t *testing.T) {
+	service, auditRepo := setupAuditService()
+	ctx := context.Background()
+
+	params := repository.AuditLogListParams{
+		PaginationParams: repository.PaginationParams{Page: 1, PerPage: 10},
+	}
+
+	result := &repository.PaginatedResult[domain.AuditLog]{
+		Data: []domain.AuditLog{{
+			Action: "update",
+			NewValues: map[string]interface{}{
+				"email":         "ana@example.com",
+				"password_hash": "$2a$10$abc",
+				"settings":      map[string]interface{}{"api_key": "k-123", "theme": "dark"},
+			},
+		}},
+		Total:      1,
+		Page:       1,
+		PerPage:    10,
+		TotalPages: 1,
+	}
+
+	auditRepo.On("List", ctx, params).Return(result, nil)
+
+	res, err := service.List(ctx, params)
+
+	assert.NoError(t, err)
+	values := res.Data[0].NewValues.(map[string]interface{})
+	assert.Equal(t, "ana@example.com", values["email"])
+	assert.Equal(t, auditRedactedValue, values["password_hash"])
+	assert.Equal(t, auditRedactedValue, values["settings"].(map[string]interface{})["api_key"])
+	assert.Equal(t, "dark", values["settings"].(map[string]interface{})["theme"])
+}
+
+func TestAuditService_Export_CSV(t *testing.T) {
+	service, auditRepo := setupAuditService()
+	ctx := context.Background()
+
+	entityID := int64(7)
+	auditRepo.On("List", ctx, mock.MatchedBy(func(p repository.AuditLogListParams) bool {
+		return p.EntityType == "loan" && p.Page == 1
+	})).Return(&repository.PaginatedResult[domain.AuditLog]{
+		Data: []domain.AuditLog{{
+			ID:         1,
+			Action:     "update",
+			EntityType: "loan",
+			EntityID:   &entityID,
+			NewValues:  map[string]interface{}{"token": "secret-token"},
+		}},
+		Total:      1,
+		Page:       1,
+		PerPage:    auditExportPageSize,
+		TotalPages: 1,
+	}, nil)
+
+	export, err := service.Export(ctx, repository.AuditLogListParams{EntityType: "loan"}, AuditExportFormatCSV)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv", export.ContentType)
+	assert.Contains(t, string(export.Data), "update,loan,7")
+	assert.Contains(t, string(export.Data), auditRedactedValue)
+	assert.NotContains(t, string(export.Data), "secret-token")
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAuditService_Export_InvalidFormat(t *testing.T) {
+	service, _ := setupAuditService()
+
+	_, err := service.Export(context.Background(), repository.AuditLogListParams{}, "xml")
+
+	assert.ErrorIs(t, err, ErrInvalidAuditExportFormat)
+}
+
 func TestAuditService_LogCreate(t *testing.T) {
 	service, auditRepo := setupAuditService()
 	ctx := context.Background()
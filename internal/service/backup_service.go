@@ -1,13 +1,18 @@
 package service
 
 import (
+	"bufio"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +20,18 @@ import (
 	"pawnshop/internal/config"
 )
 
+// Backup errors
+var (
+	ErrBackupNotFound         = errors.New("backup file not found")
+	ErrInvalidBackupFilename  = errors.New("invalid filename")
+	ErrBackupChecksumMissing  = errors.New("backup checksum not found")
+	ErrBackupChecksumMismatch = errors.New("backup checksum mismatch")
+)
+
+// backupChecksumSuffix names the file next to each backup holding its SHA-256,
+// in the format of sha256sum
+const backupChecksumSuffix = ".sha256"
+
 // BackupInfo contains information about a backup
 type BackupInfo struct {
 	Filename    string    `json:"filename"`
@@ -22,6 +39,7 @@ type BackupInfo struct {
 	CreatedAt   time.Time `json:"created_at"`
 	Compressed  bool      `json:"compressed"`
 	Description string    `json:"description,omitempty"`
+	Checksum    string    `json:"checksum,omitempty"` // SHA-256 of the backup file
 }
 
 // BackupService defines the interface for backup operations
@@ -29,8 +47,11 @@ type BackupService interface {
 	// CreateBackup creates a new database backup
 	CreateBackup(ctx context.Context, description string) (*BackupInfo, error)
 
-	// RestoreBackup restores a database from a backup file
-	RestoreBackup(ctx context.Context, filename string) error
+	// RestoreBackup restores a database from a backup file after checking it
+	// against the checksum stored when it was created. allowUnverified restores
+	// backups taken before checksums were stored; a checksum that does not
+	// match is always rejected.
+	RestoreBackup(ctx context.Context, filename string, allowUnverified bool) error
 
 	// ListBackups lists all available backups
 	ListBackups(ctx context.Context) ([]*BackupInfo, error)
@@ -46,6 +67,9 @@ type BackupService interface {
 
 	// CleanupOldBackups removes backups older than the retention period
 	CleanupOldBackups(ctx context.Context, retentionDays int) (int, error)
+
+	// PruneBackups keeps the newest backups and deletes the rest
+	PruneBackups(ctx context.Context, keep int) (int, error)
 }
 
 type backupService struct {
@@ -98,8 +122,9 @@ func (s *backupService) CreateBackup(ctx context.Context, description string) (*
 	}
 	defer outFile.Close()
 
-	// Create gzip writer
-	gzWriter := gzip.NewWriter(outFile)
+	// Create gzip writer, hashing the compressed output as it is written
+	hash := sha256.New()
+	gzWriter := gzip.NewWriter(io.MultiWriter(outFile, hash))
 	defer gzWriter.Close()
 
 	// Set comment in gzip header
@@ -130,6 +155,14 @@ func (s *backupService) CreateBackup(ctx context.Context, description string) (*
 		return nil, fmt.Errorf("failed to close gzip writer: %w", err)
 	}
 
+	// Store the checksum restores verify the file against
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if err := s.writeChecksum(filename, checksum); err != nil {
+		os.Remove(filepath)
+		s.logger.Error().Err(err).Str("filename", filename).Msg("Failed to store backup checksum")
+		return nil, fmt.Errorf("failed to store backup checksum: %w", err)
+	}
+
 	// Get file info
 	fileInfo, err := os.Stat(filepath)
 	if err != nil {
@@ -139,6 +172,7 @@ func (s *backupService) CreateBackup(ctx context.Context, description string) (*
 
 	s.logger.Info().
 		Str("filename", filename).
+		Str("checksum", checksum).
 		Int64("size_bytes", fileInfo.Size()).
 		Str("size_mb", fmt.Sprintf("%.2f", float64(fileInfo.Size())/1024/1024)).
 		Msg("Database backup completed successfully")
@@ -149,10 +183,16 @@ func (s *backupService) CreateBackup(ctx context.Context, description string) (*
 		CreatedAt:   time.Now(),
 		Compressed:  true,
 		Description: description,
+		Checksum:    checksum,
 	}, nil
 }
 
-func (s *backupService) RestoreBackup(ctx context.Context, filename string) error {
+func (s *backupService) RestoreBackup(ctx context.Context, filename string, allowUnverified bool) error {
+	// Security check: ensure filename doesn't contain path traversal
+	if !validBackupFilename(filename) {
+		s.logger.Error().Str("filename", filename).Msg("Rejected restore of invalid backup filename")
+		return ErrInvalidBackupFilename
+	}
 	filepath := filepath.Join(s.backupDir, filename)
 
 	s.logger.Warn().
@@ -162,7 +202,20 @@ func (s *backupService) RestoreBackup(ctx context.Context, filename string) erro
 	// Check if file exists
 	if _, err := os.Stat(filepath); os.IsNotExist(err) {
 		s.logger.Error().Str("filename", filename).Msg("Backup file not found")
-		return fmt.Errorf("backup file not found: %s", filename)
+		return fmt.Errorf("%w: %s", ErrBackupNotFound, filename)
+	}
+
+	// Verify the file is the one the backup wrote
+	s.logger.Info().Str("filename", filename).Msg("Verifying backup checksum")
+	err := s.verifyChecksum(filename)
+	switch {
+	case err == nil:
+		s.logger.Info().Str("filename", filename).Msg("Backup checksum verified")
+	case allowUnverified && errors.Is(err, ErrBackupChecksumMissing):
+		s.logger.Warn().Str("filename", filename).Msg("Restoring backup without a stored checksum")
+	default:
+		s.logger.Error().Err(err).Str("filename", filename).Msg("Backup checksum verification failed")
+		return err
 	}
 
 	// Open the backup file
@@ -200,6 +253,12 @@ func (s *backupService) RestoreBackup(ctx context.Context, filename string) erro
 	// Pipe backup content to psql
 	cmd.Stdin = reader
 
+	s.logger.Info().
+		Str("filename", filename).
+		Str("host", s.dbConfig.Host).
+		Str("database", s.dbConfig.DBName).
+		Msg("Running psql restore")
+
 	// Capture output
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
@@ -296,6 +355,7 @@ func (s *backupService) DeleteBackup(ctx context.Context, filename string) error
 	if err := os.Remove(filepath); err != nil {
 		return fmt.Errorf("failed to delete backup: %w", err)
 	}
+	os.Remove(filepath + backupChecksumSuffix)
 
 	return nil
 }
@@ -353,7 +413,7 @@ func (s *backupService) CleanupOldBackups(ctx context.Context, retentionDays int
 		}
 
 		name := entry.Name()
-		if !strings.HasPrefix(name, "pawnshop_backup_") {
+		if !strings.HasPrefix(name, "pawnshop_backup_") || strings.HasSuffix(name, backupChecksumSuffix) {
 			continue
 		}
 
@@ -365,6 +425,7 @@ func (s *backupService) CleanupOldBackups(ctx context.Context, retentionDays int
 		if info.ModTime().Before(cutoff) {
 			filepath := filepath.Join(s.backupDir, name)
 			if err := os.Remove(filepath); err == nil {
+				os.Remove(filepath + backupChecksumSuffix)
 				deleted++
 			}
 		}
@@ -372,3 +433,91 @@ func (s *backupService) CleanupOldBackups(ctx context.Context, retentionDays int
 
 	return deleted, nil
 }
+
+func (s *backupService) PruneBackups(ctx context.Context, keep int) (int, error) {
+	backups, err := s.ListBackups(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, backup := range backupsToPrune(backups, keep) {
+		if err := s.DeleteBackup(ctx, backup.Filename); err != nil {
+			s.logger.Error().Err(err).Str("filename", backup.Filename).Msg("Failed to prune backup")
+			continue
+		}
+		s.logger.Info().Str("filename", backup.Filename).Msg("Pruned old backup")
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// backupsToPrune returns the backups beyond the newest keep. A keep under one
+// prunes nothing, so a misconfigured retention never deletes every backup.
+func backupsToPrune(backups []*BackupInfo, keep int) []*BackupInfo {
+	if keep < 1 || len(backups) <= keep {
+		return nil
+	}
+
+	sorted := make([]*BackupInfo, len(backups))
+	copy(sorted, backups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+	return sorted[keep:]
+}
+
+// validBackupFilename checks that a filename names a file of the backup directory
+func validBackupFilename(filename string) bool {
+	return filename != "" && !strings.Contains(filename, "..") && !strings.Contains(filename, "/") && !strings.Contains(filename, "\\")
+}
+
+// writeChecksum stores the SHA-256 of a backup next to it
+func (s *backupService) writeChecksum(filename, checksum string) error {
+	line := fmt.Sprintf("%s  %s\n", checksum, filename)
+	return os.WriteFile(filepath.Join(s.backupDir, filename+backupChecksumSuffix), []byte(line), 0644)
+}
+
+// readChecksum returns the SHA-256 stored for a backup
+func (s *backupService) readChecksum(filename string) (string, error) {
+	file, err := os.Open(filepath.Join(s.backupDir, filename+backupChecksumSuffix))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %s", ErrBackupChecksumMissing, filename)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to open backup checksum: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s is empty", ErrBackupChecksumMissing, filename+backupChecksumSuffix)
+}
+
+// verifyChecksum compares a backup with the checksum stored when it was created
+func (s *backupService) verifyChecksum(filename string) error {
+	expected, err := s.readChecksum(filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(s.backupDir, filename))
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to read backup file: %w", err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: %s has %s, expected %s", ErrBackupChecksumMismatch, filename, actual, expected)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"testing"
@@ -368,9 +370,88 @@ func TestBackupService_RestoreBackup_FileNotFound(t *testing.T) {
 	svc := NewBackupService(dbConfig, tempDir, zerolog.New(os.Stdout).Level(zerolog.Disabled))
 	ctx := context.Background()
 
-	err := svc.RestoreBackup(ctx, "nonexistent_backup.sql.gz")
+	err := svc.RestoreBackup(ctx, "nonexistent_backup.sql.gz", false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
+	assert.ErrorIs(t, err, ErrBackupNotFound)
+}
+
+func TestBackupService_RestoreBackup_ChecksumMismatch(t *testing.T) {
+	tempDir, cleanup := setupBackupTestDir(t)
+	defer cleanup()
+
+	svc := NewBackupService(&config.DatabaseConfig{}, tempDir, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	bs := svc.(*backupService)
+	ctx := context.Background()
+
+	filename := "pawnshop_backup_20240101_020000.sql.gz"
+	createTestBackupFile(t, tempDir, filename, "CREATE TABLE customers ();", "Scheduled backup")
+	data, err := os.ReadFile(filepath.Join(tempDir, filename))
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	require.NoError(t, bs.writeChecksum(filename, hex.EncodeToString(sum[:])))
+	require.NoError(t, bs.verifyChecksum(filename))
+
+	// The file changed after its checksum was stored
+	createTestBackupFile(t, tempDir, filename, "DROP TABLE customers;", "Scheduled backup")
+
+	err = svc.RestoreBackup(ctx, filename, false)
+	assert.ErrorIs(t, err, ErrBackupChecksumMismatch)
+
+	// Allowing unverified backups does not let a tampered one through
+	err = svc.RestoreBackup(ctx, filename, true)
+	assert.ErrorIs(t, err, ErrBackupChecksumMismatch)
+}
+
+func TestBackupService_RestoreBackup_ChecksumMissing(t *testing.T) {
+	tempDir, cleanup := setupBackupTestDir(t)
+	defer cleanup()
+
+	svc := NewBackupService(&config.DatabaseConfig{}, tempDir, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	filename := "pawnshop_backup_20240101_020000.sql.gz"
+	createTestBackupFile(t, tempDir, filename, "SELECT 1;", "")
+
+	err := svc.RestoreBackup(context.Background(), filename, false)
+	assert.ErrorIs(t, err, ErrBackupChecksumMissing)
+}
+
+func TestBackupService_RestoreBackup_ChecksumMissingAllowed(t *testing.T) {
+	tempDir, cleanup := setupBackupTestDir(t)
+	defer cleanup()
+
+	svc := NewBackupService(&config.DatabaseConfig{}, tempDir, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	filename := "pawnshop_backup_20240101_020000.sql.gz"
+	createTestBackupFile(t, tempDir, filename, "SELECT 1;", "")
+
+	// The restore gets past the checksum check; psql itself is not available here
+	err := svc.RestoreBackup(context.Background(), filename, true)
+	assert.NotErrorIs(t, err, ErrBackupChecksumMissing)
+}
+
+func TestBackupService_RestoreBackup_InvalidFilename(t *testing.T) {
+	tempDir, cleanup := setupBackupTestDir(t)
+	defer cleanup()
+
+	svc := NewBackupService(&config.DatabaseConfig{}, tempDir, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+
+	for _, filename := range []string{"", "../pawnshop_backup.sql", "dir/pawnshop_backup.sql"} {
+		assert.ErrorIs(t, svc.RestoreBackup(context.Background(), filename, false), ErrInvalidBackupFilename, filename)
+	}
+}
+
+func TestBackupService_DeleteBackup_RemovesChecksum(t *testing.T) {
+	tempDir, cleanup := setupBackupTestDir(t)
+	defer cleanup()
+
+	svc := NewBackupService(&config.DatabaseConfig{}, tempDir, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	filename := "pawnshop_backup_20240101_020000.sql.gz"
+	createTestBackupFile(t, tempDir, filename, "SELECT 1;", "")
+	require.NoError(t, svc.(*backupService).writeChecksum(filename, "abc"))
+
+	require.NoError(t, svc.DeleteBackup(context.Background(), filename))
+
+	_, err := os.Stat(filepath.Join(tempDir, filename+backupChecksumSuffix))
+	assert.True(t, os.IsNotExist(err))
 }
 
 func TestBackupService_ListBackups_WithSubdirectories(t *testing.T) {
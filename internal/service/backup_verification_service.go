@@ -0,0 +1,255 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingBackupVerificationMinRows holds the minimum rows each table must have
+// in a restored backup, as a JSON object of table name to count
+const SettingBackupVerificationMinRows = "backup_verification_min_rows"
+
+// defaultBackupMinRows are the checks used when none are configured: any real
+// backup has at least one branch and one user
+var defaultBackupMinRows = map[string]int64{
+	"branches":  1,
+	"users":     1,
+	"customers": 0,
+	"items":     0,
+	"loans":     0,
+	"payments":  0,
+}
+
+// BackupRestorer restores backups into a scratch database and reads row counts
+// back from it
+type BackupRestorer interface {
+	// Restore replaces the scratch database contents with the given SQL dump
+	Restore(ctx context.Context, dump io.Reader) error
+
+	// CountRows counts the rows of each table in the scratch database
+	CountRows(ctx context.Context, tables []string) (map[string]int64, error)
+}
+
+// BackupVerificationService confirms backups can actually be restored by
+// restoring the latest one into a scratch database and checking its row counts
+type BackupVerificationService struct {
+	backupService    BackupService
+	restorer         BackupRestorer
+	verificationRepo repository.BackupVerificationRepository
+	settingRepo      repository.SettingRepository
+	router           *NotificationRouter
+	logger           zerolog.Logger
+}
+
+// NewBackupVerificationService creates a new BackupVerificationService
+func NewBackupVerificationService(
+	backupService BackupService,
+	restorer BackupRestorer,
+	verificationRepo repository.BackupVerificationRepository,
+	settingRepo repository.SettingRepository,
+	logger zerolog.Logger,
+) *BackupVerificationService {
+	return &BackupVerificationService{
+		backupService:    backupService,
+		restorer:         restorer,
+		verificationRepo: verificationRepo,
+		settingRepo:      settingRepo,
+		logger:           logger.With().Str("service", "backup_verification").Logger(),
+	}
+}
+
+// SetNotificationRouter enables alerting staff about failed verifications
+func (s *BackupVerificationService) SetNotificationRouter(router *NotificationRouter) {
+	s.router = router
+}
+
+// VerifyLatest test-restores the most recent backup and records the outcome.
+// It returns nil when there is no backup to verify yet; a failed verification
+// is recorded and alerted rather than returned.
+func (s *BackupVerificationService) VerifyLatest(ctx context.Context) (*domain.BackupVerification, error) {
+	backups, err := s.backupService.ListBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		s.logger.Warn().Msg("No backups to verify")
+		return nil, nil
+	}
+
+	latest := backups[0]
+	for _, backup := range backups[1:] {
+		if backup.CreatedAt.After(latest.CreatedAt) {
+			latest = backup
+		}
+	}
+
+	verification := &domain.BackupVerification{
+		BackupFilename: latest.Filename,
+		StartedAt:      time.Now(),
+	}
+	counts, checkErr := s.check(ctx, latest)
+	verification.RowCounts = counts
+	verification.FinishedAt = time.Now()
+	verification.DurationMs = verification.FinishedAt.Sub(verification.StartedAt).Milliseconds()
+
+	verification.Status = domain.BackupVerificationPassed
+	if checkErr != nil {
+		message := checkErr.Error()
+		verification.Status = domain.BackupVerificationFailed
+		verification.ErrorMessage = &message
+	}
+
+	if err := s.verificationRepo.Create(ctx, verification); err != nil {
+		return nil, fmt.Errorf("failed to record backup verification: %w", err)
+	}
+
+	if checkErr != nil {
+		s.logger.Error().Err(checkErr).Str("filename", latest.Filename).Msg("Backup verification failed")
+		s.alert(ctx, verification)
+	} else {
+		s.logger.Info().
+			Str("filename", latest.Filename).
+			Int64("duration_ms", verification.DurationMs).
+			Msg("Backup verified")
+	}
+
+	return verification, nil
+}
+
+// check restores the backup and compares its row counts with the configured
+// minimums, returning the counts it got to
+func (s *BackupVerificationService) check(ctx context.Context, backup *BackupInfo) (map[string]int64, error) {
+	minRows := defaultBackupMinRows
+	var configured map[string]int64
+	if getSettingJSON(ctx, s.settingRepo, SettingBackupVerificationMinRows, nil, &configured) && len(configured) > 0 {
+		minRows = configured
+	}
+
+	dump, _, err := s.backupService.GetBackup(ctx, backup.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer dump.Close()
+
+	var reader io.Reader = dump
+	if backup.Compressed {
+		gzReader, err := gzip.NewReader(dump)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compressed backup: %w", err)
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+
+	if err := s.restorer.Restore(ctx, reader); err != nil {
+		return nil, fmt.Errorf("restore failed: %w", err)
+	}
+
+	tables := make([]string, 0, len(minRows))
+	for table := range minRows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	counts, err := s.restorer.CountRows(ctx, tables)
+	if err != nil {
+		return counts, fmt.Errorf("failed to count restored rows: %w", err)
+	}
+
+	var short []string
+	for _, table := range tables {
+		if counts[table] < minRows[table] {
+			short = append(short, fmt.Sprintf("%s has %d rows, expected at least %d", table, counts[table], minRows[table]))
+		}
+	}
+	if len(short) > 0 {
+		return counts, errors.New(strings.Join(short, "; "))
+	}
+
+	return counts, nil
+}
+
+func (s *BackupVerificationService) alert(ctx context.Context, verification *domain.BackupVerification) {
+	if s.router == nil {
+		return
+	}
+
+	_, err := s.router.Emit(ctx, InternalEvent{
+		Event:         domain.InternalEventBackupVerifyFailed,
+		Title:         "Respaldo no verificado",
+		Message:       fmt.Sprintf("No se pudo restaurar el respaldo %s: %s", verification.BackupFilename, *verification.ErrorMessage),
+		Type:          "error",
+		ReferenceType: "backup_verification",
+		ReferenceID:   &verification.ID,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to notify staff about the backup verification")
+	}
+}
+
+// psqlRestorer restores into the scratch database with psql and counts rows
+// over a regular connection
+type psqlRestorer struct {
+	dsn string
+}
+
+// NewPsqlBackupRestorer creates a BackupRestorer for the scratch database at dsn
+func NewPsqlBackupRestorer(dsn string) BackupRestorer {
+	return &psqlRestorer{dsn: dsn}
+}
+
+func (r *psqlRestorer) Restore(ctx context.Context, dump io.Reader) error {
+	// Start from an empty schema so the dump restores as it would on a new server
+	if err := r.psql(ctx, nil, "-c", "DROP SCHEMA IF EXISTS public CASCADE; CREATE SCHEMA public;"); err != nil {
+		return err
+	}
+	return r.psql(ctx, dump)
+}
+
+func (r *psqlRestorer) psql(ctx context.Context, stdin io.Reader, args ...string) error {
+	// The DSN carries the password, so it is never logged
+	cmd := exec.CommandContext(ctx, "psql", append([]string{r.dsn, "-q", "-v", "ON_ERROR_STOP=1"}, args...)...)
+	cmd.Env = os.Environ()
+	cmd.Stdin = stdin
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("psql failed: %s - %w", stderr.String(), err)
+	}
+	return nil
+}
+
+func (r *psqlRestorer) CountRows(ctx context.Context, tables []string) (map[string]int64, error) {
+	db, err := sql.Open("postgres", r.dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		query := "SELECT COUNT(*) FROM " + pq.QuoteIdentifier(table)
+		if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return counts, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/config"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+type mockBackupRestorer struct {
+	mock.Mock
+	restored string
+}
+
+func (m *mockBackupRestorer) Restore(ctx context.Context, dump io.Reader) error {
+	content, err := io.ReadAll(dump)
+	if err != nil {
+		return err
+	}
+	m.restored = string(content)
+	return m.Called(ctx).Error(0)
+}
+
+func (m *mockBackupRestorer) CountRows(ctx context.Context, tables []string) (map[string]int64, error) {
+	args := m.Called(ctx, tables)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func setupBackupVerification(t *testing.T) (*BackupVerificationService, *mockBackupRestorer, *mocks.MockBackupVerificationRepository) {
+	tempDir, cleanup := setupBackupTestDir(t)
+	t.Cleanup(cleanup)
+	createTestBackupFile(t, tempDir, "pawnshop_backup_20261015_020000.sql.gz", "-- latest dump", "Scheduled backup")
+
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingBackupVerificationMinRows, mock.Anything).
+		Return(&domain.Setting{Key: SettingBackupVerificationMinRows, Value: map[string]interface{}{"branches": 1, "loans": 10}}, nil)
+
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	restorer := new(mockBackupRestorer)
+	verificationRepo := new(mocks.MockBackupVerificationRepository)
+	service := NewBackupVerificationService(NewBackupService(&config.DatabaseConfig{}, tempDir, logger), restorer, verificationRepo, settingRepo, logger)
+	return service, restorer, verificationRepo
+}
+
+func TestBackupVerificationService_VerifyLatest_RecordsPass(t *testing.T) {
+	service, restorer, verificationRepo := setupBackupVerification(t)
+	ctx := context.Background()
+
+	restorer.On("Restore", ctx).Return(nil)
+	restorer.On("CountRows", ctx, []string{"branches", "loans"}).Return(map[string]int64{"branches": 2, "loans": 40}, nil)
+	verificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.BackupVerification")).Return(nil)
+
+	verification, err := service.VerifyLatest(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "-- latest dump", restorer.restored)
+	assert.Equal(t, "pawnshop_backup_20261015_020000.sql.gz", verification.BackupFilename)
+	assert.Equal(t, domain.BackupVerificationPassed, verification.Status)
+	assert.Nil(t, verification.ErrorMessage)
+	assert.Equal(t, map[string]int64{"branches": 2, "loans": 40}, verification.RowCounts)
+	verificationRepo.AssertCalled(t, "Create", ctx, verification)
+}
+
+func TestBackupVerificationService_VerifyLatest_RecordsFailures(t *testing.T) {
+	t.Run("rows under the threshold", func(t *testing.T) {
+		service, restorer, verificationRepo := setupBackupVerification(t)
+		ctx := context.Background()
+
+		restorer.On("Restore", ctx).Return(nil)
+		restorer.On("CountRows", ctx, []string{"branches", "loans"}).Return(map[string]int64{"branches": 2, "loans": 3}, nil)
+		verificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.BackupVerification")).Return(nil)
+
+		verification, err := service.VerifyLatest(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackupVerificationFailed, verification.Status)
+		require.NotNil(t, verification.ErrorMessage)
+		assert.Equal(t, "loans has 3 rows, expected at least 10", *verification.ErrorMessage)
+		assert.Equal(t, int64(3), verification.RowCounts["loans"])
+	})
+
+	t.Run("restore error", func(t *testing.T) {
+		service, restorer, verificationRepo := setupBackupVerification(t)
+		ctx := context.Background()
+
+		restorer.On("Restore", ctx).Return(errors.New("syntax error at line 1"))
+		verificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.BackupVerification")).Return(nil)
+
+		verification, err := service.VerifyLatest(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackupVerificationFailed, verification.Status)
+		assert.Contains(t, *verification.ErrorMessage, "restore failed")
+		restorer.AssertNotCalled(t, "CountRows", mock.Anything, mock.Anything)
+		verificationRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// BranchComparisonService compares branch activity side by side, converting
+// each branch's figures from its own currency to a common base currency
+type BranchComparisonService struct {
+	branchRepo  repository.BranchRepository
+	loanRepo    repository.LoanRepository
+	paymentRepo repository.PaymentRepository
+	saleRepo    repository.SaleRepository
+	fxService   *FXService
+}
+
+// NewBranchComparisonService creates a new BranchComparisonService
+func NewBranchComparisonService(
+	branchRepo repository.BranchRepository,
+	loanRepo repository.LoanRepository,
+	paymentRepo repository.PaymentRepository,
+	saleRepo repository.SaleRepository,
+	fxService *FXService,
+) *BranchComparisonService {
+	return &BranchComparisonService{
+		branchRepo:  branchRepo,
+		loanRepo:    loanRepo,
+		paymentRepo: paymentRepo,
+		saleRepo:    saleRepo,
+		fxService:   fxService,
+	}
+}
+
+// BranchComparisonParams selects the period and currency of the comparison.
+// Empty base currency and mode fall back to the configured settings.
+type BranchComparisonParams struct {
+	DateFrom     string                   `query:"date_from"`
+	DateTo       string                   `query:"date_to"`
+	BaseCurrency string                   `query:"base_currency"`
+	Mode         domain.FXRevaluationMode `query:"mode"`
+}
+
+// BranchFigures are the activity totals of a branch over the period
+type BranchFigures struct {
+	LoansCount        int     `json:"loans_count"`
+	LoansDisbursed    float64 `json:"loans_disbursed"`
+	PaymentsCollected float64 `json:"payments_collected"`
+	InterestCollected float64 `json:"interest_collected"`
+	LateFeesCollected float64 `json:"late_fees_collected"`
+	SalesRevenue      float64 `json:"sales_revenue"`
+}
+
+func (f *BranchFigures) add(o BranchFigures) {
+	f.LoansCount += o.LoansCount
+	f.LoansDisbursed += o.LoansDisbursed
+	f.PaymentsCollected += o.PaymentsCollected
+	f.InterestCollected += o.InterestCollected
+	f.LateFeesCollected += o.LateFeesCollected
+	f.SalesRevenue += o.SalesRevenue
+}
+
+// converted returns the figures with every amount multiplied by rate
+func (f BranchFigures) converted(rate float64) BranchFigures {
+	return BranchFigures{
+		LoansCount:        f.LoansCount,
+		LoansDisbursed:    f.LoansDisbursed * rate,
+		PaymentsCollected: f.PaymentsCollected * rate,
+		InterestCollected: f.InterestCollected * rate,
+		LateFeesCollected: f.LateFeesCollected * rate,
+		SalesRevenue:      f.SalesRevenue * rate,
+	}
+}
+
+func (f BranchFigures) rounded() BranchFigures {
+	return BranchFigures{
+		LoansCount:        f.LoansCount,
+		LoansDisbursed:    roundCents(f.LoansDisbursed),
+		PaymentsCollected: roundCents(f.PaymentsCollected),
+		InterestCollected: roundCents(f.InterestCollected),
+		LateFeesCollected: roundCents(f.LateFeesCollected),
+		SalesRevenue:      roundCents(f.SalesRevenue),
+	}
+}
+
+// BranchComparisonRow holds a branch's figures in its own currency and in the base currency
+type BranchComparisonRow struct {
+	BranchID   int64         `json:"branch_id"`
+	BranchName string        `json:"branch_name"`
+	Currency   string        `json:"currency"`
+	Native     BranchFigures `json:"native"`
+	Base       BranchFigures `json:"base"`
+}
+
+// BranchComparisonReport compares the activity of every branch over a period
+type BranchComparisonReport struct {
+	DateFrom        string                   `json:"date_from"`
+	DateTo          string                   `json:"date_to"`
+	BaseCurrency    string                   `json:"base_currency"`
+	RevaluationMode domain.FXRevaluationMode `json:"revaluation_mode"`
+	Branches        []BranchComparisonRow    `json:"branches"`
+	BaseTotals      BranchFigures            `json:"base_totals"`
+}
+
+// branchComparisonPageSize bounds how many records are read per branch and kind
+const branchComparisonPageSize = 10000
+
+// Compare builds the branch comparison report. Converting fails when a branch's
+// currency has no rate to the base currency on or before a required day.
+func (s *BranchComparisonService) Compare(ctx context.Context, params BranchComparisonParams) (*BranchComparisonReport, error) {
+	dateFrom, err := domain.ParseDate(params.DateFrom)
+	if err != nil {
+		return nil, ErrInvalidDateRange
+	}
+	dateTo, err := domain.ParseDate(params.DateTo)
+	if err != nil || dateTo.Before(dateFrom.Time) {
+		return nil, ErrInvalidDateRange
+	}
+
+	mode := params.Mode
+	if mode == "" {
+		mode = s.fxService.RevaluationMode(ctx)
+	}
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("%w: unknown revaluation mode %q", ErrInvalidInput, mode)
+	}
+	baseCurrency := normalizeCurrency(params.BaseCurrency)
+	if baseCurrency == "" {
+		baseCurrency = s.fxService.BaseCurrency(ctx)
+	}
+	converter := s.fxService.NewConverter(baseCurrency)
+
+	branches, err := s.branchRepo.List(ctx, repository.PaginationParams{PerPage: 1000})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BranchComparisonReport{
+		DateFrom:        dateFrom.String(),
+		DateTo:          dateTo.String(),
+		BaseCurrency:    baseCurrency,
+		RevaluationMode: mode,
+		Branches:        []BranchComparisonRow{},
+	}
+
+	for _, branch := range branches.Data {
+		row := BranchComparisonRow{
+			BranchID:   branch.ID,
+			BranchName: branch.Name,
+			Currency:   normalizeCurrency(branch.Currency),
+		}
+
+		entries, err := s.branchEntries(ctx, branch.ID, dateFrom, dateTo)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			row.Native.add(entry.figures)
+			if mode == domain.FXRevaluationTransactionDate {
+				rate, err := converter.Rate(ctx, row.Currency, entry.day)
+				if err != nil {
+					return nil, err
+				}
+				row.Base.add(entry.figures.converted(rate))
+			}
+		}
+		if mode == domain.FXRevaluationPeriodEnd {
+			rate, err := converter.Rate(ctx, row.Currency, dateTo)
+			if err != nil {
+				return nil, err
+			}
+			row.Base = row.Native.converted(rate)
+		}
+
+		report.BaseTotals.add(row.Base)
+		row.Native = row.Native.rounded()
+		row.Base = row.Base.rounded()
+		report.Branches = append(report.Branches, row)
+	}
+	report.BaseTotals = report.BaseTotals.rounded()
+
+	return report, nil
+}
+
+// branchEntry is a single transaction's contribution to a branch's figures
+type branchEntry struct {
+	day     domain.Date
+	figures BranchFigures
+}
+
+// branchEntries lists the loans disbursed, payments collected and sales completed
+// by a branch within the period, each dated on its transaction day
+func (s *BranchComparisonService) branchEntries(ctx context.Context, branchID int64, dateFrom, dateTo domain.Date) ([]branchEntry, error) {
+	from, to := dateFrom.String(), dateTo.String()
+	entries := []branchEntry{}
+
+	loans, err := s.loanRepo.List(ctx, repository.LoanListParams{
+		BranchID:         branchID,
+		PaginationParams: repository.PaginationParams{PerPage: branchComparisonPageSize},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, loan := range loans.Data {
+		if loan.StartDate.Before(dateFrom.Time) || loan.StartDate.After(dateTo.Time) {
+			continue
+		}
+		entries = append(entries, branchEntry{
+			day:     loan.StartDate,
+			figures: BranchFigures{LoansCount: 1, LoansDisbursed: loan.LoanAmount},
+		})
+	}
+
+	completedPayment := domain.PaymentStatusCompleted
+	payments, err := s.paymentRepo.List(ctx, repository.PaymentListParams{
+		BranchID:         branchID,
+		Status:           &completedPayment,
+		DateFrom:         &from,
+		DateTo:           &to,
+		PaginationParams: repository.PaginationParams{PerPage: branchComparisonPageSize},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range payments.Data {
+		if payment.Status != domain.PaymentStatusCompleted {
+			continue
+		}
+		entries = append(entries, branchEntry{
+			day: domain.DateFromTime(payment.PaymentDate),
+			figures: BranchFigures{
+				PaymentsCollected: payment.Amount,
+				InterestCollected: payment.InterestAmount,
+				LateFeesCollected: payment.LateFeeAmount,
+			},
+		})
+	}
+
+	completedSale := domain.SaleStatusCompleted
+	sales, err := s.saleRepo.List(ctx, repository.SaleListParams{
+		BranchID:         branchID,
+		Status:           &completedSale,
+		DateFrom:         &from,
+		DateTo:           &to,
+		PaginationParams: repository.PaginationParams{PerPage: branchComparisonPageSize},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sale := range sales.Data {
+		if sale.Status != domain.SaleStatusCompleted {
+			continue
+		}
+		entries = append(entries, branchEntry{
+			day:     domain.DateFromTime(sale.SaleDate),
+			figures: BranchFigures{SalesRevenue: sale.FinalPrice},
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+type branchComparisonMocks struct {
+	branchRepo  *mocks.MockBranchRepository
+	loanRepo    *mocks.MockLoanRepository
+	paymentRepo *mocks.MockPaymentRepository
+	saleRepo    *mocks.MockSaleRepository
+	rateRepo    *mocks.MockFXRateRepository
+	settingRepo *mocks.MockSettingRepository
+}
+
+func setupBranchComparisonService() (*BranchComparisonService, branchComparisonMocks) {
+	m := branchComparisonMocks{
+		branchRepo:  new(mocks.MockBranchRepository),
+		loanRepo:    new(mocks.MockLoanRepository),
+		paymentRepo: new(mocks.MockPaymentRepository),
+		saleRepo:    new(mocks.MockSaleRepository),
+		rateRepo:    new(mocks.MockFXRateRepository),
+		settingRepo: new(mocks.MockSettingRepository),
+	}
+	fxService := NewFXService(m.rateRepo, m.settingRepo)
+	service := NewBranchComparisonService(m.branchRepo, m.loanRepo, m.paymentRepo, m.saleRepo, fxService)
+	return service, m
+}
+
+func marchDay(day int) time.Time {
+	return time.Date(2024, 3, day, 10, 0, 0, 0, time.UTC)
+}
+
+// branchComparisonFixtures sets up a quetzal branch and a dollar branch with
+// activity in March 2024, plus USD to GTQ rates on March 1st and 20th
+func branchComparisonFixtures(m branchComparisonMocks) {
+	m.settingRepo.On("Get", mock.Anything, SettingReportBaseCurrency, mock.Anything).Return(&domain.Setting{Value: "GTQ"}, nil)
+	m.settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	m.branchRepo.On("List", mock.Anything, mock.Anything).Return(&repository.PaginatedResult[domain.Branch]{
+		Data: []domain.Branch{
+			{ID: 1, Name: "Central", Currency: "GTQ"},
+			{ID: 2, Name: "Frontera", Currency: "USD"},
+		},
+	}, nil)
+
+	forBranch := func(id int64) interface{} {
+		return mock.MatchedBy(func(p interface{}) bool {
+			switch p := p.(type) {
+			case repository.LoanListParams:
+				return p.BranchID == id
+			case repository.PaymentListParams:
+				return p.BranchID == id
+			case repository.SaleListParams:
+				return p.BranchID == id
+			}
+			return false
+		})
+	}
+
+	m.loanRepo.On("List", mock.Anything, forBranch(1)).Return(&repository.PaginatedResult[domain.Loan]{Data: []domain.Loan{
+		{ID: 1, LoanAmount: 1000, StartDate: domain.NewDate(2024, 3, 5)},
+	}}, nil)
+	m.loanRepo.On("List", mock.Anything, forBranch(2)).Return(&repository.PaginatedResult[domain.Loan]{Data: []domain.Loan{
+		{ID: 2, LoanAmount: 100, StartDate: domain.NewDate(2024, 3, 5)},
+		{ID: 3, LoanAmount: 999, StartDate: domain.NewDate(2024, 2, 20)}, // before the period
+	}}, nil)
+	m.paymentRepo.On("List", mock.Anything, forBranch(1)).Return(&repository.PaginatedResult[domain.Payment]{Data: []domain.Payment{
+		{ID: 1, Amount: 500, InterestAmount: 50, LateFeeAmount: 10, Status: domain.PaymentStatusCompleted, PaymentDate: marchDay(10)},
+	}}, nil)
+	m.paymentRepo.On("List", mock.Anything, forBranch(2)).Return(&repository.PaginatedResult[domain.Payment]{Data: []domain.Payment{
+		{ID: 2, Amount: 50, InterestAmount: 5, Status: domain.PaymentStatusCompleted, PaymentDate: marchDay(20)},
+	}}, nil)
+	m.saleRepo.On("List", mock.Anything, forBranch(1)).Return(&repository.PaginatedResult[domain.Sale]{Data: []domain.Sale{
+		{ID: 1, FinalPrice: 300, Status: domain.SaleStatusCompleted, SaleDate: marchDay(15)},
+	}}, nil)
+	m.saleRepo.On("List", mock.Anything, forBranch(2)).Return(&repository.PaginatedResult[domain.Sale]{Data: []domain.Sale{
+		{ID: 2, FinalPrice: 40, Status: domain.SaleStatusCompleted, SaleDate: marchDay(20)},
+	}}, nil)
+}
+
+func TestBranchComparisonService_Compare_TransactionDateRates(t *testing.T) {
+	service, m := setupBranchComparisonService()
+	ctx := context.Background()
+	branchComparisonFixtures(m)
+
+	m.rateRepo.On("GetLatest", mock.Anything, "USD", "GTQ", domain.NewDate(2024, 3, 5)).Return(&domain.FXRate{Rate: 7.80}, nil).Once()
+	m.rateRepo.On("GetLatest", mock.Anything, "USD", "GTQ", domain.NewDate(2024, 3, 20)).Return(&domain.FXRate{Rate: 7.70}, nil).Once()
+
+	report, err := service.Compare(ctx, BranchComparisonParams{DateFrom: "2024-03-01", DateTo: "2024-03-31"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "GTQ", report.BaseCurrency)
+	assert.Equal(t, domain.FXRevaluationTransactionDate, report.RevaluationMode)
+	require.Len(t, report.Branches, 2)
+
+	central := report.Branches[0]
+	assert.Equal(t, central.Native, central.Base)
+	assert.Equal(t, 1000.0, central.Base.LoansDisbursed)
+
+	frontera := report.Branches[1]
+	assert.Equal(t, "USD", frontera.Currency)
+	assert.Equal(t, BranchFigures{LoansCount: 1, LoansDisbursed: 100, PaymentsCollected: 50, InterestCollected: 5, SalesRevenue: 40}, frontera.Native)
+	assert.Equal(t, 780.0, frontera.Base.LoansDisbursed)    // 100 * 7.80 on March 5th
+	assert.Equal(t, 385.0, frontera.Base.PaymentsCollected) // 50 * 7.70 on March 20th
+	assert.Equal(t, 38.5, frontera.Base.InterestCollected)
+	assert.Equal(t, 308.0, frontera.Base.SalesRevenue)
+
+	assert.Equal(t, BranchFigures{
+		LoansCount:        2,
+		LoansDisbursed:    1780,
+		PaymentsCollected: 885,
+		InterestCollected: 88.5,
+		LateFeesCollected: 10,
+		SalesRevenue:      608,
+	}, report.BaseTotals)
+	// Rates are looked up once per day
+	m.rateRepo.AssertNumberOfCalls(t, "GetLatest", 2)
+}
+
+func TestBranchComparisonService_Compare_PeriodEndRate(t *testing.T) {
+	service, m := setupBranchComparisonService()
+	ctx := context.Background()
+	branchComparisonFixtures(m)
+
+	m.rateRepo.On("GetLatest", mock.Anything, "USD", "GTQ", domain.NewDate(2024, 3, 31)).Return(&domain.FXRate{Rate: 7.70}, nil)
+
+	report, err := service.Compare(ctx, BranchComparisonParams{DateFrom: "2024-03-01", DateTo: "2024-03-31", Mode: domain.FXRevaluationPeriodEnd})
+
+	require.NoError(t, err)
+	frontera := report.Branches[1]
+	assert.Equal(t, 770.0, frontera.Base.LoansDisbursed)
+	assert.Equal(t, 385.0, frontera.Base.PaymentsCollected)
+	assert.Equal(t, 1770.0, report.BaseTotals.LoansDisbursed)
+}
+
+func TestBranchComparisonService_Compare_MissingRate(t *testing.T) {
+	service, m := setupBranchComparisonService()
+	ctx := context.Background()
+	branchComparisonFixtures(m)
+
+	m.rateRepo.On("GetLatest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	report, err := service.Compare(ctx, BranchComparisonParams{DateFrom: "2024-03-01", DateTo: "2024-03-31"})
+
+	assert.Nil(t, report)
+	assert.ErrorIs(t, err, ErrFXRateNotFound)
+}
+
+func TestBranchComparisonService_Compare_InvalidRange(t *testing.T) {
+	service, _ := setupBranchComparisonService()
+
+	_, err := service.Compare(context.Background(), BranchComparisonParams{DateFrom: "2024-03-31", DateTo: "2024-03-01"})
+
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
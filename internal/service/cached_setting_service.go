@@ -160,3 +160,15 @@ func (s *CachedSettingService) Delete(ctx context.Context, key string, branchID
 
 	return nil
 }
+
+// Import applies a settings file and invalidates cache
+func (s *CachedSettingService) Import(ctx context.Context, data []byte, mode string) (*SettingsImportResult, error) {
+	result, err := s.SettingService.Import(ctx, data, mode)
+
+	// Invalidate all settings cache, a failed import may have applied part of the file
+	if s.cache != nil && result != nil {
+		_ = s.cache.DeleteByPattern(ctx, "settings:*")
+	}
+
+	return result, err
+}
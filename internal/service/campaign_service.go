@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// CampaignService handles promotional campaign business logic
+type CampaignService struct {
+	campaignRepo repository.CampaignRepository
+	customerRepo repository.CustomerRepository
+}
+
+// NewCampaignService creates a new CampaignService
+func NewCampaignService(campaignRepo repository.CampaignRepository, customerRepo repository.CustomerRepository) *CampaignService {
+	return &CampaignService{
+		campaignRepo: campaignRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+// CreateCampaignInput represents create campaign request data
+type CreateCampaignInput struct {
+	Name                    string  `json:"name" validate:"required,min=2"`
+	Code                    string  `json:"code" validate:"required,min=2,max=30"`
+	Description             *string `json:"description"`
+	StartDate               string  `json:"start_date" validate:"required"`
+	EndDate                 string  `json:"end_date" validate:"required"`
+	BranchID                *int64  `json:"branch_id"`
+	FlaggedCustomersOnly    bool    `json:"flagged_customers_only"`
+	InterestDiscountPercent float64 `json:"interest_discount_percent" validate:"gte=0,lte=100"`
+	LateFeeDiscountPercent  float64 `json:"late_fee_discount_percent" validate:"gte=0,lte=100"`
+}
+
+// Create creates a new campaign
+func (s *CampaignService) Create(ctx context.Context, input CreateCampaignInput) (*domain.Campaign, error) {
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	if existing, _ := s.campaignRepo.GetByCode(ctx, code); existing != nil {
+		return nil, errors.New("campaign with this code already exists")
+	}
+
+	startDate, err := domain.ParseDate(input.StartDate)
+	if err != nil {
+		return nil, errors.New("invalid start date format, use YYYY-MM-DD")
+	}
+	endDate, err := domain.ParseDate(input.EndDate)
+	if err != nil {
+		return nil, errors.New("invalid end date format, use YYYY-MM-DD")
+	}
+
+	campaign := &domain.Campaign{
+		Name:                    input.Name,
+		Code:                    code,
+		Description:             input.Description,
+		StartDate:               startDate,
+		EndDate:                 endDate,
+		BranchID:                input.BranchID,
+		FlaggedCustomersOnly:    input.FlaggedCustomersOnly,
+		InterestDiscountPercent: input.InterestDiscountPercent,
+		LateFeeDiscountPercent:  input.LateFeeDiscountPercent,
+		IsActive:                true,
+	}
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// UpdateCampaignInput represents update campaign request data
+type UpdateCampaignInput struct {
+	Name                    string   `json:"name" validate:"omitempty,min=2"`
+	Description             *string  `json:"description"`
+	StartDate               string   `json:"start_date"`
+	EndDate                 string   `json:"end_date"`
+	BranchID                *int64   `json:"branch_id"`
+	FlaggedCustomersOnly    *bool    `json:"flagged_customers_only"`
+	InterestDiscountPercent *float64 `json:"interest_discount_percent" validate:"omitempty,gte=0,lte=100"`
+	LateFeeDiscountPercent  *float64 `json:"late_fee_discount_percent" validate:"omitempty,gte=0,lte=100"`
+	IsActive                *bool    `json:"is_active"`
+}
+
+// Update updates an existing campaign
+func (s *CampaignService) Update(ctx context.Context, id int64, input UpdateCampaignInput) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+
+	if input.Name != "" {
+		campaign.Name = input.Name
+	}
+	if input.Description != nil {
+		campaign.Description = input.Description
+	}
+	if input.StartDate != "" {
+		startDate, err := domain.ParseDate(input.StartDate)
+		if err != nil {
+			return nil, errors.New("invalid start date format, use YYYY-MM-DD")
+		}
+		campaign.StartDate = startDate
+	}
+	if input.EndDate != "" {
+		endDate, err := domain.ParseDate(input.EndDate)
+		if err != nil {
+			return nil, errors.New("invalid end date format, use YYYY-MM-DD")
+		}
+		campaign.EndDate = endDate
+	}
+	if input.BranchID != nil {
+		campaign.BranchID = input.BranchID
+	}
+	if input.FlaggedCustomersOnly != nil {
+		campaign.FlaggedCustomersOnly = *input.FlaggedCustomersOnly
+	}
+	if input.InterestDiscountPercent != nil {
+		campaign.InterestDiscountPercent = *input.InterestDiscountPercent
+	}
+	if input.LateFeeDiscountPercent != nil {
+		campaign.LateFeeDiscountPercent = *input.LateFeeDiscountPercent
+	}
+	if input.IsActive != nil {
+		campaign.IsActive = *input.IsActive
+	}
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.campaignRepo.Update(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// GetByID retrieves a campaign by ID
+func (s *CampaignService) GetByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("campaign not found")
+	}
+	return campaign, nil
+}
+
+// List retrieves campaigns, only the active ones unless includeInactive is set
+func (s *CampaignService) List(ctx context.Context, includeInactive bool) ([]*domain.Campaign, error) {
+	return s.campaignRepo.List(ctx, !includeInactive)
+}
+
+// Delete deletes a campaign
+func (s *CampaignService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.campaignRepo.GetByID(ctx, id); err != nil {
+		return errors.New("campaign not found")
+	}
+	return s.campaignRepo.Delete(ctx, id)
+}
+
+// FlagCustomer adds a customer to a campaign restricted to flagged customers
+func (s *CampaignService) FlagCustomer(ctx context.Context, campaignID, customerID int64) error {
+	if _, err := s.campaignRepo.GetByID(ctx, campaignID); err != nil {
+		return errors.New("campaign not found")
+	}
+	if _, err := s.customerRepo.GetByID(ctx, customerID); err != nil {
+		return errors.New("customer not found")
+	}
+	return s.campaignRepo.FlagCustomer(ctx, campaignID, customerID)
+}
+
+// UnflagCustomer removes a customer from a campaign
+func (s *CampaignService) UnflagCustomer(ctx context.Context, campaignID, customerID int64) error {
+	if _, err := s.campaignRepo.GetByID(ctx, campaignID); err != nil {
+		return errors.New("campaign not found")
+	}
+	return s.campaignRepo.UnflagCustomer(ctx, campaignID, customerID)
+}
+
+// validateCampaign checks the campaign window and discounts are consistent
+func validateCampaign(campaign *domain.Campaign) error {
+	if campaign.EndDate.Before(campaign.StartDate.Time) {
+		return errors.New("end date must be on or after start date")
+	}
+	if campaign.InterestDiscountPercent == 0 && campaign.LateFeeDiscountPercent == 0 {
+		return errors.New("campaign must discount interest or late fees")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupCampaignService() (*CampaignService, *mocks.MockCampaignRepository, *mocks.MockCustomerRepository) {
+	campaignRepo := new(mocks.MockCampaignRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	service := NewCampaignService(campaignRepo, customerRepo)
+	return service, campaignRepo, customerRepo
+}
+
+func TestCampaignService_Create_Success(t *testing.T) {
+	service, campaignRepo, _ := setupCampaignService()
+	ctx := context.Background()
+
+	campaignRepo.On("GetByCode", ctx, "NOINT7").Return(nil, errors.New("campaign not found"))
+	campaignRepo.On("Create", ctx, mock.AnythingOfType("*domain.Campaign")).Return(nil)
+
+	campaign, err := service.Create(ctx, CreateCampaignInput{
+		Name:                    "Primera semana sin intereses",
+		Code:                    " noint7 ",
+		StartDate:               "2026-11-01",
+		EndDate:                 "2026-11-07",
+		InterestDiscountPercent: 100,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "NOINT7", campaign.Code)
+	assert.Equal(t, domain.NewDate(2026, 11, 1), campaign.StartDate)
+	assert.Equal(t, domain.NewDate(2026, 11, 7), campaign.EndDate)
+	assert.True(t, campaign.IsActive)
+	campaignRepo.AssertExpectations(t)
+}
+
+func TestCampaignService_Create_EndBeforeStart(t *testing.T) {
+	service, campaignRepo, _ := setupCampaignService()
+	ctx := context.Background()
+
+	campaignRepo.On("GetByCode", ctx, "PROMO").Return(nil, errors.New("campaign not found"))
+
+	campaign, err := service.Create(ctx, CreateCampaignInput{
+		Name:                    "Promo",
+		Code:                    "PROMO",
+		StartDate:               "2026-11-07",
+		EndDate:                 "2026-11-01",
+		InterestDiscountPercent: 50,
+	})
+
+	assert.Nil(t, campaign)
+	assert.EqualError(t, err, "end date must be on or after start date")
+	campaignRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCampaignService_Create_DuplicateCode(t *testing.T) {
+	service, campaignRepo, _ := setupCampaignService()
+	ctx := context.Background()
+
+	campaignRepo.On("GetByCode", ctx, "PROMO").Return(&domain.Campaign{ID: 1, Code: "PROMO"}, nil)
+
+	campaign, err := service.Create(ctx, CreateCampaignInput{Name: "Promo", Code: "PROMO", StartDate: "2026-11-01", EndDate: "2026-11-07", InterestDiscountPercent: 50})
+
+	assert.Nil(t, campaign)
+	assert.EqualError(t, err, "campaign with this code already exists")
+}
+
+func TestCampaignService_FlagCustomer_CustomerNotFound(t *testing.T) {
+	service, campaignRepo, customerRepo := setupCampaignService()
+	ctx := context.Background()
+
+	campaignRepo.On("GetByID", ctx, int64(1)).Return(&domain.Campaign{ID: 1, FlaggedCustomersOnly: true}, nil)
+	customerRepo.On("GetByID", ctx, int64(9)).Return(nil, errors.New("customer not found"))
+
+	err := service.FlagCustomer(ctx, 1, 9)
+
+	assert.EqualError(t, err, "customer not found")
+	campaignRepo.AssertNotCalled(t, "FlagCustomer", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/rs/zerolog"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/postgres"
@@ -17,6 +21,13 @@ type CashService struct {
 	sessionRepo  repository.CashSessionRepository
 	movementRepo repository.CashMovementRepository
 	branchRepo   repository.BranchRepository
+	paymentRepo  repository.PaymentRepository
+	saleRepo     repository.SaleRepository
+	events       *EventService
+	settingRepo  repository.SettingRepository
+	router       *NotificationRouter
+	logger       zerolog.Logger
+	now          func() time.Time
 }
 
 // NewCashService creates a new CashService
@@ -25,15 +36,45 @@ func NewCashService(
 	sessionRepo repository.CashSessionRepository,
 	movementRepo repository.CashMovementRepository,
 	branchRepo repository.BranchRepository,
+	paymentRepo repository.PaymentRepository,
+	saleRepo repository.SaleRepository,
 ) *CashService {
 	return &CashService{
 		registerRepo: registerRepo,
 		sessionRepo:  sessionRepo,
 		movementRepo: movementRepo,
 		branchRepo:   branchRepo,
+		paymentRepo:  paymentRepo,
+		saleRepo:     saleRepo,
+		logger:       zerolog.Nop(),
+		now:          time.Now,
 	}
 }
 
+// SettingCashDifferenceAlertThreshold is the absolute closing difference above
+// which a cash session close is flagged as over threshold
+const SettingCashDifferenceAlertThreshold = "cash_difference_alert_threshold"
+
+// DefaultCashDifferenceAlertThreshold is used when the setting is not configured
+const DefaultCashDifferenceAlertThreshold = 50.0
+
+// SetEvents enables emitting cash session events. The setting repository
+// supplies each branch's difference alert threshold.
+func (s *CashService) SetEvents(events *EventService, settingRepo repository.SettingRepository) {
+	s.events = events
+	s.settingRepo = settingRepo
+}
+
+// SetNotificationRouter enables notifying staff of closing differences over the alert threshold
+func (s *CashService) SetNotificationRouter(router *NotificationRouter) {
+	s.router = router
+}
+
+// SetLogger logs the failures of background checks that carry on past them
+func (s *CashService) SetLogger(logger zerolog.Logger) {
+	s.logger = logger.With().Str("service", "cash").Logger()
+}
+
 // === Cash Register Methods ===
 
 // CreateRegisterInput represents create register request data
@@ -112,13 +153,38 @@ func (s *CashService) UpdateRegister(ctx context.Context, id int64, input Update
 
 // === Cash Session Methods ===
 
+// ErrCurrencyNotInSession is returned for an amount in a currency the
+// session's drawer does not hold
+var ErrCurrencyNotInSession = errors.New("cash session does not hold this currency")
+
+// ErrDenominationMismatch is returned when the bills and coins counted when
+// closing a session do not add up to the closing amount declared
+var ErrDenominationMismatch = errors.New("counted denominations do not add up to the closing amount")
+
+// ErrInvalidDenomination is returned for a counted denomination that is not a
+// positive amount of whole cents, or a negative count
+var ErrInvalidDenomination = errors.New("invalid denomination")
+
+// CashCurrencyAmount is an amount of cash in one currency
+type CashCurrencyAmount struct {
+	Currency string  `json:"currency" validate:"required,len=3"`
+	Amount   float64 `json:"amount" validate:"gte=0"`
+	// Denominations optionally break a closing amount down into the number of
+	// bills and coins counted of each denomination, e.g. {"100": 3, "0.25": 4}
+	Denominations map[string]int `json:"denominations,omitempty"`
+}
+
 // OpenSessionInput represents open session request data
 type OpenSessionInput struct {
-	BranchID         int64   `json:"branch_id" validate:"required"`
-	CashRegisterID   int64   `json:"cash_register_id" validate:"required"`
-	UserID           int64   `json:"-"`
-	OpeningAmount    float64 `json:"opening_amount" validate:"gte=0"`
-	OpeningNotes     *string `json:"opening_notes"`
+	BranchID       int64 `json:"branch_id" validate:"required"`
+	CashRegisterID int64 `json:"cash_register_id" validate:"required"`
+	UserID         int64 `json:"-"`
+	// Currency is the main currency of the session, the branch's by default
+	Currency      string  `json:"currency" validate:"omitempty,len=3"`
+	OpeningAmount float64 `json:"opening_amount" validate:"gte=0"`
+	OpeningNotes  *string `json:"opening_notes"`
+	// Currencies are the opening amounts of the other currencies in the drawer
+	Currencies []CashCurrencyAmount `json:"currencies" validate:"dive"`
 }
 
 // OpenSession opens a new cash session
@@ -146,7 +212,15 @@ func (s *CashService) OpenSession(ctx context.Context, input OpenSessionInput) (
 	// Check if register already has an open session
 	registerSession, _ := s.sessionRepo.GetOpenSessionByRegister(ctx, input.CashRegisterID)
 	if registerSession != nil {
-		return nil, errors.New("register already has an open session")
+		return nil, ErrRegisterHasOpenSession
+	}
+
+	currency := input.Currency
+	if currency == "" {
+		currency = domain.DefaultCashCurrency
+		if branch, err := s.branchRepo.GetByID(ctx, input.BranchID); err == nil && branch.Currency != "" {
+			currency = branch.Currency
+		}
 	}
 
 	session := &domain.CashSession{
@@ -154,15 +228,38 @@ func (s *CashService) OpenSession(ctx context.Context, input OpenSessionInput) (
 		CashRegisterID: input.CashRegisterID,
 		UserID:         input.UserID,
 		Status:         domain.CashSessionStatusOpen,
+		Currency:       currency,
 		OpeningAmount:  input.OpeningAmount,
 		OpenedAt:       time.Now(),
 		OpeningNotes:   input.OpeningNotes,
 	}
+	for _, amount := range input.Currencies {
+		if session.HoldsCurrency(amount.Currency) {
+			return nil, fmt.Errorf("currency %s is listed more than once", amount.Currency)
+		}
+		session.Currencies = append(session.Currencies, &domain.CashSessionCurrency{
+			Currency:      amount.Currency,
+			OpeningAmount: amount.Amount,
+		})
+	}
 
+	// The check above can race with another open on the same register; the
+	// repository reports the loser as ErrRegisterHasOpenSession
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		if errors.Is(err, ErrRegisterHasOpenSession) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create cash session: %w", err)
 	}
 
+	s.emitSessionEvent(ctx, domain.EventCashSessionOpened, session, session.UserID, map[string]interface{}{
+		"register_id":    register.ID,
+		"register_name":  register.Name,
+		"cashier_id":     session.UserID,
+		"opening_amount": session.OpeningAmount,
+		"currency":       session.Currency,
+	})
+
 	return session, nil
 }
 
@@ -176,6 +273,11 @@ func (s *CashService) GetSession(ctx context.Context, id int64) (*domain.CashSes
 	// Load movements
 	session.Movements, _ = s.movementRepo.ListBySession(ctx, id)
 
+	// Load the count of a closed session
+	if !session.IsOpen() {
+		session.Denominations, _ = s.sessionRepo.ListDenominations(ctx, id)
+	}
+
 	return session, nil
 }
 
@@ -203,9 +305,16 @@ type CloseSessionInput struct {
 	ClosingAmount float64 `json:"closing_amount" validate:"gte=0"`
 	ClosingNotes  *string `json:"closing_notes"`
 	ClosedBy      int64   `json:"-"`
+	// Currencies are the counted amounts of the session's other currencies,
+	// one for each currency the session holds
+	Currencies []CashCurrencyAmount `json:"currencies" validate:"dive"`
+	// Denominations optionally break the closing amount down into the number
+	// of bills and coins counted of each denomination
+	Denominations map[string]int `json:"denominations"`
 }
 
-// CloseSession closes a cash session
+// CloseSession closes a cash session. Each currency in the drawer is counted
+// and gets its own difference; the alert threshold applies to the main one.
 func (s *CashService) CloseSession(ctx context.Context, input CloseSessionInput) (*domain.CashSession, error) {
 	session, err := s.sessionRepo.GetByID(ctx, input.SessionID)
 	if err != nil {
@@ -216,19 +325,37 @@ func (s *CashService) CloseSession(ctx context.Context, input CloseSessionInput)
 		return nil, errors.New("cash session is not open")
 	}
 
-	// Calculate expected amount based on movements
-	movementRepo, ok := s.movementRepo.(*postgres.CashMovementRepository)
-	if !ok {
-		return nil, errors.New("invalid movement repository")
+	expectedAmount, err := s.expectedClosingAmount(ctx, session)
+	if err != nil {
+		return nil, err
 	}
+	difference := input.ClosingAmount - expectedAmount
 
-	summary, err := movementRepo.GetSessionSummary(ctx, session.ID)
+	denominations, err := countDenominations(session.Currency, input.Denominations, input.ClosingAmount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session summary: %w", err)
+		return nil, err
 	}
 
-	expectedAmount := session.OpeningAmount + summary.CashIncome - summary.CashExpense
-	difference := input.ClosingAmount - expectedAmount
+	counted := make(map[string]float64, len(input.Currencies))
+	for _, amount := range input.Currencies {
+		if session.OtherCurrency(amount.Currency) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrCurrencyNotInSession, amount.Currency)
+		}
+		counted[amount.Currency] = amount.Amount
+
+		currencyDenominations, err := countDenominations(amount.Currency, amount.Denominations, amount.Amount)
+		if err != nil {
+			return nil, err
+		}
+		denominations = append(denominations, currencyDenominations...)
+	}
+	currencies, err := s.closeCurrencies(ctx, session, func(currency string, _ float64) (float64, bool) {
+		amount, ok := counted[currency]
+		return amount, ok
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	closingNotes := ""
 	if input.ClosingNotes != nil {
@@ -241,15 +368,309 @@ func (s *CashService) CloseSession(ctx context.Context, input CloseSessionInput)
 		Difference:     difference,
 		ClosedBy:       input.ClosedBy,
 		ClosingNotes:   closingNotes,
+		Currencies:     currencies,
+		Denominations:  denominations,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to close cash session: %w", err)
 	}
 
+	threshold := getSettingFloat(ctx, s.settingRepo, SettingCashDifferenceAlertThreshold, &session.BranchID, DefaultCashDifferenceAlertThreshold)
+	if math.Abs(roundCents(difference)) > threshold {
+		s.router.emit(ctx, InternalEvent{
+			Event:         domain.InternalEventCashDifference,
+			BranchID:      session.BranchID,
+			Amount:        math.Abs(roundCents(difference)),
+			Title:         "Diferencia de Caja",
+			Message:       fmt.Sprintf("La sesión de caja #%d cerró con una diferencia de Q%.2f", session.ID, roundCents(difference)),
+			Type:          "warning",
+			ReferenceType: "cash_session",
+			ReferenceID:   &session.ID,
+		})
+	}
+
+	if s.events != nil {
+		s.emitSessionEvent(ctx, domain.EventCashSessionClosed, session, input.ClosedBy, map[string]interface{}{
+			"register_id":     session.CashRegisterID,
+			"cashier_id":      session.UserID,
+			"closed_by":       input.ClosedBy,
+			"opening_amount":  session.OpeningAmount,
+			"expected_amount": roundCents(expectedAmount),
+			"closing_amount":  input.ClosingAmount,
+			"difference":      roundCents(difference),
+			"threshold":       threshold,
+			"over_threshold":  math.Abs(roundCents(difference)) > threshold,
+			"currency":        session.Currency,
+			"currencies":      currencies,
+		})
+	}
+
 	// Reload session
 	return s.sessionRepo.GetByID(ctx, input.SessionID)
 }
 
+// countDenominations checks the bills and coins counted in a currency add up
+// to the closing amount declared for it, and returns them largest first. No
+// breakdown is fine; the count is then only the declared total.
+func countDenominations(currency string, counts map[string]int, declared float64) ([]domain.CashDenominationCount, error) {
+	denominations := make([]domain.CashDenominationCount, 0, len(counts))
+	if len(counts) == 0 {
+		return denominations, nil
+	}
+
+	// Keys such as "100" and "100.00" are the same denomination and are
+	// counted together, as they are stored once per session
+	merged := make(map[float64]int, len(counts))
+	for key, count := range counts {
+		denomination, err := strconv.ParseFloat(key, 64)
+		if err != nil || math.IsInf(denomination, 0) || math.IsNaN(denomination) || roundCents(denomination) <= 0 {
+			return nil, fmt.Errorf("%w: %s %q", ErrInvalidDenomination, currency, key)
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("%w: count %d of %s %s", ErrInvalidDenomination, count, currency, key)
+		}
+		merged[roundCents(denomination)] += count
+	}
+
+	total := 0.0
+	for denomination, count := range merged {
+		counted := domain.CashDenominationCount{Currency: currency, Denomination: denomination, Count: count}
+		total += counted.Total()
+		denominations = append(denominations, counted)
+	}
+
+	if math.Abs(roundCents(total)-roundCents(declared)) > domain.EntryBalanceTolerance {
+		return nil, fmt.Errorf("%w: %s %.2f counted, %.2f declared", ErrDenominationMismatch, currency, roundCents(total), declared)
+	}
+
+	sort.Slice(denominations, func(i, j int) bool {
+		return denominations[i].Denomination > denominations[j].Denomination
+	})
+	return denominations, nil
+}
+
+// cashSessionSummarizer sums the movements of a session, in its main currency
+// or in one of its other currencies
+type cashSessionSummarizer interface {
+	GetSessionSummary(ctx context.Context, sessionID int64) (*postgres.CashSessionSummary, error)
+	GetSessionCurrencySummary(ctx context.Context, sessionID int64, currency string) (*postgres.CashSessionSummary, error)
+}
+
+// expectedClosingAmount is the cash a session should hold in its main
+// currency based on its movements
+func (s *CashService) expectedClosingAmount(ctx context.Context, session *domain.CashSession) (float64, error) {
+	movementRepo, ok := s.movementRepo.(cashSessionSummarizer)
+	if !ok {
+		return 0, errors.New("invalid movement repository")
+	}
+
+	summary, err := movementRepo.GetSessionSummary(ctx, session.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get session summary: %w", err)
+	}
+
+	return session.OpeningAmount + summary.CashIncome - summary.CashExpense, nil
+}
+
+// expectedCurrencyAmount is the cash a session should hold in one of its
+// other currencies based on its movements in that currency
+func (s *CashService) expectedCurrencyAmount(ctx context.Context, session *domain.CashSession, amounts *domain.CashSessionCurrency) (float64, error) {
+	movementRepo, ok := s.movementRepo.(cashSessionSummarizer)
+	if !ok {
+		return 0, errors.New("invalid movement repository")
+	}
+
+	summary, err := movementRepo.GetSessionCurrencySummary(ctx, session.ID, amounts.Currency)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s session summary: %w", amounts.Currency, err)
+	}
+
+	return amounts.OpeningAmount + summary.CashIncome - summary.CashExpense, nil
+}
+
+// closeCurrencies computes the closing amounts of a session's other
+// currencies. counted returns the closing amount of a currency given its
+// expected amount, and false when the currency was not counted.
+func (s *CashService) closeCurrencies(ctx context.Context, session *domain.CashSession, counted func(currency string, expected float64) (float64, bool)) ([]domain.CashSessionCurrency, error) {
+	currencies := make([]domain.CashSessionCurrency, 0, len(session.Currencies))
+	for _, amounts := range session.Currencies {
+		expected, err := s.expectedCurrencyAmount(ctx, session, amounts)
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := counted(amounts.Currency, expected)
+		if !ok {
+			return nil, fmt.Errorf("closing amount of %s is required", amounts.Currency)
+		}
+		expected = roundCents(expected)
+		difference := roundCents(closing - expected)
+		currencies = append(currencies, domain.CashSessionCurrency{
+			Currency:       amounts.Currency,
+			OpeningAmount:  amounts.OpeningAmount,
+			ClosingAmount:  &closing,
+			ExpectedAmount: &expected,
+			Difference:     &difference,
+		})
+	}
+	return currencies, nil
+}
+
+// Settings for cash sessions left open too long, in hours since the session
+// opened. Either can be set per branch; 0 disables the step.
+const (
+	SettingCashSessionWarningHours   = "cash_session_warning_hours"
+	SettingCashSessionAutoCloseHours = "cash_session_auto_close_hours"
+)
+
+// DefaultCashSessionWarningHours is used when the warning setting is not configured
+const DefaultCashSessionWarningHours = 12
+
+// OpenSessionCheckResult summarizes a run of the open session check
+type OpenSessionCheckResult struct {
+	Notified   int `json:"notified"`
+	AutoClosed int `json:"auto_closed"`
+	Failed     int `json:"failed"`
+}
+
+// CheckOpenSessions warns the cashier and the routed managers once about each
+// session open past its branch's warning threshold, and closes the sessions
+// open past the auto-close threshold. An auto-closed session is closed at its
+// expected amount with a system note and flagged, since no cash was counted.
+// A session that fails is logged and counted, and the rest are still checked.
+func (s *CashService) CheckOpenSessions(ctx context.Context, now time.Time) (*OpenSessionCheckResult, error) {
+	sessions, err := s.sessionRepo.ListOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OpenSessionCheckResult{}
+	for _, session := range sessions {
+		openHours := now.Sub(session.OpenedAt).Hours()
+
+		autoCloseHours := getSettingInt(ctx, s.settingRepo, SettingCashSessionAutoCloseHours, &session.BranchID, 0)
+		if autoCloseHours > 0 && openHours >= float64(autoCloseHours) {
+			if err := s.autoCloseSession(ctx, session, autoCloseHours); err != nil {
+				s.logger.Error().Err(err).Int64("session_id", session.ID).Msg("Failed to auto-close cash session")
+				result.Failed++
+				continue
+			}
+			result.AutoClosed++
+			continue
+		}
+
+		warningHours := getSettingInt(ctx, s.settingRepo, SettingCashSessionWarningHours, &session.BranchID, DefaultCashSessionWarningHours)
+		if warningHours <= 0 || openHours < float64(warningHours) || session.OverdueNotifiedAt != nil {
+			continue
+		}
+
+		s.router.emit(ctx, InternalEvent{
+			Event:         domain.InternalEventCashSessionOpen,
+			BranchID:      session.BranchID,
+			Title:         "Sesión de Caja Abierta",
+			Message:       fmt.Sprintf("La sesión de caja #%d lleva %d horas abierta sin cerrarse", session.ID, int(openHours)),
+			Type:          "warning",
+			ReferenceType: "cash_session",
+			ReferenceID:   &session.ID,
+			UserIDs:       []int64{session.UserID},
+		})
+		if err := s.sessionRepo.MarkOverdueNotified(ctx, session.ID, now); err != nil {
+			s.logger.Error().Err(err).Int64("session_id", session.ID).Msg("Failed to mark cash session as notified")
+			result.Failed++
+			continue
+		}
+		result.Notified++
+	}
+
+	return result, nil
+}
+
+// autoCloseSession closes a session on behalf of the system
+func (s *CashService) autoCloseSession(ctx context.Context, session *domain.CashSession, autoCloseHours int) error {
+	expectedAmount, err := s.expectedClosingAmount(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	// Nothing was counted, so every currency closes at its expected amount
+	currencies, err := s.closeCurrencies(ctx, session, func(_ string, expected float64) (float64, bool) {
+		return roundCents(expected), true
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.sessionRepo.Close(ctx, session.ID, repository.CashSessionCloseData{
+		ClosingAmount:  expectedAmount,
+		ExpectedAmount: expectedAmount,
+		Currencies:     currencies,
+		ClosingNotes: fmt.Sprintf("Cierre automático del sistema: la sesión estuvo abierta más de %d horas. "+
+			"El monto de cierre es el esperado; no se realizó arqueo.", autoCloseHours),
+		AutoClosed: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to auto-close cash session: %w", err)
+	}
+
+	s.router.emit(ctx, InternalEvent{
+		Event:         domain.InternalEventCashSessionOpen,
+		BranchID:      session.BranchID,
+		Title:         "Sesión de Caja Cerrada Automáticamente",
+		Message:       fmt.Sprintf("La sesión de caja #%d se cerró automáticamente tras %d horas abierta; falta el arqueo", session.ID, autoCloseHours),
+		Type:          "error",
+		ReferenceType: "cash_session",
+		ReferenceID:   &session.ID,
+		UserIDs:       []int64{session.UserID},
+	})
+
+	if s.events != nil {
+		branchID := session.BranchID
+		err := s.events.Emit(ctx, &domain.Event{
+			EventType:  domain.EventCashSessionAutoClosed,
+			BranchID:   &branchID,
+			EntityType: "cash_session",
+			EntityID:   session.ID,
+			Payload: map[string]interface{}{
+				"register_id":      session.CashRegisterID,
+				"cashier_id":       session.UserID,
+				"opening_amount":   session.OpeningAmount,
+				"expected_amount":  roundCents(expectedAmount),
+				"auto_close_hours": autoCloseHours,
+			},
+		})
+		if err != nil {
+			s.logger.Error().Err(err).
+				Int64("session_id", session.ID).
+				Str("event_type", domain.EventCashSessionAutoClosed).
+				Msg("Failed to emit cash session event")
+		}
+	}
+
+	return nil
+}
+
+// emitSessionEvent records a cash session event. The session change is already
+// saved, so a failure to record the event does not fail the operation.
+func (s *CashService) emitSessionEvent(ctx context.Context, eventType string, session *domain.CashSession, userID int64, payload map[string]interface{}) {
+	if s.events == nil {
+		return
+	}
+	branchID := session.BranchID
+	err := s.events.Emit(ctx, &domain.Event{
+		EventType:  eventType,
+		BranchID:   &branchID,
+		EntityType: "cash_session",
+		EntityID:   session.ID,
+		UserID:     &userID,
+		Payload:    payload,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).
+			Int64("session_id", session.ID).
+			Str("event_type", eventType).
+			Msg("Failed to emit cash session event")
+	}
+}
+
 // GetSessionSummary retrieves summary for a session
 func (s *CashService) GetSessionSummary(ctx context.Context, sessionID int64) (*CashSessionSummaryResult, error) {
 	session, err := s.sessionRepo.GetByID(ctx, sessionID)
@@ -274,6 +695,18 @@ func (s *CashService) GetSessionSummary(ctx context.Context, sessionID int64) (*
 
 	expectedCash := session.OpeningAmount + summary.CashIncome - summary.CashExpense
 
+	// Closed sessions already carry what each other currency was expected to hold
+	if session.IsOpen() {
+		for _, amounts := range session.Currencies {
+			expected, err := s.expectedCurrencyAmount(ctx, session, amounts)
+			if err != nil {
+				return nil, err
+			}
+			expected = roundCents(expected)
+			amounts.ExpectedAmount = &expected
+		}
+	}
+
 	return &CashSessionSummaryResult{
 		Session:        session,
 		TotalIncome:    summary.TotalIncome,
@@ -298,6 +731,164 @@ type CashSessionSummaryResult struct {
 	ExpectedCash   float64             `json:"expected_cash"`
 }
 
+// Cash reconciliation issue types
+const (
+	// ReconciliationOrphanMovement is a cash income movement with no matching payment or sale
+	ReconciliationOrphanMovement = "orphan_movement"
+	// ReconciliationMissingMovement is a cash payment or sale with no income movement
+	ReconciliationMissingMovement = "missing_movement"
+	// ReconciliationAmountMismatch is a movement whose amount differs from its payment or sale
+	ReconciliationAmountMismatch = "amount_mismatch"
+)
+
+// CashReconciliationIssue describes a single mismatch found by Reconcile
+type CashReconciliationIssue struct {
+	Type           string  `json:"type"`
+	ReferenceType  string  `json:"reference_type,omitempty"`
+	ReferenceID    *int64  `json:"reference_id,omitempty"`
+	MovementID     *int64  `json:"movement_id,omitempty"`
+	ExpectedAmount float64 `json:"expected_amount"`
+	RecordedAmount float64 `json:"recorded_amount"`
+}
+
+// CashReconciliationResult contains the outcome of reconciling a session
+type CashReconciliationResult struct {
+	SessionID        int64                     `json:"session_id"`
+	PaymentsChecked  int                       `json:"payments_checked"`
+	SalesChecked     int                       `json:"sales_checked"`
+	MovementsChecked int                       `json:"movements_checked"`
+	IsReconciled     bool                      `json:"is_reconciled"`
+	Issues           []CashReconciliationIssue `json:"issues"`
+}
+
+// reconciliationPageSize is how many payments or sales Reconcile reads at a time
+const reconciliationPageSize = 500
+
+// Reconcile cross-checks the cash payments and sales recorded against a session
+// with the session's cash income movements
+func (s *CashService) Reconcile(ctx context.Context, sessionID int64) (*CashReconciliationResult, error) {
+	if _, err := s.sessionRepo.GetByID(ctx, sessionID); err != nil {
+		return nil, errors.New("cash session not found")
+	}
+
+	// Expected income keyed by reference type and ID
+	expected := map[string]map[int64]float64{"payment": {}, "sale": {}}
+
+	cashMethod := domain.PaymentMethodCash
+	for page := 1; ; page++ {
+		payments, err := s.paymentRepo.List(ctx, repository.PaymentListParams{
+			CashSessionID:    &sessionID,
+			Method:           &cashMethod,
+			PaginationParams: repository.PaginationParams{Page: page, PerPage: reconciliationPageSize},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list session payments: %w", err)
+		}
+		for _, payment := range payments.Data {
+			if payment.Status == domain.PaymentStatusFailed || payment.Status == domain.PaymentStatusPending {
+				continue
+			}
+			expected["payment"][payment.ID] = payment.Amount
+		}
+		if page >= payments.TotalPages || len(payments.Data) == 0 {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		sales, err := s.saleRepo.List(ctx, repository.SaleListParams{
+			CashSessionID:    &sessionID,
+			PaginationParams: repository.PaginationParams{Page: page, PerPage: reconciliationPageSize},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list session sales: %w", err)
+		}
+		for _, sale := range sales.Data {
+			if sale.PaymentMethod != domain.PaymentMethodCash || sale.Status == domain.SaleStatusPending || sale.Status == domain.SaleStatusCancelled {
+				continue
+			}
+			expected["sale"][sale.ID] = sale.FinalPrice
+		}
+		if page >= sales.TotalPages || len(sales.Data) == 0 {
+			break
+		}
+	}
+
+	movements, err := s.movementRepo.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session movements: %w", err)
+	}
+
+	result := &CashReconciliationResult{
+		SessionID:       sessionID,
+		PaymentsChecked: len(expected["payment"]),
+		SalesChecked:    len(expected["sale"]),
+		Issues:          []CashReconciliationIssue{},
+	}
+
+	matched := map[string]map[int64]bool{"payment": {}, "sale": {}}
+	for _, movement := range movements {
+		if !movement.IsIncome() || movement.PaymentMethod != domain.PaymentMethodCash || movement.ReferenceType == nil {
+			continue
+		}
+		refType := *movement.ReferenceType
+		if _, ok := expected[refType]; !ok {
+			continue
+		}
+		result.MovementsChecked++
+
+		movementID := movement.ID
+		amount, ok := 0.0, false
+		if movement.ReferenceID != nil {
+			amount, ok = expected[refType][*movement.ReferenceID]
+		}
+		if !ok || matched[refType][*movement.ReferenceID] {
+			result.Issues = append(result.Issues, CashReconciliationIssue{
+				Type:           ReconciliationOrphanMovement,
+				ReferenceType:  refType,
+				ReferenceID:    movement.ReferenceID,
+				MovementID:     &movementID,
+				RecordedAmount: movement.Amount,
+			})
+			continue
+		}
+
+		matched[refType][*movement.ReferenceID] = true
+		if math.Abs(amount-movement.Amount) >= 0.01 {
+			result.Issues = append(result.Issues, CashReconciliationIssue{
+				Type:           ReconciliationAmountMismatch,
+				ReferenceType:  refType,
+				ReferenceID:    movement.ReferenceID,
+				MovementID:     &movementID,
+				ExpectedAmount: amount,
+				RecordedAmount: movement.Amount,
+			})
+		}
+	}
+
+	for _, refType := range []string{"payment", "sale"} {
+		ids := make([]int64, 0, len(expected[refType]))
+		for id := range expected[refType] {
+			if !matched[refType][id] {
+				ids = append(ids, id)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		for _, id := range ids {
+			referenceID := id
+			result.Issues = append(result.Issues, CashReconciliationIssue{
+				Type:           ReconciliationMissingMovement,
+				ReferenceType:  refType,
+				ReferenceID:    &referenceID,
+				ExpectedAmount: expected[refType][id],
+			})
+		}
+	}
+
+	result.IsReconciled = len(result.Issues) == 0
+	return result, nil
+}
+
 // === Cash Movement Methods ===
 
 // CreateMovementInput represents create movement request data
@@ -309,11 +900,28 @@ type CreateMovementInput struct {
 	ReferenceType *string `json:"reference_type"`
 	ReferenceID   *int64  `json:"reference_id"`
 	Description   string  `json:"description" validate:"required"`
-	CreatedBy     int64   `json:"-"`
+	// Currency of the amount, the session's main currency by default
+	Currency  string `json:"currency" validate:"omitempty,len=3"`
+	CreatedBy int64  `json:"-"`
 }
 
 // CreateMovement creates a new cash movement
 func (s *CashService) CreateMovement(ctx context.Context, input CreateMovementInput) (*domain.CashMovement, error) {
+	movement, err := s.newMovement(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.movementRepo.Create(ctx, movement); err != nil {
+		return nil, fmt.Errorf("failed to create cash movement: %w", err)
+	}
+
+	return movement, nil
+}
+
+// newMovement checks a movement against its session and works out the balance
+// it leaves, without saving it
+func (s *CashService) newMovement(ctx context.Context, input CreateMovementInput) (*domain.CashMovement, error) {
 	// Get session
 	session, err := s.sessionRepo.GetByID(ctx, input.SessionID)
 	if err != nil {
@@ -324,15 +932,24 @@ func (s *CashService) CreateMovement(ctx context.Context, input CreateMovementIn
 		return nil, errors.New("cash session is not open")
 	}
 
-	// Get current balance
-	movementRepo, ok := s.movementRepo.(*postgres.CashMovementRepository)
-	if !ok {
-		return nil, errors.New("invalid movement repository")
+	// Get current balance, kept apart for each currency
+	currency := input.Currency
+	if currency == "" {
+		currency = session.Currency
 	}
-
-	currentBalance, err := movementRepo.GetSessionBalance(ctx, session.ID)
-	if err != nil {
-		currentBalance = session.OpeningAmount
+	var currentBalance float64
+	if other := session.OtherCurrency(currency); other != nil {
+		currentBalance, err = s.movementRepo.GetSessionCurrencyBalance(ctx, session.ID, currency)
+		if err != nil {
+			currentBalance = other.OpeningAmount
+		}
+	} else if currency == session.Currency {
+		currentBalance, err = s.movementRepo.GetSessionBalance(ctx, session.ID)
+		if err != nil {
+			currentBalance = session.OpeningAmount
+		}
+	} else {
+		return nil, fmt.Errorf("%w: %s", ErrCurrencyNotInSession, currency)
 	}
 
 	// Calculate new balance
@@ -353,6 +970,7 @@ func (s *CashService) CreateMovement(ctx context.Context, input CreateMovementIn
 		SessionID:     input.SessionID,
 		MovementType:  movementType,
 		Amount:        input.Amount,
+		Currency:      currency,
 		PaymentMethod: domain.PaymentMethod(input.PaymentMethod),
 		ReferenceType: input.ReferenceType,
 		ReferenceID:   input.ReferenceID,
@@ -361,10 +979,6 @@ func (s *CashService) CreateMovement(ctx context.Context, input CreateMovementIn
 		CreatedBy:     input.CreatedBy,
 	}
 
-	if err := s.movementRepo.Create(ctx, movement); err != nil {
-		return nil, fmt.Errorf("failed to create cash movement: %w", err)
-	}
-
 	return movement, nil
 }
 
@@ -387,20 +1001,121 @@ func (s *CashService) ListSessionMovements(ctx context.Context, sessionID int64)
 	return s.movementRepo.ListBySession(ctx, sessionID)
 }
 
+// RequireOpenSession returns the open cash session a cash operation must be recorded against.
+// When sessionID is given that session is used, otherwise the user's current open session.
+// A given session must have been opened by the user, so nobody records cash in another
+// cashier's drawer.
+func (s *CashService) RequireOpenSession(ctx context.Context, userID int64, sessionID *int64) (*domain.CashSession, error) {
+	var session *domain.CashSession
+	var err error
+	if sessionID != nil {
+		session, err = s.sessionRepo.GetByID(ctx, *sessionID)
+	} else {
+		session, err = s.sessionRepo.GetOpenSession(ctx, userID)
+	}
+	if err != nil || session == nil || session.Status != domain.CashSessionStatusOpen {
+		return nil, ErrNoOpenCashSession
+	}
+	if sessionID != nil && session.UserID != userID {
+		return nil, ErrCashSessionNotOwned
+	}
+	return session, nil
+}
+
+// EnsureCashAvailable checks the session holds enough cash for an outgoing amount
+func (s *CashService) EnsureCashAvailable(ctx context.Context, session *domain.CashSession, amount float64) error {
+	balance, err := s.movementRepo.GetSessionBalance(ctx, session.ID)
+	if err != nil {
+		balance = session.OpeningAmount
+	}
+	if balance < amount {
+		return errors.New("insufficient cash balance")
+	}
+	return nil
+}
+
+// SettingDailyCashDisbursementLimit caps the cash a branch pays out for loans
+// in a day; 0 disables the limit
+const SettingDailyCashDisbursementLimit = "daily_cash_disbursement_limit"
+
+// PermissionOverrideDisbursementLimit lets a user disburse past the daily limit
+const PermissionOverrideDisbursementLimit = "cash.override_limit"
+
+// ErrDailyDisbursementLimitExceeded is returned when a cash loan disbursement
+// would take the branch past its daily limit
+var ErrDailyDisbursementLimitExceeded = errors.New("daily cash disbursement limit exceeded")
+
+// EnsureDisbursementWithinDailyLimit checks a cash loan disbursement keeps the
+// branch's loan payouts for today within the configured limit. The error
+// carries the headroom left for the day.
+func (s *CashService) EnsureDisbursementWithinDailyLimit(ctx context.Context, branchID int64, amount float64) error {
+	limit := getSettingFloat(ctx, s.settingRepo, SettingDailyCashDisbursementLimit, &branchID, 0)
+	if limit <= 0 {
+		return nil
+	}
+
+	disbursed, err := s.movementRepo.GetDailyLoanDisbursements(ctx, branchID, domain.DateFromTime(s.now()).Time)
+	if err != nil {
+		return err
+	}
+	if disbursed+amount > limit {
+		headroom := math.Max(roundCents(limit-disbursed), 0)
+		return fmt.Errorf("%w: Q%.2f of the Q%.2f daily limit remaining", ErrDailyDisbursementLimitExceeded, headroom, limit)
+	}
+	return nil
+}
+
+// GetBranchCashOnHand sums the current balance of the open sessions of a branch
+// and returns it with the number of open sessions
+func (s *CashService) GetBranchCashOnHand(ctx context.Context, branchID int64) (float64, int, error) {
+	status := domain.CashSessionStatusOpen
+	sessions, err := s.sessionRepo.List(ctx, repository.CashSessionListParams{
+		BranchID: branchID,
+		Status:   &status,
+		PaginationParams: repository.PaginationParams{
+			PerPage: 100,
+		},
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list open cash sessions: %w", err)
+	}
+
+	var total float64
+	for _, session := range sessions.Data {
+		balance, err := s.movementRepo.GetSessionBalance(ctx, session.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to get balance of cash session %d: %w", session.ID, err)
+		}
+		total += balance
+	}
+	return total, len(sessions.Data), nil
+}
+
 // RecordPaymentMovement records a movement from a payment
 func (s *CashService) RecordPaymentMovement(ctx context.Context, sessionID int64, paymentID int64, amount float64, method string, createdBy int64) error {
+	_, err := s.CreateMovement(ctx, paymentMovementInput(sessionID, &paymentID, amount, method, createdBy))
+	return err
+}
+
+// NewPaymentMovement prepares the movement of a payment that is saved in the
+// same transaction as the payment, which sets its reference once it has an ID
+func (s *CashService) NewPaymentMovement(ctx context.Context, sessionID int64, amount float64, method string, createdBy int64) (*domain.CashMovement, error) {
+	return s.newMovement(ctx, paymentMovementInput(sessionID, nil, amount, method, createdBy))
+}
+
+// paymentMovementInput describes the cash received for a payment
+func paymentMovementInput(sessionID int64, paymentID *int64, amount float64, method string, createdBy int64) CreateMovementInput {
 	refType := "payment"
-	_, err := s.CreateMovement(ctx, CreateMovementInput{
+	return CreateMovementInput{
 		SessionID:     sessionID,
 		MovementType:  "income",
 		Amount:        amount,
 		PaymentMethod: method,
 		ReferenceType: &refType,
-		ReferenceID:   &paymentID,
+		ReferenceID:   paymentID,
 		Description:   "Payment received",
 		CreatedBy:     createdBy,
-	})
-	return err
+	}
 }
 
 // RecordSaleMovement records a movement from a sale
@@ -421,8 +1136,27 @@ func (s *CashService) RecordSaleMovement(ctx context.Context, sessionID int64, s
 
 // RecordLoanDisbursement records a movement from a loan disbursement
 func (s *CashService) RecordLoanDisbursement(ctx context.Context, sessionID int64, loanID int64, amount float64, createdBy int64) error {
+	_, err := s.CreateMovement(ctx, loanDisbursementInput(sessionID, loanID, amount, createdBy))
+	return err
+}
+
+// RecordLoanDisbursementTx records a movement from a loan disbursement within
+// the transaction that creates the loan
+func (s *CashService) RecordLoanDisbursementTx(ctx context.Context, tx repository.Transaction, sessionID int64, loanID int64, amount float64, createdBy int64) error {
+	movement, err := s.newMovement(ctx, loanDisbursementInput(sessionID, loanID, amount, createdBy))
+	if err != nil {
+		return err
+	}
+	if err := s.movementRepo.CreateTx(ctx, tx, movement); err != nil {
+		return fmt.Errorf("failed to create cash movement: %w", err)
+	}
+	return nil
+}
+
+// loanDisbursementInput describes the cash paid out for a loan
+func loanDisbursementInput(sessionID int64, loanID int64, amount float64, createdBy int64) CreateMovementInput {
 	refType := "loan"
-	_, err := s.CreateMovement(ctx, CreateMovementInput{
+	return CreateMovementInput{
 		SessionID:     sessionID,
 		MovementType:  "expense",
 		Amount:        amount,
@@ -431,8 +1165,7 @@ func (s *CashService) RecordLoanDisbursement(ctx context.Context, sessionID int6
 		ReferenceID:   &loanID,
 		Description:   "Loan disbursement",
 		CreatedBy:     createdBy,
-	})
-	return err
+	}
 }
 
 // RecordRefundMovement records a movement from a refund
@@ -450,3 +1183,19 @@ func (s *CashService) RecordRefundMovement(ctx context.Context, sessionID int64,
 	})
 	return err
 }
+
+// RecordExpenseMovement records a movement from a cash expense
+func (s *CashService) RecordExpenseMovement(ctx context.Context, sessionID int64, expenseID int64, amount float64, createdBy int64) error {
+	refType := "expense"
+	_, err := s.CreateMovement(ctx, CreateMovementInput{
+		SessionID:     sessionID,
+		MovementType:  "expense",
+		Amount:        amount,
+		PaymentMethod: "cash",
+		ReferenceType: &refType,
+		ReferenceID:   &expenseID,
+		Description:   "Expense paid",
+		CreatedBy:     createdBy,
+	})
+	return err
+}
@@ -1,15 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
+	"pawnshop/internal/repository/postgres"
 )
 
 func setupCashService() (*CashService, *mocks.MockCashRegisterRepository, *mocks.MockCashSessionRepository, *mocks.MockCashMovementRepository, *mocks.MockBranchRepository) {
@@ -17,7 +22,7 @@ func setupCashService() (*CashService, *mocks.MockCashRegisterRepository, *mocks
 	sessionRepo := new(mocks.MockCashSessionRepository)
 	movementRepo := new(mocks.MockCashMovementRepository)
 	branchRepo := new(mocks.MockBranchRepository)
-	service := NewCashService(registerRepo, sessionRepo, movementRepo, branchRepo)
+	service := NewCashService(registerRepo, sessionRepo, movementRepo, branchRepo, new(mocks.MockPaymentRepository), new(mocks.MockSaleRepository))
 	return service, registerRepo, sessionRepo, movementRepo, branchRepo
 }
 
@@ -185,33 +190,96 @@ func TestCashService_UpdateRegister_WithDescription(t *testing.T) {
 // === Cash Session Tests ===
 
 func TestCashService_OpenSession_Success(t *testing.T) {
-	service, registerRepo, sessionRepo, _, _ := setupCashService()
+	service, registerRepo, sessionRepo, _, branchRepo := setupCashService()
 	ctx := context.Background()
 
 	register := &domain.CashRegister{ID: 1, BranchID: 1, IsActive: true}
 	registerRepo.On("GetByID", ctx, int64(1)).Return(register, nil)
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1, Currency: "GTQ"}, nil)
 	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(nil, errors.New("none"))
 	sessionRepo.On("GetOpenSessionByRegister", ctx, int64(1)).Return(nil, errors.New("none"))
 	sessionRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashSession")).Return(nil)
 
-	input := OpenSessionInput{BranchID: 1, RegisterID: 1, UserID: 10, OpeningAmount: 1000.0}
+	input := OpenSessionInput{BranchID: 1, CashRegisterID: 1, UserID: 10, OpeningAmount: 1000.0}
 	result, err := service.OpenSession(ctx, input)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, domain.CashSessionStatusOpen, result.Status)
 	assert.Equal(t, 1000.0, result.OpeningAmount)
+	assert.Equal(t, "GTQ", result.Currency)
 	registerRepo.AssertExpectations(t)
 	sessionRepo.AssertExpectations(t)
 }
 
+func TestCashService_CloseSession_EmitsCloseEvent(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	eventRepo := new(mocks.MockEventRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	service.SetEvents(NewEventService(eventRepo, new(mocks.MockWebhookRepository), nil, zerolog.Nop()), settingRepo)
+	ctx := context.Background()
+
+	session := &domain.CashSession{ID: 5, BranchID: 1, CashRegisterID: 2, UserID: 10, OpeningAmount: 1000, Status: domain.CashSessionStatusOpen}
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(session, nil)
+	movementRepo.On("GetSessionSummary", ctx, int64(5)).Return(&postgres.CashSessionSummary{CashIncome: 500, CashExpense: 200}, nil)
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Return(nil)
+	settingRepo.On("Get", mock.Anything, SettingCashDifferenceAlertThreshold, mock.Anything).Return(&domain.Setting{Value: float64(50)}, nil)
+
+	var emitted *domain.Event
+	eventRepo.On("Create", ctx, mock.AnythingOfType("*domain.Event")).Run(func(args mock.Arguments) {
+		emitted = args.Get(1).(*domain.Event)
+	}).Return(nil)
+
+	// Expected 1000 + 500 - 200 = 1300; the cashier counted 1225.50
+	_, err := service.CloseSession(ctx, CloseSessionInput{SessionID: 5, ClosingAmount: 1225.50, ClosedBy: 10})
+
+	assert.NoError(t, err)
+	require.NotNil(t, emitted)
+	assert.Equal(t, domain.EventCashSessionClosed, emitted.EventType)
+	assert.Equal(t, "cash_session", emitted.EntityType)
+	assert.Equal(t, int64(5), emitted.EntityID)
+	assert.Equal(t, int64(1), *emitted.BranchID)
+	assert.Equal(t, int64(2), emitted.Payload["register_id"])
+	assert.Equal(t, int64(10), emitted.Payload["cashier_id"])
+	assert.Equal(t, 1300.0, emitted.Payload["expected_amount"])
+	assert.Equal(t, 1225.50, emitted.Payload["closing_amount"])
+	assert.Equal(t, -74.50, emitted.Payload["difference"])
+	assert.Equal(t, true, emitted.Payload["over_threshold"])
+	eventRepo.AssertExpectations(t)
+}
+
+func TestCashService_CloseSession_LogsFailedEvent(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	eventRepo := new(mocks.MockEventRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	service.SetEvents(NewEventService(eventRepo, new(mocks.MockWebhookRepository), nil, zerolog.Nop()), settingRepo)
+	var logs bytes.Buffer
+	service.SetLogger(zerolog.New(&logs))
+	ctx := context.Background()
+
+	session := &domain.CashSession{ID: 5, BranchID: 1, UserID: 10, OpeningAmount: 1000, Status: domain.CashSessionStatusOpen}
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(session, nil)
+	movementRepo.On("GetSessionSummary", ctx, int64(5)).Return(&postgres.CashSessionSummary{}, nil)
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Return(nil)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+	eventRepo.On("Create", ctx, mock.AnythingOfType("*domain.Event")).Return(errors.New("db error"))
+
+	_, err := service.CloseSession(ctx, CloseSessionInput{SessionID: 5, ClosingAmount: 1000, ClosedBy: 10})
+
+	// The session is already closed, so the failed event is only logged
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), "Failed to emit cash session event")
+	assert.Contains(t, logs.String(), `"session_id":5`)
+	assert.Contains(t, logs.String(), domain.EventCashSessionClosed)
+}
+
 func TestCashService_OpenSession_RegisterNotFound(t *testing.T) {
 	service, registerRepo, _, _, _ := setupCashService()
 	ctx := context.Background()
 
 	registerRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
 
-	input := OpenSessionInput{BranchID: 1, RegisterID: 999, UserID: 10}
+	input := OpenSessionInput{BranchID: 1, CashRegisterID: 999, UserID: 10}
 	result, err := service.OpenSession(ctx, input)
 
 	assert.Error(t, err)
@@ -226,7 +294,7 @@ func TestCashService_OpenSession_InactiveRegister(t *testing.T) {
 	register := &domain.CashRegister{ID: 1, BranchID: 1, IsActive: false}
 	registerRepo.On("GetByID", ctx, int64(1)).Return(register, nil)
 
-	input := OpenSessionInput{BranchID: 1, RegisterID: 1, UserID: 10}
+	input := OpenSessionInput{BranchID: 1, CashRegisterID: 1, UserID: 10}
 	result, err := service.OpenSession(ctx, input)
 
 	assert.Error(t, err)
@@ -241,7 +309,7 @@ func TestCashService_OpenSession_WrongBranch(t *testing.T) {
 	register := &domain.CashRegister{ID: 1, BranchID: 2, IsActive: true}
 	registerRepo.On("GetByID", ctx, int64(1)).Return(register, nil)
 
-	input := OpenSessionInput{BranchID: 1, RegisterID: 1, UserID: 10}
+	input := OpenSessionInput{BranchID: 1, CashRegisterID: 1, UserID: 10}
 	result, err := service.OpenSession(ctx, input)
 
 	assert.Error(t, err)
@@ -259,7 +327,7 @@ func TestCashService_OpenSession_UserAlreadyHasSession(t *testing.T) {
 	registerRepo.On("GetByID", ctx, int64(1)).Return(register, nil)
 	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(existingSession, nil)
 
-	input := OpenSessionInput{BranchID: 1, RegisterID: 1, UserID: 10}
+	input := OpenSessionInput{BranchID: 1, CashRegisterID: 1, UserID: 10}
 	result, err := service.OpenSession(ctx, input)
 
 	assert.Error(t, err)
@@ -278,22 +346,41 @@ func TestCashService_OpenSession_RegisterAlreadyHasSession(t *testing.T) {
 	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(nil, errors.New("none"))
 	sessionRepo.On("GetOpenSessionByRegister", ctx, int64(1)).Return(existingSession, nil)
 
-	input := OpenSessionInput{BranchID: 1, RegisterID: 1, UserID: 10}
+	input := OpenSessionInput{BranchID: 1, CashRegisterID: 1, UserID: 10}
 	result, err := service.OpenSession(ctx, input)
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrRegisterHasOpenSession)
+	assert.Nil(t, result)
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCashService_OpenSession_RegisterOpenedConcurrently(t *testing.T) {
+	service, registerRepo, sessionRepo, _, branchRepo := setupCashService()
+	ctx := context.Background()
+
+	// Another cashier opens the register between the check and the insert
+	registerRepo.On("GetByID", ctx, int64(1)).Return(&domain.CashRegister{ID: 1, BranchID: 1, IsActive: true}, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(nil, errors.New("none"))
+	sessionRepo.On("GetOpenSessionByRegister", ctx, int64(1)).Return(nil, errors.New("none"))
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1, Currency: "GTQ"}, nil)
+	sessionRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashSession")).Return(ErrRegisterHasOpenSession)
+
+	result, err := service.OpenSession(ctx, OpenSessionInput{BranchID: 1, CashRegisterID: 1, UserID: 10})
+
+	assert.ErrorIs(t, err, ErrRegisterHasOpenSession)
 	assert.Nil(t, result)
-	assert.Equal(t, "register already has an open session", err.Error())
 }
 
 func TestCashService_GetSession_Success(t *testing.T) {
 	service, _, sessionRepo, movementRepo, _ := setupCashService()
 	ctx := context.Background()
 
-	session := &domain.CashSession{ID: 1, BranchID: 1}
+	session := &domain.CashSession{ID: 1, BranchID: 1, Status: domain.CashSessionStatusClosed}
 	movements := []*domain.CashMovement{{ID: 1}, {ID: 2}}
+	denominations := []*domain.CashDenominationCount{{Currency: "GTQ", Denomination: 100, Count: 3}}
 
 	sessionRepo.On("GetByID", ctx, int64(1)).Return(session, nil)
+	sessionRepo.On("ListDenominations", ctx, int64(1)).Return(denominations, nil)
 	movementRepo.On("ListBySession", ctx, int64(1)).Return(movements, nil)
 
 	result, err := service.GetSession(ctx, 1)
@@ -301,6 +388,7 @@ func TestCashService_GetSession_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Len(t, result.Movements, 2)
+	assert.Equal(t, denominations, result.Denominations)
 	sessionRepo.AssertExpectations(t)
 	movementRepo.AssertExpectations(t)
 }
@@ -436,3 +524,450 @@ func TestCashService_ListSessionMovements_Success(t *testing.T) {
 	assert.Len(t, result, 3)
 	movementRepo.AssertExpectations(t)
 }
+
+// === Multi-currency Tests ===
+
+// twoCurrencySession is an open session holding quetzales and dollars whose
+// movements should leave Q1300 and $250 in the drawer
+func twoCurrencySession(movementRepo *mocks.MockCashMovementRepository) *domain.CashSession {
+	movementRepo.On("GetSessionSummary", mock.Anything, int64(5)).Return(&postgres.CashSessionSummary{CashIncome: 500, CashExpense: 200}, nil)
+	movementRepo.On("GetSessionCurrencySummary", mock.Anything, int64(5), "USD").Return(&postgres.CashSessionSummary{CashIncome: 80, CashExpense: 30}, nil)
+	return &domain.CashSession{
+		ID: 5, BranchID: 1, CashRegisterID: 2, UserID: 10, Status: domain.CashSessionStatusOpen,
+		Currency: "GTQ", OpeningAmount: 1000,
+		Currencies: []*domain.CashSessionCurrency{{Currency: "USD", OpeningAmount: 200}},
+	}
+}
+
+func TestCashService_CloseSession_DifferencePerCurrency(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	session := twoCurrencySession(movementRepo)
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(session, nil)
+
+	var closeData repository.CashSessionCloseData
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Run(func(args mock.Arguments) {
+		closeData = args.Get(2).(repository.CashSessionCloseData)
+	}).Return(nil)
+
+	// Quetzales are Q5 over, dollars $10 short
+	_, err := service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1305, ClosedBy: 10,
+		Currencies: []CashCurrencyAmount{{Currency: "USD", Amount: 240}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1300.0, closeData.ExpectedAmount)
+	assert.Equal(t, 5.0, closeData.Difference)
+	require.Len(t, closeData.Currencies, 1)
+	usd := closeData.Currencies[0]
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, 250.0, *usd.ExpectedAmount)
+	assert.Equal(t, 240.0, *usd.ClosingAmount)
+	assert.Equal(t, -10.0, *usd.Difference)
+}
+
+func TestCashService_CloseSession_RequiresEveryCurrency(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(twoCurrencySession(movementRepo), nil)
+
+	_, err := service.CloseSession(ctx, CloseSessionInput{SessionID: 5, ClosingAmount: 1300, ClosedBy: 10})
+	assert.ErrorContains(t, err, "USD")
+
+	_, err = service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300, ClosedBy: 10,
+		Currencies: []CashCurrencyAmount{{Currency: "USD", Amount: 250}, {Currency: "EUR", Amount: 10}},
+	})
+	assert.ErrorIs(t, err, ErrCurrencyNotInSession)
+
+	sessionRepo.AssertNotCalled(t, "Close", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCashService_CloseSession_StoresDenominationBreakdown(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(twoCurrencySession(movementRepo), nil)
+
+	var closeData repository.CashSessionCloseData
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Run(func(args mock.Arguments) {
+		closeData = args.Get(2).(repository.CashSessionCloseData)
+	}).Return(nil)
+
+	_, err := service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300.75, ClosedBy: 10,
+		Denominations: map[string]int{"0.25": 3, "100": 12, "50": 2},
+		Currencies:    []CashCurrencyAmount{{Currency: "USD", Amount: 250, Denominations: map[string]int{"50": 5}}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.CashDenominationCount{
+		{Currency: "GTQ", Denomination: 100, Count: 12},
+		{Currency: "GTQ", Denomination: 50, Count: 2},
+		{Currency: "GTQ", Denomination: 0.25, Count: 3},
+		{Currency: "USD", Denomination: 50, Count: 5},
+	}, closeData.Denominations)
+}
+
+func TestCashService_CloseSession_DenominationMismatch(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(twoCurrencySession(movementRepo), nil)
+
+	// Q1,250 counted against Q1,300 declared
+	_, err := service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300, ClosedBy: 10,
+		Denominations: map[string]int{"100": 12, "50": 1},
+		Currencies:    []CashCurrencyAmount{{Currency: "USD", Amount: 250}},
+	})
+	assert.ErrorIs(t, err, ErrDenominationMismatch)
+
+	// The other currencies are counted too
+	_, err = service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300, ClosedBy: 10,
+		Currencies: []CashCurrencyAmount{{Currency: "USD", Amount: 250, Denominations: map[string]int{"20": 12}}},
+	})
+	assert.ErrorIs(t, err, ErrDenominationMismatch)
+
+	_, err = service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300, ClosedBy: 10,
+		Denominations: map[string]int{"cien": 13},
+		Currencies:    []CashCurrencyAmount{{Currency: "USD", Amount: 250}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidDenomination)
+	assert.ErrorContains(t, err, "cien")
+
+	sessionRepo.AssertNotCalled(t, "Close", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCashService_CloseSession_MergesEqualDenominations(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(twoCurrencySession(movementRepo), nil)
+
+	var closeData repository.CashSessionCloseData
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Run(func(args mock.Arguments) {
+		closeData = args.Get(2).(repository.CashSessionCloseData)
+	}).Return(nil)
+
+	_, err := service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300, ClosedBy: 10,
+		Denominations: map[string]int{"100": 10, "100.00": 3},
+		Currencies:    []CashCurrencyAmount{{Currency: "USD", Amount: 250}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.CashDenominationCount{
+		{Currency: "GTQ", Denomination: 100, Count: 13},
+	}, closeData.Denominations)
+}
+
+func TestCashService_CloseSession_RejectsSubCentDenomination(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(twoCurrencySession(movementRepo), nil)
+
+	_, err := service.CloseSession(ctx, CloseSessionInput{
+		SessionID: 5, ClosingAmount: 1300, ClosedBy: 10,
+		Denominations: map[string]int{"100": 13, "0.001": 4},
+		Currencies:    []CashCurrencyAmount{{Currency: "USD", Amount: 250}},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidDenomination)
+	sessionRepo.AssertNotCalled(t, "Close", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCashService_CreateMovement_KeepsCurrencyBalancesApart(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(twoCurrencySession(movementRepo), nil)
+	movementRepo.On("GetSessionCurrencyBalance", ctx, int64(5), "USD").Return(250.0, nil)
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Return(nil)
+
+	movement, err := service.CreateMovement(ctx, CreateMovementInput{
+		SessionID: 5, MovementType: "expense", Amount: 20, PaymentMethod: "cash", Currency: "USD", Description: "Cambio", CreatedBy: 10,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", movement.Currency)
+	assert.Equal(t, 230.0, movement.BalanceAfter)
+	movementRepo.AssertNotCalled(t, "GetSessionBalance", mock.Anything, mock.Anything)
+
+	// Dollars cannot pay out more than the dollars in the drawer
+	_, err = service.CreateMovement(ctx, CreateMovementInput{
+		SessionID: 5, MovementType: "expense", Amount: 300, PaymentMethod: "cash", Currency: "USD", Description: "Cambio", CreatedBy: 10,
+	})
+	assert.Error(t, err)
+
+	_, err = service.CreateMovement(ctx, CreateMovementInput{
+		SessionID: 5, MovementType: "income", Amount: 20, PaymentMethod: "cash", Currency: "EUR", Description: "Pago", CreatedBy: 10,
+	})
+	assert.ErrorIs(t, err, ErrCurrencyNotInSession)
+}
+
+// === Reconciliation Tests ===
+
+func setupCashReconciliation(payments []domain.Payment, sales []domain.Sale, movements []*domain.CashMovement) *CashService {
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	saleRepo := new(mocks.MockSaleRepository)
+
+	sessionRepo.On("GetByID", mock.Anything, int64(1)).Return(&domain.CashSession{ID: 1, Status: domain.CashSessionStatusOpen}, nil)
+	paymentRepo.On("List", mock.Anything, mock.AnythingOfType("repository.PaymentListParams")).
+		Return(&repository.PaginatedResult[domain.Payment]{Data: payments, Total: len(payments)}, nil)
+	saleRepo.On("List", mock.Anything, mock.AnythingOfType("repository.SaleListParams")).
+		Return(&repository.PaginatedResult[domain.Sale]{Data: sales, Total: len(sales)}, nil)
+	movementRepo.On("ListBySession", mock.Anything, int64(1)).Return(movements, nil)
+
+	return NewCashService(new(mocks.MockCashRegisterRepository), sessionRepo, movementRepo, new(mocks.MockBranchRepository), paymentRepo, saleRepo)
+}
+
+func cashIncome(id int64, refType string, refID int64, amount float64) *domain.CashMovement {
+	return &domain.CashMovement{
+		ID:            id,
+		SessionID:     1,
+		MovementType:  domain.CashMovementTypeIncome,
+		PaymentMethod: domain.PaymentMethodCash,
+		ReferenceType: &refType,
+		ReferenceID:   &refID,
+		Amount:        amount,
+	}
+}
+
+func TestCashService_Reconcile_Balanced(t *testing.T) {
+	service := setupCashReconciliation(
+		[]domain.Payment{{ID: 10, Amount: 150, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted}},
+		[]domain.Sale{{ID: 20, FinalPrice: 300, PaymentMethod: domain.PaymentMethodCash, Status: domain.SaleStatusCompleted}},
+		[]*domain.CashMovement{cashIncome(1, "payment", 10, 150), cashIncome(2, "sale", 20, 300)},
+	)
+
+	result, err := service.Reconcile(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsReconciled)
+	assert.Empty(t, result.Issues)
+	assert.Equal(t, 1, result.PaymentsChecked)
+	assert.Equal(t, 1, result.SalesChecked)
+	assert.Equal(t, 2, result.MovementsChecked)
+}
+
+func TestCashService_Reconcile_PaymentWithoutMovement(t *testing.T) {
+	service := setupCashReconciliation(
+		[]domain.Payment{
+			{ID: 10, Amount: 150, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted},
+			{ID: 11, Amount: 75, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted},
+		},
+		nil,
+		[]*domain.CashMovement{cashIncome(1, "payment", 10, 150)},
+	)
+
+	result, err := service.Reconcile(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.False(t, result.IsReconciled)
+	assert.Len(t, result.Issues, 1)
+	issue := result.Issues[0]
+	assert.Equal(t, ReconciliationMissingMovement, issue.Type)
+	assert.Equal(t, "payment", issue.ReferenceType)
+	assert.Equal(t, int64(11), *issue.ReferenceID)
+	assert.Equal(t, 75.0, issue.ExpectedAmount)
+	assert.Nil(t, issue.MovementID)
+}
+
+func TestCashService_Reconcile_OrphanAndMismatchedMovements(t *testing.T) {
+	service := setupCashReconciliation(
+		nil,
+		[]domain.Sale{{ID: 20, FinalPrice: 300, PaymentMethod: domain.PaymentMethodCash, Status: domain.SaleStatusCompleted}},
+		[]*domain.CashMovement{
+			cashIncome(1, "sale", 20, 250),
+			cashIncome(2, "payment", 99, 40),
+			{ID: 3, MovementType: domain.CashMovementTypeIncome, PaymentMethod: domain.PaymentMethodCash, Amount: 500}, // manual deposit
+		},
+	)
+
+	result, err := service.Reconcile(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.False(t, result.IsReconciled)
+	assert.Len(t, result.Issues, 2)
+	assert.Equal(t, ReconciliationAmountMismatch, result.Issues[0].Type)
+	assert.Equal(t, 300.0, result.Issues[0].ExpectedAmount)
+	assert.Equal(t, 250.0, result.Issues[0].RecordedAmount)
+	assert.Equal(t, ReconciliationOrphanMovement, result.Issues[1].Type)
+	assert.Equal(t, int64(2), *result.Issues[1].MovementID)
+}
+
+func TestCashService_Reconcile_ReadsEveryPage(t *testing.T) {
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	saleRepo := new(mocks.MockSaleRepository)
+	service := NewCashService(new(mocks.MockCashRegisterRepository), sessionRepo, movementRepo, new(mocks.MockBranchRepository), paymentRepo, saleRepo)
+
+	sessionRepo.On("GetByID", mock.Anything, int64(1)).Return(&domain.CashSession{ID: 1, Status: domain.CashSessionStatusOpen}, nil)
+	paymentPage := func(page int) interface{} {
+		return mock.MatchedBy(func(params repository.PaymentListParams) bool { return params.Page == page })
+	}
+	paymentRepo.On("List", mock.Anything, paymentPage(1)).Return(&repository.PaginatedResult[domain.Payment]{
+		Data:       []domain.Payment{{ID: 10, Amount: 150, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted}},
+		TotalPages: 2,
+	}, nil)
+	paymentRepo.On("List", mock.Anything, paymentPage(2)).Return(&repository.PaginatedResult[domain.Payment]{
+		Data:       []domain.Payment{{ID: 11, Amount: 75, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted}},
+		TotalPages: 2,
+	}, nil)
+	saleRepo.On("List", mock.Anything, mock.AnythingOfType("repository.SaleListParams")).
+		Return(&repository.PaginatedResult[domain.Sale]{TotalPages: 1}, nil)
+	movementRepo.On("ListBySession", mock.Anything, int64(1)).Return([]*domain.CashMovement{cashIncome(1, "payment", 10, 150)}, nil)
+
+	result, err := service.Reconcile(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.PaymentsChecked)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, ReconciliationMissingMovement, result.Issues[0].Type)
+	assert.Equal(t, int64(11), *result.Issues[0].ReferenceID)
+	paymentRepo.AssertNumberOfCalls(t, "List", 2)
+}
+
+func TestCashService_Reconcile_SessionNotFound(t *testing.T) {
+	service, _, sessionRepo, _, _ := setupCashService()
+	ctx := context.Background()
+
+	sessionRepo.On("GetByID", ctx, int64(404)).Return(nil, errors.New("not found"))
+
+	result, err := service.Reconcile(ctx, 404)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "cash session not found")
+}
+
+func TestCashService_CheckOpenSessions_NotifiesPastWarningThreshold(t *testing.T) {
+	service, _, sessionRepo, _, _ := setupCashService()
+	router, m := setupNotificationRouter([]domain.NotificationRoute{
+		{Event: domain.InternalEventCashSessionOpen, Roles: []string{domain.RoleManager}, BranchOnly: true},
+	})
+	service.SetNotificationRouter(router)
+	settingRepo := new(mocks.MockSettingRepository)
+	service.SetEvents(nil, settingRepo)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	branchID, roleID, active := int64(1), int64(3), true
+
+	settingRepo.On("Get", ctx, SettingCashSessionAutoCloseHours, &branchID).Return(nil, errors.New("setting not found"))
+	settingRepo.On("Get", ctx, SettingCashSessionWarningHours, &branchID).Return(&domain.Setting{Value: float64(10)}, nil)
+
+	notifiedAt := now.Add(-time.Hour)
+	forgotten := &domain.CashSession{ID: 5, BranchID: 1, UserID: 10, Status: domain.CashSessionStatusOpen, OpenedAt: now.Add(-13 * time.Hour)}
+	alreadyNotified := &domain.CashSession{ID: 6, BranchID: 1, UserID: 11, Status: domain.CashSessionStatusOpen, OpenedAt: now.Add(-15 * time.Hour), OverdueNotifiedAt: &notifiedAt}
+	recent := &domain.CashSession{ID: 7, BranchID: 1, UserID: 12, Status: domain.CashSessionStatusOpen, OpenedAt: now.Add(-2 * time.Hour)}
+	sessionRepo.On("ListOpen", ctx).Return([]*domain.CashSession{alreadyNotified, forgotten, recent}, nil)
+	sessionRepo.On("MarkOverdueNotified", ctx, int64(5), now).Return(nil)
+
+	m.roleRepo.On("GetByName", ctx, domain.RoleManager).Return(&domain.Role{ID: roleID, Name: domain.RoleManager}, nil)
+	m.userRepo.On("List", ctx, repository.UserListParams{RoleID: &roleID, IsActive: &active, BranchID: &branchID, PaginationParams: repository.PaginationParams{Page: 1, PerPage: routeUserPageSize}}).
+		Return(&repository.PaginatedResult[domain.User]{Data: []domain.User{{ID: 20}}}, nil)
+	var created []*domain.InternalNotification
+	m.internalRepo.On("CreateBulk", ctx, mock.AnythingOfType("[]*domain.InternalNotification")).Run(func(args mock.Arguments) {
+		created = args.Get(1).([]*domain.InternalNotification)
+	}).Return(nil).Once()
+
+	result, err := service.CheckOpenSessions(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Notified)
+	assert.Equal(t, 0, result.AutoClosed)
+	// The cashier and the branch manager hear about it
+	require.Len(t, created, 2)
+	assert.Equal(t, int64(10), created[0].UserID)
+	assert.Equal(t, int64(20), created[1].UserID)
+	assert.Equal(t, int64(5), *created[0].ReferenceID)
+	sessionRepo.AssertExpectations(t)
+	sessionRepo.AssertNotCalled(t, "Close", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCashService_CheckOpenSessions_AutoClosesPastAutoCloseThreshold(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	eventRepo := new(mocks.MockEventRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	service.SetEvents(NewEventService(eventRepo, new(mocks.MockWebhookRepository), nil, zerolog.Nop()), settingRepo)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	branchID := int64(1)
+
+	settingRepo.On("Get", ctx, SettingCashSessionAutoCloseHours, &branchID).Return(&domain.Setting{Value: float64(24)}, nil)
+
+	session := &domain.CashSession{ID: 5, BranchID: 1, CashRegisterID: 2, UserID: 10, OpeningAmount: 1000, Status: domain.CashSessionStatusOpen, OpenedAt: now.Add(-30 * time.Hour)}
+	sessionRepo.On("ListOpen", ctx).Return([]*domain.CashSession{session}, nil)
+	movementRepo.On("GetSessionSummary", ctx, int64(5)).Return(&postgres.CashSessionSummary{CashIncome: 500, CashExpense: 200}, nil)
+
+	var closeData repository.CashSessionCloseData
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Run(func(args mock.Arguments) {
+		closeData = args.Get(2).(repository.CashSessionCloseData)
+	}).Return(nil)
+
+	var emitted *domain.Event
+	eventRepo.On("Create", ctx, mock.AnythingOfType("*domain.Event")).Run(func(args mock.Arguments) {
+		emitted = args.Get(1).(*domain.Event)
+	}).Return(nil)
+
+	result, err := service.CheckOpenSessions(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.AutoClosed)
+	assert.Equal(t, 0, result.Notified)
+	assert.True(t, closeData.AutoClosed)
+	assert.Equal(t, int64(0), closeData.ClosedBy)
+	assert.Equal(t, 1300.0, closeData.ClosingAmount)
+	assert.Equal(t, 1300.0, closeData.ExpectedAmount)
+	assert.Contains(t, closeData.ClosingNotes, "Cierre automático")
+	require.NotNil(t, emitted)
+	assert.Equal(t, domain.EventCashSessionAutoClosed, emitted.EventType)
+	assert.Nil(t, emitted.UserID)
+	assert.Equal(t, int64(10), emitted.Payload["cashier_id"])
+	sessionRepo.AssertNotCalled(t, "MarkOverdueNotified", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCashService_CheckOpenSessions_ContinuesPastFailedSession(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	settingRepo := new(mocks.MockSettingRepository)
+	service.SetEvents(nil, settingRepo)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	branchID := int64(1)
+
+	settingRepo.On("Get", ctx, SettingCashSessionAutoCloseHours, &branchID).Return(&domain.Setting{Value: float64(24)}, nil)
+
+	failing := &domain.CashSession{ID: 5, BranchID: 1, UserID: 10, OpeningAmount: 1000, Status: domain.CashSessionStatusOpen, OpenedAt: now.Add(-30 * time.Hour)}
+	next := &domain.CashSession{ID: 6, BranchID: 1, UserID: 11, OpeningAmount: 500, Status: domain.CashSessionStatusOpen, OpenedAt: now.Add(-26 * time.Hour)}
+	sessionRepo.On("ListOpen", ctx).Return([]*domain.CashSession{failing, next}, nil)
+	movementRepo.On("GetSessionSummary", ctx, mock.AnythingOfType("int64")).Return(&postgres.CashSessionSummary{}, nil)
+	sessionRepo.On("Close", ctx, int64(5), mock.AnythingOfType("repository.CashSessionCloseData")).Return(errors.New("connection reset"))
+	sessionRepo.On("Close", ctx, int64(6), mock.AnythingOfType("repository.CashSessionCloseData")).Return(nil)
+
+	result, err := service.CheckOpenSessions(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.AutoClosed)
+	assert.Equal(t, 1, result.Failed)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestCashService_GetBranchCashOnHand_BalanceError(t *testing.T) {
+	service, _, sessionRepo, movementRepo, _ := setupCashService()
+	ctx := context.Background()
+
+	sessionRepo.On("List", ctx, mock.AnythingOfType("repository.CashSessionListParams")).Return(&repository.PaginatedResult[domain.CashSession]{
+		Data: []domain.CashSession{{ID: 5, OpeningAmount: 1000}, {ID: 6, OpeningAmount: 500}},
+	}, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(5)).Return(1200.0, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(6)).Return(0.0, errors.New("db error"))
+
+	// A stale opening amount would hide a low float, so the error is returned
+	total, sessions, err := service.GetBranchCashOnHand(ctx, 1)
+
+	assert.Error(t, err)
+	assert.Zero(t, total)
+	assert.Zero(t, sessions)
+}
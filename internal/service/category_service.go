@@ -28,9 +28,13 @@ type CreateCategoryInput struct {
 	Description         *string  `json:"description"`
 	Icon                *string  `json:"icon"`
 	DefaultInterestRate float64  `json:"default_interest_rate" validate:"gte=0,lte=100"`
+	DefaultLateFeeRate  *float64 `json:"default_late_fee_rate" validate:"omitempty,gte=0,lte=100"`
 	MinLoanAmount       *float64 `json:"min_loan_amount" validate:"omitempty,gte=0"`
 	MaxLoanAmount       *float64 `json:"max_loan_amount" validate:"omitempty,gte=0"`
+	MinLoanTermDays     *int     `json:"min_loan_term_days" validate:"omitempty,gte=0"`
+	MaxLoanTermDays     *int     `json:"max_loan_term_days" validate:"omitempty,gte=0"`
 	LoanToValueRatio    float64  `json:"loan_to_value_ratio" validate:"gte=0,lte=1"`
+	WarrantyDays        *int     `json:"warranty_days" validate:"omitempty,gte=0,lte=3650"`
 	SortOrder           int      `json:"sort_order"`
 }
 
@@ -64,6 +68,13 @@ func (s *CategoryService) Create(ctx context.Context, input CreateCategoryInput)
 		}
 	}
 
+	// Validate min/max loan terms
+	if input.MinLoanTermDays != nil && input.MaxLoanTermDays != nil {
+		if *input.MinLoanTermDays > *input.MaxLoanTermDays {
+			return nil, errors.New("min loan term cannot exceed max loan term")
+		}
+	}
+
 	category := &domain.Category{
 		Name:                input.Name,
 		Slug:                slug,
@@ -71,9 +82,13 @@ func (s *CategoryService) Create(ctx context.Context, input CreateCategoryInput)
 		Description:         input.Description,
 		Icon:                input.Icon,
 		DefaultInterestRate: input.DefaultInterestRate,
+		DefaultLateFeeRate:  input.DefaultLateFeeRate,
 		MinLoanAmount:       input.MinLoanAmount,
 		MaxLoanAmount:       input.MaxLoanAmount,
+		MinLoanTermDays:     input.MinLoanTermDays,
+		MaxLoanTermDays:     input.MaxLoanTermDays,
 		LoanToValueRatio:    input.LoanToValueRatio,
+		WarrantyDays:        input.WarrantyDays,
 		SortOrder:           input.SortOrder,
 		IsActive:            true,
 	}
@@ -91,9 +106,13 @@ type UpdateCategoryInput struct {
 	Description         *string  `json:"description"`
 	Icon                *string  `json:"icon"`
 	DefaultInterestRate *float64 `json:"default_interest_rate" validate:"omitempty,gte=0,lte=100"`
+	DefaultLateFeeRate  *float64 `json:"default_late_fee_rate" validate:"omitempty,gte=0,lte=100"`
 	MinLoanAmount       *float64 `json:"min_loan_amount" validate:"omitempty,gte=0"`
 	MaxLoanAmount       *float64 `json:"max_loan_amount" validate:"omitempty,gte=0"`
+	MinLoanTermDays     *int     `json:"min_loan_term_days" validate:"omitempty,gte=0"`
+	MaxLoanTermDays     *int     `json:"max_loan_term_days" validate:"omitempty,gte=0"`
 	LoanToValueRatio    *float64 `json:"loan_to_value_ratio" validate:"omitempty,gte=0,lte=1"`
+	WarrantyDays        *int     `json:"warranty_days" validate:"omitempty,gte=0,lte=3650"`
 	SortOrder           *int     `json:"sort_order"`
 	IsActive            *bool    `json:"is_active"`
 }
@@ -119,15 +138,27 @@ func (s *CategoryService) Update(ctx context.Context, id int64, input UpdateCate
 	if input.DefaultInterestRate != nil {
 		category.DefaultInterestRate = *input.DefaultInterestRate
 	}
+	if input.DefaultLateFeeRate != nil {
+		category.DefaultLateFeeRate = input.DefaultLateFeeRate
+	}
 	if input.MinLoanAmount != nil {
 		category.MinLoanAmount = input.MinLoanAmount
 	}
 	if input.MaxLoanAmount != nil {
 		category.MaxLoanAmount = input.MaxLoanAmount
 	}
+	if input.MinLoanTermDays != nil {
+		category.MinLoanTermDays = input.MinLoanTermDays
+	}
+	if input.MaxLoanTermDays != nil {
+		category.MaxLoanTermDays = input.MaxLoanTermDays
+	}
 	if input.LoanToValueRatio != nil {
 		category.LoanToValueRatio = *input.LoanToValueRatio
 	}
+	if input.WarrantyDays != nil {
+		category.WarrantyDays = input.WarrantyDays
+	}
 	if input.SortOrder != nil {
 		category.SortOrder = *input.SortOrder
 	}
@@ -142,6 +173,13 @@ func (s *CategoryService) Update(ctx context.Context, id int64, input UpdateCate
 		}
 	}
 
+	// Validate min/max loan terms
+	if category.MinLoanTermDays != nil && category.MaxLoanTermDays != nil {
+		if *category.MinLoanTermDays > *category.MaxLoanTermDays {
+			return nil, errors.New("min loan term cannot exceed max loan term")
+		}
+	}
+
 	if err := s.categoryRepo.Update(ctx, category); err != nil {
 		return nil, fmt.Errorf("failed to update category: %w", err)
 	}
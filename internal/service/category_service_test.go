@@ -201,6 +201,28 @@ func TestCategoryService_Update_MinExceedsMax(t *testing.T) {
 	categoryRepo.AssertExpectations(t)
 }
 
+func TestCategoryService_Update_MinTermExceedsMax(t *testing.T) {
+	service, categoryRepo := setupCategoryService()
+	ctx := context.Background()
+
+	minTerm := 60
+	existing := &domain.Category{
+		ID:              1,
+		MinLoanTermDays: &minTerm,
+	}
+
+	categoryRepo.On("GetByID", ctx, int64(1)).Return(existing, nil)
+
+	newMax := 30
+	input := UpdateCategoryInput{MaxLoanTermDays: &newMax}
+	result, err := service.Update(ctx, 1, input)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "min loan term cannot exceed max loan term", err.Error())
+	categoryRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 func TestCategoryService_GetByID_Success(t *testing.T) {
 	service, categoryRepo := setupCategoryService()
 	ctx := context.Background()
@@ -344,3 +366,18 @@ func TestGenerateSlug_LeadingTrailingSpaces(t *testing.T) {
 func TestGenerateSlug_NumbersPreserved(t *testing.T) {
 	assert.Equal(t, "category-123", generateSlug("Category 123"))
 }
+
+func TestCategoryService_Delete_InUse(t *testing.T) {
+	service, categoryRepo := setupCategoryService()
+	ctx := context.Background()
+
+	category := &domain.Category{ID: 1}
+	categoryRepo.On("GetByID", ctx, int64(1)).Return(category, nil)
+	categoryRepo.On("Delete", ctx, int64(1)).Return(&repository.InUseError{Entity: "category", ReferencedBy: "items"})
+
+	err := service.Delete(ctx, 1)
+
+	assert.True(t, errors.Is(err, ErrInUse))
+	assert.Contains(t, err.Error(), "items")
+	categoryRepo.AssertExpectations(t)
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingCollectionsOverdueDays is how many days past due a loan enters the
+// collections queue; 0 stops new loans from entering it
+const SettingCollectionsOverdueDays = "collections_overdue_days"
+
+// DefaultCollectionsOverdueDays is used when the setting is not configured
+const DefaultCollectionsOverdueDays = 15
+
+// collectionLoanPageSize is how many open loans are scanned per query
+const collectionLoanPageSize = 100
+
+// Collection errors
+var (
+	ErrCollectionCaseNotFound = errors.New("collection case not found")
+	ErrCollectionCaseResolved = errors.New("collection case is already resolved")
+)
+
+// CollectionService keeps the queue of aging overdue loans staff follow up on
+type CollectionService struct {
+	collectionRepo repository.CollectionRepository
+	loanRepo       repository.LoanRepository
+	userRepo       repository.UserRepository
+	settingRepo    repository.SettingRepository
+	logger         zerolog.Logger
+}
+
+// NewCollectionService creates a new CollectionService
+func NewCollectionService(
+	collectionRepo repository.CollectionRepository,
+	loanRepo repository.LoanRepository,
+	userRepo repository.UserRepository,
+	settingRepo repository.SettingRepository,
+	logger zerolog.Logger,
+) *CollectionService {
+	return &CollectionService{
+		collectionRepo: collectionRepo,
+		loanRepo:       loanRepo,
+		userRepo:       userRepo,
+		settingRepo:    settingRepo,
+		logger:         logger.With().Str("service", "collections").Logger(),
+	}
+}
+
+// CollectionRefreshResult summarizes a run of the queue job
+type CollectionRefreshResult struct {
+	Entered  int `json:"entered"`
+	Updated  int `json:"updated"`
+	Resolved int `json:"resolved"`
+}
+
+// RefreshQueue places the loans past their branch's overdue threshold in the
+// queue, refreshes the balance of the cases already in it and resolves the
+// cases whose loan is no longer overdue
+func (s *CollectionService) RefreshQueue(ctx context.Context, now time.Time) (*CollectionRefreshResult, error) {
+	overdue, err := s.overdueLoans(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.collectionRepo.ListOpen(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CollectionRefreshResult{}
+	queued := make(map[int64]bool, len(open))
+	for _, collectionCase := range open {
+		queued[collectionCase.LoanID] = true
+
+		loan, ok := overdue[collectionCase.LoanID]
+		if !ok {
+			if err := s.collectionRepo.Resolve(ctx, collectionCase.ID, now); err != nil {
+				s.logger.Error().Err(err).Int64("case_id", collectionCase.ID).Msg("Failed to resolve collection case")
+				continue
+			}
+			result.Resolved++
+			continue
+		}
+
+		daysOverdue := loan.DaysPastDueAt(now)
+		amountDue := roundCents(loan.RemainingBalance())
+		if collectionCase.DaysOverdue == daysOverdue && collectionCase.AmountDue == amountDue {
+			continue
+		}
+		collectionCase.DaysOverdue = daysOverdue
+		collectionCase.AmountDue = amountDue
+		if err := s.collectionRepo.Update(ctx, collectionCase); err != nil {
+			s.logger.Error().Err(err).Int64("case_id", collectionCase.ID).Msg("Failed to refresh collection case")
+			continue
+		}
+		result.Updated++
+	}
+
+	for _, loan := range overdue {
+		if queued[loan.ID] {
+			continue
+		}
+		threshold := getSettingInt(ctx, s.settingRepo, SettingCollectionsOverdueDays, &loan.BranchID, DefaultCollectionsOverdueDays)
+		daysOverdue := loan.DaysPastDueAt(now)
+		if threshold <= 0 || daysOverdue < threshold {
+			continue
+		}
+
+		created, err := s.collectionRepo.Create(ctx, &domain.CollectionCase{
+			LoanID:      loan.ID,
+			CustomerID:  loan.CustomerID,
+			BranchID:    loan.BranchID,
+			Status:      domain.CollectionStatusInProgress,
+			DaysOverdue: daysOverdue,
+			AmountDue:   roundCents(loan.RemainingBalance()),
+		})
+		if err != nil {
+			s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to place loan in collections queue")
+			continue
+		}
+		if created {
+			result.Entered++
+		}
+	}
+
+	return result, nil
+}
+
+// overdueLoans maps the open loans that are past due by loan ID
+func (s *CollectionService) overdueLoans(ctx context.Context, now time.Time) (map[int64]*domain.Loan, error) {
+	loans := make(map[int64]*domain.Loan)
+	for _, status := range []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusOverdue} {
+		for page := 1; ; page++ {
+			result, err := s.loanRepo.List(ctx, repository.LoanListParams{
+				PaginationParams: repository.PaginationParams{Page: page, PerPage: collectionLoanPageSize},
+				Status:           &status,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for i := range result.Data {
+				loan := &result.Data[i]
+				if loan.DaysPastDueAt(now) > 0 {
+					loans[loan.ID] = loan
+				}
+			}
+			if page >= result.TotalPages {
+				break
+			}
+		}
+	}
+	return loans, nil
+}
+
+// List retrieves collection cases
+func (s *CollectionService) List(ctx context.Context, params repository.CollectionListParams) (*repository.PaginatedResult[domain.CollectionCase], error) {
+	return s.collectionRepo.List(ctx, params)
+}
+
+// GetByID retrieves a collection case with its contact attempts
+func (s *CollectionService) GetByID(ctx context.Context, id int64) (*domain.CollectionCase, error) {
+	collectionCase, err := s.collectionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrCollectionCaseNotFound
+	}
+
+	contacts, err := s.collectionRepo.ListContacts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	collectionCase.Contacts = contacts
+	return collectionCase, nil
+}
+
+// getOpen retrieves a case staff can still work on
+func (s *CollectionService) getOpen(ctx context.Context, id int64) (*domain.CollectionCase, error) {
+	collectionCase, err := s.collectionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrCollectionCaseNotFound
+	}
+	if !collectionCase.IsOpen() {
+		return nil, ErrCollectionCaseResolved
+	}
+	return collectionCase, nil
+}
+
+// AssignCollectionInput represents assign collection case request data
+type AssignCollectionInput struct {
+	UserID int64 `json:"user_id" validate:"required"`
+}
+
+// Assign assigns a case to a staff member
+func (s *CollectionService) Assign(ctx context.Context, id int64, input AssignCollectionInput) (*domain.CollectionCase, error) {
+	collectionCase, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, input.UserID)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, ErrUserNotFound
+	}
+
+	collectionCase.AssignedTo = &user.ID
+	if err := s.collectionRepo.Update(ctx, collectionCase); err != nil {
+		return nil, err
+	}
+	return collectionCase, nil
+}
+
+// UpdateCollectionStatusInput represents update collection case status request data
+type UpdateCollectionStatusInput struct {
+	Status        domain.CollectionStatus `json:"status" validate:"required"`
+	PromiseDate   *domain.Date            `json:"promise_date"`
+	PromiseAmount *float64                `json:"promise_amount"`
+	Notes         *string                 `json:"notes"`
+}
+
+// UpdateStatus moves a case along the follow-up. A promise to pay needs the
+// date the customer promised to pay by.
+func (s *CollectionService) UpdateStatus(ctx context.Context, id int64, input UpdateCollectionStatusInput) (*domain.CollectionCase, error) {
+	if !input.Status.IsValid() {
+		return nil, fmt.Errorf("%w: invalid collection status %q", ErrInvalidInput, input.Status)
+	}
+
+	collectionCase, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status == domain.CollectionStatusPromiseToPay {
+		if input.PromiseDate == nil {
+			return nil, fmt.Errorf("%w: promise_date is required for a promise to pay", ErrInvalidInput)
+		}
+		if input.PromiseDate.Before(domain.Today().Time) {
+			return nil, fmt.Errorf("%w: promise_date cannot be in the past", ErrInvalidInput)
+		}
+		if input.PromiseAmount != nil && *input.PromiseAmount <= 0 {
+			return nil, fmt.Errorf("%w: promise_amount must be positive", ErrInvalidInput)
+		}
+		collectionCase.PromiseDate = input.PromiseDate
+		collectionCase.PromiseAmount = input.PromiseAmount
+	} else {
+		collectionCase.PromiseDate = nil
+		collectionCase.PromiseAmount = nil
+	}
+
+	collectionCase.Status = input.Status
+	if input.Notes != nil {
+		collectionCase.Notes = input.Notes
+	}
+
+	if err := s.collectionRepo.Update(ctx, collectionCase); err != nil {
+		return nil, err
+	}
+	return collectionCase, nil
+}
+
+// LogCollectionContactInput represents a contact attempt on a collection case
+type LogCollectionContactInput struct {
+	Method      domain.CollectionContactMethod `json:"method" validate:"required"`
+	Reached     bool                           `json:"reached"`
+	Notes       *string                        `json:"notes"`
+	ContactedBy int64                          `json:"-"`
+}
+
+// LogContact records an attempt to contact the customer of a case
+func (s *CollectionService) LogContact(ctx context.Context, id int64, input LogCollectionContactInput) (*domain.CollectionContact, error) {
+	if !input.Method.IsValid() {
+		return nil, fmt.Errorf("%w: invalid contact method %q", ErrInvalidInput, input.Method)
+	}
+
+	collectionCase, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contact := &domain.CollectionContact{
+		CaseID:      collectionCase.ID,
+		Method:      input.Method,
+		Reached:     input.Reached,
+		Notes:       input.Notes,
+		ContactedBy: input.ContactedBy,
+	}
+	if err := s.collectionRepo.AddContact(ctx, contact); err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// CollectionAgeRow is the outstanding balance of the cases in an overdue age range
+type CollectionAgeRow struct {
+	Bucket      string  `json:"bucket"`
+	Cases       int     `json:"cases"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// CollectionAssigneeRow is the outstanding balance of the cases assigned to a
+// staff member; AssignedTo is nil for the unassigned cases
+type CollectionAssigneeRow struct {
+	AssignedTo   *int64  `json:"assigned_to"`
+	AssigneeName string  `json:"assignee_name,omitempty"`
+	Cases        int     `json:"cases"`
+	Outstanding  float64 `json:"outstanding"`
+}
+
+// CollectionsReport breaks down the balance still in the queue
+type CollectionsReport struct {
+	TotalCases       int                     `json:"total_cases"`
+	TotalOutstanding float64                 `json:"total_outstanding"`
+	ByStatus         map[string]int          `json:"by_status"`
+	ByAge            []CollectionAgeRow      `json:"by_age"`
+	ByAssignee       []CollectionAssigneeRow `json:"by_assignee"`
+}
+
+// collectionAgeBuckets lists the report's age ranges in order
+var collectionAgeBuckets = []string{"1-30", "31-60", "61-90", "90+"}
+
+// Report summarizes the outstanding balance in the queue by overdue age and
+// assignee; branchID 0 covers every branch
+func (s *CollectionService) Report(ctx context.Context, branchID int64) (*CollectionsReport, error) {
+	open, err := s.collectionRepo.ListOpen(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CollectionsReport{ByStatus: make(map[string]int)}
+	byAge := make(map[string]*CollectionAgeRow, len(collectionAgeBuckets))
+	for _, bucket := range collectionAgeBuckets {
+		row := &CollectionAgeRow{Bucket: bucket}
+		byAge[bucket] = row
+	}
+	byAssignee := make(map[int64]*CollectionAssigneeRow)
+
+	for _, collectionCase := range open {
+		report.TotalCases++
+		report.TotalOutstanding += collectionCase.AmountDue
+		report.ByStatus[string(collectionCase.Status)]++
+
+		age := byAge[domain.CollectionAgeBucket(collectionCase.DaysOverdue)]
+		age.Cases++
+		age.Outstanding += collectionCase.AmountDue
+
+		var assignee int64
+		if collectionCase.AssignedTo != nil {
+			assignee = *collectionCase.AssignedTo
+		}
+		row, ok := byAssignee[assignee]
+		if !ok {
+			row = &CollectionAssigneeRow{AssignedTo: collectionCase.AssignedTo}
+			if collectionCase.AssignedTo != nil {
+				if user, err := s.userRepo.GetByID(ctx, assignee); err == nil && user != nil {
+					row.AssigneeName = user.FullName()
+				}
+			}
+			byAssignee[assignee] = row
+		}
+		row.Cases++
+		row.Outstanding += collectionCase.AmountDue
+	}
+
+	report.TotalOutstanding = roundCents(report.TotalOutstanding)
+	for _, bucket := range collectionAgeBuckets {
+		row := byAge[bucket]
+		row.Outstanding = roundCents(row.Outstanding)
+		report.ByAge = append(report.ByAge, *row)
+	}
+	for _, row := range byAssignee {
+		row.Outstanding = roundCents(row.Outstanding)
+		report.ByAssignee = append(report.ByAssignee, *row)
+	}
+	sort.Slice(report.ByAssignee, func(i, j int) bool {
+		return report.ByAssignee[i].Outstanding > report.ByAssignee[j].Outstanding
+	})
+
+	return report, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupCollectionService() (*CollectionService, *mocks.MockCollectionRepository, *mocks.MockLoanRepository, *mocks.MockUserRepository) {
+	collectionRepo := new(mocks.MockCollectionRepository)
+	loanRepo := new(mocks.MockLoanRepository)
+	userRepo := new(mocks.MockUserRepository)
+	service := NewCollectionService(collectionRepo, loanRepo, userRepo, nil, zerolog.Nop())
+	return service, collectionRepo, loanRepo, userRepo
+}
+
+func TestCollectionService_RefreshQueue_EntersPastThresholdAndResolvesPaid(t *testing.T) {
+	service, collectionRepo, loanRepo, _ := setupCollectionService()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC)
+
+	// 19 days past due, over the default threshold
+	aging := domain.Loan{
+		ID: 1, LoanNumber: "LN-2024-000001", BranchID: 1, CustomerID: 5,
+		Status: domain.LoanStatusOverdue, DueDate: domain.NewDate(2024, 3, 1),
+		PrincipalRemaining: 1000, InterestRemaining: 100, LateFeeRemaining: 20,
+	}
+	// 5 days past due, not yet in collections
+	recent := domain.Loan{
+		ID: 2, LoanNumber: "LN-2024-000002", BranchID: 1, CustomerID: 6,
+		Status: domain.LoanStatusOverdue, DueDate: domain.NewDate(2024, 3, 15),
+		PrincipalRemaining: 500,
+	}
+	expectOpenLoans(loanRepo, nil, []domain.Loan{aging, recent})
+
+	// Loan 3 was paid off since it entered the queue
+	paid := &domain.CollectionCase{ID: 30, LoanID: 3, BranchID: 1, Status: domain.CollectionStatusPromiseToPay, DaysOverdue: 40, AmountDue: 800}
+	collectionRepo.On("ListOpen", ctx, int64(0)).Return([]*domain.CollectionCase{paid}, nil)
+	collectionRepo.On("Resolve", ctx, int64(30), now).Return(nil)
+	var entered *domain.CollectionCase
+	collectionRepo.On("Create", ctx, mock.AnythingOfType("*domain.CollectionCase")).Run(func(args mock.Arguments) {
+		entered = args.Get(1).(*domain.CollectionCase)
+	}).Return(true, nil)
+
+	result, err := service.RefreshQueue(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Entered)
+	assert.Equal(t, 1, result.Resolved)
+	require.NotNil(t, entered)
+	assert.Equal(t, int64(1), entered.LoanID)
+	assert.Equal(t, domain.CollectionStatusInProgress, entered.Status)
+	assert.Equal(t, 19, entered.DaysOverdue)
+	assert.Equal(t, 1120.0, entered.AmountDue)
+	collectionRepo.AssertNumberOfCalls(t, "Create", 1)
+	collectionRepo.AssertExpectations(t)
+}
+
+func TestCollectionService_UpdateStatus_PromiseRequiresDate(t *testing.T) {
+	service, collectionRepo, _, _ := setupCollectionService()
+	ctx := context.Background()
+
+	collectionRepo.On("GetByID", ctx, int64(30)).Return(&domain.CollectionCase{ID: 30, Status: domain.CollectionStatusInProgress}, nil)
+
+	_, err := service.UpdateStatus(ctx, 30, UpdateCollectionStatusInput{Status: domain.CollectionStatusPromiseToPay})
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	collectionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestCollectionService_LogContact_ResolvedCase(t *testing.T) {
+	service, collectionRepo, _, _ := setupCollectionService()
+	ctx := context.Background()
+	resolvedAt := time.Now()
+
+	collectionRepo.On("GetByID", ctx, int64(30)).Return(&domain.CollectionCase{ID: 30, Status: domain.CollectionStatusResolved, ResolvedAt: &resolvedAt}, nil)
+
+	_, err := service.LogContact(ctx, 30, LogCollectionContactInput{Method: domain.CollectionContactPhone, ContactedBy: 2})
+
+	assert.ErrorIs(t, err, ErrCollectionCaseResolved)
+	collectionRepo.AssertNotCalled(t, "AddContact", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingConfiscationReminderDays is how many days before a loan's grace period
+// ends the customer gets the final confiscation warning; 0 disables it
+const SettingConfiscationReminderDays = "confiscation_reminder_days"
+
+// DefaultConfiscationReminderDays is used when the setting is not configured
+const DefaultConfiscationReminderDays = 3
+
+// confiscationReminderChannel is the channel the final warning is queued on
+const confiscationReminderChannel = domain.NotificationChannelSMS
+
+// confiscationReminderPageSize is how many open loans are scanned per query
+const confiscationReminderPageSize = 100
+
+// ConfiscationReminderService warns customers a configurable number of days
+// before their loan's grace period ends and the pawned item is confiscated
+type ConfiscationReminderService struct {
+	loanRepo            repository.LoanRepository
+	itemRepo            repository.ItemRepository
+	customerRepo        repository.CustomerRepository
+	notificationRepo    repository.NotificationRepository
+	settingRepo         repository.SettingRepository
+	notificationService NotificationService
+	moneyFormat         *MoneyFormatService
+	logger              zerolog.Logger
+}
+
+// NewConfiscationReminderService creates a new ConfiscationReminderService
+func NewConfiscationReminderService(
+	loanRepo repository.LoanRepository,
+	itemRepo repository.ItemRepository,
+	customerRepo repository.CustomerRepository,
+	notificationRepo repository.NotificationRepository,
+	settingRepo repository.SettingRepository,
+	notificationService NotificationService,
+	logger zerolog.Logger,
+) *ConfiscationReminderService {
+	return &ConfiscationReminderService{
+		loanRepo:            loanRepo,
+		itemRepo:            itemRepo,
+		customerRepo:        customerRepo,
+		notificationRepo:    notificationRepo,
+		settingRepo:         settingRepo,
+		notificationService: notificationService,
+		logger:              logger.With().Str("service", "confiscation_reminder").Logger(),
+	}
+}
+
+// SetMoneyFormat writes the payoff amount in the currency of the loan's branch
+func (s *ConfiscationReminderService) SetMoneyFormat(moneyFormat *MoneyFormatService) {
+	s.moneyFormat = moneyFormat
+}
+
+// Covers checks if the loan is inside its branch's confiscation warning window,
+// in which case the final warning replaces the regular due date reminder
+func (s *ConfiscationReminderService) Covers(ctx context.Context, loan *domain.Loan, now time.Time) bool {
+	if !loan.IsOpen() {
+		return false
+	}
+	days := getSettingInt(ctx, s.settingRepo, SettingConfiscationReminderDays, &loan.BranchID, DefaultConfiscationReminderDays)
+	if days <= 0 {
+		return false
+	}
+	left := loan.DaysUntilGracePeriodEnd(domain.DateFromTime(now))
+	return left >= 0 && left <= days
+}
+
+// SendReminders queues the final warning for every open loan inside its warning
+// window. Each loan gets the warning only once, however often the job runs.
+func (s *ConfiscationReminderService) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	loans, err := s.openLoans(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, loan := range loans {
+		if !s.Covers(ctx, loan, now) {
+			continue
+		}
+
+		queued, err := s.alreadyQueued(ctx, loan.ID)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to check previous confiscation warnings")
+			continue
+		}
+		if queued {
+			continue
+		}
+
+		if err := s.queue(ctx, loan, now); err != nil {
+			s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to queue confiscation warning")
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// openLoans lists active and overdue loans, the only ones that can still be confiscated
+func (s *ConfiscationReminderService) openLoans(ctx context.Context) ([]*domain.Loan, error) {
+	var loans []*domain.Loan
+	for _, status := range []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusOverdue} {
+		for page := 1; ; page++ {
+			result, err := s.loanRepo.List(ctx, repository.LoanListParams{
+				PaginationParams: repository.PaginationParams{Page: page, PerPage: confiscationReminderPageSize},
+				Status:           &status,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for i := range result.Data {
+				loans = append(loans, &result.Data[i])
+			}
+			if page >= result.TotalPages {
+				break
+			}
+		}
+	}
+	return loans, nil
+}
+
+// alreadyQueued checks if the loan was already sent a confiscation warning
+func (s *ConfiscationReminderService) alreadyQueued(ctx context.Context, loanID int64) (bool, error) {
+	notificationType := domain.NotificationTypeConfiscationRisk
+	referenceType := "loan"
+	_, total, err := s.notificationRepo.List(ctx, repository.NotificationFilter{
+		NotificationType: &notificationType,
+		ReferenceType:    &referenceType,
+		ReferenceID:      &loanID,
+		PageSize:         1,
+	})
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// queue renders the confiscation warning template for the loan and queues it
+func (s *ConfiscationReminderService) queue(ctx context.Context, loan *domain.Loan, now time.Time) error {
+	customer, err := s.customerRepo.GetByID(ctx, loan.CustomerID)
+	if err != nil {
+		return fmt.Errorf("customer not found: %w", err)
+	}
+	item, err := s.itemRepo.GetByID(ctx, loan.ItemID)
+	if err != nil {
+		return fmt.Errorf("item not found: %w", err)
+	}
+
+	itemDescription := item.Name
+	if item.Description != nil && *item.Description != "" {
+		itemDescription = fmt.Sprintf("%s - %s", item.Name, *item.Description)
+	}
+
+	format := moneyFormatFor(ctx, s.moneyFormat, loan.BranchID)
+	_, err = s.notificationService.CreateFromTemplate(ctx, CreateNotificationFromTemplateRequest{
+		CustomerID:       loan.CustomerID,
+		BranchID:         &loan.BranchID,
+		NotificationType: domain.NotificationTypeConfiscationRisk,
+		Channel:          confiscationReminderChannel,
+		TemplateData: map[string]string{
+			"customer_name":     customer.FullName(),
+			"loan_number":       loan.LoanNumber,
+			"item_description":  itemDescription,
+			"payoff_amount":     format.Number(loan.RemainingBalance()),
+			"confiscation_date": loan.GracePeriodEnd().String(),
+			"days_left":         fmt.Sprintf("%d", loan.DaysUntilGracePeriodEnd(domain.DateFromTime(now))),
+			"currency":          format.Symbol(),
+		},
+		ReferenceType: "loan",
+		ReferenceID:   &loan.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Int64("loan_id", loan.ID).
+		Int64("customer_id", loan.CustomerID).
+		Str("confiscation_date", loan.GracePeriodEnd().String()).
+		Msg("Queued confiscation warning")
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+type confiscationReminderMocks struct {
+	loanRepo         *mocks.MockLoanRepository
+	itemRepo         *mocks.MockItemRepository
+	customerRepo     *mocks.MockCustomerRepository
+	notificationRepo *mocks.MockNotificationRepository
+	templateRepo     *mocks.MockNotificationTemplateRepository
+	preferenceRepo   *mocks.MockCustomerNotificationPreferenceRepository
+	settingRepo      *mocks.MockSettingRepository
+}
+
+func setupConfiscationReminderService() (*ConfiscationReminderService, confiscationReminderMocks) {
+	m := confiscationReminderMocks{
+		loanRepo:         new(mocks.MockLoanRepository),
+		itemRepo:         new(mocks.MockItemRepository),
+		customerRepo:     new(mocks.MockCustomerRepository),
+		notificationRepo: new(mocks.MockNotificationRepository),
+		templateRepo:     new(mocks.MockNotificationTemplateRepository),
+		preferenceRepo:   new(mocks.MockCustomerNotificationPreferenceRepository),
+		settingRepo:      new(mocks.MockSettingRepository),
+	}
+	notificationService := NewNotificationService(m.notificationRepo, m.templateRepo, m.preferenceRepo, nil, m.customerRepo, nil)
+	service := NewConfiscationReminderService(m.loanRepo, m.itemRepo, m.customerRepo, m.notificationRepo, m.settingRepo, notificationService, zerolog.Nop())
+	return service, m
+}
+
+func expectOpenLoans(loanRepo *mocks.MockLoanRepository, active, overdue []domain.Loan) {
+	for status, loans := range map[domain.LoanStatus][]domain.Loan{domain.LoanStatusActive: active, domain.LoanStatusOverdue: overdue} {
+		loanRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.LoanListParams) bool {
+			return p.Status != nil && *p.Status == status
+		})).Return(&repository.PaginatedResult[domain.Loan]{Data: loans, Total: len(loans), Page: 1, TotalPages: 1}, nil)
+	}
+}
+
+func TestConfiscationReminderService_SendReminders_QueuesOnce(t *testing.T) {
+	service, m := setupConfiscationReminderService()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 22, 9, 0, 0, 0, time.UTC)
+
+	// Grace period ends on 2024-03-25, three days away
+	loan := domain.Loan{
+		ID: 1, LoanNumber: "LN-2024-000001", BranchID: 1, CustomerID: 5, ItemID: 7,
+		Status: domain.LoanStatusOverdue, DueDate: domain.NewDate(2024, 3, 20), GracePeriodDays: 5,
+		PrincipalRemaining: 1000, InterestRemaining: 100, LateFeeRemaining: 20,
+	}
+	description := "14k, 10g"
+	expectOpenLoans(m.loanRepo, nil, []domain.Loan{loan})
+	m.settingRepo.On("Get", mock.Anything, SettingConfiscationReminderDays, mock.Anything).Return(nil, errors.New("setting not found"))
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(&domain.Customer{ID: 5, FirstName: "Ana", LastName: "López"}, nil)
+	m.itemRepo.On("GetByID", ctx, int64(7)).Return(&domain.Item{ID: 7, Name: "Gold ring", Description: &description}, nil)
+	m.templateRepo.On("GetByTypeAndChannel", ctx, domain.NotificationTypeConfiscationRisk, domain.NotificationChannelSMS).Return(&domain.NotificationTemplate{
+		NotificationType: domain.NotificationTypeConfiscationRisk,
+		Channel:          domain.NotificationChannelSMS,
+		BodyTemplate:     "#{{loan_number}} {{item_description}} {{confiscation_date}} {{currency}}{{payoff_amount}}",
+	}, nil)
+	m.preferenceRepo.On("IsEnabled", ctx, int64(5), domain.NotificationTypeConfiscationRisk, domain.NotificationChannelSMS).Return(true, nil)
+
+	isLoanWarning := mock.MatchedBy(func(f repository.NotificationFilter) bool {
+		return *f.NotificationType == domain.NotificationTypeConfiscationRisk && *f.ReferenceType == "loan" && *f.ReferenceID == 1
+	})
+	m.notificationRepo.On("List", ctx, isLoanWarning).Return([]*domain.Notification{}, int64(0), nil).Once()
+	m.notificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Notification")).Return(nil).Once()
+
+	sent, err := service.SendReminders(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// The warning is already queued on the next run
+	m.notificationRepo.On("List", ctx, isLoanWarning).Return([]*domain.Notification{{ID: 99}}, int64(1), nil)
+
+	sent, err = service.SendReminders(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	m.notificationRepo.AssertNumberOfCalls(t, "Create", 1)
+	notification := m.notificationRepo.Calls[1].Arguments.Get(1).(*domain.Notification)
+	assert.Equal(t, domain.NotificationTypeConfiscationRisk, notification.NotificationType)
+	assert.Equal(t, "#LN-2024-000001 Gold ring - 14k, 10g 2024-03-25 Q1,120.00", notification.Body)
+	assert.Equal(t, int64(1), *notification.ReferenceID)
+}
+
+func TestConfiscationReminderService_Queue_UsesBranchCurrency(t *testing.T) {
+	service, m := setupConfiscationReminderService()
+	moneyFormat, branchRepo := setupMoneyFormatService(false)
+	service.SetMoneyFormat(moneyFormat)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 22, 9, 0, 0, 0, time.UTC)
+
+	loan := &domain.Loan{
+		ID: 1, LoanNumber: "LN-2024-000001", BranchID: 2, CustomerID: 5, ItemID: 7,
+		Status: domain.LoanStatusOverdue, DueDate: domain.NewDate(2024, 3, 20), GracePeriodDays: 5,
+		PrincipalRemaining: 1000, InterestRemaining: 100, LateFeeRemaining: 20,
+	}
+	branchRepo.On("GetByID", ctx, int64(2)).Return(&domain.Branch{ID: 2, Currency: "USD"}, nil)
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(&domain.Customer{ID: 5, FirstName: "Ana", LastName: "López"}, nil)
+	m.itemRepo.On("GetByID", ctx, int64(7)).Return(&domain.Item{ID: 7, Name: "Gold ring"}, nil)
+	m.templateRepo.On("GetByTypeAndChannel", ctx, domain.NotificationTypeConfiscationRisk, domain.NotificationChannelSMS).Return(&domain.NotificationTemplate{
+		NotificationType: domain.NotificationTypeConfiscationRisk,
+		Channel:          domain.NotificationChannelSMS,
+		BodyTemplate:     "Liquide {{currency}}{{payoff_amount}}",
+	}, nil)
+	m.preferenceRepo.On("IsEnabled", ctx, int64(5), domain.NotificationTypeConfiscationRisk, domain.NotificationChannelSMS).Return(true, nil)
+
+	var queued *domain.Notification
+	m.notificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Notification")).
+		Run(func(args mock.Arguments) { queued = args.Get(1).(*domain.Notification) }).
+		Return(nil)
+
+	require.NoError(t, service.queue(ctx, loan, now))
+	require.NotNil(t, queued)
+	assert.Equal(t, "Liquide $1,120.00", queued.Body)
+}
+
+func TestConfiscationReminderService_SendReminders_OutsideWindow(t *testing.T) {
+	service, m := setupConfiscationReminderService()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	// Grace period ends on 2024-03-25, fifteen days away
+	loan := domain.Loan{ID: 1, BranchID: 1, Status: domain.LoanStatusActive, DueDate: domain.NewDate(2024, 3, 20), GracePeriodDays: 5}
+	expectOpenLoans(m.loanRepo, []domain.Loan{loan}, nil)
+	m.settingRepo.On("Get", mock.Anything, SettingConfiscationReminderDays, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	sent, err := service.SendReminders(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	m.notificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestConfiscationReminderService_Covers(t *testing.T) {
+	service, m := setupConfiscationReminderService()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 23, 9, 0, 0, 0, time.UTC)
+	m.settingRepo.On("Get", mock.Anything, SettingConfiscationReminderDays, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	inWindow := &domain.Loan{BranchID: 1, Status: domain.LoanStatusOverdue, DueDate: domain.NewDate(2024, 3, 20), GracePeriodDays: 5}
+	pastGrace := &domain.Loan{BranchID: 1, Status: domain.LoanStatusOverdue, DueDate: domain.NewDate(2024, 3, 10), GracePeriodDays: 5}
+	paid := &domain.Loan{BranchID: 1, Status: domain.LoanStatusPaid, DueDate: domain.NewDate(2024, 3, 20), GracePeriodDays: 5}
+
+	assert.True(t, service.Covers(ctx, inWindow, now))
+	assert.False(t, service.Covers(ctx, pastGrace, now))
+	assert.False(t, service.Covers(ctx, paid, now))
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/pkg/auth"
+)
+
+var (
+	ErrVerificationCodeNotFound   = errors.New("no verification code was sent to this customer")
+	ErrVerificationCodeExpired    = errors.New("verification code expired, request a new one")
+	ErrInvalidVerificationCode    = errors.New("invalid verification code")
+	ErrInvalidVerificationChannel = errors.New("invalid verification channel, expected sms or whatsapp")
+	ErrCustomerPhoneMissing       = errors.New("customer has no phone number to verify")
+)
+
+// Phone verification settings
+const (
+	SettingPhoneVerificationEnabled     = "customer_phone_verification_enabled"
+	SettingPhoneVerificationChannel     = "customer_phone_verification_channel"
+	SettingPhoneVerificationTTLMinutes  = "customer_phone_verification_ttl_minutes"
+	SettingPhoneVerificationMaxAttempts = "customer_phone_verification_max_attempts"
+)
+
+// Phone verification defaults, used when the settings are missing
+const (
+	defaultPhoneVerificationTTLMinutes  = 15
+	defaultPhoneVerificationMaxAttempts = 5
+)
+
+// verificationCodeDigits is the length of the numeric codes sent to customers
+const verificationCodeDigits = 6
+
+// ContactVerificationService sends one-time codes to customers' phones and
+// marks the phone verified once a code is confirmed
+type ContactVerificationService struct {
+	verificationRepo repository.ContactVerificationRepository
+	customerRepo     repository.CustomerRepository
+	notificationRepo repository.NotificationRepository
+	settingRepo      repository.SettingRepository
+	passwords        *auth.PasswordManager
+	generateCode     func() (string, error)
+}
+
+// NewContactVerificationService creates a new ContactVerificationService
+func NewContactVerificationService(
+	verificationRepo repository.ContactVerificationRepository,
+	customerRepo repository.CustomerRepository,
+	notificationRepo repository.NotificationRepository,
+	settingRepo repository.SettingRepository,
+) *ContactVerificationService {
+	return &ContactVerificationService{
+		verificationRepo: verificationRepo,
+		customerRepo:     customerRepo,
+		notificationRepo: notificationRepo,
+		settingRepo:      settingRepo,
+		passwords:        auth.NewPasswordManager(),
+		generateCode:     generateVerificationCode,
+	}
+}
+
+// Enabled reports whether new customers of the branch are sent a code on registration
+func (s *ContactVerificationService) Enabled(ctx context.Context, branchID int64) bool {
+	return getSettingBool(ctx, s.settingRepo, SettingPhoneVerificationEnabled, &branchID, false)
+}
+
+// RequestCode sends a new code to the customer's phone. An empty channel uses
+// the configured one. Earlier codes stop being accepted.
+func (s *ContactVerificationService) RequestCode(ctx context.Context, customerID int64, channel string) (*domain.ContactVerification, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, ErrCustomerNotFound
+	}
+	if domain.NormalizePhone(customer.Phone) == "" {
+		return nil, ErrCustomerPhoneMissing
+	}
+
+	if channel == "" {
+		channel = getSettingString(ctx, s.settingRepo, SettingPhoneVerificationChannel, &customer.BranchID, domain.NotificationChannelSMS)
+	}
+	if channel != domain.NotificationChannelSMS && channel != domain.NotificationChannelWhatsApp {
+		return nil, ErrInvalidVerificationChannel
+	}
+
+	code, err := s.generateCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	// Codes are short, so they are stored salted and slow-hashed like passwords
+	codeHash, err := s.passwords.HashPassword(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash verification code: %w", err)
+	}
+
+	ttl := getSettingInt(ctx, s.settingRepo, SettingPhoneVerificationTTLMinutes, &customer.BranchID, defaultPhoneVerificationTTLMinutes)
+	verification := &domain.ContactVerification{
+		CustomerID: customer.ID,
+		Phone:      customer.Phone,
+		Channel:    channel,
+		CodeHash:   codeHash,
+		ExpiresAt:  time.Now().Add(time.Duration(ttl) * time.Minute),
+	}
+	if err := s.verificationRepo.Create(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	// The customer asked for the code, so it is queued regardless of their
+	// notification preferences
+	notification := &domain.Notification{
+		CustomerID:       customer.ID,
+		BranchID:         &customer.BranchID,
+		NotificationType: domain.NotificationTypeContactVerification,
+		Channel:          channel,
+		Body:             fmt.Sprintf("Su código de verificación es %s. Vence en %d minutos.", code, ttl),
+		ReferenceType:    "contact_verification",
+		ReferenceID:      &verification.ID,
+		Status:           domain.NotificationStatusPending,
+	}
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return nil, fmt.Errorf("failed to queue verification code: %w", err)
+	}
+
+	return verification, nil
+}
+
+// ConfirmCode checks a code against the last one sent to the customer and, when
+// it matches, marks the customer's phone verified on the channel it was sent through
+func (s *ContactVerificationService) ConfirmCode(ctx context.Context, customerID int64, code string) (*domain.Customer, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	verification, err := s.verificationRepo.GetLatest(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, ErrVerificationCodeNotFound
+	}
+
+	now := time.Now()
+	maxAttempts := getSettingInt(ctx, s.settingRepo, SettingPhoneVerificationMaxAttempts, &customer.BranchID, defaultPhoneVerificationMaxAttempts)
+	// A code sent to a phone the customer has since changed proves nothing
+	phoneChanged := domain.NormalizePhone(verification.Phone) != domain.NormalizePhone(customer.Phone)
+	if !verification.CanVerify(now, maxAttempts) || phoneChanged {
+		return nil, ErrVerificationCodeExpired
+	}
+
+	// The hashes are compared in constant time. A hash that cannot be parsed
+	// matches no code.
+	matches, err := s.passwords.VerifyPassword(strings.TrimSpace(code), verification.CodeHash)
+	if err != nil || !matches {
+		if err := s.verificationRepo.IncrementAttempts(ctx, verification.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidVerificationCode
+	}
+
+	if err := s.verificationRepo.MarkVerified(ctx, verification.ID, now); err != nil {
+		return nil, err
+	}
+
+	customer.PhoneVerifiedAt = &now
+	customer.PhoneVerifiedChannel = verification.Channel
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		return nil, fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	return customer, nil
+}
+
+// generateVerificationCode returns a random numeric code
+func generateVerificationCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < verificationCodeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", verificationCodeDigits, n), nil
+}
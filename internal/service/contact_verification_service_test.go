@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+	"pawnshop/pkg/auth"
+)
+
+type contactVerificationMocks struct {
+	verificationRepo *mocks.MockContactVerificationRepository
+	customerRepo     *mocks.MockCustomerRepository
+	notificationRepo *mocks.MockNotificationRepository
+}
+
+func setupContactVerificationService() (*ContactVerificationService, contactVerificationMocks) {
+	m := contactVerificationMocks{
+		verificationRepo: new(mocks.MockContactVerificationRepository),
+		customerRepo:     new(mocks.MockCustomerRepository),
+		notificationRepo: new(mocks.MockNotificationRepository),
+	}
+	service := NewContactVerificationService(m.verificationRepo, m.customerRepo, m.notificationRepo, nil)
+	service.generateCode = func() (string, error) { return "482913", nil }
+	return service, m
+}
+
+// hashCode hashes a verification code the way the service stores it
+func hashCode(t *testing.T, code string) string {
+	hash, err := auth.NewPasswordManager().HashPassword(code)
+	require.NoError(t, err)
+	return hash
+}
+
+func verificationCustomer() *domain.Customer {
+	return &domain.Customer{ID: 5, BranchID: 1, FirstName: "Ana", LastName: "López", Phone: "+502 5555-1234"}
+}
+
+func TestContactVerificationService_RequestCode_QueuesCode(t *testing.T) {
+	service, m := setupContactVerificationService()
+	ctx := context.Background()
+
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(verificationCustomer(), nil)
+	m.verificationRepo.On("Create", ctx, mock.MatchedBy(func(v *domain.ContactVerification) bool {
+		return v.CustomerID == 5 && v.Channel == domain.NotificationChannelWhatsApp &&
+			v.CodeHash != "482913" && v.ExpiresAt.After(time.Now())
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.ContactVerification).ID = 9
+	}).Return(nil)
+	m.notificationRepo.On("Create", ctx, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.CustomerID == 5 && n.Channel == domain.NotificationChannelWhatsApp &&
+			n.NotificationType == domain.NotificationTypeContactVerification &&
+			n.ReferenceID != nil && *n.ReferenceID == 9 && assert.Contains(t, n.Body, "482913")
+	})).Return(nil)
+
+	verification, err := service.RequestCode(ctx, 5, domain.NotificationChannelWhatsApp)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), verification.ID)
+	// Only a salted hash of the code is stored
+	matches, err := auth.NewPasswordManager().VerifyPassword("482913", verification.CodeHash)
+	require.NoError(t, err)
+	assert.True(t, matches)
+	assert.NotEqual(t, hashCode(t, "482913"), verification.CodeHash)
+	m.verificationRepo.AssertExpectations(t)
+	m.notificationRepo.AssertExpectations(t)
+}
+
+func TestContactVerificationService_RequestCode_InvalidChannel(t *testing.T) {
+	service, m := setupContactVerificationService()
+	ctx := context.Background()
+
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(verificationCustomer(), nil)
+
+	_, err := service.RequestCode(ctx, 5, domain.NotificationChannelEmail)
+
+	assert.ErrorIs(t, err, ErrInvalidVerificationChannel)
+	m.verificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestContactVerificationService_ConfirmCode_SetsVerifiedAt(t *testing.T) {
+	service, m := setupContactVerificationService()
+	ctx := context.Background()
+
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(verificationCustomer(), nil)
+	m.verificationRepo.On("GetLatest", ctx, int64(5)).Return(&domain.ContactVerification{
+		ID: 9, CustomerID: 5, Phone: "50255551234", Channel: domain.NotificationChannelWhatsApp,
+		CodeHash: hashCode(t, "482913"), ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, nil)
+	m.verificationRepo.On("MarkVerified", ctx, int64(9), mock.AnythingOfType("time.Time")).Return(nil)
+	m.customerRepo.On("Update", ctx, mock.MatchedBy(func(c *domain.Customer) bool {
+		return c.PhoneVerifiedAt != nil && c.PhoneVerifiedChannel == domain.NotificationChannelWhatsApp
+	})).Return(nil)
+
+	customer, err := service.ConfirmCode(ctx, 5, " 482913 ")
+
+	require.NoError(t, err)
+	assert.True(t, customer.IsPhoneVerified())
+	assert.Equal(t, domain.NotificationChannelWhatsApp, customer.ReminderChannel())
+	m.customerRepo.AssertExpectations(t)
+	m.verificationRepo.AssertExpectations(t)
+}
+
+func TestContactVerificationService_ConfirmCode_WrongCodeRejected(t *testing.T) {
+	service, m := setupContactVerificationService()
+	ctx := context.Background()
+
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(verificationCustomer(), nil)
+	m.verificationRepo.On("GetLatest", ctx, int64(5)).Return(&domain.ContactVerification{
+		ID: 9, CustomerID: 5, Phone: "+502 5555-1234", Channel: domain.NotificationChannelSMS,
+		CodeHash: hashCode(t, "482913"), ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, nil)
+	m.verificationRepo.On("IncrementAttempts", ctx, int64(9)).Return(nil)
+
+	_, err := service.ConfirmCode(ctx, 5, "000000")
+
+	assert.ErrorIs(t, err, ErrInvalidVerificationCode)
+	m.verificationRepo.AssertExpectations(t)
+	m.verificationRepo.AssertNotCalled(t, "MarkVerified", mock.Anything, mock.Anything, mock.Anything)
+	m.customerRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestContactVerificationService_ConfirmCode_ExpiredOrExhausted(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification *domain.ContactVerification
+	}{
+		{"expired", &domain.ContactVerification{ID: 9, Phone: "+502 5555-1234", ExpiresAt: time.Now().Add(-time.Minute)}},
+		{"too many attempts", &domain.ContactVerification{ID: 9, Phone: "+502 5555-1234", Attempts: 5, ExpiresAt: time.Now().Add(time.Minute)}},
+		{"phone changed", &domain.ContactVerification{ID: 9, Phone: "+502 4444-0000", ExpiresAt: time.Now().Add(time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := setupContactVerificationService()
+			ctx := context.Background()
+			tt.verification.CodeHash = hashCode(t, "482913")
+
+			m.customerRepo.On("GetByID", ctx, int64(5)).Return(verificationCustomer(), nil)
+			m.verificationRepo.On("GetLatest", ctx, int64(5)).Return(tt.verification, nil)
+
+			_, err := service.ConfirmCode(ctx, 5, "482913")
+
+			assert.ErrorIs(t, err, ErrVerificationCodeExpired)
+		})
+	}
+}
@@ -12,21 +12,43 @@ import (
 
 // CustomerService handles customer business logic
 type CustomerService struct {
-	customerRepo repository.CustomerRepository
-	branchRepo   repository.BranchRepository
+	customerRepo      repository.CustomerRepository
+	branchRepo        repository.BranchRepository
+	loanRepo          repository.LoanRepository
+	paymentRepo       repository.PaymentRepository
+	saleRepo          repository.SaleRepository
+	notificationRepo  repository.NotificationRepository
+	channelStatusRepo repository.NotificationChannelStatusRepository
+	verification      *ContactVerificationService
 }
 
 // NewCustomerService creates a new CustomerService
 func NewCustomerService(
 	customerRepo repository.CustomerRepository,
 	branchRepo repository.BranchRepository,
+	loanRepo repository.LoanRepository,
+	paymentRepo repository.PaymentRepository,
+	saleRepo repository.SaleRepository,
+	notificationRepo repository.NotificationRepository,
+	channelStatusRepo repository.NotificationChannelStatusRepository,
 ) *CustomerService {
 	return &CustomerService{
-		customerRepo: customerRepo,
-		branchRepo:   branchRepo,
+		customerRepo:      customerRepo,
+		branchRepo:        branchRepo,
+		loanRepo:          loanRepo,
+		paymentRepo:       paymentRepo,
+		saleRepo:          saleRepo,
+		notificationRepo:  notificationRepo,
+		channelStatusRepo: channelStatusRepo,
 	}
 }
 
+// SetContactVerification enables sending a verification code to new customers'
+// phones when the branch has phone verification turned on
+func (s *CustomerService) SetContactVerification(verification *ContactVerificationService) {
+	s.verification = verification
+}
+
 // CreateCustomerInput represents create customer request data
 type CreateCustomerInput struct {
 	BranchID                 int64  `json:"branch_id" validate:"required"`
@@ -119,6 +141,11 @@ func (s *CustomerService) Create(ctx context.Context, input CreateCustomerInput)
 		return nil, fmt.Errorf("failed to create customer: %w", err)
 	}
 
+	// A code that fails to queue does not undo the registration; staff can request another
+	if s.verification != nil && s.verification.Enabled(ctx, customer.BranchID) {
+		s.verification.RequestCode(ctx, customer.ID, "")
+	}
+
 	return customer, nil
 }
 
@@ -172,6 +199,11 @@ func (s *CustomerService) Update(ctx context.Context, id int64, input UpdateCust
 		customer.Gender = input.Gender
 	}
 	if input.Phone != "" {
+		// A new number has to be verified again
+		if domain.NormalizePhone(input.Phone) != domain.NormalizePhone(customer.Phone) {
+			customer.PhoneVerifiedAt = nil
+			customer.PhoneVerifiedChannel = ""
+		}
 		customer.Phone = input.Phone
 	}
 	customer.PhoneSecondary = input.PhoneSecondary
@@ -275,6 +307,132 @@ func (s *CustomerService) UpdateCreditScore(ctx context.Context, id int64, score
 	})
 }
 
+// Limits of the customer 360 view
+const (
+	customer360MaxLoans    = 100
+	customer360RecentItems = 10
+)
+
+// Customer360 is a consolidated read-only view of a customer for support staff
+type Customer360 struct {
+	Customer          *domain.Customer              `json:"customer"`
+	LoanSummary       Customer360LoanSummary        `json:"loan_summary"`
+	ActiveLoans       []domain.Loan                 `json:"active_loans"`
+	HistoricalLoans   []domain.Loan                 `json:"historical_loans"`
+	RecentPayments    []domain.Payment              `json:"recent_payments"`
+	RecentSales       []domain.Sale                 `json:"recent_sales"`
+	Loyalty           *CustomerLoyaltyInfo          `json:"loyalty"`
+	RiskScore         int                           `json:"risk_score"`
+	NotificationStats *repository.NotificationStats `json:"notification_stats,omitempty"`
+	Blocks            Customer360Blocks             `json:"blocks"`
+}
+
+// Customer360LoanSummary aggregates a customer's loans
+type Customer360LoanSummary struct {
+	ActiveCount     int     `json:"active_count"`
+	ActiveBalance   float64 `json:"active_balance"`
+	HistoricalCount int     `json:"historical_count"`
+	TotalBorrowed   float64 `json:"total_borrowed"`
+	TotalLoans      int     `json:"total_loans"`
+	LoansTruncated  bool    `json:"loans_truncated"`
+}
+
+// Customer360Blocks lists what currently prevents serving or contacting a customer
+type Customer360Blocks struct {
+	IsBlocked      bool                                `json:"is_blocked"`
+	BlockedReason  string                              `json:"blocked_reason,omitempty"`
+	PausedChannels []*domain.NotificationChannelStatus `json:"paused_channels"`
+}
+
+// Get360 builds the consolidated view of a customer. Related records are read
+// with one list query per kind, never per loan, so the cost does not grow with
+// the customer's history.
+func (s *CustomerService) Get360(ctx context.Context, customerID int64) (*Customer360, error) {
+	customer, err := s.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	view := &Customer360{
+		Customer:        customer,
+		ActiveLoans:     []domain.Loan{},
+		HistoricalLoans: []domain.Loan{},
+		RecentPayments:  []domain.Payment{},
+		RecentSales:     []domain.Sale{},
+		Loyalty:         customerLoyaltyInfo(customer),
+		RiskScore:       customer.CreditScore,
+		Blocks: Customer360Blocks{
+			IsBlocked:      customer.IsBlocked,
+			BlockedReason:  customer.BlockedReason,
+			PausedChannels: []*domain.NotificationChannelStatus{},
+		},
+	}
+
+	if s.loanRepo != nil {
+		loans, err := s.loanRepo.List(ctx, repository.LoanListParams{
+			CustomerID:       &customerID,
+			PaginationParams: repository.PaginationParams{PerPage: customer360MaxLoans},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load customer loans: %w", err)
+		}
+		view.LoanSummary.TotalLoans = loans.Total
+		view.LoanSummary.LoansTruncated = loans.Total > len(loans.Data)
+		for _, loan := range loans.Data {
+			view.LoanSummary.TotalBorrowed += loan.LoanAmount
+			if loan.IsOpen() {
+				view.ActiveLoans = append(view.ActiveLoans, loan)
+				view.LoanSummary.ActiveBalance += loan.RemainingBalance()
+			} else {
+				view.HistoricalLoans = append(view.HistoricalLoans, loan)
+			}
+		}
+		view.LoanSummary.ActiveCount = len(view.ActiveLoans)
+		view.LoanSummary.HistoricalCount = len(view.HistoricalLoans)
+		view.LoanSummary.ActiveBalance = roundCents(view.LoanSummary.ActiveBalance)
+	}
+
+	if s.paymentRepo != nil {
+		payments, err := s.paymentRepo.List(ctx, repository.PaymentListParams{
+			CustomerID:       &customerID,
+			PaginationParams: repository.PaginationParams{PerPage: customer360RecentItems},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load customer payments: %w", err)
+		}
+		view.RecentPayments = payments.Data
+	}
+
+	if s.saleRepo != nil {
+		sales, err := s.saleRepo.List(ctx, repository.SaleListParams{
+			CustomerID:       &customerID,
+			PaginationParams: repository.PaginationParams{PerPage: customer360RecentItems},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load customer sales: %w", err)
+		}
+		view.RecentSales = sales.Data
+	}
+
+	// Notification data is informative; the view is still useful without it
+	if s.notificationRepo != nil {
+		if stats, err := s.notificationRepo.GetStatsByCustomer(ctx, customerID); err == nil {
+			view.NotificationStats = stats
+		}
+	}
+	if s.channelStatusRepo != nil {
+		if statuses, err := s.channelStatusRepo.ListByCustomer(ctx, customerID); err == nil {
+			for _, status := range statuses {
+				if status.IsPaused() {
+					view.Blocks.PausedChannels = append(view.Blocks.PausedChannels, status)
+				}
+			}
+		}
+	}
+
+	return view, nil
+}
+
 // Helper function to calculate age
 func calculateAge(birthDate time.Time) int {
 	now := time.Now()
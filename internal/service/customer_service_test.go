@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
@@ -16,7 +17,7 @@ import (
 func setupCustomerService() (*CustomerService, *mocks.MockCustomerRepository, *mocks.MockBranchRepository) {
 	customerRepo := new(mocks.MockCustomerRepository)
 	branchRepo := new(mocks.MockBranchRepository)
-	service := NewCustomerService(customerRepo, branchRepo)
+	service := NewCustomerService(customerRepo, branchRepo, nil, nil, nil, nil, nil)
 	return service, customerRepo, branchRepo
 }
 
@@ -123,6 +124,26 @@ func TestCustomerService_Update_Success(t *testing.T) {
 	customerRepo.AssertExpectations(t)
 }
 
+func TestCustomerService_Update_NewPhoneClearsVerification(t *testing.T) {
+	service, customerRepo, _ := setupCustomerService()
+	ctx := context.Background()
+
+	verifiedAt := time.Now().Add(-24 * time.Hour)
+	existingCustomer := &domain.Customer{
+		ID: 1, BranchID: 1, FirstName: "John", LastName: "Doe", Phone: "5555-1234",
+		PhoneVerifiedAt: &verifiedAt, PhoneVerifiedChannel: domain.NotificationChannelWhatsApp,
+	}
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(existingCustomer, nil)
+	customerRepo.On("Update", ctx, mock.AnythingOfType("*domain.Customer")).Return(nil)
+
+	result, err := service.Update(ctx, 1, UpdateCustomerInput{Phone: "5555-9999"})
+
+	assert.NoError(t, err)
+	assert.False(t, result.IsPhoneVerified())
+	assert.Equal(t, domain.NotificationChannelSMS, result.ReminderChannel())
+}
+
 func TestCustomerService_Update_NotFound(t *testing.T) {
 	service, customerRepo, _ := setupCustomerService()
 	ctx := context.Background()
@@ -568,3 +589,98 @@ func TestCalculateAge(t *testing.T) {
 	age = calculateAge(birthDate)
 	assert.Equal(t, 19, age)
 }
+
+// --- Customer 360 tests ---
+
+type customer360Mocks struct {
+	customerRepo      *mocks.MockCustomerRepository
+	branchRepo        *mocks.MockBranchRepository
+	loanRepo          *mocks.MockLoanRepository
+	paymentRepo       *mocks.MockPaymentRepository
+	saleRepo          *mocks.MockSaleRepository
+	notificationRepo  *mocks.MockNotificationRepository
+	channelStatusRepo *mocks.MockNotificationChannelStatusRepository
+}
+
+func setupCustomer360Service() (*CustomerService, customer360Mocks) {
+	m := customer360Mocks{
+		customerRepo:      new(mocks.MockCustomerRepository),
+		branchRepo:        new(mocks.MockBranchRepository),
+		loanRepo:          new(mocks.MockLoanRepository),
+		paymentRepo:       new(mocks.MockPaymentRepository),
+		saleRepo:          new(mocks.MockSaleRepository),
+		notificationRepo:  new(mocks.MockNotificationRepository),
+		channelStatusRepo: new(mocks.MockNotificationChannelStatusRepository),
+	}
+	service := NewCustomerService(m.customerRepo, m.branchRepo, m.loanRepo, m.paymentRepo, m.saleRepo, m.notificationRepo, m.channelStatusRepo)
+	return service, m
+}
+
+func TestCustomerService_Get360_BatchesRelatedRecords(t *testing.T) {
+	service, m := setupCustomer360Service()
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, BranchID: 1, FirstName: "Ana", CreditScore: 72, LoyaltyPoints: 1200, LoyaltyTier: domain.LoyaltyTierSilver}
+	pausedAt := time.Now()
+	m.customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	m.branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1}, nil)
+	m.loanRepo.On("List", ctx, mock.MatchedBy(func(p repository.LoanListParams) bool {
+		return p.CustomerID != nil && *p.CustomerID == 1
+	})).Return(&repository.PaginatedResult[domain.Loan]{
+		Data: []domain.Loan{
+			{ID: 10, Status: domain.LoanStatusActive, LoanAmount: 500, PrincipalRemaining: 400, InterestRemaining: 50},
+			{ID: 11, Status: domain.LoanStatusOverdue, LoanAmount: 300, PrincipalRemaining: 300, InterestRemaining: 30},
+			{ID: 12, Status: domain.LoanStatusPaid, LoanAmount: 200},
+		},
+		Total: 3,
+	}, nil).Once()
+	m.paymentRepo.On("List", ctx, mock.AnythingOfType("repository.PaymentListParams")).Return(&repository.PaginatedResult[domain.Payment]{
+		Data: []domain.Payment{{ID: 20, LoanID: 10, Amount: 100}, {ID: 21, LoanID: 12, Amount: 220}},
+	}, nil).Once()
+	m.saleRepo.On("List", ctx, mock.AnythingOfType("repository.SaleListParams")).Return(&repository.PaginatedResult[domain.Sale]{
+		Data: []domain.Sale{{ID: 30}},
+	}, nil).Once()
+	m.notificationRepo.On("GetStatsByCustomer", ctx, int64(1)).Return(&repository.NotificationStats{TotalSent: 4, TotalFailed: 1}, nil)
+	m.channelStatusRepo.On("ListByCustomer", ctx, int64(1)).Return([]*domain.NotificationChannelStatus{
+		{CustomerID: 1, Channel: domain.NotificationChannelSMS, PausedAt: &pausedAt},
+		{CustomerID: 1, Channel: domain.NotificationChannelEmail},
+	}, nil)
+
+	view, err := service.Get360(ctx, 1)
+
+	require.NoError(t, err)
+	assert.Len(t, view.ActiveLoans, 2)
+	assert.Len(t, view.HistoricalLoans, 1)
+	assert.Equal(t, 2, view.LoanSummary.ActiveCount)
+	assert.Equal(t, 780.0, view.LoanSummary.ActiveBalance)
+	assert.Equal(t, 1000.0, view.LoanSummary.TotalBorrowed)
+	assert.Len(t, view.RecentPayments, 2)
+	assert.Len(t, view.RecentSales, 1)
+	require.NotNil(t, view.Loyalty)
+	assert.Equal(t, 1200, view.Loyalty.Points)
+	assert.Equal(t, domain.LoyaltyTierGold, view.Loyalty.NextTier)
+	assert.Equal(t, 72, view.RiskScore)
+	assert.Equal(t, int64(4), view.NotificationStats.TotalSent)
+	require.Len(t, view.Blocks.PausedChannels, 1)
+	assert.Equal(t, domain.NotificationChannelSMS, view.Blocks.PausedChannels[0].Channel)
+
+	// One query per kind of record, none per loan
+	m.loanRepo.AssertNumberOfCalls(t, "List", 1)
+	m.paymentRepo.AssertNumberOfCalls(t, "List", 1)
+	m.saleRepo.AssertNumberOfCalls(t, "List", 1)
+	m.paymentRepo.AssertNotCalled(t, "ListByLoan", mock.Anything, mock.Anything)
+	m.loanRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestCustomerService_Get360_CustomerNotFound(t *testing.T) {
+	service, m := setupCustomer360Service()
+	ctx := context.Background()
+
+	m.customerRepo.On("GetByID", ctx, int64(99)).Return(nil, errors.New("not found"))
+
+	view, err := service.Get360(ctx, 99)
+
+	assert.Nil(t, view)
+	assert.ErrorIs(t, err, ErrCustomerNotFound)
+	m.loanRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
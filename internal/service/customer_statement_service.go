@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
+	"pawnshop/internal/repository"
+)
+
+// SettingCustomerStatementsEnabled turns on the monthly statements for the
+// customers of a branch that opted in to them
+const SettingCustomerStatementsEnabled = "customer_statements_enabled"
+
+// CustomerStatementService sends opted-in customers their account statement for
+// the previous month. Customers opt in by enabling the customer_statement
+// notification on a channel. Email statements reference the stored PDF for the
+// sender to attach; other channels carry its download link.
+type CustomerStatementService struct {
+	reportService       *ReportService
+	preferenceRepo      repository.CustomerNotificationPreferenceRepository
+	notificationRepo    repository.NotificationRepository
+	settingRepo         repository.SettingRepository
+	notificationService NotificationService
+	moneyFormat         *MoneyFormatService
+	logger              zerolog.Logger
+}
+
+// NewCustomerStatementService creates a new CustomerStatementService
+func NewCustomerStatementService(
+	reportService *ReportService,
+	preferenceRepo repository.CustomerNotificationPreferenceRepository,
+	notificationRepo repository.NotificationRepository,
+	settingRepo repository.SettingRepository,
+	notificationService NotificationService,
+	logger zerolog.Logger,
+) *CustomerStatementService {
+	return &CustomerStatementService{
+		reportService:       reportService,
+		preferenceRepo:      preferenceRepo,
+		notificationRepo:    notificationRepo,
+		settingRepo:         settingRepo,
+		notificationService: notificationService,
+		logger:              logger.With().Str("service", "customer_statements").Logger(),
+	}
+}
+
+// SetMoneyFormat writes statement amounts in the currency of the customer's branch
+func (s *CustomerStatementService) SetMoneyFormat(moneyFormat *MoneyFormatService) {
+	s.moneyFormat = moneyFormat
+}
+
+// SendMonthlyStatements queues the statement of the month before now for every
+// opted-in customer with activity in it and returns how many were queued.
+// Customers get one statement per month however often the job runs.
+func (s *CustomerStatementService) SendMonthlyStatements(ctx context.Context, now time.Time) (int, error) {
+	monthStart := domain.NewDate(now.Year(), now.Month(), 1)
+	from := domain.Date{Time: monthStart.AddDate(0, -1, 0)}
+	to := domain.Date{Time: monthStart.AddDate(0, 0, -1)}
+
+	prefs, err := s.preferenceRepo.ListEnabledByType(ctx, domain.NotificationTypeCustomerStatement)
+	if err != nil {
+		return 0, err
+	}
+
+	var customerIDs []int64
+	channels := make(map[int64][]string)
+	for _, pref := range prefs {
+		if _, ok := channels[pref.CustomerID]; !ok {
+			customerIDs = append(customerIDs, pref.CustomerID)
+		}
+		channels[pref.CustomerID] = append(channels[pref.CustomerID], pref.Channel)
+	}
+
+	queued := 0
+	for _, customerID := range customerIDs {
+		sent, err := s.alreadySent(ctx, customerID, monthStart)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("customer_id", customerID).Msg("Failed to check previous statements")
+			continue
+		}
+		if sent {
+			continue
+		}
+
+		statement, err := s.reportService.GetCustomerStatement(ctx, customerID, from, to)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("customer_id", customerID).Msg("Failed to build customer statement")
+			continue
+		}
+		customer := statement.Customer
+		if !getSettingBool(ctx, s.settingRepo, SettingCustomerStatementsEnabled, &customer.BranchID, false) {
+			continue
+		}
+		if !statementHasActivity(statement) {
+			s.logger.Debug().Int64("customer_id", customerID).Msg("Skipping statement without activity")
+			continue
+		}
+
+		channel := statementChannel(channels[customerID], customer)
+		if channel == "" {
+			s.logger.Warn().Int64("customer_id", customerID).Msg("No usable channel for the customer statement")
+			continue
+		}
+
+		if err := s.queue(ctx, statement, channel); err != nil {
+			s.logger.Error().Err(err).Int64("customer_id", customerID).Msg("Failed to queue customer statement")
+			continue
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+// alreadySent checks if the customer was already sent a statement this month
+func (s *CustomerStatementService) alreadySent(ctx context.Context, customerID int64, monthStart domain.Date) (bool, error) {
+	notificationType := domain.NotificationTypeCustomerStatement
+	since := monthStart.String()
+	_, total, err := s.notificationRepo.List(ctx, repository.NotificationFilter{
+		CustomerID:       &customerID,
+		NotificationType: &notificationType,
+		DateFrom:         &since,
+		PageSize:         1,
+	})
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// queue stores the statement PDF and queues its notification, which references
+// the stored document so its delivery is tracked with the notification
+func (s *CustomerStatementService) queue(ctx context.Context, statement *pdf.CustomerStatement, channel string) error {
+	doc, err := s.reportService.StoreCustomerStatement(ctx, statement)
+	if err != nil {
+		return err
+	}
+
+	customer := statement.Customer
+	format := moneyFormatFor(ctx, s.moneyFormat, customer.BranchID)
+	_, err = s.notificationService.CreateFromTemplate(ctx, CreateNotificationFromTemplateRequest{
+		CustomerID:       customer.ID,
+		BranchID:         &customer.BranchID,
+		NotificationType: domain.NotificationTypeCustomerStatement,
+		Channel:          channel,
+		TemplateData: map[string]string{
+			"customer_name": customer.FullName(),
+			"period":        statement.To.Format("01/2006"),
+			"total_paid":    format.Number(statement.TotalPaid),
+			"balance":       format.Number(statement.TotalBalance),
+			"statement_url": doc.FileURL,
+			"currency":      format.Symbol(),
+		},
+		ReferenceType: "document",
+		ReferenceID:   &doc.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Int64("customer_id", customer.ID).
+		Str("channel", channel).
+		Str("document_number", doc.DocumentNumber).
+		Msg("Queued customer statement")
+	return nil
+}
+
+// statementHasActivity checks if the customer opened a loan or made a payment
+// in the statement's period
+func statementHasActivity(statement *pdf.CustomerStatement) bool {
+	if len(statement.Payments) > 0 {
+		return true
+	}
+	for _, loan := range statement.Loans {
+		if !loan.StartDate.Before(statement.From) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementChannel picks the channel to deliver a statement on among those the
+// customer enabled: email, which gets the PDF attached, when the customer has
+// an address, otherwise the first other channel, which gets a link
+func statementChannel(enabled []string, customer *domain.Customer) string {
+	fallback := ""
+	for _, channel := range enabled {
+		if channel == domain.NotificationChannelEmail {
+			if customer.Email != "" {
+				return channel
+			}
+			continue
+		}
+		if fallback == "" {
+			fallback = channel
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+func expectCustomerLoans(loanRepo *mocks.MockLoanRepository, customerID int64, loans ...domain.Loan) {
+	loanRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.LoanListParams) bool {
+		return p.CustomerID != nil && *p.CustomerID == customerID
+	})).Return(&repository.PaginatedResult[domain.Loan]{Data: loans, Total: len(loans), Page: 1, TotalPages: 1}, nil)
+}
+
+func expectCustomerPayments(paymentRepo *mocks.MockPaymentRepository, customerID int64, payments ...domain.Payment) {
+	paymentRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.PaymentListParams) bool {
+		return p.CustomerID != nil && *p.CustomerID == customerID
+	})).Return(&repository.PaginatedResult[domain.Payment]{Data: payments, Total: len(payments), Page: 1, TotalPages: 1}, nil)
+}
+
+func TestCustomerStatementService_SendMonthlyStatements_SkipsCustomersWithoutActivity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+
+	loanRepo := new(mocks.MockLoanRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	notificationRepo := new(mocks.MockNotificationRepository)
+	templateRepo := new(mocks.MockNotificationTemplateRepository)
+	preferenceRepo := new(mocks.MockCustomerNotificationPreferenceRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+
+	reportService := NewReportService(loanRepo, paymentRepo, nil, customerRepo, nil, documentRepo,
+		pdf.NewGenerator("Casa de Empeño", "", "", ""), NewStorageService(nil, t.TempDir(), "/storage"))
+	notificationService := NewNotificationService(notificationRepo, templateRepo, preferenceRepo, nil, customerRepo, nil)
+	service := NewCustomerStatementService(reportService, preferenceRepo, notificationRepo, settingRepo, notificationService, zerolog.Nop())
+
+	// Ana paid in September; Luis only has a loan paid off back in July
+	ana := &domain.Customer{ID: 1, BranchID: 1, FirstName: "Ana", LastName: "López", Email: "ana@example.com"}
+	luis := &domain.Customer{ID: 2, BranchID: 1, FirstName: "Luis", LastName: "Pérez"}
+	customerRepo.On("GetByID", mock.Anything, int64(1)).Return(ana, nil)
+	customerRepo.On("GetByID", mock.Anything, int64(2)).Return(luis, nil)
+
+	preferenceRepo.On("ListEnabledByType", ctx, domain.NotificationTypeCustomerStatement).Return([]*domain.CustomerNotificationPreference{
+		{CustomerID: 1, NotificationType: domain.NotificationTypeCustomerStatement, Channel: domain.NotificationChannelEmail, IsEnabled: true},
+		{CustomerID: 2, NotificationType: domain.NotificationTypeCustomerStatement, Channel: domain.NotificationChannelSMS, IsEnabled: true},
+	}, nil)
+	preferenceRepo.On("IsEnabled", mock.Anything, mock.Anything, domain.NotificationTypeCustomerStatement, mock.Anything).Return(true, nil)
+	settingRepo.On("Get", mock.Anything, SettingCustomerStatementsEnabled, mock.Anything).
+		Return(&domain.Setting{Key: SettingCustomerStatementsEnabled, Value: true}, nil)
+	notificationRepo.On("List", mock.Anything, mock.Anything).Return([]*domain.Notification{}, int64(0), nil)
+
+	expectCustomerLoans(loanRepo, 1, domain.Loan{
+		ID: 1, LoanNumber: "LN-000001", CustomerID: 1, Status: domain.LoanStatusActive,
+		StartDate: domain.NewDate(2026, 8, 1), DueDate: domain.NewDate(2026, 10, 30), PrincipalRemaining: 700,
+	})
+	expectCustomerPayments(paymentRepo, 1, domain.Payment{
+		ID: 1, PaymentNumber: "PAY-000001", CustomerID: 1, LoanID: 1, Amount: 300,
+		Status: domain.PaymentStatusCompleted, PaymentDate: time.Date(2026, 9, 15, 10, 0, 0, 0, time.UTC),
+	})
+	expectCustomerLoans(loanRepo, 2, domain.Loan{
+		ID: 2, LoanNumber: "LN-000002", CustomerID: 2, Status: domain.LoanStatusPaid,
+		StartDate: domain.NewDate(2026, 6, 1), DueDate: domain.NewDate(2026, 7, 1),
+	})
+	expectCustomerPayments(paymentRepo, 2)
+
+	documentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Document")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.Document).ID = 10 }).
+		Return(nil).Once()
+	templateRepo.On("GetByTypeAndChannel", mock.Anything, domain.NotificationTypeCustomerStatement, domain.NotificationChannelEmail).
+		Return(&domain.NotificationTemplate{
+			NotificationType: domain.NotificationTypeCustomerStatement,
+			Channel:          domain.NotificationChannelEmail,
+			Subject:          "Su estado de cuenta de {{period}}",
+			BodyTemplate:     "Total pagado: {{currency}}{{total_paid}}",
+			IsActive:         true,
+		}, nil)
+
+	var queued *domain.Notification
+	notificationRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Notification")).
+		Run(func(args mock.Arguments) { queued = args.Get(1).(*domain.Notification) }).
+		Return(nil).Once()
+
+	sent, err := service.SendMonthlyStatements(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.NotNil(t, queued)
+	assert.Equal(t, int64(1), queued.CustomerID)
+	assert.Equal(t, domain.NotificationChannelEmail, queued.Channel)
+	assert.Equal(t, domain.NotificationStatusPending, queued.Status)
+	assert.Equal(t, "Su estado de cuenta de 09/2026", queued.Subject)
+	assert.Equal(t, "Total pagado: Q300.00", queued.Body)
+	assert.Equal(t, "document", queued.ReferenceType)
+	assert.Equal(t, int64(10), *queued.ReferenceID)
+	documentRepo.AssertNumberOfCalls(t, "Create", 1)
+	notificationRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestCustomerStatementService_Queue_UsesBranchCurrency(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mocks.MockCustomerRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	notificationRepo := new(mocks.MockNotificationRepository)
+	templateRepo := new(mocks.MockNotificationTemplateRepository)
+	preferenceRepo := new(mocks.MockCustomerNotificationPreferenceRepository)
+
+	reportService := NewReportService(nil, nil, nil, customerRepo, nil, documentRepo,
+		pdf.NewGenerator("Casa de Empeño", "", "", ""), NewStorageService(nil, t.TempDir(), "/storage"))
+	notificationService := NewNotificationService(notificationRepo, templateRepo, preferenceRepo, nil, customerRepo, nil)
+	service := NewCustomerStatementService(reportService, preferenceRepo, notificationRepo, nil, notificationService, zerolog.Nop())
+	moneyFormat, branchRepo := setupMoneyFormatService(false)
+	service.SetMoneyFormat(moneyFormat)
+
+	ana := &domain.Customer{ID: 1, BranchID: 2, FirstName: "Ana", LastName: "López", Email: "ana@example.com"}
+	customerRepo.On("GetByID", mock.Anything, int64(1)).Return(ana, nil)
+	branchRepo.On("GetByID", mock.Anything, int64(2)).Return(&domain.Branch{ID: 2, Currency: "USD"}, nil)
+	preferenceRepo.On("IsEnabled", mock.Anything, mock.Anything, domain.NotificationTypeCustomerStatement, mock.Anything).Return(true, nil)
+	documentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Document")).Return(nil)
+	templateRepo.On("GetByTypeAndChannel", mock.Anything, domain.NotificationTypeCustomerStatement, domain.NotificationChannelEmail).
+		Return(&domain.NotificationTemplate{
+			NotificationType: domain.NotificationTypeCustomerStatement,
+			Channel:          domain.NotificationChannelEmail,
+			BodyTemplate:     "Total pagado: {{currency}}{{total_paid}}. Saldo: {{currency}}{{balance}}",
+			IsActive:         true,
+		}, nil)
+
+	var queued *domain.Notification
+	notificationRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Notification")).
+		Run(func(args mock.Arguments) { queued = args.Get(1).(*domain.Notification) }).
+		Return(nil)
+
+	err := service.queue(ctx, &pdf.CustomerStatement{
+		Customer:     ana,
+		From:         time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		TotalPaid:    1234.5,
+		TotalBalance: 700,
+	}, domain.NotificationChannelEmail)
+
+	require.NoError(t, err)
+	require.NotNil(t, queued)
+	assert.Equal(t, "Total pagado: $1,234.50. Saldo: $700.00", queued.Body)
+}
+
+func TestStatementChannel_PrefersEmailWithAddress(t *testing.T) {
+	enabled := []string{domain.NotificationChannelSMS, domain.NotificationChannelEmail}
+
+	assert.Equal(t, domain.NotificationChannelEmail, statementChannel(enabled, &domain.Customer{Email: "ana@example.com"}))
+	assert.Equal(t, domain.NotificationChannelSMS, statementChannel(enabled, &domain.Customer{}))
+	assert.Equal(t, "", statementChannel([]string{domain.NotificationChannelEmail}, &domain.Customer{}))
+}
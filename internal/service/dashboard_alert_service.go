@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Dashboard alert threshold settings
+const (
+	// SettingAlertOverdueRatio is the overdue loan percentage that raises an alert
+	SettingAlertOverdueRatio = "dashboard_alert_overdue_ratio"
+	// SettingAlertMinCashFloat is the minimum cash on hand across open sessions; 0 disables the alert
+	SettingAlertMinCashFloat = "dashboard_alert_min_cash_float"
+	// SettingAlertCategoryConcentration is the share of inventory value in a single category that raises an alert
+	SettingAlertCategoryConcentration = "dashboard_alert_category_concentration"
+)
+
+// Default dashboard alert thresholds
+const (
+	DefaultAlertOverdueRatio          = 20.0
+	DefaultAlertMinCashFloat          = 0.0
+	DefaultAlertCategoryConcentration = 50.0
+)
+
+// DashboardAlertThresholds are the configured limits for the dashboard alerts
+type DashboardAlertThresholds struct {
+	OverdueRatio          float64 `json:"overdue_ratio"`
+	MinCashFloat          float64 `json:"min_cash_float"`
+	CategoryConcentration float64 `json:"category_concentration"`
+}
+
+// DashboardAlertInput holds the figures the dashboard alerts are computed from
+type DashboardAlertInput struct {
+	ActiveLoans         int
+	OverdueLoans        int
+	CashOnHand          float64
+	OpenCashSessions    int
+	InventoryByCategory map[int64]float64
+	InventoryValue      float64
+}
+
+// DashboardAlerts represents the alert flags shown on the dashboard
+type DashboardAlerts struct {
+	HighOverdueRatio      bool `json:"high_overdue_ratio"`
+	LowCashOnHand         bool `json:"low_cash_on_hand"`
+	InventoryConcentrated bool `json:"inventory_concentrated"`
+
+	OverdueRatio     float64 `json:"overdue_ratio"`
+	CashOnHand       float64 `json:"cash_on_hand"`
+	TopCategoryID    *int64  `json:"top_category_id,omitempty"`
+	TopCategoryShare float64 `json:"top_category_share"`
+
+	Thresholds DashboardAlertThresholds `json:"thresholds"`
+}
+
+// DashboardAlertService computes proactive dashboard alerts
+type DashboardAlertService struct {
+	reportService *ReportService
+	itemRepo      repository.ItemRepository
+	settingRepo   repository.SettingRepository
+	cashService   *CashService
+}
+
+// NewDashboardAlertService creates a new DashboardAlertService
+func NewDashboardAlertService(
+	reportService *ReportService,
+	itemRepo repository.ItemRepository,
+	settingRepo repository.SettingRepository,
+	cashService *CashService,
+) *DashboardAlertService {
+	return &DashboardAlertService{
+		reportService: reportService,
+		itemRepo:      itemRepo,
+		settingRepo:   settingRepo,
+		cashService:   cashService,
+	}
+}
+
+// GetThresholds returns the alert thresholds configured for a branch
+func (s *DashboardAlertService) GetThresholds(ctx context.Context, branchID int64) DashboardAlertThresholds {
+	var branch *int64
+	if branchID > 0 {
+		branch = &branchID
+	}
+	return DashboardAlertThresholds{
+		OverdueRatio:          getSettingFloat(ctx, s.settingRepo, SettingAlertOverdueRatio, branch, DefaultAlertOverdueRatio),
+		MinCashFloat:          getSettingFloat(ctx, s.settingRepo, SettingAlertMinCashFloat, branch, DefaultAlertMinCashFloat),
+		CategoryConcentration: getSettingFloat(ctx, s.settingRepo, SettingAlertCategoryConcentration, branch, DefaultAlertCategoryConcentration),
+	}
+}
+
+// GetAlerts computes the dashboard alerts for a branch
+func (s *DashboardAlertService) GetAlerts(ctx context.Context, branchID int64) (*DashboardAlerts, error) {
+	stats, err := s.reportService.GetDashboardStats(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	input := DashboardAlertInput{
+		ActiveLoans:         stats.ActiveLoans,
+		OverdueLoans:        stats.OverdueLoans,
+		InventoryByCategory: make(map[int64]float64),
+	}
+
+	if s.cashService != nil {
+		input.CashOnHand, input.OpenCashSessions, err = s.cashService.GetBranchCashOnHand(ctx, branchID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := s.itemRepo.List(ctx, repository.ItemListParams{
+		BranchID: branchID,
+		PaginationParams: repository.PaginationParams{
+			PerPage: 10000,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	for _, item := range items.Data {
+		if item.Status != domain.ItemStatusPawned && item.Status != domain.ItemStatusForSale {
+			continue
+		}
+		input.InventoryValue += item.AppraisedValue
+		if item.CategoryID != nil {
+			input.InventoryByCategory[*item.CategoryID] += item.AppraisedValue
+		}
+	}
+
+	return EvaluateDashboardAlerts(input, s.GetThresholds(ctx, branchID)), nil
+}
+
+// EvaluateDashboardAlerts sets the alert flags for the given figures.
+// The overdue ratio is taken over open (active and overdue) loans, the cash
+// alert only applies while a session is open and the concentration alert
+// compares the largest category against the whole inventory value.
+func EvaluateDashboardAlerts(input DashboardAlertInput, thresholds DashboardAlertThresholds) *DashboardAlerts {
+	alerts := &DashboardAlerts{
+		CashOnHand: input.CashOnHand,
+		Thresholds: thresholds,
+	}
+
+	if open := input.ActiveLoans + input.OverdueLoans; open > 0 {
+		alerts.OverdueRatio = roundCents(float64(input.OverdueLoans) / float64(open) * 100)
+		alerts.HighOverdueRatio = alerts.OverdueRatio > thresholds.OverdueRatio
+	}
+
+	if thresholds.MinCashFloat > 0 && input.OpenCashSessions > 0 {
+		alerts.LowCashOnHand = input.CashOnHand < thresholds.MinCashFloat
+	}
+
+	if input.InventoryValue > 0 {
+		for categoryID, value := range input.InventoryByCategory {
+			share := value / input.InventoryValue * 100
+			if alerts.TopCategoryID == nil || share > alerts.TopCategoryShare ||
+				(share == alerts.TopCategoryShare && categoryID < *alerts.TopCategoryID) {
+				id := categoryID
+				alerts.TopCategoryID = &id
+				alerts.TopCategoryShare = share
+			}
+		}
+		alerts.TopCategoryShare = roundCents(alerts.TopCategoryShare)
+		alerts.InventoryConcentrated = alerts.TopCategoryShare > thresholds.CategoryConcentration
+	}
+
+	return alerts
+}
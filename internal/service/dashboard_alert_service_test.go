@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+var testAlertThresholds = DashboardAlertThresholds{
+	OverdueRatio:          20,
+	MinCashFloat:          500,
+	CategoryConcentration: 50,
+}
+
+func TestEvaluateDashboardAlerts_OverdueRatio(t *testing.T) {
+	alerts := EvaluateDashboardAlerts(DashboardAlertInput{ActiveLoans: 6, OverdueLoans: 4}, testAlertThresholds)
+	assert.True(t, alerts.HighOverdueRatio)
+	assert.Equal(t, 40.0, alerts.OverdueRatio)
+
+	alerts = EvaluateDashboardAlerts(DashboardAlertInput{ActiveLoans: 9, OverdueLoans: 1}, testAlertThresholds)
+	assert.False(t, alerts.HighOverdueRatio)
+	assert.Equal(t, 10.0, alerts.OverdueRatio)
+
+	alerts = EvaluateDashboardAlerts(DashboardAlertInput{}, testAlertThresholds)
+	assert.False(t, alerts.HighOverdueRatio)
+}
+
+func TestEvaluateDashboardAlerts_LowCashOnHand(t *testing.T) {
+	alerts := EvaluateDashboardAlerts(DashboardAlertInput{CashOnHand: 200, OpenCashSessions: 1}, testAlertThresholds)
+	assert.True(t, alerts.LowCashOnHand)
+
+	alerts = EvaluateDashboardAlerts(DashboardAlertInput{CashOnHand: 800, OpenCashSessions: 2}, testAlertThresholds)
+	assert.False(t, alerts.LowCashOnHand)
+
+	// No open session, nothing to warn about
+	alerts = EvaluateDashboardAlerts(DashboardAlertInput{CashOnHand: 0}, testAlertThresholds)
+	assert.False(t, alerts.LowCashOnHand)
+
+	// A zero minimum disables the alert
+	disabled := testAlertThresholds
+	disabled.MinCashFloat = 0
+	alerts = EvaluateDashboardAlerts(DashboardAlertInput{CashOnHand: 0, OpenCashSessions: 1}, disabled)
+	assert.False(t, alerts.LowCashOnHand)
+}
+
+func TestEvaluateDashboardAlerts_InventoryConcentration(t *testing.T) {
+	alerts := EvaluateDashboardAlerts(DashboardAlertInput{
+		InventoryValue:      1000,
+		InventoryByCategory: map[int64]float64{1: 700, 2: 300},
+	}, testAlertThresholds)
+	assert.True(t, alerts.InventoryConcentrated)
+	require.NotNil(t, alerts.TopCategoryID)
+	assert.Equal(t, int64(1), *alerts.TopCategoryID)
+	assert.Equal(t, 70.0, alerts.TopCategoryShare)
+
+	alerts = EvaluateDashboardAlerts(DashboardAlertInput{
+		InventoryValue:      1000,
+		InventoryByCategory: map[int64]float64{1: 400, 2: 350, 3: 250},
+	}, testAlertThresholds)
+	assert.False(t, alerts.InventoryConcentrated)
+	assert.Equal(t, 40.0, alerts.TopCategoryShare)
+}
+
+func TestDashboardAlertService_GetAlerts_UsesSettings(t *testing.T) {
+	reportService, loanRepo, paymentRepo, saleRepo, customerRepo, itemRepo := setupReportService()
+	settingRepo := new(mocks.MockSettingRepository)
+	service := NewDashboardAlertService(reportService, itemRepo, settingRepo, nil)
+	ctx := context.Background()
+
+	settingRepo.On("Get", mock.Anything, SettingAlertCategoryConcentration, mock.Anything).
+		Return(&domain.Setting{Key: SettingAlertCategoryConcentration, Value: 80.0}, nil)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(&repository.PaginatedResult[domain.Loan]{Total: 0}, nil)
+	paymentRepo.On("List", ctx, mock.AnythingOfType("repository.PaymentListParams")).Return(&repository.PaginatedResult[domain.Payment]{}, nil)
+	saleRepo.On("List", ctx, mock.AnythingOfType("repository.SaleListParams")).Return(&repository.PaginatedResult[domain.Sale]{}, nil)
+	customerRepo.On("List", ctx, mock.AnythingOfType("repository.CustomerListParams")).Return(&repository.PaginatedResult[domain.Customer]{}, nil)
+
+	jewelry, tools := int64(1), int64(2)
+	itemRepo.On("List", ctx, mock.AnythingOfType("repository.ItemListParams")).Return(&repository.PaginatedResult[domain.Item]{
+		Data: []domain.Item{
+			{CategoryID: &jewelry, Status: domain.ItemStatusPawned, AppraisedValue: 700},
+			{CategoryID: &tools, Status: domain.ItemStatusForSale, AppraisedValue: 300},
+			{CategoryID: &tools, Status: domain.ItemStatusSold, AppraisedValue: 5000},
+		},
+	}, nil)
+
+	alerts, err := service.GetAlerts(ctx, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, alerts.Thresholds.CategoryConcentration)
+	assert.Equal(t, DefaultAlertOverdueRatio, alerts.Thresholds.OverdueRatio)
+	assert.Equal(t, 70.0, alerts.TopCategoryShare)
+	assert.False(t, alerts.InventoryConcentrated)
+}
@@ -1,6 +1,10 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"pawnshop/internal/repository"
+)
 
 // Common service errors
 var (
@@ -23,6 +27,10 @@ var (
 	ErrInvalidStatus     = errors.New("invalid status for this operation")
 	ErrInvalidAmount     = errors.New("invalid amount")
 	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrNoOpenCashSession = errors.New("no open cash session for cash operation")
+	// ErrCashSessionNotOwned is returned when a cash operation names a session
+	// opened by another user
+	ErrCashSessionNotOwned = errors.New("cash session belongs to another user")
 
 	// Authorization errors
 	ErrUnauthorized = errors.New("unauthorized")
@@ -32,4 +40,16 @@ var (
 	ErrOperationFailed = errors.New("operation failed")
 	ErrDuplicateEntry  = errors.New("duplicate entry")
 	ErrConflict        = errors.New("conflict with existing data")
+
+	// ErrInUse is returned when deleting a record other records still reference;
+	// the error message names what references it
+	ErrInUse = repository.ErrInUse
+
+	// ErrItemHasActiveLoan is returned when creating a loan on an item that
+	// already backs an active or overdue loan
+	ErrItemHasActiveLoan = repository.ErrItemHasActiveLoan
+
+	// ErrRegisterHasOpenSession is returned when opening a cash session on a
+	// register that already has an open one
+	ErrRegisterHasOpenSession = repository.ErrRegisterHasOpenSession
 )
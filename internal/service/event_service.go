@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Webhook errors
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+)
+
+// webhookDeliveryTimeout bounds a single webhook delivery
+const webhookDeliveryTimeout = 10 * time.Second
+
+// WebhookSender delivers an event to a webhook endpoint
+type WebhookSender interface {
+	Send(ctx context.Context, webhook *domain.Webhook, event *domain.Event) error
+}
+
+// EventService records business events in the activity feed and delivers them
+// to the webhooks subscribed to their type
+type EventService struct {
+	eventRepo   repository.EventRepository
+	webhookRepo repository.WebhookRepository
+	sender      WebhookSender
+	logger      zerolog.Logger
+}
+
+// NewEventService creates a new EventService. A nil sender records events
+// without delivering them to webhooks.
+func NewEventService(
+	eventRepo repository.EventRepository,
+	webhookRepo repository.WebhookRepository,
+	sender WebhookSender,
+	logger zerolog.Logger,
+) *EventService {
+	return &EventService{
+		eventRepo:   eventRepo,
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		logger:      logger.With().Str("service", "events").Logger(),
+	}
+}
+
+// Emit records an event and delivers it in the background to every subscribed
+// webhook. Delivery failures are logged and never returned to the caller.
+func (s *EventService) Emit(ctx context.Context, event *domain.Event) error {
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+
+	if s.sender == nil {
+		return nil
+	}
+
+	webhooks, err := s.webhookRepo.ListActive(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Str("event_type", event.EventType).Msg("Failed to list webhooks")
+		return nil
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.EventType) {
+			continue
+		}
+		go s.deliver(webhook, event)
+	}
+
+	return nil
+}
+
+func (s *EventService) deliver(webhook *domain.Webhook, event *domain.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookDeliveryTimeout)
+	defer cancel()
+
+	if err := s.sender.Send(ctx, webhook, event); err != nil {
+		s.logger.Warn().Err(err).
+			Int64("webhook_id", webhook.ID).
+			Int64("event_id", event.ID).
+			Str("event_type", event.EventType).
+			Msg("Webhook delivery failed")
+	}
+}
+
+// ListEvents retrieves the activity feed
+func (s *EventService) ListEvents(ctx context.Context, params repository.EventListParams) (*repository.PaginatedResult[domain.Event], error) {
+	return s.eventRepo.List(ctx, params)
+}
+
+// CreateWebhookInput represents create webhook request data
+type CreateWebhookInput struct {
+	Name       string   `json:"name" validate:"required,min=2,max=100"`
+	URL        string   `json:"url" validate:"required,max=500"`
+	Secret     string   `json:"secret" validate:"max=255"`
+	EventTypes []string `json:"event_types"`
+}
+
+// CreateWebhook subscribes an endpoint to events
+func (s *EventService) CreateWebhook(ctx context.Context, input CreateWebhookInput, createdBy int64) (*domain.Webhook, error) {
+	endpoint, err := url.Parse(input.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+
+	webhook := &domain.Webhook{
+		Name:       input.Name,
+		URL:        input.URL,
+		Secret:     input.Secret,
+		EventTypes: input.EventTypes,
+		IsActive:   true,
+		CreatedBy:  createdBy,
+	}
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves all webhooks
+func (s *EventService) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	return s.webhookRepo.List(ctx)
+}
+
+// DeleteWebhook unsubscribes a webhook
+func (s *EventService) DeleteWebhook(ctx context.Context, id int64) error {
+	if _, err := s.webhookRepo.GetByID(ctx, id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return s.webhookRepo.Delete(ctx, id)
+}
+
+// HTTPWebhookSender posts events as JSON. When the webhook has a secret, the
+// body is signed with HMAC-SHA256 in the X-Webhook-Signature header.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookSender creates a new HTTPWebhookSender
+func NewHTTPWebhookSender() *HTTPWebhookSender {
+	return &HTTPWebhookSender{client: &http.Client{Timeout: webhookDeliveryTimeout}}
+}
+
+// Send posts the event to the webhook's URL
+func (s *HTTPWebhookSender) Send(ctx context.Context, webhook *domain.Webhook, event *domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event.EventType)
+	if webhook.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", "sha256="+signWebhookBody(webhook.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookBody returns the hex HMAC-SHA256 of body keyed with secret
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+type recordingWebhookSender struct {
+	sent chan int64
+}
+
+func (s *recordingWebhookSender) Send(ctx context.Context, webhook *domain.Webhook, event *domain.Event) error {
+	s.sent <- webhook.ID
+	return nil
+}
+
+func TestEventService_Emit_DeliversToSubscribedWebhooks(t *testing.T) {
+	eventRepo := new(mocks.MockEventRepository)
+	webhookRepo := new(mocks.MockWebhookRepository)
+	sender := &recordingWebhookSender{sent: make(chan int64, 3)}
+	service := NewEventService(eventRepo, webhookRepo, sender, zerolog.Nop())
+	ctx := context.Background()
+
+	eventRepo.On("Create", ctx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	webhookRepo.On("ListActive", ctx).Return([]*domain.Webhook{
+		{ID: 1, IsActive: true},
+		{ID: 2, IsActive: true, EventTypes: []string{domain.EventCashSessionOpened}},
+		{ID: 3, IsActive: true, EventTypes: []string{domain.EventCashSessionClosed}},
+	}, nil)
+
+	err := service.Emit(ctx, &domain.Event{EventType: domain.EventCashSessionClosed, EntityType: "cash_session", EntityID: 5})
+	assert.NoError(t, err)
+
+	delivered := map[int64]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case id := <-sender.sent:
+			delivered[id] = true
+		case <-time.After(time.Second):
+			t.Fatal("webhook was not delivered")
+		}
+	}
+	assert.Equal(t, map[int64]bool{1: true, 3: true}, delivered)
+	eventRepo.AssertExpectations(t)
+}
+
+func TestEventService_CreateWebhook_RejectsInvalidURL(t *testing.T) {
+	service := NewEventService(new(mocks.MockEventRepository), new(mocks.MockWebhookRepository), nil, zerolog.Nop())
+
+	_, err := service.CreateWebhook(context.Background(), CreateWebhookInput{Name: "ERP", URL: "ftp://erp.local/hook"}, 1)
+
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+}
+
+func TestSignWebhookBody(t *testing.T) {
+	assert.Len(t, signWebhookBody("secret", []byte("{}")), 64)
+	assert.Equal(t, signWebhookBody("secret", []byte("{}")), signWebhookBody("secret", []byte("{}")))
+	assert.NotEqual(t, signWebhookBody("secret", []byte("{}")), signWebhookBody("other", []byte("{}")))
+}
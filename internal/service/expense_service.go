@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"pawnshop/internal/domain"
@@ -50,12 +52,21 @@ type ExpenseService interface {
 
 	// GetTotalByBranchAndDate retrieves total expenses for a branch on a date
 	GetTotalByBranchAndDate(ctx context.Context, branchID int64, date time.Time) (float64, error)
+
+	// SuggestCategory suggests a category for an expense from its description
+	SuggestCategory(ctx context.Context, description string, amount float64, branchID *int64) (*domain.ExpenseCategorySuggestion, error)
 }
 
+// SettingExpenseCategoryKeywords holds the keyword to expense category code map
+// used to suggest a category from an expense description
+const SettingExpenseCategoryKeywords = "expense_category_keywords"
+
 type expenseService struct {
 	expenseRepo  repository.ExpenseRepository
 	categoryRepo repository.ExpenseCategoryRepository
 	branchRepo   repository.BranchRepository
+	settingRepo  repository.SettingRepository
+	cashService  *CashService
 }
 
 // NewExpenseService creates a new expense service
@@ -63,11 +74,15 @@ func NewExpenseService(
 	expenseRepo repository.ExpenseRepository,
 	categoryRepo repository.ExpenseCategoryRepository,
 	branchRepo repository.BranchRepository,
+	settingRepo repository.SettingRepository,
+	cashService *CashService,
 ) ExpenseService {
 	return &expenseService{
 		expenseRepo:  expenseRepo,
 		categoryRepo: categoryRepo,
 		branchRepo:   branchRepo,
+		settingRepo:  settingRepo,
+		cashService:  cashService,
 	}
 }
 
@@ -96,6 +111,7 @@ type CreateExpenseRequest struct {
 	PaymentMethod string    `json:"payment_method" validate:"required"`
 	ReceiptNumber string    `json:"receipt_number"`
 	Vendor        string    `json:"vendor"`
+	CashSessionID *int64    `json:"cash_session_id"`
 	CreatedBy     int64     `json:"created_by" validate:"required"`
 }
 
@@ -193,6 +209,18 @@ func (s *expenseService) Create(ctx context.Context, req CreateExpenseRequest) (
 		}
 	}
 
+	// Cash expenses are paid out of an open cash session
+	var cashSession *domain.CashSession
+	if s.cashService != nil && req.PaymentMethod == string(domain.PaymentMethodCash) {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, req.CreatedBy, req.CashSessionID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.cashService.EnsureCashAvailable(ctx, cashSession, req.Amount); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate expense number
 	expenseNumber, err := s.expenseRepo.GenerateExpenseNumber(ctx)
 	if err != nil {
@@ -216,6 +244,20 @@ func (s *expenseService) Create(ctx context.Context, req CreateExpenseRequest) (
 		return nil, err
 	}
 
+	if cashSession != nil {
+		if err := s.cashService.RecordExpenseMovement(ctx, cashSession.ID, expense.ID, expense.Amount, req.CreatedBy); err != nil {
+			return expense, err
+		}
+	}
+
+	// An uncategorized expense comes back with a suggested category for the
+	// user to confirm; the expense itself is left without one
+	if expense.CategoryID == nil {
+		if suggestion, err := s.SuggestCategory(ctx, req.Description, req.Amount, &req.BranchID); err == nil {
+			expense.CategorySuggestion = suggestion
+		}
+	}
+
 	return expense, nil
 }
 
@@ -338,3 +380,50 @@ func (s *expenseService) Approve(ctx context.Context, id int64, approvedBy int64
 func (s *expenseService) GetTotalByBranchAndDate(ctx context.Context, branchID int64, date time.Time) (float64, error) {
 	return s.expenseRepo.GetTotalByBranchAndDate(ctx, branchID, date)
 }
+
+// SuggestCategory matches the description against the configured keywords and
+// returns the mapped category, or nil when no keyword matches. The longest
+// matching keyword wins so that specific rules beat generic ones.
+func (s *expenseService) SuggestCategory(ctx context.Context, description string, amount float64, branchID *int64) (*domain.ExpenseCategorySuggestion, error) {
+	var keywords map[string]string
+	if !getSettingJSON(ctx, s.settingRepo, SettingExpenseCategoryKeywords, branchID, &keywords) {
+		return nil, nil
+	}
+
+	candidates := make([]string, 0, len(keywords))
+	for keyword := range keywords {
+		if strings.TrimSpace(keyword) != "" {
+			candidates = append(candidates, keyword)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) > len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	text := strings.ToLower(description)
+	for _, keyword := range candidates {
+		if !strings.Contains(text, strings.ToLower(strings.TrimSpace(keyword))) {
+			continue
+		}
+
+		category, err := s.categoryRepo.GetByCode(ctx, keywords[keyword])
+		if err != nil {
+			return nil, err
+		}
+		if category == nil || !category.IsActive {
+			continue
+		}
+
+		return &domain.ExpenseCategorySuggestion{
+			CategoryID:   category.ID,
+			CategoryCode: category.Code,
+			CategoryName: category.Name,
+			Keyword:      keyword,
+		}, nil
+	}
+
+	return nil, nil
+}
@@ -17,7 +17,7 @@ func setupExpenseService() (ExpenseService, *mocks.MockExpenseRepository, *mocks
 	expenseRepo := new(mocks.MockExpenseRepository)
 	categoryRepo := new(mocks.MockExpenseCategoryRepository)
 	branchRepo := new(mocks.MockBranchRepository)
-	service := NewExpenseService(expenseRepo, categoryRepo, branchRepo)
+	service := NewExpenseService(expenseRepo, categoryRepo, branchRepo, nil, nil)
 	return service, expenseRepo, categoryRepo, branchRepo
 }
 
@@ -709,3 +709,78 @@ func TestExpenseService_GetByID_NoCategoryLoaded(t *testing.T) {
 	assert.NotNil(t, result.Branch)
 	expenseRepo.AssertExpectations(t)
 }
+
+// Category suggestion tests
+
+func setupExpenseServiceWithKeywords(keywords map[string]interface{}) (ExpenseService, *mocks.MockExpenseRepository, *mocks.MockExpenseCategoryRepository, *mocks.MockBranchRepository) {
+	expenseRepo := new(mocks.MockExpenseRepository)
+	categoryRepo := new(mocks.MockExpenseCategoryRepository)
+	branchRepo := new(mocks.MockBranchRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingExpenseCategoryKeywords, mock.Anything).
+		Return(&domain.Setting{Key: SettingExpenseCategoryKeywords, Value: keywords}, nil)
+	service := NewExpenseService(expenseRepo, categoryRepo, branchRepo, settingRepo, nil)
+	return service, expenseRepo, categoryRepo, branchRepo
+}
+
+func TestExpenseService_SuggestCategory_KeywordMatch(t *testing.T) {
+	service, _, categoryRepo, _ := setupExpenseServiceWithKeywords(map[string]interface{}{
+		"luz":      "UTL",
+		"gasolina": "TRN",
+	})
+	ctx := context.Background()
+
+	categoryRepo.On("GetByCode", ctx, "TRN").Return(&domain.ExpenseCategory{ID: 6, Code: "TRN", Name: "Transporte", IsActive: true}, nil)
+
+	suggestion, err := service.SuggestCategory(ctx, "Compra de GASOLINA para moto", 120, nil)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, suggestion) {
+		assert.Equal(t, int64(6), suggestion.CategoryID)
+		assert.Equal(t, "TRN", suggestion.CategoryCode)
+		assert.Equal(t, "gasolina", suggestion.Keyword)
+	}
+	categoryRepo.AssertNotCalled(t, "GetByCode", ctx, "UTL")
+}
+
+func TestExpenseService_SuggestCategory_NoMatch(t *testing.T) {
+	service, _, categoryRepo, _ := setupExpenseServiceWithKeywords(map[string]interface{}{"luz": "UTL"})
+	ctx := context.Background()
+
+	suggestion, err := service.SuggestCategory(ctx, "Café para la oficina", 30, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, suggestion)
+	categoryRepo.AssertNotCalled(t, "GetByCode", mock.Anything, mock.Anything)
+}
+
+func TestExpenseService_Create_ReturnsCategorySuggestion(t *testing.T) {
+	service, expenseRepo, categoryRepo, branchRepo := setupExpenseServiceWithKeywords(map[string]interface{}{"luz": "UTL"})
+	ctx := context.Background()
+
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1, IsActive: true}, nil)
+	categoryRepo.On("GetByCode", ctx, "UTL").Return(&domain.ExpenseCategory{ID: 3, Code: "UTL", IsActive: true}, nil)
+	expenseRepo.On("GenerateExpenseNumber", ctx).Return("EXP-003", nil)
+	var saved *domain.Expense
+	expenseRepo.On("Create", ctx, mock.AnythingOfType("*domain.Expense")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.Expense)
+	}).Return(nil)
+
+	result, err := service.Create(ctx, CreateExpenseRequest{
+		BranchID:      1,
+		Description:   "Pago de luz de marzo",
+		Amount:        80,
+		ExpenseDate:   time.Now(),
+		PaymentMethod: "transfer",
+		CreatedBy:     100,
+	})
+
+	assert.NoError(t, err)
+	// The category is only suggested, never stored without the user confirming it
+	assert.Nil(t, saved.CategoryID)
+	assert.Nil(t, result.CategoryID)
+	if assert.NotNil(t, result.CategorySuggestion) {
+		assert.Equal(t, int64(3), result.CategorySuggestion.CategoryID)
+	}
+	categoryRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
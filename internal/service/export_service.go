@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// exportBatchSize is the number of rows written and committed per batch
+const exportBatchSize = 1000
+
+// exportMaxAttempts is how often a job is retried before it is marked failed
+const exportMaxAttempts = 5
+
+var (
+	ErrExportJobNotFound    = errors.New("export job not found")
+	ErrExportNotReady       = errors.New("export is not completed yet")
+	ErrInvalidExportDataset = errors.New("invalid export dataset, expected items or payments")
+)
+
+// ExportService writes large CSV exports in the background. Each batch is
+// synced to the file before its cursor is saved, so a job interrupted at any
+// point resumes from the last saved batch without duplicating or skipping rows.
+type ExportService struct {
+	jobRepo     repository.ExportJobRepository
+	itemRepo    repository.ItemRepository
+	paymentRepo repository.PaymentRepository
+	dir         string
+	batchSize   int
+	logger      zerolog.Logger
+}
+
+// NewExportService creates a new ExportService writing files to dir
+func NewExportService(
+	jobRepo repository.ExportJobRepository,
+	itemRepo repository.ItemRepository,
+	paymentRepo repository.PaymentRepository,
+	dir string,
+	logger zerolog.Logger,
+) *ExportService {
+	return &ExportService{
+		jobRepo:     jobRepo,
+		itemRepo:    itemRepo,
+		paymentRepo: paymentRepo,
+		dir:         dir,
+		batchSize:   exportBatchSize,
+		logger:      logger.With().Str("service", "exports").Logger(),
+	}
+}
+
+// CreateExportInput represents input for requesting an export
+type CreateExportInput struct {
+	Dataset   domain.ExportDataset `json:"dataset" validate:"required"`
+	BranchID  int64                `json:"-"`
+	CreatedBy int64                `json:"-"`
+}
+
+// Create queues an export. Requesting the same export again while it is still
+// running returns the existing job instead of starting another one.
+func (s *ExportService) Create(ctx context.Context, input CreateExportInput) (*domain.ExportJob, error) {
+	if !input.Dataset.IsValid() {
+		return nil, ErrInvalidExportDataset
+	}
+
+	existing, err := s.jobRepo.FindActive(ctx, input.Dataset, input.BranchID, input.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	job := &domain.ExportJob{
+		Dataset:   input.Dataset,
+		BranchID:  input.BranchID,
+		Status:    domain.ExportJobPending,
+		FileName:  fmt.Sprintf("%s_%d_%s.csv", input.Dataset, input.CreatedBy, time.Now().Format("20060102150405")),
+		CreatedBy: input.CreatedBy,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetByID retrieves an export job with its progress. Exports are only visible
+// to the user who requested them.
+func (s *ExportService) GetByID(ctx context.Context, id, userID int64) (*domain.ExportJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil || job.CreatedBy != userID {
+		return nil, ErrExportJobNotFound
+	}
+	return job, nil
+}
+
+// FilePath returns the path of a completed export's file
+func (s *ExportService) FilePath(job *domain.ExportJob) (string, error) {
+	if job.Status != domain.ExportJobCompleted {
+		return "", ErrExportNotReady
+	}
+	return filepath.Join(s.dir, job.FileName), nil
+}
+
+// ProcessPending runs every pending or interrupted export to completion and
+// returns the number of jobs completed. A job that keeps failing is marked
+// failed after exportMaxAttempts runs.
+func (s *ExportService) ProcessPending(ctx context.Context) (int, error) {
+	jobs, err := s.jobRepo.ListUnfinished(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, job := range jobs {
+		if err := s.Run(ctx, job); err != nil {
+			s.logger.Error().Err(err).Int64("job_id", job.ID).Int("attempt", job.Attempts+1).Msg("Export interrupted")
+
+			job.Attempts++
+			if job.Attempts >= exportMaxAttempts {
+				message := err.Error()
+				job.Status = domain.ExportJobFailed
+				job.Error = &message
+			}
+			if err := s.jobRepo.SaveProgress(ctx, job); err != nil {
+				s.logger.Error().Err(err).Int64("job_id", job.ID).Msg("Failed to record export attempt")
+			}
+			continue
+		}
+		completed++
+	}
+
+	return completed, nil
+}
+
+// Run writes an export from its last saved cursor until every row is written.
+// The file is cut back to the size saved with the cursor first, dropping any
+// batch written after the last successful save.
+func (s *ExportService) Run(ctx context.Context, job *domain.ExportJob) error {
+	if job.IsFinished() {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dir, job.FileName), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer file.Close()
+
+	if err := file.Truncate(job.BytesWritten); err != nil {
+		return fmt.Errorf("failed to rewind export file: %w", err)
+	}
+	if _, err := file.Seek(job.BytesWritten, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind export file: %w", err)
+	}
+
+	if job.BytesWritten == 0 {
+		header, err := encodeCSV([][]string{exportHeader(job.Dataset)})
+		if err != nil {
+			return err
+		}
+		if err := s.commit(ctx, file, job, header, job.Cursor, 0, domain.ExportJobRunning); err != nil {
+			return err
+		}
+	}
+
+	for {
+		records, lastID, err := s.fetchBatch(ctx, job)
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			return s.commit(ctx, file, job, nil, job.Cursor, 0, domain.ExportJobCompleted)
+		}
+
+		data, err := encodeCSV(records)
+		if err != nil {
+			return err
+		}
+		if err := s.commit(ctx, file, job, data, lastID, len(records), domain.ExportJobRunning); err != nil {
+			return err
+		}
+	}
+}
+
+// commit appends data to the file, syncs it and only then saves the new
+// cursor. The job is updated in place once the save succeeds.
+func (s *ExportService) commit(ctx context.Context, file *os.File, job *domain.ExportJob, data []byte, cursor int64, rows int, status domain.ExportJobStatus) error {
+	if len(data) > 0 {
+		if _, err := file.Write(data); err != nil {
+			return fmt.Errorf("failed to write export file: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("failed to write export file: %w", err)
+		}
+	}
+
+	next := *job
+	next.Status = status
+	next.Cursor = cursor
+	next.RowsExported += rows
+	next.BytesWritten += int64(len(data))
+	if status == domain.ExportJobCompleted {
+		now := time.Now()
+		next.CompletedAt = &now
+	}
+
+	if err := s.jobRepo.SaveProgress(ctx, &next); err != nil {
+		return err
+	}
+
+	*job = next
+	return nil
+}
+
+// fetchBatch reads the rows after the job's cursor and returns them as CSV
+// records along with the ID of the last one
+func (s *ExportService) fetchBatch(ctx context.Context, job *domain.ExportJob) ([][]string, int64, error) {
+	records := [][]string{}
+	lastID := job.Cursor
+
+	switch job.Dataset {
+	case domain.ExportDatasetItems:
+		items, err := s.itemRepo.ListAfter(ctx, job.BranchID, job.Cursor, s.batchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, item := range items {
+			records = append(records, itemExportRecord(item))
+			lastID = item.ID
+		}
+	case domain.ExportDatasetPayments:
+		payments, err := s.paymentRepo.ListAfter(ctx, job.BranchID, job.Cursor, s.batchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, payment := range payments {
+			records = append(records, paymentExportRecord(payment))
+			lastID = payment.ID
+		}
+	default:
+		return nil, 0, ErrInvalidExportDataset
+	}
+
+	return records, lastID, nil
+}
+
+func exportHeader(dataset domain.ExportDataset) []string {
+	if dataset == domain.ExportDatasetPayments {
+		return []string{"id", "payment_number", "branch_id", "loan_id", "customer_id", "amount", "principal_amount",
+			"interest_amount", "late_fee_amount", "payment_method", "status", "payment_date"}
+	}
+	return []string{"id", "sku", "name", "branch_id", "category_id", "status", "condition",
+		"appraised_value", "loan_value", "sale_price", "created_at"}
+}
+
+func itemExportRecord(item *domain.Item) []string {
+	categoryID := ""
+	if item.CategoryID != nil {
+		categoryID = strconv.FormatInt(*item.CategoryID, 10)
+	}
+	salePrice := ""
+	if item.SalePrice != nil {
+		salePrice = strconv.FormatFloat(*item.SalePrice, 'f', 2, 64)
+	}
+
+	return []string{
+		strconv.FormatInt(item.ID, 10),
+		item.SKU,
+		item.Name,
+		strconv.FormatInt(item.BranchID, 10),
+		categoryID,
+		string(item.Status),
+		item.Condition,
+		strconv.FormatFloat(item.AppraisedValue, 'f', 2, 64),
+		strconv.FormatFloat(item.LoanValue, 'f', 2, 64),
+		salePrice,
+		item.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func paymentExportRecord(payment *domain.Payment) []string {
+	return []string{
+		strconv.FormatInt(payment.ID, 10),
+		payment.PaymentNumber,
+		strconv.FormatInt(payment.BranchID, 10),
+		strconv.FormatInt(payment.LoanID, 10),
+		strconv.FormatInt(payment.CustomerID, 10),
+		strconv.FormatFloat(payment.Amount, 'f', 2, 64),
+		strconv.FormatFloat(payment.PrincipalAmount, 'f', 2, 64),
+		strconv.FormatFloat(payment.InterestAmount, 'f', 2, 64),
+		strconv.FormatFloat(payment.LateFeeAmount, 'f', 2, 64),
+		string(payment.PaymentMethod),
+		string(payment.Status),
+		payment.PaymentDate.Format(time.RFC3339),
+	}
+}
+
+func encodeCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to encode export rows: %w", err)
+	}
+	return buf.Bytes(), nil
+}
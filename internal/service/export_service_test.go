@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupExportService(t *testing.T) (*ExportService, *mocks.MockExportJobRepository, *mocks.MockItemRepository) {
+	jobRepo := new(mocks.MockExportJobRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	service := NewExportService(jobRepo, itemRepo, paymentRepo, t.TempDir(), zerolog.Nop())
+	return service, jobRepo, itemRepo
+}
+
+func TestExportService_Create_ReturnsActiveJob(t *testing.T) {
+	service, jobRepo, _ := setupExportService(t)
+	ctx := context.Background()
+
+	active := &domain.ExportJob{ID: 4, Dataset: domain.ExportDatasetItems, BranchID: 1, Status: domain.ExportJobRunning, CreatedBy: 7}
+	jobRepo.On("FindActive", ctx, domain.ExportDatasetItems, int64(1), int64(7)).Return(active, nil)
+
+	job, err := service.Create(ctx, CreateExportInput{Dataset: domain.ExportDatasetItems, BranchID: 1, CreatedBy: 7})
+
+	require.NoError(t, err)
+	assert.Same(t, active, job)
+	jobRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestExportService_Run_ResumesInterruptedExportWithoutDuplicates(t *testing.T) {
+	service, jobRepo, itemRepo := setupExportService(t)
+	service.batchSize = 2
+	ctx := context.Background()
+
+	item := func(id int64) *domain.Item {
+		return &domain.Item{ID: id, BranchID: 1, SKU: "SKU", Name: "Item", Status: domain.ItemStatusAvailable}
+	}
+	itemRepo.On("ListAfter", ctx, int64(1), int64(0), 2).Return([]*domain.Item{item(3), item(5)}, nil)
+	itemRepo.On("ListAfter", ctx, int64(1), int64(5), 2).Return([]*domain.Item{item(8), item(9)}, nil)
+	itemRepo.On("ListAfter", ctx, int64(1), int64(9), 2).Return([]*domain.Item{item(12), item(15)}, nil)
+	itemRepo.On("ListAfter", ctx, int64(1), int64(15), 2).Return([]*domain.Item{item(20)}, nil)
+	itemRepo.On("ListAfter", ctx, int64(1), int64(20), 2).Return([]*domain.Item{}, nil)
+
+	// The header and the first two batches are saved; the third batch reaches
+	// the file but the worker dies before its cursor is saved
+	jobRepo.On("SaveProgress", ctx, mock.Anything).Return(nil).Times(3)
+	jobRepo.On("SaveProgress", ctx, mock.Anything).Return(errors.New("connection reset")).Once()
+	jobRepo.On("SaveProgress", ctx, mock.Anything).Return(nil)
+
+	job := &domain.ExportJob{ID: 1, Dataset: domain.ExportDatasetItems, BranchID: 1, Status: domain.ExportJobPending, FileName: "items.csv", CreatedBy: 7}
+	jobRepo.On("ListUnfinished", ctx).Return([]*domain.ExportJob{job}, nil)
+
+	completed, err := service.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, completed)
+	assert.Equal(t, domain.ExportJobRunning, job.Status)
+	assert.Equal(t, int64(9), job.Cursor)
+	assert.Equal(t, 4, job.RowsExported)
+	assert.Equal(t, 1, job.Attempts)
+
+	completed, err = service.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	assert.Equal(t, domain.ExportJobCompleted, job.Status)
+	assert.Equal(t, 7, job.RowsExported)
+	assert.NotNil(t, job.CompletedAt)
+
+	file, err := os.Open(filepath.Join(service.dir, job.FileName))
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, record := range records[1:] {
+		ids = append(ids, record[0])
+	}
+	assert.Equal(t, "id", records[0][0])
+	assert.Equal(t, []string{"3", "5", "8", "9", "12", "15", "20"}, ids)
+
+	info, err := file.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, job.BytesWritten, info.Size())
+}
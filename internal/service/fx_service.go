@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Exchange rate settings
+const (
+	// SettingCurrency is the organization's default currency
+	SettingCurrency = "currency"
+	// SettingReportBaseCurrency is the currency consolidated reports convert branch figures to
+	SettingReportBaseCurrency = "report_base_currency"
+	// SettingFXRevaluationMode selects which day's rate converts report figures
+	SettingFXRevaluationMode = "fx_revaluation_mode"
+)
+
+// DefaultCurrency is used when no currency is configured
+const DefaultCurrency = "GTQ"
+
+// Exchange rate errors
+var (
+	ErrFXRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidFXRate  = errors.New("invalid exchange rate")
+)
+
+// FXService manages exchange rates and converts figures to the report base currency
+type FXService struct {
+	rateRepo    repository.FXRateRepository
+	settingRepo repository.SettingRepository
+}
+
+// NewFXService creates a new FXService
+func NewFXService(rateRepo repository.FXRateRepository, settingRepo repository.SettingRepository) *FXService {
+	return &FXService{
+		rateRepo:    rateRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// SetFXRateInput represents set exchange rate request data
+type SetFXRateInput struct {
+	FromCurrency string  `json:"from_currency" validate:"required,min=3,max=10"`
+	ToCurrency   string  `json:"to_currency" validate:"required,min=3,max=10"`
+	RateDate     string  `json:"rate_date" validate:"required"`
+	Rate         float64 `json:"rate" validate:"required,gt=0"`
+}
+
+// SetRate records the rate of a currency pair on a day, replacing any previous rate for that day
+func (s *FXService) SetRate(ctx context.Context, input SetFXRateInput) (*domain.FXRate, error) {
+	rateDate, err := domain.ParseDate(input.RateDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: rate_date must be YYYY-MM-DD", ErrInvalidFXRate)
+	}
+
+	rate := &domain.FXRate{
+		FromCurrency: normalizeCurrency(input.FromCurrency),
+		ToCurrency:   normalizeCurrency(input.ToCurrency),
+		RateDate:     rateDate,
+		Rate:         input.Rate,
+	}
+	if rate.FromCurrency == rate.ToCurrency {
+		return nil, fmt.Errorf("%w: currencies must differ", ErrInvalidFXRate)
+	}
+	if rate.Rate <= 0 {
+		return nil, fmt.Errorf("%w: rate must be positive", ErrInvalidFXRate)
+	}
+
+	if err := s.rateRepo.Upsert(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// ListRates retrieves exchange rates
+func (s *FXService) ListRates(ctx context.Context, params repository.FXRateListParams) ([]*domain.FXRate, error) {
+	return s.rateRepo.List(ctx, params)
+}
+
+// DeleteRate deletes an exchange rate
+func (s *FXService) DeleteRate(ctx context.Context, id int64) error {
+	return s.rateRepo.Delete(ctx, id)
+}
+
+// BaseCurrency returns the configured report base currency, falling back to the
+// organization's default currency
+func (s *FXService) BaseCurrency(ctx context.Context) string {
+	currency := getSettingString(ctx, s.settingRepo, SettingCurrency, nil, DefaultCurrency)
+	return normalizeCurrency(getSettingString(ctx, s.settingRepo, SettingReportBaseCurrency, nil, currency))
+}
+
+// RevaluationMode returns the configured revaluation mode, converting at each
+// transaction's date by default
+func (s *FXService) RevaluationMode(ctx context.Context) domain.FXRevaluationMode {
+	mode := domain.FXRevaluationMode(getSettingString(ctx, s.settingRepo, SettingFXRevaluationMode, nil, ""))
+	if mode.IsValid() {
+		return mode
+	}
+	return domain.FXRevaluationTransactionDate
+}
+
+// NewConverter creates a converter to the given base currency
+func (s *FXService) NewConverter(baseCurrency string) *FXConverter {
+	return &FXConverter{
+		rateRepo:     s.rateRepo,
+		baseCurrency: normalizeCurrency(baseCurrency),
+		rates:        make(map[fxRateKey]float64),
+	}
+}
+
+type fxRateKey struct {
+	currency string
+	day      domain.Date
+}
+
+// FXConverter converts amounts to a base currency, caching the rates it looks up
+type FXConverter struct {
+	rateRepo     repository.FXRateRepository
+	baseCurrency string
+	rates        map[fxRateKey]float64
+}
+
+// BaseCurrency returns the currency amounts are converted to
+func (c *FXConverter) BaseCurrency() string {
+	return c.baseCurrency
+}
+
+// Rate returns how many base currency units one unit of currency was worth on day,
+// using the latest rate set on or before that day. The inverse pair is used when
+// only the base-to-currency rate was recorded.
+func (c *FXConverter) Rate(ctx context.Context, currency string, day domain.Date) (float64, error) {
+	currency = normalizeCurrency(currency)
+	if currency == "" || currency == c.baseCurrency {
+		return 1, nil
+	}
+
+	key := fxRateKey{currency: currency, day: day}
+	if rate, ok := c.rates[key]; ok {
+		return rate, nil
+	}
+
+	rate, err := c.rateRepo.GetLatest(ctx, currency, c.baseCurrency, day)
+	if err != nil {
+		return 0, err
+	}
+	value := 0.0
+	if rate != nil {
+		value = rate.Rate
+	} else {
+		inverse, err := c.rateRepo.GetLatest(ctx, c.baseCurrency, currency, day)
+		if err != nil {
+			return 0, err
+		}
+		if inverse == nil {
+			return 0, fmt.Errorf("%w: %s to %s on %s", ErrFXRateNotFound, currency, c.baseCurrency, day)
+		}
+		value = 1 / inverse.Rate
+	}
+
+	c.rates[key] = value
+	return value, nil
+}
+
+// Convert converts an amount in currency to the base currency at day's rate
+func (c *FXConverter) Convert(ctx context.Context, amount float64, currency string, day domain.Date) (float64, error) {
+	rate, err := c.Rate(ctx, currency, day)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
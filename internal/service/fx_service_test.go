@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupFXService() (*FXService, *mocks.MockFXRateRepository, *mocks.MockSettingRepository) {
+	rateRepo := new(mocks.MockFXRateRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	return NewFXService(rateRepo, settingRepo), rateRepo, settingRepo
+}
+
+func TestFXService_SetRate_NormalizesCurrencies(t *testing.T) {
+	service, rateRepo, _ := setupFXService()
+	ctx := context.Background()
+
+	rateRepo.On("Upsert", ctx, mock.AnythingOfType("*domain.FXRate")).Return(nil)
+
+	rate, err := service.SetRate(ctx, SetFXRateInput{FromCurrency: "usd", ToCurrency: " gtq", RateDate: "2024-03-01", Rate: 7.8})
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", rate.FromCurrency)
+	assert.Equal(t, "GTQ", rate.ToCurrency)
+	assert.Equal(t, domain.NewDate(2024, 3, 1), rate.RateDate)
+}
+
+func TestFXService_SetRate_SameCurrency(t *testing.T) {
+	service, rateRepo, _ := setupFXService()
+
+	_, err := service.SetRate(context.Background(), SetFXRateInput{FromCurrency: "GTQ", ToCurrency: "gtq", RateDate: "2024-03-01", Rate: 1})
+
+	assert.ErrorIs(t, err, ErrInvalidFXRate)
+	rateRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+}
+
+func TestFXService_BaseCurrency_FallsBackToCurrency(t *testing.T) {
+	service, _, settingRepo := setupFXService()
+	ctx := context.Background()
+
+	settingRepo.On("Get", ctx, SettingCurrency, mock.Anything).Return(&domain.Setting{Value: "MXN"}, nil)
+	settingRepo.On("Get", ctx, SettingReportBaseCurrency, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	assert.Equal(t, "MXN", service.BaseCurrency(ctx))
+}
+
+func TestFXConverter_Rate_UsesInversePair(t *testing.T) {
+	service, rateRepo, _ := setupFXService()
+	ctx := context.Background()
+	day := domain.NewDate(2024, 3, 1)
+
+	rateRepo.On("GetLatest", ctx, "USD", "GTQ", day).Return(nil, nil)
+	rateRepo.On("GetLatest", ctx, "GTQ", "USD", day).Return(&domain.FXRate{Rate: 0.125}, nil)
+
+	converter := service.NewConverter("GTQ")
+	amount, err := converter.Convert(ctx, 10, "USD", day)
+
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, amount)
+
+	same, err := converter.Convert(ctx, 10, "GTQ", day)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, same)
+}
@@ -25,6 +25,9 @@ type SettingServiceInterface interface {
 	Set(ctx context.Context, input SetSettingInput) (*domain.Setting, error)
 	SetMultiple(ctx context.Context, settings []SetSettingInput) error
 	Delete(ctx context.Context, key string, branchID *int64) error
+	Export(ctx context.Context) (*SettingsExport, error)
+	Import(ctx context.Context, data []byte, mode string) (*SettingsImportResult, error)
+	History(ctx context.Context, key string, branchID *int64, limit int) ([]*domain.SettingHistory, error)
 	GetString(ctx context.Context, key string, branchID *int64, defaultValue string) string
 	GetInt(ctx context.Context, key string, branchID *int64, defaultValue int) int
 	GetFloat(ctx context.Context, key string, branchID *int64, defaultValue float64) float64
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Settings controlling the reconciliation of inventory value to accounting
+const (
+	// SettingInventoryReconciliationAccount is the code of the account holding the value of the stock
+	SettingInventoryReconciliationAccount = "inventory_reconciliation_account"
+	// SettingInventoryValuationBasis is how on-hand items are valued: cost or appraised
+	SettingInventoryValuationBasis = "inventory_valuation_basis"
+	// SettingInventoryReconciliationTolerance is the variance accepted as reconciled
+	SettingInventoryReconciliationTolerance = "inventory_reconciliation_tolerance"
+	// SettingPeriodCloseInventoryCheck is what closing a period does with an
+	// unreconciled inventory: off, warn or block
+	SettingPeriodCloseInventoryCheck = "period_close_inventory_check"
+)
+
+// DefaultInventoryReconciliationAccount is Artículos para Venta
+const DefaultInventoryReconciliationAccount = "1320"
+
+// Period close inventory check modes
+const (
+	InventoryCheckOff   = "off"
+	InventoryCheckWarn  = "warn"
+	InventoryCheckBlock = "block"
+)
+
+var (
+	ErrInventoryReconciliationDisabled = errors.New("inventory reconciliation is not configured")
+	ErrInventoryNotReconciled          = errors.New("inventory value does not match the inventory account")
+)
+
+// SetInventoryReconciliation enables reconciling the value of the stock on hand
+// to the inventory account
+func (s *AccountingService) SetInventoryReconciliation(valuationRepo repository.InventoryValuationRepository, settingRepo repository.SettingRepository) {
+	s.valuationRepo = valuationRepo
+	s.settingRepo = settingRepo
+}
+
+// ReconcileInventory compares a branch's inventory account balance at asOf to
+// the value of the items it held then and reports the variance along with the
+// items counted, largest value first
+func (s *AccountingService) ReconcileInventory(ctx context.Context, branchID int64, asOf time.Time) (*domain.InventoryReconciliation, error) {
+	if s.valuationRepo == nil {
+		return nil, ErrInventoryReconciliationDisabled
+	}
+
+	result := &domain.InventoryReconciliation{
+		BranchID:       branchID,
+		AsOf:           asOf,
+		AccountCode:    getSettingString(ctx, s.settingRepo, SettingInventoryReconciliationAccount, &branchID, DefaultInventoryReconciliationAccount),
+		ValuationBasis: getSettingString(ctx, s.settingRepo, SettingInventoryValuationBasis, &branchID, domain.InventoryValuationCost),
+		Tolerance:      getSettingFloat(ctx, s.settingRepo, SettingInventoryReconciliationTolerance, &branchID, 0),
+		Items:          []*domain.InventoryReconciliationItem{},
+	}
+
+	balance, err := s.inventoryBalance(ctx, result.AccountCode, branchID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	result.AccountBalance = roundCents(balance)
+
+	onHand, err := s.valuationRepo.ListOnHand(ctx, branchID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	var itemsValue float64
+	for _, item := range onHand {
+		counted := valueInventoryItem(item, result.ValuationBasis)
+		itemsValue += counted.Value
+		result.Items = append(result.Items, counted)
+	}
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		return result.Items[i].Value > result.Items[j].Value
+	})
+
+	result.ItemCount = len(result.Items)
+	result.ItemsValue = roundCents(itemsValue)
+	result.Variance = roundCents(result.AccountBalance - result.ItemsValue)
+	result.Reconciled = math.Abs(result.Variance) <= result.Tolerance
+	return result, nil
+}
+
+// inventoryBalance returns the branch balance of the inventory account and its
+// sub-accounts at asOf
+func (s *AccountingService) inventoryBalance(ctx context.Context, code string, branchID int64, asOf time.Time) (float64, error) {
+	account, err := s.accountRepo.GetByCode(ctx, code)
+	if err != nil || account == nil {
+		return 0, fmt.Errorf("account %s not found", code)
+	}
+
+	var total float64
+	pending := []*domain.Account{account}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		balance, err := s.entryRepo.GetAccountBalanceByBranch(ctx, current.ID, branchID, asOf)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get balance of account %s: %w", current.Code, err)
+		}
+		total += balance
+
+		children, err := s.accountRepo.ListChildren(ctx, current.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list sub-accounts of %s: %w", current.Code, err)
+		}
+		pending = append(pending, children...)
+	}
+	return total, nil
+}
+
+// valueInventoryItem values an on-hand item on the given basis
+func valueInventoryItem(item *domain.InventoryItemValue, basis string) *domain.InventoryReconciliationItem {
+	counted := &domain.InventoryReconciliationItem{
+		ItemID: item.ItemID,
+		SKU:    item.SKU,
+		Name:   item.Name,
+		Status: item.Status,
+		Value:  item.AppraisedValue,
+	}
+	if basis != domain.InventoryValuationAppraised {
+		if item.AcquisitionPrice != nil {
+			counted.Value = *item.AcquisitionPrice
+		} else {
+			counted.ValuedAtAppraisal = true
+		}
+	}
+	return counted
+}
+
+// SetInventoryCheck makes closing a period reconcile the branch inventory first
+func (s *AccountingPeriodService) SetInventoryCheck(accountingService *AccountingService) {
+	s.accountingService = accountingService
+}
+
+// checkInventory reconciles the branch inventory at the end of the period. An
+// unreconciled inventory stops the close only when the branch blocks on it.
+func (s *AccountingPeriodService) checkInventory(ctx context.Context, period *domain.AccountingPeriod) error {
+	if s.accountingService == nil {
+		return nil
+	}
+	mode := getSettingString(ctx, s.settingRepo, SettingPeriodCloseInventoryCheck, &period.BranchID, InventoryCheckWarn)
+	if mode == InventoryCheckOff {
+		return nil
+	}
+
+	endOfPeriod := period.EndDate().AddDate(0, 0, 1).Add(-time.Nanosecond)
+	check, err := s.accountingService.ReconcileInventory(ctx, period.BranchID, endOfPeriod)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile inventory: %w", err)
+	}
+	period.InventoryCheck = check
+
+	if !check.Reconciled && mode == InventoryCheckBlock {
+		return fmt.Errorf("%w: variance of %.2f in account %s", ErrInventoryNotReconciled, check.Variance, check.AccountCode)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Settings controlling which items enter the attention queue
+const (
+	// SettingItemAttentionAgedDays is how long an item may be for sale before it needs attention
+	SettingItemAttentionAgedDays = "item_attention_aged_days"
+	// SettingItemAttentionTransferDays is how long a transfer may stay in transit before it needs attention
+	SettingItemAttentionTransferDays = "item_attention_transfer_days"
+	// SettingItemAttentionPhotoValue is the appraised value from which an item without photos needs attention
+	SettingItemAttentionPhotoValue = "item_attention_photo_value"
+)
+
+// Default attention queue thresholds
+const (
+	DefaultItemAttentionAgedDays     = 90
+	DefaultItemAttentionTransferDays = 3
+	DefaultItemAttentionPhotoValue   = 5000.0
+)
+
+// itemAttentionRules are the configured thresholds of a branch's queue
+type itemAttentionRules struct {
+	agedDays     int
+	transferDays int
+	photoValue   float64
+}
+
+// SetAttentionQueue enables the queue of items needing attention
+func (s *ItemService) SetAttentionQueue(attentionRepo repository.ItemAttentionRepository) {
+	s.attentionRepo = attentionRepo
+}
+
+// GetAttentionQueue lists a branch's items needing action, most urgent first,
+// with the reason each one is there
+func (s *ItemService) GetAttentionQueue(ctx context.Context, branchID int64, now time.Time) ([]*domain.ItemAttention, error) {
+	if s.attentionRepo == nil {
+		return nil, errors.New("item attention queue is not configured")
+	}
+
+	rules := itemAttentionRules{
+		agedDays:     getSettingInt(ctx, s.settingRepo, SettingItemAttentionAgedDays, &branchID, DefaultItemAttentionAgedDays),
+		transferDays: getSettingInt(ctx, s.settingRepo, SettingItemAttentionTransferDays, &branchID, DefaultItemAttentionTransferDays),
+		photoValue:   getSettingFloat(ctx, s.settingRepo, SettingItemAttentionPhotoValue, &branchID, DefaultItemAttentionPhotoValue),
+	}
+
+	candidates, err := s.attentionRepo.ListCandidates(ctx, branchID, rules.photoValue)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := []*domain.ItemAttention{}
+	for _, candidate := range candidates {
+		if entry := rules.evaluate(candidate, now); entry != nil {
+			queue = append(queue, entry)
+		}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		if queue[i].Priority != queue[j].Priority {
+			return queue[i].Priority < queue[j].Priority
+		}
+		return queue[i].Since.Before(queue[j].Since)
+	})
+	return queue, nil
+}
+
+// itemAttentionReason is a reason an item needs attention and since when
+type itemAttentionReason struct {
+	code   string
+	detail string
+	since  time.Time
+}
+
+// evaluate returns the queue entry of an item, under its most urgent reason,
+// or nil when the item is fine
+func (r itemAttentionRules) evaluate(c *domain.ItemAttentionCandidate, now time.Time) *domain.ItemAttention {
+	var reasons []itemAttentionReason
+
+	switch c.Status {
+	case domain.ItemStatusInTransfer:
+		shippedAt := c.StatusSince
+		if c.ShippedAt != nil {
+			shippedAt = *c.ShippedAt
+		}
+		if days := daysBetween(shippedAt, now); days >= r.transferDays {
+			detail := fmt.Sprintf("Traslado sin recibir desde hace %d día(s)", days)
+			if c.TransferNumber != nil {
+				detail = fmt.Sprintf("Traslado %s sin recibir desde hace %d día(s)", *c.TransferNumber, days)
+			}
+			reasons = append(reasons, itemAttentionReason{domain.ItemAttentionTransferUnreceived, detail, shippedAt})
+		}
+	case domain.ItemStatusConfiscated:
+		reasons = append(reasons, itemAttentionReason{domain.ItemAttentionConfiscatedUnlisted,
+			fmt.Sprintf("Decomisado hace %d día(s) y sin poner a la venta", daysBetween(c.StatusSince, now)), c.StatusSince})
+	case domain.ItemStatusForSale:
+		if days := daysBetween(c.StatusSince, now); days >= r.agedDays {
+			reasons = append(reasons, itemAttentionReason{domain.ItemAttentionAgedForSale,
+				fmt.Sprintf("A la venta desde hace %d día(s)", days), c.StatusSince})
+		}
+	}
+
+	if c.PhotoCount == 0 && c.AppraisedValue >= r.photoValue && itemInCustody(c.Status) {
+		reasons = append(reasons, itemAttentionReason{domain.ItemAttentionMissingPhotos,
+			fmt.Sprintf("Sin fotos con avalúo de %s", formatCurrency(c.AppraisedValue)), c.StatusSince})
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return domain.ItemAttentionPriority[reasons[i].code] < domain.ItemAttentionPriority[reasons[j].code]
+	})
+
+	top := reasons[0]
+	entry := &domain.ItemAttention{
+		ItemID:         c.ItemID,
+		BranchID:       c.BranchID,
+		SKU:            c.SKU,
+		Name:           c.Name,
+		Status:         c.Status,
+		AppraisedValue: c.AppraisedValue,
+		Reason:         top.code,
+		Priority:       domain.ItemAttentionPriority[top.code],
+		Detail:         top.detail,
+		Since:          top.since,
+		DaysWaiting:    daysBetween(top.since, now),
+	}
+	for _, reason := range reasons[1:] {
+		entry.OtherReasons = append(entry.OtherReasons, reason.code)
+	}
+	return entry
+}
+
+// itemInCustody reports whether the shop is holding an item in the given status
+func itemInCustody(status domain.ItemStatus) bool {
+	switch status {
+	case domain.ItemStatusAvailable, domain.ItemStatusPawned, domain.ItemStatusCollateral,
+		domain.ItemStatusForSale, domain.ItemStatusConfiscated, domain.ItemStatusInTransfer:
+		return true
+	}
+	return false
+}
+
+// daysBetween returns the whole days from since to now, never negative
+func daysBetween(since, now time.Time) int {
+	if now.Before(since) {
+		return 0
+	}
+	return int(now.Sub(since).Hours() / 24)
+}
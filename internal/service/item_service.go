@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"pawnshop/internal/domain"
@@ -16,6 +17,10 @@ type ItemService struct {
 	branchRepo   repository.BranchRepository
 	categoryRepo repository.CategoryRepository
 	customerRepo repository.CustomerRepository
+	settingRepo  repository.SettingRepository
+
+	stockTakeRepo repository.StockTakeRepository
+	attentionRepo repository.ItemAttentionRepository
 }
 
 // NewItemService creates a new ItemService
@@ -24,13 +29,67 @@ func NewItemService(
 	branchRepo repository.BranchRepository,
 	categoryRepo repository.CategoryRepository,
 	customerRepo repository.CustomerRepository,
+	settingRepo repository.SettingRepository,
 ) *ItemService {
 	return &ItemService{
 		itemRepo:     itemRepo,
 		branchRepo:   branchRepo,
 		categoryRepo: categoryRepo,
 		customerRepo: customerRepo,
+		settingRepo:  settingRepo,
+	}
+}
+
+// SetStockTakes enables locking a branch's items while it has an inventory count in progress
+func (s *ItemService) SetStockTakes(stockTakeRepo repository.StockTakeRepository) {
+	s.stockTakeRepo = stockTakeRepo
+}
+
+// checkStockTakeLock rejects item edits while the item's branch is being
+// counted, unless the branch only flags those edits in the count's report
+func (s *ItemService) checkStockTakeLock(ctx context.Context, branchID int64) error {
+	if s.stockTakeRepo == nil || !getSettingBool(ctx, s.settingRepo, SettingStockTakeLockItems, &branchID, true) {
+		return nil
+	}
+	active, err := s.stockTakeRepo.GetActiveByBranch(ctx, branchID)
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return ErrStockTakeInProgress
+	}
+	return nil
+}
+
+// SettingItemConditionScale is the setting key holding the item condition scale
+const SettingItemConditionScale = "item_condition_scale"
+
+// DefaultLoanToValueRatio is the loan-to-value ratio used for items without a category
+const DefaultLoanToValueRatio = 0.70
+
+// GetConditionScale returns the configured condition scale, or the default scale
+// when the setting is missing or malformed
+func (s *ItemService) GetConditionScale(ctx context.Context) []domain.ConditionGrade {
+	var scale []domain.ConditionGrade
+	if !getSettingJSON(ctx, s.settingRepo, SettingItemConditionScale, nil, &scale) || len(scale) == 0 {
+		return domain.DefaultConditionScale()
+	}
+	for _, grade := range scale {
+		if grade.Code == "" || grade.Factor <= 0 {
+			return domain.DefaultConditionScale()
+		}
+	}
+	return scale
+}
+
+// conditionGrade looks up a condition code in the configured scale
+func (s *ItemService) conditionGrade(ctx context.Context, condition string) (*domain.ConditionGrade, error) {
+	for _, grade := range s.GetConditionScale(ctx) {
+		if grade.Code == condition {
+			return &grade, nil
+		}
 	}
+	return nil, fmt.Errorf("invalid condition: %s", condition)
 }
 
 // CreateItemInput represents create item request data
@@ -44,7 +103,7 @@ type CreateItemInput struct {
 	Model            *string  `json:"model"`
 	SerialNumber     *string  `json:"serial_number"`
 	Color            *string  `json:"color"`
-	Condition        string   `json:"condition" validate:"required,oneof=new excellent good fair poor"`
+	Condition        string   `json:"condition" validate:"required"`
 	AppraisedValue   float64  `json:"appraised_value" validate:"required,gt=0"`
 	LoanValue        float64  `json:"loan_value" validate:"required,gt=0"`
 	SalePrice        *float64 `json:"sale_price"`
@@ -85,6 +144,11 @@ func (s *ItemService) Create(ctx context.Context, input CreateItemInput) (*domai
 		}
 	}
 
+	// Validate condition against the configured scale
+	if _, err := s.conditionGrade(ctx, input.Condition); err != nil {
+		return nil, err
+	}
+
 	// Validate loan value doesn't exceed appraised value
 	if input.LoanValue > input.AppraisedValue {
 		return nil, errors.New("loan value cannot exceed appraised value")
@@ -152,7 +216,7 @@ type UpdateItemInput struct {
 	Model          *string  `json:"model"`
 	SerialNumber   *string  `json:"serial_number"`
 	Color          *string  `json:"color"`
-	Condition      string   `json:"condition" validate:"omitempty,oneof=new excellent good fair poor"`
+	Condition      string   `json:"condition" validate:"omitempty"`
 	AppraisedValue *float64 `json:"appraised_value"`
 	LoanValue      *float64 `json:"loan_value"`
 	SalePrice      *float64 `json:"sale_price"`
@@ -171,6 +235,10 @@ func (s *ItemService) Update(ctx context.Context, id int64, input UpdateItemInpu
 		return nil, errors.New("item not found")
 	}
 
+	if err := s.checkStockTakeLock(ctx, item.BranchID); err != nil {
+		return nil, err
+	}
+
 	// Can only update items that are available or pawned
 	if item.Status != domain.ItemStatusAvailable && item.Status != domain.ItemStatusPawned {
 		return nil, errors.New("cannot update item in current status")
@@ -205,6 +273,9 @@ func (s *ItemService) Update(ctx context.Context, id int64, input UpdateItemInpu
 		item.Color = input.Color
 	}
 	if input.Condition != "" {
+		if _, err := s.conditionGrade(ctx, input.Condition); err != nil {
+			return nil, err
+		}
 		item.Condition = input.Condition
 	}
 	if input.AppraisedValue != nil {
@@ -285,6 +356,10 @@ func (s *ItemService) Delete(ctx context.Context, id int64, deletedBy int64) err
 		return errors.New("item not found")
 	}
 
+	if err := s.checkStockTakeLock(ctx, item.BranchID); err != nil {
+		return err
+	}
+
 	// Can only delete items that are available
 	if item.Status != domain.ItemStatusAvailable {
 		return errors.New("can only delete available items")
@@ -301,6 +376,34 @@ func (s *ItemService) Delete(ctx context.Context, id int64, deletedBy int64) err
 	return s.itemRepo.Delete(ctx, id)
 }
 
+// PermissionRestoreItems lets a user list deleted items and bring them back
+const PermissionRestoreItems = "items.restore"
+
+// Restore brings back a deleted item
+func (s *ItemService) Restore(ctx context.Context, id int64, restoredBy int64) (*domain.Item, error) {
+	item, err := s.itemRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("deleted item not found")
+	}
+
+	if err := s.checkStockTakeLock(ctx, item.BranchID); err != nil {
+		return nil, err
+	}
+
+	if err := s.itemRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	s.itemRepo.CreateHistory(ctx, &domain.ItemHistory{
+		ItemID:    item.ID,
+		Action:    "restored",
+		NewStatus: string(item.Status),
+		CreatedBy: restoredBy,
+	})
+
+	return s.itemRepo.GetByID(ctx, id)
+}
+
 // UpdateStatusInput represents update status request data
 type UpdateStatusInput struct {
 	Status    domain.ItemStatus `json:"status" validate:"required"`
@@ -315,6 +418,10 @@ func (s *ItemService) UpdateStatus(ctx context.Context, id int64, input UpdateSt
 		return errors.New("item not found")
 	}
 
+	if err := s.checkStockTakeLock(ctx, item.BranchID); err != nil {
+		return err
+	}
+
 	oldStatus := item.Status
 
 	// Validate status transition
@@ -339,6 +446,57 @@ func (s *ItemService) UpdateStatus(ctx context.Context, id int64, input UpdateSt
 	return nil
 }
 
+// maxBulkStatusItems caps how many items a single bulk status change may touch
+const maxBulkStatusItems = 200
+
+// ItemStatusChangeResult is the outcome of the status change of one item in a batch
+type ItemStatusChangeResult struct {
+	ItemID  int64  `json:"item_id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkStatusResult summarizes a bulk status change
+type BulkStatusResult struct {
+	Status    domain.ItemStatus        `json:"status"`
+	Succeeded int                      `json:"succeeded"`
+	Failed    int                      `json:"failed"`
+	Results   []ItemStatusChangeResult `json:"results"`
+}
+
+// BulkUpdateStatus moves several items to a new status. Each item is checked and
+// updated on its own, so an item that cannot make the transition is reported
+// in its result without stopping the rest of the batch.
+func (s *ItemService) BulkUpdateStatus(ctx context.Context, ids []int64, newStatus domain.ItemStatus, userID int64) (*BulkStatusResult, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", ErrInvalidInput)
+	}
+	if len(ids) > maxBulkStatusItems {
+		return nil, fmt.Errorf("%w: at most %d items can be updated at once", ErrInvalidInput, maxBulkStatusItems)
+	}
+
+	result := &BulkStatusResult{Status: newStatus, Results: []ItemStatusChangeResult{}}
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		outcome := ItemStatusChangeResult{ItemID: id, Success: true}
+		if err := s.UpdateStatus(ctx, id, UpdateStatusInput{Status: newStatus, UpdatedBy: userID}); err != nil {
+			outcome.Success = false
+			outcome.Error = err.Error()
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Results = append(result.Results, outcome)
+	}
+
+	return result, nil
+}
+
 // MarkForSale marks an item as available for sale
 func (s *ItemService) MarkForSale(ctx context.Context, id int64, salePrice float64, updatedBy int64) error {
 	item, err := s.itemRepo.GetByID(ctx, id)
@@ -346,6 +504,10 @@ func (s *ItemService) MarkForSale(ctx context.Context, id int64, salePrice float
 		return errors.New("item not found")
 	}
 
+	if err := s.checkStockTakeLock(ctx, item.BranchID); err != nil {
+		return err
+	}
+
 	// Can only mark confiscated or available items for sale
 	if item.Status != domain.ItemStatusConfiscated && item.Status != domain.ItemStatusAvailable {
 		return errors.New("can only mark confiscated or available items for sale")
@@ -400,6 +562,84 @@ func (s *ItemService) GetAvailableForSale(ctx context.Context, branchID int64) (
 	return items, nil
 }
 
+// ValuationInput represents a condition-adjusted valuation request
+type ValuationInput struct {
+	CategoryID  *int64  `json:"category_id"`
+	Condition   string  `json:"condition" validate:"required"`
+	MarketValue float64 `json:"market_value" validate:"required,gt=0"`
+	BranchID    *int64  `json:"branch_id"` // prices for the market of this branch
+}
+
+// ValuationSuggestion represents suggested values for an item in a given condition
+type ValuationSuggestion struct {
+	Condition       string  `json:"condition"`
+	ConditionFactor float64 `json:"condition_factor"`
+	MarketValue     float64 `json:"market_value"`
+	AppraisedValue  float64 `json:"appraised_value,omitempty"`
+	LoanValue       float64 `json:"loan_value,omitempty"`
+	SalePrice       float64 `json:"sale_price,omitempty"`
+	MarketFactor    float64 `json:"market_factor,omitempty"`
+}
+
+// SuggestAppraisal suggests appraised and loan values for an item, discounting the
+// market value by the condition factor and applying the category loan-to-value ratio
+func (s *ItemService) SuggestAppraisal(ctx context.Context, input ValuationInput) (*ValuationSuggestion, error) {
+	grade, err := s.conditionGrade(ctx, input.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := DefaultLoanToValueRatio
+	if input.CategoryID != nil {
+		category, err := s.categoryRepo.GetByID(ctx, *input.CategoryID)
+		if err != nil {
+			return nil, errors.New("invalid category")
+		}
+		if category.LoanToValueRatio > 0 {
+			ratio = category.LoanToValueRatio
+		}
+	}
+
+	appraised := roundCents(input.MarketValue * grade.Factor)
+	return &ValuationSuggestion{
+		Condition:       grade.Code,
+		ConditionFactor: grade.Factor,
+		MarketValue:     input.MarketValue,
+		AppraisedValue:  appraised,
+		LoanValue:       roundCents(appraised * ratio),
+	}, nil
+}
+
+// SettingSalePriceMarketFactor adjusts suggested sale prices to a branch's
+// market, e.g. 1.10 where items sell 10% above the reference market value
+const SettingSalePriceMarketFactor = "sale_price_market_factor"
+
+// SuggestSalePrice suggests a sale price for an item, discounting the market value by the
+// condition factor and adjusting it by the market factor of the branch, if any
+func (s *ItemService) SuggestSalePrice(ctx context.Context, input ValuationInput) (*ValuationSuggestion, error) {
+	grade, err := s.conditionGrade(ctx, input.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	marketFactor := getSettingFloat(ctx, s.settingRepo, SettingSalePriceMarketFactor, input.BranchID, 1.0)
+	if marketFactor <= 0 {
+		marketFactor = 1.0
+	}
+
+	return &ValuationSuggestion{
+		Condition:       grade.Code,
+		ConditionFactor: grade.Factor,
+		MarketValue:     input.MarketValue,
+		SalePrice:       roundCents(input.MarketValue * grade.Factor * marketFactor),
+		MarketFactor:    marketFactor,
+	}, nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
 // isValidStatusTransition validates if a status transition is allowed
 func isValidStatusTransition(from, to domain.ItemStatus) bool {
 	transitions := map[domain.ItemStatus][]domain.ItemStatus{
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
@@ -17,7 +19,7 @@ func setupItemService() (*ItemService, *mocks.MockItemRepository, *mocks.MockBra
 	branchRepo := new(mocks.MockBranchRepository)
 	categoryRepo := new(mocks.MockCategoryRepository)
 	customerRepo := new(mocks.MockCustomerRepository)
-	service := NewItemService(itemRepo, branchRepo, categoryRepo, customerRepo)
+	service := NewItemService(itemRepo, branchRepo, categoryRepo, customerRepo, nil)
 	return service, itemRepo, branchRepo, categoryRepo, customerRepo
 }
 
@@ -713,6 +715,51 @@ func TestItemService_Delete_Success(t *testing.T) {
 	itemRepo.AssertExpectations(t)
 }
 
+func TestItemService_Delete_HidesItemUntilRestored(t *testing.T) {
+	service, itemRepo, _, _, _ := setupItemService()
+	ctx := context.Background()
+
+	item := &domain.Item{ID: 1, SKU: "IT-1-000001", Name: "iPhone 15", Status: domain.ItemStatusAvailable}
+	deletedAt := time.Now()
+	deleted := *item
+	deleted.DeletedAt = &deletedAt
+
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil).Once()
+	itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).Return(nil)
+	itemRepo.On("Delete", ctx, int64(1)).Return(nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(nil, errors.New("not found")).Once()
+	itemRepo.On("GetDeletedByID", ctx, int64(1)).Return(&deleted, nil)
+	itemRepo.On("Restore", ctx, int64(1)).Return(nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil).Once()
+
+	require.NoError(t, service.Delete(ctx, 1, 5))
+
+	_, err := service.GetByID(ctx, 1)
+	assert.Error(t, err)
+
+	restored, err := service.Restore(ctx, 1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "IT-1-000001", restored.SKU)
+	assert.Nil(t, restored.DeletedAt)
+	itemRepo.AssertCalled(t, "CreateHistory", ctx, mock.MatchedBy(func(h *domain.ItemHistory) bool {
+		return h.Action == "restored" && h.CreatedBy == 5
+	}))
+	itemRepo.AssertExpectations(t)
+}
+
+func TestItemService_Restore_NotDeleted(t *testing.T) {
+	service, itemRepo, _, _, _ := setupItemService()
+	ctx := context.Background()
+
+	itemRepo.On("GetDeletedByID", ctx, int64(1)).Return(nil, errors.New("not found"))
+
+	result, err := service.Restore(ctx, 1, 5)
+
+	assert.EqualError(t, err, "deleted item not found")
+	assert.Nil(t, result)
+	itemRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
 func TestItemService_Delete_NotFound(t *testing.T) {
 	service, itemRepo, _, _, _ := setupItemService()
 	ctx := context.Background()
@@ -824,6 +871,46 @@ func TestItemService_UpdateStatus_RepoError(t *testing.T) {
 	assert.Equal(t, "failed to update item status", err.Error())
 }
 
+// --- BulkUpdateStatus tests ---
+
+func TestItemService_BulkUpdateStatus_MixedOutcomes(t *testing.T) {
+	service, itemRepo, _, _, _ := setupItemService()
+	ctx := context.Background()
+
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusConfiscated}, nil)
+	itemRepo.On("GetByID", ctx, int64(2)).Return(&domain.Item{ID: 2, Status: domain.ItemStatusSold}, nil)
+	itemRepo.On("GetByID", ctx, int64(3)).Return(&domain.Item{ID: 3, Status: domain.ItemStatusConfiscated}, nil)
+	itemRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusForSale).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(3), domain.ItemStatusForSale).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.MatchedBy(func(h *domain.ItemHistory) bool {
+		return h.Action == "status_changed" && h.OldStatus == string(domain.ItemStatusConfiscated) &&
+			h.NewStatus == string(domain.ItemStatusForSale) && h.CreatedBy == 4
+	})).Return(nil).Twice()
+
+	result, err := service.BulkUpdateStatus(ctx, []int64{1, 2, 999, 3, 1}, domain.ItemStatusForSale, 4)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, []ItemStatusChangeResult{
+		{ItemID: 1, Success: true},
+		{ItemID: 2, Success: false, Error: "invalid status transition"},
+		{ItemID: 999, Success: false, Error: "item not found"},
+		{ItemID: 3, Success: true},
+	}, result.Results)
+	itemRepo.AssertNotCalled(t, "UpdateStatus", ctx, int64(2), mock.Anything)
+	itemRepo.AssertExpectations(t)
+}
+
+func TestItemService_BulkUpdateStatus_EmptyBatch(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+
+	_, err := service.BulkUpdateStatus(context.Background(), nil, domain.ItemStatusForSale, 4)
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
 // --- MarkForSale tests ---
 
 func TestItemService_MarkForSale_Success_Confiscated(t *testing.T) {
@@ -1015,6 +1102,143 @@ func TestIsValidStatusTransition_UnknownStatus(t *testing.T) {
 	assert.False(t, isValidStatusTransition(domain.ItemStatus("unknown"), domain.ItemStatusAvailable))
 }
 
+// --- Condition scale tests ---
+
+func TestItemService_Update_LockedDuringStockTake(t *testing.T) {
+	service, itemRepo, _, _, _ := setupItemService()
+	stockTakeRepo := new(mocks.MockStockTakeRepository)
+	service.SetStockTakes(stockTakeRepo)
+	ctx := context.Background()
+
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, BranchID: 2, Status: domain.ItemStatusAvailable}, nil)
+	stockTakeRepo.On("GetActiveByBranch", ctx, int64(2)).Return(&domain.StockTake{ID: 9, BranchID: 2, Status: domain.StockTakeStatusInProgress}, nil)
+
+	result, err := service.Update(ctx, 1, UpdateItemInput{Name: "iPhone 15 Pro"})
+
+	assert.ErrorIs(t, err, ErrStockTakeInProgress)
+	assert.Nil(t, result)
+	itemRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func setupItemServiceWithScale(scale interface{}) (*ItemService, *mocks.MockCategoryRepository) {
+	categoryRepo := new(mocks.MockCategoryRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingItemConditionScale, (*int64)(nil)).
+		Return(&domain.Setting{Key: SettingItemConditionScale, Value: scale}, nil)
+	service := NewItemService(new(mocks.MockItemRepository), new(mocks.MockBranchRepository), categoryRepo, new(mocks.MockCustomerRepository), settingRepo)
+	return service, categoryRepo
+}
+
+func TestItemService_GetConditionScale_Default(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+
+	scale := service.GetConditionScale(context.Background())
+
+	assert.Equal(t, domain.DefaultConditionScale(), scale)
+}
+
+func TestItemService_GetConditionScale_Configured(t *testing.T) {
+	// JSONB settings come back from the repository as generic maps
+	service, _ := setupItemServiceWithScale([]interface{}{
+		map[string]interface{}{"code": "mint", "label": "Impecable", "factor": 1.0},
+		map[string]interface{}{"code": "used", "label": "Usado", "factor": 0.5},
+	})
+
+	scale := service.GetConditionScale(context.Background())
+
+	assert.Equal(t, []domain.ConditionGrade{
+		{Code: "mint", Label: "Impecable", Factor: 1.0},
+		{Code: "used", Label: "Usado", Factor: 0.5},
+	}, scale)
+}
+
+func TestItemService_GetConditionScale_MalformedFallsBackToDefault(t *testing.T) {
+	service, _ := setupItemServiceWithScale("not a scale")
+
+	assert.Equal(t, domain.DefaultConditionScale(), service.GetConditionScale(context.Background()))
+}
+
+func TestItemService_SuggestAppraisal_PoorVersusExcellent(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+	ctx := context.Background()
+
+	excellent, err := service.SuggestAppraisal(ctx, ValuationInput{Condition: "excellent", MarketValue: 1000})
+	assert.NoError(t, err)
+	poor, err := service.SuggestAppraisal(ctx, ValuationInput{Condition: "poor", MarketValue: 1000})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 900.0, excellent.AppraisedValue)
+	assert.Equal(t, 400.0, poor.AppraisedValue)
+	assert.InDelta(t, 0.4/0.9, poor.AppraisedValue/excellent.AppraisedValue, 0.001)
+	assert.Equal(t, 630.0, excellent.LoanValue)
+	assert.Equal(t, 280.0, poor.LoanValue)
+}
+
+func TestItemService_SuggestAppraisal_UsesConfiguredFactorAndCategoryRatio(t *testing.T) {
+	service, categoryRepo := setupItemServiceWithScale([]interface{}{
+		map[string]interface{}{"code": "excellent", "label": "Excelente", "factor": 0.8},
+		map[string]interface{}{"code": "poor", "label": "Malo", "factor": 0.2},
+	})
+	ctx := context.Background()
+	categoryID := int64(3)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, LoanToValueRatio: 0.5}, nil)
+
+	excellent, err := service.SuggestAppraisal(ctx, ValuationInput{CategoryID: &categoryID, Condition: "excellent", MarketValue: 500})
+	assert.NoError(t, err)
+	poor, err := service.SuggestAppraisal(ctx, ValuationInput{CategoryID: &categoryID, Condition: "poor", MarketValue: 500})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 400.0, excellent.AppraisedValue)
+	assert.Equal(t, 100.0, poor.AppraisedValue)
+	assert.Equal(t, 0.25, poor.AppraisedValue/excellent.AppraisedValue)
+	assert.Equal(t, 50.0, poor.LoanValue)
+}
+
+func TestItemService_SuggestSalePrice_PoorVersusExcellent(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+	ctx := context.Background()
+
+	excellent, err := service.SuggestSalePrice(ctx, ValuationInput{Condition: "excellent", MarketValue: 250})
+	assert.NoError(t, err)
+	poor, err := service.SuggestSalePrice(ctx, ValuationInput{Condition: "poor", MarketValue: 250})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 225.0, excellent.SalePrice)
+	assert.Equal(t, 100.0, poor.SalePrice)
+	assert.Equal(t, 0.4, poor.ConditionFactor)
+}
+
+func TestItemService_SuggestAppraisal_InvalidCondition(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+
+	_, err := service.SuggestAppraisal(context.Background(), ValuationInput{Condition: "broken", MarketValue: 100})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid condition")
+}
+
+func TestItemService_Create_InvalidCondition(t *testing.T) {
+	service, _, branchRepo, _, _ := setupItemService()
+	ctx := context.Background()
+
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1, IsActive: true}, nil)
+
+	input := CreateItemInput{
+		BranchID:        1,
+		Name:            "iPhone 15",
+		Condition:       "broken",
+		AppraisedValue:  1000,
+		LoanValue:       500,
+		AcquisitionType: "pawn",
+	}
+
+	item, err := service.Create(ctx, input)
+
+	assert.Nil(t, item)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid condition")
+}
+
 // --- Helper function tests ---
 
 func TestStringVal_Nil(t *testing.T) {
@@ -1030,3 +1254,63 @@ func TestFormatCurrency(t *testing.T) {
 	result := formatCurrency(100.50)
 	assert.Contains(t, result, "Q")
 }
+
+// --- Attention queue tests ---
+
+func TestItemService_GetAttentionQueue(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+	attentionRepo := new(mocks.MockItemAttentionRepository)
+	service.SetAttentionQueue(attentionRepo)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	transferNumber := "TRF-000012"
+	shippedAt := daysAgo(5)
+
+	attentionRepo.On("ListCandidates", ctx, int64(1), DefaultItemAttentionPhotoValue).Return([]*domain.ItemAttentionCandidate{
+		{ItemID: 1, SKU: "AGED", Status: domain.ItemStatusForSale, PhotoCount: 2, StatusSince: daysAgo(120)},
+		{ItemID: 2, SKU: "FRESH", Status: domain.ItemStatusForSale, PhotoCount: 2, StatusSince: daysAgo(10)},
+		{ItemID: 3, SKU: "CONF", Status: domain.ItemStatusConfiscated, PhotoCount: 1, StatusSince: daysAgo(4)},
+		{ItemID: 4, SKU: "TRF", Status: domain.ItemStatusInTransfer, PhotoCount: 1, StatusSince: daysAgo(6), TransferNumber: &transferNumber, ShippedAt: &shippedAt},
+		{ItemID: 5, SKU: "NOPHOTO", Status: domain.ItemStatusPawned, AppraisedValue: 8000, StatusSince: daysAgo(30)},
+	}, nil)
+
+	queue, err := service.GetAttentionQueue(ctx, 1, now)
+
+	require.NoError(t, err)
+	require.Len(t, queue, 4)
+	// Most urgent first; the item for sale for a few days is fine
+	assert.Equal(t, "TRF", queue[0].SKU)
+	assert.Equal(t, domain.ItemAttentionTransferUnreceived, queue[0].Reason)
+	assert.Equal(t, "Traslado TRF-000012 sin recibir desde hace 5 día(s)", queue[0].Detail)
+	assert.Equal(t, "CONF", queue[1].SKU)
+	assert.Equal(t, domain.ItemAttentionConfiscatedUnlisted, queue[1].Reason)
+	assert.Equal(t, 4, queue[1].DaysWaiting)
+	assert.Equal(t, "NOPHOTO", queue[2].SKU)
+	assert.Equal(t, domain.ItemAttentionMissingPhotos, queue[2].Reason)
+	assert.Equal(t, "AGED", queue[3].SKU)
+	assert.Equal(t, domain.ItemAttentionAgedForSale, queue[3].Reason)
+	assert.Equal(t, 120, queue[3].DaysWaiting)
+	attentionRepo.AssertExpectations(t)
+}
+
+func TestItemService_GetAttentionQueue_MultipleReasons(t *testing.T) {
+	service, _, _, _, _ := setupItemService()
+	attentionRepo := new(mocks.MockItemAttentionRepository)
+	service.SetAttentionQueue(attentionRepo)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	attentionRepo.On("ListCandidates", ctx, int64(1), DefaultItemAttentionPhotoValue).Return([]*domain.ItemAttentionCandidate{
+		{ItemID: 1, SKU: "AGED", Status: domain.ItemStatusForSale, AppraisedValue: 6000, StatusSince: now.AddDate(0, 0, -100)},
+		// A transfer shipped yesterday is still on its way
+		{ItemID: 2, SKU: "TRF", Status: domain.ItemStatusInTransfer, PhotoCount: 1, StatusSince: now.AddDate(0, 0, -1)},
+	}, nil)
+
+	queue, err := service.GetAttentionQueue(ctx, 1, now)
+
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, domain.ItemAttentionMissingPhotos, queue[0].Reason)
+	assert.Equal(t, []string{domain.ItemAttentionAgedForSale}, queue[0].OtherReasons)
+}
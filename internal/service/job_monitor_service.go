@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/pkg/metrics"
+)
+
+// JobMonitorService records scheduler job runs and flags critical jobs that
+// stopped succeeding, alerting administrators once per outage
+type JobMonitorService struct {
+	runRepo             repository.JobRunRepository
+	roleRepo            repository.RoleRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	logger              zerolog.Logger
+
+	mu      sync.Mutex
+	alerted map[string]bool
+}
+
+// NewJobMonitorService creates a new JobMonitorService
+func NewJobMonitorService(
+	runRepo repository.JobRunRepository,
+	roleRepo repository.RoleRepository,
+	userRepo repository.UserRepository,
+	notificationService NotificationService,
+	logger zerolog.Logger,
+) *JobMonitorService {
+	return &JobMonitorService{
+		runRepo:             runRepo,
+		roleRepo:            roleRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		logger:              logger.With().Str("service", "job_monitor").Logger(),
+		alerted:             make(map[string]bool),
+	}
+}
+
+// RecordRun persists the outcome of a job run and updates the job metrics
+func (s *JobMonitorService) RecordRun(ctx context.Context, run *domain.JobRun) error {
+	metrics.RecordJobExecution(run.JobName, run.Status, run.FinishedAt.Sub(run.StartedAt))
+	if run.Status == domain.JobRunStatusSuccess {
+		metrics.SetJobLastSuccessAge(run.JobName, 0)
+	}
+
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		return fmt.Errorf("failed to record job run: %w", err)
+	}
+	return nil
+}
+
+// Status retrieves the latest run of every job, flagging stale critical jobs
+func (s *JobMonitorService) Status(ctx context.Context) ([]*domain.JobStatus, error) {
+	statuses, err := s.runRepo.ListStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, status := range statuses {
+		status.Stale = status.IsStaleAt(now)
+	}
+	return statuses, nil
+}
+
+// CheckStaleJobs flags critical jobs that have not succeeded within their
+// expected interval and alerts administrators the first time each goes stale
+func (s *JobMonitorService) CheckStaleJobs(ctx context.Context) ([]*domain.JobStatus, error) {
+	statuses, err := s.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var stale []*domain.JobStatus
+	for _, status := range statuses {
+		metrics.SetJobLastSuccessAge(status.JobName, status.LastSuccessAge(now))
+
+		s.mu.Lock()
+		alreadyAlerted := s.alerted[status.JobName]
+		s.alerted[status.JobName] = status.Stale
+		s.mu.Unlock()
+
+		if !status.Stale {
+			continue
+		}
+		stale = append(stale, status)
+
+		s.logger.Warn().
+			Str("job", status.JobName).
+			Dur("last_success_age", status.LastSuccessAge(now)).
+			Msg("Critical job has not succeeded within its expected interval")
+
+		if !alreadyAlerted {
+			s.alertAdmins(ctx, status, now)
+		}
+	}
+
+	return stale, nil
+}
+
+// alertAdmins sends an internal notification about a stale job to every active administrator
+func (s *JobMonitorService) alertAdmins(ctx context.Context, status *domain.JobStatus, now time.Time) {
+	if s.notificationService == nil || s.roleRepo == nil || s.userRepo == nil {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"La tarea programada '%s' no se ha completado correctamente desde hace %s (se espera cada %s). Revise el worker.",
+		status.JobName,
+		status.LastSuccessAge(now).Round(time.Minute),
+		time.Duration(status.IntervalSeconds)*time.Second,
+	)
+	if status.LastRun != nil && status.LastRun.ErrorMessage != nil {
+		message += " Último error: " + *status.LastRun.ErrorMessage
+	}
+
+	active := true
+	for _, roleName := range []string{domain.RoleSuperAdmin, domain.RoleAdmin} {
+		role, err := s.roleRepo.GetByName(ctx, roleName)
+		if err != nil || role == nil {
+			continue
+		}
+
+		users, err := s.userRepo.List(ctx, repository.UserListParams{RoleID: &role.ID, IsActive: &active})
+		if err != nil {
+			s.logger.Error().Err(err).Str("role", roleName).Msg("Failed to list administrators for job alert")
+			continue
+		}
+
+		for _, user := range users.Data {
+			_, err := s.notificationService.CreateInternalNotification(ctx, CreateInternalNotificationRequest{
+				UserID:        user.ID,
+				Title:         "Tarea Programada Detenida",
+				Message:       message,
+				Type:          "error",
+				ReferenceType: "job",
+			})
+			if err != nil {
+				s.logger.Error().Err(err).Int64("user_id", user.ID).Msg("Failed to alert administrator about stale job")
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupJobMonitorService() (*JobMonitorService, *mocks.MockJobRunRepository, *mocks.MockRoleRepository, *mocks.MockUserRepository, *mocks.MockInternalNotificationRepository) {
+	runRepo := new(mocks.MockJobRunRepository)
+	roleRepo := new(mocks.MockRoleRepository)
+	userRepo := new(mocks.MockUserRepository)
+	internalRepo := new(mocks.MockInternalNotificationRepository)
+	notificationService := NewNotificationService(nil, nil, nil, internalRepo, nil, userRepo)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewJobMonitorService(runRepo, roleRepo, userRepo, notificationService, logger)
+	return service, runRepo, roleRepo, userRepo, internalRepo
+}
+
+func jobStatus(name string, critical bool, lastSuccessAgo time.Duration) *domain.JobStatus {
+	now := time.Now()
+	lastSuccess := now.Add(-lastSuccessAgo)
+	return &domain.JobStatus{
+		JobName:         name,
+		Critical:        critical,
+		IntervalSeconds: 60,
+		FirstRunAt:      now.Add(-24 * time.Hour),
+		LastRun:         &domain.JobRun{JobName: name, Status: domain.JobRunStatusFailed, StartedAt: now.Add(-time.Minute)},
+		LastSuccessAt:   &lastSuccess,
+	}
+}
+
+func TestJobMonitorService_RecordRun_PersistsOutcome(t *testing.T) {
+	service, runRepo, _, _, _ := setupJobMonitorService()
+	ctx := context.Background()
+
+	started := time.Now().Add(-2 * time.Second)
+	run := &domain.JobRun{JobName: "calculate_late_fees", Status: domain.JobRunStatusSuccess, ItemsProcessed: 3, StartedAt: started, FinishedAt: time.Now()}
+	runRepo.On("Create", ctx, run).Return(nil)
+
+	err := service.RecordRun(ctx, run)
+
+	require.NoError(t, err)
+	runRepo.AssertExpectations(t)
+}
+
+func TestJobMonitorService_Status_FlagsStaleCriticalJob(t *testing.T) {
+	service, runRepo, _, _, _ := setupJobMonitorService()
+	ctx := context.Background()
+
+	runRepo.On("ListStatuses", ctx).Return([]*domain.JobStatus{
+		jobStatus("process_overdue_loans", true, 10*time.Minute),
+		jobStatus("dispatch_notifications", true, 30*time.Second),
+		jobStatus("generate_daily_report", false, 10*time.Minute),
+	}, nil)
+
+	statuses, err := service.Status(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Stale)
+	assert.False(t, statuses[1].Stale, "succeeded within its interval")
+	assert.False(t, statuses[2].Stale, "non-critical jobs are never flagged")
+}
+
+func TestJobMonitorService_CheckStaleJobs_AlertsAdminsOnce(t *testing.T) {
+	service, runRepo, roleRepo, userRepo, internalRepo := setupJobMonitorService()
+	ctx := context.Background()
+
+	runRepo.On("ListStatuses", ctx).Return([]*domain.JobStatus{jobStatus("process_overdue_loans", true, 10*time.Minute)}, nil)
+	roleRepo.On("GetByName", ctx, domain.RoleSuperAdmin).Return(&domain.Role{ID: 1, Name: domain.RoleSuperAdmin}, nil)
+	roleRepo.On("GetByName", ctx, domain.RoleAdmin).Return(nil, errors.New("role not found"))
+	userRepo.On("List", ctx, mock.MatchedBy(func(params repository.UserListParams) bool {
+		return params.RoleID != nil && *params.RoleID == 1
+	})).Return(&repository.PaginatedResult[domain.User]{Data: []domain.User{{ID: 3}}}, nil)
+	internalRepo.On("Create", ctx, mock.MatchedBy(func(n *domain.InternalNotification) bool {
+		return n.UserID == 3 && n.Type == "error"
+	})).Return(nil).Once()
+
+	stale, err := service.CheckStaleJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, "process_overdue_loans", stale[0].JobName)
+
+	// Still stale on the next check: no repeated alert
+	stale, err = service.CheckStaleJobs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, stale, 1)
+	internalRepo.AssertNumberOfCalls(t, "Create", 1)
+}
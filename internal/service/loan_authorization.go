@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// SettingLoanTwoPersonThreshold is the cash loan amount above which a second
+// user must co-sign the disbursement; 0 disables the two-person rule
+const SettingLoanTwoPersonThreshold = "loan_two_person_threshold"
+
+// PermissionAuthorizeLoans lets a user co-sign the disbursement of a high-value loan
+const PermissionAuthorizeLoans = "loans.authorize"
+
+// Two-person authorization errors
+var (
+	ErrLoanNotAwaitingAuthorization = errors.New("loan is not awaiting authorization")
+	ErrSelfAuthorization            = errors.New("a loan cannot be authorized by the user who created it")
+)
+
+// requiresSecondAuthorization checks if a cash loan of the given amount needs a
+// second user to co-sign its disbursement
+func (s *LoanService) requiresSecondAuthorization(ctx context.Context, branchID int64, amount float64) bool {
+	threshold := getSettingFloat(ctx, s.settingRepo, SettingLoanTwoPersonThreshold, &branchID, 0)
+	return threshold > 0 && amount > threshold
+}
+
+// AuthorizeDisbursement co-signs a high-value loan and records its cash
+// disbursement from the open session of the user who created it. The
+// authorizer must be someone other than the creator.
+func (s *LoanService) AuthorizeDisbursement(ctx context.Context, loanID int64, authorizedBy int64) (*domain.Loan, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil || loan == nil {
+		return nil, ErrLoanNotFound
+	}
+	if !loan.AwaitingAuthorization() {
+		return nil, ErrLoanNotAwaitingAuthorization
+	}
+	if authorizedBy == loan.CreatedBy {
+		s.logger.Warn().
+			Int64("loan_id", loan.ID).
+			Int64("user_id", authorizedBy).
+			Msg("Loan authorization rejected: creator cannot co-sign")
+		return nil, ErrSelfAuthorization
+	}
+	if s.cashService == nil {
+		return nil, errors.New("cash sessions are not configured")
+	}
+
+	// The cash comes out of the drawer of the clerk handling the customer
+	cashSession, err := s.cashService.RequireOpenSession(ctx, loan.CreatedBy, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cashService.EnsureCashAvailable(ctx, cashSession, loan.LoanAmount); err != nil {
+		return nil, err
+	}
+
+	// Authorizing first lets a single co-signer through when two race; the
+	// loan goes back to waiting when the cash cannot be paid out
+	if err := s.loanRepo.AuthorizeDisbursement(ctx, loan.ID, authorizedBy); err != nil {
+		return nil, err
+	}
+	if err := s.cashService.RecordLoanDisbursement(ctx, cashSession.ID, loan.ID, loan.LoanAmount, loan.CreatedBy); err != nil {
+		s.logger.Error().Err(err).
+			Int64("loan_id", loan.ID).
+			Int64("session_id", cashSession.ID).
+			Msg("Failed to record loan disbursement cash movement")
+		if revokeErr := s.loanRepo.RevokeAuthorization(ctx, loan.ID); revokeErr != nil {
+			s.logger.Error().Err(revokeErr).Int64("loan_id", loan.ID).Msg("Failed to revoke loan authorization")
+		}
+		return nil, fmt.Errorf("failed to record loan disbursement: %w", err)
+	}
+	now := time.Now()
+	loan.Status = domain.LoanStatusActive
+	loan.AuthorizedBy = &authorizedBy
+	loan.AuthorizedAt = &now
+
+	s.logger.Info().
+		Int64("loan_id", loan.ID).
+		Str("loan_number", loan.LoanNumber).
+		Int64("created_by", loan.CreatedBy).
+		Int64("authorized_by", authorizedBy).
+		Float64("loan_amount", loan.LoanAmount).
+		Msg("Loan disbursement authorized")
+
+	return loan, nil
+}
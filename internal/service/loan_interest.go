@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+
+	"pawnshop/internal/domain"
+)
+
+// SettingLoanInterestMethod is the interest method of a branch's new loans
+// when the loan does not name one: flat, simple or compound
+const SettingLoanInterestMethod = "loan_interest_method"
+
+// interestMethodFor picks the interest method of a new loan: the one asked
+// for, else the branch's. An unknown method falls back to the default so a
+// misconfigured setting never blocks lending.
+func (s *LoanService) interestMethodFor(ctx context.Context, input CreateLoanInput) domain.InterestMethod {
+	method := domain.InterestMethod(input.InterestMethod)
+	if method == "" {
+		method = domain.InterestMethod(getSettingString(ctx, s.settingRepo, SettingLoanInterestMethod, &input.BranchID, string(domain.DefaultInterestMethod)))
+	}
+	if !method.IsValid() {
+		s.logger.Warn().
+			Str("interest_method", string(method)).
+			Int64("branch_id", input.BranchID).
+			Msg("Unknown interest method, using the default")
+		return domain.DefaultInterestMethod
+	}
+	return method
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"pawnshop/internal/domain"
+)
+
+// PayoffQuote is what a customer would pay to settle a loan on a given day.
+// Interest is charged only as earned by that day; late fees are the loan's
+// projected late fee on that day, the same figure loan responses show.
+type PayoffQuote struct {
+	LoanID             int64       `json:"loan_id"`
+	LoanNumber         string      `json:"loan_number"`
+	AsOf               domain.Date `json:"as_of"`
+	PrincipalRemaining float64     `json:"principal_remaining"`
+	AccruedInterest    float64     `json:"accrued_interest"`
+	UnearnedInterest   float64     `json:"unearned_interest"` // Interest of the term not yet earned, not charged
+	LateFees           float64     `json:"late_fees"`
+	Total              float64     `json:"total"`
+	DaysPastDue        int         `json:"days_past_due"`
+	InGracePeriod      bool        `json:"in_grace_period"`
+	PastGracePeriod    bool        `json:"past_grace_period"` // The item may already be confiscated
+}
+
+// GetPayoffQuote computes how much it would take to pay off an open loan on
+// the day of asOf
+func (s *LoanService) GetPayoffQuote(ctx context.Context, loanID int64, asOf time.Time) (*PayoffQuote, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil || loan == nil {
+		return nil, ErrLoanNotFound
+	}
+	if !loan.IsOpen() {
+		return nil, fmt.Errorf("%w: loan is %s", ErrInvalidStatus, loan.Status)
+	}
+
+	day := domain.DateFromTime(asOf)
+	if day.Before(loan.StartDate.Time) {
+		return nil, fmt.Errorf("%w: quote date is before the loan started", ErrInvalidInput)
+	}
+
+	return buildPayoffQuote(loan, day), nil
+}
+
+// buildPayoffQuote computes the payoff of a loan on a day
+func buildPayoffQuote(loan *domain.Loan, day domain.Date) *PayoffQuote {
+	quote := &PayoffQuote{
+		LoanID:             loan.ID,
+		LoanNumber:         loan.LoanNumber,
+		AsOf:               day,
+		PrincipalRemaining: roundCents(loan.PrincipalRemaining),
+		AccruedInterest:    loan.AccruedInterestAt(day),
+		DaysPastDue:        loan.DaysPastDueAt(day.Time),
+		InGracePeriod:      loan.IsInGracePeriodAt(day.Time),
+		PastGracePeriod:    day.After(loan.GracePeriodEnd().Time),
+	}
+	quote.UnearnedInterest = roundCents(math.Max(loan.InterestRemaining-quote.AccruedInterest, 0))
+	quote.LateFees = roundCents(loan.ProjectedLateFeeAt(day.Time))
+
+	quote.Total = roundCents(quote.PrincipalRemaining + quote.AccruedInterest + quote.LateFees)
+	return quote
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// LoanProductService handles loan product business logic
+type LoanProductService struct {
+	productRepo repository.LoanProductRepository
+}
+
+// NewLoanProductService creates a new LoanProductService
+func NewLoanProductService(productRepo repository.LoanProductRepository) *LoanProductService {
+	return &LoanProductService{productRepo: productRepo}
+}
+
+// CreateLoanProductInput represents create loan product request data
+type CreateLoanProductInput struct {
+	Name                   string  `json:"name" validate:"required,min=2"`
+	Code                   string  `json:"code" validate:"required,min=2,max=30"`
+	Description            *string `json:"description"`
+	InterestRate           float64 `json:"interest_rate" validate:"gte=0,lte=100"`
+	LoanTermDays           int     `json:"loan_term_days" validate:"required,gt=0"`
+	GracePeriodDays        int     `json:"grace_period_days" validate:"gte=0,lte=30"`
+	LateFeeRate            float64 `json:"late_fee_rate" validate:"gte=0"`
+	PaymentPlanType        string  `json:"payment_plan_type" validate:"required,oneof=single minimum_payment installments"`
+	NumberOfInstallments   int     `json:"number_of_installments" validate:"gte=0"`
+	RequiresMinimumPayment bool    `json:"requires_minimum_payment"`
+}
+
+// Create creates a new loan product
+func (s *LoanProductService) Create(ctx context.Context, input CreateLoanProductInput) (*domain.LoanProduct, error) {
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	if existing, _ := s.productRepo.GetByCode(ctx, code); existing != nil {
+		return nil, errors.New("loan product with this code already exists")
+	}
+
+	product := &domain.LoanProduct{
+		Name:                   input.Name,
+		Code:                   code,
+		Description:            input.Description,
+		InterestRate:           input.InterestRate,
+		LoanTermDays:           input.LoanTermDays,
+		GracePeriodDays:        input.GracePeriodDays,
+		LateFeeRate:            input.LateFeeRate,
+		PaymentPlanType:        domain.PaymentPlanType(input.PaymentPlanType),
+		NumberOfInstallments:   input.NumberOfInstallments,
+		RequiresMinimumPayment: input.RequiresMinimumPayment,
+		IsActive:               true,
+	}
+	if err := validateLoanProduct(product); err != nil {
+		return nil, err
+	}
+
+	if err := s.productRepo.Create(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to create loan product: %w", err)
+	}
+
+	return product, nil
+}
+
+// UpdateLoanProductInput represents update loan product request data
+type UpdateLoanProductInput struct {
+	Name                   string   `json:"name" validate:"omitempty,min=2"`
+	Description            *string  `json:"description"`
+	InterestRate           *float64 `json:"interest_rate" validate:"omitempty,gte=0,lte=100"`
+	LoanTermDays           *int     `json:"loan_term_days" validate:"omitempty,gt=0"`
+	GracePeriodDays        *int     `json:"grace_period_days" validate:"omitempty,gte=0,lte=30"`
+	LateFeeRate            *float64 `json:"late_fee_rate" validate:"omitempty,gte=0"`
+	PaymentPlanType        string   `json:"payment_plan_type" validate:"omitempty,oneof=single minimum_payment installments"`
+	NumberOfInstallments   *int     `json:"number_of_installments" validate:"omitempty,gte=0"`
+	RequiresMinimumPayment *bool    `json:"requires_minimum_payment"`
+	IsActive               *bool    `json:"is_active"`
+}
+
+// Update updates an existing loan product
+func (s *LoanProductService) Update(ctx context.Context, id int64, input UpdateLoanProductInput) (*domain.LoanProduct, error) {
+	product, err := s.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("loan product not found")
+	}
+
+	if input.Name != "" {
+		product.Name = input.Name
+	}
+	if input.Description != nil {
+		product.Description = input.Description
+	}
+	if input.InterestRate != nil {
+		product.InterestRate = *input.InterestRate
+	}
+	if input.LoanTermDays != nil {
+		product.LoanTermDays = *input.LoanTermDays
+	}
+	if input.GracePeriodDays != nil {
+		product.GracePeriodDays = *input.GracePeriodDays
+	}
+	if input.LateFeeRate != nil {
+		product.LateFeeRate = *input.LateFeeRate
+	}
+	if input.PaymentPlanType != "" {
+		product.PaymentPlanType = domain.PaymentPlanType(input.PaymentPlanType)
+	}
+	if input.NumberOfInstallments != nil {
+		product.NumberOfInstallments = *input.NumberOfInstallments
+	}
+	if input.RequiresMinimumPayment != nil {
+		product.RequiresMinimumPayment = *input.RequiresMinimumPayment
+	}
+	if input.IsActive != nil {
+		product.IsActive = *input.IsActive
+	}
+	if err := validateLoanProduct(product); err != nil {
+		return nil, err
+	}
+
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to update loan product: %w", err)
+	}
+
+	return product, nil
+}
+
+// GetByID retrieves a loan product by ID
+func (s *LoanProductService) GetByID(ctx context.Context, id int64) (*domain.LoanProduct, error) {
+	product, err := s.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("loan product not found")
+	}
+	return product, nil
+}
+
+// List retrieves loan products, only the active ones unless includeInactive is set
+func (s *LoanProductService) List(ctx context.Context, includeInactive bool) ([]*domain.LoanProduct, error) {
+	return s.productRepo.List(ctx, !includeInactive)
+}
+
+// Delete deletes a loan product
+func (s *LoanProductService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.productRepo.GetByID(ctx, id); err != nil {
+		return errors.New("loan product not found")
+	}
+	return s.productRepo.Delete(ctx, id)
+}
+
+// validateLoanProduct checks the preset terms are consistent with each other
+func validateLoanProduct(product *domain.LoanProduct) error {
+	if product.PaymentPlanType == domain.PaymentPlanInstallments && product.NumberOfInstallments <= 0 {
+		return errors.New("installment products require a number of installments")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupLoanProductService() (*LoanProductService, *mocks.MockLoanProductRepository) {
+	productRepo := new(mocks.MockLoanProductRepository)
+	service := NewLoanProductService(productRepo)
+	return service, productRepo
+}
+
+func TestLoanProductService_Create_Success(t *testing.T) {
+	service, productRepo := setupLoanProductService()
+	ctx := context.Background()
+
+	productRepo.On("GetByCode", ctx, "ELEC14").Return(nil, errors.New("loan product not found"))
+	productRepo.On("Create", ctx, mock.AnythingOfType("*domain.LoanProduct")).Return(nil)
+
+	product, err := service.Create(ctx, CreateLoanProductInput{
+		Name:            "Electrónicos 14 días",
+		Code:            " elec14 ",
+		InterestRate:    15,
+		LoanTermDays:    14,
+		PaymentPlanType: "single",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ELEC14", product.Code)
+	assert.Equal(t, domain.PaymentPlanSingle, product.PaymentPlanType)
+	assert.True(t, product.IsActive)
+	productRepo.AssertExpectations(t)
+}
+
+func TestLoanProductService_Create_DuplicateCode(t *testing.T) {
+	service, productRepo := setupLoanProductService()
+	ctx := context.Background()
+
+	productRepo.On("GetByCode", ctx, "GOLD30").Return(&domain.LoanProduct{ID: 1, Code: "GOLD30"}, nil)
+
+	product, err := service.Create(ctx, CreateLoanProductInput{Name: "Oro", Code: "GOLD30", LoanTermDays: 30, PaymentPlanType: "single"})
+
+	assert.Nil(t, product)
+	assert.EqualError(t, err, "loan product with this code already exists")
+	productRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLoanProductService_Update_InstallmentsRequireCount(t *testing.T) {
+	service, productRepo := setupLoanProductService()
+	ctx := context.Background()
+
+	productRepo.On("GetByID", ctx, int64(1)).Return(&domain.LoanProduct{ID: 1, LoanTermDays: 30, PaymentPlanType: domain.PaymentPlanSingle}, nil)
+
+	product, err := service.Update(ctx, 1, UpdateLoanProductInput{PaymentPlanType: "installments"})
+
+	assert.Nil(t, product)
+	assert.EqualError(t, err, "installment products require a number of installments")
+	productRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"pawnshop/internal/domain"
+)
+
+// InstallmentPlanItem is one installment of a loan's payment schedule
+type InstallmentPlanItem struct {
+	Number          int         `json:"number"`
+	DueDate         domain.Date `json:"due_date"`
+	PrincipalAmount float64     `json:"principal_amount"`
+	InterestAmount  float64     `json:"interest_amount"`
+	TotalAmount     float64     `json:"total_amount"`
+}
+
+// GetInstallmentSchedule returns a loan's stored installment schedule
+func (s *LoanService) GetInstallmentSchedule(ctx context.Context, loanID int64) ([]InstallmentPlanItem, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil || loan == nil {
+		return nil, ErrLoanNotFound
+	}
+
+	installments, err := s.loanRepo.GetInstallments(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installments: %w", err)
+	}
+
+	schedule := make([]InstallmentPlanItem, len(installments))
+	for i, installment := range installments {
+		schedule[i] = InstallmentPlanItem{
+			Number:          installment.InstallmentNumber,
+			DueDate:         domain.DateFromTime(installment.DueDate),
+			PrincipalAmount: installment.PrincipalAmount,
+			InterestAmount:  installment.InterestAmount,
+			TotalAmount:     installment.TotalAmount,
+		}
+	}
+	return schedule, nil
+}
+
+// GenerateInstallmentSchedule splits what an active loan still owes, principal
+// plus interest, into equal installments due at equal intervals from today, or
+// its start date if later, to its due date, and stores it as the loan's
+// installment schedule
+func (s *LoanService) GenerateInstallmentSchedule(ctx context.Context, loanID int64, numInstallments int) ([]InstallmentPlanItem, error) {
+	if numInstallments <= 0 {
+		return nil, fmt.Errorf("%w: number of installments must be positive", ErrInvalidInput)
+	}
+
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil || loan == nil {
+		return nil, ErrLoanNotFound
+	}
+	if loan.Status != domain.LoanStatusActive {
+		return nil, fmt.Errorf("%w: loan is %s", ErrInvalidStatus, loan.Status)
+	}
+
+	existing, err := s.loanRepo.GetInstallments(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installments: %w", err)
+	}
+	for _, installment := range existing {
+		if installment.IsPaid || installment.AmountPaid > 0 {
+			return nil, fmt.Errorf("%w: installment %d already has payments", ErrInvalidStatus, installment.InstallmentNumber)
+		}
+	}
+
+	from := domain.Today()
+	if loan.StartDate.After(from.Time) {
+		from = loan.StartDate
+	}
+	schedule := buildInstallmentSchedule(loan, from, numInstallments)
+
+	installments := make([]*domain.LoanInstallment, len(schedule))
+	for i, item := range schedule {
+		installments[i] = &domain.LoanInstallment{
+			LoanID:            loan.ID,
+			InstallmentNumber: item.Number,
+			DueDate:           item.DueDate.Time,
+			PrincipalAmount:   item.PrincipalAmount,
+			InterestAmount:    item.InterestAmount,
+			TotalAmount:       item.TotalAmount,
+		}
+	}
+	if err := s.loanRepo.ReplaceInstallments(ctx, loan.ID, installments); err != nil {
+		s.logger.Error().Err(err).
+			Int64("loan_id", loan.ID).
+			Int("num_installments", numInstallments).
+			Msg("Failed to store installment schedule")
+		return nil, fmt.Errorf("failed to store installment schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// buildInstallmentSchedule splits a loan's remaining principal and interest
+// into equal installments due from the given date on. Amounts are rounded to
+// cents and the last installment absorbs the rounding difference, so the
+// installments add up to what the loan still owes.
+func buildInstallmentSchedule(loan *domain.Loan, from domain.Date, numInstallments int) []InstallmentPlanItem {
+	principalCents := int64(math.Round(loan.PrincipalRemaining * 100))
+	interestCents := int64(math.Round(loan.InterestRemaining * 100))
+	termDays := daysBetween(from.Time, loan.DueDate.Time)
+
+	schedule := make([]InstallmentPlanItem, numInstallments)
+	var principalSoFar, interestSoFar int64
+	for i := range schedule {
+		principal := principalCents / int64(numInstallments)
+		interest := interestCents / int64(numInstallments)
+		dueDate := domain.DateFromTime(from.AddDate(0, 0, (i+1)*termDays/numInstallments))
+		if i == numInstallments-1 {
+			principal = principalCents - principalSoFar
+			interest = interestCents - interestSoFar
+			dueDate = loan.DueDate
+		}
+		principalSoFar += principal
+		interestSoFar += interest
+
+		schedule[i] = InstallmentPlanItem{
+			Number:          i + 1,
+			DueDate:         dueDate,
+			PrincipalAmount: float64(principal) / 100,
+			InterestAmount:  float64(interest) / 100,
+			TotalAmount:     float64(principal+interest) / 100,
+		}
+	}
+	return schedule
+}
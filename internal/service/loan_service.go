@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -18,18 +20,50 @@ type LoanService struct {
 	itemRepo       repository.ItemRepository
 	customerRepo   repository.CustomerRepository
 	paymentRepo    repository.PaymentRepository
+	categoryRepo   repository.CategoryRepository
+	productRepo    repository.LoanProductRepository
+	campaignRepo   repository.CampaignRepository
 	settingRepo    repository.SettingRepository
+	branchRepo     repository.BranchRepository
+	cashService    *CashService
+	contractStore  LoanContractStore
+	router         *NotificationRouter
+	moneyFormat    *MoneyFormatService
+	writeOffRepo   repository.LoanWriteOffRepository
+	accountRepo    repository.AccountRepository
+	entryRepo      repository.AccountingEntryRepository
+	verifyKey      []byte
 	logger         zerolog.Logger
 	businessLogger *logger.BusinessLogger
 }
 
+// LoanContractStore generates and stores the contract of a newly created loan
+type LoanContractStore interface {
+	StoreLoanContract(ctx context.Context, loanID int64, createdBy int64) (*domain.Document, error)
+}
+
+// SettingAutoGenerateContract enables storing the contract PDF when a loan is created
+const SettingAutoGenerateContract = "auto_generate_contract"
+
+// SettingCashDisbursementDenomination is the smallest denomination cash loans are
+// paid out in; 0 disables rounding
+const SettingCashDisbursementDenomination = "cash_disbursement_denomination"
+
+// MaxGracePeriodDays is the longest grace period a loan may have
+const MaxGracePeriodDays = 30
+
 // NewLoanService creates a new LoanService
 func NewLoanService(
 	loanRepo repository.LoanRepository,
 	itemRepo repository.ItemRepository,
 	customerRepo repository.CustomerRepository,
 	paymentRepo repository.PaymentRepository,
+	categoryRepo repository.CategoryRepository,
+	productRepo repository.LoanProductRepository,
+	campaignRepo repository.CampaignRepository,
 	settingRepo repository.SettingRepository,
+	cashService *CashService,
+	contractStore LoanContractStore,
 	log zerolog.Logger,
 ) *LoanService {
 	serviceLogger := log.With().Str("service", "loan").Logger()
@@ -38,32 +72,151 @@ func NewLoanService(
 		itemRepo:       itemRepo,
 		customerRepo:   customerRepo,
 		paymentRepo:    paymentRepo,
+		categoryRepo:   categoryRepo,
+		productRepo:    productRepo,
+		campaignRepo:   campaignRepo,
 		settingRepo:    settingRepo,
+		cashService:    cashService,
+		contractStore:  contractStore,
 		logger:         serviceLogger,
 		businessLogger: logger.NewBusinessLogger(serviceLogger),
 	}
 }
 
+// SetBranches enables filling quoted terms from the branch defaults
+func (s *LoanService) SetBranches(branchRepo repository.BranchRepository) {
+	s.branchRepo = branchRepo
+}
+
+// SetNotificationRouter enables notifying staff of high-value loans and confiscations
+func (s *LoanService) SetNotificationRouter(router *NotificationRouter) {
+	s.router = router
+}
+
+// SetMoneyFormat writes staff notification amounts in the currency of the branch
+func (s *LoanService) SetMoneyFormat(moneyFormat *MoneyFormatService) {
+	s.moneyFormat = moneyFormat
+}
+
+// SetWriteOffs enables writing off uncollectible loans against the loss account
+func (s *LoanService) SetWriteOffs(writeOffRepo repository.LoanWriteOffRepository, accountRepo repository.AccountRepository, entryRepo repository.AccountingEntryRepository) {
+	s.writeOffRepo = writeOffRepo
+	s.accountRepo = accountRepo
+	s.entryRepo = entryRepo
+}
+
 // CreateLoanInput represents create loan request data
 type CreateLoanInput struct {
-	CustomerID             int64   `json:"customer_id" validate:"required"`
-	ItemID                 int64   `json:"item_id" validate:"required"`
-	BranchID               int64   `json:"branch_id" validate:"required"`
-	LoanAmount             float64 `json:"loan_amount" validate:"required,gt=0"`
-	InterestRate           float64 `json:"interest_rate" validate:"required,gte=0,lte=100"`
-	LoanTermDays           int     `json:"loan_term_days" validate:"required,gt=0"`
-	PaymentPlanType        string  `json:"payment_plan_type" validate:"required,oneof=single minimum_payment installments"`
-	RequiresMinimumPayment bool    `json:"requires_minimum_payment"`
-	MinimumPaymentAmount   float64 `json:"minimum_payment_amount" validate:"gte=0"`
-	GracePeriodDays        int     `json:"grace_period_days" validate:"gte=0,lte=30"`
-	NumberOfInstallments   int     `json:"number_of_installments" validate:"gte=0"`
-	LateFeeRate            float64 `json:"late_fee_rate" validate:"gte=0"`
-	Notes                  string  `json:"notes"`
-	CreatedBy              int64   `json:"-"`
+	CustomerID             int64    `json:"customer_id" validate:"required"`
+	ItemID                 int64    `json:"item_id" validate:"required"`
+	BranchID               int64    `json:"branch_id" validate:"required"`
+	ProductID              *int64   `json:"product_id"`
+	LoanAmount             float64  `json:"loan_amount" validate:"required,gt=0"`
+	InterestRate           float64  `json:"interest_rate" validate:"gte=0,lte=100"`
+	InterestMethod         string   `json:"interest_method" validate:"omitempty,oneof=flat simple compound"`
+	LoanTermDays           int      `json:"loan_term_days" validate:"required_without=ProductID,gte=0"`
+	PaymentPlanType        string   `json:"payment_plan_type" validate:"omitempty,oneof=single minimum_payment installments"`
+	RequiresMinimumPayment *bool    `json:"requires_minimum_payment"`
+	MinimumPaymentAmount   float64  `json:"minimum_payment_amount" validate:"gte=0"`
+	GracePeriodDays        *int     `json:"grace_period_days" validate:"omitempty,gte=0,lte=30"`
+	NumberOfInstallments   int      `json:"number_of_installments" validate:"gte=0"`
+	LateFeeRate            *float64 `json:"late_fee_rate" validate:"omitempty,gte=0"`
+	DisbursementMethod     string   `json:"disbursement_method" validate:"omitempty,oneof=cash card transfer check other"`
+	Notes                  string   `json:"notes"`
+	RateOverrideReason     string   `json:"rate_override_reason"`
+	OverrideDailyLimit     bool     `json:"override_daily_limit"`
+	OverrideItemLimit      bool     `json:"override_item_limit"`
+	CreatedBy              int64    `json:"-"`
+}
+
+// applyLoanProduct fills the terms left empty in the input from a loan product.
+// Fields set explicitly in the input take precedence over the product presets,
+// including an explicit zero grace period or late fee rate.
+func applyLoanProduct(input CreateLoanInput, product *domain.LoanProduct) CreateLoanInput {
+	if input.InterestRate == 0 {
+		input.InterestRate = product.InterestRate
+	}
+	if input.LoanTermDays == 0 {
+		input.LoanTermDays = product.LoanTermDays
+	}
+	if input.PaymentPlanType == "" {
+		input.PaymentPlanType = string(product.PaymentPlanType)
+	}
+	if input.NumberOfInstallments == 0 && input.PaymentPlanType == string(product.PaymentPlanType) {
+		input.NumberOfInstallments = product.NumberOfInstallments
+	}
+	if input.GracePeriodDays == nil {
+		graceDays := product.GracePeriodDays
+		input.GracePeriodDays = &graceDays
+	}
+	if input.LateFeeRate == nil {
+		lateFeeRate := product.LateFeeRate
+		input.LateFeeRate = &lateFeeRate
+	}
+	if input.RequiresMinimumPayment == nil {
+		requiresMinimum := product.RequiresMinimumPayment
+		input.RequiresMinimumPayment = &requiresMinimum
+	}
+	return input
+}
+
+// applyDefaultRates fills the rates still left empty in the input. Each rate
+// is taken from the request, then the item's category, then the global
+// default_interest_rate and default_late_fee_rate settings.
+func (s *LoanService) applyDefaultRates(ctx context.Context, input CreateLoanInput, item *domain.Item) CreateLoanInput {
+	if input.InterestRate > 0 && input.LateFeeRate != nil {
+		return input
+	}
+
+	var category *domain.Category
+	if item.CategoryID != nil && s.categoryRepo != nil {
+		category, _ = s.categoryRepo.GetByID(ctx, *item.CategoryID)
+	}
+
+	if input.InterestRate == 0 {
+		if category != nil && category.DefaultInterestRate > 0 {
+			input.InterestRate = category.DefaultInterestRate
+		} else {
+			input.InterestRate = getSettingFloat(ctx, s.settingRepo, "default_interest_rate", &input.BranchID, 0)
+		}
+	}
+	if input.LateFeeRate == nil {
+		lateFeeRate := s.defaultLateFeeRate(ctx, &input.BranchID)
+		if category != nil && category.DefaultLateFeeRate != nil && *category.DefaultLateFeeRate > 0 {
+			lateFeeRate = *category.DefaultLateFeeRate
+		}
+		input.LateFeeRate = &lateFeeRate
+	}
+	return input
+}
+
+// defaultLateFeeRate returns the default_late_fee_rate setting, or a system
+// default of 1% per day when it isn't configured
+func (s *LoanService) defaultLateFeeRate(ctx context.Context, branchID *int64) float64 {
+	if rate := getSettingFloat(ctx, s.settingRepo, "default_late_fee_rate", branchID, 0); rate > 0 {
+		return rate
+	}
+	s.logger.Warn().Msg("Using system default late fee rate of 1% per day")
+	return 1.0
 }
 
 // Create creates a new loan
 func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domain.Loan, error) {
+	// Pre-fill the terms from the selected loan product
+	if input.ProductID != nil {
+		if s.productRepo == nil {
+			return nil, errors.New("loan product not found")
+		}
+		product, err := s.productRepo.GetByID(ctx, *input.ProductID)
+		if err != nil || product == nil {
+			return nil, errors.New("loan product not found")
+		}
+		if !product.IsActive {
+			return nil, errors.New("loan product is not active")
+		}
+		input = applyLoanProduct(input, product)
+	}
+
 	s.logger.Info().
 		Int64("customer_id", input.CustomerID).
 		Int64("item_id", input.ItemID).
@@ -103,6 +256,45 @@ func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domai
 		return nil, errors.New("item is not available for loan")
 	}
 
+	// An item can back a single active loan at a time
+	activeLoan, err := s.loanRepo.GetActiveByItemID(ctx, item.ID)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("item_id", item.ID).Msg("Failed to check active loans for item")
+		return nil, fmt.Errorf("failed to check active loans for item: %w", err)
+	}
+	if activeLoan != nil {
+		s.logger.Warn().
+			Int64("item_id", item.ID).
+			Str("loan_number", activeLoan.LoanNumber).
+			Msg("Loan rejected: item already backs an active loan")
+		return nil, fmt.Errorf("%w: %s", ErrItemHasActiveLoan, activeLoan.LoanNumber)
+	}
+
+	// Cap how many items a single customer may have pawned at once
+	if limit := s.pawnedItemLimit(ctx, input.BranchID); limit > 0 {
+		pawned, err := s.countPawnedItems(ctx, customer.ID)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("customer_id", customer.ID).Msg("Failed to count pawned items for customer")
+			return nil, err
+		}
+		if pawned >= limit {
+			if !input.OverrideItemLimit {
+				s.logger.Warn().
+					Int64("customer_id", customer.ID).
+					Int("pawned_items", pawned).
+					Int("limit", limit).
+					Msg("Loan rejected: customer pawned item limit reached")
+				return nil, fmt.Errorf("%w: %d of %d", ErrPawnedItemLimitExceeded, pawned, limit)
+			}
+			s.logger.Warn().
+				Int64("customer_id", customer.ID).
+				Int("pawned_items", pawned).
+				Int64("created_by", input.CreatedBy).
+				Msg("Customer pawned item limit overridden")
+			input.Notes = strings.TrimSpace(input.Notes + "\nLímite de artículos empeñados por cliente excedido con autorización")
+		}
+	}
+
 	// Validate loan amount doesn't exceed item loan value
 	if input.LoanAmount > item.LoanValue {
 		s.logger.Warn().
@@ -113,79 +305,132 @@ func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domai
 		return nil, errors.New("loan amount cannot exceed item loan value")
 	}
 
-	// Calculate interest
-	interestAmount := input.LoanAmount * (input.InterestRate / 100)
-	totalAmount := input.LoanAmount + interestAmount
+	// Cash payouts are rounded to the smallest denomination on hand, never above the cap
+	requestedAmount := input.LoanAmount
+	var disbursementRounding float64
+	input.LoanAmount, disbursementRounding = s.roundCashDisbursement(ctx, input, item.LoanValue)
+	if disbursementRounding != 0 {
+		s.logger.Info().
+			Float64("requested_amount", requestedAmount).
+			Float64("disbursed_amount", input.LoanAmount).
+			Float64("rounding", disbursementRounding).
+			Msg("Rounded cash disbursement to denomination")
+	}
 
-	// Get default late fee rate from settings if not provided
-	lateFeeRate := input.LateFeeRate
-	if lateFeeRate == 0 {
-		if setting, err := s.settingRepo.Get(ctx, "default_late_fee_rate", nil); err == nil {
-			if rate, ok := setting.Value.(float64); ok {
-				lateFeeRate = rate
-			}
-		}
-		// If still 0, use a system default of 1% per day
-		if lateFeeRate == 0 {
-			lateFeeRate = 1.0
-			s.logger.Warn().Msg("Using system default late fee rate of 1% per day")
+	// Rates left empty come from the item's category, then the global settings
+	input = s.applyDefaultRates(ctx, input, item)
+
+	// Terms must be set explicitly or come from the loan product
+	if input.InterestRate <= 0 {
+		return nil, errors.New("interest rate is required")
+	}
+	if input.PaymentPlanType == "" {
+		return nil, errors.New("payment plan type is required")
+	}
+	if input.LoanTermDays <= 0 {
+		return nil, errors.New("loan term days is required")
+	}
+	// Terms taken from the product skip the request validation, so the
+	// merged terms are checked again
+	if input.GracePeriodDays != nil && (*input.GracePeriodDays < 0 || *input.GracePeriodDays > MaxGracePeriodDays) {
+		return nil, fmt.Errorf("%w: grace period must be between 0 and %d days", ErrInvalidInput, MaxGracePeriodDays)
+	}
+	if *input.LateFeeRate < 0 {
+		return nil, fmt.Errorf("%w: late fee rate cannot be negative", ErrInvalidInput)
+	}
+
+	// Catch mistyped rates unless the clerk confirmed them with a reason
+	if err := s.GetRateBounds(ctx, input.BranchID).Validate(input.InterestRate, *input.LateFeeRate); err != nil {
+		reason := strings.TrimSpace(input.RateOverrideReason)
+		if reason == "" {
+			s.logger.Warn().
+				Float64("interest_rate", input.InterestRate).
+				Float64("late_fee_rate", *input.LateFeeRate).
+				Msg("Loan rejected: rate outside plausible bounds")
+			return nil, err
 		}
+		s.logger.Warn().
+			Err(err).
+			Str("reason", reason).
+			Int64("created_by", input.CreatedBy).
+			Msg("Rate outside plausible bounds overridden")
+		input.Notes = strings.TrimSpace(input.Notes + "\nTasa fuera de rango autorizada: " + reason)
 	}
 
-	// Generate loan number
-	loanNumber, err := s.loanRepo.GenerateNumber(ctx)
-	if err != nil {
-		s.logger.Error().Err(err).Msg("Failed to generate loan number")
-		return nil, fmt.Errorf("failed to generate loan number: %w", err)
+	// Calculate due date, interest and fees
+	loan := s.newLoanTerms(ctx, input, domain.Today())
+
+	// Validate amount and term against configured limits
+	limits := s.GetLimits(ctx, input.BranchID, item.CategoryID)
+	if err := limits.Validate(input.LoanAmount, loan.LoanTermDays); err != nil {
+		s.logger.Warn().
+			Float64("loan_amount", input.LoanAmount).
+			Int("loan_term_days", loan.LoanTermDays).
+			Interface("limits", limits).
+			Msg("Loan rejected: outside configured limits")
+		return nil, err
 	}
 
-	// Calculate due date and minimum payment info
-	startDate := domain.Today()
-	var dueDate domain.Date
-	var loanTermDays int
+	// High-value loans need their appraisal photos and documents on file
+	if unmet := limits.PhotoRequirements.Unmet(input.LoanAmount, item, customer); len(unmet) > 0 {
+		s.logger.Warn().
+			Float64("loan_amount", input.LoanAmount).
+			Int64("item_id", input.ItemID).
+			Strs("unmet", unmet).
+			Msg("Loan rejected: photo requirements not met")
+		return nil, &LoanRequirementsError{Unmet: unmet}
+	}
 
-	// For installment payment plans, due date is the last installment date
-	if input.PaymentPlanType == "installments" && input.NumberOfInstallments > 0 {
-		dueDate = domain.DateFromTime(startDate.AddDate(0, input.NumberOfInstallments, 0))
-		// Calculate actual term in days based on installments
-		loanTermDays = int(dueDate.Sub(startDate.Time).Hours() / 24)
-	} else {
-		dueDate = domain.DateFromTime(startDate.AddDate(0, 0, input.LoanTermDays))
-		loanTermDays = input.LoanTermDays
+	// Cash disbursements must come out of the user's open cash session
+	var cashSession *domain.CashSession
+	if s.cashService != nil && (input.DisbursementMethod == "" || input.DisbursementMethod == string(domain.PaymentMethodCash)) {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, input.CreatedBy, nil)
+		if err != nil {
+			s.logger.Warn().Int64("user_id", input.CreatedBy).Msg("Loan rejected: no open cash session")
+			return nil, err
+		}
+		if err := s.cashService.EnsureCashAvailable(ctx, cashSession, input.LoanAmount); err != nil {
+			s.logger.Warn().
+				Int64("session_id", cashSession.ID).
+				Float64("loan_amount", input.LoanAmount).
+				Msg("Loan rejected: insufficient cash in session")
+			return nil, err
+		}
+		if err := s.cashService.EnsureDisbursementWithinDailyLimit(ctx, cashSession.BranchID, input.LoanAmount); err != nil {
+			if !input.OverrideDailyLimit || !errors.Is(err, ErrDailyDisbursementLimitExceeded) {
+				s.logger.Warn().Err(err).
+					Int64("branch_id", cashSession.BranchID).
+					Float64("loan_amount", input.LoanAmount).
+					Msg("Loan rejected: daily cash disbursement limit")
+				return nil, err
+			}
+			s.logger.Warn().
+				Err(err).
+				Int64("branch_id", cashSession.BranchID).
+				Int64("created_by", input.CreatedBy).
+				Msg("Daily cash disbursement limit overridden")
+			input.Notes = strings.TrimSpace(input.Notes + "\nLímite diario de desembolso en efectivo excedido con autorización")
+		}
 	}
 
-	var minimumPaymentAmount *float64
-	var nextPaymentDueDate *time.Time
-	if input.RequiresMinimumPayment && input.MinimumPaymentAmount > 0 {
-		minimumPaymentAmount = &input.MinimumPaymentAmount
-		next := startDate.AddDate(0, 1, 0) // Monthly payment
-		nextPaymentDueDate = &next
+	// Generate loan number
+	loanNumber, err := s.loanRepo.GenerateNumber(ctx, sequenceResetCadence(ctx, s.settingRepo, input.BranchID))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to generate loan number")
+		return nil, fmt.Errorf("failed to generate loan number: %w", err)
 	}
 
 	// Create loan
-	loan := &domain.Loan{
-		LoanNumber:             loanNumber,
-		BranchID:               input.BranchID,
-		CustomerID:             input.CustomerID,
-		ItemID:                 input.ItemID,
-		LoanAmount:             input.LoanAmount,
-		InterestRate:           input.InterestRate,
-		InterestAmount:         interestAmount,
-		PrincipalRemaining:     input.LoanAmount,
-		InterestRemaining:      interestAmount,
-		TotalAmount:            totalAmount,
-		LateFeeRate:            lateFeeRate,
-		StartDate:              startDate,
-		DueDate:                dueDate,
-		PaymentPlanType:        domain.PaymentPlanType(input.PaymentPlanType),
-		LoanTermDays:           loanTermDays,
-		RequiresMinimumPayment: input.RequiresMinimumPayment,
-		MinimumPaymentAmount:   minimumPaymentAmount,
-		NextPaymentDueDate:     nextPaymentDueDate,
-		GracePeriodDays:        input.GracePeriodDays,
-		Status:                 domain.LoanStatusActive,
-		Notes:                  input.Notes,
-		CreatedBy:              input.CreatedBy,
+	loan.LoanNumber = loanNumber
+	loan.CustomerID = input.CustomerID
+	loan.ItemID = input.ItemID
+	loan.DisbursementRounding = disbursementRounding
+	loan.Status = domain.LoanStatusActive
+	loan.Notes = input.Notes
+	loan.CreatedBy = input.CreatedBy
+	loan.AuthorizationRequired = cashSession != nil && s.requiresSecondAuthorization(ctx, loan.BranchID, loan.LoanAmount)
+	if loan.AuthorizationRequired {
+		loan.Status = domain.LoanStatusPendingAuthorization
 	}
 
 	// Start transaction
@@ -199,6 +444,9 @@ func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domai
 	// Create loan
 	if err := s.loanRepo.CreateTx(ctx, tx, loan); err != nil {
 		s.logger.Error().Err(err).Str("loan_number", loanNumber).Msg("Failed to create loan")
+		if errors.Is(err, ErrItemHasActiveLoan) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create loan: %w", err)
 	}
 
@@ -220,17 +468,60 @@ func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domai
 		}
 	}
 
+	// Record the cash disbursement with the loan, unless it waits for a second
+	// user to co-sign it. A loan whose cash cannot be paid out is not created.
+	if cashSession != nil && !loan.AuthorizationRequired {
+		if err := s.cashService.RecordLoanDisbursementTx(ctx, tx, cashSession.ID, loan.ID, loan.LoanAmount, input.CreatedBy); err != nil {
+			s.logger.Error().Err(err).
+				Str("loan_number", loanNumber).
+				Int64("session_id", cashSession.ID).
+				Msg("Failed to record loan disbursement cash movement")
+			if serr := s.itemRepo.UpdateStatus(ctx, item.ID, item.Status); serr != nil {
+				s.logger.Error().Err(serr).Int64("item_id", item.ID).Msg("Failed to restore item status after disbursement failure")
+			}
+			return nil, fmt.Errorf("failed to record loan disbursement: %w", err)
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		s.logger.Error().Err(err).Str("loan_number", loanNumber).Msg("Failed to commit transaction")
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// Store the contract as a record of the original terms
+	if s.contractStore != nil && getSettingBool(ctx, s.settingRepo, SettingAutoGenerateContract, &input.BranchID, false) {
+		doc, err := s.contractStore.StoreLoanContract(ctx, loan.ID, input.CreatedBy)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to store loan contract")
+		} else {
+			loan.ContractDocumentID = &doc.ID
+			loan.ContractURL = doc.FileURL
+			loan.ContractHash = doc.ContentHash
+		}
+	}
+
 	// Update customer stats
 	totalLoans := customer.TotalLoans + 1
 	s.customerRepo.UpdateCreditInfo(ctx, customer.ID, repository.CustomerCreditUpdate{
 		TotalLoans: &totalLoans,
 	})
 
+	message := fmt.Sprintf("Se otorgó el préstamo %s por %s", loan.LoanNumber,
+		moneyFormatFor(ctx, s.moneyFormat, loan.BranchID).Format(loan.LoanAmount))
+	if loan.AuthorizationRequired {
+		message += ". El desembolso espera la autorización de un segundo usuario"
+	}
+	s.router.emit(ctx, InternalEvent{
+		Event:         domain.InternalEventHighValueLoan,
+		BranchID:      loan.BranchID,
+		Amount:        loan.LoanAmount,
+		Title:         "Préstamo de Alto Valor",
+		Message:       message,
+		Type:          "info",
+		ReferenceType: "loan",
+		ReferenceID:   &loan.ID,
+	})
+
 	// Load relations
 	loan.Customer = customer
 	loan.Item = item
@@ -241,9 +532,9 @@ func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domai
 		Int64("customer_id", input.CustomerID).
 		Int64("item_id", input.ItemID).
 		Float64("loan_amount", input.LoanAmount).
-		Float64("interest_amount", interestAmount).
-		Float64("total_amount", totalAmount).
-		Str("due_date", dueDate.Format("2006-01-02")).
+		Float64("interest_amount", loan.InterestAmount).
+		Float64("total_amount", loan.TotalAmount).
+		Str("due_date", loan.DueDate.Format("2006-01-02")).
 		Msg("Loan created successfully")
 
 	// Log business event
@@ -252,6 +543,469 @@ func (s *LoanService) Create(ctx context.Context, input CreateLoanInput) (*domai
 	return loan, nil
 }
 
+// newLoanTerms works out the dates, interest and fees of a loan starting on
+// start. Loan creation and quotes share it so a quote always matches the loan
+// created from the same terms.
+func (s *LoanService) newLoanTerms(ctx context.Context, input CreateLoanInput, start domain.Date) *domain.Loan {
+	var dueDate domain.Date
+	var loanTermDays int
+
+	// For installment payment plans, due date is the last installment date
+	if input.PaymentPlanType == "installments" && input.NumberOfInstallments > 0 {
+		dueDate = domain.DateFromTime(start.AddDate(0, input.NumberOfInstallments, 0))
+		// Calculate actual term in days based on installments
+		loanTermDays = int(dueDate.Sub(start.Time).Hours() / 24)
+	} else {
+		dueDate = domain.DateFromTime(start.AddDate(0, 0, input.LoanTermDays))
+		loanTermDays = input.LoanTermDays
+	}
+
+	// Calculate interest
+	interestMethod := s.interestMethodFor(ctx, input)
+	interestAmount := domain.InterestStrategyFor(interestMethod).Interest(input.LoanAmount, input.InterestRate, loanTermDays)
+
+	// Get default late fee rate from settings if not provided
+	var lateFeeRate float64
+	if input.LateFeeRate != nil {
+		lateFeeRate = *input.LateFeeRate
+	} else {
+		lateFeeRate = s.defaultLateFeeRate(ctx, nil)
+	}
+	requiresMinimumPayment := input.RequiresMinimumPayment != nil && *input.RequiresMinimumPayment
+	var gracePeriodDays int
+	if input.GracePeriodDays != nil {
+		gracePeriodDays = *input.GracePeriodDays
+	}
+
+	// Apply the best running promotional campaign
+	var campaignID *int64
+	var campaignInterestDiscount float64
+	if campaign := s.findCampaign(ctx, input.BranchID, input.CustomerID, start); campaign != nil {
+		campaignID = &campaign.ID
+		campaignInterestDiscount = roundCents(interestAmount * campaign.InterestDiscountPercent / 100)
+		interestAmount -= campaignInterestDiscount
+		lateFeeRate = lateFeeRate * (1 - campaign.LateFeeDiscountPercent/100)
+	}
+
+	var minimumPaymentAmount *float64
+	var nextPaymentDueDate *time.Time
+	if requiresMinimumPayment && input.MinimumPaymentAmount > 0 {
+		minimumPaymentAmount = &input.MinimumPaymentAmount
+		next := start.AddDate(0, 1, 0) // Monthly payment
+		nextPaymentDueDate = &next
+	}
+
+	return &domain.Loan{
+		BranchID:                 input.BranchID,
+		LoanAmount:               input.LoanAmount,
+		InterestRate:             input.InterestRate,
+		InterestAmount:           interestAmount,
+		InterestMethod:           interestMethod,
+		PrincipalRemaining:       input.LoanAmount,
+		InterestRemaining:        interestAmount,
+		TotalAmount:              input.LoanAmount + interestAmount,
+		CampaignID:               campaignID,
+		CampaignInterestDiscount: campaignInterestDiscount,
+		LateFeeRate:              lateFeeRate,
+		StartDate:                start,
+		DueDate:                  dueDate,
+		PaymentPlanType:          domain.PaymentPlanType(input.PaymentPlanType),
+		LoanTermDays:             loanTermDays,
+		RequiresMinimumPayment:   requiresMinimumPayment,
+		MinimumPaymentAmount:     minimumPaymentAmount,
+		NextPaymentDueDate:       nextPaymentDueDate,
+		GracePeriodDays:          gracePeriodDays,
+	}
+}
+
+// findCampaign picks the running campaign with the largest interest discount that
+// covers the loan's branch and customer. Lookup failures are logged and treated
+// as no campaign so a promotion never blocks a loan.
+func (s *LoanService) findCampaign(ctx context.Context, branchID, customerID int64, day domain.Date) *domain.Campaign {
+	if s.campaignRepo == nil {
+		return nil
+	}
+
+	campaigns, err := s.campaignRepo.ListRunning(ctx, day)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list running campaigns")
+		return nil
+	}
+
+	var best *domain.Campaign
+	for _, campaign := range campaigns {
+		if !campaign.IsRunningOn(day) || !campaign.AppliesToBranch(branchID) {
+			continue
+		}
+		if campaign.FlaggedCustomersOnly {
+			flagged, err := s.campaignRepo.IsCustomerFlagged(ctx, campaign.ID, customerID)
+			if err != nil {
+				s.logger.Error().Err(err).Int64("campaign_id", campaign.ID).Msg("Failed to check campaign customer")
+				continue
+			}
+			if !flagged {
+				continue
+			}
+		}
+		if best == nil || campaign.InterestDiscountPercent > best.InterestDiscountPercent {
+			best = campaign
+		}
+	}
+
+	return best
+}
+
+// Setting keys for loan limits
+const (
+	SettingMinLoanAmount   = "min_loan_amount"
+	SettingMaxLoanAmount   = "max_loan_amount"
+	SettingMinLoanTermDays = "min_loan_term_days"
+	SettingMaxLoanTermDays = "max_loan_term_days"
+)
+
+// Default loan limits used when no setting is configured
+const (
+	DefaultMinLoanAmount   = 1.0
+	DefaultMaxLoanAmount   = 1000000.0
+	DefaultMinLoanTermDays = 1
+	DefaultMaxLoanTermDays = 365
+)
+
+// LoanLimits represents the allowed bounds for a new loan
+type LoanLimits struct {
+	MinAmount   float64 `json:"min_amount"`
+	MaxAmount   float64 `json:"max_amount"`
+	MinTermDays int     `json:"min_term_days"`
+	MaxTermDays int     `json:"max_term_days"`
+
+	// Photos and documents loans from MinLoanAmount up need before they activate
+	PhotoRequirements LoanPhotoRequirements `json:"photo_requirements"`
+}
+
+// Validate checks a loan amount and term against the limits
+func (l LoanLimits) Validate(amount float64, termDays int) error {
+	if amount < l.MinAmount {
+		return fmt.Errorf("loan amount must be at least %.2f", l.MinAmount)
+	}
+	if amount > l.MaxAmount {
+		return fmt.Errorf("loan amount cannot exceed %.2f", l.MaxAmount)
+	}
+	if termDays < l.MinTermDays {
+		return fmt.Errorf("loan term must be at least %d days", l.MinTermDays)
+	}
+	if termDays > l.MaxTermDays {
+		return fmt.Errorf("loan term cannot exceed %d days", l.MaxTermDays)
+	}
+	return nil
+}
+
+// GetLimits returns the loan limits for a branch, narrowed by the category amount and term bounds when set
+func (s *LoanService) GetLimits(ctx context.Context, branchID int64, categoryID *int64) LoanLimits {
+	var branch *int64
+	if branchID > 0 {
+		branch = &branchID
+	}
+
+	limits := LoanLimits{
+		MinAmount:   getSettingFloat(ctx, s.settingRepo, SettingMinLoanAmount, branch, DefaultMinLoanAmount),
+		MaxAmount:   getSettingFloat(ctx, s.settingRepo, SettingMaxLoanAmount, branch, DefaultMaxLoanAmount),
+		MinTermDays: getSettingInt(ctx, s.settingRepo, SettingMinLoanTermDays, branch, DefaultMinLoanTermDays),
+		MaxTermDays: getSettingInt(ctx, s.settingRepo, SettingMaxLoanTermDays, branch, DefaultMaxLoanTermDays),
+	}
+
+	limits.PhotoRequirements = s.GetPhotoRequirements(ctx, branchID)
+
+	if categoryID != nil && s.categoryRepo != nil {
+		if category, err := s.categoryRepo.GetByID(ctx, *categoryID); err == nil {
+			if category.MinLoanAmount != nil && *category.MinLoanAmount > limits.MinAmount {
+				limits.MinAmount = *category.MinLoanAmount
+			}
+			if category.MaxLoanAmount != nil && *category.MaxLoanAmount > 0 && *category.MaxLoanAmount < limits.MaxAmount {
+				limits.MaxAmount = *category.MaxLoanAmount
+			}
+			if category.MinLoanTermDays != nil && *category.MinLoanTermDays > limits.MinTermDays {
+				limits.MinTermDays = *category.MinLoanTermDays
+			}
+			if category.MaxLoanTermDays != nil && *category.MaxLoanTermDays > 0 && *category.MaxLoanTermDays < limits.MaxTermDays {
+				limits.MaxTermDays = *category.MaxLoanTermDays
+			}
+		}
+	}
+
+	return limits
+}
+
+// SettingLoanPhotoRequirements holds the photos and documents high-value loans
+// need on file before they activate, as a JSON LoanPhotoRequirements object
+const SettingLoanPhotoRequirements = "loan_photo_requirements"
+
+// Documents a loan can require on file
+const (
+	LoanDocumentCustomerPhoto    = "customer_photo"
+	LoanDocumentCustomerIdentity = "customer_identity"
+)
+
+// ErrLoanRequirementsUnmet is returned when a loan lacks the photos or documents its amount requires
+var ErrLoanRequirementsUnmet = errors.New("loan requirements not met")
+
+// LoanPhotoRequirements are the appraisal photos and documents a loan from
+// MinLoanAmount up needs on file before it activates. Without a setting no
+// loan has requirements.
+type LoanPhotoRequirements struct {
+	MinLoanAmount     float64  `json:"min_loan_amount"`
+	MinPhotos         int      `json:"min_photos"`
+	RequiredDocuments []string `json:"required_documents"`
+}
+
+// Validate checks that the requirements only name documents a loan can require
+func (r LoanPhotoRequirements) Validate() error {
+	if r.MinPhotos < 0 || r.MinLoanAmount < 0 {
+		return fmt.Errorf("%w: photo requirements cannot be negative", ErrInvalidInput)
+	}
+	for _, document := range r.RequiredDocuments {
+		switch document {
+		case LoanDocumentCustomerPhoto, LoanDocumentCustomerIdentity:
+		default:
+			return fmt.Errorf("%w: unknown required document %q", ErrInvalidInput, document)
+		}
+	}
+	return nil
+}
+
+// AppliesTo checks if a loan of the amount has requirements
+func (r LoanPhotoRequirements) AppliesTo(amount float64) bool {
+	return (r.MinPhotos > 0 || len(r.RequiredDocuments) > 0) && amount >= r.MinLoanAmount
+}
+
+// Unmet lists the requirements a loan of the amount on the item to the customer does not meet
+func (r LoanPhotoRequirements) Unmet(amount float64, item *domain.Item, customer *domain.Customer) []string {
+	if !r.AppliesTo(amount) {
+		return nil
+	}
+
+	var unmet []string
+	if len(item.Photos) < r.MinPhotos {
+		unmet = append(unmet, fmt.Sprintf("item needs at least %d photos, has %d", r.MinPhotos, len(item.Photos)))
+	}
+	for _, document := range r.RequiredDocuments {
+		switch document {
+		case LoanDocumentCustomerPhoto:
+			if customer.PhotoURL == "" {
+				unmet = append(unmet, "customer photo is required")
+			}
+		case LoanDocumentCustomerIdentity:
+			if customer.IdentityNumber == "" {
+				unmet = append(unmet, "customer identity document is required")
+			}
+		default:
+			// Never met, so a misconfigured requirement blocks loans instead of being skipped
+			unmet = append(unmet, fmt.Sprintf("unknown required document %q", document))
+		}
+	}
+	return unmet
+}
+
+// LoanRequirementsError lists the requirements a loan does not meet
+type LoanRequirementsError struct {
+	Unmet []string
+}
+
+func (e *LoanRequirementsError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrLoanRequirementsUnmet, strings.Join(e.Unmet, "; "))
+}
+
+func (e *LoanRequirementsError) Unwrap() error {
+	return ErrLoanRequirementsUnmet
+}
+
+// GetPhotoRequirements returns the photo and document requirements of a branch's loans
+func (s *LoanService) GetPhotoRequirements(ctx context.Context, branchID int64) LoanPhotoRequirements {
+	var branch *int64
+	if branchID > 0 {
+		branch = &branchID
+	}
+
+	var requirements LoanPhotoRequirements
+	getSettingJSON(ctx, s.settingRepo, SettingLoanPhotoRequirements, branch, &requirements)
+	if requirements.RequiredDocuments == nil {
+		requirements.RequiredDocuments = []string{}
+	}
+	return requirements
+}
+
+// SettingMaxPawnedItemsPerCustomer caps how many items a single customer may
+// have pawned at once; 0 means no cap
+const SettingMaxPawnedItemsPerCustomer = "max_pawned_items_per_customer"
+
+// PermissionOverridePawnedItemLimit lets a user create a loan past the
+// customer's pawned item cap
+const PermissionOverridePawnedItemLimit = "loans.override_item_limit"
+
+// ErrPawnedItemLimitExceeded is returned when a customer already has as many
+// items pawned as the configured cap allows
+var ErrPawnedItemLimitExceeded = errors.New("customer has reached the maximum number of pawned items")
+
+// PawnedItemCapacity is how many items a customer has pawned against the cap
+type PawnedItemCapacity struct {
+	CustomerID  int64 `json:"customer_id"`
+	PawnedItems int   `json:"pawned_items"`
+	Limit       int   `json:"limit"`               // 0 means no cap
+	Remaining   *int  `json:"remaining,omitempty"` // nil when there is no cap
+}
+
+// GetPawnedItemCapacity returns how many items a customer has pawned and the
+// cap configured for the branch
+func (s *LoanService) GetPawnedItemCapacity(ctx context.Context, customerID, branchID int64) (*PawnedItemCapacity, error) {
+	pawned, err := s.countPawnedItems(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := &PawnedItemCapacity{
+		CustomerID:  customerID,
+		PawnedItems: pawned,
+		Limit:       s.pawnedItemLimit(ctx, branchID),
+	}
+	if capacity.Limit > 0 {
+		remaining := capacity.Limit - capacity.PawnedItems
+		if remaining < 0 {
+			remaining = 0
+		}
+		capacity.Remaining = &remaining
+	}
+
+	return capacity, nil
+}
+
+func (s *LoanService) pawnedItemLimit(ctx context.Context, branchID int64) int {
+	var branch *int64
+	if branchID > 0 {
+		branch = &branchID
+	}
+	return getSettingInt(ctx, s.settingRepo, SettingMaxPawnedItemsPerCustomer, branch, 0)
+}
+
+// countPawnedItems counts the items a customer has pawned, i.e. backing an
+// active, overdue or pending loan; an item backs a single open loan at a time
+func (s *LoanService) countPawnedItems(ctx context.Context, customerID int64) (int, error) {
+	count := 0
+	for _, status := range []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusOverdue, domain.LoanStatusPendingAuthorization} {
+		loans, err := s.loanRepo.List(ctx, repository.LoanListParams{
+			CustomerID:       &customerID,
+			Status:           &status,
+			PaginationParams: repository.PaginationParams{PerPage: 1},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to count pawned items: %w", err)
+		}
+		count += loans.Total
+	}
+	return count, nil
+}
+
+// ErrImplausibleRate is returned when a loan rate falls outside the plausible bounds
+var ErrImplausibleRate = errors.New("rate outside plausible bounds, provide rate_override_reason to proceed")
+
+// Setting keys for the plausible rate bounds
+const (
+	SettingMinPlausibleMonthlyRate = "min_plausible_monthly_interest_rate"
+	SettingMaxPlausibleMonthlyRate = "max_plausible_monthly_interest_rate"
+	SettingMaxPlausibleLateFeeRate = "max_plausible_late_fee_rate"
+)
+
+// Default plausible rate bounds used when no setting is configured
+const (
+	DefaultMinPlausibleMonthlyRate = 1.0
+	DefaultMaxPlausibleMonthlyRate = 25.0
+	DefaultMaxPlausibleLateFeeRate = 5.0
+)
+
+// RateBounds are the interest and late fee rates considered plausible. Rates
+// outside them are most likely typing mistakes.
+type RateBounds struct {
+	MinMonthlyRate float64 `json:"min_monthly_rate"`
+	MaxMonthlyRate float64 `json:"max_monthly_rate"`
+	MaxLateFeeRate float64 `json:"max_late_fee_rate"`
+}
+
+// Validate checks a monthly interest rate and a daily late fee rate against the bounds
+func (b RateBounds) Validate(interestRate, lateFeeRate float64) error {
+	if interestRate < b.MinMonthlyRate || interestRate > b.MaxMonthlyRate {
+		return fmt.Errorf("%w: interest rate of %.2f%% is outside %.2f%%-%.2f%%", ErrImplausibleRate, interestRate, b.MinMonthlyRate, b.MaxMonthlyRate)
+	}
+	if lateFeeRate > b.MaxLateFeeRate {
+		return fmt.Errorf("%w: late fee rate of %.2f%% exceeds %.2f%%", ErrImplausibleRate, lateFeeRate, b.MaxLateFeeRate)
+	}
+	return nil
+}
+
+// GetRateBounds returns the plausible rate bounds for a branch
+func (s *LoanService) GetRateBounds(ctx context.Context, branchID int64) RateBounds {
+	var branch *int64
+	if branchID > 0 {
+		branch = &branchID
+	}
+
+	return RateBounds{
+		MinMonthlyRate: getSettingFloat(ctx, s.settingRepo, SettingMinPlausibleMonthlyRate, branch, DefaultMinPlausibleMonthlyRate),
+		MaxMonthlyRate: getSettingFloat(ctx, s.settingRepo, SettingMaxPlausibleMonthlyRate, branch, DefaultMaxPlausibleMonthlyRate),
+		MaxLateFeeRate: getSettingFloat(ctx, s.settingRepo, SettingMaxPlausibleLateFeeRate, branch, DefaultMaxPlausibleLateFeeRate),
+	}
+}
+
+// CheckDefaultRates returns a warning for the default late fee rate and every
+// branch default interest rate that falls outside the plausible bounds. It is meant to be run at startup so misconfigured defaults are noticed before
+// they are used on a loan.
+func (s *LoanService) CheckDefaultRates(ctx context.Context) []string {
+	var warnings []string
+
+	bounds := s.GetRateBounds(ctx, 0)
+	lateFeeRate := getSettingFloat(ctx, s.settingRepo, "default_late_fee_rate", nil, 0)
+	if lateFeeRate > bounds.MaxLateFeeRate {
+		warnings = append(warnings, fmt.Sprintf("default_late_fee_rate of %.2f%% exceeds %.2f%%", lateFeeRate, bounds.MaxLateFeeRate))
+	}
+
+	if s.branchRepo == nil {
+		return warnings
+	}
+	branches, err := s.branchRepo.List(ctx, repository.PaginationParams{Page: 1, PerPage: 1000})
+	if err != nil {
+		return append(warnings, fmt.Sprintf("failed to list branches: %v", err))
+	}
+	for _, branch := range branches.Data {
+		if branch.DefaultInterestRate == 0 {
+			continue
+		}
+		if err := s.GetRateBounds(ctx, branch.ID).Validate(branch.DefaultInterestRate, 0); err != nil {
+			warnings = append(warnings, fmt.Sprintf("branch %s default interest rate: %v", branch.Code, err))
+		}
+	}
+
+	return warnings
+}
+
+// roundCashDisbursement rounds a cash loan amount to the nearest multiple of the
+// configured denomination, rounding down instead when rounding up would exceed
+// maxAmount. It returns the amount to disburse and the adjustment applied.
+func (s *LoanService) roundCashDisbursement(ctx context.Context, input CreateLoanInput, maxAmount float64) (float64, float64) {
+	if input.DisbursementMethod != "" && input.DisbursementMethod != string(domain.PaymentMethodCash) {
+		return input.LoanAmount, 0
+	}
+	denomination := getSettingFloat(ctx, s.settingRepo, SettingCashDisbursementDenomination, &input.BranchID, 0)
+	if denomination <= 0 {
+		return input.LoanAmount, 0
+	}
+
+	rounded := roundCents(math.Round(input.LoanAmount/denomination) * denomination)
+	if rounded > maxAmount {
+		rounded = roundCents(math.Floor(input.LoanAmount/denomination) * denomination)
+	}
+	if rounded <= 0 {
+		return input.LoanAmount, 0
+	}
+	return rounded, roundCents(rounded - input.LoanAmount)
+}
+
 // calculateInstallments calculates installments for a loan
 func (s *LoanService) calculateInstallments(loan *domain.Loan, numInstallments int) []*domain.LoanInstallment {
 	installments := make([]*domain.LoanInstallment, numInstallments)
@@ -277,12 +1031,15 @@ func (s *LoanService) calculateInstallments(loan *domain.Loan, numInstallments i
 
 // LoanCalculation represents the result of a loan calculation
 type LoanCalculation struct {
-	LoanAmount        float64                   `json:"loan_amount"`
-	InterestRate      float64                   `json:"interest_rate"`
-	InterestAmount    float64                   `json:"interest_amount"`
-	TotalAmount       float64                   `json:"total_amount"`
-	InstallmentAmount float64                   `json:"installment_amount,omitempty"`
-	Installments      []*domain.LoanInstallment `json:"installments,omitempty"`
+	LoanAmount               float64                   `json:"loan_amount"`
+	DisbursementRounding     float64                   `json:"disbursement_rounding"`
+	InterestRate             float64                   `json:"interest_rate"`
+	InterestMethod           domain.InterestMethod     `json:"interest_method"`
+	InterestAmount           float64                   `json:"interest_amount"` // After any campaign discount
+	CampaignInterestDiscount float64                   `json:"campaign_interest_discount,omitempty"`
+	TotalAmount              float64                   `json:"total_amount"`
+	InstallmentAmount        float64                   `json:"installment_amount,omitempty"`
+	Installments             []*domain.LoanInstallment `json:"installments,omitempty"`
 }
 
 // Calculate calculates loan terms without creating the loan (preview)
@@ -292,49 +1049,201 @@ func (s *LoanService) Calculate(ctx context.Context, input CreateLoanInput) (*Lo
 	if err != nil {
 		return nil, errors.New("item not found")
 	}
+	input = s.applyDefaultRates(ctx, input, item)
 
 	// Validate loan amount doesn't exceed item loan value
 	if input.LoanAmount > item.LoanValue {
 		return nil, fmt.Errorf("loan amount cannot exceed item loan value (max: %.2f)", item.LoanValue)
 	}
 
-	// Calculate interest
-	interestAmount := input.LoanAmount * (input.InterestRate / 100)
-	totalAmount := input.LoanAmount + interestAmount
+	var disbursementRounding float64
+	input.LoanAmount, disbursementRounding = s.roundCashDisbursement(ctx, input, item.LoanValue)
+
+	// The quote uses the same terms the loan would be created with
+	loan := s.newLoanTerms(ctx, input, domain.Today())
+	if err := s.GetLimits(ctx, input.BranchID, item.CategoryID).Validate(loan.LoanAmount, loan.LoanTermDays); err != nil {
+		return nil, err
+	}
 
 	result := &LoanCalculation{
-		LoanAmount:     input.LoanAmount,
-		InterestRate:   input.InterestRate,
-		InterestAmount: interestAmount,
-		TotalAmount:    totalAmount,
+		LoanAmount:               loan.LoanAmount,
+		DisbursementRounding:     disbursementRounding,
+		InterestRate:             loan.InterestRate,
+		InterestMethod:           loan.InterestMethod,
+		InterestAmount:           loan.InterestAmount,
+		CampaignInterestDiscount: loan.CampaignInterestDiscount,
+		TotalAmount:              loan.TotalAmount,
 	}
 
 	// Calculate installments if applicable
 	if input.PaymentPlanType == "installments" && input.NumberOfInstallments > 0 {
-		result.InstallmentAmount = totalAmount / float64(input.NumberOfInstallments)
-
-		// Create preview installments (without loan ID)
-		startDate := time.Now()
-		installments := make([]*domain.LoanInstallment, input.NumberOfInstallments)
-		principalPerInstallment := input.LoanAmount / float64(input.NumberOfInstallments)
-		interestPerInstallment := interestAmount / float64(input.NumberOfInstallments)
-
-		for i := 0; i < input.NumberOfInstallments; i++ {
-			dueDate := startDate.AddDate(0, i+1, 0)
-			installments[i] = &domain.LoanInstallment{
-				InstallmentNumber: i + 1,
-				DueDate:           dueDate,
-				PrincipalAmount:   principalPerInstallment,
-				InterestAmount:    interestPerInstallment,
-				TotalAmount:       principalPerInstallment + interestPerInstallment,
-			}
-		}
-		result.Installments = installments
+		result.InstallmentAmount = loan.TotalAmount / float64(input.NumberOfInstallments)
+		result.Installments = s.calculateInstallments(loan, input.NumberOfInstallments)
 	}
 
 	return result, nil
 }
 
+// Loan quote display modes
+const (
+	// LoanQuoteModeItemized shows the principal and interest separately
+	LoanQuoteModeItemized = "itemized"
+	// LoanQuoteModeAllIn shows only what the customer pays in total
+	LoanQuoteModeAllIn = "all_in"
+)
+
+// SettingLoanQuoteMode is the display mode quotes use when none is requested
+const SettingLoanQuoteMode = "loan_quote_mode"
+
+var ErrInvalidLoanQuoteMode = errors.New("invalid quote mode, expected itemized or all_in")
+
+// LoanQuoteInput represents the terms of a loan to quote. Rate, term and grace
+// period left empty come from the branch defaults.
+type LoanQuoteInput struct {
+	BranchID             int64   `query:"branch"`
+	Amount               float64 `query:"amount"`
+	TermDays             int     `query:"term"`
+	InterestRate         float64 `query:"rate"`
+	InterestMethod       string  `query:"interest_method"`
+	GracePeriodDays      *int    `query:"grace_period_days"`
+	PaymentPlanType      string  `query:"payment_plan_type"`
+	NumberOfInstallments int     `query:"number_of_installments"`
+	LateFeeRate          float64 `query:"late_fee_rate"`
+	Mode                 string  `query:"mode"`
+}
+
+// LoanQuote is the cost of a loan before it is created. In all-in mode the
+// interest is folded into the totals and the breakdown fields are left out.
+type LoanQuote struct {
+	Mode                     string            `json:"mode"`
+	LoanAmount               float64           `json:"loan_amount"`
+	InterestRate             *float64          `json:"interest_rate,omitempty"`
+	InterestAmount           *float64          `json:"interest_amount,omitempty"`
+	CampaignInterestDiscount *float64          `json:"campaign_interest_discount,omitempty"`
+	TotalAmount              float64           `json:"total_amount"`
+	LateFeeRate              float64           `json:"late_fee_rate"`
+	PaymentPlanType          string            `json:"payment_plan_type"`
+	LoanTermDays             int               `json:"loan_term_days"`
+	GracePeriodDays          int               `json:"grace_period_days"`
+	StartDate                domain.Date       `json:"start_date"`
+	DueDate                  domain.Date       `json:"due_date"`
+	GracePeriodEnd           domain.Date       `json:"grace_period_end"`
+	Schedule                 []LoanQuotePeriod `json:"schedule"`
+}
+
+// LoanQuotePeriod is one payment of a quoted loan
+type LoanQuotePeriod struct {
+	Number          int         `json:"number"`
+	DueDate         domain.Date `json:"due_date"`
+	PrincipalAmount *float64    `json:"principal_amount,omitempty"`
+	InterestAmount  *float64    `json:"interest_amount,omitempty"`
+	TotalAmount     float64     `json:"total_amount"`
+}
+
+// Quote works out the cost of a loan without creating it or reserving an item,
+// using the same calculation as Create
+func (s *LoanService) Quote(ctx context.Context, input LoanQuoteInput) (*LoanQuote, error) {
+	if input.Amount <= 0 {
+		return nil, errors.New("amount must be greater than 0")
+	}
+
+	mode := input.Mode
+	if mode == "" {
+		mode = getSettingString(ctx, s.settingRepo, SettingLoanQuoteMode, &input.BranchID, LoanQuoteModeItemized)
+	}
+	if mode != LoanQuoteModeItemized && mode != LoanQuoteModeAllIn {
+		return nil, ErrInvalidLoanQuoteMode
+	}
+
+	// Fill the terms left empty from the branch defaults
+	if s.branchRepo != nil && input.BranchID != 0 {
+		branch, err := s.branchRepo.GetByID(ctx, input.BranchID)
+		if err != nil {
+			return nil, ErrBranchNotFound
+		}
+		if input.InterestRate == 0 {
+			input.InterestRate = branch.DefaultInterestRate
+		}
+		if input.TermDays == 0 {
+			input.TermDays = branch.DefaultLoanTermDays
+		}
+		if input.GracePeriodDays == nil {
+			input.GracePeriodDays = &branch.DefaultGracePeriod
+		}
+	}
+
+	loanInput := CreateLoanInput{
+		BranchID:             input.BranchID,
+		LoanAmount:           input.Amount,
+		InterestRate:         input.InterestRate,
+		InterestMethod:       input.InterestMethod,
+		LoanTermDays:         input.TermDays,
+		PaymentPlanType:      input.PaymentPlanType,
+		NumberOfInstallments: input.NumberOfInstallments,
+		GracePeriodDays:      input.GracePeriodDays,
+	}
+	if loanInput.PaymentPlanType == "" {
+		loanInput.PaymentPlanType = string(domain.PaymentPlanSingle)
+	}
+	if input.LateFeeRate > 0 {
+		loanInput.LateFeeRate = &input.LateFeeRate
+	}
+	if loanInput.LoanTermDays <= 0 && loanInput.NumberOfInstallments <= 0 {
+		return nil, errors.New("loan term days is required")
+	}
+
+	loan := s.newLoanTerms(ctx, loanInput, domain.Today())
+	if err := s.GetLimits(ctx, input.BranchID, nil).Validate(loan.LoanAmount, loan.LoanTermDays); err != nil {
+		return nil, err
+	}
+
+	quote := &LoanQuote{
+		Mode:            mode,
+		LoanAmount:      loan.LoanAmount,
+		TotalAmount:     loan.TotalAmount,
+		LateFeeRate:     loan.LateFeeRate,
+		PaymentPlanType: string(loan.PaymentPlanType),
+		LoanTermDays:    loan.LoanTermDays,
+		GracePeriodDays: loan.GracePeriodDays,
+		StartDate:       loan.StartDate,
+		DueDate:         loan.DueDate,
+		GracePeriodEnd:  loan.GracePeriodEnd(),
+	}
+	itemized := mode == LoanQuoteModeItemized
+	if itemized {
+		quote.InterestRate = &loan.InterestRate
+		quote.InterestAmount = &loan.InterestAmount
+		if loan.CampaignID != nil {
+			quote.CampaignInterestDiscount = &loan.CampaignInterestDiscount
+		}
+	}
+
+	installments := []*domain.LoanInstallment{{
+		InstallmentNumber: 1,
+		DueDate:           loan.DueDate.Time,
+		PrincipalAmount:   loan.LoanAmount,
+		InterestAmount:    loan.InterestAmount,
+		TotalAmount:       loan.TotalAmount,
+	}}
+	if loanInput.PaymentPlanType == "installments" && loanInput.NumberOfInstallments > 0 {
+		installments = s.calculateInstallments(loan, loanInput.NumberOfInstallments)
+	}
+	for _, inst := range installments {
+		period := LoanQuotePeriod{
+			Number:      inst.InstallmentNumber,
+			DueDate:     domain.DateFromTime(inst.DueDate),
+			TotalAmount: inst.TotalAmount,
+		}
+		if itemized {
+			period.PrincipalAmount = &inst.PrincipalAmount
+			period.InterestAmount = &inst.InterestAmount
+		}
+		quote.Schedule = append(quote.Schedule, period)
+	}
+
+	return quote, nil
+}
+
 // GetByID retrieves a loan by ID
 func (s *LoanService) GetByID(ctx context.Context, id int64) (*domain.Loan, error) {
 	loan, err := s.loanRepo.GetByID(ctx, id)
@@ -379,16 +1288,82 @@ func (s *LoanService) GetInstallments(ctx context.Context, loanID int64) ([]*dom
 	return s.loanRepo.GetInstallments(ctx, loanID)
 }
 
+// SettingLoanLegalHoldTag is the loan tag that blocks confiscation
+const SettingLoanLegalHoldTag = "loan_legal_hold_tag"
+
+// ErrLoanOnLegalHold is returned when confiscating a loan on legal hold
+var ErrLoanOnLegalHold = errors.New("loan is on legal hold and cannot be confiscated")
+
+// LegalHoldTag returns the tag that puts a branch's loans on legal hold
+func LegalHoldTag(ctx context.Context, settingRepo repository.SettingRepository, branchID int64) string {
+	return getSettingString(ctx, settingRepo, SettingLoanLegalHoldTag, &branchID, domain.LoanTagLegalHold)
+}
+
+// AddTag tags a loan; tags are stored trimmed and in lower case
+func (s *LoanService) AddTag(ctx context.Context, loanID int64, tag string) (*domain.Loan, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return nil, errors.New("tag is required")
+	}
+
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, errors.New("loan not found")
+	}
+	if loan.HasTag(tag) {
+		return loan, nil
+	}
+
+	tags := append(loan.Tags, tag)
+	if err := s.loanRepo.SetTags(ctx, loan.ID, tags); err != nil {
+		return nil, fmt.Errorf("failed to tag loan: %w", err)
+	}
+	loan.Tags = tags
+
+	return loan, nil
+}
+
+// RemoveTag removes a tag from a loan
+func (s *LoanService) RemoveTag(ctx context.Context, loanID int64, tag string) (*domain.Loan, error) {
+	tag = strings.TrimSpace(tag)
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, errors.New("loan not found")
+	}
+	if !loan.HasTag(tag) {
+		return loan, nil
+	}
+
+	tags := make([]string, 0, len(loan.Tags))
+	for _, t := range loan.Tags {
+		if !strings.EqualFold(t, tag) {
+			tags = append(tags, t)
+		}
+	}
+	if err := s.loanRepo.SetTags(ctx, loan.ID, tags); err != nil {
+		return nil, fmt.Errorf("failed to untag loan: %w", err)
+	}
+	loan.Tags = tags
+
+	return loan, nil
+}
+
 // RenewLoanInput represents renew loan request data
 type RenewLoanInput struct {
 	LoanID          int64   `json:"loan_id" validate:"required"`
 	NewTermDays     int     `json:"new_term_days" validate:"required,gt=0"`
 	NewInterestRate float64 `json:"new_interest_rate" validate:"gte=0"`
-	PayInterest     bool    `json:"pay_interest"`
+	InterestPaid    float64 `json:"interest_paid" validate:"gte=0"` // Interest collected at the renewal
 	UpdatedBy       int64   `json:"-"`
 }
 
-// Renew renews an existing loan
+// ErrRenewalInterestUnpaid is returned when a loan is renewed without settling
+// its outstanding interest
+var ErrRenewalInterestUnpaid = errors.New("outstanding interest must be paid before renewal")
+
+// Renew closes a loan as renewed and opens a new loan on the same item for the
+// remaining principal, with a fresh term and interest. The outstanding interest
+// must be paid with the renewal; unpaid late fees carry over to the new loan.
 func (s *LoanService) Renew(ctx context.Context, input RenewLoanInput) (*domain.Loan, error) {
 	// Get original loan
 	loan, err := s.loanRepo.GetByID(ctx, input.LoanID)
@@ -400,32 +1375,69 @@ func (s *LoanService) Renew(ctx context.Context, input RenewLoanInput) (*domain.
 		return nil, errors.New("only active or overdue loans can be renewed")
 	}
 
-	// If paying interest, interest must be fully paid
-	if input.PayInterest && loan.InterestRemaining > 0 {
-		return nil, errors.New("interest must be paid before renewal")
+	interestPaid := roundCents(input.InterestPaid)
+	interestOwed := roundCents(loan.InterestRemaining)
+	if interestPaid < interestOwed {
+		return nil, fmt.Errorf("%w: %.2f of %.2f interest paid", ErrRenewalInterestUnpaid, interestPaid, interestOwed)
+	}
+	if interestPaid > interestOwed {
+		return nil, fmt.Errorf("%w: interest paid exceeds the %.2f owed", ErrInvalidAmount, interestOwed)
+	}
+
+	// The interest is received in cash and must go into an open cash session
+	var cashSession *domain.CashSession
+	if interestPaid > 0 && s.cashService != nil {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, input.UpdatedBy, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var payment *domain.Payment
+	if interestPaid > 0 {
+		paymentNumber, err := s.paymentRepo.GenerateNumber(ctx, sequenceResetCadence(ctx, s.settingRepo, loan.BranchID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate payment number: %w", err)
+		}
+		payment = &domain.Payment{
+			PaymentNumber:  paymentNumber,
+			BranchID:       loan.BranchID,
+			LoanID:         loan.ID,
+			CustomerID:     loan.CustomerID,
+			Amount:         interestPaid,
+			InterestAmount: interestPaid,
+			PaymentMethod:  domain.PaymentMethodCash,
+			Status:         domain.PaymentStatusCompleted,
+			PaymentDate:    time.Now(),
+			Notes:          "Pago de intereses por renovación",
+			CreatedBy:      input.UpdatedBy,
+		}
+		if cashSession != nil {
+			payment.CashSessionID = &cashSession.ID
+		}
+		loan.AmountPaid += interestPaid
+		loan.InterestRemaining = 0
 	}
 
 	// Mark old loan as renewed
 	loan.Status = domain.LoanStatusRenewed
 	loan.UpdatedBy = &input.UpdatedBy
-	if err := s.loanRepo.Update(ctx, loan); err != nil {
-		return nil, fmt.Errorf("failed to update original loan: %w", err)
-	}
 
 	// Calculate new interest
 	interestRate := input.NewInterestRate
 	if interestRate == 0 {
 		interestRate = loan.InterestRate
 	}
-	newInterestAmount := loan.PrincipalRemaining * (interestRate / 100)
+	newInterestAmount := roundCents(domain.InterestStrategyFor(loan.InterestMethod).Interest(loan.PrincipalRemaining, interestRate, input.NewTermDays))
 
 	// Generate new loan number
-	loanNumber, err := s.loanRepo.GenerateNumber(ctx)
+	loanNumber, err := s.loanRepo.GenerateNumber(ctx, sequenceResetCadence(ctx, s.settingRepo, loan.BranchID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate loan number: %w", err)
 	}
 
 	// Create new loan
+	startDate := domain.Today()
 	newLoan := &domain.Loan{
 		LoanNumber:             loanNumber,
 		BranchID:               loan.BranchID,
@@ -434,12 +1446,15 @@ func (s *LoanService) Renew(ctx context.Context, input RenewLoanInput) (*domain.
 		LoanAmount:             loan.PrincipalRemaining,
 		InterestRate:           interestRate,
 		InterestAmount:         newInterestAmount,
+		InterestMethod:         loan.InterestMethod,
 		PrincipalRemaining:     loan.PrincipalRemaining,
 		InterestRemaining:      newInterestAmount,
 		TotalAmount:            loan.PrincipalRemaining + newInterestAmount,
 		LateFeeRate:            loan.LateFeeRate,
-		StartDate:              domain.Today(),
-		DueDate:                domain.DateFromTime(time.Now().AddDate(0, 0, input.NewTermDays)),
+		LateFeeAmount:          loan.LateFeeRemaining,
+		LateFeeRemaining:       loan.LateFeeRemaining,
+		StartDate:              startDate,
+		DueDate:                domain.DateFromTime(startDate.AddDate(0, 0, input.NewTermDays)),
 		PaymentPlanType:        loan.PaymentPlanType,
 		LoanTermDays:           input.NewTermDays,
 		RequiresMinimumPayment: loan.RequiresMinimumPayment,
@@ -451,12 +1466,33 @@ func (s *LoanService) Renew(ctx context.Context, input RenewLoanInput) (*domain.
 		CreatedBy:              input.UpdatedBy,
 	}
 
-	if err := s.loanRepo.Create(ctx, newLoan); err != nil {
-		return nil, fmt.Errorf("failed to create renewed loan: %w", err)
+	// The interest payment, its cash receipt, the closed loan and its
+	// successor are saved together
+	var movement *domain.CashMovement
+	if cashSession != nil {
+		movement, err = s.cashService.NewPaymentMovement(ctx, cashSession.ID, payment.Amount, string(domain.PaymentMethodCash), input.UpdatedBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record payment movement: %w", err)
+		}
+	}
+	if err := s.loanRepo.Renew(ctx, loan, newLoan, payment, movement); err != nil {
+		return nil, fmt.Errorf("failed to renew loan: %w", err)
 	}
 
+	// The item stays pawned, now under the new loan
+	s.itemRepo.CreateHistory(ctx, &domain.ItemHistory{
+		ItemID:        loan.ItemID,
+		Action:        domain.ItemHistoryActionLoanRenewed,
+		OldStatus:     string(domain.ItemStatusPawned),
+		NewStatus:     string(domain.ItemStatusPawned),
+		ReferenceType: strPtr("loan"),
+		ReferenceID:   &newLoan.ID,
+		Notes:         fmt.Sprintf("Préstamo %s renovado como %s por %d días", loan.LoanNumber, newLoan.LoanNumber, input.NewTermDays),
+		CreatedBy:     input.UpdatedBy,
+	})
+
 	// Log business event
-	s.businessLogger.LoanRenewed(ctx, newLoan.ID, newLoan.DueDate.Format("2006-01-02"), 0)
+	s.businessLogger.LoanRenewed(ctx, newLoan.ID, newLoan.DueDate.Format("2006-01-02"), interestPaid)
 
 	return newLoan, nil
 }
@@ -472,6 +1508,11 @@ func (s *LoanService) Confiscate(ctx context.Context, loanID int64, updatedBy in
 		return errors.New("only defaulted or overdue loans can be confiscated")
 	}
 
+	// Loans on legal hold are kept out of confiscation, as the overdue job does
+	if loan.HasTag(LegalHoldTag(ctx, s.settingRepo, loan.BranchID)) {
+		return ErrLoanOnLegalHold
+	}
+
 	// Update loan status
 	now := time.Now()
 	loan.Status = domain.LoanStatusConfiscated
@@ -497,9 +1538,293 @@ func (s *LoanService) Confiscate(ctx context.Context, loanID int64, updatedBy in
 		})
 	}
 
+	s.router.emit(ctx, InternalEvent{
+		Event:         domain.InternalEventConfiscation,
+		BranchID:      loan.BranchID,
+		Amount:        loan.RemainingBalance(),
+		Title:         "Préstamo Confiscado",
+		Message:       fmt.Sprintf("Se confiscó la prenda del préstamo %s", loan.LoanNumber),
+		Type:          "warning",
+		ReferenceType: "loan",
+		ReferenceID:   &loan.ID,
+	})
+
 	return nil
 }
 
+// Accounts a write-off is booked to: the loss against each receivable
+const (
+	writeOffLossCode                = "5400" // Pérdidas por Préstamos Incobrables
+	writeOffPrincipalReceivableCode = "1210" // Préstamos por Cobrar
+	writeOffInterestReceivableCode  = "1220" // Intereses por Cobrar
+	writeOffLateFeeReceivableCode   = "1200" // Cuentas por Cobrar
+)
+
+// WriteOff takes an uncollectible defaulted or overdue loan off the books. Its
+// remaining balance is posted as a loss, the loan leaves the open portfolio
+// with the written_off status, the shop keeps the item and the reason and actor
+// are recorded, all at once; a loan is only ever written off once.
+func (s *LoanService) WriteOff(ctx context.Context, loanID int64, reason string, userID int64) (*domain.LoanWriteOff, error) {
+	if s.writeOffRepo == nil {
+		return nil, errors.New("loan write-offs are not enabled")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required to write off a loan", ErrInvalidInput)
+	}
+
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, errors.New("loan not found")
+	}
+	if loan.Status != domain.LoanStatusDefaulted && loan.Status != domain.LoanStatusOverdue {
+		return nil, errors.New("only defaulted or overdue loans can be written off")
+	}
+
+	writeOff := &domain.LoanWriteOff{
+		LoanID:       loan.ID,
+		BranchID:     loan.BranchID,
+		Principal:    roundCents(loan.PrincipalRemaining),
+		Interest:     roundCents(loan.InterestRemaining),
+		LateFees:     roundCents(loan.LateFeeRemaining),
+		Reason:       reason,
+		WrittenOffBy: userID,
+	}
+	writeOff.Amount = roundCents(writeOff.Principal + writeOff.Interest + writeOff.LateFees)
+
+	var entry *domain.AccountingEntry
+	if writeOff.Amount > 0 {
+		entry, err = s.writeOffEntry(ctx, loan, writeOff, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.writeOffRepo.Create(ctx, writeOff, entry); err != nil {
+		if errors.Is(err, repository.ErrLoanAlreadyWrittenOff) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to record write-off: %w", err)
+	}
+
+	customer, _ := s.customerRepo.GetByID(ctx, loan.CustomerID)
+	if customer != nil {
+		totalDefaulted := customer.TotalDefaulted + writeOff.Amount
+		s.customerRepo.UpdateCreditInfo(ctx, customer.ID, repository.CustomerCreditUpdate{
+			TotalDefaulted: &totalDefaulted,
+		})
+	}
+
+	s.logger.Info().
+		Int64("loan_id", loan.ID).
+		Str("loan_number", loan.LoanNumber).
+		Float64("amount", writeOff.Amount).
+		Int64("user_id", userID).
+		Msg("Loan written off")
+
+	loan.Status = domain.LoanStatusWrittenOff
+	writeOff.Loan = loan
+	return writeOff, nil
+}
+
+// writeOffEntry builds the posted entry booking the loss of a write-off,
+// debiting the loss account and crediting the receivables the balance was owed on
+func (s *LoanService) writeOffEntry(ctx context.Context, loan *domain.Loan, writeOff *domain.LoanWriteOff, userID int64) (*domain.AccountingEntry, error) {
+	account := func(code string) (*domain.Account, error) {
+		acc, err := s.accountRepo.GetByCode(ctx, code)
+		if err != nil || acc == nil {
+			return nil, fmt.Errorf("account %s not found", code)
+		}
+		return acc, nil
+	}
+
+	loss, err := account(writeOffLossCode)
+	if err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("Castigo del préstamo %s", loan.LoanNumber)
+	lines := []*domain.AccountingEntryLine{
+		{AccountID: loss.ID, EntryType: domain.EntryTypeDebit, Amount: writeOff.Amount, Description: description},
+	}
+	for _, part := range []struct {
+		amount float64
+		code   string
+	}{
+		{writeOff.Principal, writeOffPrincipalReceivableCode},
+		{writeOff.Interest, writeOffInterestReceivableCode},
+		{writeOff.LateFees, writeOffLateFeeReceivableCode},
+	} {
+		if part.amount <= 0 {
+			continue
+		}
+		receivable, err := account(part.code)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, &domain.AccountingEntryLine{
+			AccountID: receivable.ID, EntryType: domain.EntryTypeCredit, Amount: part.amount, Description: description,
+		})
+	}
+
+	number, err := s.entryRepo.GenerateEntryNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate entry number: %w", err)
+	}
+	return &domain.AccountingEntry{
+		EntryNumber:   number,
+		BranchID:      loan.BranchID,
+		EntryDate:     time.Now(),
+		Description:   description + ": " + writeOff.Reason,
+		ReferenceType: domain.AccountingReferenceLoanWriteOff,
+		ReferenceID:   &loan.ID,
+		TotalDebit:    writeOff.Amount,
+		TotalCredit:   writeOff.Amount,
+		IsPosted:      true,
+		PostedBy:      &userID,
+		Lines:         lines,
+		CreatedBy:     &userID,
+	}, nil
+}
+
+// ErrItemAlreadySold is returned when reinstating a loan whose item was sold
+var ErrItemAlreadySold = errors.New("item has already been sold, the loan cannot be reinstated")
+
+// ReinstateConfiscated reverses a confiscation when the customer comes back and
+// pays the loan off before the item is sold. The payoff is received in cash,
+// the loan is closed as paid and the item goes back to the customer.
+func (s *LoanService) ReinstateConfiscated(ctx context.Context, loanID int64, userID int64) (*PaymentResult, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, errors.New("loan not found")
+	}
+
+	if loan.Status != domain.LoanStatusConfiscated {
+		return nil, errors.New("only confiscated loans can be reinstated")
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, loan.ItemID)
+	if err != nil {
+		return nil, ErrItemNotFound
+	}
+	if item.Status == domain.ItemStatusSold {
+		return nil, ErrItemAlreadySold
+	}
+	if !item.IsOnPremises() {
+		return nil, fmt.Errorf("%w: item is not at the branch (status %s)", ErrInvalidStatus, item.Status)
+	}
+
+	// The payoff is a cash receipt and must go into an open cash session
+	var cashSession *domain.CashSession
+	if s.cashService != nil {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, userID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paymentNumber, err := s.paymentRepo.GenerateNumber(ctx, sequenceResetCadence(ctx, s.settingRepo, loan.BranchID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate payment number: %w", err)
+	}
+
+	now := time.Now()
+	payoff := loan.RemainingBalance()
+	payment := &domain.Payment{
+		PaymentNumber:   paymentNumber,
+		BranchID:        loan.BranchID,
+		LoanID:          loan.ID,
+		CustomerID:      loan.CustomerID,
+		Amount:          payoff,
+		PrincipalAmount: loan.PrincipalRemaining,
+		InterestAmount:  loan.InterestRemaining,
+		LateFeeAmount:   loan.LateFeeRemaining,
+		PaymentMethod:   domain.PaymentMethodCash,
+		Status:          domain.PaymentStatusCompleted,
+		PaymentDate:     now,
+		Notes:           "Pago total para revertir confiscación",
+		CreatedBy:       userID,
+	}
+	if cashSession != nil {
+		payment.CashSessionID = &cashSession.ID
+	}
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	loanBefore := *loan
+	loan.AmountPaid += payoff
+	loan.PrincipalRemaining = 0
+	loan.InterestRemaining = 0
+	loan.LateFeeRemaining = 0
+	loan.Status = domain.LoanStatusPaid
+	loan.PaidDate = &now
+	loan.ConfiscatedDate = nil
+	loan.UpdatedBy = &userID
+	if loan.Notes != "" {
+		loan.Notes += "\n"
+	}
+	loan.Notes += fmt.Sprintf("Confiscación revertida el %s con el pago %s", now.Format("2006-01-02"), payment.PaymentNumber)
+
+	if err := s.loanRepo.Update(ctx, loan); err != nil {
+		return nil, fmt.Errorf("failed to update loan: %w", err)
+	}
+
+	// A payoff whose cash never reached the drawer leaves the loan confiscated
+	if cashSession != nil {
+		if err := s.cashService.RecordPaymentMovement(ctx, cashSession.ID, payment.ID, payment.Amount, string(domain.PaymentMethodCash), userID); err != nil {
+			s.logger.Error().Err(err).Int64("payment_id", payment.ID).Int64("session_id", cashSession.ID).Msg("Failed to record payment cash movement, undoing reinstatement")
+			if uerr := s.loanRepo.Update(ctx, &loanBefore); uerr != nil {
+				s.logger.Error().Err(uerr).Int64("loan_id", loan.ID).Msg("Failed to restore confiscated loan after payment failure")
+			}
+			payment.Status = domain.PaymentStatusFailed
+			if uerr := s.paymentRepo.Update(ctx, payment); uerr != nil {
+				s.logger.Error().Err(uerr).Int64("payment_id", payment.ID).Msg("Failed to mark payment as failed")
+			}
+			return nil, fmt.Errorf("failed to record payment movement: %w", err)
+		}
+	}
+
+	if loan.PaymentPlanType == "installments" {
+		installments, _ := s.loanRepo.GetInstallments(ctx, loan.ID)
+		for _, installment := range installments {
+			if installment.IsPaid {
+				continue
+			}
+			installment.AmountPaid = installment.TotalAmount
+			installment.IsPaid = true
+			installment.PaidDate = &now
+			if err := s.loanRepo.UpdateInstallment(ctx, installment); err != nil {
+				s.logger.Error().Err(err).Int64("installment_id", installment.ID).Msg("Failed to settle installment")
+			}
+		}
+	}
+
+	// Return the item to the customer
+	if err := s.itemRepo.UpdateStatus(ctx, loan.ItemID, domain.ItemStatusAvailable); err != nil {
+		return nil, fmt.Errorf("failed to update item status: %w", err)
+	}
+
+	// Reverse the defaulted amount recorded at confiscation and count the payoff
+	customer, _ := s.customerRepo.GetByID(ctx, loan.CustomerID)
+	if customer != nil {
+		totalDefaulted := math.Max(customer.TotalDefaulted-payoff, 0)
+		totalPaid := customer.TotalPaid + payoff
+		s.customerRepo.UpdateCreditInfo(ctx, customer.ID, repository.CustomerCreditUpdate{
+			TotalDefaulted: &totalDefaulted,
+			TotalPaid:      &totalPaid,
+		})
+	}
+
+	s.businessLogger.ItemRedeemed(ctx, loan.ItemID, loan.ID)
+
+	return &PaymentResult{
+		Payment:          payment,
+		Loan:             loan,
+		IsFullyPaid:      true,
+		RemainingBalance: 0,
+	}, nil
+}
+
 // GetOverdueLoans retrieves overdue loans for a branch
 func (s *LoanService) GetOverdueLoans(ctx context.Context, branchID int64) ([]*domain.Loan, error) {
 	loans, err := s.loanRepo.GetOverdueLoans(ctx, branchID)
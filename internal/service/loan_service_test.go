@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -10,7 +11,9 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
 )
@@ -20,16 +23,43 @@ func adultBirthDate() *time.Time {
 	return &t
 }
 
-func setupLoanService() (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository, *mocks.MockPaymentRepository) {
+// newLoanRepositoryMock returns a loan repository mock where no item backs an
+// active loan yet
+func newLoanRepositoryMock() *mocks.MockLoanRepository {
 	loanRepo := new(mocks.MockLoanRepository)
+	loanRepo.On("GetActiveByItemID", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return loanRepo
+}
+
+func setupLoanService() (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository, *mocks.MockPaymentRepository) {
+	loanRepo := newLoanRepositoryMock()
 	itemRepo := new(mocks.MockItemRepository)
 	customerRepo := new(mocks.MockCustomerRepository)
 	paymentRepo := new(mocks.MockPaymentRepository)
+	categoryRepo := new(mocks.MockCategoryRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
 	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
-	service := NewLoanService(loanRepo, itemRepo, customerRepo, paymentRepo, logger)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, paymentRepo, categoryRepo, nil, nil, settingRepo, nil, nil, logger)
 	return service, loanRepo, itemRepo, customerRepo, paymentRepo
 }
 
+func setupLoanServiceWithSettings(settings map[string]interface{}) (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository, *mocks.MockCategoryRepository) {
+	loanRepo := newLoanRepositoryMock()
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	categoryRepo := new(mocks.MockCategoryRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	for key, value := range settings {
+		settingRepo.On("Get", mock.Anything, key, mock.Anything).Return(&domain.Setting{Key: key, Value: value}, nil).Maybe()
+	}
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, paymentRepo, categoryRepo, nil, nil, settingRepo, nil, nil, logger)
+	return service, loanRepo, itemRepo, customerRepo, categoryRepo
+}
+
 // --- Create tests ---
 
 func TestLoanService_Create_Success(t *testing.T) {
@@ -54,7 +84,7 @@ func TestLoanService_Create_Success(t *testing.T) {
 
 	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
 	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
-	loanRepo.On("GenerateNumber", ctx).Return("LN-000001", nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000001", nil)
 	loanRepo.On("BeginTx", ctx).Return(tx, nil)
 	loanRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.Loan")).Return(nil)
 	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusCollateral).Return(nil)
@@ -88,6 +118,53 @@ func TestLoanService_Create_Success(t *testing.T) {
 	tx.AssertExpectations(t)
 }
 
+func TestLoanService_Create_HighValueMessageUsesBranchCurrency(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+	router, m := setupNotificationRouter([]domain.NotificationRoute{
+		{Event: domain.InternalEventHighValueLoan, Roles: []string{domain.RoleManager}, MinAmount: 5000},
+	})
+	service.SetNotificationRouter(router)
+	moneyFormat, branchRepo := setupMoneyFormatService(false)
+	service.SetMoneyFormat(moneyFormat)
+	ctx := context.Background()
+	roleID, active := int64(3), true
+
+	tx := new(mocks.MockTransaction)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 10000}, nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000040", nil)
+	loanRepo.On("BeginTx", ctx).Return(tx, nil)
+	loanRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusCollateral).Return(nil)
+	tx.On("Commit").Return(nil)
+	tx.On("Rollback").Return(nil)
+	customerRepo.On("UpdateCreditInfo", ctx, int64(1), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
+	branchRepo.On("GetByID", ctx, int64(2)).Return(&domain.Branch{ID: 2, Currency: "USD"}, nil)
+
+	m.roleRepo.On("GetByName", ctx, domain.RoleManager).Return(&domain.Role{ID: roleID, Name: domain.RoleManager}, nil)
+	m.userRepo.On("List", ctx, repository.UserListParams{RoleID: &roleID, IsActive: &active, PaginationParams: repository.PaginationParams{Page: 1, PerPage: routeUserPageSize}}).
+		Return(&repository.PaginatedResult[domain.User]{Data: []domain.User{{ID: 11}}}, nil)
+	var created []*domain.InternalNotification
+	m.internalRepo.On("CreateBulk", ctx, mock.AnythingOfType("[]*domain.InternalNotification")).Run(func(args mock.Arguments) {
+		created = args.Get(1).([]*domain.InternalNotification)
+	}).Return(nil)
+
+	_, err := service.Create(ctx, CreateLoanInput{
+		CustomerID:      1,
+		ItemID:          1,
+		BranchID:        2,
+		LoanAmount:      8000,
+		InterestRate:    10,
+		LoanTermDays:    30,
+		PaymentPlanType: "single",
+		CreatedBy:       1,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	assert.Equal(t, "Se otorgó el préstamo LN-000040 por $8,000.00", created[0].Message)
+}
+
 func TestLoanService_Create_WithInstallments(t *testing.T) {
 	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
 	ctx := context.Background()
@@ -107,11 +184,11 @@ func TestLoanService_Create_WithInstallments(t *testing.T) {
 
 	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
 	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
-	loanRepo.On("GenerateNumber", ctx).Return("LN-000002", nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000002", nil)
 	loanRepo.On("BeginTx", ctx).Return(tx, nil)
 	loanRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.Loan")).Return(nil)
 	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusCollateral).Return(nil)
-	loanRepo.On("CreateInstallments", ctx, mock.AnythingOfType("[]*domain.LoanInstallment")).Return(nil)
+	loanRepo.On("CreateInstallmentsTx", ctx, mock.Anything, mock.AnythingOfType("[]*domain.LoanInstallment")).Return(nil)
 	tx.On("Commit").Return(nil)
 	tx.On("Rollback").Return(nil)
 	customerRepo.On("UpdateCreditInfo", ctx, int64(1), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
@@ -205,6 +282,129 @@ func TestLoanService_Create_ItemNotAvailable(t *testing.T) {
 	assert.Equal(t, "item is not available for loan", err.Error())
 }
 
+func TestLoanService_Create_ItemAlreadyBacksActiveLoan(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, new(mocks.MockPaymentRepository), new(mocks.MockCategoryRepository), nil, nil, nil, nil, nil, logger)
+	ctx := context.Background()
+
+	// The item was left available, but an active loan already references it
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	existing := &domain.Loan{ID: 7, LoanNumber: "LN-000007", ItemID: 1, Status: domain.LoanStatusActive}
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	loanRepo.On("GetActiveByItemID", ctx, int64(1)).Return(existing, nil)
+
+	result, err := service.Create(ctx, CreateLoanInput{
+		BranchID:        1,
+		CustomerID:      1,
+		ItemID:          1,
+		LoanAmount:      500,
+		InterestRate:    10,
+		LoanTermDays:    30,
+		PaymentPlanType: "single",
+		CreatedBy:       1,
+	})
+
+	assert.ErrorIs(t, err, ErrItemHasActiveLoan)
+	assert.Contains(t, err.Error(), "LN-000007")
+	assert.Nil(t, result)
+	loanRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// expectPawnedItems makes the loan repository report a customer's open loans
+func expectPawnedItems(loanRepo *mocks.MockLoanRepository, customerID int64, active, overdue int) {
+	for status, total := range map[domain.LoanStatus]int{domain.LoanStatusActive: active, domain.LoanStatusOverdue: overdue, domain.LoanStatusPendingAuthorization: 0} {
+		loanRepo.On("List", mock.Anything, mock.MatchedBy(func(params repository.LoanListParams) bool {
+			return params.CustomerID != nil && *params.CustomerID == customerID && params.Status != nil && *params.Status == status
+		})).Return(&repository.PaginatedResult[domain.Loan]{Total: total}, nil)
+	}
+}
+
+func TestLoanService_Create_PawnedItemLimit(t *testing.T) {
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	input := CreateLoanInput{
+		CustomerID:      1,
+		ItemID:          1,
+		BranchID:        1,
+		LoanAmount:      500,
+		InterestRate:    10,
+		LoanTermDays:    30,
+		PaymentPlanType: "single",
+		CreatedBy:       1,
+	}
+
+	setup := func(active, overdue int) (*LoanService, *mocks.MockLoanRepository) {
+		service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+			SettingMaxPawnedItemsPerCustomer: float64(2),
+		})
+		tx := new(mocks.MockTransaction)
+		customerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil)
+		itemRepo.On("GetByID", mock.Anything, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+		expectPawnedItems(loanRepo, 1, active, overdue)
+		loanRepo.On("GenerateNumber", mock.Anything, mock.Anything).Return("LN-000001", nil).Maybe()
+		loanRepo.On("BeginTx", mock.Anything).Return(tx, nil).Maybe()
+		loanRepo.On("CreateTx", mock.Anything, tx, mock.AnythingOfType("*domain.Loan")).Return(nil).Maybe()
+		itemRepo.On("UpdateStatus", mock.Anything, int64(1), domain.ItemStatusCollateral).Return(nil).Maybe()
+		customerRepo.On("UpdateCreditInfo", mock.Anything, int64(1), mock.Anything).Return(nil).Maybe()
+		tx.On("Commit").Return(nil).Maybe()
+		tx.On("Rollback").Return(nil).Maybe()
+		return service, loanRepo
+	}
+
+	t.Run("blocked at the cap", func(t *testing.T) {
+		service, loanRepo := setup(1, 1)
+
+		result, err := service.Create(context.Background(), input)
+
+		assert.ErrorIs(t, err, ErrPawnedItemLimitExceeded)
+		assert.Contains(t, err.Error(), "2 of 2")
+		assert.Nil(t, result)
+		loanRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("override succeeds", func(t *testing.T) {
+		service, _ := setup(1, 1)
+
+		overridden := input
+		overridden.OverrideItemLimit = true
+		result, err := service.Create(context.Background(), overridden)
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Notes, "Límite de artículos empeñados por cliente excedido con autorización")
+	})
+
+	t.Run("returning an item frees capacity", func(t *testing.T) {
+		// One of the two loans was paid off and its item returned
+		service, _ := setup(1, 0)
+
+		capacity, err := service.GetPawnedItemCapacity(context.Background(), 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, capacity.PawnedItems)
+		require.NotNil(t, capacity.Remaining)
+		assert.Equal(t, 1, *capacity.Remaining)
+
+		result, err := service.Create(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.NotContains(t, result.Notes, "Límite de artículos")
+	})
+}
+
+func TestLoanService_GetPawnedItemCapacity_NoCap(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	expectPawnedItems(loanRepo, 4, 3, 1)
+
+	capacity, err := service.GetPawnedItemCapacity(context.Background(), 4, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, &PawnedItemCapacity{CustomerID: 4, PawnedItems: 4}, capacity)
+}
+
 func TestLoanService_Create_LoanExceedsItemValue(t *testing.T) {
 	service, _, itemRepo, customerRepo, _ := setupLoanService()
 	ctx := context.Background()
@@ -233,15 +433,15 @@ func TestLoanService_Create_GenerateNumberError(t *testing.T) {
 
 	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
 	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
-	loanRepo.On("GenerateNumber", ctx).Return("", errors.New("db error"))
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("", errors.New("db error"))
 
-	input := CreateLoanInput{CustomerID: 1, ItemID: 1, LoanAmount: 500, PaymentPlanType: "single"}
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single"}
 
 	result, err := service.Create(ctx, input)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Equal(t, "failed to generate loan number", err.Error())
+	assert.Equal(t, "failed to generate loan number: db error", err.Error())
 }
 
 func TestLoanService_Create_BeginTxError(t *testing.T) {
@@ -253,7 +453,7 @@ func TestLoanService_Create_BeginTxError(t *testing.T) {
 
 	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
 	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
-	loanRepo.On("GenerateNumber", ctx).Return("LN-000001", nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000001", nil)
 	loanRepo.On("BeginTx", ctx).Return(nil, errors.New("tx error"))
 
 	input := CreateLoanInput{CustomerID: 1, ItemID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single"}
@@ -275,7 +475,7 @@ func TestLoanService_Create_WithMinimumPayment(t *testing.T) {
 
 	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
 	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
-	loanRepo.On("GenerateNumber", ctx).Return("LN-000003", nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000003", nil)
 	loanRepo.On("BeginTx", ctx).Return(tx, nil)
 	loanRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.Loan")).Return(nil)
 	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusCollateral).Return(nil)
@@ -283,6 +483,7 @@ func TestLoanService_Create_WithMinimumPayment(t *testing.T) {
 	tx.On("Rollback").Return(nil)
 	customerRepo.On("UpdateCreditInfo", ctx, int64(1), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
 
+	requiresMinimum := true
 	input := CreateLoanInput{
 		CustomerID:             1,
 		ItemID:                 1,
@@ -291,7 +492,7 @@ func TestLoanService_Create_WithMinimumPayment(t *testing.T) {
 		InterestRate:           10,
 		LoanTermDays:           60,
 		PaymentPlanType:        "minimum_payment",
-		RequiresMinimumPayment: true,
+		RequiresMinimumPayment: &requiresMinimum,
 		MinimumPaymentAmount:   100,
 		CreatedBy:              1,
 	}
@@ -304,435 +505,2398 @@ func TestLoanService_Create_WithMinimumPayment(t *testing.T) {
 	assert.NotNil(t, result.NextPaymentDueDate)
 }
 
-// --- GetByID tests ---
+// --- Limit tests ---
 
-func TestLoanService_GetByID_Success(t *testing.T) {
-	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
-	ctx := context.Background()
+func expectLoanCreation(ctx context.Context, loanRepo *mocks.MockLoanRepository, itemRepo *mocks.MockItemRepository, customerRepo *mocks.MockCustomerRepository) {
+	tx := new(mocks.MockTransaction)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000010", nil)
+	loanRepo.On("BeginTx", ctx).Return(tx, nil)
+	loanRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusCollateral).Return(nil)
+	tx.On("Commit").Return(nil)
+	tx.On("Rollback").Return(nil)
+	customerRepo.On("UpdateCreditInfo", ctx, int64(1), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
+}
 
-	loan := &domain.Loan{
-		ID:         1,
-		LoanNumber: "LN-000001",
-		Status:     domain.LoanStatusActive,
-		CustomerID: 10,
-		ItemID:     20,
-	}
+func TestLoanService_Create_TermOverMaxRejected(t *testing.T) {
+	service, _, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingMaxLoanTermDays: float64(90),
+	})
+	ctx := context.Background()
 
-	customer := &domain.Customer{ID: 10, FirstName: "John", LastName: "Doe"}
-	item := &domain.Item{ID: 20, Name: "iPhone 15"}
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
 
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	customerRepo.On("GetByID", ctx, int64(10)).Return(customer, nil)
-	itemRepo.On("GetByID", ctx, int64(20)).Return(item, nil)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 91, PaymentPlanType: "single"}
 
-	result, err := service.GetByID(ctx, 1)
+	result, err := service.Create(ctx, input)
 
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "LN-000001", result.LoanNumber)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "loan term cannot exceed 90 days", err.Error())
 }
 
-func TestLoanService_GetByID_NotFound(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_AmountUnderMinRejected(t *testing.T) {
+	service, _, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingMinLoanAmount: float64(100),
+	})
 	ctx := context.Background()
 
-	loanRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
 
-	result, err := service.GetByID(ctx, 999)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 99.99, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single"}
+
+	result, err := service.Create(ctx, input)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
+	assert.Equal(t, "loan amount must be at least 100.00", err.Error())
 }
 
-// --- GetByNumber tests ---
-
-func TestLoanService_GetByNumber_Success(t *testing.T) {
-	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+func TestLoanService_Create_CategoryMinAmountRejected(t *testing.T) {
+	service, _, itemRepo, customerRepo, categoryRepo := setupLoanServiceWithSettings(nil)
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:         1,
-		LoanNumber: "LN-000001",
-		CustomerID: 10,
-		ItemID:     20,
-	}
-
-	customer := &domain.Customer{ID: 10, FirstName: "John", LastName: "Doe"}
-	item := &domain.Item{ID: 20, Name: "iPhone 15"}
+	categoryID := int64(5)
+	minAmount := 250.0
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, CategoryID: &categoryID, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, MinLoanAmount: &minAmount}, nil)
 
-	loanRepo.On("GetByNumber", ctx, "LN-000001").Return(loan, nil)
-	customerRepo.On("GetByID", ctx, int64(10)).Return(customer, nil)
-	itemRepo.On("GetByID", ctx, int64(20)).Return(item, nil)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 200, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single"}
 
-	result, err := service.GetByNumber(ctx, "LN-000001")
+	result, err := service.Create(ctx, input)
 
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "LN-000001", result.LoanNumber)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "loan amount must be at least 250.00", err.Error())
 }
 
-func TestLoanService_GetByNumber_NotFound(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_CategoryMaxTermRejected(t *testing.T) {
+	service, _, itemRepo, customerRepo, categoryRepo := setupLoanServiceWithSettings(nil)
 	ctx := context.Background()
 
-	loanRepo.On("GetByNumber", ctx, "NONEXISTENT").Return(nil, errors.New("not found"))
+	categoryID := int64(5)
+	maxTerm := 60
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, CategoryID: &categoryID, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, MaxLoanTermDays: &maxTerm}, nil)
 
-	result, err := service.GetByNumber(ctx, "NONEXISTENT")
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 200, InterestRate: 10, LoanTermDays: 90, PaymentPlanType: "single"}
+
+	result, err := service.Create(ctx, input)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
+	assert.Equal(t, "loan term cannot exceed 60 days", err.Error())
 }
 
-// --- List tests ---
-
-func TestLoanService_List_Success(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_GetLimits_CategoryTermBounds(t *testing.T) {
+	service, _, _, _, categoryRepo := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingMinLoanTermDays: float64(7),
+		SettingMaxLoanTermDays: float64(180),
+	})
 	ctx := context.Background()
 
-	loans := []domain.Loan{
-		{ID: 1, LoanNumber: "LN-000001"},
-		{ID: 2, LoanNumber: "LN-000002"},
-	}
+	// A category bound only applies when it is narrower than the branch's
+	narrow, wide := int64(5), int64(6)
+	minTerm, maxTerm := 15, 60
+	shortest, longest := 1, 365
+	categoryRepo.On("GetByID", ctx, narrow).Return(&domain.Category{ID: narrow, MinLoanTermDays: &minTerm, MaxLoanTermDays: &maxTerm}, nil)
+	categoryRepo.On("GetByID", ctx, wide).Return(&domain.Category{ID: wide, MinLoanTermDays: &shortest, MaxLoanTermDays: &longest}, nil)
 
-	paginatedResult := &repository.PaginatedResult[domain.Loan]{
-		Data:       loans,
-		Total:      2,
-		Page:       1,
-		PerPage:    10,
-		TotalPages: 1,
-	}
+	limits := service.GetLimits(ctx, 1, &narrow)
+	assert.Equal(t, 15, limits.MinTermDays)
+	assert.Equal(t, 60, limits.MaxTermDays)
 
-	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(paginatedResult, nil)
+	limits = service.GetLimits(ctx, 1, &wide)
+	assert.Equal(t, 7, limits.MinTermDays)
+	assert.Equal(t, 180, limits.MaxTermDays)
+}
 
-	params := repository.LoanListParams{BranchID: 1}
-	result, err := service.List(ctx, params)
+func TestLoanService_Create_ImplausibleRateRejected(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(nil)
+	ctx := context.Background()
 
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Len(t, result.Data, 2)
-}
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
 
-// --- GetPayments tests ---
+	// 50% typed instead of 5%
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 50, LoanTermDays: 30, PaymentPlanType: "single"}
 
-func TestLoanService_GetPayments_Success(t *testing.T) {
-	service, _, _, _, paymentRepo := setupLoanService()
+	result, err := service.Create(ctx, input)
+
+	assert.ErrorIs(t, err, ErrImplausibleRate)
+	assert.Nil(t, result)
+	loanRepo.AssertNotCalled(t, "GenerateNumber", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Create_PlausibleRateAccepted(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingMaxPlausibleMonthlyRate: float64(8),
+	})
 	ctx := context.Background()
 
-	payments := []*domain.Payment{
-		{ID: 1, Amount: 100},
-		{ID: 2, Amount: 200},
-	}
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	paymentRepo.On("ListByLoan", ctx, int64(1)).Return(payments, nil)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 5, LoanTermDays: 30, PaymentPlanType: "single"}
 
-	result, err := service.GetPayments(ctx, 1)
+	result, err := service.Create(ctx, input)
 
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
-	paymentRepo.AssertExpectations(t)
+	assert.Equal(t, 5.0, result.InterestRate)
 }
 
-func TestLoanService_GetPayments_Error(t *testing.T) {
-	service, _, _, _, paymentRepo := setupLoanService()
+func TestLoanService_Create_ImplausibleRateOverriddenWithReason(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(nil)
 	ctx := context.Background()
 
-	paymentRepo.On("ListByLoan", ctx, int64(999)).Return(nil, errors.New("not found"))
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	result, err := service.GetPayments(ctx, 999)
+	input := CreateLoanInput{
+		CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 30, LoanTermDays: 30, PaymentPlanType: "single",
+		RateOverrideReason: "Artículo de alto riesgo aprobado por gerencia",
+	}
 
-	assert.Error(t, err)
-	assert.Nil(t, result)
-}
+	result, err := service.Create(ctx, input)
 
-// --- GetInstallments tests ---
+	require.NoError(t, err)
+	assert.Contains(t, result.Notes, "Artículo de alto riesgo aprobado por gerencia")
+}
 
-func TestLoanService_GetInstallments_Success(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_InheritsCategoryRates(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, categoryRepo := setupLoanServiceWithSettings(map[string]interface{}{
+		"default_interest_rate": float64(7),
+		"default_late_fee_rate": float64(1.5),
+	})
 	ctx := context.Background()
 
-	installments := []*domain.LoanInstallment{
-		{ID: 1, LoanID: 1, InstallmentNumber: 1},
-		{ID: 2, LoanID: 1, InstallmentNumber: 2},
-	}
+	categoryID := int64(5)
+	lateFeeRate := 2.0
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, CategoryID: &categoryID, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, DefaultInterestRate: 6, DefaultLateFeeRate: &lateFeeRate}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	loanRepo.On("GetInstallments", ctx, int64(1)).Return(installments, nil)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, LoanTermDays: 30, PaymentPlanType: "single"}
 
-	result, err := service.GetInstallments(ctx, 1)
+	result, err := service.Create(ctx, input)
 
-	assert.NoError(t, err)
-	assert.Len(t, result, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, result.InterestRate)
+	assert.Equal(t, 2.0, result.LateFeeRate)
 }
 
-// --- Renew tests ---
-
-func TestLoanService_Renew_Success(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_ExplicitRatesOverrideCategory(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, categoryRepo := setupLoanServiceWithSettings(nil)
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:                 1,
-		LoanNumber:         "LN-000001",
+	categoryID := int64(5)
+	lateFeeRate := 2.0
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, CategoryID: &categoryID, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, DefaultInterestRate: 6, DefaultLateFeeRate: &lateFeeRate}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	explicitLateFeeRate := 0.5
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 4, LateFeeRate: &explicitLateFeeRate, LoanTermDays: 30, PaymentPlanType: "single"}
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, result.InterestRate)
+	assert.Equal(t, 0.5, result.LateFeeRate)
+}
+
+func TestLoanService_Create_FallsBackToGlobalRates(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, categoryRepo := setupLoanServiceWithSettings(map[string]interface{}{
+		"default_interest_rate": float64(7),
+		"default_late_fee_rate": float64(1.5),
+	})
+	ctx := context.Background()
+
+	categoryID := int64(5)
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, CategoryID: &categoryID, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, LoanTermDays: 30, PaymentPlanType: "single"}
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, result.InterestRate)
+	assert.Equal(t, 1.5, result.LateFeeRate)
+}
+
+func TestLoanService_Create_ImplausibleCategoryRateRejected(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, categoryRepo := setupLoanServiceWithSettings(nil)
+	ctx := context.Background()
+
+	categoryID := int64(5)
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, CategoryID: &categoryID, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, DefaultInterestRate: 50}, nil)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, LoanTermDays: 30, PaymentPlanType: "single"}
+
+	result, err := service.Create(ctx, input)
+
+	assert.ErrorIs(t, err, ErrImplausibleRate)
+	assert.Nil(t, result)
+	loanRepo.AssertNotCalled(t, "GenerateNumber", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_CheckDefaultRates(t *testing.T) {
+	service, _, _, _, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		"default_late_fee_rate": float64(10),
+	})
+	branchRepo := new(mocks.MockBranchRepository)
+	service.SetBranches(branchRepo)
+	branchRepo.On("List", mock.Anything, mock.Anything).Return(&repository.PaginatedResult[domain.Branch]{
+		Data: []domain.Branch{
+			{ID: 1, Code: "MAIN", DefaultInterestRate: 10},
+			{ID: 2, Code: "NORTE", DefaultInterestRate: 100},
+		},
+	}, nil)
+
+	warnings := service.CheckDefaultRates(context.Background())
+
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "default_late_fee_rate")
+	assert.Contains(t, warnings[1], "NORTE")
+}
+
+func TestLoanService_Create_AtBoundariesAccepted(t *testing.T) {
+	settings := map[string]interface{}{
+		SettingMinLoanAmount:   float64(100),
+		SettingMaxLoanAmount:   float64(800),
+		SettingMinLoanTermDays: float64(7),
+		SettingMaxLoanTermDays: float64(90),
+	}
+
+	cases := []struct {
+		name     string
+		amount   float64
+		termDays int
+	}{
+		{"min amount and min term", 100, 7},
+		{"max amount and max term", 800, 90},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(settings)
+			ctx := context.Background()
+
+			customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+			item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+			customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+			itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+			expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+			input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: tc.amount, InterestRate: 10, LoanTermDays: tc.termDays, PaymentPlanType: "single"}
+
+			result, err := service.Create(ctx, input)
+
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+			assert.Equal(t, tc.termDays, result.LoanTermDays)
+		})
+	}
+}
+
+func TestLoanService_Create_HighValueLoanRequiresPhotos(t *testing.T) {
+	requirements := map[string]interface{}{
+		"min_loan_amount": float64(5000),
+		"min_photos":      float64(2),
+	}
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingLoanPhotoRequirements: requirements,
+	})
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 8000, Photos: []string{"/uploads/items/1-front.jpg"}}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 6000, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single"}
+
+	_, err := service.Create(ctx, input)
+
+	var unmet *LoanRequirementsError
+	require.ErrorAs(t, err, &unmet)
+	assert.ErrorIs(t, err, ErrLoanRequirementsUnmet)
+	assert.Equal(t, []string{"item needs at least 2 photos, has 1"}, unmet.Unmet)
+	loanRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+
+	// Once the second photo is attached the loan activates
+	item.Photos = append(item.Photos, "/uploads/items/1-back.jpg")
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusActive, result.Status)
+}
+
+func TestLoanPhotoRequirements_UnknownDocumentIsNeverMet(t *testing.T) {
+	requirements := LoanPhotoRequirements{RequiredDocuments: []string{"customer_passport"}}
+
+	unmet := requirements.Unmet(100, &domain.Item{}, &domain.Customer{IdentityNumber: "1234567890101", PhotoURL: "/uploads/customers/1.jpg"})
+
+	assert.Equal(t, []string{`unknown required document "customer_passport"`}, unmet)
+	assert.ErrorIs(t, requirements.Validate(), ErrInvalidInput)
+}
+
+func TestLoanService_GetLimits_PhotoRequirements(t *testing.T) {
+	service, _, _, _, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingLoanPhotoRequirements: map[string]interface{}{
+			"min_loan_amount":    float64(5000),
+			"min_photos":         float64(1),
+			"required_documents": []interface{}{LoanDocumentCustomerIdentity},
+		},
+	})
+
+	limits := service.GetLimits(context.Background(), 1, nil)
+
+	assert.Equal(t, LoanPhotoRequirements{MinLoanAmount: 5000, MinPhotos: 1, RequiredDocuments: []string{LoanDocumentCustomerIdentity}}, limits.PhotoRequirements)
+	assert.True(t, limits.PhotoRequirements.AppliesTo(5000))
+	assert.False(t, limits.PhotoRequirements.AppliesTo(4999.99))
+}
+
+func TestLoanService_GetLimits_Defaults(t *testing.T) {
+	service, _, _, _, _ := setupLoanService()
+
+	limits := service.GetLimits(context.Background(), 1, nil)
+
+	assert.Equal(t, DefaultMinLoanAmount, limits.MinAmount)
+	assert.Equal(t, DefaultMaxLoanAmount, limits.MaxAmount)
+	assert.Equal(t, DefaultMinLoanTermDays, limits.MinTermDays)
+	assert.Equal(t, DefaultMaxLoanTermDays, limits.MaxTermDays)
+}
+
+// --- GetByID tests ---
+
+func TestLoanService_GetByID_Success(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:         1,
+		LoanNumber: "LN-000001",
+		Status:     domain.LoanStatusActive,
+		CustomerID: 10,
+		ItemID:     20,
+	}
+
+	customer := &domain.Customer{ID: 10, FirstName: "John", LastName: "Doe"}
+	item := &domain.Item{ID: 20, Name: "iPhone 15"}
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(10)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(20)).Return(item, nil)
+
+	result, err := service.GetByID(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "LN-000001", result.LoanNumber)
+}
+
+func TestLoanService_GetByID_NotFound(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+
+	result, err := service.GetByID(ctx, 999)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+// --- GetByNumber tests ---
+
+func TestLoanService_GetByNumber_Success(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:         1,
+		LoanNumber: "LN-000001",
+		CustomerID: 10,
+		ItemID:     20,
+	}
+
+	customer := &domain.Customer{ID: 10, FirstName: "John", LastName: "Doe"}
+	item := &domain.Item{ID: 20, Name: "iPhone 15"}
+
+	loanRepo.On("GetByNumber", ctx, "LN-000001").Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(10)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(20)).Return(item, nil)
+
+	result, err := service.GetByNumber(ctx, "LN-000001")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "LN-000001", result.LoanNumber)
+}
+
+func TestLoanService_GetByNumber_NotFound(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetByNumber", ctx, "NONEXISTENT").Return(nil, errors.New("not found"))
+
+	result, err := service.GetByNumber(ctx, "NONEXISTENT")
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestLoanService_Verify_ChecksContractToken(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	service.SetVerificationKey("contract-secret")
+	ctx := context.Background()
+	loan := &domain.Loan{ID: 1, LoanNumber: "LN-000001", Status: domain.LoanStatusActive, DueDate: domain.NewDate(2026, time.April, 1)}
+	loanRepo.On("GetByNumber", ctx, "LN-000001").Return(loan, nil)
+
+	result, err := service.Verify(ctx, "LN-000001", pdf.LoanVerificationToken([]byte("contract-secret"), "LN-000001"))
+	require.NoError(t, err)
+	assert.Equal(t, "LN-000001", result.LoanNumber)
+	assert.Equal(t, domain.LoanStatusActive, result.Status)
+
+	_, err = service.Verify(ctx, "LN-000001", pdf.LoanVerificationToken([]byte("jwt-secret"), "LN-000001"))
+	assert.ErrorIs(t, err, ErrLoanNotFound)
+	_, err = service.Verify(ctx, "LN-000002", pdf.LoanVerificationToken([]byte("contract-secret"), "LN-000001"))
+	assert.ErrorIs(t, err, ErrLoanNotFound)
+	loanRepo.AssertNumberOfCalls(t, "GetByNumber", 1)
+}
+
+// --- List tests ---
+
+func TestLoanService_List_Success(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loans := []domain.Loan{
+		{ID: 1, LoanNumber: "LN-000001"},
+		{ID: 2, LoanNumber: "LN-000002"},
+	}
+
+	paginatedResult := &repository.PaginatedResult[domain.Loan]{
+		Data:       loans,
+		Total:      2,
+		Page:       1,
+		PerPage:    10,
+		TotalPages: 1,
+	}
+
+	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(paginatedResult, nil)
+
+	params := repository.LoanListParams{BranchID: 1}
+	result, err := service.List(ctx, params)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Len(t, result.Data, 2)
+}
+
+func TestLoanService_List_FilterByTags(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	tagged := domain.Loan{ID: 2, LoanNumber: "LN-000002", Tags: []string{"vip", "legal_hold"}}
+	loanRepo.On("List", ctx, mock.MatchedBy(func(p repository.LoanListParams) bool {
+		return len(p.Tags) == 1 && p.Tags[0] == "vip"
+	})).Return(&repository.PaginatedResult[domain.Loan]{Data: []domain.Loan{tagged}, Total: 1, Page: 1, PerPage: 10, TotalPages: 1}, nil)
+
+	result, err := service.List(ctx, repository.LoanListParams{BranchID: 1, Tags: []string{"vip"}})
+
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	assert.True(t, result.Data[0].HasTag("VIP"))
+}
+
+// --- Tag tests ---
+
+func TestLoanService_AddTag_NormalizesAndSkipsDuplicates(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(&domain.Loan{ID: 1, Tags: []string{"vip"}}, nil)
+	loanRepo.On("SetTags", ctx, int64(1), []string{"vip", "legal_hold"}).Return(nil).Once()
+
+	loan, err := service.AddTag(ctx, 1, "  Legal_Hold ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip", "legal_hold"}, loan.Tags)
+
+	_, err = service.AddTag(ctx, 1, "VIP")
+	require.NoError(t, err)
+	loanRepo.AssertNumberOfCalls(t, "SetTags", 1)
+}
+
+func TestLoanService_RemoveTag(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(&domain.Loan{ID: 1, Tags: []string{"vip", "legal_hold"}}, nil)
+	loanRepo.On("SetTags", ctx, int64(1), []string{"vip"}).Return(nil).Once()
+
+	loan, err := service.RemoveTag(ctx, 1, "legal_hold")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip"}, loan.Tags)
+}
+
+// --- GetPayments tests ---
+
+func TestLoanService_GetPayments_Success(t *testing.T) {
+	service, _, _, _, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	payments := []*domain.Payment{
+		{ID: 1, Amount: 100},
+		{ID: 2, Amount: 200},
+	}
+
+	paymentRepo.On("ListByLoan", ctx, int64(1)).Return(payments, nil)
+
+	result, err := service.GetPayments(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	paymentRepo.AssertExpectations(t)
+}
+
+func TestLoanService_GetPayments_Error(t *testing.T) {
+	service, _, _, _, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	paymentRepo.On("ListByLoan", ctx, int64(999)).Return(nil, errors.New("not found"))
+
+	result, err := service.GetPayments(ctx, 999)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+// --- GetInstallments tests ---
+
+func TestLoanService_GetInstallments_Success(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	installments := []*domain.LoanInstallment{
+		{ID: 1, LoanID: 1, InstallmentNumber: 1},
+		{ID: 2, LoanID: 1, InstallmentNumber: 2},
+	}
+
+	loanRepo.On("GetInstallments", ctx, int64(1)).Return(installments, nil)
+
+	result, err := service.GetInstallments(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+}
+
+// --- Renew tests ---
+
+func TestLoanService_Renew_Success(t *testing.T) {
+	service, loanRepo, itemRepo, _, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:                 1,
+		LoanNumber:         "LN-000001",
+		BranchID:           1,
+		CustomerID:         1,
+		ItemID:             1,
+		LoanAmount:         1000,
+		InterestRate:       10,
+		InterestAmount:     100,
+		PrincipalRemaining: 500,
+		InterestRemaining:  50,
+		LateFeeAmount:      12,
+		LateFeeRemaining:   8,
+		Status:             domain.LoanStatusActive,
+		PaymentPlanType:    domain.PaymentPlanType("single"),
+		GracePeriodDays:    5,
+		LateFeeRate:        2,
+	}
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000002", nil)
+	loanRepo.On("Renew", ctx,
+		mock.MatchedBy(func(l *domain.Loan) bool {
+			return l.ID == 1 && l.Status == domain.LoanStatusRenewed && l.InterestRemaining == 0
+		}),
+		mock.AnythingOfType("*domain.Loan"),
+		mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.LoanID == 1 && p.Amount == 50 && p.InterestAmount == 50 && p.PrincipalAmount == 0
+		}),
+		(*domain.CashMovement)(nil),
+	).Return(nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PG-000010", nil)
+	itemRepo.On("CreateHistory", ctx, mock.MatchedBy(func(h *domain.ItemHistory) bool {
+		return h.ItemID == 1 && h.Action == domain.ItemHistoryActionLoanRenewed && *h.ReferenceType == "loan"
+	})).Return(nil)
+
+	input := RenewLoanInput{
+		LoanID:       1,
+		NewTermDays:  30,
+		InterestPaid: 50,
+		UpdatedBy:    1,
+	}
+
+	result, err := service.Renew(ctx, input)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "LN-000002", result.LoanNumber)
+	assert.Equal(t, domain.LoanStatusActive, result.Status)
+	assert.Equal(t, 500.0, result.LoanAmount)               // Remaining principal
+	assert.Equal(t, loan.InterestRate, result.InterestRate)  // Same rate since NewInterestRate=0
+	assert.Equal(t, 50.0, result.InterestAmount)             // 500 * 10/100
+	assert.Equal(t, 8.0, result.LateFeeRemaining)            // Unpaid late fees carry over
+	assert.Equal(t, 30, result.LoanTermDays)
+	assert.Equal(t, domain.Today().AddDate(0, 0, 30), result.DueDate.Time)
+	assert.Equal(t, int64(1), *result.RenewedFromID)
+	assert.Equal(t, 1, result.RenewalCount)
+	assert.Equal(t, 50.0, loan.AmountPaid)
+	loanRepo.AssertExpectations(t)
+	paymentRepo.AssertExpectations(t)
+	itemRepo.AssertExpectations(t)
+}
+
+func TestLoanService_Renew_WithNewInterestRate(t *testing.T) {
+	service, loanRepo, itemRepo, _, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:                 1,
 		BranchID:           1,
 		CustomerID:         1,
 		ItemID:             1,
-		LoanAmount:         1000,
 		InterestRate:       10,
-		InterestAmount:     100,
 		PrincipalRemaining: 500,
-		InterestRemaining:  50,
-		Status:             domain.LoanStatusActive,
+		Status:             domain.LoanStatusOverdue,
 		PaymentPlanType:    domain.PaymentPlanType("single"),
-		GracePeriodDays:    5,
-		LateFeeRate:        2,
 	}
 
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
-	loanRepo.On("GenerateNumber", ctx).Return("LN-000002", nil)
-	loanRepo.On("Create", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000003", nil)
+	loanRepo.On("Renew", ctx, loan, mock.AnythingOfType("*domain.Loan"), (*domain.Payment)(nil), (*domain.CashMovement)(nil)).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).Return(nil)
+
+	input := RenewLoanInput{
+		LoanID:          1,
+		NewTermDays:     60,
+		NewInterestRate: 15,
+		UpdatedBy:       1,
+	}
+
+	result, err := service.Renew(ctx, input)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, 15.0, result.InterestRate)
+	assert.Equal(t, 75.0, result.InterestAmount) // 500 * 15/100
+	paymentRepo.AssertNotCalled(t, "GenerateNumber", mock.Anything, mock.Anything) // No interest was owed
+}
+
+func TestLoanService_Renew_FailureReturnsError(t *testing.T) {
+	service, loanRepo, itemRepo, _, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, BranchID: 1, ItemID: 1, InterestRate: 10, PrincipalRemaining: 500, InterestRemaining: 50, Status: domain.LoanStatusActive}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000002", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PG-000010", nil)
+	loanRepo.On("Renew", ctx, loan, mock.AnythingOfType("*domain.Loan"), mock.AnythingOfType("*domain.Payment"), (*domain.CashMovement)(nil)).Return(errors.New("connection reset"))
+
+	result, err := service.Renew(ctx, RenewLoanInput{LoanID: 1, NewTermDays: 30, InterestPaid: 50, UpdatedBy: 1})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	// Nothing is written outside the renewal transaction
+	paymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	itemRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Renew_SavesCashMovementWithRenewal(t *testing.T) {
+	service, loanRepo, itemRepo, _, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	paymentRepo := new(mocks.MockPaymentRepository)
+	service.paymentRepo = paymentRepo
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, BranchID: 1, ItemID: 1, InterestRate: 10, PrincipalRemaining: 500, InterestRemaining: 50, Status: domain.LoanStatusActive}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 200, Status: domain.CashSessionStatusOpen}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000002", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PG-000010", nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(200.0, nil)
+	loanRepo.On("Renew", ctx, loan, mock.AnythingOfType("*domain.Loan"), mock.AnythingOfType("*domain.Payment"),
+		mock.MatchedBy(func(m *domain.CashMovement) bool {
+			return m.SessionID == 3 &&
+				m.MovementType == domain.CashMovementTypeIncome &&
+				m.Amount == 50 &&
+				m.BalanceAfter == 250 &&
+				m.ReferenceType != nil && *m.ReferenceType == "payment"
+		}),
+	).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).Return(nil)
+
+	result, err := service.Renew(ctx, RenewLoanInput{LoanID: 1, NewTermDays: 30, InterestPaid: 50, UpdatedBy: 7})
+
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	loanRepo.AssertExpectations(t)
+	// The movement is only saved by the renewal transaction
+	movementRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Renew_ClosedCashSessionRejected(t *testing.T) {
+	service, loanRepo, _, _, sessionRepo, _ := setupLoanServiceWithCash()
+	paymentRepo := new(mocks.MockPaymentRepository)
+	service.paymentRepo = paymentRepo
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, BranchID: 1, ItemID: 1, InterestRate: 10, PrincipalRemaining: 500, InterestRemaining: 50, Status: domain.LoanStatusActive}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, Status: domain.CashSessionStatusOpen}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000002", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PG-000010", nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	// Closed by the time the receipt is recorded
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(&domain.CashSession{ID: 3, Status: domain.CashSessionStatusClosed}, nil)
+
+	result, err := service.Renew(ctx, RenewLoanInput{LoanID: 1, NewTermDays: 30, InterestPaid: 50, UpdatedBy: 7})
+
+	assert.ErrorContains(t, err, "failed to record payment movement")
+	assert.Nil(t, result)
+	loanRepo.AssertNotCalled(t, "Renew", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Renew_NotFound(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+
+	input := RenewLoanInput{LoanID: 999}
+
+	result, err := service.Renew(ctx, input)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "loan not found", err.Error())
+}
+
+func TestLoanService_Renew_InvalidStatus(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:     1,
+		Status: domain.LoanStatusPaid,
+	}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+
+	input := RenewLoanInput{LoanID: 1}
+
+	result, err := service.Renew(ctx, input)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "only active or overdue loans can be renewed", err.Error())
+}
+
+func TestLoanService_Renew_InterestNotPaid(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:                1,
+		Status:            domain.LoanStatusActive,
+		InterestRemaining: 50,
+	}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+
+	for _, paid := range []float64{0, 20} {
+		result, err := service.Renew(ctx, RenewLoanInput{LoanID: 1, NewTermDays: 30, InterestPaid: paid})
+
+		assert.ErrorIs(t, err, ErrRenewalInterestUnpaid)
+		assert.Nil(t, result)
+	}
+	assert.Equal(t, domain.LoanStatusActive, loan.Status)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Renew_InterestOverpaid(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, Status: domain.LoanStatusOverdue, InterestRemaining: 50}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+
+	_, err := service.Renew(ctx, RenewLoanInput{LoanID: 1, NewTermDays: 30, InterestPaid: 60})
+
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+// --- Confiscate tests ---
+
+func TestLoanService_Confiscate_Success(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:                 1,
+		ItemID:             10,
+		CustomerID:         20,
+		Status:             domain.LoanStatusOverdue,
+		PrincipalRemaining: 500.00,
+		InterestRemaining:  50.00,
+		LateFeeAmount:      10.00,
+	}
+
+	customer := &domain.Customer{
+		ID:             20,
+		FirstName:      "John",
+		LastName:       "Doe",
+		TotalDefaulted: 0,
+	}
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(10), domain.ItemStatusConfiscated).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(20)).Return(customer, nil)
+	customerRepo.On("UpdateCreditInfo", ctx, int64(20), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
+
+	err := service.Confiscate(ctx, 1, 1, "Loan overdue")
+
+	assert.NoError(t, err)
+}
+
+func TestLoanService_Confiscate_InvalidStatus(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:     1,
+		Status: domain.LoanStatusActive,
+	}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+
+	err := service.Confiscate(ctx, 1, 1, "test")
+
+	assert.Error(t, err)
+	assert.Equal(t, "only defaulted or overdue loans can be confiscated", err.Error())
+}
+
+func TestLoanService_Confiscate_LegalHold(t *testing.T) {
+	service, loanRepo, itemRepo, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, ItemID: 10, Status: domain.LoanStatusOverdue, Tags: []string{domain.LoanTagLegalHold}}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+
+	err := service.Confiscate(ctx, 1, 1, "test")
+
+	assert.ErrorIs(t, err, ErrLoanOnLegalHold)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	itemRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Confiscate_NotFound(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+
+	err := service.Confiscate(ctx, 999, 1, "test")
+
+	assert.Error(t, err)
+	assert.Equal(t, "loan not found", err.Error())
+}
+
+// --- WriteOff tests ---
+
+func TestLoanService_WriteOff_PostsBalancedLossEntry(t *testing.T) {
+	service, loanRepo, _, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+	writeOffRepo := new(mocks.MockLoanWriteOffRepository)
+	accountRepo := new(mocks.MockAccountRepository)
+	entryRepo := new(mocks.MockAccountingEntryRepository)
+	service.SetWriteOffs(writeOffRepo, accountRepo, entryRepo)
+
+	loan := &domain.Loan{
+		ID: 1, LoanNumber: "LN-000001", BranchID: 1, CustomerID: 3, Status: domain.LoanStatusDefaulted,
+		PrincipalRemaining: 800, InterestRemaining: 120.5, LateFeeRemaining: 30,
+	}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(3)).Return(&domain.Customer{ID: 3, TotalDefaulted: 100}, nil)
+	customerRepo.On("UpdateCreditInfo", ctx, int64(3), mock.MatchedBy(func(u repository.CustomerCreditUpdate) bool {
+		return u.TotalDefaulted != nil && *u.TotalDefaulted == 1050.5
+	})).Return(nil).Once()
+
+	accountRepo.On("GetByCode", ctx, "5400").Return(&domain.Account{ID: 54, Code: "5400"}, nil)
+	accountRepo.On("GetByCode", ctx, "1210").Return(&domain.Account{ID: 21, Code: "1210"}, nil)
+	accountRepo.On("GetByCode", ctx, "1220").Return(&domain.Account{ID: 22, Code: "1220"}, nil)
+	accountRepo.On("GetByCode", ctx, "1200").Return(&domain.Account{ID: 20, Code: "1200"}, nil)
+	entryRepo.On("GenerateEntryNumber", ctx).Return("JE-20261016-0001", nil)
+	var entry *domain.AccountingEntry
+	writeOffRepo.On("Create", ctx, mock.AnythingOfType("*domain.LoanWriteOff"), mock.AnythingOfType("*domain.AccountingEntry")).Run(func(args mock.Arguments) {
+		entry = args.Get(2).(*domain.AccountingEntry)
+		entry.ID = 40
+		args.Get(1).(*domain.LoanWriteOff).EntryID = &entry.ID
+	}).Return(nil).Once()
+
+	writeOff, err := service.WriteOff(ctx, 1, " Cliente fallecido, sin herederos ", 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, 950.5, writeOff.Amount)
+	assert.Equal(t, "Cliente fallecido, sin herederos", writeOff.Reason)
+	assert.Equal(t, int64(7), writeOff.WrittenOffBy)
+	assert.Equal(t, int64(40), *writeOff.EntryID)
+
+	require.NotNil(t, entry)
+	assert.Equal(t, domain.AccountingReferenceLoanWriteOff, entry.ReferenceType)
+	assert.True(t, entry.IsPosted)
+	assert.Equal(t, int64(7), *entry.PostedBy)
+	var debits, credits float64
+	credited := map[int64]float64{}
+	for _, line := range entry.Lines {
+		if line.EntryType == domain.EntryTypeDebit {
+			assert.Equal(t, int64(54), line.AccountID)
+			debits += line.Amount
+		} else {
+			credits += line.Amount
+			credited[line.AccountID] = line.Amount
+		}
+	}
+	assert.Equal(t, 950.5, debits)
+	assert.InDelta(t, debits, credits, 0.001)
+	assert.Equal(t, entry.TotalDebit, entry.TotalCredit)
+	assert.Equal(t, map[int64]float64{21: 800, 22: 120.5, 20: 30}, credited)
+	loanRepo.AssertExpectations(t)
+	customerRepo.AssertExpectations(t)
+	entryRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_WriteOff_AlreadyWrittenOff(t *testing.T) {
+	service, loanRepo, _, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+	writeOffRepo := new(mocks.MockLoanWriteOffRepository)
+	service.SetWriteOffs(writeOffRepo, new(mocks.MockAccountRepository), new(mocks.MockAccountingEntryRepository))
+
+	loan := &domain.Loan{ID: 1, LoanNumber: "LN-000001", BranchID: 1, CustomerID: 3, Status: domain.LoanStatusDefaulted}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	writeOffRepo.On("Create", ctx, mock.AnythingOfType("*domain.LoanWriteOff"), (*domain.AccountingEntry)(nil)).
+		Return(repository.ErrLoanAlreadyWrittenOff)
+
+	_, err := service.WriteOff(ctx, 1, "Incobrable", 7)
+
+	assert.ErrorIs(t, err, repository.ErrLoanAlreadyWrittenOff)
+	customerRepo.AssertNotCalled(t, "UpdateCreditInfo", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_WriteOff_Validation(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+	service.SetWriteOffs(new(mocks.MockLoanWriteOffRepository), new(mocks.MockAccountRepository), new(mocks.MockAccountingEntryRepository))
+
+	_, err := service.WriteOff(ctx, 1, "  ", 7)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	loanRepo.On("GetByID", ctx, int64(2)).Return(&domain.Loan{ID: 2, Status: domain.LoanStatusActive}, nil)
+	_, err = service.WriteOff(ctx, 2, "Incobrable", 7)
+	assert.EqualError(t, err, "only defaulted or overdue loans can be written off")
+	loanRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- ReinstateConfiscated tests ---
+
+func TestLoanService_ReinstateConfiscated_Success(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	confiscatedAt := time.Now().Add(-72 * time.Hour)
+	loan := &domain.Loan{
+		ID:                 1,
+		LoanNumber:         "LN-000001",
+		ItemID:             10,
+		CustomerID:         20,
+		Status:             domain.LoanStatusConfiscated,
+		ConfiscatedDate:    &confiscatedAt,
+		PrincipalRemaining: 500.00,
+		InterestRemaining:  50.00,
+		LateFeeRemaining:   10.00,
+	}
+	customer := &domain.Customer{ID: 20, TotalDefaulted: 560.00, TotalPaid: 100.00}
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusConfiscated}, nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000009", nil)
+	paymentRepo.On("Create", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Amount == 560.00 && p.PrincipalAmount == 500.00 && p.InterestAmount == 50.00 && p.LateFeeAmount == 10.00
+	})).Return(nil)
+	loanRepo.On("Update", ctx, mock.MatchedBy(func(l *domain.Loan) bool {
+		return l.Status == domain.LoanStatusPaid && l.RemainingBalance() == 0 && l.PaidDate != nil && l.ConfiscatedDate == nil
+	})).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(10), domain.ItemStatusAvailable).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(20)).Return(customer, nil)
+	customerRepo.On("UpdateCreditInfo", ctx, int64(20), mock.MatchedBy(func(u repository.CustomerCreditUpdate) bool {
+		return *u.TotalDefaulted == 0 && *u.TotalPaid == 660.00
+	})).Return(nil)
+
+	result, err := service.ReinstateConfiscated(ctx, 1, 1)
+
+	require.NoError(t, err)
+	assert.True(t, result.IsFullyPaid)
+	assert.Equal(t, "PAY-000009", result.Payment.PaymentNumber)
+	assert.Equal(t, domain.LoanStatusPaid, result.Loan.Status)
+	loanRepo.AssertExpectations(t)
+	itemRepo.AssertExpectations(t)
+	customerRepo.AssertExpectations(t)
+}
+
+func TestLoanService_ReinstateConfiscated_CashMovementFailureUndoes(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	paymentRepo := new(mocks.MockPaymentRepository)
+	service.paymentRepo = paymentRepo
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, ItemID: 10, CustomerID: 20, Status: domain.LoanStatusConfiscated, PrincipalRemaining: 500.00, InterestRemaining: 50.00}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 100, Status: domain.CashSessionStatusOpen}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusConfiscated}, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(100.0, nil)
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Return(errors.New("db error"))
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000009", nil)
+	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	var saved []domain.Loan
+	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).
+		Run(func(args mock.Arguments) { saved = append(saved, *args.Get(1).(*domain.Loan)) }).Return(nil)
+	var failed *domain.Payment
+	paymentRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payment")).
+		Run(func(args mock.Arguments) { failed = args.Get(1).(*domain.Payment) }).Return(nil)
+
+	result, err := service.ReinstateConfiscated(ctx, 1, 7)
+
+	assert.ErrorContains(t, err, "failed to record payment movement")
+	assert.Nil(t, result)
+	if assert.Len(t, saved, 2) {
+		assert.Equal(t, domain.LoanStatusPaid, saved[0].Status)
+		assert.Equal(t, domain.LoanStatusConfiscated, saved[1].Status)
+		assert.Equal(t, 550.0, saved[1].RemainingBalance())
+	}
+	if assert.NotNil(t, failed) {
+		assert.Equal(t, domain.PaymentStatusFailed, failed.Status)
+	}
+	itemRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	customerRepo.AssertNotCalled(t, "UpdateCreditInfo", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_ReinstateConfiscated_ItemAlreadySold(t *testing.T) {
+	service, loanRepo, itemRepo, _, paymentRepo := setupLoanService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, ItemID: 10, CustomerID: 20, Status: domain.LoanStatusConfiscated, PrincipalRemaining: 500.00}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+
+	_, err := service.ReinstateConfiscated(ctx, 1, 1)
+
+	assert.ErrorIs(t, err, ErrItemAlreadySold)
+	paymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	itemRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- GetOverdueLoans tests ---
+
+func TestLoanService_GetOverdueLoans_Success(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+
+	dueDate := time.Now().Add(-24 * time.Hour)
+	loans := []*domain.Loan{
+		{ID: 1, LoanNumber: "LN-000001", DueDate: domain.DateFromTime(dueDate), Status: domain.LoanStatusActive},
+	}
+
+	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(loans, nil)
+	customerRepo.On("GetByID", ctx, int64(0)).Return(nil, errors.New("not found"))
+	itemRepo.On("GetByID", ctx, int64(0)).Return(nil, errors.New("not found"))
+
+	result, err := service.GetOverdueLoans(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+}
+
+// --- UpdateOverdueStatus tests ---
+
+func TestLoanService_UpdateOverdueStatus_Success(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
+	ctx := context.Background()
+
+	// Loan past due but in grace period
+	graceLoan := &domain.Loan{
+		ID:              1,
+		Status:          domain.LoanStatusActive,
+		DueDate:         domain.DateFromTime(time.Now().Add(-2 * 24 * time.Hour)),
+		GracePeriodDays: 7,
+	}
+
+	// Loan past due and past grace period
+	defaultedLoan := &domain.Loan{
+		ID:              2,
+		Status:          domain.LoanStatusOverdue,
+		DueDate:         domain.DateFromTime(time.Now().Add(-30 * 24 * time.Hour)),
+		GracePeriodDays: 7,
+	}
+
+	loans := []*domain.Loan{graceLoan, defaultedLoan}
+
+	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(loans, nil)
+	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(0)).Return(nil, errors.New("not found"))
+	itemRepo.On("GetByID", ctx, int64(0)).Return(nil, errors.New("not found"))
+
+	err := service.UpdateOverdueStatus(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusOverdue, graceLoan.Status)
+	assert.Equal(t, domain.LoanStatusDefaulted, defaultedLoan.Status)
+	loanRepo.AssertExpectations(t)
+}
+
+func TestLoanService_UpdateOverdueStatus_NoLoans(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return([]*domain.Loan{}, nil)
+
+	err := service.UpdateOverdueStatus(ctx, 1)
+
+	assert.NoError(t, err)
+}
+
+func TestLoanService_UpdateOverdueStatus_Error(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+
+	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(nil, errors.New("db error"))
+
+	err := service.UpdateOverdueStatus(ctx, 1)
+
+	assert.Error(t, err)
+}
+
+// --- Cash disbursement tests ---
+
+func setupLoanServiceWithCash() (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository, *mocks.MockCashSessionRepository, *mocks.MockCashMovementRepository) {
+	loanRepo := newLoanRepositoryMock()
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	categoryRepo := new(mocks.MockCategoryRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	cashService := NewCashService(new(mocks.MockCashRegisterRepository), sessionRepo, movementRepo, new(mocks.MockBranchRepository), nil, nil)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, paymentRepo, categoryRepo, nil, nil, settingRepo, cashService, nil, logger)
+	return service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo
+}
+
+func TestLoanService_Create_CashDisbursementWithoutSessionRejected(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, _ := setupLoanServiceWithCash()
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(nil, errors.New("no open session"))
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrNoOpenCashSession)
+	loanRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Create_CashDisbursementCreatesMovement(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	movementRepo.On("CreateTx", ctx, mock.Anything, mock.MatchedBy(func(m *domain.CashMovement) bool {
+		return m.SessionID == 3 &&
+			m.MovementType == domain.CashMovementTypeExpense &&
+			m.Amount == 500 &&
+			m.BalanceAfter == 1000 &&
+			m.ReferenceType != nil && *m.ReferenceType == "loan"
+	})).Return(nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	movementRepo.AssertExpectations(t)
+}
+
+func TestLoanService_Create_DisbursementFailureRollsBackLoan(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	tx := new(mocks.MockTransaction)
+	loanRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("LN-000010", nil)
+	loanRepo.On("BeginTx", ctx).Return(tx, nil)
+	loanRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusCollateral).Return(nil)
+	movementRepo.On("CreateTx", ctx, tx, mock.AnythingOfType("*domain.CashMovement")).Return(errors.New("db error"))
+	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusAvailable).Return(nil)
+	tx.On("Rollback").Return(nil)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to record loan disbursement")
+	tx.AssertNotCalled(t, "Commit")
+	tx.AssertCalled(t, "Rollback")
+	itemRepo.AssertExpectations(t)
+	customerRepo.AssertNotCalled(t, "UpdateCreditInfo", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Create_NonCashDisbursementSkipsSession(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", DisbursementMethod: "transfer", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	sessionRepo.AssertNotCalled(t, "GetOpenSession", mock.Anything, mock.Anything)
+	movementRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func withDailyDisbursementLimit(service *LoanService, limit float64, now time.Time) {
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingDailyCashDisbursementLimit, mock.Anything).
+		Return(&domain.Setting{Key: SettingDailyCashDisbursementLimit, Value: limit}, nil).Maybe()
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	service.cashService.SetEvents(nil, settingRepo)
+	service.cashService.now = func() time.Time { return now }
+}
+
+func TestLoanService_Create_DailyDisbursementLimitExceeded(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	withDailyDisbursementLimit(service, 1000, now)
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	movementRepo.On("GetDailyLoanDisbursements", ctx, int64(1), domain.NewDate(2026, 3, 10).Time).Return(700.0, nil)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrDailyDisbursementLimitExceeded)
+	assert.Contains(t, err.Error(), "Q300.00")
+	loanRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Create_DailyDisbursementLimitOverridden(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	withDailyDisbursementLimit(service, 1000, now)
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	movementRepo.On("GetDailyLoanDisbursements", ctx, int64(1), domain.NewDate(2026, 3, 10).Time).Return(700.0, nil)
+	movementRepo.On("CreateTx", ctx, mock.Anything, mock.AnythingOfType("*domain.CashMovement")).Return(nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", OverrideDailyLimit: true, CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Contains(t, result.Notes, "Límite diario de desembolso")
+	movementRepo.AssertExpectations(t)
+}
+
+func TestLoanService_Create_DailyDisbursementLimitResetsAtDayBoundary(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	// Just past midnight the previous day's payouts no longer count
+	now := time.Date(2026, 3, 11, 0, 5, 0, 0, time.UTC)
+	withDailyDisbursementLimit(service, 1000, now)
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	movementRepo.On("GetDailyLoanDisbursements", ctx, int64(1), domain.NewDate(2026, 3, 10).Time).Return(700.0, nil).Maybe()
+	movementRepo.On("GetDailyLoanDisbursements", ctx, int64(1), domain.NewDate(2026, 3, 11).Time).Return(0.0, nil)
+	movementRepo.On("CreateTx", ctx, mock.Anything, mock.AnythingOfType("*domain.CashMovement")).Return(nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	movementRepo.AssertCalled(t, "GetDailyLoanDisbursements", ctx, int64(1), domain.NewDate(2026, 3, 11).Time)
+	movementRepo.AssertNotCalled(t, "GetDailyLoanDisbursements", ctx, int64(1), domain.NewDate(2026, 3, 10).Time)
+}
+
+// --- Stored contract tests ---
+
+type mockLoanContractStore struct {
+	mock.Mock
+}
+
+func (m *mockLoanContractStore) StoreLoanContract(ctx context.Context, loanID int64, createdBy int64) (*domain.Document, error) {
+	args := m.Called(ctx, loanID, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Document), args.Error(1)
+}
+
+func contractSettingRepo(autoGenerate bool) *mocks.MockSettingRepository {
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingAutoGenerateContract, mock.Anything).
+		Return(&domain.Setting{Key: SettingAutoGenerateContract, Value: autoGenerate}, nil).Maybe()
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	return settingRepo
+}
+
+func setupLoanServiceWithContracts(autoGenerate bool, store LoanContractStore) (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository) {
+	loanRepo := newLoanRepositoryMock()
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, new(mocks.MockPaymentRepository), new(mocks.MockCategoryRepository), nil, nil, contractSettingRepo(autoGenerate), nil, store, logger)
+	return service, loanRepo, itemRepo, customerRepo
+}
+
+func TestLoanService_Create_StoresContractWhenEnabled(t *testing.T) {
+	loanRepo := newLoanRepositoryMock()
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	reportService := NewReportService(loanRepo, nil, nil, customerRepo, itemRepo, documentRepo,
+		pdf.NewGenerator("Casa de Empeño", "", "", ""), NewStorageService(nil, t.TempDir(), "/storage"))
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, new(mocks.MockPaymentRepository), new(mocks.MockCategoryRepository), nil, nil, contractSettingRepo(true), nil, reportService, logger)
+	ctx := context.Background()
+
+	customer := &domain.Customer{ID: 1, FirstName: "Ana", LastName: "López", IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Name: "Anillo de oro", Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	// The contract is rendered from the persisted loan
+	loanRepo.On("GetByID", ctx, int64(0)).Return(&domain.Loan{LoanNumber: "LN-000010", BranchID: 1, CustomerID: 1, ItemID: 1, LoanAmount: 500}, nil)
+	documentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Document")).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Document).ID = 31
+	}).Return(nil)
+	loanRepo.On("SetContract", ctx, int64(0), int64(31), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	require.NotNil(t, result.ContractDocumentID)
+	assert.Equal(t, int64(31), *result.ContractDocumentID)
+	assert.Contains(t, result.ContractURL, "/storage/documents/contracts/")
+	assert.Len(t, result.ContractHash, 64)
+	loanRepo.AssertCalled(t, "SetContract", ctx, int64(0), int64(31), result.ContractURL, result.ContractHash)
+}
+
+func TestLoanService_Create_SkipsContractWhenDisabled(t *testing.T) {
+	store := new(mockLoanContractStore)
+	service, loanRepo, itemRepo, customerRepo := setupLoanServiceWithContracts(false, store)
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.ContractDocumentID)
+	store.AssertNotCalled(t, "StoreLoanContract", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoanService_Create_ContractFailureDoesNotFailLoan(t *testing.T) {
+	store := new(mockLoanContractStore)
+	service, loanRepo, itemRepo, customerRepo := setupLoanServiceWithContracts(true, store)
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+	store.On("StoreLoanContract", ctx, int64(0), int64(7)).Return(nil, errors.New("disk full"))
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	result, err := service.Create(ctx, input)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Nil(t, result.ContractDocumentID)
+	store.AssertExpectations(t)
+}
+
+// --- Loan product tests ---
+
+func setupLoanServiceWithProduct(product *domain.LoanProduct) (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository) {
+	loanRepo := newLoanRepositoryMock()
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	productRepo := new(mocks.MockLoanProductRepository)
+	productRepo.On("GetByID", mock.Anything, product.ID).Return(product, nil)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, new(mocks.MockPaymentRepository), new(mocks.MockCategoryRepository), productRepo, nil, settingRepo, nil, nil, logger)
+	return service, loanRepo, itemRepo, customerRepo
+}
+
+func goldLoanProduct() *domain.LoanProduct {
+	return &domain.LoanProduct{
+		ID:                     4,
+		Name:                   "Préstamo oro 30 días",
+		Code:                   "GOLD30",
+		InterestRate:           12,
+		LoanTermDays:           30,
+		GracePeriodDays:        5,
+		LateFeeRate:            2,
+		PaymentPlanType:        domain.PaymentPlanMinimumPayment,
+		RequiresMinimumPayment: true,
+		IsActive:               true,
+	}
+}
+
+func TestLoanService_Create_InheritsProductTerms(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo := setupLoanServiceWithProduct(goldLoanProduct())
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	productID := int64(4)
+	result, err := service.Create(ctx, CreateLoanInput{
+		CustomerID: 1,
+		ItemID:     1,
+		BranchID:   1,
+		ProductID:  &productID,
+		LoanAmount: 500,
+		CreatedBy:  1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, result.InterestRate)
+	assert.Equal(t, 60.0, result.InterestAmount)
+	assert.Equal(t, 30, result.LoanTermDays)
+	assert.Equal(t, 5, result.GracePeriodDays)
+	assert.Equal(t, 2.0, result.LateFeeRate)
+	assert.Equal(t, domain.PaymentPlanMinimumPayment, result.PaymentPlanType)
+	assert.True(t, result.RequiresMinimumPayment)
+}
+
+func TestLoanService_Create_ExplicitFieldsOverrideProduct(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo := setupLoanServiceWithProduct(goldLoanProduct())
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	productID := int64(4)
+	result, err := service.Create(ctx, CreateLoanInput{
+		CustomerID:      1,
+		ItemID:          1,
+		BranchID:        1,
+		ProductID:       &productID,
+		LoanAmount:      500,
+		InterestRate:    8,
+		LoanTermDays:    45,
+		PaymentPlanType: "single",
+		CreatedBy:       1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 8.0, result.InterestRate)
+	assert.Equal(t, 45, result.LoanTermDays)
+	assert.Equal(t, domain.PaymentPlanSingle, result.PaymentPlanType)
+	// Fields left empty still come from the product
+	assert.Equal(t, 5, result.GracePeriodDays)
+	assert.Equal(t, 2.0, result.LateFeeRate)
+}
+
+func TestLoanService_Create_ExplicitZeroTermsOverrideProduct(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo := setupLoanServiceWithProduct(goldLoanProduct())
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	productID := int64(4)
+	gracePeriodDays := 0
+	lateFeeRate := 0.0
+	requiresMinimumPayment := false
+	result, err := service.Create(ctx, CreateLoanInput{
+		CustomerID:             1,
+		ItemID:                 1,
+		BranchID:               1,
+		ProductID:              &productID,
+		LoanAmount:             500,
+		GracePeriodDays:        &gracePeriodDays,
+		LateFeeRate:            &lateFeeRate,
+		RequiresMinimumPayment: &requiresMinimumPayment,
+		CreatedBy:              1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.GracePeriodDays)
+	assert.Equal(t, 0.0, result.LateFeeRate)
+	assert.False(t, result.RequiresMinimumPayment)
+	// Fields left empty still come from the product
+	assert.Equal(t, 12.0, result.InterestRate)
+}
+
+func TestLoanService_Create_ProductGracePeriodValidated(t *testing.T) {
+	product := goldLoanProduct()
+	product.GracePeriodDays = 45
+	service, _, itemRepo, customerRepo := setupLoanServiceWithProduct(product)
+	ctx := context.Background()
 
-	input := RenewLoanInput{
-		LoanID:      1,
-		NewTermDays: 30,
-		UpdatedBy:   1,
-	}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
 
-	result, err := service.Renew(ctx, input)
+	productID := int64(4)
+	result, err := service.Create(ctx, CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, ProductID: &productID, LoanAmount: 500, CreatedBy: 1})
 
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "LN-000002", result.LoanNumber)
-	assert.Equal(t, domain.LoanStatusActive, result.Status)
-	assert.Equal(t, 500.0, result.LoanAmount)               // Remaining principal
-	assert.Equal(t, loan.InterestRate, result.InterestRate)  // Same rate since NewInterestRate=0
-	assert.Equal(t, int64(1), *result.RenewedFromID)
-	assert.Equal(t, 1, result.RenewalCount)
-	loanRepo.AssertExpectations(t)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInvalidInput)
 }
 
-func TestLoanService_Renew_WithNewInterestRate(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_InactiveProductRejected(t *testing.T) {
+	product := goldLoanProduct()
+	product.IsActive = false
+	service, _, _, _ := setupLoanServiceWithProduct(product)
+
+	productID := int64(4)
+	result, err := service.Create(context.Background(), CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, ProductID: &productID, LoanAmount: 500})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "loan product is not active")
+}
+
+// --- Cash disbursement rounding tests ---
+
+func TestLoanService_Create_RoundsCashDisbursementToDenomination(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingCashDisbursementDenomination: 5.0,
+	})
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:                 1,
-		BranchID:           1,
-		CustomerID:         1,
-		ItemID:             1,
-		InterestRate:       10,
-		PrincipalRemaining: 500,
-		Status:             domain.LoanStatusOverdue,
-		PaymentPlanType:    domain.PaymentPlanType("single"),
-	}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
-	loanRepo.On("GenerateNumber", ctx).Return("LN-000003", nil)
-	loanRepo.On("Create", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 503.40, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", DisbursementMethod: "cash", CreatedBy: 1}
 
-	input := RenewLoanInput{
-		LoanID:          1,
-		NewTermDays:     60,
-		NewInterestRate: 15,
-		UpdatedBy:       1,
-	}
+	result, err := service.Create(ctx, input)
 
-	result, err := service.Renew(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, 505.0, result.LoanAmount)
+	assert.Equal(t, 505.0, result.PrincipalRemaining)
+	assert.Equal(t, 1.6, result.DisbursementRounding)
+	assert.InDelta(t, 50.5, result.InterestAmount, 0.001)
+	loanRepo.AssertCalled(t, "CreateTx", ctx, mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.LoanAmount == 505 && loan.DisbursementRounding == 1.6
+	}))
+}
 
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, 15.0, result.InterestRate)
-	assert.Equal(t, 75.0, result.InterestAmount) // 500 * 15/100
+func TestLoanService_Create_RoundsDownWhenOverItemLoanValue(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingCashDisbursementDenomination: 5.0,
+	})
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 503.40}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 503.40, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 1}
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, result.LoanAmount)
+	assert.Equal(t, -3.4, result.DisbursementRounding)
 }
 
-func TestLoanService_Renew_NotFound(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_RejectsAmountOverItemLoanValueBeforeRounding(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingCashDisbursementDenomination: 100.0,
+	})
 	ctx := context.Background()
 
-	loanRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	loanRepo.On("GetActiveByItemID", ctx, int64(1)).Return(nil, nil).Maybe()
+	expectPawnedItems(loanRepo, 1, 0, 0)
 
-	input := RenewLoanInput{LoanID: 999}
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 1049, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 1}
 
-	result, err := service.Renew(ctx, input)
+	result, err := service.Create(ctx, input)
 
-	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Equal(t, "loan not found", err.Error())
+	assert.EqualError(t, err, "loan amount cannot exceed item loan value")
+	loanRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
 }
 
-func TestLoanService_Renew_InvalidStatus(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_NoRoundingForTransfers(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithSettings(map[string]interface{}{
+		SettingCashDisbursementDenomination: 5.0,
+	})
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:     1,
-		Status: domain.LoanStatusPaid,
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 503.40, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", DisbursementMethod: "transfer", CreatedBy: 1}
+
+	result, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, 503.40, result.LoanAmount)
+	assert.Zero(t, result.DisbursementRounding)
+}
+
+// --- Campaign tests ---
+
+func setupLoanServiceWithCampaigns(campaigns []*domain.Campaign) (*LoanService, *mocks.MockLoanRepository, *mocks.MockItemRepository, *mocks.MockCustomerRepository, *mocks.MockCampaignRepository) {
+	loanRepo := newLoanRepositoryMock()
+	itemRepo := new(mocks.MockItemRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	campaignRepo := new(mocks.MockCampaignRepository)
+	campaignRepo.On("ListRunning", mock.Anything, domain.Today()).Return(campaigns, nil)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	service := NewLoanService(loanRepo, itemRepo, customerRepo, new(mocks.MockPaymentRepository), new(mocks.MockCategoryRepository), nil, campaignRepo, settingRepo, nil, nil, logger)
+	return service, loanRepo, itemRepo, customerRepo, campaignRepo
+}
+
+func firstWeekCampaign(start domain.Date) *domain.Campaign {
+	return &domain.Campaign{
+		ID:                      7,
+		Name:                    "Primera semana sin intereses",
+		Code:                    "NOINT7",
+		StartDate:               start,
+		EndDate:                 domain.DateFromTime(start.AddDate(0, 0, 6)),
+		InterestDiscountPercent: 100,
+		LateFeeDiscountPercent:  50,
+		IsActive:                true,
 	}
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+}
 
-	input := RenewLoanInput{LoanID: 1}
+func campaignLoanInput() CreateLoanInput {
+	lateFeeRate := 2.0
+	return CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", LateFeeRate: &lateFeeRate, CreatedBy: 1}
+}
 
-	result, err := service.Renew(ctx, input)
+func TestLoanService_Create_AppliesRunningCampaign(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithCampaigns([]*domain.Campaign{firstWeekCampaign(domain.Today())})
+	ctx := context.Background()
 
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Equal(t, "only active or overdue loans can be renewed", err.Error())
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	result, err := service.Create(ctx, campaignLoanInput())
+
+	require.NoError(t, err)
+	require.NotNil(t, result.CampaignID)
+	assert.Equal(t, int64(7), *result.CampaignID)
+	assert.Equal(t, 50.0, result.CampaignInterestDiscount)
+	assert.Equal(t, 0.0, result.InterestAmount)
+	assert.Equal(t, 0.0, result.InterestRemaining)
+	assert.Equal(t, 500.0, result.TotalAmount)
+	assert.Equal(t, 1.0, result.LateFeeRate)
 }
 
-func TestLoanService_Renew_InterestNotPaid(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Create_IgnoresCampaignOutsideWindow(t *testing.T) {
+	ended := firstWeekCampaign(domain.DateFromTime(domain.Today().AddDate(0, 0, -10)))
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithCampaigns([]*domain.Campaign{ended})
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:                1,
-		Status:            domain.LoanStatusActive,
-		InterestRemaining: 50,
-	}
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	input := RenewLoanInput{LoanID: 1, PayInterest: true}
+	result, err := service.Create(ctx, campaignLoanInput())
 
-	result, err := service.Renew(ctx, input)
+	require.NoError(t, err)
+	assert.Nil(t, result.CampaignID)
+	assert.Equal(t, 0.0, result.CampaignInterestDiscount)
+	assert.Equal(t, 50.0, result.InterestAmount)
+	assert.Equal(t, 550.0, result.TotalAmount)
+	assert.Equal(t, 2.0, result.LateFeeRate)
+}
 
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Equal(t, "interest must be paid before renewal", err.Error())
+func TestLoanService_Create_FlaggedCampaignRequiresFlaggedCustomer(t *testing.T) {
+	campaign := firstWeekCampaign(domain.Today())
+	campaign.FlaggedCustomersOnly = true
+	service, loanRepo, itemRepo, customerRepo, campaignRepo := setupLoanServiceWithCampaigns([]*domain.Campaign{campaign})
+	ctx := context.Background()
+
+	campaignRepo.On("IsCustomerFlagged", ctx, int64(7), int64(1)).Return(false, nil)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	result, err := service.Create(ctx, campaignLoanInput())
+
+	require.NoError(t, err)
+	assert.Nil(t, result.CampaignID)
+	assert.Equal(t, 50.0, result.InterestAmount)
+	campaignRepo.AssertExpectations(t)
 }
 
-// --- Confiscate tests ---
+// --- Quote tests ---
 
-func TestLoanService_Confiscate_Success(t *testing.T) {
+func TestLoanService_Quote_MatchesCreatedLoan(t *testing.T) {
 	service, loanRepo, itemRepo, customerRepo, _ := setupLoanService()
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:                 1,
-		ItemID:             10,
-		CustomerID:         20,
-		Status:             domain.LoanStatusOverdue,
-		PrincipalRemaining: 500.00,
-		InterestRemaining:  50.00,
-		LateFeeAmount:      10.00,
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+	var installments []*domain.LoanInstallment
+	loanRepo.On("CreateInstallmentsTx", ctx, mock.Anything, mock.AnythingOfType("[]*domain.LoanInstallment")).
+		Run(func(args mock.Arguments) {
+			installments = args.Get(2).([]*domain.LoanInstallment)
+		}).Return(nil)
+
+	gracePeriodDays, lateFeeRate := 5, 1.5
+	loan, err := service.Create(ctx, CreateLoanInput{
+		CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 600, InterestRate: 12, LoanTermDays: 90,
+		PaymentPlanType: "installments", NumberOfInstallments: 3, GracePeriodDays: &gracePeriodDays, LateFeeRate: &lateFeeRate, CreatedBy: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, installments, 3)
+
+	quote, err := service.Quote(ctx, LoanQuoteInput{
+		BranchID: 1, Amount: 600, InterestRate: 12, TermDays: 90, GracePeriodDays: &gracePeriodDays,
+		PaymentPlanType: "installments", NumberOfInstallments: 3, LateFeeRate: 1.5,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, LoanQuoteModeItemized, quote.Mode)
+	assert.Equal(t, loan.LoanAmount, quote.LoanAmount)
+	assert.Equal(t, loan.InterestAmount, *quote.InterestAmount)
+	assert.Equal(t, loan.TotalAmount, quote.TotalAmount)
+	assert.Equal(t, loan.LateFeeRate, quote.LateFeeRate)
+	assert.Equal(t, loan.LoanTermDays, quote.LoanTermDays)
+	assert.Equal(t, loan.DueDate, quote.DueDate)
+	assert.Equal(t, loan.GracePeriodEnd(), quote.GracePeriodEnd)
+	require.Len(t, quote.Schedule, len(installments))
+	for i, inst := range installments {
+		assert.Equal(t, domain.DateFromTime(inst.DueDate), quote.Schedule[i].DueDate)
+		assert.Equal(t, inst.PrincipalAmount, *quote.Schedule[i].PrincipalAmount)
+		assert.Equal(t, inst.InterestAmount, *quote.Schedule[i].InterestAmount)
+		assert.Equal(t, inst.TotalAmount, quote.Schedule[i].TotalAmount)
 	}
+}
 
-	customer := &domain.Customer{
-		ID:             20,
-		FirstName:      "John",
-		LastName:       "Doe",
-		TotalDefaulted: 0,
-	}
+func TestLoanService_Quote_MatchesCreatedLoanWithCampaign(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithCampaigns([]*domain.Campaign{firstWeekCampaign(domain.Today())})
+	ctx := context.Background()
 
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
-	itemRepo.On("UpdateStatus", ctx, int64(10), domain.ItemStatusConfiscated).Return(nil)
-	customerRepo.On("GetByID", ctx, int64(20)).Return(customer, nil)
-	customerRepo.On("UpdateCreditInfo", ctx, int64(20), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	err := service.Confiscate(ctx, 1, 1, "Loan overdue")
+	loan, err := service.Create(ctx, campaignLoanInput())
+	require.NoError(t, err)
 
-	assert.NoError(t, err)
+	quote, err := service.Quote(ctx, LoanQuoteInput{BranchID: 1, Amount: 500, InterestRate: 10, TermDays: 30, LateFeeRate: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, loan.InterestAmount, *quote.InterestAmount)
+	assert.Equal(t, loan.CampaignInterestDiscount, *quote.CampaignInterestDiscount)
+	assert.Equal(t, loan.TotalAmount, quote.TotalAmount)
+	assert.Equal(t, loan.LateFeeRate, quote.LateFeeRate)
+	assert.Equal(t, loan.DueDate, quote.DueDate)
+	require.Len(t, quote.Schedule, 1)
+	assert.Equal(t, loan.DueDate, quote.Schedule[0].DueDate)
+	assert.Equal(t, loan.TotalAmount, quote.Schedule[0].TotalAmount)
 }
 
-func TestLoanService_Confiscate_InvalidStatus(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Calculate_MatchesCreatedLoanWithCampaign(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, _ := setupLoanServiceWithCampaigns([]*domain.Campaign{firstWeekCampaign(domain.Today())})
 	ctx := context.Background()
 
-	loan := &domain.Loan{
-		ID:     1,
-		Status: domain.LoanStatusActive,
-	}
-	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	err := service.Confiscate(ctx, 1, 1, "test")
+	calculation, err := service.Calculate(ctx, campaignLoanInput())
+	require.NoError(t, err)
+	loan, err := service.Create(ctx, campaignLoanInput())
+	require.NoError(t, err)
 
-	assert.Error(t, err)
-	assert.Equal(t, "only defaulted or overdue loans can be confiscated", err.Error())
+	assert.Equal(t, loan.InterestAmount, calculation.InterestAmount)
+	assert.Equal(t, loan.CampaignInterestDiscount, calculation.CampaignInterestDiscount)
+	assert.Equal(t, loan.TotalAmount, calculation.TotalAmount)
+	assert.Equal(t, loan.InterestMethod, calculation.InterestMethod)
 }
 
-func TestLoanService_Confiscate_NotFound(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+func TestLoanService_Quote_AllInHidesBreakdown(t *testing.T) {
+	service, _, _, _, _ := setupLoanService()
 	ctx := context.Background()
 
-	loanRepo.On("GetByID", ctx, int64(999)).Return(nil, errors.New("not found"))
+	quote, err := service.Quote(ctx, LoanQuoteInput{
+		BranchID: 1, Amount: 600, InterestRate: 12, PaymentPlanType: "installments", NumberOfInstallments: 3,
+		Mode: LoanQuoteModeAllIn,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 672.0, quote.TotalAmount)
+	assert.Nil(t, quote.InterestAmount)
+	assert.Nil(t, quote.InterestRate)
+	require.Len(t, quote.Schedule, 3)
+	for _, period := range quote.Schedule {
+		assert.Nil(t, period.PrincipalAmount)
+		assert.Nil(t, period.InterestAmount)
+		assert.InDelta(t, 224.0, period.TotalAmount, 0.001)
+	}
+}
 
-	err := service.Confiscate(ctx, 999, 1, "test")
+func TestLoanService_Quote_UsesBranchDefaults(t *testing.T) {
+	service, _, _, _, _ := setupLoanService()
+	ctx := context.Background()
+	branchRepo := new(mocks.MockBranchRepository)
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{
+		ID: 1, DefaultInterestRate: 15, DefaultLoanTermDays: 30, DefaultGracePeriod: 10,
+	}, nil)
+	service.SetBranches(branchRepo)
+
+	quote, err := service.Quote(ctx, LoanQuoteInput{BranchID: 1, Amount: 1000})
+
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, *quote.InterestAmount)
+	assert.Equal(t, 1150.0, quote.TotalAmount)
+	assert.Equal(t, 30, quote.LoanTermDays)
+	assert.Equal(t, 10, quote.GracePeriodDays)
+	assert.Equal(t, domain.DateFromTime(domain.Today().AddDate(0, 0, 30)), quote.DueDate)
+	assert.Equal(t, domain.DateFromTime(domain.Today().AddDate(0, 0, 40)), quote.GracePeriodEnd)
+}
 
-	assert.Error(t, err)
-	assert.Equal(t, "loan not found", err.Error())
+func TestLoanService_Quote_UsesBranchInterestMethod(t *testing.T) {
+	service, _, _, _, _ := setupLoanServiceWithSettings(map[string]interface{}{SettingLoanInterestMethod: "simple"})
+	ctx := context.Background()
+
+	quote, err := service.Quote(ctx, LoanQuoteInput{BranchID: 1, Amount: 1000, InterestRate: 10, TermDays: 60})
+
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, *quote.InterestAmount)
+
+	// A loan can still ask for another method
+	quote, err = service.Quote(ctx, LoanQuoteInput{BranchID: 1, Amount: 1000, InterestRate: 10, TermDays: 60, InterestMethod: "flat"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, *quote.InterestAmount)
 }
 
-// --- GetOverdueLoans tests ---
+func TestLoanService_Quote_InvalidMode(t *testing.T) {
+	service, _, _, _, _ := setupLoanService()
 
-func TestLoanService_GetOverdueLoans_Success(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+	_, err := service.Quote(context.Background(), LoanQuoteInput{Amount: 500, InterestRate: 10, TermDays: 30, Mode: "summary"})
+
+	assert.ErrorIs(t, err, ErrInvalidLoanQuoteMode)
+}
+
+func withTwoPersonThreshold(service *LoanService, threshold float64) {
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingLoanTwoPersonThreshold, mock.Anything).
+		Return(&domain.Setting{Key: SettingLoanTwoPersonThreshold, Value: threshold}, nil).Maybe()
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found")).Maybe()
+	service.settingRepo = settingRepo
+}
+
+func TestLoanService_Create_HighValueDisbursementWaitsForSecondUser(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	withTwoPersonThreshold(service, 400)
 	ctx := context.Background()
 
-	dueDate := time.Now().Add(-24 * time.Hour)
-	loans := []*domain.Loan{
-		{ID: 1, LoanNumber: "LN-000001", DueDate: dueDate, Status: domain.LoanStatusActive},
-	}
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
+
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	loan, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusPendingAuthorization, loan.Status)
+	assert.True(t, loan.AwaitingAuthorization())
+	movementRepo.AssertNotCalled(t, "CreateTx", mock.Anything, mock.Anything, mock.Anything)
+
+	// A second user co-signs and only then does the cash leave the drawer
+	loan.ID = 40
+	loanRepo.On("GetByID", ctx, int64(40)).Return(loan, nil)
+	loanRepo.On("AuthorizeDisbursement", ctx, int64(40), int64(8)).Return(nil)
+	movementRepo.On("Create", ctx, mock.MatchedBy(func(m *domain.CashMovement) bool {
+		return m.SessionID == 3 && m.Amount == 500 && m.CreatedBy == 7 &&
+			m.ReferenceID != nil && *m.ReferenceID == 40
+	})).Return(nil)
+
+	authorized, err := service.AuthorizeDisbursement(ctx, 40, 8)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), *authorized.AuthorizedBy)
+	assert.Equal(t, int64(7), authorized.CreatedBy)
+	assert.Equal(t, domain.LoanStatusActive, authorized.Status)
+	assert.False(t, authorized.AwaitingAuthorization())
+	movementRepo.AssertExpectations(t)
+}
 
-	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(loans, nil)
+func TestLoanService_Create_UnderTwoPersonThresholdDisbursesRightAway(t *testing.T) {
+	service, loanRepo, itemRepo, customerRepo, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	withTwoPersonThreshold(service, 500)
+	ctx := context.Background()
 
-	result, err := service.GetOverdueLoans(ctx, 1)
+	customer := &domain.Customer{ID: 1, IsActive: true, BirthDate: adultBirthDate()}
+	item := &domain.Item{ID: 1, Status: domain.ItemStatusAvailable, LoanValue: 1000}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	customerRepo.On("GetByID", ctx, int64(1)).Return(customer, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	movementRepo.On("CreateTx", ctx, mock.Anything, mock.AnythingOfType("*domain.CashMovement")).Return(nil)
+	expectLoanCreation(ctx, loanRepo, itemRepo, customerRepo)
 
-	assert.NoError(t, err)
-	assert.Len(t, result, 1)
+	input := CreateLoanInput{CustomerID: 1, ItemID: 1, BranchID: 1, LoanAmount: 500, InterestRate: 10, LoanTermDays: 30, PaymentPlanType: "single", CreatedBy: 7}
+
+	loan, err := service.Create(ctx, input)
+
+	require.NoError(t, err)
+	assert.False(t, loan.AuthorizationRequired)
+	movementRepo.AssertExpectations(t)
 }
 
-// --- UpdateOverdueStatus tests ---
+func TestLoanService_AuthorizeDisbursement_SelfAuthorizationRejected(t *testing.T) {
+	service, loanRepo, _, _, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	ctx := context.Background()
 
-func TestLoanService_UpdateOverdueStatus_Success(t *testing.T) {
-	service, loanRepo, _, _, _ := setupLoanService()
+	loan := &domain.Loan{ID: 40, LoanAmount: 5000, Status: domain.LoanStatusPendingAuthorization, AuthorizationRequired: true, CreatedBy: 7}
+	loanRepo.On("GetByID", ctx, int64(40)).Return(loan, nil)
+
+	result, err := service.AuthorizeDisbursement(ctx, 40, 7)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrSelfAuthorization)
+	loanRepo.AssertNotCalled(t, "AuthorizeDisbursement", mock.Anything, mock.Anything, mock.Anything)
+	sessionRepo.AssertNotCalled(t, "GetOpenSession", mock.Anything, mock.Anything)
+	movementRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLoanService_AuthorizeDisbursement_AlreadyAuthorized(t *testing.T) {
+	service, loanRepo, _, _, _, _ := setupLoanServiceWithCash()
 	ctx := context.Background()
 
-	// Loan past due but in grace period
-	graceLoan := &domain.Loan{
-		ID:              1,
-		Status:          domain.LoanStatusActive,
-		DueDate:         time.Now().Add(-2 * 24 * time.Hour),
-		GracePeriodDays: 7,
-	}
+	authorizedBy := int64(8)
+	loan := &domain.Loan{ID: 40, Status: domain.LoanStatusActive, AuthorizationRequired: true, AuthorizedBy: &authorizedBy, CreatedBy: 7}
+	loanRepo.On("GetByID", ctx, int64(40)).Return(loan, nil)
 
-	// Loan past due and past grace period
-	defaultedLoan := &domain.Loan{
-		ID:              2,
-		Status:          domain.LoanStatusOverdue,
-		DueDate:         time.Now().Add(-30 * 24 * time.Hour),
-		GracePeriodDays: 7,
-	}
+	_, err := service.AuthorizeDisbursement(ctx, 40, 9)
 
-	loans := []*domain.Loan{graceLoan, defaultedLoan}
+	assert.ErrorIs(t, err, ErrLoanNotAwaitingAuthorization)
+}
 
-	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(loans, nil)
-	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+func TestLoanService_AuthorizeDisbursement_DisbursementFailureRevokes(t *testing.T) {
+	service, loanRepo, _, _, sessionRepo, movementRepo := setupLoanServiceWithCash()
+	ctx := context.Background()
 
-	err := service.UpdateOverdueStatus(ctx, 1)
+	loan := &domain.Loan{ID: 40, LoanAmount: 500, Status: domain.LoanStatusPendingAuthorization, AuthorizationRequired: true, CreatedBy: 7}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 2000, Status: domain.CashSessionStatusOpen}
+	loanRepo.On("GetByID", ctx, int64(40)).Return(loan, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(1500.0, nil)
+	loanRepo.On("AuthorizeDisbursement", ctx, int64(40), int64(8)).Return(nil)
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Return(errors.New("db error"))
+	loanRepo.On("RevokeAuthorization", ctx, int64(40)).Return(nil)
 
-	assert.NoError(t, err)
-	assert.Equal(t, domain.LoanStatusOverdue, graceLoan.Status)
-	assert.Equal(t, domain.LoanStatusDefaulted, defaultedLoan.Status)
-	loanRepo.AssertExpectations(t)
+	result, err := service.AuthorizeDisbursement(ctx, 40, 8)
+
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	assert.Equal(t, domain.LoanStatusPendingAuthorization, loan.Status)
+	loanRepo.AssertCalled(t, "RevokeAuthorization", ctx, int64(40))
 }
 
-func TestLoanService_UpdateOverdueStatus_NoLoans(t *testing.T) {
+func payoffQuoteLoan() *domain.Loan {
+	return &domain.Loan{
+		ID:                 7,
+		LoanNumber:         "PR-000007",
+		Status:             domain.LoanStatusActive,
+		LoanAmount:         1000,
+		PrincipalRemaining: 1000,
+		InterestAmount:     100,
+		InterestRemaining:  100,
+		LateFeeRate:        0.5,
+		LoanTermDays:       30,
+		GracePeriodDays:    10,
+		StartDate:          domain.NewDate(2026, time.January, 1),
+		DueDate:            domain.NewDate(2026, time.January, 31),
+	}
+}
+
+func TestLoanService_GetPayoffQuote(t *testing.T) {
+	tests := []struct {
+		name         string
+		asOf         time.Time
+		adjust       func(*domain.Loan)
+		wantInterest float64
+		wantLateFees float64
+		wantTotal    float64
+		wantInGrace  bool
+		wantPastDue  int
+	}{
+		{
+			name:         "before due date charges the interest earned so far",
+			asOf:         time.Date(2026, 1, 16, 15, 0, 0, 0, time.UTC),
+			wantInterest: 50,
+			wantTotal:    1050,
+		},
+		{
+			name:         "interest already paid is not charged again",
+			asOf:         time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC),
+			adjust:       func(l *domain.Loan) { l.InterestRemaining = 60 },
+			wantInterest: 10,
+			wantTotal:    1010,
+		},
+		{
+			name:         "in grace period adds late fees not yet charged",
+			asOf:         time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC),
+			adjust:       func(l *domain.Loan) { l.Status = domain.LoanStatusOverdue },
+			wantInterest: 100,
+			wantLateFees: 15,
+			wantTotal:    1115,
+			wantInGrace:  true,
+			wantPastDue:  3,
+		},
+		{
+			name:         "in grace period counts late fees already charged once",
+			asOf:         time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC),
+			adjust:       func(l *domain.Loan) { l.Status, l.LateFeeAmount, l.LateFeeRemaining = domain.LoanStatusOverdue, 10, 10 },
+			wantInterest: 100,
+			wantLateFees: 15,
+			wantTotal:    1115,
+			wantInGrace:  true,
+			wantPastDue:  3,
+		},
+		{
+			name:         "past the grace period charges the projected late fee",
+			asOf:         time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC),
+			adjust:       func(l *domain.Loan) { l.Status = domain.LoanStatusOverdue },
+			wantInterest: 100,
+			wantLateFees: 100,
+			wantTotal:    1200,
+			wantPastDue:  20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, loanRepo, _, _, _ := setupLoanService()
+			ctx := context.Background()
+			loan := payoffQuoteLoan()
+			if tt.adjust != nil {
+				tt.adjust(loan)
+			}
+			loanRepo.On("GetByID", ctx, loan.ID).Return(loan, nil)
+
+			quote, err := service.GetPayoffQuote(ctx, loan.ID, tt.asOf)
+
+			require.NoError(t, err)
+			assert.Equal(t, "PR-000007", quote.LoanNumber)
+			assert.Equal(t, 1000.0, quote.PrincipalRemaining)
+			assert.InDelta(t, tt.wantInterest, quote.AccruedInterest, 0.001)
+			assert.InDelta(t, loan.InterestRemaining-tt.wantInterest, quote.UnearnedInterest, 0.001)
+			assert.InDelta(t, tt.wantLateFees, quote.LateFees, 0.001)
+			assert.InDelta(t, tt.wantTotal, quote.Total, 0.001)
+			assert.Equal(t, tt.wantInGrace, quote.InGracePeriod)
+			assert.Equal(t, tt.wantPastDue, quote.DaysPastDue)
+			assert.Equal(t, tt.wantPastDue > loan.GracePeriodDays, quote.PastGracePeriod)
+			assert.Equal(t, loan.ProjectedLateFeeAt(tt.asOf), quote.LateFees)
+		})
+	}
+}
+
+func TestLoanService_GetPayoffQuote_Rejections(t *testing.T) {
 	service, loanRepo, _, _, _ := setupLoanService()
 	ctx := context.Background()
+	paid := payoffQuoteLoan()
+	paid.ID = 8
+	paid.Status = domain.LoanStatusPaid
+	loanRepo.On("GetByID", ctx, int64(7)).Return(payoffQuoteLoan(), nil)
+	loanRepo.On("GetByID", ctx, int64(8)).Return(paid, nil)
 
-	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return([]*domain.Loan{}, nil)
+	_, err := service.GetPayoffQuote(ctx, 8, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrInvalidStatus)
 
-	err := service.UpdateOverdueStatus(ctx, 1)
+	_, err = service.GetPayoffQuote(ctx, 7, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
 
-	assert.NoError(t, err)
+func TestLoanService_GenerateInstallmentSchedule(t *testing.T) {
+	tests := []struct {
+		name            string
+		loanAmount      float64
+		totalAmount     float64
+		numInstallments int
+	}{
+		{name: "even split", loanAmount: 1200, totalAmount: 1320, numInstallments: 4},
+		{name: "last installment absorbs cents", loanAmount: 1000, totalAmount: 1100, numInstallments: 3},
+		{name: "odd cents", loanAmount: 999.99, totalAmount: 1123.45, numInstallments: 7},
+		{name: "single installment", loanAmount: 500, totalAmount: 550, numInstallments: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, loanRepo, _, _, _ := setupLoanService()
+			ctx := context.Background()
+			start := domain.DateFromTime(domain.Today().AddDate(0, 0, 1))
+			loan := &domain.Loan{
+				ID:                 3,
+				Status:             domain.LoanStatusActive,
+				LoanAmount:         tt.loanAmount,
+				InterestAmount:     roundCents(tt.totalAmount - tt.loanAmount),
+				TotalAmount:        tt.totalAmount,
+				PrincipalRemaining: tt.loanAmount,
+				InterestRemaining:  roundCents(tt.totalAmount - tt.loanAmount),
+				StartDate:          start,
+				DueDate:            domain.DateFromTime(start.AddDate(0, 3, 0)),
+			}
+			loanRepo.On("GetByID", ctx, int64(3)).Return(loan, nil)
+			loanRepo.On("GetInstallments", ctx, int64(3)).Return([]*domain.LoanInstallment{}, nil)
+			loanRepo.On("ReplaceInstallments", ctx, int64(3), mock.MatchedBy(func(installments []*domain.LoanInstallment) bool {
+				return len(installments) == tt.numInstallments && installments[0].LoanID == 3
+			})).Return(nil)
+
+			schedule, err := service.GenerateInstallmentSchedule(ctx, 3, tt.numInstallments)
+
+			require.NoError(t, err)
+			require.Len(t, schedule, tt.numInstallments)
+
+			var totalCents, principalCents int64
+			for i, item := range schedule {
+				assert.Equal(t, i+1, item.Number)
+				assert.Equal(t, roundCents(item.PrincipalAmount+item.InterestAmount), item.TotalAmount)
+				if i > 0 {
+					assert.True(t, item.DueDate.After(schedule[i-1].DueDate.Time))
+				}
+				totalCents += int64(math.Round(item.TotalAmount * 100))
+				principalCents += int64(math.Round(item.PrincipalAmount * 100))
+			}
+			assert.Equal(t, int64(math.Round(tt.totalAmount*100)), totalCents)
+			assert.Equal(t, int64(math.Round(tt.loanAmount*100)), principalCents)
+			assert.Equal(t, loan.DueDate, schedule[len(schedule)-1].DueDate)
+			loanRepo.AssertExpectations(t)
+		})
+	}
 }
 
-func TestLoanService_UpdateOverdueStatus_Error(t *testing.T) {
+func TestLoanService_GenerateInstallmentSchedule_FromRemainingBalance(t *testing.T) {
 	service, loanRepo, _, _, _ := setupLoanService()
 	ctx := context.Background()
+	today := domain.Today()
+	loan := &domain.Loan{
+		ID:                 3,
+		Status:             domain.LoanStatusActive,
+		LoanAmount:         1200,
+		InterestAmount:     120,
+		TotalAmount:        1320,
+		AmountPaid:         420,
+		PrincipalRemaining: 800,
+		InterestRemaining:  100,
+		StartDate:          domain.DateFromTime(today.AddDate(0, -1, 0)),
+		DueDate:            domain.DateFromTime(today.AddDate(0, 0, 60)),
+	}
+	loanRepo.On("GetByID", ctx, int64(3)).Return(loan, nil)
+	loanRepo.On("GetInstallments", ctx, int64(3)).Return([]*domain.LoanInstallment{}, nil)
+	loanRepo.On("ReplaceInstallments", ctx, int64(3), mock.Anything).Return(nil)
+
+	schedule, err := service.GenerateInstallmentSchedule(ctx, 3, 2)
+
+	require.NoError(t, err)
+	require.Len(t, schedule, 2)
+	assert.Equal(t, 450.0, schedule[0].TotalAmount)
+	assert.Equal(t, 450.0, schedule[1].TotalAmount)
+	// Installments fall due from today on, not from the start of the loan
+	assert.Equal(t, domain.DateFromTime(today.AddDate(0, 0, 30)), schedule[0].DueDate)
+	assert.Equal(t, loan.DueDate, schedule[1].DueDate)
+}
 
-	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(nil, errors.New("db error"))
+func TestLoanService_GetInstallmentSchedule_ReadsStoredSchedule(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+	due := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	loanRepo.On("GetByID", ctx, int64(3)).Return(&domain.Loan{ID: 3, Status: domain.LoanStatusActive}, nil)
+	loanRepo.On("GetInstallments", ctx, int64(3)).Return([]*domain.LoanInstallment{
+		{LoanID: 3, InstallmentNumber: 1, DueDate: due, PrincipalAmount: 400, InterestAmount: 50, TotalAmount: 450},
+	}, nil)
+
+	schedule, err := service.GetInstallmentSchedule(ctx, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, []InstallmentPlanItem{
+		{Number: 1, DueDate: domain.DateFromTime(due), PrincipalAmount: 400, InterestAmount: 50, TotalAmount: 450},
+	}, schedule)
+	loanRepo.AssertNotCalled(t, "ReplaceInstallments", mock.Anything, mock.Anything, mock.Anything)
+}
 
-	err := service.UpdateOverdueStatus(ctx, 1)
+func TestLoanService_GenerateInstallmentSchedule_Rejections(t *testing.T) {
+	service, loanRepo, _, _, _ := setupLoanService()
+	ctx := context.Background()
+	overdue := &domain.Loan{ID: 4, Status: domain.LoanStatusOverdue}
+	started := &domain.Loan{ID: 5, Status: domain.LoanStatusActive}
+	loanRepo.On("GetByID", ctx, int64(4)).Return(overdue, nil)
+	loanRepo.On("GetByID", ctx, int64(5)).Return(started, nil)
+	loanRepo.On("GetByID", ctx, int64(6)).Return(nil, errors.New("not found"))
+	loanRepo.On("GetInstallments", ctx, int64(5)).Return([]*domain.LoanInstallment{
+		{InstallmentNumber: 1, AmountPaid: 100, IsPaid: true},
+	}, nil)
 
-	assert.Error(t, err)
+	_, err := service.GenerateInstallmentSchedule(ctx, 4, 3)
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+
+	_, err = service.GenerateInstallmentSchedule(ctx, 5, 3)
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+
+	_, err = service.GenerateInstallmentSchedule(ctx, 6, 3)
+	assert.ErrorIs(t, err, ErrLoanNotFound)
+
+	_, err = service.GenerateInstallmentSchedule(ctx, 5, 0)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	loanRepo.AssertNotCalled(t, "ReplaceInstallments", mock.Anything, mock.Anything, mock.Anything)
 }
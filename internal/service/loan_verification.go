@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
+)
+
+// LoanVerification is what the public page a contract QR code links to shows
+type LoanVerification struct {
+	LoanNumber string            `json:"loan_number"`
+	Status     domain.LoanStatus `json:"status"`
+	DueDate    domain.Date       `json:"due_date"`
+}
+
+// SetVerificationKey enables checking the signed links of contract QR codes.
+// It must be the key the contracts were signed with.
+func (s *LoanService) SetVerificationKey(key string) {
+	s.verifyKey = []byte(key)
+}
+
+// Verify returns the status of the loan a contract QR code links to. A link
+// with a wrong token is reported as a missing loan, so loan numbers cannot be
+// probed without a printed contract.
+func (s *LoanService) Verify(ctx context.Context, loanNumber, token string) (*LoanVerification, error) {
+	if len(s.verifyKey) == 0 || !pdf.VerifyLoanToken(s.verifyKey, loanNumber, token) {
+		return nil, ErrLoanNotFound
+	}
+
+	loan, err := s.loanRepo.GetByNumber(ctx, loanNumber)
+	if err != nil || loan == nil {
+		return nil, ErrLoanNotFound
+	}
+
+	return &LoanVerification{
+		LoanNumber: loan.LoanNumber,
+		Status:     loan.Status,
+		DueDate:    loan.DueDate,
+	}, nil
+}
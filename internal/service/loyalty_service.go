@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"pawnshop/internal/domain"
@@ -15,6 +16,9 @@ var (
 	ErrAlreadyEnrolled      = errors.New("customer already enrolled in loyalty program")
 )
 
+// ErrInvalidRedemptionIncrement is returned when points are not redeemed in whole blocks
+var ErrInvalidRedemptionIncrement = errors.New("invalid loyalty redemption increment")
+
 // Points earning rates
 const (
 	PointsPerDollarLoan    = 1  // 1 point per dollar of loan principal
@@ -22,6 +26,20 @@ const (
 	PointsRedemptionRate   = 100 // 100 points = 1 dollar discount
 )
 
+// Setting keys for the points to cash conversion. Points are redeemed in whole
+// blocks of SettingLoyaltyRedemptionBlockPoints, each worth
+// SettingLoyaltyRedemptionBlockValue.
+const (
+	SettingLoyaltyRedemptionBlockPoints = "loyalty_redemption_block_points"
+	SettingLoyaltyRedemptionBlockValue  = "loyalty_redemption_block_value"
+)
+
+// DefaultLoyaltyRedemptionBlockValue is what a block of PointsRedemptionRate points is worth by default
+const DefaultLoyaltyRedemptionBlockValue = 1.0
+
+// maxRedeemOptions caps the increments listed as redemption options
+const maxRedeemOptions = 20
+
 // LoyaltyService defines the interface for loyalty program operations
 type LoyaltyService interface {
 	// EnrollCustomer enrolls a customer in the loyalty program
@@ -47,24 +65,79 @@ type LoyaltyService interface {
 
 	// CalculateDiscount calculates the discount amount for a customer
 	CalculateDiscount(ctx context.Context, customerID int64, amount float64) (float64, error)
+
+	// GetRedeemOptions lists the point increments a customer can redeem and their cash value
+	GetRedeemOptions(ctx context.Context, customerID int64, branchID *int64) (*RedeemOptions, error)
+
+	// SetMoneyFormat writes redemption amounts in the currency of the branch
+	SetMoneyFormat(moneyFormat *MoneyFormatService)
 }
 
 type loyaltyService struct {
 	customerRepo repository.CustomerRepository
 	loyaltyRepo  repository.LoyaltyRepository
+	settingRepo  repository.SettingRepository
+	moneyFormat  *MoneyFormatService
 }
 
 // NewLoyaltyService creates a new loyalty service
 func NewLoyaltyService(
 	customerRepo repository.CustomerRepository,
 	loyaltyRepo repository.LoyaltyRepository,
+	settingRepo repository.SettingRepository,
 ) LoyaltyService {
 	return &loyaltyService{
 		customerRepo: customerRepo,
 		loyaltyRepo:  loyaltyRepo,
+		settingRepo:  settingRepo,
 	}
 }
 
+// SetMoneyFormat writes redemption amounts in the currency of the branch
+func (s *loyaltyService) SetMoneyFormat(moneyFormat *MoneyFormatService) {
+	s.moneyFormat = moneyFormat
+}
+
+// moneyFormatFor returns the money format of the branch, the default one when
+// no branch is given
+func (s *loyaltyService) moneyFormatFor(ctx context.Context, branchID *int64) domain.MoneyFormat {
+	if branchID == nil {
+		return moneyFormatFor(ctx, nil, 0)
+	}
+	return moneyFormatFor(ctx, s.moneyFormat, *branchID)
+}
+
+// RedemptionRate converts loyalty points to cash in whole blocks of points
+type RedemptionRate struct {
+	BlockPoints int     `json:"block_points"`
+	BlockValue  float64 `json:"block_value"`
+}
+
+// CashValue returns what the points are worth, counting only whole blocks
+func (r RedemptionRate) CashValue(points int) float64 {
+	return roundCents(float64(points/r.BlockPoints) * r.BlockValue)
+}
+
+// Validate checks the points can be redeemed as whole blocks
+func (r RedemptionRate) Validate(points int) error {
+	if points <= 0 || points%r.BlockPoints != 0 {
+		return fmt.Errorf("%w: points must be redeemed in multiples of %d", ErrInvalidRedemptionIncrement, r.BlockPoints)
+	}
+	return nil
+}
+
+// redemptionRate reads the points to cash conversion of a branch
+func (s *loyaltyService) redemptionRate(ctx context.Context, branchID *int64) RedemptionRate {
+	rate := RedemptionRate{
+		BlockPoints: getSettingInt(ctx, s.settingRepo, SettingLoyaltyRedemptionBlockPoints, branchID, PointsRedemptionRate),
+		BlockValue:  getSettingFloat(ctx, s.settingRepo, SettingLoyaltyRedemptionBlockValue, branchID, DefaultLoyaltyRedemptionBlockValue),
+	}
+	if rate.BlockPoints <= 0 {
+		rate.BlockPoints = PointsRedemptionRate
+	}
+	return rate
+}
+
 // CustomerLoyaltyInfo contains loyalty information for a customer
 type CustomerLoyaltyInfo struct {
 	CustomerID     int64      `json:"customer_id"`
@@ -131,8 +204,14 @@ func (s *loyaltyService) GetCustomerLoyalty(ctx context.Context, customerID int6
 		return nil, ErrCustomerNotFound
 	}
 
+	return customerLoyaltyInfo(customer), nil
+}
+
+// customerLoyaltyInfo builds the loyalty summary of a customer, including the
+// points left to reach the next tier
+func customerLoyaltyInfo(customer *domain.Customer) *CustomerLoyaltyInfo {
 	info := &CustomerLoyaltyInfo{
-		CustomerID:   customerID,
+		CustomerID:   customer.ID,
 		Points:       customer.LoyaltyPoints,
 		Tier:         customer.LoyaltyTier,
 		TierDiscount: domain.GetLoyaltyDiscount(customer.LoyaltyTier),
@@ -164,7 +243,7 @@ func (s *loyaltyService) GetCustomerLoyalty(ctx context.Context, customerID int6
 		info.PointsToNextTier = 0
 	}
 
-	return info, nil
+	return info
 }
 
 func (s *loyaltyService) AddPoints(ctx context.Context, req AddPointsRequest) (*domain.LoyaltyPointsHistory, error) {
@@ -230,6 +309,12 @@ func (s *loyaltyService) RedeemPoints(ctx context.Context, req RedeemPointsReque
 		return nil, ErrLoyaltyNotEnrolled
 	}
 
+	// Points are only redeemed in whole blocks
+	rate := s.redemptionRate(ctx, req.BranchID)
+	if err := rate.Validate(req.Points); err != nil {
+		return nil, err
+	}
+
 	// Check if enough points
 	if customer.LoyaltyPoints < req.Points {
 		return nil, ErrInsufficientPoints
@@ -250,6 +335,11 @@ func (s *loyaltyService) RedeemPoints(ctx context.Context, req RedeemPointsReque
 		return nil, err
 	}
 
+	description := req.Description
+	if description == "" {
+		description = fmt.Sprintf("Canje de %d puntos por %s", req.Points, s.moneyFormatFor(ctx, req.BranchID).Format(rate.CashValue(req.Points)))
+	}
+
 	// Create history record
 	history := &domain.LoyaltyPointsHistory{
 		CustomerID:    req.CustomerID,
@@ -258,7 +348,7 @@ func (s *loyaltyService) RedeemPoints(ctx context.Context, req RedeemPointsReque
 		PointsBalance: newBalance,
 		ReferenceType: req.ReferenceType,
 		ReferenceID:   req.ReferenceID,
-		Description:   req.Description,
+		Description:   description,
 		CreatedBy:     req.CreatedBy,
 		CreatedAt:     time.Now(),
 	}
@@ -320,3 +410,62 @@ func (s *loyaltyService) CalculateDiscount(ctx context.Context, customerID int64
 	discount := domain.GetLoyaltyDiscount(customer.LoyaltyTier)
 	return amount * discount, nil
 }
+
+// RedeemOption is a number of points a customer can redeem and what it is worth
+type RedeemOption struct {
+	Points    int     `json:"points"`
+	CashValue float64 `json:"cash_value"`
+	Display   string  `json:"display"`
+}
+
+// RedeemOptions lists the redemptions a customer's balance allows
+type RedeemOptions struct {
+	CustomerID          int64          `json:"customer_id"`
+	Points              int            `json:"points"`
+	Rate                RedemptionRate `json:"rate"`
+	MaxRedeemablePoints int            `json:"max_redeemable_points"`
+	MaxCashValue        float64        `json:"max_cash_value"`
+	Options             []RedeemOption `json:"options"`
+}
+
+// GetRedeemOptions lists the whole-block redemptions the customer's balance
+// allows. Large balances list the smallest increments and the full balance.
+func (s *loyaltyService) GetRedeemOptions(ctx context.Context, customerID int64, branchID *int64) (*RedeemOptions, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	if customer.LoyaltyEnrolledAt == nil {
+		return nil, ErrLoyaltyNotEnrolled
+	}
+
+	rate := s.redemptionRate(ctx, branchID)
+	blocks := customer.LoyaltyPoints / rate.BlockPoints
+	options := &RedeemOptions{
+		CustomerID:          customer.ID,
+		Points:              customer.LoyaltyPoints,
+		Rate:                rate,
+		MaxRedeemablePoints: blocks * rate.BlockPoints,
+		MaxCashValue:        rate.CashValue(blocks * rate.BlockPoints),
+		Options:             []RedeemOption{},
+	}
+
+	format := s.moneyFormatFor(ctx, branchID)
+	for block := 1; block <= blocks; block++ {
+		if len(options.Options) == maxRedeemOptions-1 && block < blocks {
+			block = blocks
+		}
+		points := block * rate.BlockPoints
+		value := rate.CashValue(points)
+		options.Options = append(options.Options, RedeemOption{
+			Points:    points,
+			CashValue: value,
+			Display:   fmt.Sprintf("%d puntos = %s", points, format.Format(value)),
+		})
+	}
+
+	return options, nil
+}
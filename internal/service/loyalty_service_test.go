@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository/mocks"
 )
@@ -15,7 +16,7 @@ import (
 func setupLoyaltyService() (LoyaltyService, *mocks.MockCustomerRepository, *mocks.MockLoyaltyRepository) {
 	customerRepo := new(mocks.MockCustomerRepository)
 	loyaltyRepo := new(mocks.MockLoyaltyRepository)
-	service := NewLoyaltyService(customerRepo, loyaltyRepo)
+	service := NewLoyaltyService(customerRepo, loyaltyRepo, nil)
 	return service, customerRepo, loyaltyRepo
 }
 
@@ -285,6 +286,83 @@ func TestLoyaltyService_RedeemPoints_NotEnrolled(t *testing.T) {
 	customerRepo.AssertExpectations(t)
 }
 
+func setupLoyaltyServiceWithRate(blockPoints, blockValue float64) (LoyaltyService, *mocks.MockCustomerRepository, *mocks.MockLoyaltyRepository) {
+	customerRepo := new(mocks.MockCustomerRepository)
+	loyaltyRepo := new(mocks.MockLoyaltyRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingLoyaltyRedemptionBlockPoints, mock.Anything).Return(&domain.Setting{Value: blockPoints}, nil)
+	settingRepo.On("Get", mock.Anything, SettingLoyaltyRedemptionBlockValue, mock.Anything).Return(&domain.Setting{Value: blockValue}, nil)
+	service := NewLoyaltyService(customerRepo, loyaltyRepo, settingRepo)
+	return service, customerRepo, loyaltyRepo
+}
+
+func TestLoyaltyService_RedeemPoints_InvalidIncrement(t *testing.T) {
+	service, customerRepo, loyaltyRepo := setupLoyaltyServiceWithRate(250, 5)
+	ctx := context.Background()
+
+	enrolledAt := time.Now()
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, LoyaltyEnrolledAt: &enrolledAt, LoyaltyPoints: 1500}, nil)
+
+	_, err := service.RedeemPoints(ctx, RedeemPointsRequest{CustomerID: 1, Points: 600})
+
+	assert.ErrorIs(t, err, ErrInvalidRedemptionIncrement)
+	customerRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	loyaltyRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+}
+
+func TestLoyaltyService_RedeemPoints_ValidIncrementRecordsCashValue(t *testing.T) {
+	service, customerRepo, loyaltyRepo := setupLoyaltyServiceWithRate(250, 5)
+	ctx := context.Background()
+
+	enrolledAt := time.Now()
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, LoyaltyEnrolledAt: &enrolledAt, LoyaltyPoints: 1500}, nil)
+	customerRepo.On("Update", ctx, mock.AnythingOfType("*domain.Customer")).Return(nil)
+	loyaltyRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.LoyaltyPointsHistory")).Return(nil)
+
+	history, err := service.RedeemPoints(ctx, RedeemPointsRequest{CustomerID: 1, Points: 750})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 750, history.PointsBalance)
+	assert.Equal(t, "Canje de 750 puntos por Q15.00", history.Description)
+}
+
+func TestLoyaltyService_GetRedeemOptions_WholeBlocks(t *testing.T) {
+	service, customerRepo, _ := setupLoyaltyServiceWithRate(250, 5)
+	ctx := context.Background()
+
+	enrolledAt := time.Now()
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, LoyaltyEnrolledAt: &enrolledAt, LoyaltyPoints: 780}, nil)
+
+	options, err := service.GetRedeemOptions(ctx, 1, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 750, options.MaxRedeemablePoints)
+	assert.Equal(t, 15.0, options.MaxCashValue)
+	assert.Equal(t, []RedeemOption{
+		{Points: 250, CashValue: 5, Display: "250 puntos = Q5.00"},
+		{Points: 500, CashValue: 10, Display: "500 puntos = Q10.00"},
+		{Points: 750, CashValue: 15, Display: "750 puntos = Q15.00"},
+	}, options.Options)
+}
+
+func TestLoyaltyService_GetRedeemOptions_UsesBranchCurrency(t *testing.T) {
+	service, customerRepo, _ := setupLoyaltyServiceWithRate(250, 5)
+	moneyFormat, branchRepo := setupMoneyFormatService(false)
+	service.SetMoneyFormat(moneyFormat)
+	ctx := context.Background()
+
+	enrolledAt := time.Now()
+	branchID := int64(2)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, LoyaltyEnrolledAt: &enrolledAt, LoyaltyPoints: 500}, nil)
+	branchRepo.On("GetByID", ctx, branchID).Return(&domain.Branch{ID: 2, Currency: "USD"}, nil)
+
+	options, err := service.GetRedeemOptions(ctx, 1, &branchID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "250 puntos = $5.00", options.Options[0].Display)
+	assert.Equal(t, "500 puntos = $10.00", options.Options[1].Display)
+}
+
 func TestLoyaltyService_GetPointsHistory_Success(t *testing.T) {
 	service, _, loyaltyRepo := setupLoyaltyService()
 	ctx := context.Background()
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Settings controlling the automatic markdown of aging for-sale inventory
+const (
+	// SettingMarkdownEnabled turns the automatic markdown on for a branch
+	SettingMarkdownEnabled = "markdown_enabled"
+	// SettingMarkdownRules is the list of markdown steps by days listed
+	SettingMarkdownRules = "markdown_rules"
+	// SettingMarkdownFloorPercent is the lowest sale price, as a percent of the appraised value
+	SettingMarkdownFloorPercent = "markdown_floor_percent"
+	// SettingMarkdownNotifyManagers tells branch managers which items were marked down
+	SettingMarkdownNotifyManagers = "markdown_notify_managers"
+)
+
+// DefaultMarkdownFloorPercent is used when the floor setting is not configured
+const DefaultMarkdownFloorPercent = 50
+
+// MarkdownService lowers the sale price of items that stay for sale too long,
+// following each branch's markdown rules and never going below its floor price
+type MarkdownService struct {
+	markdownRepo        repository.MarkdownRepository
+	itemRepo            repository.ItemRepository
+	settingRepo         repository.SettingRepository
+	roleRepo            repository.RoleRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	moneyFormat         *MoneyFormatService
+	logger              zerolog.Logger
+}
+
+// NewMarkdownService creates a new MarkdownService
+func NewMarkdownService(
+	markdownRepo repository.MarkdownRepository,
+	itemRepo repository.ItemRepository,
+	settingRepo repository.SettingRepository,
+	roleRepo repository.RoleRepository,
+	userRepo repository.UserRepository,
+	notificationService NotificationService,
+	logger zerolog.Logger,
+) *MarkdownService {
+	return &MarkdownService{
+		markdownRepo:        markdownRepo,
+		itemRepo:            itemRepo,
+		settingRepo:         settingRepo,
+		roleRepo:            roleRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		logger:              logger.With().Str("service", "markdown").Logger(),
+	}
+}
+
+// SetMoneyFormat writes prices in the currency of the branch
+func (s *MarkdownService) SetMoneyFormat(moneyFormat *MoneyFormatService) {
+	s.moneyFormat = moneyFormat
+}
+
+// Run applies every markdown step items are due for and returns how many items
+// got cheaper. Steps already applied since an item was listed are not repeated.
+func (s *MarkdownService) Run(ctx context.Context, now time.Time) (int, error) {
+	candidates, err := s.markdownRepo.ListCandidates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	markedDown := 0
+	byBranch := make(map[int64][]string)
+	for _, candidate := range candidates {
+		branchID := candidate.BranchID
+		if !getSettingBool(ctx, s.settingRepo, SettingMarkdownEnabled, &branchID, false) {
+			continue
+		}
+
+		rules := domain.DefaultMarkdownRules()
+		getSettingJSON(ctx, s.settingRepo, SettingMarkdownRules, &branchID, &rules)
+		steps := domain.DueMarkdownSteps(rules, candidate.DaysListed(now), candidate.StepsApplied)
+		if len(steps) == 0 {
+			continue
+		}
+
+		floorPercent := getSettingFloat(ctx, s.settingRepo, SettingMarkdownFloorPercent, &branchID, DefaultMarkdownFloorPercent)
+		floor := roundCents(candidate.AppraisedValue * floorPercent / 100)
+
+		format := moneyFormatFor(ctx, s.moneyFormat, branchID)
+		oldPrice, newPrice, err := s.markDown(ctx, candidate.ItemID, steps, floor, format)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("item_id", candidate.ItemID).Msg("Failed to mark down item")
+			continue
+		}
+		if newPrice >= oldPrice {
+			continue
+		}
+
+		markedDown++
+		byBranch[branchID] = append(byBranch[branchID],
+			fmt.Sprintf("%s (%s → %s)", candidate.SKU, format.Format(oldPrice), format.Format(newPrice)))
+	}
+
+	for branchID, items := range byBranch {
+		s.notifyManagers(ctx, branchID, items)
+	}
+
+	return markedDown, nil
+}
+
+// markDown takes the due steps off the item's sale price, clamped to the floor,
+// and records one history entry per step. It returns the old and new price.
+func (s *MarkdownService) markDown(ctx context.Context, itemID int64, steps []domain.MarkdownRule, floor float64, format domain.MoneyFormat) (float64, float64, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("item not found: %w", err)
+	}
+	// The item may have been sold or repriced since it was listed as a candidate
+	if item.Status != domain.ItemStatusForSale || item.SalePrice == nil {
+		return 0, 0, nil
+	}
+
+	oldPrice := *item.SalePrice
+	price := oldPrice
+	notes := make([]string, 0, len(steps))
+	for _, step := range steps {
+		next := roundCents(price * (1 - step.Percent/100))
+		if next < floor {
+			next = floor
+		}
+		if next > price {
+			next = price
+		}
+		notes = append(notes, fmt.Sprintf("Rebaja automática de %.0f%% tras %d días en venta: %s → %s",
+			step.Percent, step.AfterDays, format.Format(price), format.Format(next)))
+		price = next
+	}
+
+	if price < oldPrice {
+		item.SalePrice = &price
+		if err := s.itemRepo.Update(ctx, item); err != nil {
+			return 0, 0, fmt.Errorf("failed to update item: %w", err)
+		}
+	}
+
+	// Steps stuck at the floor are recorded too, so they are not retried on every run
+	for _, note := range notes {
+		if err := s.itemRepo.CreateHistory(ctx, &domain.ItemHistory{
+			ItemID:    item.ID,
+			Action:    domain.ItemHistoryActionMarkedDown,
+			OldStatus: string(domain.ItemStatusForSale),
+			NewStatus: string(domain.ItemStatusForSale),
+			Notes:     note,
+		}); err != nil {
+			return 0, 0, fmt.Errorf("failed to record markdown: %w", err)
+		}
+	}
+
+	s.logger.Info().
+		Int64("item_id", item.ID).
+		Float64("old_price", oldPrice).
+		Float64("new_price", price).
+		Int("steps", len(steps)).
+		Msg("Marked down item")
+	return oldPrice, price, nil
+}
+
+// notifyManagers tells the branch's active managers which items were marked down
+func (s *MarkdownService) notifyManagers(ctx context.Context, branchID int64, items []string) {
+	if s.notificationService == nil || s.roleRepo == nil || s.userRepo == nil {
+		return
+	}
+	if !getSettingBool(ctx, s.settingRepo, SettingMarkdownNotifyManagers, &branchID, true) {
+		return
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, domain.RoleManager)
+	if err != nil || role == nil {
+		return
+	}
+
+	active := true
+	users, err := s.userRepo.List(ctx, repository.UserListParams{RoleID: &role.ID, BranchID: &branchID, IsActive: &active})
+	if err != nil {
+		s.logger.Error().Err(err).Int64("branch_id", branchID).Msg("Failed to list managers for markdown notice")
+		return
+	}
+
+	message := fmt.Sprintf("Se rebajó el precio de %d artículo(s) en venta: %s", len(items), strings.Join(items, ", "))
+	for _, user := range users.Data {
+		_, err := s.notificationService.CreateInternalNotification(ctx, CreateInternalNotificationRequest{
+			UserID:        user.ID,
+			BranchID:      &branchID,
+			Title:         "Rebaja Automática de Precios",
+			Message:       message,
+			Type:          "info",
+			ReferenceType: "item",
+		})
+		if err != nil {
+			s.logger.Error().Err(err).Int64("user_id", user.ID).Msg("Failed to notify manager about markdowns")
+		}
+	}
+}
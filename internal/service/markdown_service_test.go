@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+type markdownMocks struct {
+	markdownRepo *mocks.MockMarkdownRepository
+	itemRepo     *mocks.MockItemRepository
+	settingRepo  *mocks.MockSettingRepository
+}
+
+func setupMarkdownService(enabled bool) (*MarkdownService, markdownMocks) {
+	m := markdownMocks{
+		markdownRepo: new(mocks.MockMarkdownRepository),
+		itemRepo:     new(mocks.MockItemRepository),
+		settingRepo:  new(mocks.MockSettingRepository),
+	}
+	m.settingRepo.On("Get", mock.Anything, SettingMarkdownEnabled, mock.Anything).Return(&domain.Setting{Value: enabled}, nil)
+	m.settingRepo.On("Get", mock.Anything, SettingMarkdownRules, mock.Anything).Return(&domain.Setting{Value: []interface{}{
+		map[string]interface{}{"after_days": float64(30), "percent": float64(10)},
+		map[string]interface{}{"after_days": float64(60), "percent": float64(10)},
+	}}, nil)
+	m.settingRepo.On("Get", mock.Anything, SettingMarkdownFloorPercent, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	service := NewMarkdownService(m.markdownRepo, m.itemRepo, m.settingRepo, nil, nil, nil, zerolog.Nop())
+	return service, m
+}
+
+func markdownCandidate(salePrice float64, listedDaysAgo, applied int, now time.Time) *domain.MarkdownCandidate {
+	return &domain.MarkdownCandidate{
+		ItemID: 7, BranchID: 1, SKU: "ITM-000007", AppraisedValue: 1000, SalePrice: salePrice,
+		ListedAt: now.AddDate(0, 0, -listedDaysAgo), StepsApplied: applied,
+	}
+}
+
+func forSaleItem(salePrice float64) *domain.Item {
+	return &domain.Item{ID: 7, BranchID: 1, SKU: "ITM-000007", AppraisedValue: 1000, SalePrice: &salePrice, Status: domain.ItemStatusForSale}
+}
+
+func TestMarkdownService_Run_AppliesDueStep(t *testing.T) {
+	service, m := setupMarkdownService(true)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	m.markdownRepo.On("ListCandidates", ctx).Return([]*domain.MarkdownCandidate{markdownCandidate(600, 35, 0, now)}, nil)
+	m.itemRepo.On("GetByID", ctx, int64(7)).Return(forSaleItem(600), nil)
+	m.itemRepo.On("Update", ctx, mock.MatchedBy(func(item *domain.Item) bool {
+		return *item.SalePrice == 540
+	})).Return(nil)
+	m.itemRepo.On("CreateHistory", ctx, mock.MatchedBy(func(h *domain.ItemHistory) bool {
+		return h.Action == domain.ItemHistoryActionMarkedDown && h.ItemID == 7
+	})).Return(nil).Once()
+
+	markedDown, err := service.Run(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, markedDown)
+	m.itemRepo.AssertExpectations(t)
+}
+
+func TestMarkdownService_Run_NotesUseBranchCurrency(t *testing.T) {
+	service, m := setupMarkdownService(true)
+	moneyFormat, branchRepo := setupMoneyFormatService(false)
+	service.SetMoneyFormat(moneyFormat)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1, Currency: "USD"}, nil)
+	m.markdownRepo.On("ListCandidates", ctx).Return([]*domain.MarkdownCandidate{markdownCandidate(600, 35, 0, now)}, nil)
+	m.itemRepo.On("GetByID", ctx, int64(7)).Return(forSaleItem(600), nil)
+	m.itemRepo.On("Update", ctx, mock.AnythingOfType("*domain.Item")).Return(nil)
+	m.itemRepo.On("CreateHistory", ctx, mock.MatchedBy(func(h *domain.ItemHistory) bool {
+		return h.Notes == "Rebaja automática de 10% tras 30 días en venta: $600.00 → $540.00"
+	})).Return(nil).Once()
+
+	_, err := service.Run(ctx, now)
+
+	require.NoError(t, err)
+	m.itemRepo.AssertExpectations(t)
+}
+
+func TestMarkdownService_Run_StopsAtFloor(t *testing.T) {
+	service, m := setupMarkdownService(true)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	// Both steps are due, but the default floor is 50% of the appraised value
+	m.markdownRepo.On("ListCandidates", ctx).Return([]*domain.MarkdownCandidate{markdownCandidate(520, 65, 0, now)}, nil)
+	m.itemRepo.On("GetByID", ctx, int64(7)).Return(forSaleItem(520), nil)
+	m.itemRepo.On("Update", ctx, mock.MatchedBy(func(item *domain.Item) bool {
+		return *item.SalePrice == 500
+	})).Return(nil)
+	m.itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).Return(nil).Twice()
+
+	markedDown, err := service.Run(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, markedDown)
+	m.itemRepo.AssertExpectations(t)
+}
+
+func TestMarkdownService_Run_SkipsAppliedSteps(t *testing.T) {
+	service, m := setupMarkdownService(true)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	m.markdownRepo.On("ListCandidates", ctx).Return([]*domain.MarkdownCandidate{markdownCandidate(540, 45, 1, now)}, nil)
+
+	markedDown, err := service.Run(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, markedDown)
+	m.itemRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestMarkdownService_Run_Disabled(t *testing.T) {
+	service, m := setupMarkdownService(false)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	m.markdownRepo.On("ListCandidates", ctx).Return([]*domain.MarkdownCandidate{markdownCandidate(600, 90, 0, now)}, nil)
+
+	markedDown, err := service.Run(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, markedDown)
+	m.itemRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
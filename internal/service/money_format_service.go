@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Setting keys for money formatting
+const (
+	// SettingMoneyLocale is the locale used to write amounts, e.g. es-GT or es-ES
+	SettingMoneyLocale = "money_locale"
+	// SettingMoneySymbol replaces the symbol of the branch currency, e.g. "US$"
+	SettingMoneySymbol = "money_symbol"
+	// SettingMoneySymbolPosition writes the symbol before (prefix) or after (suffix) amounts
+	SettingMoneySymbolPosition = "money_symbol_position"
+	// SettingAPIMoneyFormatting includes formatted amounts next to the numeric
+	// ones in loan and payment responses
+	SettingAPIMoneyFormatting = "api_money_formatting"
+)
+
+// MoneyFormatService resolves how amounts are written for each branch, from the
+// branch currency, the configured locale and the symbol settings
+type MoneyFormatService struct {
+	branchRepo  repository.BranchRepository
+	settingRepo repository.SettingRepository
+}
+
+// NewMoneyFormatService creates a new MoneyFormatService
+func NewMoneyFormatService(branchRepo repository.BranchRepository, settingRepo repository.SettingRepository) *MoneyFormatService {
+	return &MoneyFormatService{
+		branchRepo:  branchRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// FormatFor returns the money format of a branch. A branch that cannot be
+// loaded gets the default currency.
+func (s *MoneyFormatService) FormatFor(ctx context.Context, branchID int64) domain.MoneyFormat {
+	currency := ""
+	if s.branchRepo != nil && branchID > 0 {
+		if branch, err := s.branchRepo.GetByID(ctx, branchID); err == nil {
+			currency = branch.Currency
+		}
+	}
+	locale := getSettingString(ctx, s.settingRepo, SettingMoneyLocale, &branchID, domain.DefaultMoneyLocale)
+	return domain.NewMoneyFormat(currency, locale).WithSymbol(
+		getSettingString(ctx, s.settingRepo, SettingMoneySymbol, &branchID, ""),
+		getSettingString(ctx, s.settingRepo, SettingMoneySymbolPosition, &branchID, domain.MoneySymbolPrefix),
+	)
+}
+
+// moneyFormatFor returns the money format of a branch, the default one when
+// no MoneyFormatService is configured
+func moneyFormatFor(ctx context.Context, moneyFormat *MoneyFormatService, branchID int64) domain.MoneyFormat {
+	if moneyFormat == nil {
+		return domain.NewMoneyFormat("", "")
+	}
+	return moneyFormat.FormatFor(ctx, branchID)
+}
+
+// FormatLoan fills in the loan's formatted amounts when formatting is enabled
+// for its branch
+func (s *MoneyFormatService) FormatLoan(ctx context.Context, loan *domain.Loan) {
+	if loan == nil || !getSettingBool(ctx, s.settingRepo, SettingAPIMoneyFormatting, &loan.BranchID, false) {
+		return
+	}
+
+	format := s.FormatFor(ctx, loan.BranchID)
+	loan.Formatted = map[string]string{
+		"loan_amount":         format.Format(loan.LoanAmount),
+		"interest_amount":     format.Format(loan.InterestAmount),
+		"principal_remaining": format.Format(loan.PrincipalRemaining),
+		"interest_remaining":  format.Format(loan.InterestRemaining),
+		"late_fee_remaining":  format.Format(loan.LateFeeRemaining),
+		"total_amount":        format.Format(loan.TotalAmount),
+		"amount_paid":         format.Format(loan.AmountPaid),
+		"balance":             format.Format(loan.RemainingBalance()),
+	}
+}
+
+// FormatPayment fills in the payment's formatted amounts when formatting is
+// enabled for its branch
+func (s *MoneyFormatService) FormatPayment(ctx context.Context, payment *domain.Payment) {
+	if payment == nil || !getSettingBool(ctx, s.settingRepo, SettingAPIMoneyFormatting, &payment.BranchID, false) {
+		return
+	}
+
+	format := s.FormatFor(ctx, payment.BranchID)
+	payment.Formatted = map[string]string{
+		"amount":                 format.Format(payment.Amount),
+		"principal_amount":       format.Format(payment.PrincipalAmount),
+		"interest_amount":        format.Format(payment.InterestAmount),
+		"late_fee_amount":        format.Format(payment.LateFeeAmount),
+		"loan_balance_after":     format.Format(payment.LoanBalanceAfter),
+		"interest_balance_after": format.Format(payment.InterestBalanceAfter),
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupMoneyFormatService(formattingEnabled bool) (*MoneyFormatService, *mocks.MockBranchRepository) {
+	branchRepo := new(mocks.MockBranchRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingAPIMoneyFormatting, mock.Anything).
+		Return(&domain.Setting{Key: SettingAPIMoneyFormatting, Value: formattingEnabled}, nil)
+	settingRepo.On("Get", mock.Anything, SettingMoneyLocale, mock.Anything).
+		Return(nil, errors.New("setting not found"))
+	settingRepo.On("Get", mock.Anything, SettingMoneySymbol, mock.Anything).
+		Return(nil, errors.New("setting not found"))
+	settingRepo.On("Get", mock.Anything, SettingMoneySymbolPosition, mock.Anything).
+		Return(nil, errors.New("setting not found"))
+	return NewMoneyFormatService(branchRepo, settingRepo), branchRepo
+}
+
+func TestMoneyFormatService_FormatLoan_UsesBranchCurrency(t *testing.T) {
+	service, branchRepo := setupMoneyFormatService(true)
+	ctx := context.Background()
+	branchRepo.On("GetByID", ctx, int64(2)).Return(&domain.Branch{ID: 2, Currency: "USD"}, nil)
+
+	loan := &domain.Loan{BranchID: 2, LoanAmount: 1500, PrincipalRemaining: 1200, InterestRemaining: 50.5}
+	service.FormatLoan(ctx, loan)
+
+	require.NotNil(t, loan.Formatted)
+	assert.Equal(t, "$1,500.00", loan.Formatted["loan_amount"])
+	assert.Equal(t, "$1,250.50", loan.Formatted["balance"])
+}
+
+func TestMoneyFormatService_FormatPayment_DisabledLeavesResponseUnchanged(t *testing.T) {
+	service, branchRepo := setupMoneyFormatService(false)
+	ctx := context.Background()
+
+	payment := &domain.Payment{BranchID: 2, Amount: 300}
+	service.FormatPayment(ctx, payment)
+
+	assert.Nil(t, payment.Formatted)
+	branchRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestReportService_GeneratorUsesBranchCurrency(t *testing.T) {
+	moneyFormat, branchRepo := setupMoneyFormatService(false)
+	ctx := context.Background()
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1, Currency: "GTQ"}, nil)
+	branchRepo.On("GetByID", ctx, int64(3)).Return(&domain.Branch{ID: 3, Currency: "EUR"}, nil)
+
+	service := NewReportService(nil, nil, nil, nil, nil, nil, pdf.NewGenerator("Casa de Empeño", "", "", ""), nil)
+	service.SetMoneyFormat(moneyFormat)
+
+	assert.Equal(t, "Q", service.generatorFor(ctx, 1).MoneyFormat().Symbol())
+	assert.Equal(t, "€", service.generatorFor(ctx, 3).MoneyFormat().Symbol())
+	assert.Equal(t, "Q", service.pdfGenerator.MoneyFormat().Symbol(), "the shared generator is left untouched")
+}
+
+func TestMoneyFormatService_FormatFor_SymbolSettings(t *testing.T) {
+	branchRepo := new(mocks.MockBranchRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	ctx := context.Background()
+	branchRepo.On("GetByID", ctx, int64(4)).Return(&domain.Branch{ID: 4, Currency: "EUR"}, nil)
+	settingRepo.On("Get", ctx, SettingMoneyLocale, mock.Anything).
+		Return(&domain.Setting{Key: SettingMoneyLocale, Value: "es-ES"}, nil)
+	settingRepo.On("Get", ctx, SettingMoneySymbol, mock.Anything).
+		Return(nil, errors.New("setting not found"))
+	settingRepo.On("Get", ctx, SettingMoneySymbolPosition, mock.Anything).
+		Return(&domain.Setting{Key: SettingMoneySymbolPosition, Value: domain.MoneySymbolSuffix}, nil)
+
+	format := NewMoneyFormatService(branchRepo, settingRepo).FormatFor(ctx, 4)
+
+	assert.Equal(t, "1.234,50 €", format.Format(1234.5))
+	assert.Equal(t, "1.234,50 €", pdf.NewGenerator("Casa de Empeño", "", "", "").WithMoneyFormat(format).MoneyFormat().Format(1234.5))
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Note errors
+var (
+	ErrNoteNotFound       = errors.New("note not found")
+	ErrInvalidNoteEntity  = errors.New("invalid note entity type, expected loan, item or customer")
+	ErrNoteParentMismatch = errors.New("parent note belongs to a different record")
+)
+
+// PermissionDeleteAnyNote lets a user delete notes written by other users
+const PermissionDeleteAnyNote = "notes.delete"
+
+// noteEntityLabels names each note entity type in mention notifications
+var noteEntityLabels = map[domain.NoteEntityType]string{
+	domain.NoteEntityLoan:     "préstamo",
+	domain.NoteEntityItem:     "artículo",
+	domain.NoteEntityCustomer: "cliente",
+}
+
+// NoteService handles internal staff notes on loans, items and customers
+type NoteService struct {
+	noteRepo            repository.NoteRepository
+	loanRepo            repository.LoanRepository
+	itemRepo            repository.ItemRepository
+	customerRepo        repository.CustomerRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+}
+
+// NewNoteService creates a new NoteService
+func NewNoteService(
+	noteRepo repository.NoteRepository,
+	loanRepo repository.LoanRepository,
+	itemRepo repository.ItemRepository,
+	customerRepo repository.CustomerRepository,
+	userRepo repository.UserRepository,
+	notificationService NotificationService,
+) *NoteService {
+	return &NoteService{
+		noteRepo:            noteRepo,
+		loanRepo:            loanRepo,
+		itemRepo:            itemRepo,
+		customerRepo:        customerRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// AddNoteInput represents add note request data
+type AddNoteInput struct {
+	ParentID         *int64  `json:"parent_id"`
+	Body             string  `json:"body" validate:"required,max=5000"`
+	MentionedUserIDs []int64 `json:"mentioned_user_ids"`
+}
+
+// Add attaches a note to a record and notifies the users mentioned in it
+func (s *NoteService) Add(ctx context.Context, entityType domain.NoteEntityType, entityID int64, input AddNoteInput, author *domain.User) (*domain.Note, error) {
+	if err := s.checkEntity(ctx, entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: note body is required", ErrInvalidInput)
+	}
+
+	if input.ParentID != nil {
+		parent, err := s.noteRepo.GetByID(ctx, *input.ParentID)
+		if err != nil {
+			return nil, ErrNoteNotFound
+		}
+		if parent.EntityType != entityType || parent.EntityID != entityID {
+			return nil, ErrNoteParentMismatch
+		}
+		// Replies always hang from the thread's first note
+		if parent.ParentID != nil {
+			input.ParentID = parent.ParentID
+		}
+	}
+
+	mentioned, err := s.mentionedUsers(ctx, input.MentionedUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &domain.Note{
+		EntityType:       entityType,
+		EntityID:         entityID,
+		ParentID:         input.ParentID,
+		Body:             body,
+		MentionedUserIDs: make([]int64, 0, len(mentioned)),
+		CreatedBy:        author.ID,
+		AuthorName:       author.FullName(),
+	}
+	for _, user := range mentioned {
+		note.MentionedUserIDs = append(note.MentionedUserIDs, user.ID)
+	}
+
+	if err := s.noteRepo.Create(ctx, note); err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, note, mentioned, author)
+
+	return note, nil
+}
+
+// List retrieves the notes of a record grouped in threads, oldest first
+func (s *NoteService) List(ctx context.Context, entityType domain.NoteEntityType, entityID int64) ([]*domain.Note, error) {
+	if !entityType.IsValid() {
+		return nil, ErrInvalidNoteEntity
+	}
+
+	notes, err := s.noteRepo.ListByEntity(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := []*domain.Note{}
+	byID := make(map[int64]*domain.Note, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+	for _, note := range notes {
+		if note.ParentID != nil {
+			if parent, ok := byID[*note.ParentID]; ok {
+				parent.Replies = append(parent.Replies, note)
+				continue
+			}
+		}
+		threads = append(threads, note)
+	}
+
+	return threads, nil
+}
+
+// Delete removes a note and its replies. Users may delete their own notes;
+// deleting someone else's requires the notes.delete permission.
+func (s *NoteService) Delete(ctx context.Context, id int64, user *domain.User) error {
+	note, err := s.noteRepo.GetByID(ctx, id)
+	if err != nil {
+		return ErrNoteNotFound
+	}
+
+	if note.CreatedBy != user.ID && !user.HasPermission(PermissionDeleteAnyNote) {
+		return ErrForbidden
+	}
+
+	return s.noteRepo.Delete(ctx, id)
+}
+
+// checkEntity checks the record the note is attached to exists
+func (s *NoteService) checkEntity(ctx context.Context, entityType domain.NoteEntityType, entityID int64) error {
+	switch entityType {
+	case domain.NoteEntityLoan:
+		if _, err := s.loanRepo.GetByID(ctx, entityID); err != nil {
+			return ErrLoanNotFound
+		}
+	case domain.NoteEntityItem:
+		if _, err := s.itemRepo.GetByID(ctx, entityID); err != nil {
+			return ErrItemNotFound
+		}
+	case domain.NoteEntityCustomer:
+		if _, err := s.customerRepo.GetByID(ctx, entityID); err != nil {
+			return ErrCustomerNotFound
+		}
+	default:
+		return ErrInvalidNoteEntity
+	}
+	return nil
+}
+
+// mentionedUsers loads the mentioned users, ignoring repeated IDs
+func (s *NoteService) mentionedUsers(ctx context.Context, ids []int64) ([]*domain.User, error) {
+	users := []*domain.User{}
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil || user == nil {
+			return nil, fmt.Errorf("%w: mentioned user %d", ErrUserNotFound, id)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// notifyMentions sends an internal notification to every mentioned user but the author.
+// A failed notification does not undo the note.
+func (s *NoteService) notifyMentions(ctx context.Context, note *domain.Note, mentioned []*domain.User, author *domain.User) {
+	if s.notificationService == nil {
+		return
+	}
+
+	message := fmt.Sprintf("%s te mencionó en una nota del %s #%d: %s",
+		author.FullName(), noteEntityLabels[note.EntityType], note.EntityID, truncateNoteBody(note.Body))
+
+	for _, user := range mentioned {
+		if user.ID == author.ID {
+			continue
+		}
+		_, _ = s.notificationService.CreateInternalNotification(ctx, CreateInternalNotificationRequest{
+			UserID:        user.ID,
+			BranchID:      user.BranchID,
+			Title:         "Te mencionaron en una nota",
+			Message:       message,
+			Type:          "info",
+			ReferenceType: string(note.EntityType),
+			ReferenceID:   &note.EntityID,
+			ActionURL:     fmt.Sprintf("/%ss/%d", note.EntityType, note.EntityID),
+		})
+	}
+}
+
+// noteExcerptLength is how much of a note is quoted in mention notifications
+const noteExcerptLength = 120
+
+func truncateNoteBody(body string) string {
+	runes := []rune(body)
+	if len(runes) <= noteExcerptLength {
+		return body
+	}
+	return string(runes[:noteExcerptLength]) + "…"
+}
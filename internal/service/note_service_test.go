@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+type noteMocks struct {
+	noteRepo     *mocks.MockNoteRepository
+	loanRepo     *mocks.MockLoanRepository
+	itemRepo     *mocks.MockItemRepository
+	customerRepo *mocks.MockCustomerRepository
+	userRepo     *mocks.MockUserRepository
+	internalRepo *mocks.MockInternalNotificationRepository
+}
+
+func setupNoteService() (*NoteService, noteMocks) {
+	m := noteMocks{
+		noteRepo:     new(mocks.MockNoteRepository),
+		loanRepo:     new(mocks.MockLoanRepository),
+		itemRepo:     new(mocks.MockItemRepository),
+		customerRepo: new(mocks.MockCustomerRepository),
+		userRepo:     new(mocks.MockUserRepository),
+		internalRepo: new(mocks.MockInternalNotificationRepository),
+	}
+	notificationService := NewNotificationService(nil, nil, nil, m.internalRepo, nil, m.userRepo)
+	service := NewNoteService(m.noteRepo, m.loanRepo, m.itemRepo, m.customerRepo, m.userRepo, notificationService)
+	return service, m
+}
+
+func TestNoteService_Add_Success(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+	author := &domain.User{ID: 1, FirstName: "Luis", LastName: "Pérez"}
+
+	m.loanRepo.On("GetByID", ctx, int64(10)).Return(&domain.Loan{ID: 10}, nil)
+	m.noteRepo.On("Create", ctx, mock.AnythingOfType("*domain.Note")).Return(nil)
+
+	note, err := service.Add(ctx, domain.NoteEntityLoan, 10, AddNoteInput{Body: "  Cliente pidió prórroga  "}, author)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NoteEntityLoan, note.EntityType)
+	assert.Equal(t, int64(10), note.EntityID)
+	assert.Equal(t, "Cliente pidió prórroga", note.Body)
+	assert.Equal(t, int64(1), note.CreatedBy)
+	assert.Empty(t, note.MentionedUserIDs)
+	m.internalRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestNoteService_Add_MentionCreatesInternalNotification(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+	author := &domain.User{ID: 1, FirstName: "Luis", LastName: "Pérez"}
+	branchID := int64(2)
+	mentioned := &domain.User{ID: 7, BranchID: &branchID, FirstName: "Ana"}
+
+	m.itemRepo.On("GetByID", ctx, int64(20)).Return(&domain.Item{ID: 20}, nil)
+	m.userRepo.On("GetByID", ctx, int64(7)).Return(mentioned, nil)
+	m.userRepo.On("GetByID", ctx, int64(1)).Return(author, nil)
+	m.noteRepo.On("Create", ctx, mock.AnythingOfType("*domain.Note")).Return(nil)
+	m.internalRepo.On("Create", ctx, mock.MatchedBy(func(n *domain.InternalNotification) bool {
+		return n.UserID == 7 && n.ReferenceType == "item" && *n.ReferenceID == 20 && n.ActionURL == "/items/20"
+	})).Return(nil).Once()
+
+	note, err := service.Add(ctx, domain.NoteEntityItem, 20, AddNoteInput{
+		Body:             "@Ana revisar el avalúo",
+		MentionedUserIDs: []int64{7, 7, 1},
+	}, author)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 1}, note.MentionedUserIDs)
+	// The author is not notified of their own mention
+	m.internalRepo.AssertNumberOfCalls(t, "Create", 1)
+	m.internalRepo.AssertExpectations(t)
+}
+
+func TestNoteService_Add_UnknownMentionedUser(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+
+	m.customerRepo.On("GetByID", ctx, int64(5)).Return(&domain.Customer{ID: 5}, nil)
+	m.userRepo.On("GetByID", ctx, int64(99)).Return(nil, errors.New("user not found"))
+
+	note, err := service.Add(ctx, domain.NoteEntityCustomer, 5, AddNoteInput{Body: "hola", MentionedUserIDs: []int64{99}}, &domain.User{ID: 1})
+
+	assert.Nil(t, note)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	m.noteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestNoteService_Add_EntityNotFound(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+
+	m.loanRepo.On("GetByID", ctx, int64(404)).Return(nil, errors.New("loan not found"))
+
+	note, err := service.Add(ctx, domain.NoteEntityLoan, 404, AddNoteInput{Body: "hola"}, &domain.User{ID: 1})
+
+	assert.Nil(t, note)
+	assert.ErrorIs(t, err, ErrLoanNotFound)
+}
+
+func TestNoteService_Add_ReplyToOtherRecord(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+	parentID := int64(3)
+
+	m.loanRepo.On("GetByID", ctx, int64(10)).Return(&domain.Loan{ID: 10}, nil)
+	m.noteRepo.On("GetByID", ctx, parentID).Return(&domain.Note{ID: 3, EntityType: domain.NoteEntityLoan, EntityID: 11}, nil)
+
+	note, err := service.Add(ctx, domain.NoteEntityLoan, 10, AddNoteInput{ParentID: &parentID, Body: "hola"}, &domain.User{ID: 1})
+
+	assert.Nil(t, note)
+	assert.ErrorIs(t, err, ErrNoteParentMismatch)
+}
+
+func TestNoteService_List_GroupsReplies(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+	parentID := int64(1)
+
+	m.noteRepo.On("ListByEntity", ctx, domain.NoteEntityCustomer, int64(5)).Return([]*domain.Note{
+		{ID: 1, Body: "first"},
+		{ID: 2, Body: "second"},
+		{ID: 3, ParentID: &parentID, Body: "reply"},
+	}, nil)
+
+	notes, err := service.List(ctx, domain.NoteEntityCustomer, 5)
+
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	require.Len(t, notes[0].Replies, 1)
+	assert.Equal(t, int64(3), notes[0].Replies[0].ID)
+}
+
+func TestNoteService_Delete_OtherAuthorForbidden(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+	user := &domain.User{ID: 2, Role: &domain.Role{Permissions: json.RawMessage(`["loans.*"]`)}}
+
+	m.noteRepo.On("GetByID", ctx, int64(1)).Return(&domain.Note{ID: 1, CreatedBy: 1}, nil)
+
+	err := service.Delete(ctx, 1, user)
+
+	assert.ErrorIs(t, err, ErrForbidden)
+	m.noteRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestNoteService_Delete_OwnNote(t *testing.T) {
+	service, m := setupNoteService()
+	ctx := context.Background()
+
+	m.noteRepo.On("GetByID", ctx, int64(1)).Return(&domain.Note{ID: 1, CreatedBy: 2}, nil)
+	m.noteRepo.On("Delete", ctx, int64(1)).Return(nil)
+
+	err := service.Delete(ctx, 1, &domain.User{ID: 2})
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Delivery receipt errors
+var (
+	ErrUnknownDeliveryProvider = errors.New("unknown notification delivery provider")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidDeliveryReceipt  = errors.New("invalid delivery receipt")
+)
+
+// DeliveryReceipt is what a provider reports about a message it was given
+type DeliveryReceipt struct {
+	ExternalMessageID string
+	Status            string // A notification status: delivered or failed; anything else is ignored
+	FailureReason     string
+}
+
+// DeliveryReceiptParser reads a provider's delivery receipt payload
+type DeliveryReceiptParser func(body []byte) (*DeliveryReceipt, error)
+
+// DeliveryWebhookRequest is a delivery receipt as a provider posted it
+type DeliveryWebhookRequest struct {
+	URL    string // The full URL the provider posted to
+	Body   []byte
+	Header func(key string) string
+}
+
+// DeliveryWebhookVerifier checks a delivery receipt's signature against the
+// provider's secret
+type DeliveryWebhookVerifier func(secret string, request DeliveryWebhookRequest) bool
+
+// deliveryProvider is a provider posting delivery receipts
+type deliveryProvider struct {
+	secret string
+	parse  DeliveryReceiptParser
+	verify DeliveryWebhookVerifier
+}
+
+// NotificationDeliveryService updates notifications from the delivery receipts
+// their providers post back
+type NotificationDeliveryService struct {
+	notificationRepo repository.NotificationRepository
+	providers        map[string]deliveryProvider
+	logger           zerolog.Logger
+}
+
+// NewNotificationDeliveryService creates a new NotificationDeliveryService
+func NewNotificationDeliveryService(notificationRepo repository.NotificationRepository, logger zerolog.Logger) *NotificationDeliveryService {
+	return &NotificationDeliveryService{
+		notificationRepo: notificationRepo,
+		providers:        make(map[string]deliveryProvider),
+		logger:           logger.With().Str("service", "notification_delivery").Logger(),
+	}
+}
+
+// RegisterProvider accepts the delivery receipts of a provider, signed with
+// secret. Providers without a format of their own post the generic format.
+func (s *NotificationDeliveryService) RegisterProvider(name, secret string) {
+	format, ok := deliveryReceiptFormats[name]
+	if !ok {
+		format = deliveryReceiptFormat{parse: ParseGenericDeliveryReceipt, verify: validGenericSignature}
+	}
+	s.providers[name] = deliveryProvider{secret: secret, parse: format.parse, verify: format.verify}
+}
+
+// HandleReceipt verifies and applies a delivery receipt. The generic signature
+// is the hex HMAC-SHA256 of the raw body keyed with the provider's secret in
+// the X-Signature header, optionally prefixed with "sha256="; providers with a
+// format of their own sign the way they document. Only a sent notification
+// takes a receipt, so a late or repeated receipt never overwrites a final
+// status. It returns the updated notification, nil when the receipt needs no
+// update.
+func (s *NotificationDeliveryService) HandleReceipt(ctx context.Context, provider string, request DeliveryWebhookRequest) (*domain.Notification, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownDeliveryProvider
+	}
+	if p.secret == "" || !p.verify(p.secret, request) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	receipt, err := p.parse(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDeliveryReceipt, err)
+	}
+	if receipt.ExternalMessageID == "" {
+		return nil, fmt.Errorf("%w: missing message ID", ErrInvalidDeliveryReceipt)
+	}
+
+	notification, err := s.notificationRepo.GetByExternalMessageID(ctx, receipt.ExternalMessageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	if notification == nil {
+		return nil, ErrNotificationNotFound
+	}
+
+	log := s.logger.With().
+		Str("provider", provider).
+		Int64("notification_id", notification.ID).
+		Str("status", receipt.Status).
+		Logger()
+
+	var reason string
+	switch receipt.Status {
+	case domain.NotificationStatusDelivered:
+	case domain.NotificationStatusFailed:
+		reason = receipt.FailureReason
+		if reason == "" {
+			reason = "provider reported delivery failure"
+		}
+	default:
+		log.Debug().Msg("Ignoring intermediate delivery receipt")
+		return nil, nil
+	}
+
+	applied, err := s.notificationRepo.ApplyDeliveryReceipt(ctx, notification.ID, receipt.Status, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply delivery receipt: %w", err)
+	}
+	if !applied {
+		log.Debug().Str("current_status", notification.Status).Msg("Ignoring delivery receipt of a notification no longer sent")
+		return nil, nil
+	}
+	notification.Status = receipt.Status
+	notification.FailureReason = reason
+
+	log.Info().Msg("Notification delivery receipt applied")
+	return notification, nil
+}
+
+// validGenericSignature checks the X-Signature of a body in constant time
+func validGenericSignature(secret string, request DeliveryWebhookRequest) bool {
+	return validWebhookSignature(secret, request.Header("X-Signature"), request.Body)
+}
+
+// validWebhookSignature checks a body's signature in constant time
+func validWebhookSignature(secret, signature string, body []byte) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	return hmac.Equal([]byte(strings.ToLower(signature)), []byte(signWebhookBody(secret, body)))
+}
+
+// validTwilioSignature checks the X-Twilio-Signature of a status callback in
+// constant time. Twilio signs with the account's auth token the base64
+// HMAC-SHA1 of the URL it posted to followed by every form parameter, sorted
+// by name, as name and value.
+func validTwilioSignature(authToken string, request DeliveryWebhookRequest) bool {
+	form, err := url.ParseQuery(string(request.Body))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(strings.TrimSpace(request.Header("X-Twilio-Signature"))), []byte(signTwilioRequest(authToken, request.URL, form)))
+}
+
+// signTwilioRequest computes the signature Twilio sends for a request
+func signTwilioRequest(authToken, requestURL string, form url.Values) string {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var payload strings.Builder
+	payload.WriteString(requestURL)
+	for _, name := range names {
+		for _, value := range form[name] {
+			payload.WriteString(name)
+			payload.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(payload.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// deliveryReceiptFormat is how a provider formats and signs its receipts
+type deliveryReceiptFormat struct {
+	parse  DeliveryReceiptParser
+	verify DeliveryWebhookVerifier
+}
+
+// deliveryReceiptFormats are the providers posting a format of their own
+var deliveryReceiptFormats = map[string]deliveryReceiptFormat{
+	"twilio": {parse: ParseTwilioDeliveryReceipt, verify: validTwilioSignature},
+}
+
+// ParseGenericDeliveryReceipt reads a JSON receipt such as
+// {"message_id": "abc", "status": "delivered", "error": ""}
+func ParseGenericDeliveryReceipt(body []byte) (*DeliveryReceipt, error) {
+	var payload struct {
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+		Error     string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return &DeliveryReceipt{
+		ExternalMessageID: payload.MessageID,
+		Status:            deliveryStatus(payload.Status),
+		FailureReason:     payload.Error,
+	}, nil
+}
+
+// ParseTwilioDeliveryReceipt reads a Twilio status callback, a form with the
+// MessageSid, MessageStatus and ErrorCode fields
+func ParseTwilioDeliveryReceipt(body []byte) (*DeliveryReceipt, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	receipt := &DeliveryReceipt{
+		ExternalMessageID: form.Get("MessageSid"),
+		Status:            deliveryStatus(form.Get("MessageStatus")),
+	}
+	if code := form.Get("ErrorCode"); code != "" {
+		receipt.FailureReason = "twilio error " + code
+	}
+	return receipt, nil
+}
+
+// deliveryStatus maps a provider's message status to a notification status
+func deliveryStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "delivered", "read":
+		return domain.NotificationStatusDelivered
+	case "failed", "undelivered", "rejected", "expired":
+		return domain.NotificationStatusFailed
+	default:
+		return strings.ToLower(status)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository/mocks"
+)
+
+// webhookRequest is a receipt posted with the given headers
+func webhookRequest(body []byte, headers map[string]string) DeliveryWebhookRequest {
+	return DeliveryWebhookRequest{
+		URL:    twilioCallbackURL,
+		Body:   body,
+		Header: func(key string) string { return headers[key] },
+	}
+}
+
+const twilioCallbackURL = "https://pawnshop.example.com/api/v1/notifications/webhook/twilio"
+
+func setupNotificationDeliveryService() (*NotificationDeliveryService, *mocks.MockNotificationRepository) {
+	notificationRepo := new(mocks.MockNotificationRepository)
+	service := NewNotificationDeliveryService(notificationRepo, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+	service.RegisterProvider("acme", "acme-secret")
+	service.RegisterProvider("twilio", "twilio-secret")
+	return service, notificationRepo
+}
+
+func TestNotificationDeliveryService_ValidSignatureMarksDelivered(t *testing.T) {
+	service, notificationRepo := setupNotificationDeliveryService()
+	ctx := context.Background()
+	body := []byte(`{"message_id": "msg-42", "status": "delivered"}`)
+
+	notificationRepo.On("GetByExternalMessageID", ctx, "msg-42").Return(&domain.Notification{ID: 7, Status: domain.NotificationStatusSent}, nil)
+	notificationRepo.On("ApplyDeliveryReceipt", ctx, int64(7), domain.NotificationStatusDelivered, "").Return(true, nil)
+
+	notification, err := service.HandleReceipt(ctx, "acme", webhookRequest(body, map[string]string{"X-Signature": "sha256=" + signWebhookBody("acme-secret", body)}))
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationStatusDelivered, notification.Status)
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestNotificationDeliveryService_TwilioFailureMarksFailed(t *testing.T) {
+	service, notificationRepo := setupNotificationDeliveryService()
+	ctx := context.Background()
+	body := []byte("MessageSid=SM123&MessageStatus=undelivered&ErrorCode=30003")
+
+	notificationRepo.On("GetByExternalMessageID", ctx, "SM123").Return(&domain.Notification{ID: 8, Status: domain.NotificationStatusSent}, nil)
+	notificationRepo.On("ApplyDeliveryReceipt", ctx, int64(8), domain.NotificationStatusFailed, "twilio error 30003").Return(true, nil)
+
+	form, _ := url.ParseQuery(string(body))
+	signature := signTwilioRequest("twilio-secret", twilioCallbackURL, form)
+	notification, err := service.HandleReceipt(ctx, "twilio", webhookRequest(body, map[string]string{"X-Twilio-Signature": signature}))
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationStatusFailed, notification.Status)
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestNotificationDeliveryService_InvalidSignatureRejected(t *testing.T) {
+	service, notificationRepo := setupNotificationDeliveryService()
+	ctx := context.Background()
+	body := []byte(`{"message_id": "msg-42", "status": "delivered"}`)
+
+	tests := []struct {
+		name      string
+		provider  string
+		signature string
+	}{
+		{name: "missing", provider: "acme", signature: ""},
+		{name: "wrong secret", provider: "acme", signature: signWebhookBody("other-secret", body)},
+		{name: "other provider's secret", provider: "twilio", signature: signWebhookBody("acme-secret", body)},
+		{name: "tampered body", provider: "acme", signature: signWebhookBody("acme-secret", []byte(`{"message_id": "msg-42", "status": "failed"}`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.HandleReceipt(ctx, tt.provider, webhookRequest(body, map[string]string{
+				"X-Signature": tt.signature, "X-Twilio-Signature": tt.signature,
+			}))
+			assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+		})
+	}
+
+	_, err := service.HandleReceipt(ctx, "unknown", webhookRequest(body, map[string]string{"X-Signature": signWebhookBody("acme-secret", body)}))
+	assert.ErrorIs(t, err, ErrUnknownDeliveryProvider)
+
+	notificationRepo.AssertNotCalled(t, "GetByExternalMessageID", mock.Anything, mock.Anything)
+	notificationRepo.AssertNotCalled(t, "ApplyDeliveryReceipt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationDeliveryService_IntermediateStatusIgnored(t *testing.T) {
+	service, notificationRepo := setupNotificationDeliveryService()
+	ctx := context.Background()
+	body := []byte(`{"message_id": "msg-42", "status": "queued"}`)
+
+	notificationRepo.On("GetByExternalMessageID", ctx, "msg-42").Return(&domain.Notification{ID: 7, Status: domain.NotificationStatusSent}, nil)
+
+	notification, err := service.HandleReceipt(ctx, "acme", webhookRequest(body, map[string]string{"X-Signature": signWebhookBody("acme-secret", body)}))
+
+	require.NoError(t, err)
+	assert.Nil(t, notification)
+	notificationRepo.AssertNotCalled(t, "ApplyDeliveryReceipt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationDeliveryService_LateFailureKeepsDelivered(t *testing.T) {
+	service, notificationRepo := setupNotificationDeliveryService()
+	ctx := context.Background()
+	body := []byte(`{"message_id": "msg-42", "status": "failed"}`)
+
+	notificationRepo.On("GetByExternalMessageID", ctx, "msg-42").Return(&domain.Notification{ID: 7, Status: domain.NotificationStatusDelivered}, nil)
+	notificationRepo.On("ApplyDeliveryReceipt", ctx, int64(7), domain.NotificationStatusFailed, "provider reported delivery failure").Return(false, nil)
+
+	notification, err := service.HandleReceipt(ctx, "acme", webhookRequest(body, map[string]string{"X-Signature": signWebhookBody("acme-secret", body)}))
+
+	require.NoError(t, err)
+	assert.Nil(t, notification)
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestSignTwilioRequest_MatchesTwilioExample(t *testing.T) {
+	// The example from Twilio's webhook security documentation
+	form := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	signature := signTwilioRequest("12345", "https://mycompany.com/myapp.php?foo=1&bar=2", form)
+	assert.Equal(t, "0/KCTR6DLpKmkAf8muzZqo1nDgQ=", signature)
+}
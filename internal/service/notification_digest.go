@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Notification digest limits
+const (
+	DefaultNotificationDigestIntervalHours = 4
+	MaxNotificationDigestIntervalHours     = 168
+	// notificationDigestMaxLines is how many notifications a digest lists one by one
+	notificationDigestMaxLines = 20
+)
+
+// notificationTypeSeverity orders notification types so a digest takes the
+// type of its most severe notification
+var notificationTypeSeverity = map[string]int{
+	"info":    0,
+	"success": 1,
+	"warning": 2,
+	"error":   3,
+}
+
+// UpdateNotificationPreferenceInput is how a user wants to receive internal notifications
+type UpdateNotificationPreferenceInput struct {
+	DigestMode          bool `json:"digest_mode"`
+	DigestIntervalHours int  `json:"digest_interval_hours"`
+}
+
+// NotificationDigestService bundles the internal notifications held back for
+// users in digest mode into a single summary notification per interval
+type NotificationDigestService struct {
+	internalNotificationRepo repository.InternalNotificationRepository
+	preferenceRepo           repository.UserNotificationPreferenceRepository
+	logger                   zerolog.Logger
+}
+
+// NewNotificationDigestService creates a new NotificationDigestService
+func NewNotificationDigestService(
+	internalNotificationRepo repository.InternalNotificationRepository,
+	preferenceRepo repository.UserNotificationPreferenceRepository,
+	logger zerolog.Logger,
+) *NotificationDigestService {
+	return &NotificationDigestService{
+		internalNotificationRepo: internalNotificationRepo,
+		preferenceRepo:           preferenceRepo,
+		logger:                   logger.With().Str("service", "notification_digest").Logger(),
+	}
+}
+
+// GetPreference returns how a user receives internal notifications, real time
+// when the user never chose
+func (s *NotificationDigestService) GetPreference(ctx context.Context, userID int64) (*domain.UserNotificationPreference, error) {
+	pref, err := s.preferenceRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+	if pref == nil {
+		pref = &domain.UserNotificationPreference{UserID: userID, DigestIntervalHours: DefaultNotificationDigestIntervalHours}
+	}
+	return pref, nil
+}
+
+// UpdatePreference switches a user between real time and digest delivery.
+// Leaving digest mode delivers the notifications held so far as a last digest.
+func (s *NotificationDigestService) UpdatePreference(ctx context.Context, userID int64, input UpdateNotificationPreferenceInput) (*domain.UserNotificationPreference, error) {
+	if input.DigestIntervalHours == 0 {
+		input.DigestIntervalHours = DefaultNotificationDigestIntervalHours
+	}
+	if input.DigestIntervalHours < 1 || input.DigestIntervalHours > MaxNotificationDigestIntervalHours {
+		return nil, fmt.Errorf("%w: digest interval must be between 1 and %d hours", ErrInvalidInput, MaxNotificationDigestIntervalHours)
+	}
+
+	pref := &domain.UserNotificationPreference{
+		UserID:              userID,
+		DigestMode:          input.DigestMode,
+		DigestIntervalHours: input.DigestIntervalHours,
+	}
+	if err := s.preferenceRepo.Upsert(ctx, pref); err != nil {
+		return nil, fmt.Errorf("failed to save notification preference: %w", err)
+	}
+
+	if !pref.DigestMode {
+		if _, err := s.sendDigest(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return pref, nil
+}
+
+// SendDue sends the digests that are due and returns how many were sent. A
+// failure for one user is logged and does not hold back the others.
+func (s *NotificationDigestService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	prefs, err := s.preferenceRepo.ListDigestUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list digest users: %w", err)
+	}
+
+	sent := 0
+	for _, pref := range prefs {
+		if !pref.DigestDue(now) {
+			continue
+		}
+		ok, err := s.sendDigest(ctx, pref.UserID)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("user_id", pref.UserID).Msg("Failed to send notification digest")
+			continue
+		}
+		// The interval restarts even when there was nothing to send
+		if err := s.preferenceRepo.MarkDigestSent(ctx, pref.UserID, now); err != nil {
+			s.logger.Error().Err(err).Int64("user_id", pref.UserID).Msg("Failed to record notification digest")
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendDigest bundles a user's held notifications into one summary and marks
+// them as delivered. It reports whether there was anything to send.
+func (s *NotificationDigestService) sendDigest(ctx context.Context, userID int64) (bool, error) {
+	pending, err := s.internalNotificationRepo.ListDigestPending(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list notifications held for digest: %w", err)
+	}
+	if len(pending) == 0 {
+		return false, nil
+	}
+
+	ids := make([]int64, len(pending))
+	for i, notification := range pending {
+		ids[i] = notification.ID
+	}
+	// The digest and the release of what it covers are saved together, so a
+	// failure can never deliver the same notifications twice
+	if err := s.internalNotificationRepo.CreateDigest(ctx, buildNotificationDigest(userID, pending), ids); err != nil {
+		return false, fmt.Errorf("failed to create notification digest: %w", err)
+	}
+	return true, nil
+}
+
+// holdForDigests flags the notifications of users in digest mode, looking up
+// only the users they are addressed to. When the preferences cannot be read
+// the notifications are delivered right away.
+func holdForDigests(ctx context.Context, preferenceRepo repository.UserNotificationPreferenceRepository, logger zerolog.Logger, notifications []*domain.InternalNotification) {
+	if preferenceRepo == nil || len(notifications) == 0 {
+		return
+	}
+
+	seen := make(map[int64]bool, len(notifications))
+	var userIDs []int64
+	for _, notification := range notifications {
+		if !seen[notification.UserID] {
+			seen[notification.UserID] = true
+			userIDs = append(userIDs, notification.UserID)
+		}
+	}
+
+	digestUserIDs, err := preferenceRepo.ListDigestUserIDs(ctx, userIDs)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to list digest users, delivering notifications right away")
+		return
+	}
+	digestUsers := make(map[int64]bool, len(digestUserIDs))
+	for _, userID := range digestUserIDs {
+		digestUsers[userID] = true
+	}
+	for _, notification := range notifications {
+		notification.DigestPending = digestUsers[notification.UserID]
+	}
+}
+
+// buildNotificationDigest summarizes notifications, oldest first, in a single
+// notification of their most severe type
+func buildNotificationDigest(userID int64, notifications []*domain.InternalNotification) *domain.InternalNotification {
+	digest := &domain.InternalNotification{
+		UserID:        userID,
+		Title:         fmt.Sprintf("Resumen de notificaciones (%d)", len(notifications)),
+		Type:          "info",
+		ReferenceType: "notification_digest",
+	}
+
+	var lines []string
+	sameBranch := true
+	for i, notification := range notifications {
+		if notificationTypeSeverity[notification.Type] > notificationTypeSeverity[digest.Type] {
+			digest.Type = notification.Type
+		}
+		if i > 0 && !sameInt64(notification.BranchID, notifications[0].BranchID) {
+			sameBranch = false
+		}
+		if i < notificationDigestMaxLines {
+			lines = append(lines, fmt.Sprintf("- %s: %s", notification.Title, notification.Message))
+		}
+	}
+	if extra := len(notifications) - notificationDigestMaxLines; extra > 0 {
+		lines = append(lines, fmt.Sprintf("... y %d más", extra))
+	}
+	if sameBranch {
+		digest.BranchID = notifications[0].BranchID
+	}
+
+	digest.Message = fmt.Sprintf("Tiene %d notificaciones desde el último resumen:\n%s", len(notifications), strings.Join(lines, "\n"))
+	return digest
+}
+
+// sameInt64 checks if two optional IDs are equal
+func sameInt64(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// NotificationSender delivers a notification over a single channel. Senders
+// whose provider reports delivery later set the notification's
+// ExternalMessageID so its delivery receipts can be matched.
+type NotificationSender interface {
+	Send(ctx context.Context, notification *domain.Notification) error
+}
+
+// DispatchResult summarizes a dispatch run
+type DispatchResult struct {
+	Sent      int `json:"sent"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	Skipped   int `json:"skipped"`
+
+	// Drain only: notifications left pending because their provider was throttling
+	Throttled int `json:"throttled"`
+	Deferred  int `json:"deferred"`
+	Batches   int `json:"batches"`
+
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	PerSecond      float64 `json:"per_second"` // Notifications sent per second
+}
+
+// NotificationDispatcher delivers queued customer notifications
+type NotificationDispatcher struct {
+	notificationRepo repository.NotificationRepository
+	loanRepo         repository.LoanRepository
+	senders          map[string]NotificationSender
+	escalation       *NotificationEscalationService
+	settingRepo      repository.SettingRepository
+	logger           zerolog.Logger
+
+	// Backpressure state of each channel, kept between drains
+	mu        sync.Mutex
+	throttles map[string]*channelThrottle
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// NewNotificationDispatcher creates a new NotificationDispatcher
+func NewNotificationDispatcher(
+	notificationRepo repository.NotificationRepository,
+	loanRepo repository.LoanRepository,
+	logger zerolog.Logger,
+) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		notificationRepo: notificationRepo,
+		loanRepo:         loanRepo,
+		senders:          make(map[string]NotificationSender),
+		logger:           logger.With().Str("service", "notification_dispatcher").Logger(),
+		throttles:        make(map[string]*channelThrottle),
+		now:              time.Now,
+		sleep:            sleepContext,
+	}
+}
+
+// RegisterSender registers the sender used for a channel
+func (d *NotificationDispatcher) RegisterSender(channel string, sender NotificationSender) {
+	d.senders[channel] = sender
+}
+
+// Channels returns the channels a sender is registered for
+func (d *NotificationDispatcher) Channels() []string {
+	channels := make([]string, 0, len(d.senders))
+	for channel := range d.senders {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// SetEscalation enables tracking of repeatedly failing customer channels
+func (d *NotificationDispatcher) SetEscalation(escalation *NotificationEscalationService) {
+	d.escalation = escalation
+}
+
+// DispatchPending delivers pending notifications that are due
+func (d *NotificationDispatcher) DispatchPending(ctx context.Context, limit int) (*DispatchResult, error) {
+	notifications, err := d.notificationRepo.ListPending(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending notifications: %w", err)
+	}
+
+	result := &DispatchResult{}
+	for _, notification := range notifications {
+		switch d.Dispatch(ctx, notification) {
+		case domain.NotificationStatusSent:
+			result.Sent++
+		case domain.NotificationStatusFailed:
+			result.Failed++
+		case domain.NotificationStatusCancelled:
+			result.Cancelled++
+		default:
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// Dispatch delivers a single notification and returns its resulting status.
+// Loan balance reminders are re-checked against the loan first and cancelled
+// when the loan has been paid or closed since they were queued. A notification
+// refused because the provider is throttling stays pending.
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, notification *domain.Notification) string {
+	status, _ := d.dispatch(ctx, notification)
+	return status
+}
+
+// dispatch delivers a single notification. When the provider is throttling,
+// the notification stays pending without counting as a failure and the
+// throttling error is returned.
+func (d *NotificationDispatcher) dispatch(ctx context.Context, notification *domain.Notification) (string, error) {
+	log := d.logger.With().
+		Int64("notification_id", notification.ID).
+		Str("type", notification.NotificationType).
+		Str("channel", notification.Channel).
+		Logger()
+
+	if notification.RequiresOpenLoan() {
+		loan, err := d.loanRepo.GetByID(ctx, *notification.ReferenceID)
+		if err != nil {
+			log.Error().Err(err).Int64("loan_id", *notification.ReferenceID).Msg("Failed to re-check loan before sending")
+			return domain.NotificationStatusPending, nil
+		}
+		if loan == nil || !loan.IsOpen() || loan.RemainingBalance() <= 0 {
+			if err := d.notificationRepo.Cancel(ctx, notification.ID); err != nil {
+				log.Error().Err(err).Msg("Failed to cancel stale loan notification")
+				return domain.NotificationStatusPending, nil
+			}
+			log.Info().Int64("loan_id", *notification.ReferenceID).Msg("Cancelled notification: loan no longer has a balance")
+			return domain.NotificationStatusCancelled, nil
+		}
+	}
+
+	sender, ok := d.senders[notification.Channel]
+	if !ok {
+		log.Debug().Msg("No sender registered for channel, leaving notification pending")
+		return domain.NotificationStatusPending, nil
+	}
+
+	if d.escalation != nil {
+		paused, err := d.escalation.IsChannelPaused(ctx, notification.CustomerID, notification.Channel)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to check notification channel status")
+			return domain.NotificationStatusPending, nil
+		}
+		if paused {
+			if err := d.notificationRepo.Cancel(ctx, notification.ID); err != nil {
+				log.Error().Err(err).Msg("Failed to cancel notification for paused channel")
+				return domain.NotificationStatusPending, nil
+			}
+			log.Info().Int64("customer_id", notification.CustomerID).Msg("Cancelled notification: channel paused until contact details are verified")
+			return domain.NotificationStatusCancelled, nil
+		}
+	}
+
+	if err := sender.Send(ctx, notification); err != nil {
+		if _, throttled := throttleDelay(err); throttled {
+			log.Warn().Err(err).Msg("Provider throttled notification, leaving it pending")
+			return domain.NotificationStatusPending, err
+		}
+		log.Warn().Err(err).Msg("Failed to send notification")
+		if err := d.notificationRepo.MarkAsFailed(ctx, notification.ID, err.Error()); err != nil {
+			log.Error().Err(err).Msg("Failed to mark notification as failed")
+		}
+		if d.escalation != nil {
+			if _, err := d.escalation.RecordFailure(ctx, notification); err != nil {
+				log.Error().Err(err).Msg("Failed to record notification failure")
+			}
+		}
+		return domain.NotificationStatusFailed, nil
+	}
+
+	if err := d.notificationRepo.MarkAsSent(ctx, notification.ID); err != nil {
+		log.Error().Err(err).Msg("Failed to mark notification as sent")
+	}
+	if notification.ContainsSecret() {
+		if err := d.notificationRepo.RedactBody(ctx, notification.ID, domain.RedactedNotificationBody); err != nil {
+			log.Error().Err(err).Msg("Failed to redact sent notification")
+		}
+	}
+	if notification.ExternalMessageID != "" {
+		if err := d.notificationRepo.SetExternalMessageID(ctx, notification.ID, notification.ExternalMessageID); err != nil {
+			log.Error().Err(err).Msg("Failed to store provider message ID")
+		}
+	}
+	if d.escalation != nil {
+		if err := d.escalation.RecordSuccess(ctx, notification); err != nil {
+			log.Error().Err(err).Msg("Failed to reset notification failure streak")
+		}
+	}
+	return domain.NotificationStatusSent, nil
+}
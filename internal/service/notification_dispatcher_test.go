@@ -0,0 +1,595 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+type mockNotificationSender struct {
+	mock.Mock
+}
+
+func (m *mockNotificationSender) Send(ctx context.Context, notification *domain.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func setupNotificationDispatcher() (*NotificationDispatcher, *mocks.MockNotificationRepository, *mocks.MockLoanRepository, *mockNotificationSender) {
+	notificationRepo := new(mocks.MockNotificationRepository)
+	loanRepo := new(mocks.MockLoanRepository)
+	sender := new(mockNotificationSender)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	dispatcher := NewNotificationDispatcher(notificationRepo, loanRepo, logger)
+	dispatcher.RegisterSender(domain.NotificationChannelSMS, sender)
+	return dispatcher, notificationRepo, loanRepo, sender
+}
+
+func loanReminder(id, loanID int64) *domain.Notification {
+	return &domain.Notification{
+		ID:               id,
+		CustomerID:       1,
+		NotificationType: domain.NotificationTypeLoanDueReminder,
+		Channel:          domain.NotificationChannelSMS,
+		ReferenceType:    "loan",
+		ReferenceID:      &loanID,
+		Status:           domain.NotificationStatusPending,
+	}
+}
+
+func TestNotificationDispatcher_CancelsReminderForPaidLoan(t *testing.T) {
+	dispatcher, notificationRepo, loanRepo, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+
+	notification := loanReminder(1, 10)
+	loanRepo.On("GetByID", ctx, int64(10)).Return(&domain.Loan{ID: 10, Status: domain.LoanStatusPaid}, nil)
+	notificationRepo.On("Cancel", ctx, int64(1)).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusCancelled, status)
+	notificationRepo.AssertExpectations(t)
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	notificationRepo.AssertNotCalled(t, "MarkAsSent", mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcher_SendsReminderForOpenLoan(t *testing.T) {
+	dispatcher, notificationRepo, loanRepo, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+
+	notification := loanReminder(2, 11)
+	loanRepo.On("GetByID", ctx, int64(11)).Return(&domain.Loan{ID: 11, Status: domain.LoanStatusActive, PrincipalRemaining: 100}, nil)
+	sender.On("Send", ctx, notification).Return(nil)
+	notificationRepo.On("MarkAsSent", ctx, int64(2)).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusSent, status)
+	sender.AssertExpectations(t)
+	notificationRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcher_PaymentReceivedNotRechecked(t *testing.T) {
+	dispatcher, notificationRepo, loanRepo, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+
+	notification := loanReminder(3, 12)
+	notification.NotificationType = domain.NotificationTypePaymentReceived
+	sender.On("Send", ctx, notification).Return(nil)
+	notificationRepo.On("MarkAsSent", ctx, int64(3)).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusSent, status)
+	loanRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	notificationRepo.AssertNotCalled(t, "RedactBody", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcher_RedactsVerificationCodeOnceSent(t *testing.T) {
+	dispatcher, notificationRepo, _, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+
+	notification := &domain.Notification{
+		ID:               4,
+		CustomerID:       1,
+		NotificationType: domain.NotificationTypeContactVerification,
+		Channel:          domain.NotificationChannelSMS,
+		Body:             "Su código de verificación es 123456",
+		Status:           domain.NotificationStatusPending,
+	}
+	sender.On("Send", ctx, notification).Return(nil)
+	notificationRepo.On("MarkAsSent", ctx, int64(4)).Return(nil)
+	notificationRepo.On("RedactBody", ctx, int64(4), domain.RedactedNotificationBody).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusSent, status)
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestNotificationDispatcher_DispatchPending(t *testing.T) {
+	dispatcher, notificationRepo, loanRepo, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+
+	paid := loanReminder(1, 10)
+	open := loanReminder(2, 11)
+	failing := loanReminder(3, 11)
+	email := &domain.Notification{ID: 4, Channel: domain.NotificationChannelEmail, Status: domain.NotificationStatusPending}
+
+	notificationRepo.On("ListPending", ctx, 50).Return([]*domain.Notification{paid, open, failing, email}, nil)
+	loanRepo.On("GetByID", ctx, int64(10)).Return(&domain.Loan{ID: 10, Status: domain.LoanStatusPaid}, nil)
+	loanRepo.On("GetByID", ctx, int64(11)).Return(&domain.Loan{ID: 11, Status: domain.LoanStatusOverdue, PrincipalRemaining: 100}, nil)
+	notificationRepo.On("Cancel", ctx, int64(1)).Return(nil)
+	sender.On("Send", ctx, open).Return(nil)
+	sender.On("Send", ctx, failing).Return(errors.New("gateway timeout"))
+	notificationRepo.On("MarkAsSent", ctx, int64(2)).Return(nil)
+	notificationRepo.On("MarkAsFailed", ctx, int64(3), "gateway timeout").Return(nil)
+
+	result, err := dispatcher.DispatchPending(ctx, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &DispatchResult{Sent: 1, Failed: 1, Cancelled: 1, Skipped: 1}, result)
+}
+
+type stubWhatsAppSender struct {
+	phones, messages []string
+}
+
+func (s *stubWhatsAppSender) SendWhatsApp(ctx context.Context, phone, message string) (string, error) {
+	s.phones = append(s.phones, phone)
+	s.messages = append(s.messages, message)
+	return fmt.Sprintf("wamid.%d", len(s.phones)), nil
+}
+
+func TestNotificationDispatcher_WhatsApp(t *testing.T) {
+	dispatcher, notificationRepo, _, _ := setupNotificationDispatcher()
+	customerRepo := new(mocks.MockCustomerRepository)
+	provider := &stubWhatsAppSender{}
+	dispatcher.SetWhatsAppSender(provider, customerRepo)
+	ctx := context.Background()
+
+	sent := &domain.Notification{
+		ID: 5, CustomerID: 7, NotificationType: domain.NotificationTypePaymentReceived, Channel: domain.NotificationChannelWhatsApp,
+		Subject: "Pago recibido", Body: "Recibimos su pago de Q 150.00", Status: domain.NotificationStatusPending,
+	}
+	noPhone := &domain.Notification{
+		ID: 6, CustomerID: 8, NotificationType: domain.NotificationTypePaymentReceived, Channel: domain.NotificationChannelWhatsApp,
+		Body: "Recibimos su pago", Status: domain.NotificationStatusPending,
+	}
+	customerRepo.On("GetByID", ctx, int64(7)).Return(&domain.Customer{ID: 7, Phone: " 50255551234 "}, nil)
+	customerRepo.On("GetByID", ctx, int64(8)).Return(&domain.Customer{ID: 8}, nil)
+	notificationRepo.On("MarkAsSent", ctx, int64(5)).Return(nil)
+	notificationRepo.On("SetExternalMessageID", ctx, int64(5), "wamid.1").Return(nil)
+	notificationRepo.On("MarkAsFailed", ctx, int64(6), ErrCustomerHasNoPhone.Error()).Return(nil)
+
+	assert.Equal(t, domain.NotificationStatusSent, dispatcher.Dispatch(ctx, sent))
+	assert.Equal(t, domain.NotificationStatusFailed, dispatcher.Dispatch(ctx, noPhone))
+	assert.Equal(t, []string{"50255551234"}, provider.phones)
+	assert.Equal(t, []string{"*Pago recibido*\nRecibimos su pago de Q 150.00"}, provider.messages)
+	notificationRepo.AssertExpectations(t)
+}
+
+type stubSMSSender struct {
+	phones, messages []string
+}
+
+func (s *stubSMSSender) SendSMS(ctx context.Context, phone, message string) (string, error) {
+	s.phones = append(s.phones, phone)
+	s.messages = append(s.messages, message)
+	return fmt.Sprintf("SM%d", len(s.phones)), nil
+}
+
+func TestNotificationDispatcher_SMS(t *testing.T) {
+	dispatcher, notificationRepo, _, _ := setupNotificationDispatcher()
+	customerRepo := new(mocks.MockCustomerRepository)
+	provider := &stubSMSSender{}
+	dispatcher.SetSMSSender(provider, customerRepo)
+	ctx := context.Background()
+
+	notification := &domain.Notification{
+		ID: 5, CustomerID: 7, NotificationType: domain.NotificationTypePaymentReceived, Channel: domain.NotificationChannelSMS,
+		Subject: "Pago recibido", Body: "Recibimos su pago de Q 150.00", Status: domain.NotificationStatusPending,
+	}
+	customerRepo.On("GetByID", ctx, int64(7)).Return(&domain.Customer{ID: 7, Phone: "+50255551234"}, nil)
+	notificationRepo.On("MarkAsSent", ctx, int64(5)).Return(nil)
+	notificationRepo.On("SetExternalMessageID", ctx, int64(5), "SM1").Return(nil)
+
+	assert.Equal(t, domain.NotificationStatusSent, dispatcher.Dispatch(ctx, notification))
+	assert.Equal(t, []string{"+50255551234"}, provider.phones)
+	assert.Equal(t, []string{"Recibimos su pago de Q 150.00"}, provider.messages)
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestEmailNotificationSender_Send(t *testing.T) {
+	customerRepo := new(mocks.MockCustomerRepository)
+	sender := NewEmailNotificationSender("smtp.example.com", 587, "", "", "avisos@example.com", customerRepo)
+	var addr string
+	var to []string
+	var msg []byte
+	sender.sendMail = func(a string, auth smtp.Auth, from string, recipients []string, m []byte) error {
+		addr, to, msg = a, recipients, m
+		return nil
+	}
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(7)).Return(&domain.Customer{ID: 7, Email: "ana@example.com"}, nil)
+	customerRepo.On("GetByID", ctx, int64(8)).Return(&domain.Customer{ID: 8}, nil)
+
+	err := sender.Send(ctx, &domain.Notification{CustomerID: 7, Subject: "Pago recibido", Body: "Recibimos su pago"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, []string{"ana@example.com"}, to)
+	assert.Contains(t, string(msg), "Subject: Pago recibido\r\n")
+	assert.True(t, strings.HasSuffix(string(msg), "\r\n\r\nRecibimos su pago"))
+
+	err = sender.Send(ctx, &domain.Notification{CustomerID: 8, Body: "Recibimos su pago"})
+	assert.ErrorIs(t, err, ErrCustomerHasNoEmail)
+}
+
+func TestTwilioClient_SendSMS(t *testing.T) {
+	var form url.Values
+	var user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Accounts/AC123/Messages.json", r.URL.Path)
+		user, password, _ = r.BasicAuth()
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM42"}`))
+	}))
+	defer server.Close()
+	client := NewTwilioClient("AC123", "token", "+15005550006")
+	client.baseURL = server.URL
+
+	sid, err := client.SendSMS(context.Background(), "+50255551234", "Recibimos su pago")
+
+	require.NoError(t, err)
+	assert.Equal(t, "SM42", sid)
+	assert.Equal(t, "AC123", user)
+	assert.Equal(t, "token", password)
+	assert.Equal(t, "+15005550006", form.Get("From"))
+	assert.Equal(t, "+50255551234", form.Get("To"))
+	assert.Equal(t, "Recibimos su pago", form.Get("Body"))
+}
+
+func TestTwilioClient_SendWhatsApp(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM43"}`))
+	}))
+	defer server.Close()
+	client := NewTwilioClient("AC123", "token", "").WithWhatsAppFrom("+14155238886")
+	client.baseURL = server.URL
+
+	sid, err := client.SendWhatsApp(context.Background(), "+50255551234", "Recibimos su pago")
+
+	require.NoError(t, err)
+	assert.Equal(t, "SM43", sid)
+	assert.Equal(t, "whatsapp:+14155238886", form.Get("From"))
+	assert.Equal(t, "whatsapp:+50255551234", form.Get("To"))
+}
+
+func TestTwilioClient_SendSMS_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 21211, "message": "The 'To' number is not a valid phone number."}`))
+	}))
+	defer server.Close()
+	client := NewTwilioClient("AC123", "token", "+15005550006")
+	client.baseURL = server.URL
+
+	_, err := client.SendSMS(context.Background(), "123", "Recibimos su pago")
+
+	assert.EqualError(t, err, "twilio responded with status 400: The 'To' number is not a valid phone number.")
+}
+
+// --- Failure escalation tests ---
+
+func setupEscalatingDispatcher() (*NotificationDispatcher, *mocks.MockNotificationRepository, *mocks.MockNotificationChannelStatusRepository, *mocks.MockInternalNotificationRepository, *mocks.MockUserRepository, *mockNotificationSender) {
+	dispatcher, notificationRepo, _, sender := setupNotificationDispatcher()
+	statusRepo := new(mocks.MockNotificationChannelStatusRepository)
+	internalRepo := new(mocks.MockInternalNotificationRepository)
+	userRepo := new(mocks.MockUserRepository)
+	notificationService := NewNotificationService(notificationRepo, nil, nil, internalRepo, nil, userRepo)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	dispatcher.SetEscalation(NewNotificationEscalationService(statusRepo, nil, notificationService, logger))
+	return dispatcher, notificationRepo, statusRepo, internalRepo, userRepo, sender
+}
+
+func exhaustedPaymentNotice(id int64) *domain.Notification {
+	branchID := int64(1)
+	return &domain.Notification{
+		ID:               id,
+		CustomerID:       1,
+		BranchID:         &branchID,
+		NotificationType: domain.NotificationTypePaymentReceived,
+		Channel:          domain.NotificationChannelSMS,
+		Status:           domain.NotificationStatusPending,
+		RetryCount:       domain.NotificationMaxRetries,
+	}
+}
+
+func TestNotificationDispatcher_CrossingFailureThresholdPausesChannelAndAlertsStaff(t *testing.T) {
+	dispatcher, notificationRepo, statusRepo, internalRepo, userRepo, sender := setupEscalatingDispatcher()
+	ctx := context.Background()
+
+	notification := exhaustedPaymentNotice(5)
+	statusRepo.On("Get", ctx, int64(1), domain.NotificationChannelSMS).Return(nil, nil)
+	sender.On("Send", ctx, notification).Return(errors.New("invalid number"))
+	notificationRepo.On("MarkAsFailed", ctx, int64(5), "invalid number").Return(nil)
+	statusRepo.On("RecordFailure", ctx, int64(1), domain.NotificationChannelSMS, mock.AnythingOfType("time.Time")).
+		Return(&domain.NotificationChannelStatus{CustomerID: 1, Channel: domain.NotificationChannelSMS, FailureStreak: DefaultNotificationFailureThreshold}, nil)
+	statusRepo.On("Pause", ctx, int64(1), domain.NotificationChannelSMS, mock.AnythingOfType("string")).Return(nil)
+	userRepo.On("List", ctx, mock.AnythingOfType("repository.UserListParams")).Return(&repository.PaginatedResult[domain.User]{
+		Data: []domain.User{{ID: 7}, {ID: 8}},
+	}, nil)
+	internalRepo.On("CreateBulk", ctx, mock.MatchedBy(func(alerts []*domain.InternalNotification) bool {
+		return len(alerts) == 2 && *alerts[0].BranchID == 1 && alerts[0].Type == "warning"
+	})).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusFailed, status)
+	statusRepo.AssertExpectations(t)
+	internalRepo.AssertExpectations(t)
+}
+
+func TestNotificationDispatcher_FailureBelowThresholdDoesNotPause(t *testing.T) {
+	dispatcher, notificationRepo, statusRepo, internalRepo, _, sender := setupEscalatingDispatcher()
+	ctx := context.Background()
+
+	notification := exhaustedPaymentNotice(6)
+	statusRepo.On("Get", ctx, int64(1), domain.NotificationChannelSMS).Return(nil, nil)
+	sender.On("Send", ctx, notification).Return(errors.New("invalid number"))
+	notificationRepo.On("MarkAsFailed", ctx, int64(6), "invalid number").Return(nil)
+	statusRepo.On("RecordFailure", ctx, int64(1), domain.NotificationChannelSMS, mock.AnythingOfType("time.Time")).
+		Return(&domain.NotificationChannelStatus{CustomerID: 1, Channel: domain.NotificationChannelSMS, FailureStreak: 1}, nil)
+
+	dispatcher.Dispatch(ctx, notification)
+
+	statusRepo.AssertNotCalled(t, "Pause", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	internalRepo.AssertNotCalled(t, "CreateBulk", mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcher_FailureWithRetriesLeftNotCounted(t *testing.T) {
+	dispatcher, notificationRepo, statusRepo, _, _, sender := setupEscalatingDispatcher()
+	ctx := context.Background()
+
+	notification := exhaustedPaymentNotice(7)
+	notification.RetryCount = 0
+	statusRepo.On("Get", ctx, int64(1), domain.NotificationChannelSMS).Return(nil, nil)
+	sender.On("Send", ctx, notification).Return(errors.New("gateway timeout"))
+	notificationRepo.On("MarkAsFailed", ctx, int64(7), "gateway timeout").Return(nil)
+
+	dispatcher.Dispatch(ctx, notification)
+
+	statusRepo.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcher_PausedChannelCancelsNotification(t *testing.T) {
+	dispatcher, notificationRepo, statusRepo, _, _, sender := setupEscalatingDispatcher()
+	ctx := context.Background()
+
+	pausedAt := time.Now()
+	notification := exhaustedPaymentNotice(8)
+	statusRepo.On("Get", ctx, int64(1), domain.NotificationChannelSMS).Return(&domain.NotificationChannelStatus{PausedAt: &pausedAt}, nil)
+	notificationRepo.On("Cancel", ctx, int64(8)).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusCancelled, status)
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcher_SuccessResetsFailureStreak(t *testing.T) {
+	dispatcher, notificationRepo, statusRepo, _, _, sender := setupEscalatingDispatcher()
+	ctx := context.Background()
+
+	notification := exhaustedPaymentNotice(9)
+	statusRepo.On("Get", ctx, int64(1), domain.NotificationChannelSMS).Return(nil, nil)
+	sender.On("Send", ctx, notification).Return(nil)
+	notificationRepo.On("MarkAsSent", ctx, int64(9)).Return(nil)
+	statusRepo.On("ResetStreak", ctx, int64(1), domain.NotificationChannelSMS).Return(nil)
+
+	status := dispatcher.Dispatch(ctx, notification)
+
+	assert.Equal(t, domain.NotificationStatusSent, status)
+	statusRepo.AssertExpectations(t)
+}
+
+// --- Queue drain tests ---
+
+// drainSettings configures the drain limits of a dispatcher
+func drainSettings(dispatcher *NotificationDispatcher, batchSize, maxBatches int, concurrency map[string]interface{}) {
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingNotificationDrainBatchSize, mock.Anything).
+		Return(&domain.Setting{Value: float64(batchSize)}, nil)
+	settingRepo.On("Get", mock.Anything, SettingNotificationDrainMaxBatches, mock.Anything).
+		Return(&domain.Setting{Value: float64(maxBatches)}, nil)
+	settingRepo.On("Get", mock.Anything, SettingNotificationDrainBatchDelayMs, mock.Anything).
+		Return(&domain.Setting{Value: float64(250)}, nil)
+	settingRepo.On("Get", mock.Anything, SettingNotificationDrainConcurrency, mock.Anything).
+		Return(&domain.Setting{Value: concurrency}, nil)
+	dispatcher.SetDrainSettings(settingRepo)
+}
+
+func smsNotices(fromID, count int) []*domain.Notification {
+	notifications := make([]*domain.Notification, count)
+	for i := range notifications {
+		notifications[i] = &domain.Notification{
+			ID:               int64(fromID + i),
+			CustomerID:       1,
+			NotificationType: domain.NotificationTypePaymentReceived,
+			Channel:          domain.NotificationChannelSMS,
+			Status:           domain.NotificationStatusPending,
+		}
+	}
+	return notifications
+}
+
+// concurrencySender records how many sends are in flight at once
+type concurrencySender struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+	calls    int
+}
+
+func (s *concurrencySender) Send(ctx context.Context, notification *domain.Notification) error {
+	s.mu.Lock()
+	s.inFlight++
+	s.calls++
+	if s.inFlight > s.max {
+		s.max = s.inFlight
+	}
+	s.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	return nil
+}
+
+func TestNotificationDispatcher_DrainRespectsBatchSizeAndConcurrency(t *testing.T) {
+	dispatcher, notificationRepo, _, _ := setupNotificationDispatcher()
+	ctx := context.Background()
+	sender := &concurrencySender{}
+	dispatcher.RegisterSender(domain.NotificationChannelSMS, sender)
+	drainSettings(dispatcher, 4, 5, map[string]interface{}{"sms": float64(2)})
+	var pauses []time.Duration
+	dispatcher.sleep = func(ctx context.Context, d time.Duration) error {
+		pauses = append(pauses, d)
+		return nil
+	}
+
+	queue := smsNotices(1, 10)
+	notificationRepo.On("ListPendingExcept", ctx, 4, []string{}).Return(queue[0:4], nil).Once()
+	notificationRepo.On("ListPendingExcept", ctx, 4, []string{}).Return(queue[4:8], nil).Once()
+	notificationRepo.On("ListPendingExcept", ctx, 4, []string{}).Return(queue[8:10], nil).Once()
+	notificationRepo.On("MarkAsSent", ctx, mock.Anything).Return(nil)
+
+	result, err := dispatcher.Drain(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, result.Sent)
+	assert.Equal(t, 3, result.Batches)
+	assert.Equal(t, 10, sender.calls)
+	assert.LessOrEqual(t, sender.max, 2)
+	// The last batch was short, so the queue is empty and there is no fourth read
+	notificationRepo.AssertNumberOfCalls(t, "ListPendingExcept", 3)
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, pauses)
+}
+
+func TestNotificationDispatcher_DrainThrottlingSlowsChannelInsteadOfFailing(t *testing.T) {
+	dispatcher, notificationRepo, _, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+	drainSettings(dispatcher, 10, 5, map[string]interface{}{"sms": float64(1)})
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	dispatcher.now = func() time.Time { return now }
+	dispatcher.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	queue := smsNotices(1, 3)
+	notificationRepo.On("ListPendingExcept", ctx, 10, []string{}).Return(queue, nil)
+	notificationRepo.On("ListPendingExcept", ctx, 10, []string{domain.NotificationChannelSMS}).Return([]*domain.Notification{}, nil)
+	sender.On("Send", ctx, queue[0]).Return(&ProviderThrottledError{RetryAfter: 2 * time.Minute}).Once()
+
+	result, err := dispatcher.Drain(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Throttled)
+	assert.Equal(t, 2, result.Deferred)
+	assert.Equal(t, 0, result.Failed)
+	// Nothing progressed, so the drain stops instead of re-reading the same batch
+	assert.Equal(t, 1, result.Batches)
+	notificationRepo.AssertNotCalled(t, "MarkAsFailed", mock.Anything, mock.Anything, mock.Anything)
+	notificationRepo.AssertNotCalled(t, "IncrementRetry", mock.Anything, mock.Anything)
+
+	// While the channel cools down its notifications are not even read
+	now = now.Add(time.Minute)
+	result, err = dispatcher.Drain(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Batches)
+	sender.AssertNumberOfCalls(t, "Send", 1)
+
+	// After the provider's retry-after, sending resumes
+	now = now.Add(90 * time.Second)
+	sender.On("Send", ctx, mock.Anything).Return(nil)
+	notificationRepo.On("MarkAsSent", ctx, mock.Anything).Return(nil)
+	result, err = dispatcher.Drain(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Sent)
+}
+
+func TestNotificationDispatcher_DrainSkipsThrottledChannel(t *testing.T) {
+	dispatcher, notificationRepo, _, sender := setupNotificationDispatcher()
+	ctx := context.Background()
+	email := new(mockNotificationSender)
+	dispatcher.RegisterSender(domain.NotificationChannelEmail, email)
+	drainSettings(dispatcher, 2, 5, nil)
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	dispatcher.now = func() time.Time { return now }
+	dispatcher.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	// The oldest batch is all SMS, and the SMS provider throttles
+	sms := smsNotices(1, 2)
+	mail := &domain.Notification{ID: 3, CustomerID: 1, NotificationType: domain.NotificationTypePaymentReceived, Channel: domain.NotificationChannelEmail, Status: domain.NotificationStatusPending}
+	notificationRepo.On("ListPendingExcept", ctx, 2, []string{}).Return(sms, nil).Once()
+	notificationRepo.On("ListPendingExcept", ctx, 2, []string{domain.NotificationChannelSMS}).Return([]*domain.Notification{mail}, nil).Once()
+	sender.On("Send", ctx, sms[0]).Return(&ProviderThrottledError{RetryAfter: time.Minute}).Once()
+	email.On("Send", ctx, mail).Return(nil).Once()
+	notificationRepo.On("MarkAsSent", ctx, mock.Anything).Return(nil)
+
+	result, err := dispatcher.Drain(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Throttled)
+	assert.Equal(t, 1, result.Sent)
+	assert.Equal(t, 2, result.Batches)
+	email.AssertExpectations(t)
+}
+
+func TestNotificationDispatcher_ThrottlingHalvesChannelConcurrency(t *testing.T) {
+	dispatcher, _, _, _ := setupNotificationDispatcher()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	dispatcher.now = func() time.Time { return now }
+
+	dispatcher.updateThrottle(domain.NotificationChannelSMS, 8, channelOutcome{throttled: 1})
+	limit, coolingDown := dispatcher.channelLimit(domain.NotificationChannelSMS, 8)
+	assert.Equal(t, 4, limit)
+	assert.True(t, coolingDown)
+
+	// A second throttle in a row doubles the backoff
+	now = now.Add(notificationThrottleBaseBackoff)
+	dispatcher.updateThrottle(domain.NotificationChannelSMS, 8, channelOutcome{throttled: 1})
+	assert.Equal(t, 2*notificationThrottleBaseBackoff, dispatcher.throttles[domain.NotificationChannelSMS].backoff)
+
+	// Clean batches grow the concurrency back one at a time
+	now = now.Add(time.Hour)
+	dispatcher.updateThrottle(domain.NotificationChannelSMS, 8, channelOutcome{sent: 2})
+	limit, coolingDown = dispatcher.channelLimit(domain.NotificationChannelSMS, 8)
+	assert.Equal(t, 3, limit)
+	assert.False(t, coolingDown)
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/pkg/metrics"
+)
+
+// Settings controlling how the worker drains the notification queue
+const (
+	// SettingNotificationDrainBatchSize is how many pending notifications are read per batch
+	SettingNotificationDrainBatchSize = "notification_drain_batch_size"
+	// SettingNotificationDrainBatchDelayMs is the pause between batches, in milliseconds
+	SettingNotificationDrainBatchDelayMs = "notification_drain_batch_delay_ms"
+	// SettingNotificationDrainMaxBatches is how many batches a single drain may send
+	SettingNotificationDrainMaxBatches = "notification_drain_max_batches"
+	// SettingNotificationDrainConcurrency is the concurrent sends allowed per channel,
+	// e.g. {"sms": 2, "email": 5}
+	SettingNotificationDrainConcurrency = "notification_drain_concurrency"
+)
+
+// Default drain limits
+const (
+	DefaultNotificationDrainBatchSize   = 50
+	DefaultNotificationDrainBatchDelay  = time.Second
+	DefaultNotificationDrainMaxBatches  = 10
+	DefaultNotificationDrainConcurrency = 2
+)
+
+// Backoff of a throttled channel, doubled on every throttle in a row
+const (
+	notificationThrottleBaseBackoff = 30 * time.Second
+	notificationThrottleMaxBackoff  = 10 * time.Minute
+)
+
+// ErrProviderThrottled is returned by senders when the provider refuses a
+// request because of its rate limits. Such notifications stay pending and
+// slow their channel down instead of using up their retries.
+var ErrProviderThrottled = errors.New("notification provider is throttling requests")
+
+// ProviderThrottledError is a throttling error carrying how long the provider
+// asked to wait, when it said
+type ProviderThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ProviderThrottledError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s, retry after %s", ErrProviderThrottled, e.RetryAfter)
+	}
+	return ErrProviderThrottled.Error()
+}
+
+// Is makes a ProviderThrottledError match ErrProviderThrottled
+func (e *ProviderThrottledError) Is(target error) bool {
+	return target == ErrProviderThrottled
+}
+
+// throttleDelay reports whether err is a throttling error and how long the
+// provider asked to wait
+func throttleDelay(err error) (time.Duration, bool) {
+	if !errors.Is(err, ErrProviderThrottled) {
+		return 0, false
+	}
+	var throttled *ProviderThrottledError
+	if errors.As(err, &throttled) {
+		return throttled.RetryAfter, true
+	}
+	return 0, true
+}
+
+// NotificationDrainConfig are the limits of a notification queue drain
+type NotificationDrainConfig struct {
+	BatchSize          int
+	BatchDelay         time.Duration
+	MaxBatches         int
+	Concurrency        map[string]int // Per channel
+	DefaultConcurrency int
+}
+
+// channelConcurrency returns the configured concurrent sends of a channel
+func (c NotificationDrainConfig) channelConcurrency(channel string) int {
+	if limit, ok := c.Concurrency[channel]; ok && limit > 0 {
+		return limit
+	}
+	return c.DefaultConcurrency
+}
+
+// channelThrottle is the backpressure state of a channel. After a throttle the
+// channel cools down until a deadline and runs at half its concurrency, which
+// grows back by one with every batch sent without throttling.
+type channelThrottle struct {
+	limit   int
+	backoff time.Duration
+	until   time.Time
+}
+
+// SetDrainSettings makes the queue drain limits configurable through settings
+func (d *NotificationDispatcher) SetDrainSettings(settingRepo repository.SettingRepository) {
+	d.settingRepo = settingRepo
+}
+
+// drainConfig reads the drain limits
+func (d *NotificationDispatcher) drainConfig(ctx context.Context) NotificationDrainConfig {
+	cfg := NotificationDrainConfig{
+		BatchSize:          getSettingInt(ctx, d.settingRepo, SettingNotificationDrainBatchSize, nil, DefaultNotificationDrainBatchSize),
+		BatchDelay:         time.Duration(getSettingInt(ctx, d.settingRepo, SettingNotificationDrainBatchDelayMs, nil, int(DefaultNotificationDrainBatchDelay/time.Millisecond))) * time.Millisecond,
+		MaxBatches:         getSettingInt(ctx, d.settingRepo, SettingNotificationDrainMaxBatches, nil, DefaultNotificationDrainMaxBatches),
+		DefaultConcurrency: DefaultNotificationDrainConcurrency,
+	}
+	getSettingJSON(ctx, d.settingRepo, SettingNotificationDrainConcurrency, nil, &cfg.Concurrency)
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultNotificationDrainBatchSize
+	}
+	if cfg.BatchDelay < 0 {
+		cfg.BatchDelay = 0
+	}
+	if cfg.MaxBatches <= 0 {
+		cfg.MaxBatches = DefaultNotificationDrainMaxBatches
+	}
+	return cfg
+}
+
+// Drain delivers pending notifications in batches, pausing between batches and
+// limiting the concurrent sends of each channel. A channel whose provider
+// throttles is slowed down: it cools down, its concurrency is halved and its
+// notifications stay pending for a later drain without using up retries.
+// Batches skip the channels cooling down, so the other channels keep going.
+func (d *NotificationDispatcher) Drain(ctx context.Context) (*DispatchResult, error) {
+	cfg := d.drainConfig(ctx)
+	start := d.now()
+	result := &DispatchResult{}
+
+	for batch := 0; batch < cfg.MaxBatches; batch++ {
+		if batch > 0 {
+			if err := d.sleep(ctx, cfg.BatchDelay); err != nil {
+				break
+			}
+		}
+
+		coolingDown := d.coolingDownChannels()
+		notifications, err := d.notificationRepo.ListPendingExcept(ctx, cfg.BatchSize, coolingDown)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pending notifications: %w", err)
+		}
+		if len(notifications) == 0 {
+			break
+		}
+		result.Batches++
+
+		// Stop once a batch makes no progress, everything left is waiting,
+		// unless a channel started cooling down and the next batch skips it
+		progress := d.dispatchBatch(ctx, cfg, notifications, result)
+		if len(notifications) < cfg.BatchSize ||
+			(progress == 0 && len(d.coolingDownChannels()) == len(coolingDown)) {
+			break
+		}
+	}
+
+	result.ElapsedSeconds = d.now().Sub(start).Seconds()
+	if result.ElapsedSeconds > 0 {
+		result.PerSecond = float64(result.Sent) / result.ElapsedSeconds
+	}
+	metrics.SetNotificationDrainRate(result.PerSecond)
+	return result, nil
+}
+
+// dispatchBatch sends a batch channel by channel, each channel concurrently up
+// to its current limit, and returns how many notifications reached a final status
+func (d *NotificationDispatcher) dispatchBatch(ctx context.Context, cfg NotificationDrainConfig, notifications []*domain.Notification, result *DispatchResult) int {
+	byChannel := make(map[string][]*domain.Notification)
+	var channels []string
+	for _, notification := range notifications {
+		if _, ok := byChannel[notification.Channel]; !ok {
+			channels = append(channels, notification.Channel)
+		}
+		byChannel[notification.Channel] = append(byChannel[notification.Channel], notification)
+	}
+
+	progress := 0
+	for _, channel := range channels {
+		queue := byChannel[channel]
+		limit, coolingDown := d.channelLimit(channel, cfg.channelConcurrency(channel))
+		if coolingDown {
+			result.Deferred += len(queue)
+			continue
+		}
+
+		outcome := d.sendChannel(ctx, queue, limit)
+		d.updateThrottle(channel, cfg.channelConcurrency(channel), outcome)
+
+		result.Sent += outcome.sent
+		result.Failed += outcome.failed
+		result.Cancelled += outcome.cancelled
+		result.Skipped += outcome.skipped
+		result.Throttled += outcome.throttled
+		result.Deferred += outcome.deferred
+		progress += outcome.sent + outcome.failed + outcome.cancelled
+	}
+	return progress
+}
+
+// channelOutcome is what happened to a channel's share of a batch
+type channelOutcome struct {
+	sent, failed, cancelled, skipped int
+	throttled, deferred              int
+	retryAfter                       time.Duration
+}
+
+// sendChannel sends a channel's notifications with at most limit in flight.
+// Once the provider throttles, the notifications not yet started are deferred.
+func (d *NotificationDispatcher) sendChannel(ctx context.Context, queue []*domain.Notification, limit int) channelOutcome {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		outcome   channelOutcome
+		throttled bool
+	)
+	jobs := make(chan *domain.Notification)
+
+	for i := 0; i < limit && i < len(queue); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for notification := range jobs {
+				mu.Lock()
+				stop := throttled
+				mu.Unlock()
+				if stop {
+					mu.Lock()
+					outcome.deferred++
+					mu.Unlock()
+					continue
+				}
+
+				status, err := d.dispatch(ctx, notification)
+
+				mu.Lock()
+				if retryAfter, ok := throttleDelay(err); ok {
+					throttled = true
+					outcome.throttled++
+					if retryAfter > outcome.retryAfter {
+						outcome.retryAfter = retryAfter
+					}
+					metrics.RecordNotificationThrottled(notification.Channel)
+				} else {
+					switch status {
+					case domain.NotificationStatusSent:
+						outcome.sent++
+					case domain.NotificationStatusFailed:
+						outcome.failed++
+					case domain.NotificationStatusCancelled:
+						outcome.cancelled++
+					default:
+						outcome.skipped++
+					}
+					if status == domain.NotificationStatusSent || status == domain.NotificationStatusFailed {
+						metrics.RecordNotificationSent(notification.Channel, notification.NotificationType, status)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, notification := range queue {
+		jobs <- notification
+	}
+	close(jobs)
+	wg.Wait()
+
+	return outcome
+}
+
+// channelLimit returns the concurrency a channel may use now and whether it is
+// still cooling down from a throttle
+func (d *NotificationDispatcher) channelLimit(channel string, configured int) (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, ok := d.throttles[channel]
+	if !ok {
+		return configured, false
+	}
+	if d.now().Before(t.until) {
+		return t.limit, true
+	}
+	if t.limit > configured {
+		t.limit = configured
+	}
+	return t.limit, false
+}
+
+// coolingDownChannels lists the channels still cooling down from a throttle
+func (d *NotificationDispatcher) coolingDownChannels() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	channels := []string{}
+	for channel, t := range d.throttles {
+		if d.now().Before(t.until) {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// updateThrottle slows a channel down after a throttle, or lets it recover
+// after a batch the provider accepted
+func (d *NotificationDispatcher) updateThrottle(channel string, configured int, outcome channelOutcome) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, ok := d.throttles[channel]
+	if outcome.throttled == 0 {
+		if !ok {
+			return
+		}
+		t.backoff = 0
+		if t.limit < configured {
+			t.limit++
+		}
+		if t.limit >= configured {
+			delete(d.throttles, channel)
+			t.limit = configured
+		}
+		metrics.SetNotificationChannelConcurrency(channel, t.limit)
+		return
+	}
+
+	if !ok {
+		t = &channelThrottle{limit: configured}
+		d.throttles[channel] = t
+	}
+	t.backoff *= 2
+	if t.backoff < notificationThrottleBaseBackoff {
+		t.backoff = notificationThrottleBaseBackoff
+	}
+	if outcome.retryAfter > t.backoff {
+		t.backoff = outcome.retryAfter
+	}
+	if t.backoff > notificationThrottleMaxBackoff {
+		t.backoff = notificationThrottleMaxBackoff
+	}
+	t.until = d.now().Add(t.backoff)
+	if t.limit = t.limit / 2; t.limit < 1 {
+		t.limit = 1
+	}
+	metrics.SetNotificationChannelConcurrency(channel, t.limit)
+
+	d.logger.Warn().
+		Str("channel", channel).
+		Dur("backoff", t.backoff).
+		Int("concurrency", t.limit).
+		Int("deferred", outcome.deferred).
+		Msg("Notification provider throttling, slowing channel down")
+}
+
+// sleepContext waits for d or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
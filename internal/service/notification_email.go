@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// ErrCustomerHasNoEmail is returned when an email notification is sent to a
+// customer without an email address
+var ErrCustomerHasNoEmail = errors.New("customer has no email address")
+
+// EmailNotificationSender delivers notifications by email through an SMTP server
+type EmailNotificationSender struct {
+	addr         string
+	auth         smtp.Auth
+	from         string
+	customerRepo repository.CustomerRepository
+	sendMail     func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotificationSender creates a new EmailNotificationSender. The
+// server is authenticated against only when a username is given.
+func NewEmailNotificationSender(host string, port int, username, password, from string, customerRepo repository.CustomerRepository) *EmailNotificationSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailNotificationSender{
+		addr:         net.JoinHostPort(host, strconv.Itoa(port)),
+		auth:         auth,
+		from:         from,
+		customerRepo: customerRepo,
+		sendMail:     smtp.SendMail,
+	}
+}
+
+// Send emails a notification to the customer as plain text
+func (s *EmailNotificationSender) Send(ctx context.Context, notification *domain.Notification) error {
+	customer, err := notificationCustomer(ctx, s.customerRepo, notification)
+	if err != nil {
+		return err
+	}
+
+	to := strings.TrimSpace(customer.Email)
+	if to == "" {
+		return ErrCustomerHasNoEmail
+	}
+
+	if err := s.sendMail(s.addr, s.auth, s.from, []string{to}, s.message(to, notification)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message builds the email of a notification
+func (s *EmailNotificationSender) message(to string, notification *domain.Notification) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", notification.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(notification.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// SetEmailSender delivers email notifications through the given sender
+func (d *NotificationDispatcher) SetEmailSender(sender *EmailNotificationSender) {
+	d.RegisterSender(domain.NotificationChannelEmail, sender)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Failed notification escalation settings
+const (
+	// SettingNotificationFailureThreshold is how many notifications to a customer's channel
+	// may exhaust their retries within the window before the channel is paused; 0 disables it
+	SettingNotificationFailureThreshold = "notification_failure_escalation_threshold"
+	// SettingNotificationFailureWindowDays is the window, in days, the failure streak is counted over
+	SettingNotificationFailureWindowDays = "notification_failure_escalation_window_days"
+)
+
+// Default failed notification escalation settings
+const (
+	DefaultNotificationFailureThreshold  = 3
+	DefaultNotificationFailureWindowDays = 30
+)
+
+// NotificationEscalationService tracks notifications that keep failing for a
+// customer's channel and, once too many exhaust their retries, pauses the
+// channel and asks branch staff to verify the contact details
+type NotificationEscalationService struct {
+	statusRepo          repository.NotificationChannelStatusRepository
+	settingRepo         repository.SettingRepository
+	notificationService NotificationService
+	logger              zerolog.Logger
+}
+
+// NewNotificationEscalationService creates a new NotificationEscalationService
+func NewNotificationEscalationService(
+	statusRepo repository.NotificationChannelStatusRepository,
+	settingRepo repository.SettingRepository,
+	notificationService NotificationService,
+	logger zerolog.Logger,
+) *NotificationEscalationService {
+	return &NotificationEscalationService{
+		statusRepo:          statusRepo,
+		settingRepo:         settingRepo,
+		notificationService: notificationService,
+		logger:              logger.With().Str("service", "notification_escalation").Logger(),
+	}
+}
+
+// IsChannelPaused checks if automatic sends to a customer's channel are paused
+func (s *NotificationEscalationService) IsChannelPaused(ctx context.Context, customerID int64, channel string) (bool, error) {
+	status, err := s.statusRepo.Get(ctx, customerID, channel)
+	if err != nil {
+		return false, err
+	}
+	return status.IsPaused(), nil
+}
+
+// RecordSuccess clears the failure streak of the notification's channel
+func (s *NotificationEscalationService) RecordSuccess(ctx context.Context, notification *domain.Notification) error {
+	return s.statusRepo.ResetStreak(ctx, notification.CustomerID, notification.Channel)
+}
+
+// RecordFailure counts a failed notification towards its channel's failure streak.
+// Only notifications that exhausted their retries count. When the streak reaches
+// the configured threshold the channel is paused and branch staff are alerted.
+func (s *NotificationEscalationService) RecordFailure(ctx context.Context, notification *domain.Notification) (*domain.NotificationChannelStatus, error) {
+	if !notification.RetriesExhausted() {
+		return nil, nil
+	}
+
+	threshold := getSettingInt(ctx, s.settingRepo, SettingNotificationFailureThreshold, notification.BranchID, DefaultNotificationFailureThreshold)
+	if threshold <= 0 {
+		return nil, nil
+	}
+	windowDays := getSettingInt(ctx, s.settingRepo, SettingNotificationFailureWindowDays, notification.BranchID, DefaultNotificationFailureWindowDays)
+
+	now := time.Now()
+	status, err := s.statusRepo.RecordFailure(ctx, notification.CustomerID, notification.Channel, now.AddDate(0, 0, -windowDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record notification failure: %w", err)
+	}
+	if status.IsPaused() || status.FailureStreak < threshold {
+		return status, nil
+	}
+
+	reason := fmt.Sprintf("%d notifications failed after all retries within %d days", status.FailureStreak, windowDays)
+	if err := s.statusRepo.Pause(ctx, notification.CustomerID, notification.Channel, reason); err != nil {
+		return nil, fmt.Errorf("failed to pause notification channel: %w", err)
+	}
+	status.PausedAt = &now
+	status.PauseReason = reason
+
+	s.logger.Warn().
+		Int64("customer_id", notification.CustomerID).
+		Str("channel", notification.Channel).
+		Int("failure_streak", status.FailureStreak).
+		Msg("Paused notification channel after repeated failures")
+
+	if notification.BranchID != nil && s.notificationService != nil {
+		message := fmt.Sprintf(
+			"Las notificaciones por %s al cliente #%d fallaron %d veces. Verifique sus datos de contacto; los envíos automáticos por este canal quedan pausados hasta entonces.",
+			notification.Channel, notification.CustomerID, status.FailureStreak,
+		)
+		if err := s.notificationService.NotifyBranchUsers(ctx, *notification.BranchID, "Verificar Datos de Contacto", message, "warning"); err != nil {
+			s.logger.Error().Err(err).Int64("customer_id", notification.CustomerID).Msg("Failed to alert branch staff about paused channel")
+		}
+	}
+
+	return status, nil
+}
+
+// ListChannelStatuses retrieves the delivery status of a customer's channels
+func (s *NotificationEscalationService) ListChannelStatuses(ctx context.Context, customerID int64) ([]*domain.NotificationChannelStatus, error) {
+	return s.statusRepo.ListByCustomer(ctx, customerID)
+}
+
+// VerifyChannel marks a customer's contact details for a channel as verified,
+// resuming automatic sends and clearing the failure streak
+func (s *NotificationEscalationService) VerifyChannel(ctx context.Context, customerID int64, channel string) error {
+	return s.statusRepo.Resume(ctx, customerID, channel)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// notificationHistoryPageSize is the page size used to read a customer's
+// notifications for an export
+const notificationHistoryPageSize = 500
+
+// NotificationHistoryExport is a rendered export of the notifications sent to a
+// customer, kept as proof of what the customer was told and when
+type NotificationHistoryExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// ExportCustomerHistory renders every notification addressed to a customer from
+// from to to, both inclusive, as CSV in the order they were created. Each row
+// has the channel, the delivery status with its timestamps and the body exactly
+// as it was rendered for the customer.
+func (s *notificationService) ExportCustomerHistory(ctx context.Context, customerID int64, from, to domain.Date) (*NotificationHistoryExport, error) {
+	if _, err := s.customerRepo.GetByID(ctx, customerID); err != nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	dateFrom := from.String()
+	dateTo := to.String() + " 23:59:59"
+	filter := repository.NotificationFilter{
+		DateFrom: &dateFrom,
+		DateTo:   &dateTo,
+		Page:     1,
+		PageSize: notificationHistoryPageSize,
+	}
+
+	var notifications []*domain.Notification
+	for {
+		page, total, err := s.notificationRepo.ListByCustomer(ctx, customerID, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list notifications: %w", err)
+		}
+		notifications = append(notifications, page...)
+		if len(page) == 0 || int64(len(notifications)) >= total {
+			break
+		}
+		filter.Page++
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.Before(notifications[j].CreatedAt)
+	})
+
+	data, err := writeNotificationHistoryCSV(notifications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return &NotificationHistoryExport{
+		Filename:    fmt.Sprintf("notifications_customer_%d_%s_%s.csv", customerID, from.Time.Format("20060102"), to.Time.Format("20060102")),
+		ContentType: "text/csv",
+		Data:        data,
+	}, nil
+}
+
+// writeNotificationHistoryCSV writes one row per notification
+func writeNotificationHistoryCSV(notifications []*domain.Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{
+		"id", "created_at", "notification_type", "channel", "status", "subject", "body",
+		"reference_type", "reference_id", "scheduled_for", "sent_at", "delivered_at", "failed_at",
+		"failure_reason", "retry_count",
+	})
+	for _, n := range notifications {
+		w.Write([]string{
+			strconv.FormatInt(n.ID, 10),
+			n.CreatedAt.Format(time.RFC3339),
+			n.NotificationType,
+			n.Channel,
+			n.Status,
+			n.Subject,
+			n.Body,
+			n.ReferenceType,
+			formatOptionalID(n.ReferenceID),
+			formatOptionalTime(n.ScheduledFor),
+			formatOptionalTime(n.SentAt),
+			formatOptionalTime(n.DeliveredAt),
+			formatOptionalTime(n.FailedAt),
+			n.FailureReason,
+			strconv.Itoa(n.RetryCount),
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
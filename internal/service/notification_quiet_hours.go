@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingNotificationQuietHours is the daily window, in the branch's timezone,
+// during which customer notifications are held back, e.g.
+// {"start": "21:00", "end": "08:00"}. Set per branch or globally; without it
+// notifications go out at any time.
+const SettingNotificationQuietHours = "notification_quiet_hours"
+
+// SetQuietHours defers customer notifications created during the quiet hours
+// configured in settings to the end of the window
+func (s *notificationService) SetQuietHours(settingRepo repository.SettingRepository, branchRepo repository.BranchRepository) {
+	s.settingRepo = settingRepo
+	s.branchRepo = branchRepo
+}
+
+// quietHoursSchedule returns when a notification meant for scheduledFor, or
+// for now when nil, may be sent: unchanged outside the quiet hours of its
+// branch, the end of the window inside them
+func (s *notificationService) quietHoursSchedule(ctx context.Context, branchID *int64, scheduledFor *time.Time) *time.Time {
+	var quietHours domain.NotificationQuietHours
+	if !getSettingJSON(ctx, s.settingRepo, SettingNotificationQuietHours, branchID, &quietHours) || !quietHours.IsValid() {
+		return scheduledFor
+	}
+
+	sendAt := s.now()
+	if scheduledFor != nil {
+		sendAt = *scheduledFor
+	}
+	allowed := quietHours.NextAllowed(sendAt.In(s.branchLocation(ctx, branchID)))
+	if allowed.Equal(sendAt) {
+		return scheduledFor
+	}
+	return &allowed
+}
+
+// branchLocation returns the timezone of a branch, the server's when the
+// branch or its timezone is unknown
+func (s *notificationService) branchLocation(ctx context.Context, branchID *int64) *time.Location {
+	if branchID == nil || s.branchRepo == nil {
+		return time.Local
+	}
+	branch, err := s.branchRepo.GetByID(ctx, *branchID)
+	if err != nil || branch == nil || branch.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(branch.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingInternalNotificationRoutes holds the routing rules of internal events
+const SettingInternalNotificationRoutes = "internal_notification_routes"
+
+// InternalEvent is something staff should hear about. Title, Message and Type
+// become the internal notification sent to each routed user.
+type InternalEvent struct {
+	Event         string
+	BranchID      int64
+	Amount        float64
+	Title         string
+	Message       string
+	Type          string // info, warning, error, success
+	ReferenceType string
+	ReferenceID   *int64
+	ActionURL     string
+	// UserIDs are notified besides the routed users, such as the cashier of a
+	// session left open
+	UserIDs []int64
+}
+
+// NotificationRouter turns internal events into internal notifications for the
+// users picked by the configured routing rules
+type NotificationRouter struct {
+	settingRepo              repository.SettingRepository
+	roleRepo                 repository.RoleRepository
+	userRepo                 repository.UserRepository
+	internalNotificationRepo repository.InternalNotificationRepository
+	preferenceRepo           repository.UserNotificationPreferenceRepository
+	logger                   zerolog.Logger
+}
+
+// NewNotificationRouter creates a new NotificationRouter
+func NewNotificationRouter(
+	settingRepo repository.SettingRepository,
+	roleRepo repository.RoleRepository,
+	userRepo repository.UserRepository,
+	internalNotificationRepo repository.InternalNotificationRepository,
+	log zerolog.Logger,
+) *NotificationRouter {
+	return &NotificationRouter{
+		settingRepo:              settingRepo,
+		roleRepo:                 roleRepo,
+		userRepo:                 userRepo,
+		internalNotificationRepo: internalNotificationRepo,
+		logger:                   log.With().Str("service", "notification_router").Logger(),
+	}
+}
+
+// SetDigests holds back the notifications of users in digest mode for their
+// next digest instead of delivering them right away
+func (r *NotificationRouter) SetDigests(preferenceRepo repository.UserNotificationPreferenceRepository) {
+	r.preferenceRepo = preferenceRepo
+}
+
+// Routes returns the routing rules, the defaults when none are configured
+func (r *NotificationRouter) Routes(ctx context.Context) []domain.NotificationRoute {
+	var routes []domain.NotificationRoute
+	if !getSettingJSON(ctx, r.settingRepo, SettingInternalNotificationRoutes, nil, &routes) {
+		return domain.DefaultNotificationRoutes()
+	}
+	return routes
+}
+
+// SetRoutes validates and stores the routing rules, replacing the current ones
+func (r *NotificationRouter) SetRoutes(ctx context.Context, routes []domain.NotificationRoute) ([]domain.NotificationRoute, error) {
+	for _, route := range routes {
+		if err := route.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		for _, roleName := range route.Roles {
+			if role, err := r.roleRepo.GetByName(ctx, roleName); err != nil || role == nil {
+				return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidInput, roleName)
+			}
+		}
+	}
+
+	err := r.settingRepo.Set(ctx, &domain.Setting{
+		Key:         SettingInternalNotificationRoutes,
+		Value:       routes,
+		Description: "Destinatarios de las notificaciones internas por evento",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification routes: %w", err)
+	}
+	return routes, nil
+}
+
+// Emit notifies the users the event is routed to and returns how many were
+// notified. Callers have already completed their operation, so they usually
+// log a failure rather than return it.
+func (r *NotificationRouter) Emit(ctx context.Context, event InternalEvent) (int, error) {
+	recipients := make(map[int64]bool)
+	var notifications []*domain.InternalNotification
+	notify := func(userID int64) {
+		if recipients[userID] {
+			return
+		}
+		recipients[userID] = true
+
+		var branchID *int64
+		if event.BranchID > 0 {
+			branchID = &event.BranchID
+		}
+		notifications = append(notifications, &domain.InternalNotification{
+			UserID:        userID,
+			BranchID:      branchID,
+			Title:         event.Title,
+			Message:       event.Message,
+			Type:          event.Type,
+			ReferenceType: event.ReferenceType,
+			ReferenceID:   event.ReferenceID,
+			ActionURL:     event.ActionURL,
+		})
+	}
+
+	for _, userID := range event.UserIDs {
+		notify(userID)
+	}
+
+	for _, route := range r.Routes(ctx) {
+		if !route.Matches(event.Event, event.Amount) {
+			continue
+		}
+
+		users, err := r.routeUsers(ctx, route, event.BranchID)
+		if err != nil {
+			return 0, err
+		}
+		for _, user := range users {
+			notify(user.ID)
+		}
+	}
+
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+	holdForDigests(ctx, r.preferenceRepo, r.logger, notifications)
+	if err := r.internalNotificationRepo.CreateBulk(ctx, notifications); err != nil {
+		return 0, fmt.Errorf("failed to create internal notifications: %w", err)
+	}
+	return len(notifications), nil
+}
+
+// emit is Emit for callers that cannot act on a failure: it only logs it
+func (r *NotificationRouter) emit(ctx context.Context, event InternalEvent) {
+	if r == nil {
+		return
+	}
+	if _, err := r.Emit(ctx, event); err != nil {
+		r.logger.Error().Err(err).Str("event", event.Event).Int64("branch_id", event.BranchID).Msg("Failed to route internal event")
+	}
+}
+
+// routeUsers returns the active users holding the route's roles
+func (r *NotificationRouter) routeUsers(ctx context.Context, route domain.NotificationRoute, branchID int64) ([]domain.User, error) {
+	active := true
+	var users []domain.User
+	for _, roleName := range route.Roles {
+		role, err := r.roleRepo.GetByName(ctx, roleName)
+		if err != nil || role == nil {
+			r.logger.Warn().Str("role", roleName).Str("event", route.Event).Msg("Notification route names an unknown role")
+			continue
+		}
+
+		params := repository.UserListParams{RoleID: &role.ID, IsActive: &active}
+		if route.BranchOnly {
+			params.BranchID = &branchID
+		}
+		for page := 1; ; page++ {
+			params.PaginationParams = repository.PaginationParams{Page: page, PerPage: routeUserPageSize}
+			result, err := r.userRepo.List(ctx, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list users with role %s: %w", roleName, err)
+			}
+			users = append(users, result.Data...)
+			if page >= result.TotalPages {
+				break
+			}
+		}
+	}
+	return users, nil
+}
+
+// routeUserPageSize is how many users are read at a time when routing an event
+const routeUserPageSize = 100
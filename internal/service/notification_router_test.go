@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+type notificationRouterMocks struct {
+	settingRepo  *mocks.MockSettingRepository
+	roleRepo     *mocks.MockRoleRepository
+	userRepo     *mocks.MockUserRepository
+	internalRepo *mocks.MockInternalNotificationRepository
+}
+
+func setupNotificationRouter(routes []domain.NotificationRoute) (*NotificationRouter, notificationRouterMocks) {
+	m := notificationRouterMocks{
+		settingRepo:  new(mocks.MockSettingRepository),
+		roleRepo:     new(mocks.MockRoleRepository),
+		userRepo:     new(mocks.MockUserRepository),
+		internalRepo: new(mocks.MockInternalNotificationRepository),
+	}
+	m.settingRepo.On("Get", mock.Anything, SettingInternalNotificationRoutes, mock.Anything).
+		Return(&domain.Setting{Key: SettingInternalNotificationRoutes, Value: routes}, nil).Maybe()
+	router := NewNotificationRouter(m.settingRepo, m.roleRepo, m.userRepo, m.internalRepo, zerolog.Nop())
+	return router, m
+}
+
+func TestNotificationRouter_Emit_HighValueLoanNotifiesBranchRole(t *testing.T) {
+	router, m := setupNotificationRouter([]domain.NotificationRoute{
+		{Event: domain.InternalEventHighValueLoan, Roles: []string{domain.RoleManager}, BranchOnly: true, MinAmount: 5000},
+		{Event: domain.InternalEventCashDifference, Roles: []string{domain.RoleAdmin}},
+	})
+	ctx := context.Background()
+	branchID, roleID, active := int64(2), int64(3), true
+	loanID := int64(40)
+
+	m.roleRepo.On("GetByName", ctx, domain.RoleManager).Return(&domain.Role{ID: roleID, Name: domain.RoleManager}, nil)
+	m.userRepo.On("List", ctx, repository.UserListParams{RoleID: &roleID, IsActive: &active, BranchID: &branchID, PaginationParams: repository.PaginationParams{Page: 1, PerPage: routeUserPageSize}}).
+		Return(&repository.PaginatedResult[domain.User]{Data: []domain.User{{ID: 11}, {ID: 12}}}, nil)
+	var created []*domain.InternalNotification
+	m.internalRepo.On("CreateBulk", ctx, mock.AnythingOfType("[]*domain.InternalNotification")).Run(func(args mock.Arguments) {
+		created = args.Get(1).([]*domain.InternalNotification)
+	}).Return(nil)
+
+	notified, err := router.Emit(ctx, InternalEvent{
+		Event:         domain.InternalEventHighValueLoan,
+		BranchID:      branchID,
+		Amount:        8000,
+		Title:         "Préstamo de Alto Valor",
+		Message:       "Se otorgó el préstamo LN-000040 por Q8000.00",
+		Type:          "info",
+		ReferenceType: "loan",
+		ReferenceID:   &loanID,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, notified)
+	require.Len(t, created, 2)
+	assert.Equal(t, int64(11), created[0].UserID)
+	assert.Equal(t, int64(12), created[1].UserID)
+	for _, notification := range created {
+		assert.Equal(t, branchID, *notification.BranchID)
+		assert.Equal(t, "loan", notification.ReferenceType)
+	}
+	m.roleRepo.AssertNotCalled(t, "GetByName", ctx, domain.RoleAdmin)
+}
+
+func TestNotificationRouter_Emit_NotifiesUsersOnEveryPage(t *testing.T) {
+	router, m := setupNotificationRouter([]domain.NotificationRoute{
+		{Event: domain.InternalEventCashDifference, Roles: []string{domain.RoleAdmin}},
+	})
+	ctx := context.Background()
+	roleID, active := int64(1), true
+
+	m.roleRepo.On("GetByName", ctx, domain.RoleAdmin).Return(&domain.Role{ID: roleID, Name: domain.RoleAdmin}, nil)
+	for page := 1; page <= 2; page++ {
+		users := make([]domain.User, 0, routeUserPageSize)
+		for i := 0; i < routeUserPageSize; i++ {
+			users = append(users, domain.User{ID: int64(page*1000 + i)})
+		}
+		m.userRepo.On("List", ctx, repository.UserListParams{RoleID: &roleID, IsActive: &active, PaginationParams: repository.PaginationParams{Page: page, PerPage: routeUserPageSize}}).
+			Return(&repository.PaginatedResult[domain.User]{Data: users, TotalPages: 2}, nil)
+	}
+	m.internalRepo.On("CreateBulk", ctx, mock.AnythingOfType("[]*domain.InternalNotification")).Return(nil)
+
+	notified, err := router.Emit(ctx, InternalEvent{Event: domain.InternalEventCashDifference, BranchID: 2, Title: "Diferencia de caja", Type: "warning"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2*routeUserPageSize, notified)
+	m.userRepo.AssertNumberOfCalls(t, "List", 2)
+}
+
+func TestNotificationRouter_Emit_UnderMinAmountNotRouted(t *testing.T) {
+	router, m := setupNotificationRouter([]domain.NotificationRoute{
+		{Event: domain.InternalEventHighValueLoan, Roles: []string{domain.RoleManager}, BranchOnly: true, MinAmount: 5000},
+	})
+
+	notified, err := router.Emit(context.Background(), InternalEvent{Event: domain.InternalEventHighValueLoan, BranchID: 2, Amount: 4999.99})
+
+	require.NoError(t, err)
+	assert.Zero(t, notified)
+	m.internalRepo.AssertNotCalled(t, "CreateBulk", mock.Anything, mock.Anything)
+}
+
+func TestNotificationRouter_SetRoutes_RejectsUnknownEvent(t *testing.T) {
+	router, m := setupNotificationRouter(nil)
+
+	_, err := router.SetRoutes(context.Background(), []domain.NotificationRoute{{Event: "new_customer", Roles: []string{domain.RoleManager}}})
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	m.settingRepo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+}
+
+func TestNotificationRouter_DigestUserGetsOneSummary(t *testing.T) {
+	router, m := setupNotificationRouter([]domain.NotificationRoute{})
+	prefRepo := new(mocks.MockUserNotificationPreferenceRepository)
+	router.SetDigests(prefRepo)
+	digests := NewNotificationDigestService(m.internalRepo, prefRepo, zerolog.Nop())
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	lastDigest := now.Add(-5 * time.Hour)
+	digestUser, realTimeUser := int64(11), int64(12)
+
+	prefRepo.On("ListDigestUserIDs", ctx, []int64{digestUser, realTimeUser}).Return([]int64{digestUser}, nil)
+	var created []*domain.InternalNotification
+	m.internalRepo.On("CreateBulk", ctx, mock.AnythingOfType("[]*domain.InternalNotification")).Run(func(args mock.Arguments) {
+		for _, notification := range args.Get(1).([]*domain.InternalNotification) {
+			notification.ID = int64(len(created) + 1)
+			created = append(created, notification)
+		}
+	}).Return(nil)
+
+	events := []InternalEvent{
+		{Event: domain.InternalEventCashDifference, Title: "Diferencia de caja", Message: "Caja 1 cerró con Q-20.00", Type: "warning"},
+		{Event: domain.InternalEventHighValueLoan, Title: "Préstamo de Alto Valor", Message: "Se otorgó el préstamo LN-000040", Type: "info"},
+		{Event: domain.InternalEventHighValueLoan, Title: "Préstamo de Alto Valor", Message: "Se otorgó el préstamo LN-000041", Type: "info"},
+	}
+	for _, event := range events {
+		event.UserIDs = []int64{digestUser, realTimeUser}
+		_, err := router.Emit(ctx, event)
+		require.NoError(t, err)
+	}
+
+	// The real-time user got each notification as it happened, the digest
+	// user's are held back
+	var held []*domain.InternalNotification
+	var delivered []int64
+	for _, notification := range created {
+		if notification.DigestPending {
+			held = append(held, notification)
+		} else {
+			delivered = append(delivered, notification.UserID)
+		}
+	}
+	assert.Equal(t, []int64{realTimeUser, realTimeUser, realTimeUser}, delivered)
+	require.Len(t, held, 3)
+	for _, notification := range held {
+		assert.Equal(t, digestUser, notification.UserID)
+	}
+
+	m.internalRepo.On("ListDigestPending", ctx, digestUser).Return(held, nil)
+	prefRepo.On("ListDigestUsers", ctx).Return([]*domain.UserNotificationPreference{
+		{UserID: digestUser, DigestMode: true, DigestIntervalHours: 4, LastDigestAt: &lastDigest},
+	}, nil)
+	var summary *domain.InternalNotification
+	m.internalRepo.On("CreateDigest", ctx, mock.AnythingOfType("*domain.InternalNotification"), []int64{held[0].ID, held[1].ID, held[2].ID}).Run(func(args mock.Arguments) {
+		summary = args.Get(1).(*domain.InternalNotification)
+	}).Return(nil).Once()
+	prefRepo.On("MarkDigestSent", ctx, digestUser, now).Return(nil)
+
+	sent, err := digests.SendDue(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.NotNil(t, summary)
+	assert.Equal(t, digestUser, summary.UserID)
+	assert.Equal(t, "Resumen de notificaciones (3)", summary.Title)
+	assert.Equal(t, "warning", summary.Type)
+	assert.Contains(t, summary.Message, "Caja 1 cerró con Q-20.00")
+	assert.Contains(t, summary.Message, "LN-000040")
+	assert.Contains(t, summary.Message, "LN-000041")
+	m.internalRepo.AssertExpectations(t)
+	prefRepo.AssertExpectations(t)
+}
+
+func TestNotificationDigestService_SendDue_SkipsDigestNotDue(t *testing.T) {
+	internalRepo := new(mocks.MockInternalNotificationRepository)
+	prefRepo := new(mocks.MockUserNotificationPreferenceRepository)
+	digests := NewNotificationDigestService(internalRepo, prefRepo, zerolog.Nop())
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	lastDigest := now.Add(-time.Hour)
+	prefRepo.On("ListDigestUsers", mock.Anything).Return([]*domain.UserNotificationPreference{
+		{UserID: 11, DigestMode: true, DigestIntervalHours: 4, LastDigestAt: &lastDigest},
+	}, nil)
+
+	sent, err := digests.SendDue(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	internalRepo.AssertNotCalled(t, "ListDigestPending", mock.Anything, mock.Anything)
+}
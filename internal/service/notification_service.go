@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/rs/zerolog"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 )
@@ -19,6 +21,40 @@ var (
 	ErrNotificationCustomerNotFound = errors.New("customer not found for notification")
 )
 
+// TemplateVariableError is returned when a template uses placeholders its
+// notification type does not provide, typically typos
+type TemplateVariableError struct {
+	NotificationType string   `json:"notification_type"`
+	Unknown          []string `json:"unknown_placeholders"`
+	Allowed          []string `json:"allowed_placeholders"`
+}
+
+func (e *TemplateVariableError) Error() string {
+	return fmt.Sprintf("unknown template placeholders for %s: %s", e.NotificationType, strings.Join(e.Unknown, ", "))
+}
+
+// Is makes a TemplateVariableError match ErrInvalidInput
+func (e *TemplateVariableError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// validateTemplateVariables checks the placeholders of a template's subject
+// and body against the variables of its notification type
+func validateTemplateVariables(template *domain.NotificationTemplate) error {
+	if unknown := template.UnknownPlaceholders(); len(unknown) > 0 {
+		return &TemplateVariableError{
+			NotificationType: template.NotificationType,
+			Unknown:          unknown,
+			Allowed:          domain.TemplateVariables(template.NotificationType),
+		}
+	}
+	return nil
+}
+
+// DefaultNotificationRetryBackoffBase is how long a failed notification waits
+// before its first retry; every later retry waits twice as long as the last
+const DefaultNotificationRetryBackoffBase = time.Minute
+
 // NotificationService defines the interface for notification operations
 type NotificationService interface {
 	// Template operations
@@ -44,6 +80,9 @@ type NotificationService interface {
 	MarkAsDelivered(ctx context.Context, id int64) error
 	MarkAsFailed(ctx context.Context, id int64, reason string) error
 	RetryNotification(ctx context.Context, id int64) error
+	SetRetryBackoffBase(base time.Duration)
+	SetQuietHours(settingRepo repository.SettingRepository, branchRepo repository.BranchRepository)
+	SetDigests(preferenceRepo repository.UserNotificationPreferenceRepository, logger zerolog.Logger)
 
 	// Customer preferences
 	GetCustomerPreferences(ctx context.Context, customerID int64) ([]*domain.CustomerNotificationPreference, error)
@@ -68,6 +107,9 @@ type NotificationService interface {
 	// Stats
 	GetStatsByCustomer(ctx context.Context, customerID int64) (*repository.NotificationStats, error)
 	GetStatsByBranch(ctx context.Context, branchID int64, dateFrom, dateTo time.Time) (*repository.NotificationStats, error)
+
+	// Compliance
+	ExportCustomerHistory(ctx context.Context, customerID int64, from, to domain.Date) (*NotificationHistoryExport, error)
 }
 
 type notificationService struct {
@@ -77,6 +119,12 @@ type notificationService struct {
 	internalNotificationRepo repository.InternalNotificationRepository
 	customerRepo             repository.CustomerRepository
 	userRepo                 repository.UserRepository
+	settingRepo              repository.SettingRepository
+	branchRepo               repository.BranchRepository
+	digestPreferenceRepo     repository.UserNotificationPreferenceRepository
+	logger                   zerolog.Logger
+	retryBackoffBase         time.Duration
+	now                      func() time.Time
 }
 
 // NewNotificationService creates a new notification service
@@ -95,6 +143,8 @@ func NewNotificationService(
 		internalNotificationRepo: internalNotificationRepo,
 		customerRepo:             customerRepo,
 		userRepo:                 userRepo,
+		retryBackoffBase:         DefaultNotificationRetryBackoffBase,
+		now:                      time.Now,
 	}
 }
 
@@ -169,6 +219,9 @@ func (s *notificationService) CreateTemplate(ctx context.Context, req CreateNoti
 		BodyTemplate:     req.BodyTemplate,
 		IsActive:         true,
 	}
+	if err := validateTemplateVariables(template); err != nil {
+		return nil, err
+	}
 
 	if err := s.templateRepo.Create(ctx, template); err != nil {
 		return nil, err
@@ -209,6 +262,9 @@ func (s *notificationService) UpdateTemplate(ctx context.Context, id int64, req
 	if req.IsActive != nil {
 		template.IsActive = *req.IsActive
 	}
+	if err := validateTemplateVariables(template); err != nil {
+		return nil, err
+	}
 
 	if err := s.templateRepo.Update(ctx, template); err != nil {
 		return nil, err
@@ -246,7 +302,7 @@ func (s *notificationService) Create(ctx context.Context, req CreateNotification
 		ReferenceType:    req.ReferenceType,
 		ReferenceID:      req.ReferenceID,
 		Status:           domain.NotificationStatusPending,
-		ScheduledFor:     req.ScheduledFor,
+		ScheduledFor:     s.quietHoursSchedule(ctx, req.BranchID, req.ScheduledFor),
 	}
 
 	if err := s.notificationRepo.Create(ctx, notification); err != nil {
@@ -289,7 +345,7 @@ func (s *notificationService) CreateFromTemplate(ctx context.Context, req Create
 		ReferenceType:    req.ReferenceType,
 		ReferenceID:      req.ReferenceID,
 		Status:           domain.NotificationStatusPending,
-		ScheduledFor:     req.ScheduledFor,
+		ScheduledFor:     s.quietHoursSchedule(ctx, req.BranchID, req.ScheduledFor),
 	}
 
 	if err := s.notificationRepo.Create(ctx, notification); err != nil {
@@ -304,12 +360,7 @@ func (s *notificationService) renderTemplate(tmpl string, data map[string]string
 		return tmpl
 	}
 
-	result := tmpl
-	for key, value := range data {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-	return result
+	return domain.RenderTemplate(tmpl, data)
 }
 
 func (s *notificationService) renderGoTemplate(tmpl string, data map[string]interface{}) (string, error) {
@@ -375,7 +426,7 @@ func (s *notificationService) GetScheduledNotifications(ctx context.Context, bef
 }
 
 func (s *notificationService) GetFailedNotifications(ctx context.Context, limit int) ([]*domain.Notification, error) {
-	return s.notificationRepo.ListFailed(ctx, 3, limit) // Max 3 retries
+	return s.notificationRepo.ListFailed(ctx, domain.NotificationMaxRetries, s.retryBackoffBase, limit)
 }
 
 func (s *notificationService) MarkAsSent(ctx context.Context, id int64) error {
@@ -402,7 +453,23 @@ func (s *notificationService) RetryNotification(ctx context.Context, id int64) e
 		return errors.New("notification cannot be retried")
 	}
 
-	return s.notificationRepo.IncrementRetry(ctx, id)
+	// Back off exponentially so a provider that is down is not hammered
+	return s.notificationRepo.IncrementRetry(ctx, id, notification.NextRetryAt(s.retryBackoffBase, s.now()))
+}
+
+// SetRetryBackoffBase sets how long a failed notification waits before its
+// first retry
+func (s *notificationService) SetRetryBackoffBase(base time.Duration) {
+	if base > 0 {
+		s.retryBackoffBase = base
+	}
+}
+
+// SetDigests holds back the internal notifications of users in digest mode
+// for their next digest instead of delivering them right away
+func (s *notificationService) SetDigests(preferenceRepo repository.UserNotificationPreferenceRepository, logger zerolog.Logger) {
+	s.digestPreferenceRepo = preferenceRepo
+	s.logger = logger.With().Str("service", "notification").Logger()
 }
 
 // Customer preferences
@@ -430,6 +497,7 @@ func (s *notificationService) CreateInternalNotification(ctx context.Context, re
 		ReferenceID:   req.ReferenceID,
 		ActionURL:     req.ActionURL,
 	}
+	holdForDigests(ctx, s.digestPreferenceRepo, s.logger, []*domain.InternalNotification{notification})
 
 	if err := s.internalNotificationRepo.Create(ctx, notification); err != nil {
 		return nil, err
@@ -487,6 +555,7 @@ func (s *notificationService) NotifyBranchUsers(ctx context.Context, branchID in
 			Type:     notificationType,
 		})
 	}
+	holdForDigests(ctx, s.digestPreferenceRepo, s.logger, notifications)
 
 	return s.internalNotificationRepo.CreateBulk(ctx, notifications)
 }
@@ -527,6 +596,7 @@ func (s *notificationService) SendToCustomer(ctx context.Context, req SendNotifi
 		ReferenceType:    refType,
 		ReferenceID:      req.ReferenceID,
 		Status:           domain.NotificationStatusPending,
+		ScheduledFor:     s.quietHoursSchedule(ctx, &customer.BranchID, nil),
 	}
 
 	if err := s.notificationRepo.Create(ctx, notification); err != nil {
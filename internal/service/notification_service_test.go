@@ -3,11 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
@@ -67,6 +70,71 @@ func TestNotificationService_CreateTemplate_Success(t *testing.T) {
 	templateRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_CreateTemplate_UnknownPlaceholders(t *testing.T) {
+	service, _, templateRepo, _, _, _, _ := setupNotificationService()
+	ctx := context.Background()
+
+	req := CreateNotificationTemplateRequest{
+		NotificationType: domain.NotificationTypeLoanDueReminder,
+		Channel:          domain.NotificationChannelEmail,
+		Name:             "Recordatorio",
+		Subject:          "Préstamo {{ loan_numbr }}",
+		BodyTemplate:     "Estimado(a) {{customer_naem}}, su préstamo #{{loan_number}} vence el {{due_date}}. Monto: {{currency}}{{amount}} {{customer_naem}}",
+	}
+
+	result, err := service.CreateTemplate(ctx, req)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	var varErr *TemplateVariableError
+	require.ErrorAs(t, err, &varErr)
+	assert.Equal(t, []string{"loan_numbr", "customer_naem"}, varErr.Unknown)
+	assert.Contains(t, varErr.Allowed, "customer_name")
+	assert.Contains(t, varErr.Allowed, "loan_number")
+	templateRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_CreateTemplate_AllowedPlaceholders(t *testing.T) {
+	service, _, templateRepo, _, _, _, _ := setupNotificationService()
+	ctx := context.Background()
+
+	templateRepo.On("Create", ctx, mock.AnythingOfType("*domain.NotificationTemplate")).Return(nil)
+
+	result, err := service.CreateTemplate(ctx, CreateNotificationTemplateRequest{
+		NotificationType: domain.NotificationTypeLoanDueReminder,
+		Channel:          domain.NotificationChannelEmail,
+		Name:             "Recordatorio",
+		Subject:          "Su préstamo #{{loan_number}} vence pronto",
+		BodyTemplate:     "Estimado(a) {{customer_name}}, su préstamo vence el {{due_date}}. Monto: {{currency}}{{amount}}",
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	templateRepo.AssertExpectations(t)
+}
+
+func TestNotificationService_UpdateTemplate_UnknownPlaceholders(t *testing.T) {
+	service, _, templateRepo, _, _, _, _ := setupNotificationService()
+	ctx := context.Background()
+
+	template := &domain.NotificationTemplate{
+		ID:               1,
+		NotificationType: domain.NotificationTypePaymentReceived,
+		BodyTemplate:     "Recibimos su pago de {{currency}}{{amount}}",
+		IsActive:         true,
+	}
+	templateRepo.On("GetByID", ctx, int64(1)).Return(template, nil)
+
+	_, err := service.UpdateTemplate(ctx, 1, UpdateNotificationTemplateRequest{
+		BodyTemplate: "Recibimos su pago de {{currency}}{{amount}} para el préstamo {{loan_number}}, vence el {{due_date}}",
+	})
+
+	var varErr *TemplateVariableError
+	require.ErrorAs(t, err, &varErr)
+	assert.Equal(t, []string{"due_date"}, varErr.Unknown)
+	templateRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 func TestNotificationService_GetTemplateByID_Success(t *testing.T) {
 	service, _, templateRepo, _, _, _, _ := setupNotificationService()
 	ctx := context.Background()
@@ -187,6 +255,85 @@ func TestNotificationService_Create_Success(t *testing.T) {
 	preferenceRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_Create_QuietHours(t *testing.T) {
+	guatemala, err := time.LoadLocation("America/Guatemala")
+	require.NoError(t, err)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, guatemala)
+	}
+
+	tests := []struct {
+		name       string
+		quietHours domain.NotificationQuietHours
+		now        time.Time
+		want       time.Time // Zero when sent immediately
+	}{
+		{name: "before window", quietHours: domain.NotificationQuietHours{Start: "21:00", End: "08:00"}, now: at(10, 20, 59)},
+		{name: "after window", quietHours: domain.NotificationQuietHours{Start: "21:00", End: "08:00"}, now: at(10, 8, 0)},
+		{name: "evening, wraps past midnight", quietHours: domain.NotificationQuietHours{Start: "21:00", End: "08:00"}, now: at(10, 23, 30), want: at(11, 8, 0)},
+		{name: "early morning, wraps past midnight", quietHours: domain.NotificationQuietHours{Start: "21:00", End: "08:00"}, now: at(11, 3, 0), want: at(11, 8, 0)},
+		{name: "same-day window", quietHours: domain.NotificationQuietHours{Start: "13:00", End: "15:00"}, now: at(10, 14, 0), want: at(10, 15, 0)},
+		{name: "outside same-day window", quietHours: domain.NotificationQuietHours{Start: "13:00", End: "15:00"}, now: at(10, 23, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, notificationRepo, _, preferenceRepo, _, _, _ := setupNotificationService()
+			settingRepo := new(mocks.MockSettingRepository)
+			branchRepo := new(mocks.MockBranchRepository)
+			svc.SetQuietHours(settingRepo, branchRepo)
+			// The server clock runs in UTC; quiet hours follow the branch's timezone
+			svc.(*notificationService).now = func() time.Time { return tt.now.UTC() }
+			ctx := context.Background()
+			branchID := int64(2)
+
+			preferenceRepo.On("IsEnabled", ctx, int64(1), domain.NotificationTypeLoanDueReminder, domain.NotificationChannelSMS).Return(true, nil)
+			settingRepo.On("Get", ctx, SettingNotificationQuietHours, &branchID).Return(&domain.Setting{
+				Key:   SettingNotificationQuietHours,
+				Value: map[string]interface{}{"start": tt.quietHours.Start, "end": tt.quietHours.End},
+			}, nil)
+			branchRepo.On("GetByID", ctx, branchID).Return(&domain.Branch{ID: branchID, Timezone: "America/Guatemala"}, nil)
+			notificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Notification")).Return(nil)
+
+			result, err := svc.Create(ctx, CreateNotificationRequest{
+				CustomerID:       1,
+				BranchID:         &branchID,
+				NotificationType: domain.NotificationTypeLoanDueReminder,
+				Channel:          domain.NotificationChannelSMS,
+				Body:             "Su préstamo vence mañana.",
+			})
+
+			require.NoError(t, err)
+			if tt.want.IsZero() {
+				assert.Nil(t, result.ScheduledFor, "sent immediately outside quiet hours")
+				return
+			}
+			require.NotNil(t, result.ScheduledFor)
+			assert.True(t, tt.want.Equal(*result.ScheduledFor), "scheduled for %s, want %s", result.ScheduledFor, tt.want)
+		})
+	}
+}
+
+func TestNotificationService_Create_QuietHoursNotConfigured(t *testing.T) {
+	svc, notificationRepo, _, preferenceRepo, _, _, _ := setupNotificationService()
+	settingRepo := new(mocks.MockSettingRepository)
+	svc.SetQuietHours(settingRepo, nil)
+	ctx := context.Background()
+
+	preferenceRepo.On("IsEnabled", ctx, int64(1), domain.NotificationTypeLoanDueReminder, domain.NotificationChannelSMS).Return(true, nil)
+	settingRepo.On("Get", ctx, SettingNotificationQuietHours, (*int64)(nil)).Return(nil, errors.New("setting not found"))
+	notificationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Notification")).Return(nil)
+
+	result, err := svc.Create(ctx, CreateNotificationRequest{
+		CustomerID:       1,
+		NotificationType: domain.NotificationTypeLoanDueReminder,
+		Channel:          domain.NotificationChannelSMS,
+		Body:             "Su préstamo vence mañana.",
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, result.ScheduledFor)
+}
+
 func TestNotificationService_Create_ChannelDisabled(t *testing.T) {
 	service, _, _, preferenceRepo, _, _, _ := setupNotificationService()
 	ctx := context.Background()
@@ -208,6 +355,25 @@ func TestNotificationService_Create_ChannelDisabled(t *testing.T) {
 	preferenceRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_Create_WhatsAppDisabled(t *testing.T) {
+	service, notificationRepo, _, preferenceRepo, _, _, _ := setupNotificationService()
+	ctx := context.Background()
+
+	preferenceRepo.On("IsEnabled", ctx, int64(1), domain.NotificationTypeLoanOverdue, domain.NotificationChannelWhatsApp).Return(false, nil)
+
+	result, err := service.Create(ctx, CreateNotificationRequest{
+		CustomerID:       1,
+		NotificationType: domain.NotificationTypeLoanOverdue,
+		Channel:          domain.NotificationChannelWhatsApp,
+		Body:             "Su préstamo está vencido",
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "disabled")
+	notificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestNotificationService_CreateFromTemplate_Success(t *testing.T) {
 	service, notificationRepo, templateRepo, preferenceRepo, _, _, _ := setupNotificationService()
 	ctx := context.Background()
@@ -386,7 +552,7 @@ func TestNotificationService_RetryNotification_Success(t *testing.T) {
 	}
 
 	notificationRepo.On("GetByID", ctx, int64(1)).Return(notification, nil)
-	notificationRepo.On("IncrementRetry", ctx, int64(1)).Return(nil)
+	notificationRepo.On("IncrementRetry", ctx, int64(1), mock.AnythingOfType("time.Time")).Return(nil)
 
 	err := service.RetryNotification(ctx, 1)
 
@@ -394,6 +560,49 @@ func TestNotificationService_RetryNotification_Success(t *testing.T) {
 	notificationRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_RetryNotification_ExponentialBackoff(t *testing.T) {
+	failedAt := time.Date(2026, 9, 10, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		retryCount int
+		want       time.Time
+	}{
+		{retryCount: 0, want: failedAt.Add(5 * time.Minute)},
+		{retryCount: 1, want: failedAt.Add(10 * time.Minute)},
+		{retryCount: 2, want: failedAt.Add(20 * time.Minute)},
+		{retryCount: 3, want: failedAt.Add(40 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("retry %d", tt.retryCount), func(t *testing.T) {
+			svc, notificationRepo, _, _, _, _, _ := setupNotificationService()
+			svc.SetRetryBackoffBase(5 * time.Minute)
+			svc.(*notificationService).now = func() time.Time { return failedAt.Add(time.Minute) }
+			ctx := context.Background()
+
+			notification := &domain.Notification{
+				ID:         1,
+				Status:     domain.NotificationStatusFailed,
+				FailedAt:   &failedAt,
+				RetryCount: tt.retryCount,
+			}
+			notificationRepo.On("GetByID", ctx, int64(1)).Return(notification, nil)
+			if notification.CanRetry() {
+				notificationRepo.On("IncrementRetry", ctx, int64(1), tt.want).Return(nil)
+			}
+
+			err := svc.RetryNotification(ctx, 1)
+
+			assert.Equal(t, tt.want, notification.NextRetryAt(5*time.Minute, failedAt))
+			if notification.CanRetry() {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+			notificationRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestNotificationService_RetryNotification_MaxRetriesReached(t *testing.T) {
 	service, notificationRepo, _, _, _, _, _ := setupNotificationService()
 	ctx := context.Background()
@@ -692,7 +901,7 @@ func TestNotificationService_GetFailedNotifications_Success(t *testing.T) {
 		{ID: 1, Status: domain.NotificationStatusFailed, RetryCount: 1},
 	}
 
-	notificationRepo.On("ListFailed", ctx, 3, 10).Return(notifications, nil)
+	notificationRepo.On("ListFailed", ctx, domain.NotificationMaxRetries, DefaultNotificationRetryBackoffBase, 10).Return(notifications, nil)
 
 	result, err := service.GetFailedNotifications(ctx, 10)
 
@@ -795,6 +1004,32 @@ func TestNotificationService_NotifyBranchUsers_Success(t *testing.T) {
 	internalRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_NotifyBranchUsers_HoldsDigestUsers(t *testing.T) {
+	service, _, _, _, internalRepo, _, userRepo := setupNotificationService()
+	digestPrefRepo := new(mocks.MockUserNotificationPreferenceRepository)
+	service.SetDigests(digestPrefRepo, zerolog.Nop())
+	ctx := context.Background()
+
+	branchID := int64(1)
+	users := &repository.PaginatedResult[domain.User]{
+		Data:  []domain.User{{ID: 1}, {ID: 2}},
+		Total: 2,
+	}
+	userRepo.On("List", ctx, repository.UserListParams{BranchID: &branchID}).Return(users, nil)
+	digestPrefRepo.On("ListDigestUserIDs", ctx, []int64{1, 2}).Return([]int64{2}, nil)
+	var created []*domain.InternalNotification
+	internalRepo.On("CreateBulk", ctx, mock.AnythingOfType("[]*domain.InternalNotification")).Run(func(args mock.Arguments) {
+		created = args.Get(1).([]*domain.InternalNotification)
+	}).Return(nil)
+
+	err := service.NotifyBranchUsers(ctx, 1, "Alert", "Test message", "warning")
+
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.False(t, created[0].DigestPending)
+	assert.True(t, created[1].DigestPending)
+}
+
 func TestNotificationService_NotifyBranchUsers_UserListError(t *testing.T) {
 	service, _, _, _, _, _, userRepo := setupNotificationService()
 	ctx := context.Background()
@@ -923,6 +1158,26 @@ func TestNotificationService_SendToCustomer_ChannelDisabled(t *testing.T) {
 	preferenceRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_SendToCustomer_WhatsAppDisabled(t *testing.T) {
+	service, notificationRepo, _, preferenceRepo, _, customerRepo, _ := setupNotificationService()
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, BranchID: 1}, nil)
+	preferenceRepo.On("IsEnabled", ctx, int64(1), domain.NotificationTypePromotion, domain.NotificationChannelWhatsApp).Return(false, nil)
+
+	result, err := service.SendToCustomer(ctx, SendNotificationRequest{
+		CustomerID: 1,
+		Type:       domain.NotificationTypePromotion,
+		Title:      "Promoción",
+		Message:    "20% de descuento en joyería",
+		Channel:    domain.NotificationChannelWhatsApp,
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	notificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestNotificationService_SendToCustomer_WithReferenceType(t *testing.T) {
 	service, notificationRepo, _, preferenceRepo, _, customerRepo, _ := setupNotificationService()
 	ctx := context.Background()
@@ -954,3 +1209,55 @@ func TestNotificationService_SendToCustomer_WithReferenceType(t *testing.T) {
 	preferenceRepo.AssertExpectations(t)
 	notificationRepo.AssertExpectations(t)
 }
+
+func TestNotificationService_ExportCustomerHistory(t *testing.T) {
+	service, notificationRepo, _, _, _, customerRepo, _ := setupNotificationService()
+	ctx := context.Background()
+
+	sentAt := time.Date(2026, 9, 3, 10, 0, 5, 0, time.UTC)
+	deliveredAt := time.Date(2026, 9, 3, 10, 0, 40, 0, time.UTC)
+	failedAt := time.Date(2026, 9, 10, 9, 0, 2, 0, time.UTC)
+	loanID := int64(12)
+	notifications := []*domain.Notification{
+		{
+			ID: 8, CustomerID: 5, NotificationType: domain.NotificationTypeLoanOverdue, Channel: domain.NotificationChannelWhatsApp,
+			Body: "Su préstamo LN-000012 está vencido", Status: domain.NotificationStatusFailed,
+			FailedAt: &failedAt, FailureReason: "number not on whatsapp", RetryCount: 3,
+			ReferenceType: "loan", ReferenceID: &loanID, CreatedAt: time.Date(2026, 9, 10, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 3, CustomerID: 5, NotificationType: domain.NotificationTypeLoanDueReminder, Channel: domain.NotificationChannelSMS,
+			Body: "Su préstamo LN-000012 vence el 05/09/2026", Status: domain.NotificationStatusDelivered,
+			SentAt: &sentAt, DeliveredAt: &deliveredAt, ReferenceType: "loan", ReferenceID: &loanID,
+			CreatedAt: time.Date(2026, 9, 3, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	customerRepo.On("GetByID", ctx, int64(5)).Return(&domain.Customer{ID: 5}, nil)
+	notificationRepo.On("ListByCustomer", ctx, int64(5), mock.MatchedBy(func(filter repository.NotificationFilter) bool {
+		return *filter.DateFrom == "2026-09-01" && *filter.DateTo == "2026-09-30 23:59:59" && filter.Page == 1
+	})).Return(notifications, int64(2), nil)
+
+	export, err := service.ExportCustomerHistory(ctx, 5, domain.NewDate(2026, 9, 1), domain.NewDate(2026, 9, 30))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv", export.ContentType)
+	assert.Equal(t, "notifications_customer_5_20260901_20260930.csv", export.Filename)
+	assert.Equal(t, "id,created_at,notification_type,channel,status,subject,body,reference_type,reference_id,scheduled_for,sent_at,delivered_at,failed_at,failure_reason,retry_count\n"+
+		"3,2026-09-03T10:00:00Z,loan_due_reminder,sms,delivered,,Su préstamo LN-000012 vence el 05/09/2026,loan,12,,2026-09-03T10:00:05Z,2026-09-03T10:00:40Z,,,0\n"+
+		"8,2026-09-10T09:00:00Z,loan_overdue,whatsapp,failed,,Su préstamo LN-000012 está vencido,loan,12,,,,2026-09-10T09:00:02Z,number not on whatsapp,3\n",
+		string(export.Data))
+}
+
+func TestNotificationService_ExportCustomerHistory_CustomerNotFound(t *testing.T) {
+	service, notificationRepo, _, _, _, customerRepo, _ := setupNotificationService()
+	ctx := context.Background()
+
+	customerRepo.On("GetByID", ctx, int64(99)).Return(nil, errors.New("not found"))
+
+	export, err := service.ExportCustomerHistory(ctx, 99, domain.NewDate(2026, 9, 1), domain.NewDate(2026, 9, 30))
+
+	assert.ErrorIs(t, err, ErrCustomerNotFound)
+	assert.Nil(t, export)
+	notificationRepo.AssertNotCalled(t, "ListByCustomer", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SMSSender is an SMS provider. It returns the ID the provider gave the
+// message, empty when it gives none.
+type SMSSender interface {
+	SendSMS(ctx context.Context, phone, message string) (string, error)
+}
+
+// SMSNotificationSender delivers notifications as text messages to the
+// customer's phone
+type SMSNotificationSender struct {
+	provider     SMSSender
+	customerRepo repository.CustomerRepository
+}
+
+// NewSMSNotificationSender creates a new SMSNotificationSender
+func NewSMSNotificationSender(provider SMSSender, customerRepo repository.CustomerRepository) *SMSNotificationSender {
+	return &SMSNotificationSender{
+		provider:     provider,
+		customerRepo: customerRepo,
+	}
+}
+
+// Send sends a notification as a text message. Text messages have no subject,
+// so only the body is sent.
+func (s *SMSNotificationSender) Send(ctx context.Context, notification *domain.Notification) error {
+	customer, err := notificationCustomer(ctx, s.customerRepo, notification)
+	if err != nil {
+		return err
+	}
+
+	phone := strings.TrimSpace(customer.Phone)
+	if phone == "" {
+		return ErrCustomerHasNoPhone
+	}
+
+	messageID, err := s.provider.SendSMS(ctx, phone, notification.Body)
+	if err != nil {
+		return err
+	}
+	notification.ExternalMessageID = messageID
+	return nil
+}
+
+// SetSMSSender delivers SMS notifications through the given provider
+func (d *NotificationDispatcher) SetSMSSender(provider SMSSender, customerRepo repository.CustomerRepository) {
+	d.RegisterSender(domain.NotificationChannelSMS, NewSMSNotificationSender(provider, customerRepo))
+}
+
+// notificationCustomer returns the customer a notification is addressed to
+func notificationCustomer(ctx context.Context, customerRepo repository.CustomerRepository, notification *domain.Notification) (*domain.Customer, error) {
+	if notification.Customer != nil {
+		return notification.Customer, nil
+	}
+	customer, err := customerRepo.GetByID(ctx, notification.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, ErrNotificationCustomerNotFound
+	}
+	return customer, nil
+}
+
+// twilioAPIURL is the base URL of the Twilio REST API
+const twilioAPIURL = "https://api.twilio.com/2010-04-01"
+
+// twilioRequestTimeout bounds a single call to the Twilio API
+const twilioRequestTimeout = 15 * time.Second
+
+// TwilioClient sends messages through the Twilio Messages API
+type TwilioClient struct {
+	accountSID   string
+	authToken    string
+	smsFrom      string
+	whatsAppFrom string
+	baseURL      string
+	client       *http.Client
+}
+
+// NewTwilioClient creates a new TwilioClient sending SMS from the given number
+func NewTwilioClient(accountSID, authToken, smsFrom string) *TwilioClient {
+	return &TwilioClient{
+		accountSID: accountSID,
+		authToken:  authToken,
+		smsFrom:    smsFrom,
+		baseURL:    twilioAPIURL,
+		client:     &http.Client{Timeout: twilioRequestTimeout},
+	}
+}
+
+// WithWhatsAppFrom sets the WhatsApp sender number messages are sent from
+func (c *TwilioClient) WithWhatsAppFrom(from string) *TwilioClient {
+	c.whatsAppFrom = from
+	return c
+}
+
+// SendSMS implements SMSSender
+func (c *TwilioClient) SendSMS(ctx context.Context, phone, message string) (string, error) {
+	return c.sendMessage(ctx, c.smsFrom, phone, message)
+}
+
+// SendWhatsApp implements WhatsAppSender
+func (c *TwilioClient) SendWhatsApp(ctx context.Context, phone, message string) (string, error) {
+	return c.sendMessage(ctx, "whatsapp:"+c.whatsAppFrom, "whatsapp:"+phone, message)
+}
+
+// sendMessage creates a message and returns the SID Twilio gave it
+func (c *TwilioClient) sendMessage(ctx context.Context, from, to, body string) (string, error) {
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", c.baseURL, url.PathEscape(c.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		SID     string `json:"sid"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && resp.StatusCode < 300 {
+		return "", fmt.Errorf("failed to decode twilio response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("twilio responded with status %d: %s", resp.StatusCode, result.Message)
+	}
+	return result.SID, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// ErrCustomerHasNoPhone is returned when a WhatsApp notification is sent to a
+// customer without a phone number
+var ErrCustomerHasNoPhone = errors.New("customer has no phone number")
+
+// WhatsAppSender is a WhatsApp messaging provider. It returns the ID the
+// provider gave the message, empty when it gives none.
+type WhatsAppSender interface {
+	SendWhatsApp(ctx context.Context, phone, message string) (string, error)
+}
+
+// WhatsAppNotificationSender delivers notifications over WhatsApp to the
+// customer's phone
+type WhatsAppNotificationSender struct {
+	provider     WhatsAppSender
+	customerRepo repository.CustomerRepository
+}
+
+// NewWhatsAppNotificationSender creates a new WhatsAppNotificationSender
+func NewWhatsAppNotificationSender(provider WhatsAppSender, customerRepo repository.CustomerRepository) *WhatsAppNotificationSender {
+	return &WhatsAppNotificationSender{
+		provider:     provider,
+		customerRepo: customerRepo,
+	}
+}
+
+// Send sends a notification as a WhatsApp message. The subject, when there is
+// one, is sent as the first line since WhatsApp messages have no subject.
+func (s *WhatsAppNotificationSender) Send(ctx context.Context, notification *domain.Notification) error {
+	customer, err := notificationCustomer(ctx, s.customerRepo, notification)
+	if err != nil {
+		return err
+	}
+
+	phone := strings.TrimSpace(customer.Phone)
+	if phone == "" {
+		return ErrCustomerHasNoPhone
+	}
+
+	message := notification.Body
+	if subject := strings.TrimSpace(notification.Subject); subject != "" {
+		message = "*" + subject + "*\n" + message
+	}
+	messageID, err := s.provider.SendWhatsApp(ctx, phone, message)
+	if err != nil {
+		return err
+	}
+	notification.ExternalMessageID = messageID
+	return nil
+}
+
+// SetWhatsAppSender delivers WhatsApp notifications through the given provider
+func (d *NotificationDispatcher) SetWhatsAppSender(provider WhatsAppSender, customerRepo repository.CustomerRepository) {
+	d.RegisterSender(domain.NotificationChannelWhatsApp, NewWhatsAppNotificationSender(provider, customerRepo))
+}
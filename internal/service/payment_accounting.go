@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Settings naming the accounts a payment is booked to, so each chart of
+// accounts can map them to its own codes
+const (
+	SettingPaymentCashAccount           = "payment_cash_account"
+	SettingPaymentInterestIncomeAccount = "payment_interest_income_account"
+	SettingPaymentLateFeeIncomeAccount  = "payment_late_fee_income_account"
+	SettingPaymentPrincipalAccount      = "payment_principal_account"
+)
+
+// Default payment accounts of the seeded chart of accounts
+const (
+	DefaultPaymentCashAccount           = "1110" // Caja General
+	DefaultPaymentInterestIncomeAccount = "4100" // Ingresos por Intereses
+	DefaultPaymentLateFeeIncomeAccount  = "4200" // Ingresos por Mora
+	DefaultPaymentPrincipalAccount      = "1210" // Préstamos por Cobrar
+)
+
+// SetAccounting enables posting an accounting entry for every completed payment
+func (s *PaymentService) SetAccounting(accountRepo repository.AccountRepository, entryRepo repository.AccountingEntryRepository) {
+	s.accountRepo = accountRepo
+	s.entryRepo = entryRepo
+}
+
+// paymentEntryLines builds the lines booking a payment: the amount received
+// is debited to cash and credited to principal, interest income and late fee
+// income as the payment was split. Parts of zero are left out.
+func (s *PaymentService) paymentEntryLines(ctx context.Context, payment *domain.Payment, description string) ([]*domain.AccountingEntryLine, error) {
+	branchID := payment.BranchID
+	account := func(setting, fallback string) (*domain.Account, error) {
+		code := getSettingString(ctx, s.settingRepo, setting, &branchID, fallback)
+		acc, err := s.accountRepo.GetByCode(ctx, code)
+		if err != nil || acc == nil {
+			return nil, fmt.Errorf("account %s not found", code)
+		}
+		return acc, nil
+	}
+
+	var lines []*domain.AccountingEntryLine
+	total := 0.0
+	for _, part := range []struct {
+		amount   float64
+		setting  string
+		fallback string
+	}{
+		{payment.PrincipalAmount, SettingPaymentPrincipalAccount, DefaultPaymentPrincipalAccount},
+		{payment.InterestAmount, SettingPaymentInterestIncomeAccount, DefaultPaymentInterestIncomeAccount},
+		{payment.LateFeeAmount, SettingPaymentLateFeeIncomeAccount, DefaultPaymentLateFeeIncomeAccount},
+	} {
+		amount := roundCents(part.amount)
+		if amount <= 0 {
+			continue
+		}
+		acc, err := account(part.setting, part.fallback)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, &domain.AccountingEntryLine{
+			AccountID: acc.ID, EntryType: domain.EntryTypeCredit, Amount: amount, Description: description,
+		})
+		total += amount
+	}
+
+	cash, err := account(SettingPaymentCashAccount, DefaultPaymentCashAccount)
+	if err != nil {
+		return nil, err
+	}
+	debit := &domain.AccountingEntryLine{
+		AccountID: cash.ID, EntryType: domain.EntryTypeDebit, Amount: roundCents(total), Description: description,
+	}
+	return append([]*domain.AccountingEntryLine{debit}, lines...), nil
+}
+
+// newPaymentEntry builds the balanced, unsaved entry of a payment. It is built
+// before the payment is saved so a missing account rejects the payment
+// instead of leaving it off the books.
+func (s *PaymentService) newPaymentEntry(ctx context.Context, payment *domain.Payment, loan *domain.Loan) (*domain.AccountingEntry, error) {
+	description := fmt.Sprintf("Pago del préstamo %s", loan.LoanNumber)
+	lines, err := s.paymentEntryLines(ctx, payment, description)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &domain.AccountingEntry{
+		BranchID:      payment.BranchID,
+		EntryDate:     payment.PaymentDate,
+		Description:   description,
+		ReferenceType: domain.AccountingReferencePayment,
+		Lines:         lines,
+		CreatedBy:     &payment.CreatedBy,
+	}
+	entry.TotalDebit, entry.TotalCredit = entry.LineTotals()
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// postEntry numbers, saves and posts an entry booked for a payment
+func (s *PaymentService) postEntry(ctx context.Context, entry *domain.AccountingEntry, paymentID, userID int64) error {
+	number, err := s.entryRepo.GenerateEntryNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate entry number: %w", err)
+	}
+	entry.EntryNumber = number
+	entry.ReferenceID = &paymentID
+
+	if err := s.entryRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to create payment entry: %w", err)
+	}
+	if err := s.entryRepo.Post(ctx, entry.ID, userID); err != nil {
+		return fmt.Errorf("failed to post payment entry: %w", err)
+	}
+	entry.IsPosted = true
+	return nil
+}
+
+// undoPayment compensates a payment whose entry could not be posted or whose
+// cash could not be recorded: the loan goes back to how it stood before and the
+// payment is marked as failed
+func (s *PaymentService) undoPayment(ctx context.Context, payment *domain.Payment, loan *domain.Loan) {
+	if err := s.loanRepo.Update(ctx, loan); err != nil {
+		s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Failed to restore loan after payment failure")
+	}
+	payment.Status = domain.PaymentStatusFailed
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		s.logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to mark payment as failed")
+	}
+}
+
+// postReversalEntry books the opposite of the entry posted for a reversed or
+// undone payment. Payments booked before entries were posted have none to
+// reverse. The payment itself has already been saved, so a failure is only logged.
+func (s *PaymentService) postReversalEntry(ctx context.Context, payment *domain.Payment, loan *domain.Loan, userID int64) {
+	entries, err := s.entryRepo.ListByReference(ctx, domain.AccountingReferencePayment, payment.ID)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to get payment entry to reverse")
+		return
+	}
+
+	description := fmt.Sprintf("Reversión del pago %s del préstamo %s", payment.PaymentNumber, loan.LoanNumber)
+	for _, ref := range entries {
+		if !ref.IsPosted {
+			continue
+		}
+		original, err := s.entryRepo.GetByID(ctx, ref.ID)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("entry_id", ref.ID).Msg("Failed to get payment entry to reverse")
+			continue
+		}
+		lines := make([]*domain.AccountingEntryLine, len(original.Lines))
+		for i, line := range original.Lines {
+			entryType := domain.EntryTypeDebit
+			if line.EntryType == domain.EntryTypeDebit {
+				entryType = domain.EntryTypeCredit
+			}
+			lines[i] = &domain.AccountingEntryLine{
+				AccountID: line.AccountID, EntryType: entryType, Amount: line.Amount, Description: description,
+			}
+		}
+		entry := &domain.AccountingEntry{
+			BranchID:      original.BranchID,
+			EntryDate:     time.Now(),
+			Description:   description,
+			ReferenceType: domain.AccountingReferencePaymentReversal,
+			TotalDebit:    original.TotalCredit,
+			TotalCredit:   original.TotalDebit,
+			Lines:         lines,
+			CreatedBy:     &userID,
+		}
+		if err := s.postEntry(ctx, entry, payment.ID, userID); err != nil {
+			s.logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to post payment reversal entry")
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/xuri/excelize/v2"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Sheets of the payment report workbook
+const (
+	paymentReportSummarySheet = "Resumen"
+	paymentReportDetailSheet  = "Pagos"
+)
+
+// reportPayments lists the payments of a payment report, those made between
+// two dates, newest first
+func (s *ReportService) reportPayments(ctx context.Context, branchID int64, dateFrom, dateTo string) (*repository.PaginatedResult[domain.Payment], error) {
+	params := repository.PaymentListParams{
+		BranchID: branchID,
+		DateFrom: &dateFrom,
+		DateTo:   &dateTo,
+		PaginationParams: repository.PaginationParams{
+			PerPage: 10000,
+			OrderBy: "payment_date",
+			Order:   "desc",
+		},
+	}
+	return s.paymentRepo.List(ctx, params)
+}
+
+// ExportPaymentReportXLSX writes the completed payments of the payment report
+// as an Excel workbook: a summary sheet with the totals by payment method and a
+// detail sheet with one row per payment. Amounts and dates are written as
+// numbers so the workbook works with formulas and pivot tables.
+func (s *ReportService) ExportPaymentReportXLSX(ctx context.Context, branchID int64, dateFrom, dateTo string) ([]byte, error) {
+	result, err := s.reportPayments(ctx, branchID, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []domain.Payment
+	for _, payment := range result.Data {
+		if payment.Status == domain.PaymentStatusCompleted {
+			payments = append(payments, payment)
+		}
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	amountStyle, err := f.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workbook style: %w", err)
+	}
+	dateStyle, err := f.NewStyle(&excelize.Style{NumFmt: 22}) // m/d/yy h:mm
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workbook style: %w", err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workbook style: %w", err)
+	}
+
+	if err := f.SetSheetName("Sheet1", paymentReportSummarySheet); err != nil {
+		return nil, fmt.Errorf("failed to create summary sheet: %w", err)
+	}
+	if _, err := f.NewSheet(paymentReportDetailSheet); err != nil {
+		return nil, fmt.Errorf("failed to create detail sheet: %w", err)
+	}
+
+	if err := writePaymentReportSummary(f, payments, dateFrom, dateTo, headerStyle, amountStyle); err != nil {
+		return nil, fmt.Errorf("failed to write summary sheet: %w", err)
+	}
+	if err := writePaymentReportDetail(f, payments, headerStyle, amountStyle, dateStyle); err != nil {
+		return nil, fmt.Errorf("failed to write detail sheet: %w", err)
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// paymentMethodTotals are the totals of the payments made with one method
+type paymentMethodTotals struct {
+	count                                int
+	amount, principal, interest, lateFee float64
+}
+
+func (t *paymentMethodTotals) add(payment domain.Payment) {
+	t.count++
+	t.amount += payment.Amount
+	t.principal += payment.PrincipalAmount
+	t.interest += payment.InterestAmount
+	t.lateFee += payment.LateFeeAmount
+}
+
+// writePaymentReportSummary writes the totals by payment method, then the
+// grand total
+func writePaymentReportSummary(f *excelize.File, payments []domain.Payment, dateFrom, dateTo string, headerStyle, amountStyle int) error {
+	sheet := paymentReportSummarySheet
+	byMethod := make(map[string]*paymentMethodTotals)
+	total := &paymentMethodTotals{}
+	for _, payment := range payments {
+		method := string(payment.PaymentMethod)
+		if byMethod[method] == nil {
+			byMethod[method] = &paymentMethodTotals{}
+		}
+		byMethod[method].add(payment)
+		total.add(payment)
+	}
+	methods := make([]string, 0, len(byMethod))
+	for method := range byMethod {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	if err := f.SetSheetRow(sheet, "A1", &[]interface{}{"Reporte de pagos", dateFrom, dateTo}); err != nil {
+		return err
+	}
+	if err := f.SetSheetRow(sheet, "A3", &[]interface{}{"Método", "Pagos", "Monto", "Capital", "Interés", "Mora"}); err != nil {
+		return err
+	}
+
+	row := 4
+	writeTotals := func(label string, t *paymentMethodTotals) error {
+		cell := fmt.Sprintf("A%d", row)
+		row++
+		return f.SetSheetRow(sheet, cell, &[]interface{}{label, t.count, roundCents(t.amount), roundCents(t.principal), roundCents(t.interest), roundCents(t.lateFee)})
+	}
+	for _, method := range methods {
+		if err := writeTotals(method, byMethod[method]); err != nil {
+			return err
+		}
+	}
+	if err := writeTotals("Total", total); err != nil {
+		return err
+	}
+
+	if err := f.SetCellStyle(sheet, "A1", "F3", headerStyle); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, fmt.Sprintf("A%d", row-1), fmt.Sprintf("A%d", row-1), headerStyle); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "C4", fmt.Sprintf("F%d", row-1), amountStyle); err != nil {
+		return err
+	}
+	return f.SetColWidth(sheet, "A", "F", 14)
+}
+
+// writePaymentReportDetail writes one row per payment
+func writePaymentReportDetail(f *excelize.File, payments []domain.Payment, headerStyle, amountStyle, dateStyle int) error {
+	sheet := paymentReportDetailSheet
+	if err := f.SetSheetRow(sheet, "A1", &[]interface{}{
+		"Número", "Fecha", "Préstamo", "Cliente", "Método", "Referencia", "Monto", "Capital", "Interés", "Mora",
+	}); err != nil {
+		return err
+	}
+
+	for i, payment := range payments {
+		loanNumber, customerName := "", ""
+		if payment.Loan != nil {
+			loanNumber = payment.Loan.LoanNumber
+		}
+		if payment.Customer != nil {
+			customerName = payment.Customer.FullName()
+		}
+		cell := fmt.Sprintf("A%d", i+2)
+		if err := f.SetSheetRow(sheet, cell, &[]interface{}{
+			payment.PaymentNumber,
+			payment.PaymentDate,
+			loanNumber,
+			customerName,
+			string(payment.PaymentMethod),
+			payment.ReferenceNumber,
+			payment.Amount,
+			payment.PrincipalAmount,
+			payment.InterestAmount,
+			payment.LateFeeAmount,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetCellStyle(sheet, "A1", "J1", headerStyle); err != nil {
+		return err
+	}
+	if len(payments) > 0 {
+		last := len(payments) + 1
+		if err := f.SetCellStyle(sheet, "B2", fmt.Sprintf("B%d", last), dateStyle); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, "G2", fmt.Sprintf("J%d", last), amountStyle); err != nil {
+			return err
+		}
+	}
+	if err := f.SetColWidth(sheet, "A", "J", 14); err != nil {
+		return err
+	}
+	return f.AutoFilter(sheet, fmt.Sprintf("A1:J%d", len(payments)+1), nil)
+}
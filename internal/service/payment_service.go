@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -17,7 +18,13 @@ type PaymentService struct {
 	loanRepo     repository.LoanRepository
 	customerRepo repository.CustomerRepository
 	itemRepo     repository.ItemRepository
+	settingRepo  repository.SettingRepository
+	cashService  *CashService
 	logger       zerolog.Logger
+
+	// Optional: books an accounting entry for every payment
+	accountRepo repository.AccountRepository
+	entryRepo   repository.AccountingEntryRepository
 }
 
 // NewPaymentService creates a new PaymentService
@@ -26,6 +33,8 @@ func NewPaymentService(
 	loanRepo repository.LoanRepository,
 	customerRepo repository.CustomerRepository,
 	itemRepo repository.ItemRepository,
+	settingRepo repository.SettingRepository,
+	cashService *CashService,
 	logger zerolog.Logger,
 ) *PaymentService {
 	return &PaymentService{
@@ -33,6 +42,8 @@ func NewPaymentService(
 		loanRepo:     loanRepo,
 		customerRepo: customerRepo,
 		itemRepo:     itemRepo,
+		settingRepo:  settingRepo,
+		cashService:  cashService,
 		logger:       logger.With().Str("service", "payment").Logger(),
 	}
 }
@@ -45,8 +56,19 @@ type CreatePaymentInput struct {
 	ReferenceNumber string  `json:"reference_number"`
 	Notes           string  `json:"notes"`
 	CashSessionID   *int64  `json:"cash_session_id"`
-	BranchID        int64   `json:"-"`
-	CreatedBy       int64   `json:"-"`
+	// PaymentDate is the day the customer actually paid, when earlier than
+	// today. Backdating needs PermissionBackdatePayment.
+	PaymentDate *domain.Date `json:"payment_date"`
+	// IdempotencyKey identifies the request, so a repeated one returns the
+	// payment it already created instead of recording it again
+	IdempotencyKey string `json:"-"`
+	BranchID       int64  `json:"-"`
+	CreatedBy      int64  `json:"-"`
+}
+
+// IsBackdated reports whether the payment is dated before the given day
+func (i CreatePaymentInput) IsBackdated(now time.Time) bool {
+	return i.PaymentDate != nil && !i.PaymentDate.IsZero() && i.PaymentDate.Before(domain.DateFromTime(now).Time)
 }
 
 // PaymentResult contains the result of a payment
@@ -55,6 +77,74 @@ type PaymentResult struct {
 	Loan             *domain.Loan    `json:"loan"`
 	IsFullyPaid      bool            `json:"is_fully_paid"`
 	RemainingBalance float64         `json:"remaining_balance"`
+	// LateFeesRolledBack are the late fees accrued after a backdated payment's
+	// date, which the loan no longer owes
+	LateFeesRolledBack float64 `json:"late_fees_rolled_back,omitempty"`
+	// Replayed is set when the payment was created by an earlier request with
+	// the same idempotency key
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// SettingPaymentBackdateMaxDays is how many days back a payment may be dated;
+// 0 disallows backdating
+const SettingPaymentBackdateMaxDays = "payment_backdate_max_days"
+
+// DefaultPaymentBackdateMaxDays is used when no backdating window is configured
+const DefaultPaymentBackdateMaxDays = 7
+
+// PermissionBackdatePayment lets a user record a payment with an earlier date
+const PermissionBackdatePayment = "payments.backdate"
+
+// ErrIdempotencyKeyReused is returned when an idempotency key already created
+// a payment of a different loan or amount
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different payment")
+
+// ErrIdempotentPaymentNotCompleted is returned when an idempotency key belongs
+// to a payment that was reversed or did not go through
+var ErrIdempotentPaymentNotCompleted = errors.New("the payment made with this idempotency key is not completed")
+
+// ErrPaymentBackdateOutOfWindow is returned when a payment is dated further
+// back than the branch allows
+var ErrPaymentBackdateOutOfWindow = errors.New("payment date is outside the permitted backdating window")
+
+// checkPaymentDate validates the date of a payment against today, the
+// backdating window of the branch and the start of the loan
+func (s *PaymentService) checkPaymentDate(ctx context.Context, loan *domain.Loan, input CreatePaymentInput, now time.Time) error {
+	if input.PaymentDate == nil || input.PaymentDate.IsZero() {
+		return nil
+	}
+	today := domain.DateFromTime(now)
+	if input.PaymentDate.After(today.Time) {
+		return errors.New("payment date cannot be in the future")
+	}
+	if !input.IsBackdated(now) {
+		return nil
+	}
+
+	maxDays := getSettingInt(ctx, s.settingRepo, SettingPaymentBackdateMaxDays, &input.BranchID, DefaultPaymentBackdateMaxDays)
+	if days := int(today.Sub(input.PaymentDate.Time).Hours() / 24); days > maxDays {
+		return fmt.Errorf("%w (%d day(s) back, at most %d allowed)", ErrPaymentBackdateOutOfWindow, days, maxDays)
+	}
+	if input.PaymentDate.Before(loan.StartDate.Time) {
+		return errors.New("payment date cannot be before the loan started")
+	}
+	return nil
+}
+
+// rollBackLateFees removes the late fees the loan accrued after the given day,
+// so a payment made that day does not pay for them, and returns how much was
+// removed. Interest is charged for the whole term up front, so only late fees
+// depend on the payment date. Late fees already paid stay paid.
+func rollBackLateFees(loan *domain.Loan, day time.Time) float64 {
+	accrued := loan.LateFeeRate / 100 * loan.LoanAmount * float64(loan.DaysPastDueAt(day))
+	if accrued >= loan.LateFeeAmount {
+		return 0
+	}
+	excess := math.Min(loan.LateFeeAmount-accrued, loan.LateFeeRemaining)
+	excess = math.Round(excess*100) / 100
+	loan.LateFeeAmount -= excess
+	loan.LateFeeRemaining -= excess
+	return excess
 }
 
 // Create creates a new payment and applies it to the loan
@@ -66,6 +156,13 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		Int64("created_by", input.CreatedBy).
 		Msg("Processing payment")
 
+	// A repeated request returns the payment it already created
+	if input.IdempotencyKey != "" {
+		if result, err := s.replayPayment(ctx, input); result != nil || err != nil {
+			return result, err
+		}
+	}
+
 	// Get loan
 	loan, err := s.loanRepo.GetByID(ctx, input.LoanID)
 	if err != nil {
@@ -82,6 +179,29 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		s.logger.Warn().Int64("loan_id", input.LoanID).Msg("Payment rejected: loan confiscated")
 		return nil, errors.New("loan has been confiscated")
 	}
+	if loan.AwaitingAuthorization() {
+		s.logger.Warn().Int64("loan_id", input.LoanID).Msg("Payment rejected: loan disbursement not authorized yet")
+		return nil, errors.New("loan disbursement is awaiting authorization")
+	}
+	loanBefore := *loan
+
+	// A backdated payment settles the loan as it stood on the day it was made
+	now := time.Now()
+	if err := s.checkPaymentDate(ctx, loan, input, now); err != nil {
+		s.logger.Warn().Err(err).Int64("loan_id", input.LoanID).Msg("Payment rejected: invalid payment date")
+		return nil, err
+	}
+	paymentDate := now
+	lateFeesRolledBack := 0.0
+	if input.IsBackdated(now) {
+		paymentDate = input.PaymentDate.Time
+		lateFeesRolledBack = rollBackLateFees(loan, paymentDate)
+		s.logger.Info().
+			Int64("loan_id", input.LoanID).
+			Time("payment_date", paymentDate).
+			Float64("late_fees_rolled_back", lateFeesRolledBack).
+			Msg("Backdated payment")
+	}
 
 	// Calculate total amount owed (prevent overpayment)
 	totalOwed := loan.PrincipalRemaining + loan.InterestRemaining + loan.LateFeeRemaining
@@ -94,6 +214,17 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		return nil, fmt.Errorf("payment amount (Q%.2f) exceeds total owed (Q%.2f)", input.Amount, totalOwed)
 	}
 
+	// Cash receipts must go into an open cash session
+	var cashSession *domain.CashSession
+	if s.cashService != nil && input.PaymentMethod == string(domain.PaymentMethodCash) {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, input.CreatedBy, input.CashSessionID)
+		if err != nil {
+			s.logger.Warn().Int64("user_id", input.CreatedBy).Msg("Payment rejected: no open cash session")
+			return nil, err
+		}
+		input.CashSessionID = &cashSession.ID
+	}
+
 	// Calculate how to apply the payment
 	// Order: Late fees -> Interest -> Principal
 	remainingPayment := input.Amount
@@ -145,12 +276,11 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 	isFullyPaid := loan.PrincipalRemaining == 0 && loan.InterestRemaining == 0 && loan.LateFeeRemaining == 0
 	if isFullyPaid {
 		loan.Status = domain.LoanStatusPaid
-		now := time.Now()
-		loan.PaidDate = &now
+		loan.PaidDate = &paymentDate
 	}
 
 	// Generate payment number
-	paymentNumber, err := s.paymentRepo.GenerateNumber(ctx)
+	paymentNumber, err := s.paymentRepo.GenerateNumber(ctx, sequenceResetCadence(ctx, s.settingRepo, input.BranchID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate payment number: %w", err)
 	}
@@ -168,16 +298,34 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		PaymentMethod:        domain.PaymentMethod(input.PaymentMethod),
 		ReferenceNumber:      input.ReferenceNumber,
 		Status:               domain.PaymentStatusCompleted,
-		PaymentDate:          time.Now(),
+		PaymentDate:          paymentDate,
 		LoanBalanceAfter:     loan.PrincipalRemaining,
 		InterestBalanceAfter: loan.InterestRemaining,
 		Notes:                input.Notes,
 		CashSessionID:        input.CashSessionID,
+		IdempotencyKey:       input.IdempotencyKey,
 		CreatedBy:            input.CreatedBy,
 	}
 
-	// Save payment
+	// Build the entry first, so a misconfigured chart of accounts rejects the
+	// payment before anything is written
+	var entry *domain.AccountingEntry
+	if s.entryRepo != nil {
+		entry, err = s.newPaymentEntry(ctx, payment, loan)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("loan_id", loan.ID).Msg("Payment rejected: cannot build accounting entry")
+			return nil, fmt.Errorf("failed to build payment entry: %w", err)
+		}
+	}
+
+	// Save payment. A concurrent request with the same key may have won the
+	// race, in which case its payment is returned.
 	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		if errors.Is(err, repository.ErrDuplicateIdempotencyKey) {
+			if result, replayErr := s.replayPayment(ctx, input); result != nil || replayErr != nil {
+				return result, replayErr
+			}
+		}
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
@@ -186,6 +334,31 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		return nil, fmt.Errorf("failed to update loan: %w", err)
 	}
 
+	// Book the payment, undoing it when the entry cannot be posted
+	if entry != nil {
+		if err := s.postEntry(ctx, entry, payment.ID, input.CreatedBy); err != nil {
+			s.logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to post payment entry, undoing payment")
+			s.undoPayment(ctx, payment, &loanBefore)
+			return nil, err
+		}
+	}
+
+	// Record the cash receipt. A payment whose cash never reached the drawer
+	// is undone, along with its entry.
+	if cashSession != nil {
+		if err := s.cashService.RecordPaymentMovement(ctx, cashSession.ID, payment.ID, payment.Amount, input.PaymentMethod, input.CreatedBy); err != nil {
+			s.logger.Error().Err(err).
+				Int64("payment_id", payment.ID).
+				Int64("session_id", cashSession.ID).
+				Msg("Failed to record payment cash movement, undoing payment")
+			if entry != nil {
+				s.postReversalEntry(ctx, payment, loan, input.CreatedBy)
+			}
+			s.undoPayment(ctx, payment, &loanBefore)
+			return nil, fmt.Errorf("failed to record payment movement: %w", err)
+		}
+	}
+
 	// Update item status to available if loan is fully paid
 	if isFullyPaid {
 		if err := s.itemRepo.UpdateStatus(ctx, loan.ItemID, domain.ItemStatusAvailable); err != nil {
@@ -225,11 +398,59 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		Bool("fully_paid", isFullyPaid).
 		Msg("Payment processed successfully")
 
+	return &PaymentResult{
+		Payment:            payment,
+		Loan:               loan,
+		IsFullyPaid:        isFullyPaid,
+		RemainingBalance:   loan.RemainingBalance(),
+		LateFeesRolledBack: lateFeesRolledBack,
+	}, nil
+}
+
+// replayPayment returns the result of the payment already created with the
+// input's idempotency key, or nil when the key is new. Only a completed payment
+// is replayed; failed payments give their key up, see PaymentRepository.Update.
+func (s *PaymentService) replayPayment(ctx context.Context, input CreatePaymentInput) (*PaymentResult, error) {
+	payment, err := s.paymentRepo.GetByIdempotencyKey(ctx, input.CreatedBy, input.IdempotencyKey)
+	if err != nil {
+		s.logger.Error().Err(err).Str("idempotency_key", input.IdempotencyKey).Msg("Failed to check idempotency key")
+		return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	if payment == nil {
+		return nil, nil
+	}
+	if payment.LoanID != input.LoanID || roundCents(payment.Amount) != roundCents(input.Amount) {
+		s.logger.Warn().
+			Str("idempotency_key", input.IdempotencyKey).
+			Int64("payment_id", payment.ID).
+			Msg("Payment rejected: idempotency key reused for a different payment")
+		return nil, ErrIdempotencyKeyReused
+	}
+	if payment.Status != domain.PaymentStatusCompleted {
+		s.logger.Warn().
+			Str("idempotency_key", input.IdempotencyKey).
+			Int64("payment_id", payment.ID).
+			Str("status", string(payment.Status)).
+			Msg("Payment rejected: idempotency key belongs to a payment that is not completed")
+		return nil, ErrIdempotentPaymentNotCompleted
+	}
+
+	loan, err := s.loanRepo.GetByID(ctx, payment.LoanID)
+	if err != nil {
+		return nil, errors.New("loan not found")
+	}
+
+	s.logger.Info().
+		Int64("payment_id", payment.ID).
+		Str("idempotency_key", input.IdempotencyKey).
+		Msg("Repeated payment request, returning existing payment")
+
 	return &PaymentResult{
 		Payment:          payment,
 		Loan:             loan,
-		IsFullyPaid:      isFullyPaid,
+		IsFullyPaid:      loan.Status == domain.LoanStatusPaid,
 		RemainingBalance: loan.RemainingBalance(),
+		Replayed:         true,
 	}, nil
 }
 
@@ -340,6 +561,10 @@ func (s *PaymentService) Reverse(ctx context.Context, input ReversePaymentInput)
 		return nil, fmt.Errorf("failed to update payment: %w", err)
 	}
 
+	if s.entryRepo != nil {
+		s.postReversalEntry(ctx, payment, loan, input.ReversedBy)
+	}
+
 	return payment, nil
 }
 
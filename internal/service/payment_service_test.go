@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
@@ -19,15 +20,17 @@ func setupPaymentService() (*PaymentService, *mocks.MockPaymentRepository, *mock
 	paymentRepo := new(mocks.MockPaymentRepository)
 	loanRepo := new(mocks.MockLoanRepository)
 	customerRepo := new(mocks.MockCustomerRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	itemRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
-	service := NewPaymentService(paymentRepo, loanRepo, customerRepo, logger)
+	service := NewPaymentService(paymentRepo, loanRepo, customerRepo, itemRepo, nil, nil, logger)
 	return service, paymentRepo, loanRepo, customerRepo
 }
 
 // --- Create tests ---
 
 func TestPaymentService_Create_Success_PartialPayment(t *testing.T) {
-	service, paymentRepo, loanRepo, _ := setupPaymentService()
+	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
 	ctx := context.Background()
 
 	loan := &domain.Loan{
@@ -37,13 +40,15 @@ func TestPaymentService_Create_Success_PartialPayment(t *testing.T) {
 		PrincipalRemaining: 800,
 		InterestRemaining:  100,
 		LateFeeAmount:      20,
+		LateFeeRemaining:   20,
 		AmountPaid:         0,
 	}
 
 	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	paymentRepo.On("GenerateNumber", ctx).Return("PAY-000001", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000001", nil)
 	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
 	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(10)).Return(nil, errors.New("not found"))
 
 	input := CreatePaymentInput{
 		LoanID:        1,
@@ -84,7 +89,7 @@ func TestPaymentService_Create_Success_FullPayment(t *testing.T) {
 	customer := &domain.Customer{ID: 10, TotalPaid: 500}
 
 	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	paymentRepo.On("GenerateNumber", ctx).Return("PAY-000002", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000002", nil)
 	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
 	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
 	customerRepo.On("GetByID", ctx, int64(10)).Return(customer, nil)
@@ -110,7 +115,7 @@ func TestPaymentService_Create_Success_FullPayment(t *testing.T) {
 }
 
 func TestPaymentService_Create_AllocatesLateFeeFirst(t *testing.T) {
-	service, paymentRepo, loanRepo, _ := setupPaymentService()
+	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
 	ctx := context.Background()
 
 	loan := &domain.Loan{
@@ -120,13 +125,15 @@ func TestPaymentService_Create_AllocatesLateFeeFirst(t *testing.T) {
 		PrincipalRemaining: 500,
 		InterestRemaining:  100,
 		LateFeeAmount:      50,
+		LateFeeRemaining:   50,
 		AmountPaid:         0,
 	}
 
 	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	paymentRepo.On("GenerateNumber", ctx).Return("PAY-000003", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000003", nil)
 	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
 	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(10)).Return(nil, errors.New("not found"))
 
 	input := CreatePaymentInput{
 		LoanID:        1,
@@ -187,6 +194,27 @@ func TestPaymentService_Create_LoanConfiscated(t *testing.T) {
 	assert.Equal(t, "loan has been confiscated", err.Error())
 }
 
+func TestPaymentService_Create_LoanAwaitingAuthorization(t *testing.T) {
+	service, _, loanRepo, _ := setupPaymentService()
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:                    1,
+		Status:                domain.LoanStatusPendingAuthorization,
+		AuthorizationRequired: true,
+	}
+
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+
+	input := CreatePaymentInput{LoanID: 1, Amount: 100, PaymentMethod: "cash"}
+
+	result, err := service.Create(ctx, input)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "loan disbursement is awaiting authorization", err.Error())
+}
+
 func TestPaymentService_Create_LoanNotFound(t *testing.T) {
 	service, _, loanRepo, _ := setupPaymentService()
 	ctx := context.Background()
@@ -216,7 +244,7 @@ func TestPaymentService_Create_GenerateNumberError(t *testing.T) {
 	}
 
 	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	paymentRepo.On("GenerateNumber", ctx).Return("", errors.New("db error"))
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("", errors.New("db error"))
 
 	input := CreatePaymentInput{LoanID: 1, Amount: 50, PaymentMethod: "cash"}
 
@@ -241,7 +269,7 @@ func TestPaymentService_Create_PaymentRepoError(t *testing.T) {
 	}
 
 	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	paymentRepo.On("GenerateNumber", ctx).Return("PAY-000001", nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000001", nil)
 	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(errors.New("db error"))
 
 	input := CreatePaymentInput{LoanID: 1, Amount: 50, PaymentMethod: "cash", BranchID: 1, CreatedBy: 1}
@@ -253,8 +281,8 @@ func TestPaymentService_Create_PaymentRepoError(t *testing.T) {
 	assert.Equal(t, "failed to create payment", err.Error())
 }
 
-func TestPaymentService_Create_OverpaymentAppliedCorrectly(t *testing.T) {
-	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
+func TestPaymentService_Create_OverpaymentRejected(t *testing.T) {
+	service, paymentRepo, loanRepo, _ := setupPaymentService()
 	ctx := context.Background()
 
 	loan := &domain.Loan{
@@ -267,14 +295,7 @@ func TestPaymentService_Create_OverpaymentAppliedCorrectly(t *testing.T) {
 		AmountPaid:         850,
 	}
 
-	customer := &domain.Customer{ID: 10, TotalPaid: 0}
-
 	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
-	paymentRepo.On("GenerateNumber", ctx).Return("PAY-000004", nil)
-	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
-	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
-	customerRepo.On("GetByID", ctx, int64(10)).Return(customer, nil)
-	customerRepo.On("UpdateCreditInfo", ctx, int64(10), mock.AnythingOfType("repository.CustomerCreditUpdate")).Return(nil)
 
 	// Overpay by 50 (200 total but only 150 remaining)
 	input := CreatePaymentInput{
@@ -287,11 +308,11 @@ func TestPaymentService_Create_OverpaymentAppliedCorrectly(t *testing.T) {
 
 	result, err := service.Create(ctx, input)
 
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.True(t, result.IsFullyPaid)
-	assert.Equal(t, 50.0, result.Payment.InterestAmount)
-	assert.Equal(t, 100.0, result.Payment.PrincipalAmount)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "payment amount (Q200.00) exceeds total owed (Q150.00)", err.Error())
+	paymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
 }
 
 // --- GetByID tests ---
@@ -366,7 +387,7 @@ func TestPaymentService_List_Success(t *testing.T) {
 // --- Reverse tests ---
 
 func TestPaymentService_Reverse_Success(t *testing.T) {
-	service, paymentRepo, loanRepo, _ := setupPaymentService()
+	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
 	ctx := context.Background()
 
 	payment := &domain.Payment{
@@ -392,6 +413,7 @@ func TestPaymentService_Reverse_Success(t *testing.T) {
 	loanRepo.On("GetByID", ctx, int64(10)).Return(loan, nil)
 	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
 	paymentRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(0)).Return(nil, errors.New("not found"))
 
 	input := ReversePaymentInput{
 		PaymentID:  1,
@@ -410,14 +432,14 @@ func TestPaymentService_Reverse_Success(t *testing.T) {
 	// Verify loan balances were restored
 	assert.Equal(t, 500.0, loan.PrincipalRemaining)  // 400 + 100
 	assert.Equal(t, 80.0, loan.InterestRemaining)     // 0 + 80
-	assert.Equal(t, 20.0, loan.LateFeeAmount)         // 0 + 20
+	assert.Equal(t, 20.0, loan.LateFeeRemaining)      // 0 + 20
 	assert.Equal(t, 400.0, loan.AmountPaid)            // 600 - 200
 	loanRepo.AssertExpectations(t)
 	paymentRepo.AssertExpectations(t)
 }
 
 func TestPaymentService_Reverse_ReactivatesPaidLoan(t *testing.T) {
-	service, paymentRepo, loanRepo, _ := setupPaymentService()
+	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
 	ctx := context.Background()
 
 	now := time.Now()
@@ -445,6 +467,7 @@ func TestPaymentService_Reverse_ReactivatesPaidLoan(t *testing.T) {
 	loanRepo.On("GetByID", ctx, int64(10)).Return(loan, nil)
 	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil)
 	paymentRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	customerRepo.On("GetByID", ctx, int64(0)).Return(nil, errors.New("not found"))
 
 	input := ReversePaymentInput{
 		PaymentID:  1,
@@ -595,3 +618,378 @@ func TestPaymentService_CalculateMinimumPayment_BalanceLessThanMinimum(t *testin
 	assert.NoError(t, err)
 	assert.Equal(t, 60.0, result) // Remaining balance < MinPayment, return balance
 }
+
+// --- Backdated payment tests ---
+
+// setupOverduePayment prepares a partial payment on a loan 10 days past due
+// whose late fees accrued up to today at 10 a day
+func setupOverduePayment() (*PaymentService, *domain.Loan) {
+	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
+	today := domain.Today()
+	loan := &domain.Loan{
+		ID:                 1,
+		CustomerID:         10,
+		Status:             domain.LoanStatusOverdue,
+		LoanAmount:         1000,
+		PrincipalRemaining: 1000,
+		LateFeeRate:        1,
+		LateFeeAmount:      100,
+		LateFeeRemaining:   100,
+		StartDate:          domain.DateFromTime(today.AddDate(0, -1, -10)),
+		DueDate:            domain.DateFromTime(today.AddDate(0, 0, -10)),
+	}
+	loanRepo.On("GetByID", mock.Anything, int64(1)).Return(loan, nil)
+	loanRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Loan")).Return(nil).Maybe()
+	paymentRepo.On("GenerateNumber", mock.Anything, domain.SequenceResetYearly).Return("PAY-000010", nil).Maybe()
+	paymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Payment")).Return(nil).Maybe()
+	customerRepo.On("GetByID", mock.Anything, int64(10)).Return(nil, errors.New("not found")).Maybe()
+	return service, loan
+}
+
+func TestPaymentService_Create_BackdatedPaymentReducesLateFees(t *testing.T) {
+	ctx := context.Background()
+	input := CreatePaymentInput{LoanID: 1, Amount: 100, PaymentMethod: "card", BranchID: 1, CreatedBy: 1}
+
+	service, _ := setupOverduePayment()
+	todayResult, err := service.Create(ctx, input)
+	assert.NoError(t, err)
+
+	// The customer actually paid 4 days ago, 6 days past due
+	service, _ = setupOverduePayment()
+	paidOn := domain.DateFromTime(domain.Today().AddDate(0, 0, -4))
+	backdated := input
+	backdated.PaymentDate = &paidOn
+	backdatedResult, err := service.Create(ctx, backdated)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 100.0, todayResult.Payment.LateFeeAmount)
+	assert.Equal(t, 60.0, backdatedResult.Payment.LateFeeAmount)
+	assert.Equal(t, 40.0, backdatedResult.Payment.PrincipalAmount)
+	assert.Equal(t, 40.0, backdatedResult.LateFeesRolledBack)
+	assert.Equal(t, 60.0, backdatedResult.Loan.LateFeeAmount)
+	assert.Equal(t, paidOn.Time, backdatedResult.Payment.PaymentDate)
+	assert.Less(t, backdatedResult.RemainingBalance, todayResult.RemainingBalance)
+}
+
+func TestPaymentService_Create_BackdatedBeyondWindow(t *testing.T) {
+	service, loan := setupOverduePayment()
+	paidOn := domain.DateFromTime(domain.Today().AddDate(0, 0, -(DefaultPaymentBackdateMaxDays + 1)))
+
+	result, err := service.Create(context.Background(), CreatePaymentInput{
+		LoanID: 1, Amount: 100, PaymentMethod: "card", PaymentDate: &paidOn, BranchID: 1, CreatedBy: 1,
+	})
+
+	assert.ErrorIs(t, err, ErrPaymentBackdateOutOfWindow)
+	assert.Nil(t, result)
+	assert.Equal(t, 100.0, loan.LateFeeAmount)
+}
+
+func TestPaymentService_Create_RepeatedIdempotencyKeyReturnsSamePayment(t *testing.T) {
+	service, paymentRepo, loanRepo, customerRepo := setupPaymentService()
+	ctx := context.Background()
+	loan := &domain.Loan{ID: 1, CustomerID: 10, Status: domain.LoanStatusActive, PrincipalRemaining: 800, InterestRemaining: 100}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).Return(nil).Once()
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000001", nil).Once()
+	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil).Once()
+	customerRepo.On("GetByID", ctx, int64(10)).Return(nil, errors.New("not found")).Once()
+	input := CreatePaymentInput{LoanID: 1, Amount: 50, PaymentMethod: "card", IdempotencyKey: "tap-1", BranchID: 1, CreatedBy: 7}
+
+	// The first request creates the payment
+	paymentRepo.On("GetByIdempotencyKey", ctx, int64(7), "tap-1").Return(nil, nil).Once()
+	first, err := service.Create(ctx, input)
+	require.NoError(t, err)
+	assert.False(t, first.Replayed)
+	assert.Equal(t, "tap-1", first.Payment.IdempotencyKey)
+
+	// The retry finds it by its key and records nothing
+	paymentRepo.On("GetByIdempotencyKey", ctx, int64(7), "tap-1").Return(first.Payment, nil)
+	second, err := service.Create(ctx, input)
+	require.NoError(t, err)
+	assert.True(t, second.Replayed)
+	assert.Same(t, first.Payment, second.Payment)
+	assert.Equal(t, 850.0, second.RemainingBalance)
+	paymentRepo.AssertNumberOfCalls(t, "Create", 1)
+	loanRepo.AssertNumberOfCalls(t, "Update", 1)
+
+	// The same key cannot be spent on a different payment
+	other := input
+	other.Amount = 60
+	_, err = service.Create(ctx, other)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	paymentRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestPaymentService_Create_ConcurrentIdempotencyKeyReturnsWinner(t *testing.T) {
+	service, paymentRepo, loanRepo, _ := setupPaymentService()
+	ctx := context.Background()
+	loan := &domain.Loan{ID: 1, CustomerID: 10, Status: domain.LoanStatusActive, PrincipalRemaining: 800, InterestRemaining: 100}
+	winner := &domain.Payment{ID: 3, PaymentNumber: "PAY-000003", LoanID: 1, Amount: 50, Status: domain.PaymentStatusCompleted}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000004", nil)
+
+	// Both requests miss the lookup; the unique key rejects the second insert
+	paymentRepo.On("GetByIdempotencyKey", ctx, int64(7), "tap-1").Return(nil, nil).Once()
+	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(repository.ErrDuplicateIdempotencyKey)
+	paymentRepo.On("GetByIdempotencyKey", ctx, int64(7), "tap-1").Return(winner, nil).Once()
+
+	result, err := service.Create(ctx, CreatePaymentInput{
+		LoanID: 1, Amount: 50, PaymentMethod: "card", IdempotencyKey: "tap-1", BranchID: 1, CreatedBy: 7,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.Replayed)
+	assert.Same(t, winner, result.Payment)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_Create_IdempotencyKeyOfFailedPaymentNotReplayed(t *testing.T) {
+	service, paymentRepo, loanRepo, _ := setupPaymentService()
+	ctx := context.Background()
+	failed := &domain.Payment{ID: 3, PaymentNumber: "PAY-000003", LoanID: 1, Amount: 50, Status: domain.PaymentStatusFailed}
+	paymentRepo.On("GetByIdempotencyKey", ctx, int64(7), "tap-1").Return(failed, nil)
+
+	result, err := service.Create(ctx, CreatePaymentInput{
+		LoanID: 1, Amount: 50, PaymentMethod: "card", IdempotencyKey: "tap-1", BranchID: 1, CreatedBy: 7,
+	})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrIdempotentPaymentNotCompleted)
+	loanRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+// --- Accounting entry tests ---
+
+// paymentAccounts are the accounts of the default payment codes, by code
+var paymentAccounts = map[string]*domain.Account{
+	"1110": {ID: 1, Code: "1110", Name: "Caja General"},
+	"1120": {ID: 2, Code: "1120", Name: "Bancos"},
+	"1210": {ID: 3, Code: "1210", Name: "Préstamos por Cobrar"},
+	"4100": {ID: 4, Code: "4100", Name: "Ingresos por Intereses"},
+	"4200": {ID: 5, Code: "4200", Name: "Ingresos por Mora"},
+}
+
+// setupPaymentAccounting prepares a payment of 150 on a loan owing 20 of late
+// fees, 100 of interest and 800 of principal, with every entry created stored
+// in the returned slice
+func setupPaymentAccounting(settings map[string]string) (*PaymentService, *mocks.MockPaymentRepository, *mocks.MockLoanRepository, *mocks.MockAccountingEntryRepository, *[]*domain.AccountingEntry) {
+	paymentRepo := new(mocks.MockPaymentRepository)
+	loanRepo := new(mocks.MockLoanRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	accountRepo := new(mocks.MockAccountRepository)
+	entryRepo := new(mocks.MockAccountingEntryRepository)
+	service := NewPaymentService(paymentRepo, loanRepo, customerRepo, new(mocks.MockItemRepository), settingRepo, nil, zerolog.Nop())
+	service.SetAccounting(accountRepo, entryRepo)
+
+	for key, code := range settings {
+		settingRepo.On("Get", mock.Anything, key, mock.Anything).Return(&domain.Setting{Key: key, Value: code}, nil)
+	}
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+	for code, account := range paymentAccounts {
+		accountRepo.On("GetByCode", mock.Anything, code).Return(account, nil)
+	}
+	accountRepo.On("GetByCode", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+
+	loan := &domain.Loan{
+		ID:                 1,
+		LoanNumber:         "LN-000001",
+		CustomerID:         10,
+		Status:             domain.LoanStatusActive,
+		PrincipalRemaining: 800,
+		InterestRemaining:  100,
+		LateFeeAmount:      20,
+		LateFeeRemaining:   20,
+	}
+	loanRepo.On("GetByID", mock.Anything, int64(1)).Return(loan, nil)
+	paymentRepo.On("GenerateNumber", mock.Anything, mock.Anything).Return("PAY-000001", nil)
+	customerRepo.On("GetByID", mock.Anything, int64(10)).Return(nil, errors.New("not found")).Maybe()
+
+	var entries []*domain.AccountingEntry
+	entryRepo.On("GenerateEntryNumber", mock.Anything).Return("AE-000001", nil).Maybe()
+	entryRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.AccountingEntry")).
+		Run(func(args mock.Arguments) {
+			entry := args.Get(1).(*domain.AccountingEntry)
+			entry.ID = int64(len(entries) + 1)
+			entries = append(entries, entry)
+		}).Return(nil).Maybe()
+	return service, paymentRepo, loanRepo, entryRepo, &entries
+}
+
+// entryAmounts maps each account code of an entry to its signed amount,
+// debits positive and credits negative
+func entryAmounts(entry *domain.AccountingEntry) map[string]float64 {
+	codes := map[int64]string{}
+	for code, account := range paymentAccounts {
+		codes[account.ID] = code
+	}
+	amounts := map[string]float64{}
+	for _, line := range entry.Lines {
+		if line.EntryType == domain.EntryTypeDebit {
+			amounts[codes[line.AccountID]] += line.Amount
+		} else {
+			amounts[codes[line.AccountID]] -= line.Amount
+		}
+	}
+	return amounts
+}
+
+func TestPaymentService_Create_PostsBalancedEntry(t *testing.T) {
+	service, paymentRepo, loanRepo, entryRepo, entries := setupPaymentAccounting(nil)
+	paymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Payment")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.Payment).ID = 7 }).Return(nil)
+	loanRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	entryRepo.On("Post", mock.Anything, int64(1), int64(3)).Return(nil)
+
+	result, err := service.Create(context.Background(), CreatePaymentInput{
+		LoanID: 1, Amount: 150, PaymentMethod: "card", BranchID: 1, CreatedBy: 3,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, *entries, 1)
+	entry := (*entries)[0]
+	assert.NoError(t, entry.Validate())
+	assert.True(t, entry.IsPosted)
+	assert.Equal(t, domain.AccountingReferencePayment, entry.ReferenceType)
+	assert.Equal(t, result.Payment.ID, *entry.ReferenceID)
+	assert.Equal(t, 150.0, entry.TotalDebit)
+	assert.Equal(t, 150.0, entry.TotalCredit)
+	assert.Equal(t, map[string]float64{"1110": 150, "4200": -20, "4100": -100, "1210": -30}, entryAmounts(entry))
+	entryRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_Create_EntryUsesConfiguredAccounts(t *testing.T) {
+	service, paymentRepo, loanRepo, entryRepo, entries := setupPaymentAccounting(map[string]string{
+		SettingPaymentCashAccount: "1120",
+	})
+	paymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	loanRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	entryRepo.On("Post", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := service.Create(context.Background(), CreatePaymentInput{
+		LoanID: 1, Amount: 50, PaymentMethod: "transfer", BranchID: 1, CreatedBy: 3,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, *entries, 1)
+	assert.Equal(t, map[string]float64{"1120": 50, "4200": -20, "4100": -30}, entryAmounts((*entries)[0]))
+}
+
+func TestPaymentService_Create_MissingAccountRejectsPayment(t *testing.T) {
+	service, paymentRepo, loanRepo, _, entries := setupPaymentAccounting(map[string]string{
+		SettingPaymentInterestIncomeAccount: "9999",
+	})
+
+	result, err := service.Create(context.Background(), CreatePaymentInput{
+		LoanID: 1, Amount: 150, PaymentMethod: "card", BranchID: 1, CreatedBy: 3,
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "9999")
+	assert.Nil(t, result)
+	assert.Empty(t, *entries)
+	paymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_Create_UndoesPaymentWhenEntryFails(t *testing.T) {
+	service, paymentRepo, loanRepo, entryRepo, _ := setupPaymentAccounting(nil)
+	paymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	var saved []domain.Loan
+	loanRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Loan")).
+		Run(func(args mock.Arguments) { saved = append(saved, *args.Get(1).(*domain.Loan)) }).Return(nil)
+	entryRepo.On("Post", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down"))
+	var failed *domain.Payment
+	paymentRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Payment")).
+		Run(func(args mock.Arguments) { failed = args.Get(1).(*domain.Payment) }).Return(nil)
+
+	result, err := service.Create(context.Background(), CreatePaymentInput{
+		LoanID: 1, Amount: 150, PaymentMethod: "card", BranchID: 1, CreatedBy: 3,
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Len(t, saved, 2)
+	assert.Equal(t, 770.0, saved[0].PrincipalRemaining)
+	assert.Equal(t, 800.0, saved[1].PrincipalRemaining)
+	assert.Equal(t, 100.0, saved[1].InterestRemaining)
+	assert.Equal(t, 20.0, saved[1].LateFeeRemaining)
+	assert.Equal(t, 0.0, saved[1].AmountPaid)
+	if assert.NotNil(t, failed) {
+		assert.Equal(t, domain.PaymentStatusFailed, failed.Status)
+	}
+}
+
+func TestPaymentService_Create_UndoesPaymentWhenCashMovementFails(t *testing.T) {
+	paymentRepo := new(mocks.MockPaymentRepository)
+	loanRepo := new(mocks.MockLoanRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	cashService := NewCashService(new(mocks.MockCashRegisterRepository), sessionRepo, movementRepo, new(mocks.MockBranchRepository), nil, nil)
+	service := NewPaymentService(paymentRepo, loanRepo, customerRepo, new(mocks.MockItemRepository), nil, cashService, zerolog.Nop())
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 1, CustomerID: 10, Status: domain.LoanStatusActive, PrincipalRemaining: 800, InterestRemaining: 100}
+	session := &domain.CashSession{ID: 3, BranchID: 1, UserID: 7, OpeningAmount: 500, Status: domain.CashSessionStatusOpen}
+	loanRepo.On("GetByID", ctx, int64(1)).Return(loan, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(7)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(3)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(3)).Return(500.0, nil)
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Return(errors.New("db error"))
+	paymentRepo.On("GenerateNumber", ctx, domain.SequenceResetYearly).Return("PAY-000001", nil)
+	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	var saved []domain.Loan
+	loanRepo.On("Update", ctx, mock.AnythingOfType("*domain.Loan")).
+		Run(func(args mock.Arguments) { saved = append(saved, *args.Get(1).(*domain.Loan)) }).Return(nil)
+	var failed *domain.Payment
+	paymentRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payment")).
+		Run(func(args mock.Arguments) { failed = args.Get(1).(*domain.Payment) }).Return(nil)
+
+	result, err := service.Create(ctx, CreatePaymentInput{LoanID: 1, Amount: 150, PaymentMethod: "cash", BranchID: 1, CreatedBy: 7})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to record payment movement")
+	assert.Nil(t, result)
+	assert.Len(t, saved, 2)
+	assert.Equal(t, 750.0, saved[0].PrincipalRemaining)
+	assert.Equal(t, 800.0, saved[1].PrincipalRemaining)
+	assert.Equal(t, 100.0, saved[1].InterestRemaining)
+	if assert.NotNil(t, failed) {
+		assert.Equal(t, domain.PaymentStatusFailed, failed.Status)
+	}
+	customerRepo.AssertNotCalled(t, "UpdateCreditInfo", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_Reverse_PostsOppositeEntry(t *testing.T) {
+	service, paymentRepo, loanRepo, entryRepo, entries := setupPaymentAccounting(nil)
+	payment := &domain.Payment{
+		ID: 7, PaymentNumber: "PAY-000007", LoanID: 1, CustomerID: 10, BranchID: 1, Amount: 150,
+		PrincipalAmount: 30, InterestAmount: 100, LateFeeAmount: 20, Status: domain.PaymentStatusCompleted,
+	}
+	original := &domain.AccountingEntry{
+		ID: 40, BranchID: 1, IsPosted: true, TotalDebit: 150, TotalCredit: 150,
+		Lines: []*domain.AccountingEntryLine{
+			{AccountID: 1, EntryType: domain.EntryTypeDebit, Amount: 150},
+			{AccountID: 5, EntryType: domain.EntryTypeCredit, Amount: 20},
+			{AccountID: 4, EntryType: domain.EntryTypeCredit, Amount: 100},
+			{AccountID: 3, EntryType: domain.EntryTypeCredit, Amount: 30},
+		},
+	}
+	paymentRepo.On("GetByID", mock.Anything, int64(7)).Return(payment, nil)
+	paymentRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	loanRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Loan")).Return(nil)
+	entryRepo.On("ListByReference", mock.Anything, domain.AccountingReferencePayment, int64(7)).
+		Return([]*domain.AccountingEntry{{ID: 40, IsPosted: true}}, nil)
+	entryRepo.On("GetByID", mock.Anything, int64(40)).Return(original, nil)
+	entryRepo.On("Post", mock.Anything, int64(1), int64(3)).Return(nil)
+
+	_, err := service.Reverse(context.Background(), ReversePaymentInput{PaymentID: 7, Reason: "Error de cajero", ReversedBy: 3})
+
+	assert.NoError(t, err)
+	assert.Len(t, *entries, 1)
+	reversal := (*entries)[0]
+	assert.NoError(t, reversal.Validate())
+	assert.Equal(t, domain.AccountingReferencePaymentReversal, reversal.ReferenceType)
+	assert.Equal(t, map[string]float64{"1110": -150, "4200": 20, "4100": 100, "1210": 30}, entryAmounts(reversal))
+}
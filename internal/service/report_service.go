@@ -1,7 +1,17 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
 	"time"
 
 	"pawnshop/internal/domain"
@@ -16,7 +26,19 @@ type ReportService struct {
 	saleRepo     repository.SaleRepository
 	customerRepo repository.CustomerRepository
 	itemRepo     repository.ItemRepository
+	documentRepo repository.DocumentRepository
 	pdfGenerator *pdf.Generator
+	storage      StorageService
+
+	dailyBalanceRepo repository.DailyBalanceRepository
+	branchRepo       repository.BranchRepository
+	loanSnapshotRepo repository.LoanBalanceSnapshotRepository
+	settingRepo      repository.SettingRepository
+	userActivityRepo repository.UserActivityRepository
+	userRepo         repository.UserRepository
+	writeOffRepo     repository.LoanWriteOffRepository
+	moneyFormat      *MoneyFormatService
+	storageQuota     *StorageQuotaService
 }
 
 // NewReportService creates a new ReportService
@@ -26,7 +48,9 @@ func NewReportService(
 	saleRepo repository.SaleRepository,
 	customerRepo repository.CustomerRepository,
 	itemRepo repository.ItemRepository,
+	documentRepo repository.DocumentRepository,
 	pdfGenerator *pdf.Generator,
+	storage StorageService,
 ) *ReportService {
 	return &ReportService{
 		loanRepo:     loanRepo,
@@ -34,10 +58,80 @@ func NewReportService(
 		saleRepo:     saleRepo,
 		customerRepo: customerRepo,
 		itemRepo:     itemRepo,
+		documentRepo: documentRepo,
 		pdfGenerator: pdfGenerator,
+		storage:      storage,
 	}
 }
 
+// SetMoneyFormat makes generated documents write amounts in the currency of
+// the branch they belong to
+func (s *ReportService) SetMoneyFormat(moneyFormat *MoneyFormatService) {
+	s.moneyFormat = moneyFormat
+}
+
+// generatorFor returns the PDF generator for documents of a branch
+func (s *ReportService) generatorFor(ctx context.Context, branchID int64) *pdf.Generator {
+	if s.moneyFormat == nil {
+		return s.pdfGenerator
+	}
+	return s.pdfGenerator.WithMoneyFormat(s.moneyFormat.FormatFor(ctx, branchID))
+}
+
+// SetDailyBalances enables recording and backfilling the daily balances of the branches
+func (s *ReportService) SetDailyBalances(dailyBalanceRepo repository.DailyBalanceRepository, branchRepo repository.BranchRepository) {
+	s.dailyBalanceRepo = dailyBalanceRepo
+	s.branchRepo = branchRepo
+}
+
+// SetLoanSnapshots enables snapshotting open loan balances and reading past
+// reports from the snapshots. The setting repository tells which branches
+// take snapshots.
+func (s *ReportService) SetLoanSnapshots(loanSnapshotRepo repository.LoanBalanceSnapshotRepository, settingRepo repository.SettingRepository) {
+	s.loanSnapshotRepo = loanSnapshotRepo
+	s.settingRepo = settingRepo
+}
+
+// SetUserPerformance enables the per-user performance report. The setting
+// repository supplies each branch's commission rates.
+func (s *ReportService) SetUserPerformance(userActivityRepo repository.UserActivityRepository, userRepo repository.UserRepository, settingRepo repository.SettingRepository) {
+	s.userActivityRepo = userActivityRepo
+	s.userRepo = userRepo
+	s.settingRepo = settingRepo
+}
+
+// SetWriteOffs enables the report of written-off loans
+func (s *ReportService) SetWriteOffs(writeOffRepo repository.LoanWriteOffRepository) {
+	s.writeOffRepo = writeOffRepo
+}
+
+// SetStorageQuota counts stored documents against their branch's storage quota
+func (s *ReportService) SetStorageQuota(storageQuota *StorageQuotaService) {
+	s.storageQuota = storageQuota
+}
+
+// saveDocument stores a generated document of a branch's entity, rejecting it
+// when it does not fit in the branch's storage quota
+func (s *ReportService) saveDocument(ctx context.Context, branchID int64, entityType string, entityID int64, data []byte, filename, category string, createdBy int64) (*ImageInfo, error) {
+	if s.storageQuota != nil {
+		if err := s.storageQuota.CheckUpload(ctx, branchID, int64(len(data))); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := s.storage.SaveDocument(ctx, data, filename, category)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storageQuota != nil {
+		if err := s.storageQuota.RecordUpload(ctx, branchID, entityType, entityID, file, createdBy); err != nil {
+			return nil, fmt.Errorf("failed to record stored document: %w", err)
+		}
+	}
+	return file, nil
+}
+
 // DashboardStats represents dashboard statistics
 type DashboardStats struct {
 	// Loan stats
@@ -189,27 +283,33 @@ type LoanReport struct {
 	ByStatus         map[string]int    `json:"by_status"`
 	ByStatusAmount   map[string]float64 `json:"by_status_amount"`
 	RecentLoans      []domain.Loan     `json:"recent_loans,omitempty"`
+	// AsOf is the past day the outstanding balance was read from snapshots for
+	AsOf *domain.Date `json:"as_of,omitempty"`
 }
 
-// GetLoanReport generates a loan report
-func (s *ReportService) GetLoanReport(ctx context.Context, branchID int64, dateFrom, dateTo string) (*LoanReport, error) {
+// GetLoanReport generates a loan report. For a past asOf day the outstanding
+// balance is read from that day's loan snapshots instead of the loans as they
+// are now.
+func (s *ReportService) GetLoanReport(ctx context.Context, branchID int64, dateFrom, dateTo string, asOf *domain.Date) (*LoanReport, error) {
 	report := &LoanReport{
 		ByStatus:       make(map[string]int),
 		ByStatusAmount: make(map[string]float64),
 	}
 
-	params := repository.LoanListParams{
-		BranchID: branchID,
-		DueAfter: &dateFrom,
-		DueBefore: &dateTo,
-		PaginationParams: repository.PaginationParams{
-			PerPage: 10000,
-			OrderBy: "created_at",
-			Order:   "desc",
-		},
+	snapshots, err := s.pastSnapshots(ctx, branchID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	var balances map[int64]float64
+	if snapshots != nil {
+		report.AsOf = asOf
+		balances = make(map[int64]float64, len(snapshots))
+		for _, snapshot := range snapshots {
+			balances[snapshot.LoanID] = snapshot.Balance
+		}
 	}
 
-	result, err := s.loanRepo.List(ctx, params)
+	result, err := s.reportLoans(ctx, branchID, dateFrom, dateTo)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +319,10 @@ func (s *ReportService) GetLoanReport(ctx context.Context, branchID int64, dateF
 	for _, loan := range result.Data {
 		report.TotalAmount += loan.LoanAmount
 		report.TotalInterest += loan.InterestAmount
-		if loan.Status == domain.LoanStatusActive || loan.Status == domain.LoanStatusOverdue {
+		if balances != nil {
+			// Loans without a snapshot were not open that day
+			report.TotalOutstanding += balances[loan.ID]
+		} else if loan.Status == domain.LoanStatusActive || loan.Status == domain.LoanStatusOverdue {
 			report.TotalOutstanding += loan.RemainingBalance()
 		}
 
@@ -238,6 +341,107 @@ func (s *ReportService) GetLoanReport(ctx context.Context, branchID int64, dateF
 	return report, nil
 }
 
+// reportLoans lists the loans of a loan report, those due between two dates,
+// newest first
+func (s *ReportService) reportLoans(ctx context.Context, branchID int64, dateFrom, dateTo string) (*repository.PaginatedResult[domain.Loan], error) {
+	params := repository.LoanListParams{
+		BranchID:  branchID,
+		DueAfter:  &dateFrom,
+		DueBefore: &dateTo,
+		PaginationParams: repository.PaginationParams{
+			PerPage: 10000,
+			OrderBy: "created_at",
+			Order:   "desc",
+		},
+	}
+	return s.loanRepo.List(ctx, params)
+}
+
+// ExportLoanReportCSV writes the loans of the loan report as CSV, one row per
+// loan with plain amounts so it can be pulled into a spreadsheet
+func (s *ReportService) ExportLoanReportCSV(ctx context.Context, branchID int64, dateFrom, dateTo string) ([]byte, error) {
+	result, err := s.reportLoans(ctx, branchID, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{
+		"loan_number", "customer_name", "item_name", "loan_amount", "interest_amount",
+		"total_amount", "status", "due_date",
+	})
+	for i := range result.Data {
+		loan := &result.Data[i]
+		customerName, itemName := "", ""
+		if loan.Customer != nil {
+			customerName = loan.Customer.FullName()
+		}
+		if loan.Item != nil {
+			itemName = loan.Item.Name
+		}
+		w.Write([]string{
+			loan.LoanNumber,
+			customerName,
+			itemName,
+			strconv.FormatFloat(loan.LoanAmount, 'f', 2, 64),
+			strconv.FormatFloat(loan.InterestAmount, 'f', 2, 64),
+			strconv.FormatFloat(loan.TotalAmount, 'f', 2, 64),
+			string(loan.Status),
+			loan.DueDate.String(),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write loan report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ErrWriteOffReportUnavailable is returned when the report is not set up
+var ErrWriteOffReportUnavailable = errors.New("write-off report is not enabled")
+
+// WriteOffReport lists the loans written off in a period and the balance lost
+type WriteOffReport struct {
+	TotalLoans     int                    `json:"total_loans"`
+	TotalAmount    float64                `json:"total_amount"`
+	TotalPrincipal float64                `json:"total_principal"`
+	TotalInterest  float64                `json:"total_interest"`
+	TotalLateFees  float64                `json:"total_late_fees"`
+	WriteOffs      []*domain.LoanWriteOff `json:"write_offs"`
+}
+
+// GetWriteOffReport generates the report of loans written off between two dates
+func (s *ReportService) GetWriteOffReport(ctx context.Context, branchID int64, dateFrom, dateTo string) (*WriteOffReport, error) {
+	if s.writeOffRepo == nil {
+		return nil, ErrWriteOffReportUnavailable
+	}
+
+	writeOffs, err := s.writeOffRepo.List(ctx, repository.LoanWriteOffListParams{
+		BranchID: branchID,
+		DateFrom: &dateFrom,
+		DateTo:   &dateTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WriteOffReport{TotalLoans: len(writeOffs), WriteOffs: writeOffs}
+	for _, writeOff := range writeOffs {
+		report.TotalAmount += writeOff.Amount
+		report.TotalPrincipal += writeOff.Principal
+		report.TotalInterest += writeOff.Interest
+		report.TotalLateFees += writeOff.LateFees
+	}
+	report.TotalAmount = roundCents(report.TotalAmount)
+	report.TotalPrincipal = roundCents(report.TotalPrincipal)
+	report.TotalInterest = roundCents(report.TotalInterest)
+	report.TotalLateFees = roundCents(report.TotalLateFees)
+
+	return report, nil
+}
+
 // PaymentReport represents payment report data
 type PaymentReport struct {
 	TotalPayments    int                    `json:"total_payments"`
@@ -257,18 +461,7 @@ func (s *ReportService) GetPaymentReport(ctx context.Context, branchID int64, da
 		ByMethodAmount: make(map[string]float64),
 	}
 
-	params := repository.PaymentListParams{
-		BranchID: branchID,
-		DateFrom: &dateFrom,
-		DateTo:   &dateTo,
-		PaginationParams: repository.PaginationParams{
-			PerPage: 10000,
-			OrderBy: "payment_date",
-			Order:   "desc",
-		},
-	}
-
-	result, err := s.paymentRepo.List(ctx, params)
+	result, err := s.reportPayments(ctx, branchID, dateFrom, dateTo)
 	if err != nil {
 		return nil, err
 	}
@@ -370,16 +563,43 @@ type OverdueReport struct {
 	OverdueLoans     []domain.Loan `json:"overdue_loans"`
 	ApproachingDue   []domain.Loan `json:"approaching_due"`
 	AboutToDefault   []domain.Loan `json:"about_to_default"`
+	// AsOf is the past day the report was read from snapshots for, in which
+	// case the overdue loans are listed as OverdueSnapshots instead
+	AsOf             *domain.Date                  `json:"as_of,omitempty"`
+	OverdueSnapshots []*domain.LoanBalanceSnapshot `json:"overdue_snapshots,omitempty"`
 }
 
-// GetOverdueReport generates an overdue loans report
-func (s *ReportService) GetOverdueReport(ctx context.Context, branchID int64) (*OverdueReport, error) {
+// GetOverdueReport generates an overdue loans report. For a past asOf day it
+// is built from that day's loan snapshots, and the loans approaching their due
+// date or default, which look ahead from today, are left empty.
+func (s *ReportService) GetOverdueReport(ctx context.Context, branchID int64, asOf *domain.Date) (*OverdueReport, error) {
 	report := &OverdueReport{
 		OverdueLoans:   []domain.Loan{},
 		ApproachingDue: []domain.Loan{},
 		AboutToDefault: []domain.Loan{},
 	}
 
+	snapshots, err := s.pastSnapshots(ctx, branchID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if snapshots != nil {
+		report.AsOf = asOf
+		report.OverdueSnapshots = []*domain.LoanBalanceSnapshot{}
+		for _, snapshot := range snapshots {
+			if snapshot.Status != domain.LoanStatusOverdue {
+				continue
+			}
+			report.TotalOverdue++
+			report.TotalAmount += snapshot.Balance
+			report.TotalLateFees += snapshot.LateFeeRemaining
+			report.OverdueSnapshots = append(report.OverdueSnapshots, snapshot)
+		}
+		report.TotalAmount = roundCents(report.TotalAmount)
+		report.TotalLateFees = roundCents(report.TotalLateFees)
+		return report, nil
+	}
+
 	// Get overdue loans
 	overdueLoans, err := s.loanRepo.GetOverdueLoans(ctx, branchID)
 	if err != nil {
@@ -505,7 +725,7 @@ func (s *ReportService) GenerateDailyReportPDF(ctx context.Context, branchID int
 	dailyReport.TotalIncome = dailyReport.PaymentsAmount + dailyReport.SalesAmount
 	dailyReport.TotalExpenses = dailyReport.NewLoansAmount
 
-	return s.pdfGenerator.GenerateDailyReport(dailyReport)
+	return s.generatorFor(ctx, branchID).GenerateDailyReport(dailyReport)
 }
 
 // GenerateLoanContractPDF generates a loan contract PDF
@@ -525,7 +745,56 @@ func (s *ReportService) GenerateLoanContractPDF(ctx context.Context, loanID int6
 		return nil, err
 	}
 
-	return s.pdfGenerator.GenerateLoanContract(loan, customer, item)
+	return s.generatorFor(ctx, loan.BranchID).GenerateLoanContract(loan, customer, item)
+}
+
+// StoreLoanContract generates the loan contract, saves it to storage and references it
+// on the loan together with the SHA-256 hash of the stored file
+func (s *ReportService) StoreLoanContract(ctx context.Context, loanID int64, createdBy int64) (*domain.Document, error) {
+	if s.documentRepo == nil || s.storage == nil {
+		return nil, errors.New("document storage is not configured")
+	}
+
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.GenerateLoanContractPDF(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate contract: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	file, err := s.saveDocument(ctx, loan.BranchID, "loan", loan.ID, data, loan.LoanNumber+".pdf", "contracts", createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store contract: %w", err)
+	}
+
+	doc := &domain.Document{
+		BranchID:       loan.BranchID,
+		DocumentType:   domain.DocumentTypeLoanContract,
+		DocumentNumber: loan.LoanNumber,
+		ReferenceType:  "loan",
+		ReferenceID:    loan.ID,
+		FilePath:       file.ID,
+		FileURL:        file.URL,
+		FileSize:       int(file.Size),
+		MimeType:       file.MimeType,
+		ContentHash:    hash,
+		CreatedBy:      createdBy,
+	}
+	if err := s.documentRepo.Create(ctx, doc); err != nil {
+		return nil, fmt.Errorf("failed to save contract document: %w", err)
+	}
+
+	if err := s.loanRepo.SetContract(ctx, loan.ID, doc.ID, doc.FileURL, doc.ContentHash); err != nil {
+		return nil, fmt.Errorf("failed to reference contract on loan: %w", err)
+	}
+
+	return doc, nil
 }
 
 // GeneratePaymentReceiptPDF generates a payment receipt PDF
@@ -545,7 +814,7 @@ func (s *ReportService) GeneratePaymentReceiptPDF(ctx context.Context, paymentID
 		return nil, err
 	}
 
-	return s.pdfGenerator.GeneratePaymentReceipt(payment, loan, customer)
+	return s.generatorFor(ctx, payment.BranchID).GeneratePaymentReceipt(payment, loan, customer)
 }
 
 // GenerateSaleReceiptPDF generates a sale receipt PDF
@@ -565,5 +834,775 @@ func (s *ReportService) GenerateSaleReceiptPDF(ctx context.Context, saleID int64
 		customer, _ = s.customerRepo.GetByID(ctx, *sale.CustomerID)
 	}
 
-	return s.pdfGenerator.GenerateSaleReceipt(sale, item, customer)
+	return s.generatorFor(ctx, sale.BranchID).GenerateSaleReceipt(sale, item, customer)
+}
+
+// GetCustomerStatement collects a customer's account activity from from to to,
+// both inclusive: the loans opened in the period or still open at its end and
+// the completed payments made in it
+func (s *ReportService) GetCustomerStatement(ctx context.Context, customerID int64, from, to domain.Date) (*pdf.CustomerStatement, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &pdf.CustomerStatement{Customer: customer, From: from.Time, To: to.Time}
+
+	loans, err := s.loanRepo.List(ctx, repository.LoanListParams{
+		CustomerID:       &customerID,
+		PaginationParams: repository.PaginationParams{PerPage: 10000},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range loans.Data {
+		loan := &loans.Data[i]
+		if loan.StartDate.After(to.Time) {
+			continue
+		}
+		if !loan.IsOpen() && loan.StartDate.Before(from.Time) {
+			continue
+		}
+		statement.Loans = append(statement.Loans, loan)
+		if loan.IsOpen() {
+			statement.TotalBalance += loan.RemainingBalance()
+		}
+	}
+
+	dateFrom := from.String()
+	dateTo := to.String() + " 23:59:59"
+	payments, err := s.paymentRepo.List(ctx, repository.PaymentListParams{
+		CustomerID: &customerID,
+		DateFrom:   &dateFrom,
+		DateTo:     &dateTo,
+		PaginationParams: repository.PaginationParams{
+			PerPage: 10000,
+			OrderBy: "payment_date",
+			Order:   "asc",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range payments.Data {
+		payment := &payments.Data[i]
+		if payment.Status != domain.PaymentStatusCompleted {
+			continue
+		}
+		statement.Payments = append(statement.Payments, payment)
+		statement.TotalPaid += payment.Amount
+	}
+
+	return statement, nil
+}
+
+// GenerateCustomerStatementPDF generates a customer's account statement PDF for a period
+func (s *ReportService) GenerateCustomerStatementPDF(ctx context.Context, customerID int64, from, to domain.Date) ([]byte, error) {
+	statement, err := s.GetCustomerStatement(ctx, customerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.generatorFor(ctx, statement.Customer.BranchID).GenerateCustomerStatement(statement)
+}
+
+// StoreCustomerStatement renders a statement and saves it to storage as a
+// document of the customer, numbered after the customer and the period's month
+func (s *ReportService) StoreCustomerStatement(ctx context.Context, statement *pdf.CustomerStatement) (*domain.Document, error) {
+	if s.documentRepo == nil || s.storage == nil {
+		return nil, errors.New("document storage is not configured")
+	}
+
+	data, err := s.generatorFor(ctx, statement.Customer.BranchID).GenerateCustomerStatement(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate statement: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	number := fmt.Sprintf("EC-%d-%s", statement.Customer.ID, statement.To.Format("200601"))
+
+	file, err := s.saveDocument(ctx, statement.Customer.BranchID, "customer", statement.Customer.ID, data, number+".pdf", "statements", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store statement: %w", err)
+	}
+
+	doc := &domain.Document{
+		BranchID:       statement.Customer.BranchID,
+		DocumentType:   domain.DocumentTypeCustomerStatement,
+		DocumentNumber: number,
+		ReferenceType:  "customer",
+		ReferenceID:    statement.Customer.ID,
+		FilePath:       file.ID,
+		FileURL:        file.URL,
+		FileSize:       int(file.Size),
+		MimeType:       file.MimeType,
+		ContentHash:    hex.EncodeToString(sum[:]),
+	}
+	if err := s.documentRepo.Create(ctx, doc); err != nil {
+		return nil, fmt.Errorf("failed to save statement document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// LoanDocumentBundle holds what is needed to export every document of a loan
+// as a ZIP archive. The files themselves are produced only while the archive
+// is written, so it is never held in memory as a whole.
+type LoanDocumentBundle struct {
+	Filename string
+
+	loan      *domain.Loan
+	customer  *domain.Customer
+	item      *domain.Item
+	payments  []*domain.Payment
+	documents []*domain.Document
+	generator *pdf.Generator
+	storage   StorageService
+}
+
+// WriteZip streams the bundle to w as a ZIP archive: contract.pdf,
+// receipt-<payment number>.pdf and attachments/<stored file name>. A failure
+// part way leaves a truncated archive, so w should not be reused.
+func (b *LoanDocumentBundle) WriteZip(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	contract, err := b.generator.GenerateLoanContract(b.loan, b.customer, b.item)
+	if err != nil {
+		return fmt.Errorf("failed to generate contract: %w", err)
+	}
+	if err := writeZipFile(zw, "contract.pdf", bytes.NewReader(contract)); err != nil {
+		return err
+	}
+
+	for _, payment := range b.payments {
+		receipt, err := b.generator.GeneratePaymentReceipt(payment, b.loan, b.customer)
+		if err != nil {
+			return fmt.Errorf("failed to generate receipt %s: %w", payment.PaymentNumber, err)
+		}
+		if err := writeZipFile(zw, "receipt-"+payment.PaymentNumber+".pdf", bytes.NewReader(receipt)); err != nil {
+			return err
+		}
+	}
+
+	for _, doc := range b.documents {
+		if err := b.writeStoredDocument(ctx, zw, doc); err != nil {
+			return fmt.Errorf("failed to write document %d: %w", doc.ID, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeStoredDocument copies a stored document into the archive
+func (b *LoanDocumentBundle) writeStoredDocument(ctx context.Context, zw *zip.Writer, doc *domain.Document) error {
+	file, _, err := b.storage.GetDocument(ctx, doc.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeZipFile(zw, "attachments/"+path.Base(doc.FilePath), file)
+}
+
+// writeZipFile adds a file to the archive
+func writeZipFile(zw *zip.Writer, name string, r io.Reader) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+// GenerateLoanDocumentBundle loads a loan with its payments and the other
+// documents stored for it. The contract and receipts are regenerated from the
+// current records when the bundle is written.
+func (s *ReportService) GenerateLoanDocumentBundle(ctx context.Context, loanID int64) (*LoanDocumentBundle, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, loan.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, loan.ItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.paymentRepo.ListByLoan(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	bundle := &LoanDocumentBundle{
+		Filename:  loan.LoanNumber + "-documents.zip",
+		loan:      loan,
+		customer:  customer,
+		item:      item,
+		payments:  payments,
+		generator: s.generatorFor(ctx, loan.BranchID),
+		storage:   s.storage,
+	}
+
+	if s.documentRepo == nil || s.storage == nil {
+		return bundle, nil
+	}
+
+	docs, err := s.documentRepo.ListByReference(ctx, "loan", loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan documents: %w", err)
+	}
+	for _, doc := range docs {
+		// The contract is regenerated from the current loan
+		if doc.DocumentType == domain.DocumentTypeLoanContract || doc.FilePath == "" {
+			continue
+		}
+		bundle.documents = append(bundle.documents, doc)
+	}
+
+	return bundle, nil
+}
+
+// RegenerateDocumentOptions controls how a document is regenerated
+type RegenerateDocumentOptions struct {
+	// Overwrite replaces the stored copy of the document with the fresh one
+	Overwrite bool
+	CreatedBy int64
+}
+
+// RegeneratedDocument is a freshly generated copy of a document
+type RegeneratedDocument struct {
+	Data     []byte
+	Filename string
+	// Document is the stored copy, only set when it was overwritten
+	Document *domain.Document
+}
+
+// RegenerateDocument generates a fresh copy of a document from the current state
+// of the record it belongs to. With Overwrite set the new file also replaces the
+// stored copy, which is created when the document was never stored before.
+func (s *ReportService) RegenerateDocument(ctx context.Context, docType domain.DocumentType, referenceID int64, opts RegenerateDocumentOptions) (*RegeneratedDocument, error) {
+	var (
+		data     []byte
+		doc      *domain.Document
+		category string
+		err      error
+	)
+
+	switch docType {
+	case domain.DocumentTypeLoanContract:
+		loan, lerr := s.loanRepo.GetByID(ctx, referenceID)
+		if lerr != nil {
+			return nil, lerr
+		}
+		data, err = s.GenerateLoanContractPDF(ctx, referenceID)
+		doc = &domain.Document{BranchID: loan.BranchID, DocumentNumber: loan.LoanNumber, ReferenceType: "loan"}
+		category = "contracts"
+	case domain.DocumentTypePaymentReceipt:
+		payment, perr := s.paymentRepo.GetByID(ctx, referenceID)
+		if perr != nil {
+			return nil, perr
+		}
+		data, err = s.GeneratePaymentReceiptPDF(ctx, referenceID)
+		doc = &domain.Document{BranchID: payment.BranchID, DocumentNumber: payment.PaymentNumber, ReferenceType: "payment"}
+		category = "receipts"
+	case domain.DocumentTypeSaleReceipt:
+		sale, serr := s.saleRepo.GetByID(ctx, referenceID)
+		if serr != nil {
+			return nil, serr
+		}
+		data, err = s.GenerateSaleReceiptPDF(ctx, referenceID)
+		doc = &domain.Document{BranchID: sale.BranchID, DocumentNumber: sale.SaleNumber, ReferenceType: "sale"}
+		category = "receipts"
+	default:
+		return nil, fmt.Errorf("%w: document type %q cannot be regenerated", ErrInvalidInput, docType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate document: %w", err)
+	}
+
+	doc.DocumentType = docType
+	doc.ReferenceID = referenceID
+	result := &RegeneratedDocument{
+		Data:     data,
+		Filename: doc.DocumentNumber + ".pdf",
+	}
+
+	if !opts.Overwrite {
+		return result, nil
+	}
+
+	stored, err := s.overwriteStoredDocument(ctx, doc, data, category, opts.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	result.Document = stored
+
+	return result, nil
+}
+
+// overwriteStoredDocument saves data as the latest stored copy of doc
+func (s *ReportService) overwriteStoredDocument(ctx context.Context, doc *domain.Document, data []byte, category string, createdBy int64) (*domain.Document, error) {
+	if s.documentRepo == nil || s.storage == nil {
+		return nil, errors.New("document storage is not configured")
+	}
+
+	existing, err := s.documentRepo.ListByReference(ctx, doc.ReferenceType, doc.ReferenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up stored document: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	file, err := s.saveDocument(ctx, doc.BranchID, doc.ReferenceType, doc.ReferenceID, data, doc.DocumentNumber+".pdf", category, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	// Documents are listed newest first, so the first match is the current copy
+	for _, candidate := range existing {
+		if candidate.DocumentType == doc.DocumentType {
+			doc = candidate
+			break
+		}
+	}
+	replacedPath, replacedURL := doc.FilePath, doc.FileURL
+	doc.FilePath = file.ID
+	doc.FileURL = file.URL
+	doc.FileSize = int(file.Size)
+	doc.MimeType = file.MimeType
+	doc.ContentHash = hex.EncodeToString(sum[:])
+
+	if doc.ID != 0 {
+		err = s.documentRepo.UpdateFile(ctx, doc)
+	} else {
+		doc.CreatedBy = createdBy
+		err = s.documentRepo.Create(ctx, doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save document: %w", err)
+	}
+
+	if doc.DocumentType == domain.DocumentTypeLoanContract {
+		if err := s.loanRepo.SetContract(ctx, doc.ReferenceID, doc.ID, doc.FileURL, doc.ContentHash); err != nil {
+			return nil, fmt.Errorf("failed to reference contract on loan: %w", err)
+		}
+	}
+
+	// The replaced file is no longer referenced. Deleting it is best effort,
+	// as the document already points at the new file.
+	if replacedPath != "" && replacedPath != file.ID {
+		_ = s.storage.DeleteDocument(ctx, replacedPath)
+		if s.storageQuota != nil {
+			_ = s.storageQuota.RecordDeletion(ctx, replacedURL)
+		}
+	}
+
+	return doc, nil
+}
+
+// Daily balance backfill limits
+const (
+	dailyBalanceBackfillBatchDays = 31
+	dailyBalanceBackfillMaxDays   = 366
+)
+
+// dailyBalanceBranchPageSize is how many branches RecordDailyBalances lists at a time
+const dailyBalanceBranchPageSize = 100
+
+var (
+	ErrDailyBalancesUnavailable = errors.New("daily balances are not enabled")
+	ErrBackfillRangeTooLong     = fmt.Errorf("backfill range cannot exceed %d days", dailyBalanceBackfillMaxDays)
+)
+
+// DailyBalanceBackfillResult summarizes a daily balance backfill
+type DailyBalanceBackfillResult struct {
+	BranchID      int64       `json:"branch_id"`
+	From          domain.Date `json:"from"`
+	To            domain.Date `json:"to"`
+	DaysProcessed int         `json:"days_processed"`
+}
+
+// RecordDailyBalance computes the balance of a branch for a day from the
+// transactional tables and stores it, replacing any balance already recorded
+func (s *ReportService) RecordDailyBalance(ctx context.Context, branchID int64, date domain.Date) (*domain.DailyBalance, error) {
+	if s.dailyBalanceRepo == nil {
+		return nil, ErrDailyBalancesUnavailable
+	}
+
+	opening, err := s.previousCashClosing(ctx, branchID, date)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.computeDailyBalance(ctx, branchID, date, opening)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.dailyBalanceRepo.Upsert(ctx, balance); err != nil {
+		return nil, fmt.Errorf("failed to store daily balance: %w", err)
+	}
+	return balance, nil
+}
+
+// RecordDailyBalances records the balance of every branch for a day. It keeps
+// going when a branch fails and returns the number of balances recorded.
+func (s *ReportService) RecordDailyBalances(ctx context.Context, date domain.Date) (int, error) {
+	if s.dailyBalanceRepo == nil || s.branchRepo == nil {
+		return 0, nil
+	}
+
+	recorded := 0
+	var errs []error
+	for page := 1; ; page++ {
+		branches, err := s.branchRepo.List(ctx, repository.PaginationParams{Page: page, PerPage: dailyBalanceBranchPageSize})
+		if err != nil {
+			return recorded, errors.Join(append(errs, fmt.Errorf("failed to list branches: %w", err))...)
+		}
+		for _, branch := range branches.Data {
+			if _, err := s.RecordDailyBalance(ctx, branch.ID, date); err != nil {
+				errs = append(errs, fmt.Errorf("branch %d: %w", branch.ID, err))
+				continue
+			}
+			recorded++
+		}
+		if page >= branches.TotalPages {
+			break
+		}
+	}
+	return recorded, errors.Join(errs...)
+}
+
+// BackfillDailyBalances recomputes the balances of a branch for every day from
+// from to to and stores them, replacing what was recorded. Days are computed in
+// order so each one opens with the closing cash of the day before, exactly as
+// the nightly job would have, and are stored in batches of one transaction each.
+// Running it again over the same range gives the same result.
+func (s *ReportService) BackfillDailyBalances(ctx context.Context, branchID int64, from, to domain.Date) (*DailyBalanceBackfillResult, error) {
+	if s.dailyBalanceRepo == nil {
+		return nil, ErrDailyBalancesUnavailable
+	}
+	if to.Before(from.Time) {
+		return nil, ErrInvalidDateRange
+	}
+	if days := int(to.Sub(from.Time).Hours()/24) + 1; days > dailyBalanceBackfillMaxDays {
+		return nil, ErrBackfillRangeTooLong
+	}
+
+	opening, err := s.previousCashClosing(ctx, branchID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DailyBalanceBackfillResult{BranchID: branchID, From: from, To: to}
+	batch := make([]*domain.DailyBalance, 0, dailyBalanceBackfillBatchDays)
+	for day := from; !day.After(to.Time); day = domain.DateFromTime(day.AddDate(0, 0, 1)) {
+		balance, err := s.computeDailyBalance(ctx, branchID, day, opening)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, balance)
+		opening = balance.CashClosing
+
+		if len(batch) == dailyBalanceBackfillBatchDays || day.Equal(to.Time) {
+			if err := s.dailyBalanceRepo.UpsertBatch(ctx, batch); err != nil {
+				return nil, fmt.Errorf("failed to store daily balances: %w", err)
+			}
+			result.DaysProcessed += len(batch)
+			batch = make([]*domain.DailyBalance, 0, dailyBalanceBackfillBatchDays)
+		}
+	}
+
+	return result, nil
+}
+
+// computeDailyBalance builds the balance of a branch for a day opening with the given cash
+func (s *ReportService) computeDailyBalance(ctx context.Context, branchID int64, date domain.Date, cashOpening float64) (*domain.DailyBalance, error) {
+	activity, err := s.dailyBalanceRepo.GetDailyActivity(ctx, branchID, date.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read activity for %s: %w", date, err)
+	}
+
+	balance := &domain.DailyBalance{
+		BranchID:            branchID,
+		BalanceDate:         date.Time,
+		LoanDisbursements:   activity.LoanDisbursements,
+		InterestIncome:      activity.InterestCollected,
+		LateFeeIncome:       activity.LateFeesCollected,
+		SalesIncome:         activity.SalesIncome,
+		OperationalExpenses: activity.Expenses,
+		Refunds:             activity.Refunds,
+		CashOpening:         cashOpening,
+		TotalLoansActive:    activity.ActiveLoansAmount,
+		TotalLoansCount:     activity.ActiveLoansCount,
+	}
+	// Disbursements move cash but are not an expense
+	balance.NetIncome = roundCents(balance.TotalIncome() - balance.OperationalExpenses - balance.Refunds - balance.OtherExpenses)
+	balance.CashClosing = roundCents(cashOpening + activity.PaymentsReceived + activity.SalesIncome -
+		activity.LoanDisbursements - activity.Expenses - activity.Refunds)
+
+	return balance, nil
+}
+
+// previousCashClosing returns the closing cash recorded for the day before date, 0 when none was
+func (s *ReportService) previousCashClosing(ctx context.Context, branchID int64, date domain.Date) (float64, error) {
+	previous, err := s.dailyBalanceRepo.GetByBranchAndDate(ctx, branchID, date.AddDate(0, 0, -1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read previous daily balance: %w", err)
+	}
+	if previous == nil {
+		return 0, nil
+	}
+	return previous.CashClosing, nil
+}
+
+// SettingLoanBalanceSnapshotsEnabled enables the nightly snapshot of open loan balances of a branch
+const SettingLoanBalanceSnapshotsEnabled = "loan_balance_snapshots_enabled"
+
+// loanSnapshotBatchSize is how many snapshots are stored per transaction
+const loanSnapshotBatchSize = 500
+
+var (
+	ErrLoanSnapshotsUnavailable = errors.New("loan balance snapshots are not enabled")
+	ErrLoanSnapshotNotFound     = errors.New("no loan balance snapshot found for that date")
+)
+
+// LoanBalanceReport is the outstanding balance of the open loans at the end of
+// a day. Past days are read from the snapshots taken that day.
+type LoanBalanceReport struct {
+	AsOf                 domain.Date                   `json:"as_of"`
+	FromSnapshot         bool                          `json:"from_snapshot"`
+	TotalLoans           int                           `json:"total_loans"`
+	TotalPrincipal       float64                       `json:"total_principal"`
+	TotalAccruedInterest float64                       `json:"total_accrued_interest"`
+	TotalLateFees        float64                       `json:"total_late_fees"`
+	TotalBalance         float64                       `json:"total_balance"`
+	ByStatus             map[string]int                `json:"by_status"`
+	Loans                []*domain.LoanBalanceSnapshot `json:"loans"`
+}
+
+// GetLoanBalanceReport reports the balances of a branch's open loans. Without
+// asOf, or for today, they are computed from the loans; for a past day they are
+// read from that day's snapshots so the figures never change afterwards.
+func (s *ReportService) GetLoanBalanceReport(ctx context.Context, branchID int64, asOf *domain.Date) (*LoanBalanceReport, error) {
+	snapshots, err := s.pastSnapshots(ctx, branchID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	fromSnapshot := snapshots != nil
+	if !fromSnapshot {
+		today := domain.Today()
+		asOf = &today
+		snapshots, err = s.openLoanSnapshots(ctx, branchID, today)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &LoanBalanceReport{
+		AsOf:         *asOf,
+		FromSnapshot: fromSnapshot,
+		ByStatus:     make(map[string]int),
+		Loans:        snapshots,
+	}
+	for _, snapshot := range snapshots {
+		report.TotalLoans++
+		report.TotalPrincipal += snapshot.PrincipalRemaining
+		report.TotalAccruedInterest += snapshot.AccruedInterest
+		report.TotalLateFees += snapshot.LateFeeRemaining
+		report.TotalBalance += snapshot.Balance
+		report.ByStatus[string(snapshot.Status)]++
+	}
+	report.TotalPrincipal = roundCents(report.TotalPrincipal)
+	report.TotalAccruedInterest = roundCents(report.TotalAccruedInterest)
+	report.TotalLateFees = roundCents(report.TotalLateFees)
+	report.TotalBalance = roundCents(report.TotalBalance)
+
+	return report, nil
+}
+
+// pastSnapshots returns the loan snapshots a report as of asOf is read from.
+// It returns nil when asOf is not given or is today, as such reports are
+// computed from the loans, and rejects days in the future.
+func (s *ReportService) pastSnapshots(ctx context.Context, branchID int64, asOf *domain.Date) ([]*domain.LoanBalanceSnapshot, error) {
+	if asOf == nil {
+		return nil, nil
+	}
+	today := domain.Today()
+	if asOf.After(today.Time) {
+		return nil, fmt.Errorf("%w: as_of cannot be in the future", ErrInvalidInput)
+	}
+	if !asOf.Before(today.Time) {
+		return nil, nil
+	}
+
+	if s.loanSnapshotRepo == nil {
+		return nil, ErrLoanSnapshotsUnavailable
+	}
+	snapshots, err := s.loanSnapshotRepo.ListByDate(ctx, branchID, asOf.Time)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrLoanSnapshotNotFound
+	}
+	return snapshots, nil
+}
+
+// RecordLoanBalanceSnapshots snapshots the open loans of every branch that has
+// snapshots enabled and returns how many were stored. Taking them again on the
+// same day replaces them, so the last run of a day holds its closing figures.
+func (s *ReportService) RecordLoanBalanceSnapshots(ctx context.Context, day domain.Date) (int, error) {
+	if s.loanSnapshotRepo == nil {
+		return 0, nil
+	}
+
+	snapshots, err := s.openLoanSnapshots(ctx, 0, day)
+	if err != nil {
+		return 0, err
+	}
+
+	enabled := make(map[int64]bool)
+	batch := make([]*domain.LoanBalanceSnapshot, 0, loanSnapshotBatchSize)
+	recorded := 0
+	for i, snapshot := range snapshots {
+		on, checked := enabled[snapshot.BranchID]
+		if !checked {
+			on = getSettingBool(ctx, s.settingRepo, SettingLoanBalanceSnapshotsEnabled, &snapshot.BranchID, true)
+			enabled[snapshot.BranchID] = on
+		}
+		if on {
+			batch = append(batch, snapshot)
+		}
+
+		if len(batch) == loanSnapshotBatchSize || (i == len(snapshots)-1 && len(batch) > 0) {
+			if err := s.loanSnapshotRepo.UpsertBatch(ctx, batch); err != nil {
+				return recorded, fmt.Errorf("failed to store loan balance snapshots: %w", err)
+			}
+			recorded += len(batch)
+			batch = make([]*domain.LoanBalanceSnapshot, 0, loanSnapshotBatchSize)
+		}
+	}
+
+	return recorded, nil
+}
+
+// openLoanSnapshots computes the snapshot at day of every active or overdue
+// loan of a branch, or of every branch when branchID is 0
+func (s *ReportService) openLoanSnapshots(ctx context.Context, branchID int64, day domain.Date) ([]*domain.LoanBalanceSnapshot, error) {
+	snapshots := []*domain.LoanBalanceSnapshot{}
+	for _, status := range []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusOverdue} {
+		for page := 1; ; page++ {
+			result, err := s.loanRepo.List(ctx, repository.LoanListParams{
+				PaginationParams: repository.PaginationParams{Page: page, PerPage: loanSnapshotBatchSize},
+				BranchID:         branchID,
+				Status:           &status,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list open loans: %w", err)
+			}
+			for i := range result.Data {
+				snapshots = append(snapshots, domain.NewLoanBalanceSnapshot(&result.Data[i], day))
+			}
+			if page >= result.TotalPages {
+				break
+			}
+		}
+	}
+	return snapshots, nil
+}
+
+// SettingUserCommissionRates holds the commission staff earn, as a percentage
+// of the loans they create and of the sales they make
+const SettingUserCommissionRates = "user_commission_rates"
+
+// CommissionRates are the commission percentages of a branch
+type CommissionRates struct {
+	LoansPercent float64 `json:"loans_percent"`
+	SalesPercent float64 `json:"sales_percent"`
+}
+
+// ErrUserPerformanceUnavailable is returned when the report is not set up
+var ErrUserPerformanceUnavailable = errors.New("user performance report is not enabled")
+
+// UserPerformance is a staff member's activity over a period with the
+// commission it earned
+type UserPerformance struct {
+	UserID   int64       `json:"user_id"`
+	UserName string      `json:"user_name"`
+	BranchID *int64      `json:"branch_id,omitempty"`
+	DateFrom domain.Date `json:"date_from"`
+	DateTo   domain.Date `json:"date_to"`
+
+	domain.UserActivity
+
+	// Branches breaks the activity down by the branches the user worked in,
+	// which need not be only their own
+	Branches []*domain.UserBranchActivity `json:"branches"`
+
+	CommissionRates  CommissionRates `json:"commission_rates"`
+	CommissionEarned float64         `json:"commission_earned"`
+}
+
+// GetUserPerformance reports what a user did from one day to another, both
+// included, in every branch they worked in. Only the records the user created
+// count towards their figures.
+func (s *ReportService) GetUserPerformance(ctx context.Context, userID int64, from, to domain.Date) (*UserPerformance, error) {
+	if s.userActivityRepo == nil {
+		return nil, ErrUserPerformanceUnavailable
+	}
+	if to.Before(from.Time) {
+		return nil, fmt.Errorf("%w: date_to cannot be before date_from", ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	activity, err := s.userActivityRepo.ListUserActivity(ctx, user.ID, from.Time, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	performance := &UserPerformance{
+		UserID:       user.ID,
+		UserName:     user.FullName(),
+		BranchID:     user.BranchID,
+		DateFrom:     from,
+		DateTo:       to,
+		UserActivity: domain.UserActivity{UserID: user.ID},
+		Branches:     []*domain.UserBranchActivity{},
+	}
+	var cashDifference float64
+	for _, a := range activity {
+		if a.UserID != user.ID {
+			continue
+		}
+		performance.Branches = append(performance.Branches, a)
+		performance.LoansCreated += a.LoansCreated
+		performance.LoansAmount += a.LoansAmount
+		performance.PaymentsProcessed += a.PaymentsProcessed
+		performance.PaymentsAmount += a.PaymentsAmount
+		performance.SalesCount += a.SalesCount
+		performance.SalesAmount += a.SalesAmount
+		performance.CashSessionsClosed += a.CashSessionsClosed
+		cashDifference += a.AverageCashDifference * float64(a.CashSessionsClosed)
+	}
+	if performance.CashSessionsClosed > 0 {
+		performance.AverageCashDifference = cashDifference / float64(performance.CashSessionsClosed)
+	}
+	performance.LoansAmount = roundCents(performance.LoansAmount)
+	performance.PaymentsAmount = roundCents(performance.PaymentsAmount)
+	performance.SalesAmount = roundCents(performance.SalesAmount)
+	performance.AverageCashDifference = roundCents(performance.AverageCashDifference)
+
+	getSettingJSON(ctx, s.settingRepo, SettingUserCommissionRates, user.BranchID, &performance.CommissionRates)
+	performance.CommissionEarned = roundCents(
+		performance.LoansAmount*performance.CommissionRates.LoansPercent/100 +
+			performance.SalesAmount*performance.CommissionRates.SalesPercent/100,
+	)
+
+	return performance, nil
 }
@@ -1,14 +1,27 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
 	"pawnshop/internal/repository"
 	"pawnshop/internal/repository/mocks"
 )
@@ -19,7 +32,7 @@ func setupReportService() (*ReportService, *mocks.MockLoanRepository, *mocks.Moc
 	saleRepo := new(mocks.MockSaleRepository)
 	customerRepo := new(mocks.MockCustomerRepository)
 	itemRepo := new(mocks.MockItemRepository)
-	service := NewReportService(loanRepo, paymentRepo, saleRepo, customerRepo, itemRepo, nil)
+	service := NewReportService(loanRepo, paymentRepo, saleRepo, customerRepo, itemRepo, nil, nil, nil)
 	return service, loanRepo, paymentRepo, saleRepo, customerRepo, itemRepo
 }
 
@@ -149,7 +162,7 @@ func TestReportService_GetLoanReport_Success(t *testing.T) {
 		Total: 2,
 	}, nil)
 
-	result, err := service.GetLoanReport(ctx, 1, "2025-01-01", "2025-01-31")
+	result, err := service.GetLoanReport(ctx, 1, "2025-01-01", "2025-01-31", nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -162,6 +175,144 @@ func TestReportService_GetLoanReport_Success(t *testing.T) {
 	assert.Len(t, result.RecentLoans, 2)
 }
 
+func TestReportService_GetLoanReport_ExcludesWrittenOffFromOutstanding(t *testing.T) {
+	service, loanRepo, _, _, _, _ := setupReportService()
+	ctx := context.Background()
+
+	loans := []domain.Loan{
+		{LoanAmount: 1000, PrincipalRemaining: 600, InterestRemaining: 40, Status: domain.LoanStatusActive},
+		{LoanAmount: 2000, PrincipalRemaining: 2000, InterestRemaining: 300, Status: domain.LoanStatusWrittenOff},
+	}
+	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(&repository.PaginatedResult[domain.Loan]{
+		Data:  loans,
+		Total: 2,
+	}, nil)
+
+	result, err := service.GetLoanReport(ctx, 1, "2026-01-01", "2026-12-31", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 640.0, result.TotalOutstanding)
+	assert.Equal(t, 1, result.ByStatus[string(domain.LoanStatusWrittenOff)])
+}
+
+func TestReportService_ExportLoanReportCSV(t *testing.T) {
+	service, loanRepo, _, _, _, _ := setupReportService()
+	ctx := context.Background()
+
+	loans := []domain.Loan{
+		{
+			LoanNumber:     "PR-000101",
+			LoanAmount:     1000,
+			InterestAmount: 100,
+			TotalAmount:    1100,
+			Status:         domain.LoanStatusActive,
+			DueDate:        domain.NewDate(2026, time.February, 15),
+			Customer:       &domain.Customer{FirstName: "Pérez, Juan", LastName: "García"},
+			Item:           &domain.Item{Name: "Anillo \"solitario\" 14k"},
+		},
+		{
+			LoanNumber:     "PR-000102",
+			LoanAmount:     2500.5,
+			InterestAmount: 250.05,
+			TotalAmount:    2750.55,
+			Status:         domain.LoanStatusPaid,
+			DueDate:        domain.NewDate(2026, time.February, 20),
+		},
+	}
+	loanRepo.On("List", ctx, mock.MatchedBy(func(p repository.LoanListParams) bool {
+		return p.BranchID == 1 && *p.DueAfter == "2026-02-01" && *p.DueBefore == "2026-02-28"
+	})).Return(&repository.PaginatedResult[domain.Loan]{Data: loans, Total: 2}, nil)
+
+	data, err := service.ExportLoanReportCSV(ctx, 1, "2026-02-01", "2026-02-28")
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{
+		"loan_number", "customer_name", "item_name", "loan_amount", "interest_amount",
+		"total_amount", "status", "due_date",
+	}, rows[0])
+	assert.Equal(t, []string{"PR-000101", "Pérez, Juan García", "Anillo \"solitario\" 14k", "1000.00", "100.00", "1100.00", "active", "2026-02-15"}, rows[1])
+	assert.Equal(t, []string{"PR-000102", "", "", "2500.50", "250.05", "2750.55", "paid", "2026-02-20"}, rows[2])
+	assert.Contains(t, string(data), `"Pérez, Juan García"`, "names with commas are quoted")
+}
+
+func TestReportService_ExportPaymentReportXLSX_TotalsMatchDetail(t *testing.T) {
+	service, _, paymentRepo, _, _, _ := setupReportService()
+	ctx := context.Background()
+
+	paidAt := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+	payments := []domain.Payment{
+		{PaymentNumber: "PG-1", Amount: 500.25, PrincipalAmount: 400, InterestAmount: 100.25, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted, PaymentDate: paidAt,
+			Loan: &domain.Loan{LoanNumber: "PR-1"}, Customer: &domain.Customer{FirstName: "Ana", LastName: "López"}},
+		{PaymentNumber: "PG-2", Amount: 1200, PrincipalAmount: 1000, InterestAmount: 150, LateFeeAmount: 50, PaymentMethod: domain.PaymentMethodCard, Status: domain.PaymentStatusCompleted, PaymentDate: paidAt},
+		{PaymentNumber: "PG-3", Amount: 99.75, PrincipalAmount: 99.75, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusCompleted, PaymentDate: paidAt},
+		{PaymentNumber: "PG-4", Amount: 700, PrincipalAmount: 700, PaymentMethod: domain.PaymentMethodCash, Status: domain.PaymentStatusReversed, PaymentDate: paidAt},
+	}
+	paymentRepo.On("List", ctx, mock.AnythingOfType("repository.PaymentListParams")).
+		Return(&repository.PaginatedResult[domain.Payment]{Data: payments, Total: 4}, nil)
+
+	data, err := service.ExportPaymentReportXLSX(ctx, 1, "2026-03-01", "2026-03-31")
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"Resumen", "Pagos"}, f.GetSheetList())
+
+	// Detail rows: one per completed payment, amounts stored as numbers
+	rows, err := f.GetRows("Pagos", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	var detailTotal float64
+	for i, row := range rows[1:] {
+		cell := fmt.Sprintf("G%d", i+2)
+		cellType, err := f.GetCellType("Pagos", cell)
+		require.NoError(t, err)
+		assert.NotContains(t, []excelize.CellType{excelize.CellTypeSharedString, excelize.CellTypeInlineString}, cellType, cell)
+		amount, err := strconv.ParseFloat(row[6], 64)
+		require.NoError(t, err)
+		detailTotal += amount
+	}
+	assert.Equal(t, []string{"PG-1", "PR-1", "Ana López", "cash"}, []string{rows[1][0], rows[1][2], rows[1][3], rows[1][4]})
+
+	// Summary: a row per method, then the total
+	summary, err := f.GetRows("Resumen", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	totalRow := summary[len(summary)-1]
+	require.Equal(t, "Total", totalRow[0])
+	assert.Equal(t, "3", totalRow[1])
+	total, err := strconv.ParseFloat(totalRow[2], 64)
+	require.NoError(t, err)
+	assert.InDelta(t, detailTotal, total, 0.001)
+	assert.InDelta(t, 1800.0, total, 0.001)
+	assert.Equal(t, []string{"card", "1", "1200"}, summary[3][:3])
+	assert.Equal(t, []string{"cash", "2", "600"}, summary[4][:3])
+}
+
+func TestReportService_GetWriteOffReport(t *testing.T) {
+	service, _, _, _, _, _ := setupReportService()
+	ctx := context.Background()
+	writeOffRepo := new(mocks.MockLoanWriteOffRepository)
+	service.SetWriteOffs(writeOffRepo)
+
+	writeOffRepo.On("List", ctx, mock.MatchedBy(func(p repository.LoanWriteOffListParams) bool {
+		return p.BranchID == 1 && *p.DateFrom == "2026-10-01" && *p.DateTo == "2026-10-31"
+	})).Return([]*domain.LoanWriteOff{
+		{ID: 1, LoanID: 5, Principal: 800, Interest: 120.5, LateFees: 30, Amount: 950.5},
+		{ID: 2, LoanID: 9, Principal: 300, Amount: 300},
+	}, nil)
+
+	report, err := service.GetWriteOffReport(ctx, 1, "2026-10-01", "2026-10-31")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.TotalLoans)
+	assert.Equal(t, 1250.5, report.TotalAmount)
+	assert.Equal(t, 1100.0, report.TotalPrincipal)
+	assert.Len(t, report.WriteOffs, 2)
+}
+
 func TestReportService_GetLoanReport_MoreThan10Loans(t *testing.T) {
 	service, loanRepo, _, _, _, _ := setupReportService()
 	ctx := context.Background()
@@ -176,7 +327,7 @@ func TestReportService_GetLoanReport_MoreThan10Loans(t *testing.T) {
 		Total: 15,
 	}, nil)
 
-	result, err := service.GetLoanReport(ctx, 1, "2025-01-01", "2025-12-31")
+	result, err := service.GetLoanReport(ctx, 1, "2025-01-01", "2025-12-31", nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 15, result.TotalLoans)
@@ -189,7 +340,7 @@ func TestReportService_GetLoanReport_Error(t *testing.T) {
 
 	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(nil, errors.New("db error"))
 
-	result, err := service.GetLoanReport(ctx, 1, "2025-01-01", "2025-01-31")
+	result, err := service.GetLoanReport(ctx, 1, "2025-01-01", "2025-01-31", nil)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -323,7 +474,7 @@ func TestReportService_GetOverdueReport_Success(t *testing.T) {
 			InterestRemaining:  50.0,
 			LateFeeAmount:      50.0,
 			Status:             domain.LoanStatusOverdue,
-			DueDate:            now.AddDate(0, 0, -10),
+			DueDate:            domain.DateFromTime(now.AddDate(0, 0, -10)),
 			GracePeriodDays:    15,
 		},
 		{
@@ -333,7 +484,7 @@ func TestReportService_GetOverdueReport_Success(t *testing.T) {
 			InterestRemaining:  25.0,
 			LateFeeAmount:      25.0,
 			Status:             domain.LoanStatusOverdue,
-			DueDate:            now.AddDate(0, 0, -14),
+			DueDate:            domain.DateFromTime(now.AddDate(0, 0, -14)),
 			GracePeriodDays:    15,
 		},
 	}
@@ -343,12 +494,12 @@ func TestReportService_GetOverdueReport_Success(t *testing.T) {
 	// Active loans for approaching due
 	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(&repository.PaginatedResult[domain.Loan]{
 		Data: []domain.Loan{
-			{DueDate: now.AddDate(0, 0, 3), Status: domain.LoanStatusActive},
+			{DueDate: domain.DateFromTime(now.AddDate(0, 0, 3)), Status: domain.LoanStatusActive},
 		},
 		Total: 1,
 	}, nil)
 
-	result, err := service.GetOverdueReport(ctx, 1)
+	result, err := service.GetOverdueReport(ctx, 1, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -364,7 +515,7 @@ func TestReportService_GetOverdueReport_Error(t *testing.T) {
 
 	loanRepo.On("GetOverdueLoans", ctx, int64(1)).Return(nil, errors.New("db error"))
 
-	result, err := service.GetOverdueReport(ctx, 1)
+	result, err := service.GetOverdueReport(ctx, 1, nil)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -380,10 +531,529 @@ func TestReportService_GetOverdueReport_NoOverdue(t *testing.T) {
 		Total: 0,
 	}, nil)
 
-	result, err := service.GetOverdueReport(ctx, 1)
+	result, err := service.GetOverdueReport(ctx, 1, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 0, result.TotalOverdue)
 	assert.Empty(t, result.OverdueLoans)
 	assert.Empty(t, result.ApproachingDue)
 }
+
+// --- Stored contract tests ---
+
+func contractFixtures(ctx context.Context, loanRepo *mocks.MockLoanRepository, customerRepo *mocks.MockCustomerRepository, itemRepo *mocks.MockItemRepository) *domain.Loan {
+	loan := &domain.Loan{
+		ID: 5, LoanNumber: "LN-000005", BranchID: 1, CustomerID: 1, ItemID: 1,
+		LoanAmount: 500, InterestRate: 10, InterestAmount: 50, TotalAmount: 550,
+		StartDate:    domain.DateFromTime(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		DueDate:      domain.DateFromTime(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)),
+		LoanTermDays: 30, PaymentPlanType: "single", Status: domain.LoanStatusActive,
+	}
+	loanRepo.On("GetByID", ctx, int64(5)).Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, FirstName: "Ana", LastName: "López", IdentityNumber: "1234567890101"}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, Name: "Anillo de oro", SKU: "MAIN-000001", Condition: "good", AppraisedValue: 800}, nil)
+	return loan
+}
+
+func TestReportService_StoreLoanContract(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	storageDir := t.TempDir()
+	storage := NewStorageService(nil, storageDir, "/storage")
+	service := NewReportService(loanRepo, nil, nil, customerRepo, itemRepo, documentRepo, pdf.NewGenerator("Casa de Empeño", "", "", ""), storage)
+	ctx := context.Background()
+
+	contractFixtures(ctx, loanRepo, customerRepo, itemRepo)
+	documentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Document")).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Document).ID = 77
+	}).Return(nil)
+	loanRepo.On("SetContract", ctx, int64(5), int64(77), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+
+	doc, err := service.StoreLoanContract(ctx, 5, 9)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.DocumentTypeLoanContract, doc.DocumentType)
+	assert.Equal(t, "loan", doc.ReferenceType)
+	assert.Equal(t, int64(5), doc.ReferenceID)
+	assert.Equal(t, "application/pdf", doc.MimeType)
+	assert.Equal(t, int64(9), doc.CreatedBy)
+
+	// The hash matches the stored file
+	file, _, err := storage.GetDocument(ctx, doc.FilePath)
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	sum := sha256.Sum256(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), doc.ContentHash)
+
+	loanRepo.AssertCalled(t, "SetContract", ctx, int64(5), int64(77), doc.FileURL, doc.ContentHash)
+}
+
+func TestReportService_StoreLoanContract_NotConfigured(t *testing.T) {
+	service, _, _, _, _, _ := setupReportService()
+
+	_, err := service.StoreLoanContract(context.Background(), 5, 9)
+
+	assert.Error(t, err)
+}
+
+func TestReportService_StoreLoanContract_StorageFailureLeavesLoanUntouched(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	// A file where the storage root should be makes every write fail
+	root := t.TempDir() + "/storage"
+	require.NoError(t, os.WriteFile(root, []byte("not a directory"), 0644))
+	service := NewReportService(loanRepo, nil, nil, customerRepo, itemRepo, documentRepo, pdf.NewGenerator("Casa de Empeño", "", "", ""), NewStorageService(nil, root, "/storage"))
+	ctx := context.Background()
+
+	contractFixtures(ctx, loanRepo, customerRepo, itemRepo)
+
+	_, err := service.StoreLoanContract(ctx, 5, 9)
+
+	assert.Error(t, err)
+	documentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "SetContract", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- Document regeneration tests ---
+
+func paymentReceiptFixtures(ctx context.Context, paymentRepo *mocks.MockPaymentRepository, loanRepo *mocks.MockLoanRepository, customerRepo *mocks.MockCustomerRepository, loan *domain.Loan) {
+	paymentRepo.On("GetByID", ctx, int64(8)).Return(&domain.Payment{
+		ID: 8, PaymentNumber: "PAY-000008", BranchID: 1, LoanID: loan.ID, CustomerID: 1,
+		Amount: 100, PrincipalAmount: 100, PaymentMethod: "cash", Status: domain.PaymentStatusCompleted,
+		PaymentDate: time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC),
+	}, nil)
+	loanRepo.On("GetByID", ctx, loan.ID).Return(loan, nil)
+	customerRepo.On("GetByID", ctx, int64(1)).Return(&domain.Customer{ID: 1, FirstName: "Ana", LastName: "Lopez"}, nil)
+}
+
+func TestReportService_RegenerateDocument_PaymentReceiptUsesCurrentBalance(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	service := NewReportService(loanRepo, paymentRepo, nil, customerRepo, nil, nil, pdf.NewGenerator("Casa de Empeño", "", "", ""), nil)
+	ctx := context.Background()
+
+	// The loan balance was corrected after the receipt was first issued
+	loan := &domain.Loan{ID: 5, LoanNumber: "LN-000005", PrincipalRemaining: 250, InterestRemaining: 20, Status: domain.LoanStatusActive}
+	paymentReceiptFixtures(ctx, paymentRepo, loanRepo, customerRepo, loan)
+
+	result, err := service.RegenerateDocument(ctx, domain.DocumentTypePaymentReceipt, 8, RegenerateDocumentOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "PAY-000008.pdf", result.Filename)
+	assert.Contains(t, string(result.Data), "Saldo Pendiente: Q270.00")
+	assert.Nil(t, result.Document)
+}
+
+func TestReportService_RegenerateDocument_OverwritesStoredCopy(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	storage := NewStorageService(nil, t.TempDir(), "/storage")
+	service := NewReportService(loanRepo, paymentRepo, nil, customerRepo, nil, documentRepo, pdf.NewGenerator("Casa de Empeño", "", "", ""), storage)
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 5, LoanNumber: "LN-000005", PrincipalRemaining: 0, Status: domain.LoanStatusPaid}
+	paymentReceiptFixtures(ctx, paymentRepo, loanRepo, customerRepo, loan)
+
+	old, err := storage.SaveDocument(ctx, []byte("%PDF old receipt"), "PAY-000008.pdf", "receipts")
+	require.NoError(t, err)
+	stored := &domain.Document{ID: 31, DocumentType: domain.DocumentTypePaymentReceipt, ReferenceType: "payment", ReferenceID: 8, FilePath: old.ID, FileURL: old.URL, ContentHash: "old"}
+	documentRepo.On("ListByReference", ctx, "payment", int64(8)).Return([]*domain.Document{stored}, nil)
+	documentRepo.On("UpdateFile", ctx, stored).Return(nil)
+
+	result, err := service.RegenerateDocument(ctx, domain.DocumentTypePaymentReceipt, 8, RegenerateDocumentOptions{Overwrite: true, CreatedBy: 9})
+	require.NoError(t, err)
+
+	require.NotNil(t, result.Document)
+	assert.Equal(t, int64(31), result.Document.ID)
+	assert.NotEqual(t, old.ID, result.Document.FilePath)
+	sum := sha256.Sum256(result.Data)
+	assert.Equal(t, hex.EncodeToString(sum[:]), result.Document.ContentHash)
+	// The replaced file is deleted
+	_, _, err = storage.GetDocument(ctx, old.ID)
+	assert.Error(t, err)
+	reader, _, err := storage.GetDocument(ctx, result.Document.FilePath)
+	require.NoError(t, err)
+	reader.Close()
+	documentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	loanRepo.AssertNotCalled(t, "SetContract", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReportService_RegenerateDocument_OverwriteCountsAgainstQuota(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	service := NewReportService(loanRepo, paymentRepo, nil, customerRepo, nil, documentRepo, pdf.NewGenerator("Casa de Empeño", "", "", ""), NewStorageService(nil, t.TempDir(), "/storage"))
+	quota, fileRepo, _ := setupStorageQuotaService(10)
+	service.SetStorageQuota(quota)
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 5, LoanNumber: "LN-000005", Status: domain.LoanStatusPaid}
+	paymentReceiptFixtures(ctx, paymentRepo, loanRepo, customerRepo, loan)
+	documentRepo.On("ListByReference", ctx, "payment", int64(8)).Return([]*domain.Document{}, nil)
+	documentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Document")).Return(nil)
+	fileRepo.On("GetBranchBytes", ctx, int64(1)).Return(int64(0), nil)
+	fileRepo.On("Create", ctx, mock.MatchedBy(func(file *domain.StoredFile) bool {
+		return file.BranchID == 1 && file.EntityType == "payment" && file.EntityID == 8 && file.CreatedBy == 9 && file.SizeBytes > 0
+	})).Return(nil)
+
+	_, err := service.RegenerateDocument(ctx, domain.DocumentTypePaymentReceipt, 8, RegenerateDocumentOptions{Overwrite: true, CreatedBy: 9})
+
+	require.NoError(t, err)
+	fileRepo.AssertExpectations(t)
+}
+
+func TestReportService_RegenerateDocument_OverwriteOverQuota(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	service := NewReportService(loanRepo, paymentRepo, nil, customerRepo, nil, documentRepo, pdf.NewGenerator("Casa de Empeño", "", "", ""), NewStorageService(nil, t.TempDir(), "/storage"))
+	quota, fileRepo, _ := setupStorageQuotaService(10)
+	service.SetStorageQuota(quota)
+	ctx := context.Background()
+
+	loan := &domain.Loan{ID: 5, LoanNumber: "LN-000005", Status: domain.LoanStatusPaid}
+	paymentReceiptFixtures(ctx, paymentRepo, loanRepo, customerRepo, loan)
+	documentRepo.On("ListByReference", ctx, "payment", int64(8)).Return([]*domain.Document{}, nil)
+	fileRepo.On("GetBranchBytes", ctx, int64(1)).Return(int64(10*bytesPerMB), nil)
+
+	_, err := service.RegenerateDocument(ctx, domain.DocumentTypePaymentReceipt, 8, RegenerateDocumentOptions{Overwrite: true, CreatedBy: 9})
+
+	assert.ErrorIs(t, err, ErrStorageQuotaExceeded)
+	documentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	fileRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestReportService_RegenerateDocument_UnsupportedType(t *testing.T) {
+	service, _, _, _, _, _ := setupReportService()
+
+	_, err := service.RegenerateDocument(context.Background(), domain.DocumentTypeConfiscationNotice, 1, RegenerateDocumentOptions{})
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestReportService_GenerateLoanDocumentBundle(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	paymentRepo := new(mocks.MockPaymentRepository)
+	customerRepo := new(mocks.MockCustomerRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	documentRepo := new(mocks.MockDocumentRepository)
+	storage := NewStorageService(nil, t.TempDir(), "/storage")
+	service := NewReportService(loanRepo, paymentRepo, nil, customerRepo, itemRepo, documentRepo, pdf.NewGenerator("Casa de Empeño", "", "", ""), storage)
+	ctx := context.Background()
+
+	contractFixtures(ctx, loanRepo, customerRepo, itemRepo)
+	paymentRepo.On("ListByLoan", ctx, int64(5)).Return([]*domain.Payment{{
+		ID: 8, PaymentNumber: "PAY-000008", BranchID: 1, LoanID: 5, CustomerID: 1,
+		Amount: 100, PrincipalAmount: 100, PaymentMethod: "cash", Status: domain.PaymentStatusCompleted,
+		PaymentDate: time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC),
+	}}, nil)
+	photo, err := storage.SaveDocument(ctx, []byte("id card"), "id.jpg", "loans")
+	require.NoError(t, err)
+	documentRepo.On("ListByReference", ctx, "loan", int64(5)).Return([]*domain.Document{
+		{ID: 40, DocumentType: domain.DocumentTypeLoanContract, FilePath: "stale-contract.pdf"},
+		{ID: 41, ReferenceType: "loan", ReferenceID: 5, FilePath: photo.ID},
+	}, nil)
+
+	bundle, err := service.GenerateLoanDocumentBundle(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "LN-000005-documents.zip", bundle.Filename)
+
+	var buf bytes.Buffer
+	require.NoError(t, bundle.WriteZip(ctx, &buf))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"contract.pdf", "receipt-PAY-000008.pdf", "attachments/" + path.Base(photo.ID)}, names)
+
+	attachment, err := archive.File[2].Open()
+	require.NoError(t, err)
+	defer attachment.Close()
+	data, err := io.ReadAll(attachment)
+	require.NoError(t, err)
+	assert.Equal(t, "id card", string(data))
+}
+
+// fakeDailyBalanceRepo keeps daily balances in memory and serves fixed daily activity
+type fakeDailyBalanceRepo struct {
+	*mocks.MockDailyBalanceRepository
+	activity map[string]*repository.DailyActivity
+	balances map[string]*domain.DailyBalance
+}
+
+func newFakeDailyBalanceRepo(activity map[string]*repository.DailyActivity, balances ...*domain.DailyBalance) *fakeDailyBalanceRepo {
+	repo := &fakeDailyBalanceRepo{activity: activity, balances: map[string]*domain.DailyBalance{}}
+	for _, balance := range balances {
+		repo.balances[balance.BalanceDate.Format(domain.DateFormat)] = balance
+	}
+	return repo
+}
+
+func (r *fakeDailyBalanceRepo) GetDailyActivity(ctx context.Context, branchID int64, date time.Time) (*repository.DailyActivity, error) {
+	if activity, ok := r.activity[date.Format(domain.DateFormat)]; ok {
+		return activity, nil
+	}
+	return &repository.DailyActivity{}, nil
+}
+
+func (r *fakeDailyBalanceRepo) GetByBranchAndDate(ctx context.Context, branchID int64, date time.Time) (*domain.DailyBalance, error) {
+	return r.balances[date.Format(domain.DateFormat)], nil
+}
+
+func (r *fakeDailyBalanceRepo) Upsert(ctx context.Context, balance *domain.DailyBalance) error {
+	r.balances[balance.BalanceDate.Format(domain.DateFormat)] = balance
+	return nil
+}
+
+func (r *fakeDailyBalanceRepo) UpsertBatch(ctx context.Context, balances []*domain.DailyBalance) error {
+	for _, balance := range balances {
+		r.balances[balance.BalanceDate.Format(domain.DateFormat)] = balance
+	}
+	return nil
+}
+
+func dailyActivityFixtures() map[string]*repository.DailyActivity {
+	return map[string]*repository.DailyActivity{
+		"2024-03-01": {LoanDisbursements: 500, PaymentsReceived: 220, InterestCollected: 20, SalesIncome: 300, Expenses: 45, ActiveLoansAmount: 5500, ActiveLoansCount: 11},
+		"2024-03-02": {PaymentsReceived: 110, InterestCollected: 10, LateFeesCollected: 5, Refunds: 80, ActiveLoansAmount: 5400, ActiveLoansCount: 10},
+		"2024-03-03": {LoanDisbursements: 250, SalesIncome: 120.5, Expenses: 12.25, ActiveLoansAmount: 5650, ActiveLoansCount: 11},
+	}
+}
+
+func TestReportService_BackfillDailyBalances_MatchesNightlyJob(t *testing.T) {
+	ctx := context.Background()
+	from, to := domain.NewDate(2024, 3, 1), domain.NewDate(2024, 3, 3)
+	opening := &domain.DailyBalance{BranchID: 1, BalanceDate: domain.NewDate(2024, 2, 29).Time, CashClosing: 1000}
+
+	// Nightly job: one day at a time, each reading the balance stored the night before
+	nightly := newFakeDailyBalanceRepo(dailyActivityFixtures(), opening)
+	nightlyService := NewReportService(nil, nil, nil, nil, nil, nil, nil, nil)
+	nightlyService.SetDailyBalances(nightly, nil)
+	for day := from; !day.After(to.Time); day = domain.DateFromTime(day.AddDate(0, 0, 1)) {
+		_, err := nightlyService.RecordDailyBalance(ctx, 1, day)
+		require.NoError(t, err)
+	}
+
+	backfilled := newFakeDailyBalanceRepo(dailyActivityFixtures(), opening)
+	backfillService := NewReportService(nil, nil, nil, nil, nil, nil, nil, nil)
+	backfillService.SetDailyBalances(backfilled, nil)
+	result, err := backfillService.BackfillDailyBalances(ctx, 1, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.DaysProcessed)
+
+	assert.Equal(t, nightly.balances, backfilled.balances)
+	assert.Equal(t, 975.0, backfilled.balances["2024-03-01"].CashClosing)
+	assert.Equal(t, 975.0, backfilled.balances["2024-03-02"].CashOpening)
+	assert.Equal(t, -65.0, backfilled.balances["2024-03-02"].NetIncome)
+}
+
+func TestReportService_BackfillDailyBalances_Batched(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.MockDailyBalanceRepository)
+	service := NewReportService(nil, nil, nil, nil, nil, nil, nil, nil)
+	service.SetDailyBalances(repo, nil)
+
+	repo.On("GetByBranchAndDate", ctx, int64(1), mock.AnythingOfType("time.Time")).Return(nil, nil)
+	repo.On("GetDailyActivity", ctx, int64(1), mock.AnythingOfType("time.Time")).Return(&repository.DailyActivity{}, nil)
+	var batchSizes []int
+	repo.On("UpsertBatch", ctx, mock.AnythingOfType("[]*domain.DailyBalance")).Run(func(args mock.Arguments) {
+		batchSizes = append(batchSizes, len(args.Get(1).([]*domain.DailyBalance)))
+	}).Return(nil)
+
+	result, err := service.BackfillDailyBalances(ctx, 1, domain.NewDate(2024, 1, 1), domain.NewDate(2024, 2, 9))
+
+	require.NoError(t, err)
+	assert.Equal(t, 40, result.DaysProcessed)
+	assert.Equal(t, []int{31, 9}, batchSizes)
+}
+
+func TestReportService_RecordDailyBalances_AllBranchPages(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeDailyBalanceRepo(nil)
+	branchRepo := new(mocks.MockBranchRepository)
+	service := NewReportService(nil, nil, nil, nil, nil, nil, nil, nil)
+	service.SetDailyBalances(repo, branchRepo)
+
+	branchRepo.On("List", ctx, repository.PaginationParams{Page: 1, PerPage: dailyBalanceBranchPageSize}).
+		Return(&repository.PaginatedResult[domain.Branch]{Data: []domain.Branch{{ID: 1}}, TotalPages: 2}, nil)
+	branchRepo.On("List", ctx, repository.PaginationParams{Page: 2, PerPage: dailyBalanceBranchPageSize}).
+		Return(&repository.PaginatedResult[domain.Branch]{Data: []domain.Branch{{ID: 2}}, TotalPages: 2}, nil)
+
+	recorded, err := service.RecordDailyBalances(ctx, domain.NewDate(2024, 3, 1))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, recorded)
+	branchRepo.AssertExpectations(t)
+}
+
+func TestReportService_BackfillDailyBalances_InvalidRange(t *testing.T) {
+	service := NewReportService(nil, nil, nil, nil, nil, nil, nil, nil)
+	service.SetDailyBalances(new(mocks.MockDailyBalanceRepository), nil)
+
+	_, err := service.BackfillDailyBalances(context.Background(), 1, domain.NewDate(2024, 3, 2), domain.NewDate(2024, 3, 1))
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+
+	_, err = service.BackfillDailyBalances(context.Background(), 1, domain.NewDate(2023, 1, 1), domain.NewDate(2024, 3, 1))
+	assert.ErrorIs(t, err, ErrBackfillRangeTooLong)
+}
+
+func TestReportService_GetLoanBalanceReport_PastDateReadsSnapshot(t *testing.T) {
+	service, loanRepo, _, _, _, _ := setupReportService()
+	snapshotRepo := new(mocks.MockLoanBalanceSnapshotRepository)
+	service.SetLoanSnapshots(snapshotRepo, nil)
+	ctx := context.Background()
+	asOf := domain.NewDate(2026, 1, 10)
+
+	snapshotRepo.On("ListByDate", ctx, int64(1), asOf.Time).Return([]*domain.LoanBalanceSnapshot{
+		{LoanID: 7, BranchID: 1, SnapshotDate: asOf, Status: domain.LoanStatusActive, PrincipalRemaining: 500, InterestRemaining: 50, AccruedInterest: 12.50, LateFeeRemaining: 12.50, Balance: 562.50},
+	}, nil)
+
+	report, err := service.GetLoanBalanceReport(ctx, 1, &asOf)
+
+	require.NoError(t, err)
+	assert.True(t, report.FromSnapshot)
+	assert.Equal(t, asOf, report.AsOf)
+	assert.Equal(t, 1, report.TotalLoans)
+	assert.Equal(t, 12.50, report.TotalAccruedInterest)
+	assert.Equal(t, 562.50, report.TotalBalance)
+	loanRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestReportService_GetLoanReport_PastDateReadsSnapshot(t *testing.T) {
+	service, loanRepo, _, _, _, _ := setupReportService()
+	snapshotRepo := new(mocks.MockLoanBalanceSnapshotRepository)
+	service.SetLoanSnapshots(snapshotRepo, nil)
+	ctx := context.Background()
+	asOf := domain.NewDate(2026, 1, 10)
+
+	// Loan 7 has since been paid off and loan 8 was not open yet on that day
+	loanRepo.On("List", ctx, mock.AnythingOfType("repository.LoanListParams")).Return(&repository.PaginatedResult[domain.Loan]{
+		Data: []domain.Loan{
+			{ID: 7, LoanAmount: 500, Status: domain.LoanStatusPaid},
+			{ID: 8, LoanAmount: 300, PrincipalRemaining: 300, Status: domain.LoanStatusActive},
+		},
+		Total: 2,
+	}, nil)
+	snapshotRepo.On("ListByDate", ctx, int64(1), asOf.Time).Return([]*domain.LoanBalanceSnapshot{
+		{LoanID: 7, BranchID: 1, SnapshotDate: asOf, Status: domain.LoanStatusActive, Balance: 562.50},
+	}, nil)
+
+	report, err := service.GetLoanReport(ctx, 1, "2026-01-01", "2026-12-31", &asOf)
+
+	require.NoError(t, err)
+	assert.Equal(t, &asOf, report.AsOf)
+	assert.Equal(t, 562.50, report.TotalOutstanding)
+}
+
+func TestReportService_GetOverdueReport_PastDateReadsSnapshot(t *testing.T) {
+	service, loanRepo, _, _, _, _ := setupReportService()
+	snapshotRepo := new(mocks.MockLoanBalanceSnapshotRepository)
+	service.SetLoanSnapshots(snapshotRepo, nil)
+	ctx := context.Background()
+	asOf := domain.NewDate(2026, 1, 10)
+
+	snapshotRepo.On("ListByDate", ctx, int64(1), asOf.Time).Return([]*domain.LoanBalanceSnapshot{
+		{LoanID: 7, BranchID: 1, SnapshotDate: asOf, Status: domain.LoanStatusOverdue, LateFeeRemaining: 40, Balance: 640, DaysPastDue: 4},
+		{LoanID: 9, BranchID: 1, SnapshotDate: asOf, Status: domain.LoanStatusActive, Balance: 300},
+	}, nil)
+
+	report, err := service.GetOverdueReport(ctx, 1, &asOf)
+
+	require.NoError(t, err)
+	assert.Equal(t, &asOf, report.AsOf)
+	assert.Equal(t, 1, report.TotalOverdue)
+	assert.Equal(t, 640.0, report.TotalAmount)
+	assert.Equal(t, 40.0, report.TotalLateFees)
+	require.Len(t, report.OverdueSnapshots, 1)
+	assert.Equal(t, int64(7), report.OverdueSnapshots[0].LoanID)
+	loanRepo.AssertNotCalled(t, "GetOverdueLoans", mock.Anything, mock.Anything)
+}
+
+func TestReportService_GetLoanBalanceReport_MissingSnapshot(t *testing.T) {
+	service, _, _, _, _, _ := setupReportService()
+	snapshotRepo := new(mocks.MockLoanBalanceSnapshotRepository)
+	service.SetLoanSnapshots(snapshotRepo, nil)
+	asOf := domain.NewDate(2026, 1, 10)
+
+	snapshotRepo.On("ListByDate", mock.Anything, int64(1), asOf.Time).Return([]*domain.LoanBalanceSnapshot{}, nil)
+
+	_, err := service.GetLoanBalanceReport(context.Background(), 1, &asOf)
+	assert.ErrorIs(t, err, ErrLoanSnapshotNotFound)
+}
+
+func TestReportService_GetUserPerformance_AttributesToActingUser(t *testing.T) {
+	service, _, _, _, _, _ := setupReportService()
+	activityRepo := new(mocks.MockUserActivityRepository)
+	userRepo := new(mocks.MockUserRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	service.SetUserPerformance(activityRepo, userRepo, settingRepo)
+	ctx := context.Background()
+	branchID := int64(2)
+
+	userRepo.On("GetByID", ctx, int64(10)).Return(&domain.User{ID: 10, BranchID: &branchID, FirstName: "Ana", LastName: "López"}, nil)
+	settingRepo.On("Get", ctx, SettingUserCommissionRates, &branchID).
+		Return(&domain.Setting{Value: map[string]interface{}{"loans_percent": 1.0, "sales_percent": 5.0}}, nil)
+
+	// The user's activity grouped per branch, including a shift covered at
+	// branch 3; the period ends after May 31
+	from, to := domain.NewDate(2024, 5, 1), domain.NewDate(2024, 5, 31)
+	activityRepo.On("ListUserActivity", ctx, int64(10), from.Time, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)).Return([]*domain.UserBranchActivity{
+		{BranchID: 2, UserActivity: domain.UserActivity{UserID: 10, LoansCreated: 2, LoansAmount: 3000, PaymentsProcessed: 10, PaymentsAmount: 1530.5,
+			SalesCount: 2, SalesAmount: 800, CashSessionsClosed: 3, AverageCashDifference: -5}},
+		{BranchID: 3, UserActivity: domain.UserActivity{UserID: 10, LoansCreated: 1, LoansAmount: 1500, PaymentsProcessed: 2, PaymentsAmount: 300,
+			CashSessionsClosed: 1, AverageCashDifference: 5}},
+		{BranchID: 2, UserActivity: domain.UserActivity{UserID: 11, LoansCreated: 1, LoansAmount: 1000, SalesCount: 9, SalesAmount: 6000}},
+	}, nil)
+
+	performance, err := service.GetUserPerformance(ctx, 10, from, to)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), performance.UserID)
+	assert.Equal(t, "Ana López", performance.UserName)
+	assert.Equal(t, 3, performance.LoansCreated)
+	assert.Equal(t, 4500.0, performance.LoansAmount)
+	assert.Equal(t, 12, performance.PaymentsProcessed)
+	assert.Equal(t, 1830.5, performance.PaymentsAmount)
+	assert.Equal(t, 2, performance.SalesCount)
+	assert.Equal(t, 800.0, performance.SalesAmount)
+	assert.Equal(t, 4, performance.CashSessionsClosed)
+	assert.Equal(t, -2.5, performance.AverageCashDifference)
+	// 1% of 4500 in loans plus 5% of 800 in sales
+	assert.Equal(t, 85.0, performance.CommissionEarned)
+	require.Len(t, performance.Branches, 2)
+	assert.Equal(t, int64(3), performance.Branches[1].BranchID)
+}
+
+func TestReportService_GetUserPerformance_NoActivity(t *testing.T) {
+	service, _, _, _, _, _ := setupReportService()
+	activityRepo := new(mocks.MockUserActivityRepository)
+	userRepo := new(mocks.MockUserRepository)
+	service.SetUserPerformance(activityRepo, userRepo, nil)
+	ctx := context.Background()
+	branchID := int64(2)
+
+	userRepo.On("GetByID", ctx, int64(12)).Return(&domain.User{ID: 12, BranchID: &branchID}, nil)
+	activityRepo.On("ListUserActivity", ctx, int64(12), mock.Anything, mock.Anything).Return([]*domain.UserBranchActivity{}, nil)
+
+	performance, err := service.GetUserPerformance(ctx, 12, domain.NewDate(2024, 5, 1), domain.NewDate(2024, 5, 31))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), performance.UserID)
+	assert.Zero(t, performance.LoansCreated)
+	assert.Zero(t, performance.LoansAmount)
+	assert.Zero(t, performance.CommissionEarned)
+}
@@ -162,6 +162,7 @@ func (s *RoleService) GetAvailablePermissions() []string {
 		"items.update",
 		"items.delete",
 		"items.appraise",
+		"items.restore",
 		// Loans
 		"loans.read",
 		"loans.create",
@@ -170,10 +171,13 @@ func (s *RoleService) GetAvailablePermissions() []string {
 		"loans.approve",
 		"loans.extend",
 		"loans.default",
+		"loans.override_item_limit",
+		"loans.authorize",
 		// Payments
 		"payments.read",
 		"payments.create",
 		"payments.void",
+		"payments.backdate",
 		// Sales
 		"sales.read",
 		"sales.create",
@@ -190,6 +194,7 @@ func (s *RoleService) GetAvailablePermissions() []string {
 		"branches.create",
 		"branches.update",
 		"branches.delete",
+		"branches.all",
 		// Roles
 		"roles.read",
 		"roles.create",
@@ -200,9 +205,16 @@ func (s *RoleService) GetAvailablePermissions() []string {
 		"cash.manage_registers",
 		"cash.manage_sessions",
 		"cash.manage_movements",
+		"cash.override_limit",
 		// Reports
 		"reports.read",
 		"reports.export",
+		"reports.backfill",
+		// Accounting
+		"accounting.close",
+		"accounting.write_off",
+		// Notes
+		"notes.delete",
 		// Settings
 		"settings.read",
 		"settings.update",
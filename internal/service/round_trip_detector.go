@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Setting keys for round-trip detection
+const (
+	// SettingRoundTripDetection turns on flagging sales of items that come back
+	// to where they came from
+	SettingRoundTripDetection = "round_trip_detection_enabled"
+	// SettingRoundTripWindowDays is how soon after being pawned an item being
+	// sold is considered suspicious
+	SettingRoundTripWindowDays = "round_trip_window_days"
+)
+
+// DefaultRoundTripWindowDays is used when no window is configured. Confiscation
+// normally takes longer than the shortest loan term plus its grace period.
+const DefaultRoundTripWindowDays = 15
+
+// Round-tripping rules
+const (
+	RoundTripRuleFormerBorrower = "sold_to_former_borrower"
+	RoundTripRuleQuickSale      = "pawned_and_sold_quickly"
+)
+
+// ErrRoundTripNoteRequired is returned when a sale matches a round-tripping
+// pattern and no note explains it
+var ErrRoundTripNoteRequired = errors.New("possible round-tripping detected, a note explaining the sale is required")
+
+// RoundTripFinding is a round-tripping pattern a sale matched against one of
+// the item's past loans
+type RoundTripFinding struct {
+	Rule       string `json:"rule"`
+	LoanID     int64  `json:"loan_id"`
+	LoanNumber string `json:"loan_number"`
+	Message    string `json:"message"`
+}
+
+// roundTripSale is the sale being checked
+type roundTripSale struct {
+	customerID *int64
+	at         time.Time
+	windowDays int
+}
+
+// roundTripRule checks a sale against one past loan of the item, returning a
+// finding when the pair looks like round-tripping
+type roundTripRule func(sale roundTripSale, loan *domain.Loan) *RoundTripFinding
+
+// roundTripRules are the checks run on every sale
+var roundTripRules = []roundTripRule{
+	soldToFormerBorrower,
+	pawnedAndSoldQuickly,
+}
+
+// soldToFormerBorrower flags selling an item back to the customer who lost it
+// by defaulting on its loan
+func soldToFormerBorrower(sale roundTripSale, loan *domain.Loan) *RoundTripFinding {
+	if sale.customerID == nil || *sale.customerID != loan.CustomerID {
+		return nil
+	}
+	switch loan.Status {
+	case domain.LoanStatusConfiscated, domain.LoanStatusDefaulted, domain.LoanStatusWrittenOff:
+	default:
+		return nil
+	}
+	return &RoundTripFinding{
+		Rule:       RoundTripRuleFormerBorrower,
+		LoanID:     loan.ID,
+		LoanNumber: loan.LoanNumber,
+		Message:    fmt.Sprintf("El artículo se vende al mismo cliente que lo perdió en el préstamo %s", loan.LoanNumber),
+	}
+}
+
+// pawnedAndSoldQuickly flags selling an item shortly after it was pawned,
+// sooner than a regular confiscation could have put it up for sale
+func pawnedAndSoldQuickly(sale roundTripSale, loan *domain.Loan) *RoundTripFinding {
+	days := int(sale.at.Sub(loan.StartDate.Time).Hours() / 24)
+	if days < 0 || days >= sale.windowDays {
+		return nil
+	}
+	return &RoundTripFinding{
+		Rule:       RoundTripRuleQuickSale,
+		LoanID:     loan.ID,
+		LoanNumber: loan.LoanNumber,
+		Message:    fmt.Sprintf("El artículo se empeñó en el préstamo %s hace %d día(s)", loan.LoanNumber, days),
+	}
+}
+
+// RoundTripDetector checks sales against the past loans of the item sold for
+// patterns of round-tripping
+type RoundTripDetector struct {
+	loanRepo    repository.LoanRepository
+	settingRepo repository.SettingRepository
+}
+
+// NewRoundTripDetector creates a new RoundTripDetector
+func NewRoundTripDetector(loanRepo repository.LoanRepository, settingRepo repository.SettingRepository) *RoundTripDetector {
+	return &RoundTripDetector{
+		loanRepo:    loanRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// Check returns the round-tripping patterns matched by selling item to
+// customerID at the given time. It returns nothing when detection is turned
+// off for the item's branch.
+func (d *RoundTripDetector) Check(ctx context.Context, item *domain.Item, customerID *int64, at time.Time) ([]RoundTripFinding, error) {
+	if !getSettingBool(ctx, d.settingRepo, SettingRoundTripDetection, &item.BranchID, false) {
+		return nil, nil
+	}
+
+	loans, err := d.loanRepo.List(ctx, repository.LoanListParams{
+		ItemID:           &item.ID,
+		PaginationParams: repository.PaginationParams{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loans of item: %w", err)
+	}
+
+	sale := roundTripSale{
+		customerID: customerID,
+		at:         at,
+		windowDays: getSettingInt(ctx, d.settingRepo, SettingRoundTripWindowDays, &item.BranchID, DefaultRoundTripWindowDays),
+	}
+
+	var findings []RoundTripFinding
+	for i := range loans.Data {
+		for _, rule := range roundTripRules {
+			if finding := rule(sale, &loans.Data[i]); finding != nil {
+				findings = append(findings, *finding)
+			}
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Settings naming the accounts a sale refund is booked to
+const (
+	SettingSaleIncomeAccount = "sale_income_account"
+	SettingSaleBankAccount   = "sale_bank_account"
+)
+
+// Default sale accounts of the seeded chart of accounts. Cash refunds leave
+// the same cash account payments go into.
+const (
+	DefaultSaleIncomeAccount = "4300" // Ingresos por Ventas
+	DefaultSaleBankAccount   = "1120" // Bancos
+)
+
+// SetAccounting enables posting an accounting entry for every sale refund
+func (s *SaleService) SetAccounting(accountRepo repository.AccountRepository, entryRepo repository.AccountingEntryRepository, logger zerolog.Logger) {
+	s.accountRepo = accountRepo
+	s.entryRepo = entryRepo
+	s.logger = logger
+}
+
+// newRefundEntry builds the balanced, unsaved entry of a refund: the amount
+// paid back is debited to sales income and credited to cash, or to the bank
+// for refunds not paid in cash. It is built before anything is saved so a
+// missing account rejects the refund instead of leaving it off the books.
+func (s *SaleService) newRefundEntry(ctx context.Context, sale *domain.Sale, amount float64, userID int64) (*domain.AccountingEntry, error) {
+	branchID := sale.BranchID
+	account := func(setting, fallback string) (*domain.Account, error) {
+		code := getSettingString(ctx, s.settingRepo, setting, &branchID, fallback)
+		acc, err := s.accountRepo.GetByCode(ctx, code)
+		if err != nil || acc == nil {
+			return nil, fmt.Errorf("account %s not found", code)
+		}
+		return acc, nil
+	}
+
+	income, err := account(SettingSaleIncomeAccount, DefaultSaleIncomeAccount)
+	if err != nil {
+		return nil, err
+	}
+	paidFrom, err := account(SettingSaleBankAccount, DefaultSaleBankAccount)
+	if sale.PaymentMethod == domain.PaymentMethodCash {
+		paidFrom, err = account(SettingPaymentCashAccount, DefaultPaymentCashAccount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Devolución de la venta %s", sale.SaleNumber)
+	amount = roundCents(amount)
+	entry := &domain.AccountingEntry{
+		BranchID:      sale.BranchID,
+		EntryDate:     time.Now(),
+		Description:   description,
+		ReferenceType: domain.AccountingReferenceSaleRefund,
+		ReferenceID:   &sale.ID,
+		Lines: []*domain.AccountingEntryLine{
+			{AccountID: income.ID, EntryType: domain.EntryTypeDebit, Amount: amount, Description: description},
+			{AccountID: paidFrom.ID, EntryType: domain.EntryTypeCredit, Amount: amount, Description: description},
+		},
+		IsPosted:  true,
+		PostedBy:  &userID,
+		CreatedBy: &userID,
+	}
+	entry.TotalDebit, entry.TotalCredit = entry.LineTotals()
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// postRefundEntry numbers and saves the entry of a refund, posted as it is
+// created. The refund has already been paid out, so a failure is only logged.
+func (s *SaleService) postRefundEntry(ctx context.Context, entry *domain.AccountingEntry) {
+	number, err := s.entryRepo.GenerateEntryNumber(ctx)
+	if err == nil {
+		entry.EntryNumber = number
+		err = s.entryRepo.Create(ctx, entry)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Int64("sale_id", *entry.ReferenceID).Msg("Failed to post sale refund entry")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/rs/zerolog"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
 )
@@ -16,6 +18,14 @@ type SaleService struct {
 	itemRepo     repository.ItemRepository
 	customerRepo repository.CustomerRepository
 	branchRepo   repository.BranchRepository
+	cashService  *CashService
+	roundTrips   *RoundTripDetector
+	router       *NotificationRouter
+	categoryRepo repository.CategoryRepository
+	settingRepo  repository.SettingRepository
+	accountRepo  repository.AccountRepository
+	entryRepo    repository.AccountingEntryRepository
+	logger       zerolog.Logger
 }
 
 // NewSaleService creates a new SaleService
@@ -24,15 +34,24 @@ func NewSaleService(
 	itemRepo repository.ItemRepository,
 	customerRepo repository.CustomerRepository,
 	branchRepo repository.BranchRepository,
+	cashService *CashService,
 ) *SaleService {
 	return &SaleService{
 		saleRepo:     saleRepo,
 		itemRepo:     itemRepo,
 		customerRepo: customerRepo,
 		branchRepo:   branchRepo,
+		cashService:  cashService,
 	}
 }
 
+// SetRoundTripDetection enables flagging sales that look like round-tripping,
+// which then need a note and alert the branch managers
+func (s *SaleService) SetRoundTripDetection(detector *RoundTripDetector, router *NotificationRouter) {
+	s.roundTrips = detector
+	s.router = router
+}
+
 // CreateSaleInput represents create sale request data
 type CreateSaleInput struct {
 	BranchID        int64   `json:"branch_id" validate:"required"`
@@ -89,6 +108,18 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 		}
 	}
 
+	// Items coming back to where they came from need an explanation
+	var roundTrips []RoundTripFinding
+	if s.roundTrips != nil {
+		roundTrips, err = s.roundTrips.Check(ctx, item, input.CustomerID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if len(roundTrips) > 0 && (input.Notes == nil || strings.TrimSpace(*input.Notes) == "") {
+			return nil, fmt.Errorf("%w: %s", ErrRoundTripNoteRequired, roundTripMessages(roundTrips))
+		}
+	}
+
 	// Get sale price from item
 	if item.SalePrice == nil || *item.SalePrice <= 0 {
 		return nil, errors.New("item does not have a valid sale price")
@@ -103,6 +134,16 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 	// Calculate final price
 	finalPrice := salePrice - input.DiscountAmount
 
+	// Cash sales must go into an open cash session
+	var cashSession *domain.CashSession
+	if s.cashService != nil && input.PaymentMethod == string(domain.PaymentMethodCash) {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, input.CreatedBy, input.CashSessionID)
+		if err != nil {
+			return nil, err
+		}
+		input.CashSessionID = &cashSession.ID
+	}
+
 	// Generate sale number
 	saleNumber, err := s.saleRepo.GenerateNumber(ctx)
 	if err != nil {
@@ -110,6 +151,8 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 	}
 
 	// Create sale record
+	saleDate := time.Now()
+	warrantyDays, warrantyExpiry := s.warrantyFor(ctx, item, saleDate)
 	sale := &domain.Sale{
 		BranchID:        input.BranchID,
 		ItemID:          input.ItemID,
@@ -123,9 +166,11 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 		PaymentMethod:   domain.PaymentMethod(input.PaymentMethod),
 		ReferenceNumber: input.ReferenceNumber,
 		Status:          domain.SaleStatusCompleted,
-		SaleDate:        time.Now(),
+		SaleDate:        saleDate,
 		Notes:           input.Notes,
 		CashSessionID:   input.CashSessionID,
+		WarrantyDays:    warrantyDays,
+		WarrantyExpiry:  warrantyExpiry,
 		CreatedBy:       input.CreatedBy,
 	}
 
@@ -134,6 +179,18 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 		return nil, fmt.Errorf("failed to create sale: %w", err)
 	}
 
+	// Record the cash receipt. A sale whose cash never reached the drawer is
+	// cancelled, leaving the item on sale.
+	if cashSession != nil {
+		if err := s.cashService.RecordSaleMovement(ctx, cashSession.ID, sale.ID, sale.FinalPrice, input.PaymentMethod, input.CreatedBy); err != nil {
+			sale.Status = domain.SaleStatusCancelled
+			if cerr := s.saleRepo.Update(ctx, sale); cerr != nil {
+				s.logger.Error().Err(cerr).Int64("sale_id", sale.ID).Msg("Failed to cancel sale after sale movement failure")
+			}
+			return nil, fmt.Errorf("failed to record sale movement: %w", err)
+		}
+	}
+
 	// Update item status to sold
 	if err := s.itemRepo.UpdateStatus(ctx, item.ID, domain.ItemStatusSold); err != nil {
 		return nil, fmt.Errorf("failed to update item status: %w", err)
@@ -151,8 +208,22 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 		CreatedBy:     input.CreatedBy,
 	})
 
+	if len(roundTrips) > 0 {
+		s.router.emit(ctx, InternalEvent{
+			Event:         domain.InternalEventRoundTrip,
+			BranchID:      sale.BranchID,
+			Amount:        sale.FinalPrice,
+			Title:         "Posible Venta Circular",
+			Message:       fmt.Sprintf("Venta %s del artículo %s: %s. Nota: %s", sale.SaleNumber, item.SKU, roundTripMessages(roundTrips), *input.Notes),
+			Type:          "warning",
+			ReferenceType: "sale",
+			ReferenceID:   &sale.ID,
+		})
+	}
+
 	// Reload item with updated status
 	item, _ = s.itemRepo.GetByID(ctx, item.ID)
+	setWarrantyRemaining(sale, saleDate)
 
 	return &SaleResult{
 		Sale: sale,
@@ -160,6 +231,14 @@ func (s *SaleService) Create(ctx context.Context, input CreateSaleInput) (*SaleR
 	}, nil
 }
 
+func roundTripMessages(findings []RoundTripFinding) string {
+	messages := make([]string, len(findings))
+	for i, finding := range findings {
+		messages[i] = finding.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
 // GetByID retrieves a sale by ID
 func (s *SaleService) GetByID(ctx context.Context, id int64) (*domain.Sale, error) {
 	sale, err := s.saleRepo.GetByID(ctx, id)
@@ -172,6 +251,7 @@ func (s *SaleService) GetByID(ctx context.Context, id int64) (*domain.Sale, erro
 	if sale.CustomerID != nil {
 		sale.Customer, _ = s.customerRepo.GetByID(ctx, *sale.CustomerID)
 	}
+	setWarrantyRemaining(sale, time.Now())
 
 	return sale, nil
 }
@@ -182,6 +262,7 @@ func (s *SaleService) GetByNumber(ctx context.Context, saleNumber string) (*doma
 	if err != nil {
 		return nil, errors.New("sale not found")
 	}
+	setWarrantyRemaining(sale, time.Now())
 
 	return sale, nil
 }
@@ -191,12 +272,19 @@ func (s *SaleService) List(ctx context.Context, params repository.SaleListParams
 	return s.saleRepo.List(ctx, params)
 }
 
+// ErrSaleAlreadyRefunded is returned when refunding a sale a second time
+var ErrSaleAlreadyRefunded = errors.New("sale has already been refunded")
+
 // RefundSaleInput represents refund sale request data
 type RefundSaleInput struct {
 	SaleID       int64   `json:"sale_id" validate:"required"`
 	RefundAmount float64 `json:"refund_amount" validate:"required,gt=0"`
 	Reason       string  `json:"reason" validate:"required"`
-	RefundedBy   int64   `json:"-"`
+	// ItemStatus is where a fully refunded item goes back to: available by
+	// default, or straight back on sale
+	ItemStatus    domain.ItemStatus `json:"item_status" validate:"omitempty,oneof=available for_sale"`
+	CashSessionID *int64            `json:"cash_session_id"`
+	RefundedBy    int64             `json:"-"`
 }
 
 // Refund processes a sale refund
@@ -208,6 +296,9 @@ func (s *SaleService) Refund(ctx context.Context, input RefundSaleInput) (*domai
 	}
 
 	// Validate sale can be refunded
+	if sale.Status == domain.SaleStatusRefunded || sale.Status == domain.SaleStatusPartialRefund {
+		return nil, ErrSaleAlreadyRefunded
+	}
 	if sale.Status != domain.SaleStatusCompleted {
 		return nil, errors.New("only completed sales can be refunded")
 	}
@@ -228,11 +319,42 @@ func (s *SaleService) Refund(ctx context.Context, input RefundSaleInput) (*domai
 		return nil, errors.New("item status has changed, cannot process refund")
 	}
 
+	// Returns after the warranty period are blocked or flagged
+	now := time.Now()
+	if err := s.checkRefundWarranty(ctx, sale, now); err != nil {
+		return nil, err
+	}
+
+	// Cash refunds are paid out of an open cash session holding enough cash
+	var cashSession *domain.CashSession
+	if s.cashService != nil && sale.PaymentMethod == domain.PaymentMethodCash {
+		cashSession, err = s.cashService.RequireOpenSession(ctx, input.RefundedBy, input.CashSessionID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.cashService.EnsureCashAvailable(ctx, cashSession, input.RefundAmount); err != nil {
+			return nil, err
+		}
+	}
+
+	var entry *domain.AccountingEntry
+	if s.entryRepo != nil {
+		entry, err = s.newRefundEntry(ctx, sale, input.RefundAmount, input.RefundedBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build refund entry: %w", err)
+		}
+	}
+
+	itemStatus := input.ItemStatus
+	if itemStatus == "" {
+		itemStatus = domain.ItemStatusAvailable
+	}
+
 	// Determine refund type
 	isFullRefund := input.RefundAmount >= sale.FinalPrice
 
 	// Update sale
-	now := time.Now()
+	previous := *sale
 	if isFullRefund {
 		sale.Status = domain.SaleStatusRefunded
 	} else {
@@ -248,9 +370,23 @@ func (s *SaleService) Refund(ctx context.Context, input RefundSaleInput) (*domai
 		return nil, fmt.Errorf("failed to update sale: %w", err)
 	}
 
-	// If full refund, return item to available status
+	// Record the cash paid back. Without it the drawer would not balance, so
+	// the sale goes back to completed when it cannot be recorded.
+	if cashSession != nil {
+		if err := s.cashService.RecordRefundMovement(ctx, cashSession.ID, sale.ID, input.RefundAmount, string(sale.PaymentMethod), input.RefundedBy); err != nil {
+			if rerr := s.saleRepo.Update(ctx, &previous); rerr != nil {
+				s.logger.Error().Err(rerr).Int64("sale_id", sale.ID).Msg("Failed to restore sale after refund movement failure")
+			}
+			return nil, fmt.Errorf("failed to record refund movement: %w", err)
+		}
+	}
+	if entry != nil {
+		s.postRefundEntry(ctx, entry)
+	}
+
+	// If full refund, return item to stock
 	if isFullRefund {
-		if err := s.itemRepo.UpdateStatus(ctx, item.ID, domain.ItemStatusAvailable); err != nil {
+		if err := s.itemRepo.UpdateStatus(ctx, item.ID, itemStatus); err != nil {
 			return nil, fmt.Errorf("failed to update item status: %w", err)
 		}
 
@@ -259,7 +395,7 @@ func (s *SaleService) Refund(ctx context.Context, input RefundSaleInput) (*domai
 			ItemID:        item.ID,
 			Action:        "returned",
 			OldStatus:     string(domain.ItemStatusSold),
-			NewStatus:     string(domain.ItemStatusAvailable),
+			NewStatus:     string(itemStatus),
 			ReferenceType: strPtr("sale_refund"),
 			ReferenceID:   &sale.ID,
 			Notes:         "Full refund for sale: " + sale.SaleNumber,
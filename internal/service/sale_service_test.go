@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"pawnshop/internal/domain"
@@ -17,7 +19,7 @@ func setupSaleService() (*SaleService, *mocks.MockSaleRepository, *mocks.MockIte
 	itemRepo := new(mocks.MockItemRepository)
 	customerRepo := new(mocks.MockCustomerRepository)
 	branchRepo := new(mocks.MockBranchRepository)
-	service := NewSaleService(saleRepo, itemRepo, customerRepo, branchRepo)
+	service := NewSaleService(saleRepo, itemRepo, customerRepo, branchRepo, nil)
 	return service, saleRepo, itemRepo, customerRepo, branchRepo
 }
 
@@ -65,6 +67,59 @@ func TestSaleService_Create_Success(t *testing.T) {
 	itemRepo.AssertExpectations(t)
 }
 
+func TestSaleService_Create_MovementFailureCancelsSale(t *testing.T) {
+	saleRepo := new(mocks.MockSaleRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	branchRepo := new(mocks.MockBranchRepository)
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	cashService := NewCashService(nil, sessionRepo, movementRepo, nil, nil, nil)
+	service := NewSaleService(saleRepo, itemRepo, nil, branchRepo, cashService)
+	ctx := context.Background()
+
+	salePrice := 500.0
+	session := &domain.CashSession{ID: 5, UserID: 10, Status: domain.CashSessionStatusOpen}
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, BranchID: 1, Status: domain.ItemStatusForSale, SalePrice: &salePrice}, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(5)).Return(0.0, nil)
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Return(errors.New("db error"))
+	saleRepo.On("GenerateNumber", ctx).Return("SALE-001", nil)
+	saleRepo.On("Create", ctx, mock.AnythingOfType("*domain.Sale")).Return(nil)
+	saleRepo.On("Update", ctx, mock.MatchedBy(func(sale *domain.Sale) bool {
+		return sale.Status == domain.SaleStatusCancelled
+	})).Return(nil)
+
+	result, err := service.Create(ctx, CreateSaleInput{BranchID: 1, ItemID: 1, SaleType: "direct", PaymentMethod: "cash", CreatedBy: 10})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	saleRepo.AssertExpectations(t)
+	itemRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSaleService_Create_RejectsAnotherUsersSession(t *testing.T) {
+	saleRepo := new(mocks.MockSaleRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	branchRepo := new(mocks.MockBranchRepository)
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	cashService := NewCashService(nil, sessionRepo, nil, nil, nil, nil)
+	service := NewSaleService(saleRepo, itemRepo, nil, branchRepo, cashService)
+	ctx := context.Background()
+
+	salePrice := 500.0
+	sessionID := int64(5)
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(&domain.Item{ID: 1, BranchID: 1, Status: domain.ItemStatusForSale, SalePrice: &salePrice}, nil)
+	sessionRepo.On("GetByID", ctx, sessionID).Return(&domain.CashSession{ID: sessionID, UserID: 11, Status: domain.CashSessionStatusOpen}, nil)
+
+	_, err := service.Create(ctx, CreateSaleInput{BranchID: 1, ItemID: 1, SaleType: "direct", PaymentMethod: "cash", CashSessionID: &sessionID, CreatedBy: 10})
+
+	assert.ErrorIs(t, err, ErrCashSessionNotOwned)
+	saleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestSaleService_Create_InvalidBranch(t *testing.T) {
 	service, _, _, _, branchRepo := setupSaleService()
 	ctx := context.Background()
@@ -362,7 +417,7 @@ func TestSaleService_Refund_NotCompleted(t *testing.T) {
 	service, saleRepo, _, _, _ := setupSaleService()
 	ctx := context.Background()
 
-	sale := &domain.Sale{ID: 1, Status: domain.SaleStatusRefunded}
+	sale := &domain.Sale{ID: 1, Status: domain.SaleStatusCancelled}
 	saleRepo.On("GetByID", ctx, int64(1)).Return(sale, nil)
 
 	input := RefundSaleInput{SaleID: 1, RefundAmount: 100.0, Reason: "test"}
@@ -373,6 +428,161 @@ func TestSaleService_Refund_NotCompleted(t *testing.T) {
 	assert.Equal(t, "only completed sales can be refunded", err.Error())
 }
 
+func TestSaleService_Refund_AlreadyRefunded(t *testing.T) {
+	for _, status := range []domain.SaleStatus{domain.SaleStatusRefunded, domain.SaleStatusPartialRefund} {
+		service, saleRepo, _, _, _ := setupSaleService()
+		ctx := context.Background()
+		saleRepo.On("GetByID", ctx, int64(1)).Return(&domain.Sale{ID: 1, ItemID: 10, FinalPrice: 500.0, Status: status}, nil)
+
+		result, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 100.0, Reason: "Devolución", RefundedBy: 10})
+
+		assert.ErrorIs(t, err, ErrSaleAlreadyRefunded, status)
+		assert.Nil(t, result)
+		saleRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	}
+}
+
+func TestSaleService_Refund_PutsItemBackOnSale(t *testing.T) {
+	service, saleRepo, itemRepo, _, _ := setupSaleService()
+	ctx := context.Background()
+
+	saleRepo.On("GetByID", ctx, int64(1)).Return(&domain.Sale{ID: 1, ItemID: 10, FinalPrice: 500.0, Status: domain.SaleStatusCompleted}, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+	saleRepo.On("Update", ctx, mock.AnythingOfType("*domain.Sale")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(10), domain.ItemStatusForSale).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.MatchedBy(func(history *domain.ItemHistory) bool {
+		return history.NewStatus == string(domain.ItemStatusForSale)
+	})).Return(nil)
+
+	result, err := service.Refund(ctx, RefundSaleInput{
+		SaleID: 1, RefundAmount: 500.0, Reason: "Devolución", ItemStatus: domain.ItemStatusForSale, RefundedBy: 10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SaleStatusRefunded, result.Status)
+	itemRepo.AssertExpectations(t)
+}
+
+func TestSaleService_Refund_CashPaidOutOfSession(t *testing.T) {
+	saleRepo := new(mocks.MockSaleRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	cashService := NewCashService(nil, sessionRepo, movementRepo, nil, nil, nil)
+	service := NewSaleService(saleRepo, itemRepo, nil, nil, cashService)
+	ctx := context.Background()
+
+	session := &domain.CashSession{ID: 5, Status: domain.CashSessionStatusOpen}
+	sale := &domain.Sale{ID: 1, ItemID: 10, FinalPrice: 500.0, PaymentMethod: domain.PaymentMethodCash, Status: domain.SaleStatusCompleted}
+	saleRepo.On("GetByID", ctx, int64(1)).Return(sale, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(5)).Return(800.0, nil)
+	saleRepo.On("Update", ctx, mock.AnythingOfType("*domain.Sale")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(10), domain.ItemStatusAvailable).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).Return(nil)
+
+	var movement *domain.CashMovement
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Run(func(args mock.Arguments) {
+		movement = args.Get(1).(*domain.CashMovement)
+	}).Return(nil)
+
+	_, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 500.0, Reason: "Devolución", RefundedBy: 10})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, movement) {
+		assert.Equal(t, domain.CashMovementTypeExpense, movement.MovementType)
+		assert.Equal(t, 500.0, movement.Amount)
+		assert.Equal(t, "sale_refund", *movement.ReferenceType)
+		assert.Equal(t, int64(1), *movement.ReferenceID)
+	}
+
+	// A drawer short of cash cannot pay a refund
+	sale.Status = domain.SaleStatusCompleted
+	movementRepo.ExpectedCalls = nil
+	movementRepo.On("GetSessionBalance", ctx, int64(5)).Return(100.0, nil)
+
+	_, err = service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 500.0, Reason: "Devolución", RefundedBy: 10})
+
+	assert.Error(t, err)
+	saleRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestSaleService_Refund_MovementFailureRestoresSale(t *testing.T) {
+	saleRepo := new(mocks.MockSaleRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	sessionRepo := new(mocks.MockCashSessionRepository)
+	movementRepo := new(mocks.MockCashMovementRepository)
+	cashService := NewCashService(nil, sessionRepo, movementRepo, nil, nil, nil)
+	service := NewSaleService(saleRepo, itemRepo, nil, nil, cashService)
+	ctx := context.Background()
+
+	session := &domain.CashSession{ID: 5, Status: domain.CashSessionStatusOpen}
+	sale := &domain.Sale{ID: 1, ItemID: 10, FinalPrice: 500.0, PaymentMethod: domain.PaymentMethodCash, Status: domain.SaleStatusCompleted}
+	saleRepo.On("GetByID", ctx, int64(1)).Return(sale, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+	sessionRepo.On("GetOpenSession", ctx, int64(10)).Return(session, nil)
+	sessionRepo.On("GetByID", ctx, int64(5)).Return(session, nil)
+	movementRepo.On("GetSessionBalance", ctx, int64(5)).Return(800.0, nil)
+	movementRepo.On("Create", ctx, mock.AnythingOfType("*domain.CashMovement")).Return(errors.New("db error"))
+	var statuses []domain.SaleStatus
+	saleRepo.On("Update", ctx, mock.AnythingOfType("*domain.Sale")).Run(func(args mock.Arguments) {
+		statuses = append(statuses, args.Get(1).(*domain.Sale).Status)
+	}).Return(nil)
+
+	result, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 500.0, Reason: "Devolución", RefundedBy: 10})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, []domain.SaleStatus{domain.SaleStatusRefunded, domain.SaleStatusCompleted}, statuses)
+	itemRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSaleService_Refund_PostsReversalEntry(t *testing.T) {
+	service, saleRepo, itemRepo, _, _ := setupSaleService()
+	accountRepo := new(mocks.MockAccountRepository)
+	entryRepo := new(mocks.MockAccountingEntryRepository)
+	service.SetAccounting(accountRepo, entryRepo, zerolog.Nop())
+	ctx := context.Background()
+
+	saleRepo.On("GetByID", ctx, int64(1)).Return(&domain.Sale{ID: 1, BranchID: 2, ItemID: 10, SaleNumber: "SALE-001", FinalPrice: 500.0, PaymentMethod: domain.PaymentMethodCard, Status: domain.SaleStatusCompleted}, nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+	saleRepo.On("Update", ctx, mock.AnythingOfType("*domain.Sale")).Return(nil)
+	accountRepo.On("GetByCode", ctx, DefaultSaleIncomeAccount).Return(&domain.Account{ID: 43, Code: DefaultSaleIncomeAccount}, nil)
+	accountRepo.On("GetByCode", ctx, DefaultSaleBankAccount).Return(&domain.Account{ID: 12, Code: DefaultSaleBankAccount}, nil)
+	entryRepo.On("GenerateEntryNumber", ctx).Return("JE-000001", nil)
+	var entry *domain.AccountingEntry
+	entryRepo.On("Create", ctx, mock.AnythingOfType("*domain.AccountingEntry")).Run(func(args mock.Arguments) {
+		entry = args.Get(1).(*domain.AccountingEntry)
+	}).Return(nil)
+
+	_, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 200.0, Reason: "Devolución", RefundedBy: 10})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, domain.AccountingReferenceSaleRefund, entry.ReferenceType)
+		assert.Equal(t, int64(1), *entry.ReferenceID)
+		assert.True(t, entry.IsPosted)
+		assert.Equal(t, int64(43), entry.Lines[0].AccountID)
+		assert.Equal(t, domain.EntryTypeDebit, entry.Lines[0].EntryType)
+		assert.Equal(t, int64(12), entry.Lines[1].AccountID)
+		assert.Equal(t, domain.EntryTypeCredit, entry.Lines[1].EntryType)
+		assert.Equal(t, 200.0, entry.TotalDebit)
+	}
+
+	// A missing account rejects the refund before anything is saved
+	saleRepo.ExpectedCalls = nil
+	saleRepo.Calls = nil
+	saleRepo.On("GetByID", ctx, int64(1)).Return(&domain.Sale{ID: 1, BranchID: 2, ItemID: 10, FinalPrice: 500.0, PaymentMethod: domain.PaymentMethodCash, Status: domain.SaleStatusCompleted}, nil)
+	accountRepo.On("GetByCode", ctx, DefaultPaymentCashAccount).Return(nil, errors.New("not found"))
+
+	_, err = service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 200.0, Reason: "Devolución", RefundedBy: 10})
+
+	assert.Error(t, err)
+	saleRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 func TestSaleService_Refund_AmountExceedsPrice(t *testing.T) {
 	service, saleRepo, _, _, _ := setupSaleService()
 	ctx := context.Background()
@@ -431,3 +641,220 @@ func TestSaleService_GetSalesSummary_Success(t *testing.T) {
 	assert.Equal(t, 300.0, result.ByMethod["card"])
 	saleRepo.AssertExpectations(t)
 }
+
+type roundTripSaleMocks struct {
+	saleRepo     *mocks.MockSaleRepository
+	itemRepo     *mocks.MockItemRepository
+	loanRepo     *mocks.MockLoanRepository
+	internalRepo *mocks.MockInternalNotificationRepository
+}
+
+// setupRoundTripSale prepares the sale of a confiscated item whose past loans are
+// loans, with round-trip detection on and branch managers listening
+func setupRoundTripSale(loans []domain.Loan) (*SaleService, roundTripSaleMocks) {
+	service, saleRepo, itemRepo, customerRepo, branchRepo := setupSaleService()
+	m := roundTripSaleMocks{saleRepo: saleRepo, itemRepo: itemRepo, loanRepo: new(mocks.MockLoanRepository)}
+
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingRoundTripDetection, mock.Anything).
+		Return(&domain.Setting{Key: SettingRoundTripDetection, Value: true}, nil)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+
+	router, routerMocks := setupNotificationRouter(domain.DefaultNotificationRoutes())
+	roleID, active, branchID := int64(3), true, int64(1)
+	routerMocks.roleRepo.On("GetByName", mock.Anything, domain.RoleManager).Return(&domain.Role{ID: roleID, Name: domain.RoleManager}, nil).Maybe()
+	routerMocks.userRepo.On("List", mock.Anything, repository.UserListParams{RoleID: &roleID, IsActive: &active, BranchID: &branchID, PaginationParams: repository.PaginationParams{Page: 1, PerPage: routeUserPageSize}}).
+		Return(&repository.PaginatedResult[domain.User]{Data: []domain.User{{ID: 11}}}, nil).Maybe()
+	m.internalRepo = routerMocks.internalRepo
+
+	service.SetRoundTripDetection(NewRoundTripDetector(m.loanRepo, settingRepo), router)
+
+	salePrice := 900.0
+	item := &domain.Item{ID: 1, SKU: "JOY-0001", BranchID: 1, Status: domain.ItemStatusForSale, SalePrice: &salePrice}
+	branchRepo.On("GetByID", mock.Anything, int64(1)).Return(&domain.Branch{ID: 1}, nil)
+	itemRepo.On("GetByID", mock.Anything, int64(1)).Return(item, nil)
+	customerRepo.On("GetByID", mock.Anything, mock.Anything).Return(&domain.Customer{ID: 7}, nil)
+	m.loanRepo.On("List", mock.Anything, mock.MatchedBy(func(params repository.LoanListParams) bool {
+		return params.ItemID != nil && *params.ItemID == 1
+	})).Return(&repository.PaginatedResult[domain.Loan]{Data: loans, Total: len(loans)}, nil)
+	saleRepo.On("GenerateNumber", mock.Anything).Return("SALE-001", nil).Maybe()
+	saleRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Sale")).Return(nil).Maybe()
+	itemRepo.On("UpdateStatus", mock.Anything, int64(1), domain.ItemStatusSold).Return(nil).Maybe()
+	itemRepo.On("CreateHistory", mock.Anything, mock.AnythingOfType("*domain.ItemHistory")).Return(nil).Maybe()
+
+	return service, m
+}
+
+func TestSaleService_Create_RoundTripToFormerBorrower(t *testing.T) {
+	ctx := context.Background()
+	confiscated := domain.Loan{
+		ID: 40, LoanNumber: "LN-000040", CustomerID: 7, ItemID: 1,
+		Status: domain.LoanStatusConfiscated, StartDate: domain.DateFromTime(time.Now().AddDate(0, -3, 0)),
+	}
+	customerID := int64(7)
+	input := CreateSaleInput{BranchID: 1, ItemID: 1, CustomerID: &customerID, SaleType: "direct", PaymentMethod: "cash", CreatedBy: 10}
+
+	t.Run("needs a note", func(t *testing.T) {
+		service, m := setupRoundTripSale([]domain.Loan{confiscated})
+
+		result, err := service.Create(ctx, input)
+
+		assert.ErrorIs(t, err, ErrRoundTripNoteRequired)
+		assert.Contains(t, err.Error(), "LN-000040")
+		assert.Nil(t, result)
+		m.saleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("alerts the managers once explained", func(t *testing.T) {
+		service, m := setupRoundTripSale([]domain.Loan{confiscated})
+		var alerts []*domain.InternalNotification
+		m.internalRepo.On("CreateBulk", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			alerts = args.Get(1).([]*domain.InternalNotification)
+		}).Return(nil)
+
+		note := "El cliente recupera la herencia familiar, autorizado por gerencia"
+		explained := input
+		explained.Notes = &note
+		result, err := service.Create(ctx, explained)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		if assert.Len(t, alerts, 1) {
+			assert.Equal(t, int64(11), alerts[0].UserID)
+			assert.Equal(t, "Posible Venta Circular", alerts[0].Title)
+			assert.Contains(t, alerts[0].Message, "LN-000040")
+			assert.Contains(t, alerts[0].Message, note)
+		}
+	})
+}
+
+func TestSaleService_Create_NormalSaleRaisesNoRoundTripAlert(t *testing.T) {
+	ctx := context.Background()
+	// Another customer buys an item confiscated months ago
+	service, m := setupRoundTripSale([]domain.Loan{{
+		ID: 40, LoanNumber: "LN-000040", CustomerID: 7, ItemID: 1,
+		Status: domain.LoanStatusConfiscated, StartDate: domain.DateFromTime(time.Now().AddDate(0, -3, 0)),
+	}})
+
+	buyerID := int64(8)
+	result, err := service.Create(ctx, CreateSaleInput{BranchID: 1, ItemID: 1, CustomerID: &buyerID, SaleType: "direct", PaymentMethod: "cash", CreatedBy: 10})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	m.internalRepo.AssertNotCalled(t, "CreateBulk", mock.Anything, mock.Anything)
+}
+
+func TestRoundTripDetector_Check_QuickSale(t *testing.T) {
+	loanRepo := new(mocks.MockLoanRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingRoundTripDetection, mock.Anything).
+		Return(&domain.Setting{Key: SettingRoundTripDetection, Value: true}, nil)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	loanRepo.On("List", mock.Anything, mock.Anything).Return(&repository.PaginatedResult[domain.Loan]{Data: []domain.Loan{
+		{ID: 41, LoanNumber: "LN-000041", CustomerID: 7, Status: domain.LoanStatusConfiscated, StartDate: domain.NewDate(2026, 10, 10)},
+	}}, nil)
+
+	findings, err := NewRoundTripDetector(loanRepo, settingRepo).Check(context.Background(), &domain.Item{ID: 1, BranchID: 1}, nil, now)
+
+	assert.NoError(t, err)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, RoundTripRuleQuickSale, findings[0].Rule)
+		assert.Equal(t, "El artículo se empeñó en el préstamo LN-000041 hace 6 día(s)", findings[0].Message)
+	}
+}
+
+// withWarrantyPolicy enables the warranty period on the sale service with the
+// given outside-warranty refund policy
+func withWarrantyPolicy(service *SaleService, categoryRepo *mocks.MockCategoryRepository, refundPolicy string) {
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingSaleRefundOutsideWarranty, mock.Anything).
+		Return(&domain.Setting{Key: SettingSaleRefundOutsideWarranty, Value: refundPolicy}, nil)
+	settingRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("setting not found"))
+	service.SetWarrantyPolicy(categoryRepo, settingRepo)
+}
+
+func TestSaleService_Create_StoresCategoryWarranty(t *testing.T) {
+	service, saleRepo, itemRepo, _, branchRepo := setupSaleService()
+	categoryRepo := new(mocks.MockCategoryRepository)
+	withWarrantyPolicy(service, categoryRepo, RefundOutsideWarrantyWarn)
+	ctx := context.Background()
+
+	salePrice, categoryID, warrantyDays := 500.0, int64(4), 30
+	item := &domain.Item{ID: 1, BranchID: 1, CategoryID: &categoryID, Status: domain.ItemStatusForSale, SalePrice: &salePrice}
+	branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1}, nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	categoryRepo.On("GetByID", ctx, categoryID).Return(&domain.Category{ID: categoryID, WarrantyDays: &warrantyDays}, nil)
+	saleRepo.On("GenerateNumber", ctx).Return("SALE-001", nil)
+	saleRepo.On("Create", ctx, mock.AnythingOfType("*domain.Sale")).Return(nil)
+	itemRepo.On("UpdateStatus", ctx, int64(1), domain.ItemStatusSold).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).Return(nil)
+
+	result, err := service.Create(ctx, CreateSaleInput{BranchID: 1, ItemID: 1, SaleType: "direct", PaymentMethod: "card", CreatedBy: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30, result.Sale.WarrantyDays)
+	if assert.NotNil(t, result.Sale.WarrantyExpiry) {
+		expected := result.Sale.SaleDate.AddDate(0, 0, 30)
+		assert.Equal(t, expected.Format("2006-01-02"), result.Sale.WarrantyExpiry.Format("2006-01-02"))
+	}
+	if assert.NotNil(t, result.Sale.WarrantyDaysRemaining) {
+		assert.Equal(t, 30, *result.Sale.WarrantyDaysRemaining)
+	}
+}
+
+// warrantySale is a completed sale whose return period ended daysAgo days ago,
+// or ends in -daysAgo days
+func warrantySale(daysAgo int) *domain.Sale {
+	expiry := time.Now().AddDate(0, 0, -daysAgo)
+	return &domain.Sale{ID: 1, BranchID: 1, ItemID: 10, FinalPrice: 500.0, Status: domain.SaleStatusCompleted,
+		SaleNumber: "SALE-001", WarrantyDays: 30, WarrantyExpiry: &expiry}
+}
+
+func TestSaleService_Refund_WithinWarranty(t *testing.T) {
+	service, saleRepo, itemRepo, _, _ := setupSaleService()
+	withWarrantyPolicy(service, new(mocks.MockCategoryRepository), RefundOutsideWarrantyBlock)
+	ctx := context.Background()
+
+	saleRepo.On("GetByID", ctx, int64(1)).Return(warrantySale(-5), nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+	saleRepo.On("Update", ctx, mock.MatchedBy(func(sale *domain.Sale) bool { return !sale.RefundOutsideWarranty })).Return(nil)
+
+	result, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 100.0, Reason: "Defecto", RefundedBy: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SaleStatusPartialRefund, result.Status)
+	assert.False(t, result.RefundOutsideWarranty)
+	saleRepo.AssertExpectations(t)
+}
+
+func TestSaleService_Refund_OutsideWarrantyWarns(t *testing.T) {
+	service, saleRepo, itemRepo, _, _ := setupSaleService()
+	withWarrantyPolicy(service, new(mocks.MockCategoryRepository), RefundOutsideWarrantyWarn)
+	ctx := context.Background()
+
+	saleRepo.On("GetByID", ctx, int64(1)).Return(warrantySale(3), nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+	saleRepo.On("Update", ctx, mock.MatchedBy(func(sale *domain.Sale) bool { return sale.RefundOutsideWarranty })).Return(nil)
+
+	result, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 100.0, Reason: "Defecto", RefundedBy: 10})
+
+	assert.NoError(t, err)
+	assert.True(t, result.RefundOutsideWarranty)
+	saleRepo.AssertExpectations(t)
+}
+
+func TestSaleService_Refund_OutsideWarrantyBlocked(t *testing.T) {
+	service, saleRepo, itemRepo, _, _ := setupSaleService()
+	withWarrantyPolicy(service, new(mocks.MockCategoryRepository), RefundOutsideWarrantyBlock)
+	ctx := context.Background()
+
+	saleRepo.On("GetByID", ctx, int64(1)).Return(warrantySale(3), nil)
+	itemRepo.On("GetByID", ctx, int64(10)).Return(&domain.Item{ID: 10, Status: domain.ItemStatusSold}, nil)
+
+	result, err := service.Refund(ctx, RefundSaleInput{SaleID: 1, RefundAmount: 100.0, Reason: "Defecto", RefundedBy: 10})
+
+	assert.ErrorIs(t, err, ErrRefundOutsideWarranty)
+	assert.Nil(t, result)
+	saleRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// Settings controlling the warranty / return period of sales
+const (
+	// SettingSaleWarrantyDays is the return period of items whose category does not set one; 0 means no period
+	SettingSaleWarrantyDays = "sale_warranty_days"
+	// SettingSaleRefundOutsideWarranty is what happens to a refund after the period ends: warn or block
+	SettingSaleRefundOutsideWarranty = "sale_refund_outside_warranty"
+)
+
+// Outside warranty refund policies
+const (
+	RefundOutsideWarrantyWarn  = "warn"
+	RefundOutsideWarrantyBlock = "block"
+)
+
+// ErrRefundOutsideWarranty is returned when a refund is requested after the
+// return period ended and the branch blocks those refunds
+var ErrRefundOutsideWarranty = errors.New("the return period of this sale has ended")
+
+// SetWarrantyPolicy enables the warranty / return period of sales, taken from
+// the item category or the branch default
+func (s *SaleService) SetWarrantyPolicy(categoryRepo repository.CategoryRepository, settingRepo repository.SettingRepository) {
+	s.categoryRepo = categoryRepo
+	s.settingRepo = settingRepo
+}
+
+// warrantyFor returns the return period of an item sold at saleDate and the
+// last day of it, nil when the item has no period
+func (s *SaleService) warrantyFor(ctx context.Context, item *domain.Item, saleDate time.Time) (int, *time.Time) {
+	days := getSettingInt(ctx, s.settingRepo, SettingSaleWarrantyDays, &item.BranchID, 0)
+	if s.categoryRepo != nil && item.CategoryID != nil {
+		if category, err := s.categoryRepo.GetByID(ctx, *item.CategoryID); err == nil && category.WarrantyDays != nil {
+			days = *category.WarrantyDays
+		}
+	}
+	if days <= 0 {
+		return 0, nil
+	}
+	expiry := saleDate.AddDate(0, 0, days)
+	expiry = time.Date(expiry.Year(), expiry.Month(), expiry.Day(), 0, 0, 0, 0, expiry.Location())
+	return days, &expiry
+}
+
+// checkRefundWarranty applies the branch policy to a refund requested after the
+// return period ended: blocked, or let through and flagged on the sale
+func (s *SaleService) checkRefundWarranty(ctx context.Context, sale *domain.Sale, now time.Time) error {
+	left, ok := sale.WarrantyDaysLeft(now)
+	if !ok || left >= 0 {
+		return nil
+	}
+	policy := getSettingString(ctx, s.settingRepo, SettingSaleRefundOutsideWarranty, &sale.BranchID, RefundOutsideWarrantyWarn)
+	if policy == RefundOutsideWarrantyBlock {
+		return ErrRefundOutsideWarranty
+	}
+	sale.RefundOutsideWarranty = true
+	return nil
+}
+
+// setWarrantyRemaining fills in the days left to return the item of a completed sale
+func setWarrantyRemaining(sale *domain.Sale, now time.Time) {
+	left, ok := sale.WarrantyDaysLeft(now)
+	if !ok || sale.Status != domain.SaleStatusCompleted {
+		return
+	}
+	if left < 0 {
+		left = 0
+	}
+	sale.WarrantyDaysRemaining = &left
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"pawnshop/internal/domain"
+)
+
+// ScheduledBackupResult is the outcome of a scheduled backup run
+type ScheduledBackupResult struct {
+	Backup *BackupInfo
+	Pruned int
+}
+
+// ScheduledBackupService takes the nightly backup and prunes the backups past
+// the retention count, telling admins how it went
+type ScheduledBackupService struct {
+	backupService BackupService
+	keep          int
+	router        *NotificationRouter
+	logger        zerolog.Logger
+}
+
+// NewScheduledBackupService creates a new ScheduledBackupService keeping the
+// newest keep backups
+func NewScheduledBackupService(backupService BackupService, keep int, logger zerolog.Logger) *ScheduledBackupService {
+	return &ScheduledBackupService{
+		backupService: backupService,
+		keep:          keep,
+		logger:        logger.With().Str("service", "scheduled_backup").Logger(),
+	}
+}
+
+// SetNotificationRouter enables notifying admins of each backup's outcome
+func (s *ScheduledBackupService) SetNotificationRouter(router *NotificationRouter) {
+	s.router = router
+}
+
+// Run creates a backup and prunes the old ones. Pruning only happens after a
+// successful backup, so a failing backup never leaves fewer copies behind.
+func (s *ScheduledBackupService) Run(ctx context.Context) (*ScheduledBackupResult, error) {
+	backup, err := s.backupService.ScheduledBackup(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Scheduled backup failed")
+		s.router.emit(ctx, InternalEvent{
+			Event:   domain.InternalEventBackupFailed,
+			Title:   "Respaldo fallido",
+			Message: fmt.Sprintf("No se pudo crear el respaldo programado: %s", err.Error()),
+			Type:    "error",
+		})
+		return nil, err
+	}
+
+	pruned, err := s.backupService.PruneBackups(ctx, s.keep)
+	if err != nil {
+		// The backup itself succeeded, so this only delays pruning to the next run
+		s.logger.Error().Err(err).Msg("Failed to prune old backups")
+	}
+
+	s.logger.Info().
+		Str("filename", backup.Filename).
+		Int("pruned", pruned).
+		Int("keep", s.keep).
+		Msg("Scheduled backup completed")
+
+	s.router.emit(ctx, InternalEvent{
+		Event: domain.InternalEventBackupCompleted,
+		Title: "Respaldo completado",
+		Message: fmt.Sprintf("Se creó el respaldo %s (%.2f MB); se eliminaron %d respaldos antiguos",
+			backup.Filename, float64(backup.Size)/1024/1024, pruned),
+		Type: "success",
+	})
+
+	return &ScheduledBackupResult{Backup: backup, Pruned: pruned}, nil
+}
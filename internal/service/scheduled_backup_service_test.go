@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackupService keeps its backup listing in memory
+type fakeBackupService struct {
+	BackupService
+	backups   []*BackupInfo
+	createErr error
+}
+
+func (f *fakeBackupService) ScheduledBackup(ctx context.Context) (*BackupInfo, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	backup := &BackupInfo{Filename: "pawnshop_backup_20261016_020000.sql.gz", CreatedAt: time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)}
+	f.backups = append(f.backups, backup)
+	return backup, nil
+}
+
+func (f *fakeBackupService) PruneBackups(ctx context.Context, keep int) (int, error) {
+	pruned := backupsToPrune(f.backups, keep)
+	for _, backup := range pruned {
+		f.DeleteBackup(ctx, backup.Filename)
+	}
+	return len(pruned), nil
+}
+
+func (f *fakeBackupService) DeleteBackup(ctx context.Context, filename string) error {
+	for i, backup := range f.backups {
+		if backup.Filename == filename {
+			f.backups = append(f.backups[:i], f.backups[i+1:]...)
+			return nil
+		}
+	}
+	return ErrBackupNotFound
+}
+
+func backupFilenames(backups []*BackupInfo) []string {
+	names := make([]string, len(backups))
+	for i, backup := range backups {
+		names[i] = backup.Filename
+	}
+	return names
+}
+
+func TestBackupsToPrune_KeepsNewest(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 2, 0, 0, 0, time.UTC) }
+	// Listed in directory order, not by age
+	backups := []*BackupInfo{
+		{Filename: "b", CreatedAt: day(12)},
+		{Filename: "d", CreatedAt: day(14)},
+		{Filename: "a", CreatedAt: day(11)},
+		{Filename: "e", CreatedAt: day(15)},
+		{Filename: "c", CreatedAt: day(13)},
+	}
+
+	assert.Equal(t, []string{"b", "a"}, backupFilenames(backupsToPrune(backups, 3)))
+	assert.Empty(t, backupsToPrune(backups, 5))
+	assert.Empty(t, backupsToPrune(backups, 10))
+	assert.Empty(t, backupsToPrune(backups, 0), "a missing retention must not delete every backup")
+}
+
+func TestScheduledBackupService_Run_PrunesOldBackups(t *testing.T) {
+	fake := &fakeBackupService{backups: []*BackupInfo{
+		{Filename: "pawnshop_backup_20261013_020000.sql.gz", CreatedAt: time.Date(2026, 10, 13, 2, 0, 0, 0, time.UTC)},
+		{Filename: "pawnshop_backup_20261015_020000.sql.gz", CreatedAt: time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)},
+		{Filename: "pawnshop_backup_20261014_020000.sql.gz", CreatedAt: time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)},
+	}}
+	svc := NewScheduledBackupService(fake, 2, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+
+	result, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "pawnshop_backup_20261016_020000.sql.gz", result.Backup.Filename)
+	assert.Equal(t, 2, result.Pruned)
+	assert.ElementsMatch(t, []string{
+		"pawnshop_backup_20261015_020000.sql.gz",
+		"pawnshop_backup_20261016_020000.sql.gz",
+	}, backupFilenames(fake.backups))
+}
+
+func TestScheduledBackupService_Run_FailedBackupPrunesNothing(t *testing.T) {
+	fake := &fakeBackupService{
+		backups: []*BackupInfo{
+			{Filename: "pawnshop_backup_20261014_020000.sql.gz", CreatedAt: time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)},
+			{Filename: "pawnshop_backup_20261015_020000.sql.gz", CreatedAt: time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)},
+		},
+		createErr: errors.New("pg_dump failed"),
+	}
+	svc := NewScheduledBackupService(fake, 1, zerolog.New(os.Stdout).Level(zerolog.Disabled))
+
+	_, err := svc.Run(context.Background())
+
+	assert.EqualError(t, err, "pg_dump failed")
+	assert.Len(t, fake.backups, 2)
+}
@@ -2,16 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository"
+	"pawnshop/pkg/logger"
 )
 
 // SettingService handles settings business logic
 type SettingService struct {
 	settingRepo repository.SettingRepository
+	historyRepo repository.SettingHistoryRepository
 }
 
 // NewSettingService creates a new SettingService
@@ -19,6 +24,11 @@ func NewSettingService(settingRepo repository.SettingRepository) *SettingService
 	return &SettingService{settingRepo: settingRepo}
 }
 
+// SetHistory enables recording every change made through the service
+func (s *SettingService) SetHistory(historyRepo repository.SettingHistoryRepository) {
+	s.historyRepo = historyRepo
+}
+
 // Get retrieves a setting by key
 func (s *SettingService) Get(ctx context.Context, key string, branchID *int64) (*domain.Setting, error) {
 	setting, err := s.settingRepo.Get(ctx, key, branchID)
@@ -66,6 +76,10 @@ type SetSettingInput struct {
 
 // Set creates or updates a setting
 func (s *SettingService) Set(ctx context.Context, input SetSettingInput) (*domain.Setting, error) {
+	if err := validateSettingValue(input.Key, input.Value); err != nil {
+		return nil, err
+	}
+
 	setting := &domain.Setting{
 		Key:         input.Key,
 		Value:       input.Value,
@@ -73,7 +87,7 @@ func (s *SettingService) Set(ctx context.Context, input SetSettingInput) (*domai
 		BranchID:    input.BranchID,
 	}
 
-	if err := s.settingRepo.Set(ctx, setting); err != nil {
+	if err := s.save(ctx, setting); err != nil {
 		return nil, fmt.Errorf("failed to save setting: %w", err)
 	}
 
@@ -82,6 +96,12 @@ func (s *SettingService) Set(ctx context.Context, input SetSettingInput) (*domai
 
 // SetMultiple sets multiple settings at once
 func (s *SettingService) SetMultiple(ctx context.Context, settings []SetSettingInput) error {
+	for _, input := range settings {
+		if err := validateSettingValue(input.Key, input.Value); err != nil {
+			return err
+		}
+	}
+
 	for _, input := range settings {
 		setting := &domain.Setting{
 			Key:         input.Key,
@@ -90,7 +110,7 @@ func (s *SettingService) SetMultiple(ctx context.Context, settings []SetSettingI
 			BranchID:    input.BranchID,
 		}
 
-		if err := s.settingRepo.Set(ctx, setting); err != nil {
+		if err := s.save(ctx, setting); err != nil {
 			return fmt.Errorf("failed to save settings: %w", err)
 		}
 	}
@@ -100,7 +120,301 @@ func (s *SettingService) SetMultiple(ctx context.Context, settings []SetSettingI
 
 // Delete deletes a setting
 func (s *SettingService) Delete(ctx context.Context, key string, branchID *int64) error {
-	return s.settingRepo.Delete(ctx, key, branchID)
+	oldValue := s.previousValue(ctx, key, branchID)
+	if err := s.settingRepo.Delete(ctx, key, branchID); err != nil {
+		return err
+	}
+	return s.recordChange(ctx, key, branchID, domain.SettingChangeDelete, oldValue, nil)
+}
+
+// save stores a setting and records the change in its history
+func (s *SettingService) save(ctx context.Context, setting *domain.Setting) error {
+	oldValue := s.previousValue(ctx, setting.Key, setting.BranchID)
+	if err := s.settingRepo.Set(ctx, setting); err != nil {
+		return err
+	}
+	return s.recordChange(ctx, setting.Key, setting.BranchID, domain.SettingChangeSet, oldValue, setting.Value)
+}
+
+// validateSettingValue rejects values of settings with a known structure that
+// would otherwise be silently misread when used
+func validateSettingValue(key string, value interface{}) error {
+	switch key {
+	case SettingLoanPhotoRequirements:
+		var requirements LoanPhotoRequirements
+		if err := decodeSettingValue(value, &requirements); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidInput, key, err)
+		}
+		return requirements.Validate()
+	}
+	return nil
+}
+
+// decodeSettingValue decodes a JSON setting value into out
+func decodeSettingValue(value interface{}, out interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// previousValue returns the value a setting holds in exactly the given scope,
+// nil when it is not set there. It is only looked up when history is kept.
+func (s *SettingService) previousValue(ctx context.Context, key string, branchID *int64) interface{} {
+	if s.historyRepo == nil {
+		return nil
+	}
+	setting, err := s.settingRepo.Get(ctx, key, branchID)
+	// Get falls back to the global setting, which is not the one changing
+	if err != nil || setting == nil || !sameInt64(setting.BranchID, branchID) {
+		return nil
+	}
+	return setting.Value
+}
+
+// settingHistoryRedacted replaces the values of secret settings in their history
+const settingHistoryRedacted = "[REDACTED]"
+
+// recordChange adds a change to a setting's history
+func (s *SettingService) recordChange(ctx context.Context, key string, branchID *int64, action string, oldValue, newValue interface{}) error {
+	entry := s.historyEntry(ctx, key, branchID, action, oldValue, newValue)
+	if entry == nil {
+		return nil
+	}
+	if err := s.historyRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record history of setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// historyEntry builds the history entry of a change, attributed to the user
+// of the request. It returns nil when no history is kept or the write leaves
+// the value as it was.
+func (s *SettingService) historyEntry(ctx context.Context, key string, branchID *int64, action string, oldValue, newValue interface{}) *domain.SettingHistory {
+	if s.historyRepo == nil {
+		return nil
+	}
+	if action == domain.SettingChangeSet && sameSettingValue(oldValue, newValue) {
+		return nil
+	}
+
+	if IsSecretSetting(key) {
+		if oldValue != nil {
+			oldValue = settingHistoryRedacted
+		}
+		if newValue != nil {
+			newValue = settingHistoryRedacted
+		}
+	}
+
+	entry := &domain.SettingHistory{
+		SettingKey: key,
+		BranchID:   branchID,
+		Action:     action,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+	}
+	if userID := logger.GetUserID(ctx); userID != 0 {
+		entry.ChangedBy = &userID
+	}
+	return entry
+}
+
+// sameSettingValue compares setting values by their stored JSON form, so a
+// number read back from the database equals the one just submitted
+func sameSettingValue(a, b interface{}) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
+
+// Setting history page sizes
+const (
+	DefaultSettingHistoryLimit = 50
+	MaxSettingHistoryLimit     = 200
+)
+
+// History returns the latest changes to a setting, newest first. A nil branch
+// lists the changes to the global setting.
+func (s *SettingService) History(ctx context.Context, key string, branchID *int64, limit int) ([]*domain.SettingHistory, error) {
+	if s.historyRepo == nil {
+		return nil, errors.New("setting history is not enabled")
+	}
+	if limit <= 0 {
+		limit = DefaultSettingHistoryLimit
+	}
+	if limit > MaxSettingHistoryLimit {
+		limit = MaxSettingHistoryLimit
+	}
+	return s.historyRepo.ListByKey(ctx, key, branchID, limit)
+}
+
+// Settings export format and import modes
+const (
+	SettingsExportVersion = 1
+
+	SettingsImportModeMerge   = "merge"
+	SettingsImportModeReplace = "replace"
+)
+
+// Settings import errors
+var (
+	ErrInvalidSettingsFile = errors.New("invalid settings file")
+	ErrInvalidImportMode   = errors.New("invalid import mode")
+)
+
+// secretSettingMarkers identify settings that must never leave the environment
+var secretSettingMarkers = []string{"password", "secret", "token", "api_key", "private_key"}
+
+// IsSecretSetting reports whether a setting key holds a credential
+func IsSecretSetting(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range secretSettingMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingsExport is a versioned snapshot of the global settings
+type SettingsExport struct {
+	Version    int                   `json:"version"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Settings   []SettingsExportEntry `json:"settings"`
+}
+
+// SettingsExportEntry is a single exported setting
+type SettingsExportEntry struct {
+	Key         string      `json:"key"`
+	Value       interface{} `json:"value"`
+	Description string      `json:"description,omitempty"`
+}
+
+// SettingsImportResult summarizes an applied import
+type SettingsImportResult struct {
+	Mode    string   `json:"mode"`
+	Updated int      `json:"updated"`
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// Export returns the global settings, excluding secrets. Branch overrides are
+// tied to the branch IDs of each environment and are not exported.
+func (s *SettingService) Export(ctx context.Context) (*SettingsExport, error) {
+	settings, err := s.settingRepo.GetAll(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings: %w", err)
+	}
+
+	export := &SettingsExport{
+		Version:    SettingsExportVersion,
+		ExportedAt: time.Now(),
+		Settings:   []SettingsExportEntry{},
+	}
+	for _, setting := range settings {
+		if setting.BranchID != nil || IsSecretSetting(setting.Key) {
+			continue
+		}
+		export.Settings = append(export.Settings, SettingsExportEntry{
+			Key:         setting.Key,
+			Value:       setting.Value,
+			Description: setting.Description,
+		})
+	}
+
+	return export, nil
+}
+
+// Import applies an exported settings file to the global settings. In merge
+// mode only the imported keys change; in replace mode non-secret settings
+// missing from the file are deleted. Every key must already exist here, so a
+// typo or a setting from a newer release is rejected before anything is saved.
+func (s *SettingService) Import(ctx context.Context, data []byte, mode string) (*SettingsImportResult, error) {
+	if mode == "" {
+		mode = SettingsImportModeMerge
+	}
+	if mode != SettingsImportModeMerge && mode != SettingsImportModeReplace {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImportMode, mode)
+	}
+
+	var export SettingsExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSettingsFile, err)
+	}
+	if export.Version != SettingsExportVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSettingsFile, export.Version)
+	}
+
+	current, err := s.settingRepo.GetAll(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings: %w", err)
+	}
+	known := make(map[string]*domain.Setting, len(current))
+	for _, setting := range current {
+		if setting.BranchID == nil {
+			known[setting.Key] = setting
+		}
+	}
+
+	imported := make(map[string]bool, len(export.Settings))
+	for _, entry := range export.Settings {
+		switch {
+		case entry.Key == "":
+			return nil, fmt.Errorf("%w: setting without key", ErrInvalidSettingsFile)
+		case imported[entry.Key]:
+			return nil, fmt.Errorf("%w: duplicate setting %s", ErrInvalidSettingsFile, entry.Key)
+		case IsSecretSetting(entry.Key):
+			return nil, fmt.Errorf("%w: secret setting %s cannot be imported", ErrInvalidSettingsFile, entry.Key)
+		case known[entry.Key] == nil:
+			return nil, fmt.Errorf("%w: unknown setting %s", ErrInvalidSettingsFile, entry.Key)
+		case entry.Value == nil:
+			return nil, fmt.Errorf("%w: setting %s has no value", ErrInvalidSettingsFile, entry.Key)
+		}
+		if err := validateSettingValue(entry.Key, entry.Value); err != nil {
+			return nil, fmt.Errorf("%w: setting %s: %v", ErrInvalidSettingsFile, entry.Key, err)
+		}
+		imported[entry.Key] = true
+	}
+
+	result := &SettingsImportResult{Mode: mode}
+	var settings []*domain.Setting
+	var history []*domain.SettingHistory
+	for _, entry := range export.Settings {
+		description := entry.Description
+		if description == "" {
+			description = known[entry.Key].Description
+		}
+		settings = append(settings, &domain.Setting{
+			Key:         entry.Key,
+			Value:       entry.Value,
+			Description: description,
+		})
+		if change := s.historyEntry(ctx, entry.Key, nil, domain.SettingChangeSet, known[entry.Key].Value, entry.Value); change != nil {
+			history = append(history, change)
+		}
+		result.Updated++
+	}
+
+	if mode == SettingsImportModeReplace {
+		for _, setting := range current {
+			if setting.BranchID != nil || imported[setting.Key] || IsSecretSetting(setting.Key) {
+				continue
+			}
+			if change := s.historyEntry(ctx, setting.Key, nil, domain.SettingChangeDelete, setting.Value, nil); change != nil {
+				history = append(history, change)
+			}
+			result.Deleted = append(result.Deleted, setting.Key)
+		}
+	}
+
+	// The whole file is applied in one transaction, so a failure part way
+	// through leaves every setting as it was
+	if err := s.settingRepo.Import(ctx, settings, result.Deleted, history); err != nil {
+		return nil, fmt.Errorf("failed to import settings: %w", err)
+	}
+
+	return result, nil
 }
 
 // GetString retrieves a setting value as string
@@ -159,3 +473,99 @@ func (s *SettingService) GetBool(ctx context.Context, key string, branchID *int6
 	}
 	return defaultValue
 }
+
+// getSettingFloat reads a numeric setting directly from the repository, falling back to a default
+func getSettingFloat(ctx context.Context, repo repository.SettingRepository, key string, branchID *int64, defaultValue float64) float64 {
+	if repo == nil {
+		return defaultValue
+	}
+	setting, err := repo.Get(ctx, key, branchID)
+	if err != nil {
+		return defaultValue
+	}
+
+	switch v := setting.Value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return defaultValue
+}
+
+// getSettingBool reads a boolean setting directly from the repository, falling back to a default
+func getSettingBool(ctx context.Context, repo repository.SettingRepository, key string, branchID *int64, defaultValue bool) bool {
+	if repo == nil {
+		return defaultValue
+	}
+	setting, err := repo.Get(ctx, key, branchID)
+	if err != nil {
+		return defaultValue
+	}
+
+	if v, ok := setting.Value.(bool); ok {
+		return v
+	}
+	return defaultValue
+}
+
+// getSettingString reads a string setting directly from the repository, falling back to a default
+func getSettingString(ctx context.Context, repo repository.SettingRepository, key string, branchID *int64, defaultValue string) string {
+	if repo == nil {
+		return defaultValue
+	}
+	setting, err := repo.Get(ctx, key, branchID)
+	if err != nil {
+		return defaultValue
+	}
+
+	if v, ok := setting.Value.(string); ok && v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// getSettingJSON decodes a structured setting directly from the repository into out.
+// It reports false when the setting is missing or does not match the shape of out.
+func getSettingJSON(ctx context.Context, repo repository.SettingRepository, key string, branchID *int64, out interface{}) bool {
+	if repo == nil {
+		return false
+	}
+	setting, err := repo.Get(ctx, key, branchID)
+	if err != nil || setting.Value == nil {
+		return false
+	}
+
+	raw, err := json.Marshal(setting.Value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, out) == nil
+}
+
+// SettingSequenceResetCadence controls when loan and payment numbers restart at 1:
+// never, yearly or monthly
+const SettingSequenceResetCadence = "sequence_reset_cadence"
+
+// sequenceResetCadence reads the numbering reset cadence of a branch, defaulting to yearly
+func sequenceResetCadence(ctx context.Context, repo repository.SettingRepository, branchID int64) domain.SequenceResetCadence {
+	if repo == nil {
+		return domain.SequenceResetYearly
+	}
+	setting, err := repo.Get(ctx, SettingSequenceResetCadence, &branchID)
+	if err != nil {
+		return domain.SequenceResetYearly
+	}
+
+	if v, ok := setting.Value.(string); ok && domain.SequenceResetCadence(v).IsValid() {
+		return domain.SequenceResetCadence(v)
+	}
+	return domain.SequenceResetYearly
+}
+
+// getSettingInt reads an integer setting directly from the repository, falling back to a default
+func getSettingInt(ctx context.Context, repo repository.SettingRepository, key string, branchID *int64, defaultValue int) int {
+	return int(getSettingFloat(ctx, repo, key, branchID, float64(defaultValue)))
+}
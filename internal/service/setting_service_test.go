@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"pawnshop/internal/domain"
 	"pawnshop/internal/repository/mocks"
+	"pawnshop/pkg/logger"
 )
 
 func setupSettingService() (*SettingService, *mocks.MockSettingRepository) {
@@ -120,6 +123,21 @@ func TestSettingService_Set_Success(t *testing.T) {
 	settingRepo.AssertExpectations(t)
 }
 
+func TestSettingService_Set_RejectsUnknownRequiredDocument(t *testing.T) {
+	service, settingRepo := setupSettingService()
+
+	input := SetSettingInput{Key: SettingLoanPhotoRequirements, Value: map[string]interface{}{
+		"min_loan_amount":    float64(5000),
+		"required_documents": []interface{}{LoanDocumentCustomerIdentity, "customer_passport"},
+	}}
+	result, err := service.Set(context.Background(), input)
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Contains(t, err.Error(), `"customer_passport"`)
+	assert.Nil(t, result)
+	settingRepo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+}
+
 func TestSettingService_Set_Error(t *testing.T) {
 	service, settingRepo := setupSettingService()
 	ctx := context.Background()
@@ -337,3 +355,257 @@ func TestSettingService_GetBool_WrongType_ReturnsDefault(t *testing.T) {
 	assert.False(t, result)
 	settingRepo.AssertExpectations(t)
 }
+
+// Export / Import
+
+func exportFixtures() []*domain.Setting {
+	branchID := int64(2)
+	return []*domain.Setting{
+		{Key: "company_name", Value: "Casa de Empeño", Description: "Nombre de la empresa"},
+		{Key: "default_interest_rate", Value: 10.0, Description: "Tasa de interés"},
+		{Key: "require_minimum_payment", Value: true},
+		{Key: "expense_category_keywords", Value: map[string]interface{}{"luz": "UTL"}},
+		{Key: "sms_api_key", Value: "s3cr3t"},
+		{Key: "default_interest_rate", Value: 8.0, BranchID: &branchID},
+	}
+}
+
+func TestSettingService_Export_ExcludesSecretsAndBranchOverrides(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	ctx := context.Background()
+
+	settingRepo.On("GetAll", ctx, (*int64)(nil)).Return(exportFixtures(), nil)
+
+	export, err := service.Export(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, SettingsExportVersion, export.Version)
+	var keys []string
+	for _, entry := range export.Settings {
+		keys = append(keys, entry.Key)
+	}
+	assert.Equal(t, []string{"company_name", "default_interest_rate", "require_minimum_payment", "expense_category_keywords"}, keys)
+	assert.Equal(t, 10.0, export.Settings[1].Value)
+}
+
+func TestSettingService_ExportImport_RoundTripLeavesSettingsUnchanged(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	ctx := context.Background()
+
+	fixtures := exportFixtures()
+	settingRepo.On("GetAll", ctx, (*int64)(nil)).Return(fixtures, nil)
+
+	saved := map[string]*domain.Setting{}
+	var deleted []string
+	settingRepo.On("Import", ctx, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, setting := range args.Get(1).([]*domain.Setting) {
+			saved[setting.Key] = setting
+		}
+		deleted = args.Get(2).([]string)
+	}).Return(nil)
+
+	export, err := service.Export(ctx)
+	require.NoError(t, err)
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+
+	result, err := service.Import(ctx, data, SettingsImportModeReplace)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Updated)
+	assert.Empty(t, result.Deleted)
+	for _, original := range fixtures[:4] {
+		require.Contains(t, saved, original.Key)
+		assert.Equal(t, original.Value, saved[original.Key].Value)
+		assert.Equal(t, original.Description, saved[original.Key].Description)
+		assert.Nil(t, saved[original.Key].BranchID)
+	}
+	assert.Empty(t, deleted)
+}
+
+func TestSettingService_Import_RejectsUnknownKey(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	ctx := context.Background()
+
+	settingRepo.On("GetAll", ctx, (*int64)(nil)).Return(exportFixtures(), nil)
+
+	data := []byte(`{"version": 1, "settings": [
+		{"key": "company_name", "value": "Nueva"},
+		{"key": "company_nmae", "value": "Typo"}
+	]}`)
+
+	result, err := service.Import(ctx, data, SettingsImportModeMerge)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInvalidSettingsFile)
+	assert.Contains(t, err.Error(), "unknown setting company_nmae")
+	settingRepo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSettingService_Import_ReplaceDeletesMissingSettings(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	ctx := context.Background()
+
+	settingRepo.On("GetAll", ctx, (*int64)(nil)).Return(exportFixtures(), nil)
+	deleted := []string{"default_interest_rate", "require_minimum_payment", "expense_category_keywords"}
+	settingRepo.On("Import", ctx, mock.AnythingOfType("[]*domain.Setting"), deleted, mock.Anything).Return(nil)
+
+	data := []byte(`{"version": 1, "settings": [{"key": "company_name", "value": "Nueva"}]}`)
+
+	result, err := service.Import(ctx, data, SettingsImportModeReplace)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, deleted, result.Deleted)
+	settingRepo.AssertExpectations(t)
+}
+
+func TestSettingService_Import_RecordsHistoryInTheSameTransaction(t *testing.T) {
+	settingRepo := new(mocks.MockSettingRepository)
+	historyRepo := new(mocks.MockSettingHistoryRepository)
+	service := NewSettingService(settingRepo)
+	service.SetHistory(historyRepo)
+	ctx := context.Background()
+
+	settingRepo.On("GetAll", ctx, (*int64)(nil)).Return(exportFixtures(), nil)
+	var history []*domain.SettingHistory
+	settingRepo.On("Import", ctx, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		history = args.Get(3).([]*domain.SettingHistory)
+	}).Return(nil)
+
+	data := []byte(`{"version": 1, "settings": [
+		{"key": "company_name", "value": "Nueva"},
+		{"key": "default_interest_rate", "value": 10}
+	]}`)
+
+	_, err := service.Import(ctx, data, SettingsImportModeMerge)
+
+	require.NoError(t, err)
+	// The unchanged interest rate is not recorded
+	require.Len(t, history, 1)
+	assert.Equal(t, "company_name", history[0].SettingKey)
+	assert.Equal(t, "Casa de Empeño", history[0].OldValue)
+	assert.Equal(t, "Nueva", history[0].NewValue)
+	historyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestSettingService_Import_FailureReturnsError(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	ctx := context.Background()
+
+	settingRepo.On("GetAll", ctx, (*int64)(nil)).Return(exportFixtures(), nil)
+	settingRepo.On("Import", ctx, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+	data := []byte(`{"version": 1, "settings": [{"key": "company_name", "value": "Nueva"}]}`)
+
+	result, err := service.Import(ctx, data, SettingsImportModeMerge)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "failed to import settings: connection reset")
+	assert.NotErrorIs(t, err, ErrInvalidSettingsFile)
+}
+
+func TestSettingService_Import_InvalidInput(t *testing.T) {
+	service, _ := setupSettingService()
+	ctx := context.Background()
+
+	_, err := service.Import(ctx, []byte(`{"version": 1}`), "overwrite")
+	assert.EqualError(t, err, "invalid import mode: overwrite")
+
+	_, err = service.Import(ctx, []byte(`{"version": 99}`), SettingsImportModeMerge)
+	assert.EqualError(t, err, "invalid settings file: unsupported version 99")
+}
+
+func TestSequenceResetCadence_ReadsBranchSetting(t *testing.T) {
+	settingRepo := new(mocks.MockSettingRepository)
+	ctx := context.Background()
+	monthlyBranch, invalidBranch, unsetBranch := int64(1), int64(2), int64(3)
+
+	settingRepo.On("Get", ctx, SettingSequenceResetCadence, &monthlyBranch).Return(&domain.Setting{Value: "monthly"}, nil)
+	settingRepo.On("Get", ctx, SettingSequenceResetCadence, &invalidBranch).Return(&domain.Setting{Value: "weekly"}, nil)
+	settingRepo.On("Get", ctx, SettingSequenceResetCadence, &unsetBranch).Return(nil, errors.New("setting not found"))
+
+	assert.Equal(t, domain.SequenceResetMonthly, sequenceResetCadence(ctx, settingRepo, monthlyBranch))
+	assert.Equal(t, domain.SequenceResetYearly, sequenceResetCadence(ctx, settingRepo, invalidBranch))
+	assert.Equal(t, domain.SequenceResetYearly, sequenceResetCadence(ctx, settingRepo, unsetBranch))
+	assert.Equal(t, domain.SequenceResetYearly, sequenceResetCadence(ctx, nil, monthlyBranch))
+}
+
+func TestSettingService_Set_RecordsHistory(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	historyRepo := new(mocks.MockSettingHistoryRepository)
+	service.SetHistory(historyRepo)
+	ctx := logger.WithUserID(context.Background(), 5)
+	branchID := int64(2)
+
+	settingRepo.On("Get", ctx, "loan_two_person_threshold", &branchID).
+		Return(&domain.Setting{Key: "loan_two_person_threshold", Value: 5000.0, BranchID: &branchID}, nil)
+	settingRepo.On("Set", ctx, mock.AnythingOfType("*domain.Setting")).Return(nil)
+	historyRepo.On("Create", ctx, mock.MatchedBy(func(entry *domain.SettingHistory) bool {
+		return entry.SettingKey == "loan_two_person_threshold" &&
+			*entry.BranchID == branchID &&
+			entry.Action == domain.SettingChangeSet &&
+			entry.OldValue == 5000.0 &&
+			entry.NewValue == 8000.0 &&
+			entry.ChangedBy != nil && *entry.ChangedBy == 5
+	})).Return(nil)
+
+	_, err := service.Set(ctx, SetSettingInput{Key: "loan_two_person_threshold", Value: 8000.0, BranchID: &branchID})
+
+	require.NoError(t, err)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestSettingService_Set_HistoryOfBranchOverrideStartsEmpty(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	historyRepo := new(mocks.MockSettingHistoryRepository)
+	service.SetHistory(historyRepo)
+	ctx := context.Background()
+	branchID := int64(2)
+
+	// The global value the branch falls back to is not the one changing
+	settingRepo.On("Get", ctx, "app_name", &branchID).Return(&domain.Setting{Key: "app_name", Value: "PawnShop"}, nil)
+	settingRepo.On("Set", ctx, mock.AnythingOfType("*domain.Setting")).Return(nil)
+	historyRepo.On("Create", ctx, mock.MatchedBy(func(entry *domain.SettingHistory) bool {
+		return entry.OldValue == nil && entry.NewValue == "Sucursal Norte" && entry.ChangedBy == nil
+	})).Return(nil)
+
+	_, err := service.Set(ctx, SetSettingInput{Key: "app_name", Value: "Sucursal Norte", BranchID: &branchID})
+
+	require.NoError(t, err)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestSettingService_Set_RedactsSecretHistory(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	historyRepo := new(mocks.MockSettingHistoryRepository)
+	service.SetHistory(historyRepo)
+	ctx := context.Background()
+
+	settingRepo.On("Get", ctx, "sms_api_key", (*int64)(nil)).Return(&domain.Setting{Key: "sms_api_key", Value: "old-key"}, nil)
+	settingRepo.On("Set", ctx, mock.AnythingOfType("*domain.Setting")).Return(nil)
+	historyRepo.On("Create", ctx, mock.MatchedBy(func(entry *domain.SettingHistory) bool {
+		return entry.OldValue == settingHistoryRedacted && entry.NewValue == settingHistoryRedacted
+	})).Return(nil)
+
+	_, err := service.Set(ctx, SetSettingInput{Key: "sms_api_key", Value: "new-key"})
+
+	require.NoError(t, err)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestSettingService_Set_UnchangedValueNotRecorded(t *testing.T) {
+	service, settingRepo := setupSettingService()
+	historyRepo := new(mocks.MockSettingHistoryRepository)
+	service.SetHistory(historyRepo)
+	ctx := context.Background()
+
+	settingRepo.On("Get", ctx, "app_name", (*int64)(nil)).Return(&domain.Setting{Key: "app_name", Value: "PawnShop"}, nil)
+	settingRepo.On("Set", ctx, mock.AnythingOfType("*domain.Setting")).Return(nil)
+
+	_, err := service.Set(ctx, SetSettingInput{Key: "app_name", Value: "PawnShop"})
+
+	require.NoError(t, err)
+	historyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingStockTakeLockItems selects whether a branch's items cannot be edited
+// while it has a count in progress. When disabled, edits are allowed and the
+// items edited during the count are flagged in its report.
+const SettingStockTakeLockItems = "stock_take_lock_items"
+
+// stockTakeItemPageSize is how many branch items are read per query when building a report
+const stockTakeItemPageSize = 200
+
+// Stock take errors
+var (
+	ErrStockTakeNotFound   = errors.New("stock take not found")
+	ErrStockTakeInProgress = errors.New("an inventory count is in progress for this branch")
+	ErrStockTakeClosed     = errors.New("stock take is not in progress")
+)
+
+// StockTakeService runs physical inventory counts and compares them with the system
+type StockTakeService struct {
+	stockTakeRepo repository.StockTakeRepository
+	itemRepo      repository.ItemRepository
+	branchRepo    repository.BranchRepository
+}
+
+// NewStockTakeService creates a new StockTakeService
+func NewStockTakeService(
+	stockTakeRepo repository.StockTakeRepository,
+	itemRepo repository.ItemRepository,
+	branchRepo repository.BranchRepository,
+) *StockTakeService {
+	return &StockTakeService{
+		stockTakeRepo: stockTakeRepo,
+		itemRepo:      itemRepo,
+		branchRepo:    branchRepo,
+	}
+}
+
+// StartStockTakeInput represents start stock take request data
+type StartStockTakeInput struct {
+	BranchID  int64   `json:"branch_id" validate:"required"`
+	Notes     *string `json:"notes"`
+	StartedBy int64   `json:"-"`
+}
+
+// Start starts a count of a branch
+func (s *StockTakeService) Start(ctx context.Context, input StartStockTakeInput) (*domain.StockTake, error) {
+	if _, err := s.branchRepo.GetByID(ctx, input.BranchID); err != nil {
+		return nil, ErrBranchNotFound
+	}
+
+	stockTake := &domain.StockTake{
+		BranchID:  input.BranchID,
+		Status:    domain.StockTakeStatusInProgress,
+		Notes:     input.Notes,
+		StartedBy: input.StartedBy,
+	}
+
+	created, err := s.stockTakeRepo.Create(ctx, stockTake)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrStockTakeInProgress
+	}
+	return stockTake, nil
+}
+
+// GetByID retrieves a stock take
+func (s *StockTakeService) GetByID(ctx context.Context, id int64) (*domain.StockTake, error) {
+	stockTake, err := s.stockTakeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrStockTakeNotFound
+	}
+	return stockTake, nil
+}
+
+// List retrieves stock takes
+func (s *StockTakeService) List(ctx context.Context, params repository.StockTakeListParams) (*repository.PaginatedResult[domain.StockTake], error) {
+	return s.stockTakeRepo.List(ctx, params)
+}
+
+// RecordScanInput represents a scanned or typed item SKU
+type RecordScanInput struct {
+	SKU       string `json:"sku" validate:"required,max=100"`
+	ScannedBy int64  `json:"-"`
+}
+
+// RecordScan records an item found on the shelves. Scanning a SKU that was
+// already counted returns its first scan.
+func (s *StockTakeService) RecordScan(ctx context.Context, stockTakeID int64, input RecordScanInput) (*domain.StockTakeScan, error) {
+	stockTake, err := s.GetByID(ctx, stockTakeID)
+	if err != nil {
+		return nil, err
+	}
+	if !stockTake.IsInProgress() {
+		return nil, ErrStockTakeClosed
+	}
+
+	scan := &domain.StockTakeScan{
+		StockTakeID: stockTake.ID,
+		SKU:         strings.TrimSpace(input.SKU),
+		ScannedBy:   input.ScannedBy,
+	}
+	if scan.SKU == "" {
+		return nil, ErrInvalidInput
+	}
+	if item, err := s.itemRepo.GetBySKU(ctx, scan.SKU); err == nil && item != nil {
+		scan.ItemID = &item.ID
+	}
+
+	if _, err := s.stockTakeRepo.AddScan(ctx, scan); err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
+
+// Report returns the discrepancy report of a count: the stored one once it is
+// finalized, or the current comparison while it is in progress
+func (s *StockTakeService) Report(ctx context.Context, id int64) (*domain.StockTakeReport, error) {
+	stockTake, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stockTake.Report != nil {
+		return stockTake.Report, nil
+	}
+	return s.buildReport(ctx, stockTake)
+}
+
+// Finalize closes a count and stores its discrepancy report
+func (s *StockTakeService) Finalize(ctx context.Context, id int64, finalizedBy int64) (*domain.StockTake, error) {
+	stockTake, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !stockTake.IsInProgress() {
+		return nil, ErrStockTakeClosed
+	}
+
+	report, err := s.buildReport(ctx, stockTake)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.stockTakeRepo.Finalize(ctx, id, finalizedBy, report); err != nil {
+		return nil, ErrStockTakeClosed
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Cancel abandons a count, releasing the branch's items
+func (s *StockTakeService) Cancel(ctx context.Context, id int64, cancelledBy int64) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.stockTakeRepo.Cancel(ctx, id, cancelledBy); err != nil {
+		return ErrStockTakeClosed
+	}
+	return nil
+}
+
+// buildReport compares the items the system expects in the branch with the SKUs scanned
+func (s *StockTakeService) buildReport(ctx context.Context, stockTake *domain.StockTake) (*domain.StockTakeReport, error) {
+	branchItems := make(map[string]*domain.Item)
+	for page := 1; ; page++ {
+		result, err := s.itemRepo.List(ctx, repository.ItemListParams{
+			BranchID:         stockTake.BranchID,
+			PaginationParams: repository.PaginationParams{Page: page, PerPage: stockTakeItemPageSize},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range result.Data {
+			item := &result.Data[i]
+			branchItems[item.SKU] = item
+		}
+		if page >= result.TotalPages || len(result.Data) == 0 {
+			break
+		}
+	}
+
+	scans, err := s.stockTakeRepo.ListScans(ctx, stockTake.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.StockTakeReport{
+		ScannedCount:   len(scans),
+		Missing:        []domain.StockTakeDiscrepancy{},
+		Extra:          []domain.StockTakeDiscrepancy{},
+		StatusMismatch: []domain.StockTakeDiscrepancy{},
+	}
+
+	discrepancy := func(kind domain.StockTakeDiscrepancyType, sku string, item *domain.Item) domain.StockTakeDiscrepancy {
+		d := domain.StockTakeDiscrepancy{Type: kind, SKU: sku}
+		if item != nil {
+			id, branchID := item.ID, item.BranchID
+			d.ItemID = &id
+			d.ItemName = item.Name
+			d.Status = item.Status
+			d.BranchID = &branchID
+			d.ChangedDuringCount = item.UpdatedAt.After(stockTake.StartedAt)
+		}
+		return d
+	}
+
+	scanned := make(map[string]bool, len(scans))
+	for _, scan := range scans {
+		scanned[scan.SKU] = true
+
+		item, inBranch := branchItems[scan.SKU]
+		switch {
+		case !inBranch:
+			// Unknown SKU, or an item registered in another branch
+			other, err := s.itemRepo.GetBySKU(ctx, scan.SKU)
+			if err != nil {
+				other = nil
+			}
+			report.Extra = append(report.Extra, discrepancy(domain.StockTakeExtra, scan.SKU, other))
+		case !item.IsOnPremises():
+			report.StatusMismatch = append(report.StatusMismatch, discrepancy(domain.StockTakeStatusMismatch, scan.SKU, item))
+		default:
+			report.MatchedCount++
+		}
+	}
+
+	for sku, item := range branchItems {
+		if !item.IsOnPremises() {
+			continue
+		}
+		report.ExpectedCount++
+		if !scanned[sku] {
+			report.Missing = append(report.Missing, discrepancy(domain.StockTakeMissing, sku, item))
+		}
+	}
+
+	for _, list := range [][]domain.StockTakeDiscrepancy{report.Missing, report.Extra, report.StatusMismatch} {
+		sort.Slice(list, func(i, j int) bool { return list[i].SKU < list[j].SKU })
+	}
+
+	return report, nil
+}
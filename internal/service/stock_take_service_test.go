@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+type stockTakeMocks struct {
+	stockTakeRepo *mocks.MockStockTakeRepository
+	itemRepo      *mocks.MockItemRepository
+	branchRepo    *mocks.MockBranchRepository
+}
+
+func setupStockTakeService() (*StockTakeService, stockTakeMocks) {
+	m := stockTakeMocks{
+		stockTakeRepo: new(mocks.MockStockTakeRepository),
+		itemRepo:      new(mocks.MockItemRepository),
+		branchRepo:    new(mocks.MockBranchRepository),
+	}
+	return NewStockTakeService(m.stockTakeRepo, m.itemRepo, m.branchRepo), m
+}
+
+func TestStockTakeService_Report_MissingExtraAndStatusMismatch(t *testing.T) {
+	service, m := setupStockTakeService()
+	ctx := context.Background()
+	startedAt := time.Now().Add(-time.Hour)
+
+	m.stockTakeRepo.On("GetByID", ctx, int64(1)).Return(&domain.StockTake{
+		ID: 1, BranchID: 1, Status: domain.StockTakeStatusInProgress, StartedAt: startedAt,
+	}, nil)
+	m.itemRepo.On("List", ctx, mock.MatchedBy(func(p repository.ItemListParams) bool {
+		return p.BranchID == 1 && p.Page == 1
+	})).Return(&repository.PaginatedResult[domain.Item]{
+		Data: []domain.Item{
+			{ID: 10, BranchID: 1, SKU: "SKU-010", Name: "Ring", Status: domain.ItemStatusPawned, UpdatedAt: startedAt.Add(-time.Hour)},
+			{ID: 11, BranchID: 1, SKU: "SKU-011", Name: "Watch", Status: domain.ItemStatusForSale, UpdatedAt: startedAt.Add(-time.Hour)},
+			{ID: 12, BranchID: 1, SKU: "SKU-012", Name: "Laptop", Status: domain.ItemStatusSold, UpdatedAt: startedAt.Add(-time.Hour)},
+		},
+		Page: 1, TotalPages: 1,
+	}, nil)
+	m.stockTakeRepo.On("ListScans", ctx, int64(1)).Return([]*domain.StockTakeScan{
+		{StockTakeID: 1, SKU: "SKU-010"},
+		{StockTakeID: 1, SKU: "SKU-012"},
+		{StockTakeID: 1, SKU: "SKU-999"},
+	}, nil)
+	m.itemRepo.On("GetBySKU", ctx, "SKU-999").Return(nil, errors.New("item not found"))
+
+	report, err := service.Report(ctx, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.ExpectedCount)
+	assert.Equal(t, 3, report.ScannedCount)
+	assert.Equal(t, 1, report.MatchedCount)
+
+	// In the system but never scanned
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, domain.StockTakeMissing, report.Missing[0].Type)
+	assert.Equal(t, "SKU-011", report.Missing[0].SKU)
+	assert.Equal(t, int64(11), *report.Missing[0].ItemID)
+
+	// Scanned but unknown to the system
+	require.Len(t, report.Extra, 1)
+	assert.Equal(t, domain.StockTakeExtra, report.Extra[0].Type)
+	assert.Equal(t, "SKU-999", report.Extra[0].SKU)
+	assert.Nil(t, report.Extra[0].ItemID)
+
+	// Scanned although the system says it was sold
+	require.Len(t, report.StatusMismatch, 1)
+	assert.Equal(t, "SKU-012", report.StatusMismatch[0].SKU)
+	assert.Equal(t, domain.ItemStatusSold, report.StatusMismatch[0].Status)
+}
+
+func TestStockTakeService_Report_FlagsItemsChangedDuringCount(t *testing.T) {
+	service, m := setupStockTakeService()
+	ctx := context.Background()
+	startedAt := time.Now().Add(-time.Hour)
+
+	m.stockTakeRepo.On("GetByID", ctx, int64(1)).Return(&domain.StockTake{
+		ID: 1, BranchID: 1, Status: domain.StockTakeStatusInProgress, StartedAt: startedAt,
+	}, nil)
+	m.itemRepo.On("List", ctx, mock.Anything).Return(&repository.PaginatedResult[domain.Item]{
+		Data:       []domain.Item{{ID: 10, BranchID: 1, SKU: "SKU-010", Status: domain.ItemStatusAvailable, UpdatedAt: startedAt.Add(time.Minute)}},
+		Page:       1,
+		TotalPages: 1,
+	}, nil)
+	m.stockTakeRepo.On("ListScans", ctx, int64(1)).Return([]*domain.StockTakeScan{}, nil)
+
+	report, err := service.Report(ctx, 1)
+
+	require.NoError(t, err)
+	require.Len(t, report.Missing, 1)
+	assert.True(t, report.Missing[0].ChangedDuringCount)
+}
+
+func TestStockTakeService_Start_AlreadyInProgress(t *testing.T) {
+	service, m := setupStockTakeService()
+	ctx := context.Background()
+
+	m.branchRepo.On("GetByID", ctx, int64(1)).Return(&domain.Branch{ID: 1}, nil)
+	m.stockTakeRepo.On("Create", ctx, mock.AnythingOfType("*domain.StockTake")).Return(false, nil)
+
+	result, err := service.Start(ctx, StartStockTakeInput{BranchID: 1, StartedBy: 5})
+
+	assert.ErrorIs(t, err, ErrStockTakeInProgress)
+	assert.Nil(t, result)
+}
+
+func TestStockTakeService_RecordScan_Finalized(t *testing.T) {
+	service, m := setupStockTakeService()
+	ctx := context.Background()
+
+	m.stockTakeRepo.On("GetByID", ctx, int64(1)).Return(&domain.StockTake{ID: 1, Status: domain.StockTakeStatusFinalized}, nil)
+
+	result, err := service.RecordScan(ctx, 1, RecordScanInput{SKU: "SKU-010", ScannedBy: 5})
+
+	assert.ErrorIs(t, err, ErrStockTakeClosed)
+	assert.Nil(t, result)
+	m.stockTakeRepo.AssertNotCalled(t, "AddScan", mock.Anything, mock.Anything)
+}
+
+func TestStockTakeService_Finalize_StoresReport(t *testing.T) {
+	service, m := setupStockTakeService()
+	ctx := context.Background()
+
+	inProgress := &domain.StockTake{ID: 1, BranchID: 1, Status: domain.StockTakeStatusInProgress}
+	finalized := &domain.StockTake{ID: 1, BranchID: 1, Status: domain.StockTakeStatusFinalized, Report: &domain.StockTakeReport{}}
+	m.stockTakeRepo.On("GetByID", ctx, int64(1)).Return(inProgress, nil).Once()
+	m.stockTakeRepo.On("GetByID", ctx, int64(1)).Return(finalized, nil).Once()
+	m.itemRepo.On("List", ctx, mock.Anything).Return(&repository.PaginatedResult[domain.Item]{Data: []domain.Item{}, Page: 1}, nil)
+	m.stockTakeRepo.On("ListScans", ctx, int64(1)).Return([]*domain.StockTakeScan{{SKU: "SKU-404"}}, nil)
+	m.itemRepo.On("GetBySKU", ctx, "SKU-404").Return(nil, errors.New("item not found"))
+	m.stockTakeRepo.On("Finalize", ctx, int64(1), int64(5), mock.MatchedBy(func(r *domain.StockTakeReport) bool {
+		return len(r.Extra) == 1 && r.Extra[0].SKU == "SKU-404"
+	})).Return(nil)
+
+	result, err := service.Finalize(ctx, 1, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.StockTakeStatusFinalized, result.Status)
+	m.stockTakeRepo.AssertExpectations(t)
+}
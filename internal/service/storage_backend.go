@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrStoredFileNotFound is returned by storage backends for missing files
+var ErrStoredFileNotFound = errors.New("file not found")
+
+// StoredObject describes a file kept by a storage backend
+type StoredObject struct {
+	Key     string // Slash-separated, e.g. images/items/<id>.jpg
+	Size    int64
+	ModTime time.Time
+}
+
+// StorageBackend is where the storage service keeps its files
+type StorageBackend interface {
+	// Save writes a file under key, replacing any previous one, and returns its size
+	Save(ctx context.Context, key string, reader io.Reader, contentType string) (int64, error)
+
+	// Open reads the file under key
+	Open(ctx context.Context, key string) (io.ReadCloser, *StoredObject, error)
+
+	// List returns the files directly under the folder of prefix whose key
+	// starts with prefix
+	List(ctx context.Context, prefix string) ([]StoredObject, error)
+
+	// Delete removes the file under key; deleting a missing file is not an error
+	Delete(ctx context.Context, key string) error
+
+	// URL returns where clients download the file under key from
+	URL(key string) string
+}
+
+// localStorageBackend keeps files in a directory of the local filesystem
+type localStorageBackend struct {
+	baseDir string
+	baseURL string
+}
+
+// NewLocalStorageBackend creates a backend storing files under baseDir, served
+// by the API under baseURL
+func NewLocalStorageBackend(baseDir, baseURL string) StorageBackend {
+	return &localStorageBackend{baseDir: baseDir, baseURL: baseURL}
+}
+
+// path returns the filesystem path of a key
+func (b *localStorageBackend) path(key string) (string, error) {
+	if strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid file key")
+	}
+	return filepath.Join(b.baseDir, filepath.FromSlash(key)), nil
+}
+
+func (b *localStorageBackend) Save(_ context.Context, key string, reader io.Reader, _ string) (int64, error) {
+	filePath, err := b.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	written, err := io.Copy(dst, reader)
+	dst.Close()
+	if err != nil {
+		os.Remove(filePath)
+		return 0, fmt.Errorf("failed to save file: %w", err)
+	}
+	return written, nil
+}
+
+func (b *localStorageBackend) Open(_ context.Context, key string) (io.ReadCloser, *StoredObject, error) {
+	filePath, err := b.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil || fileInfo.IsDir() {
+		return nil, nil, ErrStoredFileNotFound
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, &StoredObject{Key: key, Size: fileInfo.Size(), ModTime: fileInfo.ModTime()}, nil
+}
+
+func (b *localStorageBackend) List(_ context.Context, prefix string) ([]StoredObject, error) {
+	dirKey := path.Dir(prefix + "x") // The folder of prefix, itself when it ends with a slash
+	dir, err := b.path(dirKey)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []StoredObject{}, nil
+		}
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	objects := []StoredObject{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		key := path.Join(dirKey, entry.Name())
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		objects = append(objects, StoredObject{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return objects, nil
+}
+
+func (b *localStorageBackend) Delete(_ context.Context, key string) error {
+	filePath, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (b *localStorageBackend) URL(key string) string {
+	return fmt.Sprintf("%s/%s", b.baseURL, key)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+)
+
+// SettingStorageQuotaMB is the maximum storage in megabytes a branch's photos
+// and documents can use; 0 removes the limit
+const SettingStorageQuotaMB = "storage_quota_mb"
+
+// DefaultStorageQuotaMB is used when the setting is not configured
+const DefaultStorageQuotaMB = 5120
+
+const bytesPerMB = 1024 * 1024
+
+// ErrStorageQuotaExceeded is returned when an upload does not fit in the branch's storage quota
+var ErrStorageQuotaExceeded = errors.New("branch storage quota exceeded")
+
+// StorageQuotaService tracks the size of uploaded files to enforce each
+// branch's storage quota and report storage usage
+type StorageQuotaService struct {
+	fileRepo    repository.StoredFileRepository
+	branchRepo  repository.BranchRepository
+	settingRepo repository.SettingRepository
+}
+
+// NewStorageQuotaService creates a new StorageQuotaService
+func NewStorageQuotaService(
+	fileRepo repository.StoredFileRepository,
+	branchRepo repository.BranchRepository,
+	settingRepo repository.SettingRepository,
+) *StorageQuotaService {
+	return &StorageQuotaService{
+		fileRepo:    fileRepo,
+		branchRepo:  branchRepo,
+		settingRepo: settingRepo,
+	}
+}
+
+// QuotaBytes returns the branch's storage quota in bytes, 0 when unlimited
+func (s *StorageQuotaService) QuotaBytes(ctx context.Context, branchID int64) int64 {
+	quotaMB := getSettingFloat(ctx, s.settingRepo, SettingStorageQuotaMB, &branchID, DefaultStorageQuotaMB)
+	if quotaMB <= 0 {
+		return 0
+	}
+	return int64(quotaMB * bytesPerMB)
+}
+
+// CheckUpload returns ErrStorageQuotaExceeded when storing size more bytes
+// would take the branch over its quota
+func (s *StorageQuotaService) CheckUpload(ctx context.Context, branchID int64, size int64) error {
+	quota := s.QuotaBytes(ctx, branchID)
+	if quota == 0 {
+		return nil
+	}
+
+	used, err := s.fileRepo.GetBranchBytes(ctx, branchID)
+	if err != nil {
+		return err
+	}
+	if used+size > quota {
+		return fmt.Errorf("%w: %.1f MB used of %.1f MB, upload needs %.1f MB",
+			ErrStorageQuotaExceeded, float64(used)/bytesPerMB, float64(quota)/bytesPerMB, float64(size)/bytesPerMB)
+	}
+	return nil
+}
+
+// RecordUpload records the metadata of a file stored for a branch's entity
+func (s *StorageQuotaService) RecordUpload(ctx context.Context, branchID int64, entityType string, entityID int64, info *ImageInfo, createdBy int64) error {
+	return s.fileRepo.Create(ctx, &domain.StoredFile{
+		BranchID:     branchID,
+		EntityType:   entityType,
+		EntityID:     entityID,
+		FileID:       info.ID,
+		URL:          info.URL,
+		ThumbnailURL: info.ThumbnailURL,
+		MimeType:     info.MimeType,
+		SizeBytes:    info.Size,
+		CreatedBy:    createdBy,
+	})
+}
+
+// RecordDeletion releases the storage of the file at a URL
+func (s *StorageQuotaService) RecordDeletion(ctx context.Context, url string) error {
+	return s.fileRepo.DeleteByURL(ctx, url)
+}
+
+// GetFile returns the metadata, including the owning branch, of a stored file
+func (s *StorageQuotaService) GetFile(ctx context.Context, fileID string) (*domain.StoredFile, error) {
+	return s.fileRepo.GetByFileID(ctx, fileID)
+}
+
+// BranchStorageUsage is the storage used by a branch against its quota
+type BranchStorageUsage struct {
+	BranchID     int64                  `json:"branch_id"`
+	BranchName   string                 `json:"branch_name"`
+	Objects      int                    `json:"objects"`
+	Bytes        int64                  `json:"bytes"`
+	QuotaBytes   int64                  `json:"quota_bytes"` // 0 when unlimited
+	UsedPercent  float64                `json:"used_percent"`
+	ByEntityType []*domain.StorageUsage `json:"by_entity_type"`
+}
+
+// StorageUsageReport is the storage used per branch and entity type
+type StorageUsageReport struct {
+	Branches     []*BranchStorageUsage `json:"branches"`
+	TotalObjects int                   `json:"total_objects"`
+	TotalBytes   int64                 `json:"total_bytes"`
+}
+
+// Usage reports the storage used per branch and entity type, optionally for a single branch
+func (s *StorageQuotaService) Usage(ctx context.Context, branchID *int64) (*StorageUsageReport, error) {
+	branches, err := s.branchRepo.List(ctx, repository.PaginationParams{PerPage: 1000})
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.fileRepo.ListUsage(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StorageUsageReport{Branches: []*BranchStorageUsage{}}
+	byBranch := make(map[int64]*BranchStorageUsage)
+	for _, branch := range branches.Data {
+		if branchID != nil && branch.ID != *branchID {
+			continue
+		}
+		row := &BranchStorageUsage{
+			BranchID:     branch.ID,
+			BranchName:   branch.Name,
+			QuotaBytes:   s.QuotaBytes(ctx, branch.ID),
+			ByEntityType: []*domain.StorageUsage{},
+		}
+		byBranch[branch.ID] = row
+		report.Branches = append(report.Branches, row)
+	}
+
+	for _, u := range usage {
+		row, ok := byBranch[u.BranchID]
+		if !ok {
+			continue
+		}
+		row.Objects += u.Objects
+		row.Bytes += u.Bytes
+		row.ByEntityType = append(row.ByEntityType, u)
+		report.TotalObjects += u.Objects
+		report.TotalBytes += u.Bytes
+	}
+
+	for _, row := range report.Branches {
+		if row.QuotaBytes > 0 {
+			row.UsedPercent = roundCents(float64(row.Bytes) / float64(row.QuotaBytes) * 100)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pawnshop/internal/domain"
+	"pawnshop/internal/repository"
+	"pawnshop/internal/repository/mocks"
+)
+
+func setupStorageQuotaService(quotaMB float64) (*StorageQuotaService, *mocks.MockStoredFileRepository, *mocks.MockBranchRepository) {
+	fileRepo := new(mocks.MockStoredFileRepository)
+	branchRepo := new(mocks.MockBranchRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingStorageQuotaMB, mock.Anything).Return(&domain.Setting{Value: quotaMB}, nil)
+	return NewStorageQuotaService(fileRepo, branchRepo, settingRepo), fileRepo, branchRepo
+}
+
+func TestStorageQuotaService_CheckUpload_RejectsOverQuota(t *testing.T) {
+	service, fileRepo, _ := setupStorageQuotaService(10)
+	ctx := context.Background()
+
+	fileRepo.On("GetBranchBytes", ctx, int64(1)).Return(int64(9*bytesPerMB), nil)
+
+	err := service.CheckUpload(ctx, 1, 2*bytesPerMB)
+
+	assert.ErrorIs(t, err, ErrStorageQuotaExceeded)
+	assert.Contains(t, err.Error(), "9.0 MB used of 10.0 MB")
+}
+
+func TestStorageQuotaService_CheckUpload_FitsInQuota(t *testing.T) {
+	service, fileRepo, _ := setupStorageQuotaService(10)
+	ctx := context.Background()
+
+	fileRepo.On("GetBranchBytes", ctx, int64(1)).Return(int64(9*bytesPerMB), nil)
+
+	assert.NoError(t, service.CheckUpload(ctx, 1, bytesPerMB))
+}
+
+func TestStorageQuotaService_CheckUpload_Unlimited(t *testing.T) {
+	service, fileRepo, _ := setupStorageQuotaService(0)
+
+	assert.NoError(t, service.CheckUpload(context.Background(), 1, 500*bytesPerMB))
+	fileRepo.AssertNotCalled(t, "GetBranchBytes", mock.Anything, mock.Anything)
+}
+
+func TestStorageQuotaService_Usage_ReflectsStoredObjects(t *testing.T) {
+	service, fileRepo, branchRepo := setupStorageQuotaService(10)
+	ctx := context.Background()
+
+	branchRepo.On("List", ctx, mock.Anything).Return(&repository.PaginatedResult[domain.Branch]{
+		Data: []domain.Branch{{ID: 1, Name: "Central"}, {ID: 2, Name: "Norte"}},
+	}, nil)
+	fileRepo.On("ListUsage", ctx, (*int64)(nil)).Return([]*domain.StorageUsage{
+		{BranchID: 1, EntityType: "item", Objects: 3, Bytes: 4 * bytesPerMB},
+		{BranchID: 1, EntityType: "loan", Objects: 2, Bytes: bytesPerMB},
+		{BranchID: 2, EntityType: "item", Objects: 1, Bytes: bytesPerMB / 2},
+	}, nil)
+
+	report, err := service.Usage(ctx, nil)
+
+	require.NoError(t, err)
+	require.Len(t, report.Branches, 2)
+
+	central := report.Branches[0]
+	assert.Equal(t, "Central", central.BranchName)
+	assert.Equal(t, 5, central.Objects)
+	assert.Equal(t, int64(5*bytesPerMB), central.Bytes)
+	assert.Equal(t, int64(10*bytesPerMB), central.QuotaBytes)
+	assert.Equal(t, 50.0, central.UsedPercent)
+	assert.Len(t, central.ByEntityType, 2)
+
+	assert.Equal(t, 1, report.Branches[1].Objects)
+	assert.Equal(t, 6, report.TotalObjects)
+	assert.Equal(t, int64(5*bytesPerMB+bytesPerMB/2), report.TotalBytes)
+}
+
+func TestStorageQuotaService_RecordUpload_KeepsThumbnail(t *testing.T) {
+	service, fileRepo, _ := setupStorageQuotaService(10)
+	ctx := context.Background()
+
+	fileRepo.On("Create", ctx, mock.MatchedBy(func(file *domain.StoredFile) bool {
+		return file.URL == "/storage/images/items/a.png" && file.ThumbnailURL == "/storage/thumbnails/items/a.png"
+	})).Return(nil)
+
+	err := service.RecordUpload(ctx, 1, "item", 7, &ImageInfo{
+		ID:           "items/a.png",
+		URL:          "/storage/images/items/a.png",
+		ThumbnailURL: "/storage/thumbnails/items/a.png",
+		MimeType:     "image/png",
+		Size:         1024,
+	}, 3)
+
+	require.NoError(t, err)
+	fileRepo.AssertExpectations(t)
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"pawnshop/internal/config"
+)
+
+// defaultS3Region is used when no region is configured; MinIO accepts any
+const defaultS3Region = "us-east-1"
+
+// s3StorageBackend keeps files in a bucket of an S3-compatible object store
+// such as AWS S3 or MinIO. Requests use path-style addressing and are signed
+// with AWS Signature Version 4.
+type s3StorageBackend struct {
+	endpoint  string
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3StorageBackend creates a backend storing files in the configured bucket.
+// Files are linked through the public URL when set, otherwise straight from
+// the endpoint, so the bucket must allow public reads of images.
+func NewS3StorageBackend(cfg *config.StorageConfig) StorageBackend {
+	region := cfg.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	return &s3StorageBackend{
+		endpoint:  strings.TrimRight(cfg.Endpoint, "/"),
+		bucket:    cfg.Bucket,
+		region:    region,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}
+}
+
+func (b *s3StorageBackend) Save(ctx context.Context, key string, reader io.Reader, contentType string) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	resp, err := b.do(ctx, http.MethodPut, key, nil, data, contentType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to save file: %w", s3Error(resp))
+	}
+	return int64(len(data)), nil
+}
+
+func (b *s3StorageBackend) Open(ctx context.Context, key string) (io.ReadCloser, *StoredObject, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, nil, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil, ErrStoredFileNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, nil, fmt.Errorf("failed to open file: %w", s3Error(resp))
+	}
+
+	object := &StoredObject{Key: key, Size: resp.ContentLength}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModTime = modified
+	}
+	return resp.Body, object, nil
+}
+
+// s3ListResult is the response of a ListObjectsV2 request
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (b *s3StorageBackend) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	objects := []StoredObject{}
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+	for {
+		resp, err := b.do(ctx, http.MethodGet, "", query, nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file list: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, StoredObject{Key: content.Key, Size: content.Size, ModTime: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (b *s3StorageBackend) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return fmt.Errorf("failed to delete file: %w", s3Error(resp))
+}
+
+func (b *s3StorageBackend) URL(key string) string {
+	if b.publicURL != "" {
+		return b.publicURL + "/" + s3EscapePath(key)
+	}
+	return b.endpoint + b.objectPath(key)
+}
+
+// objectPath returns the escaped path-style path of a key, the bucket's for
+// an empty key
+func (b *s3StorageBackend) objectPath(key string) string {
+	if key == "" {
+		return "/" + s3Escape(b.bucket)
+	}
+	return "/" + s3Escape(b.bucket) + "/" + s3EscapePath(key)
+}
+
+// do sends a signed request for a key of the bucket
+func (b *s3StorageBackend) do(ctx context.Context, method, key string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	if b.endpoint == "" || b.bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires an endpoint and a bucket")
+	}
+	if strings.Contains(key, "..") {
+		return nil, fmt.Errorf("invalid file key")
+	}
+
+	escapedPath := b.objectPath(key)
+	rawQuery := s3CanonicalQuery(query)
+	target := b.endpoint + escapedPath
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	b.sign(req, escapedPath, rawQuery, body)
+
+	return b.client.Do(req)
+}
+
+// sign adds the AWS Signature Version 4 authorization to a request
+func (b *s3StorageBackend) sign(req *http.Request, escapedPath, rawQuery string, body []byte) {
+	now := b.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapedPath,
+		rawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + b.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+b.secretKey), day)
+	key = hmacSHA256(key, b.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, scope, signedHeaders, signature))
+}
+
+// s3Error describes an unexpected response of the object store
+func s3Error(resp *http.Response) error {
+	var payload struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(body, &payload) == nil && payload.Code != "" {
+		return fmt.Errorf("s3 %s: %s", payload.Code, payload.Message)
+	}
+	return fmt.Errorf("s3 responded %s", resp.Status)
+}
+
+// s3CanonicalQuery encodes query parameters sorted by name, as signatures require
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, s3Escape(name)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath escapes each segment of a slash-separated key
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3Escape percent-encodes everything but the unreserved characters of RFC 3986
+func s3Escape(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			buf.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&buf, "%%%02X", c)
+	}
+	return buf.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -1,22 +1,25 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"image"
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"mime/multipart"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nfnt/resize"
-	"image/jpeg"
+
+	"pawnshop/internal/config"
 )
 
 const (
@@ -74,21 +77,46 @@ type StorageService interface {
 
 	// GetThumbnailURL returns the URL for a thumbnail
 	GetThumbnailURL(id string) string
+
+	// SaveDocument stores a generated document such as a PDF contract
+	SaveDocument(ctx context.Context, data []byte, originalName, category string) (*ImageInfo, error)
+
+	// GetDocument retrieves a stored document by ID
+	GetDocument(ctx context.Context, id string) (io.ReadCloser, *ImageInfo, error)
+
+	// GetDocumentURL returns the URL for a stored document
+	GetDocumentURL(id string) string
+
+	// DeleteDocument deletes a stored document
+	DeleteDocument(ctx context.Context, id string) error
 }
 
 type storageService struct {
-	baseDir string
+	backend StorageBackend
 	baseURL string
 }
 
-// NewStorageService creates a new storage service
-func NewStorageService(baseDir, baseURL string) StorageService {
+// NewStorageService creates a new storage service keeping its files in the
+// backend picked by cfg: an S3-compatible bucket for the s3 and minio types,
+// otherwise the local directory baseDir. Stored documents are always served
+// by the API under baseURL, since they require authentication.
+func NewStorageService(cfg *config.StorageConfig, baseDir, baseURL string) StorageService {
+	if cfg != nil && (cfg.Type == "s3" || cfg.Type == "minio") {
+		return newStorageService(NewS3StorageBackend(cfg), baseURL)
+	}
+
 	// Create base directories
 	os.MkdirAll(filepath.Join(baseDir, "images"), 0755)
 	os.MkdirAll(filepath.Join(baseDir, "thumbnails"), 0755)
+	os.MkdirAll(filepath.Join(baseDir, "documents"), 0755)
+
+	return newStorageService(NewLocalStorageBackend(baseDir, baseURL), baseURL)
+}
 
+// newStorageService creates a storage service over a backend
+func newStorageService(backend StorageBackend, baseURL string) StorageService {
 	return &storageService{
-		baseDir: baseDir,
+		backend: backend,
 		baseURL: baseURL,
 	}
 }
@@ -125,30 +153,29 @@ func (s *storageService) UploadImageFromReader(ctx context.Context, reader io.Re
 	return s.uploadFromReader(ctx, reader, filename, mimeType, ext, category, 0)
 }
 
-func (s *storageService) uploadFromReader(_ context.Context, reader io.Reader, originalName, mimeType, ext, category string, size int64) (*ImageInfo, error) {
+func (s *storageService) uploadFromReader(ctx context.Context, reader io.Reader, originalName, mimeType, ext, category string, size int64) (*ImageInfo, error) {
+	if strings.Contains(category, "..") {
+		return nil, fmt.Errorf("invalid category")
+	}
+
 	// Generate unique ID
 	id := uuid.New().String()
 	filename := id + ext
+	imageID := path.Join(category, filename)
 
-	// Create category directory
-	categoryDir := filepath.Join(s.baseDir, "images", category)
-	thumbnailDir := filepath.Join(s.baseDir, "thumbnails", category)
-	os.MkdirAll(categoryDir, 0755)
-	os.MkdirAll(thumbnailDir, 0755)
-
-	// Save original image
-	imagePath := filepath.Join(categoryDir, filename)
-	dst, err := os.Create(imagePath)
+	// Read the image once: it is saved and decoded for the thumbnail
+	data, err := io.ReadAll(io.LimitReader(reader, MaxFileSize+1))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("file size exceeds maximum of %d bytes", MaxFileSize)
 	}
 
-	// Copy content
-	written, err := io.Copy(dst, reader)
-	dst.Close()
+	// Save original image
+	written, err := s.backend.Save(ctx, path.Join("images", imageID), bytes.NewReader(data), mimeType)
 	if err != nil {
-		os.Remove(imagePath)
-		return nil, fmt.Errorf("failed to save file: %w", err)
+		return nil, err
 	}
 
 	if size == 0 {
@@ -159,34 +186,29 @@ func (s *storageService) uploadFromReader(_ context.Context, reader io.Reader, o
 	width, height := 0, 0
 	thumbnailURL := ""
 
-	file, err := os.Open(imagePath)
+	// Files that cannot be decoded as images get no thumbnail
+	img, format, err := image.Decode(bytes.NewReader(data))
 	if err == nil {
-		defer file.Close()
-		img, _, err := image.Decode(file)
-		if err == nil {
-			bounds := img.Bounds()
-			width = bounds.Dx()
-			height = bounds.Dy()
-
-			// Create thumbnail
-			thumb := resize.Thumbnail(ThumbnailWidth, ThumbnailHeight, img, resize.Lanczos3)
-			thumbPath := filepath.Join(thumbnailDir, filename)
-			thumbFile, err := os.Create(thumbPath)
-			if err == nil {
-				jpeg.Encode(thumbFile, thumb, &jpeg.Options{Quality: 85})
-				thumbFile.Close()
-				thumbnailURL = s.GetThumbnailURL(filepath.Join(category, filename))
+		bounds := img.Bounds()
+		width = bounds.Dx()
+		height = bounds.Dy()
+
+		// Create thumbnail
+		thumb := resize.Thumbnail(ThumbnailWidth, ThumbnailHeight, img, resize.Lanczos3)
+		if thumbData, err := encodeThumbnail(thumb, format); err == nil {
+			if _, err := s.backend.Save(ctx, path.Join("thumbnails", imageID), bytes.NewReader(thumbData), "image/"+format); err == nil {
+				thumbnailURL = s.GetThumbnailURL(imageID)
 			}
 		}
 	}
 
 	return &ImageInfo{
-		ID:           filepath.Join(category, filename),
+		ID:           imageID,
 		Filename:     filename,
 		OriginalName: originalName,
 		MimeType:     mimeType,
 		Size:         size,
-		URL:          s.GetImageURL(filepath.Join(category, filename)),
+		URL:          s.GetImageURL(imageID),
 		ThumbnailURL: thumbnailURL,
 		Width:        width,
 		Height:       height,
@@ -194,6 +216,25 @@ func (s *storageService) uploadFromReader(_ context.Context, reader io.Reader, o
 	}, nil
 }
 
+// encodeThumbnail encodes a thumbnail in the format of its original, which it
+// is stored and served as
+func encodeThumbnail(thumb image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, thumb)
+	case "gif":
+		err = gif.Encode(&buf, thumb, nil)
+	default:
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (s *storageService) GetImage(ctx context.Context, id string) (io.ReadCloser, *ImageInfo, error) {
 	return s.getFile(ctx, "images", id)
 }
@@ -202,75 +243,66 @@ func (s *storageService) GetThumbnail(ctx context.Context, id string) (io.ReadCl
 	return s.getFile(ctx, "thumbnails", id)
 }
 
-func (s *storageService) getFile(_ context.Context, subdir, id string) (io.ReadCloser, *ImageInfo, error) {
+func (s *storageService) getFile(ctx context.Context, subdir, id string) (io.ReadCloser, *ImageInfo, error) {
 	// Security check
 	if strings.Contains(id, "..") {
 		return nil, nil, fmt.Errorf("invalid file ID")
 	}
 
-	dir := filepath.Join(s.baseDir, subdir, filepath.Dir(id))
-	baseID := filepath.Base(id)
-
-	var filePath string
-	var filename string
+	key := path.Join(subdir, id)
 
 	// First, try exact match (if ID includes extension)
-	exactPath := filepath.Join(s.baseDir, subdir, id)
-	if _, err := os.Stat(exactPath); err == nil {
-		filePath = exactPath
-		filename = baseID
-	} else {
+	file, object, err := s.backend.Open(ctx, key)
+	if err == ErrStoredFileNotFound {
 		// Fall back to prefix search (for IDs without extension)
-		entries, err := os.ReadDir(dir)
+		key, err = s.findByID(ctx, key)
 		if err != nil {
-			return nil, nil, fmt.Errorf("file not found")
-		}
-
-		for _, entry := range entries {
-			name := entry.Name()
-			if strings.HasPrefix(name, baseID+".") || name == baseID {
-				filePath = filepath.Join(dir, name)
-				filename = name
-				break
-			}
+			return nil, nil, err
 		}
+		file, object, err = s.backend.Open(ctx, key)
 	}
-
-	if filePath == "" {
-		return nil, nil, fmt.Errorf("file not found")
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Get file info
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("file not found")
+	filename := path.Base(key)
+	info := &ImageInfo{
+		ID:        id,
+		Filename:  filename,
+		MimeType:  mimeTypeByExtension(filename),
+		Size:      object.Size,
+		CreatedAt: object.ModTime,
 	}
 
-	// Open file
-	file, err := os.Open(filePath)
+	return file, info, nil
+}
+
+// findByID returns the key of the file stored under a key missing its extension
+func (s *storageService) findByID(ctx context.Context, key string) (string, error) {
+	objects, err := s.backend.List(ctx, key)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+		return "", ErrStoredFileNotFound
+	}
+	for _, object := range objects {
+		if object.Key == key || strings.HasPrefix(object.Key, key+".") {
+			return object.Key, nil
+		}
 	}
+	return "", ErrStoredFileNotFound
+}
 
-	// Determine mime type from extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	mimeType := "application/octet-stream"
+// mimeTypeByExtension determines the mime type of a stored file from its extension
+func mimeTypeByExtension(filename string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	if ext == ".pdf" {
+		return "application/pdf"
+	}
 	for mime, e := range allowedMimeTypes {
 		if e == ext {
-			mimeType = mime
-			break
+			return mime
 		}
 	}
-
-	info := &ImageInfo{
-		ID:        id,
-		Filename:  filename,
-		MimeType:  mimeType,
-		Size:      fileInfo.Size(),
-		CreatedAt: fileInfo.ModTime(),
-	}
-
-	return file, info, nil
+	return "application/octet-stream"
 }
 
 func (s *storageService) DeleteImage(ctx context.Context, id string) error {
@@ -279,22 +311,17 @@ func (s *storageService) DeleteImage(ctx context.Context, id string) error {
 		return fmt.Errorf("invalid file ID")
 	}
 
-	// Delete original
-	imageDir := filepath.Join(s.baseDir, "images", filepath.Dir(id))
-	thumbDir := filepath.Join(s.baseDir, "thumbnails", filepath.Dir(id))
-	baseID := filepath.Base(id)
-
-	// Find and delete files
-	for _, dir := range []string{imageDir, thumbDir} {
-		entries, err := os.ReadDir(dir)
+	// Delete the original and its thumbnail
+	for _, subdir := range []string{"images", "thumbnails"} {
+		key := path.Join(subdir, id)
+		objects, err := s.backend.List(ctx, key)
 		if err != nil {
 			continue
 		}
 
-		for _, entry := range entries {
-			name := entry.Name()
-			if strings.HasPrefix(name, baseID+".") {
-				os.Remove(filepath.Join(dir, name))
+		for _, object := range objects {
+			if object.Key == key || strings.HasPrefix(object.Key, key+".") {
+				s.backend.Delete(ctx, object.Key)
 			}
 		}
 	}
@@ -303,49 +330,30 @@ func (s *storageService) DeleteImage(ctx context.Context, id string) error {
 }
 
 func (s *storageService) ListImages(ctx context.Context, category string) ([]*ImageInfo, error) {
-	dir := filepath.Join(s.baseDir, "images", category)
+	if strings.Contains(category, "..") {
+		return nil, fmt.Errorf("invalid category")
+	}
 
-	entries, err := os.ReadDir(dir)
+	objects, err := s.backend.List(ctx, path.Join("images", category)+"/")
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []*ImageInfo{}, nil
-		}
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+		return nil, err
 	}
 
 	var images []*ImageInfo
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		ext := filepath.Ext(name)
+	for _, object := range objects {
+		name := path.Base(object.Key)
+		ext := path.Ext(name)
 		id := strings.TrimSuffix(name, ext)
 
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		// Determine mime type
-		mimeType := "application/octet-stream"
-		for mime, e := range allowedMimeTypes {
-			if e == strings.ToLower(ext) {
-				mimeType = mime
-				break
-			}
-		}
-
-		fullID := filepath.Join(category, id)
+		fullID := path.Join(category, id)
 		images = append(images, &ImageInfo{
 			ID:           fullID,
 			Filename:     name,
-			MimeType:     mimeType,
-			Size:         info.Size(),
-			URL:          s.GetImageURL(fullID),
-			ThumbnailURL: s.GetThumbnailURL(fullID),
-			CreatedAt:    info.ModTime(),
+			MimeType:     mimeTypeByExtension(name),
+			Size:         object.Size,
+			URL:          s.GetImageURL(path.Join(category, name)),
+			ThumbnailURL: s.GetThumbnailURL(path.Join(category, name)),
+			CreatedAt:    object.ModTime,
 		})
 	}
 
@@ -353,9 +361,48 @@ func (s *storageService) ListImages(ctx context.Context, category string) ([]*Im
 }
 
 func (s *storageService) GetImageURL(id string) string {
-	return fmt.Sprintf("%s/images/%s", s.baseURL, id)
+	return s.backend.URL(path.Join("images", id))
 }
 
 func (s *storageService) GetThumbnailURL(id string) string {
-	return fmt.Sprintf("%s/thumbnails/%s", s.baseURL, id)
+	return s.backend.URL(path.Join("thumbnails", id))
+}
+
+func (s *storageService) SaveDocument(ctx context.Context, data []byte, originalName, category string) (*ImageInfo, error) {
+	if strings.Contains(category, "..") {
+		return nil, fmt.Errorf("invalid category")
+	}
+
+	ext := strings.ToLower(filepath.Ext(originalName))
+	filename := uuid.New().String() + ext
+
+	id := path.Join(category, filename)
+	if _, err := s.backend.Save(ctx, path.Join("documents", id), bytes.NewReader(data), mimeTypeByExtension(filename)); err != nil {
+		return nil, err
+	}
+
+	return &ImageInfo{
+		ID:           id,
+		Filename:     filename,
+		OriginalName: originalName,
+		MimeType:     mimeTypeByExtension(filename),
+		Size:         int64(len(data)),
+		URL:          s.GetDocumentURL(id),
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+func (s *storageService) GetDocument(ctx context.Context, id string) (io.ReadCloser, *ImageInfo, error) {
+	return s.getFile(ctx, "documents", id)
+}
+
+func (s *storageService) GetDocumentURL(id string) string {
+	return fmt.Sprintf("%s/documents/%s", s.baseURL, id)
+}
+
+func (s *storageService) DeleteDocument(ctx context.Context, id string) error {
+	if id == "" || strings.Contains(id, "..") {
+		return fmt.Errorf("invalid file ID")
+	}
+	return s.backend.Delete(ctx, path.Join("documents", id))
 }
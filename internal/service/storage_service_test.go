@@ -77,7 +77,7 @@ func TestNewStorageService(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	assert.NotNil(t, svc)
 
 	// Verify directories were created
@@ -91,7 +91,7 @@ func TestStorageService_UploadImageFromReader(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	imageData := createTestJPEGImage(t, 400, 300)
@@ -113,7 +113,7 @@ func TestStorageService_UploadImageFromReader_PNG(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	imageData := createTestPNGImage(t, 200, 150)
@@ -132,7 +132,7 @@ func TestStorageService_UploadImageFromReader_UnsupportedType(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	reader := strings.NewReader("not an image")
@@ -146,7 +146,7 @@ func TestStorageService_GetImage(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Upload an image first
@@ -175,7 +175,7 @@ func TestStorageService_GetImage_NotFound(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	_, _, err := svc.GetImage(ctx, "items/nonexistent-id")
@@ -187,7 +187,7 @@ func TestStorageService_GetImage_PathTraversal(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	_, _, err := svc.GetImage(ctx, "../../../etc/passwd")
@@ -199,7 +199,7 @@ func TestStorageService_GetThumbnail(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Upload an image (thumbnail is created automatically)
@@ -227,7 +227,7 @@ func TestStorageService_DeleteImage(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Upload an image
@@ -255,7 +255,7 @@ func TestStorageService_DeleteImage_PathTraversal(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	err := svc.DeleteImage(ctx, "../../../important-file")
@@ -267,7 +267,7 @@ func TestStorageService_ListImages(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Upload multiple images
@@ -295,7 +295,7 @@ func TestStorageService_ListImages_EmptyCategory(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	images, err := svc.ListImages(ctx, "empty_category")
@@ -307,7 +307,7 @@ func TestStorageService_ListImages_DifferentCategories(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Upload to different categories
@@ -334,7 +334,7 @@ func TestStorageService_GetImageURL(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 
 	url := svc.GetImageURL("items/abc123")
 	assert.Equal(t, "http://localhost:8080/storage/images/items/abc123", url)
@@ -344,7 +344,7 @@ func TestStorageService_GetThumbnailURL(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 
 	url := svc.GetThumbnailURL("items/abc123")
 	assert.Equal(t, "http://localhost:8080/storage/thumbnails/items/abc123", url)
@@ -376,7 +376,7 @@ func TestStorageService_UploadImage_LargeImage(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Create a larger image to test thumbnail generation
@@ -400,7 +400,7 @@ func TestStorageService_AllowedMimeTypes(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	tests := []struct {
@@ -443,7 +443,7 @@ func TestStorageService_UploadCreatesCategories(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	imageData := createTestJPEGImage(t, 50, 50)
@@ -464,7 +464,7 @@ func TestStorageService_DeleteNonExistent(t *testing.T) {
 	tempDir, cleanup := setupStorageTestDir(t)
 	defer cleanup()
 
-	svc := NewStorageService(tempDir, "http://localhost:8080/storage")
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
 	ctx := context.Background()
 
 	// Deleting non-existent image should not error
@@ -477,3 +477,203 @@ func TestConstants(t *testing.T) {
 	assert.Equal(t, uint(200), uint(ThumbnailWidth))
 	assert.Equal(t, uint(200), uint(ThumbnailHeight))
 }
+
+func TestStorageService_SaveDocument(t *testing.T) {
+	tempDir, cleanup := setupStorageTestDir(t)
+	defer cleanup()
+
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
+	ctx := context.Background()
+
+	data := []byte("%PDF-1.4 contract")
+	info, err := svc.SaveDocument(ctx, data, "LN-000001.pdf", "contracts")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(info.ID, "contracts/"))
+	assert.True(t, strings.HasSuffix(info.ID, ".pdf"))
+	assert.Equal(t, "LN-000001.pdf", info.OriginalName)
+	assert.Equal(t, "application/pdf", info.MimeType)
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.Equal(t, "http://localhost:8080/storage/documents/"+info.ID, info.URL)
+
+	file, stored, err := svc.GetDocument(ctx, info.ID)
+	require.NoError(t, err)
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, data, content)
+	assert.Equal(t, "application/pdf", stored.MimeType)
+}
+
+func TestStorageService_SaveDocument_InvalidCategory(t *testing.T) {
+	tempDir, cleanup := setupStorageTestDir(t)
+	defer cleanup()
+
+	svc := NewStorageService(nil, tempDir, "http://localhost:8080/storage")
+
+	_, err := svc.SaveDocument(context.Background(), []byte("x"), "a.pdf", "../outside")
+	assert.Error(t, err)
+}
+
+func TestLocalStorageBackend(t *testing.T) {
+	tempDir, cleanup := setupStorageTestDir(t)
+	defer cleanup()
+
+	backend := NewLocalStorageBackend(tempDir, "/storage")
+	ctx := context.Background()
+
+	written, err := backend.Save(ctx, "images/items/abc.jpg", strings.NewReader("photo"), "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), written)
+	_, err = backend.Save(ctx, "images/items/abd.png", strings.NewReader("other"), "image/png")
+	require.NoError(t, err)
+
+	file, object, err := backend.Open(ctx, "images/items/abc.jpg")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	file.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "photo", string(content))
+	assert.Equal(t, int64(5), object.Size)
+
+	objects, err := backend.List(ctx, "images/items/abc")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "images/items/abc.jpg", objects[0].Key)
+
+	objects, err = backend.List(ctx, "images/items/")
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	assert.Equal(t, "/storage/images/items/abc.jpg", backend.URL("images/items/abc.jpg"))
+
+	require.NoError(t, backend.Delete(ctx, "images/items/abc.jpg"))
+	require.NoError(t, backend.Delete(ctx, "images/items/abc.jpg"), "deleting a missing file is not an error")
+	_, _, err = backend.Open(ctx, "images/items/abc.jpg")
+	assert.ErrorIs(t, err, ErrStoredFileNotFound)
+
+	_, err = backend.Save(ctx, "../outside.jpg", strings.NewReader("x"), "image/jpeg")
+	assert.Error(t, err)
+}
+
+// memoryStorageBackend is an in-memory object store, as a bucket would be
+type memoryStorageBackend struct {
+	objects map[string][]byte
+}
+
+func (b *memoryStorageBackend) Save(_ context.Context, key string, reader io.Reader, _ string) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+	b.objects[key] = data
+	return int64(len(data)), nil
+}
+
+func (b *memoryStorageBackend) Open(_ context.Context, key string) (io.ReadCloser, *StoredObject, error) {
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, nil, ErrStoredFileNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), &StoredObject{Key: key, Size: int64(len(data))}, nil
+}
+
+func (b *memoryStorageBackend) List(_ context.Context, prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	for key, data := range b.objects {
+		if strings.HasPrefix(key, prefix) && !strings.Contains(key[len(prefix):], "/") {
+			objects = append(objects, StoredObject{Key: key, Size: int64(len(data))})
+		}
+	}
+	return objects, nil
+}
+
+func (b *memoryStorageBackend) Delete(_ context.Context, key string) error {
+	delete(b.objects, key)
+	return nil
+}
+
+func (b *memoryStorageBackend) URL(key string) string {
+	return "https://bucket.example.com/" + key
+}
+
+func TestStorageService_ObjectStorageBackend(t *testing.T) {
+	backend := &memoryStorageBackend{objects: make(map[string][]byte)}
+	svc := newStorageService(backend, "/storage")
+	ctx := context.Background()
+
+	info, err := svc.UploadImageFromReader(ctx, bytes.NewReader(createTestJPEGImage(t, 400, 300)), "photo.jpg", "image/jpeg", "items")
+	require.NoError(t, err)
+
+	assert.Contains(t, backend.objects, "images/"+info.ID)
+	assert.Contains(t, backend.objects, "thumbnails/"+info.ID)
+	assert.Equal(t, "https://bucket.example.com/images/"+info.ID, info.URL)
+	assert.Equal(t, "https://bucket.example.com/thumbnails/"+info.ID, info.ThumbnailURL)
+
+	// Served by ID without its extension, as the handler does
+	file, stored, err := svc.GetImage(ctx, strings.TrimSuffix(info.ID, ".jpg"))
+	require.NoError(t, err)
+	file.Close()
+	assert.Equal(t, "image/jpeg", stored.MimeType)
+
+	images, err := svc.ListImages(ctx, "items")
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, info.URL, images[0].URL)
+
+	// Documents stay behind the API's authenticated route
+	doc, err := svc.SaveDocument(ctx, []byte("%PDF-1.4"), "contract.pdf", "contracts")
+	require.NoError(t, err)
+	assert.Equal(t, "/storage/documents/"+doc.ID, doc.URL)
+	assert.Contains(t, backend.objects, "documents/"+doc.ID)
+
+	require.NoError(t, svc.DeleteImage(ctx, strings.TrimSuffix(info.ID, ".jpg")))
+	assert.NotContains(t, backend.objects, "images/"+info.ID)
+	assert.NotContains(t, backend.objects, "thumbnails/"+info.ID)
+}
+
+func TestStorageService_UploadImage_Thumbnail(t *testing.T) {
+	tempDir, cleanup := setupStorageTestDir(t)
+	defer cleanup()
+
+	svc := NewStorageService(nil, tempDir, "/storage")
+	ctx := context.Background()
+
+	info, err := svc.UploadImageFromReader(ctx, bytes.NewReader(createTestPNGImage(t, 600, 400)), "photo.png", "image/png", "items")
+	require.NoError(t, err)
+	assert.Equal(t, "/storage/thumbnails/"+info.ID, info.ThumbnailURL)
+
+	file, thumbInfo, err := svc.GetThumbnail(ctx, info.ID)
+	require.NoError(t, err)
+	defer file.Close()
+	assert.Equal(t, "image/png", thumbInfo.MimeType)
+
+	// Served as PNG like its original, keeping the aspect ratio
+	thumb, format, err := image.Decode(file)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, ThumbnailWidth, thumb.Bounds().Dx())
+	assert.Equal(t, 133, thumb.Bounds().Dy())
+	assert.Less(t, thumb.Bounds().Dx(), info.Width)
+	assert.Less(t, thumb.Bounds().Dy(), info.Height)
+}
+
+func TestStorageService_NonImageUploadHasNoThumbnail(t *testing.T) {
+	tempDir, cleanup := setupStorageTestDir(t)
+	defer cleanup()
+
+	svc := NewStorageService(nil, tempDir, "/storage")
+	ctx := context.Background()
+
+	doc, err := svc.SaveDocument(ctx, []byte("%PDF-1.4 receipt"), "receipt.pdf", "items")
+	require.NoError(t, err)
+	assert.Empty(t, doc.ThumbnailURL)
+
+	// Content that does not decode as an image is stored without a thumbnail
+	info, err := svc.UploadImageFromReader(ctx, strings.NewReader("%PDF-1.4 not really webp"), "scan.webp", "image/webp", "items")
+	require.NoError(t, err)
+	assert.Empty(t, info.ThumbnailURL)
+	_, _, err = svc.GetThumbnail(ctx, info.ID)
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"pawnshop/internal/domain"
@@ -55,22 +56,33 @@ type TransferService interface {
 	GetInTransitForBranch(ctx context.Context, branchID int64) ([]*domain.ItemTransfer, error)
 }
 
+// SettingTransferRepriceOnReceipt makes a branch recompute the sale price of
+// the items it receives by transfer with its own pricing rules
+const SettingTransferRepriceOnReceipt = "transfer_reprice_on_receipt"
+
 type transferService struct {
 	transferRepo repository.TransferRepository
 	itemRepo     repository.ItemRepository
 	branchRepo   repository.BranchRepository
+	itemService  *ItemService
+	settingRepo  repository.SettingRepository
 }
 
-// NewTransferService creates a new transfer service
+// NewTransferService creates a new transfer service. The item service prices
+// received items for branches that re-price on receipt.
 func NewTransferService(
 	transferRepo repository.TransferRepository,
 	itemRepo repository.ItemRepository,
 	branchRepo repository.BranchRepository,
+	itemService *ItemService,
+	settingRepo repository.SettingRepository,
 ) TransferService {
 	return &transferService{
 		transferRepo: transferRepo,
 		itemRepo:     itemRepo,
 		branchRepo:   branchRepo,
+		itemService:  itemService,
+		settingRepo:  settingRepo,
 	}
 }
 
@@ -299,10 +311,27 @@ func (s *transferService) Receive(ctx context.Context, id int64, receivedBy int6
 	// Update item
 	item.BranchID = transfer.ToBranchID
 	item.Status = domain.ItemStatusAvailable
+	oldPrice, repriced := s.reprice(ctx, item)
 	if err := s.itemRepo.Update(ctx, item); err != nil {
 		return nil, err
 	}
 
+	if repriced {
+		s.itemRepo.CreateHistory(ctx, &domain.ItemHistory{
+			ItemID:        item.ID,
+			Action:        domain.ItemHistoryActionRepriced,
+			OldStatus:     string(item.Status),
+			NewStatus:     string(item.Status),
+			OldBranchID:   &transfer.FromBranchID,
+			NewBranchID:   &transfer.ToBranchID,
+			ReferenceType: strPtr("transfer"),
+			ReferenceID:   &transfer.ID,
+			Notes: fmt.Sprintf("Precio recalculado al recibir la transferencia %s: Q%.2f → Q%.2f",
+				transfer.TransferNumber, oldPrice, *item.SalePrice),
+			CreatedBy: receivedBy,
+		})
+	}
+
 	// Update transfer
 	now := time.Now()
 	transfer.Status = domain.TransferStatusCompleted
@@ -317,6 +346,45 @@ func (s *transferService) Receive(ctx context.Context, id int64, receivedBy int6
 	return transfer, nil
 }
 
+// reprice recomputes the sale price of an item received by a branch that
+// re-prices on receipt, from the market value its appraisal came from. It
+// returns the previous price and whether the price changed. Items the
+// destination's rules cannot price, such as an unknown condition, keep theirs.
+func (s *transferService) reprice(ctx context.Context, item *domain.Item) (float64, bool) {
+	if s.itemService == nil || item.AppraisedValue <= 0 ||
+		!getSettingBool(ctx, s.settingRepo, SettingTransferRepriceOnReceipt, &item.BranchID, false) {
+		return 0, false
+	}
+
+	// The appraised value is already discounted by the condition factor, which
+	// the sale price suggestion applies again
+	grade, err := s.itemService.conditionGrade(ctx, item.Condition)
+	if err != nil {
+		return 0, false
+	}
+	suggestion, err := s.itemService.SuggestSalePrice(ctx, ValuationInput{
+		CategoryID:  item.CategoryID,
+		Condition:   item.Condition,
+		MarketValue: item.AppraisedValue / grade.Factor,
+		BranchID:    &item.BranchID,
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	var oldPrice float64
+	if item.SalePrice != nil {
+		oldPrice = *item.SalePrice
+	}
+	if suggestion.SalePrice == oldPrice {
+		return oldPrice, false
+	}
+
+	price := suggestion.SalePrice
+	item.SalePrice = &price
+	return oldPrice, true
+}
+
 func (s *transferService) Cancel(ctx context.Context, id int64, cancelledBy int64, reason string) (*domain.ItemTransfer, error) {
 	transfer, err := s.transferRepo.GetByID(ctx, id)
 	if err != nil {
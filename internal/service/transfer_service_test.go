@@ -17,7 +17,7 @@ func setupTransferService() (TransferService, *mocks.MockTransferRepository, *mo
 	transferRepo := new(mocks.MockTransferRepository)
 	itemRepo := new(mocks.MockItemRepository)
 	branchRepo := new(mocks.MockBranchRepository)
-	service := NewTransferService(transferRepo, itemRepo, branchRepo)
+	service := NewTransferService(transferRepo, itemRepo, branchRepo, nil, nil)
 	return service, transferRepo, itemRepo, branchRepo
 }
 
@@ -523,6 +523,97 @@ func TestTransferService_Receive_Success(t *testing.T) {
 	itemRepo.AssertExpectations(t)
 }
 
+func setupRepricingTransferService(repriceOnReceipt bool) (TransferService, *mocks.MockTransferRepository, *mocks.MockItemRepository) {
+	transferRepo := new(mocks.MockTransferRepository)
+	itemRepo := new(mocks.MockItemRepository)
+	settingRepo := new(mocks.MockSettingRepository)
+	settingRepo.On("Get", mock.Anything, SettingItemConditionScale, mock.Anything).Return(nil, ErrSettingNotFound)
+	settingRepo.On("Get", mock.Anything, SettingSalePriceMarketFactor, mock.Anything).
+		Return(&domain.Setting{Key: SettingSalePriceMarketFactor, Value: 1.2}, nil)
+	settingRepo.On("Get", mock.Anything, SettingTransferRepriceOnReceipt, mock.Anything).
+		Return(&domain.Setting{Key: SettingTransferRepriceOnReceipt, Value: repriceOnReceipt}, nil)
+
+	itemService := NewItemService(itemRepo, new(mocks.MockBranchRepository), new(mocks.MockCategoryRepository), new(mocks.MockCustomerRepository), settingRepo)
+	service := NewTransferService(transferRepo, itemRepo, new(mocks.MockBranchRepository), itemService, settingRepo)
+	return service, transferRepo, itemRepo
+}
+
+func inTransitTransfer() *domain.ItemTransfer {
+	shippedAt := time.Now().Add(-1 * time.Hour)
+	return &domain.ItemTransfer{
+		ID:             1,
+		TransferNumber: "TR-001",
+		ItemID:         1,
+		FromBranchID:   1,
+		ToBranchID:     2,
+		Status:         domain.TransferStatusInTransit,
+		ShippedAt:      &shippedAt,
+	}
+}
+
+func TestTransferService_Receive_RepricesForDestinationBranch(t *testing.T) {
+	service, transferRepo, itemRepo := setupRepricingTransferService(true)
+	ctx := context.Background()
+
+	salePrice := 700.0
+	item := &domain.Item{
+		ID:             1,
+		BranchID:       1,
+		Status:         domain.ItemStatusInTransfer,
+		Condition:      string(domain.ItemConditionGood),
+		AppraisedValue: 750,
+		SalePrice:      &salePrice,
+	}
+
+	var history *domain.ItemHistory
+	transferRepo.On("GetByID", ctx, int64(1)).Return(inTransitTransfer(), nil)
+	transferRepo.On("Update", ctx, mock.AnythingOfType("*domain.ItemTransfer")).Return(nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	itemRepo.On("Update", ctx, mock.AnythingOfType("*domain.Item")).Return(nil)
+	itemRepo.On("CreateHistory", ctx, mock.AnythingOfType("*domain.ItemHistory")).
+		Run(func(args mock.Arguments) { history = args.Get(1).(*domain.ItemHistory) }).
+		Return(nil)
+
+	_, err := service.Receive(ctx, 1, 200, "")
+
+	assert.NoError(t, err)
+	// 750 appraised is 1000 market value; x 0.75 (good) x 1.2 destination market factor
+	assert.Equal(t, 900.0, *item.SalePrice)
+	if assert.NotNil(t, history) {
+		assert.Equal(t, domain.ItemHistoryActionRepriced, history.Action)
+		assert.Equal(t, int64(2), *history.NewBranchID)
+		assert.Equal(t, int64(1), *history.ReferenceID)
+		assert.Equal(t, int64(200), history.CreatedBy)
+		assert.Contains(t, history.Notes, "Q700.00 → Q900.00")
+	}
+}
+
+func TestTransferService_Receive_KeepsPriceWhenRepricingDisabled(t *testing.T) {
+	service, transferRepo, itemRepo := setupRepricingTransferService(false)
+	ctx := context.Background()
+
+	salePrice := 700.0
+	item := &domain.Item{
+		ID:             1,
+		BranchID:       1,
+		Status:         domain.ItemStatusInTransfer,
+		Condition:      string(domain.ItemConditionGood),
+		AppraisedValue: 1000,
+		SalePrice:      &salePrice,
+	}
+
+	transferRepo.On("GetByID", ctx, int64(1)).Return(inTransitTransfer(), nil)
+	transferRepo.On("Update", ctx, mock.AnythingOfType("*domain.ItemTransfer")).Return(nil)
+	itemRepo.On("GetByID", ctx, int64(1)).Return(item, nil)
+	itemRepo.On("Update", ctx, mock.AnythingOfType("*domain.Item")).Return(nil)
+
+	_, err := service.Receive(ctx, 1, 200, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 700.0, *item.SalePrice)
+	itemRepo.AssertNotCalled(t, "CreateHistory", mock.Anything, mock.Anything)
+}
+
 func TestTransferService_Receive_NotFound(t *testing.T) {
 	service, transferRepo, _, _ := setupTransferService()
 	ctx := context.Background()
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"pawnshop/internal/domain"
+	"pawnshop/internal/pdf"
+)
+
+// SetTrialBalancePDF enables rendering the trial balance as a PDF
+func (s *AccountingService) SetTrialBalancePDF(generator *pdf.Generator) {
+	s.pdfGenerator = generator
+}
+
+// GetTrialBalance sums the posted debits and credits of every account up to
+// asOf, for one branch or all of them when branchID is nil. Accounts without
+// postings are left out.
+func (s *AccountingService) GetTrialBalance(ctx context.Context, branchID *int64, asOf time.Time) (*domain.TrialBalance, error) {
+	totals, err := s.entryRepo.GetAccountTotals(ctx, branchID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum account postings: %w", err)
+	}
+
+	accounts, err := s.accountRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	accountsByID := make(map[int64]*domain.Account, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	trialBalance := &domain.TrialBalance{BranchID: branchID, AsOf: asOf, Lines: []*domain.TrialBalanceLine{}}
+	for _, total := range totals {
+		account, ok := accountsByID[total.AccountID]
+		if !ok {
+			return nil, fmt.Errorf("account %d not found", total.AccountID)
+		}
+
+		line := &domain.TrialBalanceLine{
+			AccountID:   account.ID,
+			AccountCode: account.Code,
+			AccountName: account.Name,
+			AccountType: account.AccountType,
+			Debit:       roundCents(total.Debit),
+			Credit:      roundCents(total.Credit),
+		}
+		line.Balance = roundCents(line.Credit - line.Debit)
+		if domain.IsDebitNormal(account.AccountType) {
+			line.Balance = roundCents(line.Debit - line.Credit)
+		}
+
+		trialBalance.Lines = append(trialBalance.Lines, line)
+		trialBalance.TotalDebit += line.Debit
+		trialBalance.TotalCredit += line.Credit
+	}
+
+	typeOrder := make(map[string]int, len(domain.AccountTypes))
+	for i, accountType := range domain.AccountTypes {
+		typeOrder[accountType] = i
+	}
+	sort.SliceStable(trialBalance.Lines, func(i, j int) bool {
+		a, b := trialBalance.Lines[i], trialBalance.Lines[j]
+		if typeOrder[a.AccountType] != typeOrder[b.AccountType] {
+			return typeOrder[a.AccountType] < typeOrder[b.AccountType]
+		}
+		return a.AccountCode < b.AccountCode
+	})
+
+	trialBalance.TotalDebit = roundCents(trialBalance.TotalDebit)
+	trialBalance.TotalCredit = roundCents(trialBalance.TotalCredit)
+	trialBalance.Balanced = math.Abs(trialBalance.TotalDebit-trialBalance.TotalCredit) <= domain.EntryBalanceTolerance
+
+	return trialBalance, nil
+}
+
+// GetTrialBalancePDF renders the trial balance as a PDF
+func (s *AccountingService) GetTrialBalancePDF(ctx context.Context, branchID *int64, asOf time.Time) ([]byte, error) {
+	if s.pdfGenerator == nil {
+		return nil, fmt.Errorf("PDF generation is not configured")
+	}
+
+	trialBalance, err := s.GetTrialBalance(ctx, branchID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	return s.pdfGenerator.GenerateTrialBalancePDF(trialBalance)
+}
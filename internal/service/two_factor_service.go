@@ -41,6 +41,10 @@ type TwoFactorService interface {
 	// Enable enables 2FA for a user after verifying the TOTP code
 	Enable(ctx context.Context, userID int64, code string) error
 
+	// VerifySetup confirms the user can produce a valid code from the secret
+	// issued by Setup, enables 2FA and returns a fresh set of backup codes
+	VerifySetup(ctx context.Context, userID int64, code string) ([]string, error)
+
 	// Disable disables 2FA for a user
 	Disable(ctx context.Context, userID int64, password string) error
 
@@ -149,6 +153,44 @@ func (s *twoFactorService) Enable(ctx context.Context, userID int64, code string
 	return s.twoFactorRepo.Confirm2FA(ctx, userID)
 }
 
+func (s *twoFactorService) VerifySetup(ctx context.Context, userID int64, code string) ([]string, error) {
+	enabled, err := s.twoFactorRepo.Is2FAEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := s.twoFactorRepo.Get2FASecret(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, ErrTwoFactorNotSetup
+	}
+
+	// A wrong code leaves 2FA disabled so the user can scan the QR code again
+	if !totp.Validate(code, secret) {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	// Issue the backup codes the user keeps, replacing those generated at setup
+	backupCodes, codeHashes, err := s.generateBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.CreateBackupCodes(ctx, userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.Confirm2FA(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return backupCodes, nil
+}
+
 func (s *twoFactorService) Disable(ctx context.Context, userID int64, password string) error {
 	// Get user to verify password
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -107,6 +107,48 @@ func TestTwoFactorService_Enable_InvalidCode(t *testing.T) {
 	twoFactorRepo.AssertExpectations(t)
 }
 
+func TestTwoFactorService_VerifySetup_InvalidCodeLeavesDisabled(t *testing.T) {
+	service, twoFactorRepo, _ := setupTwoFactorService()
+	ctx := context.Background()
+
+	key, _ := totp.Generate(totp.GenerateOpts{
+		Issuer:      "TestApp",
+		AccountName: "test@example.com",
+	})
+
+	twoFactorRepo.On("Is2FAEnabled", ctx, int64(1)).Return(false, nil)
+	twoFactorRepo.On("Get2FASecret", ctx, int64(1)).Return(key.Secret(), nil)
+
+	codes, err := service.VerifySetup(ctx, 1, "000000")
+
+	assert.Equal(t, ErrInvalidTOTPCode, err)
+	assert.Nil(t, codes)
+	twoFactorRepo.AssertNotCalled(t, "Confirm2FA", mock.Anything, mock.Anything)
+	twoFactorRepo.AssertNotCalled(t, "CreateBackupCodes", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTwoFactorService_VerifySetup_ValidCodeEnablesAndReturnsBackupCodes(t *testing.T) {
+	service, twoFactorRepo, _ := setupTwoFactorService()
+	ctx := context.Background()
+
+	key, _ := totp.Generate(totp.GenerateOpts{
+		Issuer:      "TestApp",
+		AccountName: "test@example.com",
+	})
+	validCode, _ := totp.GenerateCode(key.Secret(), time.Now())
+
+	twoFactorRepo.On("Is2FAEnabled", ctx, int64(1)).Return(false, nil)
+	twoFactorRepo.On("Get2FASecret", ctx, int64(1)).Return(key.Secret(), nil)
+	twoFactorRepo.On("CreateBackupCodes", ctx, int64(1), mock.AnythingOfType("[]string")).Return(nil)
+	twoFactorRepo.On("Confirm2FA", ctx, int64(1)).Return(nil)
+
+	codes, err := service.VerifySetup(ctx, 1, validCode)
+
+	assert.NoError(t, err)
+	assert.Len(t, codes, BackupCodesCount)
+	twoFactorRepo.AssertExpectations(t)
+}
+
 func TestTwoFactorService_Disable_Success(t *testing.T) {
 	service, twoFactorRepo, userRepo := setupTwoFactorService()
 	ctx := context.Background()
@@ -0,0 +1,4 @@
+-- Remove default loan limit settings
+DELETE FROM settings
+WHERE key IN ('min_loan_amount', 'max_loan_amount', 'min_loan_term_days', 'max_loan_term_days')
+  AND branch_id IS NULL;
@@ -0,0 +1,7 @@
+-- Add default loan amount and term limits (can be overridden per branch)
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('min_loan_amount', '1', 'Monto mínimo de préstamo', NULL),
+('max_loan_amount', '1000000', 'Monto máximo de préstamo', NULL),
+('min_loan_term_days', '1', 'Plazo mínimo de préstamo (días)', NULL),
+('max_loan_term_days', '365', 'Plazo máximo de préstamo (días)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
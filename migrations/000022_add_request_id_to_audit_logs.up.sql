@@ -0,0 +1,6 @@
+-- Correlate audit entries with the request that produced them
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs(request_id);
+
+COMMENT ON COLUMN audit_logs.request_id IS 'X-Request-ID of the request that produced the entry';
@@ -0,0 +1,4 @@
+-- Remove item condition scale setting
+DELETE FROM settings
+WHERE key = 'item_condition_scale'
+  AND branch_id IS NULL;
@@ -0,0 +1,7 @@
+-- Add default item condition scale with the value factor applied to each grade
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('item_condition_scale',
+ '[{"code": "new", "label": "Nuevo", "factor": 1.0}, {"code": "excellent", "label": "Excelente", "factor": 0.9}, {"code": "good", "label": "Bueno", "factor": 0.75}, {"code": "fair", "label": "Regular", "factor": 0.6}, {"code": "poor", "label": "Malo", "factor": 0.4}]',
+ 'Escala de condición de artículos y factor de valor',
+ NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
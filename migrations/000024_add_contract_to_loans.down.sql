@@ -0,0 +1,8 @@
+-- Remove stored contract reference from loans
+DELETE FROM settings
+WHERE key = 'auto_generate_contract'
+  AND branch_id IS NULL;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS contract_hash;
+ALTER TABLE loans DROP COLUMN IF EXISTS contract_url;
+ALTER TABLE loans DROP COLUMN IF EXISTS contract_document_id;
@@ -0,0 +1,9 @@
+-- Reference the contract stored when the loan was created
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS contract_document_id BIGINT REFERENCES documents(id);
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS contract_url VARCHAR(500);
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS contract_hash VARCHAR(64);
+
+-- Contract auto-generation is off by default
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('auto_generate_contract', 'false', 'Generar y guardar el contrato al crear el préstamo', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Remove normalized phone search support
+DROP INDEX IF EXISTS idx_customers_email_trgm;
+DROP INDEX IF EXISTS idx_customers_phone_normalized_trgm;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_normalized;
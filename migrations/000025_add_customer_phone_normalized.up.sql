@@ -0,0 +1,8 @@
+-- Digits-only phone for partial phone searches regardless of formatting
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_normalized VARCHAR(50)
+    GENERATED ALWAYS AS (regexp_replace(phone, '[^0-9]', '', 'g')) STORED;
+
+-- Trigram indexes keep substring matches (e.g. last 4 digits) fast
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_customers_phone_normalized_trgm ON customers USING gin (phone_normalized gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_email_trgm ON customers USING gin (email gin_trgm_ops);
@@ -0,0 +1,4 @@
+-- Remove expense category keyword rules
+DELETE FROM settings
+WHERE key = 'expense_category_keywords'
+  AND branch_id IS NULL;
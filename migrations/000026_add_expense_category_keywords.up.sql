@@ -0,0 +1,7 @@
+-- Add keyword rules used to suggest an expense category from its description
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('expense_category_keywords',
+ '{"salario": "SAL", "planilla": "SAL", "alquiler": "RNT", "renta": "RNT", "luz": "UTL", "agua": "UTL", "teléfono": "UTL", "internet": "UTL", "papelería": "SUP", "tinta": "SUP", "reparación": "MNT", "mantenimiento": "MNT", "gasolina": "TRN", "combustible": "TRN", "publicidad": "MKT", "anuncio": "MKT"}',
+ 'Palabras clave para sugerir la categoría de un gasto (palabra → código de categoría)',
+ NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,2 @@
+-- Drop accounting entry number sequences
+DROP TABLE IF EXISTS accounting_entry_sequences;
@@ -0,0 +1,14 @@
+-- Per-day counter for accounting entry numbers (JE-YYYYMMDD-NNNN)
+CREATE TABLE IF NOT EXISTS accounting_entry_sequences (
+    sequence_date DATE PRIMARY KEY,
+    last_value    INTEGER NOT NULL DEFAULT 0
+);
+
+-- Continue from the numbers already issued
+INSERT INTO accounting_entry_sequences (sequence_date, last_value)
+SELECT TO_DATE(SUBSTRING(entry_number FROM 'JE-(\d{8})-'), 'YYYYMMDD'),
+       MAX(CAST(SUBSTRING(entry_number FROM 'JE-\d{8}-(\d+)') AS INTEGER))
+FROM accounting_entries
+WHERE entry_number ~ '^JE-\d{8}-\d+$'
+GROUP BY 1
+ON CONFLICT (sequence_date) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Remove dashboard alert thresholds
+DELETE FROM settings
+WHERE key IN ('dashboard_alert_overdue_ratio', 'dashboard_alert_min_cash_float', 'dashboard_alert_category_concentration')
+  AND branch_id IS NULL;
@@ -0,0 +1,6 @@
+-- Add dashboard alert thresholds
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('dashboard_alert_overdue_ratio', '20', 'Porcentaje de préstamos vencidos que activa una alerta en el tablero', NULL),
+('dashboard_alert_min_cash_float', '0', 'Efectivo mínimo en caja abierta antes de alertar (0 = desactivado)', NULL),
+('dashboard_alert_category_concentration', '50', 'Porcentaje del valor de inventario en una sola categoría que activa una alerta', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
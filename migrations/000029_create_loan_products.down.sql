@@ -0,0 +1,2 @@
+-- Drop loan products
+DROP TABLE IF EXISTS loan_products;
@@ -0,0 +1,29 @@
+-- Loan products: named presets of loan terms
+CREATE TABLE loan_products (
+    id                       BIGSERIAL PRIMARY KEY,
+    name                     VARCHAR(255) NOT NULL,
+    code                     VARCHAR(30) NOT NULL UNIQUE,
+    description              TEXT,
+
+    -- Preset terms
+    interest_rate            DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    loan_term_days           INTEGER NOT NULL,
+    grace_period_days        INTEGER NOT NULL DEFAULT 0,
+    late_fee_rate            DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    payment_plan_type        VARCHAR(20) NOT NULL DEFAULT 'single',
+    number_of_installments   INTEGER NOT NULL DEFAULT 0,
+    requires_minimum_payment BOOLEAN NOT NULL DEFAULT false,
+
+    is_active                BOOLEAN NOT NULL DEFAULT true,
+
+    -- Timestamps
+    created_at               TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at               TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_loan_products_is_active ON loan_products(is_active);
+
+CREATE TRIGGER loan_products_updated_at
+    BEFORE UPDATE ON loan_products
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
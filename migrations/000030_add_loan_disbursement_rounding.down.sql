@@ -0,0 +1,6 @@
+-- Remove cash disbursement rounding
+DELETE FROM settings
+WHERE key = 'cash_disbursement_denomination'
+  AND branch_id IS NULL;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS disbursement_rounding;
@@ -0,0 +1,7 @@
+-- Record the adjustment applied when a cash disbursement is rounded to a denomination
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS disbursement_rounding DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Rounding is off by default
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('cash_disbursement_denomination', '0', 'Denominación mínima para redondear desembolsos en efectivo (0 = sin redondeo)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
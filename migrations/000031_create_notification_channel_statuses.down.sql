@@ -0,0 +1,6 @@
+-- Remove notification channel failure escalation
+DELETE FROM settings
+WHERE key IN ('notification_failure_escalation_threshold', 'notification_failure_escalation_window_days')
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS notification_channel_statuses;
@@ -0,0 +1,18 @@
+-- Delivery health of each customer notification channel
+CREATE TABLE IF NOT EXISTS notification_channel_statuses (
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    failure_streak INT NOT NULL DEFAULT 0,
+    streak_started_at TIMESTAMPTZ,
+    paused_at TIMESTAMPTZ,
+    pause_reason TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (customer_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channel_statuses_paused ON notification_channel_statuses(paused_at) WHERE paused_at IS NOT NULL;
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('notification_failure_escalation_threshold', '3', 'Notificaciones fallidas (tras agotar reintentos) antes de pausar el canal del cliente (0 = desactivado)', NULL),
+('notification_failure_escalation_window_days', '30', 'Días en los que se cuentan las notificaciones fallidas de un canal', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
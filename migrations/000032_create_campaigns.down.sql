@@ -0,0 +1,7 @@
+-- Drop campaigns
+ALTER TABLE loans
+    DROP COLUMN IF EXISTS campaign_interest_discount,
+    DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS campaign_customers;
+DROP TABLE IF EXISTS campaigns;
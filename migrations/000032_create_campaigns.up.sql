@@ -0,0 +1,47 @@
+-- Campaigns: date-bounded promotions that discount interest and late fees of new loans
+CREATE TABLE campaigns (
+    id                        BIGSERIAL PRIMARY KEY,
+    name                      VARCHAR(255) NOT NULL,
+    code                      VARCHAR(30) NOT NULL UNIQUE,
+    description               TEXT,
+
+    -- Window, both days inclusive
+    start_date                DATE NOT NULL,
+    end_date                  DATE NOT NULL,
+
+    -- Scope
+    branch_id                 BIGINT REFERENCES branches(id),
+    flagged_customers_only    BOOLEAN NOT NULL DEFAULT false,
+
+    -- Discounts in percent
+    interest_discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    late_fee_discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+
+    is_active                 BOOLEAN NOT NULL DEFAULT true,
+
+    -- Timestamps
+    created_at                TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at                TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT campaigns_window_check CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_campaigns_window ON campaigns(start_date, end_date) WHERE is_active = true;
+
+CREATE TRIGGER campaigns_updated_at
+    BEFORE UPDATE ON campaigns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Customers eligible for campaigns restricted to flagged customers
+CREATE TABLE campaign_customers (
+    campaign_id BIGINT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, customer_id)
+);
+
+-- Campaign applied to a loan and the interest it waived
+ALTER TABLE loans
+    ADD COLUMN campaign_id BIGINT REFERENCES campaigns(id) ON DELETE SET NULL,
+    ADD COLUMN campaign_interest_discount DECIMAL(12, 2) NOT NULL DEFAULT 0;
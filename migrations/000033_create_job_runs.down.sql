@@ -0,0 +1,2 @@
+-- Drop job runs
+DROP TABLE IF EXISTS job_runs;
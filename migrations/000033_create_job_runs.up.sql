@@ -0,0 +1,19 @@
+-- Job runs: outcome of every scheduler job execution, for worker health reporting
+CREATE TABLE job_runs (
+    id               BIGSERIAL PRIMARY KEY,
+    job_name         VARCHAR(100) NOT NULL,
+    status           VARCHAR(20) NOT NULL,
+    error_message    TEXT,
+    items_processed  INTEGER NOT NULL DEFAULT 0,
+
+    started_at       TIMESTAMPTZ NOT NULL,
+    finished_at      TIMESTAMPTZ NOT NULL,
+    duration_ms      BIGINT NOT NULL DEFAULT 0,
+
+    -- Schedule the job ran under
+    interval_seconds BIGINT NOT NULL DEFAULT 0,
+    critical         BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX idx_job_runs_job_started ON job_runs(job_name, started_at DESC);
+CREATE INDEX idx_job_runs_job_success ON job_runs(job_name, started_at DESC) WHERE status = 'success';
@@ -0,0 +1,6 @@
+-- Remove numbering reset cadence
+DELETE FROM settings
+WHERE key = 'sequence_reset_cadence'
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS number_sequences;
@@ -0,0 +1,31 @@
+-- Per-period counters for loan and payment numbers (LN-2026-000001, PY-2026-10-000001, LN-000001)
+CREATE TABLE IF NOT EXISTS number_sequences (
+    name       VARCHAR(50) NOT NULL,
+    period     VARCHAR(10) NOT NULL DEFAULT '',
+    last_value INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (name, period)
+);
+
+-- Continue the yearly sequences from the numbers already issued
+INSERT INTO number_sequences (name, period, last_value)
+SELECT 'loan',
+       SUBSTRING(loan_number FROM 'LN-(\d{4})-'),
+       MAX(CAST(SUBSTRING(loan_number FROM 'LN-\d{4}-(\d+)') AS INTEGER))
+FROM loans
+WHERE loan_number ~ '^LN-\d{4}-\d+$'
+GROUP BY 2
+ON CONFLICT (name, period) DO NOTHING;
+
+INSERT INTO number_sequences (name, period, last_value)
+SELECT 'payment',
+       SUBSTRING(payment_number FROM 'PY-(\d{4})-'),
+       MAX(CAST(SUBSTRING(payment_number FROM 'PY-\d{4}-(\d+)') AS INTEGER))
+FROM payments
+WHERE payment_number ~ '^PY-\d{4}-\d+$'
+GROUP BY 2
+ON CONFLICT (name, period) DO NOTHING;
+
+-- Numbers keep restarting every year by default; branches may override it
+INSERT INTO settings (key, value, description, branch_id) VALUES
+('sequence_reset_cadence', '"yearly"', 'Reinicio de la numeración de préstamos y pagos: never, yearly o monthly', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
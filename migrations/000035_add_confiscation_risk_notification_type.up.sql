@@ -0,0 +1,2 @@
+-- Final warning sent before a loan's grace period ends and the item is confiscated
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'confiscation_risk_reminder' AFTER 'loan_confiscation';
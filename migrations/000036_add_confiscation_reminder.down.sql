@@ -0,0 +1,7 @@
+-- Remove confiscation risk reminder templates and setting
+DELETE FROM settings
+WHERE key = 'confiscation_reminder_days'
+  AND branch_id IS NULL;
+
+DELETE FROM notification_templates
+WHERE code IN ('CONFISCATION_RISK_SMS', 'CONFISCATION_RISK_EMAIL');
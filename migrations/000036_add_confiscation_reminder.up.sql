@@ -0,0 +1,16 @@
+-- Confiscation risk reminder templates and setting
+INSERT INTO notification_templates (code, name, notification_type, channel, subject, body, variables, is_system) VALUES
+    ('CONFISCATION_RISK_SMS', 'Aviso Final de Confiscación (SMS)', 'confiscation_risk_reminder', 'sms',
+     NULL,
+     'ULTIMO AVISO: su prestamo #{{loan_number}} ({{item_description}}) sera confiscado el {{confiscation_date}}. Liquide {{currency}}{{payoff_amount}} para recuperar su articulo.',
+     '["loan_number", "item_description", "confiscation_date", "payoff_amount", "currency"]', true),
+
+    ('CONFISCATION_RISK_EMAIL', 'Aviso Final de Confiscación (Email)', 'confiscation_risk_reminder', 'email',
+     'ÚLTIMO AVISO: Su artículo será confiscado',
+     'Estimado(a) {{customer_name}},\n\nEl período de gracia de su préstamo #{{loan_number}} termina el {{confiscation_date}} (en {{days_left}} día(s)). Después de esa fecha el artículo en prenda será confiscado.\n\nArtículo: {{item_description}}\nMonto para liquidar: {{currency}}{{payoff_amount}}\n\nAcérquese a nuestra sucursal para liquidar o renovar su préstamo.\n\nAtentamente.',
+     '["customer_name", "loan_number", "confiscation_date", "days_left", "item_description", "payoff_amount", "currency"]', true)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('confiscation_reminder_days', '3', 'Días antes del fin del período de gracia para enviar el aviso final de confiscación (0 = desactivado)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,15 @@
+-- Internal staff notes attached to loans, items and customers
+CREATE TABLE notes (
+    id                  BIGSERIAL PRIMARY KEY,
+    entity_type         VARCHAR(20) NOT NULL CHECK (entity_type IN ('loan', 'item', 'customer')),
+    entity_id           BIGINT NOT NULL,
+    parent_id           BIGINT REFERENCES notes(id) ON DELETE CASCADE,
+    body                TEXT NOT NULL,
+    mentioned_user_ids  JSONB NOT NULL DEFAULT '[]',
+    created_by          BIGINT NOT NULL REFERENCES users(id),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at          TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notes_entity ON notes(entity_type, entity_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_notes_parent ON notes(parent_id);
@@ -0,0 +1,6 @@
+-- Remove exchange rates
+DELETE FROM settings
+WHERE key IN ('report_base_currency', 'fx_revaluation_mode')
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS fx_rates;
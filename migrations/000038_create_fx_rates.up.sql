@@ -0,0 +1,25 @@
+-- Exchange rates used to report branch figures in a common base currency
+CREATE TABLE fx_rates (
+    id              BIGSERIAL PRIMARY KEY,
+    from_currency   VARCHAR(10) NOT NULL,
+    to_currency     VARCHAR(10) NOT NULL,
+    rate_date       DATE NOT NULL,
+    rate            DECIMAL(18, 8) NOT NULL CHECK (rate > 0),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (from_currency, to_currency, rate_date),
+    CHECK (from_currency <> to_currency)
+);
+
+CREATE INDEX idx_fx_rates_pair_date ON fx_rates(from_currency, to_currency, rate_date DESC);
+
+CREATE TRIGGER fx_rates_updated_at
+    BEFORE UPDATE ON fx_rates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('report_base_currency', '"GTQ"', 'Moneda base para consolidar reportes de sucursales', NULL),
+    ('fx_revaluation_mode', '"transaction_date"', 'Tipo de cambio para consolidar: transaction_date (fecha de cada transacción) o period_end (fin del período)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
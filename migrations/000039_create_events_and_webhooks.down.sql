@@ -0,0 +1,7 @@
+-- Remove events and webhooks
+DELETE FROM settings
+WHERE key = 'cash_difference_alert_threshold'
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS events;
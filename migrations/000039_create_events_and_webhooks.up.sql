@@ -0,0 +1,38 @@
+-- Business events shown in the activity feed and delivered to webhooks
+CREATE TABLE events (
+    id              BIGSERIAL PRIMARY KEY,
+    event_type      VARCHAR(100) NOT NULL,
+    branch_id       BIGINT REFERENCES branches(id),
+    entity_type     VARCHAR(50) NOT NULL,
+    entity_id       BIGINT NOT NULL,
+    user_id         BIGINT REFERENCES users(id),
+    payload         JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_events_created_at ON events(created_at DESC);
+CREATE INDEX idx_events_branch ON events(branch_id, created_at DESC);
+CREATE INDEX idx_events_entity ON events(entity_type, entity_id);
+
+-- External endpoints subscribed to events
+CREATE TABLE webhooks (
+    id              BIGSERIAL PRIMARY KEY,
+    name            VARCHAR(100) NOT NULL,
+    url             VARCHAR(500) NOT NULL,
+    secret          VARCHAR(255) NOT NULL DEFAULT '',
+    event_types     JSONB NOT NULL DEFAULT '[]',
+    is_active       BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by      BIGINT REFERENCES users(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at      TIMESTAMPTZ
+);
+
+CREATE TRIGGER webhooks_updated_at
+    BEFORE UPDATE ON webhooks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('cash_difference_alert_threshold', '50', 'Diferencia absoluta al cerrar caja a partir de la cual se marca el cierre como fuera de umbral', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
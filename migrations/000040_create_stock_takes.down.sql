@@ -0,0 +1,7 @@
+-- Remove stock takes
+DELETE FROM settings
+WHERE key = 'stock_take_lock_items'
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS stock_take_scans;
+DROP TABLE IF EXISTS stock_takes;
@@ -0,0 +1,33 @@
+-- Physical inventory counts per branch
+CREATE TABLE stock_takes (
+    id              BIGSERIAL PRIMARY KEY,
+    branch_id       BIGINT NOT NULL REFERENCES branches(id),
+    status          VARCHAR(20) NOT NULL DEFAULT 'in_progress'
+                    CHECK (status IN ('in_progress', 'finalized', 'cancelled')),
+    notes           TEXT,
+    report          JSONB,
+    started_by      BIGINT NOT NULL REFERENCES users(id),
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finalized_by    BIGINT REFERENCES users(id),
+    finalized_at    TIMESTAMPTZ
+);
+
+-- Only one count in progress per branch
+CREATE UNIQUE INDEX idx_stock_takes_active_branch ON stock_takes(branch_id) WHERE status = 'in_progress';
+CREATE INDEX idx_stock_takes_branch ON stock_takes(branch_id, started_at DESC);
+
+-- SKUs scanned during a count; scanning a SKU twice is a no-op
+CREATE TABLE stock_take_scans (
+    id              BIGSERIAL PRIMARY KEY,
+    stock_take_id   BIGINT NOT NULL REFERENCES stock_takes(id) ON DELETE CASCADE,
+    sku             VARCHAR(100) NOT NULL,
+    item_id         BIGINT REFERENCES items(id),
+    scanned_by      BIGINT NOT NULL REFERENCES users(id),
+    scanned_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (stock_take_id, sku)
+);
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('stock_take_lock_items', 'true', 'Bloquear la edición de artículos de la sucursal mientras hay un inventario físico en curso; si es false solo se marcan los cambios en el reporte', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Remove stored file tracking
+DELETE FROM settings
+WHERE key = 'storage_quota_mb'
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS stored_files;
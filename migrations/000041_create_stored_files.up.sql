@@ -0,0 +1,21 @@
+-- Metadata of uploaded files, used for per-branch storage quotas and usage reporting
+CREATE TABLE stored_files (
+    id              BIGSERIAL PRIMARY KEY,
+    branch_id       BIGINT NOT NULL REFERENCES branches(id),
+    entity_type     VARCHAR(50) NOT NULL,
+    entity_id       BIGINT NOT NULL,
+    file_id         VARCHAR(500) NOT NULL,
+    url             VARCHAR(500) NOT NULL,
+    mime_type       VARCHAR(100) NOT NULL,
+    size_bytes      BIGINT NOT NULL DEFAULT 0,
+    created_by      BIGINT REFERENCES users(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at      TIMESTAMPTZ
+);
+
+CREATE INDEX idx_stored_files_branch ON stored_files(branch_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_stored_files_url ON stored_files(url) WHERE deleted_at IS NULL;
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('storage_quota_mb', '5120', 'Espacio máximo en MB para fotos y documentos de cada sucursal (0 = sin límite)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Remove automatic markdown settings
+DROP INDEX IF EXISTS idx_item_history_item_action;
+
+DELETE FROM settings
+WHERE key IN ('markdown_enabled', 'markdown_rules', 'markdown_floor_percent', 'markdown_notify_managers')
+  AND branch_id IS NULL;
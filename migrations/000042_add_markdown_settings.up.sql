@@ -0,0 +1,9 @@
+-- Automatic markdown of items that stay for sale too long
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('markdown_enabled', 'false', 'Rebajar automáticamente el precio de artículos en venta sin vender', NULL),
+    ('markdown_rules', '[{"after_days": 30, "percent": 10}, {"after_days": 60, "percent": 10}]', 'Rebajas por antigüedad en venta: porcentaje descontado al cumplir cada cantidad de días', NULL),
+    ('markdown_floor_percent', '50', 'Precio mínimo tras rebajas, como porcentaje del valor de avalúo', NULL),
+    ('markdown_notify_managers', 'true', 'Notificar a los gerentes de la sucursal cuando se rebajan artículos', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_item_history_item_action ON item_history(item_id, action);
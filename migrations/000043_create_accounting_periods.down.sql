@@ -0,0 +1,6 @@
+-- Remove accounting periods
+DELETE FROM settings
+WHERE key = 'period_close_post_accruals'
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS accounting_periods;
@@ -0,0 +1,29 @@
+-- Monthly accounting periods per branch, with the accruals booked when closing them
+CREATE TABLE accounting_periods (
+    id                  BIGSERIAL PRIMARY KEY,
+    branch_id           BIGINT NOT NULL REFERENCES branches(id),
+    year                INTEGER NOT NULL,
+    month               INTEGER NOT NULL CHECK (month BETWEEN 1 AND 12),
+    status              VARCHAR(20) NOT NULL DEFAULT 'open',
+
+    accrued_interest    DECIMAL(12,2) NOT NULL DEFAULT 0,
+    accrued_late_fees   DECIMAL(12,2) NOT NULL DEFAULT 0,
+    accrual_entry_id    BIGINT REFERENCES accounting_entries(id),
+    accruals_posted_at  TIMESTAMPTZ,
+
+    closed_at           TIMESTAMPTZ,
+    closed_by           BIGINT REFERENCES users(id),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (branch_id, year, month)
+);
+
+CREATE TRIGGER accounting_periods_updated_at
+    BEFORE UPDATE ON accounting_periods
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('period_close_post_accruals', 'true', 'Registrar automáticamente intereses y mora devengados no cobrados al cerrar un período contable', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
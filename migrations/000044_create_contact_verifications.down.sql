@@ -0,0 +1,17 @@
+-- Remove phone verification
+DELETE FROM settings
+WHERE key IN (
+    'customer_phone_verification_enabled',
+    'customer_phone_verification_channel',
+    'customer_phone_verification_ttl_minutes',
+    'customer_phone_verification_max_attempts'
+)
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS contact_verifications;
+
+-- Note: PostgreSQL does not support removing values from an enum type directly.
+-- The added value 'contact_verification' is left in place.
+
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_verified_channel;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_verified_at;
@@ -0,0 +1,27 @@
+-- Phone verification for customers
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_verified_channel VARCHAR(20);
+
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'contact_verification';
+
+-- One-time codes sent to confirm a customer's phone
+CREATE TABLE contact_verifications (
+    id              BIGSERIAL PRIMARY KEY,
+    customer_id     BIGINT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    phone           VARCHAR(50) NOT NULL,
+    channel         VARCHAR(20) NOT NULL,
+    code_hash       VARCHAR(64) NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    verified_at     TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_contact_verifications_customer ON contact_verifications(customer_id, created_at DESC);
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('customer_phone_verification_enabled', 'false', 'Enviar un código de verificación al teléfono del cliente al registrarlo', NULL),
+    ('customer_phone_verification_channel', '"sms"', 'Canal para enviar el código de verificación (sms o whatsapp)', NULL),
+    ('customer_phone_verification_ttl_minutes', '15', 'Minutos de validez del código de verificación', NULL),
+    ('customer_phone_verification_max_attempts', '5', 'Intentos permitidos para confirmar un código de verificación', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Remove loan quote mode setting
+DELETE FROM settings
+WHERE key = 'loan_quote_mode'
+  AND branch_id IS NULL;
@@ -0,0 +1,4 @@
+-- How loan quotes present the cost of a loan
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loan_quote_mode', '"itemized"', 'Presentación de cotizaciones de préstamo: itemized (capital e interés por separado) o all_in (solo el total a pagar)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
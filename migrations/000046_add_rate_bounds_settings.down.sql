@@ -0,0 +1,4 @@
+-- Remove plausible rate bounds settings
+DELETE FROM settings
+WHERE key IN ('min_plausible_monthly_interest_rate', 'max_plausible_monthly_interest_rate', 'max_plausible_late_fee_rate')
+  AND branch_id IS NULL;
@@ -0,0 +1,6 @@
+-- Plausible bounds used to catch mistyped loan rates
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('min_plausible_monthly_interest_rate', '1', 'Tasa de interés mensual mínima plausible (%); tasas menores requieren un motivo de autorización', NULL),
+    ('max_plausible_monthly_interest_rate', '25', 'Tasa de interés mensual máxima plausible (%); tasas mayores requieren un motivo de autorización', NULL),
+    ('max_plausible_late_fee_rate', '5', 'Tasa de mora diaria máxima plausible (%); tasas mayores requieren un motivo de autorización', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
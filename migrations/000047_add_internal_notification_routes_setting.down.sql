@@ -0,0 +1,4 @@
+-- Remove internal notification routing setting
+DELETE FROM settings
+WHERE key IN ('internal_notification_routes')
+  AND branch_id IS NULL;
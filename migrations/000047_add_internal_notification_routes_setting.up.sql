@@ -0,0 +1,4 @@
+-- Routing of internal events to staff roles
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('internal_notification_routes', '[{"event": "high_value_loan", "roles": ["manager"], "branch_only": true, "min_amount": 10000}, {"event": "cash_difference", "roles": ["manager"], "branch_only": true}, {"event": "confiscation", "roles": ["manager"], "branch_only": true}]', 'Destinatarios de las notificaciones internas por evento', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Remove daily cash disbursement limit setting
+DELETE FROM settings
+WHERE key IN ('daily_cash_disbursement_limit')
+  AND branch_id IS NULL;
@@ -0,0 +1,4 @@
+-- Daily ceiling on cash paid out for loans per branch
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('daily_cash_disbursement_limit', '0', 'Monto máximo de desembolsos de préstamos en efectivo por día en la sucursal (0 = sin límite)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Remove loan balance snapshots
+DELETE FROM settings
+WHERE key IN ('loan_balance_snapshots_enabled')
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS loan_balance_snapshots;
@@ -0,0 +1,25 @@
+-- End-of-day balances of open loans, so historical reports are reproducible
+CREATE TABLE loan_balance_snapshots (
+    id                  BIGSERIAL PRIMARY KEY,
+    loan_id             BIGINT NOT NULL REFERENCES loans(id),
+    branch_id           BIGINT NOT NULL REFERENCES branches(id),
+    snapshot_date       DATE NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+
+    principal_remaining DECIMAL(12,2) NOT NULL DEFAULT 0,
+    interest_remaining  DECIMAL(12,2) NOT NULL DEFAULT 0,
+    accrued_interest    DECIMAL(12,2) NOT NULL DEFAULT 0,
+    late_fee_remaining  DECIMAL(12,2) NOT NULL DEFAULT 0,
+    balance             DECIMAL(12,2) NOT NULL DEFAULT 0,
+    days_past_due       INTEGER NOT NULL DEFAULT 0,
+
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (loan_id, snapshot_date)
+);
+
+CREATE INDEX idx_loan_balance_snapshots_branch_date ON loan_balance_snapshots(branch_id, snapshot_date);
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loan_balance_snapshots_enabled', 'true', 'Guardar cada noche el saldo, interés devengado y mora de los préstamos vigentes para reportes históricos', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
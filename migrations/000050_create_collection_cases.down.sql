@@ -0,0 +1,7 @@
+-- Remove the collections queue
+DELETE FROM settings
+WHERE key = 'collections_overdue_days'
+  AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS collection_contacts;
+DROP TABLE IF EXISTS collection_cases;
@@ -0,0 +1,45 @@
+-- Collections queue of aging overdue loans
+CREATE TABLE collection_cases (
+    id              BIGSERIAL PRIMARY KEY,
+    loan_id         BIGINT NOT NULL REFERENCES loans(id),
+    customer_id     BIGINT NOT NULL REFERENCES customers(id),
+    branch_id       BIGINT NOT NULL REFERENCES branches(id),
+    status          VARCHAR(20) NOT NULL DEFAULT 'in_progress'
+                    CHECK (status IN ('in_progress', 'promise_to_pay', 'uncollectible', 'resolved')),
+    assigned_to     BIGINT REFERENCES users(id),
+
+    days_overdue    INTEGER NOT NULL DEFAULT 0,
+    amount_due      DECIMAL(12,2) NOT NULL DEFAULT 0,
+
+    promise_date    DATE,
+    promise_amount  DECIMAL(12,2),
+
+    notes           TEXT,
+    last_contact_at TIMESTAMPTZ,
+    entered_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at     TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Only one open case per loan
+CREATE UNIQUE INDEX idx_collection_cases_open_loan ON collection_cases(loan_id) WHERE resolved_at IS NULL;
+CREATE INDEX idx_collection_cases_branch_open ON collection_cases(branch_id) WHERE resolved_at IS NULL;
+CREATE INDEX idx_collection_cases_assigned ON collection_cases(assigned_to) WHERE resolved_at IS NULL;
+
+-- Contact attempts logged by staff working a case
+CREATE TABLE collection_contacts (
+    id              BIGSERIAL PRIMARY KEY,
+    case_id         BIGINT NOT NULL REFERENCES collection_cases(id) ON DELETE CASCADE,
+    method          VARCHAR(20) NOT NULL
+                    CHECK (method IN ('phone', 'sms', 'whatsapp', 'email', 'visit')),
+    reached         BOOLEAN NOT NULL DEFAULT false,
+    notes           TEXT,
+    contacted_by    BIGINT NOT NULL REFERENCES users(id),
+    contacted_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_collection_contacts_case ON collection_contacts(case_id, contacted_at DESC);
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('collections_overdue_days', '15', 'Días de atraso a partir de los cuales un préstamo entra a la cola de cobros; 0 desactiva el ingreso automático', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
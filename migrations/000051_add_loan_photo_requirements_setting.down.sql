@@ -0,0 +1,4 @@
+-- Remove the loan photo requirements setting
+DELETE FROM settings
+WHERE key = 'loan_photo_requirements'
+  AND branch_id IS NULL;
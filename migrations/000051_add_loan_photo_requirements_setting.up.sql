@@ -0,0 +1,4 @@
+-- Appraisal photos and documents high-value loans need before they activate
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loan_photo_requirements', '{"min_loan_amount": 5000, "min_photos": 1, "required_documents": []}', 'Fotos del artículo y documentos (customer_photo, customer_identity) requeridos para activar préstamos desde el monto indicado', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
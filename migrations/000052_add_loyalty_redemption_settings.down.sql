@@ -0,0 +1,4 @@
+-- Remove loyalty redemption settings
+DELETE FROM settings
+WHERE key IN ('loyalty_redemption_block_points', 'loyalty_redemption_block_value')
+  AND branch_id IS NULL;
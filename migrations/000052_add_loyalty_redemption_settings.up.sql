@@ -0,0 +1,5 @@
+-- Loyalty points are redeemed in whole blocks of a fixed cash value
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loyalty_redemption_block_points', '100', 'Los puntos de lealtad se canjean en bloques de esta cantidad', NULL),
+    ('loyalty_redemption_block_value', '1.00', 'Valor en efectivo de cada bloque de puntos canjeado', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,2 @@
+-- Remove background CSV exports
+DROP TABLE IF EXISTS export_jobs;
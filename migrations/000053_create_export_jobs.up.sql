@@ -0,0 +1,26 @@
+-- Background CSV exports written in batches and resumed from the last committed cursor
+CREATE TABLE export_jobs (
+    id              BIGSERIAL PRIMARY KEY,
+    dataset         VARCHAR(20) NOT NULL CHECK (dataset IN ('items', 'payments')),
+    branch_id       BIGINT NOT NULL DEFAULT 0, -- 0 exports every branch
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+
+    cursor          BIGINT NOT NULL DEFAULT 0,
+    rows_exported   INTEGER NOT NULL DEFAULT 0,
+    bytes_written   BIGINT NOT NULL DEFAULT 0,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+
+    file_name       VARCHAR(255) NOT NULL,
+    error           TEXT,
+
+    created_by      BIGINT NOT NULL REFERENCES users(id),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMPTZ
+);
+
+-- Only one unfinished export per user, dataset and branch
+CREATE UNIQUE INDEX idx_export_jobs_active ON export_jobs(created_by, dataset, branch_id)
+    WHERE status IN ('pending', 'running');
+CREATE INDEX idx_export_jobs_unfinished ON export_jobs(id) WHERE status IN ('pending', 'running');
@@ -0,0 +1,10 @@
+-- Remove alerts and automatic close for cash sessions left open too long
+DELETE FROM settings
+WHERE key IN ('cash_session_warning_hours', 'cash_session_auto_close_hours')
+  AND branch_id IS NULL;
+
+DROP INDEX IF EXISTS idx_cash_sessions_open;
+
+ALTER TABLE cash_sessions
+    DROP COLUMN IF EXISTS overdue_notified_at,
+    DROP COLUMN IF EXISTS auto_closed;
@@ -0,0 +1,11 @@
+-- Alerts and automatic close for cash sessions left open too long
+ALTER TABLE cash_sessions
+    ADD COLUMN auto_closed BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN overdue_notified_at TIMESTAMPTZ;
+
+CREATE INDEX idx_cash_sessions_open ON cash_sessions(opened_at) WHERE status = 'open';
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('cash_session_warning_hours', '12', 'Horas que una sesión de caja puede seguir abierta antes de avisar al cajero y al gerente; 0 desactiva el aviso', NULL),
+    ('cash_session_auto_close_hours', '0', 'Horas tras las cuales el sistema cierra automáticamente una sesión de caja abierta; 0 desactiva el cierre automático', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Remove the per-user performance report commission rates
+DELETE FROM settings
+WHERE key = 'user_commission_rates'
+  AND branch_id IS NULL;
@@ -0,0 +1,4 @@
+-- Commission rates for the per-user performance report
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('user_commission_rates', '{"loans_percent": 0, "sales_percent": 0}', 'Porcentaje de comisión del personal sobre los préstamos que otorga y las ventas que realiza', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
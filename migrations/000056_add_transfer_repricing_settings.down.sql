@@ -0,0 +1,4 @@
+-- Remove the transfer re-pricing settings
+DELETE FROM settings
+WHERE key IN ('transfer_reprice_on_receipt', 'sale_price_market_factor')
+  AND branch_id IS NULL;
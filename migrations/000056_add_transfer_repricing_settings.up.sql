@@ -0,0 +1,6 @@
+-- Re-pricing of items received by transfer, off by default. The market factor
+-- adjusts suggested sale prices to each branch's market.
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('transfer_reprice_on_receipt', 'false', 'Recalcular el precio de venta de los artículos recibidos por transferencia según las reglas de la sucursal destino', NULL),
+    ('sale_price_market_factor', '1.0', 'Factor de mercado de la sucursal aplicado al precio de venta sugerido', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
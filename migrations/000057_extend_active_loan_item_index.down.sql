@@ -0,0 +1,6 @@
+-- Restore the index covering active loans only
+DROP INDEX IF EXISTS idx_loans_active_item;
+
+CREATE UNIQUE INDEX idx_loans_active_item
+ON loans(item_id)
+WHERE status = 'active' AND deleted_at IS NULL;
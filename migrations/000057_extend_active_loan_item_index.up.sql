@@ -0,0 +1,8 @@
+-- Extend the one-active-loan-per-item index to overdue loans, which still hold
+-- the item as collateral. Creating the index fails if existing data already has
+-- duplicates, which must be resolved by hand first.
+DROP INDEX IF EXISTS idx_loans_active_item;
+
+CREATE UNIQUE INDEX idx_loans_active_item
+ON loans(item_id)
+WHERE status IN ('active', 'overdue') AND deleted_at IS NULL;
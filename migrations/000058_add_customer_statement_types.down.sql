@@ -0,0 +1,3 @@
+-- Note: PostgreSQL does not support removing values from an enum type directly.
+-- This is left as a no-op for safety.
+-- The added values are: notification_type 'customer_statement', document_type 'customer_statement'
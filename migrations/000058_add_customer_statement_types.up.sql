@@ -0,0 +1,3 @@
+-- Monthly customer account statements
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'customer_statement';
+ALTER TYPE document_type ADD VALUE IF NOT EXISTS 'customer_statement' AFTER 'confiscation_notice';
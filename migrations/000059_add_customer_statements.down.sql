@@ -0,0 +1,7 @@
+-- Remove customer statement templates and setting
+DELETE FROM settings
+WHERE key = 'customer_statements_enabled'
+  AND branch_id IS NULL;
+
+DELETE FROM notification_templates
+WHERE code IN ('CUSTOMER_STATEMENT_EMAIL', 'CUSTOMER_STATEMENT_SMS', 'CUSTOMER_STATEMENT_WHATSAPP');
@@ -0,0 +1,22 @@
+-- Customer statement templates and setting. Email statements get the PDF
+-- attached, the other channels a link to it.
+INSERT INTO notification_templates (code, name, notification_type, channel, subject, body, variables, is_system) VALUES
+    ('CUSTOMER_STATEMENT_EMAIL', 'Estado de Cuenta Mensual (Email)', 'customer_statement', 'email',
+     'Su estado de cuenta de {{period}}',
+     'Estimado(a) {{customer_name}},\n\nAdjuntamos su estado de cuenta del período {{period}}.\n\nTotal pagado: {{currency}}{{total_paid}}\nSaldo pendiente: {{currency}}{{balance}}\n\nAtentamente.',
+     '["customer_name", "period", "total_paid", "balance", "currency"]', true),
+
+    ('CUSTOMER_STATEMENT_SMS', 'Estado de Cuenta Mensual (SMS)', 'customer_statement', 'sms',
+     NULL,
+     'Su estado de cuenta de {{period}} esta disponible: {{statement_url}}. Saldo pendiente: {{currency}}{{balance}}',
+     '["period", "statement_url", "balance", "currency"]', true),
+
+    ('CUSTOMER_STATEMENT_WHATSAPP', 'Estado de Cuenta Mensual (WhatsApp)', 'customer_statement', 'whatsapp',
+     NULL,
+     'Hola {{customer_name}}, su estado de cuenta de {{period}} está disponible en {{statement_url}}. Total pagado: {{currency}}{{total_paid}}. Saldo pendiente: {{currency}}{{balance}}.',
+     '["customer_name", "period", "statement_url", "total_paid", "balance", "currency"]', true)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('customer_statements_enabled', 'false', 'Enviar mensualmente el estado de cuenta a los clientes que lo solicitaron', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
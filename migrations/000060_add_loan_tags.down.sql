@@ -0,0 +1,5 @@
+DELETE FROM settings WHERE key = 'loan_legal_hold_tag' AND branch_id IS NULL;
+
+DROP INDEX IF EXISTS idx_loans_tags;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS tags;
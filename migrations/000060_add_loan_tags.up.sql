@@ -0,0 +1,9 @@
+-- Free-form tags on loans. Loans tagged with the legal hold tag are skipped by
+-- the automatic confiscation of the overdue job.
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_loans_tags ON loans USING GIN (tags);
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loan_legal_hold_tag', '"legal_hold"', 'Etiqueta de préstamo que impide la confiscación automática por retención legal', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Drop backup verifications
+DELETE FROM settings WHERE key = 'backup_verification_min_rows' AND branch_id IS NULL;
+
+DROP TABLE IF EXISTS backup_verifications;
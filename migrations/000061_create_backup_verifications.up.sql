@@ -0,0 +1,20 @@
+-- Backup verifications: outcome of test restores of the latest backup into a
+-- scratch database
+CREATE TABLE backup_verifications (
+    id              BIGSERIAL PRIMARY KEY,
+    backup_filename VARCHAR(255) NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    row_counts      JSONB NOT NULL DEFAULT '{}',
+    error_message   TEXT,
+
+    started_at      TIMESTAMPTZ NOT NULL,
+    finished_at     TIMESTAMPTZ NOT NULL,
+    duration_ms     BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_backup_verifications_started ON backup_verifications(started_at DESC);
+
+-- Minimum rows each table must have in the restored backup
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('backup_verification_min_rows', '{"branches": 1, "users": 1, "customers": 0, "items": 0, "loans": 0, "payments": 0}', 'Mínimo de filas por tabla que debe tener un respaldo restaurado para considerarse válido', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
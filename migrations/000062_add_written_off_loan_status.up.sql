@@ -0,0 +1,2 @@
+-- Status of uncollectible loans taken off the books
+ALTER TYPE loan_status ADD VALUE IF NOT EXISTS 'written_off';
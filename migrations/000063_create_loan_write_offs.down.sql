@@ -0,0 +1,7 @@
+-- Drop loan write-offs
+UPDATE roles SET permissions = permissions - 'accounting.write_off' WHERE name = 'admin';
+
+DELETE FROM accounts WHERE code = '5400'
+  AND NOT EXISTS (SELECT 1 FROM accounting_entry_lines l JOIN accounts a ON a.id = l.account_id WHERE a.code = '5400');
+
+DROP TABLE IF EXISTS loan_write_offs;
@@ -0,0 +1,27 @@
+-- Loan write-offs: uncollectible balances posted as a loss
+CREATE TABLE loan_write_offs (
+    id              BIGSERIAL PRIMARY KEY,
+    loan_id         BIGINT NOT NULL UNIQUE REFERENCES loans(id),
+    branch_id       BIGINT NOT NULL REFERENCES branches(id),
+
+    principal       DECIMAL(12,2) NOT NULL DEFAULT 0,
+    interest        DECIMAL(12,2) NOT NULL DEFAULT 0,
+    late_fees       DECIMAL(12,2) NOT NULL DEFAULT 0,
+    amount          DECIMAL(12,2) NOT NULL DEFAULT 0,
+
+    reason          TEXT NOT NULL,
+    entry_id        BIGINT REFERENCES accounting_entries(id),
+    written_off_by  BIGINT NOT NULL REFERENCES users(id),
+    written_off_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_loan_write_offs_branch_date ON loan_write_offs(branch_id, written_off_at DESC);
+
+-- Loss account the write-offs are booked to
+INSERT INTO accounts (code, name, account_type, parent_id, is_system)
+SELECT '5400', 'Pérdidas por Préstamos Incobrables', 'expense', id, true FROM accounts WHERE code = '5000'
+ON CONFLICT (code) DO NOTHING;
+
+-- Writing off loans is reserved to administrators
+UPDATE roles SET permissions = permissions || '["accounting.write_off"]'::jsonb
+WHERE name = 'admin' AND NOT permissions ? 'accounting.write_off';
@@ -0,0 +1 @@
+DELETE FROM settings WHERE key IN ('money_locale', 'api_money_formatting') AND branch_id IS NULL;
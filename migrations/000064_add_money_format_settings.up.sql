@@ -0,0 +1,6 @@
+-- Amounts are written in each branch's currency using the configured locale.
+-- Formatted amounts in loan and payment responses are opt-in.
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('money_locale', '"es-GT"', 'Configuración regional para mostrar montos (separadores de miles y decimales)', NULL),
+    ('api_money_formatting', 'false', 'Incluir montos formateados en la moneda de la sucursal en las respuestas de préstamos y pagos', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
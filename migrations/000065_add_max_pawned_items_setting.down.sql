@@ -0,0 +1 @@
+DELETE FROM settings WHERE key = 'max_pawned_items_per_customer' AND branch_id IS NULL;
@@ -0,0 +1,6 @@
+-- Cap on how many items a single customer may have pawned at once (0 = no cap).
+-- Going past it needs loans.override_item_limit, covered by loans.* for admins
+-- and managers.
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('max_pawned_items_per_customer', '0', 'Máximo de artículos empeñados a la vez por cliente (0 = sin límite)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1 @@
+DELETE FROM settings WHERE key IN ('round_trip_detection_enabled', 'round_trip_window_days') AND branch_id IS NULL;
@@ -0,0 +1,7 @@
+-- Flag sales that look like round-tripping: an item sold back to the customer
+-- who lost it on a loan, or sold soon after being pawned. Flagged sales need a
+-- note and alert the branch managers.
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('round_trip_detection_enabled', 'false', 'Detectar ventas circulares (artículo vendido al cliente que lo empeñó o vendido poco después de empeñarse)', NULL),
+    ('round_trip_window_days', '15', 'Días desde el empeño en los que la venta de un artículo se considera sospechosa', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+DELETE FROM settings WHERE key IN ('item_attention_aged_days', 'item_attention_transfer_days', 'item_attention_photo_value') AND branch_id IS NULL;
+DROP INDEX IF EXISTS idx_item_transfers_in_transit;
+DROP INDEX IF EXISTS idx_items_attention_no_photos;
+DROP INDEX IF EXISTS idx_items_attention_status;
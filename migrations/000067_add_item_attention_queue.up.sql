@@ -0,0 +1,15 @@
+-- Partial indexes backing the queue of items needing attention: the few items
+-- that are confiscated, for sale or in transfer, the items without photos, and
+-- the transfers still on their way.
+CREATE INDEX IF NOT EXISTS idx_items_attention_status ON items (branch_id, status)
+    WHERE deleted_at IS NULL AND status IN ('confiscated', 'for_sale', 'in_transfer');
+CREATE INDEX IF NOT EXISTS idx_items_attention_no_photos ON items (branch_id, appraised_value)
+    WHERE deleted_at IS NULL AND COALESCE(cardinality(photos), 0) = 0;
+CREATE INDEX IF NOT EXISTS idx_item_transfers_in_transit ON item_transfers (item_id)
+    WHERE status = 'in_transit';
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('item_attention_aged_days', '90', 'Días a la venta tras los que un artículo aparece en la cola de atención', NULL),
+    ('item_attention_transfer_days', '3', 'Días en tránsito tras los que un traslado sin recibir aparece en la cola de atención', NULL),
+    ('item_attention_photo_value', '5000', 'Avalúo desde el que un artículo sin fotos aparece en la cola de atención', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
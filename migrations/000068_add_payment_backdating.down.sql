@@ -0,0 +1 @@
+DELETE FROM settings WHERE key = 'payment_backdate_max_days' AND branch_id IS NULL;
@@ -0,0 +1,5 @@
+-- How many days back a payment may be dated (0 = no backdating). Backdating
+-- needs payments.backdate, covered by payments.* for the default roles.
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('payment_backdate_max_days', '7', 'Días hacia atrás con los que se puede fechar un pago (0 = no se permite)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_items_soft_deleted;
+
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_item_id_fkey;
+ALTER TABLE sales ADD CONSTRAINT sales_item_id_fkey
+    FOREIGN KEY (item_id) REFERENCES items(id);
+
+ALTER TABLE loans DROP CONSTRAINT IF EXISTS loans_item_id_fkey;
+ALTER TABLE loans ADD CONSTRAINT loans_item_id_fkey
+    FOREIGN KEY (item_id) REFERENCES items(id);
@@ -0,0 +1,11 @@
+-- Items are only ever soft deleted. Make the references from loans and sales
+-- explicitly block removing an item row they point to.
+ALTER TABLE loans DROP CONSTRAINT IF EXISTS loans_item_id_fkey;
+ALTER TABLE loans ADD CONSTRAINT loans_item_id_fkey
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE RESTRICT;
+
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_item_id_fkey;
+ALTER TABLE sales ADD CONSTRAINT sales_item_id_fkey
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_items_soft_deleted ON items (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_notification_preferences;
+DROP INDEX IF EXISTS idx_internal_notifications_digest_pending;
+ALTER TABLE internal_notifications DROP COLUMN IF EXISTS digest_pending;
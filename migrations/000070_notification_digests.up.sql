@@ -0,0 +1,15 @@
+-- Internal notifications of users in digest mode are held back until the
+-- worker bundles them into a summary
+ALTER TABLE internal_notifications ADD COLUMN IF NOT EXISTS digest_pending BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_internal_notifications_digest_pending ON internal_notifications (user_id, created_at)
+    WHERE digest_pending = true;
+
+-- How each user wants to receive internal notifications; users without a row
+-- receive them as they happen
+CREATE TABLE IF NOT EXISTS user_notification_preferences (
+    user_id               BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    digest_mode           BOOLEAN NOT NULL DEFAULT false,
+    digest_interval_hours INTEGER NOT NULL DEFAULT 4 CHECK (digest_interval_hours > 0),
+    last_digest_at        TIMESTAMPTZ,
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
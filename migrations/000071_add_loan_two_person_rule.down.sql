@@ -0,0 +1,5 @@
+DELETE FROM settings WHERE key = 'loan_two_person_threshold' AND branch_id IS NULL;
+DROP INDEX IF EXISTS idx_loans_awaiting_authorization;
+ALTER TABLE loans DROP COLUMN IF EXISTS authorized_at;
+ALTER TABLE loans DROP COLUMN IF EXISTS authorized_by;
+ALTER TABLE loans DROP COLUMN IF EXISTS authorization_required;
@@ -0,0 +1,12 @@
+-- Two-person rule for high-value cash loans: the disbursement waits until a
+-- second user with loans.authorize (covered by loans.*) co-signs the loan.
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS authorization_required BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS authorized_by BIGINT REFERENCES users(id);
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS authorized_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_loans_awaiting_authorization ON loans (branch_id)
+    WHERE authorization_required AND authorized_by IS NULL AND deleted_at IS NULL;
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loan_two_person_threshold', '0', 'Monto de préstamo en efectivo por encima del cual el desembolso requiere la autorización de un segundo usuario (0 = desactivado)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,14 @@
+-- Change history of settings written through the settings API. Values of
+-- secret settings are stored redacted.
+CREATE TABLE IF NOT EXISTS setting_history (
+    id          BIGSERIAL PRIMARY KEY,
+    setting_key VARCHAR(255) NOT NULL,
+    branch_id   BIGINT REFERENCES branches(id) ON DELETE CASCADE,
+    action      VARCHAR(20) NOT NULL,  -- set, delete
+    old_value   JSONB,
+    new_value   JSONB,
+    changed_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_setting_history_key ON setting_history (setting_key, COALESCE(branch_id, 0), changed_at DESC);
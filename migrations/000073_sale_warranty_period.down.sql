@@ -0,0 +1,3 @@
+DELETE FROM settings WHERE key IN ('sale_warranty_days', 'sale_refund_outside_warranty') AND branch_id IS NULL;
+ALTER TABLE sales DROP COLUMN IF EXISTS refund_outside_warranty;
+ALTER TABLE categories DROP COLUMN IF EXISTS warranty_days;
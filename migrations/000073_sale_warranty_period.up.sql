@@ -0,0 +1,9 @@
+-- Warranty / return period of sales. A category may set its own period; the
+-- sale keeps the period and last day in the existing warranty columns.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS warranty_days INTEGER CHECK (warranty_days >= 0);
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS refund_outside_warranty BOOLEAN NOT NULL DEFAULT false;
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('sale_warranty_days', '0', 'Días de garantía y devolución de los artículos vendidos cuya categoría no define un período (0 = sin garantía)', NULL),
+    ('sale_refund_outside_warranty', '"warn"', 'Reembolsos fuera del período de garantía: warn (se permiten y se marcan) o block (se rechazan)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
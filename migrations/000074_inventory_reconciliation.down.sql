@@ -0,0 +1,6 @@
+DELETE FROM settings WHERE key IN (
+    'inventory_reconciliation_account',
+    'inventory_valuation_basis',
+    'inventory_reconciliation_tolerance',
+    'period_close_inventory_check'
+) AND branch_id IS NULL;
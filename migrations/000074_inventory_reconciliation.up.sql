@@ -0,0 +1,8 @@
+-- Reconciliation of the value of the stock on hand to the inventory account,
+-- also run when closing an accounting period
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('inventory_reconciliation_account', '"1320"', 'Código de la cuenta contable que refleja el valor del inventario propio (incluye sus subcuentas)', NULL),
+    ('inventory_valuation_basis', '"cost"', 'Valoración de los artículos en existencia al conciliar el inventario: cost (precio de adquisición, o avalúo si no se conoce) o appraised (avalúo)', NULL),
+    ('inventory_reconciliation_tolerance', '0', 'Diferencia máxima entre la cuenta de inventario y el valor de las existencias que se acepta como conciliada', NULL),
+    ('period_close_inventory_check', '"warn"', 'Conciliación de inventario al cerrar un período contable: off (no se revisa), warn (se informa la diferencia) o block (impide el cierre)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
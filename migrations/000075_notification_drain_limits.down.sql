@@ -0,0 +1,6 @@
+DELETE FROM settings WHERE key IN (
+    'notification_drain_batch_size',
+    'notification_drain_batch_delay_ms',
+    'notification_drain_max_batches',
+    'notification_drain_concurrency'
+) AND branch_id IS NULL;
@@ -0,0 +1,7 @@
+-- Limits of the worker's notification queue drain
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('notification_drain_batch_size', '50', 'Cantidad de notificaciones pendientes que el worker lee por lote', NULL),
+    ('notification_drain_batch_delay_ms', '1000', 'Pausa entre lotes de notificaciones, en milisegundos', NULL),
+    ('notification_drain_max_batches', '10', 'Cantidad máxima de lotes de notificaciones enviados en cada ejecución', NULL),
+    ('notification_drain_concurrency', '{"sms": 2, "email": 5}', 'Envíos simultáneos permitidos por canal; se reducen a la mitad cuando el proveedor limita la tasa', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1 @@
+DELETE FROM settings WHERE key IN ('money_symbol', 'money_symbol_position') AND branch_id IS NULL;
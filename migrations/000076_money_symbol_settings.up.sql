@@ -0,0 +1,6 @@
+-- Currency symbol and its position, for branches that write amounts differently
+-- from their currency's default (e.g. 1.234,50 €)
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('money_symbol', '""', 'Símbolo de moneda a mostrar en montos y documentos; vacío usa el de la moneda de la sucursal', NULL),
+    ('money_symbol_position', '"prefix"', 'Posición del símbolo de moneda: prefix (antes del monto) o suffix (después del monto)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notifications_external_message_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS external_message_id;
@@ -0,0 +1,5 @@
+-- Message ID the SMS / WhatsApp provider assigned to a notification, used to
+-- match the delivery receipts it posts back
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS external_message_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_notifications_external_message_id ON notifications (external_message_id)
+    WHERE external_message_id IS NOT NULL;
@@ -0,0 +1 @@
+DELETE FROM settings WHERE key = 'notification_quiet_hours';
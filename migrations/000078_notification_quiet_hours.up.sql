@@ -0,0 +1,4 @@
+-- Daily window during which customer notifications are held back until it ends
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('notification_quiet_hours', '{"start": "21:00", "end": "08:00"}', 'Horario en que no se envían notificaciones a clientes, en la zona horaria de la sucursal; las creadas en ese horario se programan para cuando termina', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
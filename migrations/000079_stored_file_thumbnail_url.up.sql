@@ -0,0 +1,3 @@
+-- Thumbnail generated for uploaded images, so listings can avoid loading the
+-- full-resolution photo
+ALTER TABLE stored_files ADD COLUMN IF NOT EXISTS thumbnail_url VARCHAR(500);
@@ -0,0 +1,6 @@
+DELETE FROM settings WHERE key IN (
+    'payment_cash_account',
+    'payment_principal_account',
+    'payment_interest_income_account',
+    'payment_late_fee_income_account'
+) AND branch_id IS NULL;
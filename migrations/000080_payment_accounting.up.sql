@@ -0,0 +1,7 @@
+-- Accounts the entry posted for every payment is booked to
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('payment_cash_account', '"1110"', 'Código de la cuenta contable que se carga con el monto recibido en cada pago', NULL),
+    ('payment_principal_account', '"1210"', 'Código de la cuenta contable que se abona con el capital de cada pago', NULL),
+    ('payment_interest_income_account', '"4100"', 'Código de la cuenta contable que se abona con los intereses de cada pago', NULL),
+    ('payment_late_fee_income_account', '"4200"', 'Código de la cuenta contable que se abona con la mora de cada pago', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,8 @@
+-- Remove multi-currency cash sessions
+DROP TABLE IF EXISTS cash_session_currencies;
+
+DROP INDEX IF EXISTS idx_cash_movements_session_currency;
+
+ALTER TABLE cash_movements DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE cash_sessions DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,29 @@
+-- Cash drawers that hold more than one currency
+ALTER TABLE cash_sessions
+    ADD COLUMN currency VARCHAR(10) NOT NULL DEFAULT 'GTQ';
+
+ALTER TABLE cash_movements
+    ADD COLUMN currency VARCHAR(10) NOT NULL DEFAULT 'GTQ';
+
+-- Existing sessions and movements are in their branch's currency
+UPDATE cash_sessions cs SET currency = b.currency
+FROM branches b
+WHERE b.id = cs.branch_id;
+
+UPDATE cash_movements cm SET currency = cs.currency
+FROM cash_sessions cs
+WHERE cs.id = cm.session_id;
+
+CREATE INDEX idx_cash_movements_session_currency ON cash_movements(session_id, currency);
+
+-- Amounts of the currencies a session holds besides its main one
+CREATE TABLE cash_session_currencies (
+    id                  BIGSERIAL PRIMARY KEY,
+    session_id          BIGINT NOT NULL REFERENCES cash_sessions(id) ON DELETE CASCADE,
+    currency            VARCHAR(10) NOT NULL,
+    opening_amount      DECIMAL(12,2) NOT NULL,
+    closing_amount      DECIMAL(12,2),
+    expected_amount     DECIMAL(12,2),
+    difference          DECIMAL(12,2),
+    UNIQUE (session_id, currency)
+);
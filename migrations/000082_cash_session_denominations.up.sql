@@ -0,0 +1,9 @@
+-- Bills and coins counted when closing a cash session
+CREATE TABLE cash_session_denominations (
+    id              BIGSERIAL PRIMARY KEY,
+    session_id      BIGINT NOT NULL REFERENCES cash_sessions(id) ON DELETE CASCADE,
+    currency        VARCHAR(10) NOT NULL,
+    denomination    DECIMAL(12,2) NOT NULL CHECK (denomination > 0),
+    count           INTEGER NOT NULL CHECK (count >= 0),
+    UNIQUE (session_id, currency, denomination)
+);
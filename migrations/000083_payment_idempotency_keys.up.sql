@@ -0,0 +1,7 @@
+-- Key sent by the client with a payment, so a repeated request returns the
+-- payment it already created instead of charging the loan twice
+ALTER TABLE payments ADD COLUMN idempotency_key VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_payments_idempotency_key
+ON payments(created_by, idempotency_key)
+WHERE idempotency_key IS NOT NULL;
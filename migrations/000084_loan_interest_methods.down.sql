@@ -0,0 +1,3 @@
+DELETE FROM settings WHERE key IN ('loan_interest_method') AND branch_id IS NULL;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS interest_method;
@@ -0,0 +1,8 @@
+-- Convention each loan's interest is computed with; existing loans keep the
+-- flat rate they were created with
+ALTER TABLE loans ADD COLUMN interest_method VARCHAR(20) NOT NULL DEFAULT 'flat'
+    CHECK (interest_method IN ('flat', 'simple', 'compound'));
+
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('loan_interest_method', '"flat"', 'Método de cálculo de intereses de los préstamos nuevos: flat (tasa única sobre el capital), simple (tasa mensual prorrateada por día) o compound (capitalización diaria)', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Late fee rate a category charges its loans; NULL uses the global
+-- default_late_fee_rate setting. A default_interest_rate of 0 likewise leaves
+-- new loans on the global default_interest_rate setting.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS default_late_fee_rate DECIMAL(5,2) CHECK (default_late_fee_rate >= 0 AND default_late_fee_rate <= 100);
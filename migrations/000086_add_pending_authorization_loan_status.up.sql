@@ -0,0 +1,3 @@
+-- Status of high-value loans whose cash disbursement waits for a co-signer.
+-- The value is used by the next migration, once this one is committed.
+ALTER TYPE loan_status ADD VALUE IF NOT EXISTS 'pending_authorization';
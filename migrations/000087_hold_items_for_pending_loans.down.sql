@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_loans_active_item;
+
+CREATE UNIQUE INDEX idx_loans_active_item
+ON loans(item_id)
+WHERE status IN ('active', 'overdue') AND deleted_at IS NULL;
+
+UPDATE loans SET status = 'active' WHERE status = 'pending_authorization';
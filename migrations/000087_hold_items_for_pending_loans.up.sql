@@ -0,0 +1,12 @@
+-- Loans still waiting for their disbursement to be co-signed were saved as
+-- active; move them to the pending status so payments, renewals and the
+-- interest jobs leave them alone until the cash is paid out.
+UPDATE loans SET status = 'pending_authorization'
+WHERE authorization_required AND authorized_by IS NULL AND status = 'active';
+
+-- A pending loan already holds its item as collateral
+DROP INDEX IF EXISTS idx_loans_active_item;
+
+CREATE UNIQUE INDEX idx_loans_active_item
+ON loans(item_id)
+WHERE status IN ('active', 'overdue', 'pending_authorization') AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DELETE FROM settings WHERE key IN (
+    'sale_income_account',
+    'sale_bank_account'
+) AND branch_id IS NULL;
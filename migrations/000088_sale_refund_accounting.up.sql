@@ -0,0 +1,5 @@
+-- Accounts the entry posted for every sale refund is booked to
+INSERT INTO settings (key, value, description, branch_id) VALUES
+    ('sale_income_account', '"4300"', 'Código de la cuenta contable que se carga con el monto de cada devolución de venta', NULL),
+    ('sale_bank_account', '"1120"', 'Código de la cuenta contable que se abona con las devoluciones de ventas no pagadas en efectivo', NULL)
+ON CONFLICT (key, COALESCE(branch_id, 0)) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Pending codes hashed with argon2id no longer fit and are dropped
+DELETE FROM contact_verifications WHERE LENGTH(code_hash) > 64;
+ALTER TABLE contact_verifications ALTER COLUMN code_hash TYPE VARCHAR(64);
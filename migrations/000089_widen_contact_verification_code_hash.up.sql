@@ -0,0 +1,2 @@
+-- Verification codes are stored as argon2id hashes, longer than a SHA-256 digest
+ALTER TABLE contact_verifications ALTER COLUMN code_hash TYPE VARCHAR(255);
@@ -0,0 +1,2 @@
+ALTER TABLE categories DROP COLUMN IF EXISTS max_loan_term_days;
+ALTER TABLE categories DROP COLUMN IF EXISTS min_loan_term_days;
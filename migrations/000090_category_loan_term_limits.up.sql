@@ -0,0 +1,4 @@
+-- Loan term bounds a category narrows the branch min/max_loan_term_days
+-- settings to; NULL leaves the branch bound in place
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS min_loan_term_days INTEGER CHECK (min_loan_term_days >= 0);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS max_loan_term_days INTEGER CHECK (max_loan_term_days >= 0);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_stored_files_file_id;
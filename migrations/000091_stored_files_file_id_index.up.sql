@@ -0,0 +1,3 @@
+-- Stored documents are looked up by file ID to check the requesting user can
+-- access the branch that owns them
+CREATE INDEX IF NOT EXISTS idx_stored_files_file_id ON stored_files(file_id) WHERE deleted_at IS NULL;
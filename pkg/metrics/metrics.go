@@ -134,6 +134,14 @@ var (
 		[]string{"job_name"},
 	)
 
+	jobLastSuccessAge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "job_last_success_age_seconds",
+			Help: "Seconds since the last successful execution of a job",
+		},
+		[]string{"job_name"},
+	)
+
 	// Notification Metrics
 	notificationsSent = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -142,6 +150,29 @@ var (
 		},
 		[]string{"channel", "type", "status"},
 	)
+
+	notificationsThrottled = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notifications_throttled_total",
+			Help: "Total number of notifications refused because the provider was throttling",
+		},
+		[]string{"channel"},
+	)
+
+	notificationDrainRate = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_drain_sent_per_second",
+			Help: "Notifications sent per second during the last queue drain",
+		},
+	)
+
+	notificationChannelConcurrency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "notification_channel_concurrency",
+			Help: "Concurrent sends currently allowed per notification channel",
+		},
+		[]string{"channel"},
+	)
 )
 
 // Middleware returns a Fiber middleware for collecting HTTP metrics
@@ -212,7 +243,27 @@ func RecordJobExecution(jobName, status string, duration time.Duration) {
 	jobDuration.WithLabelValues(jobName).Observe(duration.Seconds())
 }
 
+// SetJobLastSuccessAge sets the time since a job last succeeded
+func SetJobLastSuccessAge(jobName string, age time.Duration) {
+	jobLastSuccessAge.WithLabelValues(jobName).Set(age.Seconds())
+}
+
 // RecordNotificationSent records notification metrics
 func RecordNotificationSent(channel, notificationType, status string) {
 	notificationsSent.WithLabelValues(channel, notificationType, status).Inc()
 }
+
+// RecordNotificationThrottled records a notification refused by a throttling provider
+func RecordNotificationThrottled(channel string) {
+	notificationsThrottled.WithLabelValues(channel).Inc()
+}
+
+// SetNotificationDrainRate sets the send rate of the last notification queue drain
+func SetNotificationDrainRate(perSecond float64) {
+	notificationDrainRate.Set(perSecond)
+}
+
+// SetNotificationChannelConcurrency sets the concurrent sends allowed on a channel
+func SetNotificationChannelConcurrency(channel string, limit int) {
+	notificationChannelConcurrency.WithLabelValues(channel).Set(float64(limit))
+}
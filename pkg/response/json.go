@@ -90,6 +90,16 @@ func Created(c *fiber.Ctx, data interface{}) error {
 	})
 }
 
+// Accepted sends a successful response with status 202 for work that
+// finishes in the background
+func Accepted(c *fiber.Ctx, data interface{}) error {
+	return c.Status(fiber.StatusAccepted).JSON(Response{
+		Success: true,
+		Data:    data,
+		Meta:    newMeta(c),
+	})
+}
+
 // NoContent sends a successful response with status 204
 func NoContent(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
@@ -75,6 +75,25 @@ func TestCreated(t *testing.T) {
 	assert.NotNil(t, result.Data)
 }
 
+func TestAccepted(t *testing.T) {
+	app := setupTestApp()
+	app.Post("/test", func(c *fiber.Ctx) error {
+		return Accepted(c, map[string]int{"id": 1})
+	})
+
+	req := httptest.NewRequest("POST", "/test", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 202, resp.StatusCode)
+
+	var result Response
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+
+	assert.True(t, result.Success)
+	assert.NotNil(t, result.Data)
+}
+
 func TestNoContent(t *testing.T) {
 	app := setupTestApp()
 	app.Delete("/test", func(c *fiber.Ctx) error {
@@ -10,6 +10,8 @@ export interface Category {
   default_interest_rate: number
   min_loan_amount?: number
   max_loan_amount?: number
+  min_loan_term_days?: number
+  max_loan_term_days?: number
   loan_to_value_ratio: number
   sort_order: number
   is_active: boolean
@@ -27,6 +29,8 @@ export interface CreateCategoryInput {
   default_interest_rate?: number
   min_loan_amount?: number
   max_loan_amount?: number
+  min_loan_term_days?: number
+  max_loan_term_days?: number
   loan_to_value_ratio?: number
   sort_order?: number
 }
@@ -39,6 +43,8 @@ export interface UpdateCategoryInput {
   default_interest_rate?: number
   min_loan_amount?: number
   max_loan_amount?: number
+  min_loan_term_days?: number
+  max_loan_term_days?: number
   loan_to_value_ratio?: number
   sort_order?: number
   is_active?: boolean